@@ -9,6 +9,7 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sort"
 
 	"github.com/hashicorp/go-plugin"
 	svchost "github.com/hashicorp/terraform-svchost"
@@ -21,7 +22,9 @@ import (
 	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/command/webbrowser"
+	"github.com/opentofu/opentofu/internal/diffrenderer"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
 	pluginDiscovery "github.com/opentofu/opentofu/internal/plugin/discovery"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
@@ -62,6 +65,7 @@ func initCommands(
 	services *disco.Disco,
 	providerSrc getproviders.Source,
 	providerDevOverrides map[addrs.Provider]getproviders.PackageLocalDir,
+	providerPlatformFallbacks getproviders.PlatformFallbacks,
 	unmanagedProviders map[addrs.Provider]*plugin.ReattachConfig,
 ) {
 	var inAutomation bool
@@ -89,7 +93,7 @@ func initCommands(
 	meta := command.Meta{
 		WorkingDir: wd,
 		Streams:    streams,
-		View:       views.NewView(streams).SetRunningInAutomation(inAutomation),
+		View:       views.NewView(streams).SetRunningInAutomation(inAutomation).SetDiffRenderers(diffRenderers(config)),
 
 		Color:            true,
 		GlobalPluginDirs: globalPluginDirs(),
@@ -102,14 +106,23 @@ func initCommands(
 		CLIConfigDir:        configDir,
 		PluginCacheDir:      config.PluginCacheDir,
 
+		PluginSchemaCacheDir: config.PluginSchemaCacheDir,
+
+		ModulePackageCacheDir: config.ModulePackageCacheDir,
+
 		PluginCacheMayBreakDependencyLockFile: config.PluginCacheMayBreakDependencyLockFile,
 
+		WorkspaceAutoCreate: config.WorkspaceAutoCreate,
+
 		ShutdownCh:    makeShutdownCh(),
 		CallerContext: ctx,
 
-		ProviderSource:       providerSrc,
-		ProviderDevOverrides: providerDevOverrides,
-		UnmanagedProviders:   unmanagedProviders,
+		PlanAnalyzers: planAnalyzers(config),
+
+		ProviderSource:            providerSrc,
+		ProviderDevOverrides:      providerDevOverrides,
+		ProviderPlatformFallbacks: providerPlatformFallbacks,
+		UnmanagedProviders:        unmanagedProviders,
 
 		AllowExperimentalFeatures: experimentsAreAllowed(),
 	}
@@ -127,12 +140,36 @@ func initCommands(
 			}, nil
 		},
 
+		"clean": func() (cli.Command, error) {
+			return &command.CleanCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"check": func() (cli.Command, error) {
+			return &command.CheckCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"console": func() (cli.Command, error) {
 			return &command.ConsoleCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"debug": func() (cli.Command, error) {
+			return &command.DebugCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"debug profile": func() (cli.Command, error) {
+			return &command.DebugProfileCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"destroy": func() (cli.Command, error) {
 			return &command.ApplyCommand{
 				Meta:    meta,
@@ -193,6 +230,12 @@ func initCommands(
 			}, nil
 		},
 
+		"impact": func() (cli.Command, error) {
+			return &command.ImpactCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"import": func() (cli.Command, error) {
 			return &command.ImportCommand{
 				Meta: meta,
@@ -223,6 +266,12 @@ func initCommands(
 			}, nil
 		},
 
+		"metadata dump": func() (cli.Command, error) {
+			return &command.MetadataDumpCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"metadata functions": func() (cli.Command, error) {
 			return &command.MetadataFunctionsCommand{
 				Meta: meta,
@@ -241,6 +290,12 @@ func initCommands(
 			}, nil
 		},
 
+		"plan summary": func() (cli.Command, error) {
+			return &command.PlanSummaryCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"providers": func() (cli.Command, error) {
 			return &command.ProvidersCommand{
 				Meta: meta,
@@ -277,6 +332,18 @@ func initCommands(
 			}, nil
 		},
 
+		"schema export": func() (cli.Command, error) {
+			return &command.SchemaExportCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"serve": func() (cli.Command, error) {
+			return &command.ServeCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"show": func() (cli.Command, error) {
 			return &command.ShowCommand{
 				Meta: meta,
@@ -295,12 +362,30 @@ func initCommands(
 			}, nil
 		},
 
+		"test clean": func() (cli.Command, error) {
+			return &command.TestCleanCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"validate": func() (cli.Command, error) {
 			return &command.ValidateCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"vars": func() (cli.Command, error) {
+			return &command.VarsCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"vars show": func() (cli.Command, error) {
+			return &command.VarsShowCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"version": func() (cli.Command, error) {
 			return &command.VersionCommand{
 				Meta:              meta,
@@ -352,6 +437,12 @@ func initCommands(
 			}, nil
 		},
 
+		"workspace update": func() (cli.Command, error) {
+			return &command.WorkspaceUpdateCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		//-----------------------------------------------------------
 		// Plumbing
 		//-----------------------------------------------------------
@@ -366,6 +457,48 @@ func initCommands(
 			return &command.StateCommand{}, nil
 		},
 
+		"state annotate": func() (cli.Command, error) {
+			return &command.StateAnnotateCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
+		"state audit": func() (cli.Command, error) {
+			return &command.StateAuditCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state deposed": func() (cli.Command, error) {
+			return &command.StateDeposedCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state deposed list": func() (cli.Command, error) {
+			return &command.StateDeposedListCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state deposed destroy": func() (cli.Command, error) {
+			return &command.StateDeposedDestroyCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
+		"state deposed promote": func() (cli.Command, error) {
+			return &command.StateDeposedPromoteCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
 		"state list": func() (cli.Command, error) {
 			return &command.StateListCommand{
 				Meta: meta,
@@ -380,6 +513,12 @@ func initCommands(
 			}, nil
 		},
 
+		"state query": func() (cli.Command, error) {
+			return &command.StateQueryCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state rm": func() (cli.Command, error) {
 			return &command.StateRmCommand{
 				StateMeta: command.StateMeta{
@@ -406,6 +545,14 @@ func initCommands(
 			}, nil
 		},
 
+		"state move-cross": func() (cli.Command, error) {
+			return &command.StateMoveCrossCommand{
+				StateMeta: command.StateMeta{
+					Meta: meta,
+				},
+			}, nil
+		},
+
 		"state move": func() (cli.Command, error) {
 			return &command.AliasCommand{
 				Command: &command.StateMvCommand{
@@ -489,6 +636,45 @@ func credentialsSource(config *cliconfig.Config) (auth.CredentialsSource, error)
 	return config.CredentialsSource(helperPlugins)
 }
 
+// planAnalyzers returns the plan analyzers configured in the given CLI
+// configuration, in order of their names so that they always run in the
+// same order.
+func planAnalyzers(config *cliconfig.Config) []plananalyzer.Analyzer {
+	names := make([]string, 0, len(config.PlanAnalyzers))
+	for name := range config.PlanAnalyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ret := make([]plananalyzer.Analyzer, 0, len(names))
+	for _, name := range names {
+		analyzer := config.PlanAnalyzers[name]
+		ret = append(ret, &plananalyzer.External{
+			AnalyzerName: name,
+			Command:      analyzer.Command,
+			Args:         analyzer.Args,
+		})
+	}
+	return ret
+}
+
+// diffRenderers returns the diff renderers configured in the given CLI
+// configuration, keyed by the resource types that they render.
+func diffRenderers(config *cliconfig.Config) map[string]diffrenderer.Renderer {
+	ret := make(map[string]diffrenderer.Renderer)
+	for name, renderer := range config.DiffRenderers {
+		external := &diffrenderer.External{
+			RendererName: name,
+			Command:      renderer.Command,
+			Args:         renderer.Args,
+		}
+		for _, typeName := range renderer.ResourceTypes {
+			ret[typeName] = external
+		}
+	}
+	return ret
+}
+
 func getAliasCommandKeys() []string {
 	keys := []string{}
 	for key, cmdFact := range commands {
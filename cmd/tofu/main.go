@@ -28,7 +28,11 @@ import (
 	"github.com/opentofu/opentofu/internal/didyoumean"
 	"github.com/opentofu/opentofu/internal/httpclient"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/memlimit"
+	"github.com/opentofu/opentofu/internal/profiling"
+	"github.com/opentofu/opentofu/internal/secrets"
 	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/version"
 	"go.opentelemetry.io/otel/trace"
 
@@ -80,6 +84,13 @@ func realMain() int {
 		Ui.Error(fmt.Sprintf("Unset environment variable %s if you don't intend to collect telemetry from OpenTofu.", openTelemetryExporterEnvVar))
 		return 1
 	}
+	shutdownMetrics, err := openTelemetryMetricsInit()
+	if err != nil {
+		Ui.Error(fmt.Sprintf("Could not initialize metrics: %s", err))
+		Ui.Error(fmt.Sprintf("Unset environment variable %s if you don't intend to collect metrics from OpenTofu.", openTelemetryMetricsExporterEnvVar))
+		return 1
+	}
+	defer shutdownMetrics()
 	var ctx context.Context
 	var otelSpan trace.Span
 	{
@@ -114,6 +125,28 @@ func realMain() int {
 		log.Printf("[INFO] This build of OpenTofu allows using experimental features")
 	}
 
+	if raw := os.Getenv(memlimit.EnvVar); raw != "" {
+		limit, err := memlimit.Parse(raw)
+		if err != nil {
+			Ui.Error(fmt.Sprintf("Invalid %s value: %s", memlimit.EnvVar, err))
+			return 1
+		}
+		memlimit.Start(ctx, limit)
+	}
+
+	if cpuProfile, profileDir := os.Getenv(profiling.CPUProfileEnvVar), os.Getenv(profiling.DirEnvVar); cpuProfile != "" || profileDir != "" {
+		stopProfiling, err := profiling.Start(cpuProfile, profileDir)
+		if err != nil {
+			Ui.Error(fmt.Sprintf("Could not start profiling: %s", err))
+			return 1
+		}
+		defer func() {
+			if err := stopProfiling(); err != nil {
+				Ui.Error(fmt.Sprintf("Could not write the profiles: %s", err))
+			}
+		}()
+	}
+
 	streams, err := terminal.Init()
 	if err != nil {
 		Ui.Error(fmt.Sprintf("Failed to configure the terminal: %s", err))
@@ -184,8 +217,11 @@ func realMain() int {
 		services = disco.NewWithCredentialsSource(nil)
 	}
 	services.SetUserAgent(httpclient.OpenTofuUserAgent(version.String()))
+	services.Transport = httpclient.OfflineRoundTripper(services.Transport)
 
 	providerSrc, diags := providerSource(config.ProviderInstallation, services)
+	platformFallbacks, moreDiags := providerPlatformFallbacks(config.ProviderInstallation)
+	diags = diags.Append(moreDiags)
 	if len(diags) > 0 {
 		Ui.Error("There are some problems with the provider_installation configuration:")
 		for _, diag := range diags {
@@ -215,6 +251,35 @@ func realMain() int {
 	// Initialize the backends.
 	backendInit.Init(services)
 
+	// Configure where values marked as secret are saved, if anywhere. Unlike
+	// the other problems with the CLI configuration, more than one secrets
+	// helper is fatal, because the values saved with one of them can't be
+	// retrieved with another.
+	if len(config.SecretsHelpers) > 1 {
+		diag := tfdiags.Sourceless(
+			tfdiags.Error,
+			"Multiple secrets helpers",
+			"The CLI configuration has more than one secrets_helper block, so OpenTofu can't decide where to save and retrieve secret values. Remove all but one of them.",
+		)
+		earlyColor := &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true, // Disable color to be conservative until we know better
+			Reset:   true,
+		}
+		Ui.Error(format.Diagnostic(diag, nil, earlyColor, 78))
+		return 1
+	}
+	for _, helper := range config.SecretsHelpers {
+		if helper == nil || helper.Command == "" {
+			// Already reported when validating the CLI configuration.
+			continue
+		}
+		secrets.SetStore(&secrets.External{
+			Command: helper.Command,
+			Args:    helper.Args,
+		})
+	}
+
 	// Get the command line args.
 	binName := filepath.Base(os.Args[0])
 	args := os.Args[1:]
@@ -242,13 +307,22 @@ func realMain() int {
 		}
 	}
 
+	// The arguments can also begin with an -offline option, or the
+	// TF_OFFLINE environment variable can be set, to ask OpenTofu to fail
+	// any attempt to access the network instead of making the request.
+	offline, args := extractOfflineOption(args)
+	if offline || os.Getenv(offlineEnvVar) != "" {
+		log.Printf("[INFO] Running in offline mode, so all network access is disabled")
+		httpclient.SetOffline(true)
+	}
+
 	// In tests, Commands may already be set to provide mock commands
 	if commands == nil {
 		// Commands get to hold on to the original working directory here,
 		// in case they need to refer back to it for any special reason, though
 		// they should primarily be working with the override working directory
 		// that we've now switched to above.
-		initCommands(ctx, originalWd, streams, config, services, providerSrc, providerDevOverrides, unmanagedProviders)
+		initCommands(ctx, originalWd, streams, config, services, providerSrc, providerDevOverrides, platformFallbacks, unmanagedProviders)
 	}
 
 	// Attempt to ensure the config directory exists.
@@ -457,6 +531,29 @@ func parseReattachProviders(in string) (map[addrs.Provider]*plugin.ReattachConfi
 	return unmanagedProviders, nil
 }
 
+// offlineEnvVar is the environment variable that enables offline mode when
+// set to any non-empty value, as an alternative to the -offline option.
+const offlineEnvVar = "TF_OFFLINE"
+
+// extractOfflineOption returns true if the given arguments include the
+// global -offline option before the subcommand, along with the arguments
+// with that option removed.
+func extractOfflineOption(args []string) (bool, []string) {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			// Like -chdir, -offline must appear before any subcommand.
+			break
+		}
+		if arg == "-offline" {
+			newArgs := make([]string, 0, len(args)-1)
+			newArgs = append(newArgs, args[:i]...)
+			newArgs = append(newArgs, args[i+1:]...)
+			return true, newArgs
+		}
+	}
+	return false, args
+}
+
 func extractChdirOption(args []string) (string, []string, error) {
 	if len(args) == 0 {
 		return "", args, nil
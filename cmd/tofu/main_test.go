@@ -368,3 +368,39 @@ func TestMkConfigDir_noparent(t *testing.T) {
 		t.Fatalf("Expected error: %s, but got: %v", expectedError, err)
 	}
 }
+
+func TestExtractOfflineOption(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		wantOffline bool
+		wantArgs    []string
+	}{
+		"none": {
+			[]string{"init", "-upgrade"},
+			false,
+			[]string{"init", "-upgrade"},
+		},
+		"before subcommand": {
+			[]string{"-chdir=foo", "-offline", "init"},
+			true,
+			[]string{"-chdir=foo", "init"},
+		},
+		"after subcommand": {
+			[]string{"init", "-offline"},
+			false,
+			[]string{"init", "-offline"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotOffline, gotArgs := extractOfflineOption(test.args)
+			if gotOffline != test.wantOffline {
+				t.Errorf("wrong offline result %t; want %t", gotOffline, test.wantOffline)
+			}
+			if !reflect.DeepEqual(gotArgs, test.wantArgs) {
+				t.Errorf("wrong args\ngot:  %#v\nwant: %#v", gotArgs, test.wantArgs)
+			}
+		})
+	}
+}
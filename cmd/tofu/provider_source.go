@@ -244,3 +244,42 @@ func providerDevOverrides(configs []*cliconfig.ProviderInstallation) map[addrs.P
 	// ignore any additional configurations in here.
 	return configs[0].DevOverrides
 }
+
+func providerPlatformFallbacks(configs []*cliconfig.ProviderInstallation) (getproviders.PlatformFallbacks, tfdiags.Diagnostics) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	// There should only be zero or one configurations, which is checked by
+	// the validation logic in the cliconfig package. Therefore we'll just
+	// ignore any additional configurations in here.
+	var diags tfdiags.Diagnostics
+	var ret getproviders.PlatformFallbacks
+	for _, fallbackConfig := range configs[0].PlatformFallbacks {
+		include, err := getproviders.ParseMultiSourceMatchingPatterns(fallbackConfig.Include)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider platform fallback inclusion patterns",
+				fmt.Sprintf("CLI config specifies invalid provider inclusion patterns: %s.", err),
+			))
+			continue
+		}
+		exclude, err := getproviders.ParseMultiSourceMatchingPatterns(fallbackConfig.Exclude)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider platform fallback exclusion patterns",
+				fmt.Sprintf("CLI config specifies invalid provider exclusion patterns: %s.", err),
+			))
+			continue
+		}
+
+		ret = append(ret, getproviders.PlatformFallback{
+			Platforms: fallbackConfig.Platforms,
+			Include:   include,
+			Exclude:   exclude,
+		})
+	}
+	return ret, diags
+}
@@ -7,11 +7,15 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"go.opentelemetry.io/contrib/exporters/autoexport"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
@@ -31,6 +35,11 @@ import (
 // better based on experience with this experiment.
 const openTelemetryExporterEnvVar = "OTEL_TRACES_EXPORTER"
 
+// If this environment variable is set to "otlp" when running OpenTofu CLI
+// then we'll enable an experimental OTLP metrics exporter, with the same
+// caveats as for openTelemetryExporterEnvVar above.
+const openTelemetryMetricsExporterEnvVar = "OTEL_METRICS_EXPORTER"
+
 // tracer is the OpenTelemetry tracer to use for traces in package main only.
 var tracer trace.Tracer
 
@@ -65,11 +74,7 @@ func openTelemetryInit() error {
 		return nil // By default we just discard all telemetry calls
 	}
 
-	otelResource := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String("OpenTofu CLI"),
-		semconv.ServiceVersionKey.String(version.Version),
-	)
+	otelResource := openTelemetryResource()
 
 	// If the environment variable was set to explicitly enable telemetry
 	// then we'll enable it, using the "autoexport" library to automatically
@@ -91,3 +96,65 @@ func openTelemetryInit() error {
 
 	return nil
 }
+
+// openTelemetryMetricsInit initializes the optional OpenTelemetry metrics
+// exporter, returning a function that must be called before OpenTofu exits
+// to export any metrics recorded since the last periodic export.
+//
+// As with traces, metrics are only exported if the standard environment
+// variable OTEL_METRICS_EXPORTER is set to "otlp", in which case the exporter
+// is configured by the standard OTLP exporter environment variables. The
+// protocol is selected by OTEL_EXPORTER_OTLP_METRICS_PROTOCOL or
+// OTEL_EXPORTER_OTLP_PROTOCOL, defaulting to "grpc" to match the behavior
+// of the trace exporter.
+func openTelemetryMetricsInit() (shutdown func(), err error) {
+	if os.Getenv(openTelemetryMetricsExporterEnvVar) != "otlp" {
+		return func() {}, nil // By default we just discard all metrics
+	}
+
+	ctx := context.Background()
+	var exp sdkmetric.Exporter
+	switch proto := openTelemetryMetricsProtocol(); proto {
+	case "grpc":
+		exp, err = otlpmetricgrpc.New(ctx)
+	case "http/protobuf":
+		exp, err = otlpmetrichttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP metrics protocol %q", proto)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp)),
+		sdkmetric.WithResource(openTelemetryResource()),
+	)
+	otel.SetMeterProvider(provider)
+
+	return func() {
+		// Shutdown also flushes the metrics recorded since the last
+		// periodic export, which for most commands is all of them.
+		if err := provider.Shutdown(ctx); err != nil {
+			Ui.Error(fmt.Sprintf("Could not export metrics: %s", err))
+		}
+	}, nil
+}
+
+func openTelemetryMetricsProtocol() string {
+	if proto := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"); proto != "" {
+		return proto
+	}
+	if proto := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); proto != "" {
+		return proto
+	}
+	return "grpc"
+}
+
+func openTelemetryResource() *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String("OpenTofu CLI"),
+		semconv.ServiceVersionKey.String(version.Version),
+	)
+}
@@ -202,12 +202,27 @@ func parseRef(traversal hcl.Traversal) (*Reference, tfdiags.Diagnostics) {
 				Summary:  "Invalid reference",
 				Detail:   `The "data" object must be followed by two attribute names: the data source type and the resource name.`,
 				Subject:  traversal.SourceRange().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 			})
 			return nil, diags
 		}
 		remain := traversal[1:] // trim off "data" so we can use our shared resource reference parser
 		return parseResourceRef(DataResourceMode, rootRange, remain)
 
+	case "ephemeral":
+		if len(traversal) < 3 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid reference",
+				Detail:   `The "ephemeral" object must be followed by two attribute names: the ephemeral resource type and the resource name.`,
+				Subject:  traversal.SourceRange().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
+			})
+			return nil, diags
+		}
+		remain := traversal[1:] // trim off "ephemeral" so we can use our shared resource reference parser
+		return parseResourceRef(EphemeralResourceMode, rootRange, remain)
+
 	case "resource":
 		// This is an alias for the normal case of just using a managed resource
 		// type as a top-level symbol, which will serve as an escape mechanism
@@ -223,6 +238,7 @@ func parseRef(traversal hcl.Traversal) (*Reference, tfdiags.Diagnostics) {
 				Summary:  "Invalid reference",
 				Detail:   `The "resource" object must be followed by two attribute names: the resource type and the resource name.`,
 				Subject:  traversal.SourceRange().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 			})
 			return nil, diags
 		}
@@ -307,6 +323,7 @@ func parseRef(traversal hcl.Traversal) (*Reference, tfdiags.Diagnostics) {
 			Summary:  "Invalid reference",
 			Detail:   "Module instance objects do not support this operation.",
 			Subject:  remain[0].SourceRange().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 		})
 		return nil, diags
 
@@ -390,6 +407,7 @@ func parseResourceRef(mode ResourceMode, startRange hcl.Range, traversal hcl.Tra
 			Summary:  "Invalid reference",
 			Detail:   `A reference to a resource type must be followed by at least one attribute access, specifying the resource name.`,
 			Subject:  hcl.RangeBetween(traversal[0].SourceRange(), traversal[len(traversal)-1].SourceRange()).Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 		})
 		return nil, diags
 	}
@@ -407,6 +425,7 @@ func parseResourceRef(mode ResourceMode, startRange hcl.Range, traversal hcl.Tra
 			Summary:  "Invalid reference",
 			Detail:   `The "data" object does not support this operation.`,
 			Subject:  traversal[0].SourceRange().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 		})
 		return nil, diags
 	}
@@ -417,6 +436,8 @@ func parseResourceRef(mode ResourceMode, startRange hcl.Range, traversal hcl.Tra
 		switch mode {
 		case DataResourceMode:
 			what = "data source"
+		case EphemeralResourceMode:
+			what = "ephemeral resource type"
 		default:
 			what = "resource type"
 		}
@@ -425,6 +446,7 @@ func parseResourceRef(mode ResourceMode, startRange hcl.Range, traversal hcl.Tra
 			Summary:  "Invalid reference",
 			Detail:   fmt.Sprintf(`A reference to a %s must be followed by at least one attribute access, specifying the resource name.`, what),
 			Subject:  traversal[1].SourceRange().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 		})
 		return nil, diags
 	}
@@ -487,6 +509,7 @@ func parseSingleAttrRef(traversal hcl.Traversal) (string, hcl.Range, hcl.Travers
 			Summary:  "Invalid reference",
 			Detail:   fmt.Sprintf("The %q object cannot be accessed directly. Instead, access one of its attributes.", root),
 			Subject:  &rootRange,
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 		})
 		return "", hcl.Range{}, nil, diags
 	}
@@ -498,6 +521,7 @@ func parseSingleAttrRef(traversal hcl.Traversal) (string, hcl.Range, hcl.Travers
 		Summary:  "Invalid reference",
 		Detail:   fmt.Sprintf("The %q object does not support this operation.", root),
 		Subject:  traversal[1].SourceRange().Ptr(),
+		Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidReference, nil),
 	})
 	return "", hcl.Range{}, nil, diags
 }
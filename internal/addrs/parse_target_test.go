@@ -127,6 +127,24 @@ func TestParseTarget(t *testing.T) {
 			},
 			``,
 		},
+		{
+			`ephemeral.vault_token.foo`,
+			&Target{
+				Subject: AbsResource{
+					Resource: Resource{
+						Mode: EphemeralResourceMode,
+						Type: "vault_token",
+						Name: "foo",
+					},
+					Module: RootModuleInstance,
+				},
+				SourceRange: tfdiags.SourceRange{
+					Start: tfdiags.SourcePos{Line: 1, Column: 1, Byte: 0},
+					End:   tfdiags.SourcePos{Line: 1, Column: 26, Byte: 25},
+				},
+			},
+			``,
+		},
 		{
 			`data.aws_instance.foo[1]`,
 			&Target{
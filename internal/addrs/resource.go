@@ -29,6 +29,8 @@ func (r Resource) String() string {
 		return fmt.Sprintf("%s.%s", r.Type, r.Name)
 	case DataResourceMode:
 		return fmt.Sprintf("data.%s.%s", r.Type, r.Name)
+	case EphemeralResourceMode:
+		return fmt.Sprintf("ephemeral.%s.%s", r.Type, r.Name)
 	default:
 		// Should never happen, but we'll return a string here rather than
 		// crashing just in case it does.
@@ -43,7 +45,7 @@ func (r Resource) Equal(o Resource) bool {
 func (r Resource) Less(o Resource) bool {
 	switch {
 	case r.Mode != o.Mode:
-		return r.Mode == DataResourceMode
+		return r.Mode.order() < o.Mode.order()
 
 	case r.Type != o.Type:
 		return r.Type < o.Type
@@ -511,4 +513,23 @@ const (
 	// DataResourceMode indicates a data resource, as defined by
 	// "data" blocks in configuration.
 	DataResourceMode ResourceMode = 'D'
+
+	// EphemeralResourceMode indicates an ephemeral resource, as defined by
+	// "ephemeral" blocks in configuration. The objects of ephemeral resources
+	// only exist during a single operation, and are never saved in the state
+	// or in a plan.
+	EphemeralResourceMode ResourceMode = 'E'
 )
+
+// order returns the position of the mode in the sort order of resources,
+// which puts data resources first and ephemeral resources last.
+func (m ResourceMode) order() int {
+	switch m {
+	case DataResourceMode:
+		return 0
+	case ManagedResourceMode:
+		return 1
+	default:
+		return 2
+	}
+}
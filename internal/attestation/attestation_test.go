@@ -0,0 +1,186 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeal(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := LoadSigningKey(keyPath)
+	if err != nil {
+		t.Fatalf("failed to load key: %s", err)
+	}
+
+	run := &Run{
+		Command:    "plan",
+		Version:    "1.10.0",
+		Parameters: map[string]any{"workspace": "default"},
+		Subjects: []ResourceDescriptor{
+			{Name: "plan", Digest: SHA256Digest([]byte("plan"))},
+		},
+		StartedOn:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		FinishedOn: time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC),
+	}
+	env, err := Seal(run.Statement(), signer)
+	if err != nil {
+		t.Fatalf("failed to seal: %s", err)
+	}
+
+	got, err := env.Statement()
+	if err != nil {
+		t.Fatalf("invalid payload: %s", err)
+	}
+	if diff := cmp.Diff(run.Statement(), got); diff != "" {
+		t.Fatalf("wrong statement\n%s", diff)
+	}
+	if got.Predicate.BuildDefinition.BuildType != "https://opentofu.org/attestation/plan/v1" {
+		t.Fatalf("wrong build type %q", got.Predicate.BuildDefinition.BuildType)
+	}
+
+	if len(env.Signatures) != 1 {
+		t.Fatalf("wrong number of signatures %d", len(env.Signatures))
+	}
+	wantKeyID, err := KeyID(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Signatures[0].KeyID != wantKeyID {
+		t.Fatalf("wrong key ID %q; want %q", env.Signatures[0].KeyID, wantKeyID)
+	}
+	payload, _ := base64.StdEncoding.DecodeString(env.Payload)
+	sig, _ := base64.StdEncoding.DecodeString(env.Signatures[0].Sig)
+	digest := sha256.Sum256(preAuthEncoding(PayloadType, payload))
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig) {
+		t.Fatal("signature does not verify")
+	}
+}
+
+func TestPushOCI(t *testing.T) {
+	blobs := make(map[string][]byte)
+	var manifest []byte
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("scope"), "repository:infra/attestations:pull,push"; got != want {
+			t.Errorf("wrong scope %q; want %q", got, want)
+		}
+		io.WriteString(w, `{"token":"s3cret"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="http://`+r.Host+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/infra/attestations/blobs/uploads/":
+			w.Header().Set("Location", "/v2/infra/attestations/blobs/uploads/1?state=x")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/infra/attestations/blobs/uploads/1":
+			if r.URL.Query().Get("state") != "x" {
+				t.Errorf("upload location query was not preserved")
+			}
+			blobs[r.URL.Query().Get("digest")] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/infra/attestations/manifests/sha256-abc.att":
+			manifest = body
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ref, err := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/infra/attestations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := []byte(`{"payloadType":"application/vnd.in-toto+json"}`)
+	subject := ResourceDescriptor{Name: "plan", Digest: map[string]string{"sha256": "abc"}}
+	digest, err := PushOCI(context.Background(), server.Client(), ref, nil, envelope, subject)
+	if err != nil {
+		t.Fatalf("failed to push: %s", err)
+	}
+
+	if got := blobs[ociDigest(envelope)]; string(got) != string(envelope) {
+		t.Fatalf("envelope was not uploaded")
+	}
+	if digest != ociDigest(manifest) {
+		t.Fatalf("wrong manifest digest %q", digest)
+	}
+	if !strings.Contains(string(manifest), `"artifactType":"application/vnd.dsse.envelope.v1+json"`) {
+		t.Fatalf("wrong manifest\n%s", manifest)
+	}
+}
+
+func TestParseOCIReference(t *testing.T) {
+	tests := map[string]struct {
+		want    OCIReference
+		wantErr string
+	}{
+		"oci://ghcr.io/example/attestations:v1": {
+			want: OCIReference{Host: "ghcr.io", Repository: "example/attestations", Tag: "v1"},
+		},
+		"oci://localhost:5000/attestations": {
+			want: OCIReference{Host: "localhost:5000", Repository: "attestations"},
+		},
+		"oci://ghcr.io": {
+			wantErr: "must include both a registry hostname and a repository name",
+		},
+		"ghcr.io/example": {
+			wantErr: `must start with "oci://"`,
+		},
+	}
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := ParseOCIReference(input)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error %v; want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Fatalf("wrong result %#v; want %#v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+)
+
+const (
+	// OCIScheme is the URL scheme that selects pushing attestations to an
+	// OCI registry, as in "oci://registry.example.com/attestations:v1".
+	OCIScheme = "oci://"
+
+	envelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+)
+
+// OCIReference is the location of an attestation in an OCI registry.
+type OCIReference struct {
+	Host       string
+	Repository string
+	Tag        string
+}
+
+// ParseOCIReference parses a reference of the form
+// "oci://HOST/REPOSITORY[:TAG]".
+func ParseOCIReference(raw string) (OCIReference, error) {
+	var ret OCIReference
+	rest, ok := strings.CutPrefix(raw, OCIScheme)
+	if !ok {
+		return ret, fmt.Errorf("OCI references must start with %q", OCIScheme)
+	}
+	host, repo, ok := strings.Cut(rest, "/")
+	if !ok || host == "" || repo == "" {
+		return ret, fmt.Errorf("%q must include both a registry hostname and a repository name", raw)
+	}
+	if idx := strings.LastIndex(repo, ":"); idx >= 0 {
+		repo, ret.Tag = repo[:idx], repo[idx+1:]
+		if ret.Tag == "" {
+			return ret, fmt.Errorf("%q has an empty tag", raw)
+		}
+	}
+	ret.Host = host
+	ret.Repository = repo
+	return ret, nil
+}
+
+func (r OCIReference) String() string {
+	ret := OCIScheme + r.Host + "/" + r.Repository
+	if r.Tag != "" {
+		ret += ":" + r.Tag
+	}
+	return ret
+}
+
+// PushOCI uploads the given attestation envelope to an OCI registry as an
+// artifact with a single layer, returning the digest of its manifest.
+//
+// If the reference has no tag, the artifact is tagged after the digest of
+// the envelope's first subject, similar to the tags other attestation tools
+// use. If creds is not nil, it's used to authenticate to the registry;
+// otherwise PushOCI requests anonymous access tokens when the registry
+// requires them.
+func PushOCI(ctx context.Context, client *http.Client, ref OCIReference, creds svcauth.HostCredentials, envelope []byte, subject ResourceDescriptor) (string, error) {
+	if ref.Tag == "" {
+		if digest := subject.Digest["sha256"]; digest != "" {
+			ref.Tag = "sha256-" + digest + ".att"
+		} else {
+			return "", fmt.Errorf("%s must include a tag", ref)
+		}
+	}
+
+	r := &ociRegistry{
+		client: client,
+		creds:  creds,
+		base:   registryBaseURL(ref.Host),
+		repo:   ref.Repository,
+	}
+	empty := []byte("{}")
+	if err := r.pushBlob(ctx, empty); err != nil {
+		return "", err
+	}
+	if err := r.pushBlob(ctx, envelope); err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     manifestMediaType,
+		"artifactType":  envelopeMediaType,
+		"config":        ociDescriptor(emptyMediaType, empty),
+		"layers":        []any{ociDescriptor(envelopeMediaType, envelope)},
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.do(ctx, http.MethodPut, r.base+"/v2/"+r.repo+"/manifests/"+url.PathEscape(ref.Tag), manifestMediaType, manifest)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("registry rejected the attestation manifest: %s", resp.Status)
+	}
+	return ociDigest(manifest), nil
+}
+
+type ociRegistry struct {
+	client *http.Client
+	creds  svcauth.HostCredentials
+	base   string
+	repo   string
+	token  string
+}
+
+func (r *ociRegistry) pushBlob(ctx context.Context, content []byte) error {
+	resp, err := r.do(ctx, http.MethodPost, r.base+"/v2/"+r.repo+"/blobs/uploads/", "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("registry refused to start a blob upload: %s", resp.Status)
+	}
+	loc, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("registry returned an invalid upload location: %w", err)
+	}
+	query := loc.Query()
+	query.Set("digest", ociDigest(content))
+	loc.RawQuery = query.Encode()
+
+	resp, err = r.do(ctx, http.MethodPut, loc.String(), "application/octet-stream", content)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("registry rejected a blob upload: %s", resp.Status)
+	}
+	return nil
+}
+
+// do makes a request to the registry, requesting an access token and
+// retrying once if the registry requires one.
+func (r *ociRegistry) do(ctx context.Context, method, url, contentType string, body []byte) (*http.Response, error) {
+	resp, err := r.send(ctx, method, url, contentType, body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || r.token != "" {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if err := r.authenticate(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return r.send(ctx, method, url, contentType, body)
+}
+
+func (r *ociRegistry) send(ctx context.Context, method, url, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case r.token != "":
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	case r.creds != nil:
+		r.creds.PrepareRequest(req)
+	}
+	return r.client.Do(req)
+}
+
+// authenticate requests an access token as described by the given bearer
+// challenge, using the distribution token authentication protocol.
+func (r *ociRegistry) authenticate(ctx context.Context, challenge string) error {
+	params, ok := parseBearerChallenge(challenge)
+	if !ok || params["realm"] == "" {
+		return fmt.Errorf("registry requires authentication, but did not say how to obtain an access token")
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return fmt.Errorf("registry returned an invalid token service URL: %w", err)
+	}
+	query := u.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", "repository:"+r.repo+":pull,push")
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	if r.creds != nil {
+		r.creds.PrepareRequest(req)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry token service refused access: %s", resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return fmt.Errorf("registry token service returned an invalid response: %w", err)
+	}
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	if r.token == "" {
+		return fmt.Errorf("registry token service returned no token")
+	}
+	return nil
+}
+
+func parseBearerChallenge(challenge string) (map[string]string, bool) {
+	rest, ok := strings.CutPrefix(challenge, "Bearer ")
+	if !ok {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[k] = strings.Trim(v, `"`)
+		}
+	}
+	return params, true
+}
+
+// registryBaseURL returns the base URL for the registry API at the given
+// host. Registries on the loopback interface are accessed over plain HTTP,
+// as is conventional for local development registries.
+func registryBaseURL(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if ip := net.ParseIP(hostname); hostname == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+func ociDescriptor(mediaType string, content []byte) map[string]any {
+	return map[string]any{
+		"mediaType": mediaType,
+		"digest":    ociDigest(content),
+		"size":      len(content),
+	}
+}
+
+func ociDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
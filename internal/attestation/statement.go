@@ -0,0 +1,143 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package attestation produces in-toto attestations with SLSA provenance
+// predicates describing OpenTofu plan and apply runs, so that infrastructure
+// changes can take part in supply-chain security frameworks.
+package attestation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	// StatementType is the in-toto statement type of the statements
+	// produced by this package.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// PredicateType is the type of the SLSA provenance predicate included in
+	// each statement.
+	PredicateType = "https://slsa.dev/provenance/v1"
+
+	// BuildTypePrefix is the prefix of the build type recorded in the
+	// provenance, which is followed by the name of the OpenTofu command.
+	BuildTypePrefix = "https://opentofu.org/attestation/"
+
+	// BuilderID is the prefix of the builder ID recorded in the provenance,
+	// which is followed by the OpenTofu version.
+	BuilderID = "https://opentofu.org/tofu@"
+)
+
+// Statement is an in-toto statement.
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     Provenance           `json:"predicate"`
+}
+
+// ResourceDescriptor describes an artifact that a statement refers to, using
+// the in-toto resource descriptor format.
+type ResourceDescriptor struct {
+	Name        string            `json:"name,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]any    `json:"annotations,omitempty"`
+}
+
+// Provenance is a SLSA provenance predicate.
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type RunDetails struct {
+	Builder    Builder              `json:"builder"`
+	Metadata   RunMetadata          `json:"metadata"`
+	Byproducts []ResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+type RunMetadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// Run describes an OpenTofu run to attest to.
+type Run struct {
+	// Command is the name of the OpenTofu command, such as "plan".
+	Command string
+
+	// Version is the OpenTofu version that performed the run.
+	Version string
+
+	// Parameters are the settings the run was started with that affect its
+	// result, such as the workspace name.
+	Parameters map[string]any
+
+	// Subjects are the artifacts the run produced, such as a saved plan.
+	Subjects []ResourceDescriptor
+
+	// Dependencies are the inputs of the run, such as the configuration and
+	// the provider packages.
+	Dependencies []ResourceDescriptor
+
+	// Byproducts are additional results of the run, such as whether it
+	// succeeded.
+	Byproducts []ResourceDescriptor
+
+	StartedOn, FinishedOn time.Time
+}
+
+// Statement returns an in-toto statement with a SLSA provenance predicate
+// describing the run.
+func (r *Run) Statement() *Statement {
+	params := r.Parameters
+	if params == nil {
+		params = map[string]any{}
+	}
+	return &Statement{
+		Type:          StatementType,
+		Subject:       r.Subjects,
+		PredicateType: PredicateType,
+		Predicate: Provenance{
+			BuildDefinition: BuildDefinition{
+				BuildType:            BuildTypePrefix + r.Command + "/v1",
+				ExternalParameters:   params,
+				ResolvedDependencies: r.Dependencies,
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{
+					ID: BuilderID + r.Version,
+				},
+				Metadata: RunMetadata{
+					StartedOn:  r.StartedOn.UTC(),
+					FinishedOn: r.FinishedOn.UTC(),
+				},
+				Byproducts: r.Byproducts,
+			},
+		},
+	}
+}
+
+// SHA256Digest returns an in-toto digest set containing the SHA256 digest
+// of the given content.
+func SHA256Digest(content []byte) map[string]string {
+	sum := sha256.Sum256(content)
+	return map[string]string{
+		"sha256": hex.EncodeToString(sum[:]),
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/states"
@@ -269,6 +270,15 @@ type Operation struct {
 	// this operation.
 	DependencyLocks *depsfile.Locks
 
+	// ProviderPackages describes the provider packages that OpenTofu uses
+	// for the configuration directory given in ConfigDir. It's only set when
+	// saving a plan to PlanOutPath, which records it, or when applying
+	// PlanFile, whose recorded packages must then be the same unless
+	// AllowProviderMismatch is set, in which case the differences are only
+	// reported as warnings.
+	ProviderPackages      planfile.ProviderPackages
+	AllowProviderMismatch bool
+
 	// Hooks can be used to perform actions triggered by various events during
 	// the operation's lifecycle.
 	Hooks []tofu.Hook
@@ -283,11 +293,22 @@ type Operation struct {
 	AutoApprove  bool
 	Targets      []addrs.Targetable
 	Excludes     []addrs.Targetable
+	TargetGroups []string
+	TargetStrict bool
 	ForceReplace []addrs.AbsResourceInstance
 	// Injected by the command creating the operation (plan/apply/refresh/etc...)
 	Variables map[string]UnparsedVariableValue
 	RootCall  configs.StaticModuleCall
 
+	// StrictVariables requests that values for undeclared variables found in
+	// variables files are reported as errors instead of warnings.
+	StrictVariables bool
+
+	// RetryFailed is the number of times that an apply operation plans and
+	// applies the resource instances whose changes failed to apply again,
+	// once all of the other changes are applied.
+	RetryFailed int
+
 	// Some operations use root module variables only opportunistically or
 	// don't need them at all. If this flag is set, the backend must treat
 	// all variables as optional and provide an unknown value for any required
@@ -312,6 +333,13 @@ type Operation struct {
 	// implementation of clistate.Locker.
 	StateLocker clistate.Locker
 
+	// MergeConcurrentStateWrites asks state managers that implement
+	// statemgr.ConcurrentWriteMerger to merge changes written by other
+	// operations while this one was running, rather than overwriting them.
+	// This is only set when the user opts in for an operation that doesn't
+	// lock the state.
+	MergeConcurrentStateWrites bool
+
 	// Workspace is the name of the workspace that this operation should run
 	// in, which controls which named state is used.
 	Workspace string
@@ -320,6 +348,33 @@ type Operation struct {
 	// for unmatched import targets and where any generated config should be
 	// written to.
 	GenerateConfigOut string
+
+	// ModuleCachePath, if set, is the file where a plan reads the module
+	// fingerprints recorded by the previous plan and records its own, which
+	// allows it to reuse the previous results for unchanged modules.
+	ModuleCachePath string
+
+	// Incremental, if set, makes a plan skip both refreshing and planning
+	// the resources in module subtrees that haven't changed since the
+	// previous incremental plan, using the module fingerprints recorded in
+	// the state.
+	Incremental bool
+
+	// ReviewDrift, if set, makes a plan ask the user what to do about each
+	// resource instance that was deleted outside of OpenTofu: create it
+	// again, forget it, or fail.
+	ReviewDrift bool
+
+	// PlanAnalyzers are passed each plan that the operation creates before
+	// it is rendered. Any errors they return prevent the plan from being
+	// applied.
+	PlanAnalyzers []plananalyzer.Analyzer
+
+	// StateVersion, if set, selects an earlier version of the state to plan
+	// against instead of the latest one, for state managers that implement
+	// statemgr.VersionReader. It's either a storage-specific version ID or
+	// a timestamp in RFC 3339 format.
+	StateVersion string
 }
 
 // HasConfig returns true if and only if the operation has a ConfigDir value
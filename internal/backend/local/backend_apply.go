@@ -11,13 +11,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
@@ -79,6 +84,10 @@ func (b *Local) opApply(
 
 	stateHook := new(StateHook)
 	op.Hooks = append(op.Hooks, stateHook)
+	retry := new(retryHook)
+	if op.RetryFailed > 0 {
+		op.Hooks = append(op.Hooks, retry)
+	}
 
 	// Get our context
 	lr, _, opState, contextDiags := b.localRun(ctx, op)
@@ -102,11 +111,17 @@ func (b *Local) opApply(
 	// operation.
 	runningOp.State = lr.InputState
 
-	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
-	diags = diags.Append(moreDiags)
-	if moreDiags.HasErrors() {
-		op.ReportResult(runningOp, diags)
-		return
+	// A saved plan that OpenTofu Core will apply without walking the graph
+	// doesn't need any provider schemas, so we avoid launching the providers
+	// just to fetch them. The state managers can persist a state without them.
+	var schemas *tofu.Schemas
+	if lr.Plan == nil || !lr.Plan.CanSkipApply() {
+		schemas, moreDiags = lr.Core.Schemas(lr.Config, lr.InputState)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
 	}
 	// stateHook uses schemas for when it periodically persists state to the
 	// persistent storage backend.
@@ -140,10 +155,27 @@ func (b *Local) opApply(
 			return
 		}
 
+		// Pass the plan through any configured analyzers before rendering
+		// it, so that their findings are shown before we ask for approval.
+		analyzerDiags := plananalyzer.AnalyzePlan(stopCtx, op.PlanAnalyzers, &plananalyzer.Request{
+			Config:  lr.Config,
+			Plan:    plan,
+			Schemas: schemas,
+		})
+		if analyzerDiags.HasErrors() {
+			diags = diags.Append(analyzerDiags)
+			op.View.Plan(plan, schemas)
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		trivialPlan := !plan.CanApply()
 		hasUI := op.UIOut != nil && op.UIIn != nil
 		mustConfirm := hasUI && !op.AutoApprove && !trivialPlan
 		op.View.Plan(plan, schemas)
+		if len(analyzerDiags) != 0 {
+			op.View.Diagnostics(analyzerDiags)
+		}
 
 		if testHookStopPlanApply != nil {
 			testHookStopPlanApply()
@@ -258,6 +290,10 @@ func (b *Local) opApply(
 		}
 	}
 
+	// Applying can change the plan, so we check beforehand whether it has
+	// any changes to apply.
+	applyable := plan.CanApply()
+
 	// Set up our hook for continuous state updates
 	stateHook.StateMgr = opState
 
@@ -271,6 +307,9 @@ func (b *Local) opApply(
 		defer close(doneCh)
 		log.Printf("[INFO] backend/local: apply calling Apply")
 		applyState, applyDiags = lr.Core.Apply(ctx, plan, lr.Config)
+		if op.RetryFailed > 0 {
+			applyState, applyDiags = b.retryFailedApply(ctx, stopCtx, op, lr, plan, retry, applyState, applyDiags)
+		}
 	}()
 
 	if b.opWait(doneCh, stopCtx, cancelCtx, lr.Core, opState, op.View) {
@@ -278,6 +317,10 @@ func (b *Local) opApply(
 	}
 	diags = diags.Append(applyDiags)
 
+	if report := lr.Core.ApplyParallelism(); len(report) != 0 {
+		op.View.ParallelismReport(report)
+	}
+
 	// Even on error with an empty state, the state value should not be nil.
 	// Return early here to prevent corrupting any existing state.
 	if diags.HasErrors() && applyState == nil {
@@ -286,6 +329,12 @@ func (b *Local) opApply(
 		return
 	}
 
+	// Record when changes were last applied to the workspace, if it has
+	// metadata to record it in.
+	if applyState.Workspace != nil && applyable {
+		applyState.Workspace.LastAppliedAt = time.Now().UTC()
+	}
+
 	// Store the final state
 	runningOp.State = applyState
 	err := statemgr.WriteAndPersist(opState, applyState, schemas)
@@ -314,6 +363,104 @@ func (b *Local) opApply(
 	op.View.Diagnostics(diags)
 }
 
+// retryFailedApply plans and applies the changes of the resource instances
+// that failed to apply again, up to op.RetryFailed times, until they all
+// succeed. It returns the resulting state and the diagnostics of the whole
+// operation.
+//
+// The errors of a round that's retried are replaced by those of the retry,
+// and only the first round's warnings are kept, because the targeted plans
+// for the retries repeat them and add warnings about targeting that don't
+// apply to the operation as a whole.
+func (b *Local) retryFailedApply(ctx, stopCtx context.Context, op *backend.Operation, lr *backend.LocalRun, plan *plans.Plan, hook *retryHook, state *states.State, diags tfdiags.Diagnostics) (*states.State, tfdiags.Diagnostics) {
+	var warnings tfdiags.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity() == tfdiags.Warning {
+			warnings = warnings.Append(diag)
+		}
+	}
+
+	var succeeded []string
+	failed := hook.takeFailed()
+	for attempt := 1; attempt <= op.RetryFailed; attempt++ {
+		if !diags.HasErrors() || len(failed) == 0 || state == nil || stopCtx.Err() != nil {
+			break
+		}
+		log.Printf("[INFO] backend/local: retrying %d failed resource instances (attempt %d of %d)", len(failed), attempt, op.RetryFailed)
+
+		planOpts, moreDiags := retryPlanOpts(plan, failed)
+		if moreDiags.HasErrors() {
+			return state, diags.Append(moreDiags)
+		}
+		retryPlan, moreDiags := lr.Core.Plan(ctx, lr.Config, state, planOpts)
+		if moreDiags.HasErrors() {
+			return state, diags.Append(moreDiags)
+		}
+		newState, moreDiags := lr.Core.Apply(ctx, retryPlan, lr.Config)
+		if newState == nil {
+			return state, diags.Append(moreDiags)
+		}
+		state = newState
+		diags = warnings
+		for _, diag := range moreDiags {
+			if diag.Severity() == tfdiags.Error {
+				diags = diags.Append(diag)
+			}
+		}
+
+		stillFailed := hook.takeFailed()
+		for _, addr := range failed {
+			if !slices.ContainsFunc(stillFailed, addr.Equal) {
+				succeeded = append(succeeded, fmt.Sprintf("\n  - %s (retry %d)", addr, attempt))
+			}
+		}
+		failed = stillFailed
+	}
+
+	if len(succeeded) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Resources applied after retrying",
+			fmt.Sprintf("The changes of the following resource instances failed to apply at first, but succeeded when OpenTofu retried them:%s\n\nIf these failures happen regularly, consider declaring the dependencies that they might be waiting for.", strings.Join(succeeded, "")),
+		))
+	}
+	return state, diags
+}
+
+// retryPlanOpts returns the options for a plan that retries the changes of
+// the given resource instances from the given plan, which failed to apply.
+func retryPlanOpts(plan *plans.Plan, failed []addrs.AbsResourceInstance) (*tofu.PlanOpts, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	variables := make(tofu.InputValues, len(plan.VariableValues))
+	for name, dyVal := range plan.VariableValues {
+		val, err := dyVal.Decode(cty.DynamicPseudoType)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid variable value in plan",
+				fmt.Sprintf("Invalid value for variable %q recorded in plan file: %s.", name, err),
+			))
+			continue
+		}
+		variables[name] = &tofu.InputValue{
+			Value:      val,
+			SourceType: tofu.ValueFromPlan,
+		}
+	}
+
+	targets := make([]addrs.Targetable, len(failed))
+	for i, addr := range failed {
+		targets[i] = addr
+	}
+
+	return &tofu.PlanOpts{
+		Mode:         plan.UIMode,
+		SetVariables: variables,
+		Targets:      targets,
+	}, diags
+}
+
 // backupStateForError is called in a scenario where we're unable to persist the
 // state for some reason, and will attempt to save a backup copy of the state
 // to local disk to help the user recover. This is a "last ditch effort" sort
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/zclconf/go-cty/cty"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/terminal"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -77,6 +79,58 @@ test_instance.foo:
 		t.Fatalf("unexpected error output:\n%s", errOutput)
 	}
 }
+func TestLocal_applyWorkspaceMetadata(t *testing.T) {
+	b := TestLocal(t)
+
+	p := TestLocalProvider(t, b, "test", applyFixtureSchema())
+	p.ApplyResourceChangeResponse = &providers.ApplyResourceChangeResponse{NewState: cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("yes"),
+		"ami": cty.StringVal("bar"),
+	})}
+
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	state := states.NewState()
+	state.Workspace = &states.WorkspaceMetadata{
+		Description: "Production",
+		Tags:        []string{"prod"},
+		CreatedAt:   createdAt,
+	}
+	testStateFile(t, b.StatePath, state)
+
+	op, configCleanup, done := testOperationApply(t, "./testdata/apply")
+	defer configCleanup()
+
+	start := time.Now()
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("operation failed\n%s", done(t).Stderr())
+	}
+
+	f, err := os.Open(b.StateOutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sf, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sf.State.Workspace
+	if got == nil {
+		t.Fatal("workspace metadata was lost")
+	}
+	if got.Description != "Production" || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("wrong workspace metadata: %#v", got)
+	}
+	if got.LastAppliedAt.Before(start.Truncate(time.Second)) {
+		t.Errorf("last applied time %s was not updated", got.LastAppliedAt)
+	}
+}
+
 func TestLocal_applyCheck(t *testing.T) {
 	b := TestLocal(t)
 
@@ -256,6 +310,85 @@ test_instance.foo:
 	}
 }
 
+func TestLocal_applyRetryFailed(t *testing.T) {
+	b := TestLocal(t)
+
+	schema := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"ami": {Type: cty.String, Optional: true},
+						"id":  {Type: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+	}
+	p := TestLocalProvider(t, b, "test", schema)
+
+	var lock sync.Mutex
+	errored := false
+	p.ApplyResourceChangeFn = func(
+		r providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+
+		lock.Lock()
+		defer lock.Unlock()
+		var diags tfdiags.Diagnostics
+
+		ami := r.Config.GetAttr("ami").AsString()
+		if !errored && ami == "error" {
+			errored = true
+			diags = diags.Append(errors.New("ami error"))
+			return providers.ApplyResourceChangeResponse{
+				Diagnostics: diags,
+			}
+		}
+		return providers.ApplyResourceChangeResponse{
+			Diagnostics: diags,
+			NewState: cty.ObjectVal(map[string]cty.Value{
+				"id":  cty.StringVal("foo"),
+				"ami": cty.StringVal(ami),
+			}),
+		}
+	}
+
+	op, configCleanup, done := testOperationApply(t, "./testdata/apply-error")
+	defer configCleanup()
+	op.RetryFailed = 1
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	output := done(t)
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("operation failed\n%s", output.Stderr())
+	}
+
+	checkState(t, b.StateOutPath, `
+test_instance.bar:
+  ID = foo
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+  ami = error
+test_instance.foo:
+  ID = foo
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+  ami = bar
+	`)
+
+	// the backend should be unlocked after a run
+	assertBackendStateUnlocked(t, b)
+
+	if got, want := output.Stdout(), "test_instance.bar (retry 1)"; !strings.Contains(got, want) {
+		t.Fatalf("missing retry warning:\n%s\nwant: %s", got, want)
+	}
+	if got := output.Stderr(); got != "" {
+		t.Fatalf("unexpected error output:\n%s", got)
+	}
+}
+
 func TestLocal_applyBackendFail(t *testing.T) {
 	b := TestLocal(t)
 
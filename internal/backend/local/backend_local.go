@@ -7,6 +7,7 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
@@ -19,6 +20,8 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -51,6 +54,10 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 		diags = diags.Append(fmt.Errorf("error loading state: %w", err))
 		return nil, nil, nil, diags
 	}
+	if m, ok := s.(statemgr.ConcurrentWriteMerger); ok && op.MergeConcurrentStateWrites {
+		log.Printf("[TRACE] backend/local: enabling merging of concurrent state writes for workspace %q", op.Workspace)
+		m.EnableConcurrentWriteMerging()
+	}
 	log.Printf("[TRACE] backend/local: requesting state lock for workspace %q", op.Workspace)
 	if diags := op.StateLocker.Lock(s, op.Type.String()); diags.HasErrors() {
 		return nil, nil, nil, diags
@@ -132,7 +139,10 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 		}
 
 		// If validation is enabled, validate
-		if b.OpValidation {
+		// A saved plan was already validated when it was created, and if it
+		// can be applied without walking the graph then validating it again
+		// would only launch every provider for nothing.
+		if b.OpValidation && !(ret.Plan != nil && ret.Plan.CanSkipApply()) {
 			log.Printf("[TRACE] backend/local: running validation operation")
 			validateDiags := ret.Core.Validate(ctx, ret.Config)
 			diags = diags.Append(validateDiags)
@@ -142,6 +152,47 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 	return ret, configSnap, s, diags
 }
 
+// stateVersion returns the earlier snapshot of the state selected by the
+// given selector, for state managers that implement statemgr.VersionReader.
+func stateVersion(s statemgr.Full, selector string) (*states.State, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	sel := statemgr.ParseStateVersionSelector(selector)
+	var f *statefile.File
+	var err error
+	if vr, ok := s.(statemgr.VersionReader); ok {
+		f, err = vr.StateVersion(sel)
+	} else {
+		err = statemgr.ErrStateVersionsNotSupported
+	}
+	switch {
+	case errors.Is(err, statemgr.ErrStateVersionsNotSupported):
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"State versions not supported",
+			"The state storage for this workspace doesn't keep earlier versions of the state, so OpenTofu can't plan against one. Remove the -state-version option to plan against the latest state.",
+		))
+		return nil, diags
+	case errors.Is(err, statemgr.ErrStateVersionNotFound):
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"State version not found",
+			fmt.Sprintf("The state storage for this workspace has no version of the state matching %q.", sel),
+		))
+		return nil, diags
+	case err != nil:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read state version",
+			fmt.Sprintf("OpenTofu could not read the version of the state matching %q: %s.", sel, err),
+		))
+		return nil, diags
+	}
+
+	log.Printf("[INFO] backend/local: using the state version matching %q, with serial %d", sel, f.Serial)
+	return f.State, diags
+}
+
 func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, coreOpts *tofu.ContextOpts, s statemgr.Full) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -194,7 +245,7 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 		rawVariables = b.interactiveCollectVariables(context.TODO(), op.Variables, config.Module.Variables, op.UIIn)
 	}
 
-	variables, varDiags := backend.ParseVariableValues(rawVariables, config.Module.Variables)
+	variables, varDiags := backend.ParseVariableValues(rawVariables, config.Module.Variables, op.StrictVariables)
 	diags = diags.Append(varDiags)
 	if diags.HasErrors() {
 		return nil, nil, diags
@@ -204,6 +255,8 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 		Mode:               op.PlanMode,
 		Targets:            op.Targets,
 		Excludes:           op.Excludes,
+		TargetGroups:       op.TargetGroups,
+		TargetStrict:       op.TargetStrict,
 		ForceReplace:       op.ForceReplace,
 		SetVariables:       variables,
 		SkipRefresh:        op.Type != backend.OperationTypeRefresh && !op.PlanRefresh,
@@ -212,8 +265,17 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 	run.PlanOpts = planOpts
 
 	// For a "direct" local run, the input state is the most recently stored
-	// snapshot, from the previous run.
+	// snapshot, from the previous run, unless the operation asks for an
+	// earlier one.
 	state := s.State()
+	if op.StateVersion != "" {
+		var versionDiags tfdiags.Diagnostics
+		state, versionDiags = stateVersion(s, op.StateVersion)
+		diags = diags.Append(versionDiags)
+		if versionDiags.HasErrors() {
+			return nil, nil, diags
+		}
+	}
 	if state != nil {
 		migratedState, migrateDiags := tofumigrate.MigrateStateProviderAddresses(config, state)
 		diags = diags.Append(migrateDiags)
@@ -339,6 +401,12 @@ func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader,
 		))
 	}
 
+	// Even with the same locked dependencies, the provider packages can
+	// differ, for example if the plan was created on a different platform
+	// or with a development override, and so we also check that we're about
+	// to use the same packages that the plan was created with.
+	diags = diags.Append(checkPlanProviderPackages(pf, op))
+
 	// A plan file also contains a snapshot of the prior state the changes
 	// are intended to apply to.
 	priorStateFile, err := pf.ReadStateFile()
@@ -547,3 +615,48 @@ func (v unparsedUnknownVariableValue) ParseVariableValue(mode configs.VariablePa
 		SourceType: tofu.ValueFromInput,
 	}, nil
 }
+
+// checkPlanProviderPackages checks that the provider packages recorded in the
+// given plan file are the same as the ones in the given operation, which are
+// the ones that OpenTofu is about to use to apply it.
+func checkPlanProviderPackages(pf *planfile.Reader, op *backend.Operation) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	planned, err := pf.ReadProviderPackages()
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid plan file",
+			fmt.Sprintf("Failed to read the provider packages from the plan file: %s.", err),
+		))
+		return diags
+	}
+	if planned == nil || op.ProviderPackages == nil {
+		// Plan files created by earlier versions of OpenTofu don't record
+		// the provider packages, so there's nothing to compare.
+		return diags
+	}
+
+	differences := planned.Differences(op.ProviderPackages)
+	if len(differences) == 0 {
+		return diags
+	}
+	var buf strings.Builder
+	for _, difference := range differences {
+		fmt.Fprintf(&buf, "\n  - %s", difference)
+	}
+	if op.AllowProviderMismatch {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Provider packages differ from the saved plan",
+			fmt.Sprintf("The following provider packages are different from the ones the plan was created with:%s\n\nOpenTofu applies the plan anyway because of the -allow-provider-mismatch option, but the providers may not behave as the plan described.", buf.String()),
+		))
+		return diags
+	}
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Provider packages differ from the saved plan",
+		fmt.Sprintf("The following provider packages are different from the ones the plan was created with:%s\n\nA saved plan can be applied only with the same provider packages it was created with, because other packages may not behave as the plan described. Create a new plan in this environment, or use the -allow-provider-mismatch option to apply the plan anyway.", buf.String()),
+	))
+	return diags
+}
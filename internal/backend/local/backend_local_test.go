@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/clistate"
@@ -21,6 +23,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/initwd"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
@@ -211,6 +214,173 @@ func TestLocalRun_stalePlan(t *testing.T) {
 	assertBackendStateUnlocked(t, b)
 }
 
+func TestLocalRun_stateVersion(t *testing.T) {
+	configDir := "./testdata/empty"
+
+	_, configLoader, configCleanup := initwd.MustLoadConfigForTests(t, configDir, "tests")
+	defer configCleanup()
+
+	earlier := states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(addrs.OutputValue{Name: "foo"}.Absolute(addrs.RootModuleInstance), cty.StringVal("earlier"), false)
+	})
+
+	testCases := map[string]struct {
+		backend backend.Backend
+		version string
+		wantErr string
+	}{
+		"found": {
+			backend: backendWithStateVersions{versions: map[string]*states.State{"1": earlier}},
+			version: "1",
+		},
+		"not found": {
+			backend: backendWithStateVersions{versions: map[string]*states.State{"1": earlier}},
+			version: "2",
+			wantErr: `The state storage for this workspace has no version of the state matching "2".`,
+		},
+		"not supported": {
+			backend: nil,
+			version: "1",
+			wantErr: "The state storage for this workspace doesn't keep earlier versions of the state",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			b := TestLocal(t)
+			if tc.backend != nil {
+				b.Backend = tc.backend
+			}
+
+			streams, _ := terminal.StreamsForTesting(t)
+			view := views.NewView(streams)
+			stateLocker := clistate.NewLocker(0, views.NewStateLocker(arguments.ViewHuman, view))
+
+			op := &backend.Operation{
+				ConfigDir:    configDir,
+				ConfigLoader: configLoader,
+				Workspace:    backend.DefaultStateName,
+				StateLocker:  stateLocker,
+				StateVersion: tc.version,
+			}
+
+			lr, _, diags := b.LocalRun(context.Background(), op)
+			if tc.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatal("unexpected success")
+				}
+				if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected error: %s", diags.Err())
+			}
+			if got := lr.InputState.RootModule().OutputValues["foo"]; got == nil || got.Value != cty.StringVal("earlier") {
+				t.Fatalf("input state is not the earlier version: %#v", got)
+			}
+		})
+	}
+}
+
+func TestCheckPlanProviderPackages(t *testing.T) {
+	provider := addrs.NewDefaultProvider("test")
+	planned := planfile.ProviderPackages{
+		provider: {
+			Version: getproviders.MustParseVersion("1.0.0"),
+			Hash:    getproviders.MustParseHash("h1:planned"),
+		},
+	}
+	installed := planfile.ProviderPackages{
+		provider: {
+			Version: getproviders.MustParseVersion("1.0.0"),
+			Hash:    getproviders.MustParseHash("h1:installed"),
+		},
+	}
+
+	backendConfig := cty.ObjectVal(map[string]cty.Value{
+		"path":          cty.NullVal(cty.String),
+		"workspace_dir": cty.NullVal(cty.String),
+	})
+	backendConfigRaw, err := plans.NewDynamicValue(backendConfig, backendConfig.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &plans.Plan{
+		UIMode:  plans.NormalMode,
+		Changes: plans.NewChanges(),
+		Backend: plans.Backend{
+			Type:   "local",
+			Config: backendConfigRaw,
+		},
+		PrevRunState: states.NewState(),
+		PriorState:   states.NewState(),
+	}
+	planPath := filepath.Join(t.TempDir(), "plan.tfplan")
+	err = planfile.Create(planPath, planfile.CreateArgs{
+		ConfigSnapshot:       configload.NewEmptySnapshot(),
+		PreviousRunStateFile: statefile.New(plan.PrevRunState, "boop", 1),
+		StateFile:            statefile.New(plan.PriorState, "boop", 1),
+		Plan:                 plan,
+		ProviderPackages:     planned,
+	}, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("unexpected error writing planfile: %s", err)
+	}
+	wpf, err := planfile.OpenWrapped(planPath, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("unexpected error reading planfile: %s", err)
+	}
+	pf, _ := wpf.Local()
+
+	testCases := map[string]struct {
+		op          *backend.Operation
+		wantSummary string
+		wantErr     bool
+	}{
+		"same": {
+			op: &backend.Operation{ProviderPackages: planned},
+		},
+		"unknown": {
+			op: &backend.Operation{},
+		},
+		"different": {
+			op:          &backend.Operation{ProviderPackages: installed},
+			wantSummary: "Provider packages differ from the saved plan",
+			wantErr:     true,
+		},
+		"different but allowed": {
+			op:          &backend.Operation{ProviderPackages: installed, AllowProviderMismatch: true},
+			wantSummary: "Provider packages differ from the saved plan",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			diags := checkPlanProviderPackages(pf, tc.op)
+			if tc.wantSummary == "" {
+				if len(diags) != 0 {
+					t.Fatalf("unexpected diagnostics: %s", diags.ErrWithWarnings())
+				}
+				return
+			}
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+			}
+			if got := diags[0].Description().Summary; got != tc.wantSummary {
+				t.Fatalf("wrong summary %q; want %q", got, tc.wantSummary)
+			}
+			if got := diags.HasErrors(); got != tc.wantErr {
+				t.Fatalf("wrong severity: error is %t; want %t", got, tc.wantErr)
+			}
+			if got, want := diags[0].Description().Detail, "has the checksum h1:installed"; !strings.Contains(got, want) {
+				t.Fatalf("detail doesn't describe the difference:\n%s", got)
+			}
+		})
+	}
+}
+
 type backendWithStateStorageThatFailsRefresh struct {
 }
 
@@ -240,6 +410,35 @@ func (b backendWithStateStorageThatFailsRefresh) Workspaces() ([]string, error)
 	return []string{"default"}, nil
 }
 
+// backendWithStateVersions is a backend whose state storage keeps the given
+// earlier versions of the state, by ID.
+type backendWithStateVersions struct {
+	backendWithStateStorageThatFailsRefresh
+	versions map[string]*states.State
+}
+
+func (b backendWithStateVersions) StateMgr(workspace string) (statemgr.Full, error) {
+	return &stateStorageWithVersions{
+		Full:     statemgr.NewFullFake(statemgr.NewTransientInMemory(nil), nil),
+		versions: b.versions,
+	}, nil
+}
+
+type stateStorageWithVersions struct {
+	statemgr.Full
+	versions map[string]*states.State
+}
+
+var _ statemgr.VersionReader = (*stateStorageWithVersions)(nil)
+
+func (s *stateStorageWithVersions) StateVersion(sel statemgr.StateVersionSelector) (*statefile.File, error) {
+	state, ok := s.versions[sel.ID]
+	if !ok {
+		return nil, statemgr.ErrStateVersionNotFound
+	}
+	return statefile.New(state, "boop", 1), nil
+}
+
 type stateStorageThatFailsRefresh struct {
 	locked bool
 }
@@ -10,10 +10,14 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
+	"strings"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/genconfig"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/states/statefile"
@@ -108,19 +112,29 @@ func (b *Local) opPlan(
 	// resulting state is always just the input state.
 	runningOp.State = lr.InputState
 
+	switch {
+	case op.Incremental:
+		lr.PlanOpts.ModuleCache = incrementalModuleCache(lr.InputState, lr.Config, configSnap, op.DependencyLocks)
+	case op.ModuleCachePath != "":
+		lr.PlanOpts.ModuleCache = loadModuleCache(op.ModuleCachePath, lr.Config, configSnap, op.DependencyLocks)
+	}
+
 	// Perform the plan in a goroutine so we can be interrupted
 	var plan *plans.Plan
 	var planDiags tfdiags.Diagnostics
-	doneCh := make(chan struct{})
-	panicHandler := logging.PanicHandlerWithTraceFn()
-	go func() {
-		defer panicHandler()
-		defer close(doneCh)
-		log.Printf("[INFO] backend/local: plan calling Plan")
-		plan, planDiags = lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
-	}()
+	runPlan := func() (cancelled bool) {
+		doneCh := make(chan struct{})
+		panicHandler := logging.PanicHandlerWithTraceFn()
+		go func() {
+			defer panicHandler()
+			defer close(doneCh)
+			log.Printf("[INFO] backend/local: plan calling Plan")
+			plan, planDiags = lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
+		}()
+		return b.opWait(doneCh, stopCtx, cancelCtx, lr.Core, opState, op.View)
+	}
 
-	if b.opWait(doneCh, stopCtx, cancelCtx, lr.Core, opState, op.View) {
+	if runPlan() {
 		// If we get in here then the operation was cancelled, which is always
 		// considered to be a failure.
 		log.Printf("[INFO] backend/local: plan operation was force-cancelled by interrupt")
@@ -129,11 +143,53 @@ func (b *Local) opPlan(
 	}
 	log.Printf("[INFO] backend/local: plan operation completed")
 
+	if op.ReviewDrift && plan != nil && !planDiags.HasErrors() {
+		forget, reviewDiags := reviewDeletedResources(stopCtx, op, plan)
+		diags = diags.Append(reviewDiags)
+		if reviewDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+		if len(forget) > 0 {
+			// We plan again with the resource instances to forget removed
+			// from the input state, and excluded so that the plan doesn't
+			// create them again.
+			log.Printf("[INFO] backend/local: planning again without %d deleted resource instances", len(forget))
+			lr.InputState = lr.InputState.DeepCopy()
+			syncState := lr.InputState.SyncWrapper()
+			for _, addr := range forget {
+				syncState.ForgetResourceInstanceAll(addr)
+				syncState.RemoveResourceIfEmpty(addr.ContainingResource())
+				lr.PlanOpts.Excludes = append(lr.PlanOpts.Excludes, addr)
+			}
+			if runPlan() {
+				log.Printf("[INFO] backend/local: plan operation was force-cancelled by interrupt")
+				runningOp.Result = backend.OperationFailure
+				return
+			}
+		}
+	}
+
 	// NOTE: We intentionally don't stop here on errors because we always want
 	// to try to present a partial plan report and, if the user chose to,
 	// generate a partial saved plan file for external analysis.
 	diags = diags.Append(planDiags)
 
+	if cache := lr.PlanOpts.ModuleCache; cache != nil && op.Incremental && plan != nil && !planDiags.HasErrors() {
+		// The fingerprints are recorded in the prior state of the plan, so
+		// that applying the plan saves them in the new state snapshot.
+		plan.PriorState.ModuleFingerprints = cache.Current
+	}
+	if cache := lr.PlanOpts.ModuleCache; cache != nil && op.ModuleCachePath != "" && !planDiags.HasErrors() {
+		if err := saveModuleCache(op.ModuleCachePath, cache); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to save module cache",
+				fmt.Sprintf("OpenTofu could not save the module fingerprints for the next plan to %s: %s.", op.ModuleCachePath, err),
+			))
+		}
+	}
+
 	// Even if there are errors we need to handle anything that may be
 	// contained within the plan, so only exit if there is no data at all.
 	if plan == nil {
@@ -144,6 +200,39 @@ func (b *Local) opPlan(
 
 	// Record whether this plan includes any side-effects that could be applied.
 	runningOp.PlanEmpty = !plan.CanApply()
+	if plan.UIMode == plans.DriftOnlyMode {
+		// A drift-only plan is never applyable, so instead we report whether
+		// it detected any drift, which allows -detailed-exitcode to signal
+		// that drift.
+		runningOp.PlanEmpty = plan.Errored || plan.PriorState.ManagedResourcesEqual(plan.PrevRunState)
+	}
+
+	// We need the schemas both to analyze the plan and to render it.
+	// (This might potentially be a partial plan with Errored set to true)
+	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
+	// Pass the plan through any configured analyzers before saving and
+	// rendering it. A partial plan from an errored run isn't worth analyzing.
+	// If an analyzer rejects the plan then we still render it, so that the
+	// user can see what the analyzer objected to, but we don't save it.
+	if !plan.Errored {
+		moreDiags = plananalyzer.AnalyzePlan(stopCtx, op.PlanAnalyzers, &plananalyzer.Request{
+			Config:  lr.Config,
+			Plan:    plan,
+			Schemas: schemas,
+		})
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.View.Plan(plan, schemas)
+			op.ReportResult(runningOp, diags)
+			return
+		}
+	}
 
 	// Save the plan to disk
 	if path := op.PlanOutPath; path != "" {
@@ -182,6 +271,7 @@ func (b *Local) opPlan(
 			StateFile:            plannedStateFile,
 			Plan:                 plan,
 			DependencyLocks:      op.DependencyLocks,
+			ProviderPackages:     op.ProviderPackages,
 		}, op.Encryption.Plan())
 		if err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
@@ -194,15 +284,6 @@ func (b *Local) opPlan(
 		}
 	}
 
-	// Render the plan, if we produced one.
-	// (This might potentially be a partial plan with Errored set to true)
-	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
-	diags = diags.Append(moreDiags)
-	if moreDiags.HasErrors() {
-		op.ReportResult(runningOp, diags)
-		return
-	}
-
 	// Write out any generated config, before we render the plan.
 	wroteConfig, moreDiags := maybeWriteGeneratedConfig(plan, op.GenerateConfigOut)
 	diags = diags.Append(moreDiags)
@@ -220,7 +301,7 @@ func (b *Local) opPlan(
 	// creating it.
 	op.ReportResult(runningOp, diags)
 
-	if !runningOp.PlanEmpty {
+	if !runningOp.PlanEmpty && plan.UIMode != plans.DriftOnlyMode {
 		if wroteConfig {
 			op.View.PlanNextStep(op.PlanOutPath, op.GenerateConfigOut)
 		} else {
@@ -252,6 +333,24 @@ func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool,
 				return false, diags.Append(moreDiags)
 			}
 		}
+
+		// Import blocks with discover blocks produce import blocks for the
+		// objects the provider listed, rather than resource blocks.
+		for _, imp := range plan.DiscoveredImports {
+			change := genconfig.Change{
+				Addr:            imp.Addr.String(),
+				ImportID:        imp.ID,
+				GeneratedConfig: imp.GeneratedConfig,
+			}
+
+			var wroteImport bool
+			var moreDiags tfdiags.Diagnostics
+			writer, wroteImport, moreDiags = change.MaybeWriteConfig(writer, out)
+			if moreDiags.HasErrors() {
+				return false, diags.Append(moreDiags)
+			}
+			wroteConfig = wroteConfig || wroteImport
+		}
 	}
 
 	if wroteConfig {
@@ -263,3 +362,81 @@ func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool,
 
 	return wroteConfig, diags
 }
+
+// Choices for a resource instance that was deleted outside of OpenTofu, in
+// a plan that reviews drift.
+const (
+	driftChoiceRecreate = "recreate"
+	driftChoiceForget   = "forget"
+	driftChoiceFail     = "fail"
+)
+
+// reviewDeletedResources asks the user what to do about each resource
+// instance that the given plan creates because it was deleted outside of
+// OpenTofu, and returns the addresses of the instances to forget instead.
+//
+// If the user chooses to fail for any of the instances, the result has
+// errors.
+func reviewDeletedResources(ctx context.Context, op *backend.Operation, plan *plans.Plan) ([]addrs.AbsResourceInstance, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	var deleted []*plans.ResourceInstanceChangeSrc
+	for _, change := range plan.Changes.Resources {
+		if change.ActionReason == plans.ResourceInstanceCreateBecauseDeleted {
+			deleted = append(deleted, change)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil, diags
+	}
+	if op.UIIn == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot review drift",
+			"The -review-drift option requires interactive input, to ask what to do about each resource that was deleted outside of OpenTofu.",
+		))
+		return nil, diags
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].Addr.Less(deleted[j].Addr)
+	})
+
+	var forget []addrs.AbsResourceInstance
+	var failed []string
+	for _, change := range deleted {
+		addr := change.Addr.String()
+		v, err := op.UIIn.Input(ctx, &tofu.InputOpts{
+			Id:    "review-drift." + addr,
+			Query: fmt.Sprintf("\n%s was deleted outside of OpenTofu.", addr),
+			Description: fmt.Sprintf("Enter %q to create it again, %q to remove it from the state without\n"+
+				"creating it, or %q to stop without a plan.", driftChoiceRecreate, driftChoiceForget, driftChoiceFail),
+		})
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("error asking about %s: %w", addr, err))
+			return nil, diags
+		}
+		switch strings.TrimSpace(v) {
+		case driftChoiceRecreate:
+		case driftChoiceForget:
+			forget = append(forget, change.Addr)
+		case driftChoiceFail:
+			failed = append(failed, addr)
+		default:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid choice",
+				fmt.Sprintf("The answer for %s must be %q, %q, or %q.", addr, driftChoiceRecreate, driftChoiceForget, driftChoiceFail),
+			))
+			return nil, diags
+		}
+	}
+
+	if len(failed) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Resources were deleted outside of OpenTofu",
+			fmt.Sprintf("The following resource instances were deleted outside of OpenTofu:\n  - %s\n\nInvestigate why before planning again.", strings.Join(failed, "\n  - ")),
+		))
+	}
+	return forget, diags
+}
@@ -0,0 +1,154 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/modsdir"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// moduleCacheFormatVersion is the version of the module cache file format.
+// Files with any other version are ignored.
+const moduleCacheFormatVersion = 1
+
+// moduleCacheFile is the JSON representation of the module fingerprints
+// recorded by a plan.
+type moduleCacheFile struct {
+	Version int                         `json:"version"`
+	Modules map[string]moduleCacheEntry `json:"modules"`
+}
+
+type moduleCacheEntry struct {
+	Inputs    string            `json:"inputs"`
+	Providers map[string]string `json:"providers"`
+}
+
+// loadModuleCache prepares the module cache for a plan, using the
+// fingerprints saved at the given path by the previous plan, if any.
+//
+// A missing or unreadable cache file just means that the plan can't reuse
+// any previous results, so it's never an error.
+func loadModuleCache(path string, config *configs.Config, snap *configload.Snapshot, locks *depsfile.Locks) *tofu.ModuleCache {
+	cache := newModuleCache(config, snap, locks)
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] backend/local: failed to read module cache %s: %s", path, err)
+		}
+		return cache
+	}
+	var file moduleCacheFile
+	if err := json.Unmarshal(src, &file); err != nil || file.Version != moduleCacheFormatVersion {
+		log.Printf("[WARN] backend/local: ignoring invalid module cache %s", path)
+		return cache
+	}
+	for key, entry := range file.Modules {
+		cache.Previous[key] = tofu.ModuleFingerprint{
+			Inputs:    entry.Inputs,
+			Providers: entry.Providers,
+		}
+	}
+	return cache
+}
+
+// incrementalModuleCache prepares the module cache for an incremental plan,
+// using the fingerprints recorded in the given prior state, if any.
+//
+// Unlike with the fingerprints saved by loadModuleCache, the resources of the
+// unchanged subtrees aren't refreshed either.
+func incrementalModuleCache(state *states.State, config *configs.Config, snap *configload.Snapshot, locks *depsfile.Locks) *tofu.ModuleCache {
+	cache := newModuleCache(config, snap, locks)
+	cache.SkipRefresh = true
+	if state != nil {
+		for key, fingerprint := range state.ModuleFingerprints {
+			cache.Previous[key] = fingerprint.DeepCopy()
+		}
+	}
+	return cache
+}
+
+func newModuleCache(config *configs.Config, snap *configload.Snapshot, locks *depsfile.Locks) *tofu.ModuleCache {
+	cache := &tofu.ModuleCache{
+		Previous:         make(map[string]tofu.ModuleFingerprint),
+		SourceHashes:     moduleSourceHashes(config, snap),
+		ProviderVersions: make(map[addrs.Provider]string),
+	}
+	if locks != nil {
+		for provider, lock := range locks.AllProviders() {
+			cache.ProviderVersions[provider] = lock.Version().String()
+		}
+	}
+	return cache
+}
+
+// saveModuleCache saves the fingerprints recorded by a plan at the given
+// path, for use by the next plan.
+func saveModuleCache(path string, cache *tofu.ModuleCache) error {
+	file := moduleCacheFile{
+		Version: moduleCacheFormatVersion,
+		Modules: make(map[string]moduleCacheEntry, len(cache.Current)),
+	}
+	for key, fingerprint := range cache.Current {
+		file.Modules[key] = moduleCacheEntry{
+			Inputs:    fingerprint.Inputs,
+			Providers: fingerprint.Providers,
+		}
+	}
+	src, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, src, 0644)
+}
+
+// moduleSourceHashes returns a hash of the source files of each module in the
+// given configuration, by module address.
+func moduleSourceHashes(config *configs.Config, snap *configload.Snapshot) map[string]string {
+	ret := make(map[string]string)
+	if snap == nil {
+		return ret
+	}
+
+	var manifest modsdir.Manifest
+	config.DeepEach(func(c *configs.Config) {
+		mod, ok := snap.Modules[manifest.ModuleKey(c.Path)]
+		if !ok {
+			return
+		}
+		names := make([]string, 0, len(mod.Files))
+		for name := range mod.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		h := sha256.New()
+		for _, name := range names {
+			fmt.Fprintf(h, "%s %d\n", name, len(mod.Files[name]))
+			h.Write(mod.Files[name])
+		}
+		ret[c.Path.String()] = hex.EncodeToString(h.Sum(nil))
+	})
+	return ret
+}
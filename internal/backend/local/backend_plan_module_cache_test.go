@@ -0,0 +1,76 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestModuleCache_roundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "module-cache", "default.json")
+
+	// A missing cache file just means there are no previous results.
+	cache := loadModuleCache(path, nil, nil, nil)
+	if len(cache.Previous) != 0 {
+		t.Fatalf("unexpected previous fingerprints: %#v", cache.Previous)
+	}
+
+	cache.Current = map[string]tofu.ModuleFingerprint{
+		"module.child": {
+			Inputs: "abc123",
+			Providers: map[string]string{
+				`provider["registry.opentofu.org/hashicorp/test"]`: "def456",
+			},
+		},
+	}
+	if err := saveModuleCache(path, cache); err != nil {
+		t.Fatal(err)
+	}
+
+	got := loadModuleCache(path, nil, nil, nil)
+	if diff := cmp.Diff(cache.Current, got.Previous); diff != "" {
+		t.Fatalf("wrong fingerprints\n%s", diff)
+	}
+}
+
+func TestModuleCache_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "default.json")
+	if err := os.WriteFile(path, []byte(`{"version": 0, "modules": {"module.child": {"inputs": "abc123"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := loadModuleCache(path, nil, nil, nil)
+	if len(cache.Previous) != 0 {
+		t.Fatalf("unexpected previous fingerprints: %#v", cache.Previous)
+	}
+}
+
+func TestModuleCache_incremental(t *testing.T) {
+	state := states.NewState()
+	state.ModuleFingerprints = map[string]states.ModuleFingerprint{
+		"module.child": {
+			Inputs: "abc123",
+			Providers: map[string]string{
+				`provider["registry.opentofu.org/hashicorp/test"]`: "def456",
+			},
+		},
+	}
+
+	cache := incrementalModuleCache(state, nil, nil, nil)
+	if !cache.SkipRefresh {
+		t.Error("incremental plans must skip refreshing unchanged modules")
+	}
+	if diff := cmp.Diff(state.ModuleFingerprints, cache.Previous); diff != "" {
+		t.Fatalf("wrong fingerprints\n%s", diff)
+	}
+}
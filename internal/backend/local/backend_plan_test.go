@@ -23,11 +23,13 @@ import (
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/initwd"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
 
@@ -60,6 +62,73 @@ func TestLocal_planBasic(t *testing.T) {
 	}
 }
 
+func TestLocal_planAnalyzers(t *testing.T) {
+	b := TestLocal(t)
+	TestLocalProvider(t, b, "test", planFixtureSchema())
+
+	outDir := t.TempDir()
+	planPath := filepath.Join(outDir, "plan.tfplan")
+	op, configCleanup, done := testOperationPlan(t, "./testdata/plan")
+	defer configCleanup()
+	op.PlanRefresh = true
+	op.PlanOutPath = planPath
+	op.PlanOutBackend = &plans.Backend{}
+
+	warn := &testPlanAnalyzer{name: "cost", severity: tfdiags.Warning, summary: "Estimated cost"}
+	deny := &testPlanAnalyzer{name: "policy", severity: tfdiags.Error, summary: "Not allowed"}
+	op.PlanAnalyzers = []plananalyzer.Analyzer{warn, deny}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result != backend.OperationFailure {
+		t.Fatalf("plan operation succeeded; want failure")
+	}
+
+	for _, analyzer := range []*testPlanAnalyzer{warn, deny} {
+		if analyzer.plan == nil {
+			t.Errorf("analyzer %q wasn't called", analyzer.name)
+		} else if got := len(analyzer.plan.Changes.Resources); got != 1 {
+			t.Errorf("analyzer %q got a plan with %d resource changes; want 1", analyzer.name, got)
+		}
+	}
+
+	// The plan must not be saved, because an analyzer rejected it.
+	if _, err := os.Stat(planPath); !os.IsNotExist(err) {
+		t.Fatalf("plan file was written despite the analyzer error")
+	}
+
+	output := done(t)
+	if got, want := output.Stdout(), "1 to add, 0 to change, 0 to destroy"; !strings.Contains(got, want) {
+		t.Errorf("plan wasn't rendered\ngot:\n%s", got)
+	}
+	if got := output.All(); !strings.Contains(got, "Estimated cost") || !strings.Contains(got, "Not allowed") {
+		t.Errorf("analyzer diagnostics weren't shown\ngot:\n%s", got)
+	}
+}
+
+// testPlanAnalyzer is a plananalyzer.Analyzer that records the plan it is
+// given and returns a single diagnostic.
+type testPlanAnalyzer struct {
+	name     string
+	severity tfdiags.Severity
+	summary  string
+
+	plan *plans.Plan
+}
+
+func (a *testPlanAnalyzer) Name() string {
+	return a.name
+}
+
+func (a *testPlanAnalyzer) AnalyzePlan(_ context.Context, req *plananalyzer.Request) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	a.plan = req.Plan
+	return diags.Append(tfdiags.Sourceless(a.severity, a.summary, ""))
+}
+
 func TestLocal_planInAutomation(t *testing.T) {
 	b := TestLocal(t)
 	TestLocalProvider(t, b, "test", planFixtureSchema())
@@ -665,6 +734,97 @@ Plan: 0 to add, 0 to change, 1 to destroy.`
 	}
 }
 
+func TestLocal_planReviewDrift(t *testing.T) {
+	tests := map[string]struct {
+		choice      string
+		wantResult  backend.OperationResult
+		wantChanges []string
+		wantErr     string
+	}{
+		"recreate": {
+			choice:      "recreate",
+			wantResult:  backend.OperationSuccess,
+			wantChanges: []string{"test_instance.foo"},
+		},
+		"forget": {
+			choice:      "forget",
+			wantResult:  backend.OperationSuccess,
+			wantChanges: []string{},
+		},
+		"fail": {
+			choice:     "fail",
+			wantResult: backend.OperationFailure,
+			wantErr:    "Resources were deleted outside of OpenTofu",
+		},
+		"invalid": {
+			choice:     "nope",
+			wantResult: backend.OperationFailure,
+			wantErr:    "Invalid choice",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := TestLocal(t)
+			p := TestLocalProvider(t, b, "test", planFixtureSchema())
+			p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+				return providers.ReadResourceResponse{NewState: cty.NullVal(req.PriorState.Type())}
+			}
+			testStateFile(t, b.StatePath, testPlanState())
+
+			outDir := t.TempDir()
+			planPath := filepath.Join(outDir, "plan.tfplan")
+			op, configCleanup, done := testOperationPlan(t, "./testdata/plan")
+			defer configCleanup()
+			op.PlanRefresh = true
+			op.ReviewDrift = true
+			op.PlanOutPath = planPath
+			op.UIIn = &tofu.MockUIInput{
+				InputReturnMap: map[string]string{
+					"review-drift.test_instance.foo": test.choice,
+				},
+			}
+			cfg := cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal(b.StatePath),
+			})
+			cfgRaw, err := plans.NewDynamicValue(cfg, cfg.Type())
+			if err != nil {
+				t.Fatal(err)
+			}
+			op.PlanOutBackend = &plans.Backend{
+				// Just a placeholder so that we can generate a valid plan file.
+				Type:   "local",
+				Config: cfgRaw,
+			}
+
+			run, err := b.Operation(context.Background(), op)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			<-run.Done()
+			output := done(t)
+			if run.Result != test.wantResult {
+				t.Fatalf("wrong result %d; want %d\n%s", run.Result, test.wantResult, output.Stderr())
+			}
+			if test.wantErr != "" {
+				if got := output.Stderr(); !strings.Contains(got, test.wantErr) {
+					t.Fatalf("wrong error output\ngot:\n%s\nwant: %s", got, test.wantErr)
+				}
+				return
+			}
+
+			plan := testReadPlan(t, planPath)
+			if got, want := strings.Join(getAddrs(plan.Changes.Resources), ", "), strings.Join(test.wantChanges, ", "); got != want {
+				t.Fatalf("wrong changes\ngot:  %s\nwant: %s", got, want)
+			}
+			addr := mustResourceInstanceAddr("test_instance.foo")
+			if got, want := plan.PrevRunState.ResourceInstance(addr) != nil, test.choice == "recreate"; got != want {
+				t.Fatalf("wrong previous run state: instance exists is %t; want %t", got, want)
+			}
+		})
+	}
+}
+
 func getAddrs(resources []*plans.ResourceInstanceChangeSrc) []string {
 	addrs := make([]string, len(resources))
 	for i, r := range resources {
@@ -0,0 +1,59 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// retryHook is a hook that records the resource instances whose changes
+// failed to apply, so that the apply operation can retry them.
+type retryHook struct {
+	tofu.NilHook
+
+	mu     sync.Mutex
+	failed map[string]addrs.AbsResourceInstance
+}
+
+var _ tofu.Hook = (*retryHook)(nil)
+
+func (h *retryHook) PostApply(addr addrs.AbsResourceInstance, _ states.Generation, _ cty.Value, err error) (tofu.HookAction, error) {
+	if err == nil {
+		return tofu.HookActionContinue, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failed == nil {
+		h.failed = make(map[string]addrs.AbsResourceInstance)
+	}
+	h.failed[addr.String()] = addr
+	return tofu.HookActionContinue, nil
+}
+
+// takeFailed returns the resource instances that failed since the previous
+// call, sorted by address.
+func (h *retryHook) takeFailed() []addrs.AbsResourceInstance {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ret := make([]addrs.AbsResourceInstance, 0, len(h.failed))
+	for _, addr := range h.failed {
+		ret = append(ret, addr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Less(ret[j])
+	})
+	h.failed = nil
+	return ret
+}
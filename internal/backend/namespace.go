@@ -0,0 +1,86 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// NamespaceSeparator separates the state namespace from the name of the
+// workspace in the names of the workspaces that a namespaced backend stores
+// in its underlying backend.
+//
+// Namespaces can't contain the separator, so the workspaces of different
+// namespaces never collide even though workspace names can contain it.
+const NamespaceSeparator = "."
+
+// Namespaced returns a backend that stores the state of each of its
+// workspaces, including the default workspace, in a workspace of the given
+// backend whose name is the namespace and the workspace name joined by
+// NamespaceSeparator.
+//
+// This allows several root modules to share the same backend configuration,
+// such as the same bucket and key, without their states colliding, because
+// each one only sees the workspaces in its own namespace.
+//
+// The given backend must be configured before its workspaces are used, and
+// must support multiple workspaces.
+func Namespaced(b Backend, namespace string) Backend {
+	return &namespacedBackend{
+		Backend:   b,
+		namespace: namespace,
+	}
+}
+
+type namespacedBackend struct {
+	Backend
+	namespace string
+}
+
+func (b *namespacedBackend) workspace(name string) string {
+	return b.namespace + NamespaceSeparator + name
+}
+
+func (b *namespacedBackend) StateMgr(workspace string) (statemgr.Full, error) {
+	sMgr, err := b.Backend.StateMgr(b.workspace(workspace))
+	if errors.Is(err, ErrWorkspacesNotSupported) {
+		return nil, fmt.Errorf("the backend doesn't support multiple workspaces, which are required to store the state of namespace %q", b.namespace)
+	}
+	return sMgr, err
+}
+
+func (b *namespacedBackend) DeleteWorkspace(name string, force bool) error {
+	if name == DefaultStateName || name == "" {
+		return fmt.Errorf("can't delete default state")
+	}
+	return b.Backend.DeleteWorkspace(b.workspace(name), force)
+}
+
+func (b *namespacedBackend) Workspaces() ([]string, error) {
+	all, err := b.Backend.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	// The default workspace always exists, even before its state is first
+	// written, like in the backends themselves.
+	result := []string{DefaultStateName}
+	prefix := b.namespace + NamespaceSeparator
+	for _, name := range all {
+		name, ok := strings.CutPrefix(name, prefix)
+		if !ok || name == DefaultStateName || name == "" {
+			continue
+		}
+		result = append(result, name)
+	}
+	sort.Strings(result[1:])
+	return result, nil
+}
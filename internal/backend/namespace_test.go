@@ -0,0 +1,74 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package backend_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/backend/remote-state/inmem"
+	"github.com/opentofu/opentofu/internal/encryption"
+)
+
+func TestNamespaced(t *testing.T) {
+	defer inmem.Reset()
+
+	raw := inmem.New(encryption.StateEncryptionDisabled())
+	networking := backend.Namespaced(raw, "networking")
+	compute := backend.Namespaced(raw, "compute")
+
+	for _, ws := range []string{backend.DefaultStateName, "prod", "dev.eu"} {
+		if _, err := networking.StateMgr(ws); err != nil {
+			t.Fatalf("unexpected error creating %q: %s", ws, err)
+		}
+	}
+	if _, err := compute.StateMgr("prod"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := networking.Workspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{backend.DefaultStateName, "dev.eu", "prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong networking workspaces %#v; want %#v", got, want)
+	}
+
+	got, err = compute.Workspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want = []string{backend.DefaultStateName, "prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong compute workspaces %#v; want %#v", got, want)
+	}
+
+	got, err = raw.Workspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want = []string{"compute.prod", "networking.default", "networking.dev.eu", "networking.prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong underlying workspaces %#v; want %#v", got, want)
+	}
+
+	if err := networking.DeleteWorkspace(backend.DefaultStateName, false); err == nil {
+		t.Error("deleting the default workspace succeeded")
+	}
+	if err := compute.DeleteWorkspace("prod", false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err = networking.Workspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want = []string{backend.DefaultStateName, "dev.eu", "prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong networking workspaces after delete %#v; want %#v", got, want)
+	}
+}
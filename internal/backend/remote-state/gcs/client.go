@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strconv"
 
 	"cloud.google.com/go/storage"
@@ -17,11 +18,13 @@ import (
 	"github.com/opentofu/opentofu/internal/states/remote"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"golang.org/x/net/context"
+	"google.golang.org/api/googleapi"
 )
 
 // remoteClient is used by "state/remote".State to read and write
 // blobs representing state.
-// Implements "state/remote".ClientLocker
+// Implements "state/remote".ClientLocker and
+// "state/remote".ClientConditionalWriter
 type remoteClient struct {
 	storageContext context.Context
 	storageClient  *storage.Client
@@ -30,12 +33,23 @@ type remoteClient struct {
 	lockFilePath   string
 	encryptionKey  []byte
 	kmsKeyName     string
+
+	// generation is the generation of the state file that was last read or
+	// written, for conditional writes. It's zero if the state file didn't
+	// exist, and generationKnown is false until the state file is read or
+	// written.
+	generation      int64
+	generationKnown bool
 }
 
+var _ remote.ClientConditionalWriter = (*remoteClient)(nil)
+
 func (c *remoteClient) Get() (payload *remote.Payload, err error) {
 	stateFileReader, err := c.stateFile().NewReader(c.storageContext)
 	if err != nil {
 		if err == storage.ErrObjectNotExist {
+			c.generation = 0
+			c.generationKnown = true
 			return nil, nil
 		} else {
 			return nil, fmt.Errorf("Failed to open state file at %v: %w", c.stateFileURL(), err)
@@ -57,20 +71,50 @@ func (c *remoteClient) Get() (payload *remote.Payload, err error) {
 		Data: stateFileContents,
 		MD5:  stateFileAttrs.MD5,
 	}
+	c.generation = stateFileReader.Attrs.Generation
+	c.generationKnown = true
 
 	return result, nil
 }
 
 func (c *remoteClient) Put(data []byte) error {
+	return c.put(c.stateFile(), data)
+}
+
+// PutIfUnchanged writes the state file only if its generation is still the
+// one that was last read or written.
+func (c *remoteClient) PutIfUnchanged(data []byte) error {
+	if !c.generationKnown {
+		return c.Put(data)
+	}
+	cond := storage.Conditions{GenerationMatch: c.generation}
+	if c.generation == 0 {
+		cond = storage.Conditions{DoesNotExist: true}
+	}
+
+	err := c.put(c.stateFile().If(cond), data)
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return remote.ErrConcurrentWrite
+	}
+	return err
+}
+
+func (c *remoteClient) put(stateFile *storage.ObjectHandle, data []byte) error {
 	err := func() error {
-		stateFileWriter := c.stateFile().NewWriter(c.storageContext)
+		stateFileWriter := stateFile.NewWriter(c.storageContext)
 		if len(c.kmsKeyName) > 0 {
 			stateFileWriter.KMSKeyName = c.kmsKeyName
 		}
 		if _, err := stateFileWriter.Write(data); err != nil {
 			return err
 		}
-		return stateFileWriter.Close()
+		if err := stateFileWriter.Close(); err != nil {
+			return err
+		}
+		c.generation = stateFileWriter.Attrs().Generation
+		c.generationKnown = true
+		return nil
 	}()
 	if err != nil {
 		return fmt.Errorf("Failed to upload state to %v: %w", c.stateFileURL(), err)
@@ -83,6 +127,7 @@ func (c *remoteClient) Delete() error {
 	if err := c.stateFile().Delete(c.storageContext); err != nil {
 		return fmt.Errorf("Failed to delete state file %v: %w", c.stateFileURL(), err)
 	}
+	c.generation = 0
 
 	return nil
 }
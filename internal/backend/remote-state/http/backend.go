@@ -43,6 +43,20 @@ func New(enc encryption.StateEncryption) backend.Backend {
 				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_UPDATE_METHOD", "POST"),
 				Description: "HTTP method to use when updating state",
 			},
+			"lock_mode": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("TF_HTTP_LOCK_MODE", lockModeEndpoints),
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					switch v.(string) {
+					case lockModeEndpoints, lockModeETag:
+						return nil, nil
+					default:
+						return nil, []error{fmt.Errorf("%s must be %q or %q", k, lockModeEndpoints, lockModeETag)}
+					}
+				},
+				Description: "How to lock the state: \"endpoints\" to use the lock and unlock endpoints, or \"etag\" to use conditional requests",
+			},
 			"lock_address": &schema.Schema{
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -248,6 +262,22 @@ func (b *Backend) configure(ctx context.Context) error {
 
 	unlockMethod := data.Get("unlock_method").(string)
 
+	lockMode := data.Get("lock_mode").(string)
+	if lockMode == lockModeETag {
+		if unlockURL != nil {
+			return fmt.Errorf("unlock_address must not be set when lock_mode is %q", lockModeETag)
+		}
+		if lockURL == nil {
+			// By default the lock object is next to the state
+			u := *updateURL
+			u.Path += ".lock"
+			if u.RawPath != "" {
+				u.RawPath += ".lock"
+			}
+			lockURL = &u
+		}
+	}
+
 	username := data.Get("username").(string)
 	password := data.Get("password").(string)
 
@@ -288,6 +318,7 @@ func (b *Backend) configure(ctx context.Context) error {
 		URL:          updateURL,
 		UpdateMethod: updateMethod,
 
+		LockMode:     lockMode,
 		LockURL:      lockURL,
 		LockMethod:   lockMethod,
 		UnlockURL:    unlockURL,
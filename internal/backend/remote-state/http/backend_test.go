@@ -125,6 +125,46 @@ func TestHTTPClientFactory(t *testing.T) {
 	}
 }
 
+func TestHTTPClientFactory_lockModeETag(t *testing.T) {
+	conf := map[string]cty.Value{
+		"address":   cty.StringVal("http://127.0.0.1:8888/foo"),
+		"lock_mode": cty.StringVal("etag"),
+	}
+	b := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf)).(*Backend)
+	client := b.client
+
+	if client.LockMode != lockModeETag {
+		t.Fatalf("Expected lock_mode %q, got %q", lockModeETag, client.LockMode)
+	}
+	if client.LockURL == nil || client.LockURL.String() != "http://127.0.0.1:8888/foo.lock" {
+		t.Fatalf("Unexpected default lock_address %s", client.LockURL)
+	}
+	if !client.IsLockingEnabled() {
+		t.Fatal("Expected locking to be enabled")
+	}
+
+	conf["lock_address"] = cty.StringVal("http://127.0.0.1:8888/locks/foo")
+	b = backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf)).(*Backend)
+	if got := b.client.LockURL.String(); got != "http://127.0.0.1:8888/locks/foo" {
+		t.Fatalf("Expected lock_address \"%s\", got \"%s\"", conf["lock_address"].AsString(), got)
+	}
+
+	conf["unlock_address"] = cty.StringVal("http://127.0.0.1:8888/unlock")
+	_, _, errs := backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf))
+	if len(errs) != 1 || errs[0].Error() != `unlock_address must not be set when lock_mode is "etag"` {
+		t.Fatalf("Unexpected errors with unlock_address: %v", errs)
+	}
+
+	conf = map[string]cty.Value{
+		"address":   cty.StringVal("http://127.0.0.1:8888/foo"),
+		"lock_mode": cty.StringVal("blob"),
+	}
+	_, _, errs = backend.TestBackendConfigWarningsAndErrors(t, New(encryption.StateEncryptionDisabled()), configs.SynthBody("synth", conf))
+	if len(errs) != 1 {
+		t.Fatalf("Expected an error for an invalid lock_mode, got %v", errs)
+	}
+}
+
 func TestHTTPClientFactoryWithEnv(t *testing.T) {
 	// env
 	conf := map[string]string{
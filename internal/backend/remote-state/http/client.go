@@ -21,6 +21,17 @@ import (
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
 
+const (
+	// lockModeEndpoints locks the state using the custom lock and unlock
+	// endpoints of the server.
+	lockModeEndpoints = "endpoints"
+
+	// lockModeETag locks the state by creating a lock object at LockURL
+	// using conditional requests, which any server that supports ETag,
+	// If-Match and If-None-Match can handle.
+	lockModeETag = "etag"
+)
+
 // httpClient is a remote client that stores data in Consul or HTTP REST.
 type httpClient struct {
 	// Update & Retrieve
@@ -28,6 +39,7 @@ type httpClient struct {
 	UpdateMethod string
 
 	// Locking
+	LockMode     string
 	LockURL      *url.URL
 	LockMethod   string
 	UnlockURL    *url.URL
@@ -41,9 +53,20 @@ type httpClient struct {
 
 	lockID       string
 	jsonLockInfo []byte
+
+	// stateETag is the ETag of the state as of the last Get, used in
+	// lockModeETag to make sure that state uploads don't overwrite changes
+	// made by another process. It's only meaningful if stateETagKnown is
+	// set, and is empty if the state didn't exist.
+	stateETag      string
+	stateETagKnown bool
 }
 
 func (c *httpClient) httpRequest(method string, url *url.URL, data []byte, what string) (*http.Response, error) {
+	return c.httpRequestWithHeaders(method, url, data, nil, what)
+}
+
+func (c *httpClient) httpRequestWithHeaders(method string, url *url.URL, data []byte, headers map[string]string, what string) (*http.Response, error) {
 	var body interface{}
 	if len(data) > 0 {
 		body = data
@@ -62,6 +85,12 @@ func (c *httpClient) httpRequest(method string, url *url.URL, data []byte, what
 		req.Header.Set(k, v)
 	}
 
+	// Add the request-specific headers, such as the conditional request
+	// headers
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
 	if c.Username != "" {
 		req.SetBasicAuth(c.Username, c.Password)
 	}
@@ -93,6 +122,9 @@ func (c *httpClient) Lock(info *statemgr.LockInfo) (string, error) {
 	if c.LockURL == nil {
 		return "", nil
 	}
+	if c.LockMode == lockModeETag {
+		return c.lockETag(info)
+	}
 	c.lockID = ""
 
 	jsonLockInfo := info.Marshal()
@@ -139,7 +171,89 @@ func (c *httpClient) Lock(info *statemgr.LockInfo) (string, error) {
 	}
 }
 
+// lockETag creates the lock object at LockURL, using If-None-Match so that
+// the request fails if another process already holds the lock.
+func (c *httpClient) lockETag(info *statemgr.LockInfo) (string, error) {
+	c.lockID = ""
+
+	jsonLockInfo := info.Marshal()
+	resp, err := c.httpRequestWithHeaders(http.MethodPut, c.LockURL, jsonLockInfo, map[string]string{"If-None-Match": "*"}, "lock")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		c.lockID = info.ID
+		c.jsonLockInfo = jsonLockInfo
+		return info.ID, nil
+	case http.StatusUnauthorized:
+		log.Printf("[DEBUG] LOCK, Unauthorized: %s", parseResponseBodyForLog(resp))
+		return "", fmt.Errorf("HTTP remote state endpoint requires auth")
+	case http.StatusForbidden:
+		log.Printf("[DEBUG] LOCK, Forbidden: %s", parseResponseBodyForLog(resp))
+		return "", fmt.Errorf("HTTP remote state endpoint invalid auth")
+	case http.StatusPreconditionFailed:
+		existing, _, err := c.getLockInfo()
+		if err != nil {
+			return "", &statemgr.LockError{
+				Info: info,
+				Err:  fmt.Errorf("HTTP remote state already locked, failed to read lock: %w", err),
+			}
+		}
+		if existing == nil {
+			// The lock was released after our request, so the caller can
+			// retry.
+			return "", &statemgr.LockError{
+				Info: info,
+				Err:  fmt.Errorf("HTTP remote state lock was released while locking"),
+			}
+		}
+		return "", &statemgr.LockError{
+			Info: existing,
+			Err:  fmt.Errorf("HTTP remote state already locked: ID=%s", existing.ID),
+		}
+	default:
+		log.Printf("[DEBUG] LOCK, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
+		return "", fmt.Errorf("Unexpected HTTP response code %d", resp.StatusCode)
+	}
+}
+
+// getLockInfo returns the lock object at LockURL and its ETag, or nil if the
+// state isn't locked.
+func (c *httpClient) getLockInfo() (*statemgr.LockInfo, string, error) {
+	resp, err := c.httpRequest(http.MethodGet, c.LockURL, nil, "get lock")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Handled after
+	case http.StatusNotFound:
+		return nil, "", nil
+	default:
+		log.Printf("[DEBUG] GET LOCK, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
+		return nil, "", fmt.Errorf("Unexpected HTTP response code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read body: %w", err)
+	}
+	info := &statemgr.LockInfo{}
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal body: %w", err)
+	}
+	return info, resp.Header.Get("ETag"), nil
+}
+
 func (c *httpClient) Unlock(id string) error {
+	if c.LockMode == lockModeETag {
+		return c.unlockETag(id)
+	}
 	if c.UnlockURL == nil {
 		return nil
 	}
@@ -177,6 +291,55 @@ func (c *httpClient) Unlock(id string) error {
 	}
 }
 
+// unlockETag deletes the lock object at LockURL. It reads the lock object
+// first to check that it's the lock with the given ID, and uses If-Match on
+// the delete request so that it can't remove a lock that another process
+// acquired in the meantime.
+func (c *httpClient) unlockETag(id string) error {
+	if c.LockURL == nil {
+		return nil
+	}
+
+	existing, etag, err := c.getLockInfo()
+	if err != nil {
+		return fmt.Errorf("failed to read HTTP remote state lock: %w", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("HTTP remote state is not locked")
+	}
+	if existing.ID != id {
+		return &statemgr.LockError{
+			Info: existing,
+			Err:  fmt.Errorf("lock id %q does not match existing lock", id),
+		}
+	}
+
+	var headers map[string]string
+	if etag != "" {
+		headers = map[string]string{"If-Match": etag}
+	}
+	resp, err := c.httpRequestWithHeaders(http.MethodDelete, c.LockURL, nil, headers, "unlock")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		c.lockID = ""
+		c.jsonLockInfo = nil
+		return nil
+	case http.StatusPreconditionFailed:
+		return &statemgr.LockError{
+			Info: existing,
+			Err:  fmt.Errorf("HTTP remote state lock changed while unlocking"),
+		}
+	default:
+		log.Printf("[DEBUG] UNLOCK, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
+		return fmt.Errorf("Unexpected HTTP response code %d", resp.StatusCode)
+	}
+}
+
 func (c *httpClient) Get() (*remote.Payload, error) {
 	resp, err := c.httpRequest(http.MethodGet, c.URL, nil, "get state")
 	if err != nil {
@@ -184,6 +347,8 @@ func (c *httpClient) Get() (*remote.Payload, error) {
 	}
 	defer resp.Body.Close()
 
+	c.stateETag, c.stateETagKnown = "", false
+
 	// Handle the common status codes
 	switch resp.StatusCode {
 	case http.StatusOK:
@@ -191,6 +356,7 @@ func (c *httpClient) Get() (*remote.Payload, error) {
 	case http.StatusNoContent:
 		return nil, nil
 	case http.StatusNotFound:
+		c.stateETagKnown = true
 		return nil, nil
 	case http.StatusUnauthorized:
 		log.Printf("[DEBUG] GET STATE, Unauthorized: %s", parseResponseBodyForLog(resp))
@@ -212,6 +378,10 @@ func (c *httpClient) Get() (*remote.Payload, error) {
 		return nil, fmt.Errorf("Failed to read remote state: %w", err)
 	}
 
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.stateETag, c.stateETagKnown = etag, true
+	}
+
 	// Create the payload
 	payload := &remote.Payload{
 		Data: buf.Bytes(),
@@ -244,7 +414,19 @@ func (c *httpClient) Put(data []byte) error {
 	// Copy the target URL
 	base := *c.URL
 
-	if c.lockID != "" {
+	var headers map[string]string
+	if c.lockID != "" && c.LockMode == lockModeETag {
+		// Make sure that no other process changed the state since we read
+		// it, in case it doesn't use the lock.
+		switch {
+		case !c.stateETagKnown:
+			// The server didn't tell us, so we can't check
+		case c.stateETag == "":
+			headers = map[string]string{"If-None-Match": "*"}
+		default:
+			headers = map[string]string{"If-Match": c.stateETag}
+		}
+	} else if c.lockID != "" {
 		query := base.Query()
 		query.Set("ID", c.lockID)
 		base.RawQuery = query.Encode()
@@ -263,7 +445,7 @@ func (c *httpClient) Put(data []byte) error {
 	if c.UpdateMethod != "" {
 		method = c.UpdateMethod
 	}
-	resp, err := c.httpRequest(method, &base, data, "upload state")
+	resp, err := c.httpRequestWithHeaders(method, &base, data, headers, "upload state")
 	if err != nil {
 		return err
 	}
@@ -272,7 +454,13 @@ func (c *httpClient) Put(data []byte) error {
 	// Handle the error codes
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		// The next upload is conditional on this one, if the server tells us
+		// the new ETag.
+		etag := resp.Header.Get("ETag")
+		c.stateETag, c.stateETagKnown = etag, etag != ""
 		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("HTTP remote state was changed by another process since OpenTofu read it")
 	default:
 		log.Printf("[DEBUG] UPLOAD STATE, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
 		return fmt.Errorf("HTTP error: %d", resp.StatusCode)
@@ -289,7 +477,7 @@ func (c *httpClient) Delete() error {
 
 	// Handle the error codes
 	switch resp.StatusCode {
-	case http.StatusOK:
+	case http.StatusOK, http.StatusNoContent:
 		return nil
 	default:
 		log.Printf("[DEBUG] DELETE STATE, %d: %s", resp.StatusCode, parseResponseBodyForLog(resp))
@@ -298,5 +486,8 @@ func (c *httpClient) Delete() error {
 }
 
 func (c *httpClient) IsLockingEnabled() bool {
+	if c.LockMode == lockModeETag {
+		return c.LockURL != nil
+	}
 	return c.UnlockURL != nil
 }
@@ -129,6 +129,157 @@ func TestHTTPClient(t *testing.T) {
 	remote.TestClient(t, client)
 }
 
+func TestHTTPClient_lockModeETag(t *testing.T) {
+	handler := newTestETagHTTPHandler()
+	ts := httptest.NewServer(http.HandlerFunc(handler.Handle))
+	defer ts.Close()
+
+	stateURL, err := url.Parse(ts.URL + "/state")
+	if err != nil {
+		t.Fatalf("Parse: %s", err)
+	}
+	lockURL, err := url.Parse(ts.URL + "/state.lock")
+	if err != nil {
+		t.Fatalf("Parse: %s", err)
+	}
+
+	newClient := func() *httpClient {
+		return &httpClient{
+			URL:          stateURL,
+			UpdateMethod: "PUT",
+			LockMode:     lockModeETag,
+			LockURL:      lockURL,
+			Client:       retryablehttp.NewClient(),
+		}
+	}
+
+	remote.TestClient(t, newClient())
+	remote.TestRemoteLocks(t, newClient(), newClient())
+
+	// A locked client must not overwrite a state that changed since it read
+	// it.
+	a := newClient()
+	lockID, err := a.Lock(statemgr.NewLockInfo())
+	if err != nil {
+		t.Fatalf("Lock: %s", err)
+	}
+	if _, err := a.Get(); err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if err := a.Put([]byte(`{"serial":1}`)); err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+	if err := newClient().Put([]byte(`{"serial":2}`)); err != nil {
+		t.Fatalf("unlocked Put: %s", err)
+	}
+	err = a.Put([]byte(`{"serial":3}`))
+	if err == nil || err.Error() != "HTTP remote state was changed by another process since OpenTofu read it" {
+		t.Fatalf("wrong error for conflicting Put: %v", err)
+	}
+	if err := a.Unlock(lockID); err != nil {
+		t.Fatalf("Unlock: %s", err)
+	}
+	if _, ok := handler.Objects["/state.lock"]; ok {
+		t.Fatal("lock object still exists after unlocking")
+	}
+}
+
+func TestHttpClient_unlockModeETag(t *testing.T) {
+	handler := newTestETagHTTPHandler()
+	ts := httptest.NewServer(http.HandlerFunc(handler.Handle))
+	defer ts.Close()
+
+	lockURL, err := url.Parse(ts.URL + "/state.lock")
+	if err != nil {
+		t.Fatalf("Parse: %s", err)
+	}
+	client := &httpClient{
+		LockMode: lockModeETag,
+		LockURL:  lockURL,
+		Client:   retryablehttp.NewClient(),
+	}
+
+	if err := client.Unlock("a"); err == nil || err.Error() != "HTTP remote state is not locked" {
+		t.Fatalf("wrong error unlocking an unlocked state: %v", err)
+	}
+
+	info := statemgr.NewLockInfo()
+	lockID, err := client.Lock(info)
+	if err != nil {
+		t.Fatalf("Lock: %s", err)
+	}
+	err = client.Unlock("a")
+	if _, ok := err.(*statemgr.LockError); !ok {
+		t.Fatalf("expected a LockError for the wrong lock ID, got %v", err)
+	}
+
+	// Force-unlocking from another client only needs the lock ID.
+	other := &httpClient{
+		LockMode: lockModeETag,
+		LockURL:  lockURL,
+		Client:   retryablehttp.NewClient(),
+	}
+	if err := other.Unlock(lockID); err != nil {
+		t.Fatalf("force Unlock: %s", err)
+	}
+}
+
+// testETagHTTPHandler is a blob store that supports conditional requests,
+// for testing lockModeETag.
+type testETagHTTPHandler struct {
+	Objects map[string][]byte
+	ETags   map[string]string
+	counter int
+}
+
+func newTestETagHTTPHandler() *testETagHTTPHandler {
+	return &testETagHTTPHandler{
+		Objects: make(map[string][]byte),
+		ETags:   make(map[string]string),
+	}
+}
+
+func (h *testETagHTTPHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	etag, exists := h.ETags[path]
+	if match := r.Header.Get("If-Match"); match != "" && (!exists || match != etag) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if r.Header.Get("If-None-Match") == "*" && exists {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write(h.Objects[path])
+	case "PUT":
+		buf := new(bytes.Buffer)
+		if _, err := io.Copy(buf, r.Body); err != nil {
+			w.WriteHeader(500)
+			return
+		}
+		h.counter++
+		h.Objects[path] = buf.Bytes()
+		h.ETags[path] = fmt.Sprintf(`"%d"`, h.counter)
+		w.Header().Set("ETag", h.ETags[path])
+		w.WriteHeader(http.StatusOK)
+	case "DELETE":
+		delete(h.Objects, path)
+		delete(h.ETags, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(500)
+		w.Write([]byte(fmt.Sprintf("Unknown method: %s", r.Method)))
+	}
+}
+
 type testHTTPHandler struct {
 	Data   []byte
 	Locked bool
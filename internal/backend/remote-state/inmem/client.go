@@ -7,6 +7,8 @@ package inmem
 
 import (
 	"crypto/md5"
+	"strconv"
+	"time"
 
 	"github.com/opentofu/opentofu/internal/states/remote"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
@@ -17,6 +19,17 @@ type RemoteClient struct {
 	Data []byte
 	MD5  []byte
 	Name string
+
+	// Versions are all of the snapshots written with Put, oldest first, to
+	// test reading earlier versions of the state.
+	Versions []RemoteClientVersion
+}
+
+// RemoteClientVersion is a snapshot written to a RemoteClient.
+type RemoteClientVersion struct {
+	ID   string
+	Time time.Time
+	Data []byte
 }
 
 func (c *RemoteClient) Get() (*remote.Payload, error) {
@@ -35,15 +48,44 @@ func (c *RemoteClient) Put(data []byte) error {
 
 	c.Data = data
 	c.MD5 = md5[:]
+	c.Versions = append(c.Versions, RemoteClientVersion{
+		ID:   strconv.Itoa(len(c.Versions) + 1),
+		Time: time.Now(),
+		Data: data,
+	})
 	return nil
 }
 
 func (c *RemoteClient) Delete() error {
 	c.Data = nil
 	c.MD5 = nil
+	c.Versions = nil
 	return nil
 }
 
+// GetVersion is an implementation of remote.ClientVersionReader.
+func (c *RemoteClient) GetVersion(sel statemgr.StateVersionSelector) (*remote.Payload, error) {
+	var found *RemoteClientVersion
+	for i, v := range c.Versions {
+		if sel.ID != "" && v.ID == sel.ID {
+			found = &c.Versions[i]
+			break
+		}
+		if sel.ID == "" && !v.Time.After(sel.Time) {
+			found = &c.Versions[i]
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	md5 := md5.Sum(found.Data)
+	return &remote.Payload{
+		Data: found.Data,
+		MD5:  md5[:],
+	}, nil
+}
+
 func (c *RemoteClient) Lock(info *statemgr.LockInfo) (string, error) {
 	return locks.lock(c.Name, info)
 }
@@ -7,16 +7,19 @@ package inmem
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/states/remote"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
 
 func TestRemoteClient_impl(t *testing.T) {
 	var _ remote.Client = new(RemoteClient)
 	var _ remote.ClientLocker = new(RemoteClient)
+	var _ remote.ClientVersionReader = new(RemoteClient)
 }
 
 func TestRemoteClient(t *testing.T) {
@@ -31,6 +34,46 @@ func TestRemoteClient(t *testing.T) {
 	remote.TestClient(t, s.(*remote.State).Client)
 }
 
+func TestRemoteClient_GetVersion(t *testing.T) {
+	c := &RemoteClient{}
+	for _, data := range []string{"one", "two", "three"} {
+		if err := c.Put([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := range c.Versions {
+		c.Versions[i].Time = base.Add(time.Duration(i) * time.Hour)
+	}
+
+	testCases := map[string]struct {
+		sel  statemgr.StateVersionSelector
+		want string
+	}{
+		"by id":            {statemgr.StateVersionSelector{ID: "2"}, "two"},
+		"at a version":     {statemgr.StateVersionSelector{Time: base.Add(time.Hour)}, "two"},
+		"between":          {statemgr.StateVersionSelector{Time: base.Add(90 * time.Minute)}, "two"},
+		"after all":        {statemgr.StateVersionSelector{Time: base.Add(24 * time.Hour)}, "three"},
+		"unknown id":       {statemgr.StateVersionSelector{ID: "4"}, ""},
+		"before the first": {statemgr.StateVersionSelector{Time: base.Add(-time.Hour)}, ""},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := c.GetVersion(tc.sel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if payload != nil {
+				got = string(payload.Data)
+			}
+			if got != tc.want {
+				t.Fatalf("wrong version %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestInmemLocks(t *testing.T) {
 	defer Reset()
 	s, err := backend.TestBackendConfig(t, New(encryption.StateEncryptionDisabled()), hcl.EmptyBody()).StateMgr(backend.DefaultStateName)
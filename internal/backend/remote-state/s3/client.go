@@ -24,6 +24,7 @@ import (
 	dtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	multierror "github.com/hashicorp/go-multierror"
 	uuid "github.com/hashicorp/go-uuid"
 
@@ -189,6 +190,125 @@ func (c *RemoteClient) get(ctx context.Context) (*remote.Payload, error) {
 	return payload, nil
 }
 
+// GetVersion is an implementation of remote.ClientVersionReader, for buckets
+// with versioning enabled.
+func (c *RemoteClient) GetVersion(sel statemgr.StateVersionSelector) (*remote.Payload, error) {
+	ctx := context.TODO()
+	ctx, _ = attachLoggerToContext(ctx)
+
+	versionID := sel.ID
+	if versionID == "" {
+		var err error
+		versionID, err = c.versionAt(ctx, sel.Time)
+		if err != nil {
+			return nil, err
+		}
+		if versionID == "" {
+			return nil, nil
+		}
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket:    &c.bucketName,
+		Key:       &c.path,
+		VersionId: aws.String(versionID),
+	}
+
+	if c.serverSideEncryption && c.customerEncryptionKey != nil {
+		input.SSECustomerKey = aws.String(base64.StdEncoding.EncodeToString(c.customerEncryptionKey))
+		input.SSECustomerAlgorithm = aws.String(s3EncryptionAlgorithm)
+		input.SSECustomerKeyMD5 = aws.String(c.getSSECustomerKeyMD5())
+	}
+
+	output, err := c.s3Client.GetObject(ctx, input)
+	if err != nil {
+		var nb *types.NoSuchBucket
+		if errors.As(err, &nb) {
+			return nil, fmt.Errorf(errS3NoSuchBucket, err)
+		}
+
+		var nk *types.NoSuchKey
+		if errors.As(err, &nk) {
+			return nil, nil
+		}
+
+		// S3 returns NoSuchVersion for unknown version IDs, and
+		// InvalidArgument for strings that aren't valid version IDs at all.
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NoSuchVersion" || apiErr.ErrorCode() == "InvalidArgument") {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer output.Body.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, output.Body); err != nil {
+		return nil, fmt.Errorf("Failed to read remote state version %s: %w", versionID, err)
+	}
+	if buf.Len() == 0 {
+		return nil, nil
+	}
+
+	sum := md5.Sum(buf.Bytes())
+	return &remote.Payload{
+		Data: buf.Bytes(),
+		MD5:  sum[:],
+	}, nil
+}
+
+// versionAt returns the ID of the version of the state object that was
+// current at the given time, or an empty string if the object didn't exist
+// then.
+func (c *RemoteClient) versionAt(ctx context.Context, t time.Time) (string, error) {
+	var latestID string
+	var latestTime time.Time
+	consider := func(key, versionID *string, modified *time.Time, deleted bool) {
+		if aws.ToString(key) != c.path || modified == nil || modified.After(t) {
+			return
+		}
+		if !modified.After(latestTime) {
+			return
+		}
+		latestTime = *modified
+		latestID = aws.ToString(versionID)
+		if deleted {
+			// The object didn't exist between being deleted and the next
+			// version.
+			latestID = ""
+		}
+	}
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket: &c.bucketName,
+		Prefix: &c.path,
+	}
+	for {
+		output, err := c.s3Client.ListObjectVersions(ctx, input)
+		if err != nil {
+			var nb *types.NoSuchBucket
+			if errors.As(err, &nb) {
+				return "", fmt.Errorf(errS3NoSuchBucket, err)
+			}
+			return "", err
+		}
+		for _, v := range output.Versions {
+			consider(v.Key, v.VersionId, v.LastModified, false)
+		}
+		for _, m := range output.DeleteMarkers {
+			consider(m.Key, m.VersionId, m.LastModified, true)
+		}
+		if !aws.ToBool(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.VersionIdMarker = output.NextVersionIdMarker
+	}
+	return latestID, nil
+}
+
 func (c *RemoteClient) Put(data []byte) error {
 	contentType := "application/json"
 	contentLength := int64(len(data))
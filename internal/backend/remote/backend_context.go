@@ -137,7 +137,7 @@ func (b *Remote) LocalRun(_ context.Context, op *backend.Operation) (*backend.Lo
 		}
 
 		if op.Variables != nil {
-			variables, varDiags := backend.ParseVariableValues(op.Variables, config.Module.Variables)
+			variables, varDiags := backend.ParseVariableValues(op.Variables, config.Module.Variables, op.StrictVariables)
 			diags = diags.Append(varDiags)
 			if diags.HasErrors() {
 				return nil, nil, diags
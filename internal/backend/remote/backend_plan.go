@@ -136,6 +136,22 @@ func (b *Remote) opPlan(stopCtx, cancelCtx context.Context, op *backend.Operatio
 		))
 	}
 
+	if len(op.TargetGroups) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"-target-group option is not supported",
+			"The -target-group option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.TargetStrict {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"-target-strict option is not supported",
+			"The -target-strict option is not currently supported for remote plans.",
+		))
+	}
+
 	if !op.PlanRefresh {
 		desiredAPIVersion, _ := version.NewVersion("2.4")
 
@@ -168,6 +184,66 @@ func (b *Remote) opPlan(stopCtx, cancelCtx context.Context, op *backend.Operatio
 		}
 	}
 
+	if op.PlanMode == plans.DriftOnlyMode {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Drift-only mode is not supported",
+			fmt.Sprintf(
+				`The host %s does not support -drift-only mode for `+
+					`remote plans.`,
+				b.hostname,
+			),
+		))
+	}
+
+	if op.ModuleCachePath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Reusing unchanged modules is not supported",
+			fmt.Sprintf(
+				`The host %s does not support the -reuse-unchanged-modules `+
+					`option for remote plans.`,
+				b.hostname,
+			),
+		))
+	}
+
+	if op.Incremental {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incremental plans are not supported",
+			fmt.Sprintf(
+				`The host %s does not support the -incremental option for `+
+					`remote plans.`,
+				b.hostname,
+			),
+		))
+	}
+
+	if op.StateVersion != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Planning against a state version is not supported",
+			fmt.Sprintf(
+				`The host %s does not support the -state-version `+
+					`option for remote plans.`,
+				b.hostname,
+			),
+		))
+	}
+
+	if op.ReviewDrift {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Reviewing drift is not supported",
+			fmt.Sprintf(
+				`The host %s does not support the -review-drift `+
+					`option for remote plans.`,
+				b.hostname,
+			),
+		))
+	}
+
 	if op.PlanMode == plans.RefreshOnlyMode {
 		desiredAPIVersion, _ := version.NewVersion("2.4")
 
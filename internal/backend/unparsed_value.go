@@ -7,9 +7,11 @@ package backend
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/didyoumean"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 	"github.com/zclconf/go-cty/cty"
@@ -35,13 +37,33 @@ type UnparsedVariableValue interface {
 // declaration map along with detailed diagnostics about values of undeclared
 // variables being present, depending on the source of these values. If more
 // than two undeclared values are present in file form (config, auto, -var-file)
-// the remaining errors are summarized to avoid a massive list of errors.
-func ParseUndeclaredVariableValues(vv map[string]UnparsedVariableValue, decls map[string]*configs.Variable) (tofu.InputValues, tfdiags.Diagnostics) {
+// the remaining warnings are summarized to avoid a massive list of warnings.
+//
+// If strict is set then undeclared values from files are errors too, so that
+// a misspelled variable name can't be silently ignored. Values from
+// environment variables are always ignored, as those are often set globally
+// for many configurations.
+func ParseUndeclaredVariableValues(vv map[string]UnparsedVariableValue, decls map[string]*configs.Variable, strict bool) (tofu.InputValues, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	ret := make(tofu.InputValues, len(vv))
 	seenUndeclaredInFile := 0
 
-	for name, rv := range vv {
+	declNames := make([]string, 0, len(decls))
+	for name := range decls {
+		declNames = append(declNames, name)
+	}
+	sort.Strings(declNames)
+
+	// We visit the values in a predictable order so that the diagnostics,
+	// and which of them get summarized, are consistent between runs.
+	names := make([]string, 0, len(vv))
+	for name := range vv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rv := vv[name]
 		if _, declared := decls[name]; declared {
 			// Only interested in parsing undeclared variables
 			continue
@@ -54,18 +76,32 @@ func ParseUndeclaredVariableValues(vv map[string]UnparsedVariableValue, decls ma
 
 		ret[name] = val
 
+		var suggestion string
+		if suggested := didyoumean.NameSuggestion(name, declNames); suggested != "" {
+			suggestion = fmt.Sprintf(" Did you mean %q?", suggested)
+		}
+
 		switch val.SourceType {
 		case tofu.ValueFromConfig, tofu.ValueFromAutoFile, tofu.ValueFromNamedFile:
+			if strict {
+				diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Value for undeclared variable",
+					fmt.Sprintf("The root module does not declare a variable named %q but a value was found in file %q.%s\n\nThe -strict-vars option requires every value in a variables file to correspond to a declared variable. If you meant to use this value, add a \"variable\" block to the configuration, otherwise remove it from the file.", name, val.SourceRange.Filename, suggestion),
+				), tfdiags.RuleUndeclaredVariableValue))
+				continue
+			}
+
 			// We allow undeclared names for variable values from files and warn in case
 			// users have forgotten a variable {} declaration or have a typo in their var name.
 			// Some users will actively ignore this warning because they use a .tfvars file
 			// across multiple configurations.
 			if seenUndeclaredInFile < 2 {
-				diags = diags.Append(tfdiags.Sourceless(
+				diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
 					tfdiags.Warning,
 					"Value for undeclared variable",
-					fmt.Sprintf("The root module does not declare a variable named %q but a value was found in file %q. If you meant to use this value, add a \"variable\" block to the configuration.\n\nTo silence these warnings, use TF_VAR_... environment variables to provide certain \"global\" settings to all configurations in your organization. To reduce the verbosity of these warnings, use the -compact-warnings option.", name, val.SourceRange.Filename),
-				))
+					fmt.Sprintf("The root module does not declare a variable named %q but a value was found in file %q.%s If you meant to use this value, add a \"variable\" block to the configuration.\n\nTo silence these warnings, use TF_VAR_... environment variables to provide certain \"global\" settings to all configurations in your organization. To reduce the verbosity of these warnings, use the -compact-warnings option. To report these values as errors instead, use the -strict-vars option.", name, val.SourceRange.Filename, suggestion),
+				), tfdiags.RuleUndeclaredVariableValue))
 			}
 			seenUndeclaredInFile++
 
@@ -75,19 +111,19 @@ func ParseUndeclaredVariableValues(vv map[string]UnparsedVariableValue, decls ma
 			// when they are used across many (but not necessarily all)
 			// configurations.
 		case tofu.ValueFromCLIArg:
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Value for undeclared variable",
-				fmt.Sprintf("A variable named %q was assigned on the command line, but the root module does not declare a variable of that name. To use this value, add a \"variable\" block to the configuration.", name),
-			))
+				fmt.Sprintf("A variable named %q was assigned on the command line, but the root module does not declare a variable of that name.%s To use this value, add a \"variable\" block to the configuration.", name, suggestion),
+			), tfdiags.RuleUndeclaredVariableValue))
 		default:
 			// For all other source types we are more vague, but other situations
 			// don't generally crop up at this layer in practice.
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Value for undeclared variable",
-				fmt.Sprintf("A variable named %q was assigned a value, but the root module does not declare a variable of that name. To use this value, add a \"variable\" block to the configuration.", name),
-			))
+				fmt.Sprintf("A variable named %q was assigned a value, but the root module does not declare a variable of that name.%s To use this value, add a \"variable\" block to the configuration.", name, suggestion),
+			), tfdiags.RuleUndeclaredVariableValue))
 		}
 	}
 
@@ -155,15 +191,19 @@ func isDefinedAny(name string, maps ...tofu.InputValues) bool {
 // are not included in the map, ParseVariableValues will either substitute
 // a configured default value or produce an error.
 //
+// If strict is set then values for undeclared variables from files are
+// reported as errors rather than warnings, as described for
+// ParseUndeclaredVariableValues.
+//
 // If this function returns without any errors in the diagnostics, the
 // resulting input values map is guaranteed to be valid and ready to pass
 // to tofu.NewContext. If the diagnostics contains errors, the returned
 // InputValues may be incomplete but will include the subset of variables
 // that were successfully processed, allowing for careful analysis of the
 // partial result.
-func ParseVariableValues(vv map[string]UnparsedVariableValue, decls map[string]*configs.Variable) (tofu.InputValues, tfdiags.Diagnostics) {
+func ParseVariableValues(vv map[string]UnparsedVariableValue, decls map[string]*configs.Variable, strict bool) (tofu.InputValues, tfdiags.Diagnostics) {
 	ret, diags := ParseDeclaredVariableValues(vv, decls)
-	undeclared, diagsUndeclared := ParseUndeclaredVariableValues(vv, decls)
+	undeclared, diagsUndeclared := ParseUndeclaredVariableValues(vv, decls, strict)
 
 	diags = diags.Append(diagsUndeclared)
 
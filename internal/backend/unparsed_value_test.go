@@ -92,7 +92,7 @@ func TestUnparsedValue(t *testing.T) {
 	})
 
 	t.Run("ParseUndeclaredVariableValues", func(t *testing.T) {
-		gotVals, diags := ParseUndeclaredVariableValues(vv, decls)
+		gotVals, diags := ParseUndeclaredVariableValues(vv, decls, false)
 
 		if got, want := len(diags), 3; got != want {
 			t.Fatalf("wrong number of diagnostics %d; want %d", got, want)
@@ -163,7 +163,7 @@ func TestUnparsedValue(t *testing.T) {
 	})
 
 	t.Run("ParseVariableValues", func(t *testing.T) {
-		gotVals, diags := ParseVariableValues(vv, decls)
+		gotVals, diags := ParseVariableValues(vv, decls, false)
 		for _, diag := range diags {
 			t.Logf("%s: %s", diag.Description().Summary, diag.Description().Detail)
 		}
@@ -224,6 +224,69 @@ func TestUnparsedValue(t *testing.T) {
 	})
 }
 
+func TestParseUndeclaredVariableValues_strict(t *testing.T) {
+	vv := map[string]UnparsedVariableValue{
+		"undeclared0": testUnparsedVariableValue("0"),
+		"undeclared1": testUnparsedVariableValue("1"),
+		"undeclared2": testUnparsedVariableValue("2"),
+		"regoin":      testUnparsedVariableValue("3"),
+	}
+	decls := map[string]*configs.Variable{
+		"region": {
+			Name:           "region",
+			Type:           cty.String,
+			ConstraintType: cty.String,
+			ParsingMode:    configs.VariableParseLiteral,
+		},
+	}
+
+	_, diags := ParseUndeclaredVariableValues(vv, decls, true)
+
+	// Every undeclared value is reported as an error, without summarizing.
+	if got, want := len(diags), 4; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d", got, want)
+	}
+	for _, diag := range diags {
+		if got, want := diag.Severity(), tfdiags.Error; got != want {
+			t.Errorf("wrong severity for %q: got %s, want %s", diag.Description().Detail, got, want)
+		}
+	}
+
+	// The values are visited in name order, so the misspelled one is first.
+	if got, want := diags[0].Description().Detail, `The root module does not declare a variable named "regoin" but a value was found in file "fake.tfvars". Did you mean "region"?`; !strings.HasPrefix(got, want) {
+		t.Errorf("wrong detail\ngot:  %s\nwant prefix: %s", got, want)
+	}
+	if got := diags[1].Description().Detail; strings.Contains(got, "Did you mean") {
+		t.Errorf("unexpected suggestion for a dissimilar name: %s", got)
+	}
+}
+
+func TestParseUndeclaredVariableValues_suggestion(t *testing.T) {
+	vv := map[string]UnparsedVariableValue{
+		"regoin": testUnparsedVariableValue("0"),
+	}
+	decls := map[string]*configs.Variable{
+		"region": {
+			Name:           "region",
+			Type:           cty.String,
+			ConstraintType: cty.String,
+			ParsingMode:    configs.VariableParseLiteral,
+		},
+	}
+
+	// Without strict mode, the suggestion is still included in the warning.
+	_, diags := ParseUndeclaredVariableValues(vv, decls, false)
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d", got, want)
+	}
+	if got, want := diags[0].Severity(), tfdiags.Warning; got != want {
+		t.Errorf("wrong severity: got %s, want %s", got, want)
+	}
+	if got, want := diags[0].Description().Detail, `Did you mean "region"?`; !strings.Contains(got, want) {
+		t.Errorf("wrong detail\ngot:  %s\nmust contain: %s", got, want)
+	}
+}
+
 type testUnparsedVariableValue string
 
 func (v testUnparsedVariableValue) ParseVariableValue(mode configs.VariableParsingMode) (*tofu.InputValue, tfdiags.Diagnostics) {
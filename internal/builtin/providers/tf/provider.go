@@ -43,7 +43,8 @@ func (p *Provider) GetProviderSchema() providers.GetProviderSchemaResponse {
 			"terraform_remote_state": dataSourceRemoteStateGetSchema(),
 		},
 		ResourceTypes: map[string]providers.Schema{
-			"terraform_data": dataStoreResourceSchema(),
+			"terraform_data":      dataStoreResourceSchema(),
+			"terraform_workspace": workspaceResourceSchema(),
 		},
 		Functions: p.getFunctionSpecs(),
 	}
@@ -131,25 +132,34 @@ func (p *Provider) Stop() error {
 }
 
 // All the Resource-specific functions are below.
-// The terraform provider supplies a single data source, `terraform_remote_state`
-// and no resources.
+// The terraform provider supplies a single data source, `terraform_remote_state`,
+// and the `terraform_data` and `terraform_workspace` resources.
 
 // UpgradeResourceState is called when the state loader encounters an
 // instance state whose schema version is less than the one reported by the
 // currently-used version of the corresponding provider, and the upgraded
 // result is used for any further processing.
 func (p *Provider) UpgradeResourceState(req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	if req.TypeName == "terraform_workspace" {
+		return upgradeWorkspaceResourceState(req)
+	}
 	return upgradeDataStoreResourceState(req)
 }
 
 // ReadResource refreshes a resource and returns its current state.
 func (p *Provider) ReadResource(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	if req.TypeName == "terraform_workspace" {
+		return readWorkspaceResourceState(req)
+	}
 	return readDataStoreResourceState(req)
 }
 
 // PlanResourceChange takes the current state and proposed state of a
 // resource, and returns the planned final state.
 func (p *Provider) PlanResourceChange(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	if req.TypeName == "terraform_workspace" {
+		return planWorkspaceResourceChange(req)
+	}
 	return planDataStoreResourceChange(req)
 }
 
@@ -157,13 +167,19 @@ func (p *Provider) PlanResourceChange(req providers.PlanResourceChangeRequest) p
 // yet contain unknown computed values, and applies the changes returning
 // the final state.
 func (p *Provider) ApplyResourceChange(req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	if req.TypeName == "terraform_workspace" {
+		return applyWorkspaceResourceChange(req)
+	}
 	return applyDataStoreResourceChange(req)
 }
 
 // ImportResourceState requests that the given resource be imported.
 func (p *Provider) ImportResourceState(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
-	if req.TypeName == "terraform_data" {
+	switch req.TypeName {
+	case "terraform_data":
 		return importDataStore(req)
+	case "terraform_workspace":
+		return importWorkspace(req)
 	}
 
 	panic("unimplemented - terraform_remote_state has no resources")
@@ -171,6 +187,9 @@ func (p *Provider) ImportResourceState(req providers.ImportResourceStateRequest)
 
 // ValidateResourceConfig is used to to validate the resource configuration values.
 func (p *Provider) ValidateResourceConfig(req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	if req.TypeName == "terraform_workspace" {
+		return validateWorkspaceResourceConfig(req)
+	}
 	return validateDataStoreResourceConfig(req)
 }
 
@@ -194,6 +213,46 @@ func (p *Provider) CallFunction(r providers.CallFunctionRequest) providers.CallF
 	}
 }
 
+// ValidateEphemeralResourceConfig is never called for this provider, since it
+// has no ephemeral resource types.
+func (p *Provider) ValidateEphemeralResourceConfig(req providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse {
+	var res providers.ValidateEphemeralResourceConfigResponse
+	res.Diagnostics = res.Diagnostics.Append(fmt.Errorf("unsupported ephemeral resource %s", req.TypeName))
+	return res
+}
+
+// OpenEphemeralResource is never called for this provider, since it has no
+// ephemeral resource types.
+func (p *Provider) OpenEphemeralResource(req providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	var res providers.OpenEphemeralResourceResponse
+	res.Diagnostics = res.Diagnostics.Append(fmt.Errorf("unsupported ephemeral resource %s", req.TypeName))
+	return res
+}
+
+// RenewEphemeralResource is never called for this provider, since it has no
+// ephemeral resource types.
+func (p *Provider) RenewEphemeralResource(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	return providers.RenewEphemeralResourceResponse{}
+}
+
+// CloseEphemeralResource is never called for this provider, since it has no
+// ephemeral resource types.
+func (p *Provider) CloseEphemeralResource(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	return providers.CloseEphemeralResourceResponse{}
+}
+
+// CheckResourceQuotas is never called for this provider, since its resource
+// types have no quotas.
+func (p *Provider) CheckResourceQuotas(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+	return providers.CheckResourceQuotasResponse{}
+}
+
+// ListResources is never called for this provider, since it doesn't declare
+// the ListResources server capability.
+func (p *Provider) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	return providers.ListResourcesResponse{}
+}
+
 // Close is a noop for this provider, since it's run in-process.
 func (p *Provider) Close() error {
 	return nil
@@ -0,0 +1,302 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+func workspaceResourceSchema() providers.Schema {
+	return providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"backend": {
+					Type:            cty.String,
+					Description:     "The backend that stores the workspace, e.g. `s3` or `pg`.",
+					DescriptionKind: configschema.StringMarkdown,
+					Required:        true,
+				},
+				"config": {
+					Type: cty.DynamicPseudoType,
+					Description: "The configuration of the backend.\n\n" +
+						"The object can use any arguments that would be valid " +
+						"in the equivalent `terraform { backend \"<TYPE>\" { ... } }` " +
+						"block.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+				},
+				"workspace": {
+					Type: cty.String,
+					Description: "The OpenTofu workspace to create. Defaults " +
+						"to the `default` workspace.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+				},
+				"force_destroy": {
+					Type: cty.Bool,
+					Description: "Whether to delete the workspace when it is " +
+						"destroyed even if its state still tracks resources.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+				},
+				"id": {Type: cty.String, Computed: true},
+			},
+		},
+	}
+}
+
+func validateWorkspaceResourceConfig(req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+	if req.Config.IsNull() {
+		return resp
+	}
+
+	if !req.Config.GetAttr("id").IsNull() {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf(`"id" attribute is read-only`))
+	}
+
+	// Getting the backend implicitly validates the configuration for it,
+	// but we can only do that if it's all known already.
+	if req.Config.GetAttr("config").IsWhollyKnown() && req.Config.GetAttr("backend").IsKnown() {
+		_, _, diags := getBackend(req.Config, nil)
+		resp.Diagnostics = resp.Diagnostics.Append(diags)
+	} else {
+		configTy := req.Config.GetAttr("config").Type()
+		if configTy != cty.DynamicPseudoType && !(configTy.IsObjectType() || configTy.IsMapType()) {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Invalid backend configuration",
+				"The configuration must be an object value.",
+				cty.GetAttrPath("config"),
+			))
+		}
+	}
+
+	if workspace := req.Config.GetAttr("workspace"); workspace.IsKnown() && !workspace.IsNull() && workspace.AsString() == "" {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Invalid workspace name",
+			"The workspace name must not be empty.",
+			cty.GetAttrPath("workspace"),
+		))
+	}
+	return resp
+}
+
+func upgradeWorkspaceResourceState(req providers.UpgradeResourceStateRequest) (resp providers.UpgradeResourceStateResponse) {
+	ty := workspaceResourceSchema().Block.ImpliedType()
+	val, err := ctyjson.Unmarshal(req.RawStateJSON, ty)
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(err)
+		return resp
+	}
+
+	resp.UpgradedState = val
+	return resp
+}
+
+// readWorkspaceResourceState checks that the workspace still exists in the
+// backend, so that OpenTofu plans to create it again if it was deleted.
+func readWorkspaceResourceState(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+	resp.NewState = req.PriorState
+	if req.PriorState.IsNull() {
+		return resp
+	}
+
+	b, diags := configureWorkspaceBackend(req.PriorState)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	if diags.HasErrors() {
+		return resp
+	}
+
+	name := workspaceResourceName(req.PriorState)
+	workspaces, err := b.Workspaces()
+	if err == backend.ErrWorkspacesNotSupported {
+		return resp
+	}
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Failed to list workspaces",
+			fmt.Sprintf("Failed to list the workspaces in the backend: %s.", err),
+			cty.GetAttrPath("backend"),
+		))
+		return resp
+	}
+	for _, workspace := range workspaces {
+		if workspace == name {
+			return resp
+		}
+	}
+
+	log.Printf("[DEBUG] workspace %q no longer exists in the %s backend", name, req.PriorState.GetAttr("backend").AsString())
+	resp.NewState = cty.NullVal(req.PriorState.Type())
+	return resp
+}
+
+func planWorkspaceResourceChange(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+	if req.ProposedNewState.IsNull() {
+		// destroy op
+		resp.PlannedState = req.ProposedNewState
+		return resp
+	}
+
+	planned := req.ProposedNewState.AsValueMap()
+
+	if !req.PriorState.IsNull() {
+		// Any change to where the workspace is stored means it's a different
+		// workspace.
+		for _, attr := range []string{"backend", "config", "workspace"} {
+			if !req.PriorState.GetAttr(attr).RawEquals(req.ProposedNewState.GetAttr(attr)) {
+				resp.RequiresReplace = append(resp.RequiresReplace, cty.GetAttrPath(attr))
+			}
+		}
+		if len(resp.RequiresReplace) == 0 {
+			resp.PlannedState = cty.ObjectVal(planned)
+			return resp
+		}
+	}
+
+	if workspace := req.ProposedNewState.GetAttr("workspace"); workspace.IsKnown() {
+		planned["id"] = cty.StringVal(workspaceResourceName(req.ProposedNewState))
+	} else {
+		planned["id"] = cty.UnknownVal(cty.String).RefineNotNull()
+	}
+
+	resp.PlannedState = cty.ObjectVal(planned)
+	return resp
+}
+
+func applyWorkspaceResourceChange(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+	if req.PlannedState.IsNull() {
+		resp.NewState = req.PlannedState
+		resp.Diagnostics = deleteWorkspace(req.PriorState)
+		return resp
+	}
+
+	b, diags := configureWorkspaceBackend(req.PlannedState)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	if diags.HasErrors() {
+		return resp
+	}
+
+	// Getting the state manager for a workspace creates whatever the backend
+	// stores for it, the same way "tofu workspace new" does.
+	name := workspaceResourceName(req.PlannedState)
+	if _, err := b.StateMgr(name); err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Failed to create workspace",
+			fmt.Sprintf("Failed to create the workspace %q in the backend: %s.", name, err),
+			cty.GetAttrPath("workspace"),
+		))
+		return resp
+	}
+
+	newState := req.PlannedState.AsValueMap()
+	newState["id"] = cty.StringVal(name)
+	resp.NewState = cty.ObjectVal(newState)
+	return resp
+}
+
+// deleteWorkspace deletes the workspace described by the given resource
+// state. The default workspace can't be deleted, so destroying it only
+// removes it from the state of the configuration that manages it.
+func deleteWorkspace(prior cty.Value) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	name := workspaceResourceName(prior)
+	if name == backend.DefaultStateName {
+		log.Printf("[DEBUG] not deleting the default workspace")
+		return diags
+	}
+
+	b, moreDiags := configureWorkspaceBackend(prior)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	force := prior.GetAttr("force_destroy")
+	forceDestroy := !force.IsNull() && force.True()
+	if !forceDestroy {
+		stateMgr, err := b.StateMgr(name)
+		if err == nil {
+			err = stateMgr.RefreshState()
+		}
+		if err != nil {
+			diags = diags.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Failed to read workspace state",
+				fmt.Sprintf("Failed to check whether the workspace %q is empty before deleting it: %s.\n\nTo delete the workspace without checking, set force_destroy to true.", name, err),
+				cty.GetAttrPath("workspace"),
+			))
+			return diags
+		}
+		if state := stateMgr.State(); state != nil && state.HasManagedResourceInstanceObjects() {
+			diags = diags.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Workspace is not empty",
+				fmt.Sprintf("The workspace %q is still tracking resource instances, so deleting it would cause OpenTofu to lose track of them. Destroy these objects with OpenTofu before deleting the workspace, or set force_destroy to true to delete it anyway.", name),
+				cty.GetAttrPath("workspace"),
+			))
+			return diags
+		}
+	}
+
+	if err := b.DeleteWorkspace(name, forceDestroy); err != nil {
+		diags = diags.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Failed to delete workspace",
+			fmt.Sprintf("Failed to delete the workspace %q from the backend: %s.", name, err),
+			cty.GetAttrPath("workspace"),
+		))
+	}
+	return diags
+}
+
+func importWorkspace(req providers.ImportResourceStateRequest) (resp providers.ImportResourceStateResponse) {
+	resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Import not supported",
+		"The terraform_workspace resource type doesn't support import, because the import ID can't describe the backend configuration. Declare the resource instead, and OpenTofu will adopt an existing workspace when it creates it.",
+	))
+	return resp
+}
+
+// configureWorkspaceBackend returns the configured backend for the given
+// terraform_workspace object.
+//
+// The provider doesn't have access to the state encryption configuration,
+// so it uses no encryption, which is fine for creating and deleting
+// workspaces but not for reading states that are encrypted.
+func configureWorkspaceBackend(obj cty.Value) (backend.Backend, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	b, cfg, moreDiags := getBackend(obj, encryption.StateEncryptionDisabled())
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	diags = diags.Append(b.Configure(cfg))
+	return b, diags
+}
+
+func workspaceResourceName(obj cty.Value) string {
+	if workspace := obj.GetAttr("workspace"); !workspace.IsNull() {
+		return workspace.AsString()
+	}
+	return backend.DefaultStateName
+}
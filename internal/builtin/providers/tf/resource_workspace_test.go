@@ -0,0 +1,215 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestManagedWorkspaceSchema(t *testing.T) {
+	if err := workspaceResourceSchema().Block.InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestManagedWorkspaceValidate(t *testing.T) {
+	cfg := map[string]cty.Value{
+		"backend": cty.StringVal("local"),
+		"config": cty.ObjectVal(map[string]cty.Value{
+			"workspace_dir": cty.StringVal("states"),
+		}),
+		"workspace":     cty.StringVal("prod"),
+		"force_destroy": cty.NullVal(cty.Bool),
+		"id":            cty.NullVal(cty.String),
+	}
+
+	req := providers.ValidateResourceConfigRequest{
+		TypeName: "terraform_workspace",
+		Config:   cty.ObjectVal(cfg),
+	}
+	resp := validateWorkspaceResourceConfig(req)
+	if resp.Diagnostics.HasErrors() {
+		t.Fatal("unexpected error:", resp.Diagnostics.ErrWithWarnings())
+	}
+
+	cfg["config"] = cty.ObjectVal(map[string]cty.Value{
+		"nonexist": cty.StringVal("foo"),
+	})
+	req.Config = cty.ObjectVal(cfg)
+	resp = validateWorkspaceResourceConfig(req)
+	if !resp.Diagnostics.HasErrors() {
+		t.Fatal("succeeded with an invalid backend configuration; want an error")
+	}
+	if got, want := resp.Diagnostics.Err().Error(), "Invalid backend configuration"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestManagedWorkspacePlan(t *testing.T) {
+	config := cty.ObjectVal(map[string]cty.Value{
+		"backend":       cty.StringVal("local"),
+		"config":        cty.NullVal(cty.DynamicPseudoType),
+		"workspace":     cty.StringVal("prod"),
+		"force_destroy": cty.NullVal(cty.Bool),
+		"id":            cty.NullVal(cty.String),
+	})
+	ty := workspaceResourceSchema().Block.ImpliedType()
+
+	// create
+	resp := planWorkspaceResourceChange(providers.PlanResourceChangeRequest{
+		TypeName:         "terraform_workspace",
+		PriorState:       cty.NullVal(ty),
+		ProposedNewState: config,
+	})
+	if resp.Diagnostics.HasErrors() {
+		t.Fatal(resp.Diagnostics.ErrWithWarnings())
+	}
+	if got, want := resp.PlannedState.GetAttr("id"), cty.StringVal("prod"); !got.RawEquals(want) {
+		t.Fatalf("wrong planned id %#v; want %#v", got, want)
+	}
+
+	// a different workspace requires replacement
+	prior := resp.PlannedState
+	proposed := config.AsValueMap()
+	proposed["id"] = cty.StringVal("prod")
+	proposed["workspace"] = cty.StringVal("staging")
+	resp = planWorkspaceResourceChange(providers.PlanResourceChangeRequest{
+		TypeName:         "terraform_workspace",
+		PriorState:       prior,
+		ProposedNewState: cty.ObjectVal(proposed),
+	})
+	if len(resp.RequiresReplace) != 1 || !resp.RequiresReplace[0].Equals(cty.GetAttrPath("workspace")) {
+		t.Fatalf("wrong RequiresReplace %#v", resp.RequiresReplace)
+	}
+	if got, want := resp.PlannedState.GetAttr("id"), cty.StringVal("staging"); !got.RawEquals(want) {
+		t.Fatalf("wrong planned id %#v; want %#v", got, want)
+	}
+
+	// force_destroy can change in place
+	proposed["workspace"] = cty.StringVal("prod")
+	proposed["force_destroy"] = cty.True
+	resp = planWorkspaceResourceChange(providers.PlanResourceChangeRequest{
+		TypeName:         "terraform_workspace",
+		PriorState:       prior,
+		ProposedNewState: cty.ObjectVal(proposed),
+	})
+	if len(resp.RequiresReplace) != 0 {
+		t.Fatalf("unexpected RequiresReplace %#v", resp.RequiresReplace)
+	}
+}
+
+func TestManagedWorkspaceLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	ty := workspaceResourceSchema().Block.ImpliedType()
+	planned := cty.ObjectVal(map[string]cty.Value{
+		"backend": cty.StringVal("local"),
+		"config": cty.ObjectVal(map[string]cty.Value{
+			"workspace_dir": cty.StringVal(dir),
+		}),
+		"workspace":     cty.StringVal("prod"),
+		"force_destroy": cty.NullVal(cty.Bool),
+		"id":            cty.StringVal("prod"),
+	})
+
+	applyResp := applyWorkspaceResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "terraform_workspace",
+		PriorState:   cty.NullVal(ty),
+		PlannedState: planned,
+	})
+	if applyResp.Diagnostics.HasErrors() {
+		t.Fatal(applyResp.Diagnostics.ErrWithWarnings())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "prod")); err != nil {
+		t.Fatalf("the workspace wasn't created: %s", err)
+	}
+	created := applyResp.NewState
+
+	readResp := readWorkspaceResourceState(providers.ReadResourceRequest{
+		TypeName:   "terraform_workspace",
+		PriorState: created,
+	})
+	if readResp.Diagnostics.HasErrors() {
+		t.Fatal(readResp.Diagnostics.ErrWithWarnings())
+	}
+	if !readResp.NewState.RawEquals(created) {
+		t.Fatalf("wrong state after refresh\ngot:  %#v\nwant: %#v", readResp.NewState, created)
+	}
+
+	// A workspace that tracks resources isn't deleted unless forced.
+	state := states.NewState()
+	state.RootModule().SetResourceInstanceCurrent(
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_thing",
+			Name: "foo",
+		}.Instance(addrs.NoKey),
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"foo"}`),
+		},
+		addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		},
+		addrs.NoKey,
+	)
+	f, err := os.Create(filepath.Join(dir, "prod", "terraform.tfstate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = statefile.Write(statefile.New(state, "lineage", 1), f, encryption.StateEncryptionDisabled())
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applyResp = applyWorkspaceResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "terraform_workspace",
+		PriorState:   created,
+		PlannedState: cty.NullVal(ty),
+	})
+	if !applyResp.Diagnostics.HasErrors() {
+		t.Fatal("deleted a workspace that isn't empty; want an error")
+	}
+	if got, want := applyResp.Diagnostics.Err().Error(), "Workspace is not empty"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	forced := created.AsValueMap()
+	forced["force_destroy"] = cty.True
+	applyResp = applyWorkspaceResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "terraform_workspace",
+		PriorState:   cty.ObjectVal(forced),
+		PlannedState: cty.NullVal(ty),
+	})
+	if applyResp.Diagnostics.HasErrors() {
+		t.Fatal(applyResp.Diagnostics.ErrWithWarnings())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "prod")); !os.IsNotExist(err) {
+		t.Fatalf("the workspace wasn't deleted: %v", err)
+	}
+
+	readResp = readWorkspaceResourceState(providers.ReadResourceRequest{
+		TypeName:   "terraform_workspace",
+		PriorState: created,
+	})
+	if readResp.Diagnostics.HasErrors() {
+		t.Fatal(readResp.Diagnostics.ErrWithWarnings())
+	}
+	if !readResp.NewState.IsNull() {
+		t.Fatalf("the deleted workspace still exists after refresh: %#v", readResp.NewState)
+	}
+}
@@ -87,6 +87,62 @@ func (b *Cloud) opPlan(stopCtx, cancelCtx context.Context, op *backend.Operation
 		))
 	}
 
+	if len(op.TargetGroups) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"-target-group option is not supported",
+			"The -target-group option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.TargetStrict {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"-target-strict option is not supported",
+			"The -target-strict option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.PlanMode == plans.DriftOnlyMode {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Drift-only mode is not supported",
+			"The -drift-only option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.ModuleCachePath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Reusing unchanged modules is not supported",
+			"The -reuse-unchanged-modules option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.Incremental {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incremental plans are not supported",
+			"The -incremental option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.StateVersion != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Planning against a state version is not supported",
+			"The -state-version option is not currently supported for remote plans.",
+		))
+	}
+
+	if op.ReviewDrift {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Reviewing drift is not supported",
+			"The -review-drift option is not currently supported for remote plans.",
+		))
+	}
+
 	if len(op.GenerateConfigOut) > 0 {
 		diags = diags.Append(genconfig.ValidateTargetFile(op.GenerateConfigOut))
 	}
@@ -26,7 +26,7 @@ func allowedSourceType(source tofu.ValueSourceType) bool {
 // considered errors because they may be defined within the cloud workspace.
 func ParseCloudRunVariables(vv map[string]backend.UnparsedVariableValue, decls map[string]*configs.Variable) (map[string]string, tfdiags.Diagnostics) {
 	declared, diags := backend.ParseDeclaredVariableValues(vv, decls)
-	_, undedeclaredDiags := backend.ParseUndeclaredVariableValues(vv, decls)
+	_, undedeclaredDiags := backend.ParseUndeclaredVariableValues(vv, decls, false)
 	diags = diags.Append(undedeclaredDiags)
 
 	ret := make(map[string]string, len(declared))
@@ -6,9 +6,13 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/opentofu/opentofu/internal/attestation"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
@@ -51,6 +55,7 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	}
 
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetCompactChanges(args.Compact)
 
 	// Instantiate the view, even if there are flag errors, so that we render
 	// diagnostics according to the desired view
@@ -111,6 +116,10 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.refreshConcurrency = args.Operation.RefreshConcurrency
+	c.Meta.tuneParallelism = args.TuneParallelism
+	c.Meta.allowedApplyActions = args.AllowOnly
+	c.Meta.allowProviderMismatch = args.AllowProviderMismatch
 
 	// Prepare the backend, passing the plan file if present, and the
 	// backend-specific arguments
@@ -122,7 +131,7 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	}
 
 	// Build the operation request
-	opReq, opDiags := c.OperationRequest(be, view, args.ViewType, planFile, args.Operation, args.AutoApprove, enc)
+	opReq, opDiags := c.OperationRequest(be, view, args.ViewType, planFile, args.Operation, args.AutoApprove, args.RetryFailed, enc)
 	diags = diags.Append(opDiags)
 
 	// Before we delegate to the backend, we'll print any warning diagnostics
@@ -134,8 +143,38 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	}
 	diags = nil
 
+	var stream *views.JSONStreamHook
+	if args.JSONStreamPath != "" {
+		f, err := os.Create(args.JSONStreamPath)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to create JSON stream file",
+				fmt.Sprintf("OpenTofu could not create the file for the -json-stream option: %s.", err),
+			))
+			view.Diagnostics(diags)
+			return 1
+		}
+		defer f.Close()
+		stream = views.NewJSONStreamHook(f)
+		opReq.Hooks = append(opReq.Hooks, stream)
+	}
+
 	// Run the operation
+	started := time.Now()
 	op, diags := c.RunOperation(ctx, be, opReq)
+	if args.Attestation != "" && op != nil {
+		diags = diags.Append(c.attestApply(ctx, args, opReq.Workspace, op, started))
+	}
+	if stream != nil {
+		if err := stream.End(!diags.HasErrors() && op.Result == backend.OperationSuccess); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to write JSON stream",
+				fmt.Sprintf("OpenTofu could not write all of the events to the file for the -json-stream option: %s.", err),
+			))
+		}
+	}
 	view.Diagnostics(diags)
 	if diags.HasErrors() {
 		return 1
@@ -207,6 +246,60 @@ func (c *ApplyCommand) LoadPlanFile(path string, enc encryption.Encryption) (*pl
 	return planFile, diags
 }
 
+// attestApply writes an attestation of the given apply operation, as
+// requested by the -attestation option. Failed operations are attested too,
+// so that the attestations are a complete record of changes.
+func (c *ApplyCommand) attestApply(ctx context.Context, args *arguments.Apply, workspace string, op *backend.RunningOperation, started time.Time) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if op.State == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Attestation not available",
+			"OpenTofu could not create an attestation of this apply, because the resulting state is not available locally, such as when the backend runs operations remotely.",
+		))
+		return diags
+	}
+	state, err := stateDescriptor(op.State)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to create attestation",
+			fmt.Sprintf("OpenTofu could not encode the resulting state for its attestation: %s.", err),
+		))
+		return diags
+	}
+
+	run := &attestation.Run{
+		Command: "apply",
+		Parameters: map[string]any{
+			"workspace": workspace,
+			"destroy":   c.Destroy,
+		},
+		Subjects: []attestation.ResourceDescriptor{state},
+		Byproducts: []attestation.ResourceDescriptor{
+			{
+				Name:        "result",
+				Annotations: map[string]any{"success": op.Result == backend.OperationSuccess},
+			},
+		},
+		StartedOn:  started,
+		FinishedOn: time.Now(),
+	}
+	if args.PlanPath != "" {
+		planFile, err := fileDescriptor("plan", args.PlanPath)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read saved plan",
+				fmt.Sprintf("OpenTofu could not read the applied plan to create the attestation: %s.", err),
+			))
+			return diags
+		}
+		run.Dependencies = append(run.Dependencies, planFile)
+	}
+	return diags.Append(c.writeAttestation(ctx, args.Attestation, args.AttestationKey, run))
+}
+
 func (c *ApplyCommand) PrepareBackend(planFile *planfile.WrappedPlanFile, args *arguments.State, viewType arguments.ViewType, enc encryption.StateEncryption) (backend.Enhanced, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -267,6 +360,7 @@ func (c *ApplyCommand) OperationRequest(
 	planFile *planfile.WrappedPlanFile,
 	args *arguments.Operation,
 	autoApprove bool,
+	retryFailed int,
 	enc encryption.Encryption,
 ) (*backend.Operation, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
@@ -279,6 +373,7 @@ func (c *ApplyCommand) OperationRequest(
 	// Build the operation
 	opReq := c.Operation(be, viewType, enc)
 	opReq.AutoApprove = autoApprove
+	opReq.RetryFailed = retryFailed
 	opReq.ConfigDir = "."
 	opReq.PlanMode = args.PlanMode
 	opReq.Hooks = view.Hooks()
@@ -286,9 +381,21 @@ func (c *ApplyCommand) OperationRequest(
 	opReq.PlanRefresh = args.Refresh
 	opReq.Targets = args.Targets
 	opReq.Excludes = args.Excludes
+	opReq.TargetGroups = args.TargetGroups
+	opReq.TargetStrict = args.TargetStrict
 	opReq.ForceReplace = args.ForceReplace
+	opReq.StrictVariables = args.StrictVariables
 	opReq.Type = backend.OperationTypeApply
 	opReq.View = view.Operation()
+	opReq.AllowProviderMismatch = c.allowProviderMismatch
+
+	// A saved plan must be applied with the same provider packages it was
+	// created with, so we need to know which ones we're about to use.
+	if planFile != nil && !planFile.IsCloud() {
+		var moreDiags tfdiags.Diagnostics
+		opReq.ProviderPackages, moreDiags = c.providerPackages()
+		diags = diags.Append(moreDiags)
+	}
 
 	var err error
 	opReq.ConfigLoader, err = c.initConfigLoader()
@@ -348,12 +455,32 @@ Usage: tofu [global options] apply [options] [PLAN]
 
 Options:
 
+  -allow-only=kinds      Refuse to apply the plan if it includes any changes
+                         other than the given comma-separated kinds: create,
+                         update, replace, delete, and forget.
+
+  -allow-provider-mismatch
+                         Apply a saved plan even if the installed provider
+                         packages differ from the ones it was created with,
+                         only warning about the differences.
+
+  -attestation=dest      Write a provenance attestation of the apply to the
+                         given file, or push it to an OCI registry if dest is
+                         an "oci://" reference.
+
+  -attestation-key=path  Sign the attestation with the PEM-encoded private key
+                         in the given file.
+
   -auto-approve          Skip interactive approval of plan before applying.
 
   -backup=path           Path to backup the existing state file before
                          modifying. Defaults to the "-state-out" path with
                          ".backup" extension. Set to "-" to disable backup.
 
+  -compact               Show the first of the identical changes to the
+                         instances of a resource in full, and summarize the
+                         others in a single line.
+
   -compact-warnings      If OpenTofu produces any warnings that are not
                          accompanied by errors, show them in a more compact
                          form that includes only the summary messages.
@@ -376,6 +503,12 @@ Options:
 
   -lock-timeout=0s       Duration to retry a state lock.
 
+  -merge-concurrent-state-writes
+                         Together with -lock=false, merge the changes that
+                         other operations write to disjoint resources in the
+                         remote state while this one is running, instead of
+                         overwriting them.
+
   -input=true            Ask for input for variables if not directly set.
 
   -no-color              If specified, output won't contain any color.
@@ -383,9 +516,22 @@ Options:
   -parallelism=n         Limit the number of parallel resource operations.
                          Defaults to 10.
 
+  -refresh-concurrency=n Limit the number of concurrent refresh requests to
+                         each provider configuration, instead of counting
+                         them against -parallelism.
+
+  -retry-failed=n        Plan and apply the resource instances whose changes
+                         fail to apply again, up to n times, once all of the
+                         other changes are applied. Defaults to 0.
+
   -state=path            Path to read and save state (unless state-out
                          is specified). Defaults to "terraform.tfstate".
 
+  -tune-parallelism      Adjust the number of concurrent changes applied by
+                         each provider configuration based on how long the
+                         provider takes to apply them, within -parallelism,
+                         and report the chosen values after applying.
+
   -state-out=path        Path to write state to that is different than
                          "-state". This can be used to preserve the old
                          state.
@@ -396,6 +542,11 @@ Options:
                          suitable for use in text editor integrations and 
                          other automated systems. Always disables color.
 
+  -json-stream=path      Write a stream of machine-readable JSON events
+                         describing the progress of each operation, with
+                         their IDs, relationships and timing, to the given
+                         file. Doesn't change the output of the command.
+
   If you don't provide a saved plan file then this command will also accept
   all of the plan-customization options accepted by the tofu plan command.
   For more information on those options, run:
@@ -70,6 +70,49 @@ func TestApply(t *testing.T) {
 		t.Fatal("state should not be nil")
 	}
 }
+
+func TestApply_jsonStream(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+
+	p := applyFixtureProvider()
+
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-auto-approve",
+		"-json-stream=progress.jsonl",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	src, err := os.ReadFile("progress.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(src)), "\n")
+	if !strings.Contains(lines[0], `"type":"stream_start"`) {
+		t.Errorf("wrong first event: %s", lines[0])
+	}
+	if last := lines[len(lines)-1]; !strings.Contains(last, `"type":"stream_end"`) || !strings.Contains(last, `"status":"complete"`) {
+		t.Errorf("wrong last event: %s", last)
+	}
+	if !strings.Contains(string(src), `"method":"ApplyResourceChange"`) {
+		t.Errorf("provider call missing from stream:\n%s", src)
+	}
+}
+
 func TestApply_conditionalSensitive(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -824,6 +867,7 @@ func TestApply_plan_remoteState(t *testing.T) {
 	backendConfig := cty.ObjectVal(map[string]cty.Value{
 		"address":                   cty.StringVal(srv.URL),
 		"update_method":             cty.NullVal(cty.String),
+		"lock_mode":                 cty.NullVal(cty.String),
 		"lock_address":              cty.NullVal(cty.String),
 		"unlock_address":            cty.NullVal(cty.String),
 		"lock_method":               cty.NullVal(cty.String),
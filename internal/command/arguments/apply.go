@@ -7,6 +7,7 @@ package arguments
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -29,11 +30,43 @@ type Apply struct {
 	// PlanPath contains an optional path to a stored plan file
 	PlanPath string
 
+	// JSONStreamPath is an optional path to write the JSON progress stream
+	// to.
+	JSONStreamPath string
+
 	// ViewType specifies which output format to use
 	ViewType ViewType
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// RetryFailed is the number of times to retry the resource instances
+	// whose changes fail to apply, once all of the other changes are applied.
+	RetryFailed int
+
+	// TuneParallelism adjusts the number of concurrent apply requests to
+	// each provider configuration based on how long they take, within the
+	// overall parallelism.
+	TuneParallelism bool
+
+	// Compact groups the changes to instances of the same resource that are
+	// identical when rendering the plan.
+	Compact bool
+
+	// Attestation is an optional file path or OCI reference to write a
+	// provenance attestation of the apply run to, and AttestationKey is the
+	// path of the private key to sign it with.
+	Attestation    string
+	AttestationKey string
+
+	// AllowOnly, if not empty, is the set of resource instance change
+	// actions that the apply is allowed to take, from the -allow-only option.
+	AllowOnly []plans.Action
+
+	// AllowProviderMismatch allows applying a saved plan with different
+	// provider packages than it was created with, reporting the differences
+	// as warnings instead of errors.
+	AllowProviderMismatch bool
 }
 
 // ParseApply processes CLI arguments, returning an Apply value and errors.
@@ -50,7 +83,18 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 	cmdFlags := extendedFlagSet("apply", apply.State, apply.Operation, apply.Vars)
 	cmdFlags.BoolVar(&apply.AutoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.BoolVar(&apply.InputEnabled, "input", true, "input")
+	cmdFlags.StringVar(&apply.JSONStreamPath, "json-stream", "", "json-stream")
 	cmdFlags.BoolVar(&apply.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.IntVar(&apply.RetryFailed, "retry-failed", 0, "retry-failed")
+	cmdFlags.BoolVar(&apply.Compact, "compact", false, "compact")
+	cmdFlags.BoolVar(&apply.TuneParallelism, "tune-parallelism", false, "tune-parallelism")
+	cmdFlags.StringVar(&apply.Attestation, "attestation", "", "attestation")
+	cmdFlags.StringVar(&apply.AttestationKey, "attestation-key", "", "attestation-key")
+	cmdFlags.BoolVar(&apply.AllowProviderMismatch, "allow-provider-mismatch", false, "allow-provider-mismatch")
+	cmdFlags.BoolVar(&apply.State.MergeConcurrentWrites, "merge-concurrent-state-writes", false, "merge-concurrent-state-writes")
+
+	var allowOnly string
+	cmdFlags.StringVar(&allowOnly, "allow-only", "", "allow-only")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -77,6 +121,44 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 		))
 	}
 
+	if apply.RetryFailed < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid retry count",
+			"The -retry-failed option must be zero or a positive number of retries.",
+		))
+	}
+
+	if apply.AttestationKey != "" && apply.Attestation == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid attestation options",
+			"The -attestation-key option is only valid together with -attestation.",
+		))
+	}
+
+	if apply.AllowProviderMismatch && apply.PlanPath == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid provider mismatch option",
+			"The -allow-provider-mismatch option is only valid when applying a saved plan file.",
+		))
+	}
+
+	if apply.State.MergeConcurrentWrites && apply.State.Lock {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid state merging option",
+			"The -merge-concurrent-state-writes option is only valid together with -lock=false, because other operations can't write to a locked state.",
+		))
+	}
+
+	if allowOnly != "" {
+		var moreDiags tfdiags.Diagnostics
+		apply.AllowOnly, moreDiags = parseAllowOnly(allowOnly)
+		diags = diags.Append(moreDiags)
+	}
+
 	// JSON view currently does not support input, so we disable it here.
 	if json {
 		apply.InputEnabled = false
@@ -94,6 +176,13 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 	}
 
 	diags = diags.Append(apply.Operation.Parse())
+	if apply.Operation.PlanMode == plans.DriftOnlyMode {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid mode option",
+			"The -drift-only option is valid only for \"tofu plan\", because a drift-only plan cannot be applied.",
+		))
+	}
 
 	switch {
 	case json:
@@ -105,6 +194,37 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 	return apply, diags
 }
 
+// allowOnlyActions maps each kind of change accepted by the -allow-only
+// option to the plan actions it allows.
+var allowOnlyActions = map[string][]plans.Action{
+	"create":  {plans.Create},
+	"update":  {plans.Update},
+	"replace": {plans.DeleteThenCreate, plans.CreateThenDelete},
+	"delete":  {plans.Delete},
+	"forget":  {plans.Forget},
+}
+
+// parseAllowOnly parses the comma-separated list of kinds of change given
+// in the -allow-only option.
+func parseAllowOnly(raw string) ([]plans.Action, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var actions []plans.Action
+	for _, kind := range strings.Split(raw, ",") {
+		kind = strings.TrimSpace(kind)
+		allowed, ok := allowOnlyActions[kind]
+		if !ok {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid -allow-only option",
+				fmt.Sprintf("Unsupported kind of change %q. The -allow-only option takes a comma-separated list of the kinds create, update, replace, delete, and forget.", kind),
+			))
+			continue
+		}
+		actions = append(actions, allowed...)
+	}
+	return actions, diags
+}
+
 // ParseApplyDestroy is a special case of ParseApply that deals with the
 // "tofu destroy" command, which is effectively an alias for
 // "tofu apply -destroy".
@@ -135,6 +255,8 @@ func ParseApplyDestroy(args []string) (*Apply, tfdiags.Diagnostics) {
 			"Invalid mode option",
 			"The -refresh-only option is not valid for \"tofu destroy\".",
 		))
+	case plans.DriftOnlyMode:
+		// ParseApply already rejected this mode.
 	default:
 		// This is a non-ideal error message for if we forget to handle a
 		// newly-handled plan mode in Operation.Parse. Ideally they should all
@@ -70,6 +70,23 @@ func TestParseApply_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"JSON progress stream": {
+			[]string{"-json-stream=progress.jsonl"},
+			&Apply{
+				AutoApprove:    false,
+				InputEnabled:   true,
+				PlanPath:       "",
+				JSONStreamPath: "progress.jsonl",
+				ViewType:       ViewHuman,
+				State:          &State{Lock: true},
+				Vars:           &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
 		"JSON view disables input": {
 			[]string{"-json", "-auto-approve"},
 			&Apply{
@@ -156,6 +173,90 @@ func TestParseApply_invalid(t *testing.T) {
 	}
 }
 
+func TestParseApply_driftOnly(t *testing.T) {
+	_, diags := ParseApply([]string{"-drift-only"})
+	if len(diags) == 0 {
+		t.Fatal("expected diags but got none")
+	}
+	if got, want := diags.Err().Error(), "valid only for \"tofu plan\""; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_retryFailed(t *testing.T) {
+	got, diags := ParseApply([]string{"-retry-failed=2"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.RetryFailed != 2 {
+		t.Fatalf("wrong retry count, got %d, want 2", got.RetryFailed)
+	}
+
+	_, diags = ParseApply([]string{"-retry-failed=-1"})
+	if got, want := diags.Err().Error(), "Invalid retry count"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_tuneParallelism(t *testing.T) {
+	got, diags := ParseApply([]string{"-tune-parallelism", "-parallelism=20"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.TuneParallelism {
+		t.Fatalf("TuneParallelism not set")
+	}
+	if got.Operation.Parallelism != 20 {
+		t.Fatalf("wrong parallelism, got %d, want 20", got.Operation.Parallelism)
+	}
+}
+
+func TestParseApply_allowOnly(t *testing.T) {
+	got, diags := ParseApply([]string{"-allow-only=create, update,replace"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	want := []plans.Action{plans.Create, plans.Update, plans.DeleteThenCreate, plans.CreateThenDelete}
+	if diff := cmp.Diff(want, got.AllowOnly); diff != "" {
+		t.Fatalf("wrong actions\n%s", diff)
+	}
+
+	_, diags = ParseApply([]string{"-allow-only=create,destroy"})
+	if got, want := diags.Err().Error(), `Unsupported kind of change "destroy"`; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_allowProviderMismatch(t *testing.T) {
+	got, diags := ParseApply([]string{"-allow-provider-mismatch", "saved.tfplan"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.AllowProviderMismatch {
+		t.Fatalf("AllowProviderMismatch not set")
+	}
+
+	_, diags = ParseApply([]string{"-allow-provider-mismatch"})
+	if got, want := diags.Err().Error(), "Invalid provider mismatch option"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_mergeConcurrentStateWrites(t *testing.T) {
+	got, diags := ParseApply([]string{"-lock=false", "-merge-concurrent-state-writes"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.State.MergeConcurrentWrites {
+		t.Fatalf("MergeConcurrentWrites not set")
+	}
+
+	_, diags = ParseApply([]string{"-merge-concurrent-state-writes"})
+	if got, want := diags.Err().Error(), "Invalid state merging option"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
 func TestParseApply_tooManyArguments(t *testing.T) {
 	got, diags := ParseApply([]string{"saved.tfplan", "please"})
 	if len(diags) == 0 {
@@ -33,6 +33,11 @@ type State struct {
 	// The default is 0, meaning no limit.
 	LockTimeout time.Duration
 
+	// MergeConcurrentWrites asks remote state backends to merge the changes
+	// that other operations write while this one is running, rather than
+	// overwriting them. It's only valid when Lock is false.
+	MergeConcurrentWrites bool
+
 	// StatePath specifies a non-default location for the state file. The
 	// default value is blank, which is interpreted as "terraform.tfstate".
 	StatePath string
@@ -61,6 +66,12 @@ type Operation struct {
 	// as it walks the dependency graph.
 	Parallelism int
 
+	// RefreshConcurrency, if greater than zero, is the limit OpenTofu places
+	// on concurrent refresh requests to each provider configuration. Refresh
+	// requests are then no longer counted against Parallelism, so that
+	// requests to independent providers don't compete with one another.
+	RefreshConcurrency int
+
 	// Refresh controls whether or not the operation should refresh existing
 	// state before proceeding. Default is true.
 	Refresh bool
@@ -73,6 +84,16 @@ type Operation struct {
 	// than a set of excluded resource addresses and resources dependent on them.
 	Excludes []addrs.Targetable
 
+	// TargetGroups allow limiting an operation to the resources whose
+	// lifecycle blocks assign them to any of these groups, and their
+	// dependencies.
+	TargetGroups []string
+
+	// TargetStrict requests that an operation with targets fails if the
+	// targeted resources need changes to resources that aren't targeted,
+	// instead of also planning those changes.
+	TargetStrict bool
+
 	// ForceReplace addresses cause OpenTofu to force a particular set of
 	// resource instances to generate "replace" actions in any plan where they
 	// would normally have generated "no-op" or "update" actions.
@@ -85,6 +106,10 @@ type Operation struct {
 	// learn a use-case for broader matching.
 	ForceReplace []addrs.AbsResourceInstance
 
+	// StrictVariables requests that values for undeclared variables found in
+	// variables files are reported as errors, rather than as warnings.
+	StrictVariables bool
+
 	// These private fields are used only temporarily during decoding. Use
 	// method Parse to populate the exported fields from these, validating
 	// the raw values in the process.
@@ -93,6 +118,7 @@ type Operation struct {
 	forceReplaceRaw []string
 	destroyRaw      bool
 	refreshOnlyRaw  bool
+	driftOnlyRaw    bool
 }
 
 // parseTargetables gets a list of strings, each representing a targetable object, and returns a list of
@@ -162,6 +188,30 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 	o.Targets, o.Excludes, parseDiags = parseRawTargetsAndExcludes(o.targetsRaw, o.excludesRaw)
 	diags = diags.Append(parseDiags)
 
+	if len(o.TargetGroups) > 0 && len(o.excludesRaw) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid combination of arguments",
+			"-target-group and -exclude flags cannot be used together. Please remove one of the flags",
+		))
+	}
+	if o.TargetStrict && len(o.targetsRaw) == 0 && len(o.TargetGroups) == 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid combination of arguments",
+			"The -target-strict flag can only be used together with -target or -target-group.",
+		))
+	}
+	for _, group := range o.TargetGroups {
+		if !hclsyntax.ValidIdentifier(group) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid target-group %q", group),
+				"A group name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.",
+			))
+		}
+	}
+
 	for _, raw := range o.forceReplaceRaw {
 		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
 		if syntaxDiags.HasErrors() {
@@ -195,6 +245,14 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 		o.ForceReplace = append(o.ForceReplace, addr)
 	}
 
+	if o.RefreshConcurrency < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid refresh concurrency",
+			fmt.Sprintf("The -refresh-concurrency option must be a positive number, not %d.", o.RefreshConcurrency),
+		))
+	}
+
 	// If you add a new possible value for o.PlanMode here, consider also
 	// adding a specialized error message for it in ParseApplyDestroy.
 	switch {
@@ -204,6 +262,21 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 			"Incompatible plan mode options",
 			"The -destroy and -refresh-only options are mutually-exclusive.",
 		))
+	case o.driftOnlyRaw && (o.destroyRaw || o.refreshOnlyRaw):
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan mode options",
+			"The -drift-only option cannot be combined with -destroy or -refresh-only.",
+		))
+	case o.driftOnlyRaw:
+		o.PlanMode = plans.DriftOnlyMode
+		if !o.Refresh {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible refresh options",
+				"It doesn't make sense to use -drift-only at the same time as -refresh=false, because OpenTofu would have nothing to do.",
+			))
+		}
 	case o.destroyRaw:
 		o.PlanMode = plans.DestroyMode
 	case o.refreshOnlyRaw:
@@ -228,8 +301,9 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 // desirable for the arguments package to handle the gathering of variables
 // directly, returning a map of variable values.
 type Vars struct {
-	vars     *flagNameValueSlice
-	varFiles *flagNameValueSlice
+	vars          *flagNameValueSlice
+	varFiles      *flagNameValueSlice
+	varMergeFiles *flagNameValueSlice
 }
 
 func (v *Vars) All() []FlagNameValue {
@@ -266,23 +340,31 @@ func extendedFlagSet(name string, state *State, operation *Operation, vars *Vars
 
 	if operation != nil {
 		f.IntVar(&operation.Parallelism, "parallelism", DefaultParallelism, "parallelism")
+		f.IntVar(&operation.RefreshConcurrency, "refresh-concurrency", 0, "refresh-concurrency")
 		f.BoolVar(&operation.Refresh, "refresh", true, "refresh")
 		f.BoolVar(&operation.destroyRaw, "destroy", false, "destroy")
 		f.BoolVar(&operation.refreshOnlyRaw, "refresh-only", false, "refresh-only")
+		f.BoolVar(&operation.driftOnlyRaw, "drift-only", false, "drift-only")
 		f.Var((*flagStringSlice)(&operation.targetsRaw), "target", "target")
 		f.Var((*flagStringSlice)(&operation.excludesRaw), "exclude", "exclude")
+		f.Var((*flagStringSlice)(&operation.TargetGroups), "target-group", "target-group")
+		f.BoolVar(&operation.TargetStrict, "target-strict", false, "target-strict")
 		f.Var((*flagStringSlice)(&operation.forceReplaceRaw), "replace", "replace")
+		f.BoolVar(&operation.StrictVariables, "strict-vars", false, "strict-vars")
 	}
 
-	// Gather all -var and -var-file arguments into one heterogeneous structure
-	// to preserve the overall order.
+	// Gather all -var, -var-file and -var-file-merge arguments into one
+	// heterogeneous structure to preserve the overall order.
 	if vars != nil {
 		varsFlags := newFlagNameValueSlice("-var")
 		varFilesFlags := varsFlags.Alias("-var-file")
+		varMergeFilesFlags := varsFlags.Alias("-var-file-merge")
 		vars.vars = &varsFlags
 		vars.varFiles = &varFilesFlags
+		vars.varMergeFiles = &varMergeFilesFlags
 		f.Var(vars.vars, "var", "var")
 		f.Var(vars.varFiles, "var-file", "var-file")
+		f.Var(vars.varMergeFiles, "var-file-merge", "var-file-merge")
 	}
 
 	return f
@@ -6,6 +6,7 @@
 package arguments
 
 import (
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -32,11 +33,43 @@ type Plan struct {
 	// be written to.
 	GenerateConfigPath string
 
+	// ConfigFrom contains an optional path to a configuration bundle to plan
+	// instead of the configuration in the working directory, or "-" to read
+	// the bundle from stdin.
+	ConfigFrom string
+
+	// ReuseUnchangedModules allows the plan to reuse the results of the
+	// previous plan for modules that haven't changed since.
+	ReuseUnchangedModules bool
+
+	// Incremental makes the plan skip both refreshing and planning the
+	// resources in module subtrees that haven't changed since the previous
+	// incremental plan that was applied.
+	Incremental bool
+
+	// StateVersion selects an earlier version of the state to plan against,
+	// either by its ID or as a timestamp.
+	StateVersion string
+
+	// ReviewDrift asks, for each resource instance that was deleted outside
+	// of OpenTofu, whether to create it again, forget it, or fail the plan.
+	ReviewDrift bool
+
 	// ViewType specifies which output format to use
 	ViewType ViewType
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// Compact groups the changes to instances of the same resource that are
+	// identical when rendering the plan.
+	Compact bool
+
+	// Attestation is an optional file path or OCI reference to write a
+	// provenance attestation of the saved plan to, and AttestationKey is
+	// the path of the private key to sign it with.
+	Attestation    string
+	AttestationKey string
 }
 
 // ParsePlan processes CLI arguments, returning a Plan value and errors.
@@ -55,7 +88,15 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 	cmdFlags.BoolVar(&plan.InputEnabled, "input", true, "input")
 	cmdFlags.StringVar(&plan.OutPath, "out", "", "out")
 	cmdFlags.StringVar(&plan.GenerateConfigPath, "generate-config-out", "", "generate-config-out")
+	cmdFlags.StringVar(&plan.ConfigFrom, "config-from", "", "config-from")
+	cmdFlags.BoolVar(&plan.ReuseUnchangedModules, "reuse-unchanged-modules", false, "reuse-unchanged-modules")
+	cmdFlags.BoolVar(&plan.Incremental, "incremental", false, "incremental")
+	cmdFlags.StringVar(&plan.StateVersion, "state-version", "", "state-version")
+	cmdFlags.BoolVar(&plan.ReviewDrift, "review-drift", false, "review-drift")
 	cmdFlags.BoolVar(&plan.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.BoolVar(&plan.Compact, "compact", false, "compact")
+	cmdFlags.StringVar(&plan.Attestation, "attestation", "", "attestation")
+	cmdFlags.StringVar(&plan.AttestationKey, "attestation-key", "", "attestation-key")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -79,12 +120,112 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 	}
 
 	diags = diags.Append(plan.Operation.Parse())
+	if plan.Operation.PlanMode == plans.DriftOnlyMode && plan.OutPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan options",
+			"A drift-only plan cannot be applied, and so cannot be saved with the -out option.",
+		))
+	}
+	if plan.ReuseUnchangedModules {
+		switch {
+		case plan.Operation.Refresh:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -reuse-unchanged-modules option requires -refresh=false, because OpenTofu can't otherwise know whether remote objects have changed since the previous plan.",
+			))
+		case plan.Operation.PlanMode != plans.NormalMode:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -reuse-unchanged-modules option is only available in the normal planning mode.",
+			))
+		}
+	}
+	if plan.Incremental {
+		switch {
+		case plan.ReuseUnchangedModules:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -incremental and -reuse-unchanged-modules options cannot be used together.",
+			))
+		case plan.Operation.PlanMode != plans.NormalMode:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -incremental option is only available in the normal planning mode.",
+			))
+		}
+	}
+
+	if plan.StateVersion != "" {
+		switch {
+		case plan.OutPath != "":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"A plan against an earlier version of the state cannot be applied, and so cannot be saved with the -out option.",
+			))
+		case plan.ReuseUnchangedModules:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -reuse-unchanged-modules option cannot be used with -state-version, because the previous plan results are for the latest state.",
+			))
+		}
+	}
+
+	switch {
+	case plan.Attestation != "" && plan.OutPath == "":
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan options",
+			"The -attestation option requires -out, because the attestation describes the saved plan.",
+		))
+	case plan.AttestationKey != "" && plan.Attestation == "":
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan options",
+			"The -attestation-key option is only valid together with -attestation.",
+		))
+	}
 
 	// JSON view currently does not support input, so we disable it here
 	if json {
 		plan.InputEnabled = false
 	}
 
+	if plan.ReviewDrift {
+		switch {
+		case !plan.Operation.Refresh:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -review-drift option requires refreshing, because OpenTofu only detects deleted objects while refreshing.",
+			))
+		case plan.Operation.PlanMode != plans.NormalMode:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -review-drift option is only available in the normal planning mode.",
+			))
+		case len(plan.Operation.Targets) > 0 || len(plan.Operation.TargetGroups) > 0:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -review-drift option cannot be used with -target or -target-group, because forgetting a deleted object excludes it from the plan.",
+			))
+		case !plan.InputEnabled:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"The -review-drift option asks how to handle each deleted object, so it cannot be used with -input=false or -json.",
+			))
+		}
+	}
+
 	switch {
 	case json:
 		plan.ViewType = ViewJSON
@@ -54,6 +54,158 @@ func TestParsePlan_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"strict variables": {
+			[]string{"-strict-vars"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:        plans.NormalMode,
+					Parallelism:     10,
+					Refresh:         true,
+					StrictVariables: true,
+				},
+			},
+		},
+		"refresh concurrency": {
+			[]string{"-refresh-concurrency=4"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:           plans.NormalMode,
+					Parallelism:        10,
+					RefreshConcurrency: 4,
+					Refresh:            true,
+				},
+			},
+		},
+		"drift only": {
+			[]string{"-drift-only", "-detailed-exitcode"},
+			&Plan{
+				DetailedExitCode: true,
+				InputEnabled:     true,
+				OutPath:          "",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.DriftOnlyMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"reuse unchanged modules": {
+			[]string{"-reuse-unchanged-modules", "-refresh=false"},
+			&Plan{
+				DetailedExitCode:      false,
+				InputEnabled:          true,
+				OutPath:               "",
+				ReuseUnchangedModules: true,
+				ViewType:              ViewHuman,
+				State:                 &State{Lock: true},
+				Vars:                  &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     false,
+				},
+			},
+		},
+		"incremental": {
+			[]string{"-incremental"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				Incremental:      true,
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"review drift": {
+			[]string{"-review-drift"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				ReviewDrift:      true,
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"state version": {
+			[]string{"-state-version=2024-05-01T12:00:00Z"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				StateVersion:     "2024-05-01T12:00:00Z",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"compact": {
+			[]string{"-compact"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				Compact:          true,
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"configuration bundle from stdin": {
+			[]string{"-config-from=-"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				ConfigFrom:       "-",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
 		"JSON view disables input": {
 			[]string{"-json"},
 			&Plan{
@@ -100,6 +252,204 @@ func TestParsePlan_invalid(t *testing.T) {
 	}
 }
 
+func TestParsePlan_invalidRefreshConcurrency(t *testing.T) {
+	_, diags := ParsePlan([]string{"-refresh-concurrency=-1"})
+	if len(diags) == 0 {
+		t.Fatal("expected diags but got none")
+	}
+	if got, want := diags.Err().Error(), "must be a positive number, not -1"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParsePlan_invalidDriftOnly(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"with destroy": {
+			[]string{"-drift-only", "-destroy"},
+			"cannot be combined with -destroy or -refresh-only",
+		},
+		"with refresh-only": {
+			[]string{"-drift-only", "-refresh-only"},
+			"cannot be combined with -destroy or -refresh-only",
+		},
+		"without refresh": {
+			[]string{"-drift-only", "-refresh=false"},
+			"-drift-only at the same time as -refresh=false",
+		},
+		"with out": {
+			[]string{"-drift-only", "-out=saved.tfplan"},
+			"cannot be saved with the -out option",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_invalidReuseUnchangedModules(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"with refresh": {
+			[]string{"-reuse-unchanged-modules"},
+			"requires -refresh=false",
+		},
+		"with destroy": {
+			[]string{"-reuse-unchanged-modules", "-refresh=false", "-destroy"},
+			"only available in the normal planning mode",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_invalidIncremental(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"with reuse unchanged modules": {
+			[]string{"-incremental", "-reuse-unchanged-modules", "-refresh=false"},
+			"cannot be used together",
+		},
+		"with refresh only": {
+			[]string{"-incremental", "-refresh-only"},
+			"only available in the normal planning mode",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_invalidReviewDrift(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"without refresh": {
+			[]string{"-review-drift", "-refresh=false"},
+			"requires refreshing",
+		},
+		"with destroy": {
+			[]string{"-review-drift", "-destroy"},
+			"only available in the normal planning mode",
+		},
+		"with targets": {
+			[]string{"-review-drift", "-target=foo_bar.baz"},
+			"cannot be used with -target",
+		},
+		"without input": {
+			[]string{"-review-drift", "-input=false"},
+			"cannot be used with -input=false or -json",
+		},
+		"with json": {
+			[]string{"-review-drift", "-json"},
+			"cannot be used with -input=false or -json",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_invalidStateVersion(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"with out": {
+			[]string{"-state-version=3", "-out=saved.tfplan"},
+			"cannot be saved with the -out option",
+		},
+		"with reuse unchanged modules": {
+			[]string{"-state-version=3", "-reuse-unchanged-modules", "-refresh=false"},
+			"cannot be used with -state-version",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_invalidAttestation(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"without out": {
+			[]string{"-attestation=plan.intoto.json"},
+			"The -attestation option requires -out",
+		},
+		"key without attestation": {
+			[]string{"-attestation-key=key.pem", "-out=saved.tfplan"},
+			"only valid together with -attestation",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestParsePlan_tooManyArguments(t *testing.T) {
 	got, diags := ParsePlan([]string{"saved.tfplan"})
 	if len(diags) == 0 {
@@ -250,6 +600,54 @@ func TestParsePlan_excludeAndTarget(t *testing.T) {
 	}
 }
 
+func TestParsePlan_targetGroups(t *testing.T) {
+	got, diags := ParsePlan([]string{"-target-group=networking", "-target-group", "dns", "-target=foo_bar.baz"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if want := []string{"networking", "dns"}; !cmp.Equal(got.Operation.TargetGroups, want) {
+		t.Errorf("unexpected result\n%s", cmp.Diff(got.Operation.TargetGroups, want))
+	}
+	if len(got.Operation.Targets) != 1 {
+		t.Errorf("expected one target, got %d", len(got.Operation.Targets))
+	}
+}
+
+func TestParsePlan_targetGroupInvalid(t *testing.T) {
+	_, gotDiags := ParsePlan([]string{"-target-group=net.work", "-target-group=dns", "-exclude=foo_bar.baz"})
+
+	wantDiags := tfdiags.Diagnostics{
+		tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid combination of arguments",
+			"-target-group and -exclude flags cannot be used together. Please remove one of the flags",
+		),
+		tfdiags.Sourceless(
+			tfdiags.Error,
+			`Invalid target-group "net.work"`,
+			"A group name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.",
+		),
+	}
+	if diff := cmp.Diff(wantDiags.ForRPC(), gotDiags.ForRPC()); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestParsePlan_targetStrict(t *testing.T) {
+	got, diags := ParsePlan([]string{"-target=foo_bar.baz", "-target-strict"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.Operation.TargetStrict {
+		t.Error("TargetStrict is not set")
+	}
+
+	_, diags = ParsePlan([]string{"-target-strict"})
+	if got, want := diags.Err().Error(), "The -target-strict flag can only be used together with -target or -target-group."; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
 func TestParsePlan_vars(t *testing.T) {
 	testCases := map[string]struct {
 		args []string
@@ -283,6 +681,18 @@ func TestParsePlan_vars(t *testing.T) {
 				{Name: "-var", Value: "boop=beep"},
 			},
 		},
+		"var-file-merge ordering preserved": {
+			args: []string{
+				"-var-file", "common.tfvars",
+				"-var-file-merge", "prod.tfvars",
+				"-var", "boop=beep",
+			},
+			want: []FlagNameValue{
+				{Name: "-var-file", Value: "common.tfvars"},
+				{Name: "-var-file-merge", Value: "prod.tfvars"},
+				{Name: "-var", Value: "boop=beep"},
+			},
+		},
 	}
 
 	for name, tc := range testCases {
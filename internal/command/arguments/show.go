@@ -22,6 +22,28 @@ type Show struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// PolicyInput selects the stable "policy input" JSON representation of
+	// a saved plan, intended for consumption by policy engines. It implies
+	// JSON output.
+	PolicyInput bool
+
+	// HCL selects rendering the planned new values of a saved plan as HCL
+	// snippets, rather than as a diff.
+	HCL bool
+
+	// Permissions selects rendering the permissions a saved plan requires,
+	// based on the permission hints declared by its providers.
+	Permissions bool
+
+	// Compact groups the changes to instances of the same resource that are
+	// identical when rendering the plan.
+	Compact bool
+
+	// NDJSON selects writing each resource instance object of a state as a
+	// separate line of JSON, so that tools can process large states
+	// incrementally. It implies JSON output.
+	NDJSON bool
 }
 
 // ParseShow processes CLI arguments, returning a Show value and errors.
@@ -38,6 +60,11 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	cmdFlags := extendedFlagSet("show", nil, nil, show.Vars)
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&show.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.BoolVar(&show.PolicyInput, "policy-input", false, "policy-input")
+	cmdFlags.BoolVar(&show.HCL, "hcl", false, "hcl")
+	cmdFlags.BoolVar(&show.Permissions, "permissions", false, "permissions")
+	cmdFlags.BoolVar(&show.Compact, "compact", false, "compact")
+	cmdFlags.BoolVar(&show.NDJSON, "ndjson", false, "ndjson")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -60,8 +87,48 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 		show.Path = args[0]
 	}
 
+	if show.PolicyInput && show.Path == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Missing plan file",
+			"The -policy-input option requires the path to a saved plan file.",
+		))
+	}
+
+	if show.HCL && (jsonOutput || show.PolicyInput) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -hcl option cannot be used together with -json or -policy-input.",
+		))
+	}
+
+	if show.Permissions && show.Path == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Missing plan file",
+			"The -permissions option requires the path to a saved plan file.",
+		))
+	}
+
+	if show.Permissions && (show.HCL || show.PolicyInput) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -permissions option cannot be used together with -hcl or -policy-input.",
+		))
+	}
+
+	if show.NDJSON && (show.HCL || show.PolicyInput || show.Permissions) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -ndjson option cannot be used together with -hcl, -permissions or -policy-input.",
+		))
+	}
+
 	switch {
-	case jsonOutput:
+	case jsonOutput, show.PolicyInput, show.NDJSON:
 		show.ViewType = ViewJSON
 	default:
 		show.ViewType = ViewHuman
@@ -39,6 +39,38 @@ func TestParseShow_valid(t *testing.T) {
 				ViewType: ViewJSON,
 			},
 		},
+		"policy input": {
+			[]string{"-policy-input", "foo"},
+			&Show{
+				Path:        "foo",
+				ViewType:    ViewJSON,
+				PolicyInput: true,
+			},
+		},
+		"hcl": {
+			[]string{"-hcl", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewHuman,
+				HCL:      true,
+			},
+		},
+		"permissions": {
+			[]string{"-permissions", "foo"},
+			&Show{
+				Path:        "foo",
+				ViewType:    ViewHuman,
+				Permissions: true,
+			},
+		},
+		"permissions json": {
+			[]string{"-permissions", "-json", "foo"},
+			&Show{
+				Path:        "foo",
+				ViewType:    ViewJSON,
+				Permissions: true,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -89,6 +121,92 @@ func TestParseShow_invalid(t *testing.T) {
 				),
 			},
 		},
+		"policy input without plan": {
+			[]string{"-policy-input"},
+			&Show{
+				Path:        "",
+				ViewType:    ViewJSON,
+				PolicyInput: true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Missing plan file",
+					"The -policy-input option requires the path to a saved plan file.",
+				),
+			},
+		},
+		"hcl with json": {
+			[]string{"-hcl", "-json", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewJSON,
+				HCL:      true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command line options",
+					"The -hcl option cannot be used together with -json or -policy-input.",
+				),
+			},
+		},
+		"permissions without plan": {
+			[]string{"-permissions"},
+			&Show{
+				Path:        "",
+				ViewType:    ViewHuman,
+				Permissions: true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Missing plan file",
+					"The -permissions option requires the path to a saved plan file.",
+				),
+			},
+		},
+		"ndjson": {
+			[]string{"-ndjson"},
+			&Show{
+				Path:     "",
+				ViewType: ViewJSON,
+				NDJSON:   true,
+			},
+			nil,
+		},
+		"ndjson with hcl": {
+			[]string{"-ndjson", "-hcl", "foo"},
+			&Show{
+				Path:     "foo",
+				ViewType: ViewJSON,
+				HCL:      true,
+				NDJSON:   true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command line options",
+					"The -ndjson option cannot be used together with -hcl, -permissions or -policy-input.",
+				),
+			},
+		},
+		"permissions with hcl": {
+			[]string{"-permissions", "-hcl", "foo"},
+			&Show{
+				Path:        "foo",
+				ViewType:    ViewHuman,
+				HCL:         true,
+				Permissions: true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Incompatible command line options",
+					"The -permissions option cannot be used together with -hcl or -policy-input.",
+				),
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -32,6 +32,10 @@ type Test struct {
 	// human-readable format or JSON for each run step depending on the
 	// ViewType.
 	Verbose bool
+
+	// ParallelRuns is the maximum number of run blocks with parallel set
+	// that can execute at the same time.
+	ParallelRuns int
 }
 
 func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
@@ -47,6 +51,7 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 	cmdFlags.StringVar(&test.TestDirectory, "test-directory", configs.DefaultTestDirectory, "test-directory")
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&test.Verbose, "verbose", false, "verbose")
+	cmdFlags.IntVar(&test.ParallelRuns, "parallel-runs", DefaultParallelism, "parallel-runs")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -55,6 +60,14 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 			err.Error()))
 	}
 
+	if test.ParallelRuns < 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid parallel-runs value",
+			"The -parallel-runs option must be at least 1.",
+		))
+	}
+
 	switch {
 	case jsonOutput:
 		test.ViewType = ViewJSON
@@ -64,3 +77,55 @@ func ParseTest(args []string) (*Test, tfdiags.Diagnostics) {
 
 	return &test, diags
 }
+
+// TestClean represents the command-line arguments for the test clean
+// command.
+type TestClean struct {
+	// TestDirectory allows the user to override the directory that the test
+	// command will use to discover test files, defaults to "tests". It must
+	// match the value used when the kept infrastructure was created, so the
+	// configuration of the relevant run blocks can be found.
+	TestDirectory string
+
+	// ViewType specifies which output format to use: human or JSON.
+	ViewType ViewType
+
+	// You can specify common variables for all tests from the command line.
+	Vars *Vars
+}
+
+func ParseTestClean(args []string) (*TestClean, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	clean := TestClean{
+		Vars: new(Vars),
+	}
+
+	var jsonOutput bool
+	cmdFlags := extendedFlagSet("test clean", nil, nil, clean.Vars)
+	cmdFlags.StringVar(&clean.TestDirectory, "test-directory", configs.DefaultTestDirectory, "test-directory")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+
+	if err := cmdFlags.Parse(args); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to parse command-line flags",
+			err.Error()))
+	}
+
+	if len(cmdFlags.Args()) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unexpected argument",
+			"The test clean command doesn't accept positional arguments."))
+	}
+
+	switch {
+	case jsonOutput:
+		clean.ViewType = ViewJSON
+	default:
+		clean.ViewType = ViewHuman
+	}
+
+	return &clean, diags
+}
@@ -80,6 +80,7 @@ func TestParseTest(t *testing.T) {
 				TestDirectory: "tests",
 				ViewType:      ViewHuman,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
 			},
 			wantDiags: nil,
 		},
@@ -90,6 +91,7 @@ func TestParseTest(t *testing.T) {
 				TestDirectory: "tests",
 				ViewType:      ViewHuman,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
 			},
 			wantDiags: nil,
 		},
@@ -100,6 +102,7 @@ func TestParseTest(t *testing.T) {
 				TestDirectory: "tests",
 				ViewType:      ViewJSON,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
 			},
 			wantDiags: nil,
 		},
@@ -110,6 +113,7 @@ func TestParseTest(t *testing.T) {
 				TestDirectory: "other",
 				ViewType:      ViewHuman,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
 			},
 			wantDiags: nil,
 		},
@@ -121,6 +125,34 @@ func TestParseTest(t *testing.T) {
 				ViewType:      ViewHuman,
 				Verbose:       true,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
+			},
+		},
+		"parallel-runs": {
+			args: []string{"-parallel-runs=4"},
+			want: &Test{
+				Filter:        nil,
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+				Vars:          &Vars{},
+				ParallelRuns:  4,
+			},
+		},
+		"invalid parallel-runs": {
+			args: []string{"-parallel-runs=0"},
+			want: &Test{
+				Filter:        nil,
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+				Vars:          &Vars{},
+				ParallelRuns:  0,
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid parallel-runs value",
+					"The -parallel-runs option must be at least 1.",
+				),
 			},
 		},
 		"unknown flag": {
@@ -130,6 +162,7 @@ func TestParseTest(t *testing.T) {
 				TestDirectory: "tests",
 				ViewType:      ViewHuman,
 				Vars:          &Vars{},
+				ParallelRuns:  10,
 			},
 			wantDiags: tfdiags.Diagnostics{
 				tfdiags.Sourceless(
@@ -157,3 +190,70 @@ func TestParseTest(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTestClean(t *testing.T) {
+	tcs := map[string]struct {
+		args      []string
+		want      *TestClean
+		wantDiags tfdiags.Diagnostics
+	}{
+		"defaults": {
+			args: nil,
+			want: &TestClean{
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+				Vars:          &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"json": {
+			args: []string{"-json"},
+			want: &TestClean{
+				TestDirectory: "tests",
+				ViewType:      ViewJSON,
+				Vars:          &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"test-directory": {
+			args: []string{"-test-directory=other"},
+			want: &TestClean{
+				TestDirectory: "other",
+				ViewType:      ViewHuman,
+				Vars:          &Vars{},
+			},
+			wantDiags: nil,
+		},
+		"positional argument": {
+			args: []string{"main.tftest.hcl"},
+			want: &TestClean{
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+				Vars:          &Vars{},
+			},
+			wantDiags: tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Unexpected argument",
+					"The test clean command doesn't accept positional arguments.",
+				),
+			},
+		},
+	}
+
+	cmpOpts := cmpopts.IgnoreUnexported(Vars{})
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParseTestClean(tc.args)
+
+			if diff := cmp.Diff(tc.want, got, cmpOpts); len(diff) > 0 {
+				t.Errorf("diff:\n%s", diff)
+			}
+
+			if !reflect.DeepEqual(diags, tc.wantDiags) {
+				t.Errorf("wrong result\ngot: %s\nwant: %s", spew.Sdump(diags), spew.Sdump(tc.wantDiags))
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@
 package arguments
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -24,12 +27,26 @@ type Validate struct {
 	// included with the module.
 	NoTests bool
 
+	// WithProviders indicates that OpenTofu should validate the
+	// configuration with the values of the root module input variables, so
+	// that providers can validate the arguments that depend on them.
+	WithProviders bool
+
 	// ViewType specifies which output format to use: human, JSON, or "raw".
 	ViewType ViewType
 
+	// SeverityOverrides maps the rule IDs and categories of diagnostics to
+	// the severity to report those diagnostics with: "error", "warning", or
+	// SeverityOff to omit them.
+	SeverityOverrides map[string]string
+
 	Vars *Vars
 }
 
+// SeverityOff is the severity override that omits diagnostics from the
+// validate results altogether.
+const SeverityOff = "off"
+
 // ParseValidate processes CLI arguments, returning a Validate value and errors.
 // If errors are encountered, a Validate value is still returned representing
 // the best effort interpretation of the arguments.
@@ -45,6 +62,9 @@ func ParseValidate(args []string) (*Validate, tfdiags.Diagnostics) {
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.StringVar(&validate.TestDirectory, "test-directory", "tests", "test-directory")
 	cmdFlags.BoolVar(&validate.NoTests, "no-tests", false, "no-tests")
+	cmdFlags.BoolVar(&validate.WithProviders, "with-providers", false, "with-providers")
+	var severities flagStringSlice
+	cmdFlags.Var(&severities, "severity", "severity")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -67,6 +87,27 @@ func ParseValidate(args []string) (*Validate, tfdiags.Diagnostics) {
 		validate.Path = args[0]
 	}
 
+	for _, raw := range severities {
+		key, severity, ok := strings.Cut(raw, "=")
+		switch severity {
+		case "error", "warning", SeverityOff:
+		default:
+			ok = false
+		}
+		if !ok || key == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid -severity option",
+				fmt.Sprintf("The -severity option %q is not valid. It must be of the form RULE=SEVERITY, where RULE is a diagnostic rule ID or category and SEVERITY is \"error\", \"warning\" or \"off\".", raw),
+			))
+			continue
+		}
+		if validate.SeverityOverrides == nil {
+			validate.SeverityOverrides = make(map[string]string)
+		}
+		validate.SeverityOverrides[key] = severity
+	}
+
 	switch {
 	case jsonOutput:
 		validate.ViewType = ViewJSON
@@ -60,6 +60,27 @@ func TestParseValidate_valid(t *testing.T) {
 				NoTests:       true,
 			},
 		},
+		"with-providers": {
+			[]string{"-with-providers"},
+			&Validate{
+				Path:          ".",
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+				WithProviders: true,
+			},
+		},
+		"severity": {
+			[]string{"-json", "-severity=unsupported-argument=warning", "-severity", "reference=off"},
+			&Validate{
+				Path:          ".",
+				TestDirectory: "tests",
+				ViewType:      ViewJSON,
+				SeverityOverrides: map[string]string{
+					"unsupported-argument": "warning",
+					"reference":            "off",
+				},
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -69,7 +90,7 @@ func TestParseValidate_valid(t *testing.T) {
 				t.Fatalf("unexpected diags: %v", diags)
 			}
 			got.Vars = nil
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 		})
@@ -112,13 +133,33 @@ func TestParseValidate_invalid(t *testing.T) {
 				),
 			},
 		},
+		"invalid severity": {
+			[]string{"-severity=syntax=fatal", "-severity=warning"},
+			&Validate{
+				Path:          ".",
+				TestDirectory: "tests",
+				ViewType:      ViewHuman,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid -severity option",
+					`The -severity option "syntax=fatal" is not valid. It must be of the form RULE=SEVERITY, where RULE is a diagnostic rule ID or category and SEVERITY is "error", "warning" or "off".`,
+				),
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid -severity option",
+					`The -severity option "warning" is not valid. It must be of the form RULE=SEVERITY, where RULE is a diagnostic rule ID or category and SEVERITY is "error", "warning" or "off".`,
+				),
+			},
+		},
 	}
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			got, gotDiags := ParseValidate(tc.args)
 			got.Vars = nil
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 			if !reflect.DeepEqual(gotDiags, tc.wantDiags) {
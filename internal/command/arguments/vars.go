@@ -0,0 +1,70 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package arguments
+
+import (
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// VarsShow represents the command-line arguments for the vars show command.
+type VarsShow struct {
+	// Name identifies which root module input variable to show. If empty,
+	// show all variables.
+	Name string
+
+	// ViewType specifies which output format to use: human or JSON.
+	ViewType ViewType
+
+	Vars *Vars
+
+	// ShowSensitive is used to display the value of variables marked as sensitive.
+	ShowSensitive bool
+}
+
+// ParseVarsShow processes CLI arguments, returning a VarsShow value and
+// errors. If errors are encountered, a VarsShow value is still returned
+// representing the best effort interpretation of the arguments.
+func ParseVarsShow(args []string) (*VarsShow, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	varsShow := &VarsShow{
+		Vars: &Vars{},
+	}
+
+	var jsonOutput bool
+	cmdFlags := extendedFlagSet("vars show", nil, nil, varsShow.Vars)
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&varsShow.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+
+	if err := cmdFlags.Parse(args); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to parse command-line flags",
+			err.Error(),
+		))
+	}
+
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unexpected argument",
+			"The vars show command expects exactly one argument with the name of an input variable or no arguments to show all variables.",
+		))
+	}
+
+	if len(args) > 0 {
+		varsShow.Name = args[0]
+	}
+
+	switch {
+	case jsonOutput:
+		varsShow.ViewType = ViewJSON
+	default:
+		varsShow.ViewType = ViewHuman
+	}
+
+	return varsShow, diags
+}
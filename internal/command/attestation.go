@@ -0,0 +1,215 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+
+	"github.com/opentofu/opentofu/internal/attestation"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/version"
+)
+
+// writeAttestation signs a provenance attestation of the given run with the
+// key at keyPath, if any, and writes it to dest, which is either a file path
+// or an OCI reference starting with "oci://".
+//
+// The configuration and the locked provider versions are added to the run's
+// dependencies automatically.
+func (m *Meta) writeAttestation(ctx context.Context, dest, keyPath string, run *attestation.Run) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var key crypto.Signer
+	if keyPath != "" {
+		var err error
+		key, err = attestation.LoadSigningKey(keyPath)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to load attestation signing key",
+				fmt.Sprintf("OpenTofu could not load the key for the -attestation-key option: %s.", err),
+			))
+			return diags
+		}
+	} else {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Attestation is not signed",
+			"Without the -attestation-key option, OpenTofu writes the attestation without a signature, so its consumers can't verify that it's authentic.",
+		))
+	}
+
+	run.Version = version.String()
+	if config := m.configurationDescriptor(ctx); config != nil {
+		run.Dependencies = append(run.Dependencies, *config)
+	}
+	providers, moreDiags := m.providerDescriptors()
+	diags = diags.Append(moreDiags)
+	run.Dependencies = append(run.Dependencies, providers...)
+
+	env, err := attestation.Seal(run.Statement(), key)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to create attestation",
+			err.Error(),
+		))
+		return diags
+	}
+	src, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to create attestation",
+			err.Error(),
+		))
+		return diags
+	}
+
+	if !strings.HasPrefix(dest, attestation.OCIScheme) {
+		if err := os.WriteFile(dest, append(src, '\n'), 0644); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write attestation",
+				fmt.Sprintf("OpenTofu could not write the attestation to %s: %s.", dest, err),
+			))
+		}
+		return diags
+	}
+
+	ref, err := attestation.ParseOCIReference(dest)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid attestation destination",
+			err.Error(),
+		))
+		return diags
+	}
+	var creds svcauth.HostCredentials
+	if hostname, err := svchost.ForComparison(ref.Host); err == nil && m.Services != nil {
+		creds, _ = m.Services.CredentialsForHost(hostname)
+	}
+	var subject attestation.ResourceDescriptor
+	if len(run.Subjects) > 0 {
+		subject = run.Subjects[0]
+	}
+	digest, err := attestation.PushOCI(ctx, httpclient.New(), ref, creds, src, subject)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to push attestation",
+			fmt.Sprintf("OpenTofu could not push the attestation to %s: %s.", ref, err),
+		))
+		return diags
+	}
+	m.Ui.Output(fmt.Sprintf("Pushed attestation to %s (%s)", ref, digest))
+	return diags
+}
+
+// configurationDescriptor describes the Git commit of the configuration in
+// the working directory, or returns nil if it isn't in a Git repository.
+func (m *Meta) configurationDescriptor(ctx context.Context) *attestation.ResourceDescriptor {
+	git := func(args ...string) string {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", m.WorkingDir.RootModuleDir()}, args...)...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	commit := git("rev-parse", "HEAD")
+	if commit == "" {
+		return nil
+	}
+	ret := &attestation.ResourceDescriptor{
+		Name:   "configuration",
+		Digest: map[string]string{"gitCommit": commit},
+		Annotations: map[string]any{
+			// Uncommitted changes mean the commit doesn't fully describe
+			// the configuration that was used.
+			"dirty": git("status", "--porcelain") != "",
+		},
+	}
+	if origin := git("config", "--get", "remote.origin.url"); origin != "" {
+		if u, err := url.Parse(origin); err == nil && u.User != nil {
+			// Never include credentials embedded in the remote URL.
+			u.User = nil
+			origin = u.String()
+		}
+		ret.URI = "git+" + origin
+	}
+	return ret
+}
+
+// providerDescriptors describes the provider versions and checksums
+// recorded in the dependency lock file.
+func (m *Meta) providerDescriptors() ([]attestation.ResourceDescriptor, tfdiags.Diagnostics) {
+	locks, diags := m.lockedDependencies()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var ret []attestation.ResourceDescriptor
+	for addr, lock := range locks.AllProviders() {
+		hashes := make([]string, 0, len(lock.AllHashes()))
+		for _, hash := range lock.AllHashes() {
+			hashes = append(hashes, hash.String())
+		}
+		ret = append(ret, attestation.ResourceDescriptor{
+			Name: addr.String(),
+			Annotations: map[string]any{
+				"version": lock.Version().String(),
+				"hashes":  hashes,
+			},
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, diags
+}
+
+// stateDescriptor describes the given state. Its digest is of the state's
+// content only, without the lineage and serial that OpenTofu adds when
+// persisting it, and without any state encryption.
+func stateDescriptor(state *states.State) (attestation.ResourceDescriptor, error) {
+	var buf bytes.Buffer
+	if err := statefile.Write(statefile.New(state, "", 0), &buf, encryption.StateEncryptionDisabled()); err != nil {
+		return attestation.ResourceDescriptor{}, err
+	}
+	return attestation.ResourceDescriptor{
+		Name:   "state",
+		Digest: attestation.SHA256Digest(buf.Bytes()),
+	}, nil
+}
+
+// fileDescriptor describes the file at the given path by its SHA256 digest.
+func fileDescriptor(name, path string) (attestation.ResourceDescriptor, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return attestation.ResourceDescriptor{}, err
+	}
+	return attestation.ResourceDescriptor{
+		Name:   name,
+		Digest: attestation.SHA256Digest(src),
+	}, nil
+}
@@ -62,7 +62,8 @@ func (m *Meta) completePredictWorkspaceName() complete.Predictor {
 		}
 
 		b, diags := m.Backend(&BackendOpts{
-			Config: backendConfig,
+			Config:           backendConfig,
+			WorkspaceCommand: true,
 		}, enc.State())
 		if diags.HasErrors() {
 			return nil
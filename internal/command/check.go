@@ -0,0 +1,286 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/jsonchecks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// CheckCommand is a Command implementation that evaluates the check blocks in
+// the configuration against the current state, without planning any changes.
+type CheckCommand struct {
+	Meta
+}
+
+// checkFormatVersion is the version of the JSON output of "tofu check".
+const checkFormatVersion = "1.0"
+
+func (c *CheckCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	var jsonOutput bool
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.extendedFlagSet("check")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	configPath, err := modulePath(cmdFlags.Args())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	configPath = c.Meta.normalizePath(configPath)
+
+	// Check for user-supplied plugin path
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	// Load the encryption configuration
+	enc, encDiags := c.EncryptionFromPath(configPath)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	backendConfig, backendDiags := c.loadBackendConfig(configPath)
+	diags = diags.Append(backendDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(&BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We require a local backend
+	local, ok := b.(backend.Local)
+	if !ok {
+		c.showDiagnostics(diags) // in case of any warnings in here
+		c.Ui.Error(ErrUnsupportedLocalOp)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	// Build the operation
+	opReq := c.Operation(b, arguments.ViewHuman, enc)
+	opReq.ConfigDir = configPath
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	{
+		// Setup required variables/call for operation (usually done in Meta.RunOperation)
+		var moreDiags, callDiags tfdiags.Diagnostics
+		opReq.Variables, moreDiags = c.collectVariableValues()
+		opReq.RootCall, callDiags = c.rootModuleCall(opReq.ConfigDir)
+		diags = diags.Append(moreDiags).Append(callDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+
+	// Get the context
+	lr, _, ctxDiags := local.LocalRun(ctx, opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Successfully creating the context can result in a lock, so ensure we release it
+	defer func() {
+		diags := opReq.StateLocker.Unlock()
+		if diags.HasErrors() {
+			c.showDiagnostics(diags)
+		}
+	}()
+
+	planOpts := &tofu.PlanOpts{
+		Mode:       plans.RefreshOnlyMode,
+		ChecksOnly: true,
+	}
+	if lr.PlanOpts != nil {
+		planOpts.SetVariables = lr.PlanOpts.SetVariables
+	}
+	plan, planDiags := lr.Core.Plan(ctx, lr.Config, lr.InputState, planOpts)
+	diags = diags.Append(planDiags)
+	c.showDiagnostics(diags)
+	if plan == nil || plan.Checks == nil {
+		return 1
+	}
+
+	results := checkBlockResults(plan.Checks)
+	if jsonOutput {
+		out, err := json.MarshalIndent(struct {
+			FormatVersion string          `json:"format_version"`
+			Checks        json.RawMessage `json:"checks"`
+		}{
+			FormatVersion: checkFormatVersion,
+			Checks:        jsonchecks.MarshalCheckStates(results),
+		}, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal the check results: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+	} else {
+		c.showCheckResults(results)
+	}
+
+	if diags.HasErrors() {
+		return 1
+	}
+	for _, elem := range results.ConfigResults.Elems {
+		if status := elem.Value.Status; status == checks.StatusFail || status == checks.StatusError {
+			return 2
+		}
+	}
+	return 0
+}
+
+// checkBlockResults returns the results of the check blocks among the given
+// check results, leaving out the conditions of resources, outputs and input
+// variables.
+func checkBlockResults(all *states.CheckResults) *states.CheckResults {
+	ret := &states.CheckResults{
+		ConfigResults: addrs.MakeMap[addrs.ConfigCheckable, *states.CheckResultAggregate](),
+	}
+	for _, elem := range all.ConfigResults.Elems {
+		if elem.Key.CheckableKind() == addrs.CheckableCheck {
+			ret.ConfigResults.Put(elem.Key, elem.Value)
+		}
+	}
+	return ret
+}
+
+func (c *CheckCommand) showCheckResults(results *states.CheckResults) {
+	type objectResult struct {
+		addr   string
+		result *states.CheckResultObject
+	}
+	var objects []objectResult
+	for _, configElem := range results.ConfigResults.Elems {
+		if configElem.Value.ObjectResults.Len() == 0 {
+			// The check block has no instances, such as in a module with a
+			// count of zero.
+			continue
+		}
+		for _, elem := range configElem.Value.ObjectResults.Elems {
+			objects = append(objects, objectResult{
+				addr:   elem.Key.String(),
+				result: elem.Value,
+			})
+		}
+	}
+	if len(objects) == 0 {
+		c.Ui.Output("The configuration has no check blocks.")
+		return
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].addr < objects[j].addr
+	})
+
+	for _, obj := range objects {
+		var status string
+		switch obj.result.Status {
+		case checks.StatusPass:
+			status = "[green]passed[reset]"
+		case checks.StatusFail:
+			status = "[red]failed[reset]"
+		case checks.StatusError:
+			status = "[red]error[reset]"
+		default:
+			status = "[yellow]unknown[reset]"
+		}
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[bold]%s[reset]: %s", obj.addr, status)))
+		for _, msg := range obj.result.FailureMessages {
+			c.Ui.Output("  " + msg)
+		}
+	}
+}
+
+func (c *CheckCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] check [options]
+
+  Evaluates the check blocks in the configuration against the current state,
+  without refreshing managed resources or planning any changes, to validate
+  infrastructure continuously between applies.
+
+  OpenTofu reads the data resources nested in check blocks, but uses the
+  values in the state for all other resources. Check blocks whose "phases"
+  argument doesn't include "check" are skipped.
+
+  The exit code is 0 if no check failed, 1 if there was an error, and 2 if
+  any check failed.
+
+Options:
+
+  -json               Produce the check results in a machine-readable JSON
+                      format.
+
+  -lock=false         Don't hold a state lock during the operation. This is
+                      dangerous if others might concurrently run commands
+                      against the same workspace.
+
+  -lock-timeout=0s    Duration to retry a state lock.
+
+  -no-color           If specified, output won't contain any color.
+
+  -state=path         Legacy option for the local backend only. See the local
+                      backend's documentation for more information.
+
+  -var 'foo=bar'      Set a variable in the OpenTofu configuration. This
+                      flag can be set multiple times.
+
+  -var-file=foo       Set variables in the OpenTofu configuration from
+                      a file. If "terraform.tfvars" or any ".auto.tfvars"
+                      files are present, they will be automatically loaded.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CheckCommand) Synopsis() string {
+	return "Evaluate the check blocks against the current state"
+}
@@ -0,0 +1,105 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestCheck(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("check"), td)
+	defer testChdir(t, td)()
+
+	statePath := testStateFile(t, testState())
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = refreshFixtureSchema()
+	ui := cli.NewMockUi()
+	c := &CheckCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{
+		"-no-color",
+		"-state", statePath,
+	}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("wrong exit code %d; want 2\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if p.ReadResourceCalled {
+		t.Error("tofu check refreshed a managed resource")
+	}
+
+	want := `check.failing: failed
+  The instance has the wrong ID.
+check.passing: passed
+check.plan_only: unknown
+`
+	if got := ui.OutputWriter.String(); got != want {
+		t.Errorf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCheck_json(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("check"), td)
+	defer testChdir(t, td)()
+
+	statePath := testStateFile(t, testState())
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = refreshFixtureSchema()
+	ui := cli.NewMockUi()
+	c := &CheckCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{
+		"-json",
+		"-state", statePath,
+	}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("wrong exit code %d; want 2\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		FormatVersion string `json:"format_version"`
+		Checks        []struct {
+			Address struct {
+				Kind      string `json:"kind"`
+				ToDisplay string `json:"to_display"`
+			} `json:"address"`
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if got.FormatVersion != checkFormatVersion {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	var statuses []string
+	for _, check := range got.Checks {
+		if check.Address.Kind != "check" {
+			t.Errorf("unexpected %s in the results", check.Address.Kind)
+		}
+		statuses = append(statuses, check.Address.ToDisplay+"="+check.Status)
+	}
+	if got, want := strings.Join(statuses, " "), "check.failing=fail check.passing=pass check.plan_only=unknown"; got != want {
+		t.Errorf("wrong results\ngot:  %s\nwant: %s", got, want)
+	}
+}
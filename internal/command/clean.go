@@ -0,0 +1,339 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/modsdir"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// CleanCommand is a Command implementation that removes stale content from
+// the working directory's data directory.
+type CleanCommand struct {
+	Meta
+}
+
+// cleanItem is a file or directory that "tofu clean" removes.
+type cleanItem struct {
+	Path   string
+	Reason string
+}
+
+func (c *CleanCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var dryRun bool
+	cmdFlags := c.Meta.defaultFlagSet("clean")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry-run")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The clean command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+
+	var diags tfdiags.Diagnostics
+	var items []cleanItem
+
+	providerItems, providerDiags := c.staleProviderPackages()
+	diags = diags.Append(providerDiags)
+	items = append(items, providerItems...)
+
+	moduleItems, moduleDiags := c.staleModulePackages()
+	diags = diags.Append(moduleDiags)
+	items = append(items, moduleItems...)
+
+	cacheItems, cacheDiags := c.orphanedModuleCaches()
+	diags = diags.Append(cacheDiags)
+	items = append(items, cacheItems...)
+
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if len(items) == 0 {
+		c.showDiagnostics(diags)
+		c.Ui.Output("The working directory has nothing to clean.")
+		return 0
+	}
+
+	removed := 0
+	for _, item := range items {
+		if dryRun {
+			c.Ui.Output(fmt.Sprintf("Would remove %s (%s)", item.Path, item.Reason))
+			continue
+		}
+		if err := os.RemoveAll(item.Path); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to remove stale content",
+				fmt.Sprintf("OpenTofu could not remove %s: %s.", item.Path, err),
+			))
+			continue
+		}
+		c.Ui.Output(fmt.Sprintf("Removed %s (%s)", item.Path, item.Reason))
+		removed++
+	}
+	if !dryRun {
+		// Removing provider packages can leave behind the empty directories
+		// for their versions and providers.
+		removeEmptyDirs(c.providerLocalCacheDir().BasePath())
+	}
+
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	if dryRun {
+		c.Ui.Output(fmt.Sprintf("\n%d item(s) would be removed. Run the command without -dry-run to remove them.", len(items)))
+	} else {
+		c.Ui.Output(fmt.Sprintf("\n%d item(s) removed.", removed))
+	}
+	return 0
+}
+
+// staleProviderPackages returns the provider packages in the working
+// directory's provider cache that aren't the versions selected in the
+// dependency lock file.
+//
+// Without a dependency lock file there's no way to tell which packages are
+// still in use, so then no packages are stale.
+func (c *CleanCommand) staleProviderPackages() ([]cleanItem, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	dir := c.providerLocalCacheDir().BasePath()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, diags
+	}
+	if _, err := os.Stat(dependencyLockFilename); os.IsNotExist(err) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"No dependency lock file",
+			fmt.Sprintf("The working directory has no %s file, so OpenTofu can't tell which provider packages are stale and will keep all of them. Run \"tofu init\" to create the lock file.", dependencyLockFilename),
+		))
+		return nil, diags
+	}
+
+	locks, lockDiags := c.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		return nil, diags
+	}
+
+	available, err := getproviders.SearchLocalDirectory(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read provider cache",
+			fmt.Sprintf("OpenTofu could not read the provider packages in %s: %s.", dir, err),
+		))
+		return nil, diags
+	}
+
+	var items []cleanItem
+	for provider, metas := range available {
+		lock := locks.Provider(provider)
+		for _, meta := range metas {
+			switch {
+			case lock == nil:
+				items = append(items, cleanItem{
+					Path:   meta.Location.String(),
+					Reason: fmt.Sprintf("%s is not in the dependency lock file", provider.ForDisplay()),
+				})
+			case lock.Version() != meta.Version:
+				items = append(items, cleanItem{
+					Path:   meta.Location.String(),
+					Reason: fmt.Sprintf("the dependency lock file selects %s v%s", provider.ForDisplay(), lock.Version()),
+				})
+			}
+		}
+	}
+	sortCleanItems(items)
+	return items, diags
+}
+
+// staleModulePackages returns the module packages in the working directory's
+// modules directory that none of the modules in the module manifest use.
+func (c *CleanCommand) staleModulePackages() ([]cleanItem, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	dir := c.modulesDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, diags
+	} else if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read modules directory",
+			fmt.Sprintf("OpenTofu could not read the module packages in %s: %s.", dir, err),
+		))
+		return nil, diags
+	}
+
+	manifest, err := modsdir.ReadManifestSnapshotForDir(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read module manifest",
+			fmt.Sprintf("OpenTofu could not read the manifest of installed modules: %s.", err),
+		))
+		return nil, diags
+	}
+	var used []string
+	for _, record := range manifest {
+		if abs, err := filepath.Abs(record.Dir); err == nil {
+			used = append(used, abs)
+		}
+	}
+
+	var items []cleanItem
+	for _, entry := range entries {
+		if entry.Name() == modsdir.ManifestSnapshotFilename {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		inUse := false
+		for _, u := range used {
+			if u == abs || strings.HasPrefix(u, abs+string(filepath.Separator)) {
+				inUse = true
+				break
+			}
+		}
+		if !inUse {
+			items = append(items, cleanItem{
+				Path:   path,
+				Reason: "not used by any module in the module manifest",
+			})
+		}
+	}
+	sortCleanItems(items)
+	return items, diags
+}
+
+// orphanedModuleCaches returns the files saved by "tofu plan
+// -reuse-unchanged-modules" for workspaces that no longer exist.
+//
+// Finding the workspaces requires the backend, so if it isn't available the
+// files are kept with a warning.
+func (c *CleanCommand) orphanedModuleCaches() ([]cleanItem, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	dir := c.moduleCacheDir()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		return nil, diags
+	}
+
+	skipped := func(detail string) tfdiags.Diagnostics {
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Kept all saved plan results",
+			fmt.Sprintf("OpenTofu could not list the workspaces to find the saved plan results in %s that belong to deleted workspaces: %s", dir, detail),
+		))
+	}
+
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		return nil, skipped(encDiags.Err().Error())
+	}
+	b, backendDiags := c.Backend(nil, enc.State())
+	if backendDiags.HasErrors() {
+		return nil, skipped(backendDiags.Err().Error())
+	}
+	c.ignoreRemoteVersionConflict(b)
+	workspaces, err := b.Workspaces()
+	if err != nil {
+		return nil, skipped(err.Error())
+	}
+	exists := make(map[string]bool, len(workspaces))
+	for _, name := range workspaces {
+		exists[name] = true
+	}
+
+	var items []cleanItem
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		if !exists[name] {
+			items = append(items, cleanItem{
+				Path:   file,
+				Reason: fmt.Sprintf("saved plan results for deleted workspace %q", name),
+			})
+		}
+	}
+	sortCleanItems(items)
+	return items, diags
+}
+
+func sortCleanItems(items []cleanItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+}
+
+// removeEmptyDirs removes all of the empty directories within the given
+// directory, but not the directory itself.
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		removeEmptyDirs(path)
+		if remaining, err := os.ReadDir(path); err == nil && len(remaining) == 0 {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+func (c *CleanCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] clean [options]
+
+  Removes stale content from the working directory's data directory, which
+  is .terraform by default:
+
+    - Provider packages for providers or versions that the dependency lock
+      file doesn't select.
+
+    - Module packages that no module in the module manifest uses.
+
+    - Plan results saved by "tofu plan -reuse-unchanged-modules" for
+      workspaces that no longer exist.
+
+  OpenTofu never needs any of these again, but "tofu init" doesn't remove
+  them when the selected versions or module sources change.
+
+Options:
+
+  -dry-run    List the content that would be removed, without removing it.
+
+  -no-color   If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CleanCommand) Synopsis() string {
+	return "Remove stale content from the working directory"
+}
@@ -0,0 +1,160 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+// testCleanWorkingDir populates the current working directory with a lock
+// file that selects hashicorp/test v1.2.0, a provider cache with that and two
+// stale packages, a module manifest that uses one of two module packages, and
+// saved plan results for the default workspace and a deleted one.
+func testCleanWorkingDir(t *testing.T) {
+	t.Helper()
+
+	files := map[string]string{
+		dependencyLockFilename: `
+provider "registry.opentofu.org/hashicorp/test" {
+  version = "1.2.0"
+}
+`,
+		".terraform/providers/registry.opentofu.org/hashicorp/test/1.2.0/linux_amd64/terraform-provider-test": "",
+		".terraform/providers/registry.opentofu.org/hashicorp/test/1.1.0/linux_amd64/terraform-provider-test": "",
+		".terraform/providers/registry.opentofu.org/hashicorp/old/1.0.0/linux_amd64/terraform-provider-old":   "",
+		".terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"child","Source":"registry.opentofu.org/example/child/test","Version":"1.0.0","Dir":".terraform/modules/child/sub"}
+]}`,
+		".terraform/modules/child/sub/main.tf": "",
+		".terraform/modules/removed/main.tf":   "",
+		".terraform/module-cache/default.json": "{}",
+		".terraform/module-cache/deleted.json": "{}",
+		"main.tf":                              "",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testCleanWorkingDir(t)
+
+	ui := cli.NewMockUi()
+	c := &CleanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("wrong exit code %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"hashicorp/old is not in the dependency lock file",
+		"the dependency lock file selects hashicorp/test v1.2.0",
+		"not used by any module in the module manifest",
+		`saved plan results for deleted workspace "deleted"`,
+		"4 item(s) removed.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q\n%s", want, output)
+		}
+	}
+
+	for _, path := range []string{
+		".terraform/providers/registry.opentofu.org/hashicorp/test/1.1.0",
+		".terraform/providers/registry.opentofu.org/hashicorp/old",
+		".terraform/modules/removed",
+		".terraform/module-cache/deleted.json",
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", path)
+		}
+	}
+	for _, path := range []string{
+		".terraform/providers/registry.opentofu.org/hashicorp/test/1.2.0/linux_amd64/terraform-provider-test",
+		".terraform/modules/child/sub/main.tf",
+		".terraform/modules/modules.json",
+		".terraform/module-cache/default.json",
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed", path)
+		}
+	}
+}
+
+func TestClean_dryRun(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testCleanWorkingDir(t)
+
+	ui := cli.NewMockUi()
+	c := &CleanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-dry-run"}); code != 0 {
+		t.Fatalf("wrong exit code %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if output := ui.OutputWriter.String(); !strings.Contains(output, "4 item(s) would be removed.") {
+		t.Errorf("wrong output\n%s", output)
+	}
+	for _, path := range []string{
+		".terraform/providers/registry.opentofu.org/hashicorp/test/1.1.0",
+		".terraform/providers/registry.opentofu.org/hashicorp/old",
+		".terraform/modules/removed",
+		".terraform/module-cache/deleted.json",
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed", path)
+		}
+	}
+}
+
+func TestClean_noLockFile(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testCleanWorkingDir(t)
+	if err := os.Remove(dependencyLockFilename); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	c := &CleanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-dry-run"}); code != 0 {
+		t.Fatalf("wrong exit code %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if errOutput := ui.ErrorWriter.String(); !strings.Contains(errOutput, "No dependency lock file") {
+		t.Errorf("missing warning\n%s", errOutput)
+	}
+	if output := ui.OutputWriter.String(); strings.Contains(output, "providers") {
+		t.Errorf("provider packages should be kept without a lock file\n%s", output)
+	}
+}
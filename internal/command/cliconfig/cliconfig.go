@@ -20,6 +20,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl"
@@ -30,7 +31,27 @@ import (
 )
 
 const pluginCacheDirEnvVar = "TF_PLUGIN_CACHE_DIR"
+const pluginSchemaCacheDirEnvVar = "TF_PLUGIN_SCHEMA_CACHE_DIR"
 const pluginCacheMayBreakLockFileEnvVar = "TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE"
+const modulePackageCacheDirEnvVar = "TF_MODULE_PACKAGE_CACHE_DIR"
+const workspaceAutoCreateEnvVar = "TF_WORKSPACE_AUTO_CREATE"
+
+// The valid values of the workspace_auto_create setting, which decides what
+// happens when the TF_WORKSPACE environment variable selects a workspace
+// that doesn't exist.
+const (
+	// WorkspaceAutoCreateAllow creates the workspace implicitly, as the
+	// backend would if the setting wasn't given.
+	WorkspaceAutoCreateAllow = "create"
+
+	// WorkspaceAutoCreateError returns an error, so that the workspace must
+	// be created explicitly with "tofu workspace new".
+	WorkspaceAutoCreateError = "error"
+
+	// WorkspaceAutoCreatePrompt asks for confirmation before creating the
+	// workspace, and returns an error if input is disabled.
+	WorkspaceAutoCreatePrompt = "prompt"
+)
 
 // Config is the structure of the configuration for the OpenTofu CLI.
 //
@@ -55,11 +76,41 @@ type Config struct {
 	// over the requirements of the dependency lock file.
 	PluginCacheMayBreakDependencyLockFile bool `hcl:"plugin_cache_may_break_dependency_lock_file"`
 
+	// If set, provider schemas are cached in this directory, so that
+	// commands can use them without starting the providers. Otherwise they
+	// are cached inside PluginCacheDir, if that is set.
+	PluginSchemaCacheDir string `hcl:"plugin_schema_cache_dir"`
+
+	// If set, enables caching of remote module packages in this directory,
+	// shared between all working directories, to avoid repeatedly
+	// re-downloading them.
+	ModulePackageCacheDir string `hcl:"module_package_cache_dir"`
+
+	// WorkspaceAutoCreate decides what happens when the TF_WORKSPACE
+	// environment variable selects a workspace that doesn't exist. It's one
+	// of the WorkspaceAutoCreate constants, or empty to create the workspace
+	// as before.
+	WorkspaceAutoCreate string `hcl:"workspace_auto_create"`
+
 	Hosts map[string]*ConfigHost `hcl:"host"`
 
 	Credentials        map[string]map[string]interface{}   `hcl:"credentials"`
 	CredentialsHelpers map[string]*ConfigCredentialsHelper `hcl:"credentials_helper"`
 
+	// PlanAnalyzers are the external programs that each plan is passed to
+	// before it is rendered, keyed by the names given to them.
+	PlanAnalyzers map[string]*ConfigPlanAnalyzer `hcl:"plan_analyzer"`
+
+	// DiffRenderers are the external programs that render the planned
+	// changes to resources of particular types in the plan output, keyed by
+	// the names given to them.
+	DiffRenderers map[string]*ConfigDiffRenderer `hcl:"diff_renderer"`
+
+	// SecretsHelpers is the external program that values marked as secret
+	// are saved in instead of the state. Only one of these is allowed across
+	// the whole configuration.
+	SecretsHelpers map[string]*ConfigSecretsHelper `hcl:"secrets_helper"`
+
 	// ProviderInstallation represents any provider_installation blocks
 	// in the configuration. Only one of these is allowed across the whole
 	// configuration, but we decode into a slice here so that we can handle
@@ -80,6 +131,28 @@ type ConfigCredentialsHelper struct {
 	Args []string `hcl:"args"`
 }
 
+// ConfigPlanAnalyzer is the structure of the "plan_analyzer" nested block
+// within the CLI configuration.
+type ConfigPlanAnalyzer struct {
+	Command string   `hcl:"command"`
+	Args    []string `hcl:"args"`
+}
+
+// ConfigDiffRenderer is the structure of the "diff_renderer" nested block
+// within the CLI configuration.
+type ConfigDiffRenderer struct {
+	Command       string   `hcl:"command"`
+	Args          []string `hcl:"args"`
+	ResourceTypes []string `hcl:"resource_types"`
+}
+
+// ConfigSecretsHelper is the structure of the "secrets_helper" nested block
+// within the CLI configuration.
+type ConfigSecretsHelper struct {
+	Command string   `hcl:"command"`
+	Args    []string `hcl:"args"`
+}
+
 // BuiltinConfig is the built-in defaults for the configuration. These
 // can be overridden by user configurations.
 var BuiltinConfig Config
@@ -195,6 +268,27 @@ func loadConfigFile(path string) (*Config, tfdiags.Diagnostics) {
 	if result.PluginCacheDir != "" {
 		result.PluginCacheDir = os.ExpandEnv(result.PluginCacheDir)
 	}
+	if result.PluginSchemaCacheDir != "" {
+		result.PluginSchemaCacheDir = os.ExpandEnv(result.PluginSchemaCacheDir)
+	}
+	if result.ModulePackageCacheDir != "" {
+		result.ModulePackageCacheDir = os.ExpandEnv(result.ModulePackageCacheDir)
+	}
+	for _, analyzer := range result.PlanAnalyzers {
+		if analyzer != nil {
+			analyzer.Command = os.ExpandEnv(analyzer.Command)
+		}
+	}
+	for _, renderer := range result.DiffRenderers {
+		if renderer != nil {
+			renderer.Command = os.ExpandEnv(renderer.Command)
+		}
+	}
+	for _, helper := range result.SecretsHelpers {
+		if helper != nil {
+			helper.Command = os.ExpandEnv(helper.Command)
+		}
+	}
 
 	return result, diags
 }
@@ -248,6 +342,18 @@ func envConfig(env map[string]string) *Config {
 		config.PluginCacheDir = envPluginCacheDir
 	}
 
+	if envSchemaCacheDir := env[pluginSchemaCacheDirEnvVar]; envSchemaCacheDir != "" {
+		config.PluginSchemaCacheDir = envSchemaCacheDir
+	}
+
+	if envModuleCacheDir := env[modulePackageCacheDirEnvVar]; envModuleCacheDir != "" {
+		config.ModulePackageCacheDir = envModuleCacheDir
+	}
+
+	if envAutoCreate := env[workspaceAutoCreateEnvVar]; envAutoCreate != "" {
+		config.WorkspaceAutoCreate = envAutoCreate
+	}
+
 	if envMayBreak := env[pluginCacheMayBreakLockFileEnvVar]; envMayBreak != "" && envMayBreak != "0" {
 		// This is an environment variable analog to the
 		// plugin_cache_may_break_dependency_lock_file setting. If either this
@@ -320,6 +426,61 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		)
 	}
 
+	// Check that all "plan_analyzer" blocks have a program to run.
+	for name, analyzer := range c.PlanAnalyzers {
+		if analyzer == nil || analyzer.Command == "" {
+			diags = diags.Append(
+				fmt.Errorf("The plan_analyzer %q block must set the command to run", name),
+			)
+		}
+	}
+
+	// Check that all "diff_renderer" blocks have a program to run, and that
+	// each resource type has only one diff renderer.
+	diffRendererNames := make([]string, 0, len(c.DiffRenderers))
+	for name := range c.DiffRenderers {
+		diffRendererNames = append(diffRendererNames, name)
+	}
+	sort.Strings(diffRendererNames)
+	diffRendererTypes := make(map[string]string)
+	for _, name := range diffRendererNames {
+		renderer := c.DiffRenderers[name]
+		if renderer == nil || renderer.Command == "" {
+			diags = diags.Append(
+				fmt.Errorf("The diff_renderer %q block must set the command to run", name),
+			)
+			continue
+		}
+		if len(renderer.ResourceTypes) == 0 {
+			diags = diags.Append(
+				fmt.Errorf("The diff_renderer %q block must set the resource types to render", name),
+			)
+		}
+		for _, typeName := range renderer.ResourceTypes {
+			if other, exists := diffRendererTypes[typeName]; exists {
+				diags = diags.Append(
+					fmt.Errorf("The diff_renderer %q block and the diff_renderer %q block both render the resource type %q", other, name, typeName),
+				)
+				continue
+			}
+			diffRendererTypes[typeName] = name
+		}
+	}
+
+	// Should have zero or one "secrets_helper" blocks, with a program to run.
+	if len(c.SecretsHelpers) > 1 {
+		diags = diags.Append(
+			fmt.Errorf("No more than one secrets_helper block may be specified"),
+		)
+	}
+	for name, helper := range c.SecretsHelpers {
+		if helper == nil || helper.Command == "" {
+			diags = diags.Append(
+				fmt.Errorf("The secrets_helper %q block must set the command to run", name),
+			)
+		}
+	}
+
 	// Should have zero or one "provider_installation" blocks
 	if len(c.ProviderInstallation) > 1 {
 		diags = diags.Append(
@@ -336,6 +497,23 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		}
 	}
 
+	switch c.WorkspaceAutoCreate {
+	case "", WorkspaceAutoCreateAllow, WorkspaceAutoCreateError, WorkspaceAutoCreatePrompt:
+	default:
+		diags = diags.Append(
+			fmt.Errorf("The workspace_auto_create setting must be %q, %q, or %q, not %q", WorkspaceAutoCreateAllow, WorkspaceAutoCreateError, WorkspaceAutoCreatePrompt, c.WorkspaceAutoCreate),
+		)
+	}
+
+	if c.ModulePackageCacheDir != "" {
+		_, err := os.Stat(c.ModulePackageCacheDir)
+		if err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The specified module package cache dir %s cannot be opened: %w", c.ModulePackageCacheDir, err),
+			)
+		}
+	}
+
 	return diags
 }
 
@@ -369,6 +547,21 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.PluginCacheDir = c2.PluginCacheDir
 	}
 
+	result.PluginSchemaCacheDir = c.PluginSchemaCacheDir
+	if result.PluginSchemaCacheDir == "" {
+		result.PluginSchemaCacheDir = c2.PluginSchemaCacheDir
+	}
+
+	result.ModulePackageCacheDir = c.ModulePackageCacheDir
+	if result.ModulePackageCacheDir == "" {
+		result.ModulePackageCacheDir = c2.ModulePackageCacheDir
+	}
+
+	result.WorkspaceAutoCreate = c.WorkspaceAutoCreate
+	if result.WorkspaceAutoCreate == "" {
+		result.WorkspaceAutoCreate = c2.WorkspaceAutoCreate
+	}
+
 	if c.PluginCacheMayBreakDependencyLockFile || c2.PluginCacheMayBreakDependencyLockFile {
 		// This setting saturates to "on"; once either configuration sets it,
 		// there is no way to override it back to off again.
@@ -408,6 +601,36 @@ func (c *Config) Merge(c2 *Config) *Config {
 		}
 	}
 
+	if (len(c.PlanAnalyzers) + len(c2.PlanAnalyzers)) > 0 {
+		result.PlanAnalyzers = make(map[string]*ConfigPlanAnalyzer)
+		for name, analyzer := range c.PlanAnalyzers {
+			result.PlanAnalyzers[name] = analyzer
+		}
+		for name, analyzer := range c2.PlanAnalyzers {
+			result.PlanAnalyzers[name] = analyzer
+		}
+	}
+
+	if (len(c.DiffRenderers) + len(c2.DiffRenderers)) > 0 {
+		result.DiffRenderers = make(map[string]*ConfigDiffRenderer)
+		for name, renderer := range c.DiffRenderers {
+			result.DiffRenderers[name] = renderer
+		}
+		for name, renderer := range c2.DiffRenderers {
+			result.DiffRenderers[name] = renderer
+		}
+	}
+
+	if (len(c.SecretsHelpers) + len(c2.SecretsHelpers)) > 0 {
+		result.SecretsHelpers = make(map[string]*ConfigSecretsHelper)
+		for name, helper := range c.SecretsHelpers {
+			result.SecretsHelpers[name] = helper
+		}
+		for name, helper := range c2.SecretsHelpers {
+			result.SecretsHelpers[name] = helper
+		}
+	}
+
 	if (len(c.ProviderInstallation) + len(c2.ProviderInstallation)) > 0 {
 		result.ProviderInstallation = append(result.ProviderInstallation, c.ProviderInstallation...)
 		result.ProviderInstallation = append(result.ProviderInstallation, c2.ProviderInstallation...)
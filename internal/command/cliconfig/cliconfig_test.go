@@ -101,6 +101,30 @@ func TestEnvConfig(t *testing.T) {
 				PluginCacheDir: "boop",
 			},
 		},
+		"TF_PLUGIN_SCHEMA_CACHE_DIR=schemas": {
+			map[string]string{
+				"TF_PLUGIN_SCHEMA_CACHE_DIR": "schemas",
+			},
+			&Config{
+				PluginSchemaCacheDir: "schemas",
+			},
+		},
+		"TF_MODULE_PACKAGE_CACHE_DIR=modules": {
+			map[string]string{
+				"TF_MODULE_PACKAGE_CACHE_DIR": "modules",
+			},
+			&Config{
+				ModulePackageCacheDir: "modules",
+			},
+		},
+		"TF_WORKSPACE_AUTO_CREATE=error": {
+			map[string]string{
+				"TF_WORKSPACE_AUTO_CREATE": "error",
+			},
+			&Config{
+				WorkspaceAutoCreate: WorkspaceAutoCreateError,
+			},
+		},
 		"TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE=anything_except_zero": {
 			map[string]string{
 				"TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE": "anything_except_zero",
@@ -268,6 +292,49 @@ func TestLoadConfig_credentials(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_planAnalyzers(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "plan-analyzers"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+			"cost": {
+				Command: "cost-estimate",
+				Args:    []string{"--currency", "EUR"},
+			},
+			"policy": {
+				Command: "/usr/local/bin/policy-check",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_secretsHelper(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "secrets-helper"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		SecretsHelpers: map[string]*ConfigSecretsHelper{
+			"vault": {
+				Command: "/usr/local/bin/tofu-secrets-vault",
+				Args:    []string{"--mount", "secret"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := map[string]struct {
 		Config    *Config
@@ -334,6 +401,73 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // no more than one credentials_helper block allowed
 		},
+		"plan analyzer good": {
+			&Config{
+				PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+					"cost": {Command: "cost-estimate"},
+				},
+			},
+			0,
+		},
+		"plan analyzer without command": {
+			&Config{
+				PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+					"cost": {Args: []string{"--json"}},
+				},
+			},
+			1, // plan_analyzer block must set the command
+		},
+		"diff renderer good": {
+			&Config{
+				DiffRenderers: map[string]*ConfigDiffRenderer{
+					"k8s": {Command: "k8s-diff", ResourceTypes: []string{"kubernetes_manifest"}},
+				},
+			},
+			0,
+		},
+		"diff renderer without command or resource types": {
+			&Config{
+				DiffRenderers: map[string]*ConfigDiffRenderer{
+					"k8s":    {ResourceTypes: []string{"kubernetes_manifest"}},
+					"policy": {Command: "policy-diff"},
+				},
+			},
+			2, // diff_renderer block must set the command and the resource types
+		},
+		"diff renderers for the same resource type": {
+			&Config{
+				DiffRenderers: map[string]*ConfigDiffRenderer{
+					"k8s":   {Command: "k8s-diff", ResourceTypes: []string{"kubernetes_manifest"}},
+					"other": {Command: "other-diff", ResourceTypes: []string{"kubernetes_manifest"}},
+				},
+			},
+			1, // a resource type can have only one diff renderer
+		},
+		"secrets helper good": {
+			&Config{
+				SecretsHelpers: map[string]*ConfigSecretsHelper{
+					"vault": {Command: "tofu-secrets-vault"},
+				},
+			},
+			0,
+		},
+		"secrets helper too many": {
+			&Config{
+				SecretsHelpers: map[string]*ConfigSecretsHelper{
+					"vault": {Command: "tofu-secrets-vault"},
+					"aws":   {Command: "tofu-secrets-aws"},
+				},
+			},
+			1, // no more than one secrets_helper block allowed
+		},
+		"secrets helper without command": {
+			&Config{
+				SecretsHelpers: map[string]*ConfigSecretsHelper{
+					"vault": {Args: []string{"--mount", "secret"}},
+				},
+			},
+			1, // secrets_helper block must set the command
+		},
 		"provider_installation good none": {
 			&Config{
 				ProviderInstallation: nil,
@@ -357,6 +491,18 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // no more than one provider_installation block allowed
 		},
+		"workspace_auto_create good": {
+			&Config{
+				WorkspaceAutoCreate: WorkspaceAutoCreatePrompt,
+			},
+			0,
+		},
+		"workspace_auto_create invalid": {
+			&Config{
+				WorkspaceAutoCreate: "sometimes",
+			},
+			1, // The workspace_auto_create setting must be one of the known values
+		},
 		"plugin_cache_dir does not exist": {
 			&Config{
 				PluginCacheDir: "fake",
@@ -403,6 +549,9 @@ func TestConfig_Merge(t *testing.T) {
 		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
 			"buz": {},
 		},
+		PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+			"cost": {Command: "cost-estimate"},
+		},
 		ProviderInstallation: []*ProviderInstallation{
 			{
 				Methods: []*ProviderInstallationMethod{
@@ -441,6 +590,12 @@ func TestConfig_Merge(t *testing.T) {
 		CredentialsHelpers: map[string]*ConfigCredentialsHelper{
 			"biz": {},
 		},
+		PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+			"policy": {Command: "policy-check", Args: []string{"-strict"}},
+		},
+		SecretsHelpers: map[string]*ConfigSecretsHelper{
+			"vault": {Command: "tofu-secrets-vault"},
+		},
 		ProviderInstallation: []*ProviderInstallation{
 			{
 				Methods: []*ProviderInstallationMethod{
@@ -449,6 +604,9 @@ func TestConfig_Merge(t *testing.T) {
 			},
 		},
 		PluginCacheMayBreakDependencyLockFile: true,
+		PluginSchemaCacheDir:                  "schemas",
+		ModulePackageCacheDir:                 "modules",
+		WorkspaceAutoCreate:                   WorkspaceAutoCreateError,
 	}
 
 	expected := &Config{
@@ -485,6 +643,13 @@ func TestConfig_Merge(t *testing.T) {
 			"buz": {},
 			"biz": {},
 		},
+		PlanAnalyzers: map[string]*ConfigPlanAnalyzer{
+			"cost":   {Command: "cost-estimate"},
+			"policy": {Command: "policy-check", Args: []string{"-strict"}},
+		},
+		SecretsHelpers: map[string]*ConfigSecretsHelper{
+			"vault": {Command: "tofu-secrets-vault"},
+		},
 		ProviderInstallation: []*ProviderInstallation{
 			{
 				Methods: []*ProviderInstallationMethod{
@@ -504,6 +669,9 @@ func TestConfig_Merge(t *testing.T) {
 			},
 		},
 		PluginCacheMayBreakDependencyLockFile: true,
+		PluginSchemaCacheDir:                  "schemas",
+		ModulePackageCacheDir:                 "modules",
+		WorkspaceAutoCreate:                   WorkspaceAutoCreateError,
 	}
 
 	actual := c1.Merge(c2)
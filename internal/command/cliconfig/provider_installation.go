@@ -38,6 +38,13 @@ type ProviderInstallation struct {
 	// providers, because they are still subject to version constraints and
 	// checksum verification.
 	DevOverrides map[addrs.Provider]getproviders.PackageLocalDir
+
+	// PlatformFallbacks are rules allowing packages built for other
+	// platforms to be installed for a subset of providers when there is no
+	// package for the current platform, for systems that can run those
+	// packages under emulation. Unlike Methods, these don't affect where
+	// OpenTofu looks for packages.
+	PlatformFallbacks []*ProviderInstallationPlatformFallback
 }
 
 // decodeProviderInstallationFromConfig uses the HCL AST API directly to
@@ -262,6 +269,50 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 
 				continue // We won't add anything to pi.MethodConfigs for this one
 
+			case "platform_fallback":
+				type BodyContent struct {
+					Platforms []string `hcl:"platforms"`
+					Include   []string `hcl:"include"`
+					Exclude   []string `hcl:"exclude"`
+				}
+				var bodyContent BodyContent
+				err := hcl.DecodeObject(&bodyContent, methodBody)
+				if err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: %s.", methodTypeStr, block.Pos(), err),
+					))
+					continue
+				}
+				if len(bodyContent.Platforms) == 0 {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: \"platforms\" argument is required.", methodTypeStr, block.Pos()),
+					))
+					continue
+				}
+				fallback := &ProviderInstallationPlatformFallback{
+					Include: bodyContent.Include,
+					Exclude: bodyContent.Exclude,
+				}
+				for _, rawPlatform := range bodyContent.Platforms {
+					platform, err := getproviders.ParsePlatform(rawPlatform)
+					if err != nil {
+						diags = diags.Append(tfdiags.Sourceless(
+							tfdiags.Error,
+							"Invalid provider_installation method block",
+							fmt.Sprintf("Invalid %s block at %s: invalid platform %q: %s.", methodTypeStr, block.Pos(), rawPlatform, err),
+						))
+						continue
+					}
+					fallback.Platforms = append(fallback.Platforms, platform)
+				}
+				pi.PlatformFallbacks = append(pi.PlatformFallbacks, fallback)
+
+				continue // This isn't an installation method either
+
 			default:
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
@@ -296,6 +347,14 @@ type ProviderInstallationMethod struct {
 	Exclude  []string `hcl:"exclude"`
 }
 
+// ProviderInstallationPlatformFallback represents a platform_fallback block
+// inside a provider_installation block.
+type ProviderInstallationPlatformFallback struct {
+	Platforms []getproviders.Platform
+	Include   []string
+	Exclude   []string
+}
+
 // ProviderInstallationLocation is an interface type representing the
 // different installation location types. The concrete implementations of
 // this interface are:
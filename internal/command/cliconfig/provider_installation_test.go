@@ -59,17 +59,57 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_providerInstallationPlatformFallback(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-installation-platform-fallback"))
+	if diags.HasErrors() {
+		t.Errorf("unexpected diagnostics: %s", diags.Err().Error())
+	}
+
+	want := &Config{
+		ProviderInstallation: []*ProviderInstallation{
+			{
+				Methods: []*ProviderInstallationMethod{
+					{
+						Location: ProviderInstallationDirect,
+					},
+				},
+				PlatformFallbacks: []*ProviderInstallationPlatformFallback{
+					{
+						Platforms: []getproviders.Platform{
+							{OS: "darwin", Arch: "amd64"},
+						},
+						Include: []string{"example.com/*/*"},
+					},
+					{
+						Platforms: []getproviders.Platform{
+							{OS: "linux", Arch: "amd64"},
+							{OS: "linux", Arch: "386"},
+						},
+						Exclude: []string{"example.com/*/*"},
+					},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
 func TestLoadConfig_providerInstallationErrors(t *testing.T) {
 	_, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-installation-errors"))
-	want := `7 problems:
+	want := `9 problems:
 
 - Invalid provider_installation method block: Unknown provider installation method "not_a_thing" at 2:3.
 - Invalid provider_installation method block: Invalid filesystem_mirror block at 1:1: "path" argument is required.
 - Invalid provider_installation method block: Invalid network_mirror block at 1:1: "url" argument is required.
+- Invalid provider_installation method block: Invalid platform_fallback block at 1:1: "platforms" argument is required.
+- Invalid provider_installation method block: Invalid platform_fallback block at 1:1: invalid platform "nope": must be two words separated by an underscore.
 - Invalid provider_installation method block: The items inside the provider_installation block at 1:1 must all be blocks.
 - Invalid provider_installation method block: The blocks inside the provider_installation block at 1:1 may not have any labels.
-- Invalid provider_installation block: The provider_installation block at 9:1 must not have any labels.
-- Invalid provider_installation block: The provider_installation block at 11:1 must not be introduced with an equals sign.`
+- Invalid provider_installation block: The provider_installation block at 11:1 must not have any labels.
+- Invalid provider_installation block: The provider_installation block at 13:1 must not be introduced with an equals sign.`
 
 	// The above error messages include only line/column location information
 	// and not file location information because HCL 1 does not store
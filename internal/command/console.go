@@ -14,6 +14,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/repl"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -135,7 +136,19 @@ func (c *ConsoleCommand) Run(args []string) int {
 		ErrorWriter: os.Stderr,
 	}
 
-	evalOpts := &tofu.EvalOpts{}
+	// Provider functions can be called in the console expressions, so we'll
+	// start the providers as needed and shut them down when we're done.
+	funcs, err := c.providerFunctions()
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	defer funcs.Close()
+
+	evalOpts := &tofu.EvalOpts{
+		ProviderFunctions: funcs,
+	}
 	if lr.PlanOpts != nil {
 		// the LocalRun type is built primarily to support the main operations,
 		// so the variable values end up in the "PlanOpts" even though we're
@@ -169,7 +182,15 @@ func (c *ConsoleCommand) Run(args []string) int {
 
 	// IO Loop
 	session := &repl.Session{
-		Scope: scope,
+		Scope:  scope,
+		Config: lr.Config,
+		ModuleScope: func(addr addrs.ModuleInstance) (*lang.Scope, tfdiags.Diagnostics) {
+			scope, diags := lr.Core.Eval(ctx, lr.Config, lr.InputState, addr, evalOpts)
+			if scope != nil {
+				scope.ConsoleMode = true
+			}
+			return scope, diags
+		},
 	}
 
 	// Determine if stdin is a pipe. If so, we evaluate directly.
@@ -223,6 +244,11 @@ Usage: tofu [global options] console [options]
 
   This command will never modify your state.
 
+  In interactive mode, expressions can span multiple lines, <tab> completes
+  the names of objects and functions, and the history of the lines entered is
+  kept in the OpenTofu CLI configuration directory across sessions. Type
+  ":module ADDRESS" to evaluate expressions in a child module instance.
+
 Options:
 
   -compact-warnings      If OpenTofu produces any warnings that are not
@@ -9,9 +9,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"unicode"
 
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/repl"
 
 	"github.com/chzyer/readline"
@@ -21,10 +25,12 @@ import (
 func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 	// Configure input
 	l, err := readline.NewEx(&readline.Config{
-		Prompt:            "> ",
+		Prompt:            consolePrompt(session),
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "exit",
+		HistoryFile:       consoleHistoryFile(),
 		HistorySearchFold: true,
+		AutoComplete:      consoleCompleter{session: session},
 		Stdin:             os.Stdin,
 		Stdout:            os.Stdout,
 		Stderr:            os.Stderr,
@@ -71,8 +77,8 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 			}
 
 			// clear the state and buffer as we have executed a command
-			// we also reset the prompt
-			l.SetPrompt("> ")
+			// we also reset the prompt, which shows the current module
+			l.SetPrompt(consolePrompt(session))
 
 			ui.Output(out)
 		}
@@ -80,3 +86,61 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 
 	return 0
 }
+
+// consolePrompt returns the prompt for a new expression, which shows the
+// module instance that the expression is evaluated in unless that is the root
+// module.
+func consolePrompt(session *repl.Session) string {
+	if addr := session.Module(); !addr.IsRoot() {
+		return addr.String() + "> "
+	}
+	return "> "
+}
+
+// consoleHistoryFile returns the path of the file that keeps the history of
+// the console across sessions, or an empty string to keep no history if the
+// CLI configuration directory isn't available.
+func consoleHistoryFile() string {
+	dir, err := cliconfig.ConfigDir()
+	if err != nil {
+		log.Printf("[WARN] Not saving the console history: %s", err)
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("[WARN] Not saving the console history: %s", err)
+		return ""
+	}
+	return filepath.Join(dir, consoleHistoryFilename)
+}
+
+const consoleHistoryFilename = "console_history"
+
+// consoleCompleter completes the reference or function name that ends at
+// the cursor. Where there's nothing to complete it inserts a tab instead, so
+// that pasting indented expressions still works.
+type consoleCompleter struct {
+	session *repl.Session
+}
+
+var _ readline.AutoCompleter = consoleCompleter{}
+
+func (c consoleCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	start := pos
+	for start > 0 && isConsoleCompletionRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+	if prefix == "" {
+		return [][]rune{{'\t'}}, 0
+	}
+
+	var ret [][]rune
+	for _, completion := range c.session.Completions(prefix) {
+		ret = append(ret, []rune(strings.TrimPrefix(completion, prefix)))
+	}
+	return ret, pos - start
+}
+
+func isConsoleCompletionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:", r)
+}
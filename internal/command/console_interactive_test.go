@@ -7,12 +7,15 @@ package command
 
 import (
 	"bytes"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/repl"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -119,3 +122,23 @@ func TestConsole_multiline_interactive(t *testing.T) {
 		})
 	}
 }
+
+func TestConsoleCompleter(t *testing.T) {
+	session := &repl.Session{
+		Scope: &lang.Scope{},
+	}
+	completer := consoleCompleter{session: session}
+
+	line := []rune("1 + upp")
+	got, length := completer.Do(line, len(line))
+	if length != 3 {
+		t.Errorf("wrong length %d; want 3", length)
+	}
+	if !slices.ContainsFunc(got, func(c []rune) bool { return string(c) == "er(" }) {
+		t.Errorf("missing completion for upper in %q", got)
+	}
+
+	if got, length := completer.Do(line, 4); len(got) != 1 || string(got[0]) != "\t" || length != 0 {
+		t.Errorf("wanted a tab after a space, got %q, %d", got, length)
+	}
+}
@@ -350,3 +350,48 @@ func TestConsole_multiline_pipe(t *testing.T) {
 		})
 	}
 }
+
+func TestConsole_providerFunctions(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("console-provider-functions"), td)
+	defer testChdir(t, td)()
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		Functions: map[string]providers.FunctionSpec{
+			"upper": {
+				Parameters: []providers.FunctionParameterSpec{{
+					Name: "input",
+					Type: cty.String,
+				}},
+				Return: cty.String,
+			},
+		},
+	}
+	p.CallFunctionFn = func(req providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+		resp.Result = cty.StringVal(strings.ToUpper(req.Arguments[0].AsString()))
+		return resp
+	}
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &ConsoleCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	var output bytes.Buffer
+	defer testStdinPipe(t, strings.NewReader("provider::test::upper(local.name)\n"))()
+	outCloser := testStdoutCapture(t, &output)
+	code := c.Run(nil)
+	outCloser()
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if got, want := output.String(), "\"EXAMPLE\"\n"; got != want {
+		t.Fatalf("bad: %q, expected %q", got, want)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/profiling"
+	"github.com/opentofu/opentofu/version"
+)
+
+// DebugProfileCommand is a Command implementation that runs another command
+// while profiling it, and bundles the profiles into an archive.
+type DebugProfileCommand struct {
+	Meta
+
+	// command is the program, and any arguments that come before the
+	// command to profile, that runs the command to profile. It's the
+	// running OpenTofu executable unless set by tests.
+	command []string
+}
+
+// debugProfileFormatVersion is the version of the summary file in the
+// archives written by "tofu debug profile".
+const debugProfileFormatVersion = "1.0"
+
+// debugProfileSummaryFile is the name of the summary file in the archives
+// written by "tofu debug profile".
+const debugProfileSummaryFile = "summary.json"
+
+type debugProfileSummary struct {
+	FormatVersion string   `json:"format_version"`
+	TofuVersion   string   `json:"tofu_version"`
+	GoVersion     string   `json:"go_version"`
+	Platform      string   `json:"platform"`
+	NumCPU        int      `json:"num_cpu"`
+	Command       []string `json:"command"`
+	ExitCode      int      `json:"exit_code"`
+	StartedAt     string   `json:"started_at"`
+	DurationMS    float64  `json:"duration_ms"`
+}
+
+func (c *DebugProfileCommand) Run(args []string) int {
+	var outPath string
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("debug profile")
+	cmdFlags.StringVar(&outPath, "out", "", "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) == 0 {
+		c.Ui.Error("The debug profile command expects the command to profile, such as \"plan\".\n")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	started := time.Now()
+	if outPath == "" {
+		outPath = fmt.Sprintf("tofu-profile-%s.tar.gz", started.UTC().Format("20060102T150405Z"))
+	}
+
+	dir, err := os.MkdirTemp("", "tofu-profile")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to create a temporary directory for the profiles: %s", err))
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	command := c.command
+	if command == nil {
+		exe, err := os.Executable()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to find the OpenTofu executable: %s", err))
+			return 1
+		}
+		command = []string{exe}
+	}
+
+	cmd := exec.Command(command[0], append(command[1:], args...)...)
+	cmd.Env = append(debugProfileEnv(os.Environ()), profiling.DirEnvVar+"="+dir)
+	cmd.Stdin = c.Streams.Stdin.File
+	cmd.Stdout = c.Streams.Stdout.File
+	cmd.Stderr = c.Streams.Stderr.File
+
+	// The command gets interrupts directly from the terminal, so we only
+	// wait for it to exit.
+	exitCode := 0
+	err = cmd.Run()
+	duration := time.Since(started)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to run the command to profile: %s", err))
+		return 1
+	}
+
+	summary := debugProfileSummary{
+		FormatVersion: debugProfileFormatVersion,
+		TofuVersion:   version.String(),
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "_" + runtime.GOARCH,
+		NumCPU:        runtime.NumCPU(),
+		Command:       args,
+		ExitCode:      exitCode,
+		StartedAt:     started.UTC().Format(time.RFC3339),
+		DurationMS:    float64(duration) / float64(time.Millisecond),
+	}
+	src, err := json.MarshalIndent(summary, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, debugProfileSummaryFile), src, 0644)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write the profile summary: %s", err))
+		return 1
+	}
+
+	if err := writeDebugProfileArchive(outPath, dir); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write the profile archive: %s", err))
+		return 1
+	}
+	// The command's own output may be machine-readable, so we report the
+	// archive on stderr.
+	c.Streams.Eprintf("\nProfiles written to %s\n", outPath)
+
+	return exitCode
+}
+
+// debugProfileEnv returns the given environment without the variables that
+// request profiles, so that they don't conflict with the profiles that
+// "tofu debug profile" requests.
+func debugProfileEnv(environ []string) []string {
+	var ret []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == profiling.CPUProfileEnvVar || name == profiling.DirEnvVar {
+			continue
+		}
+		ret = append(ret, kv)
+	}
+	return ret
+}
+
+// writeDebugProfileArchive writes the files in the given directory into a
+// gzip-compressed tar archive at the given path.
+func writeDebugProfileArchive(path, dir string) (err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := addDebugProfileArchiveFile(tw, filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addDebugProfileArchiveFile(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = "tofu-profile/" + info.Name()
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func (c *DebugProfileCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] debug profile [options] <command> [args]
+
+  Runs the given OpenTofu command while profiling it, and writes the profiles
+  into a gzip-compressed tar archive that can be attached to a report about
+  a performance issue.
+
+  The archive contains a CPU profile, a heap profile and a goroutine profile
+  in the pprof format, a Go execution trace, the timings of the nodes of the
+  graphs that OpenTofu walked, and a summary of the run. The exit code is the
+  exit code of the given command.
+
+  The profiles can include the names of your resources and modules, but not
+  their values.
+
+Options:
+
+  -out=path    Path of the archive to write. Defaults to a file named after
+               the current time in the current directory.
+
+Example:
+
+  tofu debug profile -out=plan-profile.tar.gz plan -refresh=false
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DebugProfileCommand) Synopsis() string {
+	return "Profile another command for a performance issue report"
+}
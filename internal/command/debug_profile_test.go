@@ -0,0 +1,158 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/profiling"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+// debugProfileHelperEnvVar makes TestDebugProfile_helperProcess act as the
+// command that TestDebugProfile profiles.
+const debugProfileHelperEnvVar = "TF_TEST_DEBUG_PROFILE_HELPER"
+
+func TestDebugProfile(t *testing.T) {
+	t.Setenv(debugProfileHelperEnvVar, "1")
+	t.Setenv(profiling.CPUProfileEnvVar, filepath.Join(t.TempDir(), "ignored.pprof"))
+	outPath := filepath.Join(t.TempDir(), "profile.tar.gz")
+
+	streams, done := terminal.StreamsForTesting(t)
+	ui := cli.NewMockUi()
+	c := &DebugProfileCommand{
+		Meta: Meta{
+			Ui:      ui,
+			Streams: streams,
+		},
+		command: []string{os.Args[0], "-test.run=^TestDebugProfile_helperProcess$", "--"},
+	}
+
+	code := c.Run([]string{"-out=" + outPath, "plan", "-refresh=false"})
+	output := done(t)
+	if code != 3 {
+		t.Fatalf("wrong exit code %d; want 3\n%s\n%s", code, ui.ErrorWriter.String(), output.All())
+	}
+	if got, want := output.Stdout(), "args: plan -refresh=false\n"; !strings.Contains(got, want) {
+		t.Errorf("the command's output is missing\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := output.Stderr(), "Profiles written to "+outPath; !strings.Contains(got, want) {
+		t.Errorf("the archive is not reported\ngot:  %q\nwant: %q", got, want)
+	}
+
+	files := readDebugProfileArchive(t, outPath)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	wantNames := []string{"tofu-profile/" + profiling.GraphNodesFile, "tofu-profile/" + debugProfileSummaryFile}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Fatalf("wrong files in the archive\n%s", diff)
+	}
+
+	var summary debugProfileSummary
+	if err := json.Unmarshal(files["tofu-profile/"+debugProfileSummaryFile], &summary); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"plan", "-refresh=false"}, summary.Command); diff != "" {
+		t.Errorf("wrong command in the summary\n%s", diff)
+	}
+	if summary.ExitCode != 3 {
+		t.Errorf("wrong exit code in the summary %d; want 3", summary.ExitCode)
+	}
+	if summary.FormatVersion != debugProfileFormatVersion {
+		t.Errorf("wrong format version %q", summary.FormatVersion)
+	}
+}
+
+func TestDebugProfile_noCommand(t *testing.T) {
+	ui := cli.NewMockUi()
+	c := &DebugProfileCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "expects the command to profile"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+// TestDebugProfile_helperProcess isn't a real test. It stands in for the
+// OpenTofu executable in TestDebugProfile.
+func TestDebugProfile_helperProcess(t *testing.T) {
+	if os.Getenv(debugProfileHelperEnvVar) == "" {
+		t.Skip("only runs as the command profiled by TestDebugProfile")
+	}
+
+	if v := os.Getenv(profiling.CPUProfileEnvVar); v != "" {
+		fmt.Fprintf(os.Stderr, "unexpected %s=%s\n", profiling.CPUProfileEnvVar, v)
+		os.Exit(1)
+	}
+	dir := os.Getenv(profiling.DirEnvVar)
+	if err := os.WriteFile(filepath.Join(dir, profiling.GraphNodesFile), []byte("[]"), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var args []string
+	for i, arg := range os.Args {
+		if arg == "--" {
+			args = os.Args[i+1:]
+			break
+		}
+	}
+	fmt.Printf("args: %s\n", strings.Join(args, " "))
+	os.Exit(3)
+}
+
+func readDebugProfileArchive(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = src
+	}
+	return files
+}
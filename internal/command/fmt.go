@@ -7,17 +7,20 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
 	"github.com/mitchellh/cli"
 
 	"github.com/opentofu/opentofu/internal/configs"
@@ -36,6 +39,16 @@ var (
 		".tftest.hcl",
 		".tofutest.hcl",
 	}
+
+	// fmtSupportedJSONExts are the extensions of the JSON syntax files that
+	// are also processed when the -json option is set.
+	fmtSupportedJSONExts = []string{
+		".tf.json",
+		".tofu.json",
+		".tfvars.json",
+		".tftest.json",
+		".tofutest.json",
+	}
 )
 
 // FmtCommand is a Command implementation that rewrites OpenTofu config
@@ -47,6 +60,7 @@ type FmtCommand struct {
 	diff      bool
 	check     bool
 	recursive bool
+	json      bool
 	input     io.Reader // STDIN if nil
 }
 
@@ -62,6 +76,7 @@ func (c *FmtCommand) Run(args []string) int {
 	cmdFlags.BoolVar(&c.diff, "diff", false, "diff")
 	cmdFlags.BoolVar(&c.check, "check", false, "check")
 	cmdFlags.BoolVar(&c.recursive, "recursive", false, "recursive")
+	cmdFlags.BoolVar(&c.json, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -139,7 +154,7 @@ func (c *FmtCommand) fmt(paths []string, stdin io.Reader, stdout io.Writer) tfdi
 			diags = diags.Append(dirDiags)
 		} else {
 			fmtd := false
-			for _, ext := range fmtSupportedExts {
+			for _, ext := range c.supportedExts() {
 				if strings.HasSuffix(path, ext) {
 					f, err := os.Open(path)
 					if err != nil {
@@ -186,16 +201,33 @@ func (c *FmtCommand) processFile(path string, r io.Reader, w io.Writer, isStdout
 	// diagnostic errors can include the source code snippet
 	c.registerSynthConfigSource(path, src)
 
-	// File must be parseable as HCL native syntax before we'll try to format
-	// it. If not, the formatter is likely to make drastic changes that would
-	// be hard for the user to undo.
-	_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
-	if syntaxDiags.HasErrors() {
-		diags = diags.Append(syntaxDiags)
-		return diags
-	}
+	var result []byte
+	if c.json && (isStdout || strings.HasSuffix(path, ".json")) {
+		// As for the native syntax, the file must be valid before we'll
+		// try to format it.
+		_, syntaxDiags := hcljson.Parse(src, path)
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(syntaxDiags)
+			return diags
+		}
 
-	result := c.formatSourceCode(src, path)
+		result, err = c.formatJSONSourceCode(src)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to format %s: %w", path, err))
+			return diags
+		}
+	} else {
+		// File must be parseable as HCL native syntax before we'll try to format
+		// it. If not, the formatter is likely to make drastic changes that would
+		// be hard for the user to undo.
+		_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(syntaxDiags)
+			return diags
+		}
+
+		result = c.formatSourceCode(src, path)
+	}
 
 	if !bytes.Equal(src, result) {
 		// Something was changed
@@ -265,7 +297,7 @@ func (c *FmtCommand) processDir(path string, stdout io.Writer) tfdiags.Diagnosti
 			continue
 		}
 
-		for _, ext := range fmtSupportedExts {
+		for _, ext := range c.supportedExts() {
 			if strings.HasSuffix(name, ext) {
 				f, err := os.Open(subPath)
 				if err != nil {
@@ -288,6 +320,28 @@ func (c *FmtCommand) processDir(path string, stdout io.Writer) tfdiags.Diagnosti
 	return diags
 }
 
+// supportedExts returns the extensions of the files that should be processed,
+// which includes those of the JSON syntax files only if -json is set.
+func (c *FmtCommand) supportedExts() []string {
+	if !c.json {
+		return fmtSupportedExts
+	}
+	return append(slices.Clone(fmtSupportedExts), fmtSupportedJSONExts...)
+}
+
+// formatJSONSourceCode rewrites a JSON syntax file with one property or
+// element per line and two spaces of indentation per level. Object properties
+// are deliberately not sorted, because their order is significant in the JSON
+// syntax: for example, it determines the order of provisioner blocks.
+func (c *FmtCommand) formatJSONSourceCode(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(src), "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
 // formatSourceCode is the formatting logic itself, applied to each file that
 // is selected (directly or indirectly) on the command line.
 func (c *FmtCommand) formatSourceCode(src []byte, filename string) []byte {
@@ -555,7 +609,7 @@ Usage: tofu [global options] fmt [options] [target...]
   Rewrites all OpenTofu configuration files to a canonical format. All
   configuration files (.tf), variables files (.tfvars), and testing files 
   (.tftest.hcl) are updated. JSON files (.tf.json, .tfvars.json, or 
-  .tftest.json) are not modified.
+  .tftest.json) are only modified if the -json option is set.
 
   By default, fmt scans the current directory for configuration files. If you
   provide a directory for the target argument, then fmt will scan that
@@ -563,8 +617,8 @@ Usage: tofu [global options] fmt [options] [target...]
   file. If you provide a single dash ("-"), then fmt will read from standard
   input (STDIN).
 
-  The content must be in the OpenTofu language native syntax; JSON is not
-  supported.
+  When reading from standard input, the content must be in the OpenTofu
+  language native syntax, or in the JSON syntax if -json is set.
 
 Options:
 
@@ -583,6 +637,9 @@ Options:
 
   -recursive     Also process files in subdirectories. By default, only the
                  given directory (or current directory) is processed.
+
+  -json          Also rewrite files in the JSON syntax to a canonical
+                 indentation, preserving the order of object properties.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -146,6 +146,117 @@ func TestFmt(t *testing.T) {
 	}
 }
 
+func TestFmt_JSONFiles(t *testing.T) {
+	const inSuffix = "_in.tf.json"
+	const outSuffix = "_out.tf.json"
+	const gotSuffix = "_got.tf.json"
+	entries, err := os.ReadDir("testdata/fmt-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range entries {
+		filename := info.Name()
+		if !strings.HasSuffix(filename, inSuffix) {
+			continue
+		}
+		testName := filename[:len(filename)-len(inSuffix)]
+		t.Run(testName, func(t *testing.T) {
+			inFile := filepath.Join("testdata", "fmt-json", testName+inSuffix)
+			wantFile := filepath.Join("testdata", "fmt-json", testName+outSuffix)
+			gotFile := filepath.Join(tmpDir, testName+gotSuffix)
+			input, err := os.ReadFile(inFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(wantFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(gotFile, input, 0700)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ui := cli.NewMockUi()
+			c := &FmtCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+				},
+			}
+			args := []string{"-json", gotFile}
+			if code := c.Run(args); code != 0 {
+				t.Fatalf("fmt command was unsuccessful:\n%s", ui.ErrorWriter.String())
+			}
+
+			got, err := os.ReadFile(gotFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFmt_JSONRequiresOption(t *testing.T) {
+	tempDir := testTempDir(t)
+	const src = `{"locals":{"a":1}}`
+	path := filepath.Join(tempDir, "main.tf.json")
+	if err := os.WriteFile(path, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{tempDir}); code != 0 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); got != "" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+
+	ui = cli.NewMockUi()
+	c.Meta.Ui = ui
+	if code := c.Run([]string{"-json", "-check", tempDir}); code != 3 {
+		t.Fatalf("wrong exit code %d; want 3. errors: \n%s", code, ui.ErrorWriter.String())
+	}
+}
+
+func TestFmt_JSONSyntaxError(t *testing.T) {
+	input := new(bytes.Buffer)
+	input.WriteString(`{"locals": {"a": 1,}}`)
+
+	ui := new(cli.MockUi)
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+		input: input,
+	}
+
+	if code := c.Run([]string{"-json", "-"}); code != 2 {
+		t.Fatalf("wrong exit code %d; want 2", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Trailing comma in object"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot: %s\nwant substring: %s", got, want)
+	}
+}
+
 func TestFmt_nonexist(t *testing.T) {
 	tempDir := fmtFixtureWriteDir(t)
 
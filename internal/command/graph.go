@@ -29,6 +29,7 @@ func (c *GraphCommand) Run(args []string) int {
 
 	var drawCycles bool
 	var graphTypeStr string
+	var formatStr string
 	var moduleDepth int
 	var verbose bool
 	var planPath string
@@ -40,6 +41,7 @@ func (c *GraphCommand) Run(args []string) int {
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.BoolVar(&drawCycles, "draw-cycles", false, "draw-cycles")
 	cmdFlags.StringVar(&graphTypeStr, "type", "", "type")
+	cmdFlags.StringVar(&formatStr, "format", "dot", "format")
 	cmdFlags.IntVar(&moduleDepth, "module-depth", -1, "module-depth")
 	cmdFlags.BoolVar(&verbose, "verbose", false, "verbose")
 	cmdFlags.StringVar(&planPath, "plan", "", "plan")
@@ -49,6 +51,24 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
+	var graphFormat func(*tofu.Graph, *dag.DotOpts) (string, error)
+	switch formatStr {
+	case "dot":
+		graphFormat = tofu.GraphDot
+	case "mermaid":
+		graphFormat = tofu.GraphMermaid
+	case "json":
+		graphFormat = tofu.GraphJSON
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported graph format",
+			`The -format=... argument must be either "dot", "mermaid", or "json".`,
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
 	configPath, err := modulePath(cmdFlags.Args())
 	if err != nil {
 		c.Ui.Error(err.Error())
@@ -225,7 +245,7 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
-	graphStr, err := tofu.GraphDot(g, &dag.DotOpts{
+	graphStr, err := graphFormat(g, &dag.DotOpts{
 		DrawCycles: drawCycles,
 		MaxDepth:   moduleDepth,
 		Verbose:    verbose,
@@ -255,9 +275,9 @@ Usage: tofu [global options] graph [options]
   Produces a representation of the dependency graph between different
   objects in the current configuration and state.
 
-  The graph is presented in the DOT language. The typical program that can
-  read this format is GraphViz, but many web services are also available
-  to read this format.
+  By default the graph is presented in the DOT language. The typical program
+  that can read this format is GraphViz, but many web services are also
+  available to read this format.
 
 Options:
 
@@ -267,6 +287,10 @@ Options:
   -draw-cycles     Highlight any cycles in the graph with colored edges.
                    This helps when diagnosing cycle errors.
 
+  -format=dot      Format of the output. Can be: dot, for the DOT language;
+                   mermaid, for a Mermaid flowchart that Markdown renderers
+                   can draw; or json, for a list of nodes and edges.
+
   -type=plan       Type of graph to output. Can be: plan, plan-refresh-only,
                    plan-destroy, or apply. By default OpenTofu chooses
 				   "plan", or "apply" if you also set the -plan=... option.
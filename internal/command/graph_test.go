@@ -42,6 +42,56 @@ func TestGraph(t *testing.T) {
 	}
 }
 
+func TestGraph_format(t *testing.T) {
+	tests := map[string]string{
+		"mermaid": `{"provider[#quot;registry.opentofu.org/hashicorp/test#quot;]"}`,
+		"json":    `"label": "provider[\"registry.opentofu.org/hashicorp/test\"]"`,
+	}
+
+	for format, want := range tests {
+		t.Run(format, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("graph"), td)
+			defer testChdir(t, td)()
+
+			ui := new(cli.MockUi)
+			c := &GraphCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+					Ui:               ui,
+				},
+			}
+
+			args := []string{"-format=" + format}
+			if code := c.Run(args); code != 0 {
+				t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+			}
+
+			output := ui.OutputWriter.String()
+			if !strings.Contains(output, want) {
+				t.Fatalf("output doesn't contain %s:\n%s", want, output)
+			}
+		})
+	}
+}
+
+func TestGraph_badFormat(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-format=svg"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Unsupported graph format"; !strings.Contains(got, want) {
+		t.Fatalf("missing error %q in:\n%s", want, got)
+	}
+}
+
 func TestGraph_multipleArgs(t *testing.T) {
 	ui := new(cli.MockUi)
 	c := &GraphCommand{
@@ -0,0 +1,752 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ImpactCommand is a Command implementation that reports which resources and
+// modules could be affected by changes to configuration files, using only
+// the references between objects in the configuration.
+type ImpactCommand struct {
+	Meta
+}
+
+// impactResult is the result of "tofu impact", which is also its JSON
+// output.
+type impactResult struct {
+	Changed           []impactChange   `json:"changed"`
+	AffectedResources []impactResource `json:"affected_resources"`
+	AffectedModules   []string         `json:"affected_modules"`
+
+	// IgnoredFiles are the changed files that aren't configuration files of
+	// any module in the configuration, such as templates, whose effects
+	// can't be analyzed.
+	IgnoredFiles []string `json:"ignored_files"`
+}
+
+// impactChange is an object whose declaration changed, or that was removed
+// from the configuration.
+type impactChange struct {
+	Address string `json:"address"`
+	Removed bool   `json:"removed,omitempty"`
+}
+
+// impactResource is a resource that could be affected by the changes. Via is
+// the chain of references through which a changed object affects it, starting
+// with the changed object, and is empty if the resource itself changed.
+type impactResource struct {
+	Address string   `json:"address"`
+	Via     []string `json:"via"`
+}
+
+func (c *ImpactCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	var gitRef string
+	var jsonOutput bool
+	cmdFlags := c.Meta.defaultFlagSet("impact")
+	cmdFlags.StringVar(&gitRef, "git-diff", "", "git-diff")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	files := cmdFlags.Args()
+	if (gitRef == "") == (len(files) == 0) {
+		c.Ui.Error("Either the changed files or the -git-diff option, but not both, must be given.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	config, configDiags := c.loadConfig(".")
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	var changes []impactFileChange
+	if gitRef != "" {
+		var err error
+		changes, err = impactGitChanges(ctx, gitRef)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to get changes from Git",
+				fmt.Sprintf("OpenTofu could not determine the files changed since %q: %s.", gitRef, err),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+	} else {
+		for _, file := range files {
+			path, err := filepath.Abs(file)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid file path",
+					fmt.Sprintf("OpenTofu could not resolve the path %q: %s.", file, err),
+				))
+				c.showDiagnostics(diags)
+				return 1
+			}
+			changes = append(changes, impactFileChange{Path: path, Display: file})
+		}
+	}
+
+	result, moreDiags := impactAnalyze(config, changes)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal impact to JSON: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+		return 0
+	}
+
+	c.showDiagnostics(diags)
+	c.showImpact(result)
+	return 0
+}
+
+func (c *ImpactCommand) showImpact(result *impactResult) {
+	if len(result.Changed) == 0 {
+		c.Ui.Output("No configuration objects changed.")
+	} else {
+		c.Ui.Output(c.Colorize().Color("[bold]Changed objects:"))
+		for _, change := range result.Changed {
+			if change.Removed {
+				c.Ui.Output(fmt.Sprintf("  %s (removed)", change.Address))
+			} else {
+				c.Ui.Output("  " + change.Address)
+			}
+		}
+
+		c.Ui.Output(c.Colorize().Color("\n[bold]Affected resources:"))
+		if len(result.AffectedResources) == 0 {
+			c.Ui.Output("  (none)")
+		}
+		for _, rs := range result.AffectedResources {
+			c.Ui.Output("  " + rs.Address)
+			if len(rs.Via) > 0 {
+				c.Ui.Output("    via " + strings.Join(rs.Via, " -> "))
+			}
+		}
+
+		c.Ui.Output(c.Colorize().Color("\n[bold]Affected modules:"))
+		if len(result.AffectedModules) == 0 {
+			c.Ui.Output("  (none)")
+		}
+		for _, mod := range result.AffectedModules {
+			c.Ui.Output("  " + mod)
+		}
+	}
+
+	if len(result.IgnoredFiles) > 0 {
+		c.Ui.Output("\nThe following changed files are not configuration files of any module in\nthe configuration, so their effects were not analyzed:")
+		for _, file := range result.IgnoredFiles {
+			c.Ui.Output("  " + file)
+		}
+	}
+}
+
+// impactFileChange is a changed file. If OldSource is set, then the objects
+// declared in the file are compared with those in the old source to find the
+// changed objects. Otherwise, all objects declared in the file are changed.
+type impactFileChange struct {
+	Path    string
+	Display string
+
+	HasOldSource bool
+	OldSource    []byte
+}
+
+// impactGitChanges returns the files that changed in the Git working tree of
+// the current directory since the given commit, including untracked files.
+func impactGitChanges(ctx context.Context, ref string) ([]impactFileChange, error) {
+	git := func(dir string, args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return out, err
+	}
+
+	top, err := git(".", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	topDir := strings.TrimSpace(string(top))
+	changed, err := git(topDir, "diff", "--name-only", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(topDir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var ret []impactFileChange
+	for _, name := range strings.Split(string(changed)+string(untracked), "\n") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(topDir, filepath.FromSlash(name))
+		change := impactFileChange{Path: path, Display: path, HasOldSource: true}
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			change.Display = rel
+		}
+		// If the file didn't exist in the given commit then it's new, and so
+		// its old source is empty.
+		if src, err := git(topDir, "show", ref+":"+name); err == nil {
+			change.OldSource = src
+		}
+		ret = append(ret, change)
+	}
+	return ret, nil
+}
+
+// impactAnalyze finds the objects that the given file changes change in the
+// given configuration, and the resources and modules that they could affect.
+func impactAnalyze(config *configs.Config, changes []impactFileChange) (*impactResult, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	g := buildImpactGraph(config)
+
+	// Each module directory can be called from more than one place in the
+	// configuration.
+	modules := make(map[string][]*configs.Config)
+	config.DeepEach(func(cfg *configs.Config) {
+		dir := impactRealPath(cfg.Module.SourceDir)
+		modules[dir] = append(modules[dir], cfg)
+	})
+
+	result := &impactResult{
+		Changed:           []impactChange{},
+		AffectedResources: []impactResource{},
+		AffectedModules:   []string{},
+		IgnoredFiles:      []string{},
+	}
+	changed := make(map[string]bool)
+	for _, change := range changes {
+		dir := impactRealPath(filepath.Dir(change.Path))
+		cfgs := modules[dir]
+		native := strings.HasSuffix(change.Path, ".tf") || strings.HasSuffix(change.Path, ".tofu")
+		jsonSyntax := strings.HasSuffix(change.Path, ".tf.json") || strings.HasSuffix(change.Path, ".tofu.json")
+		if len(cfgs) == 0 || !(native || jsonSyntax) {
+			result.IgnoredFiles = append(result.IgnoredFiles, change.Display)
+			continue
+		}
+
+		var objs []string
+		if native {
+			newSrc, err := os.ReadFile(change.Path)
+			if err != nil && !os.IsNotExist(err) {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to read changed file",
+					fmt.Sprintf("OpenTofu could not read %s: %s.", change.Display, err),
+				))
+				continue
+			}
+			newObjs, moreDiags := impactFileObjects(change.Path, newSrc)
+			diags = diags.Append(moreDiags)
+			oldObjs := map[string]string{}
+			if change.HasOldSource {
+				// The old version is only used for comparison, so it's fine
+				// if it was invalid.
+				oldObjs, _ = impactFileObjects(change.Path, change.OldSource)
+			}
+			for addr, src := range newObjs {
+				if oldSrc, ok := oldObjs[addr]; !ok || oldSrc != src {
+					objs = append(objs, addr)
+				}
+			}
+			for addr := range oldObjs {
+				if _, ok := newObjs[addr]; !ok {
+					objs = append(objs, addr)
+				}
+			}
+		}
+
+		for _, cfg := range cfgs {
+			for _, obj := range objs {
+				changed[impactAddr(cfg.Path, obj)] = true
+			}
+			if jsonSyntax {
+				// We only compare objects in native syntax files, so all of
+				// the objects declared in a changed JSON file are changed.
+				for _, addr := range g.files[filepath.Join(dir, filepath.Base(change.Path))] {
+					changed[addr] = true
+				}
+			}
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Walk the dependents of the changed objects, recording the object each
+	// one was first reached from so that we can report the chain of
+	// references.
+	from := make(map[string]string)
+	var queue []string
+	for addr := range changed {
+		_, exists := g.nodes[addr]
+		result.Changed = append(result.Changed, impactChange{Address: addr, Removed: !exists})
+		from[addr] = ""
+		queue = append(queue, addr)
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		addr := queue[0]
+		queue = queue[1:]
+		dependents := g.dependents[addr]
+		sort.Strings(dependents)
+		for _, dependent := range dependents {
+			if _, seen := from[dependent]; seen {
+				continue
+			}
+			from[dependent] = addr
+			queue = append(queue, dependent)
+		}
+	}
+
+	affectedModules := make(map[string]bool)
+	for addr := range from {
+		if !impactIsResource(addr) {
+			continue
+		}
+		var via []string
+		for prev := from[addr]; prev != ""; prev = from[prev] {
+			via = append([]string{prev}, via...)
+		}
+		if via == nil {
+			via = []string{}
+		}
+		result.AffectedResources = append(result.AffectedResources, impactResource{Address: addr, Via: via})
+		for _, mod := range impactModules(addr) {
+			affectedModules[mod] = true
+		}
+	}
+	for mod := range affectedModules {
+		result.AffectedModules = append(result.AffectedModules, mod)
+	}
+
+	sort.Slice(result.Changed, func(i, j int) bool {
+		return result.Changed[i].Address < result.Changed[j].Address
+	})
+	sort.Slice(result.AffectedResources, func(i, j int) bool {
+		return result.AffectedResources[i].Address < result.AffectedResources[j].Address
+	})
+	sort.Strings(result.AffectedModules)
+	sort.Strings(result.IgnoredFiles)
+	return result, diags
+}
+
+// impactGraph is a graph of the references between the objects in a
+// configuration, identified by their addresses in the configuration, such as
+// "module.app.var.region".
+type impactGraph struct {
+	// nodes are the objects in the configuration, and files are the objects
+	// declared in each configuration file, by its real path.
+	nodes map[string]struct{}
+	files map[string][]string
+
+	// dependents are the objects whose declarations refer to each object.
+	dependents map[string][]string
+}
+
+func buildImpactGraph(config *configs.Config) *impactGraph {
+	g := &impactGraph{
+		nodes:      make(map[string]struct{}),
+		files:      make(map[string][]string),
+		dependents: make(map[string][]string),
+	}
+
+	config.DeepEach(func(cfg *configs.Config) {
+		mod := cfg.Path
+		m := cfg.Module
+		add := func(rel string, rng hcl.Range, deps ...string) string {
+			addr := impactAddr(mod, rel)
+			g.nodes[addr] = struct{}{}
+			path := filepath.Join(impactRealPath(filepath.Dir(rng.Filename)), filepath.Base(rng.Filename))
+			g.files[path] = append(g.files[path], addr)
+			for _, dep := range deps {
+				g.dependents[dep] = append(g.dependents[dep], addr)
+			}
+			return addr
+		}
+
+		// The variables of a module are set by the arguments of its module
+		// call, and the count and for_each arguments of the call expand all
+		// of its resources.
+		var call *configs.ModuleCall
+		var callAddr string
+		if !mod.IsRoot() {
+			call = cfg.Parent.Module.ModuleCalls[mod[len(mod)-1]]
+			callAddr = impactAddr(mod.Parent(), "module."+call.Name)
+		}
+		var expansion []string
+		if call != nil && (call.Count != nil || call.ForEach != nil) {
+			expansion = append(expansion, callAddr)
+		}
+
+		for name, v := range m.Variables {
+			var deps []string
+			if call != nil {
+				deps = append(deps, callAddr)
+			}
+			add("var."+name, v.DeclRange, deps...)
+		}
+		for name, l := range m.Locals {
+			add("local."+name, l.DeclRange, g.refs(cfg, impactExprTraversals(l.Expr))...)
+		}
+		for name, o := range m.Outputs {
+			traversals := append(impactExprTraversals(o.Expr), o.DependsOn...)
+			traversals = append(traversals, impactCheckRuleTraversals(o.Preconditions)...)
+			add("output."+name, o.DeclRange, g.refs(cfg, traversals)...)
+		}
+		for _, rs := range []map[string]*configs.Resource{m.ManagedResources, m.DataResources} {
+			for _, r := range rs {
+				traversals := impactBodyTraversals(r.Config)
+				traversals = append(traversals, impactExprTraversals(r.Count, r.ForEach)...)
+				traversals = append(traversals, impactExprTraversals(r.TriggersReplacement...)...)
+				traversals = append(traversals, r.DependsOn...)
+				traversals = append(traversals, impactCheckRuleTraversals(r.Preconditions)...)
+				traversals = append(traversals, impactCheckRuleTraversals(r.Postconditions)...)
+				if r.Managed != nil {
+					if r.Managed.Connection != nil {
+						traversals = append(traversals, impactBodyTraversals(r.Managed.Connection.Config)...)
+					}
+					for _, p := range r.Managed.Provisioners {
+						traversals = append(traversals, impactBodyTraversals(p.Config)...)
+						if p.Connection != nil {
+							traversals = append(traversals, impactBodyTraversals(p.Connection.Config)...)
+						}
+					}
+				}
+				deps := g.refs(cfg, traversals)
+				deps = append(deps, impactProviderAddr(cfg, r.ProviderConfigAddr()))
+				add(r.Addr().String(), r.DeclRange, append(deps, expansion...)...)
+			}
+		}
+		for name, mc := range m.ModuleCalls {
+			traversals := impactBodyTraversals(mc.Config)
+			traversals = append(traversals, impactExprTraversals(mc.Source, mc.Count, mc.ForEach)...)
+			traversals = append(traversals, mc.DependsOn...)
+			deps := g.refs(cfg, traversals)
+			for _, passed := range mc.Providers {
+				deps = append(deps, impactProviderAddr(cfg, passed.InParent.Addr()))
+			}
+			add("module."+name, mc.DeclRange, deps...)
+		}
+		for _, p := range m.ProviderConfigs {
+			add(p.Addr().String(), p.DeclRange, g.refs(cfg, impactBodyTraversals(p.Config))...)
+		}
+	})
+	return g
+}
+
+// refs returns the addresses of the objects that the given traversals in the
+// given module refer to.
+func (g *impactGraph) refs(cfg *configs.Config, traversals []hcl.Traversal) []string {
+	var ret []string
+	for _, traversal := range traversals {
+		ref, diags := addrs.ParseRef(traversal)
+		if diags.HasErrors() {
+			continue
+		}
+		switch subject := ref.Subject.(type) {
+		case addrs.InputVariable:
+			ret = append(ret, impactAddr(cfg.Path, subject.String()))
+		case addrs.LocalValue:
+			ret = append(ret, impactAddr(cfg.Path, subject.String()))
+		case addrs.Resource:
+			ret = append(ret, impactAddr(cfg.Path, subject.String()))
+		case addrs.ResourceInstance:
+			ret = append(ret, impactAddr(cfg.Path, subject.Resource.String()))
+		case addrs.ModuleCallInstanceOutput:
+			ret = append(ret, impactAddr(cfg.Path.Child(subject.Call.Call.Name), "output."+subject.Name))
+		case addrs.ModuleCallInstance:
+			ret = append(ret, impactModuleOutputs(cfg, subject.Call.Name)...)
+		case addrs.ModuleCall:
+			ret = append(ret, impactModuleOutputs(cfg, subject.Name)...)
+		}
+	}
+	return ret
+}
+
+// impactModuleOutputs returns the addresses of all of the outputs of the
+// given module call, for references to the whole module.
+func impactModuleOutputs(cfg *configs.Config, name string) []string {
+	child := cfg.Children[name]
+	if child == nil {
+		return nil
+	}
+	var ret []string
+	for output := range child.Module.Outputs {
+		ret = append(ret, impactAddr(child.Path, "output."+output))
+	}
+	return ret
+}
+
+// impactProviderAddr returns the address of the provider configuration that
+// the given provider configuration address in the given module refers to,
+// which might be inherited from or passed by a parent module.
+func impactProviderAddr(cfg *configs.Config, addr addrs.LocalProviderConfig) string {
+	for {
+		if _, ok := cfg.Module.ProviderConfigs[addr.StringCompact()]; ok || cfg.Parent == nil {
+			return impactAddr(cfg.Path, addr.String())
+		}
+		call := cfg.Parent.Module.ModuleCalls[cfg.Path[len(cfg.Path)-1]]
+		passed := false
+		for _, pc := range call.Providers {
+			if pc.InChild.Addr() == addr {
+				addr = pc.InParent.Addr()
+				passed = true
+				break
+			}
+		}
+		if !passed && addr.Alias != "" {
+			// Only default provider configurations are inherited.
+			return impactAddr(cfg.Path, addr.String())
+		}
+		cfg = cfg.Parent
+	}
+}
+
+// impactFileObjects returns the source code of each object declared in the
+// given configuration file in the native syntax, by its address relative to
+// the module. The moved, import and removed blocks are included as part of
+// the objects that they refer to.
+func impactFileObjects(filename string, src []byte) (map[string]string, hcl.Diagnostics) {
+	objs := make(map[string]string)
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return objs, diags
+	}
+	text := func(rng hcl.Range) string {
+		return string(rng.SliceBytes(src))
+	}
+
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2:
+			objs[block.Labels[0]+"."+block.Labels[1]] += text(block.Range())
+		case block.Type == "data" && len(block.Labels) == 2:
+			objs["data."+block.Labels[0]+"."+block.Labels[1]] += text(block.Range())
+		case block.Type == "module" && len(block.Labels) == 1:
+			objs["module."+block.Labels[0]] += text(block.Range())
+		case block.Type == "variable" && len(block.Labels) == 1:
+			objs["var."+block.Labels[0]] += text(block.Range())
+		case block.Type == "output" && len(block.Labels) == 1:
+			objs["output."+block.Labels[0]] += text(block.Range())
+		case block.Type == "provider" && len(block.Labels) == 1:
+			addr := addrs.LocalProviderConfig{LocalName: block.Labels[0]}
+			if attr, ok := block.Body.Attributes["alias"]; ok {
+				if v, diags := attr.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String && v.IsKnown() && !v.IsNull() {
+					addr.Alias = v.AsString()
+				}
+			}
+			objs[addr.String()] += text(block.Range())
+		case block.Type == "locals":
+			for name, attr := range block.Body.Attributes {
+				objs["local."+name] += text(attr.SrcRange)
+			}
+		case block.Type == "moved" || block.Type == "import" || block.Type == "removed":
+			for _, name := range []string{"from", "to"} {
+				attr, ok := block.Body.Attributes[name]
+				if !ok {
+					continue
+				}
+				traversal, diags := hcl.AbsTraversalForExpr(attr.Expr)
+				if diags.HasErrors() {
+					continue
+				}
+				if addr := impactEndpoint(traversal); addr != "" {
+					objs[addr] += text(block.Range())
+				}
+			}
+		}
+	}
+	return objs, diags
+}
+
+// impactEndpoint returns the address of the resource or module call that the
+// given address in a moved, import or removed block refers to, relative to
+// the module containing the block.
+func impactEndpoint(traversal hcl.Traversal) string {
+	if ri, diags := addrs.ParseAbsResourceInstance(traversal); !diags.HasErrors() {
+		return impactAddr(ri.Module.Module(), ri.Resource.Resource.String())
+	}
+	if mi, diags := addrs.ParseModuleInstance(traversal); !diags.HasErrors() && !mi.IsRoot() {
+		mod := mi.Module()
+		return impactAddr(mod.Parent(), "module."+mod[len(mod)-1])
+	}
+	return ""
+}
+
+// impactRealPath returns the absolute path of the given directory with any
+// symbolic links resolved, so that paths reported by Git match those of the
+// configuration.
+func impactRealPath(dir string) string {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+// impactAddr returns the address of the object with the given address
+// relative to the given module.
+func impactAddr(mod addrs.Module, rel string) string {
+	if mod.IsRoot() {
+		return rel
+	}
+	return mod.String() + "." + rel
+}
+
+// impactIsResource returns true if the given address is the address of a
+// resource.
+func impactIsResource(addr string) bool {
+	parts := strings.Split(addr, ".")
+	for len(parts) > 2 && parts[0] == "module" {
+		parts = parts[2:]
+	}
+	switch parts[0] {
+	case "var", "local", "output", "module", "provider":
+		return false
+	default:
+		return true
+	}
+}
+
+// impactModules returns the addresses of the module containing the given
+// address and all of its ancestors, except for the root module.
+func impactModules(addr string) []string {
+	var ret []string
+	parts := strings.Split(addr, ".")
+	for i := 0; i+2 < len(parts) && parts[i] == "module"; i += 2 {
+		ret = append(ret, strings.Join(parts[:i+2], "."))
+	}
+	return ret
+}
+
+// impactBodyTraversals returns all of the traversals in the given body. We
+// don't have the schemas of the resources or providers, so we search all of
+// the expressions of native syntax bodies and only the attributes of others.
+func impactBodyTraversals(body hcl.Body) []hcl.Traversal {
+	var ret []hcl.Traversal
+	switch body := body.(type) {
+	case nil:
+		return nil
+	case *hclsyntax.Body:
+		for _, attr := range body.Attributes {
+			ret = append(ret, impactExprTraversals(attr.Expr)...)
+		}
+		for _, block := range body.Blocks {
+			ret = append(ret, impactBodyTraversals(block.Body)...)
+		}
+	default:
+		attrs, _ := body.JustAttributes()
+		for _, attr := range attrs {
+			ret = append(ret, impactExprTraversals(attr.Expr)...)
+		}
+	}
+	return ret
+}
+
+func impactExprTraversals(exprs ...hcl.Expression) []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, expr := range exprs {
+		if expr != nil {
+			ret = append(ret, expr.Variables()...)
+		}
+	}
+	return ret
+}
+
+func impactCheckRuleTraversals(rules []*configs.CheckRule) []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, rule := range rules {
+		ret = append(ret, impactExprTraversals(rule.Condition, rule.ErrorMessage)...)
+	}
+	return ret
+}
+
+func (c *ImpactCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] impact [options] [FILE...]
+
+  Report which resources and modules could be affected by changes to the
+  given configuration files, without creating a plan or contacting any
+  providers.
+
+  OpenTofu finds the objects declared in the changed files, such as
+  resources, variables, locals, outputs, module calls and provider
+  configurations, and then follows the references between the objects in the
+  configuration to find everything that refers to them, directly or
+  indirectly.
+
+  Given files, all of the objects declared in them are considered changed.
+  With -git-diff, the files changed in the Git working tree since the given
+  commit are used instead, and only the objects whose declarations changed
+  are considered changed, including removed objects.
+
+  The result is an estimate: a reported resource might not actually change,
+  and changes to other files, such as templates, are not analyzed.
+
+Options:
+
+  -git-diff=REF  Analyze the changes in the Git working tree since the given
+                 commit, branch or tag, such as "main".
+
+  -json          Produce the report in a machine-readable JSON format.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ImpactCommand) Synopsis() string {
+	return "Report what changes to the configuration could affect"
+}
@@ -0,0 +1,149 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+)
+
+func TestImpact_files(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("impact"), td)
+	defer testChdir(t, td)()
+
+	ui := cli.NewMockUi()
+	c := &ImpactCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"variables.tf", "template.tpl"}); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `Changed objects:
+  var.region
+
+Affected resources:
+  module.app.test_instance.a
+    via var.region -> local.zone -> module.app -> module.app.var.zone
+  test_instance.web
+    via var.region -> local.zone
+
+Affected modules:
+  module.app
+
+The following changed files are not configuration files of any module in
+the configuration, so their effects were not analyzed:
+  template.tpl`
+	if got := strings.TrimSpace(ui.OutputWriter.String()); got != want {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImpact_gitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("impact"), td)
+	defer testChdir(t, td)()
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %s\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	// Change one resource of the module and remove another, and add a
+	// moved block for a resource in the root module. The other objects in
+	// the changed files don't change.
+	err := os.WriteFile("app/main.tf", []byte(`
+variable "zone" {
+  type = string
+}
+
+resource "test_instance" "a" {
+  ami = "${var.zone}-new"
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile("moved.tf", []byte(`
+moved {
+  from = test_instance.database
+  to   = test_instance.db
+}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	c := &ImpactCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-git-diff=HEAD", "-json"}); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got impactResult
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	want := impactResult{
+		Changed: []impactChange{
+			{Address: "module.app.test_instance.a"},
+			{Address: "module.app.test_instance.b", Removed: true},
+			{Address: "test_instance.database", Removed: true},
+			{Address: "test_instance.db"},
+		},
+		AffectedResources: []impactResource{
+			{Address: "module.app.test_instance.a", Via: []string{}},
+			{Address: "module.app.test_instance.b", Via: []string{}},
+			{Address: "test_instance.database", Via: []string{}},
+			{Address: "test_instance.db", Via: []string{}},
+		},
+		AffectedModules: []string{"module.app"},
+		IgnoredFiles:    []string{},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong result\n%s", diff)
+	}
+}
+
+func TestImpact_noArgs(t *testing.T) {
+	ui := cli.NewMockUi()
+	c := &ImpactCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Either the changed files or the -git-diff option"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	svchost "github.com/hashicorp/terraform-svchost"
 	"github.com/posener/complete"
-	"github.com/zclconf/go-cty/cty"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -28,7 +27,6 @@ import (
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/configs"
-	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/providercache"
@@ -47,7 +45,7 @@ type InitCommand struct {
 
 func (c *InitCommand) Run(args []string) int {
 	var flagFromModule, flagLockfile, testsDirectory string
-	var flagBackend, flagCloud, flagGet, flagUpgrade bool
+	var flagBackend, flagCloud, flagGet, flagUpgrade, flagUpgradeModules, flagInferProviders bool
 	var flagPluginPath FlagStringSlice
 	flagConfigExtra := newRawFlags("-backend-config")
 
@@ -63,7 +61,10 @@ func (c *InitCommand) Run(args []string) int {
 	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.BoolVar(&c.reconfigure, "reconfigure", false, "reconfigure")
 	cmdFlags.BoolVar(&c.migrateState, "migrate-state", false, "migrate state")
+	cmdFlags.BoolVar(&c.revalidateBackend, "revalidate-backend", false, "revalidate backend")
 	cmdFlags.BoolVar(&flagUpgrade, "upgrade", false, "")
+	cmdFlags.BoolVar(&flagUpgradeModules, "upgrade-modules", false, "")
+	cmdFlags.BoolVar(&flagInferProviders, "infer-providers", false, "infer-providers")
 	cmdFlags.Var(&flagPluginPath, "plugin-dir", "plugin directory")
 	cmdFlags.StringVar(&flagLockfile, "lockfile", "", "Set a dependency lockfile mode")
 	cmdFlags.BoolVar(&c.Meta.ignoreRemoteVersion, "ignore-remote-version", false, "continue even if remote and local OpenTofu versions are incompatible")
@@ -103,6 +104,11 @@ func (c *InitCommand) Run(args []string) int {
 		return 1
 	}
 
+	if flagUpgradeModules && flagLockfile == "readonly" {
+		c.Ui.Error("The -upgrade-modules flag conflicts with -lockfile=readonly.")
+		return 1
+	}
+
 	// Copying the state only happens during backend migration, so setting
 	// -force-copy implies -migrate-state
 	if c.forceInitCopy {
@@ -266,7 +272,7 @@ func (c *InitCommand) Run(args []string) int {
 	}
 
 	if flagGet {
-		modsOutput, modsAbort, modsDiags := c.getModules(ctx, path, testsDirectory, rootModEarly, flagUpgrade)
+		modsOutput, modsAbort, modsDiags := c.getModules(ctx, path, testsDirectory, rootModEarly, flagUpgrade || flagUpgradeModules, flagLockfile == "readonly")
 		diags = diags.Append(modsDiags)
 		if modsAbort || modsDiags.HasErrors() {
 			c.showDiagnostics(diags)
@@ -356,6 +362,21 @@ func (c *InitCommand) Run(args []string) int {
 		header = true
 	}
 
+	if flagInferProviders {
+		inferDiags := c.inferProviderRequirements(path, config)
+		diags = diags.Append(inferDiags)
+		if inferDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		header = true
+	}
+
+	// Editor integrations read the schema index exported by
+	// "tofu schema export -lsp", which must follow any change to the
+	// selected provider versions.
+	diags = diags.Append(c.refreshSchemaIndex())
+
 	// If we outputted information, then we need to output a newline
 	// so that our success message is nicely spaced out from prior text.
 	if header {
@@ -387,7 +408,7 @@ func (c *InitCommand) Run(args []string) int {
 	return 0
 }
 
-func (c *InitCommand) getModules(ctx context.Context, path, testsDir string, earlyRoot *configs.Module, upgrade bool) (output bool, abort bool, diags tfdiags.Diagnostics) {
+func (c *InitCommand) getModules(ctx context.Context, path, testsDir string, earlyRoot *configs.Module, upgrade, readonlyLocks bool) (output bool, abort bool, diags tfdiags.Diagnostics) {
 	testModules := false // We can also have modules buried in test files.
 	for _, file := range earlyRoot.Tests {
 		for _, run := range file.Runs {
@@ -418,7 +439,7 @@ func (c *InitCommand) getModules(ctx context.Context, path, testsDir string, ear
 		ShowLocalPaths: true,
 	}
 
-	installAbort, installDiags := c.installModules(ctx, path, testsDir, upgrade, false, hooks)
+	installAbort, installDiags := c.installModules(ctx, path, testsDir, upgrade, false, readonlyLocks, hooks)
 	diags = diags.Append(installDiags)
 
 	// At this point, installModules may have generated error diags or been
@@ -681,6 +702,9 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 		FetchPackageBegin: func(provider addrs.Provider, version getproviders.Version, location getproviders.PackageLocation) {
 			c.Ui.Info(fmt.Sprintf("- Installing %s v%s...", provider.ForDisplay(), version))
 		},
+		FetchPackageFallbackPlatform: func(provider addrs.Provider, version getproviders.Version, platform getproviders.Platform) {
+			c.Ui.Info(fmt.Sprintf("- %s v%s has no package for %s, so using the %s package, which must run under emulation", provider.ForDisplay(), version, getproviders.CurrentPlatform, platform))
+		},
 		QueryPackagesFailure: func(provider addrs.Provider, err error) {
 			switch errorTy := err.(type) {
 			case getproviders.ErrProviderNotFound:
@@ -846,11 +870,21 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 						tfdiags.Error,
 						summaryIncompatible,
 						fmt.Sprintf(
-							"Provider %s v%s does not have a package available for your current platform, %s.\n\nProvider releases are separate from OpenTofu CLI releases, so not all providers are available for all platforms. Other versions of this provider may have different platforms supported.",
+							"Provider %s v%s does not have a package available for your current platform, %s.\n\nProvider releases are separate from OpenTofu CLI releases, so not all providers are available for all platforms. Other versions of this provider may have different platforms supported.%s",
 							err.Provider, err.Version, err.Platform,
+							platformFallbackHint(err.Platform),
 						),
 					))
 				}
+			case getproviders.ErrExecutablePlatformMismatch:
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					summaryIncompatible,
+					fmt.Sprintf(
+						"The package for provider %s v%s contains an executable that cannot run on your current platform: %s.\n\nThe package might have been published or mirrored under the wrong platform. If your system can run this executable under emulation, add a platform_fallback block for its platform to the provider_installation block in the CLI configuration.",
+						provider.ForDisplay(), version, err,
+					),
+				))
 
 			case getproviders.ErrRequestCanceled:
 				// We don't attribute cancellation to any particular operation,
@@ -1039,109 +1073,24 @@ version control system if they represent changes you intended to make.`))
 	return true, false, diags
 }
 
-// backendConfigOverrideBody interprets the raw values of -backend-config
-// arguments into a hcl Body that should override the backend settings given
-// in the configuration.
-//
-// If the result is nil then no override needs to be provided.
-//
-// If the returned diagnostics contains errors then the returned body may be
-// incomplete or invalid.
-func (c *InitCommand) backendConfigOverrideBody(flags rawFlags, schema *configschema.Block) (hcl.Body, tfdiags.Diagnostics) {
-	items := flags.AllItems()
-	if len(items) == 0 {
-		return nil, nil
-	}
-
-	var ret hcl.Body
-	var diags tfdiags.Diagnostics
-	synthVals := make(map[string]cty.Value)
-
-	mergeBody := func(newBody hcl.Body) {
-		if ret == nil {
-			ret = newBody
-		} else {
-			ret = configs.MergeBodies(ret, newBody)
-		}
-	}
-	flushVals := func() {
-		if len(synthVals) == 0 {
-			return
-		}
-		newBody := configs.SynthBody("-backend-config=...", synthVals)
-		mergeBody(newBody)
-		synthVals = make(map[string]cty.Value)
-	}
-
-	if len(items) == 1 && items[0].Value == "" {
-		// Explicitly remove all -backend-config options.
-		// We do this by setting an empty but non-nil ConfigOverrides.
-		return configs.SynthBody("-backend-config=''", synthVals), diags
+// platformFallbackHint returns an addendum for the error message about a
+// provider not having a package for the given platform, suggesting that the
+// user allow packages for another platform if their system is likely to be
+// able to run those under emulation. It returns an empty string if there are
+// no such platforms.
+func platformFallbackHint(platform getproviders.Platform) string {
+	emulated := getproviders.EmulatedPlatforms(platform)
+	if len(emulated) == 0 {
+		return ""
 	}
-
-	for _, item := range items {
-		eq := strings.Index(item.Value, "=")
-
-		if eq == -1 {
-			// The value is interpreted as a filename.
-			newBody, fileDiags := c.loadHCLFile(item.Value)
-			diags = diags.Append(fileDiags)
-			if fileDiags.HasErrors() {
-				continue
-			}
-			// Generate an HCL body schema for the backend block.
-			var bodySchema hcl.BodySchema
-			for name := range schema.Attributes {
-				// We intentionally ignore the `Required` attribute here
-				// because backend config override files can be partial. The
-				// goal is to make sure we're not loading a file with
-				// extraneous attributes or blocks.
-				bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{
-					Name: name,
-				})
-			}
-			for name, block := range schema.BlockTypes {
-				var labelNames []string
-				if block.Nesting == configschema.NestingMap {
-					labelNames = append(labelNames, "key")
-				}
-				bodySchema.Blocks = append(bodySchema.Blocks, hcl.BlockHeaderSchema{
-					Type:       name,
-					LabelNames: labelNames,
-				})
-			}
-			// Verify that the file body matches the expected backend schema.
-			_, schemaDiags := newBody.Content(&bodySchema)
-			diags = diags.Append(schemaDiags)
-			if schemaDiags.HasErrors() {
-				continue
-			}
-			flushVals() // deal with any accumulated individual values first
-			mergeBody(newBody)
-		} else {
-			name := item.Value[:eq]
-			rawValue := item.Value[eq+1:]
-			attrS := schema.Attributes[name]
-			if attrS == nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Invalid backend configuration argument",
-					fmt.Sprintf("The backend configuration argument %q given on the command line is not expected for the selected backend type.", name),
-				))
-				continue
-			}
-			value, valueDiags := configValueFromCLI(item.String(), rawValue, attrS.Type)
-			diags = diags.Append(valueDiags)
-			if valueDiags.HasErrors() {
-				continue
-			}
-			synthVals[name] = value
-		}
+	names := make([]string, len(emulated))
+	for i, p := range emulated {
+		names[i] = fmt.Sprintf("%q", p.String())
 	}
-
-	flushVals()
-
-	return ret, diags
+	return fmt.Sprintf(
+		"\n\nIf your system can run executables built for %s under emulation, you can allow OpenTofu to install the package for that platform instead by adding a platform_fallback block to the provider_installation block in the CLI configuration:\n    platform_fallback {\n      platforms = [%s]\n    }",
+		emulated[0], strings.Join(names, ", "),
+	)
 }
 
 func (c *InitCommand) AutocompleteArgs() complete.Predictor {
@@ -1150,20 +1099,23 @@ func (c *InitCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *InitCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"-backend":        completePredictBoolean,
-		"-cloud":          completePredictBoolean,
-		"-backend-config": complete.PredictFiles("*.tfvars"), // can also be key=value, but we can't "predict" that
-		"-force-copy":     complete.PredictNothing,
-		"-from-module":    completePredictModuleSource,
-		"-get":            completePredictBoolean,
-		"-input":          completePredictBoolean,
-		"-lock":           completePredictBoolean,
-		"-lock-timeout":   complete.PredictAnything,
-		"-no-color":       complete.PredictNothing,
-		"-plugin-dir":     complete.PredictDirs(""),
-		"-reconfigure":    complete.PredictNothing,
-		"-migrate-state":  complete.PredictNothing,
-		"-upgrade":        completePredictBoolean,
+		"-backend":            completePredictBoolean,
+		"-cloud":              completePredictBoolean,
+		"-backend-config":     complete.PredictFiles("*.tfvars"), // can also be key=value, but we can't "predict" that
+		"-force-copy":         complete.PredictNothing,
+		"-from-module":        completePredictModuleSource,
+		"-get":                completePredictBoolean,
+		"-infer-providers":    complete.PredictNothing,
+		"-input":              completePredictBoolean,
+		"-lock":               completePredictBoolean,
+		"-lock-timeout":       complete.PredictAnything,
+		"-no-color":           complete.PredictNothing,
+		"-plugin-dir":         complete.PredictDirs(""),
+		"-reconfigure":        complete.PredictNothing,
+		"-migrate-state":      complete.PredictNothing,
+		"-revalidate-backend": complete.PredictNothing,
+		"-upgrade":            completePredictBoolean,
+		"-upgrade-modules":    completePredictBoolean,
 	}
 }
 
@@ -1222,6 +1174,12 @@ Options:
 
   -get=false              Disable downloading modules for this configuration.
 
+  -infer-providers        Write a versions.tf file to the root module that
+                          declares the providers that the root module uses
+                          without declaring them in required_providers, with
+                          the sources OpenTofu infers from their names and
+                          version constraints based on the selected versions.
+
   -input=false            Disable interactive prompts. Note that some actions may
                           require interactive prompts and will error if input is
                           disabled.
@@ -1245,11 +1203,20 @@ Options:
   -migrate-state          Reconfigure a backend, and attempt to migrate any
                           existing state.
 
+  -revalidate-backend     Verify that the selected workspace exists in the
+                          backend even if it was already verified for the
+                          same backend configuration by a previous run.
+
   -upgrade                Install the latest module and provider versions
                           allowed within configured constraints, overriding the
                           default behavior of selecting exactly the version
                           recorded in the dependency lockfile.
 
+  -upgrade-modules        Install the latest module versions allowed within
+                          configured constraints and record their new
+                          checksums in the dependency lockfile, without
+                          upgrading providers.
+
   -lockfile=MODE          Set a dependency lockfile mode.
                           Currently only "readonly" is valid.
 
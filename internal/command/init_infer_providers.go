@@ -0,0 +1,237 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// inferredProvidersFilename is the file in the root module that
+// "tofu init -infer-providers" writes the inferred provider requirements to,
+// unless the root module already has a required_providers block.
+const inferredProvidersFilename = "versions.tf"
+
+// inferredProvider is a provider that the root module uses without declaring
+// it in a required_providers block, so that OpenTofu infers its source from
+// its local name.
+type inferredProvider struct {
+	LocalName string
+	Provider  addrs.Provider
+	Version   string
+
+	// UsedBy are the addresses of the resources and provider configurations
+	// that use the provider.
+	UsedBy []string
+}
+
+// inferProviderRequirements declares the provider requirements that OpenTofu
+// infers for the providers that the root module uses without declaring them,
+// either in the root module's existing required_providers block or in a new
+// block in versions.tf.
+//
+// It must be called after the providers are installed, so that the version
+// constraints can be based on the versions selected in the dependency lock
+// file.
+func (c *InitCommand) inferProviderRequirements(path string, config *configs.Config) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	c.Ui.Output(c.Colorize().Color("\n[reset][bold]Inferring provider requirements..."))
+
+	inferred := inferredProviders(config.Module)
+	diags = diags.Append(inferredProviderAmbiguities(config.Module, inferred))
+	if len(inferred) == 0 {
+		c.Ui.Output("- All providers used by the root module are already declared in required_providers.")
+		return diags
+	}
+
+	locks, lockDiags := c.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		return diags
+	}
+	for _, p := range inferred {
+		if p.Version != "" {
+			continue
+		}
+		if lock := locks.Provider(p.Provider); lock != nil {
+			v := lock.Version()
+			p.Version = fmt.Sprintf("~> %d.%d", v.Major, v.Minor)
+		}
+	}
+
+	// A module can only have one required_providers block, so if the root
+	// module already has one then the inferred providers are added to it.
+	filename := filepath.Join(path, inferredProvidersFilename)
+	if existing := config.Module.ProviderRequirements.DeclRange.Filename; existing != "" {
+		filename = existing
+	}
+	if strings.HasSuffix(filename, ".json") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Can't write inferred provider requirements",
+			fmt.Sprintf("The required_providers block of the root module is in %s, which OpenTofu can't edit because it uses the JSON syntax. Add the following providers to the block:\n\n%s", filename, inferredProvidersFile(nil, inferred)),
+		))
+		return diags
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read configuration file",
+			fmt.Sprintf("OpenTofu could not read %s to add the inferred provider requirements: %s.", filename, err),
+		))
+		return diags
+	}
+	f, hclDiags := hclwrite.ParseConfig(src, filename, hcl.InitialPos)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return diags
+	}
+	if err := os.WriteFile(filename, inferredProvidersFile(f, inferred), 0644); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write inferred provider requirements",
+			fmt.Sprintf("OpenTofu could not write %s: %s.", filename, err),
+		))
+		return diags
+	}
+
+	for _, p := range inferred {
+		c.Ui.Output(fmt.Sprintf("- %q is %s, because of %s", p.LocalName, p.Provider.ForDisplay(), strings.Join(p.UsedBy, ", ")))
+	}
+	c.Ui.Output(fmt.Sprintf("OpenTofu wrote the inferred provider requirements to %s.", filename))
+	return diags
+}
+
+// inferredProviders returns the providers that the given module uses without
+// declaring them in a required_providers block, sorted by local name.
+//
+// A version constraint in a provider block is kept as the version constraint
+// of its provider.
+func inferredProviders(mod *configs.Module) []*inferredProvider {
+	byName := make(map[string]*inferredProvider)
+	use := func(localName string, usedBy string) *inferredProvider {
+		if _, declared := mod.ProviderRequirements.RequiredProviders[localName]; declared {
+			return nil
+		}
+		provider := mod.ImpliedProviderForUnqualifiedType(localName)
+		if provider.IsBuiltIn() {
+			return nil
+		}
+		p, ok := byName[localName]
+		if !ok {
+			p = &inferredProvider{
+				LocalName: localName,
+				Provider:  provider,
+			}
+			byName[localName] = p
+		}
+		p.UsedBy = append(p.UsedBy, usedBy)
+		return p
+	}
+
+	for _, pc := range mod.ProviderConfigs {
+		p := use(pc.Name, pc.Addr().StringCompact())
+		if p != nil && len(pc.Version.Required) > 0 {
+			p.Version = pc.Version.Required.String()
+		}
+	}
+	for _, resources := range []map[string]*configs.Resource{mod.ManagedResources, mod.DataResources, mod.EphemeralResources} {
+		for _, rc := range resources {
+			use(rc.ProviderConfigAddr().LocalName, rc.Addr().String())
+		}
+	}
+
+	ret := make([]*inferredProvider, 0, len(byName))
+	for _, p := range byName {
+		sort.Strings(p.UsedBy)
+		ret = append(ret, p)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].LocalName < ret[j].LocalName
+	})
+	return ret
+}
+
+// inferredProviderAmbiguities returns a warning for each inferred provider
+// that has the same type as a provider that the module declares under another
+// local name, because the resources that use the inferred provider are then
+// likely meant to use the declared one.
+func inferredProviderAmbiguities(mod *configs.Module, inferred []*inferredProvider) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	for _, p := range inferred {
+		var declared []string
+		for name, req := range mod.ProviderRequirements.RequiredProviders {
+			if req.Type.Type == p.Provider.Type && req.Type != p.Provider {
+				declared = append(declared, fmt.Sprintf("%s as %q", req.Type.ForDisplay(), name))
+			}
+		}
+		if len(declared) == 0 {
+			continue
+		}
+		sort.Strings(declared)
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Ambiguous inferred provider",
+			fmt.Sprintf("OpenTofu inferred that %s uses %s, because its local name is %q, but the root module also requires %s. If these should use the declared provider instead, set their provider arguments to its local name.", strings.Join(p.UsedBy, ", "), p.Provider.ForDisplay(), p.LocalName, strings.Join(declared, ", ")),
+		))
+	}
+	return diags
+}
+
+// inferredProvidersFile returns the source of the given configuration file
+// with the given providers added to its required_providers block, which is
+// added to the file if it doesn't have one. If the file is nil then the
+// result is a new file.
+func inferredProvidersFile(f *hclwrite.File, inferred []*inferredProvider) []byte {
+	if f == nil {
+		f = hclwrite.NewEmptyFile()
+	}
+
+	var reqs *hclwrite.Body
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "terraform" {
+			continue
+		}
+		if inner := block.Body().FirstMatchingBlock("required_providers", nil); inner != nil {
+			reqs = inner.Body()
+			break
+		}
+	}
+	if reqs == nil {
+		if len(f.Body().Attributes()) > 0 || len(f.Body().Blocks()) > 0 {
+			f.Body().AppendNewline()
+		}
+		f.Body().AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte("# Provider requirements inferred by \"tofu init -infer-providers\".\n")},
+		})
+		reqs = f.Body().AppendNewBlock("terraform", nil).Body().AppendNewBlock("required_providers", nil).Body()
+	}
+	for _, p := range inferred {
+		attrs := map[string]cty.Value{
+			"source": cty.StringVal(p.Provider.ForDisplay()),
+		}
+		if p.Version != "" {
+			attrs["version"] = cty.StringVal(p.Version)
+		}
+		reqs.SetAttributeValue(p.LocalName, cty.ObjectVal(attrs))
+	}
+	return hclwrite.Format(f.Bytes())
+}
@@ -1312,6 +1312,155 @@ func TestInit_inputFalse(t *testing.T) {
 	}
 }
 
+func TestInit_inferProviders(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-infer-providers"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"exact":        {"1.2.3"},
+		"greater-than": {"2.3.4"},
+		"between":      {"3.4.5", "2.3.4"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+
+	args := []string{"-backend=false", "-infer-providers"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	got, err := os.ReadFile("versions.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Provider requirements inferred by "tofu init -infer-providers".
+terraform {
+  required_providers {
+    between = {
+      source  = "hashicorp/between"
+      version = "~> 3.4"
+    }
+    exact = {
+      source  = "hashicorp/exact"
+      version = "1.2.3"
+    }
+  }
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("wrong versions.tf\n%s", diff)
+	}
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, `"between" is hashicorp/between, because of between_thing.a`) {
+		t.Fatalf("output doesn't explain the inferred providers:\n%s", output)
+	}
+
+	// The file now declares every provider, so running init again doesn't
+	// need to write it.
+	ui = new(cli.MockUi)
+	c = &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if output := ui.OutputWriter.String(); !strings.Contains(output, "already declared in required_providers") {
+		t.Fatalf("wrong output:\n%s", output)
+	}
+}
+
+func TestInit_inferProvidersExistingRequirements(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-infer-providers-existing"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"exact":   {"1.2.3"},
+		"between": {"3.4.5"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+
+	if code := c.Run([]string{"-backend=false", "-infer-providers"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	// A module can only have one required_providers block, so the inferred
+	// providers are added to the existing one.
+	if _, err := os.Stat("versions.tf"); !os.IsNotExist(err) {
+		t.Fatalf("versions.tf was written")
+	}
+	got, err := os.ReadFile("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `terraform {
+  required_providers {
+    exact = {
+      source = "hashicorp/exact"
+    }
+    between = {
+      source  = "hashicorp/between"
+      version = "~> 3.4"
+    }
+  }
+}
+
+resource "between_thing" "a" {
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("wrong main.tf\n%s", diff)
+	}
+}
+
+func TestInferredProviderAmbiguities(t *testing.T) {
+	config, _ := testModuleWithSnapshot(t, "init-infer-providers-ambiguous")
+
+	inferred := inferredProviders(config.Module)
+	if len(inferred) != 1 || inferred[0].LocalName != "between" {
+		t.Fatalf("wrong inferred providers %#v", inferred)
+	}
+
+	diags := inferredProviderAmbiguities(config.Module, inferred)
+	if len(diags) != 1 {
+		t.Fatalf("wrong diagnostics: %s", diags.ErrWithWarnings())
+	}
+	got := diags[0].Description()
+	if got.Summary != "Ambiguous inferred provider" {
+		t.Fatalf("wrong summary %q", got.Summary)
+	}
+	if want := `OpenTofu inferred that between_thing.a uses hashicorp/between, because its local name is "between", but the root module also requires acme/between as "acme".`; !strings.HasPrefix(got.Detail, want) {
+		t.Fatalf("wrong detail\ngot:  %s\nwant: %s", got.Detail, want)
+	}
+}
+
 func TestInit_getProvider(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -0,0 +1,90 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+)
+
+// compactGroup is a set of changes to instances of the same resource that
+// render identically apart from their addresses.
+type compactGroup struct {
+	// addr is the address of the resource, with "[...]" in place of the
+	// instance key.
+	addr string
+
+	// rendered is the rendered diff of the first instance in the group.
+	rendered string
+
+	// others counts the instances after the first one.
+	others int
+}
+
+// renderHumanCompactDiffs renders the given changes, except that only the
+// first instance of each group of identical changes to the instances of a
+// resource is rendered in full. The others are summarized in a single line
+// after it.
+func renderHumanCompactDiffs(renderer Renderer, changes []diff) {
+	var groups []*compactGroup
+	byKey := make(map[string]*compactGroup)
+
+	for _, change := range changes {
+		rendered, render := renderHumanDiff(renderer, change, proposedChange)
+		if !render {
+			continue
+		}
+
+		addr, ok := compactGroupAddr(change)
+		if !ok {
+			groups = append(groups, &compactGroup{rendered: rendered})
+			continue
+		}
+
+		// We compare the changes with the instance key replaced, so that
+		// only the address differs between the instances in a group.
+		generic := change
+		generic.change.Address = addr
+		key, _ := renderHumanDiff(renderer, generic, proposedChange)
+		if group, exists := byKey[key]; exists {
+			group.others++
+			continue
+		}
+		group := &compactGroup{addr: addr, rendered: rendered}
+		byKey[key] = group
+		groups = append(groups, group)
+	}
+
+	for _, group := range groups {
+		renderer.Streams.Println()
+		renderer.Streams.Println(group.rendered)
+		if group.others > 0 {
+			renderer.Streams.Println(renderer.Colorize.Color(fmt.Sprintf(
+				"[bold]  # %s[reset] (%d instances): same change as above",
+				group.addr, group.others,
+			)))
+		}
+	}
+}
+
+// compactGroupAddr returns the address that identifies the group the given
+// change can belong to, or false if the change must always be rendered on its
+// own because it concerns something about that specific instance.
+func compactGroupAddr(change diff) (string, bool) {
+	if len(change.change.Index) == 0 || len(change.change.Deposed) != 0 || change.Moved() || change.Importing() {
+		return "", false
+	}
+
+	addr := fmt.Sprintf("%s.%s[...]", change.change.Type, change.change.Name)
+	if change.change.Mode == jsonstate.DataResourceMode {
+		addr = "data." + addr
+	}
+	if len(change.change.ModuleAddress) != 0 {
+		addr = change.change.ModuleAddress + "." + addr
+	}
+	return addr, true
+}
@@ -22,8 +22,8 @@ func precomputeDiffs(plan Plan, mode plans.Mode) diffs {
 	for _, drift := range plan.ResourceDrift {
 
 		var relevantAttrs attribute_path.Matcher
-		if mode == plans.RefreshOnlyMode {
-			// For a refresh only plan, we show all the drift.
+		if mode.RefreshesOnly() {
+			// For a refresh only or drift only plan, we show all the drift.
 			relevantAttrs = attribute_path.AlwaysMatcher()
 		} else {
 			matcher := attribute_path.Empty(true)
@@ -0,0 +1,484 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed/renderers"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+const (
+	hclUnknownValue   = "(known after apply)"
+	hclSensitiveValue = "(sensitive value)"
+
+	hclChangedComment = "# changed"
+	hclReplaceComment = "# forces replacement"
+)
+
+// RenderHCLPlan renders the planned new values of each changed resource
+// instance and output value as HCL snippets, with the attributes that differ
+// from the prior state annotated with comments.
+//
+// This is an alternative to RenderHumanPlan which is intended to be easier
+// to read for people who are familiar with the configuration language, such
+// as when pasting plans into code review comments.
+//
+// It returns an error, without rendering anything, if the plan refers to a
+// schema that it doesn't include or contains values that don't match the
+// JSON plan format.
+func (renderer Renderer) RenderHCLPlan(plan Plan) error {
+	counts := make(map[plans.Action]int)
+	var blocks []string
+
+	for _, change := range plan.ResourceChanges {
+		action := jsonplan.UnmarshalActions(change.Change.Actions)
+		if action == plans.NoOp && !hclChangeMoved(change) && change.Change.Importing == nil {
+			continue
+		}
+		if action == plans.Delete && change.Mode != jsonstate.ManagedResourceMode {
+			// Don't render anything for deleted data sources.
+			continue
+		}
+		if action != plans.NoOp {
+			counts[action]++
+		}
+
+		var buf bytes.Buffer
+		// The human renderer indents its comments to line up with the diff
+		// action symbols, which we don't have here.
+		buf.WriteString(renderer.Colorize.Color(strings.ReplaceAll(resourceChangeComment(change, action, proposedChange), "  # ", "# ")))
+		if action == plans.Delete || action == plans.Forget {
+			// There are no planned new values to show.
+			blocks = append(blocks, buf.String())
+			continue
+		}
+
+		schema, err := hclResourceSchema(plan, change)
+		if err != nil {
+			return err
+		}
+		body, err := renderer.hclResourceBody(change, action, schema)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", change.Address, err)
+		}
+		buf.Write(hclwrite.Format([]byte(fmt.Sprintf("%s {\n%s}\n", resourceChangeHeader(change), body))))
+		blocks = append(blocks, buf.String())
+	}
+
+	outputs, err := renderer.hclOutputs(plan.OutputChanges)
+	if err != nil {
+		return err
+	}
+
+	if len(blocks) == 0 && len(outputs) == 0 {
+		renderer.Streams.Println(renderer.Colorize.Color("[reset][bold][green]No changes.[reset][bold] Your infrastructure matches the configuration.[reset]"))
+		return nil
+	}
+
+	for _, block := range blocks {
+		renderer.Streams.Println(block)
+	}
+	if len(outputs) > 0 {
+		renderer.Streams.Println(renderer.Colorize.Color("[bold]# Changes to Outputs:[reset]"))
+		renderer.Streams.Println(outputs)
+	}
+
+	renderer.Streams.Printf(
+		renderer.Colorize.Color("[bold]# Plan:[reset] %d to add, %d to change, %d to destroy.\n"),
+		counts[plans.Create]+counts[plans.DeleteThenCreate]+counts[plans.CreateThenDelete],
+		counts[plans.Update],
+		counts[plans.Delete]+counts[plans.DeleteThenCreate]+counts[plans.CreateThenDelete])
+	return nil
+}
+
+// hclResourceSchema returns the schema of the given resource instance change,
+// or an error if the plan doesn't include it.
+func hclResourceSchema(plan Plan, change jsonplan.ResourceChange) (*jsonprovider.Schema, error) {
+	provider := plan.ProviderSchemas[change.ProviderName]
+	if provider == nil {
+		return nil, fmt.Errorf("no schema for provider %s, required by %s", change.ProviderName, change.Address)
+	}
+
+	var schema *jsonprovider.Schema
+	switch change.Mode {
+	case jsonstate.ManagedResourceMode:
+		schema = provider.ResourceSchemas[change.Type]
+	case jsonstate.DataResourceMode:
+		schema = provider.DataSourceSchemas[change.Type]
+	default:
+		return nil, fmt.Errorf("unrecognized resource mode %q for %s", change.Mode, change.Address)
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("no schema for %s %q in provider %s, required by %s", change.Mode, change.Type, change.ProviderName, change.Address)
+	}
+	return schema, nil
+}
+
+func hclChangeMoved(change jsonplan.ResourceChange) bool {
+	return len(change.PreviousAddress) > 0 && change.PreviousAddress != change.Address
+}
+
+// hclValues is the set of decoded JSON values describing a single object or
+// value within a change, kept together so that nested values can be
+// selected from all of them at once.
+type hclValues struct {
+	before    interface{}
+	after     interface{}
+	unknown   interface{}
+	sensitive interface{}
+
+	// compare is true if before should be compared with after in order to
+	// annotate changed values. It is false for newly-created objects, where
+	// every value is new, and for elements of collections where we can't
+	// reliably correlate elements.
+	compare bool
+}
+
+// newHCLValues decodes the JSON values of a change.
+func newHCLValues(before, after, afterUnknown, afterSensitive json.RawMessage, compare bool) (hclValues, error) {
+	values := hclValues{compare: compare}
+	for _, v := range []struct {
+		dst *interface{}
+		raw json.RawMessage
+	}{
+		{&values.before, before},
+		{&values.after, after},
+		{&values.unknown, afterUnknown},
+		{&values.sensitive, afterSensitive},
+	} {
+		var err error
+		if *v.dst, err = hclDecode(v.raw); err != nil {
+			return values, err
+		}
+	}
+	return values, nil
+}
+
+func (v hclValues) attr(name string) hclValues {
+	return hclValues{
+		before:    hclGetAttr(v.before, name),
+		after:     hclGetAttr(v.after, name),
+		unknown:   hclGetAttr(v.unknown, name),
+		sensitive: hclGetAttr(v.sensitive, name),
+		compare:   v.compare,
+	}
+}
+
+func (v hclValues) index(i int, compare bool) hclValues {
+	return hclValues{
+		before:    hclGetIndex(v.before, i),
+		after:     hclGetIndex(v.after, i),
+		unknown:   hclGetIndex(v.unknown, i),
+		sensitive: hclGetIndex(v.sensitive, i),
+		compare:   v.compare && compare,
+	}
+}
+
+func (v hclValues) changed() bool {
+	if !v.compare {
+		return false
+	}
+	return v.unknown == true || !reflect.DeepEqual(v.before, v.after)
+}
+
+func (renderer Renderer) hclResourceBody(change jsonplan.ResourceChange, action plans.Action, schema *jsonprovider.Schema) (string, error) {
+	if schema == nil || schema.Block == nil {
+		return "", fmt.Errorf("no schema for %s", change.Address)
+	}
+
+	compare := action == plans.Update || action == plans.DeleteThenCreate || action == plans.CreateThenDelete
+	values, err := newHCLValues(change.Change.Before, change.Change.After, change.Change.AfterUnknown, change.Change.AfterSensitive, compare)
+	if err != nil {
+		return "", err
+	}
+
+	replace := make(map[string]bool)
+	paths, err := hclDecode(change.Change.ReplacePaths)
+	if err != nil {
+		return "", err
+	}
+	if paths != nil {
+		list, ok := paths.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("replace_paths is %T, not a list", paths)
+		}
+		for _, path := range list {
+			if steps, ok := path.([]interface{}); ok && len(steps) > 0 {
+				if name, ok := steps[0].(string); ok {
+					replace[name] = true
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.writeHCLBlockBody(&buf, schema.Block, values, replace); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (renderer Renderer) writeHCLBlockBody(buf *bytes.Buffer, block *jsonprovider.Block, values hclValues, replace map[string]bool) error {
+	if block == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range block.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attr := values.attr(name)
+		if attr.after == nil && attr.unknown != true && !attr.changed() {
+			// Null attributes are the same as omitted attributes in the
+			// configuration language, so we don't show them unless they
+			// have become null.
+			continue
+		}
+		if block.Attributes[name].Sensitive {
+			attr.sensitive = true
+		}
+		value, err := renderer.hclValue(attr)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", name, err)
+		}
+		fmt.Fprintf(buf, "%s = %s%s\n", renderers.EnsureValidAttributeName(name), value, hclAnnotation(attr, replace[name]))
+	}
+
+	names = names[:0]
+	for name := range block.BlockTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		blockType := block.BlockTypes[name]
+		nested := values.attr(name)
+		switch blockType.NestingMode {
+		case "single", "group":
+			if nested.after == nil {
+				continue
+			}
+			writeHCLBlockAnnotation(buf, hclAnnotation(nested, replace[name]))
+			fmt.Fprintf(buf, "%s {\n", name)
+			if err := renderer.writeHCLBlockBody(buf, blockType.Block, nested, nil); err != nil {
+				return err
+			}
+			buf.WriteString("}\n")
+		case "list", "set":
+			elems, _ := nested.after.([]interface{})
+			for i := range elems {
+				// We can only correlate list elements by index. Set elements
+				// have no stable identity, so we only report whether each
+				// one is new.
+				elem := nested.index(i, blockType.NestingMode == "list")
+				annotation := hclAnnotation(elem, replace[name])
+				if blockType.NestingMode == "set" && nested.compare && !hclContains(nested.before, elem.after) {
+					annotation = " " + hclChangedComment
+				}
+				writeHCLBlockAnnotation(buf, annotation)
+				fmt.Fprintf(buf, "%s {\n", name)
+				if err := renderer.writeHCLBlockBody(buf, blockType.Block, elem, nil); err != nil {
+					return err
+				}
+				buf.WriteString("}\n")
+			}
+		case "map":
+			elems, _ := nested.after.(map[string]interface{})
+			var keys []string
+			for key := range elems {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				elem := nested.attr(key)
+				writeHCLBlockAnnotation(buf, hclAnnotation(elem, replace[name]))
+				fmt.Fprintf(buf, "%s %s {\n", name, hclString(key))
+				if err := renderer.writeHCLBlockBody(buf, blockType.Block, elem, nil); err != nil {
+					return err
+				}
+				buf.WriteString("}\n")
+			}
+		}
+	}
+	return nil
+}
+
+// writeHCLBlockAnnotation writes the annotation for a nested block on its own
+// line before the block header, because trailing comments on block headers
+// get aligned with the comments on the attributes around them.
+func writeHCLBlockAnnotation(buf *bytes.Buffer, annotation string) {
+	if annotation != "" {
+		fmt.Fprintf(buf, "%s\n", strings.TrimSpace(annotation))
+	}
+}
+
+func hclAnnotation(values hclValues, forcesReplacement bool) string {
+	switch {
+	case forcesReplacement && values.changed():
+		return " " + hclReplaceComment
+	case values.changed():
+		return " " + hclChangedComment
+	default:
+		return ""
+	}
+}
+
+// hclValue returns the HCL representation of values.after, taking into
+// account any unknown or sensitive parts of it.
+func (renderer Renderer) hclValue(values hclValues) (string, error) {
+	if values.unknown == true {
+		return hclUnknownValue, nil
+	}
+	if values.sensitive == true && !renderer.ShowSensitive {
+		return hclSensitiveValue, nil
+	}
+
+	switch after := values.after.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return fmt.Sprintf("%t", after), nil
+	case json.Number:
+		return after.String(), nil
+	case string:
+		return hclString(after), nil
+	case []interface{}:
+		if len(after) == 0 {
+			return "[]", nil
+		}
+		var buf strings.Builder
+		buf.WriteString("[\n")
+		for i := range after {
+			elem, err := renderer.hclValue(values.index(i, false))
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&buf, "%s,\n", elem)
+		}
+		buf.WriteString("]")
+		return buf.String(), nil
+	case map[string]interface{}:
+		if len(after) == 0 {
+			return "{}", nil
+		}
+		var keys []string
+		for key := range after {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var buf strings.Builder
+		buf.WriteString("{\n")
+		for _, key := range keys {
+			elem, err := renderer.hclValue(values.attr(key))
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&buf, "%s = %s\n", renderers.EnsureValidAttributeName(key), elem)
+		}
+		buf.WriteString("}")
+		return buf.String(), nil
+	default:
+		return "", fmt.Errorf("unrecognized JSON type %T", after)
+	}
+}
+
+func (renderer Renderer) hclOutputs(outputs map[string]jsonplan.Change) (string, error) {
+	var names []string
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		change := outputs[name]
+		action := jsonplan.UnmarshalActions(change.Actions)
+		if action == plans.NoOp {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "output %s {\n", hclString(name))
+		if action != plans.Delete {
+			values, err := newHCLValues(change.Before, change.After, change.AfterUnknown, change.AfterSensitive, action == plans.Update)
+			if err != nil {
+				return "", fmt.Errorf("failed to render output %q: %w", name, err)
+			}
+			value, err := renderer.hclValue(values)
+			if err != nil {
+				return "", fmt.Errorf("failed to render output %q: %w", name, err)
+			}
+			fmt.Fprintf(&buf, "value = %s%s\n", value, hclAnnotation(values, false))
+		}
+		buf.WriteString("}\n")
+	}
+	if buf.Len() == 0 {
+		return "", nil
+	}
+	return string(hclwrite.Format(buf.Bytes())), nil
+}
+
+func hclString(s string) string {
+	return string(hclwrite.TokensForValue(cty.StringVal(s)).Bytes())
+}
+
+func hclDecode(raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var ret interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&ret); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON value: %w", err)
+	}
+	return ret, nil
+}
+
+func hclGetAttr(v interface{}, name string) interface{} {
+	if obj, ok := v.(map[string]interface{}); ok {
+		return obj[name]
+	}
+	// A whole-value unknown or sensitive marker applies to everything nested
+	// inside it too.
+	if v == true {
+		return true
+	}
+	return nil
+}
+
+func hclGetIndex(v interface{}, i int) interface{} {
+	if list, ok := v.([]interface{}); ok && i < len(list) {
+		return list[i]
+	}
+	if v == true {
+		return true
+	}
+	return nil
+}
+
+func hclContains(list interface{}, v interface{}) bool {
+	elems, _ := list.([]interface{})
+	for _, elem := range elems {
+		if reflect.DeepEqual(elem, v) {
+			return true
+		}
+	}
+	return false
+}
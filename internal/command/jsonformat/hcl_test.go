@@ -0,0 +1,338 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/colorstring"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestRenderHCLPlan(t *testing.T) {
+	providerSchemas := map[string]*jsonprovider.Provider{
+		"test": {
+			ResourceSchemas: map[string]*jsonprovider.Schema{
+				"test_instance": {
+					Block: &jsonprovider.Block{
+						Attributes: map[string]*jsonprovider.Attribute{
+							"id":       {AttributeType: json.RawMessage(`"string"`), Computed: true},
+							"ami":      {AttributeType: json.RawMessage(`"string"`), Required: true},
+							"password": {AttributeType: json.RawMessage(`"string"`), Optional: true, Sensitive: true},
+							"tags":     {AttributeType: json.RawMessage(`["map","string"]`), Optional: true},
+							"unused":   {AttributeType: json.RawMessage(`"string"`), Optional: true},
+						},
+						BlockTypes: map[string]*jsonprovider.BlockType{
+							"disk": {
+								NestingMode: "list",
+								Block: &jsonprovider.Block{
+									Attributes: map[string]*jsonprovider.Attribute{
+										"size": {AttributeType: json.RawMessage(`"number"`), Optional: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tcs := map[string]struct {
+		plan Plan
+		want string
+	}{
+		"no changes": {
+			plan: Plan{},
+			want: "No changes. Your infrastructure matches the configuration.\n",
+		},
+		"create": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_instance.a",
+						Mode:         "managed",
+						Type:         "test_instance",
+						Name:         "a",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions:        []string{"create"},
+							After:          json.RawMessage(`{"ami":"ami-123","password":"hunter2","tags":{"Name":"a","cost center":"1"},"unused":null,"disk":[{"size":10}]}`),
+							AfterUnknown:   json.RawMessage(`{"id":true}`),
+							AfterSensitive: json.RawMessage(`{"password":true}`),
+						},
+					},
+				},
+			},
+			want: `# test_instance.a will be created
+resource "test_instance" "a" {
+  ami      = "ami-123"
+  id       = (known after apply)
+  password = (sensitive value)
+  tags = {
+    Name          = "a"
+    "cost center" = "1"
+  }
+  disk {
+    size = 10
+  }
+}
+
+# Plan: 1 to add, 0 to change, 0 to destroy.
+`,
+		},
+		"update": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_instance.a",
+						Mode:         "managed",
+						Type:         "test_instance",
+						Name:         "a",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions:      []string{"update"},
+							Before:       json.RawMessage(`{"id":"i-1","ami":"ami-123","password":null,"tags":{"Name":"a"},"unused":"x","disk":[{"size":10},{"size":20}]}`),
+							After:        json.RawMessage(`{"id":"i-1","ami":"ami-456","password":null,"tags":{"Name":"a"},"unused":null,"disk":[{"size":10},{"size":30}]}`),
+							AfterUnknown: json.RawMessage(`{}`),
+						},
+					},
+				},
+				OutputChanges: map[string]jsonplan.Change{
+					"ami": {
+						Actions:      []string{"update"},
+						Before:       json.RawMessage(`"ami-123"`),
+						After:        json.RawMessage(`"ami-456"`),
+						AfterUnknown: json.RawMessage(`false`),
+					},
+					"unchanged": {
+						Actions: []string{"no-op"},
+						Before:  json.RawMessage(`"x"`),
+						After:   json.RawMessage(`"x"`),
+					},
+				},
+			},
+			want: `# test_instance.a will be updated in-place
+resource "test_instance" "a" {
+  ami = "ami-456" # changed
+  id  = "i-1"
+  tags = {
+    Name = "a"
+  }
+  unused = null # changed
+  disk {
+    size = 10
+  }
+  # changed
+  disk {
+    size = 30 # changed
+  }
+}
+
+# Changes to Outputs:
+output "ami" {
+  value = "ami-456" # changed
+}
+
+# Plan: 0 to add, 1 to change, 0 to destroy.
+`,
+		},
+		"replace": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_instance.a",
+						Mode:         "managed",
+						Type:         "test_instance",
+						Name:         "a",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions:      []string{"delete", "create"},
+							Before:       json.RawMessage(`{"id":"i-1","ami":"ami-123"}`),
+							After:        json.RawMessage(`{"ami":"ami-456"}`),
+							AfterUnknown: json.RawMessage(`{"id":true}`),
+							ReplacePaths: json.RawMessage(`[["ami"]]`),
+						},
+					},
+				},
+			},
+			want: `# test_instance.a must be replaced
+resource "test_instance" "a" {
+  ami = "ami-456"           # forces replacement
+  id  = (known after apply) # changed
+}
+
+# Plan: 1 to add, 0 to change, 1 to destroy.
+`,
+		},
+		"delete": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_instance.a",
+						Mode:         "managed",
+						Type:         "test_instance",
+						Name:         "a",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"delete"},
+							Before:  json.RawMessage(`{"id":"i-1","ami":"ami-123"}`),
+						},
+					},
+				},
+			},
+			want: `# test_instance.a will be destroyed
+
+# Plan: 0 to add, 0 to change, 1 to destroy.
+`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			renderer := Renderer{
+				Colorize: &colorstring.Colorize{
+					Colors:  colorstring.DefaultColors,
+					Disable: true,
+				},
+				Streams: streams,
+			}
+
+			plan := tc.plan
+			plan.ProviderSchemas = providerSchemas
+			if err := renderer.RenderHCLPlan(plan); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want, done(t).Stdout()); diff != "" {
+				t.Errorf("wrong output\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenderHCLPlan_errors(t *testing.T) {
+	providerSchemas := map[string]*jsonprovider.Provider{
+		"test": {
+			ResourceSchemas: map[string]*jsonprovider.Schema{
+				"test_instance": {
+					Block: &jsonprovider.Block{
+						Attributes: map[string]*jsonprovider.Attribute{
+							"ami": {AttributeType: json.RawMessage(`"string"`), Required: true},
+						},
+					},
+				},
+				"test_no_block": {},
+			},
+		},
+	}
+
+	tcs := map[string]struct {
+		change jsonplan.ResourceChange
+		want   string
+	}{
+		"missing provider": {
+			change: jsonplan.ResourceChange{
+				Address:      "other_instance.a",
+				Mode:         "managed",
+				Type:         "other_instance",
+				ProviderName: "other",
+				Change: jsonplan.Change{
+					Actions: []string{"create"},
+					After:   json.RawMessage(`{"ami":"ami-123"}`),
+				},
+			},
+			want: "no schema for provider other, required by other_instance.a",
+		},
+		"missing resource type": {
+			change: jsonplan.ResourceChange{
+				Address:      "test_missing.a",
+				Mode:         "managed",
+				Type:         "test_missing",
+				ProviderName: "test",
+				Change: jsonplan.Change{
+					Actions: []string{"create"},
+					After:   json.RawMessage(`{"ami":"ami-123"}`),
+				},
+			},
+			want: `no schema for managed "test_missing" in provider test, required by test_missing.a`,
+		},
+		"missing block": {
+			change: jsonplan.ResourceChange{
+				Address:      "test_no_block.a",
+				Mode:         "managed",
+				Type:         "test_no_block",
+				ProviderName: "test",
+				Change: jsonplan.Change{
+					Actions: []string{"create"},
+					After:   json.RawMessage(`{"ami":"ami-123"}`),
+				},
+			},
+			want: "failed to render test_no_block.a: no schema for test_no_block.a",
+		},
+		"invalid json": {
+			change: jsonplan.ResourceChange{
+				Address:      "test_instance.a",
+				Mode:         "managed",
+				Type:         "test_instance",
+				ProviderName: "test",
+				Change: jsonplan.Change{
+					Actions: []string{"create"},
+					After:   json.RawMessage(`{"ami":`),
+				},
+			},
+			want: "failed to render test_instance.a: failed to decode JSON value: unexpected EOF",
+		},
+		"invalid replace paths": {
+			change: jsonplan.ResourceChange{
+				Address:      "test_instance.a",
+				Mode:         "managed",
+				Type:         "test_instance",
+				ProviderName: "test",
+				Change: jsonplan.Change{
+					Actions:      []string{"delete", "create"},
+					Before:       json.RawMessage(`{"ami":"ami-123"}`),
+					After:        json.RawMessage(`{"ami":"ami-456"}`),
+					ReplacePaths: json.RawMessage(`{"ami":true}`),
+				},
+			},
+			want: "failed to render test_instance.a: replace_paths is map[string]interface {}, not a list",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			renderer := Renderer{
+				Colorize: &colorstring.Colorize{
+					Colors:  colorstring.DefaultColors,
+					Disable: true,
+				},
+				Streams: streams,
+			}
+
+			err := renderer.RenderHCLPlan(Plan{
+				ResourceChanges: []jsonplan.ResourceChange{tc.change},
+				ProviderSchemas: providerSchemas,
+			})
+			if err == nil {
+				t.Fatal("succeeded; want error")
+			}
+			if got := err.Error(); got != tc.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.want)
+			}
+			if got := done(t).Stdout(); got != "" {
+				t.Errorf("unexpected output\n%s", got)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/jsonplan"
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
 	"github.com/opentofu/opentofu/internal/command/jsonstate"
+	"github.com/opentofu/opentofu/internal/diffrenderer"
 	"github.com/opentofu/opentofu/internal/plans"
 )
 
@@ -121,6 +122,15 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 				renderer.Streams.Println(format.WordWrap(
 					"OpenTofu has checked that the real remote objects still match the result of your most recent changes, and found no differences.",
 					renderer.Streams.Stdout.Columns()))
+			case plans.DriftOnlyMode:
+				if haveRefreshChanges {
+					return
+				}
+
+				renderer.Streams.Print(renderer.Colorize.Color("\n[reset][bold][green]No drift detected.[reset][bold] Your infrastructure still matches the OpenTofu state.[reset]\n\n"))
+				renderer.Streams.Println(format.WordWrap(
+					"OpenTofu has checked that the real remote objects still match the result of your most recent changes, and found no differences. This was a drift-only plan, so OpenTofu did not compare your configuration with the state.",
+					renderer.Streams.Stdout.Columns()))
 			case plans.DestroyMode:
 				if haveRefreshChanges {
 					renderer.Streams.Print(format.HorizontalRule(renderer.Colorize, renderer.Streams.Stdout.Columns()))
@@ -215,11 +225,15 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 			renderer.Streams.Printf("\nOpenTofu will perform the following actions:\n")
 		}
 
-		for _, change := range changes {
-			diff, render := renderHumanDiff(renderer, change, proposedChange)
-			if render {
-				fmt.Fprintln(renderer.Streams.Stdout.File)
-				renderer.Streams.Println(diff)
+		if renderer.CompactChanges {
+			renderHumanCompactDiffs(renderer, changes)
+		} else {
+			for _, change := range changes {
+				diff, render := renderHumanDiff(renderer, change, proposedChange)
+				if render {
+					fmt.Fprintln(renderer.Streams.Stdout.File)
+					renderer.Streams.Println(diff)
+				}
 			}
 		}
 
@@ -288,7 +302,7 @@ func renderHumanDiffDrift(renderer Renderer, diffs diffs, mode plans.Mode) bool
 	// modes, move-only changes will be rendered in the planned changes, so
 	// we skip them here.
 
-	if mode == plans.RefreshOnlyMode {
+	if mode.RefreshesOnly() {
 		drs = diffs.drift
 	} else {
 		for _, dr := range diffs.drift {
@@ -304,15 +318,26 @@ func renderHumanDiffDrift(renderer Renderer, diffs diffs, mode plans.Mode) bool
 
 	// If the overall plan is empty, and it's not a refresh only plan then we
 	// won't show any drift changes.
-	if diffs.Empty() && mode != plans.RefreshOnlyMode {
+	if diffs.Empty() && !mode.RefreshesOnly() {
 		return false
 	}
 
-	renderer.Streams.Print(renderer.Colorize.Color("\n[bold][cyan]Note:[reset][bold] Objects have changed outside of OpenTofu\n"))
-	renderer.Streams.Println()
-	renderer.Streams.Print(format.WordWrap(
-		"OpenTofu detected the following changes made outside of OpenTofu since the last \"tofu apply\" which may have affected this plan:\n",
-		renderer.Streams.Stdout.Columns()))
+	if mode == plans.DriftOnlyMode {
+		// There are no changes from the configuration to distinguish these
+		// from, so we describe them as drift rather than as context for
+		// the rest of the plan.
+		renderer.Streams.Print(renderer.Colorize.Color("\n[bold][cyan]Drift detected:[reset][bold] Objects have changed outside of OpenTofu\n"))
+		renderer.Streams.Println()
+		renderer.Streams.Print(format.WordWrap(
+			"OpenTofu detected the following differences between the OpenTofu state and the real remote objects since the last \"tofu apply\":\n",
+			renderer.Streams.Stdout.Columns()))
+	} else {
+		renderer.Streams.Print(renderer.Colorize.Color("\n[bold][cyan]Note:[reset][bold] Objects have changed outside of OpenTofu\n"))
+		renderer.Streams.Println()
+		renderer.Streams.Print(format.WordWrap(
+			"OpenTofu detected the following changes made outside of OpenTofu since the last \"tofu apply\" which may have affected this plan:\n",
+			renderer.Streams.Stdout.Columns()))
+	}
 
 	for _, drift := range drs {
 		diff, render := renderHumanDiff(renderer, drift, detectedDrift)
@@ -328,6 +353,11 @@ func renderHumanDiffDrift(renderer Renderer, diffs diffs, mode plans.Mode) bool
 			"\n\nThis is a refresh-only plan, so OpenTofu will not take any actions to undo these. If you were expecting these changes then you can apply this plan to record the updated values in the OpenTofu state without changing any remote objects.",
 			renderer.Streams.Stdout.Columns(),
 		))
+	case plans.DriftOnlyMode:
+		renderer.Streams.Println(format.WordWrap(
+			"\n\nThis is a drift-only plan, so OpenTofu did not evaluate any changes from your configuration and these are not planned actions. To record the updated values in the OpenTofu state, run \"tofu apply -refresh-only\". To undo these changes, run \"tofu apply\".",
+			renderer.Streams.Stdout.Columns(),
+		))
 	default:
 		renderer.Streams.Println(format.WordWrap(
 			"\n\nUnless you have made equivalent changes to your configuration, or ignored the relevant attributes using ignore_changes, the following plan may include actions to undo or respond to these changes.",
@@ -356,6 +386,15 @@ func renderHumanDiff(renderer Renderer, diff diff, cause string) (string, bool)
 	var buf bytes.Buffer
 	buf.WriteString(renderer.Colorize.Color(resourceChangeComment(diff.change, action, cause)))
 
+	if cause == proposedChange {
+		body, comment, ok := renderCustomDiff(renderer, diff)
+		buf.WriteString(comment)
+		if ok {
+			buf.WriteString(fmt.Sprintf("%s %s %s", renderer.Colorize.Color(format.DiffActionSymbol(action)), resourceChangeHeader(diff.change), body))
+			return buf.String(), true
+		}
+	}
+
 	opts := computed.NewRenderHumanOpts(renderer.Colorize, renderer.ShowSensitive)
 
 	if action == plans.Forget {
@@ -368,6 +407,84 @@ func renderHumanDiff(renderer Renderer, diff diff, cause string) (string, bool)
 	return buf.String(), true
 }
 
+// renderCustomDiff renders the body of the given planned change with the diff
+// renderer configured for its resource type, if there is one. If ok is false
+// then the caller must render the body itself, after the comment that
+// explains why the diff renderer wasn't used, if any.
+//
+// Changes with sensitive values are only passed to diff renderers when the
+// renderer is configured to show sensitive values, since a diff renderer
+// can't be trusted to redact them.
+func renderCustomDiff(renderer Renderer, diff diff) (body string, comment string, ok bool) {
+	if diff.change.Mode != jsonstate.ManagedResourceMode {
+		return "", "", false
+	}
+	diffRenderer, exists := renderer.DiffRenderers[diff.change.Type]
+	if !exists {
+		return "", "", false
+	}
+
+	if !renderer.ShowSensitive && (hasSensitiveValues(diff.change.Change.BeforeSensitive) || hasSensitiveValues(diff.change.Change.AfterSensitive)) {
+		comment = renderer.Colorize.Color(fmt.Sprintf("  # [reset](not shown by the %q diff renderer, because it has sensitive values)\n", diffRenderer.Name()))
+		return "", comment, false
+	}
+
+	text, err := diffRenderer.RenderDiff(&diffrenderer.Request{
+		Change: diff.change,
+		Color:  !renderer.Colorize.Disable,
+	})
+	if err != nil {
+		comment = renderer.Colorize.Color("  # [reset][yellow]Warning:[reset] ") + fmt.Sprintf("the %q diff renderer failed: %s\n", diffRenderer.Name(), err)
+		return "", comment, false
+	}
+
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	if text != "" {
+		for _, line := range strings.Split(text, "\n") {
+			buf.WriteString(strings.TrimRight("      "+line, " "))
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString("    }")
+	comment = renderer.Colorize.Color(fmt.Sprintf("  # [reset](rendered by the %q diff renderer)\n", diffRenderer.Name()))
+	return buf.String(), comment, true
+}
+
+// hasSensitiveValues returns true if the given sensitivity mask from the JSON
+// plan representation marks any value as sensitive.
+func hasSensitiveValues(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var mask interface{}
+	if err := json.Unmarshal(raw, &mask); err != nil {
+		// If we can't tell, we must assume that it does.
+		return true
+	}
+	var walk func(v interface{}) bool
+	walk = func(v interface{}) bool {
+		switch v := v.(type) {
+		case bool:
+			return v
+		case []interface{}:
+			for _, elem := range v {
+				if walk(elem) {
+					return true
+				}
+			}
+		case map[string]interface{}:
+			for _, elem := range v {
+				if walk(elem) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return walk(mask)
+}
+
 func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action, changeCause string) string {
 	var buf bytes.Buffer
 
@@ -382,6 +499,9 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 	switch action {
 	case plans.Create:
 		buf.WriteString(fmt.Sprintf("[bold]  # %s[reset] will be created", dispAddr))
+		if resource.ActionReason == jsonplan.ResourceInstanceCreateBecauseDeleted {
+			buf.WriteString("\n  # (because the object was deleted outside of OpenTofu)")
+		}
 	case plans.Read:
 		buf.WriteString(fmt.Sprintf("[bold]  # %s[reset] will be read during apply", dispAddr))
 		switch resource.ActionReason {
@@ -431,6 +551,8 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 			buf.WriteString(fmt.Sprintf("\n  # (because %s.%s is not in configuration)", resource.Type, resource.Name))
 		case jsonplan.ResourceInstanceDeleteBecauseNoMoveTarget:
 			buf.WriteString(fmt.Sprintf("\n  # (because %s was moved to %s, which is not in configuration)", resource.PreviousAddress, resource.Address))
+		case jsonplan.ResourceInstanceDeleteBecauseRemovedBlock:
+			buf.WriteString("\n  # (because of a removed block with destroy = true)")
 		case jsonplan.ResourceInstanceDeleteBecauseNoModule:
 			// FIXME: Ideally we'd truncate addr.Module to reflect the earliest
 			// step that doesn't exist, so it's clearer which call this refers
@@ -501,6 +623,9 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 	if len(resource.PreviousAddress) > 0 && resource.PreviousAddress != resource.Address && !printedMoved {
 		buf.WriteString(fmt.Sprintf("  # [reset](moved from %s)\n", resource.PreviousAddress))
 	}
+	if len(resource.Note) > 0 {
+		buf.WriteString(fmt.Sprintf("  # [reset](note: %s)\n", resource.Note))
+	}
 	if resource.Change.Importing != nil && !printedImported {
 		// We want to make this as forward compatible as possible, and we know
 		// the ID may be removed from the Importing metadata in favour of
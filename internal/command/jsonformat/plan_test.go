@@ -8,6 +8,7 @@ package jsonformat
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -21,6 +22,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/jsonplan"
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/diffrenderer"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
@@ -272,6 +274,47 @@ OpenTofu will perform the following actions:
     }
 
 Plan: 1 to import, 0 to add, 1 to change, 0 to destroy.
+`,
+		},
+		"update_with_note": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Note:         "pending decommission JIRA-123",
+						Change: jsonplan.Change{
+							Actions: []string{"update"},
+							Before: marshalJson(t, map[string]interface{}{
+								"id":    "1D5F5E9E-F2E5-401B-9ED5-692A215AC67E",
+								"value": "Hello, World!",
+							}),
+							After: marshalJson(t, map[string]interface{}{
+								"id":    "1D5F5E9E-F2E5-401B-9ED5-692A215AC67E",
+								"value": "Hello, Universe!",
+							}),
+						},
+					},
+				},
+			},
+			output: `
+OpenTofu used the selected providers to generate the following execution
+plan. Resource actions are indicated with the following symbols:
+  ~ update in-place
+
+OpenTofu will perform the following actions:
+
+  # test_resource.resource will be updated in-place
+  # (note: pending decommission JIRA-123)
+  ~ resource "test_resource" "resource" {
+        id    = "1D5F5E9E-F2E5-401B-9ED5-692A215AC67E"
+      ~ value = "Hello, World!" -> "Hello, Universe!"
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.
 `,
 		},
 		"import_and_update": {
@@ -429,6 +472,207 @@ Plan: 1 to import, 1 to add, 0 to change, 1 to destroy.
 	}
 }
 
+func TestRenderHuman_CompactChanges(t *testing.T) {
+	color := &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+	streams, done := terminal.StreamsForTesting(t)
+
+	change := func(key string, value string) jsonplan.ResourceChange {
+		return jsonplan.ResourceChange{
+			Address:      fmt.Sprintf("test_resource.web[%q]", key),
+			Mode:         "managed",
+			Type:         "test_resource",
+			Name:         "web",
+			Index:        marshalJson(t, key),
+			ProviderName: "test",
+			Change: jsonplan.Change{
+				Actions: []string{"create"},
+				After: marshalJson(t, map[string]interface{}{
+					"value": value,
+				}),
+			},
+		}
+	}
+
+	plan := Plan{
+		PlanFormatVersion:     jsonplan.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		ResourceChanges: []jsonplan.ResourceChange{
+			change("a", "same"),
+			change("b", "different"),
+			change("c", "same"),
+			change("d", "same"),
+		},
+		ProviderSchemas: map[string]*jsonprovider.Provider{
+			"test": {
+				ResourceSchemas: map[string]*jsonprovider.Schema{
+					"test_resource": {
+						Block: &jsonprovider.Block{
+							Attributes: map[string]*jsonprovider.Attribute{
+								"value": {
+									AttributeType: marshalJson(t, "string"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	renderer := Renderer{Colorize: color, Streams: streams, CompactChanges: true}
+	plan.renderHuman(renderer, plans.NormalMode)
+
+	want := `
+OpenTofu used the selected providers to generate the following execution
+plan. Resource actions are indicated with the following symbols:
+  + create
+
+OpenTofu will perform the following actions:
+
+  # test_resource.web["a"] will be created
+  + resource "test_resource" "web" {
+      + value = "same"
+    }
+  # test_resource.web[...] (2 instances): same change as above
+
+  # test_resource.web["b"] will be created
+  + resource "test_resource" "web" {
+      + value = "different"
+    }
+
+Plan: 4 to add, 0 to change, 0 to destroy.
+`
+
+	got := done(t).Stdout()
+	if diff := cmp.Diff(want, got); len(diff) > 0 {
+		t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+	}
+}
+
+func TestRenderHuman_DiffRenderers(t *testing.T) {
+	color := &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+
+	change := func(name string, sensitive bool) jsonplan.ResourceChange {
+		return jsonplan.ResourceChange{
+			Address:      "test_resource." + name,
+			Mode:         "managed",
+			Type:         "test_resource",
+			Name:         name,
+			ProviderName: "test",
+			Change: jsonplan.Change{
+				Actions: []string{"create"},
+				After: marshalJson(t, map[string]interface{}{
+					"value": name,
+				}),
+				AfterSensitive: marshalJson(t, map[string]interface{}{
+					"value": sensitive,
+				}),
+			},
+		}
+	}
+
+	plan := Plan{
+		PlanFormatVersion:     jsonplan.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		ResourceChanges: []jsonplan.ResourceChange{
+			change("custom", false),
+			change("secret", true),
+		},
+		ProviderSchemas: map[string]*jsonprovider.Provider{
+			"test": {
+				ResourceSchemas: map[string]*jsonprovider.Schema{
+					"test_resource": {
+						Block: &jsonprovider.Block{
+							Attributes: map[string]*jsonprovider.Attribute{
+								"value": {
+									AttributeType: marshalJson(t, "string"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		renderer *testDiffRenderer
+		want     string
+	}{
+		"success": {
+			&testDiffRenderer{text: "value: custom\n\n(new)"},
+			`
+  # test_resource.custom will be created
+  # (rendered by the "test" diff renderer)
+  + resource "test_resource" "custom" {
+      value: custom
+
+      (new)
+    }
+
+  # test_resource.secret will be created
+  # (not shown by the "test" diff renderer, because it has sensitive values)
+  + resource "test_resource" "secret" {
+      + value = (sensitive value)
+    }
+`,
+		},
+		"failure": {
+			&testDiffRenderer{err: fmt.Errorf("not installed")},
+			`
+  # test_resource.custom will be created
+  # Warning: the "test" diff renderer failed: not installed
+  + resource "test_resource" "custom" {
+      + value = "custom"
+    }
+
+  # test_resource.secret will be created
+  # (not shown by the "test" diff renderer, because it has sensitive values)
+  + resource "test_resource" "secret" {
+      + value = (sensitive value)
+    }
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			renderer := Renderer{
+				Colorize: color,
+				Streams:  streams,
+				DiffRenderers: map[string]diffrenderer.Renderer{
+					"test_resource": test.renderer,
+				},
+			}
+			plan.renderHuman(renderer, plans.NormalMode)
+
+			got := done(t).Stdout()
+			if !strings.Contains(got, test.want) {
+				t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s", got, test.want)
+			}
+			if len(test.renderer.requests) != 1 || test.renderer.requests[0].Change.Address != "test_resource.custom" {
+				t.Errorf("wrong requests %#v", test.renderer.requests)
+			}
+		})
+	}
+}
+
+type testDiffRenderer struct {
+	text     string
+	err      error
+	requests []*diffrenderer.Request
+}
+
+func (r *testDiffRenderer) Name() string {
+	return "test"
+}
+
+func (r *testDiffRenderer) RenderDiff(req *diffrenderer.Request) (string, error) {
+	r.requests = append(r.requests, req)
+	return r.text, r.err
+}
+
 func TestResourceChange_primitiveTypes(t *testing.T) {
 	testCases := map[string]testCase{
 		"creation": {
@@ -528,6 +772,46 @@ func TestResourceChange_primitiveTypes(t *testing.T) {
 			ExpectedOutput: `  # test_instance.example will be destroyed
   - resource "test_instance" "example" {
       - id = "i-02ae66f368e8518a9" -> null
+    }`,
+		},
+		"deletion because of a removed block": {
+			Action:       plans.Delete,
+			ActionReason: plans.ResourceInstanceDeleteBecauseRemovedBlock,
+			Mode:         addrs.ManagedResourceMode,
+			Before: cty.ObjectVal(map[string]cty.Value{
+				"id": cty.StringVal("i-02ae66f368e8518a9"),
+			}),
+			After: cty.NullVal(cty.EmptyObject),
+			Schema: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Computed: true},
+				},
+			},
+			RequiredReplace: cty.NewPathSet(),
+			ExpectedOutput: `  # test_instance.example will be destroyed
+  # (because of a removed block with destroy = true)
+  - resource "test_instance" "example" {
+      - id = "i-02ae66f368e8518a9" -> null
+    }`,
+		},
+		"creation because the object was deleted": {
+			Action:       plans.Create,
+			ActionReason: plans.ResourceInstanceCreateBecauseDeleted,
+			Mode:         addrs.ManagedResourceMode,
+			Before:       cty.NullVal(cty.EmptyObject),
+			After: cty.ObjectVal(map[string]cty.Value{
+				"id": cty.StringVal("i-02ae66f368e8518a9"),
+			}),
+			Schema: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Computed: true},
+				},
+			},
+			RequiredReplace: cty.NewPathSet(),
+			ExpectedOutput: `  # test_instance.example will be created
+  # (because the object was deleted outside of OpenTofu)
+  + resource "test_instance" "example" {
+      + id = "i-02ae66f368e8518a9"
     }`,
 		},
 		"deletion of deposed object": {
@@ -20,6 +20,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
 	"github.com/opentofu/opentofu/internal/command/jsonstate"
 	viewsjson "github.com/opentofu/opentofu/internal/command/views/json"
+	"github.com/opentofu/opentofu/internal/diffrenderer"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
@@ -83,6 +84,15 @@ type Renderer struct {
 
 	RunningInAutomation bool
 	ShowSensitive       bool
+
+	// CompactChanges groups the planned changes to instances of the same
+	// resource that would render identically, so that only the first of them
+	// is shown in full.
+	CompactChanges bool
+
+	// DiffRenderers are the diff renderers that show the planned changes to
+	// managed resources of particular types, keyed by the resource type.
+	DiffRenderers map[string]diffrenderer.Renderer
 }
 
 func (renderer Renderer) RenderHumanPlan(plan Plan, mode plans.Mode, opts ...plans.Quality) {
@@ -0,0 +1,14 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonmetadata contains types and functions to marshal a
+// configuration's module tree into a document intended for documentation
+// generators.
+//
+// Unlike the format produced by package jsonconfig, the document doesn't
+// need provider schemas, so it can be produced for any configuration whose
+// modules are installed. It describes each module's variables, outputs, local
+// values, resources and module calls, along with the references between them.
+package jsonmetadata
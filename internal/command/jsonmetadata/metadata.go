@@ -0,0 +1,468 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonmetadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang"
+)
+
+// FormatVersion represents the version of the json format and will be
+// incremented for any change to this format that requires changes to a
+// consuming parser.
+const FormatVersion = "1.0"
+
+// Metadata is the top-level object of the document.
+type Metadata struct {
+	FormatVersion string  `json:"format_version"`
+	RootModule    *Module `json:"root_module"`
+}
+
+// Module describes a single module of the configuration.
+type Module struct {
+	// Path is the address of the module, such as "module.network", which is
+	// empty for the root module.
+	Path string `json:"path"`
+
+	RequiredCore      []string                     `json:"required_core,omitempty"`
+	RequiredProviders map[string]*RequiredProvider `json:"required_providers,omitempty"`
+	Variables         map[string]*Variable         `json:"variables,omitempty"`
+	Locals            map[string]*Local            `json:"locals,omitempty"`
+	Outputs           map[string]*Output           `json:"outputs,omitempty"`
+	Resources         []*Resource                  `json:"resources,omitempty"`
+	ModuleCalls       map[string]*ModuleCall       `json:"module_calls,omitempty"`
+}
+
+// RequiredProvider describes an entry in a module's required_providers
+// block, keyed by its local name.
+type RequiredProvider struct {
+	Source            string `json:"source"`
+	VersionConstraint string `json:"version_constraint,omitempty"`
+}
+
+// Variable describes an input variable.
+type Variable struct {
+	// Type is the type constraint of the variable in type constraint syntax,
+	// such as "list(string)".
+	Type string `json:"type"`
+
+	// Default is the default value of the variable, and is set only if
+	// Required is false.
+	Default     json.RawMessage `json:"default,omitempty"`
+	Required    bool            `json:"required"`
+	Description string          `json:"description,omitempty"`
+	Sensitive   bool            `json:"sensitive,omitempty"`
+	Nullable    bool            `json:"nullable"`
+	Pos         Pos             `json:"pos"`
+}
+
+// Local describes a local value.
+type Local struct {
+	References []string `json:"references,omitempty"`
+	Pos        Pos      `json:"pos"`
+}
+
+// Output describes an output value.
+type Output struct {
+	Description string   `json:"description,omitempty"`
+	Sensitive   bool     `json:"sensitive,omitempty"`
+	DependsOn   []string `json:"depends_on,omitempty"`
+	References  []string `json:"references,omitempty"`
+	Pos         Pos      `json:"pos"`
+}
+
+// Resource describes a managed resource or data resource.
+type Resource struct {
+	Address string `json:"address"`
+
+	// Mode is "managed", "data" or "ephemeral".
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+
+	// Provider is the fully-qualified source address of the resource's
+	// provider, and ProviderConfig is the address of the provider
+	// configuration within the module, such as "aws.west".
+	Provider       string `json:"provider"`
+	ProviderConfig string `json:"provider_config"`
+
+	// Repetition is either "count" or "for_each" for resources that declare
+	// multiple instances.
+	Repetition string   `json:"repetition,omitempty"`
+	DependsOn  []string `json:"depends_on,omitempty"`
+	References []string `json:"references,omitempty"`
+	Pos        Pos      `json:"pos"`
+}
+
+// ModuleCall describes a module block and the module it calls.
+type ModuleCall struct {
+	Source            string `json:"source"`
+	VersionConstraint string `json:"version_constraint,omitempty"`
+
+	// Inputs describes the arguments that set the input variables of the
+	// called module, keyed by the name of the variable.
+	Inputs     map[string]*Input `json:"inputs,omitempty"`
+	Repetition string            `json:"repetition,omitempty"`
+	DependsOn  []string          `json:"depends_on,omitempty"`
+	Pos        Pos               `json:"pos"`
+
+	// Module is the called module, which is unset if the module isn't
+	// installed.
+	Module *Module `json:"module,omitempty"`
+}
+
+// Input describes an argument of a module call.
+type Input struct {
+	// References lists the objects of the calling module that the value of
+	// the argument refers to, such as "var.region".
+	References []string `json:"references,omitempty"`
+	Pos        Pos      `json:"pos"`
+}
+
+// Pos is the location in the configuration where an object is declared.
+type Pos struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+}
+
+// Marshal returns the metadata document for the given configuration as JSON.
+func Marshal(config *configs.Config) ([]byte, error) {
+	root, err := marshalModule(config)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&Metadata{
+		FormatVersion: FormatVersion,
+		RootModule:    root,
+	})
+}
+
+func marshalModule(c *configs.Config) (*Module, error) {
+	mod := c.Module
+	ret := &Module{
+		Path: c.Path.String(),
+	}
+
+	for _, vc := range mod.CoreVersionConstraints {
+		ret.RequiredCore = append(ret.RequiredCore, vc.Required.String())
+	}
+
+	if mod.ProviderRequirements != nil && len(mod.ProviderRequirements.RequiredProviders) > 0 {
+		ret.RequiredProviders = make(map[string]*RequiredProvider, len(mod.ProviderRequirements.RequiredProviders))
+		for name, rp := range mod.ProviderRequirements.RequiredProviders {
+			ret.RequiredProviders[name] = &RequiredProvider{
+				Source:            rp.Type.String(),
+				VersionConstraint: rp.Requirement.Required.String(),
+			}
+		}
+	}
+
+	if len(mod.Variables) > 0 {
+		ret.Variables = make(map[string]*Variable, len(mod.Variables))
+		for name, v := range mod.Variables {
+			variable := &Variable{
+				Type:        typeString(v.ConstraintType),
+				Required:    v.Default == cty.NilVal,
+				Description: v.Description,
+				Sensitive:   v.Sensitive,
+				Nullable:    v.Nullable,
+				Pos:         pos(v.DeclRange),
+			}
+			if !variable.Required {
+				def, err := ctyjson.Marshal(v.Default, v.Default.Type())
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal the default value of var.%s: %w", name, err)
+				}
+				variable.Default = def
+			}
+			ret.Variables[name] = variable
+		}
+	}
+
+	if len(mod.Locals) > 0 {
+		ret.Locals = make(map[string]*Local, len(mod.Locals))
+		for name, l := range mod.Locals {
+			ret.Locals[name] = &Local{
+				References: exprReferences(l.Expr),
+				Pos:        pos(l.DeclRange),
+			}
+		}
+	}
+
+	if len(mod.Outputs) > 0 {
+		ret.Outputs = make(map[string]*Output, len(mod.Outputs))
+		for name, o := range mod.Outputs {
+			ret.Outputs[name] = &Output{
+				Description: o.Description,
+				Sensitive:   o.Sensitive,
+				DependsOn:   dependsOn(o.DependsOn),
+				References:  exprReferences(o.Expr),
+				Pos:         pos(o.DeclRange),
+			}
+		}
+	}
+
+	for _, resources := range []map[string]*configs.Resource{mod.ManagedResources, mod.DataResources, mod.EphemeralResources} {
+		for _, r := range resources {
+			resource, err := marshalResource(r)
+			if err != nil {
+				return nil, err
+			}
+			ret.Resources = append(ret.Resources, resource)
+		}
+	}
+	sort.Slice(ret.Resources, func(i, j int) bool {
+		return ret.Resources[i].Address < ret.Resources[j].Address
+	})
+
+	if len(mod.ModuleCalls) > 0 {
+		ret.ModuleCalls = make(map[string]*ModuleCall, len(mod.ModuleCalls))
+		for name, mc := range mod.ModuleCalls {
+			call, err := marshalModuleCall(mc, c.Children[name])
+			if err != nil {
+				return nil, err
+			}
+			ret.ModuleCalls[name] = call
+		}
+	}
+
+	return ret, nil
+}
+
+func marshalResource(r *configs.Resource) (*Resource, error) {
+	ret := &Resource{
+		Address:        r.Addr().String(),
+		Type:           r.Type,
+		Name:           r.Name,
+		Provider:       r.Provider.String(),
+		ProviderConfig: r.ProviderConfigAddr().StringCompact(),
+		Repetition:     repetition(r.Count, r.ForEach),
+		DependsOn:      dependsOn(r.DependsOn),
+		Pos:            pos(r.DeclRange),
+	}
+
+	switch r.Mode {
+	case addrs.ManagedResourceMode:
+		ret.Mode = "managed"
+	case addrs.DataResourceMode:
+		ret.Mode = "data"
+	case addrs.EphemeralResourceMode:
+		ret.Mode = "ephemeral"
+	default:
+		return nil, fmt.Errorf("resource %s has an unsupported mode %s", ret.Address, r.Mode)
+	}
+
+	traversals := bodyTraversals(r.Config)
+	for _, expr := range []hcl.Expression{r.Count, r.ForEach} {
+		if expr != nil {
+			traversals = append(traversals, expr.Variables()...)
+		}
+	}
+	if r.Managed != nil {
+		for _, p := range r.Managed.Provisioners {
+			traversals = append(traversals, bodyTraversals(p.Config)...)
+		}
+	}
+	ret.References = references(traversals)
+
+	return ret, nil
+}
+
+func marshalModuleCall(mc *configs.ModuleCall, child *configs.Config) (*ModuleCall, error) {
+	ret := &ModuleCall{
+		// As in package jsonconfig, we echo back exactly what the user
+		// entered rather than the normalized source address.
+		Source:            mc.SourceAddrRaw,
+		VersionConstraint: mc.Version.Required.String(),
+		Repetition:        repetition(mc.Count, mc.ForEach),
+		DependsOn:         dependsOn(mc.DependsOn),
+		Pos:               pos(mc.DeclRange),
+	}
+
+	// Module calls only have attributes besides their meta-arguments, but
+	// an invalid configuration could have blocks, which we just ignore.
+	attrs, _ := mc.Config.JustAttributes()
+	if len(attrs) > 0 {
+		ret.Inputs = make(map[string]*Input, len(attrs))
+		for name, attr := range attrs {
+			ret.Inputs[name] = &Input{
+				References: exprReferences(attr.Expr),
+				Pos:        pos(attr.Range),
+			}
+		}
+	}
+
+	if child != nil {
+		mod, err := marshalModule(child)
+		if err != nil {
+			return nil, err
+		}
+		ret.Module = mod
+	}
+
+	return ret, nil
+}
+
+// bodyTraversals returns all of the traversals in the given body.
+//
+// Without a schema we can only find the nested blocks of bodies in the native
+// syntax, so for other bodies this returns only the traversals in their
+// top-level attributes.
+func bodyTraversals(body hcl.Body) []hcl.Traversal {
+	if body == nil {
+		return nil
+	}
+
+	// The body is usually what remains after decoding the meta-arguments,
+	// so we must access it through JustAttributes and PartialContent, which
+	// skip those, rather than through its fields.
+	var ret []hcl.Traversal
+	attrs, _ := body.JustAttributes()
+	for _, attr := range attrs {
+		ret = append(ret, attr.Expr.Variables()...)
+	}
+
+	if synBody, ok := body.(*hclsyntax.Body); ok {
+		schema := &hcl.BodySchema{}
+		seen := make(map[string]struct{})
+		for _, block := range synBody.Blocks {
+			if _, ok := seen[block.Type]; ok {
+				continue
+			}
+			seen[block.Type] = struct{}{}
+			schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{
+				Type:       block.Type,
+				LabelNames: make([]string, len(block.Labels)),
+			})
+		}
+		content, _, _ := synBody.PartialContent(schema)
+		for _, block := range content.Blocks {
+			ret = append(ret, bodyTraversals(block.Body)...)
+		}
+	}
+	return ret
+}
+
+func exprReferences(expr hcl.Expression) []string {
+	if expr == nil {
+		return nil
+	}
+	return references(expr.Variables())
+}
+
+// references returns the sorted, unique addresses of the objects that the
+// given traversals refer to.
+//
+// References to objects that don't belong to the module, like count.index
+// and path.module, and traversals that aren't valid references, such as the
+// iterators of dynamic blocks, are omitted.
+func references(traversals []hcl.Traversal) []string {
+	seen := make(map[string]struct{})
+	var ret []string
+	for _, traversal := range traversals {
+		refs, diags := lang.References(addrs.ParseRef, []hcl.Traversal{traversal})
+		if diags.HasErrors() {
+			continue
+		}
+		for _, ref := range refs {
+			switch ref.Subject.(type) {
+			case addrs.CountAttr, addrs.ForEachAttr, addrs.PathAttr, addrs.TerraformAttr:
+				continue
+			}
+			if ref.Subject == addrs.Self {
+				continue
+			}
+			addr := ref.Subject.String()
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			ret = append(ret, addr)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func dependsOn(traversals []hcl.Traversal) []string {
+	var ret []string
+	for _, traversal := range traversals {
+		ref, diags := addrs.ParseRef(traversal)
+		// tofu validate would have reported invalid references already, so
+		// we just skip them here.
+		if !diags.HasErrors() {
+			ret = append(ret, ref.Subject.String())
+		}
+	}
+	return ret
+}
+
+func repetition(count, forEach hcl.Expression) string {
+	switch {
+	case count != nil:
+		return "count"
+	case forEach != nil:
+		return "for_each"
+	default:
+		return ""
+	}
+}
+
+// typeString returns the given type constraint in type constraint syntax,
+// including the optional attribute modifiers that typeexpr.TypeString
+// doesn't include.
+func typeString(ty cty.Type) string {
+	switch {
+	case ty.IsListType():
+		return "list(" + typeString(ty.ElementType()) + ")"
+	case ty.IsSetType():
+		return "set(" + typeString(ty.ElementType()) + ")"
+	case ty.IsMapType():
+		return "map(" + typeString(ty.ElementType()) + ")"
+	case ty.IsTupleType():
+		elems := make([]string, len(ty.TupleElementTypes()))
+		for i, ety := range ty.TupleElementTypes() {
+			elems[i] = typeString(ety)
+		}
+		return "tuple([" + strings.Join(elems, ",") + "])"
+	case ty.IsObjectType():
+		names := make([]string, 0, len(ty.AttributeTypes()))
+		for name := range ty.AttributeTypes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		attrs := make([]string, len(names))
+		for i, name := range names {
+			attr := typeString(ty.AttributeType(name))
+			if ty.AttributeOptional(name) {
+				attr = "optional(" + attr + ")"
+			}
+			attrs[i] = name + "=" + attr
+		}
+		return "object({" + strings.Join(attrs, ",") + "})"
+	default:
+		return typeexpr.TypeString(ty)
+	}
+}
+
+func pos(rng hcl.Range) Pos {
+	return Pos{
+		Filename: rng.Filename,
+		Line:     rng.Start.Line,
+	}
+}
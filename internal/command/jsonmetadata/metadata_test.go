@@ -0,0 +1,151 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonmetadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/initwd"
+)
+
+func TestMarshal(t *testing.T) {
+	config, _, cleanup := initwd.MustLoadConfigForTests(t, "testdata/basic", "tests")
+	defer cleanup()
+
+	got, err := Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotMetadata Metadata
+	if err := json.Unmarshal(got, &gotMetadata); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Metadata{
+		FormatVersion: "1.0",
+		RootModule: &Module{
+			Path:         "",
+			RequiredCore: []string{">= 1.6.0"},
+			RequiredProviders: map[string]*RequiredProvider{
+				"test": {
+					Source:            "registry.opentofu.org/hashicorp/test",
+					VersionConstraint: "~> 1.0",
+				},
+			},
+			Variables: map[string]*Variable{
+				"region": {
+					Type:        "string",
+					Required:    true,
+					Description: "The region to deploy to.",
+					Nullable:    true,
+					Pos:         Pos{Filename: "testdata/basic/main.tf", Line: 12},
+				},
+				"settings": {
+					Type:      "object({name=string,size=optional(number)})",
+					Default:   json.RawMessage(`{"name":"example","size":null}`),
+					Sensitive: true,
+					Nullable:  true,
+					Pos:       Pos{Filename: "testdata/basic/main.tf", Line: 17},
+				},
+			},
+			Locals: map[string]*Local{
+				"prefix": {
+					References: []string{"var.region", "var.settings"},
+					Pos:        Pos{Filename: "testdata/basic/main.tf", Line: 29},
+				},
+			},
+			Outputs: map[string]*Output{
+				"web_ids": {
+					Description: "The IDs of the web instances.",
+					References:  []string{"test_instance.web"},
+					Pos:         Pos{Filename: "testdata/basic/main.tf", Line: 57},
+				},
+			},
+			Resources: []*Resource{
+				{
+					Address:        "data.test_image.base",
+					Mode:           "data",
+					Type:           "test_image",
+					Name:           "base",
+					Provider:       "registry.opentofu.org/hashicorp/test",
+					ProviderConfig: "test",
+					References:     []string{"var.region"},
+					Pos:            Pos{Filename: "testdata/basic/main.tf", Line: 53},
+				},
+				{
+					Address:        "test_instance.web",
+					Mode:           "managed",
+					Type:           "test_instance",
+					Name:           "web",
+					Provider:       "registry.opentofu.org/hashicorp/test",
+					ProviderConfig: "test",
+					Repetition:     "for_each",
+					DependsOn:      []string{"module.network"},
+					References:     []string{"data.test_image.base", "module.network[0].subnet_id"},
+					Pos:            Pos{Filename: "testdata/basic/main.tf", Line: 40},
+				},
+			},
+			ModuleCalls: map[string]*ModuleCall{
+				"network": {
+					Source: "./network",
+					Inputs: map[string]*Input{
+						"cidr": {
+							Pos: Pos{Filename: "testdata/basic/main.tf", Line: 36},
+						},
+						"name": {
+							References: []string{"local.prefix"},
+							Pos:        Pos{Filename: "testdata/basic/main.tf", Line: 37},
+						},
+					},
+					Repetition: "count",
+					Pos:        Pos{Filename: "testdata/basic/main.tf", Line: 32},
+					Module: &Module{
+						Path: "module.network",
+						Variables: map[string]*Variable{
+							"cidr": {
+								Type:     "string",
+								Required: true,
+								Nullable: true,
+								Pos:      Pos{Filename: "testdata/basic/network/main.tf", Line: 1},
+							},
+							"name": {
+								Type:     "string",
+								Default:  json.RawMessage(`null`),
+								Nullable: true,
+								Pos:      Pos{Filename: "testdata/basic/network/main.tf", Line: 5},
+							},
+						},
+						Outputs: map[string]*Output{
+							"subnet_id": {
+								Sensitive:  true,
+								References: []string{"test_subnet.main"},
+								Pos:        Pos{Filename: "testdata/basic/network/main.tf", Line: 18},
+							},
+						},
+						Resources: []*Resource{
+							{
+								Address:        "test_subnet.main",
+								Mode:           "managed",
+								Type:           "test_subnet",
+								Name:           "main",
+								Provider:       "registry.opentofu.org/hashicorp/test",
+								ProviderConfig: "test",
+								References:     []string{"var.cidr", "var.name"},
+								Pos:            Pos{Filename: "testdata/basic/network/main.tf", Line: 11},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, gotMetadata); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+terraform {
+  required_version = ">= 1.6.0"
+
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = "~> 1.0"
+    }
+  }
+}
+
+variable "region" {
+  type        = string
+  description = "The region to deploy to."
+}
+
+variable "settings" {
+  type = object({
+    name = string
+    size = optional(number)
+  })
+  default = {
+    name = "example"
+  }
+  sensitive = true
+}
+
+locals {
+  prefix = "${var.region}-${var.settings.name}"
+}
+
+module "network" {
+  source = "./network"
+  count  = 2
+
+  cidr = "10.${count.index}.0.0/16"
+  name = local.prefix
+}
+
+resource "test_instance" "web" {
+  for_each = toset(["a", "b"])
+
+  ami       = data.test_image.base.id
+  subnet_id = module.network[0].subnet_id
+
+  network_interface {
+    name = each.key
+  }
+
+  depends_on = [module.network]
+}
+
+data "test_image" "base" {
+  name = var.region
+}
+
+output "web_ids" {
+  description = "The IDs of the web instances."
+  value       = [for i in test_instance.web : i.id]
+}
@@ -0,0 +1,21 @@
+variable "cidr" {
+  type = string
+}
+
+variable "name" {
+  type     = string
+  default  = null
+  nullable = true
+}
+
+resource "test_subnet" "main" {
+  cidr_block = var.cidr
+  tags = {
+    Name = var.name
+  }
+}
+
+output "subnet_id" {
+  value     = test_subnet.main.id
+  sensitive = true
+}
@@ -0,0 +1,14 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonpermissions implements a static analysis of the permissions a
+// plan requires, based on the optional permission hints that providers can
+// declare in their schemas, and the JSON representation of its result.
+//
+// The analysis is intended to help operators build least-privilege roles for
+// their pipelines. It can only be as accurate as the hints the providers
+// declare, so objects without hints are reported separately rather than
+// silently ignored.
+package jsonpermissions
@@ -0,0 +1,255 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpermissions
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// FormatVersion represents the version of the json format and will be
+// incremented for any change to this format that requires changes to a
+// consuming parser.
+const FormatVersion = "1.0"
+
+// The operations a plan may need to perform on an object, which select the
+// corresponding field of providers.PermissionHints.
+const (
+	OperationCreate = "create"
+	OperationRead   = "read"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Report is the top-level representation of the permissions required to
+// apply a plan.
+type Report struct {
+	FormatVersion string `json:"format_version"`
+
+	// Providers lists, for each provider that declares permission hints, the
+	// union of the permissions required by the plan, sorted by source.
+	Providers []Provider `json:"providers"`
+
+	// MissingHints lists the objects the plan acts on whose provider
+	// doesn't declare permission hints for their type, so the report can't
+	// account for them.
+	MissingHints []Resource `json:"missing_hints"`
+}
+
+// Provider describes the permissions required from a single provider.
+type Provider struct {
+	Source      string     `json:"source"`
+	Permissions []string   `json:"permissions"`
+	Resources   []Resource `json:"resources"`
+}
+
+// Resource describes the operations the plan performs on a single resource
+// instance object, and the permissions those operations require.
+type Resource struct {
+	Address     string   `json:"address"`
+	Provider    string   `json:"provider,omitempty"`
+	Operations  []string `json:"operations"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Marshal returns the JSON encoding of the permissions report for the given
+// plan.
+func Marshal(plan *plans.Plan, schemas *tofu.Schemas) ([]byte, error) {
+	return json.Marshal(Analyze(plan, schemas))
+}
+
+// Analyze maps each action in the given plan to the permission hints declared
+// by the relevant provider schemas, and returns the resulting report.
+//
+// Objects that already exist are refreshed before they are changed, so every
+// action on an existing object also requires the read permissions. Data
+// sources read while planning are found in the prior state, as they don't
+// produce a change.
+func Analyze(plan *plans.Plan, schemas *tofu.Schemas) *Report {
+	report := &Report{
+		FormatVersion: FormatVersion,
+		Providers:     []Provider{},
+		MissingHints:  []Resource{},
+	}
+
+	type object struct {
+		addr       string
+		resource   addrs.AbsResourceInstance
+		provider   addrs.Provider
+		operations []string
+	}
+	var objects []object
+	seen := make(map[string]bool)
+
+	if plan.Changes != nil {
+		for _, rc := range plan.Changes.Resources {
+			addr := rc.Addr.String()
+			if rc.DeposedKey != states.NotDeposed {
+				addr = addr + " (deposed object " + rc.DeposedKey.String() + ")"
+			}
+			seen[addr] = true
+
+			ops := operationsForAction(rc.Addr.Resource.Resource.Mode, rc.Action)
+			if len(ops) == 0 {
+				continue
+			}
+			objects = append(objects, object{
+				addr:       addr,
+				resource:   rc.Addr,
+				provider:   rc.ProviderAddr.Provider,
+				operations: ops,
+			})
+		}
+	}
+
+	if plan.PriorState != nil {
+		for _, ms := range plan.PriorState.Modules {
+			for _, rs := range ms.Resources {
+				if rs.Addr.Resource.Mode != addrs.DataResourceMode {
+					continue
+				}
+				for key := range rs.Instances {
+					inst := rs.Addr.Instance(key)
+					if seen[inst.String()] {
+						continue
+					}
+					objects = append(objects, object{
+						addr:       inst.String(),
+						resource:   inst,
+						provider:   rs.ProviderConfig.Provider,
+						operations: []string{OperationRead},
+					})
+				}
+			}
+		}
+	}
+
+	byProvider := make(map[addrs.Provider]*Provider)
+	permissions := make(map[addrs.Provider]map[string]bool)
+	for _, obj := range objects {
+		hints := permissionHints(schemas, obj.provider, obj.resource.Resource.Resource)
+		if hints == nil {
+			report.MissingHints = append(report.MissingHints, Resource{
+				Address:    obj.addr,
+				Provider:   obj.provider.String(),
+				Operations: obj.operations,
+			})
+			continue
+		}
+
+		p, ok := byProvider[obj.provider]
+		if !ok {
+			p = &Provider{
+				Source:      obj.provider.String(),
+				Permissions: []string{},
+			}
+			byProvider[obj.provider] = p
+			permissions[obj.provider] = make(map[string]bool)
+		}
+
+		required := make(map[string]bool)
+		for _, op := range obj.operations {
+			for _, perm := range hintsForOperation(hints, op) {
+				required[perm] = true
+				permissions[obj.provider][perm] = true
+			}
+		}
+		p.Resources = append(p.Resources, Resource{
+			Address:     obj.addr,
+			Operations:  obj.operations,
+			Permissions: sortedKeys(required),
+		})
+	}
+
+	for provider, p := range byProvider {
+		p.Permissions = sortedKeys(permissions[provider])
+		sort.Slice(p.Resources, func(i, j int) bool {
+			return p.Resources[i].Address < p.Resources[j].Address
+		})
+		report.Providers = append(report.Providers, *p)
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		return report.Providers[i].Source < report.Providers[j].Source
+	})
+	sort.Slice(report.MissingHints, func(i, j int) bool {
+		return report.MissingHints[i].Address < report.MissingHints[j].Address
+	})
+
+	return report
+}
+
+// operationsForAction returns the operations required to carry out the given
+// planned action on an object of the given mode.
+func operationsForAction(mode addrs.ResourceMode, action plans.Action) []string {
+	if mode == addrs.DataResourceMode {
+		if action == plans.Read {
+			return []string{OperationRead}
+		}
+		// Removing a data source from state doesn't involve the provider.
+		return nil
+	}
+
+	switch action {
+	case plans.NoOp:
+		return []string{OperationRead}
+	case plans.Create:
+		return []string{OperationCreate}
+	case plans.Update:
+		return []string{OperationRead, OperationUpdate}
+	case plans.Delete:
+		return []string{OperationRead, OperationDelete}
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return []string{OperationRead, OperationCreate, OperationDelete}
+	default:
+		// Forgetting an object only modifies the state.
+		return nil
+	}
+}
+
+func permissionHints(schemas *tofu.Schemas, provider addrs.Provider, resource addrs.Resource) *providers.PermissionHints {
+	if schemas == nil {
+		return nil
+	}
+	schema := schemas.ProviderSchema(provider)
+	switch resource.Mode {
+	case addrs.ManagedResourceMode:
+		return schema.ResourceTypes[resource.Type].PermissionHints
+	case addrs.DataResourceMode:
+		return schema.DataSources[resource.Type].PermissionHints
+	default:
+		return nil
+	}
+}
+
+func hintsForOperation(hints *providers.PermissionHints, op string) []string {
+	switch op {
+	case OperationCreate:
+		return hints.Create
+	case OperationRead:
+		return hints.Read
+	case OperationUpdate:
+		return hints.Update
+	case OperationDelete:
+		return hints.Delete
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	ret := make([]string, 0, len(set))
+	for k := range set {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,155 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpermissions
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestAnalyze(t *testing.T) {
+	provider := addrs.NewDefaultProvider("test")
+	other := addrs.NewDefaultProvider("other")
+	providerConfig := addrs.AbsProviderConfig{Provider: provider, Module: addrs.RootModule}
+	otherConfig := addrs.AbsProviderConfig{Provider: other, Module: addrs.RootModule}
+
+	schemas := &tofu.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			provider: {
+				ResourceTypes: map[string]providers.Schema{
+					"test_instance": {
+						PermissionHints: &providers.PermissionHints{
+							Create: []string{"instances.create", "networks.use"},
+							Read:   []string{"instances.get"},
+							Update: []string{"instances.update"},
+							Delete: []string{"instances.delete"},
+						},
+					},
+					"test_bucket": {
+						PermissionHints: &providers.PermissionHints{
+							Create: []string{"buckets.create"},
+							Read:   []string{"buckets.get"},
+							Delete: []string{"buckets.delete"},
+						},
+					},
+				},
+				DataSources: map[string]providers.Schema{
+					"test_image": {
+						PermissionHints: &providers.PermissionHints{
+							Read: []string{"images.get"},
+						},
+					},
+				},
+			},
+			other: {
+				ResourceTypes: map[string]providers.Schema{
+					"other_thing": {},
+				},
+			},
+		},
+	}
+
+	change := func(mode addrs.ResourceMode, typ, name string, action plans.Action, provider addrs.AbsProviderConfig) *plans.ResourceInstanceChangeSrc {
+		return &plans.ResourceInstanceChangeSrc{
+			Addr: addrs.Resource{
+				Mode: mode,
+				Type: typ,
+				Name: name,
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			ProviderAddr: provider,
+			ChangeSrc: plans.ChangeSrc{
+				Action: action,
+			},
+		}
+	}
+
+	changes := plans.NewChanges()
+	changes.Resources = []*plans.ResourceInstanceChangeSrc{
+		change(addrs.ManagedResourceMode, "test_instance", "web", plans.Update, providerConfig),
+		change(addrs.ManagedResourceMode, "test_bucket", "logs", plans.Create, providerConfig),
+		change(addrs.ManagedResourceMode, "test_instance", "old", plans.Forget, providerConfig),
+		change(addrs.ManagedResourceMode, "other_thing", "x", plans.DeleteThenCreate, otherConfig),
+	}
+
+	prior := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.DataResourceMode,
+				Type: "test_image",
+				Name: "base",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{}`),
+			},
+			providerConfig,
+			addrs.NoKey,
+		)
+	})
+
+	got := Analyze(&plans.Plan{Changes: changes, PriorState: prior}, schemas)
+	want := &Report{
+		FormatVersion: FormatVersion,
+		Providers: []Provider{
+			{
+				Source: "registry.opentofu.org/hashicorp/test",
+				Permissions: []string{
+					"buckets.create",
+					"images.get",
+					"instances.get",
+					"instances.update",
+				},
+				Resources: []Resource{
+					{
+						Address:     "data.test_image.base",
+						Operations:  []string{OperationRead},
+						Permissions: []string{"images.get"},
+					},
+					{
+						Address:     "test_bucket.logs",
+						Operations:  []string{OperationCreate},
+						Permissions: []string{"buckets.create"},
+					},
+					{
+						Address:     "test_instance.web",
+						Operations:  []string{OperationRead, OperationUpdate},
+						Permissions: []string{"instances.get", "instances.update"},
+					},
+				},
+			},
+		},
+		MissingHints: []Resource{
+			{
+				Address:    "other_thing.x",
+				Provider:   "registry.opentofu.org/hashicorp/other",
+				Operations: []string{OperationRead, OperationCreate, OperationDelete},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong report\n%s", diff)
+	}
+}
+
+func TestAnalyze_noChanges(t *testing.T) {
+	got := Analyze(&plans.Plan{Changes: plans.NewChanges(), PriorState: states.NewState()}, &tofu.Schemas{})
+	want := &Report{
+		FormatVersion: FormatVersion,
+		Providers:     []Provider{},
+		MissingHints:  []Resource{},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong report\n%s", diff)
+	}
+}
@@ -43,6 +43,8 @@ const (
 	ResourceInstanceDeleteBecauseEachKey          = "delete_because_each_key"
 	ResourceInstanceDeleteBecauseNoModule         = "delete_because_no_module"
 	ResourceInstanceDeleteBecauseNoMoveTarget     = "delete_because_no_move_target"
+	ResourceInstanceDeleteBecauseRemovedBlock     = "delete_because_removed_block"
+	ResourceInstanceCreateBecauseDeleted          = "create_because_deleted"
 	ResourceInstanceReadBecauseConfigUnknown      = "read_because_config_unknown"
 	ResourceInstanceReadBecauseDependencyPending  = "read_because_dependency_pending"
 	ResourceInstanceReadBecauseCheckNested        = "read_because_check_nested"
@@ -55,8 +57,9 @@ type Plan struct {
 	TerraformVersion string      `json:"terraform_version,omitempty"`
 	Variables        Variables   `json:"variables,omitempty"`
 	PlannedValues    StateValues `json:"planned_values,omitempty"`
-	// ResourceDrift and ResourceChanges are sorted in a user-friendly order
-	// that is undefined at this time, but consistent.
+	// ResourceDrift and ResourceChanges are sorted by resource instance
+	// address, as described in the documentation of the format. The order
+	// is part of the format, so changing it requires a new major version.
 	ResourceDrift      []ResourceChange  `json:"resource_drift,omitempty"`
 	ResourceChanges    []ResourceChange  `json:"resource_changes,omitempty"`
 	OutputChanges      map[string]Change `json:"output_changes,omitempty"`
@@ -184,7 +187,7 @@ func MarshalForRenderer(
 		return nil, nil, nil, nil, err
 	}
 
-	if output.ResourceChanges, err = MarshalResourceChanges(p.Changes.Resources, schemas); err != nil {
+	if output.ResourceChanges, err = marshalResourceChanges(p.Changes.Resources, schemas, p.PriorState); err != nil {
 		return nil, nil, nil, nil, err
 	}
 
@@ -194,7 +197,7 @@ func MarshalForRenderer(
 		// modes, move-only changes will be included in the planned changes, so
 		// we skip them here.
 		var driftedResources []*plans.ResourceInstanceChangeSrc
-		if p.UIMode == plans.RefreshOnlyMode {
+		if p.UIMode.RefreshesOnly() {
 			driftedResources = p.DriftedResources
 		} else {
 			for _, dr := range p.DriftedResources {
@@ -203,7 +206,7 @@ func MarshalForRenderer(
 				}
 			}
 		}
-		output.ResourceDrift, err = MarshalResourceChanges(driftedResources, schemas)
+		output.ResourceDrift, err = marshalResourceChanges(driftedResources, schemas, p.PriorState)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -247,7 +250,7 @@ func MarshalForLog(
 		// modes, move-only changes will be included in the planned changes, so
 		// we skip them here.
 		var driftedResources []*plans.ResourceInstanceChangeSrc
-		if p.UIMode == plans.RefreshOnlyMode {
+		if p.UIMode.RefreshesOnly() {
 			driftedResources = p.DriftedResources
 		} else {
 			for _, dr := range p.DriftedResources {
@@ -256,7 +259,7 @@ func MarshalForLog(
 				}
 			}
 		}
-		output.ResourceDrift, err = MarshalResourceChanges(driftedResources, schemas)
+		output.ResourceDrift, err = marshalResourceChanges(driftedResources, schemas, p.PriorState)
 		if err != nil {
 			return nil, fmt.Errorf("error in marshaling resource drift: %w", err)
 		}
@@ -268,7 +271,7 @@ func MarshalForLog(
 
 	// output.ResourceChanges
 	if p.Changes != nil {
-		output.ResourceChanges, err = MarshalResourceChanges(p.Changes.Resources, schemas)
+		output.ResourceChanges, err = marshalResourceChanges(p.Changes.Resources, schemas, p.PriorState)
 		if err != nil {
 			return nil, fmt.Errorf("error in marshaling resource changes: %w", err)
 		}
@@ -377,6 +380,12 @@ func (p *Plan) marshalPlanVariables(vars map[string]plans.DynamicValue, decls ma
 // ensure parity between the renderers. It probably shouldn't be used anywhere
 // else.
 func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schemas *tofu.Schemas) ([]ResourceChange, error) {
+	return marshalResourceChanges(resources, schemas, nil)
+}
+
+// marshalResourceChanges is MarshalResourceChanges with the notes of the
+// resource instances taken from the given prior state, which may be nil.
+func marshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schemas *tofu.Schemas, priorState *states.State) ([]ResourceChange, error) {
 	var ret []ResourceChange
 
 	var sortedResources []*plans.ResourceInstanceChangeSrc
@@ -501,6 +510,12 @@ func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schema
 			r.Deposed = rc.DeposedKey.String()
 		}
 
+		if priorState != nil {
+			if is := priorState.ResourceInstance(addr); is != nil {
+				r.Note = is.Note
+			}
+		}
+
 		key := addr.Resource.Key
 		if key != nil {
 			value := key.Value()
@@ -545,6 +560,10 @@ func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schema
 			r.ActionReason = ResourceInstanceDeleteBecauseNoModule
 		case plans.ResourceInstanceDeleteBecauseNoMoveTarget:
 			r.ActionReason = ResourceInstanceDeleteBecauseNoMoveTarget
+		case plans.ResourceInstanceDeleteBecauseRemovedBlock:
+			r.ActionReason = ResourceInstanceDeleteBecauseRemovedBlock
+		case plans.ResourceInstanceCreateBecauseDeleted:
+			r.ActionReason = ResourceInstanceCreateBecauseDeleted
 		case plans.ResourceInstanceReadBecauseConfigUnknown:
 			r.ActionReason = ResourceInstanceReadBecauseConfigUnknown
 		case plans.ResourceInstanceReadBecauseDependencyPending:
@@ -687,10 +706,14 @@ func (p *Plan) marshalRelevantAttrs(plan *plans.Plan) error {
 		p.RelevantAttributes = append(p.RelevantAttributes, ResourceAttr{addr, path})
 	}
 
-	// We sort the relevant attributes by resource address to make the output
-	// deterministic. Our own equivalence tests rely on it.
+	// We sort the relevant attributes by resource address and then by
+	// attribute path to make the output deterministic. Our own equivalence
+	// tests rely on it.
 	sort.Slice(p.RelevantAttributes, func(i, j int) bool {
-		return p.RelevantAttributes[i].Resource < p.RelevantAttributes[j].Resource
+		if p.RelevantAttributes[i].Resource != p.RelevantAttributes[j].Resource {
+			return p.RelevantAttributes[i].Resource < p.RelevantAttributes[j].Resource
+		}
+		return string(p.RelevantAttributes[i].Attr) < string(p.RelevantAttributes[j].Attr)
 	})
 
 	return nil
@@ -911,6 +934,12 @@ func encodePaths(pathSet cty.PathSet) (json.RawMessage, error) {
 		jsonPaths = append(jsonPaths, jsonPath)
 	}
 
+	// A PathSet has no inherent order, so we sort the paths by their encoding
+	// to make the output deterministic.
+	sort.Slice(jsonPaths, func(i, j int) bool {
+		return string(jsonPaths[i]) < string(jsonPaths[j])
+	})
+
 	return json.Marshal(jsonPaths)
 }
 
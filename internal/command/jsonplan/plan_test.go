@@ -7,16 +7,16 @@ package jsonplan
 
 import (
 	"encoding/json"
-	"fmt"
 	"reflect"
-	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang/globalref"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
 )
 
 func TestOmitUnknowns(t *testing.T) {
@@ -306,65 +306,116 @@ func TestEncodePaths(t *testing.T) {
 			json.RawMessage(`[["triggers"]]`),
 		},
 		"multiple paths of different types": {
-			// The order of the path sets is not guaranteed, so we sort the
-			// result by the number of elements in the path to make the test deterministic.
+			// The paths are sorted by their JSON encoding, so the output
+			// doesn't depend on the order of the path set.
 			cty.NewPathSet(
-				cty.GetAttrPath("alpha").GetAttr("beta"),                            // 2 elements
-				cty.GetAttrPath("triggers").IndexString("name").IndexString("test"), // 3 elements
-				cty.IndexIntPath(0).IndexInt(1).IndexInt(2).IndexInt(3),             // 4 elements
+				cty.IndexIntPath(0).IndexInt(1).IndexInt(2).IndexInt(3),
+				cty.GetAttrPath("triggers").IndexString("name").IndexString("test"),
+				cty.GetAttrPath("alpha").GetAttr("beta"),
 			),
-			json.RawMessage(`[[0,1,2,3],["alpha","beta"],["triggers","name","test"]]`),
+			json.RawMessage(`[["alpha","beta"],["triggers","name","test"],[0,1,2,3]]`),
 		},
 	}
 
-	// comp is a custom comparator for comparing JSON arrays. It sorts the
-	// arrays based on the number of elements in each path before comparing them.
-	// this allows our test cases to be more flexible about the order of the
-	// paths in the result. and deterministic on both 32 and 64 bit architectures.
-	comp := func(a, b json.RawMessage) (bool, error) {
-		if a == nil && b == nil {
-			return true, nil // Both are nil, they are equal
-		}
-		if a == nil || b == nil {
-			return false, nil // One is nil and the other is not, they are not equal
-		}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The iteration order of a path set isn't stable, so we check
+			// that we get the same result every time.
+			for i := 0; i < 10; i++ {
+				got, err := encodePaths(test.Input)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if !cmp.Equal(got, test.Want) {
+					t.Fatalf("paths do not match:\n%s", cmp.Diff(string(got), string(test.Want)))
+				}
+			}
+		})
+	}
+}
+
+func TestMarshalRelevantAttrs(t *testing.T) {
+	foo := mustAddr("test_thing.foo")
+	bar := mustAddr("test_thing.bar")
+	plan := &plans.Plan{
+		RelevantAttributes: []globalref.ResourceAttr{
+			{Resource: foo, Attr: cty.GetAttrPath("woozles")},
+			{Resource: bar, Attr: cty.GetAttrPath("woozles")},
+			{Resource: foo, Attr: cty.GetAttrPath("foozles")},
+		},
+	}
 
-		var pathsA, pathsB [][]interface{}
-		err := json.Unmarshal(a, &pathsA)
+	var output Plan
+	if err := output.marshalRelevantAttrs(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ResourceAttr{
+		{Resource: "test_thing.bar", Attr: json.RawMessage(`["woozles"]`)},
+		{Resource: "test_thing.foo", Attr: json.RawMessage(`["foozles"]`)},
+		{Resource: "test_thing.foo", Attr: json.RawMessage(`["woozles"]`)},
+	}
+	if diff := cmp.Diff(want, output.RelevantAttributes); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestMarshalResourceChanges_order(t *testing.T) {
+	change := func(addr string, deposed states.DeposedKey) *plans.ResourceInstanceChangeSrc {
+		val, err := plans.NewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+			"woozles": cty.StringVal("foo"),
+			"foozles": cty.StringVal("bar"),
+		}), cty.Object(map[string]cty.Type{
+			"woozles": cty.String,
+			"foozles": cty.String,
+		}))
 		if err != nil {
-			return false, fmt.Errorf("error unmarshalling first argument: %w", err)
+			t.Fatal(err)
 		}
-		err = json.Unmarshal(b, &pathsB)
-		if err != nil {
-			return false, fmt.Errorf("error unmarshalling second argument: %w", err)
+		return &plans.ResourceInstanceChangeSrc{
+			Addr:        mustAddr(addr),
+			PrevRunAddr: mustAddr(addr),
+			DeposedKey:  deposed,
+			ProviderAddr: addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			ChangeSrc: plans.ChangeSrc{
+				Action: plans.NoOp,
+				Before: val,
+				After:  val,
+			},
 		}
+	}
 
-		// Sort the slices based on the number of elements in each path
-		sort.Slice(pathsA, func(i, j int) bool {
-			return len(pathsA[i]) < len(pathsA[j])
-		})
-		sort.Slice(pathsB, func(i, j int) bool {
-			return len(pathsB[i]) < len(pathsB[j])
-		})
-
-		return cmp.Equal(pathsA, pathsB), nil
+	changes := []*plans.ResourceInstanceChangeSrc{
+		change("module.child.test_thing.example", states.NotDeposed),
+		change("test_thing.example[10]", states.NotDeposed),
+		change("test_thing.example[2]", "bbbbbbbb"),
+		change("test_thing.example[2]", states.NotDeposed),
+		change("test_thing.example[2]", "aaaaaaaa"),
+		change("test_thing.another", states.NotDeposed),
 	}
 
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			got, err := encodePaths(test.Input)
-			if err != nil {
-				t.Fatalf("unexpected error: %s", err)
-			}
+	got, err := MarshalResourceChanges(changes, testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
 
-			equal, err := comp(got, test.Want)
-			if err != nil {
-				t.Fatalf("error comparing JSON slices: %s", err)
-			}
-			if !equal {
-				t.Errorf("paths do not match:\n%s", cmp.Diff(got, test.Want))
-			}
-		})
+	var gotOrder []string
+	for _, rc := range got {
+		gotOrder = append(gotOrder, rc.Address+" "+rc.Deposed)
+	}
+	wantOrder := []string{
+		"test_thing.another ",
+		"test_thing.example[2] ",
+		"test_thing.example[2] aaaaaaaa",
+		"test_thing.example[2] bbbbbbbb",
+		"test_thing.example[10] ",
+		"module.child.test_thing.example ",
+	}
+	if diff := cmp.Diff(wantOrder, gotOrder); diff != "" {
+		t.Errorf("wrong order\n%s", diff)
 	}
 }
 
@@ -94,4 +94,8 @@ type ResourceChange struct {
 	// information should be resilient to encountering unrecognized values
 	// and treat them as an unspecified reason.
 	ActionReason string `json:"action_reason,omitempty"`
+
+	// Note is the note that the resource instance has in the prior state,
+	// if any, which is only for display purposes.
+	Note string `json:"note,omitempty"`
 }
@@ -171,10 +171,6 @@ func marshalPlannedValues(changes *plans.Changes, schemas *tofu.Schemas) (Module
 	if err != nil {
 		return ret, err
 	}
-	sort.Slice(childModules, func(i, j int) bool {
-		return childModules[i].Address < childModules[j].Address
-	})
-
 	ret.ChildModules = childModules
 
 	return ret, nil
@@ -298,5 +294,11 @@ func marshalPlanModules(
 		ret = append(ret, cm)
 	}
 
+	// The child modules are collected in the order of the planned changes,
+	// which isn't deterministic, so we sort them at every level of the tree.
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Address < ret[j].Address
+	})
+
 	return ret, nil
 }
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -347,6 +348,68 @@ func TestMarshalPlanValuesNoopDeposed(t *testing.T) {
 	}
 }
 
+func TestMarshalPlannedValues_childModuleOrder(t *testing.T) {
+	after, err := plans.NewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+		"woozles": cty.StringVal("foo"),
+		"foozles": cty.StringVal("bar"),
+	}), cty.Object(map[string]cty.Type{
+		"woozles": cty.String,
+		"foozles": cty.String,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changes plans.Changes
+	for _, addr := range []string{
+		"module.b.module.z.test_thing.example",
+		"module.b.module.y.test_thing.example",
+		"module.a.test_thing.example",
+		"module.b.module.x.module.w.test_thing.example",
+		"module.b.module.x.module.v.test_thing.example",
+	} {
+		changes.Resources = append(changes.Resources, &plans.ResourceInstanceChangeSrc{
+			Addr: mustAddr(addr),
+			ProviderAddr: addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			ChangeSrc: plans.ChangeSrc{
+				Action: plans.Create,
+				After:  after,
+			},
+		})
+	}
+
+	got, err := marshalPlannedValues(&changes, testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotOrder []string
+	var walk func([]Module)
+	walk = func(modules []Module) {
+		for _, m := range modules {
+			gotOrder = append(gotOrder, m.Address)
+			walk(m.ChildModules)
+		}
+	}
+	walk(got.ChildModules)
+
+	wantOrder := []string{
+		"module.a",
+		"module.b",
+		"module.b.module.x",
+		"module.b.module.x.module.v",
+		"module.b.module.x.module.w",
+		"module.b.module.y",
+		"module.b.module.z",
+	}
+	if diff := cmp.Diff(wantOrder, gotOrder); diff != "" {
+		t.Errorf("wrong child module order\n%s", diff)
+	}
+}
+
 func testSchemas() *tofu.Schemas {
 	return &tofu.Schemas{
 		Providers: map[addrs.Provider]providers.ProviderSchema{
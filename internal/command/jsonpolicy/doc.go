@@ -0,0 +1,21 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonpolicy implements the "policy input" JSON representation of a
+// plan, which is intended to be consumed by policy engines such as Open
+// Policy Agent.
+//
+// Unlike the general JSON plan format produced by package jsonplan, the
+// policy input format is a narrow, stable contract: every property described
+// in the embedded JSON Schema is always present (using null where there is
+// no value), addresses are always in their canonical string form, and all
+// collections are sorted so that two plans with the same content produce
+// byte-for-byte identical documents. Any change to this format that could
+// break an existing policy must increment FormatVersion.
+//
+// Policy input documents contain sensitive values, including sensitive input
+// variables and their defaults, in cleartext alongside markers describing
+// which values are sensitive. Callers must treat the documents as secret.
+package jsonpolicy
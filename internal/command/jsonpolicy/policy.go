@@ -0,0 +1,404 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpolicy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+	"github.com/opentofu/opentofu/version"
+)
+
+// FormatVersion represents the version of the policy input format and will be
+// incremented for any change to this format that requires changes to a
+// consuming policy.
+//
+// Additive changes increment the minor version, while any removal or change
+// of meaning of an existing property increments the major version.
+const FormatVersion = "1.0"
+
+// Schema is the JSON Schema document describing the format produced by
+// Marshal for the current FormatVersion.
+//
+//go:embed schema.json
+var Schema []byte
+
+// jsonNull is the representation used for any property that has no value,
+// so that consumers can rely on every documented property being present.
+var jsonNull = json.RawMessage("null")
+
+// Input is the top-level representation of the policy input format.
+type Input struct {
+	FormatVersion   string           `json:"format_version"`
+	OpenTofuVersion string           `json:"opentofu_version"`
+	Timestamp       string           `json:"timestamp"`
+	Plan            PlanMeta         `json:"plan"`
+	Variables       []Variable       `json:"variables"`
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+	OutputChanges   []OutputChange   `json:"output_changes"`
+	Providers       []Provider       `json:"providers"`
+}
+
+// PlanMeta describes the plan as a whole.
+type PlanMeta struct {
+	// Mode is one of "normal", "destroy", "refresh-only" or "drift-only".
+	Mode      string `json:"mode"`
+	Applyable bool   `json:"applyable"`
+	Errored   bool   `json:"errored"`
+}
+
+// Variable is a root module input variable value used to create the plan.
+//
+// Value is always the cleartext value, even for variables declared as
+// sensitive, so that policies can make decisions based on it. Sensitive only
+// reports how the variable was declared.
+type Variable struct {
+	Name      string          `json:"name"`
+	Value     json.RawMessage `json:"value"`
+	Sensitive bool            `json:"sensitive"`
+}
+
+// Provider describes a provider source address, split into its parts so
+// that policies don't need to parse it.
+type Provider struct {
+	Source    string `json:"source"`
+	Hostname  string `json:"hostname"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+}
+
+// ProviderConfig describes the provider configuration responsible for a
+// resource instance change.
+type ProviderConfig struct {
+	Provider
+
+	// ConfigAddress is the absolute address of the provider configuration,
+	// such as module.foo.provider["registry.opentofu.org/hashicorp/aws"].east
+	ConfigAddress string `json:"config_address"`
+	Alias         string `json:"alias"`
+}
+
+// ResourceChange is the representation of a planned change to a single
+// resource instance object.
+type ResourceChange struct {
+	Address         string          `json:"address"`
+	PreviousAddress string          `json:"previous_address"`
+	ModuleAddress   string          `json:"module_address"`
+	Mode            string          `json:"mode"`
+	Type            string          `json:"type"`
+	Name            string          `json:"name"`
+	Index           json.RawMessage `json:"index"`
+	Deposed         json.RawMessage `json:"deposed"`
+	Provider        ProviderConfig  `json:"provider"`
+
+	// Actions uses the same vocabulary as the "actions" property of the JSON
+	// plan format, such as ["create"] or ["delete", "create"].
+	Actions      []string        `json:"actions"`
+	ActionReason json.RawMessage `json:"action_reason"`
+	ReplacePaths json.RawMessage `json:"replace_paths"`
+	ImportingID  json.RawMessage `json:"importing_id"`
+
+	Before          json.RawMessage `json:"before"`
+	After           json.RawMessage `json:"after"`
+	AfterUnknown    json.RawMessage `json:"after_unknown"`
+	BeforeSensitive json.RawMessage `json:"before_sensitive"`
+	AfterSensitive  json.RawMessage `json:"after_sensitive"`
+}
+
+// OutputChange is the representation of a planned change to a root module
+// output value.
+type OutputChange struct {
+	Name         string          `json:"name"`
+	Actions      []string        `json:"actions"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	AfterUnknown json.RawMessage `json:"after_unknown"`
+	Sensitive    bool            `json:"sensitive"`
+}
+
+// Marshal returns the policy input JSON encoding of the given plan.
+func Marshal(config *configs.Config, p *plans.Plan, schemas *tofu.Schemas) ([]byte, error) {
+	input, err := MarshalForPolicy(config, p, schemas)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(input)
+}
+
+// MarshalForPolicy returns the policy input representation of the given
+// plan, ready to be encoded as JSON.
+func MarshalForPolicy(config *configs.Config, p *plans.Plan, schemas *tofu.Schemas) (*Input, error) {
+	input := &Input{
+		FormatVersion:   FormatVersion,
+		OpenTofuVersion: version.String(),
+		Timestamp:       p.Timestamp.UTC().Format(time.RFC3339),
+		Plan: PlanMeta{
+			Mode:      planModeString(p.UIMode),
+			Applyable: p.CanApply(),
+			Errored:   p.Errored,
+		},
+		Variables:       []Variable{},
+		ResourceChanges: []ResourceChange{},
+		OutputChanges:   []OutputChange{},
+		Providers:       []Provider{},
+	}
+
+	var err error
+	if input.Variables, err = marshalVariables(p.VariableValues, config); err != nil {
+		return nil, fmt.Errorf("error marshaling variables: %w", err)
+	}
+
+	if p.Changes != nil {
+		if input.ResourceChanges, err = marshalResourceChanges(p.Changes.Resources, schemas); err != nil {
+			return nil, fmt.Errorf("error marshaling resource changes: %w", err)
+		}
+		if input.OutputChanges, err = marshalOutputChanges(p.Changes); err != nil {
+			return nil, fmt.Errorf("error marshaling output changes: %w", err)
+		}
+	}
+
+	input.Providers = providersFromChanges(input.ResourceChanges)
+
+	return input, nil
+}
+
+func planModeString(mode plans.Mode) string {
+	switch mode {
+	case plans.DestroyMode:
+		return "destroy"
+	case plans.RefreshOnlyMode:
+		return "refresh-only"
+	case plans.DriftOnlyMode:
+		return "drift-only"
+	default:
+		return "normal"
+	}
+}
+
+func marshalVariables(vals map[string]plans.DynamicValue, config *configs.Config) ([]Variable, error) {
+	var decls map[string]*configs.Variable
+	if config != nil && config.Module != nil {
+		decls = config.Module.Variables
+	}
+
+	ret := make([]Variable, 0, len(vals))
+	for name, dv := range vals {
+		val, err := dv.Decode(cty.DynamicPseudoType)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		valJSON, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		v := Variable{
+			Name:  name,
+			Value: valJSON,
+		}
+		if decl, ok := decls[name]; ok {
+			v.Sensitive = decl.Sensitive
+		}
+		ret = append(ret, v)
+	}
+
+	// As with the JSON plan format, variables that were not set by the caller
+	// are reported with their default values so that policies see the
+	// effective value of every declared variable.
+	for name, decl := range decls {
+		if _, ok := vals[name]; ok {
+			continue
+		}
+		if decl.Default == cty.NilVal {
+			continue
+		}
+		valJSON, err := ctyjson.Marshal(decl.Default, decl.Default.Type())
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		ret = append(ret, Variable{
+			Name:      name,
+			Value:     valJSON,
+			Sensitive: decl.Sensitive,
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+func marshalResourceChanges(changes []*plans.ResourceInstanceChangeSrc, schemas *tofu.Schemas) ([]ResourceChange, error) {
+	// The JSON plan format already knows how to decode changes against the
+	// provider schemas and to summarize unknown and sensitive values, so we
+	// reuse it and then normalize its result into our stricter contract.
+	jcs, err := jsonplan.MarshalResourceChanges(changes, schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	type changeKey struct {
+		addr    string
+		deposed string
+	}
+	providerAddrs := make(map[changeKey]addrs.AbsProviderConfig, len(changes))
+	for _, rc := range changes {
+		key := changeKey{addr: rc.Addr.String()}
+		if rc.DeposedKey != states.NotDeposed {
+			key.deposed = rc.DeposedKey.String()
+		}
+		providerAddrs[key] = rc.ProviderAddr
+	}
+
+	ret := make([]ResourceChange, 0, len(jcs))
+	for _, jc := range jcs {
+		providerAddr, ok := providerAddrs[changeKey{addr: jc.Address, deposed: jc.Deposed}]
+		if !ok {
+			return nil, fmt.Errorf("no provider configuration recorded for %s", jc.Address)
+		}
+
+		rc := ResourceChange{
+			Address:         jc.Address,
+			PreviousAddress: jc.Address,
+			ModuleAddress:   jc.ModuleAddress,
+			Mode:            jc.Mode,
+			Type:            jc.Type,
+			Name:            jc.Name,
+			Index:           rawOrNull(jc.Index),
+			Deposed:         jsonNull,
+			Provider: ProviderConfig{
+				Provider:      marshalProvider(providerAddr.Provider),
+				ConfigAddress: providerAddr.String(),
+				Alias:         providerAddr.Alias,
+			},
+			Actions:         jc.Change.Actions,
+			ActionReason:    jsonNull,
+			ReplacePaths:    rawOrNull(jc.Change.ReplacePaths),
+			ImportingID:     jsonNull,
+			Before:          rawOrNull(jc.Change.Before),
+			After:           rawOrNull(jc.Change.After),
+			AfterUnknown:    rawOrNull(jc.Change.AfterUnknown),
+			BeforeSensitive: rawOrNull(jc.Change.BeforeSensitive),
+			AfterSensitive:  rawOrNull(jc.Change.AfterSensitive),
+		}
+		if jc.PreviousAddress != "" {
+			rc.PreviousAddress = jc.PreviousAddress
+		}
+		if jc.Deposed != "" {
+			if rc.Deposed, err = json.Marshal(jc.Deposed); err != nil {
+				return nil, err
+			}
+		}
+		if jc.ActionReason != "" {
+			if rc.ActionReason, err = json.Marshal(jc.ActionReason); err != nil {
+				return nil, err
+			}
+		}
+		if jc.Change.Importing != nil {
+			if rc.ImportingID, err = json.Marshal(jc.Change.Importing.ID); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, rc)
+	}
+
+	// jsonplan.MarshalResourceChanges already sorts by address and deposed
+	// key, but that ordering is part of our contract and so we don't rely
+	// on it staying that way.
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Address != ret[j].Address {
+			return ret[i].Address < ret[j].Address
+		}
+		// The current object (with a null deposed key) sorts before any
+		// deposed objects of the same instance.
+		return deposedSortKey(ret[i].Deposed) < deposedSortKey(ret[j].Deposed)
+	})
+
+	return ret, nil
+}
+
+func marshalOutputChanges(changes *plans.Changes) ([]OutputChange, error) {
+	jcs, err := jsonplan.MarshalOutputChanges(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]OutputChange, 0, len(jcs))
+	for name, jc := range jcs {
+		var sensitive bool
+		if len(jc.AfterSensitive) > 0 {
+			if err := json.Unmarshal(jc.AfterSensitive, &sensitive); err != nil {
+				return nil, fmt.Errorf("output %q: %w", name, err)
+			}
+		}
+		ret = append(ret, OutputChange{
+			Name:         name,
+			Actions:      jc.Actions,
+			Before:       rawOrNull(jc.Before),
+			After:        rawOrNull(jc.After),
+			AfterUnknown: rawOrNull(jc.AfterUnknown),
+			Sensitive:    sensitive,
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+func marshalProvider(addr addrs.Provider) Provider {
+	return Provider{
+		Source:    addr.String(),
+		Hostname:  addr.Hostname.ForDisplay(),
+		Namespace: addr.Namespace,
+		Type:      addr.Type,
+	}
+}
+
+// providersFromChanges returns the distinct set of providers responsible for
+// the given changes, sorted by source address.
+func providersFromChanges(changes []ResourceChange) []Provider {
+	seen := make(map[string]struct{})
+	ret := make([]Provider, 0)
+	for _, rc := range changes {
+		if _, ok := seen[rc.Provider.Source]; ok {
+			continue
+		}
+		seen[rc.Provider.Source] = struct{}{}
+		ret = append(ret, rc.Provider.Provider)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Source < ret[j].Source
+	})
+	return ret
+}
+
+func deposedSortKey(raw json.RawMessage) string {
+	if string(raw) == string(jsonNull) {
+		return ""
+	}
+	return string(raw)
+}
+
+func rawOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return jsonNull
+	}
+	return raw
+}
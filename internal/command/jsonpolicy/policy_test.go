@@ -0,0 +1,479 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestMarshal_matchesSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("embedded schema is not valid JSON: %s", err)
+	}
+
+	tests := map[string]*plans.Plan{
+		"empty":        {Changes: plans.NewChanges()},
+		"drift-only":   {UIMode: plans.DriftOnlyMode, Changes: plans.NewChanges()},
+		"with changes": testPlan(t),
+	}
+	for name, plan := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Marshal(testConfig(), plan, testSchemas())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc interface{}
+			if err := json.Unmarshal(got, &doc); err != nil {
+				t.Fatal(err)
+			}
+			for _, problem := range validateSchema(schema, doc, "$") {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestMarshal_schemaVersion(t *testing.T) {
+	var schema struct {
+		Properties struct {
+			FormatVersion struct {
+				Const string `json:"const"`
+			} `json:"format_version"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schema.Properties.FormatVersion.Const, FormatVersion; got != want {
+		t.Fatalf("schema describes format version %q, but FormatVersion is %q; update both together", got, want)
+	}
+}
+
+func TestMarshalForPolicy(t *testing.T) {
+	got, err := MarshalForPolicy(testConfig(), testPlan(t), testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Plan.Mode != "normal" || !got.Plan.Applyable || got.Plan.Errored {
+		t.Errorf("wrong plan metadata: %#v", got.Plan)
+	}
+	if got.Timestamp != "2024-01-02T03:04:05Z" {
+		t.Errorf("wrong timestamp %q", got.Timestamp)
+	}
+
+	wantVars := []Variable{
+		{Name: "password", Value: json.RawMessage(`"hunter2"`), Sensitive: true},
+		{Name: "region", Value: json.RawMessage(`"eu-west-1"`)},
+	}
+	if diff := cmp.Diff(wantVars, got.Variables); diff != "" {
+		t.Errorf("wrong variables\n%s", diff)
+	}
+
+	var gotAddrs []string
+	for _, rc := range got.ResourceChanges {
+		gotAddrs = append(gotAddrs, fmt.Sprintf("%s %s", rc.Address, rc.Deposed))
+	}
+	wantAddrs := []string{
+		`module.child.test_thing.b null`,
+		`test_thing.a[0] null`,
+		`test_thing.a[0] "00000001"`,
+	}
+	if diff := cmp.Diff(wantAddrs, gotAddrs); diff != "" {
+		t.Errorf("wrong resource change order\n%s", diff)
+	}
+
+	create := got.ResourceChanges[1]
+	if diff := cmp.Diff([]string{"create"}, create.Actions); diff != "" {
+		t.Errorf("wrong actions\n%s", diff)
+	}
+	if got, want := string(create.Before), "null"; got != want {
+		t.Errorf("wrong before %s; want %s", got, want)
+	}
+	if got, want := string(create.After), `{"secret":"shh"}`; got != want {
+		t.Errorf("wrong after %s; want %s", got, want)
+	}
+	if got, want := string(create.AfterUnknown), `{"id":true}`; got != want {
+		t.Errorf("wrong after_unknown %s; want %s", got, want)
+	}
+	if got, want := string(create.AfterSensitive), `{"secret":true}`; got != want {
+		t.Errorf("wrong after_sensitive %s; want %s", got, want)
+	}
+	if got, want := create.PreviousAddress, create.Address; got != want {
+		t.Errorf("wrong previous address %q; want %q", got, want)
+	}
+
+	wantProvider := ProviderConfig{
+		Provider: Provider{
+			Source:    "registry.opentofu.org/hashicorp/test",
+			Hostname:  "registry.opentofu.org",
+			Namespace: "hashicorp",
+			Type:      "test",
+		},
+		ConfigAddress: `module.child.provider["registry.opentofu.org/hashicorp/test"].east`,
+		Alias:         "east",
+	}
+	if diff := cmp.Diff(wantProvider, got.ResourceChanges[0].Provider); diff != "" {
+		t.Errorf("wrong provider\n%s", diff)
+	}
+	if got, want := got.ResourceChanges[0].PreviousAddress, "test_thing.b"; got != want {
+		t.Errorf("wrong previous address %q; want %q", got, want)
+	}
+
+	if len(got.Providers) != 1 || got.Providers[0] != wantProvider.Provider {
+		t.Errorf("wrong providers: %#v", got.Providers)
+	}
+
+	wantOutputs := []OutputChange{
+		{
+			Name:         "token",
+			Actions:      []string{"create"},
+			Before:       json.RawMessage("null"),
+			After:        json.RawMessage(`"abc"`),
+			AfterUnknown: json.RawMessage("false"),
+			Sensitive:    true,
+		},
+	}
+	if diff := cmp.Diff(wantOutputs, got.OutputChanges); diff != "" {
+		t.Errorf("wrong output changes\n%s", diff)
+	}
+}
+
+func TestMarshalForPolicy_sensitiveVariables(t *testing.T) {
+	// Policy input deliberately includes sensitive values in cleartext so
+	// that policies can inspect them, marking them as sensitive instead of
+	// redacting them as the human-oriented renderers do.
+	config := &configs.Config{
+		Module: &configs.Module{
+			Variables: map[string]*configs.Variable{
+				"api_key":     {Name: "api_key", Sensitive: true},
+				"db_password": {Name: "db_password", Sensitive: true, Default: cty.StringVal("changeme")},
+			},
+		},
+	}
+	apiKey, err := plans.NewDynamicValue(cty.StringVal("s3cr3t"), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &plans.Plan{
+		Changes: plans.NewChanges(),
+		VariableValues: map[string]plans.DynamicValue{
+			"api_key": apiKey,
+		},
+	}
+
+	got, err := MarshalForPolicy(config, plan, testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Variable{
+		{Name: "api_key", Value: json.RawMessage(`"s3cr3t"`), Sensitive: true},
+		{Name: "db_password", Value: json.RawMessage(`"changeme"`), Sensitive: true},
+	}
+	if diff := cmp.Diff(want, got.Variables); diff != "" {
+		t.Errorf("wrong variables\n%s", diff)
+	}
+}
+
+func TestMarshalForPolicy_planMode(t *testing.T) {
+	tests := map[plans.Mode]string{
+		plans.NormalMode:      "normal",
+		plans.DestroyMode:     "destroy",
+		plans.RefreshOnlyMode: "refresh-only",
+		plans.DriftOnlyMode:   "drift-only",
+	}
+	for mode, want := range tests {
+		t.Run(want, func(t *testing.T) {
+			plan := &plans.Plan{UIMode: mode, Changes: plans.NewChanges()}
+			got, err := MarshalForPolicy(testConfig(), plan, testSchemas())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Plan.Mode != want {
+				t.Errorf("wrong mode %q; want %q", got.Plan.Mode, want)
+			}
+		})
+	}
+}
+
+func TestMarshal_deterministic(t *testing.T) {
+	plan := testPlan(t)
+	first, err := Marshal(testConfig(), plan, testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reversing the input order must not change the result.
+	res := plan.Changes.Resources
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	second, err := Marshal(testConfig(), plan, testSchemas())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Fatalf("output is not deterministic\nfirst:  %s\nsecond: %s", first, second)
+	}
+}
+
+// validateSchema checks doc against the subset of JSON Schema used by our
+// embedded schema document, returning a description of each problem found.
+func validateSchema(schema map[string]interface{}, doc interface{}, path string) []string {
+	var problems []string
+
+	if types, ok := schema["type"]; ok {
+		var allowed []string
+		switch types := types.(type) {
+		case string:
+			allowed = []string{types}
+		case []interface{}:
+			for _, ty := range types {
+				allowed = append(allowed, ty.(string))
+			}
+		}
+		matched := false
+		for _, ty := range allowed {
+			if jsonTypeMatches(ty, doc) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return append(problems, fmt.Sprintf("%s: value %#v does not have type %v", path, doc, allowed))
+		}
+	}
+
+	if c, ok := schema["const"]; ok && c != doc {
+		problems = append(problems, fmt.Sprintf("%s: got %#v, want %#v", path, doc, c))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, v := range enum {
+			if v == doc {
+				found = true
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: %#v is not one of %v", path, doc, enum))
+		}
+	}
+
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := doc[name.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: missing required property %q", path, name))
+				}
+			}
+		}
+		var names []string
+		for name := range doc {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, ok := props[name].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = append(problems, fmt.Sprintf("%s: unexpected property %q", path, name))
+				}
+				continue
+			}
+			problems = append(problems, validateSchema(propSchema, doc[name], path+"."+name)...)
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, elem := range doc {
+				problems = append(problems, validateSchema(items, elem, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+func jsonTypeMatches(ty string, v interface{}) bool {
+	switch ty {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	default:
+		panic("unsupported schema type " + ty)
+	}
+}
+
+func testConfig() *configs.Config {
+	return &configs.Config{
+		Module: &configs.Module{
+			Variables: map[string]*configs.Variable{
+				"password": {Name: "password", Sensitive: true},
+				"region":   {Name: "region", Default: cty.StringVal("eu-west-1")},
+			},
+		},
+	}
+}
+
+func testSchemas() *tofu.Schemas {
+	return &tofu.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			addrs.NewDefaultProvider("test"): {
+				ResourceTypes: map[string]providers.Schema{
+					"test_thing": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"id":     {Type: cty.String, Computed: true},
+								"secret": {Type: cty.String, Optional: true, Sensitive: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testPlan(t *testing.T) *plans.Plan {
+	t.Helper()
+
+	ty := testSchemas().Providers[addrs.NewDefaultProvider("test")].ResourceTypes["test_thing"].Block.ImpliedType()
+	dv := func(v cty.Value) plans.DynamicValue {
+		ret, err := plans.NewDynamicValue(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"id":     cty.StringVal("i-123"),
+		"secret": cty.NullVal(cty.String),
+	})
+	null := cty.NullVal(ty)
+
+	instA := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_thing", Name: "a"}.
+		Instance(addrs.IntKey(0)).Absolute(addrs.RootModuleInstance)
+	instB := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_thing", Name: "b"}.
+		Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance.Child("child", addrs.NoKey))
+	prevB := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_thing", Name: "b"}.
+		Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	rootProvider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+
+	password, err := plans.NewDynamicValue(cty.StringVal("hunter2"), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := plans.NewDynamicValue(cty.StringVal("abc"), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullOutput, err := plans.NewDynamicValue(cty.NullVal(cty.DynamicPseudoType), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &plans.Plan{
+		UIMode:    plans.NormalMode,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		VariableValues: map[string]plans.DynamicValue{
+			"password": password,
+		},
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{
+				{
+					Addr:         instA,
+					PrevRunAddr:  instA,
+					DeposedKey:   states.DeposedKey("00000001"),
+					ProviderAddr: rootProvider,
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Delete,
+						Before: dv(obj),
+						After:  dv(null),
+					},
+				},
+				{
+					Addr:         instA,
+					PrevRunAddr:  instA,
+					ProviderAddr: rootProvider,
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Create,
+						Before: dv(null),
+						After: dv(cty.ObjectVal(map[string]cty.Value{
+							"id":     cty.UnknownVal(cty.String),
+							"secret": cty.StringVal("shh"),
+						})),
+						AfterValMarks: []cty.PathValueMarks{
+							{
+								Path:  cty.GetAttrPath("secret"),
+								Marks: cty.NewValueMarks(marks.Sensitive),
+							},
+						},
+					},
+				},
+				{
+					Addr:        instB,
+					PrevRunAddr: prevB,
+					ProviderAddr: addrs.AbsProviderConfig{
+						Module:   addrs.RootModule.Child("child"),
+						Provider: addrs.NewDefaultProvider("test"),
+						Alias:    "east",
+					},
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.NoOp,
+						Before: dv(obj),
+						After:  dv(obj),
+					},
+				},
+			},
+			Outputs: []*plans.OutputChangeSrc{
+				{
+					Addr:      addrs.OutputValue{Name: "token"}.Absolute(addrs.RootModuleInstance),
+					Sensitive: true,
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Create,
+						Before: nullOutput,
+						After:  token,
+					},
+				},
+			},
+		},
+	}
+}
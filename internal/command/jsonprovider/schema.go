@@ -10,8 +10,17 @@ import (
 )
 
 type Schema struct {
-	Version uint64 `json:"version"`
-	Block   *Block `json:"block,omitempty"`
+	Version         uint64           `json:"version"`
+	Block           *Block           `json:"block,omitempty"`
+	PermissionHints *PermissionHints `json:"permission_hints,omitempty"`
+}
+
+// PermissionHints is the JSON representation of providers.PermissionHints.
+type PermissionHints struct {
+	Create []string `json:"create,omitempty"`
+	Read   []string `json:"read,omitempty"`
+	Update []string `json:"update,omitempty"`
+	Delete []string `json:"delete,omitempty"`
 }
 
 // marshalSchema is a convenience wrapper around marshalBlock. Schema version
@@ -25,6 +34,15 @@ func marshalSchema(schema providers.Schema) *Schema {
 	ret.Block = marshalBlock(schema.Block)
 	ret.Version = uint64(schema.Version)
 
+	if hints := schema.PermissionHints; hints != nil {
+		ret.PermissionHints = &PermissionHints{
+			Create: hints.Create,
+			Read:   hints.Read,
+			Update: hints.Update,
+			Delete: hints.Delete,
+		}
+	}
+
 	return &ret
 }
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/providers"
 )
 
@@ -41,6 +42,24 @@ func TestMarshalSchema(t *testing.T) {
 			providers.Schema{},
 			&Schema{},
 		},
+		"permission_hints": {
+			providers.Schema{
+				Version: 1,
+				Block:   &configschema.Block{},
+				PermissionHints: &providers.PermissionHints{
+					Create: []string{"compute.instances.create"},
+					Delete: []string{"compute.instances.delete"},
+				},
+			},
+			&Schema{
+				Version: 1,
+				Block:   &Block{DescriptionKind: "plain"},
+				PermissionHints: &PermissionHints{
+					Create: []string{"compute.instances.create"},
+					Delete: []string{"compute.instances.delete"},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
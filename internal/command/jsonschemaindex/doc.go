@@ -0,0 +1,14 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonschemaindex contains types and functions to marshal the schemas
+// of the providers selected for a working directory into a compact index
+// intended for editor integrations, such as language servers offering
+// autocompletion and hover documentation.
+//
+// Unlike the format produced by package jsonprovider, the index records the
+// version of each provider, represents types using OpenTofu's type
+// constraint syntax and omits everything that editors don't need.
+package jsonschemaindex
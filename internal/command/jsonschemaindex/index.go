@@ -0,0 +1,224 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonschemaindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+// FormatVersion represents the version of the json format and will be
+// incremented for any change to this format that requires changes to a
+// consuming parser.
+const FormatVersion = "1.0"
+
+// Index is the top-level object of the schema index.
+type Index struct {
+	FormatVersion string               `json:"format_version"`
+	Providers     map[string]*Provider `json:"providers"`
+}
+
+// Provider describes the schema of a single provider, keyed in the index by
+// its fully-qualified source address.
+type Provider struct {
+	// Version is the selected version of the provider, which is empty for
+	// built-in providers.
+	Version     string               `json:"version,omitempty"`
+	Config      *Block               `json:"config,omitempty"`
+	Resources   map[string]*Block    `json:"resources,omitempty"`
+	DataSources map[string]*Block    `json:"data_sources,omitempty"`
+	Functions   map[string]*Function `json:"functions,omitempty"`
+}
+
+// Block describes the arguments and nested blocks of a provider
+// configuration, resource type, data source, or nested block.
+type Block struct {
+	Doc        string                  `json:"doc,omitempty"`
+	Markdown   bool                    `json:"markdown,omitempty"`
+	Deprecated bool                    `json:"deprecated,omitempty"`
+	Attributes map[string]*Attribute   `json:"attributes,omitempty"`
+	Blocks     map[string]*NestedBlock `json:"blocks,omitempty"`
+}
+
+// NestedBlock describes a block type that can appear inside a Block.
+type NestedBlock struct {
+	Nesting  string `json:"nesting"`
+	MinItems int    `json:"min_items,omitempty"`
+	MaxItems int    `json:"max_items,omitempty"`
+	*Block
+}
+
+// Attribute describes a single argument or exported attribute.
+type Attribute struct {
+	// Type is the type of the attribute in type constraint syntax, such as
+	// "list(string)".
+	Type       string `json:"type"`
+	Doc        string `json:"doc,omitempty"`
+	Markdown   bool   `json:"markdown,omitempty"`
+	Required   bool   `json:"required,omitempty"`
+	Optional   bool   `json:"optional,omitempty"`
+	Computed   bool   `json:"computed,omitempty"`
+	Sensitive  bool   `json:"sensitive,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+
+	// Nesting and Attributes are set only for attributes whose type is
+	// described by nested attributes rather than by a type constraint.
+	Nesting    string                `json:"nesting,omitempty"`
+	Attributes map[string]*Attribute `json:"attributes,omitempty"`
+}
+
+// Function describes a provider-defined function.
+type Function struct {
+	// Signature is the function's signature as it would be called in the
+	// configuration, such as "provider::example::upper(str string) string".
+	Signature  string `json:"signature"`
+	Doc        string `json:"doc,omitempty"`
+	Markdown   bool   `json:"markdown,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// Marshal returns the index of the given provider schemas as JSON. Versions
+// gives the selected version of each provider, and may omit providers whose
+// version isn't known.
+func Marshal(schemas map[addrs.Provider]providers.ProviderSchema, versions map[addrs.Provider]getproviders.Version) ([]byte, error) {
+	index := &Index{
+		FormatVersion: FormatVersion,
+		Providers:     make(map[string]*Provider, len(schemas)),
+	}
+	for addr, schema := range schemas {
+		p := marshalProvider(addr, schema)
+		if v, ok := versions[addr]; ok {
+			p.Version = v.String()
+		}
+		index.Providers[addr.String()] = p
+	}
+	return json.Marshal(index)
+}
+
+func marshalProvider(addr addrs.Provider, schema providers.ProviderSchema) *Provider {
+	ret := &Provider{
+		Config: marshalBlock(schema.Provider.Block),
+	}
+	if len(schema.ResourceTypes) > 0 {
+		ret.Resources = make(map[string]*Block, len(schema.ResourceTypes))
+		for name, s := range schema.ResourceTypes {
+			ret.Resources[name] = marshalBlock(s.Block)
+		}
+	}
+	if len(schema.DataSources) > 0 {
+		ret.DataSources = make(map[string]*Block, len(schema.DataSources))
+		for name, s := range schema.DataSources {
+			ret.DataSources[name] = marshalBlock(s.Block)
+		}
+	}
+	if len(schema.Functions) > 0 {
+		ret.Functions = make(map[string]*Function, len(schema.Functions))
+		for name, f := range schema.Functions {
+			ret.Functions[name] = marshalFunction(addr, name, f)
+		}
+	}
+	return ret
+}
+
+func marshalBlock(block *configschema.Block) *Block {
+	if block == nil {
+		return &Block{}
+	}
+
+	ret := &Block{
+		Doc:        block.Description,
+		Markdown:   block.DescriptionKind == configschema.StringMarkdown,
+		Deprecated: block.Deprecated,
+	}
+	if len(block.Attributes) > 0 {
+		ret.Attributes = make(map[string]*Attribute, len(block.Attributes))
+		for name, attr := range block.Attributes {
+			ret.Attributes[name] = marshalAttribute(attr)
+		}
+	}
+	if len(block.BlockTypes) > 0 {
+		ret.Blocks = make(map[string]*NestedBlock, len(block.BlockTypes))
+		for name, nested := range block.BlockTypes {
+			ret.Blocks[name] = &NestedBlock{
+				Nesting:  nestingModeString(nested.Nesting),
+				MinItems: nested.MinItems,
+				MaxItems: nested.MaxItems,
+				Block:    marshalBlock(&nested.Block),
+			}
+		}
+	}
+	return ret
+}
+
+func marshalAttribute(attr *configschema.Attribute) *Attribute {
+	ret := &Attribute{
+		Type:       typeexpr.TypeString(attr.ImpliedType()),
+		Doc:        attr.Description,
+		Markdown:   attr.DescriptionKind == configschema.StringMarkdown,
+		Required:   attr.Required,
+		Optional:   attr.Optional,
+		Computed:   attr.Computed,
+		Sensitive:  attr.Sensitive,
+		Deprecated: attr.Deprecated,
+	}
+	if attr.NestedType != nil {
+		ret.Nesting = nestingModeString(attr.NestedType.Nesting)
+		ret.Attributes = make(map[string]*Attribute, len(attr.NestedType.Attributes))
+		for name, nested := range attr.NestedType.Attributes {
+			ret.Attributes[name] = marshalAttribute(nested)
+		}
+	}
+	return ret
+}
+
+func marshalFunction(addr addrs.Provider, name string, f providers.FunctionSpec) *Function {
+	var params []string
+	for _, p := range f.Parameters {
+		params = append(params, fmt.Sprintf("%s %s", p.Name, typeexpr.TypeString(p.Type)))
+	}
+	if p := f.VariadicParameter; p != nil {
+		params = append(params, fmt.Sprintf("%s ...%s", p.Name, typeexpr.TypeString(p.Type)))
+	}
+
+	doc := f.Description
+	if doc == "" {
+		doc = f.Summary
+	}
+	return &Function{
+		Signature: fmt.Sprintf(
+			"provider::%s::%s(%s) %s",
+			addr.Type, name, strings.Join(params, ", "), typeexpr.TypeString(f.Return),
+		),
+		Doc:        doc,
+		Markdown:   f.DescriptionFormat == providers.TextFormattingMarkdown,
+		Deprecated: f.DeprecationMessage,
+	}
+}
+
+func nestingModeString(mode configschema.NestingMode) string {
+	switch mode {
+	case configschema.NestingSingle:
+		return "single"
+	case configschema.NestingGroup:
+		return "group"
+	case configschema.NestingList:
+		return "list"
+	case configschema.NestingSet:
+		return "set"
+	case configschema.NestingMap:
+		return "map"
+	default:
+		return "invalid"
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonschemaindex
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+func TestMarshal(t *testing.T) {
+	addr := addrs.NewDefaultProvider("test")
+	schemas := map[addrs.Provider]providers.ProviderSchema{
+		addr: {
+			Provider: providers.Schema{
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"token": {Type: cty.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+			ResourceTypes: map[string]providers.Schema{
+				"test_instance": {
+					Block: &configschema.Block{
+						Description:     "A **test** instance.",
+						DescriptionKind: configschema.StringMarkdown,
+						Attributes: map[string]*configschema.Attribute{
+							"id":   {Type: cty.String, Computed: true},
+							"tags": {Type: cty.Map(cty.String), Optional: true, Description: "Tags for the instance."},
+						},
+						BlockTypes: map[string]*configschema.NestedBlock{
+							"disk": {
+								Nesting:  configschema.NestingList,
+								MaxItems: 2,
+								Block: configschema.Block{
+									Attributes: map[string]*configschema.Attribute{
+										"size": {Type: cty.Number, Required: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	versions := map[addrs.Provider]getproviders.Version{
+		addr: getproviders.MustParseVersion("1.2.3"),
+	}
+
+	got, err := Marshal(schemas, versions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"format_version":"1.0","providers":{"registry.opentofu.org/hashicorp/test":{"version":"1.2.3","config":{"attributes":{"token":{"type":"string","optional":true,"sensitive":true}}},"resources":{"test_instance":{"doc":"A **test** instance.","markdown":true,"attributes":{"id":{"type":"string","computed":true},"tags":{"type":"map(string)","doc":"Tags for the instance.","optional":true}},"blocks":{"disk":{"nesting":"list","max_items":2,"attributes":{"size":{"type":"number","required":true}}}}}}}}}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
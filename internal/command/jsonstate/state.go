@@ -261,6 +261,20 @@ func marshalRootModule(s *states.State, schemas *tofu.Schemas) (Module, error) {
 	}
 	ret.Resources = rs
 
+	moduleMap, err := moduleTree(s)
+	if err != nil {
+		return ret, err
+	}
+
+	// use the state and module map to build up the module structure
+	ret.ChildModules, err = marshalModules(s, schemas, moduleMap[""], moduleMap)
+	return ret, err
+}
+
+// moduleTree returns a map from the address of each module instance in the
+// given state to the addresses of its child module instances, including any
+// that only contain other modules.
+func moduleTree(s *states.State) (map[string][]addrs.ModuleInstance, error) {
 	// build a map of module -> set[child module addresses]
 	moduleChildSet := make(map[string]map[string]struct{})
 	for _, mod := range s.Modules {
@@ -282,15 +296,12 @@ func marshalRootModule(s *states.State, schemas *tofu.Schemas) (Module, error) {
 		for child := range children {
 			childModuleInstance, diags := addrs.ParseModuleInstanceStr(child)
 			if diags.HasErrors() {
-				return ret, diags.Err()
+				return nil, diags.Err()
 			}
 			moduleMap[parent] = append(moduleMap[parent], childModuleInstance)
 		}
 	}
-
-	// use the state and module map to build up the module structure
-	ret.ChildModules, err = marshalModules(s, schemas, moduleMap[""], moduleMap)
-	return ret, err
+	return moduleMap, nil
 }
 
 // marshalModules is an ungainly recursive function to build a module structure
@@ -337,7 +348,21 @@ func marshalModules(
 
 func marshalResources(resources map[string]*states.Resource, module addrs.ModuleInstance, schemas *tofu.Schemas) ([]Resource, error) {
 	var ret []Resource
+	err := eachResource(resources, schemas, func(r Resource) error {
+		ret = append(ret, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
+// eachResource calls fn with the representation of each resource instance
+// object of the given resources in turn, in the order that marshalResources
+// returns them, so that callers don't need to hold all of them in memory at
+// once.
+func eachResource(resources map[string]*states.Resource, schemas *tofu.Schemas, fn func(Resource) error) error {
 	var sortedResources []*states.Resource
 	for _, r := range resources {
 		sortedResources = append(sortedResources, r)
@@ -373,7 +398,7 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 			if k != nil {
 				index := k.Value()
 				if current.Index, err = ctyjson.Marshal(index, index.Type()); err != nil {
-					return nil, err
+					return err
 				}
 			}
 
@@ -383,7 +408,7 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 			case addrs.DataResourceMode:
 				current.Mode = DataResourceMode
 			default:
-				return ret, fmt.Errorf("resource %s has an unsupported mode %s",
+				return fmt.Errorf("resource %s has an unsupported mode %s",
 					resAddr.String(),
 					resAddr.Mode.String(),
 				)
@@ -398,17 +423,17 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 			// It is possible that the only instance is deposed
 			if ri.Current != nil {
 				if version != ri.Current.SchemaVersion {
-					return nil, fmt.Errorf("schema version %d for %s in state does not match version %d from the provider", ri.Current.SchemaVersion, resAddr, version)
+					return fmt.Errorf("schema version %d for %s in state does not match version %d from the provider", ri.Current.SchemaVersion, resAddr, version)
 				}
 
 				current.SchemaVersion = ri.Current.SchemaVersion
 
 				if schema == nil {
-					return nil, fmt.Errorf("no schema found for %s (in provider %s)", resAddr.String(), r.ProviderConfig.Provider)
+					return fmt.Errorf("no schema found for %s (in provider %s)", resAddr.String(), r.ProviderConfig.Provider)
 				}
 				riObj, err := ri.Current.Decode(schema.ImpliedType())
 				if err != nil {
-					return nil, err
+					return err
 				}
 
 				current.AttributeValues = marshalAttributeValues(riObj.Value)
@@ -420,7 +445,7 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 				s := SensitiveAsBoolWithPathValueMarks(value, marks)
 				v, err := ctyjson.Marshal(s, s.Type())
 				if err != nil {
-					return nil, err
+					return err
 				}
 				current.SensitiveValues = v
 
@@ -435,7 +460,9 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 				if riObj.Status == states.ObjectTainted {
 					current.Tainted = true
 				}
-				ret = append(ret, current)
+				if err := fn(current); err != nil {
+					return err
+				}
 			}
 
 			var sortedDeposedKeys []string
@@ -459,7 +486,7 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 
 				riObj, err := rios.Decode(schema.ImpliedType())
 				if err != nil {
-					return nil, err
+					return err
 				}
 
 				deposed.AttributeValues = marshalAttributeValues(riObj.Value)
@@ -471,7 +498,7 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 				s := SensitiveAsBool(value.MarkWithPaths(marks))
 				v, err := ctyjson.Marshal(s, s.Type())
 				if err != nil {
-					return nil, err
+					return err
 				}
 				deposed.SensitiveValues = v
 
@@ -487,12 +514,14 @@ func marshalResources(resources map[string]*states.Resource, module addrs.Module
 					deposed.Tainted = true
 				}
 				deposed.DeposedKey = deposedKey
-				ret = append(ret, deposed)
+				if err := fn(deposed); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	return ret, nil
+	return nil
 }
 
 func SensitiveAsBool(val cty.Value) cty.Value {
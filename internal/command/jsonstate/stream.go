@@ -0,0 +1,206 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonstate
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonchecks"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// MarshalTo writes the same JSON encoding of a tofu state as Marshal to w,
+// but encodes and writes one resource instance object at a time rather than
+// building the whole document in memory first, which matters for very large
+// states.
+//
+// If MarshalTo fails part way through then it will already have written an
+// incomplete document to w.
+func MarshalTo(w io.Writer, sf *statefile.File, schemas *tofu.Schemas) error {
+	sw := &streamWriter{w: bufio.NewWriter(w)}
+
+	sw.raw(`{"format_version":`)
+	sw.value(FormatVersion)
+
+	if sf != nil && !sf.State.Empty() {
+		if sf.TerraformVersion != nil {
+			sw.raw(`,"terraform_version":`)
+			sw.value(sf.TerraformVersion.String())
+		}
+
+		outputs, err := MarshalOutputs(sf.State.RootModule().OutputValues)
+		if err != nil {
+			return err
+		}
+		sw.raw(`,"values":{`)
+		if len(outputs) > 0 {
+			sw.raw(`"outputs":`)
+			sw.value(outputs)
+			sw.raw(`,`)
+		}
+		sw.raw(`"root_module":`)
+		tree, err := moduleTree(sf.State)
+		if err != nil {
+			return err
+		}
+		if err := sw.module(sf.State, schemas, addrs.RootModuleInstance, tree); err != nil {
+			return err
+		}
+		sw.raw(`}`)
+
+		if sf.State.CheckResults != nil && sf.State.CheckResults.ConfigResults.Len() > 0 {
+			sw.raw(`,"checks":`)
+			sw.value(jsonchecks.MarshalCheckStates(sf.State.CheckResults))
+		}
+	}
+
+	sw.raw(`}`)
+	return sw.flush()
+}
+
+// MarshalResourcesTo writes each resource instance object in the given state
+// to w as a separate JSON object on its own line, in the newline-delimited
+// JSON format. The objects are the same as in the "resources" arrays of the
+// full JSON state representation, and they're written in the same order.
+func MarshalResourcesTo(w io.Writer, sf *statefile.File, schemas *tofu.Schemas) error {
+	sw := &streamWriter{w: bufio.NewWriter(w)}
+	if sf == nil || sf.State.Empty() {
+		return sw.flush()
+	}
+
+	tree, err := moduleTree(sf.State)
+	if err != nil {
+		return err
+	}
+	var walk func(addr addrs.ModuleInstance) error
+	walk = func(addr addrs.ModuleInstance) error {
+		if mod := sf.State.Module(addr); mod != nil {
+			err := eachResource(mod.Resources, schemas, func(r Resource) error {
+				sw.value(r)
+				sw.raw("\n")
+				return sw.err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, child := range sortedChildModules(tree, addr) {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(addrs.RootModuleInstance); err != nil {
+		return err
+	}
+	return sw.flush()
+}
+
+// streamWriter writes JSON to a buffered writer, retaining the first error
+// so that callers need only check it at the end.
+type streamWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *streamWriter) raw(s string) {
+	if sw.err == nil {
+		_, sw.err = sw.w.WriteString(s)
+	}
+}
+
+func (sw *streamWriter) value(v any) {
+	if sw.err != nil {
+		return
+	}
+	src, err := json.Marshal(v)
+	if err != nil {
+		sw.err = err
+		return
+	}
+	_, sw.err = sw.w.Write(src)
+}
+
+func (sw *streamWriter) flush() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// module writes the representation of the given module instance, matching
+// the encoding of Module.
+func (sw *streamWriter) module(s *states.State, schemas *tofu.Schemas, addr addrs.ModuleInstance, tree map[string][]addrs.ModuleInstance) error {
+	sw.raw(`{`)
+	empty := true
+	field := func(name string) {
+		if !empty {
+			sw.raw(`,`)
+		}
+		empty = false
+		sw.raw(`"` + name + `":`)
+	}
+
+	if mod := s.Module(addr); mod != nil {
+		count := 0
+		err := eachResource(mod.Resources, schemas, func(r Resource) error {
+			if count == 0 {
+				field("resources")
+				sw.raw(`[`)
+			} else {
+				sw.raw(`,`)
+			}
+			count++
+			sw.value(r)
+			return sw.err
+		})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			sw.raw(`]`)
+		}
+	}
+
+	if !addr.IsRoot() {
+		field("address")
+		sw.value(addr.String())
+	}
+
+	if children := sortedChildModules(tree, addr); len(children) > 0 {
+		field("child_modules")
+		sw.raw(`[`)
+		for i, child := range children {
+			if i > 0 {
+				sw.raw(`,`)
+			}
+			if err := sw.module(s, schemas, child, tree); err != nil {
+				return err
+			}
+		}
+		sw.raw(`]`)
+	}
+
+	sw.raw(`}`)
+	return sw.err
+}
+
+// sortedChildModules returns the children of the given module instance in
+// the order that marshalModules sorts them.
+func sortedChildModules(tree map[string][]addrs.ModuleInstance, addr addrs.ModuleInstance) []addrs.ModuleInstance {
+	children := tree[addr.String()]
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].String() < children[j].String()
+	})
+	return children
+}
@@ -0,0 +1,132 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonstate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+func TestMarshalTo(t *testing.T) {
+	tests := map[string]*statefile.File{
+		"nil":   nil,
+		"empty": statefile.New(states.NewState(), "", 0),
+		"full":  testStreamStateFile(),
+	}
+	for name, sf := range tests {
+		t.Run(name, func(t *testing.T) {
+			want, err := Marshal(sf, testSchemas())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var got bytes.Buffer
+			if err := MarshalTo(&got, sf, testSchemas()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.String() != string(want) {
+				t.Fatalf("wrong result\ngot:  %s\nwant: %s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestMarshalResourcesTo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := MarshalResourcesTo(&buf, testStreamStateFile(), testSchemas()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var r Resource
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid line %q: %s", line, err)
+		}
+		if r.DeposedKey != "" {
+			got = append(got, r.Address+" (deposed)")
+		} else {
+			got = append(got, r.Address)
+		}
+	}
+	want := []string{
+		"test_instance.foo[0]",
+		"test_instance.foo[0] (deposed)",
+		"test_instance.foo[1]",
+		"module.child.module.grandchild.test_instance.foo",
+		"module.other.test_thing.bar",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("wrong resources\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func testStreamStateFile() *statefile.File {
+	grandchild := addrs.RootModuleInstance.Child("child", addrs.NoKey).Child("grandchild", addrs.NoKey)
+	other := addrs.RootModuleInstance.Child("other", addrs.NoKey)
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	instance := func(mode addrs.ResourceMode, typ, name string, key addrs.InstanceKey, module addrs.ModuleInstance) addrs.AbsResourceInstance {
+		return addrs.Resource{Mode: mode, Type: typ, Name: name}.Instance(key).Absolute(module)
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(addrs.OutputValue{Name: "html"}.Absolute(addrs.RootModuleInstance), cty.StringVal("<b>&</b>"), false)
+		s.SetOutputValue(addrs.OutputValue{Name: "secret"}.Absolute(addrs.RootModuleInstance), cty.StringVal("shh"), true)
+		for _, key := range []addrs.InstanceKey{addrs.IntKey(1), addrs.IntKey(0)} {
+			s.SetResourceInstanceCurrent(
+				instance(addrs.ManagedResourceMode, "test_instance", "foo", key, addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"<foo>","foo":"value","bar":"value"}`),
+					Status:    states.ObjectReady,
+				},
+				provider,
+				addrs.NoKey,
+			)
+		}
+		s.SetResourceInstanceDeposed(
+			instance(addrs.ManagedResourceMode, "test_instance", "foo", addrs.IntKey(0), addrs.RootModuleInstance),
+			states.DeposedKey("deadbeef"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"old"}`),
+				Status:    states.ObjectTainted,
+			},
+			provider,
+			addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			instance(addrs.ManagedResourceMode, "test_instance", "foo", addrs.NoKey, grandchild),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"nested"}`),
+				Status:    states.ObjectReady,
+			},
+			provider,
+			addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			instance(addrs.ManagedResourceMode, "test_thing", "bar", addrs.NoKey, other),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"woozles":"confuzles","foozles":"sensuzles"}`),
+				Status:    states.ObjectReady,
+			},
+			provider,
+			addrs.NoKey,
+		)
+	})
+	sf := statefile.New(state, "lineage", 1)
+	sf.TerraformVersion = version.Must(version.NewVersion("1.10.0"))
+	return sf
+}
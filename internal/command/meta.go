@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-plugin"
+	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/terraform-svchost/disco"
 	"github.com/mitchellh/cli"
 	"github.com/mitchellh/colorstring"
@@ -36,6 +37,8 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
+	"github.com/opentofu/opentofu/internal/plananalyzer"
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/states"
@@ -108,6 +111,15 @@ type Meta struct {
 	// into the given directory.
 	PluginCacheDir string
 
+	// PluginSchemaCacheDir, if non-empty, is the directory where provider
+	// schemas are cached across runs. Otherwise they are cached in
+	// PluginCacheDir, if that is set.
+	PluginSchemaCacheDir string
+
+	// ModulePackageCacheDir, if non-empty, enables caching of remote module
+	// packages into the given directory, shared between working directories.
+	ModulePackageCacheDir string
+
 	// PluginCacheMayBreakDependencyLockFile is a temporary CLI configuration-based
 	// opt out for the behavior of only using the plugin cache dir if its
 	// contents match checksums recorded in the dependency lock file.
@@ -124,6 +136,15 @@ type Meta struct {
 	// longer any compelling reasons for folks to not lock their dependencies.
 	PluginCacheMayBreakDependencyLockFile bool
 
+	// WorkspaceAutoCreate is the workspace_auto_create setting of the CLI
+	// configuration, which decides what happens when the TF_WORKSPACE
+	// environment variable selects a workspace that doesn't exist.
+	WorkspaceAutoCreate string
+
+	// PlanAnalyzers are the analyzers configured in the CLI configuration,
+	// which each plan is passed to before it is rendered.
+	PlanAnalyzers []plananalyzer.Analyzer
+
 	// ProviderSource allows determining the available versions of a provider
 	// and determines where a distribution package for a particular
 	// provider version can be obtained.
@@ -153,6 +174,11 @@ type Meta struct {
 	// checksums they have.
 	ProviderDevOverrides map[addrs.Provider]getproviders.PackageLocalDir
 
+	// ProviderPlatformFallbacks are rules that allow "tofu init" to install
+	// provider packages built for other platforms, which this system can run
+	// under emulation, when there is no package for the current platform.
+	ProviderPlatformFallbacks getproviders.PlatformFallbacks
+
 	// UnmanagedProviders are a set of providers that exist as processes
 	// predating OpenTofu, which OpenTofu should use but not worry about the
 	// lifecycle of.
@@ -221,6 +247,10 @@ type Meta struct {
 	color bool
 	oldUi cli.Ui
 
+	// runID uniquely identifies this run of OpenTofu for the tofu.run_id
+	// symbol. It is generated on first use.
+	runID string
+
 	// The fields below are expected to be set by the command via
 	// command line flags. See the Apply command for an example.
 	//
@@ -239,6 +269,20 @@ type Meta struct {
 	// parallelism is used to control the number of concurrent operations
 	// allowed when walking the graph
 	//
+	// refreshConcurrency is used to control the number of concurrent refresh
+	// requests allowed for each provider configuration, or zero to count them
+	// against parallelism instead.
+	//
+	// tuneParallelism adjusts the number of concurrent apply requests
+	// allowed for each provider configuration during apply based on how
+	// long they take, within parallelism.
+	//
+	// allowedApplyActions, if not empty, is the set of resource instance
+	// change actions that apply is allowed to take.
+	//
+	// allowProviderMismatch allows applying a saved plan with different
+	// provider packages than it was created with.
+	//
 	// provider is to specify specific resource providers
 	//
 	// stateLock is set to false to disable state locking
@@ -246,6 +290,10 @@ type Meta struct {
 	// stateLockTimeout is the optional duration to retry a state locks locks
 	// when it is already locked by another process.
 	//
+	// stateMergeConcurrentWrites (-merge-concurrent-state-writes) merges the
+	// changes written concurrently by other operations when the state isn't
+	// locked.
+	//
 	// forceInitCopy suppresses confirmation for copying state data during
 	// init.
 	//
@@ -254,6 +302,9 @@ type Meta struct {
 	// migrateState confirms the user wishes to migrate from the prior backend
 	// configuration to a new configuration.
 	//
+	// revalidateBackend forces init to verify the selected workspace against
+	// the backend even if it was already verified for the same configuration.
+	//
 	// compactWarnings (-compact-warnings) selects a more compact presentation
 	// of warnings in the output when they are not accompanied by errors.
 	//
@@ -262,18 +313,24 @@ type Meta struct {
 	//
 	// consolidateErrors (-consolidate-errors=true) enables consolodation
 	// of errors in the output, printing a single instances of a particular warning.
-	statePath           string
-	stateOutPath        string
-	backupPath          string
-	parallelism         int
-	stateLock           bool
-	stateLockTimeout    time.Duration
-	forceInitCopy       bool
-	reconfigure         bool
-	migrateState        bool
-	compactWarnings     bool
-	consolidateWarnings bool
-	consolidateErrors   bool
+	statePath                  string
+	stateOutPath               string
+	backupPath                 string
+	parallelism                int
+	refreshConcurrency         int
+	tuneParallelism            bool
+	allowedApplyActions        []plans.Action
+	allowProviderMismatch      bool
+	stateLock                  bool
+	stateLockTimeout           time.Duration
+	stateMergeConcurrentWrites bool
+	forceInitCopy              bool
+	reconfigure                bool
+	migrateState               bool
+	revalidateBackend          bool
+	compactWarnings            bool
+	consolidateWarnings        bool
+	consolidateErrors          bool
 
 	// Used with commands which write state to allow users to write remote
 	// state even if the remote and local OpenTofu versions don't match.
@@ -285,6 +342,10 @@ type Meta struct {
 	// This helps prevent duplicate errors/warnings.
 	rootModuleCallCache *configs.StaticModuleCall
 	inputVariableCache  map[string]backend.UnparsedVariableValue
+
+	// Used to call provider functions outside of a graph walk. See
+	// Meta.providerFunctions.
+	providerFunctionsCache *tofu.ProviderFunctions
 }
 
 type testingOverrides struct {
@@ -558,6 +619,9 @@ func (m *Meta) contextOpts() (*tofu.ContextOpts, error) {
 
 	opts.UIInput = m.UIInput()
 	opts.Parallelism = m.parallelism
+	opts.RefreshConcurrency = m.refreshConcurrency
+	opts.TuneParallelism = m.tuneParallelism
+	opts.AllowedApplyActions = m.allowedApplyActions
 
 	// If testingOverrides are set, we'll skip the plugin discovery process
 	// and just work with what we've been given, thus allowing the tests
@@ -572,9 +636,17 @@ func (m *Meta) contextOpts() (*tofu.ContextOpts, error) {
 		opts.Provisioners = m.provisionerFactories()
 	}
 
+	if m.runID == "" {
+		m.runID, err = uuid.GenerateUUID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate run ID: %w", err)
+		}
+	}
+
 	opts.Meta = &tofu.ContextMeta{
 		Env:                workspace,
 		OriginalWorkingDir: m.WorkingDir.OriginalWorkingDir(),
+		RunID:              m.runID,
 	}
 
 	return &opts, err
@@ -611,8 +683,10 @@ func (m *Meta) varFlagSet(f *flag.FlagSet) {
 	}
 	varValues := m.variableArgs.Alias("-var")
 	varFiles := m.variableArgs.Alias("-var-file")
+	varMergeFiles := m.variableArgs.Alias("-var-file-merge")
 	f.Var(varValues, "var", "variables")
 	f.Var(varFiles, "var-file", "variable file")
+	f.Var(varMergeFiles, "var-file-merge", "variable file to merge")
 }
 
 // extendedFlagSet adds custom flags that are mostly used by commands
@@ -858,6 +932,7 @@ func isAutoVarFile(path string) bool {
 func (m *Meta) applyStateArguments(args *arguments.State) {
 	m.stateLock = args.Lock
 	m.stateLockTimeout = args.LockTimeout
+	m.stateMergeConcurrentWrites = args.MergeConcurrentWrites
 	m.statePath = args.StatePath
 	m.stateOutPath = args.StateOutPath
 	m.backupPath = args.BackupPath
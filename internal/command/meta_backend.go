@@ -26,9 +26,11 @@ import (
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/cloud"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/command/clistate"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
@@ -63,6 +65,12 @@ type BackendOpts struct {
 	// ViewType will set console output format for the
 	// initialization operation (JSON or human-readable).
 	ViewType arguments.ViewType
+
+	// WorkspaceCommand should be set by commands that manage workspaces
+	// explicitly, such as "tofu workspace new", so that the
+	// workspace_auto_create policy doesn't stop them from working with a
+	// workspace that doesn't exist yet.
+	WorkspaceCommand bool
 }
 
 // BackendWithRemoteTerraformVersion is a shared interface between the 'remote' and 'cloud' backends
@@ -169,6 +177,11 @@ func (m *Meta) Backend(opts *BackendOpts, enc encryption.StateEncryption) (backe
 	// then return that as-is. This works even if b == nil (it will be !ok).
 	if enhanced, ok := b.(backend.Enhanced); ok {
 		log.Printf("[TRACE] Meta.Backend: backend %T supports operations", b)
+		if !opts.WorkspaceCommand {
+			if diags := m.checkWorkspaceAutoCreate(enhanced, m.backendState); diags.HasErrors() {
+				return nil, diags
+			}
+		}
 		return enhanced, nil
 	}
 
@@ -187,6 +200,8 @@ func (m *Meta) Backend(opts *BackendOpts, enc encryption.StateEncryption) (backe
 		panic(err)
 	}
 
+	savedBackendState := m.backendState
+
 	// If we got here from backendFromConfig returning nil then m.backendState
 	// won't be set, since that codepath considers that to be no backend at all,
 	// but our caller considers that to be the local backend with no config
@@ -210,6 +225,12 @@ func (m *Meta) Backend(opts *BackendOpts, enc encryption.StateEncryption) (backe
 		}
 	}
 
+	if !opts.ForceLocal && !opts.WorkspaceCommand {
+		if diags := m.checkWorkspaceAutoCreate(local, savedBackendState); diags.HasErrors() {
+			return nil, diags
+		}
+	}
+
 	return local, nil
 }
 
@@ -298,6 +319,73 @@ func (m *Meta) selectWorkspace(b backend.Backend) error {
 	return m.SetWorkspace(workspace)
 }
 
+// checkWorkspaceAutoCreate enforces the workspace_auto_create setting of the
+// CLI configuration when the TF_WORKSPACE environment variable selects a
+// workspace that doesn't exist in the given backend, which most backends
+// would otherwise create implicitly with an empty state.
+//
+// s is the saved configuration of the backend, if any, which allows skipping
+// the check for a workspace that was already verified for it.
+func (m *Meta) checkWorkspaceAutoCreate(b backend.Backend, s *legacy.BackendState) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	policy := m.WorkspaceAutoCreate
+	if policy == "" || policy == cliconfig.WorkspaceAutoCreateAllow {
+		return diags
+	}
+	workspace, overridden := m.WorkspaceOverridden()
+	if !overridden || !validWorkspaceName(workspace) {
+		// An invalid name is reported when the workspace is used.
+		return diags
+	}
+	if m.verifiedBackendCheck(s, workspace) != nil {
+		log.Printf("[TRACE] Meta.checkWorkspaceAutoCreate: workspace %q was already verified for the unchanged backend", workspace)
+		return diags
+	}
+
+	workspaces, err := b.Workspaces()
+	if err == backend.ErrWorkspacesNotSupported {
+		return diags
+	}
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to get existing workspaces: %w", err))
+		return diags
+	}
+	for _, name := range workspaces {
+		if name == workspace {
+			if s != nil {
+				m.recordBackendCheck(s, workspace)
+			}
+			return diags
+		}
+	}
+
+	if policy == cliconfig.WorkspaceAutoCreatePrompt && m.input {
+		v, err := m.UIInput().Input(context.Background(), &tofu.InputOpts{
+			Id: "create-workspace-env-var",
+			Query: fmt.Sprintf(
+				"\n[reset][bold][yellow]The workspace %q selected with the %s environment variable does not exist.[reset]",
+				workspace, WorkspaceNameEnvVar),
+			Description: "Do you want to create it? Only 'yes' will be accepted to confirm.",
+		})
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to confirm workspace creation: %w", err))
+			return diags
+		}
+		if v == "yes" {
+			log.Printf("[TRACE] Meta.checkWorkspaceAutoCreate: the user confirmed the creation of workspace %q", workspace)
+			return diags
+		}
+	}
+
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Workspace does not exist",
+		fmt.Sprintf(strings.TrimSpace(errWorkspaceEnvVarNotExist), workspace, WorkspaceNameEnvVar),
+	))
+	return diags
+}
+
 // BackendForLocalPlan is similar to Backend, but uses backend settings that were
 // stored in a plan.
 //
@@ -334,6 +422,12 @@ func (m *Meta) BackendForLocalPlan(settings plans.Backend, enc encryption.StateE
 		return nil, diags
 	}
 
+	b, nsDiags := m.namespacedBackend(b, settings.Type, settings.Namespace)
+	diags = diags.Append(nsDiags)
+	if nsDiags.HasErrors() {
+		return nil, diags
+	}
+
 	// If the backend supports CLI initialization, do it.
 	if cli, ok := b.(backend.CLI); ok {
 		cliOpts, err := m.backendCLIOpts()
@@ -440,15 +534,17 @@ func (m *Meta) Operation(b backend.Backend, vt arguments.ViewType, enc encryptio
 	}
 
 	return &backend.Operation{
-		Encryption:      enc,
-		PlanOutBackend:  planOutBackend,
-		Targets:         m.targets,
-		Excludes:        m.excludes,
-		UIIn:            m.UIInput(),
-		UIOut:           m.Ui,
-		Workspace:       workspace,
-		StateLocker:     stateLocker,
-		DependencyLocks: depLocks,
+		Encryption:                 enc,
+		PlanOutBackend:             planOutBackend,
+		Targets:                    m.targets,
+		Excludes:                   m.excludes,
+		UIIn:                       m.UIInput(),
+		UIOut:                      m.Ui,
+		Workspace:                  workspace,
+		StateLocker:                stateLocker,
+		MergeConcurrentStateWrites: m.stateMergeConcurrentWrites,
+		DependencyLocks:            depLocks,
+		PlanAnalyzers:              m.PlanAnalyzers,
 	}
 }
 
@@ -667,7 +763,7 @@ func (m *Meta) backendFromConfig(opts *BackendOpts, enc encryption.StateEncrypti
 			savedBackend, diags := m.savedBackend(sMgr, enc)
 			// Verify that selected workspace exist. Otherwise prompt user to create one
 			if opts.Init && savedBackend != nil {
-				if err := m.selectWorkspace(savedBackend); err != nil {
+				if err := m.selectWorkspaceCached(savedBackend, sMgr.State().Backend); err != nil {
 					diags = diags.Append(err)
 					return nil, diags
 				}
@@ -696,7 +792,7 @@ func (m *Meta) backendFromConfig(opts *BackendOpts, enc encryption.StateEncrypti
 			}
 			// Verify that selected workspace exist. Otherwise prompt user to create one
 			if opts.Init && savedBackend != nil {
-				if err := m.selectWorkspace(savedBackend); err != nil {
+				if err := m.selectWorkspaceCached(savedBackend, sMgr.State().Backend); err != nil {
 					diags = diags.Append(err)
 					return nil, diags
 				}
@@ -855,6 +951,12 @@ func (m *Meta) backendFromState(ctx context.Context, enc encryption.StateEncrypt
 		}
 	}
 
+	b, nsDiags := m.namespacedBackend(b, s.Backend.Type, s.Backend.Namespace)
+	diags = diags.Append(nsDiags)
+	if nsDiags.HasErrors() {
+		return nil, diags
+	}
+
 	return b, diags
 }
 
@@ -1080,11 +1182,12 @@ func (m *Meta) backend_C_r_s(c *configs.Backend, cHash int, sMgr *clistate.Local
 		Type:      c.Type,
 		ConfigRaw: json.RawMessage(configJSON),
 		Hash:      uint64(cHash),
+		Namespace: c.Namespace,
 	}
 
 	// Verify that selected workspace exists in the backend.
 	if opts.Init && b != nil {
-		err := m.selectWorkspace(b)
+		err := m.selectWorkspaceCached(b, s.Backend)
 		if err != nil {
 			diags = diags.Append(err)
 
@@ -1225,11 +1328,12 @@ func (m *Meta) backend_C_r_S_changed(c *configs.Backend, cHash int, sMgr *clista
 		Type:      c.Type,
 		ConfigRaw: json.RawMessage(configJSON),
 		Hash:      uint64(cHash),
+		Namespace: c.Namespace,
 	}
 
 	// Verify that selected workspace exist. Otherwise prompt user to create one
 	if opts.Init && b != nil {
-		if err := m.selectWorkspace(b); err != nil {
+		if err := m.selectWorkspaceCached(b, s.Backend); err != nil {
 			diags = diags.Append(err)
 			return b, diags
 		}
@@ -1288,11 +1392,17 @@ func (m *Meta) savedBackend(sMgr *clistate.LocalState, enc encryption.StateEncry
 		return nil, diags
 	}
 
-	// Validate the config and then configure the backend
-	newVal, validDiags := b.PrepareConfig(configVal)
-	diags = diags.Append(validDiags)
-	if validDiags.HasErrors() {
-		return nil, diags
+	// Validate the config and then configure the backend, unless the
+	// unchanged config was already validated for the selected workspace.
+	newVal, cached := m.cachedPreparedBackendConfig(s.Backend, schema.ImpliedType())
+	if !cached {
+		var validDiags tfdiags.Diagnostics
+		newVal, validDiags = b.PrepareConfig(configVal)
+		diags = diags.Append(validDiags)
+		if validDiags.HasErrors() {
+			return nil, diags
+		}
+		m.savePreparedBackendConfig(s.Backend, newVal)
 	}
 
 	configDiags := b.Configure(newVal)
@@ -1312,6 +1422,12 @@ func (m *Meta) savedBackend(sMgr *clistate.LocalState, enc encryption.StateEncry
 		}
 	}
 
+	b, nsDiags := m.namespacedBackend(b, s.Backend.Type, s.Backend.Namespace)
+	diags = diags.Append(nsDiags)
+	if nsDiags.HasErrors() {
+		return nil, diags
+	}
+
 	return b, diags
 }
 
@@ -1353,6 +1469,10 @@ func (m *Meta) backendConfigNeedsMigration(c *configs.Backend, s *legacy.Backend
 		log.Printf("[TRACE] backendConfigNeedsMigration: type changed from %q to %q, so migration is required", s.Type, c.Type)
 		return true
 	}
+	if c.Namespace != s.Namespace {
+		log.Printf("[TRACE] backendConfigNeedsMigration: state namespace changed from %q to %q, so migration is required", s.Namespace, c.Namespace)
+		return true
+	}
 
 	// We need the backend's schema to do our comparison here.
 	f := backendInit.Backend(c.Type)
@@ -1453,13 +1573,149 @@ func (m *Meta) backendInitFromConfig(c *configs.Backend, enc encryption.StateEnc
 		}
 	}
 
+	b, nsDiags := m.namespacedBackend(b, c.Type, c.Namespace)
+	diags = diags.Append(nsDiags)
+	if nsDiags.HasErrors() {
+		return nil, cty.NilVal, diags
+	}
+
 	return b, configVal, diags
 }
 
+// namespacedBackend returns a backend that stores its states in the given
+// state namespace of the given backend, or the given backend itself if the
+// namespace is empty.
+//
+// Only backends that just store state support namespaces, because backends
+// that run operations decide themselves where the state is stored.
+func (m *Meta) namespacedBackend(b backend.Backend, backendType, namespace string) (backend.Backend, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if namespace == "" {
+		return b, diags
+	}
+	if _, ok := b.(backend.Enhanced); ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Backend doesn't support state namespaces",
+			fmt.Sprintf("The %q backend runs operations itself, so OpenTofu can't apply a state namespace to it. Remove the state_namespace argument from the backend configuration.", backendType),
+		))
+		return nil, diags
+	}
+
+	log.Printf("[TRACE] Meta.Backend: storing the states of %q backend in namespace %q", backendType, namespace)
+	return backend.Namespaced(b, namespace), diags
+}
+
 // Helper method to get aliases from the enhanced backend and alias them
 // in the Meta service discovery. It's unfortunate that the Meta backend
 // is modifying the service discovery at this level, but the owner
 // of the service discovery pointer does not have easy access to the backend.
+// backendConfigOverrideBody interprets the raw values of -backend-config
+// arguments into a hcl Body that should override the backend settings given
+// in the configuration.
+//
+// If the result is nil then no override needs to be provided.
+//
+// If the returned diagnostics contains errors then the returned body may be
+// incomplete or invalid.
+func (m *Meta) backendConfigOverrideBody(flags rawFlags, schema *configschema.Block) (hcl.Body, tfdiags.Diagnostics) {
+	items := flags.AllItems()
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var ret hcl.Body
+	var diags tfdiags.Diagnostics
+	synthVals := make(map[string]cty.Value)
+
+	mergeBody := func(newBody hcl.Body) {
+		if ret == nil {
+			ret = newBody
+		} else {
+			ret = configs.MergeBodies(ret, newBody)
+		}
+	}
+	flushVals := func() {
+		if len(synthVals) == 0 {
+			return
+		}
+		newBody := configs.SynthBody("-backend-config=...", synthVals)
+		mergeBody(newBody)
+		synthVals = make(map[string]cty.Value)
+	}
+
+	if len(items) == 1 && items[0].Value == "" {
+		// Explicitly remove all -backend-config options.
+		// We do this by setting an empty but non-nil ConfigOverrides.
+		return configs.SynthBody("-backend-config=''", synthVals), diags
+	}
+
+	for _, item := range items {
+		eq := strings.Index(item.Value, "=")
+
+		if eq == -1 {
+			// The value is interpreted as a filename.
+			newBody, fileDiags := m.loadHCLFile(item.Value)
+			diags = diags.Append(fileDiags)
+			if fileDiags.HasErrors() {
+				continue
+			}
+			// Generate an HCL body schema for the backend block.
+			var bodySchema hcl.BodySchema
+			for name := range schema.Attributes {
+				// We intentionally ignore the `Required` attribute here
+				// because backend config override files can be partial. The
+				// goal is to make sure we're not loading a file with
+				// extraneous attributes or blocks.
+				bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{
+					Name: name,
+				})
+			}
+			for name, block := range schema.BlockTypes {
+				var labelNames []string
+				if block.Nesting == configschema.NestingMap {
+					labelNames = append(labelNames, "key")
+				}
+				bodySchema.Blocks = append(bodySchema.Blocks, hcl.BlockHeaderSchema{
+					Type:       name,
+					LabelNames: labelNames,
+				})
+			}
+			// Verify that the file body matches the expected backend schema.
+			_, schemaDiags := newBody.Content(&bodySchema)
+			diags = diags.Append(schemaDiags)
+			if schemaDiags.HasErrors() {
+				continue
+			}
+			flushVals() // deal with any accumulated individual values first
+			mergeBody(newBody)
+		} else {
+			name := item.Value[:eq]
+			rawValue := item.Value[eq+1:]
+			attrS := schema.Attributes[name]
+			if attrS == nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid backend configuration argument",
+					fmt.Sprintf("The backend configuration argument %q given on the command line is not expected for the selected backend type.", name),
+				))
+				continue
+			}
+			value, valueDiags := configValueFromCLI(item.String(), rawValue, attrS.Type)
+			diags = diags.Append(valueDiags)
+			if valueDiags.HasErrors() {
+				continue
+			}
+			synthVals[name] = value
+		}
+	}
+
+	flushVals()
+
+	return ret, diags
+}
+
 func (m *Meta) setupEnhancedBackendAliases(b backend.Enhanced) error {
 	// Set up the service discovery aliases specified by the enhanced backend.
 	serviceAliases, err := b.ServiceDiscoveryAliases()
@@ -1589,6 +1845,14 @@ If you'd like to run OpenTofu and store state locally, you can fix this
 error by removing the backend configuration from your configuration.
 `
 
+const errWorkspaceEnvVarNotExist = `
+The workspace %q selected with the %s environment variable does not exist,
+and the workspace_auto_create setting of the CLI configuration doesn't allow
+creating it implicitly.
+
+Check the name of the workspace, or create it with "tofu workspace new".
+`
+
 const errBackendNoExistingWorkspaces = `
 No existing workspaces.
 
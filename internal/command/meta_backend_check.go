@@ -0,0 +1,193 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
+)
+
+// DefaultBackendCheckFilename is the name of the file, within the data
+// directory, that records the backend configuration and workspace that were
+// most recently verified against the backend.
+const DefaultBackendCheckFilename = "backend-check.json"
+
+// backendCheck is the content of the DefaultBackendCheckFilename file.
+type backendCheck struct {
+	// Hash covers the backend type, the complete backend configuration
+	// (including any -backend-config values) and the selected workspace.
+	Hash string `json:"hash"`
+
+	// PreparedConfig is the backend configuration as returned by the
+	// backend's PrepareConfig method, encoded as JSON, or nil if it wasn't
+	// recorded yet.
+	PreparedConfig json.RawMessage `json:"prepared_config,omitempty"`
+}
+
+// verifiedBackendCheck returns the cached backend check if it shows that the
+// given workspace was already verified to exist for the given backend
+// configuration, or nil otherwise. Every command uses the cache to skip
+// listing the workspaces of the backend and validating its configuration
+// again while neither changes, which can take a long time for some
+// backends.
+//
+// The -revalidate-backend and -reconfigure options of "tofu init" disable the
+// cache, which is necessary to notice a workspace that was deleted outside of
+// this working directory.
+func (m *Meta) verifiedBackendCheck(s *legacy.BackendState, workspace string) *backendCheck {
+	if m.revalidateBackend || m.reconfigure || s == nil {
+		return nil
+	}
+	check, err := m.loadBackendCheck()
+	if err != nil {
+		log.Printf("[WARN] Meta.verifiedBackendCheck: ignoring unreadable backend check cache: %s", err)
+		return nil
+	}
+	if check == nil || check.Hash != backendCheckHash(s, workspace) {
+		return nil
+	}
+	return check
+}
+
+// recordBackendCheck records that the given workspace exists for the given
+// backend configuration.
+func (m *Meta) recordBackendCheck(s *legacy.BackendState, workspace string) {
+	if err := m.saveBackendCheck(&backendCheck{Hash: backendCheckHash(s, workspace)}); err != nil {
+		// The cache is only an optimization, so failing to write it just
+		// means the next run will verify the workspace again.
+		log.Printf("[WARN] Meta.recordBackendCheck: failed to save backend check cache: %s", err)
+	}
+}
+
+// selectWorkspaceCached behaves like selectWorkspace, except that it skips
+// asking the backend for its workspaces if the same workspace was already
+// verified for the same backend configuration. Re-running init with an
+// unchanged configuration is common in automation.
+func (m *Meta) selectWorkspaceCached(b backend.Backend, s *legacy.BackendState) error {
+	workspace, err := m.Workspace()
+	if err != nil {
+		return err
+	}
+
+	if m.verifiedBackendCheck(s, workspace) != nil {
+		log.Printf("[TRACE] Meta.selectWorkspaceCached: workspace %q was already verified for the unchanged %q backend", workspace, s.Type)
+		return nil
+	}
+
+	if err := m.selectWorkspace(b); err != nil {
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+
+	// The user may have selected a different workspace, so we must record
+	// whichever one is selected now.
+	workspace, err = m.Workspace()
+	if err != nil {
+		return err
+	}
+	m.recordBackendCheck(s, workspace)
+	return nil
+}
+
+// cachedPreparedBackendConfig returns the result of the backend's
+// PrepareConfig method for the given saved backend configuration, if it was
+// recorded for the verified configuration and the selected workspace.
+func (m *Meta) cachedPreparedBackendConfig(s *legacy.BackendState, ty cty.Type) (cty.Value, bool) {
+	workspace, err := m.Workspace()
+	if err != nil {
+		return cty.NilVal, false
+	}
+	check := m.verifiedBackendCheck(s, workspace)
+	if check == nil || check.PreparedConfig == nil {
+		return cty.NilVal, false
+	}
+	val, err := ctyjson.Unmarshal(check.PreparedConfig, ty)
+	if err != nil {
+		log.Printf("[WARN] Meta.cachedPreparedBackendConfig: ignoring invalid prepared backend configuration: %s", err)
+		return cty.NilVal, false
+	}
+	log.Printf("[TRACE] Meta.cachedPreparedBackendConfig: using the prepared configuration of the unchanged %q backend", s.Type)
+	return val, true
+}
+
+// savePreparedBackendConfig records the result of the backend's
+// PrepareConfig method for the given saved backend configuration, but only
+// if the configuration was already verified for the selected workspace.
+func (m *Meta) savePreparedBackendConfig(s *legacy.BackendState, val cty.Value) {
+	workspace, err := m.Workspace()
+	if err != nil {
+		return
+	}
+	check := m.verifiedBackendCheck(s, workspace)
+	if check == nil {
+		return
+	}
+	src, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		log.Printf("[WARN] Meta.savePreparedBackendConfig: can't serialize the prepared backend configuration: %s", err)
+		return
+	}
+	check.PreparedConfig = src
+	if err := m.saveBackendCheck(check); err != nil {
+		log.Printf("[WARN] Meta.savePreparedBackendConfig: failed to save backend check cache: %s", err)
+	}
+}
+
+func (m *Meta) loadBackendCheck() (*backendCheck, error) {
+	src, err := os.ReadFile(filepath.Join(m.DataDir(), DefaultBackendCheckFilename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var check backendCheck
+	if err := json.Unmarshal(src, &check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (m *Meta) saveBackendCheck(check *backendCheck) error {
+	src, err := json.Marshal(check)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.DataDir(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.DataDir(), DefaultBackendCheckFilename), src, 0644)
+}
+
+// backendCheckHash returns a hash identifying the given backend configuration
+// and state namespace together with the given workspace.
+func backendCheckHash(s *legacy.BackendState, workspace string) string {
+	h := sha256.New()
+	// The fields are separated by NUL bytes, which can't appear in any of
+	// them, so different combinations can't produce the same input.
+	h.Write([]byte(s.Type))
+	h.Write([]byte{0})
+	h.Write(s.ConfigRaw)
+	h.Write([]byte{0})
+	h.Write([]byte(s.Namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(workspace))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,200 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	backendInit "github.com/opentofu/opentofu/internal/backend/init"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/encryption"
+	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+)
+
+// countingWorkspacesBackend is a backend that counts how many times its
+// workspaces are listed.
+type countingWorkspacesBackend struct {
+	backend.Backend
+
+	workspaces []string
+	calls      int
+}
+
+func (b *countingWorkspacesBackend) Workspaces() ([]string, error) {
+	b.calls++
+	return b.workspaces, nil
+}
+
+func TestMetaBackend_selectWorkspaceCached(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	m := testMetaBackend(t, nil)
+	b := &countingWorkspacesBackend{workspaces: []string{"default", "prod"}}
+	s := &legacy.BackendState{
+		Type:      "example",
+		ConfigRaw: json.RawMessage(`{"bucket":"a"}`),
+	}
+
+	if err := m.selectWorkspaceCached(b, s); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 1 {
+		t.Fatalf("workspaces should be listed on the first run, got %d calls", b.calls)
+	}
+	if _, err := os.Stat(filepath.Join(m.DataDir(), DefaultBackendCheckFilename)); err != nil {
+		t.Fatalf("backend check cache was not written: %s", err)
+	}
+
+	// Unchanged configuration and workspace
+	if err := m.selectWorkspaceCached(b, s); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 1 {
+		t.Fatalf("workspaces should not be listed again, got %d calls", b.calls)
+	}
+
+	// Changed configuration
+	changed := &legacy.BackendState{
+		Type:      "example",
+		ConfigRaw: json.RawMessage(`{"bucket":"b"}`),
+	}
+	if err := m.selectWorkspaceCached(b, changed); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 2 {
+		t.Fatalf("workspaces should be listed after a configuration change, got %d calls", b.calls)
+	}
+
+	// Changed workspace
+	if err := m.SetWorkspace("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.selectWorkspaceCached(b, changed); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 3 {
+		t.Fatalf("workspaces should be listed after selecting another workspace, got %d calls", b.calls)
+	}
+
+	// Escape hatch
+	m.revalidateBackend = true
+	if err := m.selectWorkspaceCached(b, changed); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 4 {
+		t.Fatalf("workspaces should be listed with -revalidate-backend, got %d calls", b.calls)
+	}
+}
+
+func TestMetaBackend_selectWorkspaceCachedMissing(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	m := testMetaBackend(t, nil)
+	m.input = false
+	b := &countingWorkspacesBackend{workspaces: []string{"a", "b"}}
+	s := &legacy.BackendState{
+		Type:      "example",
+		ConfigRaw: json.RawMessage(`{}`),
+	}
+
+	if err := m.selectWorkspaceCached(b, s); err == nil {
+		t.Fatal("expected an error for a missing workspace")
+	}
+	if _, err := os.Stat(filepath.Join(m.DataDir(), DefaultBackendCheckFilename)); !os.IsNotExist(err) {
+		t.Fatalf("backend check cache should not be written after a failed check: %v", err)
+	}
+}
+
+func TestMetaBackend_backendCheckOtherCommands(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-unchanged-vars"), td)
+	defer testChdir(t, td)()
+
+	mock := &backendInit.MockBackend{
+		ConfigSchemaFn: func() *configschema.Block {
+			return &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"path":          {Type: cty.String, Required: true},
+					"workspace_dir": {Type: cty.String, Required: true},
+				},
+			}
+		},
+		WorkspacesFn: func() ([]string, error) {
+			return []string{"default"}, nil
+		},
+		StateMgrFn: func(workspace string) (statemgr.Full, error) {
+			return statemgr.NewFilesystem("local-state.tfstate", encryption.StateEncryptionDisabled()), nil
+		},
+	}
+	t.Cleanup(backendInit.RegisterTemp("_test_local", func(enc encryption.StateEncryption) backend.Backend {
+		return mock
+	}))
+	checkPath := filepath.Join(td, DefaultDataDir, DefaultBackendCheckFilename)
+
+	runBackend := func(opts *BackendOpts) {
+		t.Helper()
+		mock.PrepareConfigCalled = false
+		mock.ConfigureCalled = false
+		mock.WorkspacesCalled = false
+		m := testMetaBackend(t, nil)
+		m.migrateState = false
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreateError
+		if _, diags := m.Backend(opts, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+		if !mock.ConfigureCalled {
+			t.Fatal("backend was not configured")
+		}
+	}
+	t.Setenv(WorkspaceNameEnvVar, backend.DefaultStateName)
+
+	// Before init has verified the workspace, every command validates the
+	// configuration and checks that the workspace exists.
+	runBackend(nil)
+	if !mock.PrepareConfigCalled || !mock.WorkspacesCalled {
+		t.Fatalf("backend was not verified without a cache (PrepareConfig %t, Workspaces %t)", mock.PrepareConfigCalled, mock.WorkspacesCalled)
+	}
+	if _, err := os.Stat(checkPath); err != nil {
+		t.Fatalf("backend check cache was not written: %s", err)
+	}
+
+	// The next command records the prepared configuration of the verified
+	// backend, and the commands after it don't verify the backend again.
+	runBackend(nil)
+	if !mock.PrepareConfigCalled {
+		t.Fatal("configuration was not validated before it was recorded")
+	}
+	if mock.WorkspacesCalled {
+		t.Fatal("workspaces were listed again for a verified workspace")
+	}
+	runBackend(nil)
+	if mock.PrepareConfigCalled || mock.WorkspacesCalled {
+		t.Fatalf("unchanged backend was verified again (PrepareConfig %t, Workspaces %t)", mock.PrepareConfigCalled, mock.WorkspacesCalled)
+	}
+	if got, want := mock.ConfigureConfigObj.GetAttr("path"), cty.StringVal("local-state.tfstate"); !got.RawEquals(want) {
+		t.Errorf("wrong configuration from the cache %#v; want %#v", got, want)
+	}
+
+	// Selecting another workspace invalidates the cache.
+	t.Setenv(WorkspaceNameEnvVar, "other")
+	mock.WorkspacesFn = func() ([]string, error) {
+		return []string{"default", "other"}, nil
+	}
+	runBackend(nil)
+	if !mock.PrepareConfigCalled || !mock.WorkspacesCalled {
+		t.Fatalf("backend was not verified for another workspace (PrepareConfig %t, Workspaces %t)", mock.PrepareConfigCalled, mock.WorkspacesCalled)
+	}
+}
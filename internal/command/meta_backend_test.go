@@ -21,6 +21,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/copy"
@@ -75,6 +76,63 @@ func TestMetaBackend_emptyDir(t *testing.T) {
 	}
 }
 
+// Test the workspace_auto_create policy for a workspace that doesn't exist.
+func TestMetaBackend_workspaceAutoCreate(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	t.Setenv(WorkspaceNameEnvVar, "prod")
+
+	t.Run("create", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreateAllow
+		if _, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreateError
+		_, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled())
+		if !diags.HasErrors() {
+			t.Fatal("expected an error")
+		}
+		if got, want := diags.Err().Error(), `The workspace "prod" selected with the TF_WORKSPACE environment variable does not exist`; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+
+		// The workspace commands can still create the workspace.
+		if _, diags := m.Backend(&BackendOpts{Init: true, WorkspaceCommand: true}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+	})
+	t.Run("prompt", func(t *testing.T) {
+		defer testInputMap(t, map[string]string{
+			"create-workspace-env-var": "yes",
+		})()
+
+		m := testMetaBackend(t, nil)
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreatePrompt
+		if _, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+	})
+	t.Run("prompt without input", func(t *testing.T) {
+		m := testMetaBackend(t, []string{"-input=false"})
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreatePrompt
+		if _, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled()); !diags.HasErrors() {
+			t.Fatal("expected an error")
+		}
+	})
+	t.Run("existing workspace", func(t *testing.T) {
+		t.Setenv(WorkspaceNameEnvVar, backend.DefaultStateName)
+		m := testMetaBackend(t, nil)
+		m.WorkspaceAutoCreate = cliconfig.WorkspaceAutoCreateError
+		if _, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+	})
+}
+
 // check for no state. Either the file doesn't exist, or is empty
 func isEmptyState(path string) bool {
 	fi, err := os.Stat(path)
@@ -306,6 +364,91 @@ func TestMetaBackend_configureNew(t *testing.T) {
 }
 
 // Newly configured backend with prior local state and no remote state
+// Newly configured backend with a state namespace
+func TestMetaBackend_configureNewStateNamespace(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-state-namespace"), td)
+	defer testChdir(t, td)()
+	defer backendInmem.Reset()
+
+	// Setup the meta
+	m := testMetaBackend(t, nil)
+
+	// Get the backend
+	b, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled())
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if got, want := m.backendState.Namespace, "networking"; got != want {
+		t.Fatalf("wrong saved namespace %q; want %q", got, want)
+	}
+
+	// Write some state
+	s, err := b.StateMgr(backend.DefaultStateName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.RefreshState(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	state := states.NewState()
+	mark := markStateForMatching(state, "changing")
+	s.WriteState(state)
+	if err := s.PersistState(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The state is in the namespaced workspace of the inmem backend, and
+	// the namespaced backend only sees the workspaces in its namespace.
+	raw := backendInmem.New(encryption.StateEncryptionDisabled())
+	rawState, err := raw.StateMgr("networking.default")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := rawState.RefreshState(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertStateHasMarker(t, rawState.State(), mark)
+
+	if _, err := raw.StateMgr("other.dev"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	workspaces, err := b.Workspaces()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := []string{backend.DefaultStateName}; !reflect.DeepEqual(workspaces, want) {
+		t.Fatalf("wrong workspaces %#v; want %#v", workspaces, want)
+	}
+
+	// A plan records the namespace, so that applying it uses the same state.
+	planBackend, err := m.backendState.ForPlan(raw.ConfigSchema(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := planBackend.Namespace, "networking"; got != want {
+		t.Fatalf("wrong plan namespace %q; want %q", got, want)
+	}
+}
+
+// A backend that runs operations can't have a state namespace
+func TestMetaBackend_configureNewStateNamespaceEnhanced(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-state-namespace-local"), td)
+	defer testChdir(t, td)()
+
+	// Setup the meta
+	m := testMetaBackend(t, nil)
+
+	_, diags := m.Backend(&BackendOpts{Init: true}, encryption.StateEncryptionDisabled())
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags.Err().Error(), "Backend doesn't support state namespaces"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestMetaBackend_configureNewWithState(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -620,7 +763,9 @@ func TestMetaBackend_configureNewWithStateExistingNoMigrate(t *testing.T) {
 
 // Saved backend state matching config
 func TestMetaBackend_configuredUnchanged(t *testing.T) {
-	defer testChdir(t, testFixturePath("backend-unchanged"))()
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-unchanged"), td)
+	defer testChdir(t, td)()
 
 	// Setup the meta
 	m := testMetaBackend(t, nil)
@@ -681,7 +826,9 @@ func TestMetaBackend_configuredUnchangedWithStaticEvalVars(t *testing.T) {
 	// have migration skipped, even if the rules for activating that fast path
 	// change in future.
 
-	defer testChdir(t, testFixturePath("backend-unchanged-vars"))()
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-unchanged-vars"), td)
+	defer testChdir(t, td)()
 
 	// We'll use a mock backend here because we need to control the schema to
 	// make sure that we always have a required field for the ConfigOverride
@@ -8,6 +8,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
@@ -24,6 +26,8 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/initwd"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -155,6 +159,19 @@ func (m *Meta) rootModuleCall(rootDir string) (configs.StaticModuleCall, tfdiags
 		parsed, parsedDiags := v.ParseVariableValue(variable.ParsingMode)
 		return parsed.Value, parsedDiags.ToHCL()
 	}, rootDir, workspace)
+	call = call.WithProviderFunctions(func(provider addrs.Provider, name string, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+		var diags tfdiags.Diagnostics
+		funcs, err := m.providerFunctions()
+		if err != nil {
+			return nil, diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Provider functions unavailable",
+				Detail:   fmt.Sprintf("Cannot call function %q of provider %s: %s.", name, provider, err),
+				Subject:  rng.ToHCL().Ptr(),
+			})
+		}
+		return funcs.Function(provider, name, rng)
+	})
 	m.rootModuleCallCache = &call
 	return call, diags
 }
@@ -273,7 +290,11 @@ func (m *Meta) loadHCLFile(filename string) (hcl.Body, tfdiags.Diagnostics) {
 // can then be relayed to the end-user. The uiModuleInstallHooks type in
 // this package has a reasonable implementation for displaying notifications
 // via a provided cli.Ui.
-func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upgrade, installErrsOnly bool, hooks initwd.ModuleInstallHooks) (abort bool, diags tfdiags.Diagnostics) {
+//
+// The installed module packages are recorded in the dependency lock file,
+// unless readonlyLocks is set, in which case changes to the recorded modules
+// are errors.
+func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upgrade, installErrsOnly, readonlyLocks bool, hooks initwd.ModuleInstallHooks) (abort bool, diags tfdiags.Diagnostics) {
 	ctx, span := tracer.Start(ctx, "install modules")
 	defer span.End()
 
@@ -292,6 +313,17 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 	}
 
 	inst := initwd.NewModuleInstaller(m.modulesDir(), loader, m.registryClient())
+	if m.ModulePackageCacheDir != "" {
+		inst.SetPackageCache(getmodules.NewPackageCache(m.ModulePackageCacheDir))
+	}
+
+	previousLocks, lockDiags := m.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		return true, diags
+	}
+	locks := previousLocks.DeepCopy()
+	inst.SetLocks(locks)
 
 	call, vDiags := m.rootModuleCall(rootDir)
 	diags = diags.Append(vDiags)
@@ -308,9 +340,43 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 		return true, diags
 	}
 
+	if !diags.HasErrors() && !locks.EqualModules(previousLocks) {
+		switch {
+		case readonlyLocks && !sameModuleKeys(locks, previousLocks):
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Module dependency changes detected",
+				"The modules used by the configuration don't match those recorded in the dependency lock file, which can't be updated because of -lockfile=readonly.",
+			))
+		case readonlyLocks:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Module lock entries not updated",
+				"The module entries in the dependency lock file are outdated, but weren't updated because of -lockfile=readonly.",
+			))
+		default:
+			diags = diags.Append(m.replaceLockedDependencies(locks))
+		}
+	}
+
 	return false, diags
 }
 
+// sameModuleKeys returns true if the given locks both have module locks for
+// exactly the same set of module calls.
+func sameModuleKeys(a, b *depsfile.Locks) bool {
+	aMods, bMods := a.AllModules(), b.AllModules()
+	if len(aMods) != len(bMods) {
+		return false
+	}
+	for path := range aMods {
+		if _, ok := bMods[path]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // initDirFromModule initializes the given directory (which should be
 // pre-verified as empty by the caller) by copying the source code from the
 // given module address.
@@ -419,6 +485,12 @@ func (m *Meta) modulesDir() string {
 	return filepath.Join(m.DataDir(), "modules")
 }
 
+// moduleCacheDir returns the directory that holds the plan results saved by
+// "tofu plan -reuse-unchanged-modules", with one file per workspace.
+func (m *Meta) moduleCacheDir() string {
+	return filepath.Join(m.DataDir(), "module-cache")
+}
+
 // registerSynthConfigSource allows commands to add synthetic additional source
 // buffers to the config loader's cache of sources (as returned by
 // configSources), which is useful when a command is directly parsing something
@@ -464,6 +536,51 @@ func (m *Meta) initConfigLoader() (*configload.Loader, error) {
 	return m.configLoader, nil
 }
 
+// initConfigLoaderFromBundle initializes the shared configuration loader to
+// read the configuration only from the configuration bundle at the given
+// path, or from stdin if the path is "-", instead of from the working
+// directory.
+//
+// This must be called before anything else loads configuration. Other files,
+// such as variable definitions files and the dependency lock file, are still
+// read from the working directory.
+func (m *Meta) initConfigLoaderFromBundle(path string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read configuration bundle",
+				fmt.Sprintf("Could not read the configuration bundle: %s.", err),
+			))
+			return diags
+		}
+		defer f.Close()
+		r = f
+	}
+
+	snap, err := configload.ReadBundle(r)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read configuration bundle",
+			fmt.Sprintf("Could not read the configuration bundle: %s.", err),
+		))
+		return diags
+	}
+
+	loader := configload.NewLoaderFromSnapshot(snap)
+	loader.AllowLanguageExperiments(m.AllowExperimentalFeatures)
+	m.configLoader = loader
+	if m.View != nil {
+		m.View.SetConfigSources(loader.Sources)
+	}
+	return diags
+}
+
 // registryClient instantiates and returns a new Registry client.
 func (m *Meta) registryClient() *registry.Client {
 	return registry.NewClient(m.Services, nil)
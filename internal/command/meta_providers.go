@@ -13,18 +13,25 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	terraformProvider "github.com/opentofu/opentofu/internal/builtin/providers/tf"
+	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
 	tfplugin "github.com/opentofu/opentofu/internal/plugin"
 	tfplugin6 "github.com/opentofu/opentofu/internal/plugin6"
 	"github.com/opentofu/opentofu/internal/providercache"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 var errUnsupportedProtocolVersion = errors.New("unsupported protocol version")
@@ -36,6 +43,65 @@ var errUnsupportedProtocolVersion = errors.New("unsupported protocol version")
 // This is not intended to be set by end-users.
 var enableProviderAutoMTLS = os.Getenv("TF_DISABLE_PLUGIN_TLS") == ""
 
+// pluginKeepaliveEnvVar is the environment variable that sets how often
+// OpenTofu checks that the connection to a plugin is still alive while it
+// waits for a call to the plugin to complete, or disables those checks if
+// it's set to zero.
+const pluginKeepaliveEnvVar = "TF_PLUGIN_KEEPALIVE"
+
+// defaultPluginKeepalive is how often OpenTofu checks the connection to a
+// plugin by default. Plugins built with go-plugin close the connection to
+// clients that check more often than every five minutes.
+const defaultPluginKeepalive = 5 * time.Minute
+
+// pluginKeepaliveTimeout is how long OpenTofu waits for a plugin to
+// acknowledge a keepalive check before treating the connection as lost.
+const pluginKeepaliveTimeout = 30 * time.Second
+
+// pluginDialOptions returns the gRPC options for connecting to plugins, which
+// configure keepalive checks according to the TF_PLUGIN_KEEPALIVE
+// environment variable.
+//
+// The checks allow OpenTofu to notice a connection that was lost during a
+// long-running call, such as over a container boundary, rather than waiting
+// for the call forever.
+func pluginDialOptions() []grpc.DialOption {
+	interval, err := pluginKeepaliveInterval(os.Getenv(pluginKeepaliveEnvVar))
+	if err != nil {
+		log.Printf("[WARN] Ignoring invalid %s: %s", pluginKeepaliveEnvVar, err)
+		interval = defaultPluginKeepalive
+	}
+	if interval == 0 {
+		return nil
+	}
+	return []grpc.DialOption{
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    interval,
+			Timeout: pluginKeepaliveTimeout,
+		}),
+	}
+}
+
+// pluginKeepaliveInterval parses the value of the TF_PLUGIN_KEEPALIVE
+// environment variable, which is a duration such as "10m", or zero to
+// disable keepalive checks. An empty value selects the default.
+func pluginKeepaliveInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultPluginKeepalive, nil
+	}
+	if raw == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return interval, nil
+}
+
 // providerInstaller returns an object that knows how to install providers and
 // how to recover the selections from a prior installation process.
 //
@@ -81,6 +147,7 @@ func (m *Meta) providerInstallerCustomSource(source getproviders.Source) *provid
 		unmanagedProviderTypes[ty] = struct{}{}
 	}
 	inst.SetUnmanagedProviderTypes(unmanagedProviderTypes)
+	inst.SetPlatformFallbacks(m.ProviderPlatformFallbacks)
 	return inst
 }
 
@@ -120,6 +187,26 @@ func (m *Meta) providerLocalCacheDir() *providercache.Dir {
 	return providercache.NewDir(dir)
 }
 
+// installedProviderProvenance returns the recorded provenance of the
+// provider packages selected in the dependency lock file and installed in
+// the local cache directory. Providers without a provenance record, such as
+// those installed by an older version of OpenTofu, are omitted.
+func (m *Meta) installedProviderProvenance(locks *depsfile.Locks) map[addrs.Provider]*providercache.Provenance {
+	ret := make(map[addrs.Provider]*providercache.Provenance)
+	cacheDir := m.providerLocalCacheDir()
+	for addr, lock := range locks.AllProviders() {
+		provenance, err := cacheDir.ProviderProvenance(addr, lock.Version())
+		if err != nil {
+			log.Printf("[WARN] Failed to read the provenance of %s: %s", addr, err)
+			continue
+		}
+		if provenance != nil {
+			ret[addr] = provenance
+		}
+	}
+	return ret
+}
+
 // providerGlobalCacheDir returns an object representing the shared global
 // provider cache directory, used as a read-through cache when installing
 // new provider plugin packages.
@@ -138,6 +225,21 @@ func (m *Meta) providerGlobalCacheDir() *providercache.Dir {
 	return providercache.NewDir(dir)
 }
 
+// providerSchemaCacheDir returns the on-disk cache of provider schemas, or
+// nil if schemas aren't cached across runs.
+//
+// The cache is in the directory set by plugin_schema_cache_dir in the CLI
+// configuration, or otherwise in the global plugin cache directory, if any.
+func (m *Meta) providerSchemaCacheDir() *providercache.SchemaCacheDir {
+	if m.PluginSchemaCacheDir != "" {
+		return providercache.NewSchemaCacheDirAt(m.PluginSchemaCacheDir)
+	}
+	if globalCacheDir := m.providerGlobalCacheDir(); globalCacheDir != nil {
+		return providercache.NewSchemaCacheDir(globalCacheDir)
+	}
+	return nil
+}
+
 // providerInstallSource returns an object that knows how to consult one or
 // more external sources to determine the availability of and package
 // locations for versions of OpenTofu providers that are available for
@@ -221,6 +323,57 @@ func (m *Meta) providerDevOverrideRuntimeWarnings() tfdiags.Diagnostics {
 	}
 }
 
+// providerPackages describes the provider packages that providerFactories
+// uses, so that a saved plan can record them and applying the plan can check
+// that it uses the same ones.
+//
+// Providers whose package isn't in the local cache directory are left out,
+// because providerFactories reports those when they're used.
+func (m *Meta) providerPackages() (planfile.ProviderPackages, tfdiags.Diagnostics) {
+	if m.testingOverrides != nil {
+		// The providers in tests aren't installed from packages.
+		return nil, nil
+	}
+
+	locks, diags := m.lockedDependencies()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	cacheDir := m.providerLocalCacheDir()
+	ret := make(planfile.ProviderPackages)
+	for provider, lock := range locks.AllProviders() {
+		if locks.ProviderIsOverridden(provider) {
+			continue
+		}
+		version := lock.Version()
+		cached := cacheDir.ProviderVersion(provider, version)
+		if cached == nil {
+			continue
+		}
+		hash, err := cached.Hash()
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to calculate provider package checksum",
+				fmt.Sprintf("Could not calculate the checksum of the %s %s package cached in %s, to record it in the plan: %s.", provider.ForDisplay(), version, cacheDir.BasePath(), err),
+			))
+			continue
+		}
+		ret[provider] = planfile.ProviderPackage{
+			Version: version,
+			Hash:    hash,
+		}
+	}
+	for provider := range m.ProviderDevOverrides {
+		ret[provider] = planfile.ProviderPackage{Development: true}
+	}
+	for provider := range m.UnmanagedProviders {
+		ret[provider] = planfile.ProviderPackage{Development: true}
+	}
+	return ret, diags
+}
+
 // providerFactories uses the selections made previously by an installer in
 // the local cache directory (m.providerLocalCacheDir) to produce a map
 // from provider addresses to factory functions to create instances of
@@ -232,6 +385,32 @@ func (m *Meta) providerDevOverrideRuntimeWarnings() tfdiags.Diagnostics {
 // package have been modified outside of the installer. If it returns an error,
 // the returned map may be incomplete or invalid, but will be as complete
 // as possible given the cause of the error.
+// providerFunctions returns the object used to call provider functions outside
+// of a graph walk, such as during static evaluation and in the console. It's
+// created on first use, and any providers it starts are shut down along with
+// all other plugins when OpenTofu exits, if not closed earlier.
+func (m *Meta) providerFunctions() (*tofu.ProviderFunctions, error) {
+	if m.providerFunctionsCache != nil {
+		return m.providerFunctionsCache, nil
+	}
+
+	var factories map[addrs.Provider]providers.Factory
+	if m.testingOverrides != nil {
+		factories = m.testingOverrides.Providers
+	} else {
+		var err error
+		factories, err = m.providerFactories()
+		if factories == nil {
+			return nil, err
+		}
+		// Any other errors are reported by the individual factories if the
+		// affected providers are actually used.
+	}
+
+	m.providerFunctionsCache = tofu.NewProviderFunctions(factories)
+	return m.providerFunctionsCache, nil
+}
+
 func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error) {
 	locks, diags := m.lockedDependencies()
 	if diags.HasErrors() {
@@ -251,6 +430,10 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 	providerLocks := locks.AllProviders()
 	cacheDir := m.providerLocalCacheDir()
 
+	// Provider schemas are cached on disk if possible, so that later
+	// OpenTofu processes can use them without starting the provider.
+	schemaCacheDir := m.providerSchemaCacheDir()
+
 	// The internal providers are _always_ available, even if the configuration
 	// doesn't request them, because they don't need any special installation
 	// and they'll just be ignored if not used.
@@ -323,7 +506,17 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 				continue
 			}
 		}
-		factories[provider] = providerFactory(cached)
+		factory := providerFactory(cached)
+		if hashes := lock.PreferredHashes(); schemaCacheDir != nil && len(hashes) != 0 {
+			if _, ok := providers.SchemaCache.Get(provider); !ok {
+				if schema, ok := schemaCacheDir.Get(provider, version, hashes); ok {
+					providers.SchemaCache.Set(provider, schema)
+				} else {
+					factory = schemaCachingProviderFactory(factory, schemaCacheDir, provider, version, hashes)
+				}
+			}
+		}
+		factories[provider] = factory
 	}
 	for provider, localDir := range devOverrideProviders {
 		factories[provider] = devOverrideProviderFactory(provider, localDir)
@@ -367,6 +560,7 @@ func providerFactory(meta *providercache.CachedProvider) providers.Factory {
 			VersionedPlugins: tfplugin.VersionedPlugins,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", meta.Provider)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Provider)),
+			GRPCDialOptions:  append(pluginDialOptions(), providerMetricsDialOption(meta.Provider)),
 		}
 
 		client := plugin.NewClient(config)
@@ -390,6 +584,29 @@ func providerFactory(meta *providercache.CachedProvider) providers.Factory {
 	}
 }
 
+// schemaCachingProviderFactory wraps the given provider factory so that the
+// schema of the first provider instance it produces is saved in the given
+// schema cache directory, for use by later OpenTofu processes.
+func schemaCachingProviderFactory(factory providers.Factory, cacheDir *providercache.SchemaCacheDir, provider addrs.Provider, version getproviders.Version, hashes []getproviders.Hash) providers.Factory {
+	var once sync.Once
+	return func() (providers.Interface, error) {
+		p, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		once.Do(func() {
+			// The provider caches its schema in memory, so asking for it
+			// here doesn't cause any extra work when OpenTofu Core asks
+			// for it later.
+			resp := p.GetProviderSchema()
+			if err := cacheDir.Set(provider, version, hashes, resp); err != nil {
+				log.Printf("[WARN] Failed to cache the schema for %s %s: %s", provider, version, err)
+			}
+		})
+		return p, nil
+	}
+}
+
 // initializeProviderInstance uses the plugin dispensed by the RPC client, and initializes a plugin instance
 // per the protocol version
 func initializeProviderInstance(plugin interface{}, protoVer int, pluginClient *plugin.Client, pluginAddr addrs.Provider) (providers.Interface, error) {
@@ -436,6 +653,7 @@ func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.Reattach
 			Reattach:         reattach,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", provider)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", provider)),
+			GRPCDialOptions:  append(pluginDialOptions(), providerMetricsDialOption(provider)),
 		}
 
 		if reattach.ProtocolVersion == 0 {
@@ -14,6 +14,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/configs"
@@ -121,6 +122,19 @@ func (m *Meta) collectVariableValues() (map[string]backend.UnparsedVariableValue
 			moreDiags := m.addVarsFromFile(rawFlag.Value, tofu.ValueFromNamedFile, ret)
 			diags = diags.Append(moreDiags)
 
+		case "-var-file-merge":
+			// Values from this file are merged into whatever was set by
+			// the sources before it, rather than replacing them.
+			layer := map[string]backend.UnparsedVariableValue{}
+			moreDiags := m.addVarsFromFile(rawFlag.Value, tofu.ValueFromNamedFile, layer)
+			diags = diags.Append(moreDiags)
+			for name, v := range layer {
+				if base, exists := ret[name]; exists {
+					v = unparsedVariableValueMerged{base: base, overlay: v}
+				}
+				ret[name] = v
+			}
+
 		default:
 			// Should never happen; always a bug in the code that built up
 			// the contents of m.variableArgs.
@@ -283,6 +297,63 @@ func (v unparsedVariableValueExpression) ParseVariableValue(mode configs.Variabl
 	}, diags
 }
 
+// unparsedVariableValueMerged is a backend.UnparsedVariableValue
+// implementation that deep-merges the value from a file given with
+// -var-file-merge into the value that was set by an earlier source.
+type unparsedVariableValueMerged struct {
+	base    backend.UnparsedVariableValue
+	overlay backend.UnparsedVariableValue
+}
+
+func (v unparsedVariableValueMerged) ParseVariableValue(mode configs.VariableParsingMode) (*tofu.InputValue, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	base, moreDiags := v.base.ParseVariableValue(mode)
+	diags = diags.Append(moreDiags)
+	overlay, moreDiags := v.overlay.ParseVariableValue(mode)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		return overlay, diags
+	}
+
+	return &tofu.InputValue{
+		Value:       mergeVariableValues(base.Value, overlay.Value),
+		SourceType:  overlay.SourceType,
+		SourceRange: overlay.SourceRange,
+	}, diags
+}
+
+// mergeVariableValues deep-merges overlay into base, following the rules
+// documented for the -var-file-merge option:
+//   - If both values are objects or maps, the result has the attributes of
+//     both, and attributes present in both are merged recursively.
+//   - Otherwise, including for lists, sets and tuples, overlay replaces base.
+//     An explicit null in overlay therefore removes the earlier value.
+func mergeVariableValues(base, overlay cty.Value) cty.Value {
+	if base.IsNull() || overlay.IsNull() || !base.IsKnown() || !overlay.IsKnown() {
+		return overlay
+	}
+	if !isMergeableVariableType(base.Type()) || !isMergeableVariableType(overlay.Type()) {
+		return overlay
+	}
+
+	merged := base.AsValueMap()
+	if merged == nil {
+		merged = map[string]cty.Value{}
+	}
+	for k, ov := range overlay.AsValueMap() {
+		if bv, exists := merged[k]; exists {
+			ov = mergeVariableValues(bv, ov)
+		}
+		merged[k] = ov
+	}
+	return cty.ObjectVal(merged)
+}
+
+func isMergeableVariableType(ty cty.Type) bool {
+	return ty.IsObjectType() || ty.IsMapType()
+}
+
 // unparsedVariableValueString is a backend.UnparsedVariableValue
 // implementation that parses its value from a string. This can be used
 // to deal with values given directly on the command line and via environment
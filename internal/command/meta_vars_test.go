@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
@@ -76,3 +78,78 @@ func TestMeta_addVarsFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeVariableValues(t *testing.T) {
+	cases := map[string]struct {
+		base, overlay, want cty.Value
+	}{
+		"primitive replaced": {
+			base:    cty.StringVal("a"),
+			overlay: cty.StringVal("b"),
+			want:    cty.StringVal("b"),
+		},
+		"objects merged recursively": {
+			base: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+				"nested": cty.ObjectVal(map[string]cty.Value{
+					"x": cty.NumberIntVal(1),
+					"y": cty.NumberIntVal(2),
+				}),
+			}),
+			overlay: cty.ObjectVal(map[string]cty.Value{
+				"b": cty.StringVal("overlay"),
+				"nested": cty.ObjectVal(map[string]cty.Value{
+					"y": cty.NumberIntVal(3),
+				}),
+			}),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+				"b": cty.StringVal("overlay"),
+				"nested": cty.ObjectVal(map[string]cty.Value{
+					"x": cty.NumberIntVal(1),
+					"y": cty.NumberIntVal(3),
+				}),
+			}),
+		},
+		"map merged with object": {
+			base: cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+			}),
+			overlay: cty.ObjectVal(map[string]cty.Value{
+				"b": cty.StringVal("overlay"),
+			}),
+			want: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+				"b": cty.StringVal("overlay"),
+			}),
+		},
+		"lists replaced": {
+			base:    cty.TupleVal([]cty.Value{cty.StringVal("a")}),
+			overlay: cty.TupleVal([]cty.Value{cty.StringVal("b")}),
+			want:    cty.TupleVal([]cty.Value{cty.StringVal("b")}),
+		},
+		"object replaced by primitive": {
+			base: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+			}),
+			overlay: cty.StringVal("b"),
+			want:    cty.StringVal("b"),
+		},
+		"null replaces": {
+			base: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("base"),
+			}),
+			overlay: cty.NullVal(cty.DynamicPseudoType),
+			want:    cty.NullVal(cty.DynamicPseudoType),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := mergeVariableValues(tc.base, tc.overlay)
+			if !got.RawEquals(tc.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/command/jsonmetadata"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// MetadataDumpCommand is a Command implementation that prints out a
+// description of the modules of the configuration in the working directory,
+// for documentation generators.
+type MetadataDumpCommand struct {
+	Meta
+}
+
+func (c *MetadataDumpCommand) Help() string {
+	return metadataDumpCommandHelp
+}
+
+func (c *MetadataDumpCommand) Synopsis() string {
+	return "Show the modules, variables, outputs and resources of the configuration"
+}
+
+func (c *MetadataDumpCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("metadata dump")
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	c.Meta.varFlagSet(cmdFlags)
+
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	if !jsonOutput {
+		c.Ui.Error(
+			"The `tofu metadata dump` command requires the `-json` flag.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	configPath, err := modulePath(cmdFlags.Args())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	config, configDiags := c.loadConfig(configPath)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	out, err := jsonmetadata.Marshal(config)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to marshal configuration metadata",
+			err.Error(),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Warnings go to stderr, so they don't invalidate the JSON output.
+	c.showDiagnostics(diags)
+	c.Ui.Output(string(out))
+	return 0
+}
+
+const metadataDumpCommandHelp = `
+Usage: tofu [global options] metadata dump -json [options]
+
+  Prints out a json representation of the modules of the configuration in
+  the current directory, including their input variables, output values,
+  local values, resources and module calls, and the references between them.
+
+  The configuration's modules must be installed with "tofu init" first.
+
+Options:
+
+  -var 'foo=bar'         Set a variable in the OpenTofu configuration, for
+                         use in module sources and versions. This flag can
+                         be set multiple times.
+
+  -var-file=foo          Set variables in the OpenTofu configuration from
+                         a file. If "terraform.tfvars" or any ".auto.tfvars"
+                         files are present, they will be automatically loaded.
+`
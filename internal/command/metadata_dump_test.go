@@ -0,0 +1,78 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/jsonmetadata"
+)
+
+func TestMetadataDump_error(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("metadata-dump"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &MetadataDumpCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	// This test will always error because it's missing the -json flag
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("expected error, got:\n%s", ui.OutputWriter.String())
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "requires the `-json` flag") {
+		t.Fatalf("wrong error\n%s", got)
+	}
+}
+
+func TestMetadataDump_output(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("metadata-dump"), td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &MetadataDumpCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	if code := c.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, ui.ErrorWriter.String())
+	}
+
+	var got jsonmetadata.Metadata
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FormatVersion != jsonmetadata.FormatVersion {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	if v := got.RootModule.Variables["name"]; v == nil || v.Description != "The name of the instance." {
+		t.Errorf("wrong root module variable %#v", v)
+	}
+
+	call := got.RootModule.ModuleCalls["child"]
+	if call == nil || call.Module == nil {
+		t.Fatalf("missing module call %#v", call)
+	}
+	if diff := cmp.Diff([]string{"var.name"}, call.Inputs["name"].References); diff != "" {
+		t.Errorf("wrong references for the module call input\n%s", diff)
+	}
+	if len(call.Module.Resources) != 1 || call.Module.Resources[0].Address != "test_instance.foo" {
+		t.Errorf("wrong resources in the child module %#v", call.Module.Resources)
+	}
+}
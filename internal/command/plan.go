@@ -6,9 +6,13 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/opentofu/opentofu/internal/attestation"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
@@ -39,6 +43,7 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	args, diags := arguments.ParsePlan(rawArgs)
 
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetCompactChanges(args.Compact)
 
 	// Instantiate the view, even if there are flag errors, so that we render
 	// diagnostics according to the desired view
@@ -62,6 +67,11 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// operation, but there is no clear path to pass this value down, so we
 	// continue to mutate the Meta object state for now.
 	c.Meta.input = args.InputEnabled
+	if args.ConfigFrom == "-" {
+		// stdin carries the configuration bundle, so it can't also be used
+		// to answer prompts.
+		c.Meta.input = false
+	}
 
 	// FIXME: the -parallelism flag is used to control the concurrency of
 	// OpenTofu operations. At the moment, this value is used both to
@@ -70,12 +80,23 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.refreshConcurrency = args.Operation.RefreshConcurrency
 
 	diags = diags.Append(c.providerDevOverrideRuntimeWarnings())
 
 	// Inject variables from args into meta for static evaluation
 	c.GatherVariables(args.Vars)
 
+	// A configuration bundle replaces the configuration in the working
+	// directory, so it must be in place before anything loads configuration.
+	if args.ConfigFrom != "" {
+		diags = diags.Append(c.initConfigLoaderFromBundle(args.ConfigFrom))
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+	}
+
 	// Load the encryption configuration
 	enc, encDiags := c.Encryption()
 	diags = diags.Append(encDiags)
@@ -92,6 +113,18 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// Remote operations upload the working directory rather than the
+	// configuration loaded locally, so they can't use a bundle.
+	if rb, isRemoteBackend := be.(BackendWithRemoteTerraformVersion); args.ConfigFrom != "" && isRemoteBackend && !rb.IsLocalOperations() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Configuration bundle not supported",
+			"The -config-from option can't be used with a backend that runs operations remotely. Write the configuration to a directory instead, or configure the backend to use local operations.",
+		))
+		view.Diagnostics(diags)
+		return 1
+	}
+
 	// Build the operation request
 	opReq, opDiags := c.OperationRequest(be, view, args.ViewType, args.Operation, args.OutPath, args.GenerateConfigPath, enc)
 	diags = diags.Append(opDiags)
@@ -99,6 +132,12 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		view.Diagnostics(diags)
 		return 1
 	}
+	if args.ReuseUnchangedModules {
+		opReq.ModuleCachePath = filepath.Join(c.moduleCacheDir(), opReq.Workspace+".json")
+	}
+	opReq.Incremental = args.Incremental
+	opReq.StateVersion = args.StateVersion
+	opReq.ReviewDrift = args.ReviewDrift
 
 	// Before we delegate to the backend, we'll print any warning diagnostics
 	// we've accumulated here, since the backend will start fresh with its own
@@ -107,6 +146,7 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	diags = nil
 
 	// Perform the operation
+	started := time.Now()
 	op, diags := c.RunOperation(ctx, be, opReq)
 	view.Diagnostics(diags)
 	if diags.HasErrors() {
@@ -116,6 +156,14 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	if op.Result != backend.OperationSuccess {
 		return op.Result.ExitStatus()
 	}
+
+	if args.Attestation != "" {
+		diags = c.attestPlan(ctx, args, opReq.Workspace, op, started)
+		view.Diagnostics(diags)
+		if diags.HasErrors() {
+			return 1
+		}
+	}
 	if args.DetailedExitCode && !op.PlanEmpty {
 		return 2
 	}
@@ -123,6 +171,36 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	return op.Result.ExitStatus()
 }
 
+// attestPlan writes an attestation of the plan saved by the given operation,
+// as requested by the -attestation option.
+func (c *PlanCommand) attestPlan(ctx context.Context, args *arguments.Plan, workspace string, op *backend.RunningOperation, started time.Time) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	planFile, err := fileDescriptor("plan", args.OutPath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read saved plan",
+			fmt.Sprintf("OpenTofu could not read the saved plan to create its attestation: %s.", err),
+		))
+		return diags
+	}
+	return c.writeAttestation(ctx, args.Attestation, args.AttestationKey, &attestation.Run{
+		Command: "plan",
+		Parameters: map[string]any{
+			"workspace": workspace,
+		},
+		Subjects: []attestation.ResourceDescriptor{planFile},
+		Byproducts: []attestation.ResourceDescriptor{
+			{
+				Name:        "result",
+				Annotations: map[string]any{"changes": !op.PlanEmpty},
+			},
+		},
+		StartedOn:  started,
+		FinishedOn: time.Now(),
+	})
+}
+
 func (c *PlanCommand) PrepareBackend(args *arguments.State, viewType arguments.ViewType, enc encryption.Encryption) (backend.Enhanced, tfdiags.Diagnostics) {
 	// FIXME: we need to apply the state arguments to the meta object here
 	// because they are later used when initializing the backend. Carving a
@@ -169,10 +247,21 @@ func (c *PlanCommand) OperationRequest(
 	opReq.GenerateConfigOut = generateConfigOut
 	opReq.Targets = args.Targets
 	opReq.Excludes = args.Excludes
+	opReq.TargetGroups = args.TargetGroups
+	opReq.TargetStrict = args.TargetStrict
 	opReq.ForceReplace = args.ForceReplace
+	opReq.StrictVariables = args.StrictVariables
 	opReq.Type = backend.OperationTypePlan
 	opReq.View = view.Operation()
 
+	// A saved plan records the provider packages it was created with, so
+	// that applying it can check that it uses the same ones.
+	if planOutPath != "" {
+		var moreDiags tfdiags.Diagnostics
+		opReq.ProviderPackages, moreDiags = c.providerPackages()
+		diags = diags.Append(moreDiags)
+	}
+
 	var err error
 	opReq.ConfigLoader, err = c.initConfigLoader()
 	if err != nil {
@@ -226,6 +315,12 @@ Plan Customization Options:
                       most recent OpenTofu apply but does not propose any
                       actions to undo any changes made outside of OpenTofu.
 
+  -drift-only         Select the "drift only" planning mode, which reports
+                      how remote objects differ from the outcome of the most
+                      recent OpenTofu apply without evaluating any changes
+                      from the configuration. A drift-only plan cannot be
+                      saved or applied.
+
   -refresh=false      Skip checking for external changes to remote objects
                       while creating the plan. This can potentially make
                       planning faster, but at the expense of possibly planning
@@ -250,6 +345,16 @@ Plan Customization Options:
                       This is for exceptional use only. Cannot be used alongside
                       the -target flag
 
+  -target-group=name  Limit the planning operation to the resources whose
+                      lifecycle blocks assign them to the given group, and
+                      all of their dependencies. You can use this option
+                      multiple times to include more than one group. Cannot
+                      be used alongside the -exclude flag.
+
+  -target-strict      Fail instead of planning changes for resources that
+                      the -target and -target-group options don't select,
+                      but that the targeted resources need.
+
   -var 'foo=bar'      Set a value for one of the input variables in the root
                       module of the configuration. Use this option more than
                       once to set more than one variable.
@@ -259,12 +364,43 @@ Plan Customization Options:
                       Use this option more than once to include more than one
                       variables file.
 
+  -var-file-merge=filename
+                      Like -var-file, but object and map values in the
+                      file are deep-merged into the values set by the
+                      options and files before it, instead of replacing
+                      them.
+
+  -strict-vars        Report values for undeclared variables in variables
+                      files as errors instead of warnings, to catch misspelled
+                      variable names.
+
 Other Options:
 
+  -attestation=dest          Write a provenance attestation of the plan saved
+                             with -out to the given file, or push it to an OCI
+                             registry if dest is an "oci://" reference.
+
+  -attestation-key=path      Sign the attestation with the PEM-encoded private
+                             key in the given file.
+
+  -compact                   Show the first of the identical changes to the
+                             instances of a resource in full, and summarize
+                             the others in a single line.
+
   -compact-warnings          If OpenTofu produces any warnings that are not
                              accompanied by errors, shows them in a more compact
                              form that includes only the summary messages.
 
+  -config-from=path          Plan the configuration in the given configuration
+                             bundle instead of the configuration in the working
+                             directory. A bundle is a JSON document containing
+                             the source code of the root module and of every
+                             module it calls, typically generated by external
+                             tooling. Use "-" to read the bundle from stdin.
+                             Variable definitions files, the dependency lock
+                             file and providers are still read from the
+                             working directory.
+
   -consolidate-warnings      If OpenTofu produces any warnings, no consolodation
                              will be performed. All locations, for all warnings
                              will be listed. Enabled by default.
@@ -286,6 +422,13 @@ Other Options:
                              which must not already exist. OpenTofu may still
                              attempt to write configuration if the plan errors.
 
+  -incremental               Skip refreshing and planning the resources of
+                             modules that haven't changed since the last
+                             applied incremental plan, according to the module
+                             fingerprints recorded in the state. Changes made
+                             outside of OpenTofu to those resources are not
+                             detected.
+
   -input=true                Ask for input for variables if not directly set.
 
   -lock=false                Don't hold a state lock during the operation. This
@@ -305,10 +448,31 @@ Other Options:
   -parallelism=n             Limit the number of concurrent operations. Defaults
                              to 10.
 
+  -refresh-concurrency=n     Limit the number of concurrent refresh requests to
+                             each provider configuration, instead of counting
+                             them against -parallelism.
+
+  -reuse-unchanged-modules   Reuse the results of the previous plan for modules
+                             whose configuration, input values, providers and
+                             prior state haven't changed since, instead of
+                             planning their resources again. Requires
+                             -refresh=false.
+
   -state=statefile           A legacy option used for the local backend only.
                              See the local backend's documentation for more
                              information.
 
+  -state-version=version     Plan against an earlier version of the state,
+                             selected by its ID or, as an RFC 3339 timestamp,
+                             the latest version written at or before that
+                             time. Requires state storage that keeps earlier
+                             versions, and can't be used with -out.
+
+  -review-drift              Ask, for each resource instance that was deleted
+                             outside of OpenTofu, whether to create it again,
+                             forget it, or fail the plan. Requires
+                             interactive input.
+
   -show-sensitive            If specified, sensitive values will be displayed.
 
   -json                      Produce output in a machine-readable JSON format, 
@@ -0,0 +1,370 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// PlanSummaryCommand is a Command implementation that summarizes the saved
+// plans of several root modules, such as those planned separately for each
+// directory of a repository, in a single report.
+type PlanSummaryCommand struct {
+	Meta
+}
+
+// planSummaryFormatVersion is the version of the JSON report format. It
+// follows the same rules as the format versions of the other JSON outputs.
+const planSummaryFormatVersion = "1.0"
+
+// planSummary is the JSON report of "tofu plan summary".
+type planSummary struct {
+	FormatVersion string            `json:"format_version"`
+	Roots         []planSummaryRoot `json:"roots"`
+	Total         planSummaryCounts `json:"total"`
+
+	// RootsWithChanges is the number of roots whose plans can be applied.
+	RootsWithChanges int `json:"roots_with_changes"`
+}
+
+// planSummaryRoot summarizes the saved plan of one root module. It includes
+// the plan file's path and checksum so that a caller can find and verify the
+// plan to apply for each root.
+type planSummaryRoot struct {
+	Name            string              `json:"name"`
+	PlanFile        string              `json:"plan_file"`
+	SHA256          string              `json:"sha256"`
+	Mode            string              `json:"mode"`
+	Errored         bool                `json:"errored"`
+	Applyable       bool                `json:"applyable"`
+	Changes         planSummaryCounts   `json:"changes"`
+	ResourceChanges []planSummaryChange `json:"resource_changes"`
+	OutputChanges   []string            `json:"output_changes"`
+}
+
+// planSummaryCounts counts the planned changes in the same way as the
+// summary at the end of "tofu plan".
+type planSummaryCounts struct {
+	Add    int `json:"add"`
+	Change int `json:"change"`
+	Import int `json:"import"`
+	Remove int `json:"remove"`
+	Forget int `json:"forget"`
+}
+
+type planSummaryChange struct {
+	Address string `json:"address"`
+	Action  string `json:"action"`
+}
+
+func (c *PlanSummaryCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var outPath string
+	var jsonOutput bool
+	cmdFlags := c.Meta.defaultFlagSet("plan summary")
+	cmdFlags.StringVar(&outPath, "out", "", "out")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) == 0 {
+		c.Ui.Error("At least one saved plan file must be given.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	enc, encDiags := c.Encryption()
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	summary, moreDiags := planSummarize(args, enc)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal plan summary to JSON: %s", err))
+		return 1
+	}
+	if outPath != "" {
+		if err := os.WriteFile(outPath, append(out, '\n'), 0644); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write plan summary",
+				fmt.Sprintf("OpenTofu could not write the plan summary to %s: %s.", outPath, err),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+
+	if jsonOutput {
+		c.Ui.Output(string(out))
+		return 0
+	}
+
+	c.showDiagnostics(diags)
+	c.showPlanSummary(summary)
+	return 0
+}
+
+// planSummarize reads the given saved plan files and summarizes their
+// changes. Each argument is either the path of a plan file, in which case the
+// root is named after the directory containing it, or NAME=PATH.
+func planSummarize(args []string, enc encryption.Encryption) (*planSummary, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	summary := &planSummary{
+		FormatVersion: planSummaryFormatVersion,
+		Roots:         make([]planSummaryRoot, 0, len(args)),
+	}
+	names := make(map[string]bool, len(args))
+	for _, arg := range args {
+		name, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			path = arg
+			name = filepath.ToSlash(filepath.Dir(path))
+		}
+		if names[name] {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Duplicate root name",
+				fmt.Sprintf("More than one plan file is named %q. Use the NAME=PATH form to give each plan file a unique name.", name),
+			))
+			continue
+		}
+		names[name] = true
+
+		root, err := planSummaryForFile(name, path, enc)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read plan file",
+				fmt.Sprintf("OpenTofu could not read the plan file for %s: %s.", name, err),
+			))
+			continue
+		}
+		summary.Roots = append(summary.Roots, *root)
+
+		summary.Total.Add += root.Changes.Add
+		summary.Total.Change += root.Changes.Change
+		summary.Total.Import += root.Changes.Import
+		summary.Total.Remove += root.Changes.Remove
+		summary.Total.Forget += root.Changes.Forget
+		if root.Applyable {
+			summary.RootsWithChanges++
+		}
+	}
+
+	sort.Slice(summary.Roots, func(i, j int) bool {
+		return summary.Roots[i].Name < summary.Roots[j].Name
+	})
+	return summary, diags
+}
+
+func planSummaryForFile(name, path string, enc encryption.Encryption) (*planSummaryRoot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+
+	pr, err := planfile.Open(path, enc.Plan())
+	if err != nil {
+		return nil, err
+	}
+	plan, err := pr.ReadPlan()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &planSummaryRoot{
+		Name:            name,
+		PlanFile:        filepath.ToSlash(path),
+		SHA256:          hex.EncodeToString(sum[:]),
+		Mode:            planSummaryMode(plan.UIMode),
+		Errored:         plan.Errored,
+		Applyable:       plan.CanApply(),
+		ResourceChanges: []planSummaryChange{},
+		OutputChanges:   []string{},
+	}
+	for _, change := range plan.Changes.Resources {
+		if change.Action == plans.Delete && change.Addr.Resource.Resource.Mode == addrs.DataResourceMode {
+			// Data sources are never shown as deleted in the plan output
+			continue
+		}
+
+		if change.Importing != nil {
+			root.Changes.Import++
+		}
+		switch change.Action {
+		case plans.Create:
+			root.Changes.Add++
+		case plans.Delete:
+			root.Changes.Remove++
+		case plans.Update:
+			root.Changes.Change++
+		case plans.CreateThenDelete, plans.DeleteThenCreate:
+			root.Changes.Add++
+			root.Changes.Remove++
+		case plans.Forget:
+			root.Changes.Forget++
+		}
+
+		if change.Action != plans.NoOp || change.Importing != nil {
+			root.ResourceChanges = append(root.ResourceChanges, planSummaryChange{
+				Address: change.Addr.String(),
+				Action:  planSummaryAction(change),
+			})
+		}
+	}
+	sort.Slice(root.ResourceChanges, func(i, j int) bool {
+		return root.ResourceChanges[i].Address < root.ResourceChanges[j].Address
+	})
+
+	for _, output := range plan.Changes.Outputs {
+		if output.Addr.Module.IsRoot() && output.Action != plans.NoOp {
+			root.OutputChanges = append(root.OutputChanges, output.Addr.OutputValue.Name)
+		}
+	}
+	sort.Strings(root.OutputChanges)
+
+	return root, nil
+}
+
+// planSummaryAction describes a planned change with the same action names as
+// the planned_change messages of the machine-readable UI.
+func planSummaryAction(change *plans.ResourceInstanceChangeSrc) string {
+	switch change.Action {
+	case plans.NoOp:
+		if change.Importing != nil {
+			return "import"
+		}
+		return "noop"
+	case plans.Create:
+		return "create"
+	case plans.Read:
+		return "read"
+	case plans.Update:
+		return "update"
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return "replace"
+	case plans.Delete:
+		return "delete"
+	case plans.Forget:
+		return "remove"
+	default:
+		return "noop"
+	}
+}
+
+func planSummaryMode(mode plans.Mode) string {
+	switch mode {
+	case plans.DestroyMode:
+		return "destroy"
+	case plans.RefreshOnlyMode:
+		return "refresh-only"
+	case plans.DriftOnlyMode:
+		return "drift-only"
+	default:
+		return "normal"
+	}
+}
+
+func (c *PlanSummaryCommand) showPlanSummary(summary *planSummary) {
+	for _, root := range summary.Roots {
+		var status string
+		switch {
+		case root.Errored:
+			status = "Plan failed, so its changes are incomplete."
+		case !root.Applyable:
+			status = "No changes."
+		default:
+			status = planSummaryCountsString(root.Changes) + "."
+		}
+		c.Ui.Output(fmt.Sprintf("%s: %s", root.Name, status))
+	}
+	c.Ui.Output("")
+	c.Ui.Output(fmt.Sprintf(
+		"Total: %s across %d roots, %d with changes.",
+		planSummaryCountsString(summary.Total), len(summary.Roots), summary.RootsWithChanges,
+	))
+}
+
+// planSummaryCountsString formats counts in the same way as the summary at the
+// end of "tofu plan".
+func planSummaryCountsString(counts planSummaryCounts) string {
+	var parts []string
+	if counts.Import > 0 {
+		parts = append(parts, fmt.Sprintf("%d to import", counts.Import))
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d to add", counts.Add),
+		fmt.Sprintf("%d to change", counts.Change),
+		fmt.Sprintf("%d to destroy", counts.Remove),
+	)
+	if counts.Forget > 0 {
+		parts = append(parts, fmt.Sprintf("%d to forget", counts.Forget))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *PlanSummaryCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] plan summary [options] PLAN...
+
+  Summarize the changes in the saved plans of several root modules, such as
+  the plans created for each directory of a repository, in a single report.
+
+  Each PLAN is the path of a plan file created by "tofu plan -out", and is
+  named after the directory containing it. Use NAME=PATH to give a plan file
+  a different name.
+
+  The report lists the number of changes in each plan and in all of them
+  together. Use -json or -out for a machine-readable report that also lists
+  the changed resources and output values of each plan, and the path and
+  SHA-256 checksum of each plan file.
+
+  The plan files are decrypted with the encryption configuration of the
+  current working directory, if any.
+
+Options:
+
+  -json        Print the report in JSON format.
+
+  -out=path    Write the report in JSON format to the given file, in addition
+               to printing it.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PlanSummaryCommand) Synopsis() string {
+	return "Summarize the saved plans of several root modules"
+}
@@ -0,0 +1,157 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+func TestPlanSummary(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	createPlan := showFixturePlanFile(t, plans.Create)
+	replacePlan := showFixturePlanFile(t, plans.DeleteThenCreate)
+	noopPlan := testPlanFileNoop(t)
+
+	ui := cli.NewMockUi()
+	c := &PlanSummaryCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	args := []string{
+		"-out=summary.json",
+		"prod=" + replacePlan,
+		"dev=" + createPlan,
+		"staging=" + noopPlan,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `dev: 1 to add, 0 to change, 0 to destroy.
+prod: 1 to add, 0 to change, 1 to destroy.
+staging: No changes.
+
+Total: 2 to add, 0 to change, 1 to destroy across 3 roots, 2 with changes.`
+	if got := strings.TrimSpace(ui.OutputWriter.String()); got != want {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	raw, err := os.ReadFile("summary.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got planSummary
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("invalid report: %s\n%s", err, raw)
+	}
+	wantSummary := planSummary{
+		FormatVersion: planSummaryFormatVersion,
+		Roots: []planSummaryRoot{
+			{
+				Name:            "dev",
+				PlanFile:        filepath.ToSlash(createPlan),
+				Mode:            "normal",
+				Applyable:       true,
+				Changes:         planSummaryCounts{Add: 1},
+				ResourceChanges: []planSummaryChange{{Address: "test_instance.foo", Action: "create"}},
+				OutputChanges:   []string{},
+			},
+			{
+				Name:            "prod",
+				PlanFile:        filepath.ToSlash(replacePlan),
+				Mode:            "normal",
+				Applyable:       true,
+				Changes:         planSummaryCounts{Add: 1, Remove: 1},
+				ResourceChanges: []planSummaryChange{{Address: "test_instance.foo", Action: "replace"}},
+				OutputChanges:   []string{},
+			},
+			{
+				Name:            "staging",
+				PlanFile:        filepath.ToSlash(noopPlan),
+				Mode:            "normal",
+				ResourceChanges: []planSummaryChange{},
+				OutputChanges:   []string{},
+			},
+		},
+		Total:            planSummaryCounts{Add: 2, Remove: 1},
+		RootsWithChanges: 2,
+	}
+	if diff := cmp.Diff(wantSummary, got, cmpopts.IgnoreFields(planSummaryRoot{}, "SHA256")); diff != "" {
+		t.Fatalf("wrong report\n%s", diff)
+	}
+	for _, root := range got.Roots {
+		if len(root.SHA256) != 64 {
+			t.Errorf("wrong checksum for %s: %q", root.Name, root.SHA256)
+		}
+	}
+}
+
+func TestPlanSummary_rootNames(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	// Without NAME=, each plan is named after its directory, so two plans
+	// in the same directory have the same name.
+	plan, err := os.ReadFile(testPlanFileNoop(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"envs/dev", "envs/prod"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "tfplan"), plan, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ui := cli.NewMockUi()
+	c := &PlanSummaryCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"-json", "envs/prod/tfplan", "envs/dev/tfplan"}); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+	var got planSummary
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid report: %s\n%s", err, ui.OutputWriter.String())
+	}
+	var names []string
+	for _, root := range got.Roots {
+		names = append(names, root.Name)
+	}
+	if diff := cmp.Diff([]string{"envs/dev", "envs/prod"}, names); diff != "" {
+		t.Fatalf("wrong root names\n%s", diff)
+	}
+
+	ui = cli.NewMockUi()
+	c = &PlanSummaryCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"envs/dev/tfplan", "envs/dev/tfplan"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Duplicate root name"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -8,24 +8,29 @@ package command
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/attestation"
 	backendinit "github.com/opentofu/opentofu/internal/backend/init"
 	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -327,6 +332,63 @@ func TestPlan_outPath(t *testing.T) {
 	testReadPlan(t, outPath) // will call t.Fatal itself if the file cannot be read
 }
 
+func TestPlan_attestation(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	outPath := filepath.Join(td, "test.plan")
+	attestationPath := filepath.Join(td, "test.intoto.json")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-out", outPath,
+		"-attestation", attestationPath,
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	if !strings.Contains(output.All(), "Attestation is not signed") {
+		t.Errorf("missing warning about the unsigned attestation\n%s", output.All())
+	}
+
+	src, err := os.ReadFile(attestationPath)
+	if err != nil {
+		t.Fatalf("attestation was not written: %s", err)
+	}
+	var env attestation.Envelope
+	if err := json.Unmarshal(src, &env); err != nil {
+		t.Fatalf("invalid attestation: %s", err)
+	}
+	statement, err := env.Statement()
+	if err != nil {
+		t.Fatalf("invalid attestation payload: %s", err)
+	}
+	planSrc, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []attestation.ResourceDescriptor{
+		{Name: "plan", Digest: attestation.SHA256Digest(planSrc)},
+	}
+	if diff := cmp.Diff(want, statement.Subject); diff != "" {
+		t.Errorf("wrong subject\n%s", diff)
+	}
+	if got, want := statement.Predicate.RunDetails.Byproducts[0].Annotations["changes"], true; got != want {
+		t.Errorf("wrong changes annotation %#v; want %#v", got, want)
+	}
+}
+
 func TestPlan_outPathNoChange(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -1121,6 +1183,57 @@ func TestPlan_varFile(t *testing.T) {
 	}
 }
 
+func TestPlan_varFileStrict(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-vars"), td)
+	defer testChdir(t, td)()
+
+	varFilePath := testTempFile(t)
+	if err := os.WriteFile(varFilePath, []byte("foo = \"bar\"\nfo = \"baz\"\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := planVarsFixtureProvider()
+
+	// Without -strict-vars, the misspelled variable only produces a warning.
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+	code := c.Run([]string{"-no-color", "-var-file", varFilePath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	// The diagnostics are wrapped, so we compare them without line breaks.
+	unwrap := func(s string) string {
+		return strings.Join(strings.Fields(strings.ReplaceAll(s, "│", "")), " ")
+	}
+	if got, want := unwrap(output.Stdout()), `Did you mean "foo"?`; !strings.Contains(got, want) {
+		t.Errorf("missing suggestion in warning\ngot: %s", got)
+	}
+
+	view, done = testView(t)
+	c = &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+	code = c.Run([]string{"-no-color", "-strict-vars", "-var-file", varFilePath})
+	output = done(t)
+	if code != 1 {
+		t.Fatalf("expected failure, got %d\n\n%s", code, output.Stdout())
+	}
+	if got, want := unwrap(output.Stderr()), `The root module does not declare a variable named "fo"`; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot: %s", got)
+	}
+}
+
 func TestPlan_varFileDefault(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -1771,6 +1884,78 @@ func TestPlan_jsonGoldenReference(t *testing.T) {
 // planFixtureSchema returns a schema suitable for processing the
 // configuration in testdata/plan . This schema should be
 // assigned to a mock provider named "test".
+func TestPlan_configFromBundle(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-config-bundle"), td)
+	defer testChdir(t, td)()
+
+	outPath := filepath.Join(td, "saved.tfplan")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-config-from", "bundle.json",
+		"-var", "ami=bar",
+		"-out", outPath,
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	if got, want := output.Stdout(), "1 to add, 0 to change, 0 to destroy"; !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:\n%s\nwant to contain: %s", got, want)
+	}
+
+	// The saved plan must contain the configuration from the bundle, so that
+	// it can be applied without it.
+	f, err := planfile.Open(outPath, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("error opening plan file: %s", err)
+	}
+	snap, err := f.ReadConfigSnapshot()
+	if err != nil {
+		t.Fatalf("error reading config snapshot: %s", err)
+	}
+	if _, exists := snap.Modules["child"].Files["main.tf"]; !exists {
+		t.Errorf("config snapshot has no main.tf for module.child")
+	}
+}
+
+func TestPlan_configFromBundleInvalid(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	if err := os.WriteFile("bundle.json", []byte(`{"format_version": "2.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-config-from=bundle.json"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("unexpected success\n\n%s", output.Stdout())
+	}
+	if got, want := output.Stderr(), "Failed to read configuration bundle"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:\n%s\nwant to contain: %s", got, want)
+	}
+}
+
 func planFixtureSchema() *providers.GetProviderSchemaResponse {
 	return &providers.GetProviderSchemaResponse{
 		ResourceTypes: map[string]providers.Schema{
@@ -1968,3 +2153,98 @@ foo = "bar"
 variable "nope" {
 }
 `
+
+func TestPlan_incremental(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-incremental"), td)
+	defer testChdir(t, td)()
+
+	// The root resource needs a change, and the child module's doesn't.
+	providerAddr := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	testStateFileDefault(t, states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "root",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"root","ami":"old"}`),
+				Status:    states.ObjectReady,
+			},
+			providerAddr, addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "a",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance.Child("child", addrs.NoKey)),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"ami":"child","id":"a"}`),
+				Status:    states.ObjectReady,
+			},
+			providerAddr, addrs.NoKey,
+		)
+	}))
+
+	p := applyFixtureProvider()
+	var mu sync.Mutex
+	var refreshed []string
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		mu.Lock()
+		refreshed = append(refreshed, req.PriorState.GetAttr("id").AsString())
+		mu.Unlock()
+		return providers.ReadResourceResponse{NewState: req.PriorState}
+	}
+
+	plan := func(args ...string) {
+		t.Helper()
+		refreshed = nil
+		view, done := testView(t)
+		c := &PlanCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				View:             view,
+			},
+		}
+		if code := c.Run(append([]string{"-incremental"}, args...)); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, done(t).Stderr())
+		}
+		done(t)
+		sort.Strings(refreshed)
+	}
+
+	// There are no fingerprints in the state yet, so everything is refreshed.
+	plan("-out=tfplan")
+	if diff := cmp.Diff([]string{"a", "root"}, refreshed); diff != "" {
+		t.Fatalf("wrong refreshed resources in the first plan\n%s", diff)
+	}
+
+	view, done := testView(t)
+	apply := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+	if code := apply.Run([]string{"tfplan"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, done(t).Stderr())
+	}
+	done(t)
+
+	// Applying the plan recorded the fingerprint of the unchanged module.
+	state := testStateRead(t, DefaultStateFilename)
+	if _, ok := state.ModuleFingerprints["module.child"]; !ok {
+		t.Fatalf("no fingerprint for module.child in the state: %#v", state.ModuleFingerprints)
+	}
+
+	// The child module is still unchanged, so it isn't refreshed again.
+	plan()
+	if diff := cmp.Diff([]string{"root"}, refreshed); diff != "" {
+		t.Fatalf("wrong refreshed resources in the second plan\n%s", diff)
+	}
+}
@@ -142,6 +142,7 @@ func provisionerFactory(meta discovery.PluginMeta) provisioners.Factory {
 			AutoMTLS:         enableProviderAutoMTLS,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", meta.Name)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Name)),
+			GRPCDialOptions:  pluginDialOptions(),
 		}
 		client := plugin.NewClient(cfg)
 		return newProvisionerClient(client)
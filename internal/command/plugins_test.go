@@ -9,6 +9,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPluginPath(t *testing.T) {
@@ -47,3 +48,35 @@ func TestInternalProviders(t *testing.T) {
 		t.Errorf("didn't find terraform_remote_state in internal \"terraform\" provider")
 	}
 }
+
+func TestPluginKeepaliveInterval(t *testing.T) {
+	tests := map[string]struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		"default":  {raw: "", want: defaultPluginKeepalive},
+		"disabled": {raw: "0", want: 0},
+		"duration": {raw: "10m", want: 10 * time.Minute},
+		"invalid":  {raw: "often", wantErr: true},
+		"negative": {raw: "-1m", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := pluginKeepaliveInterval(test.raw)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("unexpected success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("wrong interval %s; want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -6,15 +6,22 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/xlab/treeprint"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providercache"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 // ProvidersCommand is a Command implementation that prints out information
@@ -33,11 +40,13 @@ func (c *ProvidersCommand) Synopsis() string {
 
 func (c *ProvidersCommand) Run(args []string) int {
 	var testsDirectory string
+	var jsonOutput bool
 
 	args = c.Meta.process(args)
 	cmdFlags := c.Meta.defaultFlagSet("providers")
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.StringVar(&testsDirectory, "test-directory", "tests", "test-directory")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -133,6 +142,10 @@ func (c *ProvidersCommand) Run(args []string) int {
 		stateReqs = state.ProviderRequirements()
 	}
 
+	if jsonOutput {
+		return c.outputJSON(config, state, diags)
+	}
+
 	printRoot := treeprint.New()
 	c.populateTreeNode(printRoot, reqs)
 
@@ -146,6 +159,10 @@ func (c *ProvidersCommand) Run(args []string) int {
 		}
 	}
 
+	if locks, locksDiags := c.lockedDependencies(); !locksDiags.HasErrors() {
+		c.outputProvenance(c.installedProviderProvenance(locks))
+	}
+
 	c.showDiagnostics(diags)
 	if diags.HasErrors() {
 		return 1
@@ -153,6 +170,147 @@ func (c *ProvidersCommand) Run(args []string) int {
 	return 0
 }
 
+// providersOutput is the machine-readable output of "tofu providers -json".
+type providersOutput struct {
+	FormatVersion string                     `json:"format_version"`
+	Providers     map[string]*providerOutput `json:"providers"`
+}
+
+type providerOutput struct {
+	VersionConstraint    string                    `json:"version_constraint,omitempty"`
+	RequiredCapabilities []string                  `json:"required_capabilities,omitempty"`
+	Capabilities         []string                  `json:"capabilities"`
+	Provenance           *providercache.Provenance `json:"provenance,omitempty"`
+}
+
+// outputJSON prints the providers that the configuration and state require,
+// along with the capabilities that the modules require of them and that the
+// installed providers announce.
+//
+// Finding the capabilities requires starting each of the providers to get
+// its schema, so unlike the default output this requires the providers to
+// be installed.
+func (c *ProvidersCommand) outputJSON(config *configs.Config, state *states.State, diags tfdiags.Diagnostics) int {
+	// providersFormatVersion is the version of the JSON format, which will
+	// be incremented for any change to it that requires changes to a
+	// consuming parser.
+	const providersFormatVersion = "1.0"
+
+	opts, err := c.contextOpts()
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	tfCtx, ctxDiags := tofu.NewContext(opts)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	schemas, schemaDiags := tfCtx.Schemas(config, state)
+	diags = diags.Append(schemaDiags)
+	if schemaDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	reqs, reqDiags := config.ProviderRequirements()
+	diags = diags.Append(reqDiags)
+	if reqDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	var provenance map[addrs.Provider]*providercache.Provenance
+	if locks, locksDiags := c.lockedDependencies(); !locksDiags.HasErrors() {
+		provenance = c.installedProviderProvenance(locks)
+	}
+
+	output := providersOutput{
+		FormatVersion: providersFormatVersion,
+		Providers:     make(map[string]*providerOutput, len(schemas.Providers)),
+	}
+	for addr, schema := range schemas.Providers {
+		output.Providers[addr.String()] = &providerOutput{
+			VersionConstraint: getproviders.VersionConstraintsString(reqs[addr]),
+			Capabilities:      schema.ServerCapabilities.Names(),
+			Provenance:        provenance[addr],
+		}
+	}
+	config.DeepEach(func(modCfg *configs.Config) {
+		if modCfg.Module.ProviderRequirements == nil {
+			return
+		}
+		for _, req := range modCfg.Module.ProviderRequirements.RequiredProviders {
+			p, ok := output.Providers[req.Type.String()]
+			if !ok {
+				continue
+			}
+			for _, capability := range req.Capabilities {
+				if !slices.Contains(p.RequiredCapabilities, capability) {
+					p.RequiredCapabilities = append(p.RequiredCapabilities, capability)
+				}
+			}
+			sort.Strings(p.RequiredCapabilities)
+		}
+	})
+
+	out, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("failed to marshal the providers to JSON: %w", err))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.Ui.Output(string(out))
+
+	// Any warnings go to stderr, so they don't break the JSON output.
+	c.showDiagnostics(diags)
+	return 0
+}
+
+// outputProvenance prints where each of the installed providers came from
+// and how it was verified, for supply-chain audits.
+func (c *ProvidersCommand) outputProvenance(provenance map[addrs.Provider]*providercache.Provenance) {
+	if len(provenance) == 0 {
+		return
+	}
+	providerAddrs := make([]addrs.Provider, 0, len(provenance))
+	for addr := range provenance {
+		providerAddrs = append(providerAddrs, addr)
+	}
+	sort.Slice(providerAddrs, func(i, j int) bool {
+		return providerAddrs[i].LessThan(providerAddrs[j])
+	})
+
+	c.Ui.Output("Installed provider packages:\n")
+	for _, addr := range providerAddrs {
+		p := provenance[addr]
+		c.Ui.Output(fmt.Sprintf("    provider[%s] %s (%s)", addr, p.Version, p.Platform))
+		if p.Source != "" {
+			c.Ui.Output(fmt.Sprintf("        source:       %s", p.Source))
+		}
+		if p.Location != "" {
+			c.Ui.Output(fmt.Sprintf("        location:     %s", p.Location))
+		}
+		if p.CacheDir != "" {
+			c.Ui.Output(fmt.Sprintf("        linked from:  %s", p.CacheDir))
+		}
+		switch {
+		case p.SigningKeyID != "":
+			c.Ui.Output(fmt.Sprintf("        verification: %s, key ID %s", p.Authentication, p.SigningKeyID))
+		case p.Authentication != "":
+			c.Ui.Output(fmt.Sprintf("        verification: %s", p.Authentication))
+		default:
+			c.Ui.Output("        verification: none")
+		}
+		for _, hash := range p.Hashes {
+			c.Ui.Output(fmt.Sprintf("        hash:         %s", hash))
+		}
+		c.Ui.Output("")
+	}
+}
+
 func (c *ProvidersCommand) populateTreeNode(tree treeprint.Tree, node *configs.ModuleRequirements) {
 	for fqn, dep := range node.Requirements {
 		versionsStr := getproviders.VersionConstraintsString(dep)
@@ -197,6 +355,11 @@ Usage: tofu [global options] providers [options] [DIR]
 
 Options:
 
+  -json                 Print the providers in a machine-readable JSON format,
+                        including the protocol capabilities that the installed
+                        providers announce and that the modules require. This
+                        requires the providers to be installed.
+
   -test-directory=path  Set the OpenTofu test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
@@ -9,8 +9,11 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/copy"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/providercache"
@@ -45,9 +48,13 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	var optPlatforms FlagStringSlice
 	var fsMirrorDir string
 	var netMirrorURL string
+	var exportBundleDir string
+	var mergeFiles string
 	cmdFlags.Var(&optPlatforms, "platform", "target platform")
 	cmdFlags.StringVar(&fsMirrorDir, "fs-mirror", "", "filesystem mirror directory")
 	cmdFlags.StringVar(&netMirrorURL, "net-mirror", "", "network mirror base URL")
+	cmdFlags.StringVar(&exportBundleDir, "export-bundle", "", "directory to export the locked packages to")
+	cmdFlags.StringVar(&mergeFiles, "merge", "", "lock files to merge")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -68,6 +75,19 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 
 	providerStrs := cmdFlags.Args()
 
+	if mergeFiles != "" {
+		if len(optPlatforms) != 0 || fsMirrorDir != "" || netMirrorURL != "" || exportBundleDir != "" || len(providerStrs) != 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid merge options",
+				"The -merge option cannot be combined with the -platform, -fs-mirror, -net-mirror, or -export-bundle options, or with provider addresses.",
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+		return c.mergeLockFiles(mergeFiles)
+	}
+
 	var platforms []getproviders.Platform
 	if len(optPlatforms) == 0 {
 		platforms = []getproviders.Platform{getproviders.CurrentPlatform}
@@ -258,6 +278,17 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 			break
 		}
 		updatedLocks[platform] = newLocks
+
+		if exportBundleDir != "" {
+			if err := exportProvidersBundle(exportBundleDir, platform, dir, newLocks, reqs); err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Could not export provider bundle",
+					fmt.Sprintf("OpenTofu failed to export the providers for %s to %s: %s.", platform, exportBundleDir, err),
+				))
+				break
+			}
+		}
 	}
 
 	// If we have any error diagnostics from installation then we won't
@@ -334,11 +365,22 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	moreDiags = c.replaceLockedDependencies(newLocks)
 	diags = diags.Append(moreDiags)
 
+	// The bundle includes a copy of the lock file, so that the packages
+	// in it can be verified even in a configuration that doesn't have the
+	// locks yet.
+	if exportBundleDir != "" && !diags.HasErrors() {
+		diags = diags.Append(depsfile.SaveLocksToFile(newLocks, filepath.Join(exportBundleDir, dependencyLockFilename)))
+	}
+
 	c.showDiagnostics(diags)
 	if diags.HasErrors() {
 		return 1
 	}
 
+	if exportBundleDir != "" {
+		c.Ui.Output(fmt.Sprintf("\nOpenTofu exported the providers and the lock file to %s. Copy the directory to\nanother machine and use it as a filesystem mirror, such as with\n\"tofu init -plugin-dir=DIR\", to install the providers without network access.", exportBundleDir))
+	}
+
 	if madeAnyChange {
 		c.Ui.Output(c.Colorize().Color("\n[bold][green]Success![reset] [bold]OpenTofu has updated the lock file.[reset]"))
 		c.Ui.Output("\nReview the changes in .terraform.lock.hcl and then commit to your\nversion control system to retain the new checksums.\n")
@@ -348,6 +390,50 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	return 0
 }
 
+// mergeLockFiles implements the -merge option, which replaces the lock file
+// with the result of merging the two comma-separated lock files given in
+// files, such as the two sides of a version control merge conflict.
+func (c *ProvidersLockCommand) mergeLockFiles(files string) int {
+	var diags tfdiags.Diagnostics
+
+	paths := strings.Split(files, ",")
+	if len(paths) != 2 || paths[0] == "" || paths[1] == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid merge options",
+			"The -merge option requires two lock files separated by a comma, such as -merge=ours.hcl,theirs.hcl.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	ours, moreDiags := depsfile.LoadLocksFromFile(paths[0])
+	diags = diags.Append(moreDiags)
+	theirs, moreDiags := depsfile.LoadLocksFromFile(paths[1])
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	merged, moreDiags := depsfile.MergeLocks(ours, theirs)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	diags = diags.Append(c.replaceLockedDependencies(merged))
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[bold][green]Success![reset] [bold]OpenTofu has merged %s and %s into the lock file.[reset]", paths[0], paths[1])))
+	c.Ui.Output("\nReview the changes in .terraform.lock.hcl and then commit to your\nversion control system to resolve the conflict.\n")
+	return 0
+}
+
 func (c *ProvidersLockCommand) Help() string {
 	return `
 Usage: tofu [global options] providers lock [options] [providers...]
@@ -391,6 +477,26 @@ Options:
                      of valid checksums will be limited only to what OpenTofu
                      can learn from the data in the mirror indices.
 
+  -export-bundle=dir Also copy the locked provider packages for each of the
+                     selected platforms to the given directory, along with
+                     a copy of the updated lock file.
+
+                     The directory uses the unpacked filesystem mirror
+                     layout, so it can be copied to a machine without
+                     network access and used there as a filesystem mirror
+                     to install the providers, such as with
+                     "tofu init -plugin-dir=dir".
+
+  -merge=ours,theirs Instead of consulting any registry or mirror, replace the
+                     lock file with the result of merging the two given
+                     lock files, such as the two sides of a version control
+                     merge conflict in the lock file.
+
+                     For a provider locked in both files, OpenTofu selects
+                     the newer of the two versions that satisfies the
+                     version constraints recorded in both files. The merge
+                     fails if neither version satisfies them.
+
   -platform=os_arch  Choose a target platform to request package checksums
                      for.
 
@@ -416,6 +522,35 @@ Options:
 `
 }
 
+// exportProvidersBundle copies the packages of the given providers for the
+// given platform, at the versions selected in the given locks, from the given
+// cache directory to the given bundle directory, using the unpacked filesystem
+// mirror layout.
+func exportProvidersBundle(bundleDir string, platform getproviders.Platform, dir *providercache.Dir, locks *depsfile.Locks, reqs getproviders.Requirements) error {
+	for provider := range reqs {
+		lock := locks.Provider(provider)
+		if lock == nil {
+			continue
+		}
+		cached := dir.ProviderVersion(provider, lock.Version())
+		if cached == nil {
+			return fmt.Errorf("the package for %s %s was not installed", provider.ForDisplay(), lock.Version())
+		}
+
+		// The installer may have linked to the package in a filesystem
+		// mirror instead of copying it, but CopyDir resolves that link, so
+		// the bundle always contains the package itself.
+		dst := getproviders.UnpackedDirectoryPathForPackage(bundleDir, provider, lock.Version(), platform)
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		if err := copy.CopyDir(dst, cached.PackageDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // providersLockCalculateChangeType works out whether there is any difference
 // between oldLock and newLock and returns a variable the main function can use
 // to decide on which message to print.
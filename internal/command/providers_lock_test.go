@@ -114,6 +114,79 @@ func runProviderLockGenericTest(t *testing.T, testDirectory, expected string) {
 	}
 }
 
+func TestProvidersLock_exportBundle(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-lock/basic"), td)
+	defer testChdir(t, td)()
+
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	fixtMachineDir := filepath.Join(td, "fs-mirror/registry.opentofu.org/hashicorp/test/1.0.0/os_arch")
+	wantMachineDir := filepath.Join(td, "fs-mirror/registry.opentofu.org/hashicorp/test/1.0.0/", platform)
+	if err := os.Rename(fixtMachineDir, wantMachineDir); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &ProvidersLockCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+		},
+	}
+	if code := c.Run([]string{"-fs-mirror=fs-mirror", "-export-bundle=bundle"}); code != 0 {
+		t.Fatalf("wrong exit code; expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lockfile, err := os.ReadFile(".terraform.lock.hcl")
+	if err != nil {
+		t.Fatal("error reading lockfile")
+	}
+	bundleLockfile, err := os.ReadFile(filepath.Join("bundle", ".terraform.lock.hcl"))
+	if err != nil {
+		t.Fatal("error reading bundle lockfile")
+	}
+	if string(bundleLockfile) != string(lockfile) {
+		t.Fatalf("wrong bundle lockfile content\n%s", bundleLockfile)
+	}
+
+	// The bundle must contain the package itself rather than a link to the
+	// mirror it was installed from, so that it can be moved elsewhere.
+	pkg := filepath.Join("bundle/registry.opentofu.org/hashicorp/test/1.0.0", platform, "terraform-provider-test")
+	info, err := os.Lstat(pkg)
+	if err != nil {
+		t.Fatalf("missing package in bundle: %s", err)
+	}
+	if !info.Mode().IsRegular() {
+		t.Fatalf("package in bundle is not a regular file")
+	}
+
+	// The bundle can then be used as a filesystem mirror in another
+	// configuration.
+	if err := os.RemoveAll("fs-mirror"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(".terraform.lock.hcl"); err != nil {
+		t.Fatal(err)
+	}
+	ui = new(cli.MockUi)
+	c = &ProvidersLockCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+		},
+	}
+	if code := c.Run([]string{"-fs-mirror=bundle"}); code != 0 {
+		t.Fatalf("wrong exit code using the bundle; expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+	}
+	lockfile, err = os.ReadFile(".terraform.lock.hcl")
+	if err != nil {
+		t.Fatal("error reading lockfile")
+	}
+	if string(bundleLockfile) != string(lockfile) {
+		t.Fatalf("wrong lockfile content from the bundle\n%s", lockfile)
+	}
+}
+
 func TestProvidersLock_args(t *testing.T) {
 
 	t.Run("mirror collision", func(t *testing.T) {
@@ -181,6 +254,118 @@ func TestProvidersLock_args(t *testing.T) {
 			t.Fatalf("missing expected error message: %s", output)
 		}
 	})
+
+	t.Run("merge with platform", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		c := &ProvidersLockCommand{
+			Meta: Meta{
+				Ui: ui,
+			},
+		}
+
+		// merging doesn't consult any registry, so it can't request checksums
+		args := []string{"-merge=ours.hcl,theirs.hcl", "-platform=linux_amd64"}
+		code := c.Run(args)
+
+		if code != 1 {
+			t.Fatalf("wrong exit code; expected 1, got %d", code)
+		}
+		output := ui.ErrorWriter.String()
+		if !strings.Contains(output, "The -merge option cannot be combined with") {
+			t.Fatalf("missing expected error message: %s", output)
+		}
+	})
+
+	t.Run("merge with one file", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		c := &ProvidersLockCommand{
+			Meta: Meta{
+				Ui: ui,
+			},
+		}
+
+		args := []string{"-merge=ours.hcl"}
+		code := c.Run(args)
+
+		if code != 1 {
+			t.Fatalf("wrong exit code; expected 1, got %d", code)
+		}
+		output := ui.ErrorWriter.String()
+		if !strings.Contains(output, "The -merge option requires two lock files") {
+			t.Fatalf("missing expected error message: %s", output)
+		}
+	})
+}
+
+func TestProvidersLock_merge(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	ours := `provider "registry.opentofu.org/hashicorp/test" {
+  version     = "1.0.0"
+  constraints = ">= 1.0.0"
+  hashes = [
+    "h1:ours",
+  ]
+}
+
+provider "registry.opentofu.org/hashicorp/other" {
+  version = "2.0.0"
+  hashes = [
+    "h1:other",
+  ]
+}
+`
+	theirs := `provider "registry.opentofu.org/hashicorp/test" {
+  version     = "1.1.0"
+  constraints = ">= 1.0.0, < 2.0.0"
+  hashes = [
+    "h1:theirs",
+  ]
+}
+`
+	if err := os.WriteFile("ours.hcl", []byte(ours), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("theirs.hcl", []byte(theirs), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &ProvidersLockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"-merge=ours.hcl,theirs.hcl"}); code != 0 {
+		t.Fatalf("wrong exit code; expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+	}
+
+	lockfile, err := os.ReadFile(".terraform.lock.hcl")
+	if err != nil {
+		t.Fatal("error reading lockfile")
+	}
+	expected := `# This file is maintained automatically by "tofu init".
+# Manual edits may be lost in future updates.
+
+provider "registry.opentofu.org/hashicorp/other" {
+  version = "2.0.0"
+  hashes = [
+    "h1:other",
+  ]
+}
+
+provider "registry.opentofu.org/hashicorp/test" {
+  version     = "1.1.0"
+  constraints = ">= 1.0.0, < 2.0.0"
+  hashes = [
+    "h1:theirs",
+  ]
+}
+`
+	if string(lockfile) != expected {
+		t.Fatalf("wrong lockfile content\ngot:\n%s\nwant:\n%s", lockfile, expected)
+	}
 }
 
 func TestProvidersLockCalculateChangeType(t *testing.T) {
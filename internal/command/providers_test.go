@@ -6,11 +6,16 @@
 package command
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
 )
 
 func TestProviders(t *testing.T) {
@@ -204,3 +209,48 @@ func TestProviders_tests(t *testing.T) {
 		}
 	}
 }
+
+func TestProviders_json(t *testing.T) {
+	wd := tempWorkingDirFixture(t, "providers/capabilities")
+	defer testChdir(t, wd.RootModuleDir())()
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {Block: &configschema.Block{}},
+		},
+		ServerCapabilities: providers.ServerCapabilities{
+			PlanDestroy: true,
+		},
+	}
+	ui := new(cli.MockUi)
+	c := &ProvidersCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			WorkingDir:       wd,
+		},
+	}
+
+	if code := c.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	want := map[string]interface{}{
+		"format_version": "1.0",
+		"providers": map[string]interface{}{
+			"registry.opentofu.org/hashicorp/test": map[string]interface{}{
+				"version_constraint":    "~> 1.0",
+				"required_capabilities": []interface{}{"plan_destroy"},
+				"capabilities":          []interface{}{"plan_destroy"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
@@ -69,6 +69,7 @@ func (c *RefreshCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.refreshConcurrency = args.Operation.RefreshConcurrency
 
 	// Inject variables from args into meta for static evaluation
 	c.GatherVariables(args.Vars)
@@ -152,6 +153,9 @@ func (c *RefreshCommand) OperationRequest(be backend.Enhanced, view views.Refres
 	opReq.Hooks = view.Hooks()
 	opReq.Targets = args.Targets
 	opReq.Excludes = args.Excludes
+	opReq.TargetGroups = args.TargetGroups
+	opReq.TargetStrict = args.TargetStrict
+	opReq.StrictVariables = args.StrictVariables
 	opReq.Type = backend.OperationTypeRefresh
 	opReq.View = view.Operation()
 
@@ -224,11 +228,19 @@ Options:
 
   -parallelism=n         Limit the number of concurrent operations. Defaults to 10.
 
+  -refresh-concurrency=n Limit the number of concurrent refresh requests to
+                         each provider configuration, instead of counting
+                         them against -parallelism.
+
   -target=resource       Resource to target. Operation will be limited to this
                          resource and its dependencies. This flag can be used
                          multiple times.  Cannot be used alongside the -exclude
                          flag.
 
+  -target-group=name     Group of resources to target. Operation will be
+                         limited to the resources in this group and their
+                         dependencies. This flag can be used multiple times.
+
   -var 'foo=bar'         Set a variable in the OpenTofu configuration. This
                          flag can be set multiple times.
 
@@ -236,6 +248,13 @@ Options:
                          a file. If "terraform.tfvars" or any ".auto.tfvars"
                          files are present, they will be automatically loaded.
 
+  -var-file-merge=foo    Like -var-file, but object and map values in the
+                         file are deep-merged into the values set before it,
+                         instead of replacing them.
+
+  -strict-vars           Report values for undeclared variables in variables
+                         files as errors instead of warnings.
+
   -json                  Produce output in a machine-readable JSON format,
                          suitable for use in text editor integrations and 
                          other automated systems. Always disables color.
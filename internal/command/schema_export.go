@@ -0,0 +1,211 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonschemaindex"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// DefaultSchemaIndexFilename is the name of the file, within the data
+// directory, where "tofu schema export -lsp" writes the schema index by
+// default. "tofu init" regenerates the file if it exists, so that it always
+// describes the currently-selected provider versions.
+const DefaultSchemaIndexFilename = "schema-index.json"
+
+// SchemaExportCommand is a Command implementation that exports the schemas of
+// the providers selected in the dependency lock file in a format intended for
+// editor integrations.
+type SchemaExportCommand struct {
+	Meta
+}
+
+func (c *SchemaExportCommand) Help() string {
+	return schemaExportCommandHelp
+}
+
+func (c *SchemaExportCommand) Synopsis() string {
+	return "Export provider schemas for editor integrations"
+}
+
+func (c *SchemaExportCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("schema export")
+	var lspOutput bool
+	var outPath string
+	cmdFlags.BoolVar(&lspOutput, "lsp", false, "produce the editor schema index")
+	cmdFlags.StringVar(&outPath, "out", "", "out")
+
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	if !lspOutput {
+		c.Ui.Error(
+			"The `tofu schema export` command requires the `-lsp` flag.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+	if args := cmdFlags.Args(); len(args) > 0 {
+		c.Ui.Error("The `tofu schema export` command expects no positional arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	// Check for user-supplied plugin path
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	src, diags := c.schemaIndex()
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.showDiagnostics(diags)
+
+	if outPath == "-" {
+		c.Ui.Output(string(src))
+		return 0
+	}
+	if outPath == "" {
+		outPath = filepath.Join(c.DataDir(), DefaultSchemaIndexFilename)
+		if err := os.MkdirAll(c.DataDir(), 0755); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to create the data directory: %s", err))
+			return 1
+		}
+	}
+	if err := os.WriteFile(outPath, src, 0644); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write the schema index: %s", err))
+		return 1
+	}
+	return 0
+}
+
+// schemaIndex returns the editor schema index for the providers that are
+// selected in the dependency lock file, along with the built-in providers.
+func (m *Meta) schemaIndex() ([]byte, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	locks, lockDiags := m.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		return nil, diags
+	}
+
+	opts, err := m.contextOpts()
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+
+	versions := make(map[addrs.Provider]getproviders.Version)
+	for addr, lock := range locks.AllProviders() {
+		versions[addr] = lock.Version()
+	}
+
+	schemas := make(map[addrs.Provider]providers.ProviderSchema)
+	for addr, factory := range opts.Providers {
+		if _, locked := versions[addr]; !locked && !addr.IsBuiltIn() {
+			// Providers that are available but not selected for this
+			// working directory, such as those from testing overrides,
+			// don't belong in the index.
+			continue
+		}
+
+		provider, err := factory()
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to load provider schema",
+				fmt.Sprintf("Could not start provider %s to read its schema: %s.", addr, err),
+			))
+			continue
+		}
+		resp := provider.GetProviderSchema()
+		provider.Close()
+		diags = diags.Append(resp.Diagnostics)
+		if resp.Diagnostics.HasErrors() {
+			continue
+		}
+		schemas[addr] = resp
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	src, err := jsonschemaindex.Marshal(schemas, versions)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to marshal the schema index: %w", err))
+		return nil, diags
+	}
+	return src, diags
+}
+
+// refreshSchemaIndex regenerates the schema index in the data directory if it
+// was previously created by "tofu schema export -lsp". Failing to regenerate
+// it doesn't prevent the working directory from being used, so any errors
+// are returned as a warning.
+func (m *Meta) refreshSchemaIndex() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	path := filepath.Join(m.DataDir(), DefaultSchemaIndexFilename)
+	if _, err := os.Stat(path); err != nil {
+		return diags
+	}
+	log.Printf("[TRACE] Meta.refreshSchemaIndex: regenerating %s", path)
+
+	src, indexDiags := m.schemaIndex()
+	if indexDiags.HasErrors() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to update the editor schema index",
+			fmt.Sprintf("The schema index at %s may be outdated: %s", path, indexDiags.Err()),
+		))
+		return diags
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to update the editor schema index",
+			fmt.Sprintf("The schema index at %s may be outdated: %s.", path, err),
+		))
+	}
+	return diags
+}
+
+const schemaExportCommandHelp = `
+Usage: tofu [global options] schema export -lsp [options]
+
+  Exports a compact index of the resource types, data sources, attributes,
+  nested blocks and documentation of the providers selected in the
+  dependency lock file, for use by editor integrations such as language
+  servers.
+
+  By default the index is written to schema-index.json in the working
+  directory's data directory, and is then kept up to date by subsequent
+  runs of "tofu init".
+
+Options:
+
+  -lsp               Produce the editor schema index. This option is
+                     required.
+
+  -out=path          Write the index to the given path instead, or to the
+                     standard output if the path is "-".
+`
@@ -0,0 +1,138 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/jsonschemaindex"
+)
+
+func TestSchemaExport_requiresLSP(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &SchemaExportCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("expected error\n%s", ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "requires the `-lsp` flag"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestSchemaExport_lsp(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-schema/basic"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(providersSchemaFixtureProvider()),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+
+	ic := &InitCommand{Meta: m}
+	if code := ic.Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	ui.OutputWriter.Reset()
+
+	c := &SchemaExportCommand{Meta: m}
+	if code := c.Run([]string{"-lsp", "-out=-"}); code != 0 {
+		t.Fatalf("schema export failed\n%s", ui.ErrorWriter)
+	}
+
+	var index jsonschemaindex.Index
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &index); err != nil {
+		t.Fatalf("invalid index: %s\n%s", err, ui.OutputWriter)
+	}
+	if got, want := index.FormatVersion, jsonschemaindex.FormatVersion; got != want {
+		t.Errorf("wrong format version %q; want %q", got, want)
+	}
+	p := index.Providers["registry.opentofu.org/hashicorp/test"]
+	if p == nil {
+		t.Fatalf("index has no entry for the test provider\n%s", ui.OutputWriter)
+	}
+	if got, want := p.Version, "1.2.3"; got != want {
+		t.Errorf("wrong provider version %q; want %q", got, want)
+	}
+	volumes := p.Resources["test_instance"].Attributes["volumes"]
+	if got, want := volumes.Type, "list(object({mount_point=string,size=string}))"; got != want {
+		t.Errorf("wrong type for volumes\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := p.Functions["test_func"].Signature, "provider::test::test_func(input number, variadic_input ...list(bool)) string"; got != want {
+		t.Errorf("wrong function signature\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestSchemaExport_regeneratedByInit(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-schema/basic"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(providersSchemaFixtureProvider()),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+
+	ic := &InitCommand{Meta: m}
+	if code := ic.Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	path := filepath.Join(DefaultDataDir, DefaultSchemaIndexFilename)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("init created a schema index without it being exported first")
+	}
+
+	c := &SchemaExportCommand{Meta: m}
+	if code := c.Run([]string{"-lsp"}); code != 0 {
+		t.Fatalf("schema export failed\n%s", ui.ErrorWriter)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A later init must replace an outdated index.
+	if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ic = &InitCommand{Meta: m}
+	if code := ic.Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("schema index was not regenerated\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,418 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+// ServeCommand is a Command implementation that runs OpenTofu as a
+// long-lived server, which other programs such as editors and automation
+// tools drive by sending JSON-RPC 2.0 requests to run OpenTofu commands in
+// the working directory.
+//
+// The commands run in the same process one at a time, so that the provider
+// schemas that OpenTofu caches in memory stay cached between requests.
+type ServeCommand struct {
+	Meta
+}
+
+// serveMethod is a JSON-RPC method of "tofu serve", which runs an OpenTofu
+// command.
+type serveMethod struct {
+	command func(Meta) cli.Command
+
+	// args are the arguments that are always passed to the command before
+	// the arguments given in the request, to select its machine-readable
+	// output and disable interactive input.
+	args []string
+}
+
+var serveMethods = map[string]serveMethod{
+	"init": {
+		command: func(m Meta) cli.Command { return &InitCommand{Meta: m} },
+		args:    []string{"-input=false", "-json"},
+	},
+	"validate": {
+		command: func(m Meta) cli.Command { return &ValidateCommand{Meta: m} },
+		args:    []string{"-json"},
+	},
+	"plan": {
+		command: func(m Meta) cli.Command { return &PlanCommand{Meta: m} },
+		args:    []string{"-input=false", "-json"},
+	},
+	"apply": {
+		command: func(m Meta) cli.Command { return &ApplyCommand{Meta: m} },
+		args:    []string{"-input=false", "-json"},
+	},
+	"show": {
+		command: func(m Meta) cli.Command { return &ShowCommand{Meta: m} },
+		args:    []string{"-json"},
+	},
+	"output": {
+		command: func(m Meta) cli.Command { return &OutputCommand{Meta: m} },
+		args:    []string{"-json"},
+	},
+	"state.list": {
+		command: func(m Meta) cli.Command { return &StateListCommand{Meta: m} },
+	},
+	"state.pull": {
+		command: func(m Meta) cli.Command { return &StatePullCommand{Meta: m} },
+	},
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	serveErrParse          = -32700
+	serveErrInvalidRequest = -32600
+	serveErrMethodNotFound = -32601
+	serveErrInvalidParams  = -32602
+	serveErrInternal       = -32603
+)
+
+type serveRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type serveParams struct {
+	Args []string `json:"args"`
+}
+
+type serveResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  *serveResult    `json:"result,omitempty"`
+	Error   *serveError     `json:"error,omitempty"`
+}
+
+// serveResult is the result of running a command. Stdout is the output of the
+// command, which is JSON for the methods whose commands support it.
+type serveResult struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+type serveError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (c *ServeCommand) Run(args []string) int {
+	// The commands that the server runs each get a copy of the Meta as it
+	// was before this command started using it.
+	server := newServeServer(c.Meta)
+
+	args = c.Meta.process(args)
+	var socketPath string
+	cmdFlags := c.Meta.defaultFlagSet("serve")
+	cmdFlags.StringVar(&socketPath, "socket", "", "socket")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The serve command expects no positional arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(c.CommandContext())
+	defer cancel()
+	go server.forwardInterrupts(c.ShutdownCh, cancel)
+
+	if socketPath == "" {
+		// Without a socket, the server talks to the program that started it
+		// over its standard input and output.
+		done := make(chan error, 1)
+		go func() {
+			done <- server.serveConn(os.Stdin, os.Stdout)
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error reading requests: %s", err))
+				return 1
+			}
+		case <-ctx.Done():
+		}
+		return 0
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to listen on %s: %s", socketPath, err))
+		return 1
+	}
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	log.Printf("[INFO] tofu serve: listening on %s", socketPath)
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				break
+			}
+			c.Ui.Error(fmt.Sprintf("Failed to accept a connection: %s", err))
+			return 1
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			if err := server.serveConn(conn, conn); err != nil {
+				log.Printf("[WARN] tofu serve: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return 0
+}
+
+func (c *ServeCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] serve [options]
+
+  Runs OpenTofu as a long-lived server that other programs, such as editors
+  and automation tools, can drive by sending JSON-RPC 2.0 requests to run
+  OpenTofu commands in the current working directory.
+
+  Each request is a JSON object on its own line, with one of the methods
+  init, validate, plan, apply, show, output, state.list and state.pull, and
+  the command line arguments of the command as the "args" parameter. The
+  result has the exit code and output of the command.
+
+  The server runs one command at a time, and keeps provider schemas cached
+  between requests.
+
+Options:
+
+  -socket=path        Listen for connections on the Unix domain socket at
+                      the given path, instead of reading requests from
+                      standard input and writing responses to standard
+                      output.
+
+  -no-color           If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ServeCommand) Synopsis() string {
+	return "Run OpenTofu as a JSON-RPC server for other programs"
+}
+
+// serveServer runs the commands requested of "tofu serve".
+type serveServer struct {
+	meta Meta
+
+	// runMu makes the commands run one at a time, since they share the
+	// process's working directory and environment.
+	runMu sync.Mutex
+
+	// interruptCh, if not nil, is the shutdown channel of the command that
+	// is running, which interrupts are forwarded to.
+	interruptMu sync.Mutex
+	interruptCh chan struct{}
+}
+
+func newServeServer(meta Meta) *serveServer {
+	return &serveServer{meta: meta}
+}
+
+// forwardInterrupts forwards the interrupts received on the given channel to
+// the running command, if any, or calls stop otherwise.
+func (s *serveServer) forwardInterrupts(shutdownCh <-chan struct{}, stop func()) {
+	if shutdownCh == nil {
+		return
+	}
+	for range shutdownCh {
+		s.interruptMu.Lock()
+		ch := s.interruptCh
+		s.interruptMu.Unlock()
+		if ch == nil {
+			stop()
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// serveConn reads JSON-RPC requests from r until it's exhausted, writing the
+// responses to w.
+func (s *serveServer) serveConn(r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			// We can't find the start of the next request after invalid
+			// JSON, so this ends the connection.
+			_ = enc.Encode(serveErrorResponse(nil, serveErrParse, fmt.Sprintf("invalid JSON: %s", err)))
+			return err
+		}
+
+		resp := s.handle(raw)
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs the command for the given request, returning the response, or
+// nil if the request is a notification, which has no response.
+func (s *serveServer) handle(raw json.RawMessage) *serveResponse {
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		return serveErrorResponse(nil, serveErrInvalidRequest, "batch requests aren't supported")
+	}
+
+	var req serveRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return serveErrorResponse(nil, serveErrInvalidRequest, fmt.Sprintf("invalid request: %s", err))
+	}
+	if req.JSONRPC != "2.0" {
+		return serveErrorResponse(req.ID, serveErrInvalidRequest, `the "jsonrpc" property must be "2.0"`)
+	}
+	notification := len(req.ID) == 0
+
+	method, exists := serveMethods[req.Method]
+	if !exists {
+		if notification {
+			return nil
+		}
+		return serveErrorResponse(req.ID, serveErrMethodNotFound, fmt.Sprintf("unsupported method %q", req.Method))
+	}
+
+	var params serveParams
+	if len(req.Params) != 0 && string(req.Params) != "null" {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			if notification {
+				return nil
+			}
+			return serveErrorResponse(req.ID, serveErrInvalidParams, fmt.Sprintf("invalid params: %s", err))
+		}
+	}
+
+	result, err := s.run(method, params.Args)
+	if notification {
+		return nil
+	}
+	if err != nil {
+		return serveErrorResponse(req.ID, serveErrInternal, err.Error())
+	}
+	return &serveResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+// run runs the command of the given method with the given arguments,
+// capturing its output.
+func (s *serveServer) run(method serveMethod, args []string) (*serveResult, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		return nil, err
+	}
+	defer stdin.Close()
+	stdout, err := os.CreateTemp("", "tofu-serve-stdout")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(stdout.Name())
+	defer stdout.Close()
+	stderr, err := os.CreateTemp("", "tofu-serve-stderr")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+
+	streams := &terminal.Streams{
+		Stdout: &terminal.OutputStream{File: stdout},
+		Stderr: &terminal.OutputStream{File: stderr},
+		Stdin:  &terminal.InputStream{File: stdin},
+	}
+	shutdownCh := make(chan struct{}, 1)
+
+	m := s.meta
+	m.Streams = streams
+	m.View = views.NewView(streams).SetRunningInAutomation(true)
+	m.Ui = &cli.BasicUi{Reader: stdin, Writer: stdout, ErrorWriter: stderr}
+	m.Color = false
+	m.RunningInAutomation = true
+	m.ShutdownCh = shutdownCh
+
+	s.interruptMu.Lock()
+	s.interruptCh = shutdownCh
+	s.interruptMu.Unlock()
+	defer func() {
+		s.interruptMu.Lock()
+		s.interruptCh = nil
+		s.interruptMu.Unlock()
+	}()
+
+	cmdArgs := append(append([]string(nil), method.args...), args...)
+	code := method.command(m).Run(cmdArgs)
+
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		return nil, err
+	}
+	errOut, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &serveResult{
+		ExitCode: code,
+		Stdout:   string(out),
+		Stderr:   string(errOut),
+	}, nil
+}
+
+func serveErrorResponse(id json.RawMessage, code int, message string) *serveResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &serveResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   &serveError{Code: code, Message: message},
+	}
+}
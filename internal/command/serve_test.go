@@ -0,0 +1,76 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	server := newServeServer(Meta{
+		testingOverrides: metaOverridesForProvider(testProvider()),
+	})
+
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"state.list","params":{"args":["-state",` + fmt.Sprintf("%q", statePath) + `]}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"destroy-everything"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"state.list","params":{"args":"-state"}}`,
+		`{"jsonrpc":"2.0","method":"state.list","params":{"args":["-state",` + fmt.Sprintf("%q", statePath) + `]}}`,
+		`{"jsonrpc":"1.0","id":4,"method":"state.list"}`,
+		`[{"jsonrpc":"2.0","id":5,"method":"state.list"}]`,
+	}
+	var out bytes.Buffer
+	if err := server.serveConn(strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var responses []serveResponse
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp serveResponse
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %s", err)
+		}
+		responses = append(responses, resp)
+	}
+	// The notification has no response.
+	if got, want := len(responses), 5; got != want {
+		t.Fatalf("wrong number of responses %d; want %d\n%#v", got, want, responses)
+	}
+
+	if resp := responses[0]; string(resp.ID) != "1" || resp.Error != nil || resp.Result == nil {
+		t.Errorf("wrong response to state.list: %#v", resp)
+	} else {
+		if resp.Result.ExitCode != 0 {
+			t.Errorf("wrong exit code %d\n%s", resp.Result.ExitCode, resp.Result.Stderr)
+		}
+		if got, want := resp.Result.Stdout, strings.TrimSpace(testStateListOutput)+"\n"; got != want {
+			t.Errorf("wrong output\ngot:  %q\nwant: %q", got, want)
+		}
+	}
+
+	wantErrors := []struct {
+		id   string
+		code int
+	}{
+		{"2", serveErrMethodNotFound},
+		{"3", serveErrInvalidParams},
+		{"4", serveErrInvalidRequest},
+		{"null", serveErrInvalidRequest},
+	}
+	for i, want := range wantErrors {
+		resp := responses[i+1]
+		if string(resp.ID) != want.id || resp.Error == nil || resp.Error.Code != want.code {
+			t.Errorf("wrong response %d: got id %s and error %#v; want id %s and error code %d", i+1, resp.ID, resp.Error, want.id, want.code)
+		}
+	}
+}
@@ -70,9 +70,22 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 	}
 	c.viewType = args.ViewType
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetCompactChanges(args.Compact)
 
 	// Set up view
-	view := views.NewShow(args.ViewType, c.View)
+	var view views.Show
+	switch {
+	case args.PolicyInput:
+		view = views.NewShowPolicyInput(c.View)
+	case args.HCL:
+		view = views.NewShowHCL(c.View)
+	case args.Permissions:
+		view = views.NewShowPermissions(args.ViewType, c.View)
+	case args.NDJSON:
+		view = views.NewShowNDJSON(c.View)
+	default:
+		view = views.NewShow(args.ViewType, c.View)
+	}
 
 	// Check for user-supplied plugin path
 	var err error
@@ -116,9 +129,31 @@ Options:
 
   -no-color           If specified, output won't contain any color.
 
+  -compact            If specified, show the first of the identical changes
+                      to the instances of a resource in a plan in full, and
+                      summarize the others in a single line.
+
+  -hcl                If specified, output the planned new values of the
+                      given saved plan as HCL, with changed attributes
+                      annotated.
+
   -json               If specified, output the OpenTofu plan or state in
                       a machine-readable form.
 
+  -ndjson             If specified, output each resource instance of the
+                      state as a separate JSON object on its own line, so
+                      that very large states can be processed
+                      incrementally. Can't be used with a plan.
+
+  -permissions        If specified, output the permissions required to
+                      apply the given saved plan, based on the permission
+                      hints declared by its providers. Combine with -json
+                      for a machine-readable form.
+
+  -policy-input       If specified, output the given saved plan in the
+                      stable, versioned policy input format intended for
+                      policy engines such as Open Policy Agent.
+
   -show-sensitive     If specified, sensitive values will be displayed.
 
   -var 'foo=bar'      Set a value for one of the input variables in the root
@@ -18,6 +18,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonpolicy"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
@@ -496,6 +497,123 @@ func TestShow_plan_json(t *testing.T) {
 	}
 }
 
+func TestShow_plan_policyInput(t *testing.T) {
+	planPath := showFixturePlanFile(t, plans.Create)
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-policy-input",
+		planPath,
+		"-no-color",
+	}
+	code := c.Run(args)
+	output := done(t)
+
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	var got jsonpolicy.Input
+	if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+		t.Fatalf("output is not valid policy input JSON: %s\n%s", err, output.Stdout())
+	}
+	if got.FormatVersion != jsonpolicy.FormatVersion {
+		t.Errorf("wrong format version %q; want %q", got.FormatVersion, jsonpolicy.FormatVersion)
+	}
+	if len(got.ResourceChanges) != 1 || got.ResourceChanges[0].Address != "test_instance.foo" {
+		t.Fatalf("wrong resource changes: %#v", got.ResourceChanges)
+	}
+}
+
+func TestShow_plan_hcl(t *testing.T) {
+	planPath := showFixturePlanFile(t, plans.Create)
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-hcl", planPath, "-no-color"})
+	output := done(t)
+
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	got := output.Stdout()
+	want := `resource "test_instance" "foo" {`
+	if !strings.Contains(got, want) {
+		t.Fatalf("unexpected output\ngot: %s\nwant: %s", got, want)
+	}
+}
+
+func TestShow_plan_permissions(t *testing.T) {
+	planPath := showFixturePlanFile(t, plans.Create)
+
+	provider := showFixtureProvider()
+	schema := provider.GetProviderSchemaResponse.ResourceTypes["test_instance"]
+	schema.PermissionHints = &providers.PermissionHints{
+		Create: []string{"instances.create"},
+		Delete: []string{"instances.delete"},
+	}
+	provider.GetProviderSchemaResponse.ResourceTypes["test_instance"] = schema
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-permissions", planPath, "-no-color"})
+	output := done(t)
+
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	want := `Permissions required from registry.opentofu.org/hashicorp/test:
+  - instances.create
+
+`
+	if diff := cmp.Diff(want, output.Stdout()); diff != "" {
+		t.Fatalf("unexpected output\n%s", diff)
+	}
+}
+
+func TestShow_policyInputState(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-policy-input", statePath})
+	output := done(t)
+
+	if code != 1 {
+		t.Fatalf("unexpected exit status %d; want 1\ngot: %s", code, output.Stdout())
+	}
+	if got, want := output.Stderr(), "only available for local saved plan files"; !strings.Contains(got, want) {
+		t.Fatalf("unexpected error\ngot: %s\nwant: %s", got, want)
+	}
+}
+
 func TestShow_state(t *testing.T) {
 	originalState := testState()
 	root := originalState.RootModule()
@@ -528,6 +646,36 @@ func TestShow_state(t *testing.T) {
 	}
 }
 
+func TestShow_stateNDJSON(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-ndjson", statePath})
+	output := done(t)
+
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+	lines := strings.Split(strings.TrimSuffix(output.Stdout(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("wrong number of lines %d\n%s", len(lines), output.Stdout())
+	}
+	var resource map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &resource); err != nil {
+		t.Fatalf("invalid JSON line: %s", err)
+	}
+	if got, want := resource["address"], "test_instance.foo"; got != want {
+		t.Fatalf("wrong address %q; want %q", got, want)
+	}
+}
+
 func TestShow_json_output(t *testing.T) {
 	fixtureDir := "testdata/show-json"
 	testDirs, err := os.ReadDir(fixtureDir)
@@ -0,0 +1,190 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// StateAnnotateCommand is a Command implementation that sets the note of
+// resource instances in the state.
+type StateAnnotateCommand struct {
+	StateMeta
+}
+
+func (c *StateAnnotateCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state annotate")
+	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) != 2 {
+		c.Ui.Error("Exactly one address and one note are required.\n")
+		return cli.RunResultHelp
+	}
+	note := strings.TrimSpace(args[1])
+
+	if diags := c.Meta.checkRequiredVersion(); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Get the state
+	stateMgr, err := c.State(enc)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state-annotate"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	addrs, diags := c.lookupResourceInstanceAddr(state, false, args[0])
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if len(addrs) == 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid target address",
+			"No matching objects found. To view the available instances, use \"tofu state list\". Please modify the address to reference a specific instance.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	ss := state.SyncWrapper()
+	for _, addr := range addrs {
+		ss.SetResourceInstanceNote(addr, note)
+	}
+
+	b, backendDiags := c.Backend(nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Get schemas, if possible, before writing state
+	var schemas *tofu.Schemas
+	if isCloudMode(b) {
+		var schemaDiags tfdiags.Diagnostics
+		schemas, schemaDiags = c.MaybeGetSchemas(state, nil)
+		diags = diags.Append(schemaDiags)
+	}
+
+	if err := stateMgr.WriteState(state); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateAnnotatePersist, err))
+		return 1
+	}
+	if err := stateMgr.PersistState(schemas); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateAnnotatePersist, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	for _, addr := range addrs {
+		if note == "" {
+			c.Ui.Output(fmt.Sprintf("Removed the note of %s", addr))
+		} else {
+			c.Ui.Output(fmt.Sprintf("Annotated %s", addr))
+		}
+	}
+	return 0
+}
+
+func (c *StateAnnotateCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state annotate [options] ADDRESS NOTE
+
+  Set a note on one or more resource instances in the OpenTofu state, which
+  OpenTofu then shows whenever those instances appear in a plan. This can be
+  used to carry operational context with a resource, such as the ticket for
+  its pending decommissioning.
+
+  If you give the address of a resource that has "count" or "for_each" set,
+  or the address of a module, the note is set on all of the matching
+  instances.
+
+  An empty NOTE removes the note. A resource's "note" lifecycle argument, if
+  set, replaces the note the next time the resource is planned or applied.
+
+Options:
+
+  -backup=PATH            Path where OpenTofu should write the backup
+                          state.
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -state=PATH             Path to the state file to update. Defaults to the
+                          current workspace state.
+
+  -ignore-remote-version  Continue even if remote and local OpenTofu versions
+                          are incompatible. This may result in an unusable
+                          workspace, and should be used with extreme caution.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateAnnotateCommand) Synopsis() string {
+	return "Set a note on instances in the state"
+}
+
+const errStateAnnotatePersist = `Error saving the state: %s
+
+The state was not saved. No notes were changed in the persisted state.
+No backup was created since no modification occurred. Please resolve the
+issue above and try again.`
@@ -0,0 +1,140 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestStateAnnotate(t *testing.T) {
+	fooAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, key := range []addrs.InstanceKey{addrs.IntKey(0), addrs.IntKey(1)} {
+			s.SetResourceInstanceCurrent(
+				fooAddr.Instance(key).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"foo"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+	statePath := testStateFile(t, state)
+
+	newCommand := func(ui cli.Ui) *StateAnnotateCommand {
+		view, _ := testView(t)
+		return &StateAnnotateCommand{
+			StateMeta{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+					View:             view,
+				},
+			},
+		}
+	}
+
+	ui := new(cli.MockUi)
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+		"pending decommission JIRA-123",
+	}
+	if code := newCommand(ui).Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "Annotated test_instance.foo[1]"; !strings.Contains(got, want) {
+		t.Fatalf("missing %q in output:\n%s", want, got)
+	}
+
+	state = testStateRead(t, statePath)
+	for _, key := range []addrs.InstanceKey{addrs.IntKey(0), addrs.IntKey(1)} {
+		is := state.ResourceInstance(fooAddr.Instance(key).Absolute(addrs.RootModuleInstance))
+		if got, want := is.Note, "pending decommission JIRA-123"; got != want {
+			t.Fatalf("wrong note for instance %s\ngot:  %q\nwant: %q", key, got, want)
+		}
+	}
+
+	// An empty note removes the note.
+	ui = new(cli.MockUi)
+	args = []string{
+		"-state", statePath,
+		"test_instance.foo[0]",
+		"",
+	}
+	if code := newCommand(ui).Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	state = testStateRead(t, statePath)
+	if got := state.ResourceInstance(fooAddr.Instance(addrs.IntKey(0)).Absolute(addrs.RootModuleInstance)).Note; got != "" {
+		t.Fatalf("note not removed: %q", got)
+	}
+	if got := state.ResourceInstance(fooAddr.Instance(addrs.IntKey(1)).Absolute(addrs.RootModuleInstance)).Note; got == "" {
+		t.Fatalf("note of the other instance removed")
+	}
+}
+
+func TestStateAnnotate_noMatch(t *testing.T) {
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "foo",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"foo"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	})
+	statePath := testStateFile(t, state)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateAnnotateCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.bar",
+		"note",
+	}
+	if code := c.Run(args); code == 0 {
+		t.Fatalf("expected error output, got:\n%s", ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Unknown resource"; !strings.Contains(got, want) {
+		t.Fatalf("missing %q in error output:\n%s", want, got)
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// defaultDeposedAge is the default age after which "tofu state audit"
+// reports a deposed object.
+const defaultDeposedAge = 24 * time.Hour
+
+// StateAuditCommand is a Command implementation that checks the state for
+// anomalies and suggests state commands to fix them.
+type StateAuditCommand struct {
+	Meta
+	StateMeta
+}
+
+// stateAuditFinding is a single anomaly found by "tofu state audit".
+type stateAuditFinding struct {
+	// Addr is the address of the resource instance the finding is about, and
+	// DeposedKey identifies one of its deposed objects, if relevant.
+	Addr       addrs.AbsResourceInstance
+	DeposedKey states.DeposedKey
+
+	Summary string
+
+	// Fixes are the commands that would resolve the finding. Not all of them
+	// are appropriate in every situation, so the user must choose.
+	Fixes []string
+}
+
+func (c *StateAuditCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var statePath string
+	var deposedAge time.Duration
+	cmdFlags := c.Meta.defaultFlagSet("state audit")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.StringVar(&statePath, "state", "", "path")
+	cmdFlags.DurationVar(&deposedAge, "deposed-age", defaultDeposedAge, "age")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The state audit command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+
+	if statePath != "" {
+		c.Meta.statePath = statePath
+	}
+
+	var diags tfdiags.Diagnostics
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	// Get the state
+	env, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	stateMgr, err := b.StateMgr(env)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	// The provider check needs the configuration, but the other checks are
+	// still useful without it, such as when auditing a state file given with
+	// -state from a directory that has no configuration.
+	config, configDiags := c.stateAuditConfig()
+	diags = diags.Append(configDiags)
+
+	var findings []stateAuditFinding
+	if config != nil {
+		findings = append(findings, stateAuditUnrequiredProviders(state, config)...)
+	}
+	findings = append(findings, stateAuditDanglingDependencies(state)...)
+	findings = append(findings, stateAuditDuplicateIDs(state)...)
+	findings = append(findings, stateAuditDeposedObjects(state, deposedAge, time.Now())...)
+
+	c.showDiagnostics(diags)
+
+	if len(findings) == 0 {
+		c.Ui.Output("No problems found in the state.")
+		return 0
+	}
+
+	c.Ui.Output(fmt.Sprintf("Found %d problem(s) in the state:\n", len(findings)))
+	for _, finding := range findings {
+		addr := finding.Addr.String()
+		if finding.DeposedKey != states.NotDeposed {
+			addr = fmt.Sprintf("%s (deposed object %s)", addr, finding.DeposedKey)
+		}
+		c.Ui.Output(fmt.Sprintf("# %s\n%s", addr, finding.Summary))
+		if len(finding.Fixes) > 0 {
+			c.Ui.Output("Suggested fix:")
+			for _, fix := range finding.Fixes {
+				c.Ui.Output("  " + fix)
+			}
+		}
+		c.Ui.Output("")
+	}
+	return 2
+}
+
+// stateAuditConfig loads the configuration in the current working directory
+// for "tofu state audit". It returns nil, with a warning explaining why, if
+// the configuration isn't available.
+func (c *StateAuditCommand) stateAuditConfig() (*configs.Config, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if empty, err := configs.IsEmptyDir("."); err != nil || empty {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Skipped checking provider requirements",
+			"There is no configuration in the current working directory, so OpenTofu can't check whether the providers in the state are still required.",
+		))
+		return nil, diags
+	}
+
+	config, configDiags := c.loadConfig(".")
+	if configDiags.HasErrors() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Skipped checking provider requirements",
+			fmt.Sprintf("OpenTofu can't check whether the providers in the state are still required, because the configuration is invalid: %s", configDiags.Err()),
+		))
+		return nil, diags
+	}
+	return config, diags
+}
+
+// stateAuditUnrequiredProviders finds the resource instances whose provider is
+// no longer required by the configuration, which OpenTofu will not be able to
+// destroy.
+func stateAuditUnrequiredProviders(state *states.State, config *configs.Config) []stateAuditFinding {
+	reqs, diags := config.ProviderRequirements()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	var findings []stateAuditFinding
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			provider := rs.ProviderConfig.Provider
+			if _, required := reqs[provider]; required || provider.IsBuiltIn() {
+				continue
+			}
+			for key := range rs.Instances {
+				addr := rs.Addr.Instance(key)
+				findings = append(findings, stateAuditFinding{
+					Addr:    addr,
+					Summary: fmt.Sprintf("The provider %s is no longer required by the configuration, so OpenTofu can't manage this object. If the provider has moved to a new source address, replace it in the state. Otherwise, remove the object from the state and destroy it manually if necessary.", provider.ForDisplay()),
+					Fixes: []string{
+						fmt.Sprintf("tofu state replace-provider %s <new source address>", provider.ForDisplay()),
+						fmt.Sprintf("tofu state rm %s", stateAuditQuote(addr.String())),
+					},
+				})
+			}
+		}
+	}
+	sortStateAuditFindings(findings)
+	return findings
+}
+
+// stateAuditDanglingDependencies finds the objects that depend on resources
+// that no longer exist in the state.
+func stateAuditDanglingDependencies(state *states.State) []stateAuditFinding {
+	var findings []stateAuditFinding
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				addr := rs.Addr.Instance(key)
+				check := func(obj *states.ResourceInstanceObjectSrc, dk states.DeposedKey) {
+					var missing []string
+					for _, dep := range obj.Dependencies {
+						if len(state.Resources(dep)) == 0 {
+							missing = append(missing, dep.String())
+						}
+					}
+					if len(missing) == 0 {
+						return
+					}
+					sort.Strings(missing)
+					findings = append(findings, stateAuditFinding{
+						Addr:       addr,
+						DeposedKey: dk,
+						Summary:    fmt.Sprintf("The object depends on resources that no longer exist in the state: %s. Applying the configuration again will record its current dependencies.", strings.Join(missing, ", ")),
+						Fixes:      []string{"tofu apply"},
+					})
+				}
+				if is.Current != nil {
+					check(is.Current, states.NotDeposed)
+				}
+				for dk, obj := range is.Deposed {
+					check(obj, dk)
+				}
+			}
+		}
+	}
+	sortStateAuditFindings(findings)
+	return findings
+}
+
+// stateAuditDuplicateIDs finds managed resource instances of the same type and
+// provider that track objects with the same ID, which usually means that the
+// same remote object was imported at more than one address.
+func stateAuditDuplicateIDs(state *states.State) []stateAuditFinding {
+	type objectKey struct {
+		provider addrs.Provider
+		typeName string
+		id       string
+	}
+	seen := make(map[objectKey][]addrs.AbsResourceInstance)
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for key, is := range rs.Instances {
+				if is.Current == nil {
+					continue
+				}
+				id := states.LegacyInstanceObjectID(is.Current)
+				if id == "" || id == "<none>" {
+					// The resource type has no "id" attribute.
+					continue
+				}
+				k := objectKey{rs.ProviderConfig.Provider, rs.Addr.Resource.Type, id}
+				seen[k] = append(seen[k], rs.Addr.Instance(key))
+			}
+		}
+	}
+
+	var findings []stateAuditFinding
+	for k, instAddrs := range seen {
+		if len(instAddrs) < 2 {
+			continue
+		}
+		sort.Slice(instAddrs, func(i, j int) bool {
+			return instAddrs[i].Less(instAddrs[j])
+		})
+		for _, addr := range instAddrs[1:] {
+			findings = append(findings, stateAuditFinding{
+				Addr:    addr,
+				Summary: fmt.Sprintf("The object has the same ID %q as %s, so both addresses probably track the same remote object. If so, remove all but one of them from the state.", k.id, instAddrs[0]),
+				Fixes:   []string{fmt.Sprintf("tofu state rm %s", stateAuditQuote(addr.String()))},
+			})
+		}
+	}
+	sortStateAuditFindings(findings)
+	return findings
+}
+
+// stateAuditDeposedObjects finds the deposed objects that were deposed longer
+// than maxAge before now, or at an unknown time.
+//
+// Deposed objects are normally destroyed in the same apply that deposed them,
+// so an old one means that destroying it failed.
+func stateAuditDeposedObjects(state *states.State, maxAge time.Duration, now time.Time) []stateAuditFinding {
+	var findings []stateAuditFinding
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				for dk := range is.Deposed {
+					var summary string
+					if deposedAt, ok := is.DeposedAt[dk]; !ok {
+						summary = "The object was deposed at an unknown time by an earlier version of OpenTofu and is still waiting to be destroyed."
+					} else if age := now.Sub(deposedAt); age > maxAge {
+						summary = fmt.Sprintf("The object was deposed at %s and is still waiting to be destroyed.", deposedAt.Format(time.RFC3339))
+					} else {
+						continue
+					}
+					addr := rs.Addr.Instance(key)
+					findings = append(findings, stateAuditFinding{
+						Addr:       addr,
+						DeposedKey: dk,
+						Summary:    summary + " Applying the configuration again will retry destroying it, or you can destroy it on its own.",
+						Fixes: []string{
+							"tofu apply",
+							fmt.Sprintf("tofu state deposed destroy %s %s", stateAuditQuote(addr.String()), dk),
+						},
+					})
+				}
+			}
+		}
+	}
+	sortStateAuditFindings(findings)
+	return findings
+}
+
+func sortStateAuditFindings(findings []stateAuditFinding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if !findings[i].Addr.Equal(findings[j].Addr) {
+			return findings[i].Addr.Less(findings[j].Addr)
+		}
+		return findings[i].DeposedKey < findings[j].DeposedKey
+	})
+}
+
+// stateAuditQuote quotes an address for use as a shell argument if it
+// contains characters that a shell would interpret.
+func stateAuditQuote(addr string) string {
+	if !strings.ContainsAny(addr, `["] `) {
+		return addr
+	}
+	return "'" + addr + "'"
+}
+
+func (c *StateAuditCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state audit [options]
+
+  Check the OpenTofu state for anomalies.
+
+  This command reports resource instances whose provider is no longer
+  required by the configuration in the current working directory, objects
+  that depend on resources that no longer exist in the state, objects that
+  share the same ID with an object at another address, and deposed objects
+  that have not been destroyed. For each problem it suggests commands that
+  would fix it.
+
+  The command exits with status 0 if it found no problems, 2 if it found
+  any, and 1 if an error occurred.
+
+Options:
+
+  -state=statefile      Path to a OpenTofu state file to audit. By default,
+                        OpenTofu will consult the state of the
+                        currently-selected workspace.
+
+  -deposed-age=24h      Report deposed objects that were deposed longer ago
+                        than the given duration. Deposed objects recorded by
+                        earlier versions of OpenTofu are always reported.
+
+  -var 'foo=bar'        Set a value for one of the input variables in the root
+                        module of the configuration. Use this option more than
+                        once to set more than one variable.
+
+  -var-file=filename    Load variable values from the given file, in addition
+                        to the default files terraform.tfvars and *.auto.tfvars.
+                        Use this option more than once to include more than one
+                        variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateAuditCommand) Synopsis() string {
+	return "Check the state for anomalies"
+}
@@ -0,0 +1,153 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestStateAudit(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	if err := os.WriteFile("main.tf", []byte(`resource "test_instance" "foo" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testProviderAddr := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	resourceAddr := func(typeName, name string) addrs.ResourceInstance {
+		return addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: typeName,
+			Name: name,
+		}.Instance(addrs.NoKey)
+	}
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			resourceAddr("test_instance", "foo").Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"shared"}`),
+				Status:    states.ObjectReady,
+				Dependencies: []addrs.ConfigResource{
+					resourceAddr("test_instance", "gone").ContainingResource().InModule(addrs.RootModule),
+				},
+			},
+			testProviderAddr, addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			resourceAddr("test_instance", "bar").Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"shared"}`),
+				Status:    states.ObjectReady,
+			},
+			testProviderAddr, addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			resourceAddr("other_thing", "baz").Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"baz"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("other"),
+				Module:   addrs.RootModule,
+			}, addrs.NoKey,
+		)
+		for _, name := range []string{"old", "recent"} {
+			s.SetResourceInstanceDeposed(
+				resourceAddr("test_instance", name).Absolute(addrs.RootModuleInstance),
+				states.DeposedKey("00000001"),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `"}`),
+					Status:    states.ObjectReady,
+				},
+				testProviderAddr, addrs.NoKey,
+			)
+		}
+	})
+	deposedAt := func(name string, t time.Time) {
+		is := state.RootModule().ResourceInstance(resourceAddr("test_instance", name))
+		is.DeposedAt = map[states.DeposedKey]time.Time{"00000001": t}
+	}
+	deposedAt("old", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	deposedAt("recent", time.Now().UTC())
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := cli.NewMockUi()
+	c := &StateAuditCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath}); code != 2 {
+		t.Fatalf("wrong exit code %d; want 2\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `Found 4 problem(s) in the state:
+
+# other_thing.baz
+The provider hashicorp/other is no longer required by the configuration, so OpenTofu can't manage this object. If the provider has moved to a new source address, replace it in the state. Otherwise, remove the object from the state and destroy it manually if necessary.
+Suggested fix:
+  tofu state replace-provider hashicorp/other <new source address>
+  tofu state rm other_thing.baz
+
+# test_instance.foo
+The object depends on resources that no longer exist in the state: test_instance.gone. Applying the configuration again will record its current dependencies.
+Suggested fix:
+  tofu apply
+
+# test_instance.foo
+The object has the same ID "shared" as test_instance.bar, so both addresses probably track the same remote object. If so, remove all but one of them from the state.
+Suggested fix:
+  tofu state rm test_instance.foo
+
+# test_instance.old (deposed object 00000001)
+The object was deposed at 2020-01-01T00:00:00Z and is still waiting to be destroyed. Applying the configuration again will retry destroying it, or you can destroy it on its own.
+Suggested fix:
+  tofu apply
+  tofu state deposed destroy test_instance.old 00000001
+`
+	if got := ui.OutputWriter.String(); strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStateAudit_noProblems(t *testing.T) {
+	state := testState()
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := cli.NewMockUi()
+	c := &StateAuditCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath}); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "No problems found in the state."; !strings.Contains(got, want) {
+		t.Fatalf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := ui.ErrorWriter.String(), "Skipped checking provider requirements"; !strings.Contains(got, want) {
+		t.Fatalf("missing warning about the configuration\ngot: %s", got)
+	}
+}
@@ -0,0 +1,722 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// StateDeposedCommand is a Command implementation that only shows the help
+// for the subcommands that manage deposed objects.
+type StateDeposedCommand struct {
+	Meta
+}
+
+func (c *StateDeposedCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *StateDeposedCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state deposed <subcommand> [options] [args]
+
+  This command has subcommands for managing deposed objects.
+
+  When OpenTofu replaces an object whose resource uses the
+  create_before_destroy lifecycle setting, it first "deposes" the existing
+  object, then creates its replacement and finally destroys the deposed
+  object. If destroying the deposed object fails, it remains in the state
+  and OpenTofu tries again in the next apply.
+
+  These subcommands can list the remaining deposed objects, destroy one of
+  them on its own, or make one of them the current object of its resource
+  instance again.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDeposedCommand) Synopsis() string {
+	return "Manage deposed objects in the state"
+}
+
+// StateDeposedListCommand is a Command implementation that lists the deposed
+// objects in the state.
+type StateDeposedListCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateDeposedListCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var statePath string
+	cmdFlags := c.Meta.defaultFlagSet("state deposed list")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.StringVar(&statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+
+	if statePath != "" {
+		c.Meta.statePath = statePath
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil, enc.State())
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(backendDiags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	// Get the state
+	env, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	stateMgr, err := b.StateMgr(env)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	var addrs []addrs.AbsResourceInstance
+	var diags tfdiags.Diagnostics
+	if len(args) == 0 {
+		addrs, diags = c.lookupAllResourceInstanceAddrs(state)
+	} else {
+		addrs, diags = c.lookupResourceInstanceAddrs(state, args...)
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	for _, addr := range addrs {
+		is := state.ResourceInstance(addr)
+		if is == nil {
+			continue
+		}
+		for _, dk := range sortedDeposedKeys(is) {
+			deposedAt := "-"
+			if t, ok := is.DeposedAt[dk]; ok {
+				deposedAt = t.Format(time.RFC3339)
+			}
+			c.Ui.Output(fmt.Sprintf("%s %s %s", addr, dk, deposedAt))
+		}
+	}
+
+	c.showDiagnostics(diags)
+
+	return 0
+}
+
+func (c *StateDeposedListCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state deposed list [options] [address...]
+
+  List the deposed objects in the OpenTofu state.
+
+  Each line of the output gives the address of the resource instance, the
+  key of the deposed object and the time the object was deposed, or "-" for
+  objects deposed by earlier versions of OpenTofu. The address arguments
+  filter the objects by resource or module, in the same way as for
+  "tofu state list".
+
+Options:
+
+  -state=statefile    Path to a OpenTofu state file to use to look
+                      up OpenTofu-managed resources. By default, OpenTofu
+                      will consult the state of the currently-selected
+                      workspace.
+
+  -var 'foo=bar'      Set a value for one of the input variables in the root
+                      module of the configuration. Use this option more than
+                      once to set more than one variable.
+
+  -var-file=filename  Load variable values from the given file, in addition
+                      to the default files terraform.tfvars and *.auto.tfvars.
+                      Use this option more than once to include more than one
+                      variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDeposedListCommand) Synopsis() string {
+	return "List deposed objects in the state"
+}
+
+// StateDeposedPromoteCommand is a Command implementation that makes a deposed
+// object the current object of its resource instance again.
+type StateDeposedPromoteCommand struct {
+	StateMeta
+}
+
+func (c *StateDeposedPromoteCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state deposed promote")
+	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error("Exactly one address and an optional deposed key are required.\n")
+		return cli.RunResultHelp
+	}
+
+	if diags := c.Meta.checkRequiredVersion(); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	if encDiags.HasErrors() {
+		c.showDiagnostics(encDiags)
+		return 1
+	}
+
+	// Get the state
+	stateMgr, err := c.State(enc)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state-deposed-promote"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	addr, dk, diags := c.lookupDeposedObject(state, args)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// The current object, if any, takes the place of the promoted object so
+	// that the next apply will destroy it, rather than it being forgotten
+	// while it still exists in the remote system.
+	ss := state.SyncWrapper()
+	demotedKey := ss.DeposeResourceInstanceObject(addr)
+	if !ss.MaybeRestoreResourceInstanceDeposed(addr, dk) {
+		// Should never happen, because we just made sure that there is no
+		// current object.
+		c.Ui.Error(fmt.Sprintf("Failed to promote deposed object %s of %s.", dk, addr))
+		return 1
+	}
+
+	b, backendDiags := c.Backend(nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Get schemas, if possible, before writing state
+	var schemas *tofu.Schemas
+	if isCloudMode(b) {
+		var schemaDiags tfdiags.Diagnostics
+		schemas, schemaDiags = c.MaybeGetSchemas(state, nil)
+		diags = diags.Append(schemaDiags)
+	}
+
+	if err := stateMgr.WriteState(state); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateDeposedPersist, err))
+		return 1
+	}
+	if err := stateMgr.PersistState(schemas); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateDeposedPersist, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Promoted deposed object %s to be the current object of %s.", dk, addr))
+	if demotedKey != states.NotDeposed {
+		c.Ui.Output(fmt.Sprintf("The previous current object is now deposed object %s, which the next apply will destroy.", demotedKey))
+	}
+	return 0
+}
+
+func (c *StateDeposedPromoteCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state deposed promote [options] ADDRESS [KEY]
+
+  Make a deposed object the current object of its resource instance again.
+
+  Use this if OpenTofu failed to create the replacement for an object, or if
+  you want to keep the original object instead of its replacement. If the
+  resource instance has a current object, it becomes a deposed object in
+  turn, so that the next apply will destroy it. Use "tofu state deposed
+  destroy" to destroy it right away instead.
+
+  The deposed key is required only if the resource instance has more than
+  one deposed object. Use "tofu state deposed list" to find the keys.
+
+Options:
+
+  -backup=PATH            Path where OpenTofu should write the backup
+                          state.
+
+  -lock=false             Don't hold a state lock during the operation. This
+                          is dangerous if others might concurrently run
+                          commands against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -state=PATH             Path to the state file to update. Defaults to the
+                          current workspace state.
+
+  -ignore-remote-version  Continue even if remote and local OpenTofu versions
+                          are incompatible. This may result in an unusable
+                          workspace, and should be used with extreme caution.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDeposedPromoteCommand) Synopsis() string {
+	return "Make a deposed object current again"
+}
+
+// StateDeposedDestroyCommand is a Command implementation that destroys a
+// single deposed object, without making any other changes.
+type StateDeposedDestroyCommand struct {
+	StateMeta
+}
+
+func (c *StateDeposedDestroyCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	var autoApprove bool
+	cmdFlags := c.Meta.extendedFlagSet("state deposed destroy")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "skip interactive approval")
+	cmdFlags.BoolVar(&c.ignoreRemoteVersion, "ignore-remote-version", false, "continue even if remote and local OpenTofu versions are incompatible")
+	cmdFlags.IntVar(&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
+	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	if len(args) < 1 || len(args) > 2 {
+		c.Ui.Error("Exactly one address and an optional deposed key are required.\n")
+		return cli.RunResultHelp
+	}
+
+	var diags tfdiags.Diagnostics
+
+	// The command selects the changes to apply itself, so it can't honor
+	// the resource targeting options.
+	if len(c.Meta.targetFlags) != 0 || len(c.Meta.excludeFlags) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Resource targeting is not supported",
+			"The -target and -exclude options cannot be used with \"tofu state deposed destroy\", which only destroys the selected deposed object.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if !c.dirIsConfigPath(".") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No OpenTofu configuration files",
+			"Destroying a deposed object requires the configuration of its provider, but the current working directory does not contain any OpenTofu configuration files (.tf or .tf.json).",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	config, configDiags := c.loadConfig(".")
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.EncryptionFromPath(".")
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Check for user-supplied plugin path
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(&BackendOpts{
+		Config: config.Module.Backend,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We require a backend.Local to build a context, as for "tofu import".
+	local, ok := b.(backend.Local)
+	if !ok {
+		c.Ui.Error(ErrUnsupportedLocalOp)
+		return 1
+	}
+
+	// Build the operation
+	opReq := c.Operation(b, arguments.ViewHuman, enc)
+	opReq.ConfigDir = "."
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	opReq.Hooks = []tofu.Hook{c.uiHook()}
+	{
+		// Setup required variables/call for operation (usually done in Meta.RunOperation)
+		var moreDiags, callDiags tfdiags.Diagnostics
+		opReq.Variables, moreDiags = c.collectVariableValues()
+		opReq.RootCall, callDiags = c.rootModuleCall(opReq.ConfigDir)
+		diags = diags.Append(moreDiags).Append(callDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+	opReq.View = views.NewOperation(arguments.ViewHuman, c.RunningInAutomation, c.View)
+
+	// Check remote OpenTofu version is compatible
+	remoteVersionDiags := c.remoteVersionCheck(b, opReq.Workspace)
+	diags = diags.Append(remoteVersionDiags)
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	diags = nil
+
+	// Get the context
+	lr, state, ctxDiags := local.LocalRun(ctx, opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Successfully creating the context can result in a lock, so ensure we release it
+	defer func() {
+		diags := opReq.StateLocker.Unlock()
+		if diags.HasErrors() {
+			c.showDiagnostics(diags)
+		}
+	}()
+
+	addr, dk, lookupDiags := c.lookupDeposedObject(lr.InputState, args)
+	diags = diags.Append(lookupDiags)
+	if lookupDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We plan normally with the resource instance as the target, which
+	// includes destroying its deposed objects, and then discard the other
+	// planned changes to managed resources so that only the selected object
+	// is destroyed. The reads of data resources stay in the plan, because
+	// the provider configuration may refer to data resources whose reads
+	// were deferred to the apply. The targeting warnings would only be
+	// confusing here, because the user didn't ask for targeting.
+	planOpts := *lr.PlanOpts
+	planOpts.Mode = plans.NormalMode
+	planOpts.SkipRefresh = true
+	planOpts.Targets = []addrs.Targetable{addr}
+	plan, planDiags := lr.Core.Plan(ctx, lr.Config, lr.InputState, &planOpts)
+	diags = diags.Append(withoutTargetingWarnings(planDiags))
+	if planDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	var change *plans.ResourceInstanceChangeSrc
+	var keep []*plans.ResourceInstanceChangeSrc
+	for _, rc := range plan.Changes.Resources {
+		switch {
+		case rc.Addr.Equal(addr) && rc.DeposedKey == dk && rc.Action == plans.Delete:
+			change = rc
+			keep = append(keep, rc)
+		case rc.Addr.Resource.Resource.Mode == addrs.DataResourceMode:
+			keep = append(keep, rc)
+		}
+	}
+	if change == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot destroy deposed object",
+			fmt.Sprintf("OpenTofu did not plan to destroy deposed object %s of %s.", dk, addr),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	plan.Changes.Resources = keep
+	plan.Changes.Outputs = []*plans.OutputChangeSrc{}
+
+	if !autoApprove {
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+			"\n[bold]Do you really want to destroy deposed object %s of %s?[reset]\n"+
+				"OpenTofu will destroy this object in the remote system.\n"+
+				"Only 'yes' will be accepted to confirm.\n", dk, addr,
+		)))
+		v, err := c.Ui.Ask("Enter a value:")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error asking for approval: %s", err))
+			return 1
+		}
+		if v != "yes" {
+			c.Ui.Output("Cancelled destroying the deposed object.")
+			return 0
+		}
+	}
+
+	newState, applyDiags := lr.Core.Apply(ctx, plan, lr.Config)
+	diags = diags.Append(withoutTargetingWarnings(applyDiags))
+
+	// The apply may have partially succeeded, so we persist the state even
+	// if there were errors.
+	if newState != nil {
+		// Get schemas, if possible, before writing state
+		var schemas *tofu.Schemas
+		if isCloudMode(b) {
+			var schemaDiags tfdiags.Diagnostics
+			schemas, schemaDiags = c.MaybeGetSchemas(newState, nil)
+			diags = diags.Append(schemaDiags)
+		}
+
+		log.Printf("[INFO] Writing state output to: %s", c.Meta.StateOutPath())
+		if err := state.WriteState(newState); err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateDeposedPersist, err))
+			return 1
+		}
+		if err := state.PersistState(schemas); err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateDeposedPersist, err))
+			return 1
+		}
+	}
+
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[reset][green]\nDestroyed deposed object %s of %s.", dk, addr)))
+	return 0
+}
+
+func (c *StateDeposedDestroyCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state deposed destroy [options] ADDRESS [KEY]
+
+  Destroy a single deposed object, without making any other changes.
+
+  Deposed objects are normally destroyed by the next apply, along with all of
+  the other changes in the plan. Use this command to destroy one of them on
+  its own instead, such as to retry after a failure without applying any
+  other changes. This command requires the configuration of the object's
+  provider, so it must be run in the configuration's directory.
+
+  The deposed key is required only if the resource instance has more than
+  one deposed object. Use "tofu state deposed list" to find the keys.
+
+Options:
+
+  -auto-approve           Skip interactive approval before destroying.
+
+  -backup=PATH            Path to backup the existing state file before
+                          modifying. Defaults to the "-state-out" path with
+                          ".backup" extension. Set to "-" to disable backup.
+
+  -lock=false             Don't hold a state lock during the operation. This
+                          is dangerous if others might concurrently run
+                          commands against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -state=PATH             Path to the state file to update. Defaults to the
+                          current workspace state.
+
+  -state-out=PATH         Path to write the updated state file. By default,
+                          the "-state" path will be used.
+
+  -var 'foo=bar'          Set a value for one of the input variables in the root
+                          module of the configuration. Use this option more than
+                          once to set more than one variable.
+
+  -var-file=filename      Load variable values from the given file, in addition
+                          to the default files terraform.tfvars and *.auto.tfvars.
+                          Use this option more than once to include more than one
+                          variables file.
+
+  -ignore-remote-version  Continue even if remote and local OpenTofu versions
+                          are incompatible. This may result in an unusable
+                          workspace, and should be used with extreme caution.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateDeposedDestroyCommand) Synopsis() string {
+	return "Destroy a deposed object"
+}
+
+// lookupDeposedObject finds the deposed object selected by the arguments of
+// the "tofu state deposed" subcommands, which are a resource instance
+// address and a deposed key that is optional if the instance has only one
+// deposed object.
+func (c *StateMeta) lookupDeposedObject(state *states.State, args []string) (addrs.AbsResourceInstance, states.DeposedKey, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	addr, addrDiags := addrs.ParseAbsResourceInstanceStr(args[0])
+	diags = diags.Append(addrDiags)
+	if addrDiags.HasErrors() {
+		return addr, states.NotDeposed, diags
+	}
+
+	is := state.ResourceInstance(addr)
+	keys := sortedDeposedKeys(is)
+	if len(keys) == 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No deposed objects",
+			fmt.Sprintf("The current state contains no deposed objects for %s.", addr),
+		))
+		return addr, states.NotDeposed, diags
+	}
+
+	if len(args) < 2 {
+		if len(keys) > 1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Deposed key required",
+				fmt.Sprintf("%s has %d deposed objects, so you must specify which one to use. Use \"tofu state deposed list\" to find their keys.", addr, len(keys)),
+			))
+			return addr, states.NotDeposed, diags
+		}
+		return addr, keys[0], diags
+	}
+
+	dk := states.DeposedKey(args[1])
+	if !is.HasDeposed(dk) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unknown deposed object",
+			fmt.Sprintf("%s has no deposed object with key %s. Use \"tofu state deposed list\" to find the keys of its deposed objects.", addr, dk),
+		))
+		return addr, states.NotDeposed, diags
+	}
+	return addr, dk, diags
+}
+
+func sortedDeposedKeys(is *states.ResourceInstance) []states.DeposedKey {
+	if is == nil {
+		return nil
+	}
+	keys := make([]states.DeposedKey, 0, len(is.Deposed))
+	for dk := range is.Deposed {
+		keys = append(keys, dk)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// withoutTargetingWarnings removes the warnings about resource targeting from
+// the given diagnostics, for operations that use targeting internally.
+func withoutTargetingWarnings(diags tfdiags.Diagnostics) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity() == tfdiags.Warning {
+			switch diag.Description().Summary {
+			case "Resource targeting is in effect", "Applied changes may be incomplete":
+				continue
+			}
+		}
+		ret = ret.Append(diag)
+	}
+	return ret
+}
+
+const errStateDeposedPersist = `Error saving the state: %s
+
+The state was not saved. No items were changed in the persisted state.`
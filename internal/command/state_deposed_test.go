@@ -0,0 +1,280 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func testStateDeposed() *states.State {
+	state := states.BuildState(func(s *states.SyncState) {
+		addr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: "foo",
+		}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+		provider := addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		}
+		s.SetResourceInstanceCurrent(
+			addr,
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON:           []byte(`{"id":"new","ami":"bar"}`),
+				Status:              states.ObjectReady,
+				CreateBeforeDestroy: true,
+			},
+			provider, addrs.NoKey,
+		)
+		s.SetResourceInstanceDeposed(
+			addr,
+			states.DeposedKey("00000001"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON:           []byte(`{"id":"old","ami":"bar"}`),
+				Status:              states.ObjectReady,
+				CreateBeforeDestroy: true,
+			},
+			provider, addrs.NoKey,
+		)
+	})
+	state.RootModule().Resources["test_instance.foo"].Instances[addrs.NoKey].DeposedAt = map[states.DeposedKey]time.Time{
+		"00000001": time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+	}
+	return state
+}
+
+func TestStateDeposedList(t *testing.T) {
+	statePath := testStateFile(t, testStateDeposed())
+
+	ui := cli.NewMockUi()
+	c := &StateDeposedListCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "test_instance.foo 00000001 2024-05-01T10:30:00Z\n"; got != want {
+		t.Fatalf("wrong output\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestStateDeposedPromote(t *testing.T) {
+	statePath := testStateFile(t, testStateDeposed())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateDeposedPromoteCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, "test_instance.foo"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	state := testStateRead(t, statePath)
+	is := state.RootModule().Resources["test_instance.foo"].Instances[addrs.NoKey]
+	if got, want := string(is.Current.AttrsJSON), `{"id":"old","ami":"bar"}`; got != want {
+		t.Errorf("wrong current object\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := len(is.Deposed), 1; got != want {
+		t.Fatalf("wrong number of deposed objects %d; want %d", got, want)
+	}
+	for dk, obj := range is.Deposed {
+		if got, want := string(obj.AttrsJSON), `{"id":"new","ami":"bar"}`; got != want {
+			t.Errorf("wrong deposed object\ngot:  %s\nwant: %s", got, want)
+		}
+		if _, ok := is.DeposedAt[dk]; !ok {
+			t.Errorf("demoted object has no deposed time")
+		}
+	}
+}
+
+func TestStateDeposedPromote_unknownKey(t *testing.T) {
+	statePath := testStateFile(t, testStateDeposed())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateDeposedPromoteCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, "test_instance.foo", "deadbeef"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "has no deposed object with key deadbeef"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestStateDeposedDestroy(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+	statePath := testStateFile(t, testStateDeposed())
+
+	p := applyFixtureProvider()
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateDeposedDestroyCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{"-state", statePath, "-auto-approve", "test_instance.foo", "00000001"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !p.ApplyResourceChangeCalled {
+		t.Fatal("provider was not asked to destroy the deposed object")
+	}
+	if got, want := p.ApplyResourceChangeRequest.PriorState.GetAttr("id"), cty.StringVal("old"); !got.RawEquals(want) {
+		t.Errorf("wrong object destroyed %#v; want %#v", got, want)
+	}
+	if !p.ApplyResourceChangeRequest.PlannedState.IsNull() {
+		t.Errorf("change was not a destroy: %#v", p.ApplyResourceChangeRequest.PlannedState)
+	}
+
+	state := testStateRead(t, statePath)
+	is := state.RootModule().Resources["test_instance.foo"].Instances[addrs.NoKey]
+	if len(is.Deposed) != 0 {
+		t.Errorf("deposed object is still in the state")
+	}
+	if got, want := string(is.Current.AttrsJSON), `{"ami":"bar","id":"new"}`; got != want {
+		t.Errorf("current object changed\ngot:  %s\nwant: %s", got, want)
+	}
+	if strings.Contains(ui.ErrorWriter.String(), "targeting") {
+		t.Errorf("unexpected targeting warning\n%s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateDeposedDestroy_deferredDataSource(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-deposed-destroy-data"), td)
+	defer testChdir(t, td)()
+	state := testStateDeposed()
+	state.RootModule().Resources["test_instance.foo"].ProviderConfig = addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+		Alias:    "deposed",
+	}
+	statePath := testStateFile(t, state)
+
+	p := applyFixtureProvider()
+	p.GetProviderSchemaResponse.Provider = providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"region": {Type: cty.String, Optional: true},
+			},
+		},
+	}
+	p.GetProviderSchemaResponse.DataSources = map[string]providers.Schema{
+		"test_data_source": {
+			Block: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Computed: true},
+				},
+			},
+		},
+	}
+	p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+		State: cty.ObjectVal(map[string]cty.Value{
+			"id": cty.StringVal("us-east-1"),
+		}),
+	}
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateDeposedDestroyCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{"-state", statePath, "-auto-approve", "test_instance.foo", "00000001"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if !p.ReadDataSourceCalled {
+		t.Fatal("the deferred data source was not read")
+	}
+	if got, want := p.ConfigureProviderRequest.Config.GetAttr("region"), cty.StringVal("us-east-1"); !got.RawEquals(want) {
+		t.Errorf("wrong provider configuration %#v; want %#v", got, want)
+	}
+
+	state = testStateRead(t, statePath)
+	if rs := state.RootModule().Resources["test_instance.setup"]; rs != nil {
+		t.Errorf("unrelated resource was created")
+	}
+	is := state.RootModule().Resources["test_instance.foo"].Instances[addrs.NoKey]
+	if len(is.Deposed) != 0 {
+		t.Errorf("deposed object is still in the state")
+	}
+}
+
+func TestStateDeposedDestroy_target(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+	statePath := testStateFile(t, testStateDeposed())
+
+	p := applyFixtureProvider()
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateDeposedDestroyCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{"-state", statePath, "-auto-approve", "-target", "test_instance.foo", "test_instance.foo", "00000001"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Resource targeting is not supported"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Error("provider was asked to apply a change")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// removedBlocksForState returns the configuration of the removed blocks that
+// record the removal of the given resource instances from the state, which
+// must already have been removed from it.
+//
+// A removed block refers to all of the instances of a resource, so for
+// resources that still have instances in the state the result has warnings
+// instead of blocks. Data resources don't need removed blocks, so they are
+// skipped.
+func removedBlocksForState(state *states.State, removed []addrs.AbsResourceInstance) ([]string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	resources := make(map[string]addrs.ConfigResource)
+	for _, addr := range removed {
+		if addr.Resource.Resource.Mode != addrs.ManagedResourceMode {
+			continue
+		}
+		resource := addr.ConfigResource()
+		resources[resource.String()] = resource
+	}
+
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var blocks []string
+	for _, key := range keys {
+		resource := resources[key]
+		if len(state.Resources(resource)) > 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Cannot generate removed block",
+				fmt.Sprintf("Some instances of %s are still in the state, but a removed block removes all of the instances of a resource, so OpenTofu didn't generate one. Remove these instances from the configuration yourself, for example by changing the resource's count or for_each argument.", resource),
+			))
+			continue
+		}
+		blocks = append(blocks, fmt.Sprintf("removed {\n  from = %s\n}\n", resource))
+	}
+	return blocks, diags
+}
+
+// movedBlockForState returns the configuration of the moved block that
+// records moving the object at the given source address in the state to the
+// given destination address.
+//
+// Moved blocks don't support data resources, which OpenTofu reads again
+// anyway, so for these the result is empty and has a warning.
+func movedBlockForState(from, to addrs.Targetable) (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	for _, addr := range []addrs.Targetable{from, to} {
+		var mode addrs.ResourceMode
+		switch addr := addr.(type) {
+		case addrs.AbsResource:
+			mode = addr.Resource.Mode
+		case addrs.AbsResourceInstance:
+			mode = addr.Resource.Resource.Mode
+		default:
+			continue
+		}
+		if mode == addrs.DataResourceMode {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Cannot generate moved block",
+				fmt.Sprintf("Moved blocks can't refer to data resources, so OpenTofu didn't generate one for moving %s to %s.", from, to),
+			))
+			return "", diags
+		}
+	}
+
+	return fmt.Sprintf("moved {\n  from = %s\n  to   = %s\n}\n", from, to), diags
+}
+
+// appendGeneratedConfig appends the given generated configuration blocks to
+// the file at the given path, creating it if it doesn't exist yet, with a
+// comment naming the command that generated them.
+func appendGeneratedConfig(path string, command string, blocks []string) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var buf strings.Builder
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "# Generated by \"tofu %s\"\n", command)
+	buf.WriteString(strings.Join(blocks, "\n"))
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(buf.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
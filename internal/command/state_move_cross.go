@@ -0,0 +1,636 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	backendInit "github.com/opentofu/opentofu/internal/backend/init"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// StateMoveCrossCommand is a Command implementation that moves objects from
+// one state to another, such as between workspaces or backends.
+type StateMoveCrossCommand struct {
+	StateMeta
+}
+
+// stateMoveCrossLocation is one of the two states that the move-cross command
+// moves objects between.
+type stateMoveCrossLocation struct {
+	backend   backend.Backend
+	workspace string
+	mgr       statemgr.Full
+
+	// config identifies the type, configuration and state namespace of the
+	// backend, so that we can tell whether two locations are the same state
+	// even if they were selected in different ways.
+	config string
+}
+
+func (c *StateMoveCrossCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+
+	var dryRun bool
+	var fromWorkspace, toWorkspace, fromBackend, toBackend string
+	fromBackendConfig := newRawFlags("-from-backend-config")
+	toBackendConfig := newRawFlags("-to-backend-config")
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state move-cross")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&fromWorkspace, "from-workspace", "", "workspace")
+	cmdFlags.StringVar(&toWorkspace, "to-workspace", "", "workspace")
+	cmdFlags.StringVar(&fromBackend, "from-backend", "", "backend type")
+	cmdFlags.StringVar(&toBackend, "to-backend", "", "backend type")
+	cmdFlags.Var(fromBackendConfig, "from-backend-config", "")
+	cmdFlags.Var(toBackendConfig, "to-backend-config", "")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock states")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) == 0 {
+		c.Ui.Error("At least one address is required.\n")
+		return cli.RunResultHelp
+	}
+
+	if diags := c.Meta.checkRequiredVersion(); diags != nil {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+	if fromBackend == "" && !fromBackendConfig.Empty() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid command line options: -from-backend-config",
+			"The -from-backend-config option requires -from-backend, to select the type of the source backend.",
+		))
+	}
+	if toBackend == "" && !toBackendConfig.Empty() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid command line options: -to-backend-config",
+			"The -to-backend-config option requires -to-backend, to select the type of the destination backend.",
+		))
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	currentWorkspace, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	if fromWorkspace == "" {
+		fromWorkspace = currentWorkspace
+	}
+	if toWorkspace == "" {
+		toWorkspace = currentWorkspace
+	}
+	if fromBackend == "" && toBackend == "" && fromWorkspace == toWorkspace {
+		diags = diags.Append(errStateMoveCrossSameLocation())
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We initialize the backend of the current working directory only if
+	// at least one side uses it.
+	var current backend.Backend
+	if fromBackend == "" || toBackend == "" {
+		b, backendDiags := c.Backend(nil, enc.State())
+		diags = diags.Append(backendDiags)
+		if backendDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		current = b
+	}
+
+	from, moreDiags := c.location(current, fromBackend, fromBackendConfig, fromWorkspace, enc)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	to, moreDiags := c.location(current, toBackend, toBackendConfig, toWorkspace, enc)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// The backend options can also select the current backend, or the same
+	// backend on both sides, so we compare the resolved locations too.
+	if from.config == to.config && from.workspace == to.workspace {
+		diags = diags.Append(errStateMoveCrossSameLocation())
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We hold locks on both states for the whole operation, so that nobody
+	// else can change either of them between reading and writing.
+	if c.stateLock {
+		for _, loc := range []*stateMoveCrossLocation{from, to} {
+			stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+			if diags := stateLocker.Lock(loc.mgr, "state-move-cross"); diags.HasErrors() {
+				c.showDiagnostics(diags)
+				return 1
+			}
+			defer func() {
+				if diags := stateLocker.Unlock(); diags.HasErrors() {
+					c.showDiagnostics(diags)
+				}
+			}()
+		}
+	}
+
+	if err := from.mgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh source state: %s", err))
+		return 1
+	}
+	if err := to.mgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh destination state: %s", err))
+		return 1
+	}
+
+	origFrom := from.mgr.State()
+	if origFrom == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+	stateFrom := origFrom.DeepCopy()
+	stateTo := to.mgr.State().DeepCopy()
+	if stateTo == nil {
+		stateTo = states.NewState()
+	}
+
+	// We make all of the changes to copies of the two states, and only write
+	// them once every requested move has succeeded.
+	prefix := "Move"
+	if dryRun {
+		prefix = "Would move"
+	}
+	var moved []*states.ResourceInstance
+	var messages []string
+	for _, arg := range args {
+		target, moreDiags := c.lookupSingleStateObjectAddr(stateFrom, arg)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		instances, moreDiags := stateMoveCrossObject(stateFrom, stateTo, target)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		moved = append(moved, instances...)
+		messages = append(messages, fmt.Sprintf("%s %q", prefix, target.String()))
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	for _, msg := range messages {
+		c.Ui.Output(msg)
+	}
+
+	if dryRun {
+		c.showDiagnostics(diags)
+		return 0 // This is as far as we go in dry-run mode
+	}
+
+	// The moved objects can't depend on resources that stayed behind in the
+	// source state, and objects remaining in the source state can't depend
+	// on the moved ones any more, so we drop those dependency records. The
+	// next apply records the dependencies again from the configuration.
+	for _, is := range moved {
+		stateMoveCrossPruneDependencies(is, func(dep addrs.ConfigResource) bool {
+			return len(stateTo.Resources(dep)) != 0
+		})
+	}
+	for _, ms := range stateFrom.Modules {
+		for _, rs := range ms.Resources {
+			for _, is := range rs.Instances {
+				stateMoveCrossPruneDependencies(is, func(dep addrs.ConfigResource) bool {
+					return len(stateFrom.Resources(dep)) != 0 || len(origFrom.Resources(dep)) == 0
+				})
+			}
+		}
+	}
+
+	// We write the destination state first, so that if anything goes wrong
+	// the objects are at worst tracked in both states, rather than in none.
+	if err := c.persistMoveCrossState(to, stateTo); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateMoveCrossPersistDestination, err))
+		return 1
+	}
+	if err := c.persistMoveCrossState(from, stateFrom); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateMoveCrossPersistSource, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Successfully moved %d object(s).", len(messages)))
+	return 0
+}
+
+// location prepares the state manager for the given workspace of either the
+// given backend of the current working directory, if backendType is empty,
+// or a backend of the given type with the given configuration.
+func (c *StateMoveCrossCommand) location(current backend.Backend, backendType string, config rawFlags, workspace string, enc encryption.Encryption) (*stateMoveCrossLocation, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	b := current
+	var id string
+	if backendType != "" {
+		b, id, diags = c.backendFromFlags(backendType, config, workspace, enc)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	} else {
+		id, diags = c.currentBackendConfigID()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	// Check remote OpenTofu version is compatible
+	diags = diags.Append(c.remoteVersionCheck(b, workspace))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	mgr, err := b.StateMgr(workspace)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf(errStateLoadingState, err))
+		return nil, diags
+	}
+	return &stateMoveCrossLocation{
+		backend:   b,
+		workspace: workspace,
+		mgr:       mgr,
+		config:    id,
+	}, diags
+}
+
+// currentBackendConfigID returns the identity of the backend of the current
+// working directory in the same form as backendFromFlags, for comparing the
+// two locations.
+func (c *StateMoveCrossCommand) currentBackendConfigID() (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	s := c.backendState
+	if s == nil {
+		// Without a backend block, the working directory uses the local
+		// backend with its default settings.
+		f := backendInit.Backend("local")
+		return stateMoveCrossBackendConfigID("local", "", f(nil).ConfigSchema().NoneRequired().EmptyValue())
+	}
+
+	f := backendInit.Backend(s.Type)
+	if f == nil {
+		diags = diags.Append(fmt.Errorf(strings.TrimSpace(errBackendSavedUnknown), s.Type))
+		return "", diags
+	}
+	configVal, err := s.Config(f(nil).ConfigSchema())
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to decode current backend config: %w", err))
+		return "", diags
+	}
+	return stateMoveCrossBackendConfigID(s.Type, s.Namespace, configVal)
+}
+
+// stateMoveCrossBackendConfigID returns a string that identifies a backend by
+// its type, configuration and state namespace.
+func stateMoveCrossBackendConfigID(backendType, namespace string, configVal cty.Value) (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	src, err := ctyjson.Marshal(configVal, configVal.Type())
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Can't serialize backend configuration as JSON: %w", err))
+		return "", diags
+	}
+	// The fields are separated by NUL bytes, which can't appear in any of
+	// them, so different combinations can't produce the same string.
+	return backendType + "\x00" + namespace + "\x00" + string(src), diags
+}
+
+// backendFromFlags initializes a backend of the given type using settings
+// given in the same form as the -backend-config options of "tofu init".
+// It also returns the identity of the backend, as for currentBackendConfigID.
+func (c *StateMoveCrossCommand) backendFromFlags(backendType string, config rawFlags, workspace string, enc encryption.Encryption) (backend.Backend, string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	f := backendInit.Backend(backendType)
+	if f == nil {
+		diags = diags.Append(fmt.Errorf(strings.TrimSpace(errBackendNewUnknown), backendType))
+		return nil, "", diags
+	}
+
+	body, moreDiags := c.backendConfigOverrideBody(config, f(enc.State()).ConfigSchema())
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, "", diags
+	}
+	if body == nil {
+		body = hcl.EmptyBody()
+	}
+
+	b, configVal, moreDiags := c.backendInitFromConfig(&configs.Backend{
+		Type:   backendType,
+		Config: body,
+		Eval:   configs.NewStaticEvaluator(&configs.Module{}, configs.NewStaticModuleCall(addrs.RootModule, nil, ".", workspace)),
+	}, enc.State())
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, "", diags
+	}
+
+	id, moreDiags := stateMoveCrossBackendConfigID(backendType, "", configVal)
+	diags = diags.Append(moreDiags)
+	return b, id, diags
+}
+
+func (c *StateMoveCrossCommand) persistMoveCrossState(loc *stateMoveCrossLocation, state *states.State) error {
+	// Get schemas, if possible, before writing state
+	var schemas *tofu.Schemas
+	if enhanced, ok := loc.backend.(backend.Enhanced); ok && isCloudMode(enhanced) {
+		var schemaDiags tfdiags.Diagnostics
+		schemas, schemaDiags = c.MaybeGetSchemas(state, nil)
+		c.showDiagnostics(schemaDiags)
+	}
+
+	if err := loc.mgr.WriteState(state); err != nil {
+		return err
+	}
+	return loc.mgr.PersistState(schemas)
+}
+
+// stateMoveCrossObject moves the object at the given address from one state
+// to the other, keeping its address, and returns the resource instances that
+// it moved.
+func stateMoveCrossObject(from, to *states.State, target addrs.Targetable) ([]*states.ResourceInstance, tfdiags.Diagnostics) {
+	const msgInvalidSource = "Invalid source address"
+	const msgInvalidTarget = "Invalid target address"
+
+	var diags tfdiags.Diagnostics
+	var ret []*states.ResourceInstance
+	ssFrom := from.SyncWrapper()
+
+	switch addr := target.(type) {
+	case addrs.ModuleInstance:
+		var modules []*states.Module
+		for _, ms := range from.Modules {
+			if len(ms.Addr) >= len(addr) && ms.Addr[:len(addr)].Equal(addr) {
+				modules = append(modules, ms)
+			}
+		}
+		if len(modules) == 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidSource,
+				fmt.Sprintf("Cannot move %s: does not match anything in the source state.", addr),
+			))
+			return nil, diags
+		}
+		for _, ms := range modules {
+			if to.Module(ms.Addr) != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					msgInvalidTarget,
+					fmt.Sprintf("Cannot move %s: the destination state already contains that module.", ms.Addr),
+				))
+			}
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, ms := range modules {
+			ssFrom.RemoveModule(ms.Addr)
+			to.Modules[ms.Addr.String()] = ms
+			for _, rs := range ms.Resources {
+				for _, is := range rs.Instances {
+					ret = append(ret, is)
+				}
+			}
+		}
+
+	case addrs.AbsResource:
+		rs := from.Resource(addr)
+		if rs == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidSource,
+				fmt.Sprintf("Cannot move %s: does not match anything in the source state.", addr),
+			))
+			return nil, diags
+		}
+		if to.Resource(addr) != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidTarget,
+				fmt.Sprintf("Cannot move %s: the destination state already contains that resource.", addr),
+			))
+			return nil, diags
+		}
+		ssFrom.RemoveResource(addr)
+		to.EnsureModule(addr.Module).Resources[addr.Resource.String()] = rs
+		for _, is := range rs.Instances {
+			ret = append(ret, is)
+		}
+
+	case addrs.AbsResourceInstance:
+		rsFrom := from.Resource(addr.ContainingResource())
+		is := from.ResourceInstance(addr)
+		if is == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidSource,
+				fmt.Sprintf("Cannot move %s: does not match anything in the source state.", addr),
+			))
+			return nil, diags
+		}
+		if to.ResourceInstance(addr) != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidTarget,
+				fmt.Sprintf("Cannot move %s: the destination state already contains that resource instance.", addr),
+			))
+			return nil, diags
+		}
+		rsTo := to.Resource(addr.ContainingResource())
+		if rsTo != nil && rsTo.ProviderConfig.String() != rsFrom.ProviderConfig.String() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				msgInvalidTarget,
+				fmt.Sprintf("Cannot move %s: the resource uses %s in the destination state, but %s in the source state.", addr, rsTo.ProviderConfig, rsFrom.ProviderConfig),
+			))
+			return nil, diags
+		}
+		ssFrom.ForgetResourceInstanceAll(addr)
+		ssFrom.RemoveResourceIfEmpty(addr.ContainingResource())
+		if rsTo == nil {
+			to.SyncWrapper().SetResourceProvider(addr.ContainingResource(), rsFrom.ProviderConfig)
+			rsTo = to.Resource(addr.ContainingResource())
+		}
+		rsTo.Instances[addr.Resource.Key] = is
+		ret = append(ret, is)
+
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			msgInvalidSource,
+			fmt.Sprintf("Cannot move %s: OpenTofu doesn't know how to move this object.", target),
+		))
+	}
+
+	return ret, diags
+}
+
+// stateMoveCrossPruneDependencies removes the dependencies of all of the
+// objects of the given resource instance for which keep returns false.
+func stateMoveCrossPruneDependencies(is *states.ResourceInstance, keep func(addrs.ConfigResource) bool) {
+	prune := func(obj *states.ResourceInstanceObjectSrc) {
+		if obj == nil || len(obj.Dependencies) == 0 {
+			return
+		}
+		deps := obj.Dependencies[:0]
+		for _, dep := range obj.Dependencies {
+			if keep(dep) {
+				deps = append(deps, dep)
+			}
+		}
+		obj.Dependencies = deps
+	}
+
+	prune(is.Current)
+	for _, obj := range is.Deposed {
+		prune(obj)
+	}
+}
+
+func (c *StateMoveCrossCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state move-cross [options] ADDRESS...
+
+  Move the resources, resource instances and modules matching the given
+  addresses from one state to another, keeping their addresses. The source
+  and destination can be different workspaces of the same backend, or
+  different backends.
+
+  OpenTofu locks both states for the whole operation, and saves the
+  destination state before removing the objects from the source state. It
+  removes any dependency records between the moved objects and the objects
+  that remain in the source state; the next apply records them again
+  from the configuration.
+
+  By default, both the source and the destination are the current workspace
+  of the backend of the current working directory, so you must select a
+  different destination with at least one of -to-workspace or -to-backend.
+
+Options:
+
+  -from-workspace=NAME        Workspace to move the objects from. Defaults to
+                              the current workspace.
+
+  -to-workspace=NAME          Workspace to move the objects to. Defaults to
+                              the current workspace.
+
+  -from-backend=TYPE          Type of the backend to move the objects from,
+                              instead of the backend of the current working
+                              directory.
+
+  -from-backend-config=path   Configuration for the -from-backend backend, in
+                              the same form as the -backend-config option of
+                              "tofu init". Can be specified multiple times.
+
+  -to-backend=TYPE            Type of the backend to move the objects to,
+                              instead of the backend of the current working
+                              directory.
+
+  -to-backend-config=path     Configuration for the -to-backend backend, in
+                              the same form as the -backend-config option of
+                              "tofu init". Can be specified multiple times.
+
+  -dry-run                    If set, prints out what would've been moved but
+                              doesn't actually move anything.
+
+  -lock=false                 Don't hold a state lock during the operation.
+                              This is dangerous if others might concurrently
+                              run commands against the same workspaces.
+
+  -lock-timeout=0s            Duration to retry a state lock.
+
+  -ignore-remote-version      A rare option used for the remote backend only.
+                              See the remote backend documentation for more
+                              information.
+
+  -var 'foo=bar'              Set a value for one of the input variables in
+                              the root module of the configuration. Use this
+                              option more than once to set more than one
+                              variable.
+
+  -var-file=filename          Load variable values from the given file, in
+                              addition to the default files terraform.tfvars
+                              and *.auto.tfvars. Use this option more than
+                              once to include more than one variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateMoveCrossCommand) Synopsis() string {
+	return "Move items from one state to another"
+}
+
+const errStateMoveCrossPersistDestination = `Error saving the destination state: %s
+
+No items were moved. The source state is unchanged. Please resolve the
+issue above and try again.`
+
+const errStateMoveCrossPersistSource = `Error saving the source state: %s
+
+The moved items were saved in the destination state, but OpenTofu could
+not remove them from the source state, so both states now track them.
+Please resolve the issue above and then remove the items from the source
+state with "tofu state rm" to complete the move.`
+
+func errStateMoveCrossSameLocation() tfdiags.Diagnostic {
+	return tfdiags.Sourceless(
+		tfdiags.Error,
+		"Source and destination are the same",
+		"Use -to-workspace or -to-backend to select a different destination state, or use \"tofu state mv\" to move objects within the same state.",
+	)
+}
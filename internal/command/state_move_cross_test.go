@@ -0,0 +1,249 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	backendLocal "github.com/opentofu/opentofu/internal/backend/local"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func testStateMoveCrossSource() *states.State {
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	foo := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}
+	bar := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "bar",
+	}
+	return states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			foo.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"foo"}`),
+				Status:    states.ObjectReady,
+			},
+			provider, addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			bar.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON:    []byte(`{"id":"bar"}`),
+				Status:       states.ObjectReady,
+				Dependencies: []addrs.ConfigResource{foo.InModule(addrs.RootModule)},
+			},
+			provider, addrs.NoKey,
+		)
+	})
+}
+
+func testStateMoveCrossAddr(s string) addrs.AbsResource {
+	addr, diags := addrs.ParseAbsResourceStr(s)
+	if diags.HasErrors() {
+		panic(diags.Err())
+	}
+	return addr
+}
+
+func TestStateMoveCross_workspace(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testStateFileDefault(t, testStateMoveCrossSource())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateMoveCrossCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{"-to-workspace", "other", "test_instance.foo"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "Move \"test_instance.foo\"\nSuccessfully moved 1 object(s).\n"; got != want {
+		t.Errorf("wrong output\ngot:  %q\nwant: %q", got, want)
+	}
+
+	src := testStateRead(t, DefaultStateFilename)
+	if src.Resource(testStateMoveCrossAddr("test_instance.foo")) != nil {
+		t.Error("test_instance.foo is still in the source state")
+	}
+	bar := src.ResourceInstance(testStateMoveCrossAddr("test_instance.bar").Instance(addrs.NoKey))
+	if bar == nil {
+		t.Fatal("test_instance.bar is missing from the source state")
+	}
+	if len(bar.Current.Dependencies) != 0 {
+		t.Errorf("test_instance.bar still depends on %v", bar.Current.Dependencies)
+	}
+
+	dst := testStateRead(t, filepath.Join(backendLocal.DefaultWorkspaceDir, "other", DefaultStateFilename))
+	if dst.Resource(testStateMoveCrossAddr("test_instance.foo")) == nil {
+		t.Error("test_instance.foo is missing from the destination state")
+	}
+	if dst.Resource(testStateMoveCrossAddr("test_instance.bar")) != nil {
+		t.Error("test_instance.bar was moved too")
+	}
+}
+
+func TestStateMoveCross_backend(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testStateFileDefault(t, testStateMoveCrossSource())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateMoveCrossCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-to-backend", "local",
+		"-to-backend-config", "path=other.tfstate",
+		"test_instance.foo", "test_instance.bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	src := testStateRead(t, DefaultStateFilename)
+	if !src.Empty() {
+		t.Errorf("source state still has objects")
+	}
+	dst := testStateRead(t, "other.tfstate")
+	bar := dst.ResourceInstance(testStateMoveCrossAddr("test_instance.bar").Instance(addrs.NoKey))
+	if bar == nil {
+		t.Fatal("test_instance.bar is missing from the destination state")
+	}
+	if len(bar.Current.Dependencies) != 1 {
+		t.Errorf("test_instance.bar lost its dependency on test_instance.foo, which moved with it")
+	}
+}
+
+func TestStateMoveCross_conflict(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testStateFileDefault(t, testStateMoveCrossSource())
+	testStateFileWorkspaceDefault(t, "other", testStateMoveCrossSource())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateMoveCrossCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{"-to-workspace", "other", "test_instance.foo"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid target address"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Neither state should have changed.
+	src := testStateRead(t, DefaultStateFilename)
+	if src.Resource(testStateMoveCrossAddr("test_instance.foo")) == nil {
+		t.Error("test_instance.foo was removed from the source state")
+	}
+}
+
+func TestStateMoveCross_sameState(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	testStateFileDefault(t, testStateMoveCrossSource())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StateMoveCrossCommand{
+		StateMeta{
+			Meta: Meta{
+				Ui:   ui,
+				View: view,
+			},
+		},
+	}
+
+	if code := c.Run([]string{"test_instance.foo"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Source and destination are the same"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestStateMoveCross_sameStateFromFlags(t *testing.T) {
+	tests := map[string][]string{
+		"current backend": {
+			"-to-backend", "local",
+		},
+		"same backend on both sides": {
+			"-from-backend", "local",
+			"-from-backend-config", "path=other.tfstate",
+			"-to-backend", "local",
+			"-to-backend-config", "path=other.tfstate",
+		},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			defer testChdir(t, td)()
+			testStateFileDefault(t, testStateMoveCrossSource())
+
+			ui := cli.NewMockUi()
+			view, _ := testView(t)
+			c := &StateMoveCrossCommand{
+				StateMeta{
+					Meta: Meta{
+						Ui:   ui,
+						View: view,
+					},
+				},
+			}
+
+			if code := c.Run(append(args, "test_instance.foo")); code != 1 {
+				t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+			}
+			if got, want := ui.ErrorWriter.String(), "Source and destination are the same"; !strings.Contains(got, want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+
+			src := testStateRead(t, DefaultStateFilename)
+			if src.Resource(testStateMoveCrossAddr("test_instance.foo")) == nil {
+				t.Error("test_instance.foo was removed from the state")
+			}
+		})
+	}
+}
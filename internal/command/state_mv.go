@@ -32,8 +32,10 @@ func (c *StateMvCommand) Run(args []string) int {
 	var backupPathOut, statePathOut string
 
 	var dryRun bool
+	var generateConfigOut string
 	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state mv")
 	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&generateConfigOut, "generate-config-out", "", "generate-config-out")
 	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
 	cmdFlags.StringVar(&backupPathOut, "backup-out", "-", "backup")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock states")
@@ -55,6 +57,15 @@ func (c *StateMvCommand) Run(args []string) int {
 		return 1
 	}
 
+	if generateConfigOut != "" && statePathOut != "" {
+		c.showDiagnostics(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid command line options: -generate-config-out, -state-out",
+			"A moved block can only describe moving an object within the same state, so OpenTofu can't generate one for moving it to a different state file.",
+		))
+		return 1
+	}
+
 	// If backup or backup-out options are set
 	// and the state option is not set, make sure
 	// the backend is local
@@ -436,6 +447,20 @@ func (c *StateMvCommand) Run(args []string) int {
 		}
 	}
 
+	if generateConfigOut != "" && moved > 0 {
+		block, genDiags := movedBlockForState(sourceAddr, destAddr)
+		diags = diags.Append(genDiags)
+		var blocks []string
+		if block != "" {
+			blocks = append(blocks, block)
+		}
+		if err := appendGeneratedConfig(generateConfigOut, "state mv", blocks); err != nil {
+			c.showDiagnostics(diags)
+			c.Ui.Error(fmt.Sprintf(errStateGenerateConfig, generateConfigOut, err))
+			return 1
+		}
+	}
+
 	c.showDiagnostics(diags)
 
 	if moved == 0 {
@@ -557,6 +582,12 @@ Options:
 
   -lock-timeout=0s        Duration to retry a state lock.
 
+  -generate-config-out=PATH
+                          Append a moved block for the move to the given file,
+                          creating it if it doesn't exist, so that the
+                          configuration records the new address. Can't be
+                          used with -state-out.
+
   -ignore-remote-version  A rare option used for the remote backend only. See
                           the remote backend documentation for more information.
 
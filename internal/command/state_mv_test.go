@@ -157,6 +157,82 @@ func TestStateMv(t *testing.T) {
 
 }
 
+func TestStateMv_generateConfigOut(t *testing.T) {
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_instance.foo"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"bar","foo":"value","bar":"value"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	})
+	statePath := testStateFile(t, state)
+	configPath := filepath.Join(t.TempDir(), "maintenance.tf")
+	if err := os.WriteFile(configPath, []byte("# Maintenance blocks\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateMvCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-generate-config-out", configPath,
+		"test_instance.foo",
+		"test_instance.bar[\"baz\"]",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("return code: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Maintenance blocks
+
+# Generated by "tofu state mv"
+moved {
+  from = test_instance.foo
+  to   = test_instance.bar["baz"]
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("wrong generated configuration\n%s", diff)
+	}
+
+	// Moved blocks can't describe moving to a different state.
+	args = []string{
+		"-state", statePath,
+		"-state-out", filepath.Join(t.TempDir(), "other.tfstate"),
+		"-generate-config-out", configPath,
+		"test_instance.bar[\"baz\"]",
+		"test_instance.foo",
+	}
+	if code := c.Run(args); code == 0 {
+		t.Fatal("succeeded with -state-out; want an error")
+	}
+	if got, want := ui.ErrorWriter.String(), "-generate-config-out, -state-out"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestStateMv_backupAndBackupOutOptionsWithNonLocalBackend(t *testing.T) {
 	state := states.BuildState(func(s *states.SyncState) {
 		s.SetResourceInstanceCurrent(
@@ -31,9 +31,16 @@ type StatePushCommand struct {
 
 func (c *StatePushCommand) Run(args []string) int {
 	args = c.Meta.process(args)
-	var flagForce bool
+	var flagForce, flagMerge bool
+	var flagMergeStrategy string
+	var flagKeepLocal, flagKeepRemote []string
 	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state push")
 	cmdFlags.BoolVar(&flagForce, "force", false, "")
+	cmdFlags.BoolVar(&flagMerge, "merge", false, "merge conflicting states")
+	cmdFlags.StringVar(&flagMergeStrategy, "merge-strategy", "", "merge strategy")
+	cmdFlags.Var((*FlagStringSlice)(&flagKeepLocal), "keep-local", "resource to keep from the local state")
+	cmdFlags.Var((*FlagStringSlice)(&flagKeepRemote), "keep-remote", "resource to keep from the remote state")
+	cmdFlags.BoolVar(&c.Meta.input, "input", true, "input")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
 	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	if err := cmdFlags.Parse(args); err != nil {
@@ -47,6 +54,23 @@ func (c *StatePushCommand) Run(args []string) int {
 		return cli.RunResultHelp
 	}
 
+	var mergeStrategy stateMergeSide
+	if flagMergeStrategy != "" {
+		var ok bool
+		if mergeStrategy, ok = parseStateMergeSide(flagMergeStrategy); !ok {
+			c.Ui.Error(fmt.Sprintf("Invalid -merge-strategy %q: must be either \"local\" or \"remote\".\n", flagMergeStrategy))
+			return 1
+		}
+	}
+	if !flagMerge && (mergeStrategy != "" || len(flagKeepLocal) > 0 || len(flagKeepRemote) > 0) {
+		c.Ui.Error("The -merge-strategy, -keep-local and -keep-remote options require -merge.\n")
+		return 1
+	}
+	if flagMerge && flagForce {
+		c.Ui.Error("The -merge and -force options are mutually exclusive.\n")
+		return 1
+	}
+
 	if diags := c.Meta.checkRequiredVersion(); diags != nil {
 		c.showDiagnostics(diags)
 		return 1
@@ -137,6 +161,20 @@ func (c *StatePushCommand) Run(args []string) int {
 		srcStateFile = statemgr.NewStateFile()
 	}
 
+	// If the state can't be written as a successor of the current state, we
+	// can help the user to merge the two instead of refusing to write it.
+	if flagMerge {
+		existing := statemgr.Export(stateMgr)
+		if conflictErr := statemgr.CheckValidImport(srcStateFile, existing); conflictErr != nil {
+			merged, diags := c.mergeConflictingStates(srcStateFile, existing, conflictErr, mergeStrategy, flagKeepLocal, flagKeepRemote)
+			c.showDiagnostics(diags)
+			if diags.HasErrors() {
+				return 1
+			}
+			srcStateFile = merged
+		}
+	}
+
 	// Import it, forcing through the lineage/serial if requested and possible.
 	if err := statemgr.Import(srcStateFile, stateMgr, flagForce); err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to write state: %s", err))
@@ -163,6 +201,45 @@ func (c *StatePushCommand) Run(args []string) int {
 	return 0
 }
 
+// mergeConflictingStates runs the conflict resolution assistant for a state
+// that conflicts with the existing state, and returns the merged state as a
+// successor of the existing one.
+func (c *StatePushCommand) mergeConflictingStates(local, existing *statefile.File, conflictErr error, strategy stateMergeSide, keepLocal, keepRemote []string) (*statefile.File, tfdiags.Diagnostics) {
+	conflicts := stateResourceConflicts(local.State, existing.State)
+
+	c.Ui.Output(fmt.Sprintf("The state being pushed conflicts with the remote state: %s.\n", conflictErr))
+	if len(conflicts) == 0 {
+		c.Ui.Output("No resources differ between the local and remote states, so the remote resources will be kept.\n")
+	} else {
+		var buf strings.Builder
+		buf.WriteString("The following resources differ between the local state being pushed and the remote state:\n\n")
+		for _, conflict := range conflicts {
+			buf.WriteString(conflict.String())
+		}
+		c.Ui.Output(buf.String())
+	}
+
+	choices, diags := c.resolveStateConflicts(conflicts, strategy, keepLocal, keepRemote)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var fromLocal, fromRemote int
+	for _, conflict := range conflicts {
+		if choices[conflict.Addr.String()] == stateMergeLocal {
+			fromLocal++
+		} else {
+			fromRemote++
+		}
+	}
+	if len(conflicts) > 0 {
+		c.Ui.Output(fmt.Sprintf("Merged the states, keeping %d conflicting resource(s) from the local state and %d from the remote state.", fromLocal, fromRemote))
+	}
+
+	merged := mergeStates(existing.State, conflicts, choices)
+	return statefile.New(merged, existing.Lineage, existing.Serial+1), diags
+}
+
 func (c *StatePushCommand) Help() string {
 	helpText := `
 Usage: tofu [global options] state push [options] PATH
@@ -172,7 +249,8 @@ Usage: tofu [global options] state push [options] PATH
   This command "pushes" a local state and overwrites remote state
   with a local state file. The command will protect you against writing
   an older serial or a different state file lineage unless you specify the
-  "-force" flag.
+  "-force" flag, or the "-merge" flag to merge the two states resource by
+  resource.
 
   This command works with local state (it will overwrite the local
   state), but is less useful for this use case.
@@ -186,6 +264,26 @@ Options:
   -force              Write the state even if lineages don't match or the
                       remote serial is higher.
 
+  -merge              If lineages don't match or the remote serial is higher,
+                      show the resources that differ between the two states
+                      and merge them instead of refusing to write the state.
+                      Differing resources are resolved by -keep-local,
+                      -keep-remote and -merge-strategy, or interactively.
+
+  -merge-strategy=X   With -merge, keep differing resources that aren't named
+                      by -keep-local or -keep-remote from the "local" state
+                      being pushed or from the "remote" state, instead of
+                      asking for each of them.
+
+  -keep-local=ADDR    With -merge, keep the given resource as recorded in the
+                      state being pushed. Can be used multiple times.
+
+  -keep-remote=ADDR   With -merge, keep the given resource as recorded in the
+                      remote state. Can be used multiple times.
+
+  -input=false        Disable interactive resolution of differing resources
+                      with -merge.
+
   -lock=false         Don't hold a state lock during the operation. This is
                       dangerous if others might concurrently run commands
                       against the same workspace.
@@ -0,0 +1,263 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// stateMergeSide selects which of the two conflicting state snapshots a
+// resource is taken from when "tofu state push -merge" resolves a conflict.
+type stateMergeSide string
+
+const (
+	// stateMergeLocal keeps the resource as recorded in the state being
+	// pushed, which includes removing it if it's absent there.
+	stateMergeLocal stateMergeSide = "local"
+
+	// stateMergeRemote keeps the resource as recorded in the current remote
+	// state.
+	stateMergeRemote stateMergeSide = "remote"
+)
+
+func parseStateMergeSide(s string) (stateMergeSide, bool) {
+	switch side := stateMergeSide(strings.ToLower(strings.TrimSpace(s))); side {
+	case stateMergeLocal, stateMergeRemote:
+		return side, true
+	default:
+		return "", false
+	}
+}
+
+// stateResourceConflict describes a resource that is recorded differently in
+// the state being pushed and in the remote state. Either Local or Remote is
+// nil if the resource exists only in the other state.
+type stateResourceConflict struct {
+	Addr   addrs.AbsResource
+	Local  *states.Resource
+	Remote *states.Resource
+}
+
+// stateResourceConflicts returns the resources that differ between the two
+// given states, sorted by address.
+func stateResourceConflicts(local, remote *states.State) []stateResourceConflict {
+	var ret []stateResourceConflict
+	seen := make(map[string]bool)
+
+	add := func(addr addrs.AbsResource) {
+		key := addr.String()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		l, r := local.Resource(addr), remote.Resource(addr)
+		if reflect.DeepEqual(l, r) {
+			return
+		}
+		ret = append(ret, stateResourceConflict{Addr: addr, Local: l, Remote: r})
+	}
+	for _, s := range []*states.State{local, remote} {
+		for _, ms := range s.Modules {
+			for _, rs := range ms.Resources {
+				add(rs.Addr)
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Addr.Less(ret[j].Addr)
+	})
+	return ret
+}
+
+// String returns a description of the conflict for the resolution
+// assistant, including the individual instances that differ when the
+// resource has more than one.
+func (c stateResourceConflict) String() string {
+	var buf strings.Builder
+	switch {
+	case c.Remote == nil:
+		fmt.Fprintf(&buf, "  + %s (only in the local state)\n", c.Addr)
+		return buf.String()
+	case c.Local == nil:
+		fmt.Fprintf(&buf, "  - %s (only in the remote state)\n", c.Addr)
+		return buf.String()
+	}
+
+	fmt.Fprintf(&buf, "  ~ %s\n", c.Addr)
+
+	var keys []addrs.InstanceKey
+	for k := range c.Local.Instances {
+		keys = append(keys, k)
+	}
+	for k := range c.Remote.Instances {
+		if _, exists := c.Local.Instances[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return addrs.InstanceKeyLess(keys[i], keys[j])
+	})
+
+	if len(keys) == 1 && keys[0] == addrs.NoKey {
+		// There's nothing more to say about a single instance.
+		return buf.String()
+	}
+	for _, k := range keys {
+		l, r := c.Local.Instances[k], c.Remote.Instances[k]
+		addr := c.Addr.Instance(k)
+		switch {
+		case r == nil:
+			fmt.Fprintf(&buf, "      + %s (only in the local state)\n", addr)
+		case l == nil:
+			fmt.Fprintf(&buf, "      - %s (only in the remote state)\n", addr)
+		case !reflect.DeepEqual(l, r):
+			fmt.Fprintf(&buf, "      ~ %s\n", addr)
+		}
+	}
+	return buf.String()
+}
+
+// mergeStates returns a copy of the remote state with the resources of the
+// given conflicts replaced by their version in the local state, for each
+// conflict that choices resolves to stateMergeLocal.
+//
+// The root module output values and check results are kept from the remote
+// state, because they are updated by the next apply anyway.
+func mergeStates(remote *states.State, conflicts []stateResourceConflict, choices map[string]stateMergeSide) *states.State {
+	merged := remote.DeepCopy()
+	for _, c := range conflicts {
+		if choices[c.Addr.String()] != stateMergeLocal {
+			continue
+		}
+		ms := merged.EnsureModule(c.Addr.Module)
+		if c.Local != nil {
+			ms.Resources[c.Addr.Resource.String()] = c.Local.DeepCopy()
+			continue
+		}
+		ms.RemoveResource(c.Addr.Resource)
+		if !ms.Addr.IsRoot() && len(ms.Resources) == 0 {
+			merged.RemoveModule(ms.Addr)
+		}
+	}
+	return merged
+}
+
+// resolveStateConflicts decides, for each of the given conflicts, which state
+// to keep it from. Resources named by keepLocal or keepRemote are resolved
+// accordingly, the others use the given strategy, or are asked interactively
+// if the strategy is empty.
+func (c *StatePushCommand) resolveStateConflicts(conflicts []stateResourceConflict, strategy stateMergeSide, keepLocal, keepRemote []string) (map[string]stateMergeSide, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	choices := make(map[string]stateMergeSide, len(conflicts))
+	known := make(map[string]bool, len(conflicts))
+	for _, conflict := range conflicts {
+		known[conflict.Addr.String()] = true
+	}
+	for _, opt := range []struct {
+		name  string
+		addrs []string
+		side  stateMergeSide
+	}{
+		{"-keep-local", keepLocal, stateMergeLocal},
+		{"-keep-remote", keepRemote, stateMergeRemote},
+	} {
+		for _, raw := range opt.addrs {
+			addr, addrDiags := addrs.ParseAbsResourceStr(raw)
+			diags = diags.Append(addrDiags)
+			if addrDiags.HasErrors() {
+				continue
+			}
+			key := addr.String()
+			if !known[key] {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid resource address",
+					fmt.Sprintf("The %s option refers to %s, which doesn't differ between the local and remote states.", opt.name, key),
+				))
+				continue
+			}
+			if prev, exists := choices[key]; exists && prev != opt.side {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Conflicting merge options",
+					fmt.Sprintf("The resource %s is selected by both -keep-local and -keep-remote.", key),
+				))
+				continue
+			}
+			choices[key] = opt.side
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var unresolved []string
+	for _, conflict := range conflicts {
+		key := conflict.Addr.String()
+		if _, exists := choices[key]; exists {
+			continue
+		}
+		if strategy != "" {
+			choices[key] = strategy
+			continue
+		}
+		if !c.Input() {
+			unresolved = append(unresolved, key)
+			continue
+		}
+
+		side, err := c.askStateMergeSide(conflict)
+		if err != nil {
+			diags = diags.Append(err)
+			return nil, diags
+		}
+		choices[key] = side
+	}
+
+	if len(unresolved) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unresolved state conflicts",
+			fmt.Sprintf(
+				"Interactive input is disabled, so the following resources must be resolved with -keep-local, -keep-remote or -merge-strategy:\n  %s",
+				strings.Join(unresolved, "\n  "),
+			),
+		))
+		return nil, diags
+	}
+
+	return choices, diags
+}
+
+func (c *StatePushCommand) askStateMergeSide(conflict stateResourceConflict) (stateMergeSide, error) {
+	addr := conflict.Addr.String()
+	for i := 0; i < 3; i++ {
+		v, err := c.UIInput().Input(context.Background(), &tofu.InputOpts{
+			Id:          "state-push-merge-" + addr,
+			Query:       fmt.Sprintf("Keep which version of %s?", addr),
+			Description: `Enter "local" to keep the resource as recorded in the state being pushed, or "remote" to keep it as recorded in the remote state.`,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error asking how to resolve %s: %w", addr, err)
+		}
+		if side, ok := parseStateMergeSide(v); ok {
+			return side, nil
+		}
+	}
+	return "", fmt.Errorf("No valid choice was given for %s. Enter either \"local\" or \"remote\".", addr)
+}
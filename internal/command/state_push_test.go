@@ -7,14 +7,18 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/backend/remote-state/inmem"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
 )
 
 func TestStatePush_empty(t *testing.T) {
@@ -320,3 +324,184 @@ func TestStatePush_checkRequiredVersion(t *testing.T) {
 		t.Fatalf("output should not point to met version constraint, but is:\n\n%s", errStr)
 	}
 }
+
+func TestStatePush_merge(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-push-merge"), td)
+	defer testChdir(t, td)()
+
+	p := testProvider()
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-merge",
+		"-keep-local=test_instance.a",
+		"-merge-strategy=remote",
+		"replace.tfstate",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"cannot import state with serial 2 over newer state with serial 3",
+		"  ~ test_instance.a\n",
+		"  - test_instance.b (only in the remote state)\n",
+		"  + test_instance.d (only in the local state)\n",
+		"keeping 1 conflicting resource(s) from the local state and 2 from the remote state",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "test_instance.c") {
+		t.Errorf("output mentions the identical resource test_instance.c\n%s", output)
+	}
+
+	sf := testStatePushReadFile(t, "local-state.tfstate")
+	if got, want := sf.Lineage, "hello"; got != want {
+		t.Errorf("wrong lineage %q; want %q", got, want)
+	}
+	if sf.Serial <= 3 {
+		t.Errorf("wrong serial %d; want a successor of the remote serial 3", sf.Serial)
+	}
+	testStatePushResourceIDs(t, sf.State, map[string]string{
+		"test_instance.a": "a-local",
+		"test_instance.b": "b-remote",
+		"test_instance.c": "c",
+	})
+}
+
+func TestStatePush_mergeInteractive(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-push-merge"), td)
+	defer testChdir(t, td)()
+
+	defer testInputMap(t, map[string]string{
+		"state-push-merge-test_instance.a": "remote",
+		"state-push-merge-test_instance.b": "local",
+		"state-push-merge-test_instance.d": "local",
+	})()
+
+	p := testProvider()
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	if code := c.Run([]string{"-merge", "replace.tfstate"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	sf := testStatePushReadFile(t, "local-state.tfstate")
+	testStatePushResourceIDs(t, sf.State, map[string]string{
+		"test_instance.a": "a-remote",
+		"test_instance.c": "c",
+		"test_instance.d": "d-local",
+	})
+}
+
+func TestStatePush_mergeUnresolved(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-push-merge"), td)
+	defer testChdir(t, td)()
+
+	expected := testStateRead(t, "local-state.tfstate")
+
+	p := testProvider()
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+
+	args := []string{"-merge", "-input=false", "-keep-remote=test_instance.b", "replace.tfstate"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	gotErr := ui.ErrorWriter.String()
+	if !strings.Contains(gotErr, "Unresolved state conflicts") || !strings.Contains(gotErr, "test_instance.a") || !strings.Contains(gotErr, "test_instance.d") {
+		t.Errorf("wrong error\n%s", gotErr)
+	}
+
+	actual := testStateRead(t, "local-state.tfstate")
+	if !actual.Equal(expected) {
+		t.Fatalf("remote state was modified: %#v", actual)
+	}
+}
+
+func TestStatePush_mergeRequiresMerge(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("state-push-merge"), td)
+	defer testChdir(t, td)()
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-keep-local=test_instance.a", "replace.tfstate"}); code != 1 {
+		t.Fatalf("bad: %d", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "require -merge"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want to contain %q", got, want)
+	}
+}
+
+func testStatePushReadFile(t *testing.T, path string) *statefile.File {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sf, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sf
+}
+
+// testStatePushResourceIDs checks that the given state contains exactly the
+// given resources, with the given ids.
+func testStatePushResourceIDs(t *testing.T, state *states.State, want map[string]string) {
+	t.Helper()
+
+	got := make(map[string]string)
+	for _, rs := range state.RootModule().Resources {
+		for _, is := range rs.Instances {
+			var attrs map[string]interface{}
+			if err := json.Unmarshal(is.Current.AttrsJSON, &attrs); err != nil {
+				t.Fatal(err)
+			}
+			got[rs.Addr.String()], _ = attrs["id"].(string)
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong resources\n%s", diff)
+	}
+}
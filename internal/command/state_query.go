@@ -0,0 +1,331 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// StateQueryCommand is a Command implementation that lists the resource
+// instance objects in the state that match a filter expression.
+type StateQueryCommand struct {
+	Meta
+	StateMeta
+}
+
+// stateQueryResult is the JSON representation of a resource instance object
+// matched by "tofu state query". Its properties are named after those of the
+// resources in the JSON state representation.
+type stateQueryResult struct {
+	Address       string          `json:"address"`
+	ModuleAddress string          `json:"module_address,omitempty"`
+	Mode          string          `json:"mode"`
+	Type          string          `json:"type"`
+	Name          string          `json:"name"`
+	Index         json.RawMessage `json:"index,omitempty"`
+	ProviderName  string          `json:"provider_name"`
+	DeposedKey    string          `json:"deposed_key,omitempty"`
+	Tainted       bool            `json:"tainted,omitempty"`
+	Values        json.RawMessage `json:"values"`
+
+	addr addrs.AbsResourceInstance
+}
+
+func (c *StateQueryCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var statePath string
+	cmdFlags := c.Meta.defaultFlagSet("state query")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.StringVar(&statePath, "state", "", "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("Exactly one argument expected: the query expression.\n")
+		return cli.RunResultHelp
+	}
+
+	if statePath != "" {
+		c.Meta.statePath = statePath
+	}
+
+	var diags tfdiags.Diagnostics
+
+	src := []byte(args[0])
+	c.registerSynthConfigSource("<query>", src)
+	expr, hclDiags := hclsyntax.ParseExpression(src, "<query>", hcl.InitialPos)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := c.Encryption()
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	// Get the state
+	env, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	stateMgr, err := b.StateMgr(env)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return 1
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		c.Ui.Error(errStateNotFound)
+		return 1
+	}
+
+	results, queryDiags := stateQuery(state, expr)
+	diags = diags.Append(queryDiags)
+	if queryDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.showDiagnostics(diags)
+
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal query results to JSON: %s", err))
+		return 1
+	}
+	c.Ui.Output(string(out))
+	return 0
+}
+
+// stateQuery evaluates the given filter expression for each resource instance
+// object in the state, returning the objects for which it's true.
+//
+// The attributes of different resource types differ, so an expression that
+// fails for some objects, such as by referring to an attribute they don't
+// have, doesn't match those objects. It's only an error if the expression
+// fails for all of them, which usually means that the expression is wrong.
+func stateQuery(state *states.State, expr hcl.Expression) ([]stateQueryResult, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	funcs := (&lang.Scope{BaseDir: "."}).Functions()
+
+	results := []stateQueryResult{}
+	var evaluated, failed int
+	var firstDiags hcl.Diagnostics
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				addr := rs.Addr.Instance(key)
+				query := func(obj *states.ResourceInstanceObjectSrc, dk states.DeposedKey) {
+					values := cty.EmptyObjectVal
+					if len(obj.AttrsJSON) > 0 {
+						ty, err := ctyjson.ImpliedType(obj.AttrsJSON)
+						if err == nil {
+							values, err = ctyjson.Unmarshal(obj.AttrsJSON, ty)
+						}
+						if err != nil {
+							diags = diags.Append(tfdiags.Sourceless(
+								tfdiags.Error,
+								"Invalid resource instance object",
+								fmt.Sprintf("The attributes of %s in the state are invalid: %s.", addr, err),
+							))
+							return
+						}
+					}
+
+					index := cty.NullVal(cty.DynamicPseudoType)
+					if key != addrs.NoKey {
+						index = key.Value()
+					}
+					evalCtx := &hcl.EvalContext{
+						Variables: map[string]cty.Value{
+							"address":     cty.StringVal(addr.String()),
+							"module":      cty.StringVal(addr.Module.String()),
+							"mode":        cty.StringVal(stateQueryMode(addr.Resource.Resource.Mode)),
+							"type":        cty.StringVal(addr.Resource.Resource.Type),
+							"name":        cty.StringVal(addr.Resource.Resource.Name),
+							"index":       index,
+							"provider":    cty.StringVal(rs.ProviderConfig.Provider.String()),
+							"deposed_key": cty.StringVal(string(dk)),
+							"tainted":     cty.BoolVal(obj.Status == states.ObjectTainted),
+							"values":      values,
+						},
+						Functions: funcs,
+					}
+
+					evaluated++
+					match, hclDiags := expr.Value(evalCtx)
+					if !hclDiags.HasErrors() {
+						var err error
+						match, err = convert.Convert(match, cty.Bool)
+						if err != nil || match.IsNull() || !match.IsWhollyKnown() {
+							hclDiags = hclDiags.Append(&hcl.Diagnostic{
+								Severity: hcl.DiagError,
+								Summary:  "Invalid query result",
+								Detail:   "The query expression must return either true or false.",
+								Subject:  expr.Range().Ptr(),
+							})
+						}
+					}
+					if hclDiags.HasErrors() {
+						if failed == 0 {
+							firstDiags = hclDiags
+						}
+						failed++
+						return
+					}
+					if match.False() {
+						return
+					}
+
+					result := stateQueryResult{
+						Address:      addr.String(),
+						Mode:         stateQueryMode(addr.Resource.Resource.Mode),
+						Type:         addr.Resource.Resource.Type,
+						Name:         addr.Resource.Resource.Name,
+						ProviderName: rs.ProviderConfig.Provider.String(),
+						DeposedKey:   string(dk),
+						Tainted:      obj.Status == states.ObjectTainted,
+						Values:       json.RawMessage(obj.AttrsJSON),
+						addr:         addr,
+					}
+					if !addr.Module.IsRoot() {
+						result.ModuleAddress = addr.Module.String()
+					}
+					if key != addrs.NoKey {
+						result.Index, _ = ctyjson.Marshal(index, index.Type())
+					}
+					if len(result.Values) == 0 {
+						result.Values = json.RawMessage("{}")
+					}
+					results = append(results, result)
+				}
+				if is.Current != nil {
+					query(is.Current, states.NotDeposed)
+				}
+				for dk, obj := range is.Deposed {
+					query(obj, dk)
+				}
+			}
+		}
+	}
+
+	if evaluated > 0 && failed == evaluated {
+		diags = diags.Append(firstDiags)
+		return nil, diags
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].addr.Equal(results[j].addr) {
+			return results[i].addr.Less(results[j].addr)
+		}
+		return results[i].DeposedKey < results[j].DeposedKey
+	})
+	return results, diags
+}
+
+// stateQueryMode returns the name of the given resource mode as used in the
+// JSON state representation.
+func stateQueryMode(mode addrs.ResourceMode) string {
+	if mode == addrs.DataResourceMode {
+		return "data"
+	}
+	return "managed"
+}
+
+func (c *StateQueryCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] state query [options] EXPRESSION
+
+  Find the resource instances in the OpenTofu state that match a filter
+  expression, and print them as a JSON array.
+
+  The expression uses the OpenTofu language expression syntax and functions,
+  and is evaluated for each resource instance object in the state with the
+  following variables:
+
+    address      The address of the resource instance.
+    module       The address of the module instance, or "" for the root
+                 module.
+    mode         "managed" for managed resources or "data" for data
+                 resources.
+    type         The resource type.
+    name         The resource name.
+    index        The instance key, or null if the resource has no count or
+                 for_each.
+    provider     The source address of the provider.
+    deposed_key  The deposed key of a deposed object, or "".
+    tainted      Whether the object is tainted.
+    values       The attributes of the object.
+
+  The objects for which the expression returns true are printed. Objects for
+  which the expression fails, such as because they don't have an attribute
+  that it refers to, don't match. For example:
+
+    tofu state query 'type == "aws_instance" && values.tags.env == "prod"'
+
+  The printed attribute values include any sensitive values.
+
+Options:
+
+  -state=statefile    Path to a OpenTofu state file to use to look
+                      up OpenTofu-managed resources. By default, OpenTofu
+                      will consult the state of the currently-selected
+                      workspace.
+
+  -var 'foo=bar'      Set a value for one of the input variables in the root
+                      module of the configuration. Use this option more than
+                      once to set more than one variable.
+
+  -var-file=filename  Load variable values from the given file, in addition
+                      to the default files terraform.tfvars and *.auto.tfvars.
+                      Use this option more than once to include more than one
+                      variables file.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateQueryCommand) Synopsis() string {
+	return "Find resources in the state with a filter expression"
+}
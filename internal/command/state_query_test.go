@@ -0,0 +1,188 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestStateQuery(t *testing.T) {
+	testProviderAddr := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	resourceAddr := func(typeName, name string, key addrs.InstanceKey, module addrs.ModuleInstance) addrs.AbsResourceInstance {
+		return addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: typeName,
+			Name: name,
+		}.Instance(key).Absolute(module)
+	}
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, env := range []string{"prod", "dev"} {
+			s.SetResourceInstanceCurrent(
+				resourceAddr("test_instance", "web", addrs.StringKey(env), addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"web-` + env + `","tags":{"env":"` + env + `"}}`),
+					Status:    states.ObjectReady,
+				},
+				testProviderAddr, addrs.NoKey,
+			)
+		}
+		s.SetResourceInstanceCurrent(
+			resourceAddr("test_instance", "db", addrs.NoKey, addrs.RootModuleInstance.Child("data", addrs.NoKey)),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"db","tags":{"env":"prod"}}`),
+				Status:    states.ObjectTainted,
+			},
+			testProviderAddr, addrs.NoKey,
+		)
+		// This resource type has no tags, so the queries below fail for it.
+		s.SetResourceInstanceCurrent(
+			resourceAddr("test_thing", "other", addrs.NoKey, addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"other"}`),
+				Status:    states.ObjectReady,
+			},
+			testProviderAddr, addrs.NoKey,
+		)
+	})
+	statePath := testStateFile(t, state)
+
+	tests := map[string]struct {
+		query string
+		want  []string
+	}{
+		"attribute": {
+			query: `values.tags.env == "prod"`,
+			want:  []string{`test_instance.web["prod"]`, `module.data.test_instance.db`},
+		},
+		"type and attribute": {
+			query: `type == "test_instance" && values.tags.env == "dev"`,
+			want:  []string{`test_instance.web["dev"]`},
+		},
+		"functions": {
+			query: `startswith(module, "module.") || tainted`,
+			want:  []string{`module.data.test_instance.db`},
+		},
+		"no matches": {
+			query: `name == "nope"`,
+			want:  []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := &StateQueryCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+				},
+			}
+
+			if code := c.Run([]string{"-state", statePath, test.query}); code != 0 {
+				t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+			}
+
+			var results []stateQueryResult
+			if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &results); err != nil {
+				t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+			}
+			got := []string{}
+			for _, result := range results {
+				got = append(got, result.Address)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("wrong results\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStateQuery_output(t *testing.T) {
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "foo",
+			}.Instance(addrs.IntKey(0)).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"bar"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	})
+	statePath := testStateFile(t, state)
+
+	ui := cli.NewMockUi()
+	c := &StateQueryCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-state", statePath, `values.id == "bar"`}); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `[
+  {
+    "address": "test_instance.foo[0]",
+    "mode": "managed",
+    "type": "test_instance",
+    "name": "foo",
+    "index": 0,
+    "provider_name": "registry.opentofu.org/hashicorp/test",
+    "values": {
+      "id": "bar"
+    }
+  }
+]`
+	if got := strings.TrimSpace(ui.OutputWriter.String()); got != want {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStateQuery_invalidQuery(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	tests := map[string]string{
+		`type ==`:       "Missing expression",
+		`typo == "foo"`: "Unknown variable",
+		`name`:          "Invalid query result",
+	}
+	for query, want := range tests {
+		t.Run(query, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := &StateQueryCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+				},
+			}
+			if code := c.Run([]string{"-state", statePath, query}); code != 1 {
+				t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, want) {
+				t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
@@ -27,8 +27,10 @@ type StateRmCommand struct {
 func (c *StateRmCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	var dryRun bool
+	var generateConfigOut string
 	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state rm")
 	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
+	cmdFlags.StringVar(&generateConfigOut, "generate-config-out", "", "generate-config-out")
 	cmdFlags.StringVar(&c.backupPath, "backup", "-", "backup")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
 	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
@@ -148,6 +150,15 @@ func (c *StateRmCommand) Run(args []string) int {
 		return 1
 	}
 
+	if generateConfigOut != "" {
+		blocks, genDiags := removedBlocksForState(state, addrs)
+		diags = diags.Append(genDiags)
+		if err := appendGeneratedConfig(generateConfigOut, "state rm", blocks); err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateGenerateConfig, generateConfigOut, err))
+			return 1
+		}
+	}
+
 	if len(diags) > 0 && isCount != 0 {
 		c.showDiagnostics(diags)
 	}
@@ -191,6 +202,11 @@ Options:
   -backup=PATH            Path where OpenTofu should write the backup
                           state.
 
+  -generate-config-out=PATH
+                          Append removed blocks for the removed resources to
+                          the given file, creating it if it doesn't exist, so
+                          that the configuration records their removal.
+
   -lock=false             Don't hold a state lock during the operation. This is
                           dangerous if others might concurrently run commands
                           against the same workspace.
@@ -221,6 +237,11 @@ func (c *StateRmCommand) Synopsis() string {
 	return "Remove instances from the state"
 }
 
+const errStateGenerateConfig = `Error writing the generated configuration to %s: %s
+
+The state was saved, but the configuration blocks that record the change
+were not written. Add them to the configuration yourself.`
+
 const errStateRmPersist = `Error saving the state: %s
 
 The state was not saved. No items were removed from the persisted
@@ -86,6 +86,69 @@ func TestStateRm(t *testing.T) {
 	testStateOutput(t, backups[0], testStateRmOutputOriginal)
 }
 
+func TestStateRm_generateConfigOut(t *testing.T) {
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, addr := range []string{"test_instance.foo", "test_instance.bar[0]", "test_instance.bar[1]"} {
+			s.SetResourceInstanceCurrent(
+				mustResourceInstanceAddr(addr),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"bar","foo":"value","bar":"value"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+	statePath := testStateFile(t, state)
+	configPath := filepath.Join(t.TempDir(), "maintenance.tf")
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateRmCommand{
+		StateMeta{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-generate-config-out", configPath,
+		"test_instance.foo",
+		"test_instance.bar[0]",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Generated by "tofu state rm"
+removed {
+  from = test_instance.foo
+}
+`
+	if string(got) != want {
+		t.Fatalf("wrong generated configuration\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// test_instance.bar[1] is still in the state, so a removed block for
+	// the whole resource would be wrong.
+	if output := ui.ErrorWriter.String(); !strings.Contains(output, "Cannot generate removed block") {
+		t.Fatalf("missing warning about test_instance.bar\n%s", output)
+	}
+}
+
 func TestStateRmNotChildModule(t *testing.T) {
 	state := states.BuildState(func(s *states.SyncState) {
 		s.SetResourceInstanceCurrent(
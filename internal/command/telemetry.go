@@ -6,12 +6,54 @@
 package command
 
 import (
+	"context"
+	"path"
+	"time"
+
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/opentofu/opentofu/internal/addrs"
 )
 
 var tracer trace.Tracer
 
+// providerRPCDuration records how long each gRPC call to a provider plugin
+// takes, including the time the provider spends talking to its remote API.
+var providerRPCDuration metric.Float64Histogram
+
 func init() {
 	tracer = otel.Tracer("github.com/opentofu/opentofu/internal/command")
+
+	meter := otel.Meter("github.com/opentofu/opentofu/internal/command")
+	var err error
+	providerRPCDuration, err = meter.Float64Histogram(
+		"opentofu.provider.rpc.duration",
+		metric.WithDescription("Duration of calls to provider plugins."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		// Can only fail if the instrument definition above is invalid.
+		panic(err)
+	}
+}
+
+// providerMetricsDialOption returns a gRPC dial option that records the
+// duration of each call to the given provider in providerRPCDuration.
+func providerMetricsDialOption(provider addrs.Provider) grpc.DialOption {
+	providerAttr := attribute.String("provider", provider.String())
+	return grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		providerRPCDuration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), metric.WithAttributes(
+			providerAttr,
+			attribute.String("rpc.method", path.Base(method)),
+			attribute.String("rpc.grpc.status_code", status.Code(err).String()),
+		))
+		return err
+	})
 }
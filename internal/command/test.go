@@ -11,8 +11,10 @@ import (
 	"fmt"
 	"log"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
@@ -24,10 +26,12 @@ import (
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/dag"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/logging"
 	"github.com/opentofu/opentofu/internal/moduletest"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -58,6 +62,10 @@ Usage: tofu [global options] test [options]
   testing infrastructure on completion. Monitor the output carefully to ensure
   this cleanup process is successful.
 
+  Test files and run blocks that set keep_on_failure = true leave their
+  infrastructure in place when the test file fails, so it can be
+  investigated. Use "tofu test clean" to destroy it afterwards.
+
 Options:
 
   -compact-warnings     If OpenTofu produces any warnings that are not
@@ -81,6 +89,10 @@ Options:
 
   -no-color             If specified, output won't contain any color.
 
+  -parallel-runs=n      Limit the number of run blocks marked with
+                        parallel = true that execute concurrently within a
+                        test file. Defaults to 10.
+
   -test-directory=path  Set the OpenTofu test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
@@ -240,6 +252,16 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	// Don't use encryption during testing
 	opts.Encryption = encryption.Disabled()
 
+	manifest, err := moduletest.LoadManifest(c.testManifestDir())
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to load test state manifest",
+			fmt.Sprintf("OpenTofu could not read the manifest of test infrastructure kept by previous test executions: %s.", err)))
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
 	// Print out all the diagnostics we have from the setup. These will just be
 	// warnings, and we want them out of the way before we start the actual
 	// testing.
@@ -275,7 +297,15 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		Cancelled: false,
 		Stopped:   false,
 
-		Verbose: args.Verbose,
+		Verbose:  args.Verbose,
+		Manifest: manifest,
+
+		ParallelRuns: args.ParallelRuns,
+		configs: &testConfigPool{
+			load: func() (*configs.Config, tfdiags.Diagnostics) {
+				return c.loadConfigWithTests(".", args.TestDirectory)
+			},
+		},
 	}
 
 	view.Abstract(&suite)
@@ -288,6 +318,13 @@ func (c *TestCommand) Run(rawArgs []string) int {
 		defer cancel()
 
 		runner.Start(ctx)
+
+		if err := manifest.Save(); err != nil {
+			view.Diagnostics(nil, nil, tfdiags.Diagnostics{}.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to save test state manifest",
+				fmt.Sprintf("OpenTofu could not update the manifest of kept test infrastructure: %s. The state files for any kept infrastructure are in %s.", err, c.testManifestDir()))))
+		}
 	}()
 
 	// Wait for the operation to complete, or for an interrupt to occur.
@@ -335,6 +372,12 @@ func (c *TestCommand) Run(rawArgs []string) int {
 	return 0
 }
 
+// testManifestDir returns the directory that holds the test state manifest,
+// and the state files it references, for the current working directory.
+func (c *TestCommand) testManifestDir() string {
+	return filepath.Join(c.DataDir(), "test")
+}
+
 // test runner
 
 type TestSuiteRunner struct {
@@ -366,6 +409,19 @@ type TestSuiteRunner struct {
 
 	// Verbose tells the runner to print out plan files during each test run.
 	Verbose bool
+
+	// Manifest records the states that are kept, instead of destroyed, when a
+	// test file fails with keep_on_failure set. If nil, all states are
+	// destroyed regardless of keep_on_failure.
+	Manifest *moduletest.Manifest
+
+	// ParallelRuns is the maximum number of parallel run blocks that can
+	// execute at the same time.
+	ParallelRuns int
+
+	// configs hands out the copies of the configuration that run blocks
+	// executing in parallel need.
+	configs *testConfigPool
 }
 
 func (runner *TestSuiteRunner) Start(ctx context.Context) {
@@ -403,6 +459,15 @@ type TestFileRunner struct {
 	Suite *TestSuiteRunner
 
 	States map[string]*TestFileState
+
+	// mu protects States, the status of the file being executed, and
+	// aborted, while run blocks execute in parallel.
+	mu sync.Mutex
+
+	// aborted is set when the remaining run blocks can't execute, either
+	// because of a hard stop or because a run block failed in a way that
+	// leaves no usable state.
+	aborted bool
 }
 
 type TestFileState struct {
@@ -414,97 +479,161 @@ func (runner *TestFileRunner) ExecuteTestFile(ctx context.Context, file *modulet
 	log.Printf("[TRACE] TestFileRunner: executing test file %s", file.Name)
 
 	file.Status = file.Status.Merge(moduletest.Pass)
+
+	// Run blocks execute in the order they're declared, except that parallel
+	// run blocks can execute at the same time as each other once the run
+	// blocks they depend on have completed.
+	parallelRuns := runner.Suite.ParallelRuns
+	if parallelRuns <= 0 {
+		parallelRuns = 1
+	}
+	sem := tofu.NewSemaphore(parallelRuns)
+	testRunGraph(file).Walk(func(v dag.Vertex) tfdiags.Diagnostics {
+		sem.Acquire()
+		defer sem.Release()
+		runner.executeRun(ctx, v.(*moduletest.Run), file)
+		return nil
+	})
+	if runner.aborted {
+		return
+	}
+
+	runner.Suite.View.File(file)
 	for _, run := range file.Runs {
-		if runner.Suite.Cancelled {
-			// This means a hard stop has been requested, in this case we don't
-			// even stop to mark future tests as having been skipped. They'll
-			// just show up as pending in the printed summary.
-			return
-		}
+		runner.Suite.View.Run(run, file)
+	}
+}
 
-		if runner.Suite.Stopped {
-			// Then the test was requested to be stopped, so we just mark each
-			// following test as skipped and move on.
-			run.Status = moduletest.Skip
-			continue
-		}
+// executeRun executes a single run block within the given file, once all the
+// run blocks it depends on have completed, and records the state it leaves
+// behind.
+func (runner *TestFileRunner) executeRun(ctx context.Context, run *moduletest.Run, file *moduletest.File) {
+	runner.mu.Lock()
+	switch {
+	case runner.Suite.Cancelled, runner.aborted:
+		// This means a hard stop has been requested, in this case we don't
+		// even stop to mark future tests as having been skipped. They'll
+		// just show up as pending in the printed summary.
+		runner.aborted = true
+		runner.mu.Unlock()
+		return
 
-		if file.Status == moduletest.Error {
-			// If the overall test file has errored, we don't keep trying to
-			// execute tests. Instead, we mark all remaining run blocks as
-			// skipped.
-			run.Status = moduletest.Skip
-			continue
-		}
+	case runner.Suite.Stopped:
+		// Then the test was requested to be stopped, so we just mark each
+		// following test as skipped and move on.
+		run.Status = moduletest.Skip
+		runner.mu.Unlock()
+		return
 
-		key := MainStateIdentifier
-		config := runner.Suite.Config
-		if run.Config.ConfigUnderTest != nil {
-			config = run.Config.ConfigUnderTest
-			// Then we need to load an alternate state and not the main one.
+	case file.Status == moduletest.Error:
+		// If the overall test file has errored, we don't keep trying to
+		// execute tests. Instead, we mark all remaining run blocks as
+		// skipped.
+		run.Status = moduletest.Skip
+		runner.mu.Unlock()
+		return
+	}
 
-			key = run.Config.Module.Source.String()
-			if key == MainStateIdentifier {
-				// This is bad. It means somehow the module we're loading has
-				// the same key as main state and we're about to corrupt things.
-
-				run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid module source",
-					Detail:   fmt.Sprintf("The source for the selected module evaluated to %s which should not be possible. This is a bug in OpenTofu - please report it!", key),
-					Subject:  run.Config.Module.DeclRange.Ptr(),
-				})
-
-				run.Status = moduletest.Error
-				file.Status = moduletest.Error
-				continue // Abort!
-			}
+	key := MainStateIdentifier
+	config := runner.Suite.Config
+	if run.Config.ConfigUnderTest != nil {
+		config = run.Config.ConfigUnderTest
+		// Then we need to load an alternate state and not the main one.
 
-			if _, exists := runner.States[key]; !exists {
-				runner.States[key] = &TestFileState{
-					Run:   nil,
-					State: states.NewState(),
-				}
-			}
+		key = run.Config.Module.Source.String()
+		if key == MainStateIdentifier {
+			// This is bad. It means somehow the module we're loading has
+			// the same key as main state and we're about to corrupt things.
+
+			run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid module source",
+				Detail:   fmt.Sprintf("The source for the selected module evaluated to %s which should not be possible. This is a bug in OpenTofu - please report it!", key),
+				Subject:  run.Config.Module.DeclRange.Ptr(),
+			})
+
+			run.Status = moduletest.Error
+			file.Status = moduletest.Error
+			runner.mu.Unlock()
+			return // Abort!
 		}
 
-		state, updatedState := runner.ExecuteTestRun(ctx, run, file, runner.States[key].State, config)
-		if updatedState {
-			var err error
-
-			// We need to simulate state serialization between multiple runs
-			// due to its side effects. One of such side effects is removal
-			// of destroyed non-root module outputs. This is not handled
-			// during graph walk since those values are not stored in the
-			// state file. This is more of a weird workaround instead of a
-			// proper fix, unfortunately.
-			state, err = simulateStateSerialization(state)
-			if err != nil {
-				run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Failure during state serialization",
-					Detail:   err.Error(),
-				})
-
-				// We cannot reuse state later so that's a hard stop.
-				return
+		if _, exists := runner.States[key]; !exists {
+			runner.States[key] = &TestFileState{
+				Run:   nil,
+				State: states.NewState(),
 			}
-
-			// Only update the most recent run and state if the state was
-			// actually updated by this change. We want to use the run that
-			// most recently updated the tracked state as the cleanup
-			// configuration.
-			runner.States[key].State = state
-			runner.States[key].Run = run
 		}
+	}
+	state := runner.States[key].State
+	runner.mu.Unlock()
 
+	// Run blocks executing in parallel each need their own copy of the
+	// configuration, because it's modified while the run block executes.
+	config, release, configDiags := runner.Suite.configs.Acquire(config, file, run)
+	defer release()
+	if configDiags.HasErrors() {
+		run.Diagnostics = run.Diagnostics.Append(configDiags)
+		run.Status = moduletest.Error
+		runner.mu.Lock()
 		file.Status = file.Status.Merge(run.Status)
+		runner.mu.Unlock()
+		return
 	}
 
-	runner.Suite.View.File(file)
-	for _, run := range file.Runs {
-		runner.Suite.View.Run(run, file)
+	state, updatedState := runner.ExecuteTestRun(ctx, run, file, state, config)
+
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+
+	if updatedState {
+		var err error
+
+		// We need to simulate state serialization between multiple runs
+		// due to its side effects. One of such side effects is removal
+		// of destroyed non-root module outputs. This is not handled
+		// during graph walk since those values are not stored in the
+		// state file. This is more of a weird workaround instead of a
+		// proper fix, unfortunately.
+		state, err = simulateStateSerialization(state)
+		if err != nil {
+			run.Diagnostics = run.Diagnostics.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failure during state serialization",
+				Detail:   err.Error(),
+			})
+
+			// We cannot reuse state later so that's a hard stop.
+			runner.aborted = true
+			return
+		}
+
+		// Only update the most recent run and state if the state was
+		// actually updated by this change. We want to use the run that
+		// most recently updated the tracked state as the cleanup
+		// configuration. We replace the entry rather than updating it so
+		// that run blocks executing in parallel can keep using the
+		// snapshot returned by currentStates.
+		runner.States[key] = &TestFileState{
+			Run:   run,
+			State: state,
+		}
 	}
+
+	file.Status = file.Status.Merge(run.Status)
+}
+
+// currentStates returns a copy of the states tracked for the file, which
+// stays consistent while other run blocks execute in parallel.
+func (runner *TestFileRunner) currentStates() map[string]*TestFileState {
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+
+	ret := make(map[string]*TestFileState, len(runner.States))
+	for key, state := range runner.States {
+		ret[key] = state
+	}
+	return ret
 }
 
 func (runner *TestFileRunner) ExecuteTestRun(ctx context.Context, run *moduletest.Run, file *moduletest.File, state *states.State, config *configs.Config) (*states.State, bool) {
@@ -682,7 +811,7 @@ func (runner *TestFileRunner) validate(ctx context.Context, config *configs.Conf
 
 	var diags tfdiags.Diagnostics
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(config))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return diags
@@ -722,7 +851,7 @@ func (runner *TestFileRunner) destroy(ctx context.Context, config *configs.Confi
 
 	var diags tfdiags.Diagnostics
 
-	evalCtx, ctxDiags := getEvalContextForTest(runner.States, config, runner.Suite.GlobalVariables)
+	evalCtx, ctxDiags := getEvalContextForTest(runner.currentStates(), config, runner.Suite.GlobalVariables)
 	diags = diags.Append(ctxDiags)
 
 	variables, variableDiags := buildInputVariablesForTest(run, file, config, runner.Suite.GlobalVariables, evalCtx)
@@ -737,7 +866,7 @@ func (runner *TestFileRunner) destroy(ctx context.Context, config *configs.Confi
 		SetVariables: variables,
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(config))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return state, diags
@@ -788,7 +917,7 @@ func (runner *TestFileRunner) plan(ctx context.Context, config *configs.Config,
 	references, referenceDiags := run.GetReferences()
 	diags = diags.Append(referenceDiags)
 
-	evalCtx, ctxDiags := getEvalContextForTest(runner.States, config, runner.Suite.GlobalVariables)
+	evalCtx, ctxDiags := getEvalContextForTest(runner.currentStates(), config, runner.Suite.GlobalVariables)
 	diags = diags.Append(ctxDiags)
 
 	variables, variableDiags := buildInputVariablesForTest(run, file, config, runner.Suite.GlobalVariables, evalCtx)
@@ -814,7 +943,7 @@ func (runner *TestFileRunner) plan(ctx context.Context, config *configs.Config,
 		ExternalReferences: references,
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(config))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return nil, nil, diags
@@ -869,7 +998,7 @@ func (runner *TestFileRunner) apply(ctx context.Context, plan *plans.Plan, state
 		created = append(created, change)
 	}
 
-	tfCtx, ctxDiags := tofu.NewContext(runner.Suite.Opts)
+	tfCtx, ctxDiags := tofu.NewContext(runner.contextOpts(config))
 	diags = diags.Append(ctxDiags)
 	if ctxDiags.HasErrors() {
 		return nil, state, diags
@@ -900,6 +1029,40 @@ func (runner *TestFileRunner) apply(ctx context.Context, plan *plans.Plan, state
 	return tfCtx, updated, diags
 }
 
+// contextOpts returns the options for a context that operates on the given
+// configuration, which must already be transformed for the current run block.
+//
+// If the mock_provider blocks for a provider declare the schemas of their
+// mock resources, the context uses an in-process moduletest.MockProvider in
+// place of the real provider, so the test doesn't need the provider plugin.
+func (runner *TestFileRunner) contextOpts(config *configs.Config) *tofu.ContextOpts {
+	mocked := make(map[addrs.Provider][]*configs.MockResource)
+	for _, pc := range config.Module.ProviderConfigs {
+		if !pc.IsMocked || !configs.MockResourcesHaveSchemas(pc.MockResources) {
+			continue
+		}
+		addr := config.Module.ProviderForLocalConfig(pc.Addr())
+		mocked[addr] = append(mocked[addr], pc.MockResources...)
+	}
+	if len(mocked) == 0 {
+		return runner.Suite.Opts
+	}
+
+	opts := *runner.Suite.Opts
+	opts.Providers = make(map[addrs.Provider]providers.Factory, len(runner.Suite.Opts.Providers)+len(mocked))
+	for addr, factory := range runner.Suite.Opts.Providers {
+		opts.Providers[addr] = factory
+	}
+	for addr, resources := range mocked {
+		opts.Providers[addr] = providers.FactoryFixed(moduletest.NewMockProvider(addr, resources))
+
+		// The global schema cache can hold the schema of the real provider,
+		// which would otherwise take precedence over the mock schemas.
+		providers.SchemaCache.Remove(addr)
+	}
+	return &opts
+}
+
 func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context, run *moduletest.Run, file *moduletest.File, created []*plans.ResourceInstanceChangeSrc) (diags tfdiags.Diagnostics, cancelled bool) {
 	var identifier string
 	if file == nil {
@@ -919,9 +1082,10 @@ func (runner *TestFileRunner) wait(ctx *tofu.Context, runningCtx context.Context
 	handleCancelled := func() {
 		log.Printf("[DEBUG] TestFileRunner: test execution cancelled during %s", identifier)
 
+		current := runner.currentStates()
 		states := make(map[*moduletest.Run]*states.State)
-		states[nil] = runner.States[MainStateIdentifier].State
-		for key, module := range runner.States {
+		states[nil] = current[MainStateIdentifier].State
+		for key, module := range current {
 			if key == MainStateIdentifier {
 				continue
 			}
@@ -1024,36 +1188,90 @@ func (runner *TestFileRunner) Cleanup(ctx context.Context, file *moduletest.File
 			return
 		}
 
-		var diags tfdiags.Diagnostics
-		var runConfig *configs.Config
-
-		isMainState := state.Run.Config.Module == nil
-		if isMainState {
-			runConfig = runner.Suite.Config
-		} else {
-			runConfig = state.Run.Config.ConfigUnderTest
+		if runner.keepState(file, state) {
+			continue
 		}
 
-		reset, configDiags := runConfig.TransformForTest(state.Run.Config, file.Config)
-		diags = diags.Append(configDiags)
-
-		updated := state.State
-		if !diags.HasErrors() {
-			var destroyDiags tfdiags.Diagnostics
-			updated, destroyDiags = runner.destroy(ctx, runConfig, state.State, state.Run, file)
-			diags = diags.Append(destroyDiags)
-		}
+		updated, diags := runner.destroyState(ctx, file, state)
 		runner.Suite.View.DestroySummary(diags, state.Run, file, updated)
 
 		if updated.HasManagedResourceInstanceObjects() {
 			views.SaveErroredTestStateFile(updated, state.Run, file, runner.Suite.View)
 		}
-		reset()
 	}
 }
 
+// destroyState destroys the given state using the configuration of the run
+// block that most recently updated it. It returns whatever is left in the
+// state after the destroy operation.
+func (runner *TestFileRunner) destroyState(ctx context.Context, file *moduletest.File, state *TestFileState) (*states.State, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var runConfig *configs.Config
+
+	isMainState := state.Run.Config.Module == nil
+	if isMainState {
+		runConfig = runner.Suite.Config
+	} else {
+		runConfig = state.Run.Config.ConfigUnderTest
+	}
+
+	reset, configDiags := runConfig.TransformForTest(state.Run.Config, file.Config)
+	defer reset()
+	diags = diags.Append(configDiags)
+
+	updated := state.State
+	if !diags.HasErrors() {
+		var destroyDiags tfdiags.Diagnostics
+		updated, destroyDiags = runner.destroy(ctx, runConfig, state.State, state.Run, file)
+		diags = diags.Append(destroyDiags)
+	}
+	return updated, diags
+}
+
+// keepState records the given state in the test state manifest instead of
+// destroying it, if the test file failed and keep_on_failure applies to the
+// run block that most recently updated the state. It returns true if the
+// state was kept.
+func (runner *TestFileRunner) keepState(file *moduletest.File, state *TestFileState) bool {
+	if runner.Suite.Manifest == nil || state.State.Empty() {
+		return false
+	}
+	if file.Status != moduletest.Fail && file.Status != moduletest.Error {
+		return false
+	}
+	if !state.Run.Config.ShouldKeepOnFailure(file.Config) {
+		return false
+	}
+
+	var diags tfdiags.Diagnostics
+	if err := runner.Suite.Manifest.Record(file.Name, state.Run.Name, testStateKey(state.Run.Config), state.State); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to keep test infrastructure",
+			fmt.Sprintf("OpenTofu could not record the state left by %s/%s in the test state manifest, so the infrastructure will be destroyed instead: %s.", file.Name, state.Run.Name, err)))
+		runner.Suite.View.Diagnostics(state.Run, file, diags)
+		return false
+	}
+
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Test infrastructure kept",
+		fmt.Sprintf("The test file %s failed and keep_on_failure is set, so OpenTofu did not destroy the infrastructure created by %s/%s. Run \"tofu test clean\" to destroy it once you have finished investigating.", file.Name, file.Name, state.Run.Name)))
+	runner.Suite.View.Diagnostics(state.Run, file, diags)
+	return true
+}
+
 // helper functions
 
+// testStateKey returns the key of the state that the given run block operates
+// on within its test file.
+func testStateKey(run *configs.TestRun) string {
+	if run.ConfigUnderTest == nil {
+		return MainStateIdentifier
+	}
+	return run.Module.Source.String()
+}
+
 // buildInputVariablesForTest creates a tofu.InputValues mapping for
 // variable values that are relevant to the config being tested.
 //
@@ -1099,7 +1317,7 @@ func buildInputVariablesForTest(run *moduletest.Run, file *moduletest.File, conf
 		// so we'll just not add anything to the map.
 	}
 
-	return backend.ParseVariableValues(variables, config.Module.Variables)
+	return backend.ParseVariableValues(variables, config.Module.Variables, false)
 }
 
 // getEvalContextForTest constructs an hcl.EvalContext based on the provided map of
@@ -1219,7 +1437,7 @@ func parseAndApplyDefaultValues(unparsedVariables map[string]backend.UnparsedVar
 // the config which must be called so the config can be reused going forward.
 func (runner *TestFileRunner) prepareInputVariablesForAssertions(config *configs.Config, run *moduletest.Run, file *moduletest.File, globals map[string]backend.UnparsedVariableValue) (tofu.InputValues, func(), tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
-	ctx, ctxDiags := getEvalContextForTest(runner.States, config, globals)
+	ctx, ctxDiags := getEvalContextForTest(runner.currentStates(), config, globals)
 	diags = diags.Append(ctxDiags)
 
 	variables := make(map[string]backend.UnparsedVariableValue)
@@ -0,0 +1,319 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// TestCleanCommand is a Command implementation that destroys the test
+// infrastructure kept by keep_on_failure during previous test executions.
+type TestCleanCommand struct {
+	Meta
+}
+
+func (c *TestCleanCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] test clean [options]
+
+  Destroys infrastructure that was kept, instead of destroyed, by a previous
+  execution of "tofu test" because a test file failed with keep_on_failure
+  set.
+
+  The kept states are recorded in a manifest within the working directory's
+  data directory. Each state is destroyed using the configuration of the run
+  block that most recently updated it, so the test files must still contain
+  those run blocks. States that can't be fully destroyed remain in the
+  manifest so this command can be retried.
+
+Options:
+
+  -json                 If specified, machine readable output will be printed in
+                        JSON format
+
+  -no-color             If specified, output won't contain any color.
+
+  -test-directory=path  Set the OpenTofu test directory, defaults to "tests".
+                        This must match the directory used when the
+                        infrastructure was kept.
+
+  -var 'foo=bar'        Set a value for one of the input variables in the root
+                        module of the configuration. Use this option more than
+                        once to set more than one variable.
+
+  -var-file=filename    Load variable values from the given file, in addition
+                        to the default files terraform.tfvars and *.auto.tfvars.
+                        Use this option more than once to include more than one
+                        variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *TestCleanCommand) Synopsis() string {
+	return "Destroy test infrastructure kept after failed tests"
+}
+
+func (c *TestCleanCommand) Run(rawArgs []string) int {
+	var diags tfdiags.Diagnostics
+	ctx := c.CommandContext()
+
+	common, rawArgs := arguments.ParseView(rawArgs)
+	c.View.Configure(common)
+
+	args, diags := arguments.ParseTestClean(rawArgs)
+	if diags.HasErrors() {
+		c.View.Diagnostics(diags)
+		c.View.HelpPrompt("test clean")
+		return 1
+	}
+
+	view := views.NewTest(args.ViewType, c.View)
+
+	test := &TestCommand{Meta: c.Meta}
+	manifest, err := moduletest.LoadManifest(test.testManifestDir())
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to load test state manifest",
+			fmt.Sprintf("OpenTofu could not read the manifest of test infrastructure kept by previous test executions: %s.", err)))
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	if len(manifest.Entries) == 0 {
+		view.CleanSummary(0, 0)
+		return 0
+	}
+
+	var items []rawFlag
+	for _, variable := range args.Vars.All() {
+		items = append(items, rawFlag{
+			Name:  variable.Name,
+			Value: variable.Value,
+		})
+	}
+	c.variableArgs = rawFlags{items: &items}
+
+	variables, variableDiags := c.collectVariableValuesWithTests(args.TestDirectory)
+	diags = diags.Append(variableDiags)
+	if variableDiags.HasErrors() {
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	config, configDiags := c.loadConfigWithTests(".", args.TestDirectory)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	opts, err := c.contextOpts()
+	if err != nil {
+		diags = diags.Append(err)
+		view.Diagnostics(nil, nil, diags)
+		return 1
+	}
+
+	// Don't use encryption during testing
+	opts.Encryption = encryption.Disabled()
+
+	view.Diagnostics(nil, nil, diags)
+
+	// We mirror the interrupt handling of the test command. A 'stop' finishes
+	// destroying the current state and skips the rest, while a 'cancel'
+	// abandons the current destroy operation immediately.
+	stopCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	defer stop()
+	cancelCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	runner := &TestSuiteRunner{
+		command: test,
+
+		Config: config,
+		View:   view,
+
+		GlobalVariables: variables,
+		Opts:            opts,
+
+		CancelledCtx: cancelCtx,
+		StoppedCtx:   stopCtx,
+	}
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-c.ShutdownCh:
+			view.Interrupted()
+			runner.Stopped = true
+			stop()
+		case <-finished:
+			return
+		}
+
+		select {
+		case <-c.ShutdownCh:
+			view.FatalInterrupt()
+			runner.Cancelled = true
+			cancel()
+		case <-finished:
+		}
+	}()
+
+	cleaned, remaining := 0, 0
+
+	// Destroy the most recently kept states first, as they may depend on
+	// infrastructure in states that were kept before them.
+	entries := make([]*moduletest.ManifestEntry, len(manifest.Entries))
+	copy(entries, manifest.Entries)
+	for ix := len(entries) - 1; ix >= 0; ix-- {
+		entry := entries[ix]
+
+		if runner.Stopped || runner.Cancelled {
+			remaining++
+			continue
+		}
+
+		updated, cleanDiags := c.cleanEntry(ctx, runner, manifest, entry)
+		if updated == nil || updated.HasManagedResourceInstanceObjects() {
+			if updated != nil {
+				if err := manifest.Update(entry, updated); err != nil {
+					cleanDiags = cleanDiags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Failed to update kept test state",
+						fmt.Sprintf("OpenTofu could not record the resources left after destroying %s/%s: %s.", entry.File, entry.Run, err)))
+				}
+			}
+			remaining++
+		} else {
+			if err := manifest.Remove(entry); err != nil {
+				cleanDiags = cleanDiags.Append(tfdiags.Sourceless(
+					tfdiags.Warning,
+					"Failed to remove kept test state",
+					fmt.Sprintf("The infrastructure kept by %s/%s was destroyed, but OpenTofu could not remove its state file: %s.", entry.File, entry.Run, err)))
+			}
+			cleaned++
+		}
+		diags = diags.Append(cleanDiags)
+	}
+
+	if err := manifest.Save(); err != nil {
+		var saveDiags tfdiags.Diagnostics
+		saveDiags = saveDiags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to save test state manifest",
+			fmt.Sprintf("OpenTofu could not update the manifest of kept test infrastructure: %s.", err)))
+		view.Diagnostics(nil, nil, saveDiags)
+		diags = diags.Append(saveDiags)
+	}
+
+	if runner.Cancelled {
+		return 1
+	}
+
+	view.CleanSummary(cleaned, remaining)
+	if remaining > 0 || diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// cleanEntry destroys the state recorded by a single manifest entry. It
+// returns whatever is left in the state afterwards, or nil if the state could
+// not be loaded or the configuration that created it could not be found.
+func (c *TestCleanCommand) cleanEntry(ctx context.Context, suite *TestSuiteRunner, manifest *moduletest.Manifest, entry *moduletest.ManifestEntry) (*states.State, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	log.Printf("[DEBUG] TestCleanCommand: cleaning up kept state for %s/%s", entry.File, entry.Run)
+
+	fileConfig, ok := suite.Config.Module.Tests[entry.File]
+	if !ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unknown test file",
+			fmt.Sprintf("The test file %s, which created kept infrastructure, could not be found. Check the -test-directory option matches the value used when running the tests.", entry.File)))
+		suite.View.Diagnostics(nil, nil, diags)
+		return nil, diags
+	}
+
+	file := &moduletest.File{
+		Config: fileConfig,
+		Name:   entry.File,
+	}
+
+	var run *moduletest.Run
+	for ix, runConfig := range fileConfig.Runs {
+		if runConfig.Name == entry.Run {
+			run = &moduletest.Run{
+				Config: runConfig,
+				Index:  ix,
+				Name:   runConfig.Name,
+			}
+			break
+		}
+	}
+	if run == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unknown run block",
+			fmt.Sprintf("The run block %s, which created kept infrastructure, could not be found in %s.", entry.Run, entry.File)))
+		suite.View.Diagnostics(nil, file, diags)
+		return nil, diags
+	}
+
+	state, err := manifest.ReadState(entry)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read kept test state",
+			fmt.Sprintf("OpenTofu could not read the state kept by %s/%s: %s.", entry.File, entry.Run, err)))
+		suite.View.Diagnostics(run, file, diags)
+		return nil, diags
+	}
+
+	if key := testStateKey(run.Config); key != entry.StateKey {
+		// The run block no longer loads the module this state was created
+		// from, so we can't use it to destroy anything.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Inconsistent kept test state",
+			fmt.Sprintf("The state kept by %s/%s was created from a different module than the run block now loads. Restore the run block's original module to clean up this state.", entry.File, entry.Run)))
+		suite.View.Diagnostics(run, file, diags)
+		return state, diags
+	}
+
+	kept := &TestFileState{
+		Run:   run,
+		State: state,
+	}
+	fileRunner := &TestFileRunner{
+		Suite: suite,
+		States: map[string]*TestFileState{
+			MainStateIdentifier: {
+				Run:   nil,
+				State: states.NewState(),
+			},
+			entry.StateKey: kept,
+		},
+	}
+
+	updated, destroyDiags := fileRunner.destroyState(ctx, file, kept)
+	diags = diags.Append(destroyDiags)
+	suite.View.Diagnostics(run, file, destroyDiags)
+	return updated, diags
+}
@@ -0,0 +1,151 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/moduletest"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// testRunGraph returns a graph of the runs in the given file, with an edge
+// from each run to each of the runs that must complete before it starts.
+//
+// A run that isn't parallel depends on all the runs before it, so that a file
+// without any parallel runs executes strictly in order. A parallel run
+// depends on:
+//   - the closest run before it that isn't parallel,
+//   - the runs named in its depends_on argument,
+//   - the runs whose outputs it refers to,
+//   - the earlier runs that use the same state, unless both runs only plan,
+//     because applying changes to a state while another run is using it
+//     would make the result depend on which run happened to finish first.
+func testRunGraph(file *moduletest.File) *dag.AcyclicGraph {
+	var g dag.AcyclicGraph
+	for _, run := range file.Runs {
+		g.Add(run)
+	}
+
+	barrier := -1 // index of the closest run that isn't parallel
+	for i, run := range file.Runs {
+		if !run.Config.Parallel {
+			for _, earlier := range file.Runs[:i] {
+				g.Connect(dag.BasicEdge(run, earlier))
+			}
+			barrier = i
+			continue
+		}
+
+		if barrier >= 0 {
+			g.Connect(dag.BasicEdge(run, file.Runs[barrier]))
+		}
+
+		names := make(map[string]bool)
+		for _, name := range run.Config.DependsOnNames() {
+			names[name] = true
+		}
+		for _, name := range run.Config.References() {
+			names[name] = true
+		}
+
+		key := testStateKey(run.Config)
+		applies := run.Config.Command == configs.ApplyTestCommand
+		for _, earlier := range file.Runs[barrier+1 : i] {
+			switch {
+			case names[earlier.Name], names[earlier.Config.BlockName()]:
+				g.Connect(dag.BasicEdge(run, earlier))
+			case testStateKey(earlier.Config) == key && (applies || earlier.Config.Command == configs.ApplyTestCommand):
+				g.Connect(dag.BasicEdge(run, earlier))
+			}
+		}
+	}
+	return &g
+}
+
+// testConfigPool hands out copies of the configuration under test to run
+// blocks, so that run blocks executing in parallel don't interfere with each
+// other while they temporarily modify the configuration for their test.
+//
+// The configuration that was originally loaded is handed out first, and
+// additional copies are only loaded when that's already in use.
+type testConfigPool struct {
+	// load loads a new copy of the configuration including its tests. If it's
+	// nil, the original configuration is always returned, which is only safe
+	// when the run blocks execute one at a time.
+	load func() (*configs.Config, tfdiags.Diagnostics)
+
+	mu     sync.Mutex
+	inUse  map[*configs.Config]bool
+	copies map[*configs.Config][]*configs.Config
+}
+
+// Acquire returns a configuration equivalent to the given one, which is the
+// configuration under test for the given run, and a function that must be
+// called once the run no longer needs it.
+func (p *testConfigPool) Acquire(original *configs.Config, file *moduletest.File, run *moduletest.Run) (*configs.Config, func(), tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if p == nil || p.load == nil {
+		return original, func() {}, diags
+	}
+
+	p.mu.Lock()
+	if p.inUse == nil {
+		p.inUse = make(map[*configs.Config]bool)
+		p.copies = make(map[*configs.Config][]*configs.Config)
+	}
+	for _, candidate := range append([]*configs.Config{original}, p.copies[original]...) {
+		if !p.inUse[candidate] {
+			p.inUse[candidate] = true
+			p.mu.Unlock()
+			return candidate, p.releaseFunc(candidate), diags
+		}
+	}
+	p.mu.Unlock()
+
+	loaded, loadDiags := p.load()
+	diags = diags.Append(loadDiags)
+	if loadDiags.HasErrors() {
+		return nil, func() {}, diags
+	}
+
+	config := loaded
+	if run.Config.ConfigUnderTest != nil {
+		config = nil
+		if loadedFile, ok := loaded.Module.Tests[file.Name]; ok {
+			for _, loadedRun := range loadedFile.Runs {
+				if loadedRun.Name == run.Name {
+					config = loadedRun.ConfigUnderTest
+					break
+				}
+			}
+		}
+		if config == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to load configuration",
+				fmt.Sprintf("OpenTofu could not load another copy of the module under test for %s/%s, to execute it in parallel with other run blocks. This is a bug in OpenTofu - please report it!", file.Name, run.Name)))
+			return nil, func() {}, diags
+		}
+	}
+
+	p.mu.Lock()
+	p.copies[original] = append(p.copies[original], config)
+	p.inUse[config] = true
+	p.mu.Unlock()
+	return config, p.releaseFunc(config), diags
+}
+
+func (p *testConfigPool) releaseFunc(config *configs.Config) func() {
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.inUse[config] = false
+	}
+}
@@ -6,18 +6,24 @@
 package command
 
 import (
+	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/mitchellh/cli"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	testing_command "github.com/opentofu/opentofu/internal/command/testing"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/moduletest"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
@@ -66,10 +72,18 @@ func TestTest(t *testing.T) {
 			expected: "2 passed, 0 failed.",
 			code:     0,
 		},
+		"matrix": {
+			expected: "4 passed, 0 failed.",
+			code:     0,
+		},
 		"plan_then_apply": {
 			expected: "2 passed, 0 failed.",
 			code:     0,
 		},
+		"parallel": {
+			expected: "6 passed, 0 failed.",
+			code:     0,
+		},
 		"expect_failures_checks": {
 			expected: "1 passed, 0 failed.",
 			code:     0,
@@ -170,6 +184,10 @@ func TestTest(t *testing.T) {
 			expected: "1 passed, 0 failed.",
 			code:     0,
 		},
+		"override_data": {
+			expected: "2 passed, 0 failed.",
+			code:     0,
+		},
 	}
 	for name, tc := range tcs {
 		t.Run(name, func(t *testing.T) {
@@ -1464,3 +1482,193 @@ func TestTest_MockProviderValidation(t *testing.T) {
 		t.Fatalf("expected status code 0 but got %d: %s", code, ui.ErrorWriter)
 	}
 }
+
+// TestTest_MockProviderSchemas checks that mock_provider blocks whose mock
+// resources declare schemas don't need the real provider.
+func TestTest_MockProviderSchemas(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("test/mock_provider_schema"), td)
+	defer testChdir(t, td)()
+
+	view, done := testView(t)
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: &testingOverrides{
+				Providers: map[addrs.Provider]providers.Factory{},
+			},
+			View: view,
+		},
+	}
+
+	code := c.Run([]string{"-no-color"})
+	output := done(t)
+
+	if code != 0 {
+		t.Fatalf("expected status code 0 but got %d\n\n%s", code, output.All())
+	}
+	if got, want := output.Stdout(), "2 passed, 0 failed."; !strings.Contains(got, want) {
+		t.Errorf("output is missing %q\n%s", want, got)
+	}
+}
+
+func TestTest_MockProviderSchemas_missingSchema(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("test/mock_provider_schema"), td)
+	defer testChdir(t, td)()
+
+	test := `
+mock_provider "cloud" {
+  mock_resource "cloud_instance" {
+    schema = {
+      address = string
+    }
+  }
+
+  mock_data "cloud_image" {}
+}
+
+run "plan" {
+  command = plan
+}
+`
+	if err := os.WriteFile("main.tftest.hcl", []byte(test), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	view, done := testView(t)
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: &testingOverrides{
+				Providers: map[addrs.Provider]providers.Factory{},
+			},
+			View: view,
+		},
+	}
+
+	code := c.Run([]string{"-no-color"})
+	output := done(t)
+
+	if code != 1 {
+		t.Fatalf("expected status code 1 but got %d\n\n%s", code, output.All())
+	}
+	if got, want := output.Stderr(), "`mock_data.cloud_image` must declare a schema"; !strings.Contains(got, want) {
+		t.Errorf("output is missing %q\n%s", want, got)
+	}
+}
+
+func TestTest_KeepOnFailure(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "keep_on_failure")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+	view, done := testView(t)
+
+	c := &TestCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-no-color"})
+	output := done(t)
+
+	if code != 1 {
+		t.Errorf("expected status code 1 but got %d", code)
+	}
+
+	expectedOut := `main.tftest.hcl... fail
+  run "validate_test_resource"... fail
+
+Warning: Test infrastructure kept
+
+The test file main.tftest.hcl failed and keep_on_failure is set, so OpenTofu
+did not destroy the infrastructure created by
+main.tftest.hcl/validate_test_resource. Run "tofu test clean" to destroy it
+once you have finished investigating.
+
+Failure! 0 passed, 1 failed.
+`
+	if diff := cmp.Diff(expectedOut, output.Stdout()); len(diff) > 0 {
+		t.Errorf("std out didn't match expected:\n%s", diff)
+	}
+
+	if provider.ResourceCount() != 1 {
+		t.Fatalf("expected the failed test to keep its resource but found %v", provider.ResourceString())
+	}
+
+	if _, err := os.Stat(filepath.Join(DefaultDataDir, "test", moduletest.ManifestFilename)); err != nil {
+		t.Fatalf("expected the test state manifest to be written: %s", err)
+	}
+
+	view, done = testView(t)
+	clean := &TestCleanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(provider.Provider),
+			View:             view,
+		},
+	}
+
+	code = clean.Run([]string{"-no-color"})
+	output = done(t)
+
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d: %s", code, output.Stderr())
+	}
+
+	if diff := cmp.Diff("Cleaned up 1 of 1 kept test states.\n", output.Stdout()); len(diff) > 0 {
+		t.Errorf("std out didn't match expected:\n%s", diff)
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %v", provider.ResourceString())
+	}
+
+	if _, err := os.Stat(filepath.Join(DefaultDataDir, "test")); !os.IsNotExist(err) {
+		t.Errorf("expected the test state manifest directory to be removed, got %v", err)
+	}
+}
+
+func TestTestRunGraph(t *testing.T) {
+	dependsOn := func(names ...string) []hcl.Traversal {
+		var ret []hcl.Traversal
+		for _, name := range names {
+			ret = append(ret, hcl.Traversal{hcl.TraverseRoot{Name: "run"}, hcl.TraverseAttr{Name: name}})
+		}
+		return ret
+	}
+	file := &moduletest.File{
+		Runs: []*moduletest.Run{
+			{Name: "setup", Config: &configs.TestRun{Name: "setup"}},
+			{Name: "plan_a", Config: &configs.TestRun{Name: "plan_a", Command: configs.PlanTestCommand, Parallel: true}},
+			{Name: "plan_b", Config: &configs.TestRun{Name: "plan_b", Command: configs.PlanTestCommand, Parallel: true, DependsOn: dependsOn("plan_a")}},
+			{Name: "apply", Config: &configs.TestRun{Name: "apply", Parallel: true}},
+			{Name: "plan_c", Config: &configs.TestRun{Name: "plan_c", Command: configs.PlanTestCommand, Parallel: true}},
+			{Name: "final", Config: &configs.TestRun{Name: "final"}},
+		},
+	}
+
+	g := testRunGraph(file)
+	got := make(map[string][]string)
+	for _, run := range file.Runs {
+		var deps []string
+		for _, dep := range g.DownEdges(run) {
+			deps = append(deps, dep.(*moduletest.Run).Name)
+		}
+		sort.Strings(deps)
+		got[run.Name] = deps
+	}
+
+	want := map[string][]string{
+		"setup":  nil,
+		"plan_a": {"setup"},
+		"plan_b": {"plan_a", "setup"},
+		"apply":  {"plan_a", "plan_b", "setup"},
+		"plan_c": {"apply", "setup"},
+		"final":  {"apply", "plan_a", "plan_b", "plan_c", "setup"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong dependencies\n%s", diff)
+	}
+}
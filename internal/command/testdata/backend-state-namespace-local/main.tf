@@ -0,0 +1,5 @@
+terraform {
+  backend "local" {
+    state_namespace = "networking"
+  }
+}
@@ -0,0 +1,5 @@
+terraform {
+  backend "inmem" {
+    state_namespace = "networking"
+  }
+}
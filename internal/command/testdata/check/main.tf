@@ -0,0 +1,26 @@
+resource "test_instance" "foo" {
+  ami = "bar"
+}
+
+check "passing" {
+  assert {
+    condition     = test_instance.foo.id == "bar"
+    error_message = "The instance has the wrong ID."
+  }
+}
+
+check "failing" {
+  assert {
+    condition     = test_instance.foo.id == "baz"
+    error_message = "The instance has the wrong ID."
+  }
+}
+
+check "plan_only" {
+  phases = ["plan"]
+
+  assert {
+    condition     = test_instance.foo.id == "baz"
+    error_message = "The instance has the wrong ID."
+  }
+}
@@ -0,0 +1,11 @@
+variable "zone" {
+  type = string
+}
+
+resource "test_instance" "a" {
+  ami = var.zone
+}
+
+resource "test_instance" "b" {
+  ami = "static"
+}
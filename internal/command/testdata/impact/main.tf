@@ -0,0 +1,20 @@
+locals {
+  zone = "${var.region}a"
+}
+
+resource "test_instance" "web" {
+  ami = local.zone
+}
+
+resource "test_instance" "db" {
+  ami = "static"
+}
+
+module "app" {
+  source = "./app"
+  zone   = local.zone
+}
+
+output "web" {
+  value = test_instance.web.id
+}
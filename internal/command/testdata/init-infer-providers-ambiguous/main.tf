@@ -0,0 +1,13 @@
+terraform {
+  required_providers {
+    acme = {
+      source = "acme/between"
+    }
+  }
+}
+
+resource "between_thing" "a" {
+}
+
+resource "acme_thing" "b" {
+}
@@ -0,0 +1,10 @@
+terraform {
+  required_providers {
+    exact = {
+      source = "hashicorp/exact"
+    }
+  }
+}
+
+resource "between_thing" "a" {
+}
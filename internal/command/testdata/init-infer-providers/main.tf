@@ -0,0 +1,10 @@
+provider "exact" {
+  version = "1.2.3"
+}
+
+resource "between_thing" "a" {
+}
+
+data "terraform_remote_state" "c" {
+  backend = "local"
+}
@@ -0,0 +1,7 @@
+variable "name" {
+  type = string
+}
+
+resource "test_instance" "foo" {
+  ami = var.name
+}
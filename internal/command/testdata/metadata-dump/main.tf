@@ -0,0 +1,9 @@
+variable "name" {
+  type        = string
+  description = "The name of the instance."
+}
+
+module "child" {
+  source = "./child"
+  name   = var.name
+}
@@ -0,0 +1,7 @@
+resource "test_instance" "root" {
+  ami = "root"
+}
+
+module "child" {
+  source = "./child"
+}
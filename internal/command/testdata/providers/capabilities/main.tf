@@ -0,0 +1,12 @@
+terraform {
+  required_providers {
+    test = {
+      source       = "hashicorp/test"
+      version      = "~> 1.0"
+      capabilities = ["plan_destroy"]
+    }
+  }
+}
+
+resource "test_instance" "foo" {
+}
@@ -0,0 +1,19 @@
+provider "test" {
+  alias  = "deposed"
+  region = data.test_data_source.config.id
+}
+
+# The read of this data source is deferred to the apply, because it depends
+# on a resource with pending changes.
+data "test_data_source" "config" {
+  depends_on = [test_instance.setup]
+}
+
+resource "test_instance" "setup" {
+  ami = "setup"
+}
+
+resource "test_instance" "foo" {
+  provider = test.deposed
+  ami      = "bar"
+}
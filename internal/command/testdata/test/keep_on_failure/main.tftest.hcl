@@ -0,0 +1,8 @@
+keep_on_failure = true
+
+run "validate_test_resource" {
+  assert {
+    condition = test_resource.foo.value == "zap"
+    error_message = "invalid value"
+  }
+}
@@ -0,0 +1,11 @@
+variable "input" {
+  type = string
+}
+
+variable "suffix" {
+  type = string
+}
+
+resource "test_resource" "foo" {
+  value = "${var.input}-${var.suffix}"
+}
@@ -0,0 +1,15 @@
+variables {
+  suffix = "global"
+}
+
+run "validate_test_resource" {
+  matrix {
+    input  = ["bar", "zap"]
+    suffix = ["one", "two"]
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "${var.input}-${var.suffix}"
+    error_message = "invalid value"
+  }
+}
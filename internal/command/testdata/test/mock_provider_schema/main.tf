@@ -0,0 +1,22 @@
+terraform {
+  required_providers {
+    cloud = {
+      source = "example/cloud"
+    }
+  }
+}
+
+data "cloud_image" "base" {
+  name = "ubuntu"
+}
+
+resource "cloud_instance" "web" {
+  image_id = data.cloud_image.base.id
+  tags = {
+    Name = "web"
+  }
+}
+
+output "address" {
+  value = cloud_instance.web.address
+}
@@ -0,0 +1,38 @@
+mock_provider "cloud" {
+  mock_resource "cloud_instance" {
+    schema = {
+      image_id = string
+      address  = string
+      tags     = map(string)
+    }
+    defaults = {
+      address = "10.0.0.1"
+    }
+  }
+
+  mock_data "cloud_image" {
+    schema = {
+      id   = string
+      name = string
+    }
+    defaults = {
+      id = "image-1234"
+    }
+  }
+}
+
+run "plan" {
+  command = plan
+
+  assert {
+    condition     = cloud_instance.web.image_id == "image-1234"
+    error_message = "Unexpected image ID"
+  }
+}
+
+run "apply" {
+  assert {
+    condition     = output.address == "10.0.0.1" && cloud_instance.web.tags["Name"] == "web"
+    error_message = "Unexpected instance"
+  }
+}
@@ -0,0 +1,7 @@
+data "test_data_source" "image" {
+  id = "image-lookup"
+}
+
+resource "test_resource" "foo" {
+  value = data.test_data_source.image.value
+}
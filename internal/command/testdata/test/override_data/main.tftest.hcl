@@ -0,0 +1,30 @@
+// The test provider has no data object with the ID "image-lookup", so reading
+// the data source would fail with a "not found" error. Overriding it at the
+// file level means no run needs to read it.
+override_data {
+  target = data.test_data_source.image
+  values = {
+    value = "image-from-file"
+  }
+}
+
+run "file_override" {
+  assert {
+    condition     = test_resource.foo.value == "image-from-file"
+    error_message = "invalid value"
+  }
+}
+
+run "run_override" {
+  override_data {
+    target = data.test_data_source.image
+    values = {
+      value = "image-from-run"
+    }
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "image-from-run"
+    error_message = "invalid value"
+  }
+}
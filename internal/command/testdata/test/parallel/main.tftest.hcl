@@ -0,0 +1,74 @@
+run "setup" {
+  variables {
+    input = "setup"
+  }
+}
+
+run "plan_a" {
+  command  = plan
+  parallel = true
+
+  variables {
+    input = run.setup.value
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "setup"
+    error_message = "invalid value"
+  }
+}
+
+run "plan_b" {
+  command  = plan
+  parallel = true
+
+  variables {
+    input = "b"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "b"
+    error_message = "invalid value"
+  }
+}
+
+run "plan_c" {
+  command    = plan
+  parallel   = true
+  depends_on = [run.plan_a]
+
+  variables {
+    input = "c"
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "c"
+    error_message = "invalid value"
+  }
+}
+
+run "apply" {
+  parallel = true
+
+  variables {
+    input = "apply"
+  }
+
+  assert {
+    condition     = output.value == "apply"
+    error_message = "invalid value"
+  }
+}
+
+run "final" {
+  command = plan
+
+  variables {
+    input = run.apply.value
+  }
+
+  assert {
+    condition     = test_resource.foo.value == "apply"
+    error_message = "invalid value"
+  }
+}
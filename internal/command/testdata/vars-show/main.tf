@@ -0,0 +1,25 @@
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+variable "tags" {
+  type = map(string)
+}
+
+variable "settings" {
+  type = object({
+    size   = string
+    nested = map(number)
+    extra  = optional(string, "none")
+  })
+}
+
+variable "secret" {
+  type      = string
+  sensitive = true
+}
+
+variable "unset" {
+  type = string
+}
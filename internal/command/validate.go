@@ -12,8 +12,10 @@ import (
 	"strings"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
+	viewsjson "github.com/opentofu/opentofu/internal/command/views/json"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -62,7 +64,7 @@ func (c *ValidateCommand) Run(rawArgs []string) int {
 	// Inject variables from args into meta for static evaluation
 	c.GatherVariables(args.Vars)
 
-	validateDiags := c.validate(ctx, dir, args.TestDirectory, args.NoTests)
+	validateDiags := c.validate(ctx, dir, args.TestDirectory, args.NoTests, args.WithProviders)
 	diags = diags.Append(validateDiags)
 
 	// Validating with dev overrides in effect means that the result might
@@ -71,9 +73,40 @@ func (c *ValidateCommand) Run(rawArgs []string) int {
 	// check before submitting a change.
 	diags = diags.Append(c.providerDevOverrideRuntimeWarnings())
 
+	diags = overrideSeverities(diags, args.SeverityOverrides)
+
 	return view.Results(diags)
 }
 
+// overrideSeverities changes the severities of the given diagnostics as the
+// given -severity options request. An option for the rule of a diagnostic
+// takes precedence over an option for its category.
+func overrideSeverities(diags tfdiags.Diagnostics, overrides map[string]string) tfdiags.Diagnostics {
+	if len(overrides) == 0 {
+		return diags
+	}
+
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		rule := viewsjson.DiagnosticRuleFor(diag)
+		severity, ok := overrides[rule.ID]
+		if !ok {
+			severity, ok = overrides[rule.Category]
+		}
+		switch {
+		case !ok:
+			ret = ret.Append(diag)
+		case severity == arguments.SeverityOff:
+			// Omitted
+		case severity == "error":
+			ret = ret.Append(tfdiags.Override(diag, tfdiags.Error, nil))
+		case severity == "warning":
+			ret = ret.Append(tfdiags.Override(diag, tfdiags.Warning, nil))
+		}
+	}
+	return ret
+}
+
 func (c *ValidateCommand) GatherVariables(args *arguments.Vars) {
 	// FIXME the arguments package currently trivially gathers variable related
 	// arguments in a heterogeneous slice, in order to minimize the number of
@@ -91,7 +124,7 @@ func (c *ValidateCommand) GatherVariables(args *arguments.Vars) {
 	c.Meta.variableArgs = rawFlags{items: &items}
 }
 
-func (c *ValidateCommand) validate(ctx context.Context, dir, testDir string, noTests bool) tfdiags.Diagnostics {
+func (c *ValidateCommand) validate(ctx context.Context, dir, testDir string, noTests, withProviders bool) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 	var cfg *configs.Config
 
@@ -104,7 +137,27 @@ func (c *ValidateCommand) validate(ctx context.Context, dir, testDir string, noT
 		return diags
 	}
 
-	validate := func(cfg *configs.Config) tfdiags.Diagnostics {
+	var validateOpts *tofu.ValidateOpts
+	if withProviders {
+		// Providers can only validate the arguments whose values are known,
+		// so we'll validate the root module with the input variable values
+		// that are available without a plan.
+		unparsed, moreDiags := c.collectVariableValues()
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			return diags
+		}
+		varValues, moreDiags := backend.ParseDeclaredVariableValues(unparsed, cfg.Module.Variables)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			return diags
+		}
+		validateOpts = &tofu.ValidateOpts{
+			SetVariables: varValues,
+		}
+	}
+
+	validate := func(cfg *configs.Config, validateOpts *tofu.ValidateOpts) tfdiags.Diagnostics {
 		var diags tfdiags.Diagnostics
 
 		opts, err := c.contextOpts()
@@ -119,10 +172,10 @@ func (c *ValidateCommand) validate(ctx context.Context, dir, testDir string, noT
 			return diags
 		}
 
-		return diags.Append(tfCtx.Validate(ctx, cfg))
+		return diags.Append(tfCtx.ValidateWithOpts(ctx, cfg, validateOpts))
 	}
 
-	diags = diags.Append(validate(cfg))
+	diags = diags.Append(validate(cfg, validateOpts))
 
 	if noTests {
 		return diags
@@ -154,7 +207,7 @@ func (c *ValidateCommand) validate(ctx context.Context, dir, testDir string, noT
 						// not validate the same thing multiple times.
 
 						validatedModules[run.Module.Source.String()] = true
-						diags = diags.Append(validate(run.ConfigUnderTest))
+						diags = diags.Append(validate(run.ConfigUnderTest, nil))
 					}
 
 				}
@@ -219,6 +272,11 @@ Options:
 
   -no-tests             If specified, OpenTofu will not validate test files.
 
+  -severity=RULE=LEVEL  Report the diagnostics of the given rule ID or
+                        category with the given severity, which is "error",
+                        "warning" or "off" to omit them. Use this option more
+                        than once to override more than one rule or category.
+
   -test-directory=path  Set the OpenTofu test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
@@ -231,6 +289,13 @@ Options:
                         to the default files terraform.tfvars and *.auto.tfvars.
                         Use this option more than once to include more than one
                         variables file.
+
+  -with-providers       Validate the configuration with the values of the root
+                        module input variables from -var and -var-file
+                        options, variable definitions files, environment
+                        variables, and defaults, instead of with unknown
+                        values, so that providers can also validate the
+                        arguments that depend on them.
 `
 	return strings.TrimSpace(helpText)
 }
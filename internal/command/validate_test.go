@@ -22,6 +22,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 func setupTest(t *testing.T, fixturepath string, args ...string) (*terminal.TestOutput, int) {
@@ -369,3 +370,128 @@ func TestValidate_json(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_severity(t *testing.T) {
+	tests := map[string]struct {
+		args         []string
+		wantCode     int
+		wantErrors   int
+		wantWarnings int
+	}{
+		"none": {
+			nil,
+			1, 2, 0,
+		},
+		"category": {
+			[]string{"-severity=provider-schema=warning"},
+			0, 0, 2,
+		},
+		"rule takes precedence over category": {
+			[]string{"-severity=provider-schema=off", "-severity=unsupported-argument=error"},
+			1, 1, 0,
+		},
+		"off": {
+			[]string{"-severity=missing-required-argument=off", "-severity=unsupported-argument=off"},
+			0, 0, 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, code := setupTest(t, "validate-invalid/outputs", append(tc.args, "-json")...)
+			if code != tc.wantCode {
+				t.Errorf("wrong exit code %d; want %d\n%s", code, tc.wantCode, output.Stderr())
+			}
+
+			var got struct {
+				ErrorCount   int `json:"error_count"`
+				WarningCount int `json:"warning_count"`
+			}
+			if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+				t.Fatalf("failed to unmarshal output: %s\n%s", err, output.Stdout())
+			}
+			if got.ErrorCount != tc.wantErrors || got.WarningCount != tc.wantWarnings {
+				t.Errorf("got %d errors and %d warnings; want %d and %d\n%s", got.ErrorCount, got.WarningCount, tc.wantErrors, tc.wantWarnings, output.Stdout())
+			}
+		})
+	}
+}
+
+func TestValidate_withProviders(t *testing.T) {
+	td := t.TempDir()
+	config := `
+variable "ami" {
+  type = string
+}
+
+resource "test_instance" "foo" {
+  ami = var.ami
+}
+`
+	if err := os.WriteFile(path.Join(td, "main.tf"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer testChdir(t, td)()
+
+	tests := map[string]struct {
+		args     []string
+		wantCode int
+	}{
+		"unknown variables": {
+			[]string{"-var=ami=invalid"},
+			0,
+		},
+		"with providers": {
+			[]string{"-with-providers", "-var=ami=invalid"},
+			1,
+		},
+		"with providers and a valid value": {
+			[]string{"-with-providers", "-var=ami=ami-123"},
+			0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := testProvider()
+			p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+				ResourceTypes: map[string]providers.Schema{
+					"test_instance": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"ami": {Type: cty.String, Optional: true},
+							},
+						},
+					},
+				},
+			}
+			p.ValidateResourceConfigFn = func(req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+				if ami := req.Config.GetAttr("ami"); ami.IsKnown() && !strings.HasPrefix(ami.AsString(), "ami-") {
+					resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+						tfdiags.Error,
+						"Invalid AMI",
+						"The AMI ID must start with \"ami-\".",
+						cty.GetAttrPath("ami"),
+					))
+				}
+				return resp
+			}
+
+			view, done := testView(t)
+			c := &ValidateCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(p),
+					View:             view,
+				},
+			}
+			code := c.Run(append(tc.args, "-no-color"))
+			output := done(t)
+			if code != tc.wantCode {
+				t.Fatalf("wrong exit code %d; want %d\n%s", code, tc.wantCode, output.All())
+			}
+			if code != 0 && !strings.Contains(output.Stderr(), "Invalid AMI") {
+				t.Fatalf("missing provider diagnostic:\n%s", output.Stderr())
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// VarsShowCommand is a Command implementation that shows the final values of
+// the root module input variables and where each of them was set.
+type VarsShowCommand struct {
+	Meta
+}
+
+func (c *VarsShowCommand) Run(rawArgs []string) int {
+	// Parse and apply global view arguments
+	common, rawArgs := arguments.ParseView(rawArgs)
+	c.View.Configure(common)
+
+	// Parse and validate flags
+	args, diags := arguments.ParseVarsShow(rawArgs)
+	if diags.HasErrors() {
+		c.View.Diagnostics(diags)
+		c.View.HelpPrompt("vars show")
+		return 1
+	}
+
+	c.View.SetShowSensitive(args.ShowSensitive)
+
+	view := views.NewVarsShow(args.ViewType, c.View)
+
+	// Inject variables from args into meta
+	c.GatherVariables(args.Vars)
+
+	module, moreDiags := c.loadSingleModule(".", configs.SelectiveLoadAll)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	if _, declared := module.Variables[args.Name]; args.Name != "" && !declared {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Undeclared input variable",
+			fmt.Sprintf("The root module does not declare a variable named %q.", args.Name),
+		))
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	vars, moreDiags := c.variableValues(module, args.Name)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	diags = diags.Append(view.Variables(vars))
+	view.Diagnostics(diags)
+
+	if diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
+// variableValues determines the value that each input variable declared in the
+// given root module would have for an operation, along with its sources. If
+// name is not empty, only that variable is included.
+func (c *VarsShowCommand) variableValues(module *configs.Module, name string) ([]*views.VariableValue, tfdiags.Diagnostics) {
+	raw, diags := c.collectVariableValues()
+
+	// Values for undeclared variables are reported in the same way as for
+	// the other commands.
+	_, moreDiags := backend.ParseUndeclaredVariableValues(raw, module.Variables, false)
+	diags = diags.Append(moreDiags)
+
+	var names []string
+	if name != "" {
+		names = []string{name}
+	} else {
+		for name := range module.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	ret := make([]*views.VariableValue, 0, len(names))
+	for _, name := range names {
+		vc := module.Variables[name]
+		vv := &views.VariableValue{
+			Name:      name,
+			Value:     cty.NilVal,
+			Sensitive: vc.Sensitive,
+		}
+
+		given := cty.NilVal
+		var subject *hcl.Range
+		if rv, ok := raw[name]; ok {
+			iv, valDiags := rv.ParseVariableValue(vc.ParsingMode)
+			diags = diags.Append(valDiags)
+			if valDiags.HasErrors() {
+				continue
+			}
+			given = iv.Value
+			vv.Sources = variableValueSources(rv)
+			if iv.HasSourceRange() {
+				subject = iv.SourceRange.ToHCL().Ptr()
+			}
+		}
+
+		// As in OpenTofu Core, a null value for a non-nullable variable
+		// is replaced by its default.
+		if given == cty.NilVal || (given.IsNull() && !vc.Nullable && vc.Default != cty.NilVal) {
+			if vc.Default == cty.NilVal {
+				// Required variables with no value are reported as not set,
+				// rather than as an error, as this command is for debugging.
+				ret = append(ret, vv)
+				continue
+			}
+			given = vc.Default
+			vv.Sources = []views.VariableValueSource{
+				{
+					Type:  tofu.ValueFromConfig,
+					Range: tfdiags.SourceRangeFromHCL(vc.DeclRange),
+				},
+			}
+			subject = vc.DeclRange.Ptr()
+		}
+
+		if vc.TypeDefaults != nil && !given.IsNull() {
+			given = vc.TypeDefaults.Apply(given)
+		}
+		val, err := convert.Convert(given, vc.ConstraintType)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid value for input variable",
+				Detail:   fmt.Sprintf("The given value is not suitable for var.%s declared at %s: %s.", name, vc.DeclRange, err),
+				Subject:  subject,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableValue, nil),
+			})
+			continue
+		}
+		vv.Value = val
+
+		ret = append(ret, vv)
+	}
+
+	return ret, diags
+}
+
+// variableValueSources returns the sources that contributed to the given
+// value, in the order they were applied.
+func variableValueSources(v backend.UnparsedVariableValue) []views.VariableValueSource {
+	switch v := v.(type) {
+	case unparsedVariableValueMerged:
+		return append(variableValueSources(v.base), variableValueSources(v.overlay)...)
+	case unparsedVariableValueExpression:
+		return []views.VariableValueSource{
+			{
+				Type:  v.sourceType,
+				Range: tfdiags.SourceRangeFromHCL(v.expr.Range()),
+			},
+		}
+	case unparsedVariableValueString:
+		return []views.VariableValueSource{
+			{
+				Type: v.sourceType,
+			},
+		}
+	default:
+		return []views.VariableValueSource{
+			{
+				Type: tofu.ValueFromUnknown,
+			},
+		}
+	}
+}
+
+func (c *VarsShowCommand) GatherVariables(args *arguments.Vars) {
+	// FIXME the arguments package currently trivially gathers variable related
+	// arguments in a heterogeneous slice, in order to minimize the number of
+	// code paths gathering variables during the transition to this structure.
+	// Once all commands that gather variables have been converted to this
+	// structure, we could move the variable gathering code to the arguments
+	// package directly, removing this shim layer.
+
+	varArgs := args.All()
+	items := make([]rawFlag, len(varArgs))
+	for i := range varArgs {
+		items[i].Name = varArgs[i].Name
+		items[i].Value = varArgs[i].Value
+	}
+	c.Meta.variableArgs = rawFlags{items: &items}
+}
+
+func (c *VarsShowCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] vars show [options] [NAME]
+
+  Shows the final value of each input variable of the root module, as
+  it would be used by a plan or apply with the same options, along with
+  the default value, file, option or environment variable that set it.
+
+  This is intended for debugging configurations that layer multiple
+  variable files. If NAME is given, only that variable is shown.
+
+Options:
+
+  -json                   If specified, machine readable output will be
+                          printed in JSON format.
+
+  -show-sensitive         If specified, sensitive values will be displayed.
+
+  -var 'foo=bar'          Set a value for one of the input variables in the
+                          root module of the configuration. Use this option
+                          more than once to set more than one variable.
+
+  -var-file=filename      Load variable values from the given file, in
+                          addition to the default files terraform.tfvars and
+                          *.auto.tfvars. Use this option more than once to
+                          include more than one variables file.
+
+  -var-file-merge=filename
+                          Like -var-file, but object and map values in the
+                          file are merged into the values set before it
+                          instead of replacing them.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *VarsShowCommand) Synopsis() string {
+	return "Show input variable values and where they were set"
+}
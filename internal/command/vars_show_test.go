@@ -0,0 +1,138 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVarsShow(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("vars-show"), td)
+	defer testChdir(t, td)()
+
+	view, done := testView(t)
+	c := &VarsShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-var-file", "common.tfvars",
+		"-var-file-merge", "prod.tfvars",
+		"-var", "secret=hunter2",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: \n%s", output.Stderr())
+	}
+
+	want := `region = "us-east-1"
+  from the default value at main.tf:1,1
+secret = <sensitive>
+  from -var="secret=..."
+settings = {
+  "extra" = "none"
+  "nested" = tomap({
+    "a" = 1
+    "b" = 3
+  })
+  "size" = "small"
+}
+  from common.tfvars:5,12
+  merged with prod.tfvars:4,12
+tags = tomap({
+  "env" = "prod"
+  "team" = "platform"
+})
+  from common.tfvars:1,8
+  merged with prod.tfvars:1,8
+unset = <not set>`
+	if diff := cmp.Diff(want, strings.TrimSpace(output.Stdout())); diff != "" {
+		t.Fatalf("wrong output\n%s", diff)
+	}
+}
+
+func TestVarsShow_json(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("vars-show"), td)
+	defer testChdir(t, td)()
+
+	view, done := testView(t)
+	c := &VarsShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-json",
+		"-var-file", "common.tfvars",
+		"-var-file", "prod.tfvars",
+		"tags",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: \n%s", output.Stderr())
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, output.Stdout())
+	}
+	// Without -var-file-merge, the later file replaces the whole map.
+	want := map[string]interface{}{
+		"tags": map[string]interface{}{
+			"sensitive": false,
+			"type":      []interface{}{"map", "string"},
+			"value": map[string]interface{}{
+				"env": "prod",
+			},
+			"sources": []interface{}{
+				map[string]interface{}{
+					"type":     "file",
+					"filename": "prod.tfvars",
+					"line":     float64(1),
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong output\n%s", diff)
+	}
+}
+
+func TestVarsShow_undeclared(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("vars-show"), td)
+	defer testChdir(t, td)()
+
+	view, done := testView(t)
+	c := &VarsShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"nope"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("unexpected success\n%s", output.Stdout())
+	}
+	if got, want := output.Stderr(), `The root module does not declare a variable named "nope".`; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot: %s\nwant substring: %s", got, want)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providercache"
 )
 
 // VersionCommand is a Command implementation prints the version.
@@ -30,6 +31,11 @@ type VersionOutput struct {
 	Version            string            `json:"terraform_version"`
 	Platform           string            `json:"platform"`
 	ProviderSelections map[string]string `json:"provider_selections"`
+
+	// ProviderProvenance describes where each of the selected providers
+	// was installed from and how it was verified, for those that are
+	// installed and have a provenance record.
+	ProviderProvenance map[string]*providercache.Provenance `json:"provider_provenance,omitempty"`
 }
 
 func (c *VersionCommand) Help() string {
@@ -78,8 +84,10 @@ func (c *VersionCommand) Run(args []string) int {
 	// and then hit a problem running _another_ command.
 	var providerVersions []string
 	var providerLocks map[addrs.Provider]*depsfile.ProviderLock
+	var provenance map[addrs.Provider]*providercache.Provenance
 	if locks, err := c.lockedDependencies(); err == nil {
 		providerLocks = locks.AllProviders()
+		provenance = c.installedProviderProvenance(locks)
 		for providerAddr, lock := range providerLocks {
 			version := lock.Version().String()
 			if version == "0.0.0" {
@@ -109,6 +117,12 @@ func (c *VersionCommand) Run(args []string) int {
 			Platform:           c.Platform.String(),
 			ProviderSelections: selectionsOutput,
 		}
+		if len(provenance) > 0 {
+			output.ProviderProvenance = make(map[string]*providercache.Provenance, len(provenance))
+			for providerAddr, p := range provenance {
+				output.ProviderProvenance[providerAddr.String()] = p
+			}
+		}
 
 		jsonOutput, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
@@ -7,6 +7,7 @@ package views
 
 import (
 	"bufio"
+	"log"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/format"
 	"github.com/opentofu/opentofu/internal/command/views/json"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
@@ -174,3 +176,27 @@ func (h *jsonHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Genera
 	h.view.Hook(json.NewRefreshComplete(addr, idKey, idValue))
 	return tofu.HookActionContinue, nil
 }
+
+func (h *jsonHook) PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (tofu.HookAction, error) {
+	name := addr.OutputValue.Name
+	outputs, diags := json.OutputsFromMap(map[string]*states.OutputValue{
+		name: {
+			Addr:      addr,
+			Value:     value,
+			Sensitive: sensitive,
+		},
+	})
+	if diags.HasErrors() {
+		// The value is also reported with all of the others once the apply
+		// completes, so we don't let this interrupt the apply.
+		log.Printf("[ERROR] Failed to serialize the value of %s: %s", addr, diags.Err())
+		return tofu.HookActionContinue, nil
+	}
+	h.view.Hook(json.NewOutputAvailable(name, outputs[name]))
+	return tofu.HookActionContinue, nil
+}
+
+func (h *jsonHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (tofu.HookAction, error) {
+	h.view.Hook(json.NewProviderRetry(addr, event.Method, event.Attempt, event.MaxAttempts, event.Delay, retryErrorSummary(event)))
+	return tofu.HookActionContinue, nil
+}
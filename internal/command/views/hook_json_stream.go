@@ -0,0 +1,404 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"bufio"
+	encJson "encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/views/json"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+	tfversion "github.com/opentofu/opentofu/version"
+)
+
+// JSONStreamHook writes a versioned stream of newline-delimited JSON events
+// describing the progress of an operation, for programs such as CI systems
+// that display their own progress UI.
+//
+// Unlike the events of the -json UI, every operation in the stream has an ID,
+// its start and end events record when it started and ended, and operations
+// record the ID of the operation that they're part of, such as the apply of
+// a resource instance for a call to its provider.
+type JSONStreamHook struct {
+	tofu.NilHook
+
+	mu     sync.Mutex
+	enc    *encJson.Encoder
+	err    error
+	seq    int64
+	lastID int64
+	root   *streamOperation
+
+	// open tracks the operations in progress for each resource instance, by
+	// address, with the innermost operation last.
+	open map[string][]*streamOperation
+
+	timeNow func() time.Time
+}
+
+var _ tofu.Hook = (*JSONStreamHook)(nil)
+
+type streamOperation struct {
+	id     string
+	parent string
+	kind   json.StreamOperationKind
+	start  time.Time
+
+	// provider and method identify a provider call, and provisioner a
+	// provisioner step.
+	provider    string
+	method      string
+	provisioner string
+}
+
+// NewJSONStreamHook returns a hook that writes the JSON progress stream to
+// the given writer, starting with the stream_start event. The caller must
+// call End once the operation is complete.
+func NewJSONStreamHook(w io.Writer) *JSONStreamHook {
+	return newJSONStreamHook(w, time.Now)
+}
+
+func newJSONStreamHook(w io.Writer, timeNow func() time.Time) *JSONStreamHook {
+	h := &JSONStreamHook{
+		enc:     encJson.NewEncoder(w),
+		open:    make(map[string][]*streamOperation),
+		timeNow: timeNow,
+	}
+	h.root = h.newOperation(json.OperationRoot)
+	h.emit(&json.StreamEvent{
+		Type:      json.StreamStart,
+		Timestamp: h.root.start,
+		ID:        h.root.id,
+		Kind:      json.OperationRoot,
+		Tofu:      tfversion.String(),
+	})
+	return h
+}
+
+// End writes the stream_end event, with the given outcome of the whole
+// operation. It returns the first error encountered while writing the
+// stream, if any.
+func (h *JSONStreamHook) End(success bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event := h.endEvent(h.root, json.StreamEnd)
+	if !success {
+		event.Status = json.StatusErrored
+	}
+	h.emit(event)
+	return h.err
+}
+
+func (h *JSONStreamHook) PreRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value) (tofu.HookAction, error) {
+	h.startResourceOperation(addr, json.OperationRefresh, plans.NoOp)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value, newState cty.Value) (tofu.HookAction, error) {
+	h.endResourceOperation(addr, json.OperationRefresh, plans.NoOp, nil)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PreDiff(addr addrs.AbsResourceInstance, gen states.Generation, priorState, proposedNewState cty.Value) (tofu.HookAction, error) {
+	h.startResourceOperation(addr, json.OperationPlan, plans.NoOp)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PostDiff(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (tofu.HookAction, error) {
+	h.endResourceOperation(addr, json.OperationPlan, action, nil)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (tofu.HookAction, error) {
+	if action != plans.NoOp {
+		h.startResourceOperation(addr, json.OperationApply, action)
+	}
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, err error) (tofu.HookAction, error) {
+	h.endResourceOperation(addr, json.OperationApply, plans.NoOp, err)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PreProvisionInstanceStep(addr addrs.AbsResourceInstance, typeName string) (tofu.HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := h.newOperation(json.OperationProvision)
+	op.provisioner = typeName
+	event := h.startEvent(addr, op)
+	event.Provisioner = typeName
+	h.emit(event)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PostProvisionInstanceStep(addr addrs.AbsResourceInstance, typeName string, err error) (tofu.HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := h.closeOperation(addr, func(op *streamOperation) bool {
+		return op.kind == json.OperationProvision && op.provisioner == typeName
+	})
+	if op == nil {
+		return tofu.HookActionContinue, nil
+	}
+	event := h.endEvent(op, json.OperationEnd)
+	event.Resource = json.NewStreamResourceAddr(addr)
+	event.Provisioner = typeName
+	if err != nil {
+		event.Status = json.StatusErrored
+		event.Error = err.Error()
+	}
+	h.emit(event)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) ProvisionOutput(addr addrs.AbsResourceInstance, typeName string, msg string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	parent := h.findOperation(addr.String(), func(op *streamOperation) bool {
+		return op.kind == json.OperationProvision && op.provisioner == typeName
+	})
+	s := bufio.NewScanner(strings.NewReader(msg))
+	s.Split(scanLines)
+	for s.Scan() {
+		line := strings.TrimRightFunc(s.Text(), unicode.IsSpace)
+		if line == "" {
+			continue
+		}
+		h.emit(&json.StreamEvent{
+			Type:        json.StreamProvisionOutput,
+			Timestamp:   h.timeNow(),
+			ID:          h.nextID(),
+			ParentID:    parent.id,
+			Resource:    json.NewStreamResourceAddr(addr),
+			Provisioner: typeName,
+			Output:      line,
+		})
+	}
+}
+
+func (h *JSONStreamHook) PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (tofu.HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := h.newOperation(json.OperationProviderCall)
+	op.provider = provider.String()
+	op.method = method
+	event := h.startEvent(addr, op)
+	event.Provider = op.provider
+	event.Method = method
+	h.emit(event)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (tofu.HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := h.closeOperation(addr, func(op *streamOperation) bool {
+		return op.kind == json.OperationProviderCall && op.method == method
+	})
+	if op == nil {
+		return tofu.HookActionContinue, nil
+	}
+	event := h.endEvent(op, json.OperationEnd)
+	event.Resource = json.NewStreamResourceAddr(addr)
+	event.Provider = op.provider
+	event.Method = method
+	for _, diag := range diags {
+		event.Diagnostics = append(event.Diagnostics, json.NewDiagnostic(diag, nil))
+	}
+	if diags.HasErrors() {
+		event.Status = json.StatusErrored
+		event.Error = diags.Err().Error()
+	}
+	h.emit(event)
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (tofu.HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// The retry hook doesn't know which resource instance the call is for,
+	// so we attribute it to any call to the same method of the same provider
+	// that is in progress.
+	parent := h.root
+	provider := addr.String()
+	for key := range h.open {
+		if op := h.findOperation(key, func(op *streamOperation) bool {
+			return op.kind == json.OperationProviderCall && op.provider == provider && op.method == event.Method
+		}); op != h.root {
+			parent = op
+			break
+		}
+	}
+	h.emit(&json.StreamEvent{
+		Type:        json.StreamProviderRetry,
+		Timestamp:   h.timeNow(),
+		ID:          h.nextID(),
+		ParentID:    parent.id,
+		Provider:    provider,
+		Method:      event.Method,
+		Attempt:     event.Attempt,
+		MaxAttempts: event.MaxAttempts,
+		DelayMS:     event.Delay.Milliseconds(),
+		Error:       retryErrorSummary(event),
+	})
+	return tofu.HookActionContinue, nil
+}
+
+func (h *JSONStreamHook) startResourceOperation(addr addrs.AbsResourceInstance, kind json.StreamOperationKind, action plans.Action) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event := h.startEvent(addr, h.newOperation(kind))
+	if action != plans.NoOp {
+		event.Action = json.NewStreamChangeAction(action)
+	}
+	h.emit(event)
+}
+
+func (h *JSONStreamHook) endResourceOperation(addr addrs.AbsResourceInstance, kind json.StreamOperationKind, action plans.Action, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op := h.closeOperation(addr, func(op *streamOperation) bool {
+		return op.kind == kind
+	})
+	if op == nil {
+		return
+	}
+	event := h.endEvent(op, json.OperationEnd)
+	event.Resource = json.NewStreamResourceAddr(addr)
+	if kind == json.OperationPlan {
+		event.Action = json.NewStreamChangeAction(action)
+	}
+	if err != nil {
+		event.Status = json.StatusErrored
+		event.Error = err.Error()
+	}
+	h.emit(event)
+}
+
+// startEvent records the given operation as in progress for the given
+// resource instance, as part of the innermost operation already in progress
+// for it, and returns the operation's start event. The caller must hold the
+// lock.
+func (h *JSONStreamHook) startEvent(addr addrs.AbsResourceInstance, op *streamOperation) *json.StreamEvent {
+	key := addr.String()
+	parent := h.root
+	if open := h.open[key]; len(open) > 0 {
+		parent = open[len(open)-1]
+	}
+	op.parent = parent.id
+	h.open[key] = append(h.open[key], op)
+
+	return &json.StreamEvent{
+		Type:      json.OperationStart,
+		Timestamp: op.start,
+		ID:        op.id,
+		ParentID:  op.parent,
+		Kind:      op.kind,
+		Resource:  json.NewStreamResourceAddr(addr),
+	}
+}
+
+// endEvent returns the end event of the given operation. The caller must
+// hold the lock.
+func (h *JSONStreamHook) endEvent(op *streamOperation, typ json.StreamEventType) *json.StreamEvent {
+	now := h.timeNow()
+	elapsed := now.Sub(op.start).Milliseconds()
+	event := &json.StreamEvent{
+		Type:      typ,
+		Timestamp: now,
+		ID:        op.id,
+		ParentID:  op.parent,
+		Kind:      op.kind,
+		Status:    json.StatusComplete,
+		StartedAt: &op.start,
+		ElapsedMS: &elapsed,
+	}
+	return event
+}
+
+// findOperation returns the innermost operation in progress for the
+// resource instance with the given address that matches the given function,
+// or the root operation if there is none. The caller must hold the lock.
+func (h *JSONStreamHook) findOperation(key string, match func(*streamOperation) bool) *streamOperation {
+	open := h.open[key]
+	for i := len(open) - 1; i >= 0; i-- {
+		if match(open[i]) {
+			return open[i]
+		}
+	}
+	return h.root
+}
+
+// closeOperation removes the innermost operation in progress for the given
+// resource instance that matches the given function, and returns it, or nil
+// if there is none. The caller must hold the lock.
+func (h *JSONStreamHook) closeOperation(addr addrs.AbsResourceInstance, match func(*streamOperation) bool) *streamOperation {
+	key := addr.String()
+	op := h.findOperation(key, match)
+	if op == h.root {
+		return nil
+	}
+
+	open := h.open[key]
+	for i := range open {
+		if open[i] == op {
+			open = append(open[:i], open[i+1:]...)
+			break
+		}
+	}
+	if len(open) == 0 {
+		delete(h.open, key)
+	} else {
+		h.open[key] = open
+	}
+	return op
+}
+
+func (h *JSONStreamHook) newOperation(kind json.StreamOperationKind) *streamOperation {
+	return &streamOperation{
+		id:    h.nextID(),
+		kind:  kind,
+		start: h.timeNow(),
+	}
+}
+
+func (h *JSONStreamHook) nextID() string {
+	h.lastID++
+	return strconv.FormatInt(h.lastID, 10)
+}
+
+// emit writes the given event to the stream. The caller must hold the lock.
+func (h *JSONStreamHook) emit(event *json.StreamEvent) {
+	h.seq++
+	event.Version = json.STREAM_VERSION
+	event.Seq = h.seq
+	if err := h.enc.Encode(event); err != nil && h.err == nil {
+		h.err = err
+	}
+}
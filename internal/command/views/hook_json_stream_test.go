@@ -0,0 +1,146 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"bytes"
+	encJson "encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestJSONStreamHook_apply(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hook := newJSONStreamHook(&buf, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "boop",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	plannedNewState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("test"),
+	})
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.SimpleWarning("Deprecated attribute"))
+
+	action, err := hook.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(plannedNewState.Type()), plannedNewState)
+	testHookReturnValues(t, action, err)
+	action, err = hook.PreProviderCall(addr, provider, "ApplyResourceChange")
+	testHookReturnValues(t, action, err)
+	action, err = hook.PostProviderCall(addr, provider, "ApplyResourceChange", diags)
+	testHookReturnValues(t, action, err)
+	action, err = hook.PreProvisionInstanceStep(addr, "local-exec")
+	testHookReturnValues(t, action, err)
+	hook.ProvisionOutput(addr, "local-exec", "Executing: touch /etc/motd\n")
+	action, err = hook.PostProvisionInstanceStep(addr, "local-exec", nil)
+	testHookReturnValues(t, action, err)
+	action, err = hook.PostApply(addr, states.CurrentGen, plannedNewState, nil)
+	testHookReturnValues(t, action, err)
+	if err := hook.End(true); err != nil {
+		t.Fatal(err)
+	}
+
+	type event struct {
+		Seq       int64  `json:"seq"`
+		Type      string `json:"type"`
+		ID        string `json:"id"`
+		ParentID  string `json:"parent_id"`
+		Kind      string `json:"kind"`
+		Status    string `json:"status"`
+		Method    string `json:"method"`
+		ElapsedMS *int64 `json:"elapsed_ms"`
+	}
+	var got []event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var raw map[string]interface{}
+		if err := encJson.Unmarshal([]byte(line), &raw); err != nil {
+			t.Fatalf("invalid event %q: %s", line, err)
+		}
+		if raw["version"] != "2.0" {
+			t.Errorf("wrong version in %s", line)
+		}
+		var ev event
+		if err := encJson.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev)
+	}
+
+	ms := func(n int64) *int64 { return &n }
+	want := []event{
+		{Seq: 1, Type: "stream_start", ID: "1", Kind: "root"},
+		{Seq: 2, Type: "operation_start", ID: "2", ParentID: "1", Kind: "apply"},
+		{Seq: 3, Type: "operation_start", ID: "3", ParentID: "2", Kind: "provider_call", Method: "ApplyResourceChange"},
+		{Seq: 4, Type: "operation_end", ID: "3", ParentID: "2", Kind: "provider_call", Status: "complete", Method: "ApplyResourceChange", ElapsedMS: ms(1000)},
+		{Seq: 5, Type: "operation_start", ID: "4", ParentID: "2", Kind: "provision"},
+		{Seq: 6, Type: "provision_output", ID: "5", ParentID: "4"},
+		{Seq: 7, Type: "operation_end", ID: "4", ParentID: "2", Kind: "provision", Status: "complete", ElapsedMS: ms(2000)},
+		{Seq: 8, Type: "operation_end", ID: "2", ParentID: "1", Kind: "apply", Status: "complete", ElapsedMS: ms(6000)},
+		{Seq: 9, Type: "stream_end", ID: "1", Kind: "root", Status: "complete", ElapsedMS: ms(8000)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong events\n%s", diff)
+	}
+
+	if !strings.Contains(buf.String(), `"summary":"Deprecated attribute"`) {
+		t.Errorf("provider diagnostics missing from stream:\n%s", buf.String())
+	}
+}
+
+func TestJSONStreamHook_errored(t *testing.T) {
+	var buf bytes.Buffer
+	hook := NewJSONStreamHook(&buf)
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "boop",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Quota exceeded", "Too many instances."))
+
+	_, _ = hook.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(cty.EmptyObject), cty.EmptyObjectVal)
+	_, _ = hook.PreProviderCall(addr, provider, "ApplyResourceChange")
+	_, _ = hook.PostProviderCall(addr, provider, "ApplyResourceChange", diags)
+	_, _ = hook.PostApply(addr, states.CurrentGen, cty.NullVal(cty.EmptyObject), diags.Err())
+	if err := hook.End(false); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("wrong number of events %d:\n%s", len(lines), buf.String())
+	}
+	for _, i := range []int{3, 4, 5} {
+		if !strings.Contains(lines[i], `"status":"errored"`) {
+			t.Errorf("event %d is not errored: %s", i, lines[i])
+		}
+	}
+}
@@ -15,8 +15,10 @@ import (
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -339,6 +341,84 @@ func TestJSONHook_refresh(t *testing.T) {
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
 
+func TestJSONHook_providerRetry(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	hook := newJSONHook(NewJSONView(NewView(streams)))
+
+	addr := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Rate exceeded", "Too many requests."))
+
+	action, err := hook.ProviderRetry(addr, providers.RetryEvent{
+		Method:      "PlanResourceChange",
+		Attempt:     2,
+		MaxAttempts: 5,
+		Delay:       1500 * time.Millisecond,
+		Diagnostics: diags,
+	})
+	testHookReturnValues(t, action, err)
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": `provider["registry.opentofu.org/hashicorp/test"]: Retrying PlanResourceChange in 1.5s (attempt 2 of 5): Rate exceeded`,
+			"@module":  "tofu.ui",
+			"type":     "provider_retry",
+			"hook": map[string]interface{}{
+				"provider":      `provider["registry.opentofu.org/hashicorp/test"]`,
+				"method":        "PlanResourceChange",
+				"attempt":       float64(2),
+				"max_attempts":  float64(5),
+				"delay_seconds": float64(1.5),
+				"error":         "Rate exceeded",
+			},
+		},
+	}
+
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
+func TestJSONHook_postApplyOutput(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	hook := newJSONHook(NewJSONView(NewView(streams)))
+
+	action, err := hook.PostApplyOutput(addrs.OutputValue{Name: "endpoint"}.Absolute(addrs.RootModuleInstance), false, cty.StringVal("https://example.com/"))
+	testHookReturnValues(t, action, err)
+	action, err = hook.PostApplyOutput(addrs.OutputValue{Name: "password"}.Absolute(addrs.RootModuleInstance), true, cty.StringVal("hunter2"))
+	testHookReturnValues(t, action, err)
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "output.endpoint: Value available",
+			"@module":  "tofu.ui",
+			"type":     "output_available",
+			"hook": map[string]interface{}{
+				"name":      "endpoint",
+				"sensitive": false,
+				"type":      "string",
+				"value":     "https://example.com/",
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "output.password: Value available",
+			"@module":  "tofu.ui",
+			"type":     "output_available",
+			"hook": map[string]interface{}{
+				"name":      "password",
+				"sensitive": true,
+				"type":      "string",
+			},
+		},
+	}
+
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
 func testHookReturnValues(t *testing.T, action tofu.HookAction, err error) {
 	t.Helper()
 
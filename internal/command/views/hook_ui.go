@@ -21,6 +21,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
 
@@ -284,6 +285,29 @@ func (h *UiHook) PreRefresh(addr addrs.AbsResourceInstance, gen states.Generatio
 	return tofu.HookActionContinue, nil
 }
 
+func (h *UiHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (tofu.HookAction, error) {
+	var reason string
+	if summary := retryErrorSummary(event); summary != "" {
+		reason = ": " + summary
+	}
+	h.println(fmt.Sprintf(
+		h.view.colorize.Color("[reset][bold][yellow]%s: Retrying %s in %s (attempt %d of %d)%s"),
+		addr, event.Method, event.Delay, event.Attempt, event.MaxAttempts, reason,
+	))
+	return tofu.HookActionContinue, nil
+}
+
+// retryErrorSummary returns the summary of the first error that caused a
+// provider call to be retried.
+func retryErrorSummary(event providers.RetryEvent) string {
+	for _, diag := range event.Diagnostics {
+		if diag.Severity() == tfdiags.Error {
+			return diag.Description().Summary
+		}
+	}
+	return ""
+}
+
 func (h *UiHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (tofu.HookAction, error) {
 	h.println(fmt.Sprintf(
 		h.view.colorize.Color("[reset][bold]%s: Importing from ID %q..."),
@@ -21,6 +21,7 @@ import (
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 )
 
@@ -531,6 +532,39 @@ func TestPreImportState(t *testing.T) {
 	}
 }
 
+func TestProviderRetry(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	h := NewUiHook(view)
+
+	addr := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Plugin did not respond", "The plugin encountered an error."))
+
+	action, err := h.ProviderRetry(addr, providers.RetryEvent{
+		Method:      "ReadResource",
+		Attempt:     2,
+		MaxAttempts: 3,
+		Delay:       time.Second,
+		Diagnostics: diags,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != tofu.HookActionContinue {
+		t.Fatalf("Expected hook to continue, given: %#v", action)
+	}
+	result := done(t)
+
+	want := `provider["registry.opentofu.org/hashicorp/test"]: Retrying ReadResource in 1s (attempt 2 of 3): Plugin did not respond` + "\n"
+	if got := result.Stdout(); got != want {
+		t.Fatalf("unexpected output\n got: %q\nwant: %q", got, want)
+	}
+}
+
 // Test the PostImportState UI hook. Again, this hook behaviour seems odd to
 // me (see below), so please don't consider these tests as justification for
 // keeping this behaviour.
@@ -112,6 +112,8 @@ const (
 	ReasonDeleteBecauseEachKey          ChangeReason = "delete_because_each_key"
 	ReasonDeleteBecauseNoModule         ChangeReason = "delete_because_no_module"
 	ReasonDeleteBecauseNoMoveTarget     ChangeReason = "delete_because_no_move_target"
+	ReasonDeleteBecauseRemovedBlock     ChangeReason = "delete_because_removed_block"
+	ReasonCreateBecauseDeleted          ChangeReason = "create_because_deleted"
 	ReasonReadBecauseConfigUnknown      ChangeReason = "read_because_config_unknown"
 	ReasonReadBecauseDependencyPending  ChangeReason = "read_because_dependency_pending"
 	ReasonReadBecauseCheckNested        ChangeReason = "read_because_check_nested"
@@ -143,6 +145,10 @@ func changeReason(reason plans.ResourceInstanceChangeActionReason) ChangeReason
 		return ReasonReadBecauseConfigUnknown
 	case plans.ResourceInstanceDeleteBecauseNoMoveTarget:
 		return ReasonDeleteBecauseNoMoveTarget
+	case plans.ResourceInstanceDeleteBecauseRemovedBlock:
+		return ReasonDeleteBecauseRemovedBlock
+	case plans.ResourceInstanceCreateBecauseDeleted:
+		return ReasonCreateBecauseDeleted
 	case plans.ResourceInstanceReadBecauseDependencyPending:
 		return ReasonReadBecauseDependencyPending
 	case plans.ResourceInstanceReadBecauseCheckNested:
@@ -0,0 +1,125 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// These categories group the rules of diagnostics by the kind of problem
+// they report.
+const (
+	DiagnosticCategorySyntax         = "syntax"
+	DiagnosticCategoryReference      = "reference"
+	DiagnosticCategoryType           = "type"
+	DiagnosticCategoryProviderSchema = "provider-schema"
+	DiagnosticCategoryOther          = "other"
+)
+
+// DiagnosticRule identifies the kind of problem that a diagnostic reports, so
+// that automation can recognize it without depending on the wording of the
+// diagnostic's summary and detail, which can change between releases.
+//
+// Diagnostics that OpenTofu doesn't have a rule for have an empty ID and the
+// "other" category.
+type DiagnosticRule struct {
+	ID       string
+	Category string
+}
+
+// diagnosticRuleCategories maps the IDs of the rules that OpenTofu gives its
+// own diagnostics to their categories.
+var diagnosticRuleCategories = map[string]string{
+	tfdiags.RuleInvalidModuleName: DiagnosticCategorySyntax,
+
+	tfdiags.RuleInvalidReference:         DiagnosticCategoryReference,
+	tfdiags.RuleUndeclaredVariable:       DiagnosticCategoryReference,
+	tfdiags.RuleUndeclaredLocal:          DiagnosticCategoryReference,
+	tfdiags.RuleUndeclaredModule:         DiagnosticCategoryReference,
+	tfdiags.RuleUndeclaredOutput:         DiagnosticCategoryReference,
+	tfdiags.RuleUndeclaredResource:       DiagnosticCategoryReference,
+	tfdiags.RuleUndefinedVariable:        DiagnosticCategoryReference,
+	tfdiags.RuleInvalidProviderReference: DiagnosticCategoryReference,
+
+	tfdiags.RuleInvalidCount:           DiagnosticCategoryType,
+	tfdiags.RuleInvalidVariableDefault: DiagnosticCategoryType,
+	tfdiags.RuleInvalidForEach:         DiagnosticCategoryType,
+	tfdiags.RuleInvalidVariableValue:   DiagnosticCategoryType,
+
+	tfdiags.RuleInvalidDataSource:   DiagnosticCategoryProviderSchema,
+	tfdiags.RuleInvalidResourceType: DiagnosticCategoryProviderSchema,
+
+	tfdiags.RuleDuplicateImport:         DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateLocal:          DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateModuleCall:     DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateOutput:         DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateProviderConfig: DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateResource:       DiagnosticCategoryOther,
+	tfdiags.RuleDuplicateVariable:       DiagnosticCategoryOther,
+	tfdiags.RuleMissingRequiredProvider: DiagnosticCategoryOther,
+	tfdiags.RuleModuleNotInstalled:      DiagnosticCategoryOther,
+	tfdiags.RuleUndeclaredVariableValue: DiagnosticCategoryOther,
+}
+
+// hclDiagnosticRules maps the summaries of the diagnostics that the HCL
+// library creates to their rules, because those diagnostics can't carry the
+// rules that OpenTofu gives its own diagnostics. The rule IDs are part of the
+// machine-readable validate output, so once added they must not change.
+var hclDiagnosticRules = map[string]DiagnosticRule{
+	// Syntax errors
+	"Argument or block definition required":           {"argument-or-block-required", DiagnosticCategorySyntax},
+	"Attribute redefined":                             {"argument-redefined", DiagnosticCategorySyntax},
+	"Extra characters after interpolation expression": {"extra-characters-after-interpolation", DiagnosticCategorySyntax},
+	"Invalid argument name":                           {"invalid-argument-name", DiagnosticCategorySyntax},
+	"Invalid block definition":                        {"invalid-block-definition", DiagnosticCategorySyntax},
+	"Invalid character":                               {"invalid-character", DiagnosticCategorySyntax},
+	"Invalid expression":                              {"invalid-expression", DiagnosticCategorySyntax},
+	"Invalid multi-line string":                       {"invalid-multi-line-string", DiagnosticCategorySyntax},
+	"Missing item separator":                          {"missing-item-separator", DiagnosticCategorySyntax},
+	"Missing key/value separator":                     {"missing-key-value-separator", DiagnosticCategorySyntax},
+	"Missing newline after argument":                  {"missing-newline-after-argument", DiagnosticCategorySyntax},
+	"Unclosed configuration block":                    {"unclosed-block", DiagnosticCategorySyntax},
+	"Unsupported operator":                            {"unsupported-operator", DiagnosticCategorySyntax},
+	"Unterminated template string":                    {"unterminated-template-string", DiagnosticCategorySyntax},
+
+	// References to objects and functions
+	"Call to unknown function": {"unknown-function", DiagnosticCategoryReference},
+	"Unsupported attribute":    {"unsupported-attribute", DiagnosticCategoryReference},
+	"Variables not allowed":    {"variables-not-allowed", DiagnosticCategoryReference},
+
+	// Values of the wrong type
+	"Incorrect attribute value type":        {"incorrect-value-type", DiagnosticCategoryType},
+	"Inconsistent conditional result types": {"inconsistent-conditional-types", DiagnosticCategoryType},
+	"Invalid function argument":             {"invalid-function-argument", DiagnosticCategoryType},
+	"Invalid index":                         {"invalid-index", DiagnosticCategoryType},
+	"Invalid template interpolation value":  {"invalid-interpolation-value", DiagnosticCategoryType},
+	"Invalid type specification":            {"invalid-type-specification", DiagnosticCategoryType},
+	"Not enough function arguments":         {"not-enough-function-arguments", DiagnosticCategoryType},
+	"Too many function arguments":           {"too-many-function-arguments", DiagnosticCategoryType},
+	"Unsuitable value type":                 {"unsuitable-value-type", DiagnosticCategoryType},
+
+	// Arguments and blocks that don't match the schema of a provider or of
+	// a configuration block
+	"Duplicate argument":        {"duplicate-argument", DiagnosticCategoryProviderSchema},
+	"Missing required argument": {"missing-required-argument", DiagnosticCategoryProviderSchema},
+	"Unsupported argument":      {"unsupported-argument", DiagnosticCategoryProviderSchema},
+	"Unsupported block type":    {"unsupported-block-type", DiagnosticCategoryProviderSchema},
+}
+
+// DiagnosticRuleFor returns the rule of the given diagnostic.
+func DiagnosticRuleFor(diag tfdiags.Diagnostic) DiagnosticRule {
+	if id := tfdiags.DiagnosticRuleID(diag); id != "" {
+		category, ok := diagnosticRuleCategories[id]
+		if !ok {
+			category = DiagnosticCategoryOther
+		}
+		return DiagnosticRule{ID: id, Category: category}
+	}
+	if rule, ok := hclDiagnosticRules[diag.Description().Summary]; ok {
+		return rule
+	}
+	return DiagnosticRule{Category: DiagnosticCategoryOther}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestDiagnosticRuleFor(t *testing.T) {
+	tests := map[string]struct {
+		diag tfdiags.Diagnostic
+		want DiagnosticRule
+	}{
+		"rule from the HCL library's summary": {
+			tfdiags.Sourceless(tfdiags.Error, "Unsupported argument", ""),
+			DiagnosticRule{"unsupported-argument", DiagnosticCategoryProviderSchema},
+		},
+		"explicit rule": {
+			hclDiag(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to undeclared input variable",
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredVariable, nil),
+			}),
+			DiagnosticRule{"undeclared-variable", DiagnosticCategoryReference},
+		},
+		"explicit rule wrapping another extra": {
+			hclDiag(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  `Duplicate resource "aws_instance" configuration`,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateResource, "something else"),
+			}),
+			DiagnosticRule{"duplicate-resource", DiagnosticCategoryOther},
+		},
+		"explicit rule on a sourceless diagnostic": {
+			tfdiags.WithRule(tfdiags.Sourceless(tfdiags.Error, "Value for undeclared variable", ""), tfdiags.RuleUndeclaredVariableValue),
+			DiagnosticRule{"undeclared-variable-value", DiagnosticCategoryOther},
+		},
+		"no rule without an explicit one": {
+			// The summary is the same as one with an explicit rule, but
+			// only the diagnostics that have the rule report it.
+			tfdiags.Sourceless(tfdiags.Error, "Reference to undeclared input variable", ""),
+			DiagnosticRule{"", DiagnosticCategoryOther},
+		},
+		"no rule": {
+			tfdiags.Sourceless(tfdiags.Error, "Something unusual happened", ""),
+			DiagnosticRule{"", DiagnosticCategoryOther},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := DiagnosticRuleFor(test.diag); got != test.want {
+				t.Errorf("wrong rule\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+			// Overriding the severity must not change the rule.
+			if got := DiagnosticRuleFor(tfdiags.Override(test.diag, tfdiags.Warning, nil)); got != test.want {
+				t.Errorf("wrong rule for overridden diagnostic\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func hclDiag(diag *hcl.Diagnostic) tfdiags.Diagnostic {
+	var diags tfdiags.Diagnostics
+	return diags.Append(diag)[0]
+}
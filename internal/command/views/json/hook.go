@@ -303,6 +303,66 @@ func NewRefreshComplete(addr addrs.AbsResourceInstance, idKey, idValue string) H
 	}
 }
 
+// ProviderRetry: triggered by ProviderRetry hook
+type providerRetry struct {
+	Provider    string  `json:"provider"`
+	Method      string  `json:"method"`
+	Attempt     int     `json:"attempt"`
+	MaxAttempts int     `json:"max_attempts"`
+	Delay       float64 `json:"delay_seconds"`
+	Error       string  `json:"error,omitempty"`
+	delay       time.Duration
+}
+
+var _ Hook = (*providerRetry)(nil)
+
+func (h *providerRetry) HookType() MessageType {
+	return MessageProviderRetry
+}
+
+func (h *providerRetry) String() string {
+	var reason string
+	if h.Error != "" {
+		reason = ": " + h.Error
+	}
+	return fmt.Sprintf("%s: Retrying %s in %s (attempt %d of %d)%s", h.Provider, h.Method, h.delay, h.Attempt, h.MaxAttempts, reason)
+}
+
+func NewProviderRetry(addr addrs.AbsProviderConfig, method string, attempt, maxAttempts int, delay time.Duration, errSummary string) Hook {
+	return &providerRetry{
+		Provider:    addr.String(),
+		Method:      method,
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		Delay:       delay.Seconds(),
+		Error:       errSummary,
+		delay:       delay,
+	}
+}
+
+// OutputAvailable: triggered by PostApplyOutput hook
+type outputAvailable struct {
+	Name string `json:"name"`
+	Output
+}
+
+var _ Hook = (*outputAvailable)(nil)
+
+func (h *outputAvailable) HookType() MessageType {
+	return MessageOutputAvailable
+}
+
+func (h *outputAvailable) String() string {
+	return fmt.Sprintf("output.%s: Value available", h.Name)
+}
+
+func NewOutputAvailable(name string, output Output) Hook {
+	return &outputAvailable{
+		Name:   name,
+		Output: output,
+	}
+}
+
 // Convert the subset of plans.Action values we expect to receive into a
 // present-tense verb for the applyStart hook message.
 func startActionVerb(action plans.Action) string {
@@ -30,6 +30,8 @@ const (
 	MessageProvisionErrored  MessageType = "provision_errored"
 	MessageRefreshStart      MessageType = "refresh_start"
 	MessageRefreshComplete   MessageType = "refresh_complete"
+	MessageProviderRetry     MessageType = "provider_retry"
+	MessageOutputAvailable   MessageType = "output_available"
 
 	// Test messages
 	MessageTestAbstract  MessageType = "test_abstract"
@@ -0,0 +1,114 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// STREAM_VERSION describes the schema of the events in the JSON progress
+// stream. This version must be updated after making any changes to
+// StreamEvent or to the events emitted by the JSON stream hook.
+const STREAM_VERSION = "2.0"
+
+// StreamEventType is the type of an event in the JSON progress stream.
+type StreamEventType string
+
+const (
+	// StreamStart and StreamEnd are the first and last events of a stream.
+	// They share the ID of the root operation, which is the parent of all
+	// resource operations.
+	StreamStart StreamEventType = "stream_start"
+	StreamEnd   StreamEventType = "stream_end"
+
+	// OperationStart and OperationEnd mark the boundaries of an operation,
+	// and share its ID.
+	OperationStart StreamEventType = "operation_start"
+	OperationEnd   StreamEventType = "operation_end"
+
+	// StreamProvisionOutput is a line of output from a provisioner, and
+	// StreamProviderRetry announces that a failed provider call is about to
+	// be retried. Each has an ID of its own, and the ID of the operation it
+	// belongs to as its parent.
+	StreamProvisionOutput StreamEventType = "provision_output"
+	StreamProviderRetry   StreamEventType = "provider_retry"
+)
+
+// StreamOperationKind describes what an operation in the JSON progress
+// stream does.
+type StreamOperationKind string
+
+const (
+	OperationRoot         StreamOperationKind = "root"
+	OperationRefresh      StreamOperationKind = "refresh"
+	OperationPlan         StreamOperationKind = "plan"
+	OperationApply        StreamOperationKind = "apply"
+	OperationProvision    StreamOperationKind = "provision"
+	OperationProviderCall StreamOperationKind = "provider_call"
+)
+
+// StreamStatus is the outcome of an operation in the JSON progress stream.
+type StreamStatus string
+
+const (
+	StatusComplete StreamStatus = "complete"
+	StatusErrored  StreamStatus = "errored"
+)
+
+// StreamEvent is a single line of the JSON progress stream. Only the fields
+// that are relevant to the type of event are set.
+type StreamEvent struct {
+	Version   string          `json:"version"`
+	Seq       int64           `json:"seq"`
+	Type      StreamEventType `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+
+	// ID identifies the operation that the event belongs to, and ParentID
+	// the operation that it's part of, if any.
+	ID       string              `json:"id"`
+	ParentID string              `json:"parent_id,omitempty"`
+	Kind     StreamOperationKind `json:"kind,omitempty"`
+
+	Tofu        string        `json:"tofu,omitempty"`
+	Resource    *ResourceAddr `json:"resource,omitempty"`
+	Action      ChangeAction  `json:"action,omitempty"`
+	Provider    string        `json:"provider,omitempty"`
+	Method      string        `json:"method,omitempty"`
+	Provisioner string        `json:"provisioner,omitempty"`
+	Output      string        `json:"output,omitempty"`
+
+	// Attempt, MaxAttempts and DelayMS describe a provider retry.
+	Attempt     int   `json:"attempt,omitempty"`
+	MaxAttempts int   `json:"max_attempts,omitempty"`
+	DelayMS     int64 `json:"delay_ms,omitempty"`
+
+	// Status, StartedAt and ElapsedMS are set for the end of an operation,
+	// whose Timestamp is the time it ended.
+	Status    StreamStatus `json:"status,omitempty"`
+	StartedAt *time.Time   `json:"started_at,omitempty"`
+	ElapsedMS *int64       `json:"elapsed_ms,omitempty"`
+
+	// Error summarizes why an operation failed, and Diagnostics are the
+	// diagnostics returned by a provider call.
+	Error       string        `json:"error,omitempty"`
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// NewStreamResourceAddr returns the representation of a resource instance
+// address for the JSON progress stream.
+func NewStreamResourceAddr(addr addrs.AbsResourceInstance) *ResourceAddr {
+	ret := newResourceAddr(addr)
+	return &ret
+}
+
+// NewStreamChangeAction returns the representation of a change action for
+// the JSON progress stream.
+func NewStreamChangeAction(action plans.Action) ChangeAction {
+	return changeAction(action)
+}
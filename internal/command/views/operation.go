@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/arguments"
@@ -18,6 +19,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
 	"github.com/opentofu/opentofu/internal/command/views/json"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/memlimit"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -36,6 +38,8 @@ type Operation interface {
 	Plan(plan *plans.Plan, schemas *tofu.Schemas)
 	PlanNextStep(planPath string, genConfigPath string)
 
+	ParallelismReport(report []tofu.ProviderParallelism)
+
 	Diagnostics(diags tfdiags.Diagnostics)
 }
 
@@ -106,6 +110,17 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
 		Streams:             v.view.streams,
 		RunningInAutomation: v.inAutomation,
 		ShowSensitive:       v.view.showSensitive,
+		CompactChanges:      v.view.compactChanges,
+		DiffRenderers:       v.view.diffRenderers,
+	}
+
+	if memlimit.Exceeded() && !renderer.CompactChanges {
+		// Rendering every change in full for a plan this large could
+		// exceed the budget further, so we group the identical ones.
+		renderer.CompactChanges = true
+		v.view.streams.Println(format.WordWrap(
+			fmt.Sprintf("\nOpenTofu used more memory than the budget set by %s, so identical changes to instances of the same resource are shown in a compact form.", memlimit.EnvVar),
+			v.view.outputColumns()))
 	}
 
 	jplan := jsonformat.Plan{
@@ -170,6 +185,15 @@ func (v *OperationHuman) PlanNextStep(planPath string, genConfigPath string) {
 	}
 }
 
+// ParallelismReport shows the parallelism that was chosen for each provider
+// configuration when tuning the parallelism of an apply.
+func (v *OperationHuman) ParallelismReport(report []tofu.ProviderParallelism) {
+	v.view.streams.Println(v.view.colorize.Color("\n[bold]Parallelism chosen for each provider configuration:[reset]"))
+	for _, p := range report {
+		v.view.streams.Printf("  %s\n", parallelismReportLine(p))
+	}
+}
+
 func (v *OperationHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -219,7 +243,7 @@ func (v *OperationJSON) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
 		// including those which have moved without other changes. In other plan
 		// modes, move-only changes will be included in the planned changes, so
 		// we skip them here.
-		if dr.Action != plans.NoOp || plan.UIMode == plans.RefreshOnlyMode {
+		if dr.Action != plans.NoOp || plan.UIMode.RefreshesOnly() {
 			v.view.ResourceDrift(json.NewResourceInstanceChange(dr))
 		}
 	}
@@ -281,10 +305,25 @@ func (v *OperationJSON) PlannedChange(change *plans.ResourceInstanceChangeSrc) {
 func (v *OperationJSON) PlanNextStep(planPath string, genConfigPath string) {
 }
 
+// ParallelismReport logs the parallelism that was chosen for each provider
+// configuration when tuning the parallelism of an apply.
+func (v *OperationJSON) ParallelismReport(report []tofu.ProviderParallelism) {
+	for _, p := range report {
+		v.view.Log(fmt.Sprintf("Parallelism chosen for %s", parallelismReportLine(p)))
+	}
+}
+
 func (v *OperationJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
+func parallelismReportLine(p tofu.ProviderParallelism) string {
+	return fmt.Sprintf(
+		"%s: %d (lowest %d), %d changes taking %s on average",
+		p.Provider, p.Limit, p.MinLimit, p.Requests, p.MeanLatency.Round(time.Millisecond),
+	)
+}
+
 const fatalInterrupt = `
 Two interrupts received. Exiting immediately. Note that data loss may have occurred.
 `
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/arguments"
@@ -105,6 +106,15 @@ func TestOperation_planNoChanges(t *testing.T) {
 			},
 			"OpenTofu has checked that the real remote objects still match",
 		},
+		"nothing at all in drift-only mode": {
+			func(schemas *tofu.Schemas) *plans.Plan {
+				return &plans.Plan{
+					UIMode:  plans.DriftOnlyMode,
+					Changes: plans.NewChanges(),
+				}
+			},
+			"No drift detected.",
+		},
 		"nothing at all in destroy mode": {
 			func(schemas *tofu.Schemas) *plans.Plan {
 				return &plans.Plan{
@@ -243,6 +253,47 @@ func TestOperation_planNoChanges(t *testing.T) {
 			},
 			"If you were expecting these changes then you can apply this plan",
 		},
+		"drift detected in drift-only mode": {
+			func(schemas *tofu.Schemas) *plans.Plan {
+				addr := addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_resource",
+					Name: "somewhere",
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+				schema, _ := schemas.ResourceTypeConfig(
+					addrs.NewDefaultProvider("test"),
+					addr.Resource.Resource.Mode,
+					addr.Resource.Resource.Type,
+				)
+				ty := schema.ImpliedType()
+				rc := &plans.ResourceInstanceChange{
+					Addr:        addr,
+					PrevRunAddr: addr,
+					ProviderAddr: addrs.RootModuleInstance.ProviderConfigDefault(
+						addrs.NewDefaultProvider("test"),
+					),
+					Change: plans.Change{
+						Action: plans.Update,
+						Before: cty.NullVal(ty),
+						After: cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.StringVal("1234"),
+							"foo": cty.StringVal("bar"),
+						}),
+					},
+				}
+				rcs, err := rc.Encode(ty)
+				if err != nil {
+					panic(err)
+				}
+				drs := []*plans.ResourceInstanceChangeSrc{rcs}
+				return &plans.Plan{
+					UIMode:           plans.DriftOnlyMode,
+					Changes:          plans.NewChanges(),
+					DriftedResources: drs,
+				}
+			},
+			"This is a drift-only plan, so OpenTofu did not evaluate any changes",
+		},
 		"move-only changes in refresh-only mode": {
 			func(schemas *tofu.Schemas) *plans.Plan {
 				addr := addrs.Resource{
@@ -478,6 +529,29 @@ func TestOperation_planNextStepInAutomation(t *testing.T) {
 
 // Test all the trivial OperationJSON methods together. Y'know, for brevity.
 // This test is not a realistic stream of messages.
+func TestOperation_parallelismReport(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewOperation(arguments.ViewHuman, false, NewView(streams))
+
+	v.ParallelismReport([]tofu.ProviderParallelism{
+		{
+			Provider:    addrs.RootModuleInstance.ProviderConfigDefault(addrs.NewDefaultProvider("test")),
+			Limit:       4,
+			MinLimit:    2,
+			Requests:    12,
+			MeanLatency: 1500 * time.Millisecond,
+		},
+	})
+
+	want := `
+Parallelism chosen for each provider configuration:
+  provider["registry.opentofu.org/hashicorp/test"]: 4 (lowest 2), 12 changes taking 1.5s on average
+`
+	if got := done(t).Stdout(); got != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
 func TestOperationJSON_logs(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	v := &OperationJSON{view: NewJSONView(NewView(streams))}
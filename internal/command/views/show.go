@@ -9,11 +9,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/cloud/cloudplan"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/jsonformat"
+	"github.com/opentofu/opentofu/internal/command/jsonpermissions"
 	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonpolicy"
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
 	"github.com/opentofu/opentofu/internal/command/jsonstate"
 	"github.com/opentofu/opentofu/internal/configs"
@@ -54,6 +57,8 @@ func (v *ShowHuman) Display(config *configs.Config, plan *plans.Plan, planJSON *
 		Streams:             v.view.streams,
 		RunningInAutomation: v.view.runningInAutomation,
 		ShowSensitive:       v.view.showSensitive,
+		CompactChanges:      v.view.compactChanges,
+		DiffRenderers:       v.view.diffRenderers,
 	}
 
 	// Prefer to display a pre-built JSON plan, if we got one; then, fall back
@@ -74,22 +79,12 @@ func (v *ShowHuman) Display(config *configs.Config, plan *plans.Plan, planJSON *
 		renderer.RenderHumanPlan(p, planJSON.Mode, planJSON.Qualities...)
 		v.view.streams.Print(v.view.colorize.Color("\n" + planJSON.RunFooter + "\n"))
 	} else if plan != nil {
-		outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
+		jplan, err := renderablePlan(plan, schemas)
 		if err != nil {
 			v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
 			return 1
 		}
 
-		jplan := jsonformat.Plan{
-			PlanFormatVersion:     jsonplan.FormatVersion,
-			ProviderFormatVersion: jsonprovider.FormatVersion,
-			OutputChanges:         outputs,
-			ResourceChanges:       changed,
-			ResourceDrift:         drift,
-			ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
-			RelevantAttributes:    attrs,
-		}
-
 		var opts []plans.Quality
 		if !plan.CanApply() {
 			opts = append(opts, plans.NoChanges)
@@ -128,6 +123,79 @@ func (v *ShowHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
+// renderablePlan converts the given plan into the representation consumed by
+// the jsonformat renderers.
+func renderablePlan(plan *plans.Plan, schemas *tofu.Schemas) (jsonformat.Plan, error) {
+	outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
+	if err != nil {
+		return jsonformat.Plan{}, err
+	}
+
+	return jsonformat.Plan{
+		PlanFormatVersion:     jsonplan.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		OutputChanges:         outputs,
+		ResourceChanges:       changed,
+		ResourceDrift:         drift,
+		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
+		RelevantAttributes:    attrs,
+	}, nil
+}
+
+// ShowHCL renders the planned new values of a saved plan as HCL snippets.
+type ShowHCL struct {
+	view *View
+}
+
+var _ Show = (*ShowHCL)(nil)
+
+// NewShowHCL returns the view used by "tofu show -hcl".
+func NewShowHCL(view *View) Show {
+	return &ShowHCL{view: view}
+}
+
+func (v *ShowHCL) Display(config *configs.Config, plan *plans.Plan, planJSON *cloudplan.RemotePlanJSON, stateFile *statefile.File, schemas *tofu.Schemas) int {
+	renderer := jsonformat.Renderer{
+		Colorize:            v.view.colorize,
+		Streams:             v.view.streams,
+		RunningInAutomation: v.view.runningInAutomation,
+		ShowSensitive:       v.view.showSensitive,
+	}
+
+	var jplan jsonformat.Plan
+	switch {
+	case planJSON != nil:
+		if !planJSON.Redacted {
+			v.view.streams.Eprintf("Didn't get renderable JSON plan format for HCL display")
+			return 1
+		}
+		if err := json.Unmarshal(planJSON.JSONBytes, &jplan); err != nil {
+			v.view.streams.Eprintf("Couldn't decode renderable JSON plan format: %s", err)
+			return 1
+		}
+	case plan != nil:
+		var err error
+		jplan, err = renderablePlan(plan, schemas)
+		if err != nil {
+			v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
+			return 1
+		}
+	default:
+		v.view.streams.Eprintf("The HCL format is only available for saved plan files\n")
+		return 1
+	}
+
+	if err := renderer.RenderHCLPlan(jplan); err != nil {
+		v.view.streams.Eprintf("Failed to render plan as HCL: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (v *ShowHCL) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
 type ShowJSON struct {
 	view *View
 }
@@ -154,12 +222,15 @@ func (v *ShowJSON) Display(config *configs.Config, plan *plans.Plan, planJSON *c
 	} else {
 		// It is possible that there is neither state nor a plan.
 		// That's ok, we'll just return an empty object.
-		jsonState, err := jsonstate.Marshal(stateFile, schemas)
+		//
+		// States can be very large, so we write the JSON as we go rather
+		// than building it all in memory first.
+		err := jsonstate.MarshalTo(v.view.streams.Stdout.File, stateFile, schemas)
 		if err != nil {
 			v.view.streams.Eprintf("Failed to marshal state to json: %s", err)
 			return 1
 		}
-		v.view.streams.Println(string(jsonState))
+		v.view.streams.Println()
 	}
 	return 0
 }
@@ -170,3 +241,126 @@ func (v *ShowJSON) Display(config *configs.Config, plan *plans.Plan, planJSON *c
 func (v *ShowJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
+
+// ShowNDJSON renders the resource instance objects of a state as
+// newline-delimited JSON.
+type ShowNDJSON struct {
+	view *View
+}
+
+var _ Show = (*ShowNDJSON)(nil)
+
+func NewShowNDJSON(view *View) Show {
+	return &ShowNDJSON{view: view}
+}
+
+func (v *ShowNDJSON) Display(config *configs.Config, plan *plans.Plan, planJSON *cloudplan.RemotePlanJSON, stateFile *statefile.File, schemas *tofu.Schemas) int {
+	if plan != nil || planJSON != nil {
+		v.view.streams.Eprintln("The -ndjson option can only be used to show a state, not a plan.")
+		return 1
+	}
+	if err := jsonstate.MarshalResourcesTo(v.view.streams.Stdout.File, stateFile, schemas); err != nil {
+		v.view.streams.Eprintf("Failed to marshal state to json: %s", err)
+		return 1
+	}
+	return 0
+}
+
+// Diagnostics should only be called if show cannot be executed.
+func (v *ShowNDJSON) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+// ShowPolicyInput renders a saved plan using the policy input format
+// described by package jsonpolicy.
+type ShowPolicyInput struct {
+	view *View
+}
+
+var _ Show = (*ShowPolicyInput)(nil)
+
+// NewShowPolicyInput returns the view used by "tofu show -policy-input".
+func NewShowPolicyInput(view *View) Show {
+	return &ShowPolicyInput{view: view}
+}
+
+func (v *ShowPolicyInput) Display(config *configs.Config, plan *plans.Plan, planJSON *cloudplan.RemotePlanJSON, stateFile *statefile.File, schemas *tofu.Schemas) int {
+	if plan == nil {
+		v.view.streams.Eprintf("The policy input format is only available for local saved plan files\n")
+		return 1
+	}
+
+	policyJSON, err := jsonpolicy.Marshal(config, plan, schemas)
+	if err != nil {
+		v.view.streams.Eprintf("Failed to marshal plan to policy input json: %s", err)
+		return 1
+	}
+	v.view.streams.Println(string(policyJSON))
+	return 0
+}
+
+// Diagnostics renders human-readable diagnostics, for the same reasons as
+// ShowJSON.Diagnostics.
+func (v *ShowPolicyInput) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+// ShowPermissions renders the permissions required to apply a saved plan, as
+// computed by package jsonpermissions.
+type ShowPermissions struct {
+	view     *View
+	viewType arguments.ViewType
+}
+
+var _ Show = (*ShowPermissions)(nil)
+
+// NewShowPermissions returns the view used by "tofu show -permissions".
+func NewShowPermissions(vt arguments.ViewType, view *View) Show {
+	return &ShowPermissions{view: view, viewType: vt}
+}
+
+func (v *ShowPermissions) Display(config *configs.Config, plan *plans.Plan, planJSON *cloudplan.RemotePlanJSON, stateFile *statefile.File, schemas *tofu.Schemas) int {
+	if plan == nil {
+		v.view.streams.Eprintf("The permissions report is only available for local saved plan files\n")
+		return 1
+	}
+
+	if v.viewType == arguments.ViewJSON {
+		reportJSON, err := jsonpermissions.Marshal(plan, schemas)
+		if err != nil {
+			v.view.streams.Eprintf("Failed to marshal permissions report to json: %s", err)
+			return 1
+		}
+		v.view.streams.Println(string(reportJSON))
+		return 0
+	}
+
+	report := jsonpermissions.Analyze(plan, schemas)
+	if len(report.Providers) == 0 && len(report.MissingHints) == 0 {
+		v.view.streams.Println("This plan doesn't require any provider permissions.")
+		return 0
+	}
+
+	for _, provider := range report.Providers {
+		v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("[bold]Permissions required from %s:[reset]", provider.Source)))
+		for _, permission := range provider.Permissions {
+			v.view.streams.Printf("  - %s\n", permission)
+		}
+		v.view.streams.Println()
+	}
+
+	if len(report.MissingHints) > 0 {
+		v.view.streams.Println(v.view.colorize.Color("[bold][yellow]The providers of the following objects don't declare permission hints, so the permissions above may be incomplete:[reset]"))
+		for _, resource := range report.MissingHints {
+			v.view.streams.Printf("  - %s (%s)\n", resource.Address, strings.Join(resource.Operations, ", "))
+		}
+		v.view.streams.Println()
+	}
+	return 0
+}
+
+// Diagnostics renders human-readable diagnostics, for the same reasons as
+// ShowJSON.Diagnostics.
+func (v *ShowPermissions) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
@@ -72,6 +72,12 @@ type Test interface {
 	// operation alongside the current state as the state will be missing newly
 	// created resources that also need to be handled manually.
 	FatalInterruptSummary(run *moduletest.Run, file *moduletest.File, states map[*moduletest.Run]*states.State, created []*plans.ResourceInstanceChangeSrc)
+
+	// CleanSummary prints out the outcome of the test clean command, which
+	// destroys infrastructure kept by keep_on_failure. Cleaned states were
+	// fully destroyed, while remaining states are still recorded in the test
+	// state manifest.
+	CleanSummary(cleaned, remaining int)
 }
 
 func NewTest(vt arguments.ViewType, view *View) Test {
@@ -304,6 +310,20 @@ func (t *TestHuman) FatalInterruptSummary(run *moduletest.Run, file *moduletest.
 	}
 }
 
+func (t *TestHuman) CleanSummary(cleaned, remaining int) {
+	if cleaned == 0 && remaining == 0 {
+		t.view.streams.Println("No kept test infrastructure to clean up.")
+		return
+	}
+
+	if cleaned > 0 {
+		t.view.streams.Println(t.view.colorize.Color(fmt.Sprintf("[green]Cleaned up %d of %d kept test states.[reset]", cleaned, cleaned+remaining)))
+	}
+	if remaining > 0 {
+		t.view.streams.Eprint(format.WordWrap(fmt.Sprintf("\n%s\n", testCleanRemainingMessage(remaining)), t.view.errorColumns()))
+	}
+}
+
 type TestJSON struct {
 	view *JSONView
 }
@@ -541,6 +561,28 @@ func (t *TestJSON) FatalInterruptSummary(run *moduletest.Run, file *moduletest.F
 		"@testfile", file.Name)
 }
 
+func (t *TestJSON) CleanSummary(cleaned, remaining int) {
+	if cleaned == 0 && remaining == 0 {
+		t.view.log.Info("No kept test infrastructure to clean up.")
+		return
+	}
+
+	if cleaned > 0 {
+		t.view.log.Info(fmt.Sprintf("Cleaned up %d of %d kept test states.", cleaned, cleaned+remaining))
+	}
+	if remaining > 0 {
+		t.view.log.Error(testCleanRemainingMessage(remaining))
+	}
+}
+
+func testCleanRemainingMessage(remaining int) string {
+	states := "states"
+	if remaining == 1 {
+		states = "state"
+	}
+	return fmt.Sprintf("OpenTofu could not destroy everything in %d kept test %s. They remain recorded in the test state manifest, run \"tofu test clean\" again once the errors above are resolved.", remaining, states)
+}
+
 func colorizeTestStatus(status moduletest.Status, color *colorstring.Colorize) string {
 	switch status {
 	case moduletest.Error, moduletest.Fail:
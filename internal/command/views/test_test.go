@@ -1326,6 +1326,49 @@ OpenTofu was in the process of creating the following resources for
 	}
 }
 
+func TestTestHuman_CleanSummary(t *testing.T) {
+	tcs := map[string]struct {
+		cleaned   int
+		remaining int
+		stdout    string
+		stderr    string
+	}{
+		"nothing to clean": {
+			stdout: "No kept test infrastructure to clean up.\n",
+		},
+		"all cleaned": {
+			cleaned: 2,
+			stdout:  "Cleaned up 2 of 2 kept test states.\n",
+		},
+		"some remaining": {
+			cleaned:   1,
+			remaining: 1,
+			stdout:    "Cleaned up 1 of 2 kept test states.\n",
+			stderr: `
+OpenTofu could not destroy everything in 1 kept test state. They remain
+recorded in the test state manifest, run "tofu test clean" again once the
+errors above are resolved.
+`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			view := NewTest(arguments.ViewHuman, NewView(streams))
+
+			view.CleanSummary(tc.cleaned, tc.remaining)
+
+			output := done(t)
+			if diff := cmp.Diff(tc.stdout, output.Stdout()); len(diff) > 0 {
+				t.Errorf("wrong stdout\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.stderr, output.Stderr()); len(diff) > 0 {
+				t.Errorf("wrong stderr\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestTestJSON_Abstract(t *testing.T) {
 	tcs := map[string]struct {
 		suite *moduletest.Suite
@@ -3256,6 +3299,25 @@ func TestTestJSON_FatalInterruptSummary(t *testing.T) {
 	}
 }
 
+func TestTestJSON_CleanSummary(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewTest(arguments.ViewJSON, NewView(streams))
+
+	view.CleanSummary(1, 2)
+	testJSONViewOutputEquals(t, done(t).All(), []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "Cleaned up 1 of 3 kept test states.",
+			"@module":  "tofu.ui",
+		},
+		{
+			"@level":   "error",
+			"@message": "OpenTofu could not destroy everything in 2 kept test states. They remain recorded in the test state manifest, run \"tofu test clean\" again once the errors above are resolved.",
+			"@module":  "tofu.ui",
+		},
+	})
+}
+
 func TestSaveErroredStateFile(t *testing.T) {
 	tcsHuman := map[string]struct {
 		state  *states.State
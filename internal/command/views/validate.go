@@ -86,7 +86,16 @@ func (v *ValidateJSON) Results(diags tfdiags.Diagnostics) int {
 	// FormatVersion represents the version of the json format and will be
 	// incremented for any change to this format that requires changes to a
 	// consuming parser.
-	const FormatVersion = "1.0"
+	const FormatVersion = "1.1"
+
+	// Diagnostic extends the JSON diagnostic format with the rule and
+	// category of the diagnostic, so that automation can tell which kind
+	// of problem each diagnostic reports.
+	type Diagnostic struct {
+		*viewsjson.Diagnostic
+		Rule     string `json:"rule,omitempty"`
+		Category string `json:"category"`
+	}
 
 	type Output struct {
 		FormatVersion string `json:"format_version"`
@@ -94,10 +103,10 @@ func (v *ValidateJSON) Results(diags tfdiags.Diagnostics) int {
 		// We include some summary information that is actually redundant
 		// with the detailed diagnostics, but avoids the need for callers
 		// to re-implement our logic for deciding these.
-		Valid        bool                    `json:"valid"`
-		ErrorCount   int                     `json:"error_count"`
-		WarningCount int                     `json:"warning_count"`
-		Diagnostics  []*viewsjson.Diagnostic `json:"diagnostics"`
+		Valid        bool          `json:"valid"`
+		ErrorCount   int           `json:"error_count"`
+		WarningCount int           `json:"warning_count"`
+		Diagnostics  []*Diagnostic `json:"diagnostics"`
 	}
 
 	output := Output{
@@ -106,7 +115,12 @@ func (v *ValidateJSON) Results(diags tfdiags.Diagnostics) int {
 	}
 	configSources := v.view.configSources()
 	for _, diag := range diags {
-		output.Diagnostics = append(output.Diagnostics, viewsjson.NewDiagnostic(diag, configSources))
+		rule := viewsjson.DiagnosticRuleFor(diag)
+		output.Diagnostics = append(output.Diagnostics, &Diagnostic{
+			Diagnostic: viewsjson.NewDiagnostic(diag, configSources),
+			Rule:       rule.ID,
+			Category:   rule.Category,
+		})
 
 		switch diag.Severity() {
 		case tfdiags.Error:
@@ -119,7 +133,7 @@ func (v *ValidateJSON) Results(diags tfdiags.Diagnostics) int {
 	if output.Diagnostics == nil {
 		// Make sure this always appears as an array in our output, since
 		// this is easier to consume for dynamically-typed languages.
-		output.Diagnostics = []*viewsjson.Diagnostic{}
+		output.Diagnostics = []*Diagnostic{}
 	}
 
 	j, err := json.MarshalIndent(&output, "", "  ")
@@ -133,6 +133,12 @@ func TestValidateJSON(t *testing.T) {
 			if err := json.Unmarshal([]byte(got), &result); err != nil {
 				t.Fatal(err)
 			}
+			if tc.diag != nil {
+				diag := result["diagnostics"].([]interface{})[0].(map[string]interface{})
+				if diag["category"] != "other" {
+					t.Errorf("wrong category %#v", diag["category"])
+				}
+			}
 		})
 	}
 }
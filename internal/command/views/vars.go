@@ -0,0 +1,207 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/repl"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// VariableValue describes the final value of a root module input variable,
+// as reported by the "tofu vars show" command.
+type VariableValue struct {
+	Name string
+
+	// Value is cty.NilVal if the variable has no default value and wasn't
+	// set by any source.
+	Value cty.Value
+
+	Sensitive bool
+
+	// Sources lists the places that contributed to the value, in the order
+	// they were applied. There is more than one source only when values were
+	// merged using -var-file-merge.
+	Sources []VariableValueSource
+}
+
+// VariableValueSource describes one place that set a variable value.
+type VariableValueSource struct {
+	Type tofu.ValueSourceType
+
+	// Range is the location of the value or, for defaults, of the variable
+	// declaration. It is the zero value for sources other than files.
+	Range tfdiags.SourceRange
+}
+
+// The VarsShow view renders the final values of root module input variables
+// and where they came from.
+type VarsShow interface {
+	Variables(vars []*VariableValue) tfdiags.Diagnostics
+	Diagnostics(diags tfdiags.Diagnostics)
+}
+
+// NewVarsShow returns an initialized VarsShow implementation for the given
+// ViewType.
+func NewVarsShow(vt arguments.ViewType, view *View) VarsShow {
+	switch vt {
+	case arguments.ViewJSON:
+		return &VarsShowJSON{view: view}
+	case arguments.ViewHuman:
+		return &VarsShowHuman{view: view}
+	default:
+		panic(fmt.Sprintf("unknown view type %v", vt))
+	}
+}
+
+// The VarsShowHuman implementation renders each value in a format equivalent
+// to HCL source, followed by its sources.
+type VarsShowHuman struct {
+	view *View
+}
+
+var _ VarsShow = (*VarsShowHuman)(nil)
+
+func (v *VarsShowHuman) Variables(vars []*VariableValue) tfdiags.Diagnostics {
+	var buf bytes.Buffer
+	for _, vv := range vars {
+		switch {
+		case vv.Value == cty.NilVal:
+			fmt.Fprintf(&buf, "%s = <not set>\n", vv.Name)
+		case vv.Sensitive && !v.view.showSensitive:
+			fmt.Fprintf(&buf, "%s = <sensitive>\n", vv.Name)
+		default:
+			fmt.Fprintf(&buf, "%s = %s\n", vv.Name, repl.FormatValue(vv.Value, 0))
+		}
+		for i, source := range vv.Sources {
+			prefix := "from"
+			if i > 0 {
+				prefix = "merged with"
+			}
+			fmt.Fprintf(&buf, "  %s %s\n", prefix, describeVariableValueSource(vv.Name, source))
+		}
+	}
+	v.view.streams.Println(strings.TrimSpace(buf.String()))
+	return nil
+}
+
+func (v *VarsShowHuman) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+func describeVariableValueSource(name string, source VariableValueSource) string {
+	switch source.Type {
+	case tofu.ValueFromConfig:
+		return fmt.Sprintf("the default value at %s", source.Range.StartString())
+	case tofu.ValueFromAutoFile:
+		return fmt.Sprintf("%s (loaded automatically)", source.Range.StartString())
+	case tofu.ValueFromNamedFile:
+		return source.Range.StartString()
+	case tofu.ValueFromCLIArg:
+		return fmt.Sprintf("-var=\"%s=...\"", name)
+	case tofu.ValueFromEnvVar:
+		return fmt.Sprintf("the TF_VAR_%s environment variable", name)
+	case tofu.ValueFromInput:
+		return "an interactive prompt"
+	default:
+		return "outside of the configuration"
+	}
+}
+
+// The VarsShowJSON implementation renders the variables as a JSON object.
+type VarsShowJSON struct {
+	view *View
+}
+
+var _ VarsShow = (*VarsShowJSON)(nil)
+
+func (v *VarsShowJSON) Variables(vars []*VariableValue) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	type SourceMeta struct {
+		Type     string `json:"type"`
+		Filename string `json:"filename,omitempty"`
+		Line     int    `json:"line,omitempty"`
+	}
+	type VariableMeta struct {
+		Sensitive bool            `json:"sensitive"`
+		Type      json.RawMessage `json:"type,omitempty"`
+		Value     json.RawMessage `json:"value,omitempty"`
+		Sources   []SourceMeta    `json:"sources"`
+	}
+	variableMetas := map[string]VariableMeta{}
+
+	for _, vv := range vars {
+		meta := VariableMeta{
+			Sensitive: vv.Sensitive,
+			Sources:   []SourceMeta{},
+		}
+		if vv.Value != cty.NilVal {
+			jsonVal, err := ctyjson.Marshal(vv.Value, vv.Value.Type())
+			if err != nil {
+				diags = diags.Append(err)
+				return diags
+			}
+			jsonType, err := ctyjson.MarshalType(vv.Value.Type())
+			if err != nil {
+				diags = diags.Append(err)
+				return diags
+			}
+			meta.Type = json.RawMessage(jsonType)
+			meta.Value = json.RawMessage(jsonVal)
+		}
+		for _, source := range vv.Sources {
+			meta.Sources = append(meta.Sources, SourceMeta{
+				Type:     variableValueSourceTypeJSON(source.Type),
+				Filename: source.Range.Filename,
+				Line:     source.Range.Start.Line,
+			})
+		}
+		variableMetas[vv.Name] = meta
+	}
+
+	jsonOutputs, err := json.MarshalIndent(variableMetas, "", "  ")
+	if err != nil {
+		diags = diags.Append(err)
+		return diags
+	}
+
+	v.view.streams.Println(string(jsonOutputs))
+
+	return nil
+}
+
+func (v *VarsShowJSON) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+func variableValueSourceTypeJSON(t tofu.ValueSourceType) string {
+	switch t {
+	case tofu.ValueFromConfig:
+		return "default"
+	case tofu.ValueFromAutoFile:
+		return "auto_file"
+	case tofu.ValueFromNamedFile:
+		return "file"
+	case tofu.ValueFromCLIArg:
+		return "cli"
+	case tofu.ValueFromEnvVar:
+		return "env"
+	case tofu.ValueFromInput:
+		return "input"
+	default:
+		return "other"
+	}
+}
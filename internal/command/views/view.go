@@ -10,6 +10,7 @@ import (
 	"github.com/mitchellh/colorstring"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/diffrenderer"
 	"github.com/opentofu/opentofu/internal/terminal"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -39,6 +40,14 @@ type View struct {
 	// showSensitive is used to display the value of variables marked as sensitive.
 	showSensitive bool
 
+	// compactChanges is used to group identical changes to the instances of
+	// a resource when rendering a plan.
+	compactChanges bool
+
+	// diffRenderers are the diff renderers that show the planned changes to
+	// resources of particular types, keyed by the resource type.
+	diffRenderers map[string]diffrenderer.Renderer
+
 	// This unfortunate wart is required to enable rendering of diagnostics which
 	// have associated source code in the configuration. This function pointer
 	// will be dereferenced as late as possible when rendering diagnostics in
@@ -72,6 +81,17 @@ func (v *View) SetRunningInAutomation(new bool) *View {
 	return v
 }
 
+// SetDiffRenderers sets the diff renderers that show the planned changes to
+// resources of particular types when rendering a plan, keyed by the resource
+// type.
+//
+// For convenient use during initialization (in conjunction with NewView),
+// SetDiffRenderers returns the receiver after modifying it.
+func (v *View) SetDiffRenderers(renderers map[string]diffrenderer.Renderer) *View {
+	v.diffRenderers = renderers
+	return v
+}
+
 func (v *View) RunningInAutomation() bool {
 	return v.runningInAutomation
 }
@@ -187,3 +207,7 @@ func (v *View) outputHorizRule() {
 func (v *View) SetShowSensitive(showSensitive bool) {
 	v.showSensitive = showSensitive
 }
+
+func (v *View) SetCompactChanges(compactChanges bool) {
+	v.compactChanges = compactChanges
+}
@@ -33,7 +33,7 @@ func (c *WorkspaceCommand) Help() string {
 	helpText := `
 Usage: tofu [global options] workspace
 
-  new, list, show, select and delete OpenTofu workspaces.
+  new, list, show, select, update and delete OpenTofu workspaces.
 
 `
 	return strings.TrimSpace(helpText)
@@ -87,6 +87,8 @@ for this configuration.
 
 	envDeleted = `[reset][green]Deleted workspace %q!`
 
+	envUpdated = `[reset][green]Updated the metadata of workspace %q.`
+
 	envWarnNotEmpty = `[reset][yellow]WARNING: %q was non-empty.
 The resources managed by the deleted workspace may still exist,
 but are no longer manageable by OpenTofu since the state has
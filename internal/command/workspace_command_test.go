@@ -6,11 +6,14 @@
 package command
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
@@ -469,3 +472,97 @@ func TestWorkspace_selectWithOrCreate(t *testing.T) {
 	}
 
 }
+
+func TestWorkspace_metadata(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	os.MkdirAll(td, 0755)
+	defer testChdir(t, td)()
+
+	view, _ := testView(t)
+	ui := new(cli.MockUi)
+	newCmd := &WorkspaceNewCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := newCmd.Run([]string{"-description=Production", "-tag=b", "-tag=a", "-tag=b", "prod"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+
+	ui = new(cli.MockUi)
+	showCmd := &WorkspaceShowCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := showCmd.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	var shown workspaceJSON
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &shown); err != nil {
+		t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if _, err := time.Parse(time.RFC3339, shown.CreatedAt); err != nil {
+		t.Fatalf("invalid created_at: %s", err)
+	}
+	shown.CreatedAt = ""
+	want := workspaceJSON{
+		Name:        "prod",
+		Current:     true,
+		Description: "Production",
+		Tags:        []string{"a", "b"},
+	}
+	if diff := cmp.Diff(want, shown); diff != "" {
+		t.Fatalf("wrong workspace\n%s", diff)
+	}
+
+	// Only the given metadata changes
+	ui = new(cli.MockUi)
+	updateCmd := &WorkspaceUpdateCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := updateCmd.Run([]string{"-tag=c", "prod"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+
+	ui = new(cli.MockUi)
+	listCmd := &WorkspaceListCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := listCmd.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	var listed workspaceListJSON
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &listed); err != nil {
+		t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	for i := range listed.Workspaces {
+		listed.Workspaces[i].CreatedAt = ""
+	}
+	wantList := workspaceListJSON{
+		FormatVersion:    workspaceJSONFormatVersion,
+		CurrentWorkspace: "prod",
+		Workspaces: []workspaceJSON{
+			{Name: "default", Tags: []string{}},
+			{Name: "prod", Current: true, Description: "Production", Tags: []string{"c"}},
+		},
+	}
+	if diff := cmp.Diff(wantList, listed); diff != "" {
+		t.Fatalf("wrong workspaces\n%s", diff)
+	}
+}
+
+func TestWorkspace_updateNoOptions(t *testing.T) {
+	td := t.TempDir()
+	os.MkdirAll(td, 0755)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	updateCmd := &WorkspaceUpdateCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := updateCmd.Run(nil); code != cli.RunResultHelp {
+		t.Fatalf("wrong exit code %d; want %d", code, cli.RunResultHelp)
+	}
+	if got, want := ui.ErrorWriter.String(), "At least one of the -description and -tag options"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -74,7 +74,8 @@ func (c *WorkspaceDeleteCommand) Run(args []string) int {
 
 	// Load the backend
 	b, backendDiags := c.Backend(&BackendOpts{
-		Config: backendConfig,
+		Config:           backendConfig,
+		WorkspaceCommand: true,
 	}, enc.State())
 	diags = diags.Append(backendDiags)
 	if backendDiags.HasErrors() {
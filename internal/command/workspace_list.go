@@ -7,11 +7,13 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/posener/complete"
 
+	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -24,8 +26,10 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	envCommandShowWarning(c.Ui, c.LegacyName)
 
+	var jsonOutput bool
 	cmdFlags := c.Meta.defaultFlagSet("workspace list")
 	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -57,7 +61,8 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 
 	// Load the backend
 	b, backendDiags := c.Backend(&BackendOpts{
-		Config: backendConfig,
+		Config:           backendConfig,
+		WorkspaceCommand: true,
 	}, enc.State())
 	diags = diags.Append(backendDiags)
 	if backendDiags.HasErrors() {
@@ -76,6 +81,10 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 
 	env, isOverridden := c.WorkspaceOverridden()
 
+	if jsonOutput {
+		return c.outputJSON(b, states, env)
+	}
+
 	var out bytes.Buffer
 	for _, s := range states {
 		if s == env {
@@ -95,12 +104,49 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 	return 0
 }
 
+// outputJSON prints the given workspaces and their metadata in JSON format.
+func (c *WorkspaceListCommand) outputJSON(b backend.Backend, workspaces []string, current string) int {
+	var diags tfdiags.Diagnostics
+
+	ret := workspaceListJSON{
+		FormatVersion:    workspaceJSONFormatVersion,
+		CurrentWorkspace: current,
+		Workspaces:       make([]workspaceJSON, 0, len(workspaces)),
+	}
+	for _, name := range workspaces {
+		meta, moreDiags := workspaceMetadata(b, name)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		ret.Workspaces = append(ret.Workspaces, newWorkspaceJSON(name, name == current, meta))
+	}
+
+	out, err := json.MarshalIndent(ret, "", "  ")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal workspaces to JSON: %s", err))
+		return 1
+	}
+	c.Ui.Output(string(out))
+	return 0
+}
+
+// workspaceListJSON is the JSON output of "tofu workspace list -json".
+type workspaceListJSON struct {
+	FormatVersion    string          `json:"format_version"`
+	CurrentWorkspace string          `json:"current_workspace"`
+	Workspaces       []workspaceJSON `json:"workspaces"`
+}
+
 func (c *WorkspaceListCommand) AutocompleteArgs() complete.Predictor {
 	return complete.PredictDirs("")
 }
 
 func (c *WorkspaceListCommand) AutocompleteFlags() complete.Flags {
-	return nil
+	return complete.Flags{
+		"-json": complete.PredictNothing,
+	}
 }
 
 func (c *WorkspaceListCommand) Help() string {
@@ -111,6 +157,9 @@ Usage: tofu [global options] workspace list [options]
 
 Options:
 
+  -json              List the workspaces and their metadata, such as their
+                     descriptions and tags, in JSON format.
+
   -var 'foo=bar'     Set a value for one of the input variables in the root
                      module of the configuration. Use this option more than
                      once to set more than one variable.
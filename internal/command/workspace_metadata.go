@@ -0,0 +1,113 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// workspaceJSONFormatVersion is the version of the JSON output of the
+// "tofu workspace list" and "tofu workspace show" commands.
+const workspaceJSONFormatVersion = "1.0"
+
+// workspaceJSON is the JSON representation of a workspace and its metadata.
+type workspaceJSON struct {
+	Name          string   `json:"name"`
+	Current       bool     `json:"current"`
+	Description   string   `json:"description,omitempty"`
+	Tags          []string `json:"tags"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	LastAppliedAt string   `json:"last_applied_at,omitempty"`
+}
+
+func newWorkspaceJSON(name string, current bool, meta *states.WorkspaceMetadata) workspaceJSON {
+	ret := workspaceJSON{
+		Name:    name,
+		Current: current,
+		Tags:    []string{},
+	}
+	if meta == nil {
+		return ret
+	}
+	ret.Description = meta.Description
+	if len(meta.Tags) != 0 {
+		ret.Tags = meta.Tags
+	}
+	if !meta.CreatedAt.IsZero() {
+		ret.CreatedAt = meta.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if !meta.LastAppliedAt.IsZero() {
+		ret.LastAppliedAt = meta.LastAppliedAt.UTC().Format(time.RFC3339)
+	}
+	return ret
+}
+
+// workspaceMetadata reads the metadata of the given workspace from its latest
+// state snapshot, returning nil if it has none.
+func workspaceMetadata(b backend.Backend, workspace string) (*states.WorkspaceMetadata, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	stateMgr, err := b.StateMgr(workspace)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to load workspace state",
+			fmt.Sprintf("OpenTofu could not load the state of workspace %q: %s.", workspace, err),
+		))
+		return nil, diags
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to load workspace state",
+			fmt.Sprintf("OpenTofu could not load the state of workspace %q: %s.", workspace, err),
+		))
+		return nil, diags
+	}
+	state := stateMgr.State()
+	if state == nil {
+		return nil, diags
+	}
+	return state.Workspace, diags
+}
+
+// loadWorkspaceBackend loads the backend for the configuration in the given
+// directory, for the workspace commands that need to read the workspaces'
+// states.
+func (m *Meta) loadWorkspaceBackend(configPath string) (backend.Enhanced, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	backendConfig, backendDiags := m.loadBackendConfig(configPath)
+	diags = diags.Append(backendDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := m.EncryptionFromPath(configPath)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		return nil, diags
+	}
+
+	b, backendDiags := m.Backend(&BackendOpts{
+		Config:           backendConfig,
+		WorkspaceCommand: true,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		return nil, diags
+	}
+
+	// These commands only read or write workspace metadata
+	m.ignoreRemoteVersionConflict(b)
+	return b, diags
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/clistate"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -34,11 +35,15 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 	var stateLock bool
 	var stateLockTimeout time.Duration
 	var statePath string
+	var description string
+	var tags FlagStringSlice
 	cmdFlags := c.Meta.defaultFlagSet("workspace new")
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.BoolVar(&stateLock, "lock", true, "lock state")
 	cmdFlags.DurationVar(&stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.StringVar(&statePath, "state", "", "tofu state file")
+	cmdFlags.StringVar(&description, "description", "", "workspace description")
+	cmdFlags.Var(&tags, "tag", "workspace tag")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -90,7 +95,8 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 
 	// Load the backend
 	b, backendDiags := c.Backend(&BackendOpts{
-		Config: backendConfig,
+		Config:           backendConfig,
+		WorkspaceCommand: true,
 	}, enc.State())
 	diags = diags.Append(backendDiags)
 	if backendDiags.HasErrors() {
@@ -98,7 +104,8 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 		return 1
 	}
 
-	// This command will not write state
+	// This command only writes the workspace metadata, and any state given
+	// with -state as-is.
 	c.ignoreRemoteVersionConflict(b)
 
 	workspaces, err := b.Workspaces()
@@ -128,11 +135,6 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
 		strings.TrimSpace(envCreated), workspace)))
 
-	if statePath == "" {
-		// if we're not loading a state, then we're done
-		return 0
-	}
-
 	// load the new Backend state
 	stateMgr, err := b.StateMgr(workspace)
 	if err != nil {
@@ -153,21 +155,39 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 		}()
 	}
 
-	// read the existing state file
-	f, err := os.Open(statePath)
-	if err != nil {
-		c.Ui.Error(err.Error())
-		return 1
+	state := states.NewState()
+	if statePath != "" {
+		// read the existing state file
+		f, err := os.Open(statePath)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		stateFile, err := statefile.Read(f, encryption.StateEncryptionDisabled()) // Assume given statefile is not encrypted
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		state = stateFile.State
 	}
 
-	stateFile, err := statefile.Read(f, encryption.StateEncryptionDisabled()) // Assume given statefile is not encrypted
-	if err != nil {
-		c.Ui.Error(err.Error())
-		return 1
+	// The workspace metadata is stored in its state, so we save a state even
+	// if we're not loading one.
+	var tagValues []string
+	for _, tag := range tags {
+		if tag != "" {
+			tagValues = append(tagValues, tag)
+		}
+	}
+	state.Workspace = &states.WorkspaceMetadata{
+		Description: description,
+		Tags:        states.NormalizeTags(tagValues),
+		CreatedAt:   time.Now().UTC(),
 	}
 
-	// save the existing state in the new Backend.
-	err = stateMgr.WriteState(stateFile.State)
+	// save the state in the new Backend.
+	err = stateMgr.WriteState(state)
 	if err != nil {
 		c.Ui.Error(err.Error())
 		return 1
@@ -190,7 +210,9 @@ func (c *WorkspaceNewCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *WorkspaceNewCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"-state": complete.PredictFiles("*.tfstate"),
+		"-state":       complete.PredictFiles("*.tfstate"),
+		"-description": complete.PredictAnything,
+		"-tag":         complete.PredictAnything,
 	}
 }
 
@@ -202,6 +224,13 @@ Usage: tofu [global options] workspace new [OPTIONS] NAME
 
 Options:
 
+    -description=text   A description of the purpose of the workspace, which
+                        is shown by "tofu workspace show -json".
+
+    -tag=tag            A tag for the workspace, for tooling that manages
+                        many workspaces. Use this option more than once to
+                        add more than one tag.
+
     -lock=false         Don't hold a state lock during the operation. This is
                         dangerous if others might concurrently run commands
                         against the same workspace.
@@ -210,7 +239,6 @@ Options:
 
     -state=path         Copy an existing state file into the new workspace.
 
-
     -var 'foo=bar'      Set a value for one of the input variables in the root
                         module of the configuration. Use this option more than
                         once to set more than one variable.
@@ -71,7 +71,8 @@ func (c *WorkspaceSelectCommand) Run(args []string) int {
 
 	// Load the backend
 	b, backendDiags := c.Backend(&BackendOpts{
-		Config: backendConfig,
+		Config:           backendConfig,
+		WorkspaceCommand: true,
 	}, enc.State())
 	diags = diags.Append(backendDiags)
 	if backendDiags.HasErrors() {
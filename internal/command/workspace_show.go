@@ -6,6 +6,7 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -18,7 +19,9 @@ type WorkspaceShowCommand struct {
 
 func (c *WorkspaceShowCommand) Run(args []string) int {
 	args = c.Meta.process(args)
+	var jsonOutput bool
 	cmdFlags := c.Meta.extendedFlagSet("workspace show")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -30,8 +33,34 @@ func (c *WorkspaceShowCommand) Run(args []string) int {
 		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
 		return 1
 	}
-	c.Ui.Output(workspace)
+	if !jsonOutput {
+		c.Ui.Output(workspace)
+		return 0
+	}
+
+	// The metadata is stored in the workspace's state, so we need the
+	// backend to read it.
+	b, diags := c.loadWorkspaceBackend(".")
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	meta, moreDiags := workspaceMetadata(b, workspace)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
 
+	out, err := json.MarshalIndent(struct {
+		FormatVersion string `json:"format_version"`
+		workspaceJSON
+	}{workspaceJSONFormatVersion, newWorkspaceJSON(workspace, true, meta)}, "", "  ")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal workspace to JSON: %s", err))
+		return 1
+	}
+	c.Ui.Output(string(out))
 	return 0
 }
 
@@ -40,14 +69,21 @@ func (c *WorkspaceShowCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *WorkspaceShowCommand) AutocompleteFlags() complete.Flags {
-	return nil
+	return complete.Flags{
+		"-json": complete.PredictNothing,
+	}
 }
 
 func (c *WorkspaceShowCommand) Help() string {
 	helpText := `
-Usage: tofu [global options] workspace show
+Usage: tofu [global options] workspace show [options]
 
   Show the name of the current workspace.
+
+Options:
+
+  -json    Show the current workspace and its metadata, such as its
+           description and tags, in JSON format.
 `
 	return strings.TrimSpace(helpText)
 }
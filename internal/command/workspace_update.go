@@ -0,0 +1,212 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/clistate"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// WorkspaceUpdateCommand is a Command implementation that changes the
+// description and tags of a workspace.
+type WorkspaceUpdateCommand struct {
+	Meta
+}
+
+func (c *WorkspaceUpdateCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+
+	var stateLock bool
+	var stateLockTimeout time.Duration
+	var description string
+	var tags FlagStringSlice
+	cmdFlags := c.Meta.defaultFlagSet("workspace update")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.BoolVar(&stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&description, "description", "", "workspace description")
+	cmdFlags.Var(&tags, "tag", "workspace tag")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	// Only the metadata given on the command line changes, so we need to
+	// know which options were set, including to empty values.
+	var setDescription, setTags bool
+	cmdFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "description":
+			setDescription = true
+		case "tag":
+			setTags = true
+		}
+	})
+
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("Expected at most one argument: NAME.\n")
+		return cli.RunResultHelp
+	}
+	if !setDescription && !setTags {
+		c.Ui.Error("At least one of the -description and -tag options must be given.\n")
+		return cli.RunResultHelp
+	}
+
+	workspace, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+	if len(args) == 1 {
+		workspace = args[0]
+	}
+
+	b, diags := c.loadWorkspaceBackend(".")
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	workspaces, err := b.Workspaces()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	exists := false
+	for _, ws := range workspaces {
+		if workspace == ws {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		c.Ui.Error(fmt.Sprintf(strings.TrimSpace(envDoesNotExist), workspace))
+		return 1
+	}
+
+	stateMgr, err := b.StateMgr(workspace)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "workspace-update"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	state := states.NewState()
+	if s := stateMgr.State(); s != nil {
+		state = s.DeepCopy()
+	}
+	if state.Workspace == nil {
+		// The workspace was created before workspaces had metadata, so we
+		// don't know when.
+		state.Workspace = &states.WorkspaceMetadata{}
+	}
+	if setDescription {
+		state.Workspace.Description = description
+	}
+	if setTags {
+		// An empty -tag option clears the tags.
+		var tagValues []string
+		for _, tag := range tags {
+			if tag != "" {
+				tagValues = append(tagValues, tag)
+			}
+		}
+		state.Workspace.Tags = states.NormalizeTags(tagValues)
+	}
+
+	if err := stateMgr.WriteState(state); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	if err := stateMgr.PersistState(nil); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(envUpdated, workspace)))
+	return 0
+}
+
+func (c *WorkspaceUpdateCommand) AutocompleteArgs() complete.Predictor {
+	return completePredictSequence{
+		c.completePredictWorkspaceName(),
+	}
+}
+
+func (c *WorkspaceUpdateCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-description": complete.PredictAnything,
+		"-tag":         complete.PredictAnything,
+	}
+}
+
+func (c *WorkspaceUpdateCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] workspace update [OPTIONS] [NAME]
+
+  Change the description or tags of an OpenTofu workspace, which defaults to
+  the current workspace. The metadata that isn't given in the options is
+  left unchanged.
+
+Options:
+
+    -description=text   A new description of the purpose of the workspace.
+
+    -tag=tag            A tag for the workspace. Use this option more than
+                        once to give more than one tag. The given tags
+                        replace all the existing tags of the workspace, and
+                        -tag="" removes them.
+
+    -lock=false         Don't hold a state lock during the operation. This is
+                        dangerous if others might concurrently run commands
+                        against the same workspace.
+
+    -lock-timeout=0s    Duration to retry a state lock.
+
+    -var 'foo=bar'      Set a value for one of the input variables in the root
+                        module of the configuration. Use this option more than
+                        once to set more than one variable.
+
+    -var-file=filename  Load variable values from the given file, in addition
+                        to the default files terraform.tfvars and *.auto.tfvars.
+                        Use this option more than once to include more than one
+                        variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *WorkspaceUpdateCommand) Synopsis() string {
+	return "Update the description and tags of a workspace"
+}
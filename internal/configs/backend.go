@@ -7,8 +7,10 @@ package configs
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
@@ -24,17 +26,54 @@ type Backend struct {
 	Config hcl.Body
 	Eval   *StaticEvaluator
 
+	// Namespace is the state namespace set by the "state_namespace"
+	// argument, which OpenTofu handles itself rather than passing it to the
+	// backend with the rest of Config. It's an empty string if the argument
+	// isn't set.
+	Namespace      string
+	NamespaceRange hcl.Range
+
 	TypeRange hcl.Range
 	DeclRange hcl.Range
 }
 
+// validStateNamespace matches the valid values of the "state_namespace"
+// argument. The namespaces can't contain periods, which separate the
+// namespace from the workspace name in the workspaces that OpenTofu stores in
+// the backend.
+var validStateNamespace = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var backendBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "state_namespace"},
+	},
+}
+
 func decodeBackendBlock(block *hcl.Block) (*Backend, hcl.Diagnostics) {
-	return &Backend{
+	content, remain, diags := block.Body.PartialContent(backendBlockSchema)
+
+	b := &Backend{
 		Type:      block.Labels[0],
 		TypeRange: block.LabelRanges[0],
-		Config:    block.Body,
+		Config:    remain,
 		DeclRange: block.DefRange,
-	}, nil
+	}
+
+	if attr, ok := content.Attributes["state_namespace"]; ok {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &b.Namespace)
+		diags = append(diags, valDiags...)
+		b.NamespaceRange = attr.Expr.Range()
+		if !valDiags.HasErrors() && !validStateNamespace.MatchString(b.Namespace) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid state namespace",
+				Detail:   "A state namespace must contain only letters, digits, underscores, and dashes.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	return b, diags
 }
 
 // Hash produces a hash value for the receiver that covers the type and the
@@ -72,10 +111,16 @@ func (b *Backend) Hash(schema *configschema.Block) (int, hcl.Diagnostics) {
 		})
 	}
 
-	toHash := cty.TupleVal([]cty.Value{
+	hashVals := []cty.Value{
 		cty.StringVal(b.Type),
 		val,
-	})
+	}
+	if b.Namespace != "" {
+		// The namespace is only included when it's set, so that adding the
+		// argument doesn't change the hashes of existing configurations.
+		hashVals = append(hashVals, cty.StringVal(b.Namespace))
+	}
+	toHash := cty.TupleVal(hashVals)
 
 	return toHash.Hash(), diags
 }
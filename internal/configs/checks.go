@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -147,6 +148,36 @@ var checkRuleBlockSchema = &hcl.BodySchema{
 	},
 }
 
+// CheckSeverity describes how the failures of the assertions in a check
+// block are reported.
+type CheckSeverity string
+
+const (
+	// CheckSeverityWarning reports failed assertions as warnings, which is
+	// the default.
+	CheckSeverityWarning CheckSeverity = "warning"
+
+	// CheckSeverityError reports failed assertions as errors, which makes the
+	// operation fail.
+	CheckSeverityError CheckSeverity = "error"
+)
+
+// CheckPhase is an operation that a check block can be restricted to.
+type CheckPhase string
+
+const (
+	// CheckPhasePlan is the plan operation, including the plan that "tofu
+	// apply" creates when it isn't given a saved plan.
+	CheckPhasePlan CheckPhase = "plan"
+
+	// CheckPhaseApply is the apply operation.
+	CheckPhaseApply CheckPhase = "apply"
+
+	// CheckPhaseCheck is the "tofu check" command, which only evaluates check
+	// blocks.
+	CheckPhaseCheck CheckPhase = "check"
+)
+
 // Check represents a configuration defined check block.
 //
 // A check block contains 0-1 data blocks, and 0-n assert blocks. The check
@@ -158,6 +189,14 @@ type Check struct {
 	DataResource *Resource
 	Asserts      []*CheckRule
 
+	// Severity is the severity of the diagnostics reported for failed
+	// assertions and for errors reading DataResource.
+	Severity CheckSeverity
+
+	// Phases are the operations that the check block runs in. If it's empty
+	// the check block runs in all of them.
+	Phases []CheckPhase
+
 	DeclRange hcl.Range
 }
 
@@ -167,6 +206,21 @@ func (c Check) Addr() addrs.Check {
 	}
 }
 
+// RunsIn returns true if the check block should run in the given phase.
+//
+// An empty phase means the operation doesn't restrict which check blocks run.
+func (c Check) RunsIn(phase CheckPhase) bool {
+	if len(c.Phases) == 0 || phase == "" {
+		return true
+	}
+	for _, p := range c.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
 func (c Check) Accessible(addr addrs.Referenceable) bool {
 	if check, ok := addr.(addrs.Check); ok {
 		return check.Equal(c.Addr())
@@ -179,6 +233,7 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 
 	check := &Check{
 		Name:      block.Labels[0],
+		Severity:  CheckSeverityWarning,
 		DeclRange: block.DefRange,
 	}
 
@@ -208,6 +263,54 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 		})
 	}
 
+	if attr, exists := content.Attributes["severity"]; exists {
+		var severity string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &severity)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			switch CheckSeverity(severity) {
+			case CheckSeverityWarning, CheckSeverityError:
+				check.Severity = CheckSeverity(severity)
+			default:
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid check severity",
+					Detail:   `The "severity" argument must be either "warning" or "error".`,
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+
+	if attr, exists := content.Attributes["phases"]; exists {
+		var phases []string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &phases)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			if len(phases) == 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid check phases",
+					Detail:   `The "phases" argument must list at least one of "plan", "apply" and "check". Remove the argument to run the check block in all of them.`,
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+			for _, phase := range phases {
+				switch CheckPhase(phase) {
+				case CheckPhasePlan, CheckPhaseApply, CheckPhaseCheck:
+					check.Phases = append(check.Phases, CheckPhase(phase))
+				default:
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid check phases",
+						Detail:   fmt.Sprintf(`The phase %q is not valid. The "phases" argument may only contain "plan", "apply" and "check".`, phase),
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				}
+			}
+		}
+	}
+
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case "data":
@@ -255,6 +358,10 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 }
 
 var checkBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "severity"},
+		{Name: "phases"},
+	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "data", LabelNames: []string{"type", "name"}},
 		{Type: "assert"},
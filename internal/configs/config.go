@@ -431,6 +431,14 @@ func (c *Config) addProviderRequirements(reqs getproviders.Requirements, recurse
 		}
 		reqs[fqn] = nil
 	}
+	for _, rc := range c.Module.EphemeralResources {
+		fqn := rc.Provider
+		if _, exists := reqs[fqn]; exists {
+			// Explicit dependency already present
+			continue
+		}
+		reqs[fqn] = nil
+	}
 
 	// Import blocks that are generating config may also have a custom provider
 	// meta argument. Like the provider meta argument used in resource blocks,
@@ -499,6 +507,21 @@ func (c *Config) addProviderRequirements(reqs getproviders.Requirements, recurse
 					})
 					continue
 				}
+
+				if !providerKeyExprsEqual(i.ProviderConfigRef.KeyExpression, target.ProviderConfigRef.KeyExpression) {
+					// The same applies to the provider instance keys, but
+					// we can only compare keys whose values are known
+					// during decoding. Dynamic keys must be written only in
+					// the resource block, so that there's only one source
+					// of truth for which instance to use.
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid import provider argument",
+						Detail:   "The provider instance key in the target resource block must match the import block. If the key isn't a constant value, specify it only in the resource block.",
+						Subject:  i.ProviderDeclRange.Ptr(),
+					})
+					continue
+				}
 			}
 		}
 	}
@@ -72,9 +72,9 @@ func buildTestModules(root *Config, walker ModuleWalker) hcl.Diagnostics {
 			if dir != "." {
 				path = append(path, strings.Split(dir, "/")...)
 			}
-			path = append(path, strings.TrimSuffix(base, ".tftest.hcl"), run.Name)
+			path = append(path, strings.TrimSuffix(base, ".tftest.hcl"), run.BlockName())
 			req := ModuleRequest{
-				Name:              run.Name,
+				Name:              run.BlockName(),
 				Path:              path,
 				SourceAddr:        run.Module.Source,
 				SourceAddrRange:   run.Module.SourceDeclRange,
@@ -141,7 +141,7 @@ func buildChildModules(parent *Config, walker ModuleWalker) (map[string]*Config,
 			VersionConstraint: call.Version,
 			Parent:            parent,
 			CallRange:         call.DeclRange,
-			Call:              NewStaticModuleCall(path, call.Variables, parent.Root.Module.SourceDir, call.Workspace),
+			Call:              NewStaticModuleCall(path, call.Variables, parent.Root.Module.SourceDir, call.Workspace).WithProviderFunctions(call.ProviderFunctions),
 		}
 		if call.Source != nil {
 			// Invalid modules sometimes have a nil source field which is handled through loadModule below
@@ -196,6 +196,15 @@ func loadModule(root *Config, req *ModuleRequest, walker ModuleWalker) (*Config,
 		})
 	}
 
+	for _, reqs := range mod.RequiredTags {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Required tags ignored",
+			Detail:   "Tag requirements apply to the resources of the entire configuration, so OpenTofu only enforces the required_tags blocks of the root module.\n\nThis is a warning rather than an error because it's sometimes convenient to temporarily call a root module as a child module for testing purposes, but this required_tags block will have no effect.",
+			Subject:  reqs.DeclRange.Ptr(),
+		})
+	}
+
 	if len(mod.Import) > 0 {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -122,6 +122,27 @@ func TestBuildConfigDiags(t *testing.T) {
 	}
 }
 
+func TestBuildConfigChildModuleRequiredTags(t *testing.T) {
+	parser := NewParser(nil)
+	mod, diags := parser.LoadConfigDir("testdata/nested-required-tags-warning", RootModuleCallForTesting())
+	assertNoDiagnostics(t, diags)
+	if mod == nil {
+		t.Fatal("got nil root module; want non-nil")
+	}
+
+	_, diags = BuildConfig(mod, ModuleWalkerFunc(
+		func(req *ModuleRequest) (*Module, *version.Version, hcl.Diagnostics) {
+			sourcePath := filepath.Join("testdata/nested-required-tags-warning", req.SourceAddr.String())
+
+			mod, modDiags := parser.LoadConfigDir(sourcePath, req.Call)
+			version, _ := version.NewVersion("1.0.0")
+			return mod, version, modDiags
+		},
+	))
+
+	assertDiagnosticSummary(t, diags, "Required tags ignored")
+}
+
 func TestBuildConfigChildModuleBackend(t *testing.T) {
 	parser := NewParser(nil)
 	mod, diags := parser.LoadConfigDir("testdata/nested-backend-warning", RootModuleCallForTesting())
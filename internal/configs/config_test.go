@@ -626,6 +626,23 @@ func TestConfigImportProviderWithNoResourceProvider(t *testing.T) {
 	})
 }
 
+func TestConfigImportProviderKeyClashesWithResources(t *testing.T) {
+	cfg, diags := testModuleConfigFromFile("testdata/invalid-import-files/import-and-resource-key-clash.tf")
+	assertNoDiagnostics(t, diags)
+
+	diags = cfg.addProviderRequirements(getproviders.Requirements{}, true, false)
+	assertExactDiagnostics(t, diags, []string{
+		`testdata/invalid-import-files/import-and-resource-key-clash.tf:8,3-34: Invalid import provider argument; The provider instance key in the target resource block must match the import block. If the key isn't a constant value, specify it only in the resource block.`,
+	})
+}
+
+func TestConfigImportProviderMissingKey(t *testing.T) {
+	_, diags := testModuleConfigFromFile("testdata/invalid-import-files/import-provider-missing-key.tf")
+	assertExactDiagnostics(t, diags, []string{
+		`testdata/invalid-import-files/import-provider-missing-key.tf:8,14-19: Invalid import provider configuration; A reference to a provider configuration which uses for_each requires an instance key. Passing a collection of provider instances into a child module is not allowed.`,
+	})
+}
+
 func TestTransformForTest(t *testing.T) {
 
 	str := func(providers map[string]string) string {
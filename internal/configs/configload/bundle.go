@@ -0,0 +1,128 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+// BundleFormatVersion is the version of the configuration bundle format
+// understood by ReadBundle. Bundles with a different major version are
+// rejected.
+const BundleFormatVersion = "1.0"
+
+// bundle is the JSON representation of a configuration bundle, which is a
+// self-contained serialization of a root module and all of the modules it
+// calls, typically generated by external tooling.
+type bundle struct {
+	FormatVersion string                   `json:"format_version"`
+	Modules       map[string]*bundleModule `json:"modules"`
+}
+
+type bundleModule struct {
+	// Dir is the virtual directory of the module, which appears in
+	// diagnostics. It defaults to the module key, and is ignored for the root
+	// module, which always appears as the current directory.
+	Dir string `json:"dir"`
+
+	// Source and Version must match the source address and the version
+	// constraint of the module call, as for an installed module.
+	Source  string `json:"source"`
+	Version string `json:"version"`
+
+	// Files maps the name of each configuration file to its source code.
+	Files map[string]string `json:"files"`
+}
+
+// ReadBundle decodes a configuration bundle from the given reader and returns
+// it as a Snapshot, suitable for NewLoaderFromSnapshot.
+//
+// The modules of a bundle are identified by the same keys as in the module
+// manifest created by "tofu init": the empty string for the root module, and
+// the dot-separated names of the module calls for the others.
+func ReadBundle(r io.Reader) (*Snapshot, error) {
+	var b bundle
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid configuration bundle: %w", err)
+	}
+
+	if b.FormatVersion == "" {
+		return nil, fmt.Errorf("configuration bundle has no format_version")
+	}
+	v, err := version.NewVersion(b.FormatVersion)
+	if err != nil {
+		return nil, fmt.Errorf("configuration bundle has invalid format_version %q", b.FormatVersion)
+	}
+	supported := version.Must(version.NewVersion(BundleFormatVersion))
+	if v.Segments()[0] != supported.Segments()[0] {
+		return nil, fmt.Errorf("unsupported configuration bundle format_version %q; this version of OpenTofu supports %s", b.FormatVersion, BundleFormatVersion)
+	}
+
+	if _, exists := b.Modules[""]; !exists {
+		return nil, fmt.Errorf("configuration bundle has no root module")
+	}
+
+	snap := &Snapshot{
+		Modules: make(map[string]*SnapshotModule, len(b.Modules)),
+	}
+	dirs := make(map[string]string, len(b.Modules))
+	for key, mod := range b.Modules {
+		if mod == nil {
+			return nil, fmt.Errorf("configuration bundle module %q is null", key)
+		}
+
+		modSnap := &SnapshotModule{
+			Files:      make(map[string][]byte, len(mod.Files)),
+			SourceAddr: mod.Source,
+		}
+
+		switch {
+		case key == "":
+			modSnap.Dir = "."
+			modSnap.SourceAddr = ""
+		case mod.Source == "":
+			return nil, fmt.Errorf("configuration bundle module %q has no source address", key)
+		case mod.Dir == "":
+			modSnap.Dir = key
+		default:
+			modSnap.Dir = filepath.Clean(filepath.FromSlash(mod.Dir))
+		}
+		if key != "" && (modSnap.Dir == "." || filepath.IsAbs(modSnap.Dir)) {
+			return nil, fmt.Errorf("configuration bundle module %q has invalid directory %q", key, mod.Dir)
+		}
+		if other, exists := dirs[modSnap.Dir]; exists {
+			return nil, fmt.Errorf("configuration bundle modules %q and %q have the same directory %q", other, key, modSnap.Dir)
+		}
+		dirs[modSnap.Dir] = key
+
+		if key != "" && mod.Version != "" {
+			v, err := version.NewVersion(mod.Version)
+			if err != nil {
+				return nil, fmt.Errorf("configuration bundle module %q has invalid version %q", key, mod.Version)
+			}
+			modSnap.Version = v
+		}
+
+		for name, src := range mod.Files {
+			if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+				return nil, fmt.Errorf("configuration bundle module %q has invalid file name %q", key, name)
+			}
+			modSnap.Files[name] = []byte(src)
+		}
+
+		snap.Modules[key] = modSnap
+	}
+
+	return snap, nil
+}
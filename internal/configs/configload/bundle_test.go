@@ -0,0 +1,120 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configload
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/configs"
+)
+
+func TestReadBundle(t *testing.T) {
+	src := `{
+  "format_version": "1.0",
+  "modules": {
+    "": {
+      "files": {
+        "main.tf": "module \"child\" {\n  source  = \"example.com/foo/child/aws\"\n  version = \"~> 1.0\"\n}\n"
+      }
+    },
+    "child": {
+      "source": "example.com/foo/child/aws",
+      "version": "1.2.0",
+      "files": {
+        "main.tf": "module \"grandchild\" {\n  source = \"./grandchild\"\n}\n"
+      }
+    },
+    "child.grandchild": {
+      "dir": "child/grandchild",
+      "source": "./grandchild",
+      "files": {
+        "main.tf": "output \"hello\" {\n  value = \"world\"\n}\n"
+      }
+    }
+  }
+}`
+
+	snap, err := ReadBundle(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	loader := NewLoaderFromSnapshot(snap)
+	cfg, diags := loader.LoadConfig(".", configs.RootModuleCallForTesting())
+	assertNoDiagnostics(t, diags)
+
+	grandchild := cfg.Descendent([]string{"child", "grandchild"})
+	if grandchild == nil {
+		t.Fatalf("grandchild module is not loaded")
+	}
+	if _, exists := grandchild.Module.Outputs["hello"]; !exists {
+		t.Errorf("grandchild module has no output \"hello\"")
+	}
+	if got, want := cfg.Children["child"].Version.String(), "1.2.0"; got != want {
+		t.Errorf("wrong child version %q; want %q", got, want)
+	}
+}
+
+func TestReadBundle_invalid(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"not json": {
+			`module "foo" {}`,
+			"invalid configuration bundle",
+		},
+		"unknown field": {
+			`{"format_version": "1.0", "modules": {"": {}}, "extra": true}`,
+			"unknown field",
+		},
+		"no format version": {
+			`{"modules": {"": {}}}`,
+			"has no format_version",
+		},
+		"unsupported format version": {
+			`{"format_version": "2.0", "modules": {"": {}}}`,
+			`unsupported configuration bundle format_version "2.0"`,
+		},
+		"no root module": {
+			`{"format_version": "1.0", "modules": {"child": {"source": "./child"}}}`,
+			"has no root module",
+		},
+		"no source": {
+			`{"format_version": "1.0", "modules": {"": {}, "child": {}}}`,
+			`module "child" has no source address`,
+		},
+		"invalid version": {
+			`{"format_version": "1.0", "modules": {"": {}, "child": {"source": "example.com/a/b/c", "version": "latest"}}}`,
+			`module "child" has invalid version "latest"`,
+		},
+		"file in subdirectory": {
+			`{"format_version": "1.0", "modules": {"": {"files": {"sub/main.tf": ""}}}}`,
+			`module "" has invalid file name "sub/main.tf"`,
+		},
+		"child in root directory": {
+			`{"format_version": "1.0", "modules": {"": {}, "child": {"source": "./child", "dir": "."}}}`,
+			`module "child" has invalid directory "."`,
+		},
+		"duplicate directory": {
+			`{"format_version": "1.0", "modules": {"": {}, "a": {"source": "./a", "dir": "mod"}, "b": {"source": "./b", "dir": "mod"}}}`,
+			`have the same directory "mod"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadBundle(strings.NewReader(test.src))
+			if err == nil {
+				t.Fatalf("unexpected success")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", err, test.want)
+			}
+		})
+	}
+}
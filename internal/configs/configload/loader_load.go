@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // LoadConfig reads the OpenTofu module in the given directory and uses it as the
@@ -69,6 +70,7 @@ func (l *Loader) moduleWalkerLoad(req *configs.ModuleRequest) (*configs.Module,
 				Summary:  "Module not installed",
 				Detail:   "This module is not yet installed. Run \"tofu init\" to install all modules required by this configuration.",
 				Subject:  &req.CallRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleModuleNotInstalled, nil),
 			},
 		}
 	}
@@ -120,6 +122,7 @@ func (l *Loader) moduleWalkerLoad(req *configs.ModuleRequest) (*configs.Module,
 				Summary:  "Module not installed",
 				Detail:   fmt.Sprintf("This module's local cache directory %s could not be read. Run \"tofu init\" to install all modules required by this configuration.", record.Dir),
 				Subject:  &req.CallRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleModuleNotInstalled, nil),
 			},
 		}
 	}
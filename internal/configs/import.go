@@ -6,6 +6,8 @@
 package configs
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hcljson "github.com/hashicorp/hcl/v2/json"
@@ -47,6 +49,12 @@ type Import struct {
 
 	ForEach hcl.Expression
 
+	// Discover is set for import blocks that ask the provider to list the
+	// existing objects of the resource type, instead of importing one with a
+	// given ID. Such a block doesn't import anything itself: OpenTofu writes
+	// an import block for each object it finds when generating configuration.
+	Discover *ImportDiscover
+
 	ProviderConfigRef *ProviderConfigRef
 	Provider          addrs.Provider
 
@@ -118,17 +126,116 @@ func decodeImportBlock(block *hcl.Block) (*Import, hcl.Diagnostics) {
 		imp.ForEach = attr.Expr
 	}
 
+	for _, block := range content.Blocks {
+		if block.Type != "discover" {
+			continue
+		}
+		if imp.Discover != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate discover block",
+				Detail:   fmt.Sprintf("This import block already has a discover block at %s.", imp.Discover.DeclRange),
+				Subject:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		discover, discoverDiags := decodeImportDiscoverBlock(block)
+		diags = append(diags, discoverDiags...)
+		imp.Discover = discover
+	}
+
+	if imp.Discover != nil {
+		diags = append(diags, validateImportDiscover(imp, content)...)
+	} else if _, exists := content.Attributes["id"]; !exists {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   "The argument \"id\" is required, but no definition was found.",
+			Subject:  block.Body.MissingItemRange().Ptr(),
+		})
+	}
+
 	return imp, diags
 }
 
+// ImportDiscover represents a discover block inside an import block.
+type ImportDiscover struct {
+	// Filter is an optional expression whose value OpenTofu passes to the
+	// provider to narrow down the objects that it lists. Its meaning is
+	// specific to the provider and the resource type.
+	Filter hcl.Expression
+
+	DeclRange hcl.Range
+}
+
+func decodeImportDiscoverBlock(block *hcl.Block) (*ImportDiscover, hcl.Diagnostics) {
+	discover := &ImportDiscover{
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(importDiscoverBlockSchema)
+	if attr, exists := content.Attributes["filter"]; exists {
+		discover.Filter = attr.Expr
+	}
+	return discover, diags
+}
+
+// validateImportDiscover checks the arguments of an import block that has a
+// discover block, which names the resource type and the prefix for the names
+// of the generated resources instead of a single import target.
+func validateImportDiscover(imp *Import, content *hcl.BodyContent) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for _, name := range []string{"id", "for_each"} {
+		if attr, exists := content.Attributes[name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid import discovery",
+				Detail:   fmt.Sprintf("An import block with a discover block imports the objects that the provider lists, so it can't also have a %q argument.", name),
+				Subject:  attr.Range.Ptr(),
+			})
+		}
+	}
+
+	if imp.To != nil && imp.ResolvedTo != nil {
+		if !imp.ResolvedTo.Module.IsRoot() || imp.ResolvedTo.Resource.Key != addrs.NoKey {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid import discovery",
+				Detail:   "The \"to\" argument of an import block with a discover block must be the address of a resource in the root module, without an instance key. OpenTofu generates a resource with this name as a prefix for each object that the provider lists.",
+				Subject:  imp.To.Range().Ptr(),
+			})
+		}
+	} else if imp.To != nil && imp.StaticTo.Resource.Type != "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid import discovery",
+			Detail:   "The \"to\" argument of an import block with a discover block must be a static resource address.",
+			Subject:  imp.To.Range().Ptr(),
+		})
+	}
+
+	if imp.ProviderConfigRef != nil && imp.ProviderConfigRef.KeyExpression != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid import discovery",
+			Detail:   "An import block with a discover block can't refer to an instance of a provider configuration that uses for_each.",
+			Subject:  imp.ProviderDeclRange.Ptr(),
+		})
+	}
+
+	return diags
+}
+
 var importBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{
 			Name: "provider",
 		},
 		{
-			Name:     "id",
-			Required: true,
+			// The id argument is required unless the block has a discover
+			// block, which decodeImportBlock checks.
+			Name: "id",
 		},
 		{
 			Name:     "to",
@@ -138,6 +245,19 @@ var importBlockSchema = &hcl.BodySchema{
 			Name: "for_each",
 		},
 	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "discover",
+		},
+	},
+}
+
+var importDiscoverBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "filter",
+		},
+	},
 }
 
 // absTraversalForImportToExpr returns a static traversal of an import block's "to" field.
@@ -208,3 +328,19 @@ func resolvedImportAddress(expr hcl.Expression) *addrs.AbsResourceInstance {
 	}
 	return &to
 }
+
+// providerKeyExprsEqual returns true if the given provider instance key
+// expressions, either of which may be nil, are known to select the same
+// provider instance. Expressions whose values aren't constant can't be
+// compared, and so are never considered equal.
+func providerKeyExprsEqual(a, b hcl.Expression) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	aVal, aDiags := a.Value(nil)
+	bVal, bDiags := b.Value(nil)
+	if aDiags.HasErrors() || bDiags.HasErrors() {
+		return false
+	}
+	return aVal.RawEquals(bVal)
+}
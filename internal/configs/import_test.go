@@ -198,6 +198,87 @@ func TestImportBlock_decode(t *testing.T) {
 			},
 			``,
 		},
+		"discover": {
+			&hcl.Block{
+				Type: "import",
+				Body: hcltest.MockBody(&hcl.BodyContent{
+					Attributes: hcl.Attributes{
+						"to": {
+							Name: "to",
+							Expr: barExpr,
+						},
+					},
+					Blocks: hcl.Blocks{
+						{
+							Type: "discover",
+							Body: hcltest.MockBody(&hcl.BodyContent{
+								Attributes: hcl.Attributes{
+									"filter": {
+										Name: "filter",
+										Expr: fooStrExpr,
+									},
+								},
+							}),
+							DefRange: blockRange,
+						},
+					},
+				}),
+				DefRange: blockRange,
+			},
+			&Import{
+				To: barExpr,
+				ResolvedTo: &addrs.AbsResourceInstance{
+					Resource: addrs.ResourceInstance{Resource: barResource},
+				},
+				StaticTo: addrs.ConfigResource{
+					Resource: barResource,
+				},
+				Discover: &ImportDiscover{
+					Filter:    fooStrExpr,
+					DeclRange: blockRange,
+				},
+				DeclRange: blockRange,
+			},
+			``,
+		},
+		"error: discover with an indexed resource": {
+			&hcl.Block{
+				Type: "import",
+				Body: hcltest.MockBody(&hcl.BodyContent{
+					Attributes: hcl.Attributes{
+						"to": {
+							Name: "to",
+							Expr: barIndexExpr,
+						},
+					},
+					Blocks: hcl.Blocks{
+						{
+							Type:     "discover",
+							Body:     hcltest.MockBody(&hcl.BodyContent{}),
+							DefRange: blockRange,
+						},
+					},
+				}),
+				DefRange: blockRange,
+			},
+			&Import{
+				To: barIndexExpr,
+				ResolvedTo: &addrs.AbsResourceInstance{
+					Resource: addrs.ResourceInstance{
+						Resource: barResource,
+						Key:      addrs.StringKey("one"),
+					},
+				},
+				StaticTo: addrs.ConfigResource{
+					Resource: barResource,
+				},
+				Discover: &ImportDiscover{
+					DeclRange: blockRange,
+				},
+				DeclRange: blockRange,
+			},
+			"Invalid import discovery",
+		},
 		"error: missing id argument": {
 			&hcl.Block{
 				Type: "import",
@@ -13,6 +13,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/encryption/config"
 	"github.com/opentofu/opentofu/internal/experiments"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	tfversion "github.com/opentofu/opentofu/version"
 )
 
@@ -43,14 +44,19 @@ type Module struct {
 	ProviderMetas        map[addrs.Provider]*ProviderMeta
 	Encryption           *config.EncryptionConfig
 
+	// RequiredTags lists the tag requirements declared in the module's
+	// terraform blocks. They are only enforced for the root module.
+	RequiredTags []*RequiredTags
+
 	Variables map[string]*Variable
 	Locals    map[string]*Local
 	Outputs   map[string]*Output
 
 	ModuleCalls map[string]*ModuleCall
 
-	ManagedResources map[string]*Resource
-	DataResources    map[string]*Resource
+	ManagedResources   map[string]*Resource
+	DataResources      map[string]*Resource
+	EphemeralResources map[string]*Resource
 
 	Moved   []*Moved
 	Import  []*Import
@@ -97,6 +103,7 @@ type File struct {
 	ProviderMetas     []*ProviderMeta
 	RequiredProviders []*RequiredProviders
 	Encryptions       []*config.EncryptionConfig
+	RequiredTags      []*RequiredTags
 
 	Variables []*Variable
 	Locals    []*Local
@@ -104,8 +111,9 @@ type File struct {
 
 	ModuleCalls []*ModuleCall
 
-	ManagedResources []*Resource
-	DataResources    []*Resource
+	ManagedResources   []*Resource
+	DataResources      []*Resource
+	EphemeralResources []*Resource
 
 	Moved   []*Moved
 	Import  []*Import
@@ -177,6 +185,7 @@ func NewModule(primaryFiles, overrideFiles []*File, call StaticModuleCall, sourc
 		ModuleCalls:        map[string]*ModuleCall{},
 		ManagedResources:   map[string]*Resource{},
 		DataResources:      map[string]*Resource{},
+		EphemeralResources: map[string]*Resource{},
 		Checks:             map[string]*Check{},
 		ProviderMetas:      map[addrs.Provider]*ProviderMeta{},
 		Tests:              map[string]*TestFile{},
@@ -272,6 +281,8 @@ func (m *Module) ResourceByAddr(addr addrs.Resource) *Resource {
 		return m.ManagedResources[key]
 	case addrs.DataResourceMode:
 		return m.DataResources[key]
+	case addrs.EphemeralResourceMode:
+		return m.EphemeralResources[key]
 	default:
 		return nil
 	}
@@ -331,6 +342,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 					Summary:  "Duplicate provider configuration",
 					Detail:   fmt.Sprintf("A default (non-aliased) provider configuration for %q was already given at %s. If multiple configurations are required, set the \"alias\" argument for alternative configurations.", existing.Name, existing.DeclRange),
 					Subject:  &pc.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateProviderConfig, nil),
 				})
 			} else {
 				diags = append(diags, &hcl.Diagnostic{
@@ -338,6 +350,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 					Summary:  "Duplicate provider configuration",
 					Detail:   fmt.Sprintf("A provider configuration for %q with alias %q was already given at %s. Each configuration for the same provider must have a distinct alias.", existing.Name, existing.Alias, existing.DeclRange),
 					Subject:  &pc.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateProviderConfig, nil),
 				})
 			}
 			continue
@@ -371,6 +384,8 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 		m.Encryption = e
 	}
 
+	m.RequiredTags = append(m.RequiredTags, file.RequiredTags...)
+
 	for _, v := range file.Variables {
 		if existing, exists := m.Variables[v.Name]; exists {
 			diags = append(diags, &hcl.Diagnostic{
@@ -378,6 +393,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  "Duplicate variable declaration",
 				Detail:   fmt.Sprintf("A variable named %q was already declared at %s. Variable names must be unique within a module.", existing.Name, existing.DeclRange),
 				Subject:  &v.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateVariable, nil),
 			})
 		}
 		m.Variables[v.Name] = v
@@ -390,6 +406,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  "Duplicate local value definition",
 				Detail:   fmt.Sprintf("A local value named %q was already defined at %s. Local value names must be unique within a module.", existing.Name, existing.DeclRange),
 				Subject:  &l.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateLocal, nil),
 			})
 		}
 		m.Locals[l.Name] = l
@@ -402,6 +419,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  "Duplicate output definition",
 				Detail:   fmt.Sprintf("An output named %q was already defined at %s. Output names must be unique within a module.", existing.Name, existing.DeclRange),
 				Subject:  &o.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateOutput, nil),
 			})
 		}
 		m.Outputs[o.Name] = o
@@ -414,6 +432,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  "Duplicate module call",
 				Detail:   fmt.Sprintf("A module call named %q was already defined at %s. Module calls must have unique names within a module.", existing.Name, existing.DeclRange),
 				Subject:  &mc.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateModuleCall, nil),
 			})
 		}
 		m.ModuleCalls[mc.Name] = mc
@@ -427,6 +446,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  fmt.Sprintf("Duplicate resource %q configuration", existing.Type),
 				Detail:   fmt.Sprintf("A %s resource named %q was already declared at %s. Resource names must be unique per type in each module.", existing.Type, existing.Name, existing.DeclRange),
 				Subject:  &r.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateResource, nil),
 			})
 			continue
 		}
@@ -459,6 +479,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 				Summary:  fmt.Sprintf("Duplicate data %q configuration", existing.Type),
 				Detail:   fmt.Sprintf("A %s data resource named %q was already declared at %s. Resource names must be unique per type in each module.", existing.Type, existing.Name, existing.DeclRange),
 				Subject:  &r.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateResource, nil),
 			})
 			continue
 		}
@@ -474,6 +495,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 					Summary:  fmt.Sprintf("Duplicate data %q configuration", existing.Type),
 					Detail:   fmt.Sprintf("A %s data resource named %q was already declared at %s. Resource names must be unique per type in each module, including within check blocks.", existing.Type, existing.Name, existing.DeclRange),
 					Subject:  &c.DataResource.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateResource, nil),
 				})
 				continue
 			}
@@ -492,21 +514,38 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 		m.Checks[c.Name] = c
 	}
 
-	// Handle the provider associations for all data resources together.
-	for _, r := range m.DataResources {
-		// set the provider FQN for the resource
-		if r.ProviderConfigRef != nil {
-			r.Provider = m.ProviderForLocalConfig(r.ProviderConfigAddr())
-		} else {
-			// an invalid data source name (for e.g. "null resource" instead of
-			// "null_resource") can cause a panic down the line in addrs:
-			// https://github.com/hashicorp/terraform/issues/25560
-			implied, err := addrs.ParseProviderPart(r.Addr().ImpliedProvider())
-			if err == nil {
-				r.Provider = m.ImpliedProviderForUnqualifiedType(implied)
+	for _, r := range file.EphemeralResources {
+		key := r.moduleUniqueKey()
+		if existing, exists := m.EphemeralResources[key]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Duplicate ephemeral %q configuration", existing.Type),
+				Detail:   fmt.Sprintf("A %s ephemeral resource named %q was already declared at %s. Resource names must be unique per type in each module.", existing.Type, existing.Name, existing.DeclRange),
+				Subject:  &r.DeclRange,
+			})
+			continue
+		}
+		m.EphemeralResources[key] = r
+	}
+
+	// Handle the provider associations for all data and ephemeral resources
+	// together.
+	for _, resources := range []map[string]*Resource{m.DataResources, m.EphemeralResources} {
+		for _, r := range resources {
+			// set the provider FQN for the resource
+			if r.ProviderConfigRef != nil {
+				r.Provider = m.ProviderForLocalConfig(r.ProviderConfigAddr())
+			} else {
+				// an invalid data source name (for e.g. "null resource" instead of
+				// "null_resource") can cause a panic down the line in addrs:
+				// https://github.com/hashicorp/terraform/issues/25560
+				implied, err := addrs.ParseProviderPart(r.Addr().ImpliedProvider())
+				if err == nil {
+					r.Provider = m.ImpliedProviderForUnqualifiedType(implied)
+				}
+				// We don't return a diagnostic because the invalid resource name
+				// will already have been caught.
 			}
-			// We don't return a diagnostic because the invalid resource name
-			// will already have been caught.
 		}
 	}
 
@@ -523,6 +562,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 					Summary:  fmt.Sprintf("Duplicate import configuration for %q", *i.ResolvedTo),
 					Detail:   fmt.Sprintf("An import block for the resource %q was already declared at %s. A resource can have only one import block.", *i.ResolvedTo, mi.DeclRange),
 					Subject:  &i.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateImport, nil),
 				})
 				continue
 			}
@@ -643,6 +683,10 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		}
 	}
 
+	// Override files can only add further tag requirements, as there's no
+	// natural way to identify which existing requirement they would replace.
+	m.RequiredTags = append(m.RequiredTags, file.RequiredTags...)
+
 	for _, v := range file.Variables {
 		existing, exists := m.Variables[v.Name]
 		if !exists {
@@ -735,6 +779,22 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		diags = append(diags, mergeDiags...)
 	}
 
+	for _, r := range file.EphemeralResources {
+		key := r.moduleUniqueKey()
+		existing, exists := m.EphemeralResources[key]
+		if !exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing ephemeral resource to override",
+				Detail:   fmt.Sprintf("There is no %s ephemeral resource named %q. An override file can only override an ephemeral block defined in a primary configuration file.", r.Type, r.Name),
+				Subject:  &r.DeclRange,
+			})
+			continue
+		}
+		mergeDiags := existing.merge(r, m.ProviderRequirements.RequiredProviders)
+		diags = append(diags, mergeDiags...)
+	}
+
 	for _, m := range file.Moved {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
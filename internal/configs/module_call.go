@@ -15,6 +15,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/getmodules"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // ModuleCall represents a "module" block in a module or file.
@@ -27,8 +28,9 @@ type ModuleCall struct {
 	SourceSet     bool
 
 	// Used when building the corresponding StaticModuleCall
-	Variables StaticModuleVariables
-	Workspace string
+	Variables         StaticModuleVariables
+	Workspace         string
+	ProviderFunctions StaticProviderFunctions
 
 	Config hcl.Body
 
@@ -68,6 +70,7 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 			Summary:  "Invalid module instance name",
 			Detail:   badIdentifierDetail,
 			Subject:  &block.LabelRanges[0],
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidModuleName, nil),
 		})
 	}
 
@@ -148,6 +151,7 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 
 func (mc *ModuleCall) decodeStaticFields(eval *StaticEvaluator) hcl.Diagnostics {
 	mc.Workspace = eval.call.workspace
+	mc.ProviderFunctions = eval.call.funcs
 	mc.decodeStaticVariables(eval)
 
 	var diags hcl.Diagnostics
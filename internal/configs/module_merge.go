@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
@@ -51,6 +52,13 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 		v.Sensitive = ov.Sensitive
 		v.SensitiveSet = ov.SensitiveSet
 	}
+	if ov.SecretSet {
+		v.Secret = ov.Secret
+		v.SecretSet = ov.SecretSet
+		if v.Secret {
+			v.Sensitive = true
+		}
+	}
 	if ov.Default != cty.NilVal {
 		v.Default = ov.Default
 	}
@@ -89,6 +97,7 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 					Summary:  "Invalid default value for variable",
 					Detail:   fmt.Sprintf("Overriding this variable's type constraint has made its default value invalid: %s.", err),
 					Subject:  &ov.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 				})
 			case ov.Type == cty.NilType && ov.Default != cty.NilVal:
 				// Only the default was overridden
@@ -97,6 +106,7 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 					Summary:  "Invalid default value for variable",
 					Detail:   fmt.Sprintf("The overridden default value for this variable is not compatible with the variable's type constraint: %s.", err),
 					Subject:  &ov.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 				})
 			default:
 				diags = append(diags, &hcl.Diagnostic{
@@ -104,6 +114,7 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 					Summary:  "Invalid default value for variable",
 					Detail:   fmt.Sprintf("This variable's default value is not compatible with its type constraint: %s.", err),
 					Subject:  &ov.DeclRange,
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 				})
 			}
 		} else {
@@ -117,6 +128,7 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 				Summary:  "Invalid default value for variable",
 				Detail:   "A null default value is not valid when nullable=false.",
 				Subject:  &ov.DeclRange,
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 			})
 		}
 	}
@@ -248,6 +260,12 @@ func (r *Resource) merge(or *Resource, rps map[string]*RequiredProvider) hcl.Dia
 		if or.Managed.IgnoreAllChanges {
 			r.Managed.IgnoreAllChanges = true
 		}
+		if len(or.Managed.Groups) != 0 {
+			r.Managed.Groups = or.Managed.Groups
+		}
+		if or.Managed.Note != "" {
+			r.Managed.Note = or.Managed.Note
+		}
 		if or.Managed.PreventDestroySet {
 			r.Managed.PreventDestroy = or.Managed.PreventDestroy
 			r.Managed.PreventDestroySet = or.Managed.PreventDestroySet
@@ -256,6 +274,9 @@ func (r *Resource) merge(or *Resource, rps map[string]*RequiredProvider) hcl.Dia
 			r.Managed.Provisioners = or.Managed.Provisioners
 		}
 	}
+	if or.NoCache {
+		r.NoCache = true
+	}
 
 	r.Config = MergeBodies(r.Config, or.Config)
 
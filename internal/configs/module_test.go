@@ -90,6 +90,14 @@ func TestNewModule_resource_providers(t *testing.T) {
 		)
 	}
 
+	// an ephemeral resource
+	if !cfg.Module.EphemeralResources["ephemeral.test_token.explicit"].Provider.Equals(wantFoo) {
+		t.Fatalf("wrong provider for \"ephemeral.test_token.explicit\"\ngot:  %s\nwant: %s",
+			cfg.Module.EphemeralResources["ephemeral.test_token.explicit"].Provider,
+			wantFoo,
+		)
+	}
+
 	// child module
 	cm := cfg.Children["child"].Module
 	if !cm.ManagedResources["test_instance.explicit"].Provider.Equals(wantBar) {
@@ -369,6 +377,26 @@ func TestModule_backend_override(t *testing.T) {
 	}
 }
 
+func TestModule_backend_state_namespace(t *testing.T) {
+	mod, diags := testModuleFromDir("testdata/valid-modules/backend-state-namespace")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if got, want := mod.Backend.Namespace, "networking"; got != want {
+		t.Errorf("wrong result for backend namespace: got %q, want %q", got, want)
+	}
+
+	// The namespace isn't part of the configuration passed to the backend.
+	attrs, _ := mod.Backend.Config.JustAttributes()
+	if _, ok := attrs["state_namespace"]; ok {
+		t.Errorf("backend configuration includes state_namespace")
+	}
+	if _, ok := attrs["bucket"]; !ok {
+		t.Errorf("backend configuration doesn't include bucket")
+	}
+}
+
 // Unlike most other overrides, backend blocks do not require a base configuration in a primary
 // configuration file, as an omitted backend there implies the local backend.
 func TestModule_backend_override_no_base(t *testing.T) {
@@ -39,8 +39,14 @@ type Variable struct {
 	Validations []*CheckRule
 	Sensitive   bool
 
+	// Secret indicates that values derived from the variable must never be
+	// saved in the state in cleartext. A secret variable is always also
+	// sensitive.
+	Secret bool
+
 	DescriptionSet bool
 	SensitiveSet   bool
+	SecretSet      bool
 
 	// Nullable indicates that null is a valid value for this variable. Setting
 	// Nullable to false means that the module can expect this variable to
@@ -123,6 +129,24 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 		v.SensitiveSet = true
 	}
 
+	if attr, exists := content.Attributes["secret"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Secret)
+		diags = append(diags, valDiags...)
+		v.SecretSet = true
+
+		if v.Secret {
+			if v.SensitiveSet && !v.Sensitive {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid secret variable",
+					Detail:   "A secret variable is always sensitive, so it can't set sensitive to false.",
+					Subject:  content.Attributes["sensitive"].Range.Ptr(),
+				})
+			}
+			v.Sensitive = true
+		}
+	}
+
 	if attr, exists := content.Attributes["nullable"]; exists {
 		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Nullable)
 		diags = append(diags, valDiags...)
@@ -160,6 +184,7 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 					Summary:  "Invalid default value for variable",
 					Detail:   fmt.Sprintf("This default value is not compatible with the variable's type constraint: %s.", tfdiags.FormatError(err)),
 					Subject:  attr.Expr.Range().Ptr(),
+					Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 				})
 				val = cty.DynamicVal
 			}
@@ -171,6 +196,7 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 				Summary:  "Invalid default value for variable",
 				Detail:   "A null default value is not valid when nullable=false.",
 				Subject:  attr.Expr.Range().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableDefault, nil),
 			})
 		}
 
@@ -535,6 +561,9 @@ var variableBlockSchema = &hcl.BodySchema{
 		{
 			Name: "sensitive",
 		},
+		{
+			Name: "secret",
+		},
 		{
 			Name: "nullable",
 		},
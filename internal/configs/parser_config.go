@@ -119,6 +119,13 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 						file.Encryptions = append(file.Encryptions, encryptionCfg)
 					}
 
+				case "required_tags":
+					reqs, reqsDiags := decodeRequiredTagsBlock(innerBlock)
+					diags = append(diags, reqsDiags...)
+					if !reqsDiags.HasErrors() {
+						file.RequiredTags = append(file.RequiredTags, reqs)
+					}
+
 				default:
 					// Should never happen because the above cases should be exhaustive
 					// for all block type names in our schema.
@@ -183,6 +190,13 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 				file.DataResources = append(file.DataResources, cfg)
 			}
 
+		case "ephemeral":
+			cfg, cfgDiags := decodeEphemeralBlock(block)
+			diags = append(diags, cfgDiags...)
+			if cfg != nil {
+				file.EphemeralResources = append(file.EphemeralResources, cfg)
+			}
+
 		case "moved":
 			cfg, cfgDiags := decodeMovedBlock(block)
 			diags = append(diags, cfgDiags...)
@@ -298,6 +312,10 @@ var configFileSchema = &hcl.BodySchema{
 			Type:       "data",
 			LabelNames: []string{"type", "name"},
 		},
+		{
+			Type:       "ephemeral",
+			LabelNames: []string{"type", "name"},
+		},
 		{
 			Type: "moved",
 		},
@@ -340,6 +358,9 @@ var terraformBlockSchema = &hcl.BodySchema{
 		{
 			Type: "encryption",
 		},
+		{
+			Type: "required_tags",
+		},
 	},
 }
 
@@ -100,18 +100,66 @@ func TestParserLoadConfigFileFailureMessages(t *testing.T) {
 		WantDiag     string
 		WantDetail   string
 	}{
+		{
+			"invalid-files/backend-state-namespace-invalid.tf",
+			hcl.DiagError,
+			"Invalid state namespace",
+			`A state namespace must contain only letters, digits, underscores, and dashes.`,
+		},
+		{
+			"invalid-files/check-phases-invalid.tf",
+			hcl.DiagError,
+			"Invalid check phases",
+			`The phase "refresh" is not valid. The "phases" argument may only contain "plan", "apply" and "check".`,
+		},
+		{
+			"invalid-files/check-severity-invalid.tf",
+			hcl.DiagError,
+			"Invalid check severity",
+			`The "severity" argument must be either "warning" or "error".`,
+		},
 		{
 			"invalid-files/data-resource-lifecycle.tf",
 			hcl.DiagError,
 			"Invalid data resource lifecycle argument",
 			`The lifecycle argument "ignore_changes" is defined only for managed resources ("resource" blocks), and is not valid for data resources.`,
 		},
+		{
+			"invalid-files/ephemeral-resource-lifecycle.tf",
+			hcl.DiagError,
+			"Unsupported lifecycle block",
+			"Ephemeral resources don't support lifecycle customizations, because OpenTofu opens their objects again in each operation.",
+		},
+		{
+			"invalid-files/resource-lifecycle-groups-invalid.tf",
+			hcl.DiagError,
+			"Invalid resource group name",
+			`The group name "not a name" is not valid. A name must start with a letter or underscore and may contain only letters, digits, underscores, and dashes.`,
+		},
+		{
+			"invalid-files/resource-lifecycle-no-cache.tf",
+			hcl.DiagError,
+			"Invalid resource lifecycle argument",
+			`The lifecycle argument "no_cache" is defined only for data resources ("data" blocks), and is not valid for managed resources.`,
+		},
 		{
 			"invalid-files/variable-type-unknown.tf",
 			hcl.DiagError,
 			"Invalid type specification",
 			`The keyword "notatype" is not a valid type specification.`,
 		},
+		{
+			"invalid-files/required-tags-enforcement.tf",
+			hcl.DiagError,
+			"Invalid enforcement",
+			`The "enforcement" argument must be either "error" or "warning".`,
+		},
+		{
+			"invalid-files/required-tags-no-tags.tf",
+			hcl.DiagError,
+			"Missing required tags",
+			`A "required_tags" block must contain at least one "tag" block.`,
+		},
 		{
 			"invalid-files/unexpected-attr.tf",
 			hcl.DiagError,
@@ -7,6 +7,8 @@ package configs
 
 import (
 	"fmt"
+	"regexp"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
@@ -25,8 +27,47 @@ type RequiredProvider struct {
 	Requirement VersionConstraint
 	DeclRange   hcl.Range
 	Aliases     []addrs.LocalProviderConfig
+
+	// Retry is the policy for retrying calls to the provider that fail for
+	// transient reasons, or nil if the configuration doesn't enable retries.
+	Retry *ProviderRetry
+
+	// Capabilities are the names of the protocol capabilities that the
+	// module requires the provider to announce. The names aren't validated
+	// here, because the set of known capabilities belongs to the provider
+	// protocol rather than to the configuration language.
+	Capabilities      []string
+	CapabilitiesRange hcl.Range
 }
 
+// ProviderRetry represents the settings of the "retry" attribute in a
+// required_providers entry, which asks OpenTofu to retry provider calls that
+// fail because the plugin stopped responding or with an error matching one of
+// RetryableErrors.
+type ProviderRetry struct {
+	// MaxAttempts is the total number of attempts for each call, including
+	// the first.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first retry. Each later retry
+	// waits twice as long as the previous one, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	// RetryableErrors are patterns that match the summary or detail of
+	// errors returned by the provider that are worth retrying.
+	RetryableErrors []*regexp.Regexp
+
+	DeclRange hcl.Range
+}
+
+// Defaults for the optional settings of a provider retry policy.
+const (
+	defaultProviderRetryMaxAttempts  = 3
+	defaultProviderRetryInitialDelay = time.Second
+	defaultProviderRetryMaxDelay     = 30 * time.Second
+)
+
 type RequiredProviders struct {
 	RequiredProviders map[string]*RequiredProvider
 	DeclRange         hcl.Range
@@ -214,11 +255,42 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 					rp.Aliases = append(rp.Aliases, addr)
 				}
 
+			case "retry":
+				retry, retryDiags := decodeProviderRetry(kv.Value)
+				diags = append(diags, retryDiags...)
+				rp.Retry = retry
+
+			case "capabilities":
+				val, valDiags := kv.Value.Value(nil)
+				if valDiags.HasErrors() || !(val.Type().IsListType() || val.Type().IsTupleType()) || !val.IsWhollyKnown() {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid capabilities",
+						Detail:   "Capabilities must be specified as a list of capability names.",
+						Subject:  kv.Value.Range().Ptr(),
+					})
+					continue
+				}
+				for it := val.ElementIterator(); it.Next(); {
+					_, v := it.Element()
+					if v.IsNull() || !v.Type().Equals(cty.String) {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Invalid capabilities",
+							Detail:   "Capabilities must be specified as a list of capability names.",
+							Subject:  kv.Value.Range().Ptr(),
+						})
+						continue LOOP
+					}
+					rp.Capabilities = append(rp.Capabilities, v.AsString())
+				}
+				rp.CapabilitiesRange = kv.Value.Range()
+
 			default:
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid required_providers object",
-					Detail:   `required_providers objects can only contain "version", "source" and "configuration_aliases" attributes. To configure a provider, use a "provider" block.`,
+					Detail:   `required_providers objects can only contain "version", "source", "configuration_aliases", "retry" and "capabilities" attributes. To configure a provider, use a "provider" block.`,
 					Subject:  kv.Key.Range().Ptr(),
 				})
 				break LOOP
@@ -251,3 +323,142 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 
 	return ret, diags
 }
+
+func decodeProviderRetry(expr hcl.Expression) (*ProviderRetry, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &ProviderRetry{
+		MaxAttempts:  defaultProviderRetryMaxAttempts,
+		InitialDelay: defaultProviderRetryInitialDelay,
+		MaxDelay:     defaultProviderRetryMaxDelay,
+		DeclRange:    expr.Range(),
+	}
+
+	kvs, mapDiags := hcl.ExprMap(expr)
+	if mapDiags.HasErrors() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid retry policy",
+			Detail:   "The retry policy must be an object.",
+			Subject:  expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	decodeDuration := func(kv hcl.KeyValuePair, name string) (time.Duration, bool) {
+		val, valDiags := kv.Value.Value(nil)
+		if valDiags.HasErrors() || !val.Type().Equals(cty.String) || val.IsNull() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry policy",
+				Detail:   fmt.Sprintf("The %s must be a duration string, such as \"5s\".", name),
+				Subject:  kv.Value.Range().Ptr(),
+			})
+			return 0, false
+		}
+		d, err := time.ParseDuration(val.AsString())
+		if err != nil || d < 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry policy",
+				Detail:   fmt.Sprintf("The %s must be a positive duration string, such as \"5s\".", name),
+				Subject:  kv.Value.Range().Ptr(),
+			})
+			return 0, false
+		}
+		return d, true
+	}
+
+	for _, kv := range kvs {
+		key, keyDiags := kv.Key.Value(nil)
+		if keyDiags.HasErrors() || !key.Type().Equals(cty.String) || key.IsNull() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry policy",
+				Detail:   "The retry policy attribute names must be strings.",
+				Subject:  kv.Key.Range().Ptr(),
+			})
+			continue
+		}
+
+		switch key.AsString() {
+		case "max_attempts":
+			val, valDiags := kv.Value.Value(nil)
+			var n int
+			if !valDiags.HasErrors() && val.Type().Equals(cty.Number) && !val.IsNull() {
+				if bf := val.AsBigFloat(); bf.IsInt() {
+					i, _ := bf.Int64()
+					n = int(i)
+				}
+			}
+			if n < 1 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid retry policy",
+					Detail:   "The maximum number of attempts must be a whole number greater than zero.",
+					Subject:  kv.Value.Range().Ptr(),
+				})
+				continue
+			}
+			ret.MaxAttempts = n
+
+		case "initial_delay":
+			if d, ok := decodeDuration(kv, "initial delay"); ok {
+				ret.InitialDelay = d
+			}
+
+		case "max_delay":
+			if d, ok := decodeDuration(kv, "maximum delay"); ok {
+				ret.MaxDelay = d
+			}
+
+		case "retryable_errors":
+			exprs, listDiags := hcl.ExprList(kv.Value)
+			if listDiags.HasErrors() {
+				diags = append(diags, listDiags...)
+				continue
+			}
+			for _, expr := range exprs {
+				val, valDiags := expr.Value(nil)
+				if valDiags.HasErrors() || !val.Type().Equals(cty.String) || val.IsNull() {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid retry policy",
+						Detail:   "Retryable errors must be given as regular expression strings.",
+						Subject:  expr.Range().Ptr(),
+					})
+					continue
+				}
+				re, err := regexp.Compile(val.AsString())
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid retry policy",
+						Detail:   fmt.Sprintf("Invalid regular expression for a retryable error: %s.", err),
+						Subject:  expr.Range().Ptr(),
+					})
+					continue
+				}
+				ret.RetryableErrors = append(ret.RetryableErrors, re)
+			}
+
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry policy",
+				Detail:   `The retry policy can only contain "max_attempts", "initial_delay", "max_delay" and "retryable_errors" attributes.`,
+				Subject:  kv.Key.Range().Ptr(),
+			})
+		}
+	}
+
+	if ret.MaxDelay < ret.InitialDelay {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid retry policy",
+			Detail:   "The maximum delay must not be shorter than the initial delay.",
+			Subject:  expr.Range().Ptr(),
+		})
+	}
+
+	return ret, diags
+}
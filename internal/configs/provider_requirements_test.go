@@ -6,12 +6,15 @@
 package configs
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hcltest"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/zclconf/go-cty/cty"
@@ -345,6 +348,151 @@ func TestDecodeRequiredProvidersBlock(t *testing.T) {
 	}
 }
 
+func TestDecodeRequiredProvidersBlock_retry(t *testing.T) {
+	tests := map[string]struct {
+		Src     string
+		Want    *ProviderRetry
+		Matches string
+		Error   string
+	}{
+		"defaults": {
+			Src: `retry = {}`,
+			Want: &ProviderRetry{
+				MaxAttempts:  3,
+				InitialDelay: time.Second,
+				MaxDelay:     30 * time.Second,
+			},
+		},
+		"all settings": {
+			Src: `retry = {
+				max_attempts     = 5
+				initial_delay    = "500ms"
+				max_delay        = "1m"
+				retryable_errors = ["(?i)rate exceeded"]
+			}`,
+			Want: &ProviderRetry{
+				MaxAttempts:  5,
+				InitialDelay: 500 * time.Millisecond,
+				MaxDelay:     time.Minute,
+			},
+			Matches: "Rate Exceeded",
+		},
+		"zero attempts": {
+			Src:   `retry = { max_attempts = 0 }`,
+			Error: "The maximum number of attempts must be a whole number greater than zero.",
+		},
+		"invalid duration": {
+			Src:   `retry = { initial_delay = "soon" }`,
+			Error: "The initial delay must be a positive duration string",
+		},
+		"max delay too short": {
+			Src:   `retry = { initial_delay = "10s", max_delay = "1s" }`,
+			Error: "The maximum delay must not be shorter than the initial delay.",
+		},
+		"invalid pattern": {
+			Src:   `retry = { retryable_errors = ["("] }`,
+			Error: "Invalid regular expression for a retryable error",
+		},
+		"unknown attribute": {
+			Src:   `retry = { forever = true }`,
+			Error: `The retry policy can only contain "max_attempts"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := "required_providers {\n  test = {\n    source = \"hashicorp/test\"\n    " + test.Src + "\n  }\n}\n"
+			file, diags := hclsyntax.ParseConfig([]byte(src), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			block := file.Body.(*hclsyntax.Body).Blocks[0].AsHCLBlock()
+
+			got, diags := decodeRequiredProvidersBlock(block)
+			if test.Error != "" {
+				if !diags.HasErrors() {
+					t.Fatal("expected error")
+				}
+				if gotErr := diags[0].Detail; !strings.Contains(gotErr, test.Error) {
+					t.Fatalf("wrong error, got %q, want %q", gotErr, test.Error)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			retry := got.RequiredProviders["test"].Retry
+			if retry == nil {
+				t.Fatal("no retry policy")
+			}
+			if retry.MaxAttempts != test.Want.MaxAttempts || retry.InitialDelay != test.Want.InitialDelay || retry.MaxDelay != test.Want.MaxDelay {
+				t.Errorf("wrong retry policy %d/%s/%s; want %d/%s/%s",
+					retry.MaxAttempts, retry.InitialDelay, retry.MaxDelay,
+					test.Want.MaxAttempts, test.Want.InitialDelay, test.Want.MaxDelay)
+			}
+			if test.Matches != "" {
+				if len(retry.RetryableErrors) != 1 || !retry.RetryableErrors[0].MatchString(test.Matches) {
+					t.Errorf("wrong retryable errors %v", retry.RetryableErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeRequiredProvidersBlock_capabilities(t *testing.T) {
+	tests := map[string]struct {
+		Src   string
+		Want  []string
+		Error string
+	}{
+		"capabilities": {
+			Src:  `capabilities = ["plan_destroy", "check_resource_quotas"]`,
+			Want: []string{"plan_destroy", "check_resource_quotas"},
+		},
+		"empty": {
+			Src: `capabilities = []`,
+		},
+		"not a list": {
+			Src:   `capabilities = "plan_destroy"`,
+			Error: "Capabilities must be specified as a list of capability names.",
+		},
+		"not strings": {
+			Src:   `capabilities = [["plan_destroy"]]`,
+			Error: "Capabilities must be specified as a list of capability names.",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			src := "required_providers {\n  test = {\n    source = \"hashicorp/test\"\n    " + test.Src + "\n  }\n}\n"
+			file, diags := hclsyntax.ParseConfig([]byte(src), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			block := file.Body.(*hclsyntax.Body).Blocks[0].AsHCLBlock()
+
+			got, diags := decodeRequiredProvidersBlock(block)
+			if test.Error != "" {
+				if !diags.HasErrors() {
+					t.Fatal("expected error")
+				}
+				if gotErr := diags[0].Detail; gotErr != test.Error {
+					t.Fatalf("wrong error, got %q, want %q", gotErr, test.Error)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if diff := cmp.Diff(test.Want, got.RequiredProviders["test"].Capabilities); diff != "" {
+				t.Errorf("wrong capabilities\n%s", diff)
+			}
+		})
+	}
+}
+
 func testVC(ver string) VersionConstraint {
 	constraint, _ := version.NewConstraint(ver)
 	return VersionConstraint{
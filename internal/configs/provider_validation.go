@@ -458,6 +458,7 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 	}
 	checkImpliedProviderNames(mod.ManagedResources)
 	checkImpliedProviderNames(mod.DataResources)
+	checkImpliedProviderNames(mod.EphemeralResources)
 
 	// collect providers passed from the parent
 	if parentCall != nil {
@@ -519,6 +520,17 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 	}
 	checkProviderKeys(mod.ManagedResources)
 	checkProviderKeys(mod.DataResources)
+	checkProviderKeys(mod.EphemeralResources)
+
+	// Import blocks can also refer to a specific instance of a provider
+	// configuration, which is then used for the imported resource.
+	for _, i := range mod.Import {
+		if i.ProviderConfigRef == nil {
+			continue
+		}
+		instanceExpr := instanced[providerName(i.ProviderConfigRef.Name, i.ProviderConfigRef.Alias)]
+		diags = diags.Extend(i.ProviderConfigRef.InstanceValidation("import", instanceExpr != nil))
+	}
 
 	// Verify that any module calls only refer to named providers, and that
 	// those providers will have a configuration at runtime. This way we can
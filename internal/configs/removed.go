@@ -6,7 +6,10 @@
 package configs
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/opentofu/opentofu/internal/addrs"
 )
 
@@ -14,6 +17,11 @@ import (
 type Removed struct {
 	From *addrs.RemoveEndpoint
 
+	// Destroy is true if the objects that the block removes are to be
+	// destroyed, rather than only removed from the state. It's set by the
+	// "destroy" argument of the block's lifecycle block.
+	Destroy bool
+
 	DeclRange hcl.Range
 }
 
@@ -36,6 +44,31 @@ func decodeRemovedBlock(block *hcl.Block) (*Removed, hcl.Diagnostics) {
 		}
 	}
 
+	var seenLifecycle *hcl.Block
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "lifecycle":
+			if seenLifecycle != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate lifecycle block",
+					Detail:   fmt.Sprintf("This removed block already has a lifecycle block at %s.", seenLifecycle.DefRange),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+			seenLifecycle = block
+
+			lcContent, lcDiags := block.Body.Content(removedLifecycleBlockSchema)
+			diags = append(diags, lcDiags...)
+
+			if attr, exists := lcContent.Attributes["destroy"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &removed.Destroy)
+				diags = append(diags, valDiags...)
+			}
+		}
+	}
+
 	return removed, diags
 }
 
@@ -46,4 +79,18 @@ var removedBlockSchema = &hcl.BodySchema{
 			Required: true,
 		},
 	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "lifecycle",
+		},
+	},
+}
+
+var removedLifecycleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "destroy",
+			Required: true,
+		},
+	},
 }
@@ -168,7 +168,11 @@ func TestRemovedBlock_inModule(t *testing.T) {
 
 	var got []string
 	for _, mc := range mod.Removed {
-		got = append(got, mc.From.RelSubject.String())
+		addr := mc.From.RelSubject.String()
+		if mc.Destroy {
+			addr += " (destroy)"
+		}
+		got = append(got, addr)
 	}
 	want := []string{
 		`test.foo`,
@@ -177,6 +181,7 @@ func TestRemovedBlock_inModule(t *testing.T) {
 		`module.a`,
 		`test.foo`,
 		`test.boop`,
+		`module.b (destroy)`,
 	}
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("wrong addresses\n%s", diff)
@@ -0,0 +1,175 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// RequiredTagsEnforcement describes how violations of a required_tags block
+// are reported.
+type RequiredTagsEnforcement string
+
+const (
+	// RequiredTagsError reports violations as errors, which prevents the plan
+	// from being applied.
+	RequiredTagsError RequiredTagsEnforcement = "error"
+
+	// RequiredTagsWarning reports violations as warnings only.
+	RequiredTagsWarning RequiredTagsEnforcement = "warning"
+)
+
+// RequiredTags represents a "required_tags" block inside a "terraform" block,
+// which declares the tags (or labels) that managed resources of certain types
+// must carry.
+//
+// Only the required_tags blocks of the root module are enforced, so they act
+// as a project level policy covering the resources of every module.
+type RequiredTags struct {
+	// ResourceTypes lists the managed resource types the requirement applies
+	// to. A type ending with "*" matches every type with that prefix.
+	ResourceTypes []string
+
+	// Attribute is the name of the map attribute that holds the tags within
+	// each of the resource types. Defaults to "tags".
+	Attribute string
+
+	Enforcement RequiredTagsEnforcement
+
+	Tags []*RequiredTag
+
+	DeclRange hcl.Range
+}
+
+// RequiredTag represents a "tag" block inside a "required_tags" block.
+type RequiredTag struct {
+	Key string
+
+	// AllowedValues, if not empty, lists the only values the tag may have.
+	AllowedValues []string
+
+	DeclRange hcl.Range
+}
+
+// AppliesTo returns true if the requirement covers managed resources of the
+// given type.
+func (r *RequiredTags) AppliesTo(resourceType string) bool {
+	for _, pattern := range r.ResourceTypes {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(resourceType, prefix) {
+				return true
+			}
+			continue
+		}
+		if pattern == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeRequiredTagsBlock(block *hcl.Block) (*RequiredTags, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	content, contentDiags := block.Body.Content(requiredTagsBlockSchema)
+	diags = append(diags, contentDiags...)
+
+	ret := &RequiredTags{
+		Attribute:   "tags",
+		Enforcement: RequiredTagsError,
+		DeclRange:   block.DefRange,
+	}
+
+	if attr, exists := content.Attributes["resource_types"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &ret.ResourceTypes)...)
+	}
+
+	if attr, exists := content.Attributes["attribute"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &ret.Attribute)...)
+	}
+
+	if attr, exists := content.Attributes["enforcement"]; exists {
+		var enforcement string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &enforcement)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			switch RequiredTagsEnforcement(enforcement) {
+			case RequiredTagsError, RequiredTagsWarning:
+				ret.Enforcement = RequiredTagsEnforcement(enforcement)
+			default:
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid enforcement",
+					Detail:   `The "enforcement" argument must be either "error" or "warning".`,
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+
+	seen := make(map[string]*RequiredTag)
+	for _, block := range content.Blocks {
+		tag := &RequiredTag{
+			Key:       block.Labels[0],
+			DeclRange: block.DefRange,
+		}
+
+		if existing, exists := seen[tag.Key]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate required tag",
+				Detail:   fmt.Sprintf("The tag %q was already required at %s.", tag.Key, existing.DeclRange),
+				Subject:  block.DefRange.Ptr(),
+			})
+			continue
+		}
+		seen[tag.Key] = tag
+
+		attrs, attrDiags := block.Body.JustAttributes()
+		diags = append(diags, attrDiags...)
+		for name, attr := range attrs {
+			switch name {
+			case "allowed_values":
+				diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &tag.AllowedValues)...)
+			default:
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unsupported argument",
+					Detail:   fmt.Sprintf("An argument named %q is not expected here.", name),
+					Subject:  attr.NameRange.Ptr(),
+				})
+			}
+		}
+
+		ret.Tags = append(ret.Tags, tag)
+	}
+
+	if len(ret.Tags) == 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required tags",
+			Detail:   `A "required_tags" block must contain at least one "tag" block.`,
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	return ret, diags
+}
+
+var requiredTagsBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "resource_types", Required: true},
+		{Name: "attribute"},
+		{Name: "enforcement"},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "tag", LabelNames: []string{"key"}},
+	},
+}
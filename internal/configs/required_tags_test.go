@@ -0,0 +1,88 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestRequiredTags_decode(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+terraform {
+  required_tags {
+    resource_types = ["aws_*"]
+
+    tag "Owner" {}
+    tag "Environment" {
+      allowed_values = ["dev", "prod"]
+    }
+  }
+}
+`,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf")
+	assertNoDiagnostics(t, diags)
+
+	want := []*RequiredTags{
+		{
+			ResourceTypes: []string{"aws_*"},
+			Attribute:     "tags",
+			Enforcement:   RequiredTagsError,
+			Tags: []*RequiredTag{
+				{Key: "Owner"},
+				{Key: "Environment", AllowedValues: []string{"dev", "prod"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, file.RequiredTags, cmpopts.IgnoreTypes(hcl.Range{})); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestRequiredTags_duplicateTag(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+terraform {
+  required_tags {
+    resource_types = ["aws_instance"]
+
+    tag "Owner" {}
+    tag "Owner" {}
+  }
+}
+`,
+	})
+
+	_, diags := parser.LoadConfigFile("main.tf")
+	assertExactDiagnostics(t, diags, []string{
+		`main.tf:7,5-16: Duplicate required tag; The tag "Owner" was already required at main.tf:6,5-16.`,
+	})
+}
+
+func TestRequiredTags_AppliesTo(t *testing.T) {
+	reqs := &RequiredTags{
+		ResourceTypes: []string{"aws_*", "google_compute_instance"},
+	}
+
+	tests := map[string]bool{
+		"aws_instance":            true,
+		"aws_s3_bucket":           true,
+		"google_compute_instance": true,
+		"google_compute_disk":     false,
+		"azurerm_resource_group":  false,
+	}
+	for typ, want := range tests {
+		if got := reqs.AppliesTo(typ); got != want {
+			t.Errorf("wrong result for %s: got %t, want %t", typ, got, want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package configs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -39,6 +40,11 @@ type Resource struct {
 
 	TriggersReplacement []hcl.Expression
 
+	// NoCache is set by the "no_cache" lifecycle argument of a data resource,
+	// to always read the data source rather than reusing the result of an
+	// identical read in the same operation.
+	NoCache bool
+
 	// Managed is populated only for Mode = addrs.ManagedResourceMode,
 	// containing the additional fields that apply to managed resources.
 	// For all other resource modes, this field is nil.
@@ -73,6 +79,15 @@ type ManagedResource struct {
 	IgnoreChanges       []hcl.Traversal
 	IgnoreAllChanges    bool
 
+	// Groups are the labels that the lifecycle block assigns to the
+	// resource, which the -target-group planning option selects resources
+	// by.
+	Groups []string
+
+	// Note is a note about the resource that OpenTofu saves with each of its
+	// instances in the state and shows whenever they appear in a plan.
+	Note string
+
 	CreateBeforeDestroySet bool
 	PreventDestroySet      bool
 }
@@ -210,6 +225,11 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 				r.Managed.PreventDestroySet = true
 			}
 
+			if attr, exists := lcContent.Attributes["note"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.Managed.Note)
+				diags = append(diags, valDiags...)
+			}
+
 			if attr, exists := lcContent.Attributes["replace_triggered_by"]; exists {
 				exprs, hclDiags := decodeReplaceTriggeredBy(attr.Expr)
 				diags = diags.Extend(hclDiags)
@@ -217,6 +237,30 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 				r.TriggersReplacement = append(r.TriggersReplacement, exprs...)
 			}
 
+			if attr, exists := lcContent.Attributes["no_cache"]; exists {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid resource lifecycle argument",
+					Detail:   `The lifecycle argument "no_cache" is defined only for data resources ("data" blocks), and is not valid for managed resources.`,
+					Subject:  attr.NameRange.Ptr(),
+				})
+			}
+
+			if attr, exists := lcContent.Attributes["groups"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.Managed.Groups)
+				diags = append(diags, valDiags...)
+				for _, group := range r.Managed.Groups {
+					if !hclsyntax.ValidIdentifier(group) {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Invalid resource group name",
+							Detail:   fmt.Sprintf("The group name %q is not valid. %s", group, badIdentifierDetail),
+							Subject:  attr.Expr.Range().Ptr(),
+						})
+					}
+				}
+			}
+
 			if attr, exists := lcContent.Attributes["ignore_changes"]; exists {
 
 				// ignore_changes can either be a list of relative traversals
@@ -498,10 +542,15 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 			lcContent, lcDiags := block.Body.Content(resourceLifecycleBlockSchema)
 			diags = append(diags, lcDiags...)
 
-			// All of the attributes defined for resource lifecycle are for
-			// managed resources only, so we can emit a common error message
-			// for any given attributes that HCL accepted.
+			// Except for no_cache, all of the attributes defined for resource
+			// lifecycle are for managed resources only, so we can emit a
+			// common error message for any given attributes that HCL accepted.
 			for name, attr := range lcContent.Attributes {
+				if name == "no_cache" {
+					valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.NoCache)
+					diags = append(diags, valDiags...)
+					continue
+				}
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid data resource lifecycle argument",
@@ -547,6 +596,115 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 	return r, diags
 }
 
+// decodeEphemeralBlock decodes an "ephemeral" block, which declares an
+// ephemeral resource whose objects OpenTofu opens during each operation that
+// needs them and closes again before the operation ends.
+func decodeEphemeralBlock(block *hcl.Block) (*Resource, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	r := &Resource{
+		Mode:      addrs.EphemeralResourceMode,
+		Type:      block.Labels[0],
+		Name:      block.Labels[1],
+		DeclRange: block.DefRange,
+		TypeRange: block.LabelRanges[0],
+	}
+
+	content, remain, moreDiags := block.Body.PartialContent(ephemeralBlockSchema)
+	diags = append(diags, moreDiags...)
+	r.Config = remain
+
+	if !hclsyntax.ValidIdentifier(r.Type) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid ephemeral resource type name",
+			Detail:   badIdentifierDetail,
+			Subject:  &block.LabelRanges[0],
+		})
+	}
+	if !hclsyntax.ValidIdentifier(r.Name) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid ephemeral resource name",
+			Detail:   badIdentifierDetail,
+			Subject:  &block.LabelRanges[1],
+		})
+	}
+
+	if attr, exists := content.Attributes["count"]; exists {
+		r.Count = attr.Expr
+	}
+
+	if attr, exists := content.Attributes["for_each"]; exists {
+		r.ForEach = attr.Expr
+		// Cannot have count and for_each on the same ephemeral block
+		if r.Count != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  `Invalid combination of "count" and "for_each"`,
+				Detail:   `The "count" and "for_each" meta-arguments are mutually-exclusive, only one should be used to be explicit about the number of resources to be created.`,
+				Subject:  &attr.NameRange,
+			})
+		}
+	}
+
+	if attr, exists := content.Attributes["provider"]; exists {
+		var providerDiags hcl.Diagnostics
+		r.ProviderConfigRef, providerDiags = decodeProviderConfigRef(attr.Expr, "provider")
+		diags = append(diags, providerDiags...)
+	}
+
+	if attr, exists := content.Attributes["depends_on"]; exists {
+		deps, depsDiags := decodeDependsOn(attr)
+		diags = append(diags, depsDiags...)
+		r.DependsOn = append(r.DependsOn, deps...)
+	}
+
+	var seenEscapeBlock *hcl.Block
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "_":
+			if seenEscapeBlock != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate escaping block",
+					Detail: fmt.Sprintf(
+						"The special block type \"_\" can be used to force particular arguments to be interpreted as resource-type-specific rather than as meta-arguments, but each ephemeral block can have only one such block. The first escaping block was at %s.",
+						seenEscapeBlock.DefRange,
+					),
+					Subject: &block.DefRange,
+				})
+				continue
+			}
+			seenEscapeBlock = block
+
+			// When there's an escaping block its content merges with the
+			// existing config we extracted earlier, so later decoding
+			// will see a blend of both.
+			r.Config = hcl.MergeBodies([]hcl.Body{r.Config, block.Body})
+
+		case "lifecycle":
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported lifecycle block",
+				Detail:   "Ephemeral resources don't support lifecycle customizations, because OpenTofu opens their objects again in each operation.",
+				Subject:  block.DefRange.Ptr(),
+			})
+
+		default:
+			// Any other block types are ones we're reserving for future use,
+			// but don't have any defined meaning today.
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reserved block type name in ephemeral block",
+				Detail:   fmt.Sprintf("The block type name %q is reserved for use by OpenTofu in a future version.", block.Type),
+				Subject:  block.TypeRange.Ptr(),
+			})
+		}
+	}
+
+	return r, diags
+}
+
 // decodeReplaceTriggeredBy decodes and does basic validation of the
 // replace_triggered_by expressions, ensuring they only contains references to
 // a single resource, and the only extra variables are count.index or each.key.
@@ -676,6 +834,14 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 	)
 	var maxTraversalLength = keyIndex + 1
 
+	// In the JSON syntax the reference is given as a string, which can only
+	// be interpreted as a static traversal. We parse it as a native syntax
+	// expression instead so that references with a dynamic instance key,
+	// like "aws.by_region[each.key]", are possible in both syntaxes.
+	if hcljson.IsJSONExpression(expr) {
+		expr = jsonProviderConfigRefExpr(expr)
+	}
+
 	// name.alias[expr_key]
 	if iex, ok := expr.(*hclsyntax.IndexExpr); ok {
 		maxTraversalLength = aliasIndex + 1 // expr key found, no const key allowed
@@ -709,6 +875,7 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 				Summary:  "Invalid provider configuration reference",
 				Detail:   "A provider configuration reference must not be given in quotes.",
 				Subject:  expr.Range().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidProviderReference, nil),
 			})
 			return nil, diags
 		}
@@ -718,6 +885,7 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 			Summary:  "Invalid provider configuration reference",
 			Detail:   fmt.Sprintf("The %s argument requires a provider type name, optionally followed by a period and then a configuration alias and optional instance key.", argName),
 			Subject:  expr.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidProviderReference, nil),
 		})
 		return nil, diags
 	}
@@ -744,6 +912,7 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 				Summary:  "Invalid provider configuration reference",
 				Detail:   "Provider name must either stand alone or be followed by a period and then a configuration alias.",
 				Subject:  traversal[aliasIndex].SourceRange().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidProviderReference, nil),
 			})
 			return ret, diags
 		}
@@ -760,6 +929,7 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 				Summary:  "Invalid provider configuration reference",
 				Detail:   "Provider name must either stand alone or be followed by a period and then a configuration alias.",
 				Subject:  traversal[keyIndex].SourceRange().Ptr(),
+				Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidProviderReference, nil),
 			})
 			return ret, diags
 		}
@@ -773,12 +943,46 @@ func decodeProviderConfigRef(expr hcl.Expression, argName string) (*ProviderConf
 			Summary:  "Invalid provider configuration reference",
 			Detail:   "Provider assignment requires an alias when specifying an instance key, in the form of provider.name[instance_key]",
 			Subject:  traversal.SourceRange().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidProviderReference, nil),
 		})
 	}
 
 	return ret, diags
 }
 
+// jsonProviderConfigRefExpr returns the native syntax expression within the
+// given JSON string expression, which may optionally be written as a
+// template with a single interpolation, like "${aws.foo}". If the string
+// isn't a valid expression then the given expression is returned unchanged,
+// leaving the caller to report a suitable error.
+func jsonProviderConfigRefExpr(expr hcl.Expression) hcl.Expression {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.String || !val.IsKnown() || val.IsNull() {
+		return expr
+	}
+	src := val.AsString()
+
+	// As in shimTraversalInString, this doesn't account for any escape
+	// sequences, but is close enough for error reporting.
+	startPos := expr.Range().Start
+	startPos.Column++ // skip initial quote
+	startPos.Byte++   // skip initial quote
+
+	if strings.HasPrefix(src, "${") {
+		converted, diags := hclsyntax.ParseTemplate([]byte(src), expr.Range().Filename, startPos)
+		if wrap, ok := converted.(*hclsyntax.TemplateWrapExpr); ok && !diags.HasErrors() {
+			return wrap.Wrapped
+		}
+		return expr
+	}
+
+	converted, diags := hclsyntax.ParseExpression([]byte(src), expr.Range().Filename, startPos)
+	if diags.HasErrors() {
+		return expr
+	}
+	return converted
+}
+
 // Addr returns the provider config address corresponding to the receiving
 // config reference.
 //
@@ -874,6 +1078,15 @@ var dataBlockSchema = &hcl.BodySchema{
 	},
 }
 
+var ephemeralBlockSchema = &hcl.BodySchema{
+	Attributes: commonResourceAttributes,
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "lifecycle"},
+		{Type: "locals"}, // reserved for future use
+		{Type: "_"},      // meta-argument escaping block
+	},
+}
+
 var resourceLifecycleBlockSchema = &hcl.BodySchema{
 	// We tell HCL that these elements are all valid for both "resource"
 	// and "data" lifecycle blocks, but the rules are actually more restrictive
@@ -892,6 +1105,15 @@ var resourceLifecycleBlockSchema = &hcl.BodySchema{
 		{
 			Name: "replace_triggered_by",
 		},
+		{
+			Name: "groups",
+		},
+		{
+			Name: "note",
+		},
+		{
+			Name: "no_cache",
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "precondition"},
@@ -0,0 +1,102 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+)
+
+func TestDecodeProviderConfigRef_json(t *testing.T) {
+	tests := map[string]struct {
+		src       string
+		wantName  string
+		wantAlias string
+		wantKey   string
+		wantErr   bool
+	}{
+		"name only": {
+			src:      `"aws"`,
+			wantName: "aws",
+		},
+		"alias": {
+			src:       `"aws.west"`,
+			wantName:  "aws",
+			wantAlias: "west",
+		},
+		"constant key": {
+			src:       `"aws.west[\"a\"]"`,
+			wantName:  "aws",
+			wantAlias: "west",
+			wantKey:   `"a"`,
+		},
+		"dynamic key": {
+			src:       `"aws.by_region[each.key]"`,
+			wantName:  "aws",
+			wantAlias: "by_region",
+			wantKey:   "each.key",
+		},
+		"template with dynamic key": {
+			src:       `"${aws.by_region[each.key]}"`,
+			wantName:  "aws",
+			wantAlias: "by_region",
+			wantKey:   "each.key",
+		},
+		"too many steps": {
+			src:     `"aws.by_region.extra[each.key]"`,
+			wantErr: true,
+		},
+		"not an expression": {
+			src:     `"aws by region"`,
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, diags := hcljson.ParseExpression([]byte(test.src), "test.tf.json")
+			if diags.HasErrors() {
+				t.Fatalf("invalid test expression: %s", diags.Error())
+			}
+
+			ref, diags := decodeProviderConfigRef(expr, "provider")
+			if test.wantErr {
+				if !diags.HasErrors() {
+					t.Fatalf("unexpected success")
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+
+			if ref.Name != test.wantName {
+				t.Errorf("wrong name %q; want %q", ref.Name, test.wantName)
+			}
+			if ref.Alias != test.wantAlias {
+				t.Errorf("wrong alias %q; want %q", ref.Alias, test.wantAlias)
+			}
+			gotKey := ""
+			if ref.KeyExpression != nil {
+				if val, diags := ref.KeyExpression.Value(nil); !diags.HasErrors() {
+					gotKey = fmt.Sprintf("%q", val.AsString())
+				} else {
+					traversal, diags := hcl.AbsTraversalForExpr(ref.KeyExpression)
+					if diags.HasErrors() {
+						t.Fatalf("key is neither a constant nor a traversal: %s", diags.Error())
+					}
+					gotKey = traversal.RootName() + "." + traversal[1].(hcl.TraverseAttr).Name
+				}
+			}
+			if gotKey != test.wantKey {
+				t.Errorf("wrong key %q; want %q", gotKey, test.wantKey)
+			}
+		})
+	}
+}
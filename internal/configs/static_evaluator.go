@@ -14,7 +14,9 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 // StaticIdentifier holds a Referenceable item and where it was declared
@@ -34,12 +36,17 @@ func (ref StaticIdentifier) String() string {
 
 type StaticModuleVariables func(v *Variable) (cty.Value, hcl.Diagnostics)
 
+// StaticProviderFunctions returns the given function of the given provider,
+// for calling it in a static context.
+type StaticProviderFunctions func(provider addrs.Provider, name string, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics)
+
 // StaticModuleCall contains the information required to call a given module
 type StaticModuleCall struct {
 	addr      addrs.Module
 	vars      StaticModuleVariables
 	rootPath  string
 	workspace string
+	funcs     StaticProviderFunctions
 }
 
 func NewStaticModuleCall(addr addrs.Module, vars StaticModuleVariables, rootPath string, workspace string) StaticModuleCall {
@@ -57,9 +64,18 @@ func (s StaticModuleCall) WithVariables(vars StaticModuleVariables) StaticModule
 		vars:      vars,
 		rootPath:  s.rootPath,
 		workspace: s.workspace,
+		funcs:     s.funcs,
 	}
 }
 
+// WithProviderFunctions returns a copy of the module call that allows provider
+// functions to be used in static expressions, calling them with funcs. Without
+// it, provider functions are not allowed in a static context.
+func (s StaticModuleCall) WithProviderFunctions(funcs StaticProviderFunctions) StaticModuleCall {
+	s.funcs = funcs
+	return s
+}
+
 // only used in testing
 func RootModuleCallForTesting() StaticModuleCall {
 	return NewStaticModuleCall(addrs.RootModule, func(_ *Variable) (cty.Value, hcl.Diagnostics) {
@@ -11,8 +11,14 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+
+	"github.com/opentofu/opentofu/version"
 )
 
 // This exercises most of the logic in StaticEvaluator and staticScopeData
@@ -79,6 +85,10 @@ locals {
 	func = md5("my-string")
 	missing_func = missing_fn("my-string")
 	provider_func = provider::type::fn("my-string")
+
+	# OpenTofu version
+	tofu_version = tofu.version
+	run_id = tofu.run_id
 }
 
 resource "foo" "bar" {}
@@ -284,6 +294,29 @@ resource "foo" "bar" {}
 		}
 	})
 
+	t.Run("Version", func(t *testing.T) {
+		mod, _ := NewModule([]*File{file}, nil, RootModuleCallForTesting(), "dir", SelectiveLoadAll)
+		eval := NewStaticEvaluator(mod, RootModuleCallForTesting())
+
+		value, diags := eval.Evaluate(mod.Locals["tofu_version"].Expr, dummyIdentifier)
+		if diags.HasErrors() {
+			t.Error(diags)
+		}
+		if value.AsString() != version.String() {
+			t.Errorf("Expected %s got %s", version.String(), value.AsString())
+		}
+	})
+
+	t.Run("RunID", func(t *testing.T) {
+		mod, _ := NewModule([]*File{file}, nil, RootModuleCallForTesting(), "dir", SelectiveLoadAll)
+		eval := NewStaticEvaluator(mod, RootModuleCallForTesting())
+
+		_, diags := eval.Evaluate(mod.Locals["run_id"].Expr, dummyIdentifier)
+		assertExactDiagnostics(t, diags, []string{
+			`eval.tf:65,11-22: Dynamic value in static context; Unable to use tofu.run_id in static context, because it has a different value in each run of OpenTofu.`,
+		})
+	})
+
 	t.Run("Functions", func(t *testing.T) {
 		mod, _ := NewModule([]*File{file}, nil, RootModuleCallForTesting(), "dir", SelectiveLoadAll)
 		eval := NewStaticEvaluator(mod, RootModuleCallForTesting())
@@ -417,3 +450,66 @@ terraform {
 		})
 	}
 }
+
+func TestStaticEvaluator_providerFunctions(t *testing.T) {
+	parser := testParser(map[string]string{"eval.tf": `
+terraform {
+	required_providers {
+		type = {
+			source = "example/type"
+		}
+	}
+}
+
+locals {
+	provider_func = provider::type::fn("my-string")
+	unknown_provider = provider::other::fn("my-string")
+}
+
+module "child" {
+	source = "./child-${local.provider_func}"
+}
+`})
+	file, fileDiags := parser.LoadConfigFile("eval.tf")
+	if fileDiags.HasErrors() {
+		t.Fatal(fileDiags)
+	}
+
+	var calledProvider addrs.Provider
+	call := RootModuleCallForTesting().WithProviderFunctions(func(provider addrs.Provider, name string, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+		calledProvider = provider
+		fn := stdlib.UpperFunc
+		return &fn, nil
+	})
+	mod, diags := NewModule([]*File{file}, nil, call, "dir", SelectiveLoadAll)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	eval := NewStaticEvaluator(mod, call)
+
+	local := mod.Locals["provider_func"]
+	value, diags := eval.Evaluate(local.Expr, StaticIdentifier{Subject: "local.provider_func", DeclRange: local.DeclRange})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	if value.AsString() != "MY-STRING" {
+		t.Errorf("Expected %s got %s", "MY-STRING", value.AsString())
+	}
+	if want := addrs.MustParseProviderSourceString("example/type"); calledProvider != want {
+		t.Errorf("Expected provider %s got %s", want, calledProvider)
+	}
+
+	local = mod.Locals["unknown_provider"]
+	_, diags = eval.Evaluate(local.Expr, StaticIdentifier{Subject: "local.unknown_provider", DeclRange: local.DeclRange})
+	assertExactDiagnostics(t, diags, []string{`eval.tf:12,21-40: Unknown function provider; Provider "other" does not exist within the required_providers of this module`})
+
+	// The module source is static too, and the module call passes the
+	// provider functions on to the child module.
+	mc := mod.ModuleCalls["child"]
+	if mc.SourceAddrRaw != "./child-MY-STRING" {
+		t.Errorf("Expected source %s got %s", "./child-MY-STRING", mc.SourceAddrRaw)
+	}
+	if mc.ProviderFunctions == nil {
+		t.Error("module call has no provider functions")
+	}
+}
@@ -13,23 +13,48 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/didyoumean"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/version"
 )
 
 // newStaticScope creates a lang.Scope that's backed by the static view of the module represented by the StaticEvaluator
 func newStaticScope(eval *StaticEvaluator, stack0 StaticIdentifier, stack ...StaticIdentifier) *lang.Scope {
-	return &lang.Scope{
+	scope := &lang.Scope{
 		Data:        staticScopeData{eval, append([]StaticIdentifier{stack0}, stack...)},
 		ParseRef:    addrs.ParseRef,
 		BaseDir:     ".", // Always current working directory for now. (same as Evaluator.Scope())
 		PureOnly:    false,
 		ConsoleMode: false,
 	}
+	if eval.call.funcs != nil {
+		scope.ProviderFunctions = eval.staticProviderFunction
+	}
+	return scope
+}
+
+// staticProviderFunction resolves a provider function referenced in a static
+// expression, using the module's required_providers to find the provider.
+func (s *StaticEvaluator) staticProviderFunction(pf addrs.ProviderFunction, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var pr *RequiredProvider
+	if s.cfg.ProviderRequirements != nil {
+		pr = s.cfg.ProviderRequirements.RequiredProviders[pf.ProviderName]
+	}
+	if pr == nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown function provider",
+			Detail:   fmt.Sprintf("Provider %q does not exist within the required_providers of this module", pf.ProviderName),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+	return s.call.funcs(pr.Type, pf.Function, rng)
 }
 
 // This structure represents the data required to evaluate a specific identifier reference (top of the stack)
@@ -95,6 +120,9 @@ func (s staticScopeData) StaticValidateReferences(refs []*addrs.Reference, _ add
 				Subject:  ref.SourceRange.ToHCL().Ptr(),
 			})
 		case addrs.ProviderFunction:
+			if s.eval.call.funcs != nil {
+				continue
+			}
 			diags = diags.Append(&hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Provider function in static context",
@@ -215,6 +243,29 @@ func (s staticScopeData) GetTerraformAttr(addr addrs.TerraformAttr, rng tfdiags.
 		workspaceName := s.eval.call.workspace
 		return cty.StringVal(workspaceName), diags
 
+	case "version":
+		if addr.Alias != addrs.IdentTofu {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  `Invalid "terraform" attribute`,
+				Detail:   `The terraform.version attribute is only available as tofu.version.`,
+				Subject:  rng.ToHCL().Ptr(),
+			})
+			return cty.DynamicVal, diags
+		}
+		return cty.StringVal(version.String()), diags
+
+	case "run_id":
+		// The run ID is different for each command, so a value that depends
+		// on it would change between init and the commands that follow it.
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Dynamic value in static context",
+			Detail:   fmt.Sprintf("Unable to use %s in static context, because it has a different value in each run of OpenTofu.", addr.String()),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+		return cty.DynamicVal, diags
+
 	case "env":
 		// Prior to Terraform 0.12 there was an attribute "env", which was
 		// an alias name for "workspace". This was deprecated and is now
@@ -248,6 +299,7 @@ func (s staticScopeData) GetInputVariable(ident addrs.InputVariable, rng tfdiags
 			Summary:  "Undefined variable",
 			Detail:   fmt.Sprintf("Undefined variable %s", ident.String()),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndefinedVariable, nil),
 		})
 	}
 
@@ -325,6 +377,7 @@ func (s staticScopeData) GetInputVariable(ident addrs.InputVariable, rng tfdiags
 				"The given value is not suitable for %s declared at %s: %s.",
 				id.Subject, id.DeclRange, err,
 			),
+			Extra: tfdiags.RuleExtra(tfdiags.RuleInvalidVariableValue, nil),
 		})
 		// We'll return a placeholder unknown value to avoid producing
 		// redundant downstream errors.
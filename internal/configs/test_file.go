@@ -7,10 +7,14 @@ package configs
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -75,6 +79,11 @@ type TestFile struct {
 	// with Providers map to use later when instantiating provider instance.
 	MockProviders map[string]*MockProvider
 
+	// KeepOnFailure tells the test command to leave any infrastructure
+	// created by this file in place, instead of destroying it, when the file
+	// does not pass. Individual run blocks can override this setting.
+	KeepOnFailure bool
+
 	VariablesDeclRange hcl.Range
 }
 
@@ -184,11 +193,58 @@ type TestRun struct {
 	// Underlying modules shouldn't be called.
 	OverrideModules []*OverrideModule
 
+	// KeepOnFailure overrides the file level keep_on_failure setting for the
+	// state most recently updated by this run block. It is nil when the run
+	// block doesn't set it, in which case the file level setting applies.
+	KeepOnFailure *bool
+
+	// Parallel is true if the run block may execute at the same time as
+	// other parallel run blocks, instead of waiting for all the run blocks
+	// before it to complete.
+	//
+	// A parallel run block still waits for the closest run block before it
+	// that isn't parallel, for the run blocks in DependsOn and those it
+	// refers to, and for earlier run blocks that would otherwise use the
+	// same state at the same time.
+	Parallel bool
+
+	// DependsOn are references to earlier run blocks that a parallel run
+	// block must wait for, in addition to the ones it waits for implicitly.
+	DependsOn []hcl.Traversal
+
+	// Matrix contains the values of the variables in the matrix block of the
+	// run block that this run was generated from. It is nil when the run
+	// block doesn't have a matrix block.
+	//
+	// A run block with a matrix block generates a run for each combination
+	// of the values of its matrix variables, named after the run block and
+	// the values, and the values are also included in Variables.
+	Matrix map[string]cty.Value
+
 	NameDeclRange      hcl.Range
 	VariablesDeclRange hcl.Range
+	MatrixDeclRange    hcl.Range
 	DeclRange          hcl.Range
 }
 
+// BlockName returns the name of the run block that this run was generated
+// from, which is the same as Name unless the run block has a matrix block.
+func (run *TestRun) BlockName() string {
+	// Run block names are identifiers, so they can't contain the bracket
+	// that starts the matrix values in the name of a generated run.
+	name, _, _ := strings.Cut(run.Name, "[")
+	return name
+}
+
+// ShouldKeepOnFailure returns true if the state most recently updated by this
+// run block should be kept, rather than destroyed, when the given file fails.
+func (run *TestRun) ShouldKeepOnFailure(file *TestFile) bool {
+	if run.KeepOnFailure != nil {
+		return *run.KeepOnFailure
+	}
+	return file != nil && file.KeepOnFailure
+}
+
 // Validate does a very simple and cursory check across the run block to look
 // for simple issues we can highlight early on.
 func (run *TestRun) Validate() tfdiags.Diagnostics {
@@ -356,6 +412,22 @@ func (p *MockProvider) validateMockResources() hcl.Diagnostics {
 		resources[res.Type] = struct{}{}
 	}
 
+	// A mock provider either uses the schema of the real provider or the
+	// schemas of its mock resources, so if any mock resource declares a
+	// schema then all of them must.
+	if MockResourcesHaveSchemas(p.MockResources) {
+		for _, res := range p.MockResources {
+			if res.Schema == nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Missing mock resource schema",
+					Detail:   fmt.Sprintf("`%v.%v` must declare a schema, because other mock resources in this `mock_provider` block do.", res.getBlockName(), res.Type),
+					Subject:  p.DeclRange.Ptr(),
+				})
+			}
+		}
+	}
+
 	return diags
 }
 
@@ -394,6 +466,23 @@ type MockResource struct {
 	Mode     addrs.ResourceMode
 	Type     string
 	Defaults map[string]cty.Value
+
+	// Schema is the type of each attribute of the resource type, and is set
+	// only if the mock resource declares its own schema. Mock providers whose
+	// mock resources declare schemas don't use the real provider at all.
+	Schema map[string]cty.Type
+}
+
+// MockResourcesHaveSchemas returns true if the given mock resources declare
+// their own schemas, in which case the mock provider they belong to doesn't
+// need the real provider.
+func MockResourcesHaveSchemas(resources []*MockResource) bool {
+	for _, res := range resources {
+		if res.Schema != nil {
+			return true
+		}
+	}
+	return false
 }
 
 func (r MockResource) getBlockName() string {
@@ -423,10 +512,10 @@ func loadTestFile(body hcl.Body) (*TestFile, hcl.Diagnostics) {
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case "run":
-			run, runDiags := decodeTestRunBlock(block)
+			runs, runDiags := decodeTestRunBlock(block)
 			diags = append(diags, runDiags...)
 			if !runDiags.HasErrors() {
-				tf.Runs = append(tf.Runs, run)
+				tf.Runs = append(tf.Runs, runs...)
 			}
 
 		case "variables":
@@ -491,12 +580,113 @@ func loadTestFile(body hcl.Body) (*TestFile, hcl.Diagnostics) {
 		}
 	}
 
+	if attr, exists := content.Attributes["keep_on_failure"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &tf.KeepOnFailure)...)
+	}
+
+	diags = append(diags, checkTestRunDependencies(tf.Runs)...)
+
 	return &tf, diags
 }
 
-func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
+// checkTestRunDependencies checks that the depends_on argument of each of the
+// given runs only refers to run blocks declared before it, which also makes
+// sure that the dependencies between run blocks can't form a cycle.
+func checkTestRunDependencies(runs []*TestRun) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
+	earlier := make(map[string]bool)
+	for i, run := range runs {
+		if i > 0 && runs[i-1].BlockName() != run.BlockName() {
+			earlier[runs[i-1].BlockName()] = true
+		}
+
+		for _, traversal := range run.DependsOn {
+			name, ok := testRunReferenceName(traversal)
+			if !ok || len(traversal) != 2 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid `depends_on` reference",
+					Detail:   "The depends_on argument of a run block can only refer to other run blocks, like run.setup.",
+					Subject:  traversal.SourceRange().Ptr(),
+				})
+				continue
+			}
+			if !earlier[name] {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid `depends_on` reference",
+					Detail:   fmt.Sprintf("There is no run block named %q before this run block. A run block can only depend on the run blocks declared before it in the same file.", name),
+					Subject:  traversal.SourceRange().Ptr(),
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+// testRunReferenceName returns the name of the run block that the given
+// traversal refers to, if it starts with run.<name>.
+func testRunReferenceName(traversal hcl.Traversal) (string, bool) {
+	if len(traversal) < 2 || traversal.RootName() != "run" {
+		return "", false
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	return attr.Name, true
+}
+
+// DependsOnNames returns the names of the run blocks in the run's depends_on
+// argument.
+func (run *TestRun) DependsOnNames() []string {
+	var names []string
+	for _, traversal := range run.DependsOn {
+		if name, ok := testRunReferenceName(traversal); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// References returns the names of the earlier run blocks that the run refers
+// to in its variables and assertions, whose outputs it uses.
+func (run *TestRun) References() []string {
+	var exprs []hcl.Expression
+	for _, expr := range run.Variables {
+		exprs = append(exprs, expr)
+	}
+	for _, rule := range run.CheckRules {
+		exprs = append(exprs, rule.Condition, rule.ErrorMessage)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		for _, traversal := range expr.Variables() {
+			name, ok := testRunReferenceName(traversal)
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeTestRunBlock decodes the given run block into the runs that it
+// defines, which is a single run unless the block has a matrix block.
+func decodeTestRunBlock(block *hcl.Block) ([]*TestRun, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	var matrix map[string][]cty.Value
+
 	content, contentDiags := block.Body.Content(testRunBlockSchema)
 	diags = append(diags, contentDiags...)
 
@@ -558,6 +748,22 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 			for _, v := range vars {
 				r.Variables[v.Name] = v.Expr
 			}
+		case "matrix":
+			if matrix != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Multiple \"matrix\" blocks",
+					Detail:   fmt.Sprintf("This run block already has a matrix block defined at %s.", r.MatrixDeclRange),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+
+			r.MatrixDeclRange = block.DefRange
+
+			var matrixDiags hcl.Diagnostics
+			matrix, matrixDiags = decodeTestRunMatrixBlock(block)
+			diags = append(diags, matrixDiags...)
 		case "module":
 			if r.Module != nil {
 				diags = append(diags, &hcl.Diagnostic{
@@ -636,7 +842,148 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 		r.ExpectFailures = failures
 	}
 
-	return &r, diags
+	if attr, exists := content.Attributes["keep_on_failure"]; exists {
+		var keep bool
+		keepDiags := gohcl.DecodeExpression(attr.Expr, nil, &keep)
+		diags = append(diags, keepDiags...)
+		if !keepDiags.HasErrors() {
+			r.KeepOnFailure = &keep
+		}
+	}
+
+	if attr, exists := content.Attributes["parallel"]; exists {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &r.Parallel)...)
+	}
+
+	if attr, exists := content.Attributes["depends_on"]; exists {
+		deps, depsDiags := decodeDependsOn(attr)
+		diags = append(diags, depsDiags...)
+		r.DependsOn = deps
+	}
+
+	if matrix == nil {
+		return []*TestRun{&r}, diags
+	}
+
+	for name := range matrix {
+		if _, exists := r.Variables[name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Conflicting matrix variable",
+				Detail:   fmt.Sprintf("The variable %q is defined in both the variables block and the matrix block of this run block. A variable can only be defined in one of them.", name),
+				Subject:  r.MatrixDeclRange.Ptr(),
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return expandTestRunMatrix(&r, matrix), diags
+}
+
+// decodeTestRunMatrixBlock decodes the given matrix block into the values of
+// each of its variables.
+//
+// The values must be known when the test file is loaded, since they decide
+// which runs the run block generates, so the arguments can't refer to
+// anything.
+func decodeTestRunMatrixBlock(block *hcl.Block) (map[string][]cty.Value, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	attrs, attrsDiags := block.Body.JustAttributes()
+	diags = append(diags, attrsDiags...)
+
+	matrix := make(map[string][]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, valDiags := attr.Expr.Value(nil)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			continue
+		}
+
+		if val.IsNull() || !val.IsWhollyKnown() || !val.CanIterateElements() || val.Type().IsMapType() || val.Type().IsObjectType() || val.LengthInt() == 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid matrix values",
+				Detail:   fmt.Sprintf("The matrix variable %q must be a non-empty list of the values to run the run block with.", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+
+		var values []cty.Value
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			values = append(values, v)
+		}
+		matrix[name] = values
+	}
+
+	if len(attrs) == 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Empty \"matrix\" block",
+			Detail:   "A matrix block must define the values of at least one variable.",
+			Subject:  block.DefRange.Ptr(),
+		})
+	}
+
+	return matrix, diags
+}
+
+// expandTestRunMatrix returns a copy of the given run for each combination of
+// the given matrix values, with the values added to its variables.
+//
+// The runs are ordered by the values of the matrix variables, sorted by name,
+// in the order they were given, with the values of the last variable changing
+// fastest.
+func expandTestRunMatrix(run *TestRun, matrix map[string][]cty.Value) []*TestRun {
+	names := make([]string, 0, len(matrix))
+	for name := range matrix {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	runs := []*TestRun{run}
+	for _, name := range names {
+		var expanded []*TestRun
+		for _, prev := range runs {
+			for _, val := range matrix[name] {
+				next := *prev
+				next.Variables = make(map[string]hcl.Expression, len(prev.Variables)+1)
+				for k, v := range prev.Variables {
+					next.Variables[k] = v
+				}
+				next.Variables[name] = hcl.StaticExpr(val, run.MatrixDeclRange)
+				next.Matrix = make(map[string]cty.Value, len(prev.Matrix)+1)
+				for k, v := range prev.Matrix {
+					next.Matrix[k] = v
+				}
+				next.Matrix[name] = val
+				expanded = append(expanded, &next)
+			}
+		}
+		runs = expanded
+	}
+
+	for _, r := range runs {
+		values := make([]string, len(names))
+		for i, name := range names {
+			values[i] = fmt.Sprintf("%s=%s", name, testRunMatrixValueString(r.Matrix[name]))
+		}
+		r.Name = fmt.Sprintf("%s[%s]", run.Name, strings.Join(values, ", "))
+	}
+	return runs
+}
+
+// testRunMatrixValueString returns the given matrix value as it appears in
+// the name of a generated run, which is its HCL syntax except that strings
+// aren't quoted.
+func testRunMatrixValueString(val cty.Value) string {
+	if val.Type() == cty.String && !val.IsNull() {
+		return val.AsString()
+	}
+	return string(hclwrite.TokensForValue(val).Bytes())
 }
 
 func decodeTestRunModuleBlock(block *hcl.Block) (*TestRunModuleCall, hcl.Diagnostics) {
@@ -969,9 +1316,59 @@ func decodeMockResourceBlock(block *hcl.Block) (*MockResource, hcl.Diagnostics)
 		res.Defaults, diags = v, append(diags, moreDiags...)
 	}
 
+	if attr, exists := content.Attributes["schema"]; exists {
+		schema, moreDiags := decodeMockResourceSchema(attr)
+		res.Schema, diags = schema, append(diags, moreDiags...)
+
+		if !moreDiags.HasErrors() {
+			for name := range res.Defaults {
+				if _, ok := schema[name]; !ok {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid mock resource default",
+						Detail:   fmt.Sprintf("The default value for `%v` can't be used, because the schema of `%v.%v` doesn't declare the attribute.", name, block.Type, res.Type),
+						Subject:  content.Attributes["defaults"].Range.Ptr(),
+					})
+				}
+			}
+		}
+	}
+
 	return res, diags
 }
 
+// decodeMockResourceSchema decodes the schema attribute of a mock resource,
+// which is an object whose attributes are type expressions, like
+// `{ id = string, tags = map(string) }`.
+func decodeMockResourceSchema(attr *hcl.Attribute) (map[string]cty.Type, hcl.Diagnostics) {
+	pairs, diags := hcl.ExprMap(attr.Expr)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema := make(map[string]cty.Type, len(pairs))
+	for _, pair := range pairs {
+		name := hcl.ExprAsKeyword(pair.Key)
+		if name == "" || !hclsyntax.ValidIdentifier(name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid mock resource schema",
+				Detail:   "The keys of `schema` must be attribute names.",
+				Subject:  pair.Key.Range().Ptr(),
+			})
+			continue
+		}
+
+		ty, tyDiags := typeexpr.Type(pair.Value)
+		diags = append(diags, tyDiags...)
+		if !tyDiags.HasErrors() {
+			schema[name] = ty
+		}
+	}
+
+	return schema, diags
+}
+
 func parseObjectAttrWithNoVariables(attr *hcl.Attribute) (map[string]cty.Value, hcl.Diagnostics) {
 	attrVal, valDiags := attr.Expr.Value(nil)
 	diags := valDiags
@@ -1039,6 +1436,10 @@ func checkForDuplicatedOverrideModules(modules []*OverrideModule) hcl.Diagnostic
 
 // testFileSchema defines the structure of test file configuration for tofu tests.
 var testFileSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		// keep_on_failure leaves the infrastructure created by a failed file in place.
+		{Name: "keep_on_failure"},
+	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
 			// run block defines the steps to execute during a test run.
@@ -1080,6 +1481,12 @@ var testRunBlockSchema = &hcl.BodySchema{
 		{Name: "providers"},
 		// expect_failures indicates whether test failures are expected.
 		{Name: "expect_failures"},
+		// keep_on_failure overrides the file level keep_on_failure setting.
+		{Name: "keep_on_failure"},
+		// parallel allows the run block to execute at the same time as others.
+		{Name: "parallel"},
+		// depends_on lists earlier run blocks that must complete first.
+		{Name: "depends_on"},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -1094,6 +1501,10 @@ var testRunBlockSchema = &hcl.BodySchema{
 			// variables block provides input variables to be used during the test.
 			Type: "variables",
 		},
+		{
+			// matrix block generates a run for each combination of its variable values.
+			Type: "matrix",
+		},
 		{
 			// module block specifies the module to be tested.
 			Type: "module",
@@ -1196,5 +1607,8 @@ var mockResourceBlockSchema = &hcl.BodySchema{
 		{
 			Name: "defaults",
 		},
+		{
+			Name: "schema",
+		},
 	},
 }
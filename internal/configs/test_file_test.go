@@ -6,11 +6,14 @@
 package configs
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestTestRun_Validate(t *testing.T) {
@@ -97,3 +100,366 @@ func parseTraversal(t *testing.T, addr string) hcl.Traversal {
 	}
 	return traversal
 }
+
+func TestLoadTestFile_keepOnFailure(t *testing.T) {
+	src := `
+keep_on_failure = true
+
+run "inherits" {}
+
+run "overrides" {
+  keep_on_failure = false
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	file, diags := loadTestFile(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if !file.KeepOnFailure {
+		t.Errorf("expected file level keep_on_failure to be true")
+	}
+	if len(file.Runs) != 2 {
+		t.Fatalf("expected 2 run blocks, got %d", len(file.Runs))
+	}
+	if file.Runs[0].KeepOnFailure != nil {
+		t.Errorf("expected first run block to inherit keep_on_failure")
+	}
+	if !file.Runs[0].ShouldKeepOnFailure(file) {
+		t.Errorf("expected first run block to keep state on failure")
+	}
+	if file.Runs[1].ShouldKeepOnFailure(file) {
+		t.Errorf("expected second run block to destroy state on failure")
+	}
+}
+
+func TestLoadTestFile_matrix(t *testing.T) {
+	src := `
+run "single" {}
+
+run "combinations" {
+  variables {
+    input = "fixed"
+  }
+
+  matrix {
+    size   = [1, 2]
+    region = ["eu", "us"]
+  }
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	file, diags := loadTestFile(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var names []string
+	for _, run := range file.Runs {
+		names = append(names, run.Name)
+	}
+	wantNames := []string{
+		"single",
+		"combinations[region=eu, size=1]",
+		"combinations[region=eu, size=2]",
+		"combinations[region=us, size=1]",
+		"combinations[region=us, size=2]",
+	}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Fatalf("wrong run names\n%s", diff)
+	}
+
+	if file.Runs[0].Matrix != nil {
+		t.Errorf("expected run without a matrix block to have no matrix values")
+	}
+
+	run := file.Runs[3]
+	if got, want := run.BlockName(), "combinations"; got != want {
+		t.Errorf("wrong block name %q; want %q", got, want)
+	}
+	wantMatrix := map[string]cty.Value{
+		"region": cty.StringVal("us"),
+		"size":   cty.NumberIntVal(1),
+	}
+	if diff := cmp.Diff(wantMatrix, run.Matrix, ctydebug.CmpOptions); diff != "" {
+		t.Errorf("wrong matrix values\n%s", diff)
+	}
+	for name, want := range map[string]cty.Value{
+		"input":  cty.StringVal("fixed"),
+		"region": cty.StringVal("us"),
+		"size":   cty.NumberIntVal(1),
+	} {
+		expr, ok := run.Variables[name]
+		if !ok {
+			t.Errorf("missing variable %q", name)
+			continue
+		}
+		got, diags := expr.Value(nil)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		if !got.RawEquals(want) {
+			t.Errorf("wrong value for %q: %#v; want %#v", name, got, want)
+		}
+	}
+}
+
+func TestLoadTestFile_matrixInvalid(t *testing.T) {
+	tcs := map[string]struct {
+		src  string
+		want string
+	}{
+		"empty": {
+			src:  "run \"test\" {\n  matrix {}\n}",
+			want: "Empty \"matrix\" block",
+		},
+		"not a list": {
+			src:  "run \"test\" {\n  matrix { input = \"value\" }\n}",
+			want: "Invalid matrix values",
+		},
+		"empty list": {
+			src:  "run \"test\" {\n  matrix { input = [] }\n}",
+			want: "Invalid matrix values",
+		},
+		"references": {
+			src:  "run \"test\" {\n  matrix { input = var.values }\n}",
+			want: "Variables not allowed",
+		},
+		"conflicting": {
+			src: `
+run "test" {
+  variables {
+    input = "value"
+  }
+  matrix {
+    input = ["a", "b"]
+  }
+}
+`,
+			want: "Conflicting matrix variable",
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(tc.src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			file, diags := loadTestFile(f.Body)
+			if !diags.HasErrors() {
+				t.Fatalf("expected an error")
+			}
+			if got := diags[0].Summary; got != tc.want {
+				t.Errorf("wrong error %q; want %q", got, tc.want)
+			}
+			if len(file.Runs) != 0 {
+				t.Errorf("expected no runs, got %d", len(file.Runs))
+			}
+		})
+	}
+}
+
+func TestLoadTestFile_parallel(t *testing.T) {
+	src := `
+run "setup" {}
+
+run "first" {
+  parallel = true
+}
+
+run "second" {
+  parallel   = true
+  depends_on = [run.setup, run.first]
+
+  variables {
+    id = run.setup.id
+  }
+
+  assert {
+    condition     = run.first.id != ""
+    error_message = "missing id"
+  }
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	file, diags := loadTestFile(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if file.Runs[0].Parallel {
+		t.Errorf("expected setup run block not to be parallel")
+	}
+	if !file.Runs[1].Parallel || !file.Runs[2].Parallel {
+		t.Errorf("expected first and second run blocks to be parallel")
+	}
+	if got := len(file.Runs[2].DependsOn); got != 2 {
+		t.Errorf("expected 2 dependencies, got %d", got)
+	}
+	if diff := cmp.Diff([]string{"first", "setup"}, file.Runs[2].References()); diff != "" {
+		t.Errorf("wrong references\n%s", diff)
+	}
+}
+
+func TestLoadTestFile_parallelInvalid(t *testing.T) {
+	tcs := map[string]string{
+		"later run": `
+run "first" {
+  depends_on = [run.second]
+}
+
+run "second" {}
+`,
+		"itself": `
+run "first" {
+  depends_on = [run.first]
+}
+`,
+		"not a run": `
+run "first" {}
+
+run "second" {
+  depends_on = [var.first]
+}
+`,
+		"run output": `
+run "first" {}
+
+run "second" {
+  depends_on = [run.first.id]
+}
+`,
+	}
+	for name, src := range tcs {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			_, diags = loadTestFile(f.Body)
+			if !diags.HasErrors() {
+				t.Fatalf("expected an error")
+			}
+			if got, want := diags[0].Summary, "Invalid `depends_on` reference"; got != want {
+				t.Errorf("wrong error %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadTestFile_mockResourceSchema(t *testing.T) {
+	src := `
+mock_provider "test" {
+  mock_resource "test_instance" {
+    schema = {
+      id   = string
+      tags = map(string)
+    }
+    defaults = {
+      id = "i-1234"
+    }
+  }
+}
+`
+	f, diags := hclsyntax.ParseConfig([]byte(src), "main.tftest.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	file, diags := loadTestFile(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	res := file.MockProviders["test"].MockResources[0]
+	want := map[string]cty.Type{
+		"id":   cty.String,
+		"tags": cty.Map(cty.String),
+	}
+	if diff := cmp.Diff(want, res.Schema, ctydebug.CmpOptions); diff != "" {
+		t.Errorf("wrong schema\n%s", diff)
+	}
+	if !MockResourcesHaveSchemas(file.MockProviders["test"].MockResources) {
+		t.Errorf("expected the mock resources to have schemas")
+	}
+}
+
+func TestLoadTestFile_mockResourceSchemaInvalid(t *testing.T) {
+	tcs := map[string]struct {
+		src  string
+		want string
+	}{
+		"invalid type": {
+			src: `
+mock_provider "test" {
+  mock_resource "test_instance" {
+    schema = {
+      id = str
+    }
+  }
+}
+`,
+			want: "Invalid type specification",
+		},
+		"undeclared default": {
+			src: `
+mock_provider "test" {
+  mock_resource "test_instance" {
+    schema = {
+      id = string
+    }
+    defaults = {
+      name = "boop"
+    }
+  }
+}
+`,
+			want: "Invalid mock resource default",
+		},
+		"missing schema": {
+			src: `
+mock_provider "test" {
+  mock_resource "test_instance" {
+    schema = {
+      id = string
+    }
+  }
+  mock_data "test_image" {}
+}
+`,
+			want: "Missing mock resource schema",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(tc.src), "main.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			_, diags = loadTestFile(f.Body)
+			if !diags.HasErrors() {
+				t.Fatal("expected errors")
+			}
+			if got := diags.Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong error %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+variable "password" {
+  secret    = true
+  sensitive = false # ERROR: Invalid secret variable
+}
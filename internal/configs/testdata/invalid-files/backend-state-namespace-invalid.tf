@@ -0,0 +1,5 @@
+terraform {
+  backend "example" {
+    state_namespace = "networking.core"
+  }
+}
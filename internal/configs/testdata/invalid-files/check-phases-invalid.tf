@@ -0,0 +1,8 @@
+check "health" {
+  phases = ["refresh"]
+
+  assert {
+    condition     = var.healthy
+    error_message = "The service is unhealthy."
+  }
+}
@@ -0,0 +1,7 @@
+ephemeral "example" "example" {
+  lifecycle {
+    # Ephemeral resources are opened again in each operation, so they
+    # don't support any lifecycle customizations.
+    create_before_destroy = true
+  }
+}
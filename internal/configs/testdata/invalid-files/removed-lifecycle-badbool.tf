@@ -0,0 +1,6 @@
+removed {
+  from = test.foo
+  lifecycle {
+    destroy = "ABSOLUTELY NOT"
+  }
+}
@@ -0,0 +1,8 @@
+terraform {
+  required_tags {
+    resource_types = ["aws_instance"]
+    enforcement    = "block"
+
+    tag "Owner" {}
+  }
+}
@@ -0,0 +1,5 @@
+terraform {
+  required_tags {
+    resource_types = ["aws_instance"]
+  }
+}
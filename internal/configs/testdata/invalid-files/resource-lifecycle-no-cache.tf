@@ -0,0 +1,5 @@
+resource "example" "example" {
+  lifecycle {
+    no_cache = true
+  }
+}
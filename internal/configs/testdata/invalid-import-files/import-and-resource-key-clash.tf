@@ -0,0 +1,15 @@
+
+provider "local" {
+  alias    = "by_region"
+  for_each = toset(["a", "b"])
+}
+
+import {
+  provider = local.by_region["a"]
+  id = "foo/bar"
+  to = local_file.foo_bar
+}
+
+resource "local_file" "foo_bar" {
+  provider = local.by_region["b"]
+}
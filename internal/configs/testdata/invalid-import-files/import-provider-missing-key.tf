@@ -0,0 +1,11 @@
+
+provider "local" {
+  alias    = "by_region"
+  for_each = toset(["a", "b"])
+}
+
+import {
+  provider = local.by_region
+  id = "foo/bar"
+  to = local_file.foo_bar
+}
@@ -0,0 +1,7 @@
+terraform {
+  required_tags {
+    resource_types = ["aws_instance"]
+
+    tag "Owner" {}
+  }
+}
@@ -0,0 +1,13 @@
+check "health" {
+  severity = "error"
+  phases   = ["apply", "check"]
+
+  data "http" "health" {
+    url = "https://example.com/health"
+  }
+
+  assert {
+    condition     = data.http.health.status_code == 200
+    error_message = "The service is unhealthy."
+  }
+}
@@ -0,0 +1,5 @@
+data "example" "example" {
+  lifecycle {
+    no_cache = true
+  }
+}
@@ -0,0 +1,12 @@
+ephemeral "vault_token" "example1" {
+}
+
+ephemeral "vault_token" "example2" {
+  policies = ["read"]
+
+  count    = 2
+  provider = vault.admin
+  depends_on = [
+    ephemeral.vault_token.example1,
+  ]
+}
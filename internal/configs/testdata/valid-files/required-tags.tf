@@ -0,0 +1,19 @@
+terraform {
+  required_tags {
+    resource_types = ["aws_*", "google_compute_instance"]
+    attribute      = "tags_all"
+    enforcement    = "warning"
+
+    tag "Owner" {}
+    tag "Environment" {
+      allowed_values = ["dev", "prod"]
+    }
+  }
+
+  required_tags {
+    resource_types = ["google_*"]
+    attribute      = "labels"
+
+    tag "team" {}
+  }
+}
@@ -0,0 +1,13 @@
+resource "aws_vpc" "main" {
+  lifecycle {
+    groups = ["networking"]
+  }
+}
+
+resource "aws_subnet" "main" {
+  vpc_id = aws_vpc.main.id
+
+  lifecycle {
+    groups = ["networking", "subnets"]
+  }
+}
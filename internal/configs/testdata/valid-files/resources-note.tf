@@ -0,0 +1,5 @@
+resource "aws_instance" "legacy" {
+  lifecycle {
+    note = "pending decommission JIRA-123"
+  }
+}
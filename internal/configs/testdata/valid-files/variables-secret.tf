@@ -0,0 +1,10 @@
+variable "password" {
+  type   = string
+  secret = true
+}
+
+variable "token" {
+  type      = string
+  secret    = true
+  sensitive = true
+}
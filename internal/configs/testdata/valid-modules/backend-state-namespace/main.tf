@@ -0,0 +1,7 @@
+terraform {
+  backend "example" {
+    state_namespace = "networking"
+
+    bucket = "shared"
+  }
+}
@@ -3,3 +3,10 @@
 removed {
   from = test.boop
 }
+
+removed {
+  from = module.b
+  lifecycle {
+    destroy = true
+  }
+}
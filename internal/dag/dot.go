@@ -91,33 +91,74 @@ func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 		graphName = "root"
 	}
 
-	name := v.Name
-	attrs := v.Attrs
-	if v.graphNodeDotter != nil {
-		node := v.graphNodeDotter.DotNode(name, opts)
-		if node == nil {
-			return []byte{}
-		}
-
-		newAttrs := make(map[string]string)
-		for k, v := range attrs {
-			newAttrs[k] = v
-		}
-		for k, v := range node.Attrs {
-			newAttrs[k] = v
-		}
-
-		name = node.Name
-		attrs = newAttrs
+	node := v.dotNode(opts)
+	if node == nil {
+		return []byte{}
 	}
 
-	buf.WriteString(fmt.Sprintf(`"[%s] %s"`, graphName, name))
-	writeAttrs(&buf, attrs)
+	buf.WriteString(fmt.Sprintf(`"[%s] %s"`, graphName, node.Name))
+	writeAttrs(&buf, node.Attrs)
 	buf.WriteByte('\n')
 
 	return buf.Bytes()
 }
 
+// dotNode returns the name and attributes to draw the vertex with, or nil if
+// the vertex asked not to be drawn.
+func (v *marshalVertex) dotNode(opts *DotOpts) *DotNode {
+	if v.graphNodeDotter == nil {
+		return &DotNode{Name: v.Name, Attrs: v.Attrs}
+	}
+
+	node := v.graphNodeDotter.DotNode(v.Name, opts)
+	if node == nil {
+		return nil
+	}
+
+	attrs := make(map[string]string)
+	for k, v := range v.Attrs {
+		attrs[k] = v
+	}
+	for k, v := range node.Attrs {
+		attrs[k] = v
+	}
+	return &DotNode{Name: node.Name, Attrs: attrs}
+}
+
+// drawnNodes returns the vertices of the graph, but not of its subgraphs,
+// that the DOT output would draw, along with the nodes to draw them as.
+// Vertices that aren't GraphNodeDotters aren't drawn.
+func (g *marshalGraph) drawnNodes(opts *DotOpts) ([]*marshalVertex, map[string]*DotNode) {
+	var drawn []*marshalVertex
+	nodes := make(map[string]*DotNode)
+	for _, v := range g.Vertices {
+		if v.graphNodeDotter == nil {
+			continue
+		}
+		if node := v.dotNode(opts); node != nil {
+			drawn = append(drawn, v)
+			nodes[v.ID] = node
+		}
+	}
+	return drawn, nodes
+}
+
+// cycleEdge returns true if both ends of the given edge are in the same
+// cycle.
+func (g *marshalGraph) cycleEdge(e *marshalEdge) bool {
+	for _, c := range g.Cycles {
+		var source, target bool
+		for _, v := range c {
+			source = source || v.ID == e.Source
+			target = target || v.ID == e.Target
+		}
+		if source && target {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *marshalEdge) dot(g *marshalGraph) string {
 	var buf bytes.Buffer
 	graphName := g.Name
@@ -42,3 +42,90 @@ func (v *testDotVertex) DotNode(title string, opts *DotOpts) *DotNode {
 	v.DotNodeOpts = opts
 	return v.DotNodeReturn
 }
+
+func TestGraphMermaid(t *testing.T) {
+	var g AcyclicGraph
+	a := &testNamedDotVertex{"a", &DotNode{Name: "a", Attrs: map[string]string{"label": `a "quoted"`}}}
+	b := &testNamedDotVertex{"b", &DotNode{Name: "b", Attrs: map[string]string{"shape": "diamond"}}}
+	hidden := &testNamedDotVertex{"c", nil}
+	g.Add(a)
+	g.Add(b)
+	g.Add(hidden)
+	g.Connect(BasicEdge(a, b))
+	g.Connect(BasicEdge(b, a))
+	g.Connect(BasicEdge(hidden, a))
+
+	got := string(g.Mermaid(&DotOpts{DrawCycles: true}))
+	want := `flowchart TD
+    n0["a #quot;quoted#quot;"]
+    n1{"b"}
+    n0 --> n1
+    n1 --> n0
+    linkStyle 0,1 stroke:red,stroke-width:2px
+`
+	if got != want {
+		t.Fatalf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGraphJSON(t *testing.T) {
+	var g AcyclicGraph
+	a := &testNamedDotVertex{"a", &DotNode{Name: "a", Attrs: map[string]string{"label": "A", "shape": "box"}}}
+	b := &testNamedDotVertex{"b", &DotNode{Name: "b"}}
+	g.Add(a)
+	g.Add(b)
+	g.Connect(BasicEdge(a, b))
+	g.Connect(BasicEdge(b, a))
+
+	got, err := g.JSON(&DotOpts{DrawCycles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "nodes": [
+    {
+      "id": "a",
+      "label": "A",
+      "attributes": {
+        "shape": "box"
+      }
+    },
+    {
+      "id": "b",
+      "label": "b"
+    }
+  ],
+  "edges": [
+    {
+      "source": "a",
+      "target": "b"
+    },
+    {
+      "source": "b",
+      "target": "a"
+    }
+  ],
+  "cycles": [
+    [
+      "a",
+      "b"
+    ]
+  ]
+}`
+	if string(got) != want {
+		t.Fatalf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+type testNamedDotVertex struct {
+	name string
+	node *DotNode
+}
+
+func (v *testNamedDotVertex) Name() string {
+	return v.name
+}
+
+func (v *testNamedDotVertex) DotNode(string, *DotOpts) *DotNode {
+	return v.node
+}
@@ -0,0 +1,103 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package dag
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// jsonGraph is the structure of the JSON representation of a graph.
+type jsonGraph struct {
+	Nodes  []jsonNode `json:"nodes"`
+	Edges  []jsonEdge `json:"edges"`
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+type jsonNode struct {
+	ID         string            `json:"id"`
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type jsonEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// JSON returns a representation of the Graph as a JSON object with lists of
+// its nodes and edges, for tools that analyze the graph.
+//
+// It includes the same nodes as Dot, with their DotNode attributes, but
+// doesn't include subgraphs. Each node is identified by its name. If
+// opts.DrawCycles is set then it also lists the nodes of each cycle.
+func (g *Graph) JSON(opts *DotOpts) ([]byte, error) {
+	return newMarshalGraph("", g).JSON(opts)
+}
+
+func (g *marshalGraph) JSON(opts *DotOpts) ([]byte, error) {
+	if opts == nil {
+		opts = &DotOpts{
+			DrawCycles: true,
+			MaxDepth:   -1,
+			Verbose:    true,
+		}
+	}
+
+	ret := jsonGraph{
+		Nodes: []jsonNode{},
+		Edges: []jsonEdge{},
+	}
+
+	drawn, nodes := g.drawnNodes(opts)
+	ids := make(map[string]string, len(drawn))
+	for _, v := range drawn {
+		node := nodes[v.ID]
+		id := unescapeVertexName(node.Name)
+		ids[v.ID] = id
+
+		attrs := make(map[string]string, len(node.Attrs))
+		for k, v := range node.Attrs {
+			attrs[k] = v
+		}
+		label, ok := attrs["label"]
+		if !ok {
+			label = id
+		}
+		delete(attrs, "label")
+
+		ret.Nodes = append(ret.Nodes, jsonNode{
+			ID:         id,
+			Label:      label,
+			Attributes: attrs,
+		})
+	}
+
+	for _, e := range g.Edges {
+		source, target := ids[e.Source], ids[e.Target]
+		if source == "" || target == "" {
+			continue
+		}
+		ret.Edges = append(ret.Edges, jsonEdge{Source: source, Target: target})
+	}
+
+	if opts.DrawCycles {
+		for _, c := range g.Cycles {
+			var cycle []string
+			for _, v := range c {
+				if id, ok := ids[v.ID]; ok {
+					cycle = append(cycle, id)
+				}
+			}
+			if len(cycle) > 1 {
+				sort.Strings(cycle)
+				ret.Cycles = append(ret.Cycles, cycle)
+			}
+		}
+	}
+
+	return json.MarshalIndent(ret, "", "  ")
+}
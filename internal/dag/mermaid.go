@@ -0,0 +1,85 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package dag
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mermaid returns a representation of the Graph as a Mermaid flowchart, which
+// many Markdown renderers can draw directly.
+//
+// It draws the same nodes as Dot, labelled and shaped by their DotNode
+// attributes, but doesn't draw subgraphs.
+func (g *Graph) Mermaid(opts *DotOpts) []byte {
+	return newMarshalGraph("", g).Mermaid(opts)
+}
+
+func (g *marshalGraph) Mermaid(opts *DotOpts) []byte {
+	if opts == nil {
+		opts = &DotOpts{
+			DrawCycles: true,
+			MaxDepth:   -1,
+			Verbose:    true,
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("flowchart TD\n")
+
+	drawn, nodes := g.drawnNodes(opts)
+	ids := make(map[string]string, len(drawn))
+	for i, v := range drawn {
+		ids[v.ID] = fmt.Sprintf("n%d", i)
+		node := nodes[v.ID]
+		label, ok := node.Attrs["label"]
+		if !ok {
+			label = unescapeVertexName(node.Name)
+		}
+		start, end := `["`, `"]`
+		if node.Attrs["shape"] == "diamond" {
+			start, end = `{"`, `"}`
+		}
+		fmt.Fprintf(&buf, "    %s%s%s%s\n", ids[v.ID], start, mermaidEscape(label), end)
+	}
+
+	var cycleLinks []string
+	link := 0
+	for _, e := range g.Edges {
+		source, target := ids[e.Source], ids[e.Target]
+		if source == "" || target == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "    %s --> %s\n", source, target)
+		if opts.DrawCycles && g.cycleEdge(e) {
+			cycleLinks = append(cycleLinks, strconv.Itoa(link))
+		}
+		link++
+	}
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&buf, "    linkStyle %s stroke:red,stroke-width:2px\n", strings.Join(cycleLinks, ","))
+	}
+
+	return buf.Bytes()
+}
+
+// mermaidEscape escapes the characters that can't appear in a quoted Mermaid
+// label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+// unescapeVertexName reverses the escaping that newMarshalVertex applies to
+// vertex names for the DOT output.
+func unescapeVertexName(name string) string {
+	if unquoted, err := strconv.Unquote(`"` + name + `"`); err == nil {
+		return unquoted
+	}
+	return name
+}
@@ -37,11 +37,10 @@ type Locks struct {
 	// settings, environment variables, or whatever similar sources.
 	overriddenProviders map[addrs.Provider]struct{}
 
-	// TODO: In future we'll also have module locks, but the design of that
-	// still needs some more work and we're deferring that to get the
-	// provider locking capability out sooner, because it's more common to
-	// directly depend on providers maintained outside your organization than
-	// modules maintained outside your organization.
+	// modules records the source, version and content of each remote module
+	// package, keyed by the path of the module call that installed it, using
+	// the same dot-separated form as the module manifest, like "network.vpc".
+	modules map[string]*ModuleLock
 
 	// sources is a copy of the map of source buffers produced by the HCL
 	// parser during loading, which we retain only so that the caller can
@@ -54,6 +53,7 @@ type Locks struct {
 func NewLocks() *Locks {
 	return &Locks{
 		providers: make(map[addrs.Provider]*ProviderLock),
+		modules:   make(map[string]*ModuleLock),
 
 		// no "sources" here, because that's only for locks objects loaded
 		// from files.
@@ -120,6 +120,48 @@ func (l *Locks) RemoveProvider(addr addrs.Provider) {
 	delete(l.providers, addr)
 }
 
+// Module returns the stored lock for the module call at the given path, in
+// the dot-separated form used by the module manifest, or nil if that module
+// currently has no lock.
+func (l *Locks) Module(path string) *ModuleLock {
+	return l.modules[path]
+}
+
+// AllModules returns a map describing all of the module locks in the
+// receiver, keyed by module path.
+func (l *Locks) AllModules() map[string]*ModuleLock {
+	ret := make(map[string]*ModuleLock, len(l.modules))
+	for k, v := range l.modules {
+		ret[k] = v
+	}
+	return ret
+}
+
+// SetModule creates a new lock or replaces the existing lock for the module
+// call at the given path.
+//
+// version is the version selected from a module registry, and is empty for
+// modules that don't come from a registry. commit is the Git commit the
+// package was fetched from, if known. hash is the checksum of the package's
+// content, as returned by getmodules.HashPackageDir.
+func (l *Locks) SetModule(path, source, version, commit string, hash getproviders.Hash) *ModuleLock {
+	new := &ModuleLock{
+		path:    path,
+		source:  source,
+		version: version,
+		commit:  commit,
+		hash:    hash,
+	}
+	l.modules[path] = new
+	return new
+}
+
+// RemoveModule removes any existing lock file entry for the module call at
+// the given path.
+func (l *Locks) RemoveModule(path string) {
+	delete(l.modules, path)
+}
+
 // SetProviderOverridden records that this particular OpenTofu process will
 // not pay attention to the recorded lock entry for the given provider, and
 // will instead access that provider's functionality in some other special
@@ -278,6 +320,21 @@ func (l *Locks) Equal(other *Locks) bool {
 	// We don't need to worry about providers that are in "other" but not
 	// in the receiver, because we tested the lengths being equal above.
 
+	return l.EqualModules(other)
+}
+
+// EqualModules returns true if the given Locks records the same modules as
+// the receiver, disregarding the provider locks.
+func (l *Locks) EqualModules(other *Locks) bool {
+	if len(l.modules) != len(other.modules) {
+		return false
+	}
+	for path, thisLock := range l.modules {
+		otherLock, ok := other.modules[path]
+		if !ok || *thisLock != *otherLock {
+			return false
+		}
+	}
 	return true
 }
 
@@ -303,7 +360,7 @@ func (l *Locks) EqualProviderAddress(other *Locks) bool {
 // UI code might wish to use this to distinguish a lock file being
 // written for the first time from subsequent updates to that lock file.
 func (l *Locks) Empty() bool {
-	return len(l.providers) == 0
+	return len(l.providers) == 0 && len(l.modules) == 0
 }
 
 // DeepCopy creates a new Locks that represents the same information as the
@@ -324,6 +381,10 @@ func (l *Locks) DeepCopy() *Locks {
 		}
 		ret.SetProvider(addr, lock.version, lock.versionConstraints, hashes)
 	}
+	for path, lock := range l.modules {
+		copied := *lock
+		ret.modules[path] = &copied
+	}
 	return ret
 }
 
@@ -442,3 +503,47 @@ func (l *ProviderLock) ContainsAll(target *ProviderLock) bool {
 func (l *ProviderLock) PreferredHashes() []getproviders.Hash {
 	return getproviders.PreferredHashes(l.hashes)
 }
+
+// ModuleLock represents lock information for the remote module package
+// installed for a specific module call.
+//
+// Unlike providers, modules are locked per module call rather than per
+// source address, because each call can select a different version of the
+// same module.
+type ModuleLock struct {
+	path    string
+	source  string
+	version string
+	commit  string
+	hash    getproviders.Hash
+}
+
+// Path returns the path of the module call this lock applies to, in the
+// dot-separated form used by the module manifest.
+func (l *ModuleLock) Path() string {
+	return l.path
+}
+
+// Source returns the source address the module was installed from, as
+// written in the module call after normalization.
+func (l *ModuleLock) Source() string {
+	return l.source
+}
+
+// Version returns the version selected from a module registry, or an empty
+// string if the module doesn't come from a registry.
+func (l *ModuleLock) Version() string {
+	return l.version
+}
+
+// Commit returns the Git commit the module package was fetched from, or an
+// empty string if that isn't known, such as for packages that aren't Git
+// repositories.
+func (l *ModuleLock) Commit() string {
+	return l.commit
+}
+
+// Hash returns the checksum of the content of the module package.
+func (l *ModuleLock) Hash() getproviders.Hash {
+	return l.hash
+}
@@ -8,6 +8,7 @@ package depsfile
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -20,7 +21,6 @@ import (
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/replacefile"
 	"github.com/opentofu/opentofu/internal/tfdiags"
-	"github.com/opentofu/opentofu/version"
 )
 
 // LoadLocksFromFile reads locks from the given file, expecting it to be a
@@ -163,6 +163,27 @@ func SaveLocksToBytes(locks *Locks) ([]byte, tfdiags.Diagnostics) {
 		}
 	}
 
+	modulePaths := make([]string, 0, len(locks.modules))
+	for path := range locks.modules {
+		modulePaths = append(modulePaths, path)
+	}
+	sort.Strings(modulePaths)
+
+	for _, path := range modulePaths {
+		lock := locks.modules[path]
+		rootBody.AppendNewline()
+		block := rootBody.AppendNewBlock("module", []string{lock.path})
+		body := block.Body()
+		body.SetAttributeValue("source", cty.StringVal(lock.source))
+		if lock.version != "" {
+			body.SetAttributeValue("version", cty.StringVal(lock.version))
+		}
+		if lock.commit != "" {
+			body.SetAttributeValue("commit", cty.StringVal(lock.commit))
+		}
+		body.SetAttributeValue("hash", cty.StringVal(lock.hash.String()))
+	}
+
 	return f.Bytes(), diags
 }
 
@@ -175,11 +196,6 @@ func decodeLocksFromHCL(locks *Locks, body hcl.Body) tfdiags.Diagnostics {
 				Type:       "provider",
 				LabelNames: []string{"source_addr"},
 			},
-
-			// "module" is just a placeholder for future enhancement, so we
-			// can mostly-ignore the this block type we intend to add in
-			// future, but warn in case someone tries to use one e.g. if they
-			// downgraded to an earlier version of OpenTofu.
 			{
 				Type:       "module",
 				LabelNames: []string{"path"},
@@ -189,7 +205,7 @@ func decodeLocksFromHCL(locks *Locks, body hcl.Body) tfdiags.Diagnostics {
 	diags = diags.Append(hclDiags)
 
 	seenProviders := make(map[addrs.Provider]hcl.Range)
-	seenModule := false
+	seenModules := make(map[string]hcl.Range)
 	for _, block := range content.Blocks {
 
 		switch block.Type {
@@ -212,19 +228,22 @@ func decodeLocksFromHCL(locks *Locks, body hcl.Body) tfdiags.Diagnostics {
 			seenProviders[lock.addr] = block.DefRange
 
 		case "module":
-			// We'll just take the first module block to use for a single warning,
-			// because that's sufficient to get the point across without swamping
-			// the output with warning noise.
-			if !seenModule {
-				currentVersion := version.SemVer.String()
+			lock, moreDiags := decodeModuleLockFromHCL(block)
+			diags = diags.Append(moreDiags)
+			if lock == nil {
+				continue
+			}
+			if previousRng, exists := seenModules[lock.path]; exists {
 				diags = diags.Append(&hcl.Diagnostic{
-					Severity: hcl.DiagWarning,
-					Summary:  "Dependency locks for modules are not yet supported",
-					Detail:   fmt.Sprintf("OpenTofu v%s only supports dependency locks for providers, not for modules. This configuration may be intended for a later version of OpenTofu that also supports dependency locks for modules.", currentVersion),
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate module lock",
+					Detail:   fmt.Sprintf("This lockfile already declared a lock for module %s at %s.", lock.path, previousRng.String()),
 					Subject:  block.TypeRange.Ptr(),
 				})
-				seenModule = true
+				continue
 			}
+			locks.modules[lock.path] = lock
+			seenModules[lock.path] = block.DefRange
 
 		default:
 			// Shouldn't get here because this should be exhaustive for
@@ -320,6 +339,58 @@ func decodeProviderLockFromHCL(block *hcl.Block) (*ProviderLock, tfdiags.Diagnos
 	return ret, diags
 }
 
+func decodeModuleLockFromHCL(block *hcl.Block) (*ModuleLock, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	path := block.Labels[0]
+	for _, name := range strings.Split(path, ".") {
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid module path",
+				Detail:   "The module path for a module lock must be the dot-separated names of the module calls leading to the module, like \"network.vpc\".",
+				Subject:  block.LabelRanges[0].Ptr(),
+			})
+			return nil, diags
+		}
+	}
+
+	var raw struct {
+		Source  string  `hcl:"source"`
+		Version *string `hcl:"version"`
+		Commit  *string `hcl:"commit"`
+		Hash    string  `hcl:"hash"`
+	}
+	hclDiags := gohcl.DecodeBody(block.Body, nil, &raw)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	ret := &ModuleLock{
+		path:   path,
+		source: raw.Source,
+	}
+	if raw.Version != nil {
+		ret.version = *raw.Version
+	}
+	if raw.Commit != nil {
+		ret.commit = *raw.Commit
+	}
+	hash, err := getproviders.ParseHash(raw.Hash)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid module hash string",
+			Detail:   fmt.Sprintf("Cannot interpret %q as a module package hash: %s.", raw.Hash, err),
+			Subject:  block.DefRange.Ptr(),
+		})
+		return nil, diags
+	}
+	ret.hash = hash
+	return ret, diags
+}
+
 func decodeProviderVersionArgument(provider addrs.Provider, attr *hcl.Attribute) (getproviders.Version, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	if attr == nil {
@@ -276,3 +276,44 @@ provider "registry.opentofu.org/test/foo" {
 		t.Errorf("wrong result\n%s", diff)
 	}
 }
+
+func TestSaveLocksToBytes_modules(t *testing.T) {
+	locks := NewLocks()
+	hash := getproviders.MustParseHash("h1:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa=")
+	locks.SetModule("network.vpc", "git::https://example.com/vpc.git?ref=v1.0.0", "", "0123456789abcdef0123456789abcdef01234567", hash)
+	locks.SetModule("network", "registry.opentofu.org/example/network/aws", "1.2.0", "", hash)
+
+	src, diags := SaveLocksToBytes(locks)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	wantContent := `# This file is maintained automatically by "tofu init".
+# Manual edits may be lost in future updates.
+
+module "network" {
+  source  = "registry.opentofu.org/example/network/aws"
+  version = "1.2.0"
+  hash    = "h1:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa="
+}
+
+module "network.vpc" {
+  source = "git::https://example.com/vpc.git?ref=v1.0.0"
+  commit = "0123456789abcdef0123456789abcdef01234567"
+  hash   = "h1:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa="
+}
+`
+	if diff := cmp.Diff(wantContent, string(src)); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	loaded, diags := LoadLocksFromBytes(src, LockFilePath)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors loading saved locks\n%s", diags.Err().Error())
+	}
+	if !loaded.Equal(locks) {
+		t.Errorf("loaded locks don't match the saved locks")
+	}
+	if got := loaded.Module("network.vpc").Commit(); got != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("wrong commit %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package depsfile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// MergeLocks merges two sets of locks, such as the two sides of a version
+// control merge conflict in a dependency lock file.
+//
+// Locks that only appear on one side are retained as-is. For a provider that
+// is locked on both sides, MergeLocks selects the newest of the two locked
+// versions that satisfies the version constraints recorded on both sides,
+// and records the combination of both sets of constraints. If both sides
+// locked the same version then the result has the union of their hashes,
+// and otherwise it has only the hashes of the selected version, because the
+// hashes of the other version can't match its packages.
+//
+// It's an error if neither version satisfies both sets of constraints, or if
+// the two sides locked different packages for the same module call, because
+// then only the configuration author can decide which to keep.
+func MergeLocks(ours, theirs *Locks) (*Locks, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	ret := NewLocks()
+
+	providers := make(map[addrs.Provider]struct{})
+	for addr := range ours.providers {
+		providers[addr] = struct{}{}
+	}
+	for addr := range theirs.providers {
+		providers[addr] = struct{}{}
+	}
+	for addr := range providers {
+		lock, err := mergeProviderLocks(ours.Provider(addr), theirs.Provider(addr))
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible provider versions",
+				fmt.Sprintf("Cannot merge the locks for provider %s: %s. Resolve any conflict in the version constraints of the configuration first, and then run \"tofu init -upgrade\" to select a version that satisfies them.", addr, err),
+			))
+			continue
+		}
+		ret.providers[addr] = lock
+	}
+
+	modules := make(map[string]struct{})
+	for path := range ours.modules {
+		modules[path] = struct{}{}
+	}
+	for path := range theirs.modules {
+		modules[path] = struct{}{}
+	}
+	for path := range modules {
+		oursLock, theirsLock := ours.Module(path), theirs.Module(path)
+		switch {
+		case oursLock == nil:
+			copied := *theirsLock
+			ret.modules[path] = &copied
+		case theirsLock == nil || *oursLock == *theirsLock:
+			copied := *oursLock
+			ret.modules[path] = &copied
+		default:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Conflicting module locks",
+				fmt.Sprintf("The two lock files record different packages for the module call %q. Resolve any conflict in the module's source or version in the configuration first, and then run \"tofu init -upgrade\" to lock the selected package.", path),
+			))
+		}
+	}
+
+	return ret, diags
+}
+
+func mergeProviderLocks(ours, theirs *ProviderLock) (*ProviderLock, error) {
+	switch {
+	case ours == nil:
+		return NewProviderLock(theirs.addr, theirs.version, theirs.versionConstraints, append([]getproviders.Hash(nil), theirs.hashes...)), nil
+	case theirs == nil:
+		return NewProviderLock(ours.addr, ours.version, ours.versionConstraints, append([]getproviders.Hash(nil), ours.hashes...)), nil
+	}
+
+	constraints := mergeVersionConstraints(ours.versionConstraints, theirs.versionConstraints)
+	allowed := getproviders.MeetingConstraints(constraints)
+
+	if ours.version.Same(theirs.version) {
+		hashes := make([]getproviders.Hash, 0, len(ours.hashes)+len(theirs.hashes))
+		hashes = append(hashes, ours.hashes...)
+		hashes = append(hashes, theirs.hashes...)
+		return NewProviderLock(ours.addr, ours.version, constraints, hashes), nil
+	}
+
+	// The locks select different versions, so we'll prefer the newer one,
+	// which is most likely the result of a deliberate upgrade.
+	candidates := []*ProviderLock{ours, theirs}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[j].version.LessThan(candidates[i].version)
+	})
+	for _, candidate := range candidates {
+		if allowed.Has(candidate.version) {
+			return NewProviderLock(candidate.addr, candidate.version, constraints, append([]getproviders.Hash(nil), candidate.hashes...)), nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"neither of the locked versions %s and %s satisfies the version constraints %q",
+		ours.version, theirs.version, getproviders.VersionConstraintsString(constraints),
+	)
+}
+
+// mergeVersionConstraints returns the intersection of the given version
+// constraints, without any duplicate constraints.
+func mergeVersionConstraints(a, b getproviders.VersionConstraints) getproviders.VersionConstraints {
+	ret := make(getproviders.VersionConstraints, 0, len(a)+len(b))
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for _, spec := range append(append(getproviders.VersionConstraints(nil), a...), b...) {
+		key := getproviders.VersionConstraintsString(getproviders.VersionConstraints{spec})
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		ret = append(ret, spec)
+	}
+	return ret
+}
@@ -0,0 +1,140 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package depsfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestMergeLocks(t *testing.T) {
+	boopProvider := addrs.NewDefaultProvider("boop")
+	beepProvider := addrs.NewDefaultProvider("beep")
+	v1 := getproviders.MustParseVersion("1.0.0")
+	v2 := getproviders.MustParseVersion("2.0.0")
+	hash1 := getproviders.HashScheme("test").New("1")
+	hash2 := getproviders.HashScheme("test").New("2")
+	hash3 := getproviders.HashScheme("test").New("3")
+
+	t.Run("providers on one side", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints("~> 1.0"), []getproviders.Hash{hash1})
+		theirs.SetProvider(beepProvider, v2, getproviders.MustParseVersionConstraints(">= 2.0.0"), []getproviders.Hash{hash2})
+
+		got, diags := MergeLocks(ours, theirs)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		want := NewLocks()
+		want.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints("~> 1.0"), []getproviders.Hash{hash1})
+		want.SetProvider(beepProvider, v2, getproviders.MustParseVersionConstraints(">= 2.0.0"), []getproviders.Hash{hash2})
+		if !got.Equal(want) {
+			t.Fatalf("wrong result\n%s", cmp.Diff(want.AllProviders(), got.AllProviders()))
+		}
+	})
+	t.Run("same version", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints(">= 1.0.0"), []getproviders.Hash{hash1, hash2})
+		theirs.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints(">= 1.0.0, < 3.0.0"), []getproviders.Hash{hash2, hash3})
+
+		got, diags := MergeLocks(ours, theirs)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		lock := got.Provider(boopProvider)
+		if diff := cmp.Diff([]getproviders.Hash{hash1, hash2, hash3}, lock.AllHashes()); diff != "" {
+			t.Errorf("wrong hashes\n%s", diff)
+		}
+		if got, want := getproviders.VersionConstraintsString(lock.VersionConstraints()), ">= 1.0.0, < 3.0.0"; got != want {
+			t.Errorf("wrong constraints %q; want %q", got, want)
+		}
+	})
+	t.Run("different versions", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints(">= 1.0.0"), []getproviders.Hash{hash1})
+		theirs.SetProvider(boopProvider, v2, getproviders.MustParseVersionConstraints(">= 2.0.0"), []getproviders.Hash{hash2})
+
+		got, diags := MergeLocks(ours, theirs)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		lock := got.Provider(boopProvider)
+		if !lock.Version().Same(v2) {
+			t.Errorf("wrong version %s; want %s", lock.Version(), v2)
+		}
+		if diff := cmp.Diff([]getproviders.Hash{hash2}, lock.AllHashes()); diff != "" {
+			t.Errorf("wrong hashes\n%s", diff)
+		}
+		if got, want := getproviders.VersionConstraintsString(lock.VersionConstraints()), ">= 1.0.0, >= 2.0.0"; got != want {
+			t.Errorf("wrong constraints %q; want %q", got, want)
+		}
+	})
+	t.Run("different versions with the newer one excluded", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints("~> 1.0"), []getproviders.Hash{hash1})
+		theirs.SetProvider(boopProvider, v2, getproviders.MustParseVersionConstraints(">= 1.0.0"), []getproviders.Hash{hash2})
+
+		got, diags := MergeLocks(ours, theirs)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		if got, want := got.Provider(boopProvider).Version(), v1; !got.Same(want) {
+			t.Errorf("wrong version %s; want %s", got, want)
+		}
+	})
+	t.Run("incompatible versions", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetProvider(boopProvider, v1, getproviders.MustParseVersionConstraints("~> 1.0"), []getproviders.Hash{hash1})
+		theirs.SetProvider(boopProvider, v2, getproviders.MustParseVersionConstraints("~> 2.0"), []getproviders.Hash{hash2})
+
+		_, diags := MergeLocks(ours, theirs)
+		if !diags.HasErrors() {
+			t.Fatalf("unexpected success")
+		}
+		if got, want := diags.Err().Error(), "Incompatible provider versions"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("modules", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetModule("a", "example.com/a", "1.0.0", "", hash1)
+		ours.SetModule("b", "example.com/b", "1.0.0", "", hash2)
+		theirs.SetModule("b", "example.com/b", "1.0.0", "", hash2)
+		theirs.SetModule("c", "example.com/c", "1.0.0", "", hash3)
+
+		got, diags := MergeLocks(ours, theirs)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		if got, want := len(got.AllModules()), 3; got != want {
+			t.Fatalf("wrong number of modules %d; want %d", got, want)
+		}
+	})
+	t.Run("conflicting modules", func(t *testing.T) {
+		ours := NewLocks()
+		theirs := NewLocks()
+		ours.SetModule("a", "example.com/a", "1.0.0", "", hash1)
+		theirs.SetModule("a", "example.com/a", "1.1.0", "", hash2)
+
+		_, diags := MergeLocks(ours, theirs)
+		if !diags.HasErrors() {
+			t.Fatalf("unexpected success")
+		}
+		if got, want := diags.Err().Error(), "Conflicting module locks"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package diffrenderer implements an extension point that lets the human
+// plan output show the planned changes to resources of particular types with
+// specialized diff tooling, such as a manifest-aware diff for Kubernetes
+// manifests or a statement-level diff for IAM policies, instead of OpenTofu's
+// own attribute diff.
+//
+// Diff renderers are usually external programs configured in the CLI
+// configuration, which OpenTofu runs using the protocol implemented by
+// External.
+package diffrenderer
+
+import (
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+)
+
+// Renderer is the interface implemented by diff renderers.
+type Renderer interface {
+	// Name returns a name for the diff renderer, which OpenTofu uses to refer
+	// to it in the plan output.
+	Name() string
+
+	// RenderDiff returns the text that describes the given planned change,
+	// which OpenTofu shows in place of its own diff of the resource's
+	// attributes.
+	RenderDiff(req *Request) (string, error)
+}
+
+// Request is the information about a planned change to a resource instance
+// that is passed to a diff renderer.
+type Request struct {
+	// Change is the planned change, in the same format as the
+	// "resource_changes" of the JSON plan representation.
+	Change jsonplan.ResourceChange
+
+	// Color is true if the plan output uses colors, in which case the diff
+	// renderer may use terminal escape sequences too.
+	Color bool
+}
@@ -0,0 +1,89 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diffrenderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+)
+
+// FormatVersion is the version of the JSON documents written to external
+// diff renderers. It follows the same rules as the other JSON formats
+// OpenTofu produces: the minor version is incremented for
+// backward-compatible changes, and the major version for incompatible ones.
+const FormatVersion = "1.0"
+
+// External is a Renderer implemented by an external program.
+//
+// OpenTofu runs the program once for each planned change to a resource
+// instance of the types it is configured for, writing a JSON object to its
+// standard input with the following properties:
+//
+//   - "format_version": the value of FormatVersion.
+//   - "renderer": the name of the diff renderer.
+//   - "color": whether the plan output uses colors.
+//   - "resource_change": the planned change, in the same format as the
+//     elements of "resource_changes" in the output of "tofu show -json",
+//     including the "before" and "after" values of the resource instance.
+//
+// The program must exit with status zero and write the rendered diff to its
+// standard output as plain text. Anything the program writes to its standard
+// error is included in the error OpenTofu returns if the program fails.
+type External struct {
+	// RendererName is the name that the diff renderer was configured with.
+	RendererName string
+
+	// Command is the program to run, and Args are the arguments to run it
+	// with.
+	Command string
+	Args    []string
+}
+
+var _ Renderer = (*External)(nil)
+
+// externalRequest is the JSON object written to the standard input of an
+// external diff renderer.
+type externalRequest struct {
+	FormatVersion  string                  `json:"format_version"`
+	Renderer       string                  `json:"renderer"`
+	Color          bool                    `json:"color"`
+	ResourceChange jsonplan.ResourceChange `json:"resource_change"`
+}
+
+func (e *External) Name() string {
+	return e.RendererName
+}
+
+func (e *External) RenderDiff(req *Request) (string, error) {
+	input, err := json.Marshal(externalRequest{
+		FormatVersion:  FormatVersion,
+		Renderer:       e.RendererName,
+		Color:          req.Color,
+		ResourceChange: req.Change,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(e.Command, e.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errOutput := strings.TrimSpace(stderr.String()); errOutput != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", e.Command, err, errOutput)
+		}
+		return "", fmt.Errorf("%s failed: %w", e.Command, err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
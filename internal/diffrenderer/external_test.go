@@ -0,0 +1,104 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diffrenderer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+)
+
+func TestExternal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test diff renderers are shell scripts")
+	}
+
+	tests := map[string]struct {
+		script  string
+		want    string
+		wantErr string
+	}{
+		"success": {
+			script: `printf 'spec.replicas: 1 -> 3\n\n'`,
+			want:   "spec.replicas: 1 -> 3",
+		},
+		"no output": {
+			script: `true`,
+			want:   "",
+		},
+		"failure": {
+			script:  `echo 'no cluster' >&2; exit 1`,
+			wantErr: "no cluster",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			inputPath := filepath.Join(dir, "input.json")
+			scriptPath := filepath.Join(dir, "renderer")
+			script := "#!/bin/sh\ncat > \"$1\"\n" + test.script + "\n"
+			if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			renderer := &External{
+				RendererName: "test",
+				Command:      scriptPath,
+				Args:         []string{inputPath},
+			}
+			got, err := renderer.RenderDiff(&Request{
+				Change: jsonplan.ResourceChange{
+					Address: "kubernetes_manifest.app",
+					Type:    "kubernetes_manifest",
+					Change: jsonplan.Change{
+						Actions: []string{"update"},
+						Before:  json.RawMessage(`{"replicas":1}`),
+						After:   json.RawMessage(`{"replicas":3}`),
+					},
+				},
+				Color: true,
+			})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error\ngot:  %v\nwant: %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.want)
+			}
+
+			src, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var input struct {
+				FormatVersion  string                  `json:"format_version"`
+				Renderer       string                  `json:"renderer"`
+				Color          bool                    `json:"color"`
+				ResourceChange jsonplan.ResourceChange `json:"resource_change"`
+			}
+			if err := json.Unmarshal(src, &input); err != nil {
+				t.Fatalf("diff renderer got invalid JSON: %s", err)
+			}
+			if input.FormatVersion != FormatVersion || input.Renderer != "test" || !input.Color {
+				t.Errorf("wrong request header: %s", src)
+			}
+			if input.ResourceChange.Address != "kubernetes_manifest.app" || string(input.ResourceChange.Change.After) != `{"replicas":3}` {
+				t.Errorf("wrong resource change in request: %s", src)
+			}
+		})
+	}
+}
@@ -27,9 +27,13 @@ import (
 // If you want to generate actual valid OpenTofu code you should follow this
 // call up with a call to WrapResourceContents, which will place an OpenTofu
 // resource header around the attributes and blocks returned by this function.
+//
+// If the provider configuration uses for_each then providerKey is the key of
+// the provider instance the resource belongs to, and is otherwise addrs.NoKey.
 func GenerateResourceContents(addr addrs.AbsResourceInstance,
 	schema *configschema.Block,
 	pc addrs.LocalProviderConfig,
+	providerKey addrs.InstanceKey,
 	stateVal cty.Value) (string, tfdiags.Diagnostics) {
 	var buf strings.Builder
 
@@ -37,7 +41,11 @@ func GenerateResourceContents(addr addrs.AbsResourceInstance,
 
 	if pc.LocalName != addr.Resource.Resource.ImpliedProvider() || pc.Alias != "" {
 		buf.WriteString(strings.Repeat(" ", 2))
-		buf.WriteString(fmt.Sprintf("provider = %s\n", pc.StringCompact()))
+		if providerKey != addrs.NoKey {
+			buf.WriteString(fmt.Sprintf("provider = %s%s\n", pc.StringCompact(), providerKey))
+		} else {
+			buf.WriteString(fmt.Sprintf("provider = %s\n", pc.StringCompact()))
+		}
 	}
 
 	stateVal = omitUnknowns(stateVal)
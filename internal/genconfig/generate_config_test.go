@@ -29,11 +29,12 @@ func TestConfigGeneration(t *testing.T) {
 	}
 
 	tcs := map[string]struct {
-		schema   *configschema.Block
-		addr     addrs.AbsResourceInstance
-		provider addrs.LocalProviderConfig
-		value    cty.Value
-		expected string
+		schema      *configschema.Block
+		addr        addrs.AbsResourceInstance
+		provider    addrs.LocalProviderConfig
+		providerKey addrs.InstanceKey
+		value       cty.Value
+		expected    string
 	}{
 		"simple_resource": {
 			schema: &configschema.Block{
@@ -501,6 +502,40 @@ resource "tfcoremock_simple_resource" "example" {
   juststr          = "{a=b}"
   secrets          = null # sensitive
   sensitivejsonobj = null # sensitive
+}`,
+		},
+		"provider_instance_key": {
+			schema: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Optional: true,
+					},
+				},
+			},
+			addr: addrs.AbsResourceInstance{
+				Module: nil,
+				Resource: addrs.ResourceInstance{
+					Resource: addrs.Resource{
+						Mode: addrs.ManagedResourceMode,
+						Type: "tfcoremock_simple_resource",
+						Name: "example",
+					},
+					Key: nil,
+				},
+			},
+			provider: addrs.LocalProviderConfig{
+				LocalName: "tfcoremock",
+				Alias:     "by_region",
+			},
+			providerKey: addrs.StringKey("us-east-1"),
+			value: cty.ObjectVal(map[string]cty.Value{
+				"value": cty.StringVal("hello"),
+			}),
+			expected: `
+resource "tfcoremock_simple_resource" "example" {
+  provider = tfcoremock.by_region["us-east-1"]
+  value    = "hello"
 }`,
 		},
 		"optional_empty_sensitive_string": {
@@ -675,7 +710,7 @@ resource "tfcoremock_simple_resource" "example" {
 			if err != nil {
 				t.Fatalf("schema failed InternalValidate: %s", err)
 			}
-			contents, diags := GenerateResourceContents(tc.addr, tc.schema, tc.provider, tc.value)
+			contents, diags := GenerateResourceContents(tc.addr, tc.schema, tc.provider, tc.providerKey, tc.value)
 			if len(diags) > 0 {
 				t.Errorf("expected no diagnostics but found %s", diags)
 			}
@@ -0,0 +1,212 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/replacefile"
+)
+
+// PackageCache is a content-addressed cache of module packages shared
+// between working directories, so that each module package only needs to be
+// downloaded once rather than once per working directory.
+//
+// The cache directory contains a "content" directory with one subdirectory
+// per distinct package content, named after the hash of that content, and an
+// "index" directory that maps each package address to the hash of the
+// content most recently fetched from it. Content directories are never
+// modified after they are created, and both kinds of entry are written
+// atomically, so several OpenTofu processes can safely share a cache.
+type PackageCache struct {
+	baseDir string
+}
+
+// NewPackageCache returns a package cache that stores its entries in the
+// given directory, which must already exist.
+func NewPackageCache(baseDir string) *PackageCache {
+	return &PackageCache{
+		baseDir: baseDir,
+	}
+}
+
+type packageCacheIndexEntry struct {
+	Package string `json:"package"`
+	Hash    string `json:"hash"`
+}
+
+// Get installs the cached content of the package at the given address into
+// the given directory, which must not exist yet, returning the hash of the
+// installed content. It returns an empty hash if the cache has no entry for
+// the package.
+//
+// Get verifies the cached content against its hash before installing it,
+// and discards entries that don't match, such as if a file was modified
+// through a hard link in a working directory, so that the caller can fetch
+// the package again.
+func (c *PackageCache) Get(packageAddr string, instDir string) (string, error) {
+	src, err := os.ReadFile(c.indexPath(packageAddr))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var entry packageCacheIndexEntry
+	if err := json.Unmarshal(src, &entry); err != nil || entry.Package != packageAddr {
+		log.Printf("[WARN] getmodules: ignoring invalid cache index entry for %s", packageAddr)
+		return "", nil
+	}
+
+	contentDir := c.contentPath(entry.Hash)
+	got, err := HashPackageDir(contentDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if got != entry.Hash {
+		log.Printf("[WARN] getmodules: cached content for %s has hash %s rather than %s, so discarding it", packageAddr, got, entry.Hash)
+		if err := os.RemoveAll(contentDir); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	log.Printf("[TRACE] getmodules: installing %q from cache %s to %s", packageAddr, contentDir, instDir)
+	if err := linkOrCopyDir(instDir, contentDir); err != nil {
+		return "", fmt.Errorf("failed to install %s from the module package cache: %w", packageAddr, err)
+	}
+	return entry.Hash, nil
+}
+
+// Put adds the package at the given address, which was just fetched into
+// the given directory, to the cache, returning the hash of its content.
+func (c *PackageCache) Put(packageAddr string, instDir string) (string, error) {
+	contentRoot := filepath.Join(c.baseDir, "content")
+	if err := os.MkdirAll(contentRoot, 0755); err != nil {
+		return "", err
+	}
+	// We populate a temporary directory first and then rename it into place,
+	// so that other processes never see partial content. We hash the copy
+	// rather than the original because copying skips some files, such as
+	// version control metadata.
+	tmpDir, err := os.MkdirTemp(contentRoot, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := copy.CopyDir(tmpDir, instDir); err != nil {
+		return "", err
+	}
+	hash, err := HashPackageDir(tmpDir)
+	if err != nil {
+		return "", err
+	}
+
+	contentDir := c.contentPath(hash)
+	if err := os.Rename(tmpDir, contentDir); err != nil {
+		if _, statErr := os.Stat(contentDir); statErr != nil {
+			return "", err
+		}
+		// The cache already has the same content, such as from another
+		// package address or another process, so we can use that.
+	}
+
+	src, err := json.Marshal(packageCacheIndexEntry{
+		Package: packageAddr,
+		Hash:    hash,
+	})
+	if err != nil {
+		return "", err
+	}
+	indexPath := c.indexPath(packageAddr)
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return "", err
+	}
+	if err := replacefile.AtomicWriteFile(indexPath, src, 0644); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (c *PackageCache) indexPath(packageAddr string) string {
+	sum := sha256.Sum256([]byte(packageAddr))
+	return filepath.Join(c.baseDir, "index", hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *PackageCache) contentPath(hash string) string {
+	// The hash is a base64 string that can contain slashes, so we use a
+	// hex encoding of it as the directory name instead.
+	return filepath.Join(c.baseDir, "content", hex.EncodeToString([]byte(hash)))
+}
+
+// linkOrCopyDir recreates the directory tree at src in dst, using hard links
+// for the files where possible and copying them otherwise, such as when the
+// two directories are on different filesystems.
+func linkOrCopyDir(dst, src string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case strings.HasPrefix(rel, ".."):
+			return fmt.Errorf("unexpected path %s outside of %s", path, src)
+		}
+
+		if err := os.Link(path, target); err == nil {
+			return nil
+		}
+		return copyFile(target, path, info.Mode().Perm())
+	})
+}
+
+func copyFile(dst, src string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,71 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageCache(t *testing.T) {
+	const addr = "git::https://example.com/network.git?ref=v1.0.0"
+	cache := NewPackageCache(t.TempDir())
+
+	fetched := t.TempDir()
+	writeTestFile(t, filepath.Join(fetched, "main.tf"), "# network module\n")
+	writeTestFile(t, filepath.Join(fetched, "modules", "subnet", "main.tf"), "# subnet module\n")
+	writeTestFile(t, filepath.Join(fetched, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	hash, err := cache.Get(addr, filepath.Join(t.TempDir(), "miss"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if hash != "" {
+		t.Fatalf("unexpected cache hit before the package was added: %s", hash)
+	}
+
+	putHash, err := cache.Put(addr, fetched)
+	if err != nil {
+		t.Fatalf("failed to add package: %s", err)
+	}
+
+	instDir := filepath.Join(t.TempDir(), "network")
+	getHash, err := cache.Get(addr, instDir)
+	if err != nil {
+		t.Fatalf("failed to install package: %s", err)
+	}
+	if getHash != putHash {
+		t.Fatalf("wrong hash %q; want %q", getHash, putHash)
+	}
+	if got, err := os.ReadFile(filepath.Join(instDir, "modules", "subnet", "main.tf")); err != nil || string(got) != "# subnet module\n" {
+		t.Fatalf("package content was not installed: %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(instDir, ".git")); !os.IsNotExist(err) {
+		t.Fatalf("version control metadata was cached")
+	}
+
+	// Modifying the cached content, such as through a hard link, must
+	// invalidate the cache entry rather than spreading the modification.
+	writeTestFile(t, filepath.Join(instDir, "main.tf"), "# tampered\n")
+	hash, err = cache.Get(addr, filepath.Join(t.TempDir(), "network"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if hash != "" {
+		t.Fatalf("unexpected cache hit for modified content")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
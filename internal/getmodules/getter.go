@@ -9,12 +9,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strings"
 
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	getter "github.com/hashicorp/go-getter"
 	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/httpclient"
 )
 
 // We configure our own go-getter detector and getter sets here, because
@@ -141,6 +143,10 @@ func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAdd
 			return fmt.Errorf("failed to copy from %s to %s: %w", prevDir, instPath, err)
 		}
 	} else {
+		if host, remote := packageHost(packageAddr); remote && httpclient.Offline() {
+			return httpclient.ErrOffline{Host: host}
+		}
+
 		log.Printf("[TRACE] getmodules: fetching %q to %q", packageAddr, instPath)
 		client := getter.Client{
 			Src: packageAddr,
@@ -169,6 +175,26 @@ func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAdd
 	return nil
 }
 
+// packageHost returns the host that the package at the given normalized
+// address would be fetched from, and whether fetching it requires network
+// access at all, which is not the case for packages in the local filesystem.
+func packageHost(packageAddr string) (string, bool) {
+	forced, rest := "", packageAddr
+	if idx := strings.Index(packageAddr, "::"); idx >= 0 {
+		forced, rest = packageAddr[:idx], packageAddr[idx+2:]
+	}
+	u, err := url.Parse(rest)
+	if err != nil || u.Host == "" {
+		// Not a URL we understand, so we'll assume it needs the network
+		// and report the whole address in place of the host.
+		return packageAddr, forced != "file" && (err != nil || u.Scheme != "file")
+	}
+	if forced == "file" || u.Scheme == "file" {
+		return "", false
+	}
+	return u.Hostname(), true
+}
+
 // withoutQueryParams implements getter.Detector and can be used to wrap another detector.
 // This will look for any query params that might exist in the src and strip that away before calling
 // getter.Detector#Detect. After the response is returned, the query params are attached back to the resulted src.
@@ -0,0 +1,98 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// HashPackageDir returns the "h1:" hash of the content of the module package
+// installed in the given directory, using the same algorithm as for provider
+// packages.
+//
+// The hash ignores files and directories whose names start with a dot, such
+// as the .git directory left behind by fetching a package from a Git
+// repository, because their content varies between otherwise-identical
+// fetches and because OpenTofu doesn't copy them when reusing a package
+// that it already fetched.
+func HashPackageDir(dir string) (string, error) {
+	// Packages fetched from the local filesystem are installed as symlinks
+	// to their original location.
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// PackageGitCommit returns the ID of the Git commit checked out in the module
+// package installed in the given directory, or an empty string if the
+// package isn't a Git working tree, such as when it was installed from an
+// archive or from the module package cache.
+func PackageGitCommit(dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, isRef := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !isRef {
+		// A detached HEAD, which is what fetching a tag or commit produces,
+		// contains the commit ID directly.
+		return ref
+	}
+
+	if id, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(id))
+	}
+	// The ref might have been packed instead.
+	f, err := os.Open(filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if id, name, ok := strings.Cut(sc.Text(), " "); ok && name == ref {
+			return id
+		}
+	}
+	return ""
+}
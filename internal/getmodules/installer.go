@@ -7,6 +7,8 @@ package getmodules
 
 import (
 	"context"
+	"log"
+	"os"
 )
 
 // PackageFetcher is a low-level utility for fetching remote module packages
@@ -22,8 +24,14 @@ import (
 // rather than fetching the package from its origin repeatedly. There is
 // no way to reset this cache, so a particular PackageFetcher instance should
 // live only for the duration of a single initialization process.
+//
+// A PackageFetcher can also use a PackageCache that is shared between
+// working directories, which is configured by calling SetCache.
 type PackageFetcher struct {
 	getter reusingGetter
+
+	cache   *PackageCache
+	refresh bool // if set, packages are fetched again even if cached
 }
 
 func NewPackageFetcher() *PackageFetcher {
@@ -45,5 +53,49 @@ func NewPackageFetcher() *PackageFetcher {
 // caller must resolve that itself, possibly with the help of the
 // getmodules.SplitPackageSubdir and getmodules.ExpandSubdirGlobs functions.
 func (f *PackageFetcher) FetchPackage(ctx context.Context, instDir string, packageAddr string) error {
-	return f.getter.getWithGoGetter(ctx, instDir, packageAddr)
+	_, remote := packageHost(packageAddr)
+	if f.cache == nil || !remote {
+		// Packages in the local filesystem can change at any time, so we
+		// always fetch them directly.
+		return f.getter.getWithGoGetter(ctx, instDir, packageAddr)
+	}
+	if _, reused := f.getter[packageAddr]; reused {
+		return f.getter.getWithGoGetter(ctx, instDir, packageAddr)
+	}
+
+	if !f.refresh {
+		hash, err := f.cache.Get(packageAddr, instDir)
+		switch {
+		case err != nil:
+			log.Printf("[WARN] getmodules: failed to use module package cache for %q, so fetching it instead: %s", packageAddr, err)
+			if err := os.RemoveAll(instDir); err != nil {
+				return err
+			}
+		case hash != "":
+			f.getter[packageAddr] = instDir
+			return nil
+		}
+	}
+
+	if err := f.getter.getWithGoGetter(ctx, instDir, packageAddr); err != nil {
+		return err
+	}
+	if _, err := f.cache.Put(packageAddr, instDir); err != nil {
+		// The cache is only an optimization, so failing to update it
+		// doesn't prevent installation.
+		log.Printf("[WARN] getmodules: failed to add %q to the module package cache: %s", packageAddr, err)
+	}
+	return nil
+}
+
+// SetCache configures the fetcher to install packages from the given shared
+// cache where possible, and to add the packages it fetches to that cache.
+//
+// If refresh is set, the fetcher ignores the existing cache entries and
+// fetches every package again, replacing the cache entries with the result.
+// This is intended for use when upgrading modules, because the content at a
+// package address can change over time.
+func (f *PackageFetcher) SetCache(cache *PackageCache, refresh bool) {
+	f.cache = cache
+	f.refresh = refresh
 }
@@ -8,6 +8,7 @@ package getproviders
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	svchost "github.com/hashicorp/terraform-svchost"
 
@@ -155,6 +156,35 @@ func (err ErrPlatformNotSupported) Error() string {
 	)
 }
 
+// ErrExecutablePlatformMismatch is an error type used to indicate that a
+// provider executable was built for a different platform than the one
+// OpenTofu is running on, and so OpenTofu would be unable to run it.
+type ErrExecutablePlatformMismatch struct {
+	Filename string
+
+	// Found describes the architecture and executable format the executable
+	// was built for, such as "amd64 (Mach-O)".
+	Found string
+
+	// Platforms are the platforms the executable was expected to be built
+	// for, which is the current platform and any fallback platforms allowed
+	// for the provider.
+	Platforms []Platform
+}
+
+func (err ErrExecutablePlatformMismatch) Error() string {
+	platforms := make([]string, len(err.Platforms))
+	for i, platform := range err.Platforms {
+		platforms[i] = platform.String()
+	}
+	return fmt.Sprintf(
+		"%s is an executable for %s, which cannot run on %s",
+		err.Filename,
+		err.Found,
+		strings.Join(platforms, " or "),
+	)
+}
+
 // ErrProtocolNotSupported is an error type used to indicate that a particular
 // version of a provider is not supported by the current version of OpenTofu.
 //
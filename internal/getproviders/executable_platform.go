@@ -0,0 +1,157 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// executableTarget describes the platform that an executable file was built
+// for, as far as can be determined from its header.
+type executableTarget struct {
+	// format is the name of the executable format, such as "ELF".
+	format string
+
+	// os is the operating system the executable is for, or empty if the
+	// format is used by several operating systems, as is the case for ELF.
+	os string
+
+	// arch is the CPU architecture the executable is for, using the same
+	// names as Platform.Arch.
+	arch string
+}
+
+func (t executableTarget) runsOn(platform Platform) bool {
+	switch t.format {
+	case "ELF":
+		// ELF is used by most operating systems other than macOS and
+		// Windows, and Go doesn't set the OS/ABI field in the header, so
+		// this is the best we can do.
+		if platform.OS == "darwin" || platform.OS == "windows" {
+			return false
+		}
+	default:
+		if t.os != platform.OS {
+			return false
+		}
+	}
+	return t.arch == platform.Arch
+}
+
+func (t executableTarget) String() string {
+	return fmt.Sprintf("%s (%s)", t.arch, t.format)
+}
+
+// CheckExecutablePlatform inspects the header of the executable file at the
+// given path and returns an error if it was built for a platform other than
+// the given ones, which would otherwise only be detected when OpenTofu fails
+// to start the executable with an unhelpful error such as
+// "exec format error".
+//
+// Files that are not in a recognized executable format, or that are built
+// for an architecture this function doesn't know about, are assumed to be
+// compatible, so that for example scripts are still allowed.
+func CheckExecutablePlatform(filename string, platforms ...Platform) error {
+	targets, err := executableTargets(filename)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	for _, target := range targets {
+		if target.arch == "" {
+			return nil // unknown architecture, so we can't tell
+		}
+		for _, platform := range platforms {
+			if target.runsOn(platform) {
+				return nil
+			}
+		}
+	}
+
+	found := make([]string, len(targets))
+	for i, target := range targets {
+		found[i] = target.String()
+	}
+	return ErrExecutablePlatformMismatch{
+		Filename:  filename,
+		Found:     strings.Join(found, ", "),
+		Platforms: platforms,
+	}
+}
+
+// executableTargets returns the platforms that the executable at the given
+// path was built for, which can be more than one for a macOS universal
+// binary, or no platforms at all if the file isn't in a recognized format.
+func executableTargets(filename string) ([]executableTarget, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil {
+		// Too short to be an executable in any of the formats we know.
+		return nil, nil
+	}
+
+	switch {
+	case string(magic[:]) == elf.ELFMAG:
+		ef, err := elf.NewFile(f)
+		if err != nil {
+			return nil, nil
+		}
+		return []executableTarget{{format: "ELF", arch: elfArchs[ef.Machine]}}, nil
+
+	case magic[0] == 'M' && magic[1] == 'Z':
+		pf, err := pe.NewFile(f)
+		if err != nil {
+			return nil, nil
+		}
+		return []executableTarget{{format: "PE", os: "windows", arch: peArchs[pf.Machine]}}, nil
+
+	default:
+		if ff, err := macho.NewFatFile(f); err == nil {
+			targets := make([]executableTarget, len(ff.Arches))
+			for i, arch := range ff.Arches {
+				targets[i] = executableTarget{format: "Mach-O", os: "darwin", arch: machoArchs[arch.Cpu]}
+			}
+			return targets, nil
+		}
+		if mf, err := macho.NewFile(f); err == nil {
+			return []executableTarget{{format: "Mach-O", os: "darwin", arch: machoArchs[mf.Cpu]}}, nil
+		}
+		return nil, nil
+	}
+}
+
+var elfArchs = map[elf.Machine]string{
+	elf.EM_386:     "386",
+	elf.EM_X86_64:  "amd64",
+	elf.EM_ARM:     "arm",
+	elf.EM_AARCH64: "arm64",
+}
+
+var peArchs = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+}
+
+var machoArchs = map[macho.Cpu]string{
+	macho.Cpu386:   "386",
+	macho.CpuAmd64: "amd64",
+	macho.CpuArm:   "arm",
+	macho.CpuArm64: "arm64",
+}
@@ -0,0 +1,55 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckExecutablePlatform(t *testing.T) {
+	// The test program itself is a convenient executable that we know was
+	// built for the current platform.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("current platform", func(t *testing.T) {
+		if err := CheckExecutablePlatform(exe, CurrentPlatform); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("other platform", func(t *testing.T) {
+		other := Platform{OS: CurrentPlatform.OS, Arch: "arm64"}
+		if CurrentPlatform.Arch == "arm64" {
+			other.Arch = "amd64"
+		}
+		err := CheckExecutablePlatform(exe, other)
+		if _, ok := err.(ErrExecutablePlatformMismatch); !ok {
+			t.Errorf("wrong error type. Expected ErrExecutablePlatformMismatch, got %T", err)
+		}
+	})
+
+	t.Run("fallback platform", func(t *testing.T) {
+		other := Platform{OS: "plan9", Arch: "mips"}
+		if err := CheckExecutablePlatform(exe, other, CurrentPlatform); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("unrecognized format", func(t *testing.T) {
+		script := filepath.Join(t.TempDir(), "terraform-provider-script")
+		if err := os.WriteFile(script, []byte("#!/bin/sh\necho hello\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckExecutablePlatform(script, Platform{OS: "plan9", Arch: "mips"}); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+}
@@ -70,6 +70,7 @@ func newHTTPMirrorSourceWithHTTPClient(baseURL *url.URL, creds svcauth.Credentia
 	retryableClient := retryablehttp.NewClient()
 	retryableClient.HTTPClient = httpClient
 	retryableClient.RetryMax = discoveryRetry
+	retryableClient.CheckRetry = httpclient.CheckRetry
 	retryableClient.RequestLogHook = requestLogHook
 	retryableClient.ErrorHandler = maxRetryErrorHandler
 
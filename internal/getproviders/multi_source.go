@@ -98,6 +98,9 @@ func (s MultiSource) PackageMeta(ctx context.Context, provider addrs.Provider, v
 		meta, err := selector.Source.PackageMeta(ctx, provider, version, target)
 		switch err.(type) {
 		case nil:
+			if meta.Source == "" {
+				meta.Source = selector.Source.ForDisplay(provider)
+			}
 			return meta, nil
 		case ErrProviderNotFound, ErrRegistryProviderNotKnown, ErrPlatformNotSupported:
 			continue // ignore, then
@@ -305,6 +305,7 @@ func TestMultiSourcePackageMeta(t *testing.T) {
 			platform2,
 		)
 		want := onlyInS1
+		want.Source = "mock source"
 		if err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
@@ -320,6 +321,7 @@ func TestMultiSourcePackageMeta(t *testing.T) {
 			platform1,
 		)
 		want := onlyInS2
+		want.Source = "mock source"
 		if err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
@@ -335,6 +337,7 @@ func TestMultiSourcePackageMeta(t *testing.T) {
 			platform1,
 		)
 		want := inBothS1 // S1 "wins" because it's earlier in the MultiSource
+		want.Source = "mock source"
 		if err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
@@ -0,0 +1,75 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// PlatformFallback is a rule allowing packages built for other platforms to
+// be installed for a set of providers when there is no package available for
+// the platform OpenTofu is running on, for systems that can run executables
+// for those platforms under emulation, such as Rosetta 2 on macOS.
+type PlatformFallback struct {
+	// Platforms are the platforms to try, in order of preference.
+	Platforms []Platform
+
+	// Include and Exclude are sets of provider matching patterns that
+	// together define which providers the rule applies to, with the same
+	// meaning as for MultiSourceSelector.
+	Include MultiSourceMatchingPatterns
+	Exclude MultiSourceMatchingPatterns
+}
+
+// CanHandleProvider returns true if the receiving rule applies to the
+// given provider.
+func (f PlatformFallback) CanHandleProvider(addr addrs.Provider) bool {
+	switch {
+	case f.Exclude.MatchesProvider(addr):
+		return false
+	case len(f.Include) > 0:
+		return f.Include.MatchesProvider(addr)
+	default:
+		return true
+	}
+}
+
+// PlatformFallbacks is a set of PlatformFallback rules.
+type PlatformFallbacks []PlatformFallback
+
+// ForProvider returns the fallback platforms allowed for the given provider,
+// taken from the first rule that applies to it, or nil if no rule applies.
+func (fs PlatformFallbacks) ForProvider(addr addrs.Provider) []Platform {
+	for _, f := range fs {
+		if f.CanHandleProvider(addr) {
+			return f.Platforms
+		}
+	}
+	return nil
+}
+
+// EmulatedPlatforms returns the platforms whose executables are commonly
+// able to run on the given platform under emulation or through a
+// compatibility layer, such as amd64 executables on arm64 macOS using
+// Rosetta 2. This is only a suggestion for use in error messages, because
+// whether the emulation is actually available depends on how the system
+// is configured.
+func EmulatedPlatforms(host Platform) []Platform {
+	switch host {
+	case Platform{OS: "darwin", Arch: "arm64"}:
+		return []Platform{{OS: "darwin", Arch: "amd64"}}
+	case Platform{OS: "windows", Arch: "arm64"}:
+		return []Platform{{OS: "windows", Arch: "amd64"}, {OS: "windows", Arch: "386"}}
+	case Platform{OS: "windows", Arch: "amd64"}:
+		return []Platform{{OS: "windows", Arch: "386"}}
+	case Platform{OS: "linux", Arch: "arm64"}:
+		return []Platform{{OS: "linux", Arch: "amd64"}}
+	case Platform{OS: "linux", Arch: "amd64"}:
+		return []Platform{{OS: "linux", Arch: "386"}}
+	default:
+		return nil
+	}
+}
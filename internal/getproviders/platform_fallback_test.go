@@ -0,0 +1,44 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestPlatformFallbacksForProvider(t *testing.T) {
+	amd64 := Platform{OS: "darwin", Arch: "amd64"}
+	i386 := Platform{OS: "darwin", Arch: "386"}
+	fallbacks := PlatformFallbacks{
+		{
+			Platforms: []Platform{amd64},
+			Include:   mustParseMultiSourceMatchingPatterns("example.com/*/*"),
+			Exclude:   mustParseMultiSourceMatchingPatterns("example.com/legacy/*"),
+		},
+		{
+			Platforms: []Platform{amd64, i386},
+			Include:   mustParseMultiSourceMatchingPatterns("example.com/legacy/*"),
+		},
+	}
+
+	tests := map[string][]Platform{
+		"example.com/foo/bar":       {amd64},
+		"example.com/legacy/bar":    {amd64, i386},
+		"registry.opentofu.org/a/b": nil,
+	}
+	for addr, want := range tests {
+		t.Run(addr, func(t *testing.T) {
+			got := fallbacks.ForProvider(addrs.MustParseProviderSourceString(addr))
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
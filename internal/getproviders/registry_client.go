@@ -79,6 +79,7 @@ func newRegistryClient(baseURL *url.URL, creds svcauth.HostCredentials) *registr
 	retryableClient := retryablehttp.NewClient()
 	retryableClient.HTTPClient = httpClient
 	retryableClient.RetryMax = discoveryRetry
+	retryableClient.CheckRetry = httpclient.CheckRetry
 	retryableClient.RequestLogHook = requestLogHook
 	retryableClient.ErrorHandler = maxRetryErrorHandler
 
@@ -478,6 +479,11 @@ func maxRetryErrorHandler(resp *http.Response, err error, numTries int) (*http.R
 		resp.Body.Close()
 	}
 
+	// Retrying or trying again later won't help if OpenTofu is offline.
+	if errors.As(err, &httpclient.ErrOffline{}) {
+		return resp, err
+	}
+
 	// Additional error detail: if we have a response, use the status code;
 	// if we have an error, use that; otherwise nothing. We will never have
 	// both response and error.
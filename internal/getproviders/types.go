@@ -201,6 +201,12 @@ type PackageMeta struct {
 	// This is likely appropriate only for packages that are already available
 	// on the local system.
 	Authentication PackageAuthentication
+
+	// Source, if set, is a description of the installation source that
+	// produced this meta, in the form returned by Source.ForDisplay. This
+	// is populated by MultiSource so that callers can report which of its
+	// underlying sources a package came from.
+	Source string
 }
 
 // LessThan returns true if the receiver should sort before the given other
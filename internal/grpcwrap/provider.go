@@ -57,19 +57,23 @@ func (p *provider) GetSchema(_ context.Context, req *tfplugin5.GetProviderSchema
 
 	for typ, res := range p.schema.ResourceTypes {
 		resp.ResourceSchemas[typ] = &tfplugin5.Schema{
-			Version: res.Version,
-			Block:   convert.ConfigSchemaToProto(res.Block),
+			Version:         res.Version,
+			Block:           convert.ConfigSchemaToProto(res.Block),
+			PermissionHints: convert.PermissionHintsToProto(res.PermissionHints),
 		}
 	}
 	for typ, dat := range p.schema.DataSources {
 		resp.DataSourceSchemas[typ] = &tfplugin5.Schema{
-			Version: dat.Version,
-			Block:   convert.ConfigSchemaToProto(dat.Block),
+			Version:         dat.Version,
+			Block:           convert.ConfigSchemaToProto(dat.Block),
+			PermissionHints: convert.PermissionHintsToProto(dat.PermissionHints),
 		}
 	}
 
 	resp.ServerCapabilities = &tfplugin5.ServerCapabilities{
-		PlanDestroy: p.schema.ServerCapabilities.PlanDestroy,
+		PlanDestroy:         p.schema.ServerCapabilities.PlanDestroy,
+		CheckResourceQuotas: p.schema.ServerCapabilities.CheckResourceQuotas,
+		ListResources:       p.schema.ServerCapabilities.ListResources,
 	}
 
 	// include any diagnostics from the original GetSchema call
@@ -356,10 +360,57 @@ func (p *provider) ImportResourceState(_ context.Context, req *tfplugin5.ImportR
 	return resp, nil
 }
 
+func (p *provider) ListResources(_ context.Context, req *tfplugin5.ListResources_Request) (*tfplugin5.ListResources_Response, error) {
+	resp := &tfplugin5.ListResources_Response{}
+
+	filter, err := decodeDynamicValue(req.Filter, cty.DynamicPseudoType)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+
+	listResp := p.provider.ListResources(providers.ListResourcesRequest{
+		TypeName: req.TypeName,
+		Filter:   filter,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, listResp.Diagnostics)
+	for _, res := range listResp.Resources {
+		resp.Resources = append(resp.Resources, &tfplugin5.ListResources_Resource{
+			Id:   res.ID,
+			Name: res.Name,
+		})
+	}
+
+	return resp, nil
+}
+
 func (p *provider) MoveResourceState(context.Context, *tfplugin5.MoveResourceState_Request) (*tfplugin5.MoveResourceState_Response, error) {
 	panic("Not Implemented")
 }
 
+func (p *provider) CheckResourceQuotas(_ context.Context, req *tfplugin5.CheckResourceQuotas_Request) (*tfplugin5.CheckResourceQuotas_Response, error) {
+	resp := &tfplugin5.CheckResourceQuotas_Response{}
+
+	creates := make(map[string]int, len(req.Creates))
+	for typeName, count := range req.Creates {
+		creates[typeName] = int(count)
+	}
+	quotasResp := p.provider.CheckResourceQuotas(providers.CheckResourceQuotasRequest{
+		Creates: creates,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, quotasResp.Diagnostics)
+	for _, risk := range quotasResp.Risks {
+		resp.Risks = append(resp.Risks, &tfplugin5.CheckResourceQuotas_QuotaRisk{
+			TypeName:  risk.TypeName,
+			Requested: int64(risk.Requested),
+			Available: int64(risk.Available),
+			Detail:    risk.Detail,
+		})
+	}
+
+	return resp, nil
+}
+
 func (p *provider) ReadDataSource(_ context.Context, req *tfplugin5.ReadDataSource_Request) (*tfplugin5.ReadDataSource_Response, error) {
 	resp := &tfplugin5.ReadDataSource_Response{}
 	ty := p.schema.DataSources[req.TypeName].Block.ImpliedType()
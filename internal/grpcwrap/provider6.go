@@ -57,19 +57,23 @@ func (p *provider6) GetProviderSchema(_ context.Context, req *tfplugin6.GetProvi
 
 	for typ, res := range p.schema.ResourceTypes {
 		resp.ResourceSchemas[typ] = &tfplugin6.Schema{
-			Version: res.Version,
-			Block:   convert.ConfigSchemaToProto(res.Block),
+			Version:         res.Version,
+			Block:           convert.ConfigSchemaToProto(res.Block),
+			PermissionHints: convert.PermissionHintsToProto(res.PermissionHints),
 		}
 	}
 	for typ, dat := range p.schema.DataSources {
 		resp.DataSourceSchemas[typ] = &tfplugin6.Schema{
-			Version: dat.Version,
-			Block:   convert.ConfigSchemaToProto(dat.Block),
+			Version:         dat.Version,
+			Block:           convert.ConfigSchemaToProto(dat.Block),
+			PermissionHints: convert.PermissionHintsToProto(dat.PermissionHints),
 		}
 	}
 
 	resp.ServerCapabilities = &tfplugin6.ServerCapabilities{
-		PlanDestroy: p.schema.ServerCapabilities.PlanDestroy,
+		PlanDestroy:         p.schema.ServerCapabilities.PlanDestroy,
+		CheckResourceQuotas: p.schema.ServerCapabilities.CheckResourceQuotas,
+		ListResources:       p.schema.ServerCapabilities.ListResources,
 	}
 
 	// include any diagnostics from the original GetSchema call
@@ -356,10 +360,57 @@ func (p *provider6) ImportResourceState(_ context.Context, req *tfplugin6.Import
 	return resp, nil
 }
 
+func (p *provider6) ListResources(_ context.Context, req *tfplugin6.ListResources_Request) (*tfplugin6.ListResources_Response, error) {
+	resp := &tfplugin6.ListResources_Response{}
+
+	filter, err := decodeDynamicValue6(req.Filter, cty.DynamicPseudoType)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+
+	listResp := p.provider.ListResources(providers.ListResourcesRequest{
+		TypeName: req.TypeName,
+		Filter:   filter,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, listResp.Diagnostics)
+	for _, res := range listResp.Resources {
+		resp.Resources = append(resp.Resources, &tfplugin6.ListResources_Resource{
+			Id:   res.ID,
+			Name: res.Name,
+		})
+	}
+
+	return resp, nil
+}
+
 func (p *provider6) MoveResourceState(context.Context, *tfplugin6.MoveResourceState_Request) (*tfplugin6.MoveResourceState_Response, error) {
 	panic("Not Implemented")
 }
 
+func (p *provider6) CheckResourceQuotas(_ context.Context, req *tfplugin6.CheckResourceQuotas_Request) (*tfplugin6.CheckResourceQuotas_Response, error) {
+	resp := &tfplugin6.CheckResourceQuotas_Response{}
+
+	creates := make(map[string]int, len(req.Creates))
+	for typeName, count := range req.Creates {
+		creates[typeName] = int(count)
+	}
+	quotasResp := p.provider.CheckResourceQuotas(providers.CheckResourceQuotasRequest{
+		Creates: creates,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, quotasResp.Diagnostics)
+	for _, risk := range quotasResp.Risks {
+		resp.Risks = append(resp.Risks, &tfplugin6.CheckResourceQuotas_QuotaRisk{
+			TypeName:  risk.TypeName,
+			Requested: int64(risk.Requested),
+			Available: int64(risk.Available),
+			Detail:    risk.Detail,
+		})
+	}
+
+	return resp, nil
+}
+
 func (p *provider6) ReadDataSource(_ context.Context, req *tfplugin6.ReadDataSource_Request) (*tfplugin6.ReadDataSource_Response, error) {
 	resp := &tfplugin6.ReadDataSource_Response{}
 	ty := p.schema.DataSources[req.TypeName].Block.ImpliedType()
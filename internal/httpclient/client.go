@@ -13,12 +13,13 @@ import (
 )
 
 // New returns the DefaultPooledClient from the cleanhttp
-// package that will also send a OpenTofu User-Agent string.
+// package that will also send a OpenTofu User-Agent string, and that
+// refuses to make any requests while offline mode is enabled.
 func New() *http.Client {
 	cli := cleanhttp.DefaultPooledClient()
 	cli.Transport = &userAgentRoundTripper{
 		userAgent: OpenTofuUserAgent(version.Version),
-		inner:     cli.Transport,
+		inner:     OfflineRoundTripper(cli.Transport),
 	}
 	return cli
 }
@@ -0,0 +1,77 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var offline atomic.Bool
+
+// SetOffline enables or disables offline mode, in which every request made
+// by a client returned from New, or through a transport wrapped with
+// OfflineRoundTripper, fails immediately with ErrOffline instead of
+// accessing the network.
+//
+// Offline mode is intended for air-gapped environments, where it's better
+// to fail fast and name the host OpenTofu tried to reach than to wait for
+// a connection attempt to time out.
+func SetOffline(enabled bool) {
+	offline.Store(enabled)
+}
+
+// Offline returns true if offline mode is enabled.
+func Offline() bool {
+	return offline.Load()
+}
+
+// ErrOffline is the error returned for a network request made while offline
+// mode is enabled.
+type ErrOffline struct {
+	// Host is the host that OpenTofu tried to connect to.
+	Host string
+}
+
+func (err ErrOffline) Error() string {
+	return fmt.Sprintf("cannot connect to %s because OpenTofu is running in offline mode", err.Host)
+}
+
+// OfflineRoundTripper wraps the given transport so that it fails with
+// ErrOffline while offline mode is enabled, for HTTP clients that are not
+// created using New.
+func OfflineRoundTripper(inner http.RoundTripper) http.RoundTripper {
+	return &offlineRoundTripper{inner: inner}
+}
+
+type offlineRoundTripper struct {
+	inner http.RoundTripper
+}
+
+func (rt *offlineRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if Offline() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrOffline{Host: req.URL.Host}
+	}
+	return rt.inner.RoundTrip(req)
+}
+
+// CheckRetry is a retry policy for retryablehttp clients that behaves like
+// retryablehttp.DefaultRetryPolicy, except that it doesn't retry requests
+// that failed because offline mode is enabled.
+func CheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if errors.As(err, &ErrOffline{}) {
+		return false, err
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNew_offline(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	SetOffline(true)
+	defer SetOffline(false)
+
+	_, err := New().Get(ts.URL)
+	var offlineErr ErrOffline
+	if !errors.As(err, &offlineErr) {
+		t.Fatalf("wrong error: %s", err)
+	}
+	if got, want := offlineErr.Host, ts.Listener.Addr().String(); got != want {
+		t.Errorf("wrong host %q; want %q", got, want)
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests while offline", requests)
+	}
+
+	retry, _ := CheckRetry(context.Background(), nil, err)
+	if retry {
+		t.Errorf("request would be retried while offline")
+	}
+
+	SetOffline(false)
+	if _, err := New().Get(ts.URL); err != nil {
+		t.Fatalf("unexpected error after leaving offline mode: %s", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests; want 1", requests)
+	}
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getmodules"
+	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/modsdir"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/registry/regsrc"
@@ -44,6 +46,17 @@ type ModuleInstaller struct {
 	// The keys in moduleVersionsUrl are the moduleVersion struct below and
 	// addresses and the values are underlying remote source addresses.
 	registryPackageSources map[moduleVersion]addrs.ModuleSourceRemote
+
+	// packageCache, if set, is a module package cache shared with other
+	// working directories.
+	packageCache *getmodules.PackageCache
+
+	// locks, if set, are the dependency locks that the installer selects
+	// module versions from, verifies module packages against and records
+	// the installed module packages in. lockedModules tracks which of the
+	// locked modules are still in use.
+	locks         *depsfile.Locks
+	lockedModules map[string]bool
 }
 
 type moduleVersion struct {
@@ -61,6 +74,27 @@ func NewModuleInstaller(modsDir string, loader *configload.Loader, reg *registry
 	}
 }
 
+// SetPackageCache configures the installer to install remote module packages
+// from the given shared cache where possible, and to add the packages it
+// fetches to that cache.
+func (i *ModuleInstaller) SetPackageCache(cache *getmodules.PackageCache) {
+	i.packageCache = cache
+}
+
+// SetLocks configures the installer to use the module locks in the given
+// dependency locks, which it updates in place to describe the installed
+// module packages.
+//
+// Unless upgrading, the installer then selects the locked version of each
+// registry module if it still meets the version constraints, and fails if
+// the content of a module package it fetches doesn't match its recorded
+// checksum, which means that the module changed upstream since it was
+// locked. Locks for modules that are no longer used are removed, unless
+// installation fails.
+func (i *ModuleInstaller) SetLocks(locks *depsfile.Locks) {
+	i.locks = locks
+}
+
 // InstallModules analyses the root module in the given directory and installs
 // all of its direct and transitive dependencies into the given modules
 // directory, which must already exist.
@@ -125,6 +159,11 @@ func (i *ModuleInstaller) InstallModules(ctx context.Context, rootDir, testsDir
 	}
 
 	fetcher := getmodules.NewPackageFetcher()
+	if i.packageCache != nil {
+		// When upgrading we must fetch the packages again, because the
+		// content at a package address can change over time.
+		fetcher.SetCache(i.packageCache, upgrade)
+	}
 
 	if hooks == nil {
 		// Use our no-op implementation as a placeholder
@@ -139,9 +178,19 @@ func (i *ModuleInstaller) InstallModules(ctx context.Context, rootDir, testsDir
 	}
 	walker := i.moduleInstallWalker(ctx, manifest, upgrade, hooks, fetcher)
 
+	i.lockedModules = make(map[string]bool)
 	cfg, instDiags := i.installDescendentModules(rootMod, manifest, walker, installErrsOnly)
 	diags = append(diags, instDiags...)
 
+	if i.locks != nil && !diags.HasErrors() {
+		for path := range i.locks.AllModules() {
+			if !i.lockedModules[path] {
+				log.Printf("[TRACE] ModuleInstaller: removing lock for %s, which is no longer used", path)
+				i.locks.RemoveModule(path)
+			}
+		}
+	}
+
 	return cfg, diags
 }
 
@@ -175,9 +224,19 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 
 			key := manifest.ModuleKey(req.Path)
 			instPath := i.packageInstallPath(req.Path)
+			_, isLocal := req.SourceAddr.(addrs.ModuleSourceLocal)
 
 			log.Printf("[DEBUG] Module installer: begin %s", key)
 
+			// A lock only applies while the module's source is unchanged, and
+			// we disregard locks entirely when upgrading.
+			var lock *depsfile.ModuleLock
+			if i.locks != nil && !upgrade && !isLocal {
+				if l := i.locks.Module(key); l != nil && l.Source() == req.SourceAddr.String() {
+					lock = l
+				}
+			}
+
 			// First we'll check if we need to upgrade/replace an existing
 			// installed module, and delete it out of the way if so.
 			replace := upgrade
@@ -193,6 +252,9 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 				case record.Version != nil && !req.VersionConstraint.Required.Check(record.Version):
 					log.Printf("[TRACE] ModuleInstaller: %s version %s no longer compatible with constraints %s", key, record.Version, req.VersionConstraint.Required)
 					replace = true
+				case lock != nil && !installedMatchesLock(instPath, record, lock):
+					log.Printf("[TRACE] ModuleInstaller: %s installed package doesn't match its lock", key)
+					replace = true
 				}
 			}
 
@@ -255,6 +317,14 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 						diags = diags.Extend(mDiags)
 					}
 
+					if !isLocal {
+						if lock == nil {
+							diags = diags.Extend(i.lockModule(req, key, instPath, record.Version, nil))
+						} else {
+							i.lockedModules[key] = true
+						}
+					}
+
 					log.Printf("[TRACE] ModuleInstaller: Module installer: %s %s already installed in %s", key, record.Version, record.Dir)
 					return mod, record.Version, diags
 				}
@@ -275,13 +345,13 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 
 			case addrs.ModuleSourceRegistry:
 				log.Printf("[TRACE] ModuleInstaller: %s is a registry module at %s", key, addr.String())
-				mod, v, mDiags := i.installRegistryModule(ctx, req, key, instPath, addr, manifest, hooks, fetcher)
+				mod, v, mDiags := i.installRegistryModule(ctx, req, key, instPath, addr, manifest, hooks, fetcher, lock)
 				diags = append(diags, mDiags...)
 				return mod, v, diags
 
 			case addrs.ModuleSourceRemote:
 				log.Printf("[TRACE] ModuleInstaller: %s address %q will be handled by go-getter", key, addr.String())
-				mod, mDiags := i.installGoGetterModule(ctx, req, key, instPath, manifest, hooks, fetcher)
+				mod, mDiags := i.installGoGetterModule(ctx, req, key, instPath, manifest, hooks, fetcher, lock)
 				diags = append(diags, mDiags...)
 				return mod, nil, diags
 
@@ -418,7 +488,7 @@ func (i *ModuleInstaller) installLocalModule(req *configs.ModuleRequest, key str
 // public hashicorp/go-version API.
 var versionRegexp = regexp.MustCompile(version.VersionRegexpRaw)
 
-func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, addr addrs.ModuleSourceRegistry, manifest modsdir.Manifest, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher) (*configs.Module, *version.Version, hcl.Diagnostics) {
+func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, addr addrs.ModuleSourceRegistry, manifest modsdir.Manifest, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher, lock *depsfile.ModuleLock) (*configs.Module, *version.Version, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	hostname := addr.Package.Host
@@ -492,8 +562,17 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 
 	modMeta := resp.Modules[0]
 
+	var lockedVersion *version.Version
+	if lock != nil && lock.Version() != "" {
+		// An invalid version in the lock file just means we'll select a
+		// new version as if there were no lock.
+		lockedVersion, _ = version.NewVersion(lock.Version())
+	}
+
 	var latestMatch *version.Version
 	var latestVersion *version.Version
+	var lockedMatch *version.Version
+	deprecations := make(map[string]*response.ModuleVersionDeprecation)
 	for _, mv := range modMeta.Versions {
 		v, err := version.NewVersion(mv.Version)
 		if err != nil {
@@ -603,11 +682,17 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 		if latestVersion == nil || v.GreaterThan(latestVersion) {
 			latestVersion = v
 		}
+		if mv.Deprecation != nil {
+			deprecations[v.String()] = mv.Deprecation
+		}
 
 		if req.VersionConstraint.Required.Check(v) {
 			if latestMatch == nil || v.GreaterThan(latestMatch) {
 				latestMatch = v
 			}
+			if lockedVersion != nil && v.Equal(lockedVersion) {
+				lockedMatch = v
+			}
 		}
 	}
 
@@ -631,6 +716,29 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 		return nil, nil, diags
 	}
 
+	if lockedMatch != nil {
+		// The locked version takes priority over any newer versions, so
+		// that modules only change when intentionally upgraded.
+		log.Printf("[TRACE] ModuleInstaller: %s selecting locked version %s rather than newest matching version %s", key, lockedMatch, latestMatch)
+		latestMatch = lockedMatch
+	}
+
+	if deprecation := deprecations[latestMatch.String()]; deprecation != nil {
+		detail := fmt.Sprintf("Version %s of module %q (%s:%d) is deprecated on %s.", latestMatch, addr, req.CallRange.Filename, req.CallRange.Start.Line, hostname)
+		if deprecation.Reason != "" {
+			detail += "\n\n" + deprecation.Reason
+		}
+		if deprecation.Link != "" {
+			detail += fmt.Sprintf("\n\nFor more information, see %s.", deprecation.Link)
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated module version",
+			Detail:   detail,
+			Subject:  req.CallRange.Ptr(),
+		})
+	}
+
 	// Report up to the caller that we're about to start downloading.
 	hooks.Download(key, packageAddr.String(), latestMatch)
 
@@ -707,6 +815,10 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 
 	log.Printf("[TRACE] ModuleInstaller: %s %q was downloaded to %s", key, dlAddr.Package, instPath)
 
+	if lockDiags := i.lockModule(req, key, instPath, latestMatch, lock); lockDiags.HasErrors() {
+		return nil, nil, diags.Extend(lockDiags)
+	}
+
 	// Incorporate any subdir information from the original path into the
 	// address returned by the registry in order to find the final directory
 	// of the target module.
@@ -751,7 +863,7 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 	return mod, latestMatch, diags
 }
 
-func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, manifest modsdir.Manifest, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher) (*configs.Module, hcl.Diagnostics) {
+func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *configs.ModuleRequest, key string, instPath string, manifest modsdir.Manifest, hooks ModuleInstallHooks, fetcher *getmodules.PackageFetcher, lock *depsfile.ModuleLock) (*configs.Module, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	// Report up to the caller that we're about to start downloading.
@@ -805,6 +917,10 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 		return nil, diags
 	}
 
+	if lockDiags := i.lockModule(req, key, instPath, nil, lock); lockDiags.HasErrors() {
+		return nil, diags.Extend(lockDiags)
+	}
+
 	modDir, err := getmodules.ExpandSubdirGlobs(instPath, addr.Subdir)
 	if err != nil {
 		diags = diags.Append(&hcl.Diagnostic{
@@ -851,6 +967,67 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 	return mod, diags
 }
 
+// lockModule records the module package installed in instPath for the given
+// module call in the installer's locks, if any. If the given previous lock
+// is for the same version, the package must match its checksum.
+func (i *ModuleInstaller) lockModule(req *configs.ModuleRequest, key, instPath string, v *version.Version, lock *depsfile.ModuleLock) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if i.locks == nil {
+		return diags
+	}
+
+	rawHash, err := getmodules.HashPackageDir(instPath)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to compute module checksum",
+			Detail:   fmt.Sprintf("OpenTofu could not compute the checksum of module %q (%s:%d) to record it in the dependency lock file: %s.", req.Name, req.CallRange.Filename, req.CallRange.Start.Line, err),
+			Subject:  req.CallRange.Ptr(),
+		})
+		return diags
+	}
+	hash := getproviders.Hash(rawHash)
+	versionStr := ""
+	if v != nil {
+		versionStr = v.String()
+	}
+	commit := getmodules.PackageGitCommit(instPath)
+
+	if lock != nil && lock.Version() == versionStr {
+		if lock.Hash() != hash {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Module doesn't match the dependency lock file",
+				Detail: fmt.Sprintf(
+					"The content of module %q (%s:%d) from %s has checksum %s, but the dependency lock file records %s. The module may have changed at its source since it was locked.\n\nIf you intended to update the module, run:\n  tofu init -upgrade-modules",
+					req.Name, req.CallRange.Filename, req.CallRange.Start.Line, req.SourceAddr, hash, lock.Hash(),
+				),
+				Subject: req.CallRange.Ptr(),
+			})
+			return diags
+		}
+		if commit == "" {
+			// The package may have come from a cache that doesn't retain
+			// the Git metadata, but its content is identical.
+			commit = lock.Commit()
+		}
+	}
+
+	i.locks.SetModule(key, req.SourceAddr.String(), versionStr, commit, hash)
+	i.lockedModules[key] = true
+	return diags
+}
+
+// installedMatchesLock returns true if the module package already installed
+// in instPath is the one described by the given lock.
+func installedMatchesLock(instPath string, record modsdir.Record, lock *depsfile.ModuleLock) bool {
+	if lock.Version() != "" && (record.Version == nil || record.Version.String() != lock.Version()) {
+		return false
+	}
+	hash, err := getmodules.HashPackageDir(instPath)
+	return err == nil && getproviders.Hash(hash) == lock.Hash()
+}
+
 func (i *ModuleInstaller) packageInstallPath(modulePath addrs.Module) string {
 	return filepath.Join(i.modsDir, strings.Join(modulePath, "."))
 }
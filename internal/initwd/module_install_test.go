@@ -25,6 +25,8 @@ import (
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/registry"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 
@@ -235,6 +237,80 @@ func TestModuleInstaller_explicitPackageBoundary(t *testing.T) {
 	}
 }
 
+func TestModuleInstaller_locks(t *testing.T) {
+	fixtureDir := filepath.Clean("testdata/load-module-package-prefix")
+	dir, done := tempChdir(t, fixtureDir)
+	defer done()
+
+	// As in TestModuleInstaller_explicitPackageBoundary, we need an absolute
+	// path so that the child module is a separate, remote module package.
+	{
+		rootFilename := filepath.Join(dir, "package-prefix.tf")
+		template, err := os.ReadFile(rootFilename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		final := bytes.ReplaceAll(template, []byte("%%BASE%%"), []byte(filepath.ToSlash(dir)))
+		err = os.WriteFile(rootFilename, final, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modulesDir := filepath.Join(dir, ".terraform/modules")
+	install := func(locks *depsfile.Locks, upgrade bool) tfdiags.Diagnostics {
+		t.Helper()
+		loader, close := configload.NewLoaderForTests(t)
+		defer close()
+		inst := NewModuleInstaller(modulesDir, loader, nil)
+		inst.SetLocks(locks)
+		_, diags := inst.InstallModules(context.Background(), ".", "tests", upgrade, false, &testInstallHooks{}, configs.RootModuleCallForTesting())
+		return diags
+	}
+
+	locks := depsfile.NewLocks()
+	locks.SetModule("unused", "git::https://example.com/unused.git", "", "", getproviders.Hash("h1:unused"))
+	if diags := install(locks, false); diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	if got, want := len(locks.AllModules()), 1; got != want {
+		t.Fatalf("wrong number of module locks %d; want %d", got, want)
+	}
+	lock := locks.Module("child")
+	if lock == nil {
+		t.Fatal("no lock for module.child")
+	}
+	if got, want := lock.Source(), "file://"+filepath.ToSlash(dir)+"/package//child"; got != want {
+		t.Errorf("wrong source %q; want %q", got, want)
+	}
+	if !strings.HasPrefix(lock.Hash().String(), "h1:") {
+		t.Errorf("wrong hash %q", lock.Hash())
+	}
+	hash := lock.Hash()
+
+	// Changing the module upstream must not be silently accepted.
+	err := os.WriteFile(filepath.Join(dir, "package/child/extra.tf"), []byte("# changed\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(modulesDir); err != nil {
+		t.Fatal(err)
+	}
+	diags := install(locks, false)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	assertDiagnosticSummary(t, diags, "Module doesn't match the dependency lock file")
+
+	// ...unless we're intentionally upgrading.
+	if diags := install(locks, true); diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	if got := locks.Module("child").Hash(); got == hash {
+		t.Errorf("hash was not updated after upgrade")
+	}
+}
+
 func TestModuleInstaller_Prerelease(t *testing.T) {
 	if os.Getenv("TF_ACC") == "" {
 		t.Skip("this test accesses registry.opentofu.org and github.com; set TF_ACC=1 to run it")
@@ -50,7 +50,7 @@ func EvaluateCountExpression(expr hcl.Expression, ctx EvaluateFunc, excludableAd
 			// we can't easily do that right now because the hcl.EvalContext
 			// (which is not the same as the ctx we have in scope here) is
 			// hidden away inside evaluateCountExpressionValue.
-			Extra: DiagnosticCausedByUnknown(true),
+			Extra: tfdiags.RuleExtra(tfdiags.RuleInvalidCount, DiagnosticCausedByUnknown(true)),
 		})
 	}
 
@@ -89,6 +89,7 @@ func EvaluateCountExpressionValue(expr hcl.Expression, ctx EvaluateFunc) (cty.Va
 			Summary:  "Invalid count argument",
 			Detail:   `The given "count" argument value is null. An integer is required.`,
 			Subject:  expr.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidCount, nil),
 		})
 		return nullCount, diags
 
@@ -104,6 +105,7 @@ func EvaluateCountExpressionValue(expr hcl.Expression, ctx EvaluateFunc) (cty.Va
 			Summary:  "Invalid count argument",
 			Detail:   fmt.Sprintf(`The given "count" argument value is unsuitable: %s.`, err),
 			Subject:  expr.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidCount, nil),
 		})
 		return nullCount, diags
 	}
@@ -113,6 +115,7 @@ func EvaluateCountExpressionValue(expr hcl.Expression, ctx EvaluateFunc) (cty.Va
 			Summary:  "Invalid count argument",
 			Detail:   `The given "count" argument value is unsuitable: must be greater than or equal to zero.`,
 			Subject:  expr.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidCount, nil),
 		})
 		return nullCount, diags
 	}
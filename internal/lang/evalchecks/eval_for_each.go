@@ -88,7 +88,7 @@ func EvaluateForEachExpressionValue(expr hcl.Expression, ctx ContextFunc, allowU
 			Subject:     expr.Range().Ptr(),
 			Expression:  expr,
 			EvalContext: hclCtx,
-			Extra:       DiagnosticCausedBySensitive(true),
+			Extra:       tfdiags.RuleExtra(tfdiags.RuleInvalidForEach, DiagnosticCausedBySensitive(true)),
 		})
 	}
 
@@ -113,6 +113,7 @@ func EvaluateForEachExpressionValue(expr hcl.Expression, ctx ContextFunc, allowU
 			Subject:     expr.Range().Ptr(),
 			Expression:  expr,
 			EvalContext: hclCtx,
+			Extra:       tfdiags.RuleExtra(tfdiags.RuleInvalidForEach, nil),
 		})
 	}
 
@@ -144,6 +145,7 @@ func performForEachValueChecks(expr hcl.Expression, hclCtx *hcl.EvalContext, all
 			Subject:     expr.Range().Ptr(),
 			Expression:  expr,
 			EvalContext: hclCtx,
+			Extra:       tfdiags.RuleExtra(tfdiags.RuleInvalidForEach, nil),
 		})
 		return nullMap, diags
 	case !forEachVal.IsKnown():
@@ -164,7 +166,7 @@ func performForEachValueChecks(expr hcl.Expression, hclCtx *hcl.EvalContext, all
 				Subject:     expr.Range().Ptr(),
 				Expression:  expr,
 				EvalContext: hclCtx,
-				Extra:       DiagnosticCausedByUnknown(true),
+				Extra:       tfdiags.RuleExtra(tfdiags.RuleInvalidForEach, DiagnosticCausedByUnknown(true)),
 			})
 		}
 		// ensure that we have a map, and not a DynamicValue
@@ -203,7 +205,7 @@ func performSetTypeChecks(expr hcl.Expression, hclCtx *hcl.EvalContext, allowUnk
 				Subject:     expr.Range().Ptr(),
 				Expression:  expr,
 				EvalContext: hclCtx,
-				Extra:       DiagnosticCausedByUnknown(true),
+				Extra:       tfdiags.RuleExtra(tfdiags.RuleInvalidForEach, DiagnosticCausedByUnknown(true)),
 			})
 		}
 		return cty.UnknownVal(ty), diags
@@ -244,6 +244,10 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`index` finds the element index for a given value in a list.",
 		ParamDescription: []string{"", ""},
 	},
+	"inidecode": {
+		Description:      "`inidecode` parses a string in INI format, and produces a map of its sections, each of which is a map of its keys and values.",
+		ParamDescription: []string{""},
+	},
 	"issensitive": {
 		Description:      "`issensitive` takes any value and returns `true` if the value is marked as sensitive, and `false` otherwise.",
 		ParamDescription: []string{""},
@@ -428,8 +432,12 @@ var DescriptionList = map[string]descriptionEntry{
 		ParamDescription: []string{""},
 	},
 	"templatefile": {
-		Description:      "`templatefile` reads the file at the given path and renders its content as a template using a supplied set of template variables.",
-		ParamDescription: []string{"", ""},
+		Description: "`templatefile` reads the file at the given path and renders its content as a template using a supplied set of template variables.",
+		ParamDescription: []string{
+			"",
+			"",
+			"Optional set of the names of the only functions that the template may call.",
+		},
 	},
 	"templatestring": {
 		Description:      "`templatestring` processes the provided string as a template using a supplied set of template variables.",
@@ -475,6 +483,14 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`tomap` converts its argument to a map value.",
 		ParamDescription: []string{""},
 	},
+	"tomldecode": {
+		Description:      "`tomldecode` parses a string as a [TOML](https://toml.io/) document, and produces a representation of its root table.",
+		ParamDescription: []string{""},
+	},
+	"tomlencode": {
+		Description:      "`tomlencode` encodes a given object or map value to a string using [TOML](https://toml.io/) syntax.",
+		ParamDescription: []string{""},
+	},
 	"tonumber": {
 		Description:      "`tonumber` converts its argument to a number value.",
 		ParamDescription: []string{""},
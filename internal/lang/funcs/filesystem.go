@@ -22,6 +22,8 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/opentofu/opentofu/internal/addrs"
 )
 
 // MakeFileFunc constructs a function that takes a file path and returns the
@@ -115,12 +117,17 @@ func (err ErrorTemplateRecursionLimit) Error() string {
 // those variables provided in the second function argument, to ensure that all
 // dependencies on other graph nodes can be seen before executing this function.
 //
+// An optional third argument is a set of function names that restricts which
+// functions the template, and any templates it renders in turn, may call.
+//
 // As a special exception, a referenced template file may call the templatefile
-// function, with a recursion depth limit providing an error when reached
+// function, with a recursion depth limit providing an error when reached. It
+// may also call the include function, which renders another template file
+// relative to the directory of the referencing template.
 func MakeTemplateFileFunc(baseDir string, funcsCb func() map[string]function.Function) function.Function {
-	return makeTemplateFileFuncImpl(baseDir, funcsCb, 0)
+	return makeTemplateFileFuncImpl(baseDir, funcsCb, 0, nil)
 }
-func makeTemplateFileFuncImpl(baseDir string, funcsCb func() map[string]function.Function, depth int) function.Function {
+func makeTemplateFileFuncImpl(baseDir string, funcsCb func() map[string]function.Function, depth int, allowed map[string]bool) function.Function {
 	params := []function.Parameter{
 		{
 			Name:        "path",
@@ -133,83 +140,210 @@ func makeTemplateFileFuncImpl(baseDir string, funcsCb func() map[string]function
 		},
 	}
 
-	loadTmpl := func(path string, marks cty.ValueMarks) (hcl.Expression, error) {
-		maxDepth, err := templateMaxRecursionDepth()
-		if err != nil {
-			return nil, err
-		}
-		if depth > maxDepth {
-			// Sources will unwind up the stack
-			return nil, ErrorTemplateRecursionLimit{}
-		}
-
-		// We re-use File here to ensure the same filename interpretation
-		// as it does, along with its other safety checks.
-		templateValue, err := File(baseDir, cty.StringVal(path).WithMarks(marks))
-		if err != nil {
-			return nil, err
-		}
-
-		// unmark the template ready to be handled
-		templateValue, _ = templateValue.Unmark()
-
-		expr, diags := hclsyntax.ParseTemplate([]byte(templateValue.AsString()), path, hcl.Pos{Line: 1, Column: 1})
-		if diags.HasErrors() {
-			return nil, diags
-		}
-
-		return expr, nil
-	}
-
-	funcsCbDepth := func() map[string]function.Function {
-		givenFuncs := funcsCb() // this callback indirection is to avoid chicken/egg problems
-		funcs := make(map[string]function.Function, len(givenFuncs))
-		for name, fn := range givenFuncs {
-			if name == "templatefile" {
-				// Increment the recursion depth counter
-				funcs[name] = makeTemplateFileFuncImpl(baseDir, funcsCb, depth+1)
-				continue
-			}
-			funcs[name] = fn
-		}
-		return funcs
-	}
-
 	return function.New(&function.Spec{
 		Params: params,
+		VarParam: &function.Parameter{
+			Name: "functions",
+			Type: cty.Set(cty.String),
+		},
 		Type: func(args []cty.Value) (cty.Type, error) {
 			if !(args[0].IsKnown() && args[1].IsKnown()) {
 				return cty.DynamicPseudoType, nil
 			}
+			for _, arg := range args[2:] {
+				if !arg.IsWhollyKnown() {
+					return cty.DynamicPseudoType, nil
+				}
+			}
 
 			// We'll render our template now to see what result type it produces.
 			// A template consisting only of a single interpolation an potentially
 			// return any type.
 
-			pathArg, pathMarks := args[0].Unmark()
-			expr, err := loadTmpl(pathArg.AsString(), pathMarks)
+			fnAllowed, err := templateFuncAllowlist(args[2:], allowed, funcsCb())
 			if err != nil {
 				return cty.DynamicPseudoType, err
 			}
 
 			// This is safe even if args[1] contains unknowns because the HCL
 			// template renderer itself knows how to short-circuit those.
-			val, err := renderTemplate(expr, args[1], funcsCbDepth())
+			pathArg, pathMarks := args[0].Unmark()
+			val, err := renderTemplateFile(baseDir, pathArg.AsString(), pathMarks, args[1], funcsCb, depth, fnAllowed)
 			return val.Type(), err
 		},
 		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-			pathArg, pathMarks := args[0].Unmark()
-			expr, err := loadTmpl(pathArg.AsString(), pathMarks)
+			fnAllowed, err := templateFuncAllowlist(args[2:], allowed, funcsCb())
 			if err != nil {
 				return cty.DynamicVal, err
 			}
 
-			result, err := renderTemplate(expr, args[1], funcsCbDepth())
+			pathArg, pathMarks := args[0].Unmark()
+			result, err := renderTemplateFile(baseDir, pathArg.AsString(), pathMarks, args[1], funcsCb, depth, fnAllowed)
 			return result.WithMarks(pathMarks), err
 		},
 	})
 }
 
+// makeTemplateIncludeFunc constructs the include function that is available
+// in templates rendered by templatefile. It renders the template file at the
+// given path, relative to dir, with either the given vars or the vars of the
+// including template.
+func makeTemplateIncludeFunc(baseDir, dir string, parentVars cty.Value, funcsCb func() map[string]function.Function, depth int, allowed map[string]bool) function.Function {
+	resolve := func(args []cty.Value) (string, cty.Value, error) {
+		if len(args) > 2 {
+			return "", cty.NilVal, function.NewArgErrorf(2, "include accepts at most one vars argument")
+		}
+		vars := parentVars
+		if len(args) == 2 {
+			vars = args[1]
+		}
+		path, _ := args[0].Unmark()
+		p := path.AsString()
+		if !filepath.IsAbs(p) && !strings.HasPrefix(p, "~") {
+			p = filepath.Join(dir, p)
+		}
+		return p, vars, nil
+	}
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name:        "path",
+				Type:        cty.String,
+				AllowMarked: true,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "vars",
+			Type: cty.DynamicPseudoType,
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !args[0].IsKnown() {
+				return cty.DynamicPseudoType, nil
+			}
+			path, vars, err := resolve(args)
+			if err != nil {
+				return cty.DynamicPseudoType, err
+			}
+			if !vars.IsKnown() {
+				return cty.DynamicPseudoType, nil
+			}
+			_, pathMarks := args[0].Unmark()
+			val, err := renderTemplateFile(baseDir, path, pathMarks, vars, funcsCb, depth, allowed)
+			return val.Type(), err
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path, vars, err := resolve(args)
+			if err != nil {
+				return cty.DynamicVal, err
+			}
+			_, pathMarks := args[0].Unmark()
+			result, err := renderTemplateFile(baseDir, path, pathMarks, vars, funcsCb, depth, allowed)
+			return result.WithMarks(pathMarks), err
+		},
+	})
+}
+
+// renderTemplateFile loads the template file at the given path and renders it
+// with the given vars, giving it access to either all of the functions
+// returned by funcsCb, or only the allowed ones if allowed is not nil.
+func renderTemplateFile(baseDir, path string, marks cty.ValueMarks, vars cty.Value, funcsCb func() map[string]function.Function, depth int, allowed map[string]bool) (cty.Value, error) {
+	maxDepth, err := templateMaxRecursionDepth()
+	if err != nil {
+		return cty.DynamicVal, err
+	}
+	if depth > maxDepth {
+		// Sources will unwind up the stack
+		return cty.DynamicVal, ErrorTemplateRecursionLimit{}
+	}
+
+	// We re-use File here to ensure the same filename interpretation
+	// as it does, along with its other safety checks.
+	templateValue, err := File(baseDir, cty.StringVal(path).WithMarks(marks))
+	if err != nil {
+		return cty.DynamicVal, err
+	}
+
+	// unmark the template ready to be handled
+	templateValue, _ = templateValue.Unmark()
+
+	expr, diags := hclsyntax.ParseTemplate([]byte(templateValue.AsString()), path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+
+	givenFuncs := funcsCb() // this callback indirection is to avoid chicken/egg problems
+	funcs := make(map[string]function.Function, len(givenFuncs)+1)
+	for name, fn := range givenFuncs {
+		if allowed != nil && !allowed[strings.TrimPrefix(name, coreFuncPrefix)] {
+			continue
+		}
+		if name == "templatefile" || name == coreFuncPrefix+"templatefile" {
+			// Increment the recursion depth counter
+			funcs[name] = makeTemplateFileFuncImpl(baseDir, funcsCb, depth+1, allowed)
+			continue
+		}
+		funcs[name] = fn
+	}
+	funcs["include"] = makeTemplateIncludeFunc(baseDir, filepath.Dir(path), vars, funcsCb, depth+1, allowed)
+
+	if allowed != nil {
+		// HCL would report a call to a function that isn't allowed as a call
+		// to an unknown function, so we check for these first to explain.
+		var err error
+		hclsyntax.VisitAll(expr.(hclsyntax.Node), func(node hclsyntax.Node) hcl.Diagnostics {
+			call, ok := node.(*hclsyntax.FunctionCallExpr)
+			if !ok || err != nil {
+				return nil
+			}
+			if _, exists := funcs[call.Name]; !exists {
+				if _, exists := givenFuncs[call.Name]; exists {
+					err = fmt.Errorf("function %q, called at %s, is not in the allowlist of functions for this template", call.Name, call.NameRange)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return cty.DynamicVal, err
+		}
+	}
+
+	return renderTemplate(expr, vars, funcs)
+}
+
+// coreFuncPrefix is the prefix of the names under which the built-in
+// functions are also available.
+const coreFuncPrefix = addrs.FunctionNamespaceCore + "::"
+
+// templateFuncAllowlist returns the set of function names given as the
+// optional allowlist argument of templatefile, or the inherited allowlist if
+// the argument isn't present. The given allowlist can only restrict the
+// inherited one further, and must refer only to functions in available.
+func templateFuncAllowlist(args []cty.Value, inherited map[string]bool, available map[string]function.Function) (map[string]bool, error) {
+	switch len(args) {
+	case 0:
+		return inherited, nil
+	case 1:
+		// Handled below
+	default:
+		return nil, function.NewArgErrorf(3, "templatefile accepts at most one functions argument")
+	}
+
+	allowed := make(map[string]bool)
+	for it := args[0].ElementIterator(); it.Next(); {
+		_, v := it.Element()
+		name := strings.TrimPrefix(v.AsString(), coreFuncPrefix)
+		if _, exists := available[name]; !exists {
+			return nil, function.NewArgErrorf(2, "there is no function named %q", name)
+		}
+		if inherited != nil && !inherited[name] {
+			return nil, function.NewArgErrorf(2, "function %q is not in the allowlist of functions for the calling template", name)
+		}
+		allowed[name] = true
+	}
+	return allowed, nil
+}
+
 // MakeFileExistsFunc constructs a function that takes a path
 // and determines whether a file exists at that path
 func MakeFileExistsFunc(baseDir string) function.Function {
@@ -237,6 +237,100 @@ func TestTemplateFile(t *testing.T) {
 	}
 }
 
+func TestTemplateFile_include(t *testing.T) {
+	templateFileFn := MakeTemplateFileFunc(".", func() map[string]function.Function {
+		return map[string]function.Function{
+			"join":         stdlib.JoinFunc,
+			"templatefile": MakeFileFunc(".", false), // just a placeholder, since templatefile itself overrides this
+		}
+	})
+
+	got, err := templateFileFn.Call([]cty.Value{
+		cty.StringVal("testdata/include/main.tmpl"),
+		cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("Jodie"),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := cty.StringVal("Hello, Jodie! Hello, Ada!"); !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestTemplateFile_functionAllowlist(t *testing.T) {
+	tests := map[string]struct {
+		Path      cty.Value
+		Functions cty.Value
+		Want      cty.Value
+		Err       string
+	}{
+		"allowed": {
+			cty.StringVal("testdata/func.tmpl"),
+			cty.SetVal([]cty.Value{cty.StringVal("join")}),
+			cty.StringVal("The items are a, b, c"),
+			``,
+		},
+		"allowed with namespace": {
+			cty.StringVal("testdata/func.tmpl"),
+			cty.SetVal([]cty.Value{cty.StringVal("core::join")}),
+			cty.StringVal("The items are a, b, c"),
+			``,
+		},
+		"not allowed": {
+			cty.StringVal("testdata/func.tmpl"),
+			cty.SetValEmpty(cty.String),
+			cty.NilVal,
+			`function "join", called at testdata/func.tmpl:1,17-21, is not in the allowlist of functions for this template`,
+		},
+		"not allowed in included template": {
+			cty.StringVal("testdata/include/func.tmpl"),
+			cty.SetVal([]cty.Value{cty.StringVal("templatefile")}),
+			cty.NilVal,
+			`testdata/include/func.tmpl:1,3-11: Error in function call; Call to function "include" failed: function "join", called at testdata/func.tmpl:1,17-21, is not in the allowlist of functions for this template.`,
+		},
+		"unknown function": {
+			cty.StringVal("testdata/func.tmpl"),
+			cty.SetVal([]cty.Value{cty.StringVal("frob")}),
+			cty.NilVal,
+			`there is no function named "frob"`,
+		},
+	}
+
+	templateFileFn := MakeTemplateFileFunc(".", func() map[string]function.Function {
+		return map[string]function.Function{
+			"join":         stdlib.JoinFunc,
+			"templatefile": MakeFileFunc(".", false), // just a placeholder, since templatefile itself overrides this
+		}
+	})
+	vars := cty.ObjectVal(map[string]cty.Value{
+		"list": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+	})
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := templateFileFn.Call([]cty.Value{test.Path, vars, test.Functions})
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got, want := err.Error(), test.Err; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
 func Test_templateMaxRecursionDepth(t *testing.T) {
 	tests := []struct {
 		Input string
@@ -0,0 +1,67 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"gopkg.in/ini.v1"
+)
+
+// INIDecodeFunc constructs a function that parses a string in INI format and
+// returns a map of its sections, each of which is a map of its keys.
+var INIDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "src",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Map(cty.Map(cty.String))),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		f, err := ini.LoadSources(ini.LoadOptions{
+			// Values often contain "#" or ";", such as in URLs, so we only
+			// treat them as comments at the start of a line.
+			IgnoreInlineComment: true,
+		}, []byte(args[0].AsString()))
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgErrorf(0, "invalid INI: %s", err)
+		}
+
+		sections := make(map[string]cty.Value)
+		for _, section := range f.Sections() {
+			keys := section.Keys()
+			if section.Name() == ini.DefaultSection && len(keys) == 0 {
+				// The default section always exists, but we only include it
+				// if it has something in it.
+				continue
+			}
+			values := make(map[string]cty.Value, len(keys))
+			for _, key := range keys {
+				values[key.Name()] = cty.StringVal(key.Value())
+			}
+			if len(values) == 0 {
+				sections[section.Name()] = cty.MapValEmpty(cty.String)
+				continue
+			}
+			sections[section.Name()] = cty.MapVal(values)
+		}
+		if len(sections) == 0 {
+			return cty.MapValEmpty(cty.Map(cty.String)), nil
+		}
+		return cty.MapVal(sections), nil
+	},
+})
+
+// INIDecode parses the given string in INI format, returning a map of the
+// sections in it, each of which is a map of its keys to their values.
+//
+// Keys that appear before the first section header are in the section named
+// "DEFAULT", which is omitted if it's empty.
+func INIDecode(src cty.Value) (cty.Value, error) {
+	return INIDecodeFunc.Call([]cty.Value{src})
+}
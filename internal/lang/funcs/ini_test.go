@@ -0,0 +1,83 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestINIDecode(t *testing.T) {
+	tests := []struct {
+		Src  cty.Value
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			cty.StringVal(`
+; top-level keys
+name = example
+
+[server]
+url = https://example.com/#/home
+port = 8080
+
+[empty]
+`),
+			cty.MapVal(map[string]cty.Value{
+				"DEFAULT": cty.MapVal(map[string]cty.Value{
+					"name": cty.StringVal("example"),
+				}),
+				"server": cty.MapVal(map[string]cty.Value{
+					"url":  cty.StringVal("https://example.com/#/home"),
+					"port": cty.StringVal("8080"),
+				}),
+				"empty": cty.MapValEmpty(cty.String),
+			}),
+			false,
+		},
+		{
+			cty.StringVal("[server]\nport = 8080\n"),
+			cty.MapVal(map[string]cty.Value{
+				"server": cty.MapVal(map[string]cty.Value{
+					"port": cty.StringVal("8080"),
+				}),
+			}),
+			false,
+		},
+		{
+			cty.StringVal(""),
+			cty.MapValEmpty(cty.Map(cty.String)),
+			false,
+		},
+		{
+			cty.StringVal("[server\nport"),
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("inidecode(%#v)", test.Src), func(t *testing.T) {
+			got, err := INIDecode(test.Src)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// TOMLDecodeFunc constructs a function that parses a string as a TOML
+// document and returns an object representing its root table.
+var TOMLDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "src",
+			Type: cty.String,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		if !args[0].IsKnown() {
+			return cty.DynamicPseudoType, nil
+		}
+		v, err := tomlDecode(args[0].AsString())
+		if err != nil {
+			return cty.NilType, function.NewArgError(0, err)
+		}
+		return v.Type(), nil
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return tomlDecode(args[0].AsString())
+	},
+})
+
+func tomlDecode(src string) (cty.Value, error) {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(src, &doc); err != nil {
+		return cty.NilVal, fmt.Errorf("invalid TOML: %w", err)
+	}
+	return tomlToCty(doc)
+}
+
+func tomlToCty(raw interface{}) (cty.Value, error) {
+	switch v := raw.(type) {
+	case string:
+		return cty.StringVal(v), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case int64:
+		return cty.NumberIntVal(v), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return cty.NilVal, fmt.Errorf("TOML value %v cannot be represented as a number", v)
+		}
+		return cty.NumberFloatVal(v), nil
+	case time.Time:
+		// Local dates and times have a placeholder location that we can
+		// recognize by name, so that they round-trip without an offset.
+		switch v.Location().String() {
+		case "datetime-local":
+			return cty.StringVal(v.Format("2006-01-02T15:04:05.999999999")), nil
+		case "date-local":
+			return cty.StringVal(v.Format("2006-01-02")), nil
+		case "time-local":
+			return cty.StringVal(v.Format("15:04:05.999999999")), nil
+		default:
+			return cty.StringVal(v.Format(time.RFC3339Nano)), nil
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		elems := make([]cty.Value, len(v))
+		for i, raw := range v {
+			elem, err := tomlToCty(raw)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems[i] = elem
+		}
+		return cty.TupleVal(elems), nil
+	case []map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		elems := make([]cty.Value, len(v))
+		for i, raw := range v {
+			elem, err := tomlToCty(raw)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems[i] = elem
+		}
+		return cty.TupleVal(elems), nil
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		attrs := make(map[string]cty.Value, len(v))
+		for k, raw := range v {
+			attr, err := tomlToCty(raw)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attrs[k] = attr
+		}
+		return cty.ObjectVal(attrs), nil
+	default:
+		// Should not get here, because the above covers all of the types
+		// the TOML decoder produces for an interface{} target.
+		return cty.NilVal, fmt.Errorf("unsupported TOML value of type %T", raw)
+	}
+}
+
+// TOMLEncodeFunc constructs a function that encodes an object or map as a
+// TOML document.
+var TOMLEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "value",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val := args[0]
+		ty := val.Type()
+		if !(ty.IsObjectType() || ty.IsMapType()) {
+			return cty.NilVal, function.NewArgErrorf(0, "a TOML document must be an object or a map, not %s", ty.FriendlyName())
+		}
+
+		doc, err := ctyToTOML(val, nil)
+		if err != nil {
+			return cty.NilVal, function.NewArgError(0, err)
+		}
+
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		if err := enc.Encode(doc); err != nil {
+			return cty.NilVal, function.NewArgErrorf(0, "failed to encode TOML: %s", err)
+		}
+		return cty.StringVal(buf.String()), nil
+	},
+})
+
+// ctyToTOML converts the given value to the Go types expected by the TOML
+// encoder. TOML has no null value, so null elements of objects and maps are
+// omitted and all other null values are errors.
+func ctyToTOML(val cty.Value, path cty.Path) (interface{}, error) {
+	if val.IsNull() {
+		return nil, path.NewErrorf("TOML cannot represent null values")
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString(), nil
+	case ty == cty.Bool:
+		return val.True(), nil
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		if i, acc := bf.Int64(); acc == big.Exact {
+			return i, nil
+		}
+		f, _ := bf.Float64()
+		return f, nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		ret := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			elem, err := ctyToTOML(ev, path.Index(k))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, elem)
+		}
+		return ret, nil
+	case ty.IsMapType() || ty.IsObjectType():
+		ret := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			if ev.IsNull() {
+				continue
+			}
+			elem, err := ctyToTOML(ev, path.Index(k))
+			if err != nil {
+				return nil, err
+			}
+			ret[k.AsString()] = elem
+		}
+		return ret, nil
+	default:
+		return nil, path.NewErrorf("TOML cannot represent values of type %s", ty.FriendlyName())
+	}
+}
+
+// TOMLDecode parses the given string as a TOML document, returning an object
+// whose attributes are the keys of the document's root table.
+//
+// Tables become objects, arrays become tuples, and dates and times become
+// strings in the same format as they appear in the document.
+func TOMLDecode(src cty.Value) (cty.Value, error) {
+	return TOMLDecodeFunc.Call([]cty.Value{src})
+}
+
+// TOMLEncode encodes the given object or map as a TOML document.
+func TOMLEncode(val cty.Value) (cty.Value, error) {
+	return TOMLEncodeFunc.Call([]cty.Value{val})
+}
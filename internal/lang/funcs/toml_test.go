@@ -0,0 +1,197 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+)
+
+func TestTOMLDecode(t *testing.T) {
+	tests := []struct {
+		Src  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.StringVal(`
+title = "example"
+ports = [8000, 8001]
+ratio = 0.5
+
+[owner]
+name = "Tom"
+dob = 1979-05-27T07:32:00-08:00
+born = 1979-05-27
+
+[[servers]]
+name = "alpha"
+
+[[servers]]
+name = "beta"
+enabled = false
+`),
+			cty.ObjectVal(map[string]cty.Value{
+				"title": cty.StringVal("example"),
+				"ports": cty.TupleVal([]cty.Value{cty.NumberIntVal(8000), cty.NumberIntVal(8001)}),
+				"ratio": cty.NumberFloatVal(0.5),
+				"owner": cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("Tom"),
+					"dob":  cty.StringVal("1979-05-27T07:32:00-08:00"),
+					"born": cty.StringVal("1979-05-27"),
+				}),
+				"servers": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("alpha"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"name":    cty.StringVal("beta"),
+						"enabled": cty.False,
+					}),
+				}),
+			}),
+			"",
+		},
+		{
+			cty.StringVal(""),
+			cty.EmptyObjectVal,
+			"",
+		},
+		{
+			cty.StringVal("a = 1").Mark(marks.Sensitive),
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.NumberIntVal(1),
+			}).Mark(marks.Sensitive),
+			"",
+		},
+		{
+			cty.UnknownVal(cty.String),
+			cty.DynamicVal,
+			"",
+		},
+		{
+			cty.StringVal("a = "),
+			cty.NilVal,
+			"invalid TOML",
+		},
+		{
+			cty.StringVal("a = nan"),
+			cty.NilVal,
+			"cannot be represented as a number",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("tomldecode(%#v)", test.Src), func(t *testing.T) {
+			got, err := TOMLDecode(test.Src)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if !strings.Contains(err.Error(), test.Err) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", err, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestTOMLEncode(t *testing.T) {
+	tests := []struct {
+		Val  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"title":  cty.StringVal("example"),
+				"ports":  cty.ListVal([]cty.Value{cty.NumberIntVal(8000), cty.NumberIntVal(8001)}),
+				"ratio":  cty.NumberFloatVal(0.5),
+				"unset":  cty.NullVal(cty.String),
+				"labels": cty.MapValEmpty(cty.String),
+				"owner": cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("Tom"),
+				}),
+				"servers": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("alpha"),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("beta"),
+					}),
+				}),
+			}),
+			cty.StringVal(`ports = [8000, 8001]
+ratio = 0.5
+title = "example"
+
+[labels]
+
+[owner]
+name = "Tom"
+
+[[servers]]
+name = "alpha"
+
+[[servers]]
+name = "beta"
+`),
+			"",
+		},
+		{
+			cty.UnknownVal(cty.Map(cty.String)),
+			cty.UnknownVal(cty.String).RefineNotNull(),
+			"",
+		},
+		{
+			cty.StringVal("a"),
+			cty.NilVal,
+			"a TOML document must be an object or a map, not string",
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"list": cty.TupleVal([]cty.Value{cty.NullVal(cty.String)}),
+			}),
+			cty.NilVal,
+			"TOML cannot represent null values",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("tomlencode(%#v)", test.Val), func(t *testing.T) {
+			got, err := TOMLEncode(test.Val)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if !strings.Contains(err.Error(), test.Err) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", err, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
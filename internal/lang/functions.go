@@ -166,6 +166,7 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"formatlist":       stdlib.FormatListFunc,
 		"indent":           stdlib.IndentFunc,
 		"index":            funcs.IndexFunc, // stdlib.IndexFunc is not compatible
+		"inidecode":        funcs.INIDecodeFunc,
 		"join":             stdlib.JoinFunc,
 		"jsondecode":       stdlib.JSONDecodeFunc,
 		"jsonencode":       stdlib.JSONEncodeFunc,
@@ -222,6 +223,8 @@ func makeBaseFunctionTable(baseDir string) map[string]function.Function {
 		"toset":            funcs.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
 		"tolist":           funcs.MakeToFunc(cty.List(cty.DynamicPseudoType)),
 		"tomap":            funcs.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tomldecode":       funcs.TOMLDecodeFunc,
+		"tomlencode":       funcs.TOMLEncodeFunc,
 		"transpose":        funcs.TransposeFunc,
 		"trim":             stdlib.TrimFunc,
 		"trimprefix":       stdlib.TrimPrefixFunc,
@@ -532,6 +532,17 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"inidecode": {
+			{
+				`inidecode("[server]\nport = 8080")`,
+				cty.MapVal(map[string]cty.Value{
+					"server": cty.MapVal(map[string]cty.Value{
+						"port": cty.StringVal("8080"),
+					}),
+				}),
+			},
+		},
+
 		"issensitive": {
 			{
 				`issensitive(1)`,
@@ -991,6 +1002,10 @@ func TestFunctions(t *testing.T) {
 				`templatefile("hello.tmpl", {name = "Jodie"})`,
 				cty.StringVal("Hello, Jodie!"),
 			},
+			{
+				`templatefile("hello.tmpl", {name = "Jodie"}, [])`,
+				cty.StringVal("Hello, Jodie!"),
+			},
 		},
 
 		"templatestring": {
@@ -1047,6 +1062,25 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"tomldecode": {
+			{
+				`tomldecode("a = 1\n[b]\nc = true")`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1),
+					"b": cty.ObjectVal(map[string]cty.Value{
+						"c": cty.True,
+					}),
+				}),
+			},
+		},
+
+		"tomlencode": {
+			{
+				`tomlencode({a = "b", c = 1})`,
+				cty.StringVal("a = \"b\"\nc = 1\n"),
+			},
+		},
+
 		"tonumber": {
 			{
 				`tonumber("42")`,
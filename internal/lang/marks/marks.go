@@ -45,3 +45,12 @@ const Sensitive = valueMark("Sensitive")
 // another value's type. This is part of the implementation of the console-only
 // `type` function.
 const TypeType = valueMark("TypeType")
+
+// Ephemeral indicates that this value is derived from the result of an
+// ephemeral resource, and so it must never be saved in the state or in a plan.
+const Ephemeral = valueMark("Ephemeral")
+
+// Secret indicates that this value is derived from an input variable declared
+// as secret, and so it must never be saved in the state in cleartext. Secret
+// values are always also marked as Sensitive.
+const Secret = valueMark("Secret")
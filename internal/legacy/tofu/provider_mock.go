@@ -371,6 +371,30 @@ func (p *MockProvider) CallFunction(r providers.CallFunctionRequest) providers.C
 	panic("Not Implemented")
 }
 
+func (p *MockProvider) ValidateEphemeralResourceConfig(providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse {
+	panic("Not Implemented")
+}
+
+func (p *MockProvider) OpenEphemeralResource(providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (p *MockProvider) RenewEphemeralResource(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (p *MockProvider) CloseEphemeralResource(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (p *MockProvider) CheckResourceQuotas(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+	panic("Not Implemented")
+}
+
+func (p *MockProvider) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	panic("Not Implemented")
+}
+
 func (p *MockProvider) Close() error {
 	p.CloseCalled = true
 	return p.CloseError
@@ -775,9 +775,10 @@ func (s *State) String() string {
 
 // BackendState stores the configuration to connect to a remote backend.
 type BackendState struct {
-	Type      string          `json:"type"`   // Backend type
-	ConfigRaw json.RawMessage `json:"config"` // Backend raw config
-	Hash      uint64          `json:"hash"`   // Hash of portion of configuration from config files
+	Type      string          `json:"type"`                // Backend type
+	ConfigRaw json.RawMessage `json:"config"`              // Backend raw config
+	Hash      uint64          `json:"hash"`                // Hash of portion of configuration from config files
+	Namespace string          `json:"namespace,omitempty"` // State namespace from the backend block
 }
 
 // Empty returns true if BackendState has no state.
@@ -828,7 +829,12 @@ func (s *BackendState) ForPlan(schema *configschema.Block, workspaceName string)
 	if err != nil {
 		return nil, errwrap.Wrapf("failed to decode backend config: {{err}}", err)
 	}
-	return plans.NewBackend(s.Type, configVal, schema, workspaceName)
+	ret, err := plans.NewBackend(s.Type, configVal, schema, workspaceName)
+	if err != nil {
+		return nil, err
+	}
+	ret.Namespace = s.Namespace
+	return ret, nil
 }
 
 // RemoteState is used to track the information about a remote
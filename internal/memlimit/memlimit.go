@@ -0,0 +1,111 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package memlimit implements the optional memory budget that users can set
+// with the TF_MEMORY_LIMIT environment variable.
+//
+// The budget becomes the soft memory limit of the Go runtime, so that the
+// garbage collector works harder as OpenTofu approaches it rather than
+// letting the process grow until the operating system kills it. This package
+// also watches the memory usage so that other parts of OpenTofu can produce
+// less detailed output once the budget has been exceeded.
+package memlimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EnvVar is the name of the environment variable that sets the memory
+// budget.
+const EnvVar = "TF_MEMORY_LIMIT"
+
+// checkInterval is how often the memory usage is compared with the budget.
+const checkInterval = 500 * time.Millisecond
+
+var exceeded atomic.Bool
+
+// Parse parses a memory budget, which uses the same syntax as the GOMEMLIMIT
+// environment variable: a number of bytes, optionally followed by one of the
+// units B, KiB, MiB, GiB or TiB.
+func Parse(raw string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		// The longer suffixes must come first, because they all end in "B".
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+		{"TiB", 1 << 40},
+		{"B", 1},
+	}
+
+	num, size := strings.TrimSpace(raw), int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(num, unit.suffix) {
+			num, size = strings.TrimSuffix(num, unit.suffix), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive number of bytes, optionally followed by B, KiB, MiB, GiB or TiB", raw)
+	}
+	if n > (1<<63-1)/size {
+		return 0, fmt.Errorf("%q is too large", raw)
+	}
+	return n * size, nil
+}
+
+// Start sets the given budget, in bytes, as the soft memory limit of the Go
+// runtime and checks the memory usage against it until the given context is
+// cancelled or the budget is first exceeded.
+func Start(ctx context.Context, limit int64) {
+	debug.SetMemoryLimit(limit)
+	log.Printf("[INFO] Memory budget set to %d bytes", limit)
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if used := usage(); used > uint64(limit) {
+					log.Printf("[WARN] Memory usage of %d bytes exceeds the budget of %d bytes", used, limit)
+					exceeded.Store(true)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Exceeded returns true if the memory usage has exceeded the budget given to
+// Start.
+func Exceeded() bool {
+	return exceeded.Load()
+}
+
+// usage returns the memory that the Go runtime holds, measured in the same
+// way as for its soft memory limit.
+func usage() uint64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
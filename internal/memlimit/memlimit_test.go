@@ -0,0 +1,49 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package memlimit
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		want    int64
+		wantErr bool
+	}{
+		"1024":          {want: 1024},
+		"512B":          {want: 512},
+		"64KiB":         {want: 64 << 10},
+		"256MiB":        {want: 256 << 20},
+		" 4GiB ":        {want: 4 << 30},
+		"2TiB":          {want: 2 << 40},
+		"":              {wantErr: true},
+		"0":             {wantErr: true},
+		"-1GiB":         {wantErr: true},
+		"1.5GiB":        {wantErr: true},
+		"4GB":           {wantErr: true},
+		"lots":          {wantErr: true},
+		"9999999999TiB": {wantErr: true},
+	}
+
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := Parse(input)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("wrong result %d; want %d", got, test.want)
+			}
+		})
+	}
+}
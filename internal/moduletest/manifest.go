@@ -0,0 +1,209 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package moduletest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+const (
+	// ManifestFilename is the name of the file, within the manifest directory,
+	// that records the test states left behind by keep_on_failure.
+	ManifestFilename = "manifest.json"
+
+	manifestFormatVersion = "1.0"
+)
+
+// Manifest records the states that the test command kept on disk, instead of
+// destroying, because a test file failed with keep_on_failure set.
+//
+// The manifest and the state files it references all live in a single
+// directory, so the manifest can be copied or removed as a unit.
+type Manifest struct {
+	FormatVersion string           `json:"format_version"`
+	Entries       []*ManifestEntry `json:"entries"`
+
+	dir string
+}
+
+// ManifestEntry describes a single state kept by the test command.
+type ManifestEntry struct {
+	// File and Run identify the test file and the run block that most
+	// recently updated the state, and therefore the configuration that should
+	// be used to destroy it.
+	File string `json:"file"`
+	Run  string `json:"run"`
+
+	// StateKey identifies the state within the test file. The state for the
+	// main configuration is identified by an empty key, while states for
+	// alternate modules are identified by their module source.
+	StateKey string `json:"state_key,omitempty"`
+
+	// State is the path to the state file, relative to the manifest directory.
+	State string `json:"state"`
+}
+
+// LoadManifest reads the manifest from the given directory. A missing
+// manifest is not an error, and results in an empty manifest.
+func LoadManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{
+		FormatVersion: manifestFormatVersion,
+		dir:           dir,
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return manifest, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(src, manifest); err != nil {
+		return nil, fmt.Errorf("invalid test state manifest %s: %w", filepath.Join(dir, ManifestFilename), err)
+	}
+	if manifest.FormatVersion != manifestFormatVersion {
+		return nil, fmt.Errorf("unsupported test state manifest format version %q", manifest.FormatVersion)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest back to disk. An empty manifest is removed
+// entirely, so a clean working directory doesn't retain any test artifacts.
+func (m *Manifest) Save() error {
+	path := filepath.Join(m.dir, ManifestFilename)
+
+	if len(m.Entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// We don't care if the directory can't be removed, it just means
+		// something else is in there.
+		_ = os.Remove(m.dir)
+		return nil
+	}
+
+	src, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0644)
+}
+
+// Record writes the given state to disk and adds an entry for it to the
+// manifest. Entries recorded by earlier test executions are left alone, as
+// they track infrastructure that hasn't been destroyed yet.
+//
+// The manifest itself is not saved, callers should call Save once they have
+// finished recording states.
+func (m *Manifest) Record(file, run, key string, state *states.State) error {
+	entry := &ManifestEntry{
+		File:     file,
+		Run:      run,
+		StateKey: key,
+		State:    m.stateFilename(file, key),
+	}
+
+	if err := m.writeState(entry, state); err != nil {
+		return err
+	}
+
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+// Update replaces the state referenced by the given entry, for example with
+// whatever remains after a partially successful destroy operation.
+func (m *Manifest) Update(entry *ManifestEntry, state *states.State) error {
+	return m.writeState(entry, state)
+}
+
+// ReadState loads the state referenced by the given entry.
+func (m *Manifest) ReadState(entry *ManifestEntry) (*states.State, error) {
+	f, err := os.Open(filepath.Join(m.dir, entry.State))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sf, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+	if err != nil {
+		return nil, err
+	}
+	return sf.State, nil
+}
+
+// Remove deletes the state referenced by the given entry and removes the
+// entry from the manifest.
+func (m *Manifest) Remove(entry *ManifestEntry) error {
+	if err := os.Remove(filepath.Join(m.dir, entry.State)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for ix, existing := range m.Entries {
+		if existing == entry {
+			m.Entries = append(m.Entries[:ix], m.Entries[ix+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) writeState(entry *ManifestEntry, state *states.State) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(m.dir, entry.State))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sf := statefile.New(state, "", 0)
+	return statefile.Write(sf, f, encryption.StateEncryptionDisabled())
+}
+
+// stateFilename returns a filename for the state identified by the given test
+// file and state key that is safe to use within the manifest directory, and
+// that isn't already used by another entry.
+func (m *Manifest) stateFilename(file, key string) string {
+	name := file
+	if key != "" {
+		name = fmt.Sprintf("%s.%s", file, key)
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+
+	used := make(map[string]bool, len(m.Entries))
+	for _, entry := range m.Entries {
+		used[entry.State] = true
+	}
+
+	candidate := name + ".tfstate"
+	for ix := 1; used[candidate]; ix++ {
+		candidate = fmt.Sprintf("%s.%d.tfstate", name, ix)
+	}
+	return candidate
+}
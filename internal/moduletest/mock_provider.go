@@ -0,0 +1,192 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package moduletest
+
+import (
+	"fmt"
+
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+var _ providers.Interface = (*MockProvider)(nil)
+
+// MockProvider is an in-process provider that replaces a real provider
+// whose mock_provider blocks declare the schemas of their mock resources, so
+// that tests can run without the provider plugin.
+//
+// Its schema has an optional and computed attribute for each attribute that
+// the mock resources declare. The test framework wraps the provider so that
+// the mock resources, rather than the provider itself, produce the planned
+// and applied values, so the provider itself only echoes back the values it
+// receives.
+type MockProvider struct {
+	addr   addrs.Provider
+	schema providers.ProviderSchema
+}
+
+// NewMockProvider returns a MockProvider for the given provider, with the
+// schemas declared by the given mock resources.
+func NewMockProvider(addr addrs.Provider, resources []*configs.MockResource) *MockProvider {
+	schema := providers.ProviderSchema{
+		Provider:      providers.Schema{Block: &configschema.Block{}},
+		ResourceTypes: make(map[string]providers.Schema),
+		DataSources:   make(map[string]providers.Schema),
+	}
+
+	for _, res := range resources {
+		block := &configschema.Block{
+			Attributes: make(map[string]*configschema.Attribute, len(res.Schema)),
+		}
+		for name, ty := range res.Schema {
+			block.Attributes[name] = &configschema.Attribute{
+				Type:     ty,
+				Optional: true,
+				Computed: true,
+			}
+		}
+
+		switch res.Mode {
+		case addrs.ManagedResourceMode:
+			schema.ResourceTypes[res.Type] = providers.Schema{Block: block}
+		case addrs.DataResourceMode:
+			schema.DataSources[res.Type] = providers.Schema{Block: block}
+		}
+	}
+
+	return &MockProvider{
+		addr:   addr,
+		schema: schema,
+	}
+}
+
+func (p *MockProvider) GetProviderSchema() providers.GetProviderSchemaResponse {
+	return p.schema
+}
+
+func (p *MockProvider) ValidateProviderConfig(r providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	return providers.ValidateProviderConfigResponse{
+		PreparedConfig: r.Config,
+	}
+}
+
+func (p *MockProvider) ValidateResourceConfig(providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	return providers.ValidateResourceConfigResponse{}
+}
+
+func (p *MockProvider) ValidateDataResourceConfig(providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	return providers.ValidateDataResourceConfigResponse{}
+}
+
+func (p *MockProvider) UpgradeResourceState(r providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	var resp providers.UpgradeResourceStateResponse
+
+	schema, ok := p.schema.ResourceTypes[r.TypeName]
+	if !ok {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("the mock provider for %s has no schema for resource type %q", p.addr, r.TypeName))
+		return resp
+	}
+
+	val, err := ctyjson.Unmarshal(r.RawStateJSON, schema.Block.ImpliedType())
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("failed to decode the state of a %q resource: %w", r.TypeName, err))
+		return resp
+	}
+	resp.UpgradedState = val
+	return resp
+}
+
+// ConfigureProvider fails, because the provider can only be used through
+// mock_provider blocks, which are never configured.
+func (p *MockProvider) ConfigureProvider(providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	var resp providers.ConfigureProviderResponse
+	resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Provider is only available for mocking",
+		fmt.Sprintf("The test file declares the schemas of the mock resources of %s, so OpenTofu doesn't use the real provider and can't configure it. Use a mock_provider block for every configuration of the provider in this test file.", p.addr),
+	))
+	return resp
+}
+
+func (p *MockProvider) Stop() error {
+	return nil
+}
+
+func (p *MockProvider) ReadResource(r providers.ReadResourceRequest) providers.ReadResourceResponse {
+	return providers.ReadResourceResponse{
+		NewState: r.PriorState,
+		Private:  r.Private,
+	}
+}
+
+func (p *MockProvider) PlanResourceChange(r providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	return providers.PlanResourceChangeResponse{
+		PlannedState:   r.ProposedNewState,
+		PlannedPrivate: r.PriorPrivate,
+	}
+}
+
+func (p *MockProvider) ApplyResourceChange(r providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	return providers.ApplyResourceChangeResponse{
+		NewState: r.PlannedState,
+		Private:  r.PlannedPrivate,
+	}
+}
+
+func (p *MockProvider) ImportResourceState(r providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	var resp providers.ImportResourceStateResponse
+	resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("the mock provider for %s can't import %q resources", p.addr, r.TypeName))
+	return resp
+}
+
+func (p *MockProvider) ReadDataSource(r providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	return providers.ReadDataSourceResponse{
+		State: r.Config,
+	}
+}
+
+func (p *MockProvider) GetFunctions() providers.GetFunctionsResponse {
+	return providers.GetFunctionsResponse{}
+}
+
+func (p *MockProvider) CallFunction(r providers.CallFunctionRequest) providers.CallFunctionResponse {
+	return providers.CallFunctionResponse{
+		Error: fmt.Errorf("the mock provider for %s has no function %q", p.addr, r.Name),
+	}
+}
+
+func (p *MockProvider) ValidateEphemeralResourceConfig(providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse {
+	return providers.ValidateEphemeralResourceConfigResponse{}
+}
+
+func (p *MockProvider) OpenEphemeralResource(providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	return providers.OpenEphemeralResourceResponse{}
+}
+
+func (p *MockProvider) RenewEphemeralResource(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	return providers.RenewEphemeralResourceResponse{}
+}
+
+func (p *MockProvider) CloseEphemeralResource(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	return providers.CloseEphemeralResourceResponse{}
+}
+
+func (p *MockProvider) CheckResourceQuotas(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+	return providers.CheckResourceQuotasResponse{}
+}
+
+func (p *MockProvider) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	return providers.ListResourcesResponse{}
+}
+
+func (p *MockProvider) Close() error {
+	return nil
+}
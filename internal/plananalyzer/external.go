@@ -0,0 +1,143 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plananalyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// FormatVersion is the version of the JSON documents exchanged with external
+// analyzers. It follows the same rules as the other JSON formats OpenTofu
+// produces: the minor version is incremented for backward-compatible
+// changes, and the major version for incompatible ones.
+const FormatVersion = "1.0"
+
+// External is an Analyzer implemented by an external program.
+//
+// OpenTofu runs the program once for each plan, writing a JSON object to its
+// standard input with the following properties:
+//
+//   - "format_version": the value of FormatVersion.
+//   - "analyzer": the name of the analyzer.
+//   - "plan": the plan, in the same format as "tofu show -json" produces.
+//
+// The program must exit with status zero and write a JSON object to its
+// standard output which can have a "diagnostics" property, which is an
+// array of objects with "severity" ("error" or "warning"), "summary" and
+// "detail" properties. Anything the program writes to its standard error is
+// included in the error OpenTofu returns if the program fails.
+type External struct {
+	// AnalyzerName is the name that the analyzer was configured with.
+	AnalyzerName string
+
+	// Command is the program to run, and Args are the arguments to run it
+	// with.
+	Command string
+	Args    []string
+}
+
+var _ Analyzer = (*External)(nil)
+
+// externalRequest is the JSON object written to the standard input of an
+// external analyzer.
+type externalRequest struct {
+	FormatVersion string          `json:"format_version"`
+	Analyzer      string          `json:"analyzer"`
+	Plan          json.RawMessage `json:"plan"`
+}
+
+// externalResponse is the JSON object read from the standard output of an
+// external analyzer.
+type externalResponse struct {
+	Diagnostics []externalDiagnostic `json:"diagnostics"`
+}
+
+type externalDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+}
+
+func (e *External) Name() string {
+	return e.AnalyzerName
+}
+
+func (e *External) AnalyzePlan(ctx context.Context, req *Request) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	stateFile := statefile.New(req.Plan.PriorState, "", 0)
+	planJSON, err := jsonplan.Marshal(req.Config, req.Plan, stateFile, req.Schemas)
+	if err != nil {
+		diags = diags.Append(e.failed(fmt.Sprintf("OpenTofu could not encode the plan for the analyzer: %s.", err)))
+		return diags
+	}
+	input, err := json.Marshal(externalRequest{
+		FormatVersion: FormatVersion,
+		Analyzer:      e.AnalyzerName,
+		Plan:          planJSON,
+	})
+	if err != nil {
+		diags = diags.Append(e.failed(fmt.Sprintf("OpenTofu could not encode the request for the analyzer: %s.", err)))
+		return diags
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := fmt.Sprintf("The analyzer program %s failed: %s.", e.Command, err)
+		if errOutput := strings.TrimSpace(stderr.String()); errOutput != "" {
+			msg += "\n\n" + errOutput
+		}
+		diags = diags.Append(e.failed(msg))
+		return diags
+	}
+
+	var resp externalResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		diags = diags.Append(e.failed(fmt.Sprintf("The analyzer program %s returned invalid JSON: %s.", e.Command, err)))
+		return diags
+	}
+
+	for _, diag := range resp.Diagnostics {
+		var severity tfdiags.Severity
+		switch diag.Severity {
+		case "error":
+			severity = tfdiags.Error
+		case "warning":
+			severity = tfdiags.Warning
+		default:
+			diags = diags.Append(e.failed(fmt.Sprintf("The analyzer program %s returned a diagnostic with the unsupported severity %q. The severity must be either \"error\" or \"warning\".", e.Command, diag.Severity)))
+			continue
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			severity,
+			fmt.Sprintf("%s: %s", e.AnalyzerName, diag.Summary),
+			diag.Detail,
+		))
+	}
+
+	return diags
+}
+
+func (e *External) failed(detail string) tfdiags.Diagnostic {
+	return tfdiags.Sourceless(
+		tfdiags.Error,
+		fmt.Sprintf("Plan analyzer %q failed", e.AnalyzerName),
+		detail,
+	)
+}
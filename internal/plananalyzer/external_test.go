@@ -0,0 +1,139 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plananalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestExternal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test analyzers are shell scripts")
+	}
+
+	tests := map[string]struct {
+		script    string
+		wantDiags []string
+	}{
+		"no findings": {
+			script: `echo '{}'`,
+		},
+		"findings": {
+			script: `echo '{"diagnostics":[{"severity":"warning","summary":"Estimated cost","detail":"$10 per month"},{"severity":"error","summary":"Over budget"}]}'`,
+			wantDiags: []string{
+				"Warning: test: Estimated cost; $10 per month",
+				"Error: test: Over budget; ",
+			},
+		},
+		"unsupported severity": {
+			script: `echo '{"diagnostics":[{"severity":"info","summary":"Hello"}]}'`,
+			wantDiags: []string{
+				`Error: Plan analyzer "test" failed; The analyzer program`,
+			},
+		},
+		"invalid output": {
+			script: `echo 'not json'`,
+			wantDiags: []string{
+				`Error: Plan analyzer "test" failed; The analyzer program`,
+			},
+		},
+		"failure": {
+			script: `echo 'out of credits' >&2; exit 1`,
+			wantDiags: []string{
+				`Error: Plan analyzer "test" failed; The analyzer program`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			inputPath := filepath.Join(dir, "input.json")
+			scriptPath := filepath.Join(dir, "analyzer")
+			script := "#!/bin/sh\ncat > \"$1\"\n" + test.script + "\n"
+			if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			analyzer := &External{
+				AnalyzerName: "test",
+				Command:      scriptPath,
+				Args:         []string{inputPath},
+			}
+			diags := AnalyzePlan(context.Background(), []Analyzer{analyzer}, testRequest())
+
+			var gotDiags []string
+			for _, diag := range diags {
+				desc := diag.Description()
+				gotDiags = append(gotDiags, diag.Severity().String()+": "+desc.Summary+"; "+desc.Detail)
+			}
+			if len(gotDiags) != len(test.wantDiags) {
+				t.Fatalf("wrong diagnostics\ngot:  %q\nwant: %q", gotDiags, test.wantDiags)
+			}
+			for i := range gotDiags {
+				if !strings.HasPrefix(gotDiags[i], test.wantDiags[i]) {
+					t.Errorf("wrong diagnostic %d\ngot:  %s\nwant: %s...", i, gotDiags[i], test.wantDiags[i])
+				}
+			}
+
+			src, err := os.ReadFile(inputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var input struct {
+				FormatVersion string                     `json:"format_version"`
+				Analyzer      string                     `json:"analyzer"`
+				Plan          map[string]json.RawMessage `json:"plan"`
+			}
+			if err := json.Unmarshal(src, &input); err != nil {
+				t.Fatalf("analyzer got invalid JSON: %s", err)
+			}
+			if input.FormatVersion != FormatVersion || input.Analyzer != "test" {
+				t.Errorf("wrong request header: %s", src)
+			}
+			if _, ok := input.Plan["format_version"]; !ok {
+				t.Errorf("request has no JSON plan: %s", src)
+			}
+		})
+	}
+}
+
+func TestAnalyzePlan_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	analyzer := &External{AnalyzerName: "test", Command: "does-not-exist"}
+	diags := AnalyzePlan(ctx, []Analyzer{analyzer}, testRequest())
+	if got, want := diags.Err().Error(), "Plan analysis cancelled"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func testRequest() *Request {
+	config := configs.NewEmptyConfig()
+	config.Module.ProviderRequirements = &configs.RequiredProviders{}
+	return &Request{
+		Config: config,
+		Plan: &plans.Plan{
+			Changes:      plans.NewChanges(),
+			PriorState:   states.NewState(),
+			PrevRunState: states.NewState(),
+		},
+		Schemas: &tofu.Schemas{},
+	}
+}
+
@@ -0,0 +1,65 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package plananalyzer implements an extension point that passes each plan
+// that OpenTofu creates through a set of "plan analyzers" before the plan is
+// rendered, so that tools such as cost estimators can report on the plan
+// without the user having to run "tofu show -json" in a separate step.
+//
+// Analyzers are usually external programs configured in the CLI
+// configuration, which OpenTofu runs using the protocol implemented by
+// External.
+package plananalyzer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Analyzer is the interface implemented by plan analyzers.
+type Analyzer interface {
+	// Name returns a name for the analyzer, which OpenTofu uses to refer to
+	// it in messages.
+	Name() string
+
+	// AnalyzePlan inspects the given plan and returns diagnostics describing
+	// the analyzer's findings. Error diagnostics prevent the plan from being
+	// applied.
+	AnalyzePlan(ctx context.Context, req *Request) tfdiags.Diagnostics
+}
+
+// Request is the information about a finished plan that is passed to each
+// analyzer.
+type Request struct {
+	Config  *configs.Config
+	Plan    *plans.Plan
+	Schemas *tofu.Schemas
+}
+
+// AnalyzePlan passes the plan in the given request to each of the given
+// analyzers in turn, returning all of their diagnostics.
+//
+// All of the analyzers run even if some of them return errors, so that the
+// user can see all of the findings at once.
+func AnalyzePlan(ctx context.Context, analyzers []Analyzer, req *Request) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, analyzer := range analyzers {
+		if err := ctx.Err(); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Plan analysis cancelled",
+				fmt.Sprintf("OpenTofu didn't run the plan analyzer %q because the operation was cancelled.", analyzer.Name()),
+			))
+			break
+		}
+		diags = diags.Append(analyzer.AnalyzePlan(ctx, req))
+	}
+	return diags
+}
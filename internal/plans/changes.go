@@ -450,6 +450,16 @@ const (
 	// this combination evaluates to a deletion of the "new" resource.
 	ResourceInstanceDeleteBecauseNoMoveTarget ResourceInstanceChangeActionReason = 'A'
 
+	// ResourceInstanceDeleteBecauseRemovedBlock indicates that the resource
+	// instance is planned to be deleted because a "removed" block that
+	// targets it, or the module containing it, requests its destruction.
+	ResourceInstanceDeleteBecauseRemovedBlock ResourceInstanceChangeActionReason = 'B'
+
+	// ResourceInstanceCreateBecauseDeleted indicates that the resource
+	// instance is planned to be created because refreshing it found that its
+	// existing object was deleted outside of OpenTofu.
+	ResourceInstanceCreateBecauseDeleted ResourceInstanceChangeActionReason = 'G'
+
 	// ResourceInstanceReadBecauseConfigUnknown indicates that the resource
 	// must be read during apply (rather than during planning) because its
 	// configuration contains unknown values. This reason applies only to
@@ -111,6 +111,27 @@ func (cs *ChangesSync) GetChangesForAbsResource(addr addrs.AbsResource) []*Resou
 	return changes
 }
 
+// GetChangesForProvider returns all of the resource instance changes planned
+// using the given provider configuration.
+//
+// The returned objects are a deep copy of the change recorded in the plan, so
+// callers may mutate them although it's generally better (less confusing) to
+// treat planned changes as immutable after they've been initially constructed.
+func (cs *ChangesSync) GetChangesForProvider(addr addrs.AbsProviderConfig) []*ResourceInstanceChangeSrc {
+	if cs == nil {
+		panic("GetChangesForProvider on nil ChangesSync")
+	}
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	var changes []*ResourceInstanceChangeSrc
+	for _, c := range cs.changes.Resources {
+		if c.ProviderAddr.String() == addr.String() {
+			changes = append(changes, c.DeepCopy())
+		}
+	}
+	return changes
+}
+
 // RemoveResourceInstanceChange searches the set of resource instance changes
 // for one matching the given address and generation, and removes it from the
 // set if it exists.
@@ -33,4 +33,19 @@ const (
 	// This mode corresponds with the "-refresh-only" option to
 	// "tofu plan".
 	RefreshOnlyMode Mode = 'R'
+
+	// DriftOnlyMode is a special planning mode which, like RefreshOnlyMode,
+	// only performs the synchronization of prior state with remote objects,
+	// but which is intended only for reporting the differences it detects.
+	// A drift-only plan can never be applied or saved.
+	//
+	// This mode corresponds with the "-drift-only" option to "tofu plan".
+	DriftOnlyMode Mode = 'F'
 )
+
+// RefreshesOnly returns true if the mode skips any effort to generate change
+// actions for resource instances, and so only reports the result of
+// synchronizing the prior state with remote objects.
+func (m Mode) RefreshesOnly() bool {
+	return m == RefreshOnlyMode || m == DriftOnlyMode
+}
@@ -10,13 +10,15 @@ func _() {
 	var x [1]struct{}
 	_ = x[NormalMode-0]
 	_ = x[DestroyMode-68]
+	_ = x[DriftOnlyMode-70]
 	_ = x[RefreshOnlyMode-82]
 }
 
 const (
 	_Mode_name_0 = "NormalMode"
 	_Mode_name_1 = "DestroyMode"
-	_Mode_name_2 = "RefreshOnlyMode"
+	_Mode_name_2 = "DriftOnlyMode"
+	_Mode_name_3 = "RefreshOnlyMode"
 )
 
 func (i Mode) String() string {
@@ -25,8 +27,10 @@ func (i Mode) String() string {
 		return _Mode_name_0
 	case i == 68:
 		return _Mode_name_1
-	case i == 82:
+	case i == 70:
 		return _Mode_name_2
+	case i == 82:
+		return _Mode_name_3
 	default:
 		return "Mode(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/lang/globalref"
 	"github.com/opentofu/opentofu/internal/states"
@@ -115,8 +116,39 @@ type Plan struct {
 	// representation of the plan.
 	ExternalReferences []*addrs.Reference
 
+	// DiscoveredImports describes the objects that providers listed for
+	// import blocks with discover blocks, for which OpenTofu writes import
+	// blocks when it generates configuration. As with PlannedState this is
+	// only used right after planning, and so isn't written into the binary
+	// plan file or any other external representation of the plan.
+	DiscoveredImports []*DiscoveredImport
+
 	// Timestamp is the record of truth for when the plan happened.
 	Timestamp time.Time
+
+	// SecretVariables are the names of the input variables in VariableValues
+	// that are declared as secret, whose values plan files save in the
+	// secrets store instead of in cleartext.
+	SecretVariables map[string]bool
+
+	// RunID is the value of tofu.run_id during the plan. The apply step
+	// reuses it, so that resource arguments that refer to tofu.run_id have
+	// the same value as when they were planned.
+	RunID string
+}
+
+// DiscoveredImport describes an object that a provider listed for an import
+// block with a discover block.
+type DiscoveredImport struct {
+	// Addr is the address of the resource instance that the generated import
+	// block imports the object into.
+	Addr addrs.AbsResourceInstance
+
+	// ID is the import ID of the object.
+	ID string
+
+	// GeneratedConfig is the HCL source of the generated import block.
+	GeneratedConfig string
 }
 
 // CanApply returns true if and only if the receiving plan includes content
@@ -138,6 +170,11 @@ func (p *Plan) CanApply() bool {
 		// causes of the errors.
 		return false
 
+	case p.UIMode == DriftOnlyMode:
+		// A drift-only plan exists only to report what has changed outside
+		// of OpenTofu, and so there's never anything to apply.
+		return false
+
 	case !p.Changes.Empty():
 		// "Empty" means that everything in the changes is a "NoOp", so if
 		// not empty then there's at least one non-NoOp change.
@@ -166,6 +203,51 @@ func (p *Plan) CanApply() bool {
 	}
 }
 
+// CanSkipApply returns true if applying the receiving plan can have no effect
+// other than persisting its prior state as a new state snapshot, in which case
+// OpenTofu can skip walking the apply graph and launching any providers.
+//
+// That is the case for a normal mode plan without any changes whose checks
+// all passed during planning, because re-evaluating them during apply would
+// produce the same results. Check blocks are the exception, as they are meant
+// to be re-evaluated against up-to-date data on every apply.
+func (p *Plan) CanSkipApply() bool {
+	if p.Errored || p.UIMode != NormalMode || p.PriorState == nil {
+		return false
+	}
+	if p.Changes == nil || !p.Changes.Empty() {
+		return false
+	}
+	for _, oc := range p.Changes.Outputs {
+		// Empty ignores changes to the outputs of child modules, but the
+		// apply walk must still remove any that are no longer declared.
+		if oc.Action != NoOp {
+			return false
+		}
+	}
+	if len(p.TargetAddrs) > 0 || len(p.ExcludeAddrs) > 0 {
+		// The apply step reports that targeted changes may be incomplete.
+		return false
+	}
+	if len(p.ExternalReferences) > 0 {
+		// The testing framework needs the apply walk to evaluate them.
+		return false
+	}
+
+	if p.Checks != nil {
+		for _, elem := range p.Checks.ConfigResults.Elems {
+			if elem.Key.CheckableKind() == addrs.CheckableCheck {
+				return false
+			}
+			if elem.Value.Status != checks.StatusPass {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // ProviderAddrs returns a list of all of the provider configuration addresses
 // referenced throughout the receiving plan.
 //
@@ -215,6 +297,10 @@ type Backend struct {
 	// but storing this explicitly allows us to return a better error message
 	// in the situation where the user has the wrong workspace selected.)
 	Workspace string
+
+	// Namespace is the state namespace of the backend configuration, or
+	// an empty string if the backend doesn't use a state namespace.
+	Namespace string
 }
 
 func NewBackend(typeName string, config cty.Value, configSchema *configschema.Block, workspaceName string) (*Backend, error) {
@@ -11,6 +11,8 @@ import (
 	"github.com/go-test/deep"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/states"
 )
 
 func TestProviderAddrs(t *testing.T) {
@@ -98,3 +100,102 @@ func TestModuleOutputChangesEmpty(t *testing.T) {
 		t.Fatal("plan has no visible changes")
 	}
 }
+
+func TestPlanCanSkipApply(t *testing.T) {
+	resourceCheck := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "woot",
+	}.InModule(addrs.RootModule)
+	checkBlock := addrs.Check{Name: "health"}.InModule(addrs.RootModule)
+
+	checkResults := func(addr addrs.ConfigCheckable, status checks.Status) *states.CheckResults {
+		return &states.CheckResults{
+			ConfigResults: addrs.MakeMap(
+				addrs.MakeMapElem[addrs.ConfigCheckable](addr, &states.CheckResultAggregate{
+					Status: status,
+				}),
+			),
+		}
+	}
+
+	tests := map[string]struct {
+		plan *Plan
+		want bool
+	}{
+		"empty": {
+			&Plan{UIMode: NormalMode, Changes: NewChanges(), PriorState: states.NewState()},
+			true,
+		},
+		"errored": {
+			&Plan{UIMode: NormalMode, Changes: NewChanges(), PriorState: states.NewState(), Errored: true},
+			false,
+		},
+		"refresh-only": {
+			&Plan{UIMode: RefreshOnlyMode, Changes: NewChanges(), PriorState: states.NewState()},
+			false,
+		},
+		"resource change": {
+			&Plan{
+				UIMode: NormalMode,
+				Changes: &Changes{
+					Resources: []*ResourceInstanceChangeSrc{
+						{
+							Addr:      resourceCheck.Resource.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+							ChangeSrc: ChangeSrc{Action: Create},
+						},
+					},
+				},
+				PriorState: states.NewState(),
+			},
+			false,
+		},
+		"module output change": {
+			&Plan{
+				UIMode: NormalMode,
+				Changes: &Changes{
+					Outputs: []*OutputChangeSrc{
+						{
+							Addr: addrs.AbsOutputValue{
+								Module:      addrs.RootModuleInstance.Child("child", addrs.NoKey),
+								OutputValue: addrs.OutputValue{Name: "output"},
+							},
+							ChangeSrc: ChangeSrc{Action: Delete},
+						},
+					},
+				},
+				PriorState: states.NewState(),
+			},
+			false,
+		},
+		"targeted": {
+			&Plan{
+				UIMode:      NormalMode,
+				Changes:     NewChanges(),
+				PriorState:  states.NewState(),
+				TargetAddrs: []addrs.Targetable{resourceCheck.Resource.Absolute(addrs.RootModuleInstance)},
+			},
+			false,
+		},
+		"passed condition": {
+			&Plan{UIMode: NormalMode, Changes: NewChanges(), PriorState: states.NewState(), Checks: checkResults(resourceCheck, checks.StatusPass)},
+			true,
+		},
+		"unknown condition": {
+			&Plan{UIMode: NormalMode, Changes: NewChanges(), PriorState: states.NewState(), Checks: checkResults(resourceCheck, checks.StatusUnknown)},
+			false,
+		},
+		"check block": {
+			&Plan{UIMode: NormalMode, Changes: NewChanges(), PriorState: states.NewState(), Checks: checkResults(checkBlock, checks.StatusPass)},
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.plan.CanSkipApply(); got != test.want {
+				t.Errorf("wrong result: got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -95,6 +95,16 @@ func TestRoundtrip(t *testing.T) {
 		},
 	)
 
+	packagesIn := ProviderPackages{
+		addrs.NewDefaultProvider("boop"): {
+			Version: getproviders.MustParseVersion("1.0.0"),
+			Hash:    getproviders.MustParseHash("h1:hello"),
+		},
+		addrs.NewDefaultProvider("beep"): {
+			Development: true,
+		},
+	}
+
 	planFn := filepath.Join(t.TempDir(), "tfplan")
 
 	err = Create(planFn, CreateArgs{
@@ -103,6 +113,7 @@ func TestRoundtrip(t *testing.T) {
 		StateFile:            stateFileIn,
 		Plan:                 planIn,
 		DependencyLocks:      locksIn,
+		ProviderPackages:     packagesIn,
 	}, encryption.PlanEncryptionDisabled())
 	if err != nil {
 		t.Fatalf("failed to create plan file: %s", err)
@@ -181,6 +192,96 @@ func TestRoundtrip(t *testing.T) {
 			t.Errorf("provider locks did not survive round-trip\n%s", diff)
 		}
 	})
+
+	t.Run("ReadProviderPackages", func(t *testing.T) {
+		packagesOut, err := pr.ReadProviderPackages()
+		if err != nil {
+			t.Fatalf("failed to read provider packages: %s", err)
+		}
+		if diff := cmp.Diff(packagesIn, packagesOut); diff != "" {
+			t.Errorf("provider packages did not survive round-trip\n%s", diff)
+		}
+	})
+}
+
+func TestProviderPackagesDifferences(t *testing.T) {
+	boop := addrs.NewDefaultProvider("boop")
+	beep := addrs.NewDefaultProvider("beep")
+	planned := ProviderPackages{
+		boop: {
+			Version: getproviders.MustParseVersion("1.0.0"),
+			Hash:    getproviders.MustParseHash("h1:hello"),
+		},
+		beep: {
+			Development: true,
+		},
+	}
+
+	tests := map[string]struct {
+		current ProviderPackages
+		want    []string
+	}{
+		"same": {
+			ProviderPackages{
+				boop: planned[boop],
+				beep: planned[beep],
+			},
+			nil,
+		},
+		"different hash": {
+			ProviderPackages{
+				boop: {
+					Version: getproviders.MustParseVersion("1.0.0"),
+					Hash:    getproviders.MustParseHash("h1:goodbye"),
+				},
+				beep: planned[beep],
+			},
+			[]string{
+				"the installed package of hashicorp/boop 1.0.0 has the checksum h1:goodbye, but the plan was created with a package whose checksum is h1:hello",
+			},
+		},
+		"different version": {
+			ProviderPackages{
+				boop: {
+					Version: getproviders.MustParseVersion("1.1.0"),
+					Hash:    getproviders.MustParseHash("h1:hello"),
+				},
+				beep: planned[beep],
+			},
+			[]string{
+				"hashicorp/boop 1.0.0 was used to create the plan, but 1.1.0 is installed now",
+			},
+		},
+		"no longer a development override": {
+			ProviderPackages{
+				boop: planned[boop],
+				beep: {
+					Version: getproviders.MustParseVersion("2.0.0"),
+					Hash:    getproviders.MustParseHash("h1:beep"),
+				},
+			},
+			[]string{
+				"hashicorp/beep was a development override when the plan was created, but 2.0.0 is installed now",
+			},
+		},
+		"missing": {
+			ProviderPackages{
+				beep: planned[beep],
+			},
+			[]string{
+				"hashicorp/boop was used to create the plan, but isn't available now",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := planned.Differences(test.current)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
 }
 
 func TestWrappedError(t *testing.T) {
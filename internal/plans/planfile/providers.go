@@ -0,0 +1,150 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+const providerPackagesFilename = "tfplan-providers.json"
+
+// ProviderPackage describes the package of a provider that OpenTofu used to
+// create a plan.
+type ProviderPackage struct {
+	// Version is the version of the provider, and Hash is the hash of its
+	// package in the local provider cache directory, which identifies the
+	// exact build for the current platform.
+	Version getproviders.Version
+	Hash    getproviders.Hash
+
+	// Development is true for providers that OpenTofu didn't run from an
+	// installed package, such as development overrides in the CLI
+	// configuration, whose version and hash are unknown.
+	Development bool
+}
+
+// ProviderPackages records the provider packages that OpenTofu used to create
+// a plan, so that applying the plan can check that it uses the same ones.
+type ProviderPackages map[addrs.Provider]ProviderPackage
+
+// Differences returns descriptions of the differences between the packages
+// recorded in a plan and the given packages that are available now, in a
+// stable order. The result is empty if the packages are the same.
+func (p ProviderPackages) Differences(current ProviderPackages) []string {
+	providers := make([]addrs.Provider, 0, len(p)+len(current))
+	for provider := range p {
+		providers = append(providers, provider)
+	}
+	for provider := range current {
+		if _, exists := p[provider]; !exists {
+			providers = append(providers, provider)
+		}
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].LessThan(providers[j])
+	})
+
+	var ret []string
+	for _, provider := range providers {
+		planned, inPlan := p[provider]
+		installed, isInstalled := current[provider]
+		switch {
+		case !isInstalled:
+			ret = append(ret, fmt.Sprintf("%s was used to create the plan, but isn't available now", provider.ForDisplay()))
+		case !inPlan:
+			ret = append(ret, fmt.Sprintf("%s is available now, but wasn't used to create the plan", provider.ForDisplay()))
+		case planned.Development && !installed.Development:
+			ret = append(ret, fmt.Sprintf("%s was a development override when the plan was created, but %s is installed now", provider.ForDisplay(), installed.Version))
+		case !planned.Development && installed.Development:
+			ret = append(ret, fmt.Sprintf("%s %s was used to create the plan, but it's a development override now", provider.ForDisplay(), planned.Version))
+		case planned.Development:
+			// We can't compare development overrides any further.
+		case !planned.Version.Same(installed.Version):
+			ret = append(ret, fmt.Sprintf("%s %s was used to create the plan, but %s is installed now", provider.ForDisplay(), planned.Version, installed.Version))
+		case planned.Hash != installed.Hash:
+			ret = append(ret, fmt.Sprintf("the installed package of %s %s has the checksum %s, but the plan was created with a package whose checksum is %s", provider.ForDisplay(), installed.Version, installed.Hash, planned.Hash))
+		}
+	}
+	return ret
+}
+
+// providerPackagesJSON is the serialization of ProviderPackages in a plan
+// file.
+type providerPackagesJSON struct {
+	FormatVersion string                         `json:"format_version"`
+	Providers     map[string]providerPackageJSON `json:"providers"`
+}
+
+type providerPackageJSON struct {
+	Version     string `json:"version,omitempty"`
+	Hash        string `json:"hash,omitempty"`
+	Development bool   `json:"development,omitempty"`
+}
+
+const providerPackagesFormatVersion = "1.0"
+
+func writeProviderPackages(packages ProviderPackages, w io.Writer) error {
+	raw := providerPackagesJSON{
+		FormatVersion: providerPackagesFormatVersion,
+		Providers:     make(map[string]providerPackageJSON, len(packages)),
+	}
+	for provider, pkg := range packages {
+		rawPkg := providerPackageJSON{
+			Hash:        pkg.Hash.String(),
+			Development: pkg.Development,
+		}
+		if pkg.Version != getproviders.UnspecifiedVersion {
+			rawPkg.Version = pkg.Version.String()
+		}
+		raw.Providers[provider.String()] = rawPkg
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(raw)
+}
+
+func readProviderPackages(r io.Reader) (ProviderPackages, error) {
+	var raw providerPackagesJSON
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	if raw.FormatVersion != providerPackagesFormatVersion {
+		return nil, fmt.Errorf("unsupported format version %q", raw.FormatVersion)
+	}
+
+	ret := make(ProviderPackages, len(raw.Providers))
+	for addr, rawPkg := range raw.Providers {
+		provider, diags := addrs.ParseProviderSourceString(addr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("invalid provider address %q: %w", addr, diags.Err())
+		}
+		pkg := ProviderPackage{
+			Development: rawPkg.Development,
+		}
+		if rawPkg.Version != "" {
+			version, err := getproviders.ParseVersion(rawPkg.Version)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version for %s: %w", addr, err)
+			}
+			pkg.Version = version
+		}
+		if rawPkg.Hash != "" {
+			hash, err := getproviders.ParseHash(rawPkg.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hash for %s: %w", addr, err)
+			}
+			pkg.Hash = hash
+		}
+		ret[provider] = pkg
+	}
+	return ret, nil
+}
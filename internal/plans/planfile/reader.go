@@ -163,6 +163,38 @@ func (r *Reader) ReadPlan() (*plans.Plan, error) {
 	return ret, nil
 }
 
+// ReadPlanForInspection reads the planned changes embedded in the plan file,
+// along with the version of OpenTofu that created it.
+//
+// Unlike ReadPlan, this accepts plan files created by any version of OpenTofu
+// that uses the current plan file format version, and it does not read the
+// embedded prior and previous run states. The result is only suitable for
+// inspecting the planned changes and must never be applied.
+func (r *Reader) ReadPlanForInspection() (*plans.Plan, string, error) {
+	var planFile *zip.File
+	for _, file := range r.zip.File {
+		if file.Name == tfplanFilename {
+			planFile = file
+			break
+		}
+	}
+	if planFile == nil {
+		return nil, "", errUnusable(fmt.Errorf("the plan file is invalid"))
+	}
+
+	pr, err := planFile.Open()
+	if err != nil {
+		return nil, "", errUnusable(fmt.Errorf("failed to retrieve plan from plan file: %w", err))
+	}
+	defer pr.Close()
+
+	ret, createdBy, err := readTfplanAnyVersion(pr)
+	if err != nil {
+		return nil, "", errUnusable(err)
+	}
+	return ret, createdBy, nil
+}
+
 // ReadStateFile reads the state file embedded in the plan file, which
 // represents the "PriorState" as defined in plans.Plan.
 //
@@ -277,3 +309,26 @@ func (r *Reader) ReadDependencyLocks() (*depsfile.Locks, tfdiags.Diagnostics) {
 	))
 	return nil, diags
 }
+
+// ReadProviderPackages reads the description of the provider packages that
+// were used to create the plan.
+//
+// Plan files created by earlier versions of OpenTofu don't include this
+// information, in which case the result is nil.
+func (r *Reader) ReadProviderPackages() (ProviderPackages, error) {
+	for _, file := range r.zip.File {
+		if file.Name == providerPackagesFilename {
+			r, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract provider packages from plan file: %w", err)
+			}
+			defer r.Close()
+			ret, err := readProviderPackages(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read provider packages from plan file: %w", err)
+			}
+			return ret, nil
+		}
+	}
+	return nil, nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/internal/planproto"
+	"github.com/opentofu/opentofu/internal/secrets"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/version"
 )
@@ -38,23 +39,33 @@ const tfplanFilename = "tfplan"
 // a plan file, which is stored in a special file in the archive called
 // "tfplan".
 func readTfplan(r io.Reader) (*plans.Plan, error) {
-	src, err := io.ReadAll(r)
+	plan, createdBy, err := readTfplanAnyVersion(r)
 	if err != nil {
 		return nil, err
 	}
+	if createdBy != version.String() {
+		return nil, fmt.Errorf("plan file was created by OpenTofu or Terraform %s, but this is %s; plan files cannot be transferred between different versions of OpenTofu / Terraform", createdBy, version.String())
+	}
+	return plan, nil
+}
+
+// readTfplanAnyVersion is like readTfplan but accepts a plan created by any
+// version of OpenTofu that uses the current plan file format version,
+// returning the version that created it alongside the plan.
+func readTfplanAnyVersion(r io.Reader) (*plans.Plan, string, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
 
 	var rawPlan planproto.Plan
 	err = proto.Unmarshal(src, &rawPlan)
 	if err != nil {
-		return nil, fmt.Errorf("parse error: %w", err)
+		return nil, "", fmt.Errorf("parse error: %w", err)
 	}
 
 	if rawPlan.Version != tfplanFormatVersion {
-		return nil, fmt.Errorf("unsupported plan file format version %d; only version %d is supported", rawPlan.Version, tfplanFormatVersion)
-	}
-
-	if rawPlan.TerraformVersion != version.String() {
-		return nil, fmt.Errorf("plan file was created by OpenTofu or Terraform %s, but this is %s; plan files cannot be transferred between different versions of OpenTofu / Terraform", rawPlan.TerraformVersion, version.String())
+		return nil, "", fmt.Errorf("unsupported plan file format version %d; only version %d is supported", rawPlan.Version, tfplanFormatVersion)
 	}
 
 	plan := &plans.Plan{
@@ -77,14 +88,14 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	case planproto.Mode_REFRESH_ONLY:
 		plan.UIMode = plans.RefreshOnlyMode
 	default:
-		return nil, fmt.Errorf("plan has invalid mode %s", rawPlan.UiMode)
+		return nil, "", fmt.Errorf("plan has invalid mode %s", rawPlan.UiMode)
 	}
 
 	for _, rawOC := range rawPlan.OutputChanges {
 		name := rawOC.Name
 		change, err := changeFromTfplan(rawOC.Change)
 		if err != nil {
-			return nil, fmt.Errorf("invalid plan for output %q: %w", name, err)
+			return nil, "", fmt.Errorf("invalid plan for output %q: %w", name, err)
 		}
 
 		plan.Changes.Outputs = append(plan.Changes.Outputs, &plans.OutputChangeSrc{
@@ -110,7 +121,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		case planproto.CheckResults_ERROR:
 			aggr.Status = checks.StatusError
 		default:
-			return nil, fmt.Errorf("aggregate check results for %s have unsupported status %#v", rawCRs.ConfigAddr, rawCRs.Status)
+			return nil, "", fmt.Errorf("aggregate check results for %s have unsupported status %#v", rawCRs.ConfigAddr, rawCRs.Status)
 		}
 
 		var objKind addrs.CheckableKind
@@ -124,7 +135,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		case planproto.CheckResults_INPUT_VARIABLE:
 			objKind = addrs.CheckableInputVariable
 		default:
-			return nil, fmt.Errorf("aggregate check results for %s have unsupported object kind %s", rawCRs.ConfigAddr, objKind)
+			return nil, "", fmt.Errorf("aggregate check results for %s have unsupported object kind %s", rawCRs.ConfigAddr, objKind)
 		}
 
 		// Some trickiness here: we only have an address parser for
@@ -135,24 +146,24 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		// thus we can reuse the same parser for both here.
 		configAddrProxy, diags := addrs.ParseCheckableStr(objKind, rawCRs.ConfigAddr)
 		if diags.HasErrors() {
-			return nil, diags.Err()
+			return nil, "", diags.Err()
 		}
 		configAddr := configAddrProxy.ConfigCheckable()
 		if configAddr.String() != configAddrProxy.String() {
 			// This is how we catch if the config address included index
 			// information that would be allowed in a Checkable but not
 			// in a ConfigCheckable.
-			return nil, fmt.Errorf("invalid checkable config address %s", rawCRs.ConfigAddr)
+			return nil, "", fmt.Errorf("invalid checkable config address %s", rawCRs.ConfigAddr)
 		}
 
 		aggr.ObjectResults = addrs.MakeMap[addrs.Checkable, *states.CheckResultObject]()
 		for _, rawCR := range rawCRs.Objects {
 			objectAddr, diags := addrs.ParseCheckableStr(objKind, rawCR.ObjectAddr)
 			if diags.HasErrors() {
-				return nil, diags.Err()
+				return nil, "", diags.Err()
 			}
 			if !addrs.Equivalent(objectAddr.ConfigCheckable(), configAddr) {
-				return nil, fmt.Errorf("checkable object %s should not be grouped under %s", objectAddr, configAddr)
+				return nil, "", fmt.Errorf("checkable object %s should not be grouped under %s", objectAddr, configAddr)
 			}
 
 			obj := &states.CheckResultObject{
@@ -168,7 +179,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 			case planproto.CheckResults_ERROR:
 				obj.Status = checks.StatusError
 			default:
-				return nil, fmt.Errorf("object check results for %s has unsupported status %#v", rawCR.ObjectAddr, rawCR.Status)
+				return nil, "", fmt.Errorf("object check results for %s has unsupported status %#v", rawCR.ObjectAddr, rawCR.Status)
 			}
 
 			aggr.ObjectResults.Put(objectAddr, obj)
@@ -191,7 +202,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		change, err := resourceChangeFromTfplan(rawRC)
 		if err != nil {
 			// errors from resourceChangeFromTfplan already include context
-			return nil, err
+			return nil, "", err
 		}
 
 		plan.Changes.Resources = append(plan.Changes.Resources, change)
@@ -201,7 +212,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		change, err := resourceChangeFromTfplan(rawRC)
 		if err != nil {
 			// errors from resourceChangeFromTfplan already include context
-			return nil, err
+			return nil, "", err
 		}
 
 		plan.DriftedResources = append(plan.DriftedResources, change)
@@ -210,7 +221,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	for _, rawRA := range rawPlan.RelevantAttributes {
 		ra, err := resourceAttrFromTfplan(rawRA)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		plan.RelevantAttributes = append(plan.RelevantAttributes, ra)
 	}
@@ -218,7 +229,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	for _, rawTargetAddr := range rawPlan.TargetAddrs {
 		target, diags := addrs.ParseTargetStr(rawTargetAddr)
 		if diags.HasErrors() {
-			return nil, fmt.Errorf("plan contains invalid target address %q: %w", target, diags.Err())
+			return nil, "", fmt.Errorf("plan contains invalid target address %q: %w", target, diags.Err())
 		}
 		plan.TargetAddrs = append(plan.TargetAddrs, target.Subject)
 	}
@@ -226,7 +237,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	for _, rawExcludeAddr := range rawPlan.ExcludeAddrs {
 		exclude, diags := addrs.ParseTargetStr(rawExcludeAddr)
 		if diags.HasErrors() {
-			return nil, fmt.Errorf("plan contains invalid exclude address %q: %w", exclude, diags.Err())
+			return nil, "", fmt.Errorf("plan contains invalid exclude address %q: %w", exclude, diags.Err())
 		}
 		plan.ExcludeAddrs = append(plan.ExcludeAddrs, exclude.Subject)
 	}
@@ -234,7 +245,7 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	for _, rawReplaceAddr := range rawPlan.ForceReplaceAddrs {
 		addr, diags := addrs.ParseAbsResourceInstanceStr(rawReplaceAddr)
 		if diags.HasErrors() {
-			return nil, fmt.Errorf("plan contains invalid force-replace address %q: %w", addr, diags.Err())
+			return nil, "", fmt.Errorf("plan contains invalid force-replace address %q: %w", addr, diags.Err())
 		}
 		plan.ForceReplaceAddrs = append(plan.ForceReplaceAddrs, addr)
 	}
@@ -242,30 +253,38 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	for name, rawVal := range rawPlan.Variables {
 		val, err := valueFromTfplan(rawVal)
 		if err != nil {
-			return nil, fmt.Errorf("invalid value for input variable %q: %w", name, err)
+			return nil, "", fmt.Errorf("invalid value for input variable %q: %w", name, err)
 		}
 		plan.VariableValues[name] = val
+		if rawVal.SecretRef != "" {
+			if plan.SecretVariables == nil {
+				plan.SecretVariables = make(map[string]bool)
+			}
+			plan.SecretVariables[name] = true
+		}
 	}
 
 	if rawBackend := rawPlan.Backend; rawBackend == nil {
-		return nil, fmt.Errorf("plan file has no backend settings; backend settings are required")
+		return nil, "", fmt.Errorf("plan file has no backend settings; backend settings are required")
 	} else {
 		config, err := valueFromTfplan(rawBackend.Config)
 		if err != nil {
-			return nil, fmt.Errorf("plan file has invalid backend configuration: %w", err)
+			return nil, "", fmt.Errorf("plan file has invalid backend configuration: %w", err)
 		}
 		plan.Backend = plans.Backend{
 			Type:      rawBackend.Type,
 			Config:    config,
 			Workspace: rawBackend.Workspace,
+			Namespace: rawBackend.Namespace,
 		}
 	}
 
 	if plan.Timestamp, err = time.Parse(time.RFC3339, rawPlan.Timestamp); err != nil {
-		return nil, fmt.Errorf("invalid value for timestamp %s: %w", rawPlan.Timestamp, err)
+		return nil, "", fmt.Errorf("invalid value for timestamp %s: %w", rawPlan.Timestamp, err)
 	}
+	plan.RunID = rawPlan.RunId
 
-	return plan, nil
+	return plan, rawPlan.TerraformVersion, nil
 }
 
 func resourceChangeFromTfplan(rawChange *planproto.ResourceInstanceChange) (*plans.ResourceInstanceChangeSrc, error) {
@@ -358,6 +377,10 @@ func resourceChangeFromTfplan(rawChange *planproto.ResourceInstanceChange) (*pla
 		ret.ActionReason = plans.ResourceInstanceReadBecauseCheckNested
 	case planproto.ResourceInstanceActionReason_DELETE_BECAUSE_NO_MOVE_TARGET:
 		ret.ActionReason = plans.ResourceInstanceDeleteBecauseNoMoveTarget
+	case planproto.ResourceInstanceActionReason_DELETE_BECAUSE_REMOVED_BLOCK:
+		ret.ActionReason = plans.ResourceInstanceDeleteBecauseRemovedBlock
+	case planproto.ResourceInstanceActionReason_CREATE_BECAUSE_DELETED:
+		ret.ActionReason = plans.ResourceInstanceCreateBecauseDeleted
 	default:
 		return nil, fmt.Errorf("resource has invalid action reason %s", rawChange.ActionReason)
 	}
@@ -468,6 +491,13 @@ func changeFromTfplan(rawChange *planproto.Change) (*plans.ChangeSrc, error) {
 }
 
 func valueFromTfplan(rawV *planproto.DynamicValue) (plans.DynamicValue, error) {
+	if rawV.SecretRef != "" {
+		raw, err := secrets.CurrentStore().Get(rawV.SecretRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve secret value from the secrets store: %w", err)
+		}
+		return plans.DynamicValue(raw), nil
+	}
 	if len(rawV.Msgpack) == 0 { // len(0) because that's the default value for a "bytes" in protobuf
 		return nil, fmt.Errorf("dynamic value does not have msgpack serialization")
 	}
@@ -477,6 +507,11 @@ func valueFromTfplan(rawV *planproto.DynamicValue) (plans.DynamicValue, error) {
 
 // writeTfplan serializes the given plan into the protobuf-based format used
 // for the "tfplan" portion of a plan file.
+//
+// The values that include values marked as secret are saved in the secrets
+// store set with secrets.SetStore, and the plan only records references to
+// them. The values in a plan don't include their types, so unlike in a state
+// the whole value of an input variable or of one side of a change is saved.
 func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	if plan == nil {
 		return fmt.Errorf("cannot write plan file for nil plan")
@@ -498,6 +533,10 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 
 	rawPlan.Errored = plan.Errored
 
+	// Each plan saves its secret values under its own keys, so that saving
+	// a plan never replaces the values that another saved plan refers to.
+	secretKeyPrefix := "plan/" + plan.RunID + "/"
+
 	switch plan.UIMode {
 	case plans.NormalMode:
 		rawPlan.UiMode = planproto.Mode_NORMAL
@@ -522,7 +561,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 		// Writing outputs as cty.DynamicPseudoType forces the stored values
 		// to also contain dynamic type information, so we can recover the
 		// original type when we read the values back in readTFPlan.
-		protoChange, err := changeToTfplan(&oc.ChangeSrc)
+		protoChange, err := changeToTfplan(&oc.ChangeSrc, secretKeyPrefix+oc.Addr.String())
 		if err != nil {
 			return fmt.Errorf("cannot write output value %q: %w", name, err)
 		}
@@ -591,7 +630,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	}
 
 	for _, rc := range plan.Changes.Resources {
-		rawRC, err := resourceChangeToTfplan(rc)
+		rawRC, err := resourceChangeToTfplan(rc, secretKeyPrefix)
 		if err != nil {
 			return err
 		}
@@ -599,7 +638,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	}
 
 	for _, rc := range plan.DriftedResources {
-		rawRC, err := resourceChangeToTfplan(rc)
+		rawRC, err := resourceChangeToTfplan(rc, secretKeyPrefix+"drift/")
 		if err != nil {
 			return err
 		}
@@ -627,6 +666,14 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	}
 
 	for name, val := range plan.VariableValues {
+		if plan.SecretVariables[name] {
+			rawVal, err := secretValueToTfplan(val, secretKeyPrefix+"var."+name)
+			if err != nil {
+				return fmt.Errorf("cannot write input variable %q: %w", name, err)
+			}
+			rawPlan.Variables[name] = rawVal
+			continue
+		}
 		rawPlan.Variables[name] = valueToTfplan(val)
 	}
 
@@ -641,9 +688,11 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 		Type:      plan.Backend.Type,
 		Config:    valueToTfplan(plan.Backend.Config),
 		Workspace: plan.Backend.Workspace,
+		Namespace: plan.Backend.Namespace,
 	}
 
 	rawPlan.Timestamp = plan.Timestamp.Format(time.RFC3339)
+	rawPlan.RunId = plan.RunID
 
 	src, err := proto.Marshal(rawPlan)
 	if err != nil {
@@ -691,7 +740,7 @@ func resourceAttrFromTfplan(ra *planproto.PlanResourceAttr) (globalref.ResourceA
 	return res, nil
 }
 
-func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto.ResourceInstanceChange, error) {
+func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc, secretKeyPrefix string) (*planproto.ResourceInstanceChange, error) {
 	ret := &planproto.ResourceInstanceChange{}
 
 	if change.PrevRunAddr.Resource.Resource.Type == "" {
@@ -723,7 +772,11 @@ func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto
 		ret.RequiredReplace = append(ret.RequiredReplace, path)
 	}
 
-	valChange, err := changeToTfplan(&change.ChangeSrc)
+	secretKey := secretKeyPrefix + change.Addr.String()
+	if change.DeposedKey != states.NotDeposed {
+		secretKey += "/deposed/" + string(change.DeposedKey)
+	}
+	valChange, err := changeToTfplan(&change.ChangeSrc, secretKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize resource %s change: %w", change.Addr, err)
 	}
@@ -758,6 +811,10 @@ func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto
 		ret.ActionReason = planproto.ResourceInstanceActionReason_READ_BECAUSE_CHECK_NESTED
 	case plans.ResourceInstanceDeleteBecauseNoMoveTarget:
 		ret.ActionReason = planproto.ResourceInstanceActionReason_DELETE_BECAUSE_NO_MOVE_TARGET
+	case plans.ResourceInstanceDeleteBecauseRemovedBlock:
+		ret.ActionReason = planproto.ResourceInstanceActionReason_DELETE_BECAUSE_REMOVED_BLOCK
+	case plans.ResourceInstanceCreateBecauseDeleted:
+		ret.ActionReason = planproto.ResourceInstanceActionReason_CREATE_BECAUSE_DELETED
 	default:
 		return nil, fmt.Errorf("resource %s has unsupported action reason %s", change.Addr, change.ActionReason)
 	}
@@ -769,11 +826,28 @@ func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto
 	return ret, nil
 }
 
-func changeToTfplan(change *plans.ChangeSrc) (*planproto.Change, error) {
+// changeToTfplan serializes the given change, saving the values that include
+// values marked as secret in the secrets store under keys that start with
+// the given key.
+func changeToTfplan(change *plans.ChangeSrc, secretKey string) (*planproto.Change, error) {
 	ret := &planproto.Change{}
 
 	before := valueToTfplan(change.Before)
+	if change.Before != nil && hasSecretMarks(change.BeforeValMarks) {
+		var err error
+		before, err = secretValueToTfplan(change.Before, secretKey+"/before")
+		if err != nil {
+			return nil, err
+		}
+	}
 	after := valueToTfplan(change.After)
+	if change.After != nil && hasSecretMarks(change.AfterValMarks) {
+		var err error
+		after, err = secretValueToTfplan(change.After, secretKey+"/after")
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	beforeSensitivePaths, err := pathValueMarksToTfplan(change.BeforeValMarks)
 	if err != nil {
@@ -837,6 +911,30 @@ func valueToTfplan(val plans.DynamicValue) *planproto.DynamicValue {
 	}
 }
 
+// secretValueToTfplan saves the given value in the secrets store set with
+// secrets.SetStore under the given key, and returns a value that refers to
+// it.
+func secretValueToTfplan(val plans.DynamicValue, key string) (*planproto.DynamicValue, error) {
+	ref, err := secrets.CurrentStore().Put(key, []byte(val))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save secret value in the secrets store: %w", err)
+	}
+	return &planproto.DynamicValue{
+		SecretRef: ref,
+	}, nil
+}
+
+// hasSecretMarks returns true if any of the given marks are marked as
+// secret.
+func hasSecretMarks(pvm []cty.PathValueMarks) bool {
+	for _, pm := range pvm {
+		if _, secret := pm.Marks[marks.Secret]; secret {
+			return true
+		}
+	}
+	return false
+}
+
 func pathValueMarksFromTfplan(paths []*planproto.Path, marks cty.ValueMarks) ([]cty.PathValueMarks, error) {
 	ret := make([]cty.PathValueMarks, 0, len(paths))
 	for _, p := range paths {
@@ -7,6 +7,7 @@ package planfile
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -17,6 +18,7 @@ import (
 	"github.com/opentofu/opentofu/internal/lang/globalref"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/secrets"
 	"github.com/opentofu/opentofu/internal/states"
 )
 
@@ -26,6 +28,7 @@ func TestTFPlanRoundTrip(t *testing.T) {
 	})
 
 	plan := &plans.Plan{
+		RunID: "run-123",
 		VariableValues: map[string]plans.DynamicValue{
 			"foo": mustNewDynamicValueStr("foo value"),
 		},
@@ -289,6 +292,7 @@ func TestTFPlanRoundTrip(t *testing.T) {
 				}),
 			),
 			Workspace: "default",
+			Namespace: "networking",
 		},
 	}
 
@@ -442,3 +446,99 @@ func TestTFPlanRoundTripDestroy(t *testing.T) {
 		}
 	}
 }
+
+func TestTFPlanRoundTripSecrets(t *testing.T) {
+	store := secrets.NewMemoryStore()
+	secrets.SetStore(store)
+	t.Cleanup(func() {
+		secrets.SetStore(nil)
+	})
+
+	objTy := cty.Object(map[string]cty.Type{
+		"id":       cty.String,
+		"password": cty.String,
+	})
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "woot",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+
+	plan := &plans.Plan{
+		RunID: "run-123",
+		VariableValues: map[string]plans.DynamicValue{
+			"password": mustNewDynamicValue(cty.StringVal("hunter2"), cty.DynamicPseudoType),
+			"region":   mustNewDynamicValue(cty.StringVal("eu-west-1"), cty.DynamicPseudoType),
+		},
+		SecretVariables: map[string]bool{
+			"password": true,
+		},
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{
+				{
+					Addr:        addr,
+					PrevRunAddr: addr,
+					ProviderAddr: addrs.AbsProviderConfig{
+						Provider: addrs.NewDefaultProvider("test"),
+						Module:   addrs.RootModule,
+					},
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Create,
+						Before: mustNewDynamicValue(cty.NullVal(objTy), objTy),
+						After: mustNewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+							"id":       cty.UnknownVal(cty.String),
+							"password": cty.StringVal("hunter2"),
+						}), objTy),
+						AfterValMarks: []cty.PathValueMarks{
+							{
+								Path:  cty.GetAttrPath("password"),
+								Marks: cty.NewValueMarks(marks.Sensitive, marks.Secret),
+							},
+						},
+					},
+				},
+			},
+		},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    mustNewDynamicValue(cty.EmptyObjectVal, cty.EmptyObject),
+			Workspace: "default",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTfplan(plan, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatal("the plan includes the secret value")
+	}
+	if !strings.Contains(buf.String(), "eu-west-1") {
+		t.Fatal("the plan doesn't include the value of the variable that isn't secret")
+	}
+	if got, want := store.Len(), 2; got != want {
+		t.Fatalf("wrong number of values in the store %d; want %d", got, want)
+	}
+
+	newPlan, err := readTfplan(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !newPlan.SecretVariables["password"] || newPlan.SecretVariables["region"] {
+		t.Errorf("wrong secret variables %#v", newPlan.SecretVariables)
+	}
+	password, err := newPlan.VariableValues["password"].Decode(cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !password.RawEquals(cty.StringVal("hunter2")) {
+		t.Errorf("wrong value for the secret variable %#v", password)
+	}
+	ric, err := newPlan.Changes.Resources[0].Decode(objTy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ric.After.GetAttr("password"), cty.StringVal("hunter2"); !got.RawEquals(want.Mark(marks.Sensitive)) {
+		t.Errorf("wrong planned value for the secret attribute %#v", got)
+	}
+}
@@ -46,6 +46,11 @@ type CreateArgs struct {
 	// checked prior to creating the plan, so we can make sure that all of the
 	// same dependencies are still available when applying the plan.
 	DependencyLocks *depsfile.Locks
+
+	// ProviderPackages records the provider packages that were used to
+	// create the plan, so we can make sure that applying the plan uses the
+	// same ones.
+	ProviderPackages ProviderPackages
 }
 
 // Create creates a new plan file with the given filename, overwriting any
@@ -136,6 +141,22 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 		}
 	}
 
+	// tfplan-providers.json file, describing the provider packages
+	if args.ProviderPackages != nil {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     providerPackagesFilename,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create embedded provider packages file: %w", err)
+		}
+		err = writeProviderPackages(args.ProviderPackages, w)
+		if err != nil {
+			return fmt.Errorf("failed to write embedded provider packages file: %w", err)
+		}
+	}
+
 	// Finish zip file
 	zw.Close()
 	// Encrypt payload
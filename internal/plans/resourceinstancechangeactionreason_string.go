@@ -19,6 +19,8 @@ func _() {
 	_ = x[ResourceInstanceDeleteBecauseEachKey-69]
 	_ = x[ResourceInstanceDeleteBecauseNoModule-77]
 	_ = x[ResourceInstanceDeleteBecauseNoMoveTarget-65]
+	_ = x[ResourceInstanceDeleteBecauseRemovedBlock-66]
+	_ = x[ResourceInstanceCreateBecauseDeleted-71]
 	_ = x[ResourceInstanceReadBecauseConfigUnknown-63]
 	_ = x[ResourceInstanceReadBecauseDependencyPending-33]
 	_ = x[ResourceInstanceReadBecauseCheckNested-35]
@@ -29,8 +31,8 @@ const (
 	_ResourceInstanceChangeActionReason_name_1 = "ResourceInstanceReadBecauseDependencyPending"
 	_ResourceInstanceChangeActionReason_name_2 = "ResourceInstanceReadBecauseCheckNested"
 	_ResourceInstanceChangeActionReason_name_3 = "ResourceInstanceReadBecauseConfigUnknown"
-	_ResourceInstanceChangeActionReason_name_4 = "ResourceInstanceDeleteBecauseNoMoveTarget"
-	_ResourceInstanceChangeActionReason_name_5 = "ResourceInstanceDeleteBecauseCountIndexResourceInstanceReplaceByTriggersResourceInstanceDeleteBecauseEachKeyResourceInstanceReplaceBecauseCannotUpdate"
+	_ResourceInstanceChangeActionReason_name_4 = "ResourceInstanceDeleteBecauseNoMoveTargetResourceInstanceDeleteBecauseRemovedBlock"
+	_ResourceInstanceChangeActionReason_name_5 = "ResourceInstanceDeleteBecauseCountIndexResourceInstanceReplaceByTriggersResourceInstanceDeleteBecauseEachKeyResourceInstanceReplaceBecauseCannotUpdateResourceInstanceCreateBecauseDeleted"
 	_ResourceInstanceChangeActionReason_name_6 = "ResourceInstanceDeleteBecauseNoModuleResourceInstanceDeleteBecauseNoResourceConfig"
 	_ResourceInstanceChangeActionReason_name_7 = "ResourceInstanceReplaceByRequest"
 	_ResourceInstanceChangeActionReason_name_8 = "ResourceInstanceReplaceBecauseTainted"
@@ -38,7 +40,8 @@ const (
 )
 
 var (
-	_ResourceInstanceChangeActionReason_index_5 = [...]uint8{0, 39, 72, 108, 150}
+	_ResourceInstanceChangeActionReason_index_4 = [...]uint8{0, 41, 82}
+	_ResourceInstanceChangeActionReason_index_5 = [...]uint8{0, 39, 72, 108, 150, 186}
 	_ResourceInstanceChangeActionReason_index_6 = [...]uint8{0, 37, 82}
 )
 
@@ -52,9 +55,10 @@ func (i ResourceInstanceChangeActionReason) String() string {
 		return _ResourceInstanceChangeActionReason_name_2
 	case i == 63:
 		return _ResourceInstanceChangeActionReason_name_3
-	case i == 65:
-		return _ResourceInstanceChangeActionReason_name_4
-	case 67 <= i && i <= 70:
+	case 65 <= i && i <= 66:
+		i -= 65
+		return _ResourceInstanceChangeActionReason_name_4[_ResourceInstanceChangeActionReason_index_4[i]:_ResourceInstanceChangeActionReason_index_4[i+1]]
+	case 67 <= i && i <= 71:
 		i -= 67
 		return _ResourceInstanceChangeActionReason_name_5[_ResourceInstanceChangeActionReason_index_5[i]:_ResourceInstanceChangeActionReason_index_5[i+1]]
 	case 77 <= i && i <= 78:
@@ -93,8 +93,37 @@ func protoSchemaNestedBlock(name string, b *configschema.NestedBlock) *proto.Sch
 // ProtoToProviderSchema takes a proto.Schema and converts it to a providers.Schema.
 func ProtoToProviderSchema(s *proto.Schema) providers.Schema {
 	return providers.Schema{
-		Version: s.Version,
-		Block:   ProtoToConfigSchema(s.Block),
+		Version:         s.Version,
+		Block:           ProtoToConfigSchema(s.Block),
+		PermissionHints: ProtoToPermissionHints(s.PermissionHints),
+	}
+}
+
+// PermissionHintsToProto takes the permission hints of a providers.Schema and
+// converts them to a proto.Schema_PermissionHints for a grpc response.
+func PermissionHintsToProto(h *providers.PermissionHints) *proto.Schema_PermissionHints {
+	if h == nil {
+		return nil
+	}
+	return &proto.Schema_PermissionHints{
+		Create: h.Create,
+		Read:   h.Read,
+		Update: h.Update,
+		Delete: h.Delete,
+	}
+}
+
+// ProtoToPermissionHints takes the proto.Schema_PermissionHints from a grpc
+// response and converts them to the permission hints of a providers.Schema.
+func ProtoToPermissionHints(h *proto.Schema_PermissionHints) *providers.PermissionHints {
+	if h == nil {
+		return nil
+	}
+	return &providers.PermissionHints{
+		Create: h.Create,
+		Read:   h.Read,
+		Update: h.Update,
+		Delete: h.Delete,
 	}
 }
 
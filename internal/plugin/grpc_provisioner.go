@@ -65,7 +65,7 @@ func (p *GRPCProvisioner) GetSchema() (resp provisioners.GetSchemaResponse) {
 
 	protoResp, err := p.client.GetSchema(p.ctx, new(proto.GetProvisionerSchema_Request))
 	if err != nil {
-		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err))
+		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err, p.process()))
 		return resp
 	}
 	resp.Diagnostics = resp.Diagnostics.Append(convert.ProtoToDiagnostics(protoResp.Diagnostics))
@@ -100,7 +100,7 @@ func (p *GRPCProvisioner) ValidateProvisionerConfig(r provisioners.ValidateProvi
 	}
 	protoResp, err := p.client.ValidateProvisionerConfig(p.ctx, protoReq)
 	if err != nil {
-		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err))
+		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err, p.process()))
 		return resp
 	}
 	resp.Diagnostics = resp.Diagnostics.Append(convert.ProtoToDiagnostics(protoResp.Diagnostics))
@@ -134,7 +134,7 @@ func (p *GRPCProvisioner) ProvisionResource(r provisioners.ProvisionResourceRequ
 
 	outputClient, err := p.client.ProvisionResource(p.ctx, protoReq)
 	if err != nil {
-		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err))
+		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err, p.process()))
 		return resp
 	}
 
@@ -170,6 +170,15 @@ func (p *GRPCProvisioner) Stop() error {
 	return nil
 }
 
+// process returns the plugin process, or nil if there isn't one, such as
+// in tests.
+func (p *GRPCProvisioner) process() pluginProcess {
+	if p.PluginClient == nil {
+		return nil
+	}
+	return p.PluginClient
+}
+
 func (p *GRPCProvisioner) Close() error {
 	// check this since it's not automatically inserted during plugin creation
 	if p.PluginClient == nil {
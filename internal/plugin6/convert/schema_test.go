@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
 	proto "github.com/opentofu/opentofu/internal/tfplugin6"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -569,3 +570,26 @@ func TestConvertProtoSchemaBlocks(t *testing.T) {
 		})
 	}
 }
+
+func TestProtoToProviderSchema_permissionHints(t *testing.T) {
+	hints := &providers.PermissionHints{
+		Create: []string{"thing:Create"},
+		Read:   []string{"thing:Get", "thing:List"},
+		Delete: []string{"thing:Delete"},
+	}
+
+	got := ProtoToProviderSchema(&proto.Schema{
+		Block:           &proto.Schema_Block{},
+		PermissionHints: PermissionHintsToProto(hints),
+	})
+	if diff := cmp.Diff(hints, got.PermissionHints); diff != "" {
+		t.Fatalf("wrong permission hints\n%s", diff)
+	}
+
+	got = ProtoToProviderSchema(&proto.Schema{
+		Block: &proto.Schema_Block{},
+	})
+	if got.PermissionHints != nil {
+		t.Fatalf("unexpected permission hints %#v", got.PermissionHints)
+	}
+}
@@ -9,18 +9,48 @@ import (
 	"fmt"
 	"path"
 	"runtime"
+	"time"
 
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// pluginProcess is implemented by *plugin.Client, and reports whether the
+// plugin process has exited.
+type pluginProcess interface {
+	Exited() bool
+}
+
+// pluginExitGracePeriod is how long pluginExited waits for the plugin process
+// to exit after the connection to it was lost, because the connection is
+// usually closed slightly before the exit is noticed.
+var pluginExitGracePeriod = 2 * time.Second
+
+// pluginExited reports whether the given plugin process has exited, or exits
+// within pluginExitGracePeriod.
+func pluginExited(proc pluginProcess) bool {
+	deadline := time.Now().Add(pluginExitGracePeriod)
+	for {
+		if proc.Exited() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
 // grpcErr extracts some known error types and formats them into better
 // representations for core. This must only be called from plugin methods.
 // Since we don't use RPC status errors for the plugin protocol, these do not
 // contain any useful details, and we can return some text that at least
 // indicates the plugin call and possible error condition.
-func grpcErr(err error) (diags tfdiags.Diagnostics) {
+//
+// The given plugin process, if not nil, is used to tell whether a lost
+// connection was caused by the plugin crashing.
+func grpcErr(err error, proc pluginProcess) (diags tfdiags.Diagnostics) {
 	if err == nil {
 		return
 	}
@@ -48,14 +78,33 @@ func grpcErr(err error) (diags tfdiags.Diagnostics) {
 	// annotate the returned errors.
 	switch status.Code(err) {
 	case codes.Unavailable:
-		// This case is when the plugin has stopped running for some reason,
-		// and is usually the result of a crash.
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Plugin did not respond",
-			fmt.Sprintf("The plugin encountered an error, and failed to respond to the %s call. "+
-				"The plugin logs may contain more details.", requestName),
-		))
+		// This case is when the connection to the plugin was lost, which is
+		// usually the result of the plugin crashing, but can also be caused
+		// by a problem with the connection itself.
+		switch {
+		case proc == nil:
+			diags = diags.Append(tfdiags.RetryableSourceless(
+				tfdiags.Error,
+				"Plugin did not respond",
+				fmt.Sprintf("The plugin encountered an error, and failed to respond to the %s call. "+
+					"The plugin logs may contain more details.", requestName),
+			))
+		case pluginExited(proc):
+			diags = diags.Append(tfdiags.RetryableSourceless(
+				tfdiags.Error,
+				"Plugin crashed",
+				fmt.Sprintf("The plugin process exited unexpectedly during the %s call, which usually means that the plugin crashed. "+
+					"The plugin logs may contain more details.", requestName),
+			))
+		default:
+			diags = diags.Append(tfdiags.RetryableSourceless(
+				tfdiags.Error,
+				"Plugin connection lost",
+				fmt.Sprintf("OpenTofu lost its connection to the plugin during the %s call, although the plugin process is still running. "+
+					"This can happen when the connection passes through a container boundary or a proxy that closes idle connections. "+
+					"The TF_PLUGIN_KEEPALIVE environment variable controls how often OpenTofu checks the connection.", requestName),
+			))
+		}
 	case codes.Canceled:
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -0,0 +1,63 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin6
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+type testPluginProcess struct {
+	exited bool
+}
+
+func (p *testPluginProcess) Exited() bool {
+	return p.exited
+}
+
+func TestGRPCErr_unavailable(t *testing.T) {
+	defer func(prev time.Duration) { pluginExitGracePeriod = prev }(pluginExitGracePeriod)
+	pluginExitGracePeriod = 0
+
+	err := status.Error(codes.Unavailable, "connection reset")
+	tests := map[string]struct {
+		proc pluginProcess
+		want string
+	}{
+		"unknown process": {
+			proc: nil,
+			want: "Plugin did not respond",
+		},
+		"process exited": {
+			proc: &testPluginProcess{exited: true},
+			want: "Plugin crashed",
+		},
+		"process still running": {
+			proc: &testPluginProcess{exited: false},
+			want: "Plugin connection lost",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := grpcErr(err, test.proc)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+			}
+			if got := diags[0].Description().Summary; got != test.want {
+				t.Errorf("wrong summary\ngot:  %s\nwant: %s", got, test.want)
+			}
+			if !tfdiags.DiagnosticRetryable(diags[0]) {
+				t.Errorf("diagnostic is not retryable")
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/msgpack"
 	"go.uber.org/mock/gomock"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -927,3 +928,87 @@ func TestGRPCProvider_CallFunction(t *testing.T) {
 		t.Fatalf("%v", resp.Result)
 	}
 }
+
+func TestGRPCProvider_CheckResourceQuotas(t *testing.T) {
+	// Quota checks don't need the schema.
+	client := mockproto.NewMockProviderClient(gomock.NewController(t))
+	p := &GRPCProvider{
+		client: client,
+	}
+
+	client.EXPECT().CheckResourceQuotas(
+		gomock.Any(),
+		gomock.Cond(func(x any) bool {
+			req := x.(*proto.CheckResourceQuotas_Request)
+			return len(req.Creates) == 1 && req.Creates["resource"] == 3
+		}),
+	).Return(&proto.CheckResourceQuotas_Response{
+		Risks: []*proto.CheckResourceQuotas_QuotaRisk{
+			{
+				TypeName:  "resource",
+				Requested: 3,
+				Available: 1,
+				Detail:    "resources per region (limit 5)",
+			},
+		},
+	}, nil)
+
+	resp := p.CheckResourceQuotas(providers.CheckResourceQuotasRequest{
+		Creates: map[string]int{"resource": 3},
+	})
+	checkDiags(t, resp.Diagnostics)
+
+	want := []providers.QuotaRisk{
+		{
+			TypeName:  "resource",
+			Requested: 3,
+			Available: 1,
+			Detail:    "resources per region (limit 5)",
+		},
+	}
+	if diff := cmp.Diff(want, resp.Risks); diff != "" {
+		t.Fatalf("wrong risks\n%s", diff)
+	}
+}
+
+func TestGRPCProvider_ListResources(t *testing.T) {
+	// Listing resources doesn't need the schema.
+	client := mockproto.NewMockProviderClient(gomock.NewController(t))
+	p := &GRPCProvider{
+		client: client,
+	}
+
+	filter := cty.ObjectVal(map[string]cty.Value{
+		"tag": cty.StringVal("imported"),
+	})
+	client.EXPECT().ListResources(
+		gomock.Any(),
+		gomock.Cond(func(x any) bool {
+			req := x.(*proto.ListResources_Request)
+			if req.TypeName != "resource" {
+				return false
+			}
+			got, err := msgpack.Unmarshal(req.Filter.Msgpack, cty.DynamicPseudoType)
+			return err == nil && got.RawEquals(filter)
+		}),
+	).Return(&proto.ListResources_Response{
+		Resources: []*proto.ListResources_Resource{
+			{Id: "abc123", Name: "first"},
+			{Id: "def456"},
+		},
+	}, nil)
+
+	resp := p.ListResources(providers.ListResourcesRequest{
+		TypeName: "resource",
+		Filter:   filter,
+	})
+	checkDiags(t, resp.Diagnostics)
+
+	want := []providers.ListedResource{
+		{ID: "abc123", Name: "first"},
+		{ID: "def456"},
+	}
+	if diff := cmp.Diff(want, resp.Resources); diff != "" {
+		t.Fatalf("wrong resources\n%s", diff)
+	}
+}
@@ -81,6 +81,26 @@ func (mr *MockProviderClientMockRecorder) CallFunction(arg0, arg1 any, arg2 ...a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallFunction", reflect.TypeOf((*MockProviderClient)(nil).CallFunction), varargs...)
 }
 
+// CheckResourceQuotas mocks base method.
+func (m *MockProviderClient) CheckResourceQuotas(arg0 context.Context, arg1 *tfplugin6.CheckResourceQuotas_Request, arg2 ...grpc.CallOption) (*tfplugin6.CheckResourceQuotas_Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CheckResourceQuotas", varargs...)
+	ret0, _ := ret[0].(*tfplugin6.CheckResourceQuotas_Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckResourceQuotas indicates an expected call of CheckResourceQuotas.
+func (mr *MockProviderClientMockRecorder) CheckResourceQuotas(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckResourceQuotas", reflect.TypeOf((*MockProviderClient)(nil).CheckResourceQuotas), varargs...)
+}
+
 // CloseEphemeralResource mocks base method.
 func (m *MockProviderClient) CloseEphemeralResource(arg0 context.Context, arg1 *tfplugin6.CloseEphemeralResource_Request, arg2 ...grpc.CallOption) (*tfplugin6.CloseEphemeralResource_Response, error) {
 	m.ctrl.T.Helper()
@@ -201,6 +221,26 @@ func (mr *MockProviderClientMockRecorder) ImportResourceState(arg0, arg1 any, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportResourceState", reflect.TypeOf((*MockProviderClient)(nil).ImportResourceState), varargs...)
 }
 
+// ListResources mocks base method.
+func (m *MockProviderClient) ListResources(arg0 context.Context, arg1 *tfplugin6.ListResources_Request, arg2 ...grpc.CallOption) (*tfplugin6.ListResources_Response, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListResources", varargs...)
+	ret0, _ := ret[0].(*tfplugin6.ListResources_Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListResources indicates an expected call of ListResources.
+func (mr *MockProviderClientMockRecorder) ListResources(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResources", reflect.TypeOf((*MockProviderClient)(nil).ListResources), varargs...)
+}
+
 // MoveResourceState mocks base method.
 func (m *MockProviderClient) MoveResourceState(arg0 context.Context, arg1 *tfplugin6.MoveResourceState_Request, arg2 ...grpc.CallOption) (*tfplugin6.MoveResourceState_Response, error) {
 	m.ctrl.T.Helper()
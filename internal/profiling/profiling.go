@@ -0,0 +1,227 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package profiling implements the optional profiles of OpenTofu's own
+// execution that users can request with the TOFU_CPU_PROFILE and
+// TOFU_PROFILE_DIR environment variables, for reporting performance issues.
+//
+// TOFU_CPU_PROFILE only writes a CPU profile. TOFU_PROFILE_DIR, which the
+// "tofu debug profile" command sets for the command that it runs, writes a
+// CPU profile, a heap profile, a goroutine profile, an execution trace and
+// the timings of the graph nodes into a directory.
+package profiling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CPUProfileEnvVar is the name of the environment variable that sets the
+// path of a CPU profile to write.
+const CPUProfileEnvVar = "TOFU_CPU_PROFILE"
+
+// DirEnvVar is the name of the environment variable that sets the directory
+// to write all of the profiles into.
+const DirEnvVar = "TOFU_PROFILE_DIR"
+
+// The names of the files that Start writes into the profile directory.
+const (
+	CPUProfileFile       = "cpu.pprof"
+	HeapProfileFile      = "heap.pprof"
+	GoroutineProfileFile = "goroutine.pprof"
+	TraceFile            = "trace.out"
+	GraphNodesFile       = "graph-nodes.json"
+)
+
+// GraphNode is the timing of one execution of a graph node.
+type GraphNode struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Walk string `json:"walk"`
+
+	// StartMS is the time at which the node became ready to execute, in
+	// milliseconds since the profiles were started.
+	StartMS float64 `json:"start_ms"`
+
+	// WaitMS is the time that the node waited for one of the concurrent
+	// operations allowed by -parallelism, in milliseconds.
+	WaitMS float64 `json:"wait_ms"`
+
+	// DurationMS is the time that the node took to execute, in milliseconds.
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type graphNodeRecorder struct {
+	start time.Time
+
+	mu    sync.Mutex
+	nodes []GraphNode
+}
+
+var recorder atomic.Pointer[graphNodeRecorder]
+
+// Start starts the profiles that the given arguments ask for: a CPU profile
+// written to cpuProfile, unless it's empty, and all of the profiles written
+// into dir, unless it's empty. A CPU profile in dir takes precedence over
+// cpuProfile, because only one CPU profile can be written at a time.
+//
+// The returned function stops the profiles and writes the ones that are only
+// taken at the end, so it must be called before OpenTofu exits.
+func Start(cpuProfile, dir string) (stop func() error, err error) {
+	if dir != "" {
+		// The profiles taken at the end are written after OpenTofu may have
+		// changed its working directory for the -chdir option.
+		dir, err = filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile directory: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create the profile directory: %w", err)
+		}
+		cpuProfile = filepath.Join(dir, CPUProfileFile)
+	}
+
+	var stops []func() error
+	stopAll := func() error {
+		var errs []error
+		// The profiles are stopped in the reverse order in which they were
+		// started, like deferred calls.
+		for i := len(stops) - 1; i >= 0; i-- {
+			errs = append(errs, stops[i]())
+		}
+		return errors.Join(errs...)
+	}
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start the CPU profile: %w", err)
+		}
+		log.Printf("[INFO] Writing a CPU profile to %s", cpuProfile)
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if dir == "" {
+		return stopAll, nil
+	}
+
+	f, err := os.Create(filepath.Join(dir, TraceFile))
+	if err != nil {
+		_ = stopAll()
+		return nil, fmt.Errorf("failed to create the execution trace: %w", err)
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		_ = stopAll()
+		return nil, fmt.Errorf("failed to start the execution trace: %w", err)
+	}
+	stops = append(stops, func() error {
+		trace.Stop()
+		return f.Close()
+	})
+
+	rec := &graphNodeRecorder{start: time.Now()}
+	recorder.Store(rec)
+	log.Printf("[INFO] Writing profiles into %s", dir)
+
+	stops = append(stops, func() error {
+		recorder.Store(nil)
+		return errors.Join(
+			writeGraphNodes(filepath.Join(dir, GraphNodesFile), rec),
+			writeProfile(filepath.Join(dir, HeapProfileFile), "heap"),
+			writeProfile(filepath.Join(dir, GoroutineProfileFile), "goroutine"),
+		)
+	})
+	return stopAll, nil
+}
+
+// RecordingGraphNodes returns true if the timings of the graph nodes are
+// being recorded, so that callers can avoid measuring them otherwise.
+func RecordingGraphNodes() bool {
+	return recorder.Load() != nil
+}
+
+// RecordGraphNode records the timing of one execution of a graph node that
+// became ready to execute at queued, started executing at started and
+// finished executing at finished. It does nothing if the timings of the
+// graph nodes aren't being recorded.
+func RecordGraphNode(name, typeName, walk string, queued, started, finished time.Time) {
+	rec := recorder.Load()
+	if rec == nil {
+		return
+	}
+	node := GraphNode{
+		Name:       name,
+		Type:       typeName,
+		Walk:       walk,
+		StartMS:    milliseconds(queued.Sub(rec.start)),
+		WaitMS:     milliseconds(started.Sub(queued)),
+		DurationMS: milliseconds(finished.Sub(started)),
+	}
+
+	rec.mu.Lock()
+	rec.nodes = append(rec.nodes, node)
+	rec.mu.Unlock()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func writeGraphNodes(path string, rec *graphNodeRecorder) error {
+	rec.mu.Lock()
+	nodes := rec.nodes
+	rec.mu.Unlock()
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].StartMS < nodes[j].StartMS
+	})
+	if nodes == nil {
+		nodes = []GraphNode{}
+	}
+	src, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the graph node timings: %w", err)
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		return fmt.Errorf("failed to write the graph node timings: %w", err)
+	}
+	return nil
+}
+
+func writeProfile(path, name string) error {
+	if name == "heap" {
+		// The heap profile describes the state as of the last garbage
+		// collection, so we run one to include the most recent allocations.
+		runtime.GC()
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create the %s profile: %w", name, err)
+	}
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write the %s profile: %w", name, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,85 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package profiling
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStart_dir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+
+	stop, err := Start("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !RecordingGraphNodes() {
+		t.Fatal("graph node timings are not being recorded")
+	}
+	queued := time.Now()
+	RecordGraphNode("test_thing.b", "*tofu.NodeTest", "walkPlan", queued.Add(time.Millisecond), queued.Add(3*time.Millisecond), queued.Add(10*time.Millisecond))
+	RecordGraphNode("test_thing.a", "*tofu.NodeTest", "walkPlan", queued, queued, queued.Add(2*time.Millisecond))
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if RecordingGraphNodes() {
+		t.Fatal("graph node timings are still being recorded")
+	}
+
+	for _, name := range []string{CPUProfileFile, HeapProfileFile, GoroutineProfileFile, TraceFile, GraphNodesFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", name)
+		}
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, GraphNodesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes []GraphNode
+	if err := json.Unmarshal(src, &nodes); err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 graph nodes, got %d", len(nodes))
+	}
+	if got, want := nodes[0].Name, "test_thing.a"; got != want {
+		t.Errorf("wrong first node %q; want %q", got, want)
+	}
+	if got, want := nodes[1].WaitMS, 2.0; got != want {
+		t.Errorf("wrong wait time %v; want %v", got, want)
+	}
+	if got, want := nodes[1].DurationMS, 7.0; got != want {
+		t.Errorf("wrong duration %v; want %v", got, want)
+	}
+}
+
+func TestStart_cpuProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpu.pprof")
+
+	stop, err := Start(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if RecordingGraphNodes() {
+		t.Fatal("graph node timings are recorded without a profile directory")
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+}
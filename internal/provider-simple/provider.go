@@ -146,6 +146,30 @@ func (s simple) CallFunction(r providers.CallFunctionRequest) providers.CallFunc
 	panic("Not Implemented")
 }
 
+func (s simple) ValidateEphemeralResourceConfig(providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse {
+	panic("Not Implemented")
+}
+
+func (s simple) OpenEphemeralResource(providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (s simple) RenewEphemeralResource(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (s simple) CloseEphemeralResource(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	panic("Not Implemented")
+}
+
+func (s simple) CheckResourceQuotas(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+	panic("Not Implemented")
+}
+
+func (s simple) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	panic("Not Implemented")
+}
+
 func (s simple) Close() error {
 	return nil
 }
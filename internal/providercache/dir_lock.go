@@ -0,0 +1,70 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+// lockDirName is the name of the directory, inside a cache directory, that
+// holds the files used to lock its entries. It can never be mistaken for a
+// provider package because it isn't a valid registry hostname.
+const lockDirName = ".locks"
+
+// lockProviderVersion acquires an exclusive lock on installing the given
+// provider version into the directory, which is shared between all OpenTofu
+// processes using the same directory, such as when several runs on one
+// machine share a global plugin cache directory. It blocks until any other
+// process holding the lock releases it.
+//
+// The lock is held on behalf of the whole process, so it doesn't coordinate
+// concurrent installations within a single process.
+//
+// Another process might have installed the package while we were waiting, so
+// acquiring the lock also discards the cached directory listing.
+//
+// The caller must call the returned function to release the lock once it's
+// finished modifying the directory.
+func (d *Dir) lockProviderVersion(provider addrs.Provider, version getproviders.Version) (unlock func(), err error) {
+	dir := filepath.Join(d.baseDir, lockDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory for %s: %w", d.baseDir, err)
+	}
+
+	// Hostnames can include a port number, but colons aren't allowed in
+	// filenames on all platforms.
+	name := strings.ReplaceAll(
+		fmt.Sprintf("%s_%s_%s_%s_%s.lock", provider.Hostname, provider.Namespace, provider.Type, version, d.targetPlatform),
+		":", "_",
+	)
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file for %s %s: %w", provider, version, err)
+	}
+
+	log.Printf("[TRACE] providercache.Dir.lockProviderVersion: locking %s", path)
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s %s in %s: %w", provider, version, d.baseDir, err)
+	}
+	d.metaCache = nil
+
+	return func() {
+		log.Printf("[TRACE] providercache.Dir.lockProviderVersion: unlocking %s", path)
+		if err := unlockFile(f); err != nil {
+			log.Printf("[WARN] failed to unlock %s: %s", path, err)
+		}
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestDirLockProviderVersion(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := NewDirWithPlatform(baseDir, getproviders.Platform{OS: "linux", Arch: "amd64"})
+	provider := addrs.NewProvider(addrs.DefaultProviderRegistryHost, "hashicorp", "null")
+	version := versions.MustParseVersion("2.0.0")
+
+	// Populate the directory listing, so we can check that locking
+	// discards it.
+	if got := dir.AllAvailablePackages(); len(got) != 0 {
+		t.Fatalf("unexpected packages in new directory: %#v", got)
+	}
+	if dir.metaCache == nil {
+		t.Fatal("directory listing was not cached")
+	}
+
+	unlock, err := dir.lockProviderVersion(provider, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir.metaCache != nil {
+		t.Error("locking did not discard the cached directory listing")
+	}
+	unlock()
+
+	wantPath := filepath.Join(baseDir, lockDirName, "registry.opentofu.org_hashicorp_null_2.0.0_linux_amd64.lock")
+	if _, err := os.Stat(wantPath); err != nil {
+		t.Errorf("lock file not created: %s", err)
+	}
+
+	// The lock can be acquired again once released.
+	unlock, err = dir.lockProviderVersion(provider, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	// Neither the lock files nor the schema cache are packages.
+	if err := os.MkdirAll(filepath.Join(baseDir, schemaCacheDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, schemaCacheDirName, "entry.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir.metaCache = nil
+	if got := dir.AllAvailablePackages(); len(got) != 0 {
+		t.Errorf("lock files or schema cache entries reported as packages: %#v", got)
+	}
+}
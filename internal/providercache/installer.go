@@ -60,6 +60,11 @@ type Installer struct {
 	// lifecycle for, and therefore does not need to worry about the
 	// installation of.
 	unmanagedProviderTypes map[addrs.Provider]struct{}
+
+	// platformFallbacks are rules for installing packages built for other
+	// platforms, to run under emulation, when a provider has no package
+	// for the target platform.
+	platformFallbacks getproviders.PlatformFallbacks
 }
 
 // NewInstaller constructs and returns a new installer with the given target
@@ -163,6 +168,17 @@ func (i *Installer) SetUnmanagedProviderTypes(types map[addrs.Provider]struct{})
 	i.unmanagedProviderTypes = types
 }
 
+// SetPlatformFallbacks tells the receiver which other platforms it may
+// install packages for when a provider doesn't have a package available for
+// the target platform, because the system is able to run executables for
+// those platforms under emulation.
+//
+// The default, if this method isn't called, is to install only packages for
+// the target platform.
+func (i *Installer) SetPlatformFallbacks(fallbacks getproviders.PlatformFallbacks) {
+	i.platformFallbacks = fallbacks
+}
+
 // EnsureProviderVersions compares the given provider requirements with what
 // is already available in the installer's target directory and then takes
 // appropriate installation actions to ensure that suitable packages
@@ -438,7 +454,21 @@ func (i *Installer) ensureProviderVersionsInstall(
 	evts := installerEventsForContext(ctx)
 	authResults := map[addrs.Provider]*getproviders.PackageAuthenticationResult{} // record auth results for all successfully fetched providers
 
+	// While installing each provider via the global cache directory we hold
+	// its lock for that provider version, which we release before moving on
+	// to the next provider.
+	var unlockGlobalCache func()
+	defer func() {
+		if unlockGlobalCache != nil {
+			unlockGlobalCache()
+		}
+	}()
+
 	for provider, version := range need {
+		if unlockGlobalCache != nil {
+			unlockGlobalCache()
+			unlockGlobalCache = nil
+		}
 		if err := ctx.Err(); err != nil {
 			// If our context has been cancelled or reached a timeout then
 			// we'll abort early, because subsequent operations against
@@ -465,6 +495,17 @@ func (i *Installer) ensureProviderVersionsInstall(
 		}
 
 		if i.globalCacheDir != nil {
+			// Other OpenTofu processes sharing the global cache directory
+			// might be installing this same provider version concurrently,
+			// so we wait for them to finish and can then use their result
+			// rather than installing it again.
+			unlock, err := i.globalCacheDir.lockProviderVersion(provider, version)
+			if err != nil {
+				errs[provider] = err
+				continue
+			}
+			unlockGlobalCache = unlock
+
 			// If our global cache already has this version available then
 			// we'll just link it in.
 			installed, err := tryInstallPackageFromCacheDir(
@@ -482,6 +523,7 @@ func (i *Installer) ensureProviderVersionsInstall(
 				continue
 			}
 			if installed {
+				i.recordLinkedProvenance(provider, version)
 				continue // nothing left to do for this provider, then
 			}
 		}
@@ -495,6 +537,28 @@ func (i *Installer) ensureProviderVersionsInstall(
 			cb(provider, version)
 		}
 		meta, err := i.source.PackageMeta(ctx, provider, version, targetPlatform)
+		fallbackPlatforms := i.platformFallbacks.ForProvider(provider)
+		if _, ok := err.(getproviders.ErrPlatformNotSupported); ok {
+			// If the operator told us that this system can run executables
+			// for some other platforms under emulation then we'll try those
+			// in turn, but we'll still report the original error if none
+			// of them have a package either.
+			for _, fallback := range fallbackPlatforms {
+				fallbackMeta, fallbackErr := i.source.PackageMeta(ctx, provider, version, fallback)
+				if fallbackErr != nil {
+					continue
+				}
+				if cb := evts.FetchPackageFallbackPlatform; cb != nil {
+					cb(provider, version, fallback)
+				}
+				// The package will be installed as if it were for the
+				// target platform, because that's the platform whose
+				// cache directory OpenTofu will look in to run it.
+				fallbackMeta.TargetPlatform = targetPlatform
+				meta, err = fallbackMeta, nil
+				break
+			}
+		}
 		if err != nil {
 			errs[provider] = err
 			if cb := evts.FetchPackageFailure; cb != nil {
@@ -542,7 +606,8 @@ func (i *Installer) ensureProviderVersionsInstall(
 			}
 			continue
 		}
-		if _, err := new.ExecutableFile(); err != nil {
+		exeFile, err := new.ExecutableFile()
+		if err != nil {
 			err := fmt.Errorf("provider binary not found: %w", err)
 			errs[provider] = err
 			if cb := evts.FetchPackageFailure; cb != nil {
@@ -550,6 +615,17 @@ func (i *Installer) ensureProviderVersionsInstall(
 			}
 			continue
 		}
+		// We check that the executable can actually run here, so that we can
+		// report a package built for the wrong platform now rather than
+		// with a confusing error the first time OpenTofu tries to run it.
+		allowedPlatforms := append([]getproviders.Platform{targetPlatform}, fallbackPlatforms...)
+		if err := getproviders.CheckExecutablePlatform(exeFile, allowedPlatforms...); err != nil {
+			errs[provider] = err
+			if cb := evts.FetchPackageFailure; cb != nil {
+				cb(provider, version, err)
+			}
+			continue
+		}
 		if linkTo != nil {
 			// We skip emitting the "LinkFromCache..." events here because
 			// it's simpler for the caller to treat them as mutually exclusive.
@@ -654,6 +730,17 @@ func (i *Installer) ensureProviderVersionsInstall(
 			cb(provider, version, []getproviders.Hash{newHash}, signedHashes, priorHashes)
 		}
 
+		if meta.Source == "" {
+			meta.Source = i.source.ForDisplay(provider)
+		}
+		provenance := newProvenance(meta, authResult, append([]getproviders.Hash{newHash}, signedHashes...))
+		if err := installTo.recordProvenance(provider, version, provenance); err != nil {
+			log.Printf("[WARN] Failed to record the provenance of %s v%s: %s", provider, version, err)
+		}
+		if linkTo != nil {
+			i.recordLinkedProvenance(provider, version)
+		}
+
 		if cb := evts.FetchPackageSuccess; cb != nil {
 			cb(provider, version, new.PackageDir, authResult)
 		}
@@ -662,6 +749,30 @@ func (i *Installer) ensureProviderVersionsInstall(
 	return authResults, nil
 }
 
+// recordLinkedProvenance records the provenance of a package that was linked
+// into the target directory from the global cache directory, based on the
+// provenance recorded for it in the global cache directory, if any.
+func (i *Installer) recordLinkedProvenance(provider addrs.Provider, version getproviders.Version) {
+	provenance, err := i.globalCacheDir.ProviderProvenance(provider, version)
+	if err != nil {
+		log.Printf("[WARN] Failed to read the provenance of %s v%s from the global cache directory: %s", provider, version, err)
+	}
+	if provenance == nil {
+		// The package was added to the cache directory before OpenTofu
+		// started recording provenance, so all we know is where it was
+		// linked from.
+		provenance = &Provenance{
+			Provider: provider.String(),
+			Version:  version.String(),
+			Platform: i.targetDir.targetPlatform.String(),
+		}
+	}
+	provenance.CacheDir = i.globalCacheDir.BasePath()
+	if err := i.targetDir.recordProvenance(provider, version, provenance); err != nil {
+		log.Printf("[WARN] Failed to record the provenance of %s v%s: %s", provider, version, err)
+	}
+}
+
 // tryInstallPackageFromCacheDir attempts to satisfy a provider selection from
 // the upstream cache sourceDir.
 //
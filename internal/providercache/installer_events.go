@@ -111,6 +111,12 @@ type InstallerEvents struct {
 	FetchPackageSuccess func(provider addrs.Provider, version getproviders.Version, localDir string, authResult *getproviders.PackageAuthenticationResult)
 	FetchPackageFailure func(provider addrs.Provider, version getproviders.Version, err error)
 
+	// FetchPackageFallbackPlatform is called between FetchPackageMeta and
+	// FetchPackageBegin if there is no package for the current platform
+	// and so the installer will instead install a package for the given
+	// fallback platform, which the system must run under emulation.
+	FetchPackageFallbackPlatform func(provider addrs.Provider, version getproviders.Version, platform getproviders.Platform)
+
 	// The ProvidersLockUpdated event is called whenever the lock file will be
 	// updated. It provides the following information:
 	//
@@ -2375,6 +2375,85 @@ func TestEnsureProviderVersions_local_source(t *testing.T) {
 	}
 }
 
+func TestEnsureProviderVersions_platformFallback(t *testing.T) {
+	provider := addrs.MustParseProviderSourceString("example.com/foo/beep")
+	version := getproviders.MustParseVersion("1.0.0")
+	platform := getproviders.Platform{OS: "gameboy", Arch: "lr35902"}
+	otherPlatform := getproviders.Platform{OS: "gameboy", Arch: "z80"}
+
+	meta, close, err := getproviders.FakeInstallablePackageMeta(provider, version, nil, otherPlatform, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	source := getproviders.NewMockSource([]getproviders.PackageMeta{meta}, nil)
+	reqs := getproviders.Requirements{
+		provider: getproviders.MustParseVersionConstraints("1.0.0"),
+	}
+
+	t.Run("no fallback", func(t *testing.T) {
+		dir := NewDirWithPlatform(t.TempDir(), platform)
+		installer := NewInstaller(dir, source)
+
+		_, err := installer.EnsureProviderVersions(context.Background(), depsfile.NewLocks(), reqs, InstallNewProvidersOnly)
+		instErr, ok := err.(InstallerError)
+		if !ok {
+			t.Fatalf("wrong error type. Expected InstallerError, got %T", err)
+		}
+		if _, ok := instErr.ProviderErrors[provider].(getproviders.ErrPlatformNotSupported); !ok {
+			t.Fatalf("wrong error type. Expected ErrPlatformNotSupported, got %T", instErr.ProviderErrors[provider])
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		dir := NewDirWithPlatform(t.TempDir(), platform)
+		installer := NewInstaller(dir, source)
+		installer.SetPlatformFallbacks(getproviders.PlatformFallbacks{
+			{
+				Platforms: []getproviders.Platform{otherPlatform},
+			},
+		})
+
+		var gotFallback getproviders.Platform
+		ctx := (&InstallerEvents{
+			FetchPackageFallbackPlatform: func(_ addrs.Provider, _ getproviders.Version, platform getproviders.Platform) {
+				gotFallback = platform
+			},
+		}).OnContext(context.Background())
+
+		_, err := installer.EnsureProviderVersions(ctx, depsfile.NewLocks(), reqs, InstallNewProvidersOnly)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if gotFallback != otherPlatform {
+			t.Errorf("wrong fallback platform reported\ngot:  %s\nwant: %s", gotFallback, otherPlatform)
+		}
+		if dir.ProviderVersion(provider, version) == nil {
+			t.Errorf("provider was not installed for %s", platform)
+		}
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		dir := NewDirWithPlatform(t.TempDir(), platform)
+		installer := NewInstaller(dir, source)
+		exclude, err := getproviders.ParseMultiSourceMatchingPatterns([]string{"example.com/foo/*"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		installer.SetPlatformFallbacks(getproviders.PlatformFallbacks{
+			{
+				Platforms: []getproviders.Platform{otherPlatform},
+				Exclude:   exclude,
+			},
+		})
+
+		_, err = installer.EnsureProviderVersions(context.Background(), depsfile.NewLocks(), reqs, InstallNewProvidersOnly)
+		if err == nil {
+			t.Fatal("unexpected success; want error")
+		}
+	})
+}
+
 // This test only verifies protocol errors and does not try for successful
 // installation (at the time of writing, the test files aren't signed so the
 // signature verification fails); that's left to the e2e tests.
@@ -2700,3 +2779,81 @@ func tmpDir(t *testing.T) string {
 	}
 	return filepath.Clean(unlinked)
 }
+
+func TestEnsureProviderVersions_provenance(t *testing.T) {
+	provider := addrs.MustParseProviderSourceString("example.com/foo/beep")
+	version := getproviders.MustParseVersion("1.0.0")
+	platform := getproviders.Platform{OS: "gameboy", Arch: "lr35902"}
+
+	meta, close, err := getproviders.FakeInstallablePackageMeta(provider, version, nil, platform, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	source := getproviders.MultiSource{
+		{Source: getproviders.NewMockSource([]getproviders.PackageMeta{meta}, nil)},
+	}
+	reqs := getproviders.Requirements{
+		provider: getproviders.MustParseVersionConstraints("1.0.0"),
+	}
+
+	checkProvenance := func(t *testing.T, dir *Dir, wantCacheDir string) {
+		t.Helper()
+		got, err := dir.ProviderProvenance(provider, version)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got == nil {
+			t.Fatalf("no provenance recorded in %s", dir.BasePath())
+		}
+		installed := dir.ProviderVersion(provider, version)
+		hash, err := installed.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &Provenance{
+			Provider:       provider.String(),
+			Version:        version.String(),
+			Platform:       platform.String(),
+			Source:         "mock source",
+			Location:       meta.Location.String(),
+			Authentication: "verified checksum",
+			Hashes:         []string{string(hash)},
+			CacheDir:       wantCacheDir,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong provenance\n%s", diff)
+		}
+	}
+
+	t.Run("direct", func(t *testing.T) {
+		dir := NewDirWithPlatform(t.TempDir(), platform)
+		installer := NewInstaller(dir, source)
+
+		_, err := installer.EnsureProviderVersions(context.Background(), depsfile.NewLocks(), reqs, InstallNewProvidersOnly)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		checkProvenance(t, dir, "")
+	})
+
+	t.Run("global cache", func(t *testing.T) {
+		cacheDir := NewDirWithPlatform(t.TempDir(), platform)
+		for _, name := range []string{"first", "second"} {
+			t.Run(name, func(t *testing.T) {
+				// The first installation fetches the package into the
+				// cache directory, and the second links it from there.
+				dir := NewDirWithPlatform(t.TempDir(), platform)
+				installer := NewInstaller(dir, source)
+				installer.SetGlobalCacheDir(cacheDir)
+
+				_, err := installer.EnsureProviderVersions(context.Background(), depsfile.NewLocks(), reqs, InstallNewProvidersOnly)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				checkProvenance(t, cacheDir, "")
+				checkProvenance(t, dir, cacheDir.BasePath())
+			})
+		}
+	})
+}
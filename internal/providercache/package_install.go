@@ -77,6 +77,7 @@ func installFromHTTPURL(ctx context.Context, meta getproviders.PackageMeta, targ
 	retryableClient := retryablehttp.NewClient()
 	retryableClient.HTTPClient = httpclient.New()
 	retryableClient.RetryMax = maxRetryCount
+	retryableClient.CheckRetry = httpclient.CheckRetry
 	retryableClient.RequestLogHook = requestLogHook
 	retryableClient.Logger = log.New(logging.LogOutput(), "", log.Flags())
 
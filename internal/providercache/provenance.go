@@ -0,0 +1,117 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/replacefile"
+)
+
+// provenanceDirName is the name of the directory, inside a cache directory,
+// that holds the provenance records of the packages installed there.
+const provenanceDirName = ".provenance"
+
+// Provenance records where an installed provider package came from and how
+// OpenTofu verified it during installation, so that it can be audited later.
+type Provenance struct {
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+	Platform string `json:"platform"`
+
+	// Source describes the installation source that provided the package,
+	// such as the hostname of a provider registry or the location of a
+	// provider mirror.
+	Source string `json:"source"`
+
+	// Location is where the package was retrieved from, such as the URL of
+	// the distribution archive.
+	Location string `json:"location"`
+
+	// Authentication describes how the package was verified, using the
+	// same terms as getproviders.PackageAuthenticationResult. It's empty if
+	// the package was not verified when it was installed.
+	Authentication string `json:"authentication,omitempty"`
+
+	// SigningKeyID is the ID of the key that signed the package's checksums,
+	// if the package was verified by signature.
+	SigningKeyID string `json:"signing_key_id,omitempty"`
+
+	// Hashes are the checksums of the package that OpenTofu computed or
+	// verified during installation.
+	Hashes []string `json:"hashes,omitempty"`
+
+	// CacheDir is the global plugin cache directory the package was linked
+	// from, if any.
+	CacheDir string `json:"cache_dir,omitempty"`
+}
+
+// newProvenance returns the provenance of a package that was just installed
+// using the given metadata, with the given authentication result.
+func newProvenance(meta getproviders.PackageMeta, authResult *getproviders.PackageAuthenticationResult, hashes []getproviders.Hash) *Provenance {
+	ret := &Provenance{
+		Provider: meta.Provider.String(),
+		Version:  meta.Version.String(),
+		Platform: meta.TargetPlatform.String(),
+		Source:   meta.Source,
+		Location: meta.Location.String(),
+	}
+	if authResult != nil {
+		ret.Authentication = authResult.String()
+		ret.SigningKeyID = authResult.KeyID
+	}
+	for _, hash := range hashes {
+		ret.Hashes = append(ret.Hashes, string(hash))
+	}
+	return ret
+}
+
+// ProviderProvenance returns the recorded provenance of the package for the
+// given provider version in the receiving cache directory, or nil if there
+// is no such record, such as for packages installed by older versions of
+// OpenTofu or placed in the directory manually.
+func (d *Dir) ProviderProvenance(provider addrs.Provider, version getproviders.Version) (*Provenance, error) {
+	src, err := os.ReadFile(d.provenancePath(provider, version))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ret Provenance
+	if err := json.Unmarshal(src, &ret); err != nil {
+		return nil, fmt.Errorf("invalid provenance record for %s v%s: %w", provider, version, err)
+	}
+	return &ret, nil
+}
+
+// recordProvenance writes the given provenance record for the package it
+// describes, replacing any existing record.
+func (d *Dir) recordProvenance(provider addrs.Provider, version getproviders.Version, p *Provenance) error {
+	src, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := d.provenancePath(provider, version)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return replacefile.AtomicWriteFile(path, src, 0644)
+}
+
+func (d *Dir) provenancePath(provider addrs.Provider, version getproviders.Version) string {
+	// The records are kept flat in a single directory, so that the
+	// provider package search never mistakes them for packages.
+	name := fmt.Sprintf("%s_%s_%s.json", strings.ReplaceAll(provider.String(), "/", "_"), version, d.targetPlatform)
+	return filepath.Join(d.BasePath(), provenanceDirName, name)
+}
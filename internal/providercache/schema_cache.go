@@ -0,0 +1,376 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/replacefile"
+)
+
+// schemaCacheDirName is the name of the directory, inside a cache directory,
+// that holds the schemas of the providers cached there.
+const schemaCacheDirName = ".schemas"
+
+// schemaCacheFormatVersion is recorded in each schema cache entry, and must be
+// incremented whenever a change to the providers.ProviderSchema types would
+// make previously-written entries decode incorrectly.
+const schemaCacheFormatVersion = 2
+
+// SchemaCacheDir is an on-disk cache of provider schemas, which allows all of
+// the OpenTofu processes that share a global plugin cache directory to
+// reuse a schema that any one of them has already retrieved, rather than each
+// starting the provider to ask for it again.
+//
+// Entries are keyed by the checksums of the provider package, so an entry
+// can only ever be used for exactly the package it was retrieved from.
+// Entries are written atomically, and so concurrent processes can safely
+// read and write the same entries without any further coordination.
+type SchemaCacheDir struct {
+	baseDir string
+}
+
+// NewSchemaCacheDir returns a schema cache that stores its entries inside the
+// given cache directory.
+func NewSchemaCacheDir(cacheDir *Dir) *SchemaCacheDir {
+	return NewSchemaCacheDirAt(filepath.Join(cacheDir.BasePath(), schemaCacheDirName))
+}
+
+// NewSchemaCacheDirAt returns a schema cache that stores its entries directly
+// in the given directory, which is created when the first entry is stored.
+func NewSchemaCacheDirAt(baseDir string) *SchemaCacheDir {
+	return &SchemaCacheDir{
+		baseDir: baseDir,
+	}
+}
+
+type schemaCacheEntry struct {
+	FormatVersion      int                               `json:"format_version"`
+	Provider           string                            `json:"provider"`
+	Version            string                            `json:"version"`
+	ProviderConfig     cachedSchema                      `json:"provider_config"`
+	ProviderMeta       cachedSchema                      `json:"provider_meta"`
+	ResourceTypes      map[string]cachedSchema           `json:"resource_types"`
+	DataSources        map[string]cachedSchema           `json:"data_sources"`
+	EphemeralResources map[string]cachedSchema           `json:"ephemeral_resources"`
+	Functions          map[string]providers.FunctionSpec `json:"functions"`
+	ServerCapabilities providers.ServerCapabilities      `json:"server_capabilities"`
+}
+
+// The configschema types can't be encoded as JSON directly, because
+// attributes with nested types have no value for their Type field, so the
+// schema cache uses its own equivalent types.
+
+type cachedSchema struct {
+	Version int64        `json:"version"`
+	Block   *cachedBlock `json:"block,omitempty"`
+}
+
+type cachedBlock struct {
+	Attributes      map[string]*cachedAttribute   `json:"attributes,omitempty"`
+	BlockTypes      map[string]*cachedNestedBlock `json:"block_types,omitempty"`
+	Description     string                        `json:"description,omitempty"`
+	DescriptionKind configschema.StringKind       `json:"description_kind,omitempty"`
+	Deprecated      bool                          `json:"deprecated,omitempty"`
+}
+
+type cachedAttribute struct {
+	Type            *cty.Type               `json:"type,omitempty"`
+	NestedType      *cachedObject           `json:"nested_type,omitempty"`
+	Description     string                  `json:"description,omitempty"`
+	DescriptionKind configschema.StringKind `json:"description_kind,omitempty"`
+	Required        bool                    `json:"required,omitempty"`
+	Optional        bool                    `json:"optional,omitempty"`
+	Computed        bool                    `json:"computed,omitempty"`
+	Sensitive       bool                    `json:"sensitive,omitempty"`
+	Deprecated      bool                    `json:"deprecated,omitempty"`
+}
+
+type cachedObject struct {
+	Attributes map[string]*cachedAttribute `json:"attributes"`
+	Nesting    configschema.NestingMode    `json:"nesting"`
+}
+
+type cachedNestedBlock struct {
+	Block    cachedBlock              `json:"block"`
+	Nesting  configschema.NestingMode `json:"nesting"`
+	MinItems int                      `json:"min_items,omitempty"`
+	MaxItems int                      `json:"max_items,omitempty"`
+}
+
+// Get returns the cached schema for the given provider package, if any. The
+// hashes must be the checksums of the package as recorded in the dependency
+// lock file, which must have already been verified against the package.
+func (d *SchemaCacheDir) Get(provider addrs.Provider, version getproviders.Version, hashes []getproviders.Hash) (providers.ProviderSchema, bool) {
+	path := d.entryPath(provider, version, hashes)
+	if path == "" {
+		return providers.ProviderSchema{}, false
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] failed to read cached schema for %s %s: %s", provider, version, err)
+		}
+		return providers.ProviderSchema{}, false
+	}
+
+	// Entries that can't be used are removed, so that the next caller
+	// retrieves the schema from the provider and caches it again.
+	var entry schemaCacheEntry
+	if err := json.Unmarshal(src, &entry); err != nil {
+		log.Printf("[WARN] removing invalid cached schema for %s %s in %s: %s", provider, version, path, err)
+		d.remove(path)
+		return providers.ProviderSchema{}, false
+	}
+	if entry.FormatVersion != schemaCacheFormatVersion || entry.Provider != provider.String() || entry.Version != version.String() {
+		log.Printf("[TRACE] providercache.SchemaCacheDir: removing incompatible cached schema in %s", path)
+		d.remove(path)
+		return providers.ProviderSchema{}, false
+	}
+
+	log.Printf("[TRACE] providercache.SchemaCacheDir: using cached schema for %s %s from %s", provider, version, path)
+	return entry.providerSchema(), true
+}
+
+// Set stores the given schema for the given provider package. Schemas that
+// include errors are not stored, because they would prevent the provider from
+// being retried in future.
+func (d *SchemaCacheDir) Set(provider addrs.Provider, version getproviders.Version, hashes []getproviders.Hash, schema providers.ProviderSchema) error {
+	path := d.entryPath(provider, version, hashes)
+	if path == "" || schema.Diagnostics.HasErrors() {
+		return nil
+	}
+
+	// Warnings are specific to the call that produced them, so they are not
+	// cached and therefore not repeated for every future caller.
+	entry := newSchemaCacheEntry(schema)
+	entry.Provider = provider.String()
+	entry.Version = version.String()
+	src, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema for %s %s: %w", provider, version, err)
+	}
+
+	if err := os.MkdirAll(d.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create schema cache directory: %w", err)
+	}
+	log.Printf("[TRACE] providercache.SchemaCacheDir: caching schema for %s %s in %s", provider, version, path)
+	return replacefile.AtomicWriteFile(path, src, 0644)
+}
+
+// remove removes the given cache entry. Another process might be replacing
+// it at the same time, which is fine because then it's replaced atomically
+// either before or after the removal.
+func (d *SchemaCacheDir) remove(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[WARN] failed to remove cached schema %s: %s", path, err)
+	}
+}
+
+// entryPath returns the path of the file that caches the schema for the given
+// provider package, or an empty string if it doesn't have any hashes and so
+// its schema can't be cached.
+func (d *SchemaCacheDir) entryPath(provider addrs.Provider, version getproviders.Version, hashes []getproviders.Hash) string {
+	if len(hashes) == 0 {
+		return ""
+	}
+
+	strs := make([]string, len(hashes))
+	for i, h := range hashes {
+		strs[i] = h.String()
+	}
+	sort.Strings(strs)
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%s", provider, version, strings.Join(strs, "\n"))))
+	return filepath.Join(d.baseDir, hex.EncodeToString(sum[:])+".json")
+}
+
+func newSchemaCacheEntry(schema providers.ProviderSchema) *schemaCacheEntry {
+	ret := &schemaCacheEntry{
+		FormatVersion:      schemaCacheFormatVersion,
+		ProviderConfig:     newCachedSchema(schema.Provider),
+		ProviderMeta:       newCachedSchema(schema.ProviderMeta),
+		Functions:          schema.Functions,
+		ServerCapabilities: schema.ServerCapabilities,
+	}
+	if schema.ResourceTypes != nil {
+		ret.ResourceTypes = make(map[string]cachedSchema, len(schema.ResourceTypes))
+		for name, s := range schema.ResourceTypes {
+			ret.ResourceTypes[name] = newCachedSchema(s)
+		}
+	}
+	if schema.DataSources != nil {
+		ret.DataSources = make(map[string]cachedSchema, len(schema.DataSources))
+		for name, s := range schema.DataSources {
+			ret.DataSources[name] = newCachedSchema(s)
+		}
+	}
+	if schema.EphemeralResources != nil {
+		ret.EphemeralResources = make(map[string]cachedSchema, len(schema.EphemeralResources))
+		for name, s := range schema.EphemeralResources {
+			ret.EphemeralResources[name] = newCachedSchema(s)
+		}
+	}
+	return ret
+}
+
+func (e *schemaCacheEntry) providerSchema() providers.ProviderSchema {
+	ret := providers.ProviderSchema{
+		Provider:           e.ProviderConfig.schema(),
+		ProviderMeta:       e.ProviderMeta.schema(),
+		Functions:          e.Functions,
+		ServerCapabilities: e.ServerCapabilities,
+	}
+	if e.ResourceTypes != nil {
+		ret.ResourceTypes = make(map[string]providers.Schema, len(e.ResourceTypes))
+		for name, s := range e.ResourceTypes {
+			ret.ResourceTypes[name] = s.schema()
+		}
+	}
+	if e.DataSources != nil {
+		ret.DataSources = make(map[string]providers.Schema, len(e.DataSources))
+		for name, s := range e.DataSources {
+			ret.DataSources[name] = s.schema()
+		}
+	}
+	if e.EphemeralResources != nil {
+		ret.EphemeralResources = make(map[string]providers.Schema, len(e.EphemeralResources))
+		for name, s := range e.EphemeralResources {
+			ret.EphemeralResources[name] = s.schema()
+		}
+	}
+	return ret
+}
+
+func newCachedSchema(s providers.Schema) cachedSchema {
+	ret := cachedSchema{Version: s.Version}
+	if s.Block != nil {
+		ret.Block = newCachedBlock(s.Block)
+	}
+	return ret
+}
+
+func (s cachedSchema) schema() providers.Schema {
+	ret := providers.Schema{Version: s.Version}
+	if s.Block != nil {
+		ret.Block = s.Block.block()
+	}
+	return ret
+}
+
+func newCachedBlock(b *configschema.Block) *cachedBlock {
+	ret := &cachedBlock{
+		Description:     b.Description,
+		DescriptionKind: b.DescriptionKind,
+		Deprecated:      b.Deprecated,
+	}
+	if b.Attributes != nil {
+		ret.Attributes = newCachedAttributes(b.Attributes)
+	}
+	if b.BlockTypes != nil {
+		ret.BlockTypes = make(map[string]*cachedNestedBlock, len(b.BlockTypes))
+		for name, nested := range b.BlockTypes {
+			ret.BlockTypes[name] = &cachedNestedBlock{
+				Block:    *newCachedBlock(&nested.Block),
+				Nesting:  nested.Nesting,
+				MinItems: nested.MinItems,
+				MaxItems: nested.MaxItems,
+			}
+		}
+	}
+	return ret
+}
+
+func (b *cachedBlock) block() *configschema.Block {
+	ret := &configschema.Block{
+		Description:     b.Description,
+		DescriptionKind: b.DescriptionKind,
+		Deprecated:      b.Deprecated,
+	}
+	if b.Attributes != nil {
+		ret.Attributes = cachedAttributes(b.Attributes)
+	}
+	if b.BlockTypes != nil {
+		ret.BlockTypes = make(map[string]*configschema.NestedBlock, len(b.BlockTypes))
+		for name, nested := range b.BlockTypes {
+			ret.BlockTypes[name] = &configschema.NestedBlock{
+				Block:    *nested.Block.block(),
+				Nesting:  nested.Nesting,
+				MinItems: nested.MinItems,
+				MaxItems: nested.MaxItems,
+			}
+		}
+	}
+	return ret
+}
+
+func newCachedAttributes(attrs map[string]*configschema.Attribute) map[string]*cachedAttribute {
+	ret := make(map[string]*cachedAttribute, len(attrs))
+	for name, attr := range attrs {
+		a := &cachedAttribute{
+			Description:     attr.Description,
+			DescriptionKind: attr.DescriptionKind,
+			Required:        attr.Required,
+			Optional:        attr.Optional,
+			Computed:        attr.Computed,
+			Sensitive:       attr.Sensitive,
+			Deprecated:      attr.Deprecated,
+		}
+		if attr.Type != cty.NilType {
+			ty := attr.Type
+			a.Type = &ty
+		}
+		if attr.NestedType != nil {
+			a.NestedType = &cachedObject{
+				Attributes: newCachedAttributes(attr.NestedType.Attributes),
+				Nesting:    attr.NestedType.Nesting,
+			}
+		}
+		ret[name] = a
+	}
+	return ret
+}
+
+func cachedAttributes(attrs map[string]*cachedAttribute) map[string]*configschema.Attribute {
+	ret := make(map[string]*configschema.Attribute, len(attrs))
+	for name, a := range attrs {
+		attr := &configschema.Attribute{
+			Description:     a.Description,
+			DescriptionKind: a.DescriptionKind,
+			Required:        a.Required,
+			Optional:        a.Optional,
+			Computed:        a.Computed,
+			Sensitive:       a.Sensitive,
+			Deprecated:      a.Deprecated,
+		}
+		if a.Type != nil {
+			attr.Type = *a.Type
+		}
+		if a.NestedType != nil {
+			attr.NestedType = &configschema.Object{
+				Attributes: cachedAttributes(a.NestedType.Attributes),
+				Nesting:    a.NestedType.Nesting,
+			}
+		}
+		ret[name] = attr
+	}
+	return ret
+}
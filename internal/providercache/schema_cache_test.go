@@ -0,0 +1,172 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestSchemaCacheDir(t *testing.T) {
+	cache := NewSchemaCacheDir(NewDir(t.TempDir()))
+
+	provider := addrs.NewDefaultProvider("test")
+	version := getproviders.MustParseVersion("1.2.3")
+	hashes := []getproviders.Hash{
+		getproviders.HashScheme1.New("aaaa"),
+		getproviders.HashSchemeZip.New("bbbb"),
+	}
+	schema := providers.ProviderSchema{
+		Provider: providers.Schema{
+			Block: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"region": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Version: 2,
+				Block: &configschema.Block{
+					Description:     "An instance.",
+					DescriptionKind: configschema.StringMarkdown,
+					Attributes: map[string]*configschema.Attribute{
+						"id":   {Type: cty.String, Computed: true},
+						"tags": {Type: cty.Map(cty.String), Optional: true, Sensitive: true},
+						"volumes": {
+							NestedType: &configschema.Object{
+								Nesting: configschema.NestingList,
+								Attributes: map[string]*configschema.Attribute{
+									"size": {Type: cty.Number, Required: true},
+								},
+							},
+							Optional: true,
+						},
+					},
+					BlockTypes: map[string]*configschema.NestedBlock{
+						"network": {
+							Nesting:  configschema.NestingSet,
+							MaxItems: 3,
+							Block: configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"any": {Type: cty.DynamicPseudoType, Optional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		DataSources: map[string]providers.Schema{
+			"test_data": {Block: &configschema.Block{}},
+		},
+		Functions: map[string]providers.FunctionSpec{
+			"upper": {
+				Parameters: []providers.FunctionParameterSpec{
+					{Name: "str", Type: cty.String},
+				},
+				VariadicParameter: &providers.FunctionParameterSpec{Name: "rest", Type: cty.List(cty.Bool)},
+				Return:            cty.String,
+				DescriptionFormat: providers.TextFormattingMarkdown,
+			},
+		},
+		ServerCapabilities: providers.ServerCapabilities{
+			GetProviderSchemaOptional: true,
+		},
+	}
+
+	if _, ok := cache.Get(provider, version, hashes); ok {
+		t.Fatal("empty cache returned a schema")
+	}
+	if err := cache.Set(provider, version, hashes, schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// The order of the hashes doesn't matter, but they must all match.
+	got, ok := cache.Get(provider, version, []getproviders.Hash{hashes[1], hashes[0]})
+	if !ok {
+		t.Fatal("cached schema not found")
+	}
+	if diff := cmp.Diff(schema, got, cmp.Comparer(cty.Type.Equals)); diff != "" {
+		t.Fatalf("wrong schema\n%s", diff)
+	}
+	if _, ok := cache.Get(provider, version, hashes[:1]); ok {
+		t.Fatal("returned a schema for a package with different hashes")
+	}
+	if _, ok := cache.Get(provider, getproviders.MustParseVersion("1.2.4"), hashes); ok {
+		t.Fatal("returned a schema for a different version")
+	}
+}
+
+func TestSchemaCacheDir_notCached(t *testing.T) {
+	cache := NewSchemaCacheDir(NewDir(t.TempDir()))
+	provider := addrs.NewDefaultProvider("test")
+	version := getproviders.MustParseVersion("1.2.3")
+	hashes := []getproviders.Hash{getproviders.HashScheme1.New("aaaa")}
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Broken", "The provider is broken."))
+	if err := cache.Set(provider, version, hashes, providers.ProviderSchema{Diagnostics: diags}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(provider, version, hashes); ok {
+		t.Fatal("cached a schema with errors")
+	}
+
+	// Without any hashes there's nothing to identify the package by.
+	if err := cache.Set(provider, version, nil, providers.ProviderSchema{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get(provider, version, nil); ok {
+		t.Fatal("cached a schema without hashes")
+	}
+}
+
+func TestSchemaCacheDir_removesInvalidEntries(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schemas")
+	cache := NewSchemaCacheDirAt(dir)
+	provider := addrs.NewDefaultProvider("test")
+	version := getproviders.MustParseVersion("1.2.3")
+	hashes := []getproviders.Hash{getproviders.HashScheme1.New("aaaa")}
+
+	if err := cache.Set(provider, version, hashes, providers.ProviderSchema{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cache.entryPath(provider, version, hashes)
+	if filepath.Dir(path) != dir {
+		t.Fatalf("entry %s is not in the schema cache directory %s", path, dir)
+	}
+	if _, ok := cache.Get(provider, version, hashes); !ok {
+		t.Fatal("schema not cached")
+	}
+
+	for name, content := range map[string]string{
+		"invalid":      "{",
+		"incompatible": `{"format_version":0}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := cache.Get(provider, version, hashes); ok {
+				t.Fatal("returned an unusable schema")
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Fatal("unusable entry was not removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"sort"
+)
+
+// serverCapabilities maps the names that the configuration and the
+// machine-readable output of "tofu providers" use for the fields of
+// ServerCapabilities to functions that report whether they are set.
+var serverCapabilities = map[string]func(ServerCapabilities) bool{
+	"plan_destroy":                 func(c ServerCapabilities) bool { return c.PlanDestroy },
+	"get_provider_schema_optional": func(c ServerCapabilities) bool { return c.GetProviderSchemaOptional },
+	"check_resource_quotas":        func(c ServerCapabilities) bool { return c.CheckResourceQuotas },
+	"list_resources":               func(c ServerCapabilities) bool { return c.ListResources },
+}
+
+// ServerCapabilityNames returns the names of all of the capabilities that
+// providers can announce, in lexical order.
+func ServerCapabilityNames() []string {
+	names := make([]string, 0, len(serverCapabilities))
+	for name := range serverCapabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsServerCapabilityName returns true if the given name is the name of a
+// capability that providers can announce.
+func IsServerCapabilityName(name string) bool {
+	_, ok := serverCapabilities[name]
+	return ok
+}
+
+// Has returns true if the receiver includes the capability with the given
+// name, and false if it doesn't or if the name is unknown.
+func (c ServerCapabilities) Has(name string) bool {
+	has, ok := serverCapabilities[name]
+	return ok && has(c)
+}
+
+// Names returns the names of the capabilities that the receiver includes, in
+// lexical order.
+func (c ServerCapabilities) Names() []string {
+	names := []string{}
+	for _, name := range ServerCapabilityNames() {
+		if c.Has(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,35 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServerCapabilities(t *testing.T) {
+	caps := ServerCapabilities{
+		PlanDestroy:         true,
+		CheckResourceQuotas: true,
+	}
+
+	if diff := cmp.Diff([]string{"check_resource_quotas", "plan_destroy"}, caps.Names()); diff != "" {
+		t.Errorf("wrong names\n%s", diff)
+	}
+	if caps.Has("get_provider_schema_optional") {
+		t.Error("unexpected get_provider_schema_optional capability")
+	}
+	if caps.Has("not_a_capability") {
+		t.Error("unexpected unknown capability")
+	}
+	if got := len(ServerCapabilityNames()); got != 4 {
+		t.Errorf("got %d capability names; want 4", got)
+	}
+	if !IsServerCapabilityName("plan_destroy") || IsServerCapabilityName("not_a_capability") {
+		t.Error("wrong result from IsServerCapabilityName")
+	}
+}
@@ -6,6 +6,8 @@
 package providers
 
 import (
+	"time"
+
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/configs/configschema"
@@ -84,6 +86,37 @@ type Interface interface {
 	// CallFunction requests that the given function is called and response returned.
 	CallFunction(CallFunctionRequest) CallFunctionResponse
 
+	// ValidateEphemeralResourceConfig allows the provider to validate the
+	// ephemeral resource configuration values.
+	ValidateEphemeralResourceConfig(ValidateEphemeralResourceConfigRequest) ValidateEphemeralResourceConfigResponse
+
+	// OpenEphemeralResource opens an ephemeral resource and returns its
+	// result. OpenTofu never saves the result in the state or in a plan.
+	OpenEphemeralResource(OpenEphemeralResourceRequest) OpenEphemeralResourceResponse
+
+	// RenewEphemeralResource extends the lifetime of an open ephemeral
+	// resource. It's called at the time that the provider returned in the
+	// RenewAt field of the previous open or renew response.
+	RenewEphemeralResource(RenewEphemeralResourceRequest) RenewEphemeralResourceResponse
+
+	// CloseEphemeralResource closes an ephemeral resource once OpenTofu no
+	// longer needs its result.
+	CloseEphemeralResource(CloseEphemeralResourceRequest) CloseEphemeralResourceResponse
+
+	// CheckResourceQuotas asks the provider whether creating the given number
+	// of new objects of each resource type would exceed a quota or limit in
+	// its target platform. It's only called for providers that declare the
+	// CheckResourceQuotas server capability, and only after the provider has
+	// been configured.
+	CheckResourceQuotas(CheckResourceQuotasRequest) CheckResourceQuotasResponse
+
+	// ListResources lists the existing objects of a managed resource type in
+	// the provider's target platform, so that OpenTofu can generate import
+	// blocks for them. It's only called for providers that declare the
+	// ListResources server capability, and only after the provider has been
+	// configured.
+	ListResources(ListResourcesRequest) ListResourcesResponse
+
 	// Close shuts down the plugin process if applicable.
 	Close() error
 }
@@ -106,6 +139,10 @@ type GetProviderSchemaResponse struct {
 	// DataSources maps the data source name to that data source's schema.
 	DataSources map[string]Schema
 
+	// EphemeralResources maps the ephemeral resource type name to that
+	// type's schema.
+	EphemeralResources map[string]Schema
+
 	// Diagnostics contains any warnings or errors from the method call.
 	Diagnostics tfdiags.Diagnostics
 
@@ -125,6 +162,27 @@ type GetProviderSchemaResponse struct {
 type Schema struct {
 	Version int64
 	Block   *configschema.Block
+
+	// PermissionHints optionally describes the permissions the provider
+	// needs in its target platform to act on objects of this type. It is nil
+	// if the provider doesn't declare any.
+	PermissionHints *PermissionHints
+}
+
+// PermissionHints describes the permissions a provider expects to need in
+// order to perform each kind of action on a resource type or data source.
+//
+// The permission names are opaque to OpenTofu, and use whatever vocabulary
+// the provider's target platform uses for access control, such as IAM action
+// names. They are only used to report the permissions required by a plan.
+type PermissionHints struct {
+	// Create, Read, Update and Delete list the permissions required to
+	// create, read (or refresh), update in-place and destroy an object
+	// respectively. Data sources only use Read.
+	Create []string
+	Read   []string
+	Update []string
+	Delete []string
 }
 
 // ServerCapabilities allows providers to communicate extra information
@@ -143,6 +201,15 @@ type ServerCapabilities struct {
 	// In other words, the providers for which GetProviderSchemaOptional is false
 	// require their schema to be read after EVERY instantiation to function normally.
 	GetProviderSchemaOptional bool
+
+	// CheckResourceQuotas signals that this provider can check, during
+	// planning, whether the objects a plan proposes to create would exceed
+	// quotas or limits in its target platform.
+	CheckResourceQuotas bool
+
+	// ListResources signals that this provider can list the existing objects
+	// of its managed resource types, for import discovery.
+	ListResources bool
 }
 
 type FunctionSpec struct {
@@ -478,6 +545,80 @@ type ReadDataSourceResponse struct {
 	Diagnostics tfdiags.Diagnostics
 }
 
+type ValidateEphemeralResourceConfigRequest struct {
+	// TypeName is the name of the ephemeral resource type to validate.
+	TypeName string
+
+	// Config is the configuration value to validate, which may contain
+	// unknown values.
+	Config cty.Value
+}
+
+type ValidateEphemeralResourceConfigResponse struct {
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+type OpenEphemeralResourceRequest struct {
+	// TypeName is the name of the ephemeral resource type to open.
+	TypeName string
+
+	// Config is the complete configuration for the requested ephemeral
+	// resource.
+	Config cty.Value
+}
+
+type OpenEphemeralResourceResponse struct {
+	// Result is the object that the ephemeral resource opened.
+	Result cty.Value
+
+	// Private is opaque data that the provider needs to renew and close the
+	// ephemeral resource.
+	Private []byte
+
+	// RenewAt is the time at which OpenTofu must renew the ephemeral
+	// resource if it's still open, or the zero time if it doesn't need to be
+	// renewed.
+	RenewAt time.Time
+
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+type RenewEphemeralResourceRequest struct {
+	// TypeName is the name of the ephemeral resource type to renew.
+	TypeName string
+
+	// Private is the private data from the previous open or renew response.
+	Private []byte
+}
+
+type RenewEphemeralResourceResponse struct {
+	// RenewAt is the time at which OpenTofu must renew the ephemeral
+	// resource again, or the zero time if it doesn't need to be renewed
+	// again.
+	RenewAt time.Time
+
+	// Private is the updated private data for the ephemeral resource.
+	Private []byte
+
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+type CloseEphemeralResourceRequest struct {
+	// TypeName is the name of the ephemeral resource type to close.
+	TypeName string
+
+	// Private is the private data from the latest open or renew response.
+	Private []byte
+}
+
+type CloseEphemeralResourceResponse struct {
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
 type GetFunctionsResponse struct {
 	Functions map[string]FunctionSpec
 
@@ -502,3 +643,69 @@ type CallFunctionArgumentError struct {
 func (err *CallFunctionArgumentError) Error() string {
 	return err.Text
 }
+
+type CheckResourceQuotasRequest struct {
+	// Creates maps the names of resource types to the number of new objects
+	// of each type that the plan proposes to create.
+	Creates map[string]int
+}
+
+type CheckResourceQuotasResponse struct {
+	// Risks describes the resource types for which creating the requested
+	// number of objects would probably exceed a quota or limit. Resource
+	// types with enough headroom are not included.
+	Risks []QuotaRisk
+
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+// QuotaRisk describes a quota or limit that creating new objects of a
+// resource type would probably exceed.
+type QuotaRisk struct {
+	// TypeName is the resource type the quota applies to.
+	TypeName string
+
+	// Requested is the number of new objects of the type that the plan
+	// proposes to create.
+	Requested int
+
+	// Available is the number of new objects the quota still allows, or a
+	// negative number if the provider can't tell.
+	Available int
+
+	// Detail is a human-readable description of the quota, such as
+	// "VPCs per region (limit 5)".
+	Detail string
+}
+
+type ListResourcesRequest struct {
+	// TypeName is the name of the managed resource type to list.
+	TypeName string
+
+	// Filter is the value of the filter argument of the discover block, or a
+	// null value if it isn't set. Its meaning is specific to the provider
+	// and the resource type.
+	Filter cty.Value
+}
+
+type ListResourcesResponse struct {
+	// Resources describes the objects that the provider found.
+	Resources []ListedResource
+
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+// ListedResource describes an existing object that a provider found when
+// listing the objects of a resource type.
+type ListedResource struct {
+	// ID is the ID that imports the object, as accepted by
+	// ImportResourceState.
+	ID string
+
+	// Name is an optional human-readable name for the object, such as the
+	// value of a name tag, which OpenTofu uses in the name of the generated
+	// resource instead of the ID if it's set.
+	Name string
+}
@@ -0,0 +1,365 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// RetryPolicy describes how to retry provider calls that fail for transient
+// reasons.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts for each call, including
+	// the first. Values less than two disable retries.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first retry. Each later retry
+	// waits twice as long as the previous one, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	// RetryableErrors are patterns matched against the summary and detail
+	// of the errors returned by the provider. A call whose errors all match
+	// at least one pattern is retried, in addition to calls that fail
+	// because the plugin stopped responding.
+	RetryableErrors []*regexp.Regexp
+}
+
+// Delay returns how long to wait before the given retry, where 1 is the
+// first retry.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.InitialDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// retryable reports whether a call that returned the given diagnostics is
+// worth retrying, and whether the plugin itself failed, in which case it must
+// be restarted before trying again.
+func (p RetryPolicy) retryable(diags tfdiags.Diagnostics) (retry, restart bool) {
+	if !diags.HasErrors() {
+		return false, false
+	}
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		if tfdiags.DiagnosticRetryable(diag) {
+			restart = true
+			continue
+		}
+		if !p.matches(diag) {
+			return false, false
+		}
+	}
+	return true, restart
+}
+
+func (p RetryPolicy) matches(diag tfdiags.Diagnostic) bool {
+	desc := diag.Description()
+	for _, re := range p.RetryableErrors {
+		if re.MatchString(desc.Summary) || re.MatchString(desc.Detail) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryEvent describes a retry of a failed provider call, for reporting to
+// the user.
+type RetryEvent struct {
+	// Method is the name of the provider method being retried.
+	Method string
+
+	// Attempt is the number of the attempt about to be made, starting at 2
+	// for the first retry.
+	Attempt     int
+	MaxAttempts int
+
+	// Delay is how long OpenTofu waits before the attempt.
+	Delay time.Duration
+
+	// Diagnostics are the errors returned by the previous attempt.
+	Diagnostics tfdiags.Diagnostics
+}
+
+// NewRetryingProvider wraps the given provider so that its calls are retried
+// according to the given policy. If the plugin stops responding, the wrapper
+// starts a new instance of the provider using the given factory and
+// configures it in the same way as the failed instance before retrying.
+//
+// The notify function, if not nil, is called before each retry.
+//
+// ApplyResourceChange is never retried, because a failed call might already
+// have partially changed the remote object. CallFunction is not retried
+// either, because function errors don't describe their cause.
+func NewRetryingProvider(p Interface, factory Factory, policy RetryPolicy, notify func(RetryEvent)) Interface {
+	return &retryingProvider{
+		policy:  policy,
+		factory: factory,
+		notify:  notify,
+		current: p,
+		stopCh:  make(chan struct{}),
+	}
+}
+
+type retryingProvider struct {
+	policy  RetryPolicy
+	factory Factory
+	notify  func(RetryEvent)
+
+	mu sync.Mutex
+	// current is the provider instance that calls are currently sent to,
+	// and generation counts the restarts so that concurrent callers which
+	// saw the same failure restart the plugin only once.
+	current    Interface
+	generation int
+	configure  *ConfigureProviderRequest
+	stopped    bool
+	stopCh     chan struct{}
+}
+
+var _ Interface = (*retryingProvider)(nil)
+
+func (p *retryingProvider) instance() (Interface, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current, p.generation
+}
+
+// restart replaces the provider instance of the given generation with a new
+// one, unless another caller has already done so.
+func (p *retryingProvider) restart(generation int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.generation != generation || p.stopped {
+		return
+	}
+
+	if err := p.current.Close(); err != nil {
+		log.Printf("[DEBUG] retryingProvider: failed to close the failed provider instance: %s", err)
+	}
+	next, err := p.factory()
+	if err != nil {
+		log.Printf("[ERROR] retryingProvider: failed to restart the provider: %s", err)
+		return
+	}
+	if p.configure != nil {
+		resp := next.ConfigureProvider(*p.configure)
+		if resp.Diagnostics.HasErrors() {
+			log.Printf("[ERROR] retryingProvider: failed to configure the restarted provider: %s", resp.Diagnostics.Err())
+		}
+	}
+	p.current = next
+	p.generation++
+}
+
+// wait sleeps for the given duration, returning false if the provider was
+// stopped in the meantime.
+func (p *retryingProvider) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.stopCh:
+		return false
+	}
+}
+
+func withRetries[R any](p *retryingProvider, method string, call func(Interface) (R, tfdiags.Diagnostics)) R {
+	for attempt := 1; ; attempt++ {
+		inner, generation := p.instance()
+		resp, diags := call(inner)
+		if attempt >= p.policy.MaxAttempts {
+			return resp
+		}
+		retry, restart := p.policy.retryable(diags)
+		if !retry {
+			return resp
+		}
+
+		delay := p.policy.Delay(attempt)
+		log.Printf("[WARN] retryingProvider: %s failed, retrying in %s (attempt %d of %d): %s", method, delay, attempt+1, p.policy.MaxAttempts, diags.Err())
+		if p.notify != nil {
+			p.notify(RetryEvent{
+				Method:      method,
+				Attempt:     attempt + 1,
+				MaxAttempts: p.policy.MaxAttempts,
+				Delay:       delay,
+				Diagnostics: diags,
+			})
+		}
+		if !p.wait(delay) {
+			return resp
+		}
+		if restart {
+			p.restart(generation)
+		}
+	}
+}
+
+func (p *retryingProvider) GetProviderSchema() GetProviderSchemaResponse {
+	return withRetries(p, "GetProviderSchema", func(inner Interface) (GetProviderSchemaResponse, tfdiags.Diagnostics) {
+		resp := inner.GetProviderSchema()
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ValidateProviderConfig(req ValidateProviderConfigRequest) ValidateProviderConfigResponse {
+	return withRetries(p, "ValidateProviderConfig", func(inner Interface) (ValidateProviderConfigResponse, tfdiags.Diagnostics) {
+		resp := inner.ValidateProviderConfig(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ValidateResourceConfig(req ValidateResourceConfigRequest) ValidateResourceConfigResponse {
+	return withRetries(p, "ValidateResourceConfig", func(inner Interface) (ValidateResourceConfigResponse, tfdiags.Diagnostics) {
+		resp := inner.ValidateResourceConfig(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ValidateDataResourceConfig(req ValidateDataResourceConfigRequest) ValidateDataResourceConfigResponse {
+	return withRetries(p, "ValidateDataResourceConfig", func(inner Interface) (ValidateDataResourceConfigResponse, tfdiags.Diagnostics) {
+		resp := inner.ValidateDataResourceConfig(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) UpgradeResourceState(req UpgradeResourceStateRequest) UpgradeResourceStateResponse {
+	return withRetries(p, "UpgradeResourceState", func(inner Interface) (UpgradeResourceStateResponse, tfdiags.Diagnostics) {
+		resp := inner.UpgradeResourceState(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ConfigureProvider(req ConfigureProviderRequest) ConfigureProviderResponse {
+	// We remember the configuration so that we can configure a restarted
+	// instance in the same way.
+	p.mu.Lock()
+	p.configure = &req
+	p.mu.Unlock()
+
+	return withRetries(p, "ConfigureProvider", func(inner Interface) (ConfigureProviderResponse, tfdiags.Diagnostics) {
+		resp := inner.ConfigureProvider(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) Stop() error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.stopCh)
+	}
+	inner := p.current
+	p.mu.Unlock()
+
+	return inner.Stop()
+}
+
+func (p *retryingProvider) ReadResource(req ReadResourceRequest) ReadResourceResponse {
+	return withRetries(p, "ReadResource", func(inner Interface) (ReadResourceResponse, tfdiags.Diagnostics) {
+		resp := inner.ReadResource(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) PlanResourceChange(req PlanResourceChangeRequest) PlanResourceChangeResponse {
+	return withRetries(p, "PlanResourceChange", func(inner Interface) (PlanResourceChangeResponse, tfdiags.Diagnostics) {
+		resp := inner.PlanResourceChange(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ApplyResourceChange(req ApplyResourceChangeRequest) ApplyResourceChangeResponse {
+	inner, _ := p.instance()
+	return inner.ApplyResourceChange(req)
+}
+
+func (p *retryingProvider) ImportResourceState(req ImportResourceStateRequest) ImportResourceStateResponse {
+	return withRetries(p, "ImportResourceState", func(inner Interface) (ImportResourceStateResponse, tfdiags.Diagnostics) {
+		resp := inner.ImportResourceState(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ReadDataSource(req ReadDataSourceRequest) ReadDataSourceResponse {
+	return withRetries(p, "ReadDataSource", func(inner Interface) (ReadDataSourceResponse, tfdiags.Diagnostics) {
+		resp := inner.ReadDataSource(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) GetFunctions() GetFunctionsResponse {
+	return withRetries(p, "GetFunctions", func(inner Interface) (GetFunctionsResponse, tfdiags.Diagnostics) {
+		resp := inner.GetFunctions()
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) CallFunction(req CallFunctionRequest) CallFunctionResponse {
+	inner, _ := p.instance()
+	return inner.CallFunction(req)
+}
+
+func (p *retryingProvider) ValidateEphemeralResourceConfig(req ValidateEphemeralResourceConfigRequest) ValidateEphemeralResourceConfigResponse {
+	return withRetries(p, "ValidateEphemeralResourceConfig", func(inner Interface) (ValidateEphemeralResourceConfigResponse, tfdiags.Diagnostics) {
+		resp := inner.ValidateEphemeralResourceConfig(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) OpenEphemeralResource(req OpenEphemeralResourceRequest) OpenEphemeralResourceResponse {
+	return withRetries(p, "OpenEphemeralResource", func(inner Interface) (OpenEphemeralResourceResponse, tfdiags.Diagnostics) {
+		resp := inner.OpenEphemeralResource(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) RenewEphemeralResource(req RenewEphemeralResourceRequest) RenewEphemeralResourceResponse {
+	return withRetries(p, "RenewEphemeralResource", func(inner Interface) (RenewEphemeralResourceResponse, tfdiags.Diagnostics) {
+		resp := inner.RenewEphemeralResource(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) CloseEphemeralResource(req CloseEphemeralResourceRequest) CloseEphemeralResourceResponse {
+	return withRetries(p, "CloseEphemeralResource", func(inner Interface) (CloseEphemeralResourceResponse, tfdiags.Diagnostics) {
+		resp := inner.CloseEphemeralResource(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) CheckResourceQuotas(req CheckResourceQuotasRequest) CheckResourceQuotasResponse {
+	return withRetries(p, "CheckResourceQuotas", func(inner Interface) (CheckResourceQuotasResponse, tfdiags.Diagnostics) {
+		resp := inner.CheckResourceQuotas(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) ListResources(req ListResourcesRequest) ListResourcesResponse {
+	return withRetries(p, "ListResources", func(inner Interface) (ListResourcesResponse, tfdiags.Diagnostics) {
+		resp := inner.ListResources(req)
+		return resp, resp.Diagnostics
+	})
+}
+
+func (p *retryingProvider) Close() error {
+	inner, _ := p.instance()
+	return inner.Close()
+}
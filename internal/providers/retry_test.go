@@ -0,0 +1,174 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// retryTestProvider fails each ReadResource and ApplyResourceChange call
+// with errors from its failures list until the list is exhausted.
+type retryTestProvider struct {
+	Interface
+
+	failures   []tfdiags.Diagnostic
+	calls      int
+	configured cty.Value
+	closed     bool
+}
+
+func (p *retryTestProvider) result() tfdiags.Diagnostics {
+	p.calls++
+	var diags tfdiags.Diagnostics
+	if len(p.failures) > 0 {
+		diags = diags.Append(p.failures[0])
+		p.failures = p.failures[1:]
+	}
+	return diags
+}
+
+func (p *retryTestProvider) ReadResource(ReadResourceRequest) ReadResourceResponse {
+	return ReadResourceResponse{Diagnostics: p.result()}
+}
+
+func (p *retryTestProvider) ApplyResourceChange(ApplyResourceChangeRequest) ApplyResourceChangeResponse {
+	return ApplyResourceChangeResponse{Diagnostics: p.result()}
+}
+
+func (p *retryTestProvider) ConfigureProvider(req ConfigureProviderRequest) ConfigureProviderResponse {
+	p.configured = req.Config
+	return ConfigureProviderResponse{}
+}
+
+func (p *retryTestProvider) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestRetryingProvider_restart(t *testing.T) {
+	crashed := &retryTestProvider{
+		failures: []tfdiags.Diagnostic{
+			tfdiags.RetryableSourceless(tfdiags.Error, "Plugin did not respond", "The plugin crashed."),
+		},
+	}
+	restarted := &retryTestProvider{}
+
+	var events []RetryEvent
+	p := NewRetryingProvider(crashed, FactoryFixed(restarted), RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+	}, func(event RetryEvent) {
+		events = append(events, event)
+	})
+
+	config := cty.ObjectVal(map[string]cty.Value{"region": cty.StringVal("moon")})
+	p.ConfigureProvider(ConfigureProviderRequest{Config: config})
+
+	resp := p.ReadResource(ReadResourceRequest{})
+	if resp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected error: %s", resp.Diagnostics.Err())
+	}
+	if !crashed.closed {
+		t.Error("crashed instance was not closed")
+	}
+	if restarted.calls != 1 {
+		t.Errorf("restarted instance got %d calls; want 1", restarted.calls)
+	}
+	if !restarted.configured.RawEquals(config) {
+		t.Errorf("restarted instance has the wrong configuration %#v", restarted.configured)
+	}
+	if len(events) != 1 || events[0].Method != "ReadResource" || events[0].Attempt != 2 {
+		t.Errorf("wrong retry events %#v", events)
+	}
+}
+
+func TestRetryingProvider_retryableErrors(t *testing.T) {
+	inner := &retryTestProvider{
+		failures: []tfdiags.Diagnostic{
+			tfdiags.Sourceless(tfdiags.Error, "Rate exceeded", "Slow down."),
+			tfdiags.Sourceless(tfdiags.Error, "Rate exceeded", "Slow down."),
+			tfdiags.Sourceless(tfdiags.Error, "Rate exceeded", "Slow down."),
+		},
+	}
+	p := NewRetryingProvider(inner, nil, RetryPolicy{
+		MaxAttempts:     3,
+		InitialDelay:    time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		RetryableErrors: []*regexp.Regexp{regexp.MustCompile("(?i)rate exceeded")},
+	}, nil)
+
+	resp := p.ReadResource(ReadResourceRequest{})
+	if !resp.Diagnostics.HasErrors() {
+		t.Fatal("expected the error from the last attempt")
+	}
+	if inner.calls != 3 {
+		t.Errorf("got %d calls; want 3", inner.calls)
+	}
+	if inner.closed {
+		t.Error("instance was restarted for a provider error")
+	}
+}
+
+func TestRetryingProvider_notRetryable(t *testing.T) {
+	inner := &retryTestProvider{
+		failures: []tfdiags.Diagnostic{
+			tfdiags.Sourceless(tfdiags.Error, "Invalid region", "There's no such region."),
+		},
+	}
+	p := NewRetryingProvider(inner, nil, RetryPolicy{
+		MaxAttempts:     3,
+		InitialDelay:    time.Millisecond,
+		MaxDelay:        time.Millisecond,
+		RetryableErrors: []*regexp.Regexp{regexp.MustCompile("(?i)rate exceeded")},
+	}, nil)
+
+	if resp := p.ReadResource(ReadResourceRequest{}); !resp.Diagnostics.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if inner.calls != 1 {
+		t.Errorf("got %d calls; want 1", inner.calls)
+	}
+}
+
+func TestRetryingProvider_applyNotRetried(t *testing.T) {
+	inner := &retryTestProvider{
+		failures: []tfdiags.Diagnostic{
+			tfdiags.RetryableSourceless(tfdiags.Error, "Plugin did not respond", "The plugin crashed."),
+		},
+	}
+	p := NewRetryingProvider(inner, nil, RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+	}, nil)
+
+	if resp := p.ApplyResourceChange(ApplyResourceChangeRequest{}); !resp.Diagnostics.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if inner.calls != 1 {
+		t.Errorf("got %d calls; want 1", inner.calls)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := policy.Delay(i + 1); got != w {
+			t.Errorf("wrong delay for retry %d: got %s, want %s", i+1, got, w)
+		}
+	}
+}
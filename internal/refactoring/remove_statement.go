@@ -16,23 +16,42 @@ import (
 
 type RemoveStatement struct {
 	From      addrs.ConfigRemovable
+	Destroy   bool
 	DeclRange tfdiags.SourceRange
 }
 
 // GetEndpointsToRemove recurses through the modules of the given configuration
-// and returns an array of all "removed" addresses within, in a
-// deterministic but undefined order.
+// and returns an array of all "removed" addresses within that are to be
+// forgotten rather than destroyed, in a deterministic but undefined order.
 // We also validate that the removed modules/resources configuration blocks were removed.
 func GetEndpointsToRemove(rootCfg *configs.Config) ([]addrs.ConfigRemovable, tfdiags.Diagnostics) {
 	rm := findRemoveStatements(rootCfg, nil)
 	diags := validateRemoveStatements(rootCfg, rm)
-	removedAddresses := make([]addrs.ConfigRemovable, len(rm))
-	for i, rs := range rm {
-		removedAddresses[i] = rs.From
+	var removedAddresses []addrs.ConfigRemovable
+	for _, rs := range rm {
+		if !rs.Destroy {
+			removedAddresses = append(removedAddresses, rs.From)
+		}
 	}
 	return removedAddresses, diags
 }
 
+// GetEndpointsToDestroy recurses through the modules of the given
+// configuration and returns an array of all "removed" addresses within whose
+// objects are to be destroyed, in a deterministic but undefined order.
+//
+// GetEndpointsToRemove validates the same statements, so this function
+// doesn't return diagnostics.
+func GetEndpointsToDestroy(rootCfg *configs.Config) []addrs.ConfigRemovable {
+	var ret []addrs.ConfigRemovable
+	for _, rs := range findRemoveStatements(rootCfg, nil) {
+		if rs.Destroy {
+			ret = append(ret, rs.From)
+		}
+	}
+	return ret
+}
+
 func findRemoveStatements(cfg *configs.Config, into []*RemoveStatement) []*RemoveStatement {
 	modAddr := cfg.Path
 
@@ -51,7 +70,7 @@ func findRemoveStatements(cfg *configs.Config, into []*RemoveStatement) []*Remov
 				Module:   absModule,
 			}
 
-			removedEndpoint = &RemoveStatement{From: absConfigResource, DeclRange: tfdiags.SourceRangeFromHCL(rc.DeclRange)}
+			removedEndpoint = &RemoveStatement{From: absConfigResource, Destroy: rc.Destroy, DeclRange: tfdiags.SourceRangeFromHCL(rc.DeclRange)}
 
 		case addrs.Module:
 			// Get the absolute address of the module by appending the module config address
@@ -59,7 +78,7 @@ func findRemoveStatements(cfg *configs.Config, into []*RemoveStatement) []*Remov
 			var absModule = make(addrs.Module, 0, len(modAddr)+len(FromAddress))
 			absModule = append(absModule, modAddr...)
 			absModule = append(absModule, FromAddress...)
-			removedEndpoint = &RemoveStatement{From: absModule, DeclRange: tfdiags.SourceRangeFromHCL(rc.DeclRange)}
+			removedEndpoint = &RemoveStatement{From: absModule, Destroy: rc.Destroy, DeclRange: tfdiags.SourceRangeFromHCL(rc.DeclRange)}
 
 		default:
 			panic(fmt.Sprintf("unhandled address type %T", FromAddress))
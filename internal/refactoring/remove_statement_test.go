@@ -76,6 +76,31 @@ func TestGetEndpointsToRemove(t *testing.T) {
 	}
 }
 
+func TestGetEndpointsToDestroy(t *testing.T) {
+	rootCfg, _ := loadRefactoringFixture(t, "testdata/remove-statement/valid-remove-statements-destroy")
+
+	gotRemove, diags := GetEndpointsToRemove(rootCfg)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	wantRemove := []addrs.ConfigRemovable{
+		interface{}(mustConfigResourceAddr("foo.forgotten_resource")).(addrs.ConfigRemovable),
+		interface{}(mustConfigResourceAddr("foo.kept_resource")).(addrs.ConfigRemovable),
+	}
+	if diff := cmp.Diff(wantRemove, gotRemove); diff != "" {
+		t.Errorf("wrong endpoints to remove\n%s", diff)
+	}
+
+	gotDestroy := GetEndpointsToDestroy(rootCfg)
+	wantDestroy := []addrs.ConfigRemovable{
+		interface{}(mustConfigResourceAddr("foo.destroyed_resource")).(addrs.ConfigRemovable),
+		interface{}(addrs.Module{"destroyed_module"}).(addrs.ConfigRemovable),
+	}
+	if diff := cmp.Diff(wantDestroy, gotDestroy); diff != "" {
+		t.Errorf("wrong endpoints to destroy\n%s", diff)
+	}
+}
+
 func mustConfigResourceAddr(s string) addrs.ConfigResource {
 	addr, diags := addrs.ParseAbsResourceStr(s)
 	if diags.HasErrors() {
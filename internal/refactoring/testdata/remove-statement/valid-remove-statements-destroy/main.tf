@@ -0,0 +1,24 @@
+removed {
+  from = foo.forgotten_resource
+}
+
+removed {
+  from = foo.kept_resource
+  lifecycle {
+    destroy = false
+  }
+}
+
+removed {
+  from = foo.destroyed_resource
+  lifecycle {
+    destroy = true
+  }
+}
+
+removed {
+  from = module.destroyed_module
+  lifecycle {
+    destroy = true
+  }
+}
@@ -8,6 +8,7 @@ package registry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -50,6 +51,10 @@ const (
 	// defaultRequestTimeout is the default timeout duration for requests to the
 	// remote registry.
 	defaultRequestTimeout = 10 * time.Second
+
+	// maxModuleVersionsPages limits the number of pages of module versions
+	// that the client fetches, in case a registry never returns a last page.
+	maxModuleVersionsPages = 100
 )
 
 var (
@@ -87,6 +92,7 @@ func NewClient(services *disco.Disco, client *http.Client) *Client {
 	retryableClient := retryablehttp.NewClient()
 	retryableClient.HTTPClient = client
 	retryableClient.RetryMax = discoveryRetry
+	retryableClient.CheckRetry = httpclient.CheckRetry
 	retryableClient.RequestLogHook = requestLogHook
 	retryableClient.ErrorHandler = maxRetryErrorHandler
 
@@ -116,6 +122,9 @@ func (c *Client) Discover(host svchost.Hostname, serviceID string) (*url.URL, er
 }
 
 // ModuleVersions queries the registry for a module, and returns the available versions.
+//
+// If the registry returns the versions in several pages, ModuleVersions
+// fetches all of them and returns the versions of all pages together.
 func (c *Client) ModuleVersions(ctx context.Context, module *regsrc.Module) (*response.ModuleVersions, error) {
 	host, err := module.SvcHost()
 	if err != nil {
@@ -134,6 +143,46 @@ func (c *Client) ModuleVersions(ctx context.Context, module *regsrc.Module) (*re
 
 	service = service.ResolveReference(p)
 
+	var versions *response.ModuleVersions
+	fetched := make(map[string]bool)
+	for pageURL := service; pageURL != nil; {
+		if len(fetched) == maxModuleVersionsPages {
+			return nil, fmt.Errorf("error looking up module versions: the registry returned more than %d pages of versions", maxModuleVersionsPages)
+		}
+		fetched[pageURL.String()] = true
+
+		page, err := c.moduleVersionsPage(ctx, host, module, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		if versions == nil {
+			versions = page
+		} else {
+			mergeModuleVersions(versions, page)
+		}
+
+		pageURL, err = nextModuleVersionsPage(pageURL, page.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up module versions: %w", err)
+		}
+		if pageURL != nil && fetched[pageURL.String()] {
+			return nil, fmt.Errorf("error looking up module versions: the registry returned %s as the next page again", pageURL)
+		}
+	}
+	// The result has the versions of all the pages.
+	versions.Meta = nil
+
+	for _, mod := range versions.Modules {
+		for _, v := range mod.Versions {
+			log.Printf("[DEBUG] found available version %q for %s", v.Version, module.Module())
+		}
+	}
+
+	return versions, nil
+}
+
+// moduleVersionsPage fetches a single page of the versions of a module.
+func (c *Client) moduleVersionsPage(ctx context.Context, host svchost.Hostname, module *regsrc.Module, service *url.URL) (*response.ModuleVersions, error) {
 	log.Printf("[DEBUG] fetching module versions from %q", service)
 
 	req, err := retryablehttp.NewRequest("GET", service.String(), nil)
@@ -167,13 +216,64 @@ func (c *Client) ModuleVersions(ctx context.Context, module *regsrc.Module) (*re
 		return nil, err
 	}
 
-	for _, mod := range versions.Modules {
-		for _, v := range mod.Versions {
-			log.Printf("[DEBUG] found available version %q for %s", v.Version, module.Module())
+	return &versions, nil
+}
+
+// nextModuleVersionsPage returns the URL of the page of module versions after
+// the one at the given URL, or nil if it was the last page.
+//
+// The registry can give the next page either as a URL, which can be relative
+// to the current page, or as an offset for the current URL. The next page
+// must be on the same host, because the request has the credentials for the
+// host.
+func nextModuleVersionsPage(current *url.URL, meta *response.PaginationMeta) (*url.URL, error) {
+	if meta == nil {
+		return nil, nil
+	}
+
+	var next *url.URL
+	switch {
+	case meta.NextURL != "":
+		u, err := url.Parse(meta.NextURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid next page URL %q: %w", meta.NextURL, err)
+		}
+		next = current.ResolveReference(u)
+	case meta.NextOffset != nil:
+		u := *current
+		q := u.Query()
+		q.Set("offset", strconv.Itoa(*meta.NextOffset))
+		if meta.Limit > 0 {
+			q.Set("limit", strconv.Itoa(meta.Limit))
 		}
+		u.RawQuery = q.Encode()
+		next = &u
+	default:
+		return nil, nil
 	}
 
-	return &versions, nil
+	if next.Scheme != current.Scheme || next.Host != current.Host {
+		return nil, fmt.Errorf("the next page URL %s is not on the registry host %s", next, current.Host)
+	}
+	return next, nil
+}
+
+// mergeModuleVersions adds the versions in the given page to the result.
+func mergeModuleVersions(result, page *response.ModuleVersions) {
+	for _, pageMod := range page.Modules {
+		var mod *response.ModuleProviderVersions
+		for _, m := range result.Modules {
+			if m.Source == pageMod.Source {
+				mod = m
+				break
+			}
+		}
+		if mod == nil {
+			result.Modules = append(result.Modules, pageMod)
+			continue
+		}
+		mod.Versions = append(mod.Versions, pageMod.Versions...)
+	}
 }
 
 func (c *Client) addRequestCreds(host svchost.Hostname, req *http.Request) {
@@ -316,6 +416,11 @@ func maxRetryErrorHandler(resp *http.Response, err error, numTries int) (*http.R
 		resp.Body.Close()
 	}
 
+	// Retrying or trying again later won't help if OpenTofu is offline.
+	if errors.As(err, &httpclient.ErrOffline{}) {
+		return resp, err
+	}
+
 	// Additional error detail: if we have a response, use the status code;
 	// if we have an error, use that; otherwise nothing. We will never have
 	// both response and error.
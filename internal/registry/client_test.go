@@ -10,6 +10,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"strings"
@@ -137,6 +138,89 @@ func TestLookupModuleVersions(t *testing.T) {
 	}
 }
 
+func TestLookupModuleVersions_paginated(t *testing.T) {
+	tests := map[string]struct {
+		pages        map[string]string
+		wantVersions []string
+		wantErr      string
+	}{
+		"next offset and next URL": {
+			pages: map[string]string{
+				"":                 `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.1.0"},{"version":"0.2.0"}]}],"meta":{"limit":2,"current_offset":0,"next_offset":2}}`,
+				"limit=2&offset=2": `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.3.0","deprecation":{"reason":"Use 0.4.0 instead."}}]}],"meta":{"limit":2,"current_offset":2,"next_url":"/v1/modules/paged/name/provider/versions?offset=4"}}`,
+				"offset=4":         `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.4.0"}]}],"meta":{"limit":2,"current_offset":4}}`,
+			},
+			wantVersions: []string{"0.1.0", "0.2.0", "0.3.0", "0.4.0"},
+		},
+		"next page on another host": {
+			pages: map[string]string{
+				"": `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.1.0"}]}],"meta":{"next_url":"https://example.net/versions?offset=1"}}`,
+			},
+			wantErr: "is not on the registry host",
+		},
+		"repeated page": {
+			pages: map[string]string{
+				"":         `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.1.0"}]}],"meta":{"next_url":"?offset=1"}}`,
+				"offset=1": `{"modules":[{"source":"paged/name/provider","versions":[{"version":"0.2.0"}]}],"meta":{"next_url":"?offset=1"}}`,
+			},
+			wantErr: "as the next page again",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/modules/paged/name/provider/versions" {
+					http.NotFound(w, r)
+					return
+				}
+				page, ok := tc.pages[r.URL.RawQuery]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, page)
+			}))
+			defer server.Close()
+
+			client := NewClient(test.Disco(server), nil)
+			modsrc, err := regsrc.ParseModuleSource("example.com/paged/name/provider")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.ModuleVersions(context.Background(), modsrc)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("wrong error\ngot:  %v\nwant: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(resp.Modules) != 1 {
+				t.Fatal("expected 1 module, got", len(resp.Modules))
+			}
+			var got []string
+			for _, v := range resp.Modules[0].Versions {
+				got = append(got, v.Version)
+			}
+			if !reflect.DeepEqual(got, tc.wantVersions) {
+				t.Fatalf("wrong versions\ngot:  %v\nwant: %v", got, tc.wantVersions)
+			}
+			if resp.Meta != nil {
+				t.Errorf("unexpected pagination metadata in the result: %#v", resp.Meta)
+			}
+			if d := resp.Modules[0].Versions[2].Deprecation; d == nil || d.Reason != "Use 0.4.0 instead." {
+				t.Errorf("wrong deprecation for 0.3.0: %#v", d)
+			}
+		})
+	}
+}
+
 func TestInvalidRegistry(t *testing.T) {
 	server := test.Registry()
 	defer server.Close()
@@ -10,6 +10,10 @@ package response
 // TF-042 for details on this format.
 type ModuleVersions struct {
 	Modules []*ModuleProviderVersions `json:"modules"`
+
+	// Meta describes the next page of versions, for registries that return
+	// the versions of a module in several pages.
+	Meta *PaginationMeta `json:"meta,omitempty"`
 }
 
 // ModuleProviderVersions is the response format for a single module instance,
@@ -25,6 +29,19 @@ type ModuleVersion struct {
 	Version    string              `json:"version"`
 	Root       VersionSubmodule    `json:"root"`
 	Submodules []*VersionSubmodule `json:"submodules"`
+
+	// Deprecation is set if the module author has deprecated this version.
+	Deprecation *ModuleVersionDeprecation `json:"deprecation,omitempty"`
+}
+
+// ModuleVersionDeprecation describes why a module version is deprecated.
+type ModuleVersionDeprecation struct {
+	// Reason is a message from the module author, such as which version to
+	// upgrade to instead.
+	Reason string `json:"reason,omitempty"`
+
+	// Link is an optional URL with more information.
+	Link string `json:"link,omitempty"`
 }
 
 // VersionSubmodule is the output metadata for a submodule within a given
@@ -0,0 +1,78 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package repl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/configs"
+)
+
+// Completions returns the references and function calls that are valid in
+// the current module and start with the given prefix, sorted.
+//
+// The references come from the declarations in the configuration, so they
+// don't include the attributes of resources.
+func (s *Session) Completions(prefix string) []string {
+	var candidates []string
+	if s.Config != nil {
+		if cfg := s.Config.DescendentForInstance(s.module); cfg != nil {
+			candidates = append(candidates, moduleSymbols(cfg)...)
+		}
+	}
+	if s.Scope != nil {
+		for name := range s.Scope.Functions() {
+			candidates = append(candidates, name+"(")
+		}
+	}
+
+	var ret []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			ret = append(ret, candidate)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// moduleSymbols returns the references that expressions in the given module
+// can use.
+func moduleSymbols(cfg *configs.Config) []string {
+	ret := []string{
+		"path.cwd",
+		"path.module",
+		"path.root",
+		"terraform.workspace",
+		"tofu.run_id",
+		"tofu.version",
+		"tofu.workspace",
+	}
+	mod := cfg.Module
+	for name := range mod.Variables {
+		ret = append(ret, "var."+name)
+	}
+	for name := range mod.Locals {
+		ret = append(ret, "local."+name)
+	}
+	for name, call := range mod.ModuleCalls {
+		ret = append(ret, "module."+name)
+		// The outputs of a module with count or for_each belong to each of
+		// its instances.
+		if child := cfg.Children[name]; child != nil && call.Count == nil && call.ForEach == nil {
+			for output := range child.Module.Outputs {
+				ret = append(ret, "module."+name+"."+output)
+			}
+		}
+	}
+	for _, resources := range []map[string]*configs.Resource{mod.ManagedResources, mod.DataResources, mod.EphemeralResources} {
+		for _, r := range resources {
+			ret = append(ret, r.Addr().String())
+		}
+	}
+	return ret
+}
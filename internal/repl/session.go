@@ -14,6 +14,8 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/lang/types"
@@ -24,6 +26,24 @@ import (
 type Session struct {
 	// Scope is the evaluation scope where expressions will be evaluated.
 	Scope *lang.Scope
+
+	// Config is the configuration that the expressions are evaluated
+	// against, which provides the completions. It may be nil.
+	Config *configs.Config
+
+	// ModuleScope returns the evaluation scope for the given module
+	// instance, which the ":module" command switches to. If it is nil then
+	// the ":module" command is not available.
+	ModuleScope func(addrs.ModuleInstance) (*lang.Scope, tfdiags.Diagnostics)
+
+	// module is the module instance that Scope belongs to.
+	module addrs.ModuleInstance
+}
+
+// Module returns the address of the module instance whose scope the
+// expressions are evaluated in.
+func (s *Session) Module() addrs.ModuleInstance {
+	return s.module
 }
 
 // Handle handles a single line of input from the REPL.
@@ -41,6 +61,9 @@ func (s *Session) Handle(line string) (string, bool, tfdiags.Diagnostics) {
 	case strings.TrimSpace(line) == "help":
 		ret := s.handleHelp()
 		return ret, false, nil
+	case strings.HasPrefix(strings.TrimSpace(line), ":module"):
+		ret, diags := s.handleModule(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ":module")))
+		return ret, false, diags
 	default:
 		ret, diags := s.handleEval(line)
 		return ret, false, diags
@@ -93,6 +116,43 @@ func (s *Session) handleEval(line string) (string, tfdiags.Diagnostics) {
 	return FormatValue(val, 0), diags
 }
 
+// handleModule switches to the scope of the module instance with the given
+// address, or to the root module if the address is empty.
+func (s *Session) handleModule(raw string) (string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if s.ModuleScope == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Module selection is not available",
+			"This console session cannot evaluate expressions in other modules.",
+		))
+		return "", diags
+	}
+
+	addr := addrs.RootModuleInstance
+	if raw != "" {
+		var addrDiags tfdiags.Diagnostics
+		addr, addrDiags = addrs.ParseModuleInstanceStr(raw)
+		diags = diags.Append(addrDiags)
+		if addrDiags.HasErrors() {
+			return "", diags
+		}
+	}
+
+	scope, scopeDiags := s.ModuleScope(addr)
+	diags = diags.Append(scopeDiags)
+	if scope == nil {
+		return "", diags
+	}
+	s.Scope = scope
+	s.module = addr
+
+	if addr.IsRoot() {
+		return "Evaluating expressions in the root module.", diags
+	}
+	return fmt.Sprintf("Evaluating expressions in %s.", addr), diags
+}
+
 func (s *Session) handleHelp() string {
 	text := `
 The OpenTofu console allows you to experiment with OpenTofu interpolations.
@@ -102,6 +162,11 @@ to the ID of "aws_instance.foo" if it exists in your state.
 
 Type in the interpolation to test and hit <enter> to see the result.
 
+To evaluate expressions in a child module instead, type ":module" followed by
+the address of one of its instances, such as ":module module.network[0]".
+Type ":module" alone to return to the root module. Press <tab> to complete the
+names of the objects and functions that the current module can use.
+
 To exit the console, type "exit" and hit <enter>, or use Control-C or
 Control-D.
 `
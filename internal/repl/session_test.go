@@ -9,6 +9,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -18,8 +19,10 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/initwd"
+	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
 
 	_ "github.com/opentofu/opentofu/internal/logging"
@@ -266,6 +269,51 @@ func TestSession_stateless(t *testing.T) {
 func testSession(t *testing.T, test testSessionTest) {
 	t.Helper()
 
+	s := testSessionForState(t, test.State)
+
+	// Test the inputs. We purposely don't use subtests here because
+	// the inputs don't represent subtests, but a sequence of stateful
+	// operations.
+	for _, input := range test.Inputs {
+		result, exit, diags := s.Handle(input.Input)
+		if exit != input.Exit {
+			t.Fatalf("incorrect 'exit' result %t; want %t", exit, input.Exit)
+		}
+		if (diags.HasErrors()) != input.Error {
+			t.Fatalf("%q: unexpected errors: %s", input.Input, diags.Err())
+		}
+		if diags.HasErrors() {
+			if input.ErrorContains != "" {
+				if !strings.Contains(diags.Err().Error(), input.ErrorContains) {
+					t.Fatalf(
+						"%q: diagnostics should contain: %q\n\n%s",
+						input.Input, input.ErrorContains, diags.Err(),
+					)
+				}
+			}
+
+			continue
+		}
+
+		if input.Output != "" && result != input.Output {
+			t.Fatalf(
+				"%q: expected:\n\n%s\n\ngot:\n\n%s",
+				input.Input, input.Output, result)
+		}
+
+		if input.OutputContains != "" && !strings.Contains(result, input.OutputContains) {
+			t.Fatalf(
+				"%q: expected contains:\n\n%s\n\ngot:\n\n%s",
+				input.Input, input.OutputContains, result)
+		}
+	}
+}
+
+// testSessionForState returns a session for the test configuration with the
+// given state, whose scope is the root module.
+func testSessionForState(t *testing.T, state *states.State) *Session {
+	t.Helper()
+
 	p := &tofu.MockProvider{}
 	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
 		ResourceTypes: map[string]providers.Schema{
@@ -280,7 +328,7 @@ func testSession(t *testing.T, test testSessionTest) {
 	}
 
 	config, _, cleanup, configDiags := initwd.LoadConfigForTests(t, "testdata/config-fixture", "tests")
-	defer cleanup()
+	t.Cleanup(cleanup)
 	if configDiags.HasErrors() {
 		t.Fatalf("unexpected problems loading config: %s", configDiags.Err())
 	}
@@ -295,7 +343,6 @@ func testSession(t *testing.T, test testSessionTest) {
 		t.Fatalf("failed to create context: %s", diags.Err())
 	}
 
-	state := test.State
 	if state == nil {
 		state = states.NewState()
 	}
@@ -308,45 +355,16 @@ func testSession(t *testing.T, test testSessionTest) {
 	scope.ConsoleMode = true
 
 	// Build the session
-	s := &Session{
-		Scope: scope,
-	}
-
-	// Test the inputs. We purposely don't use subtests here because
-	// the inputs don't represent subtests, but a sequence of stateful
-	// operations.
-	for _, input := range test.Inputs {
-		result, exit, diags := s.Handle(input.Input)
-		if exit != input.Exit {
-			t.Fatalf("incorrect 'exit' result %t; want %t", exit, input.Exit)
-		}
-		if (diags.HasErrors()) != input.Error {
-			t.Fatalf("%q: unexpected errors: %s", input.Input, diags.Err())
-		}
-		if diags.HasErrors() {
-			if input.ErrorContains != "" {
-				if !strings.Contains(diags.Err().Error(), input.ErrorContains) {
-					t.Fatalf(
-						"%q: diagnostics should contain: %q\n\n%s",
-						input.Input, input.ErrorContains, diags.Err(),
-					)
-				}
+	return &Session{
+		Scope:  scope,
+		Config: config,
+		ModuleScope: func(addr addrs.ModuleInstance) (*lang.Scope, tfdiags.Diagnostics) {
+			scope, diags := ctx.Eval(context.Background(), config, state, addr, &tofu.EvalOpts{})
+			if scope != nil {
+				scope.ConsoleMode = true
 			}
-
-			continue
-		}
-
-		if input.Output != "" && result != input.Output {
-			t.Fatalf(
-				"%q: expected:\n\n%s\n\ngot:\n\n%s",
-				input.Input, input.Output, result)
-		}
-
-		if input.OutputContains != "" && !strings.Contains(result, input.OutputContains) {
-			t.Fatalf(
-				"%q: expected contains:\n\n%s\n\ngot:\n\n%s",
-				input.Input, input.OutputContains, result)
-		}
+			return scope, diags
+		},
 	}
 }
 
@@ -369,6 +387,58 @@ type testSessionInput struct {
 	ErrorContains  string
 }
 
+func TestSession_module(t *testing.T) {
+	testSession(t, testSessionTest{
+		Inputs: []testSessionInput{
+			{
+				Input:  ":module module.module",
+				Output: "Evaluating expressions in module.module.",
+			},
+			{
+				Input:  "local.greeting",
+				Output: `"hello"`,
+			},
+			{
+				Input:  ":module",
+				Output: "Evaluating expressions in the root module.",
+			},
+			{
+				Input:         "local.greeting",
+				Error:         true,
+				ErrorContains: `A local value with the name "greeting" has not been declared.`,
+			},
+			{
+				Input:         ":module module.child",
+				Error:         true,
+				ErrorContains: "The configuration has no module module.child.",
+			},
+			{
+				Input:         `:module module.module["a"]`,
+				Error:         true,
+				ErrorContains: `it has no instance module.module["a"]`,
+			},
+		},
+	})
+}
+
+func TestSession_completions(t *testing.T) {
+	s := testSessionForState(t, nil)
+
+	if diff := cmp.Diff([]string{"module.module", "test_instance.foo"}, append(s.Completions("mod"), s.Completions("test_")...)); diff != "" {
+		t.Errorf("wrong completions\n%s", diff)
+	}
+	if got := s.Completions("upp"); !slices.Contains(got, "upper(") {
+		t.Errorf("missing function completion in %#v", got)
+	}
+
+	if _, _, diags := s.Handle(":module module.module"); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if diff := cmp.Diff([]string{"local.greeting"}, s.Completions("local.")); diff != "" {
+		t.Errorf("wrong completions in the child module\n%s", diff)
+	}
+}
+
 func TestTypeString(t *testing.T) {
 	tests := []struct {
 		Input cty.Value
@@ -0,0 +1,78 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// External is a Store implemented by an external program, such as a wrapper
+// around a secrets manager.
+//
+// To save a value, OpenTofu runs the program with the additional arguments
+// "put" and the key, writing the value to its standard input. The program
+// must write the reference to the saved value to its standard output.
+//
+// To retrieve a value, OpenTofu runs the program with the additional
+// arguments "get" and the reference, and the program must write the value to
+// its standard output.
+//
+// To delete a value that OpenTofu no longer needs, OpenTofu runs the program
+// with the additional arguments "delete" and the reference. The program must
+// succeed if the value was already deleted.
+//
+// In all cases the program must exit with status zero. Anything the program
+// writes to its standard error is included in the error OpenTofu returns if
+// the program fails.
+type External struct {
+	// Command is the program to run, and Args are the arguments to run it
+	// with before the arguments for each operation.
+	Command string
+	Args    []string
+}
+
+var _ Store = (*External)(nil)
+
+func (e *External) Put(key string, value []byte) (string, error) {
+	out, err := e.run(value, "put", key)
+	if err != nil {
+		return "", err
+	}
+	ref := strings.TrimSpace(string(out))
+	if ref == "" {
+		return "", fmt.Errorf("the secrets program %s returned an empty reference", e.Command)
+	}
+	return ref, nil
+}
+
+func (e *External) Get(ref string) ([]byte, error) {
+	return e.run(nil, "get", ref)
+}
+
+func (e *External) Delete(ref string) error {
+	_, err := e.run(nil, "delete", ref)
+	return err
+}
+
+func (e *External) run(input []byte, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(e.Command, append(append([]string(nil), e.Args...), args...)...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := fmt.Sprintf("the secrets program %s failed: %s", e.Command, err)
+		if errOutput := strings.TrimSpace(stderr.String()); errOutput != "" {
+			msg += "\n\n" + errOutput
+		}
+		return nil, errors.New(msg)
+	}
+	return stdout.Bytes(), nil
+}
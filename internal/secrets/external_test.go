@@ -0,0 +1,85 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExternal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test stores are shell scripts")
+	}
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "store")
+	script := `#!/bin/sh
+case "$2" in
+put)
+  name=$(printf '%s' "$3" | tr '/' '_')
+  cat > "$1/$name"
+  echo "ref-$name"
+  ;;
+get)
+  cat "$1/${3#ref-}" || exit 1
+  ;;
+delete)
+  rm -f "$1/${3#ref-}"
+  ;;
+*)
+  echo "unsupported operation $2" >&2
+  exit 1
+  ;;
+esac
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	valuesDir := filepath.Join(dir, "values")
+	if err := os.Mkdir(valuesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &External{
+		Command: scriptPath,
+		Args:    []string{valuesDir},
+	}
+
+	ref, err := store.Put("lineage/test_instance.foo.password", []byte(`"hunter2"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ref, "ref-lineage_test_instance.foo.password"; got != want {
+		t.Fatalf("wrong reference %q; want %q", got, want)
+	}
+
+	value, err := store.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(value), `"hunter2"`; got != want {
+		t.Fatalf("wrong value %s; want %s", got, want)
+	}
+
+	if err := store.Delete(ref); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ref); err == nil {
+		t.Fatal("the deleted value can still be retrieved")
+	}
+
+	_, err = store.Get("ref-missing")
+	if err == nil {
+		t.Fatal("expected an error for a missing value")
+	}
+	if got, want := err.Error(), "the secrets program "+scriptPath+" failed"; !strings.HasPrefix(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s...", got, want)
+	}
+}
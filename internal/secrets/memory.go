@@ -0,0 +1,68 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps the values in memory, for use in tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+const memoryRefPrefix = "memory:"
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		values: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) Put(key string, value []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = append([]byte(nil), value...)
+	return memoryRefPrefix + key, nil
+}
+
+func (s *MemoryStore) Get(ref string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := strings.CutPrefix(ref, memoryRefPrefix)
+	if !ok {
+		return nil, fmt.Errorf("invalid reference %q", ref)
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return nil, fmt.Errorf("no value for reference %q", ref)
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *MemoryStore) Delete(ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := strings.CutPrefix(ref, memoryRefPrefix)
+	if !ok {
+		return fmt.Errorf("invalid reference %q", ref)
+	}
+	delete(s.values, key)
+	return nil
+}
+
+// Len returns the number of values in the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.values)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package secrets implements an extension point for storing the values that
+// the configuration marks as secret outside of the state, so that the state
+// only records references to them.
+//
+// Stores are usually external programs configured in the CLI configuration,
+// which OpenTofu runs using the protocol implemented by External.
+package secrets
+
+import (
+	"errors"
+	"sync"
+)
+
+// Store is the interface implemented by secrets stores.
+type Store interface {
+	// Put saves the given value under the given key, replacing any value
+	// previously saved under the same key, and returns a reference that Get
+	// accepts to retrieve the value again.
+	//
+	// The key identifies the attribute that the value belongs to, so that
+	// writing the same state repeatedly doesn't accumulate values in the
+	// store.
+	Put(key string, value []byte) (string, error)
+
+	// Get returns the value that the given reference, previously returned
+	// by Put, refers to.
+	Get(ref string) ([]byte, error)
+
+	// Delete removes the value that the given reference, previously returned
+	// by Put, refers to. Deleting a value that was already removed is not an
+	// error.
+	Delete(ref string) error
+}
+
+// ErrNoStore is returned by the Unavailable store.
+var ErrNoStore = errors.New("no secrets store is configured")
+
+// Unavailable is the Store that CurrentStore returns when no store is
+// configured. All of its methods return ErrNoStore, so that secret values are
+// never saved in cleartext by accident.
+var Unavailable Store = unavailableStore{}
+
+type unavailableStore struct{}
+
+func (unavailableStore) Put(string, []byte) (string, error) {
+	return "", ErrNoStore
+}
+
+func (unavailableStore) Get(string) ([]byte, error) {
+	return nil, ErrNoStore
+}
+
+func (unavailableStore) Delete(string) error {
+	return ErrNoStore
+}
+
+var (
+	currentStore Store
+	currentMu    sync.Mutex
+)
+
+// SetStore sets the store that secret values are saved in when a state is
+// written, and retrieved from when it is read. The CLI calls it once at
+// startup with the store configured in the CLI configuration.
+//
+// Passing nil removes any previously set store.
+func SetStore(store Store) {
+	currentMu.Lock()
+	currentStore = store
+	currentMu.Unlock()
+}
+
+// CurrentStore returns the store set with SetStore, or Unavailable if none
+// is set.
+func CurrentStore() Store {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if currentStore == nil {
+		return Unavailable
+	}
+	return currentStore
+}
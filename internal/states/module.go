@@ -169,6 +169,7 @@ func (ms *Module) SetResourceInstanceDeposed(addr addrs.ResourceInstance, key De
 		is.Deposed[key] = obj
 	} else {
 		delete(is.Deposed, key)
+		delete(is.DeposedAt, key)
 	}
 
 	if !is.HasObjects() {
@@ -212,6 +213,7 @@ func (ms *Module) ForgetResourceInstanceDeposed(addr addrs.ResourceInstance, key
 		return
 	}
 	delete(is.Deposed, key)
+	delete(is.DeposedAt, key)
 
 	if !is.HasObjects() {
 		// If we have no objects at all then we'll clean up.
@@ -254,6 +256,7 @@ func (ms *Module) maybeRestoreResourceInstanceDeposed(addr addrs.ResourceInstanc
 	}
 	is.Current = is.Deposed[key]
 	delete(is.Deposed, key)
+	delete(is.DeposedAt, key)
 	return true
 }
 
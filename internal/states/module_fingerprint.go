@@ -0,0 +1,35 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package states
+
+import (
+	"maps"
+)
+
+// ModuleFingerprint summarizes everything that affects the plan for the
+// resources in a module instance's subtree, as recorded by an incremental
+// plan for the module instances whose subtrees had no changes.
+//
+// The fingerprints are stored in the state so that the next incremental plan
+// against the state, possibly on another machine, can skip the subtrees that
+// are still unchanged.
+type ModuleFingerprint struct {
+	// Inputs is a hash of the configuration, input variable values, provider
+	// versions and prior state of the subtree.
+	Inputs string
+
+	// Providers maps each provider instance used by resources in the
+	// subtree to a hash of its configuration.
+	Providers map[string]string
+}
+
+// DeepCopy returns a copy of the receiver that shares no memory with it.
+func (f ModuleFingerprint) DeepCopy() ModuleFingerprint {
+	return ModuleFingerprint{
+		Inputs:    f.Inputs,
+		Providers: maps.Clone(f.Providers),
+	}
+}
@@ -6,6 +6,8 @@
 package remote
 
 import (
+	"errors"
+
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 )
 
@@ -41,6 +43,32 @@ type OptionalClientLocker interface {
 	IsLockingEnabled() bool
 }
 
+// ClientVersionReader is an optional interface that allows a remote state
+// backend to read earlier versions of the state, for storage that keeps
+// them. GetVersion returns nil if there's no matching version.
+type ClientVersionReader interface {
+	Client
+	GetVersion(sel statemgr.StateVersionSelector) (*Payload, error)
+}
+
+// ClientConditionalWriter is an optional interface that allows a remote
+// state backend to write a snapshot only if nobody else wrote one since it
+// was last read, for storage that supports conditional writes. State uses it
+// when merging concurrent writes, so that no write can slip in between
+// reading the snapshot to merge with and writing the merged snapshot.
+type ClientConditionalWriter interface {
+	Client
+
+	// PutIfUnchanged is like Put, but returns ErrConcurrentWrite without
+	// writing anything if the stored snapshot was changed since the client
+	// last read or wrote it.
+	PutIfUnchanged([]byte) error
+}
+
+// ErrConcurrentWrite is returned by ClientConditionalWriter.PutIfUnchanged
+// if the stored snapshot was changed by someone else.
+var ErrConcurrentWrite = errors.New("the remote state was changed concurrently")
+
 // Payload is the return value from the remote state storage.
 type Payload struct {
 	MD5  []byte
@@ -83,6 +83,38 @@ func (c *mockClient) appendLog(method string, content []byte) {
 	c.log = append(c.log, mockClientRequest{method, contentVal})
 }
 
+// mockConditionalClient is like mockClient, but also implements
+// ClientConditionalWriter. beforePut is called once before the next
+// conditional write, to simulate a concurrent writer.
+type mockConditionalClient struct {
+	mockClient
+	version, readVersion int
+	beforePut            func()
+}
+
+func (c *mockConditionalClient) Get() (*Payload, error) {
+	c.readVersion = c.version
+	return c.mockClient.Get()
+}
+
+func (c *mockConditionalClient) Put(data []byte) error {
+	c.version++
+	c.readVersion = c.version
+	return c.mockClient.Put(data)
+}
+
+func (c *mockConditionalClient) PutIfUnchanged(data []byte) error {
+	if f := c.beforePut; f != nil {
+		c.beforePut = nil
+		f()
+	}
+	if c.version != c.readVersion {
+		c.appendLog("Conflicting Put", data)
+		return ErrConcurrentWrite
+	}
+	return c.Put(data)
+}
+
 // mockClientForcePusher is like mockClient, but also implements
 // EnableForcePush, allowing testing for this behavior
 type mockClientForcePusher struct {
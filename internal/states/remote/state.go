@@ -7,9 +7,11 @@ package remote
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	uuid "github.com/hashicorp/go-uuid"
 
@@ -51,10 +53,25 @@ type State struct {
 	// progress. Otherwise (by default) it will accept persistent snapshots
 	// using the default rules defined in the local backend.
 	disableIntermediateSnapshots bool
+
+	// If this is set then PersistState merges the changes of any concurrent
+	// writer into the snapshot it persists, using mergeBase as the common
+	// ancestor. mergeBase is the snapshot this state manager was last given
+	// by its own writer and persisted, which can differ from readState after
+	// a merge.
+	mergeConcurrentWrites bool
+	mergeBase             *states.State
 }
 
+// maxConcurrentWriteAttempts is the number of times that PersistState tries
+// to merge concurrent writes and conditionally write the merged snapshot,
+// for clients that implement ClientConditionalWriter.
+const maxConcurrentWriteAttempts = 5
+
 var _ statemgr.Full = (*State)(nil)
 var _ statemgr.Migrator = (*State)(nil)
+var _ statemgr.ConcurrentWriteMerger = (*State)(nil)
+var _ statemgr.VersionReader = (*State)(nil)
 var _ local.IntermediateStateConditionalPersister = (*State)(nil)
 
 func NewState(client Client, enc encryption.StateEncryption) *State {
@@ -68,6 +85,29 @@ func (s *State) DisableIntermediateSnapshots() {
 	s.disableIntermediateSnapshots = true
 }
 
+// EnableConcurrentWriteMerging is an implementation of
+// statemgr.ConcurrentWriteMerger.
+func (s *State) EnableConcurrentWriteMerging() {
+	s.mergeConcurrentWrites = true
+}
+
+// StateVersion is an implementation of statemgr.VersionReader, for clients
+// that implement ClientVersionReader.
+func (s *State) StateVersion(sel statemgr.StateVersionSelector) (*statefile.File, error) {
+	c, ok := s.Client.(ClientVersionReader)
+	if !ok {
+		return nil, statemgr.ErrStateVersionsNotSupported
+	}
+	payload, err := c.GetVersion(sel)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, statemgr.ErrStateVersionNotFound
+	}
+	return statefile.Read(bytes.NewReader(payload.Data), s.encryption)
+}
+
 // statemgr.Reader impl.
 func (s *State) State() *states.State {
 	s.mu.Lock()
@@ -137,6 +177,10 @@ func (s *State) WriteStateForMigration(f *statefile.File, force bool) error {
 	s.lineage = f.Lineage
 	s.serial = f.Serial
 
+	// A migrated snapshot replaces whatever is stored, so there's nothing
+	// to merge it with.
+	s.mergeBase = nil
+
 	return nil
 }
 
@@ -151,7 +195,9 @@ func (s *State) RefreshState() error {
 // that we can make internal calls to it from methods that are already holding
 // the s.mu lock.
 func (s *State) refreshState() error {
+	start := time.Now()
 	payload, err := s.Client.Get()
+	recordStateOperation("get", start, err)
 	if err != nil {
 		return err
 	}
@@ -159,6 +205,7 @@ func (s *State) refreshState() error {
 	// no remote state is OK
 	if payload == nil {
 		s.readState = nil
+		s.mergeBase = nil
 		s.lineage = ""
 		s.serial = 0
 		return nil
@@ -179,6 +226,9 @@ func (s *State) refreshState() error {
 	s.readSerial = stateFile.Serial
 	s.readEncryption = stateFile.EncryptionStatus
 	s.readState = s.state.DeepCopy()
+	if s.mergeConcurrentWrites {
+		s.mergeBase = s.state.DeepCopy()
+	}
 	return nil
 }
 
@@ -190,6 +240,10 @@ func (s *State) PersistState(schemas *tofu.Schemas) error {
 	log.Printf("[DEBUG] states/remote: state read serial is: %d; serial is: %d", s.readSerial, s.serial)
 	log.Printf("[DEBUG] states/remote: state read lineage is: %s; lineage is: %s", s.readLineage, s.lineage)
 
+	// ours is the snapshot given by our own writer, if it is about to be
+	// merged with the changes of a concurrent writer.
+	var ours *states.State
+
 	if s.readState != nil {
 		lineageUnchanged := s.readLineage != "" && s.lineage == s.readLineage
 		serialUnchanged := s.readSerial != 0 && s.serial == s.readSerial
@@ -199,6 +253,13 @@ func (s *State) PersistState(schemas *tofu.Schemas) error {
 			return nil
 		}
 		s.serial++
+
+		if s.mergeConcurrentWrites && s.mergeBase != nil {
+			ours = s.state.DeepCopy()
+			if err := s.mergeConcurrentWrite(); err != nil {
+				return err
+			}
+		}
 	} else {
 		// We might be writing a new state altogether, but before we do that
 		// we'll check to make sure there isn't already a snapshot present
@@ -219,17 +280,45 @@ func (s *State) PersistState(schemas *tofu.Schemas) error {
 		}
 	}
 
-	f := statefile.New(s.state, s.lineage, s.serial)
-
-	var buf bytes.Buffer
-	err := statefile.Write(f, &buf, s.encryption)
-	if err != nil {
-		return err
+	// Without conditional writes, someone else can still write between the
+	// merge and our write, in which case their changes are lost.
+	conditional, _ := s.Client.(ClientConditionalWriter)
+	if ours == nil {
+		conditional = nil
+	} else if conditional == nil {
+		log.Printf("[WARN] states/remote: the backend doesn't support conditional writes, so changes written concurrently with this one can still be lost")
 	}
 
-	err = s.Client.Put(buf.Bytes())
-	if err != nil {
-		return err
+	for attempt := 1; ; attempt++ {
+		f := statefile.New(s.state, s.lineage, s.serial)
+
+		var buf bytes.Buffer
+		err := statefile.Write(f, &buf, s.encryption)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		if conditional != nil {
+			err = conditional.PutIfUnchanged(buf.Bytes())
+		} else {
+			err = s.Client.Put(buf.Bytes())
+		}
+		recordStateOperation("put", start, err)
+		if conditional == nil || !errors.Is(err, ErrConcurrentWrite) || attempt == maxConcurrentWriteAttempts {
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		// Someone else wrote to the state after we merged their earlier
+		// changes, so we merge our own snapshot with theirs again.
+		log.Printf("[DEBUG] states/remote: remote state changed while merging concurrent changes, merging again")
+		s.state = ours.DeepCopy()
+		if err := s.mergeConcurrentWrite(); err != nil {
+			return err
+		}
 	}
 
 	// After we've successfully persisted, what we just wrote is our new
@@ -242,6 +331,55 @@ func (s *State) PersistState(schemas *tofu.Schemas) error {
 	s.readLineage = s.lineage
 	s.readEncryption = encryption.StatusSatisfied
 	s.readSerial = s.serial
+	if s.mergeConcurrentWrites {
+		if ours == nil {
+			ours = s.state
+		}
+		s.mergeBase = ours.DeepCopy()
+	}
+	return nil
+}
+
+// mergeConcurrentWrite checks whether the remote snapshot was changed since
+// it was last read or written by this state manager and, if so, merges those
+// changes into s.state and advances s.serial past the remote serial. It must
+// be called with s.mu held.
+func (s *State) mergeConcurrentWrite() error {
+	start := time.Now()
+	payload, err := s.Client.Get()
+	recordStateOperation("get", start, err)
+	if err != nil {
+		return fmt.Errorf("failed checking for concurrent changes to remote state: %w", err)
+	}
+	if payload == nil {
+		// The snapshot was deleted, so there's nothing left to merge with.
+		return nil
+	}
+
+	theirs, err := statefile.Read(bytes.NewReader(payload.Data), s.encryption)
+	if err != nil {
+		return fmt.Errorf("failed checking for concurrent changes to remote state: %w", err)
+	}
+	if theirs.Lineage != s.readLineage {
+		return fmt.Errorf("the remote state was replaced by a state with a different lineage (%q) while this operation was running", theirs.Lineage)
+	}
+	if theirs.Serial == s.readSerial && statefile.StatesMarshalEqual(s.mergeBase, s.readState) {
+		// Nobody else wrote to the state since we last did, and we haven't
+		// merged any changes that our writer doesn't know about.
+		return nil
+	}
+
+	merged, err := statemgr.MergeConcurrentChanges(s.mergeBase, s.state, theirs.State)
+	if err != nil {
+		return err
+	}
+	if theirs.Serial != s.readSerial {
+		log.Printf("[INFO] states/remote: merged concurrent changes from remote state serial %d", theirs.Serial)
+	}
+	s.state = merged
+	if theirs.Serial >= s.serial {
+		s.serial = theirs.Serial + 1
+	}
 	return nil
 }
 
@@ -263,7 +401,10 @@ func (s *State) Lock(info *statemgr.LockInfo) (string, error) {
 	}
 
 	if c, ok := s.Client.(ClientLocker); ok {
-		return c.Lock(info)
+		start := time.Now()
+		id, err := c.Lock(info)
+		recordStateOperation("lock", start, err)
+		return id, err
 	}
 	return "", nil
 }
@@ -278,7 +419,10 @@ func (s *State) Unlock(id string) error {
 	}
 
 	if c, ok := s.Client.(ClientLocker); ok {
-		return c.Unlock(id)
+		start := time.Now()
+		err := c.Unlock(id)
+		recordStateOperation("unlock", start, err)
+		return err
 	}
 	return nil
 }
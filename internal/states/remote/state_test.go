@@ -6,6 +6,9 @@
 package remote
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 	"testing"
@@ -848,3 +851,178 @@ func TestState_IsLockingEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestState_concurrentWriteMerging(t *testing.T) {
+	setID := func(s *states.State, name, id string) {
+		s.RootModule().SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_thing",
+				Name: name,
+			}.Instance(addrs.NoKey),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"` + id + `"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	}
+	ids := func(t *testing.T, client *mockClient) map[string]string {
+		t.Helper()
+		f, err := statefile.Read(bytes.NewReader(client.current), encryption.StateEncryptionDisabled())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret := make(map[string]string)
+		for _, rs := range f.State.RootModule().Resources {
+			var attrs map[string]string
+			if err := json.Unmarshal(rs.Instances[addrs.NoKey].Current.AttrsJSON, &attrs); err != nil {
+				t.Fatal(err)
+			}
+			ret[rs.Addr.Resource.Name] = attrs["id"]
+		}
+		return ret
+	}
+	newManagers := func(t *testing.T) (*mockClient, *State, *State) {
+		t.Helper()
+		initial := states.NewState()
+		setID(initial, "shared", "original")
+		var buf bytes.Buffer
+		if err := statefile.Write(statefile.New(initial, "mock-lineage", 1), &buf, encryption.StateEncryptionDisabled()); err != nil {
+			t.Fatal(err)
+		}
+		client := &mockClient{current: buf.Bytes()}
+
+		a := NewState(client, encryption.StateEncryptionDisabled())
+		a.EnableConcurrentWriteMerging()
+		b := NewState(client, encryption.StateEncryptionDisabled())
+		b.EnableConcurrentWriteMerging()
+		for _, mgr := range []*State{a, b} {
+			if err := mgr.RefreshState(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return client, a, b
+	}
+
+	t.Run("disjoint resources", func(t *testing.T) {
+		client, a, b := newManagers(t)
+
+		aState := a.State()
+		setID(aState, "a", "from-a")
+		if err := a.WriteState(aState); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.PersistState(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		bState := b.State()
+		setID(bState, "b", "from-b")
+		if err := b.WriteState(bState); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.PersistState(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// A later snapshot from the same writer, which still doesn't know
+		// about the changes made by the other one.
+		setID(bState, "b", "from-b-again")
+		if err := b.WriteState(bState); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.PersistState(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{"shared": "original", "a": "from-a", "b": "from-b-again"}
+		if diff := cmp.Diff(want, ids(t, client)); diff != "" {
+			t.Errorf("wrong persisted resources\n%s", diff)
+		}
+		if got, want := b.StateSnapshotMeta().Serial, uint64(4); got != want {
+			t.Errorf("wrong serial %d; want %d", got, want)
+		}
+	})
+
+	t.Run("overlapping resources", func(t *testing.T) {
+		client, a, b := newManagers(t)
+
+		aState := a.State()
+		setID(aState, "shared", "from-a")
+		if err := a.WriteState(aState); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.PersistState(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		bState := b.State()
+		setID(bState, "shared", "from-b")
+		if err := b.WriteState(bState); err != nil {
+			t.Fatal(err)
+		}
+		err := b.PersistState(nil)
+		if err == nil {
+			t.Fatal("unexpected success")
+		}
+		var conflict *statemgr.MergeConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("wrong error type %T: %s", err, err)
+		}
+		if got, want := len(conflict.Resources), 1; got != want {
+			t.Fatalf("wrong number of conflicts %d; want %d", got, want)
+		}
+		if got, want := conflict.Resources[0].String(), "test_thing.shared"; got != want {
+			t.Errorf("wrong conflict %q; want %q", got, want)
+		}
+
+		want := map[string]string{"shared": "from-a"}
+		if diff := cmp.Diff(want, ids(t, client)); diff != "" {
+			t.Errorf("wrong persisted resources\n%s", diff)
+		}
+	})
+
+	t.Run("conditional write", func(t *testing.T) {
+		client, a, _ := newManagers(t)
+		conditional := &mockConditionalClient{mockClient: *client}
+		b := NewState(conditional, encryption.StateEncryptionDisabled())
+		b.EnableConcurrentWriteMerging()
+		if err := b.RefreshState(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The other writer persists its snapshot after b checked for
+		// concurrent changes, but before b writes its merged snapshot.
+		a.Client = &conditional.mockClient
+		conditional.beforePut = func() {
+			aState := a.State()
+			setID(aState, "a", "from-a")
+			if err := a.WriteState(aState); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.PersistState(nil); err != nil {
+				t.Fatal(err)
+			}
+			conditional.version++
+		}
+
+		bState := b.State()
+		setID(bState, "b", "from-b")
+		if err := b.WriteState(bState); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.PersistState(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{"shared": "original", "a": "from-a", "b": "from-b"}
+		if diff := cmp.Diff(want, ids(t, &conditional.mockClient)); diff != "" {
+			t.Errorf("wrong persisted resources\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package remote
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// stateOperationDuration records how long each operation on the remote
+// state storage takes.
+var stateOperationDuration metric.Float64Histogram
+
+func init() {
+	meter := otel.Meter("github.com/opentofu/opentofu/internal/states/remote")
+	var err error
+	stateOperationDuration, err = meter.Float64Histogram(
+		"opentofu.state.operation.duration",
+		metric.WithDescription("Duration of operations on remote state storage."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		// Can only fail if the instrument definition above is invalid.
+		panic(err)
+	}
+}
+
+// recordStateOperation records the duration of an operation, such as "get"
+// or "lock", that started at the given time and has just completed with
+// the given error.
+func recordStateOperation(operation string, start time.Time, err error) {
+	stateOperationDuration.Record(context.Background(), float64(time.Since(start))/float64(time.Millisecond), metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.Bool("error", err != nil),
+	))
+}
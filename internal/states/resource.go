@@ -71,10 +71,20 @@ type ResourceInstance struct {
 	// lifecycle mode.
 	Deposed map[DeposedKey]*ResourceInstanceObjectSrc
 
+	// DeposedAt records when each of the objects in Deposed was deposed, if
+	// known. Objects deposed by earlier versions of OpenTofu have no entry.
+	DeposedAt map[DeposedKey]time.Time
+
 	// ProviderKey, in combination with Resource.ProviderConfig, represents
 	// the resource instance's provider configuration. This is only set
 	// when using provider iteration on resources or modules
 	ProviderKey addrs.InstanceKey
+
+	// Note is an optional note about the resource instance, set either by
+	// the "note" lifecycle argument or by "tofu state annotate", which is
+	// shown whenever the instance appears in a plan. It's kept for as long
+	// as the instance has objects.
+	Note string
 }
 
 // NewResourceInstance constructs and returns a new ResourceInstance, ready to
@@ -130,6 +140,10 @@ func (i *ResourceInstance) deposeCurrentObject(forceKey DeposedKey) DeposedKey {
 	}
 	i.Deposed[key] = i.Current
 	i.Current = nil
+	if i.DeposedAt == nil {
+		i.DeposedAt = make(map[DeposedKey]time.Time)
+	}
+	i.DeposedAt[key] = time.Now().UTC()
 	return key
 }
 
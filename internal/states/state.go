@@ -43,6 +43,16 @@ type State struct {
 	// created by a version of OpenTofu that didn't yet support checks
 	// then this field will be nil.
 	CheckResults *CheckResults
+
+	// Workspace contains the metadata of the workspace that the state
+	// belongs to, or is nil if the workspace has no metadata.
+	Workspace *WorkspaceMetadata
+
+	// ModuleFingerprints are the fingerprints of the module instances whose
+	// subtrees had no changes in the incremental plan that produced this
+	// state, by module instance address. It's nil if the state wasn't
+	// produced by an incremental plan.
+	ModuleFingerprints map[string]ModuleFingerprint
 }
 
 // NewState constructs a minimal empty state, containing an empty root module.
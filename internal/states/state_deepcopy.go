@@ -6,6 +6,8 @@
 package states
 
 import (
+	"time"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -33,9 +35,18 @@ func (s *State) DeepCopy() *State {
 	for k, m := range s.Modules {
 		modules[k] = m.DeepCopy()
 	}
+	var fingerprints map[string]ModuleFingerprint
+	if s.ModuleFingerprints != nil {
+		fingerprints = make(map[string]ModuleFingerprint, len(s.ModuleFingerprints))
+		for k, f := range s.ModuleFingerprints {
+			fingerprints[k] = f.DeepCopy()
+		}
+	}
 	return &State{
-		Modules:      modules,
-		CheckResults: s.CheckResults.DeepCopy(),
+		Modules:            modules,
+		CheckResults:       s.CheckResults.DeepCopy(),
+		Workspace:          s.Workspace.DeepCopy(),
+		ModuleFingerprints: fingerprints,
 	}
 }
 
@@ -117,10 +128,20 @@ func (i *ResourceInstance) DeepCopy() *ResourceInstance {
 		deposed[k] = obj.DeepCopy()
 	}
 
+	var deposedAt map[DeposedKey]time.Time
+	if i.DeposedAt != nil {
+		deposedAt = make(map[DeposedKey]time.Time, len(i.DeposedAt))
+		for k, t := range i.DeposedAt {
+			deposedAt[k] = t
+		}
+	}
+
 	return &ResourceInstance{
 		Current:     i.Current.DeepCopy(),
 		Deposed:     deposed,
+		DeposedAt:   deposedAt,
 		ProviderKey: i.ProviderKey,
+		Note:        i.Note,
 	}
 }
 
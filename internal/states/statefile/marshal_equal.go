@@ -10,6 +10,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/states"
+	tfversion "github.com/opentofu/opentofu/version"
 )
 
 // StatesMarshalEqual returns true if and only if the two given states have
@@ -30,16 +31,17 @@ func StatesMarshalEqual(a, b *states.State) bool {
 
 	// We write here some temporary files that have no header information
 	// populated, thus ensuring that we're only comparing the state itself
-	// and not any metadata.
-	err := Write(&File{State: a}, &aBuf, encryption.StateEncryptionDisabled())
-	if err != nil {
+	// and not any metadata. The secret values are written in cleartext,
+	// because they must be compared too and the result is never persisted.
+	aFile := &File{State: a, TerraformVersion: tfversion.SemVer}
+	if diags := writeStateV4(aFile, &aBuf, encryption.StateEncryptionDisabled(), nil); diags.HasErrors() {
 		// Should never happen, because we're writing to an in-memory buffer
-		panic(err)
+		panic(diags.Err())
 	}
-	err = Write(&File{State: b}, &bBuf, encryption.StateEncryptionDisabled())
-	if err != nil {
+	bFile := &File{State: b, TerraformVersion: tfversion.SemVer}
+	if diags := writeStateV4(bFile, &bBuf, encryption.StateEncryptionDisabled(), nil); diags.HasErrors() {
 		// Should never happen, because we're writing to an in-memory buffer
-		panic(err)
+		panic(diags.Err())
 	}
 
 	return bytes.Equal(aBuf.Bytes(), bBuf.Bytes())
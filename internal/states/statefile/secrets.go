@@ -0,0 +1,315 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package statefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/secrets"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// secretPaths returns the paths in the given marks that are marked as
+// secret.
+func secretPaths(pvm []cty.PathValueMarks) []cty.Path {
+	var ret []cty.Path
+	for _, pm := range pvm {
+		if _, secret := pm.Marks[marks.Secret]; secret {
+			ret = append(ret, pm.Path)
+		}
+	}
+	return ret
+}
+
+// redactSecretAttrs replaces the values at the given paths of the given
+// attributes JSON with references to the values saved in the given store,
+// under keys that start with the given prefix.
+//
+// A path that leads into a set can't be followed in the JSON representation,
+// so the whole set is saved in the store instead. The result includes the
+// paths of the values that were replaced, which are the paths to record in
+// the state.
+func redactSecretAttrs(attrsJSON []byte, paths []cty.Path, store secrets.Store, keyPrefix string) ([]byte, []cty.Path, error) {
+	attrs, err := decodeAttrsJSON(attrsJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// We redact the shortest paths first, so that any paths inside the
+	// values they redact can be skipped.
+	paths = append([]cty.Path(nil), paths...)
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) < len(paths[j])
+	})
+
+	var redacted []cty.Path
+	for _, path := range paths {
+		if hasRedactedPrefix(path, redacted) {
+			continue
+		}
+		parent, step, path := jsonPathTarget(attrs, path)
+		if parent == nil || hasRedactedPrefix(path, redacted) {
+			continue
+		}
+		value := step.get(parent)
+		if value == nil {
+			// A null value doesn't reveal anything.
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		ref, err := store.Put(keyPrefix+tfdiags.FormatCtyPath(path), raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save the secret value of %s: %w", tfdiags.FormatCtyPath(path), err)
+		}
+		step.set(parent, ref)
+		redacted = append(redacted, path)
+	}
+
+	ret, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ret, redacted, nil
+}
+
+// restoreSecretAttrs reverses redactSecretAttrs, replacing the references
+// at the given paths of the given attributes JSON with the values they refer
+// to in the given store.
+func restoreSecretAttrs(attrsJSON []byte, paths []cty.Path, store secrets.Store) ([]byte, error) {
+	attrs, err := decodeAttrsJSON(attrsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		parent, step, _ := jsonPathTarget(attrs, path)
+		if parent == nil {
+			return nil, fmt.Errorf("no secret value at %s", tfdiags.FormatCtyPath(path))
+		}
+		ref, ok := step.get(parent).(string)
+		if !ok {
+			return nil, fmt.Errorf("the secret value at %s is not a reference", tfdiags.FormatCtyPath(path))
+		}
+		raw, err := store.Get(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the secret value of %s: %w", tfdiags.FormatCtyPath(path), err)
+		}
+		value, err := decodeAttrsJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("the secret value of %s is invalid: %w", tfdiags.FormatCtyPath(path), err)
+		}
+		step.set(parent, value)
+	}
+
+	return json.Marshal(attrs)
+}
+
+// hasRedactedPrefix returns true if one of the given redacted paths is a
+// prefix of the given path.
+func hasRedactedPrefix(path cty.Path, redacted []cty.Path) bool {
+	for _, prefix := range redacted {
+		if len(prefix) <= len(path) && prefix.Equals(path[:len(prefix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeAttrsJSON(src []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+	var ret interface{}
+	if err := dec.Decode(&ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// jsonStep is a step from a decoded JSON object or array to one of its
+// elements.
+type jsonStep struct {
+	key   string
+	index int
+}
+
+func (s jsonStep) get(parent interface{}) interface{} {
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		return parent[s.key]
+	case []interface{}:
+		return parent[s.index]
+	}
+	return nil
+}
+
+func (s jsonStep) set(parent interface{}, value interface{}) {
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		parent[s.key] = value
+	case []interface{}:
+		parent[s.index] = value
+	}
+}
+
+// jsonPathTarget follows the given path in the given decoded JSON value as
+// far as possible, and returns the container of the value at the end of the
+// path that could be followed, the step from the container to the value,
+// and that path. It returns a nil container if not even the first step of
+// the path can be followed.
+func jsonPathTarget(root interface{}, path cty.Path) (interface{}, jsonStep, cty.Path) {
+	var parent interface{}
+	var last jsonStep
+	current := root
+	for i, step := range path {
+		var next jsonStep
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return parent, last, path[:i]
+			}
+			if _, exists := obj[step.Name]; !exists {
+				return parent, last, path[:i]
+			}
+			next = jsonStep{key: step.Name}
+		case cty.IndexStep:
+			switch container := current.(type) {
+			case map[string]interface{}:
+				if step.Key.Type() != cty.String || !step.Key.IsKnown() || step.Key.IsNull() {
+					return parent, last, path[:i]
+				}
+				if _, exists := container[step.Key.AsString()]; !exists {
+					return parent, last, path[:i]
+				}
+				next = jsonStep{key: step.Key.AsString()}
+			case []interface{}:
+				if step.Key.Type() != cty.Number || !step.Key.IsKnown() || step.Key.IsNull() {
+					// This is an element of a set, which we can't identify
+					// in the JSON representation.
+					return parent, last, path[:i]
+				}
+				idx, accuracy := step.Key.AsBigFloat().Int64()
+				if accuracy != big.Exact || idx < 0 || idx >= int64(len(container)) {
+					return parent, last, path[:i]
+				}
+				next = jsonStep{index: int(idx)}
+			default:
+				return parent, last, path[:i]
+			}
+		default:
+			return parent, last, path[:i]
+		}
+		parent, last = current, next
+		current = next.get(current)
+	}
+	return parent, last, path
+}
+
+// refRecordingStore is a secrets.Store that records the references that it
+// returns from Put and that are passed to Get.
+type refRecordingStore struct {
+	secrets.Store
+
+	refs map[string]struct{}
+}
+
+func (s *refRecordingStore) Put(key string, value []byte) (string, error) {
+	ref, err := s.Store.Put(key, value)
+	if err == nil {
+		s.record(ref)
+	}
+	return ref, err
+}
+
+func (s *refRecordingStore) Get(ref string) ([]byte, error) {
+	s.record(ref)
+	return s.Store.Get(ref)
+}
+
+func (s *refRecordingStore) record(ref string) {
+	if s.refs == nil {
+		s.refs = make(map[string]struct{})
+	}
+	s.refs[ref] = struct{}{}
+}
+
+// secretRefsSnapshot is the set of references to secret values in one
+// snapshot of a state.
+type secretRefsSnapshot struct {
+	serial uint64
+	refs   map[string]struct{}
+}
+
+// knownSecretRefs records the references in the newest snapshot of each state
+// lineage that this process has read or written, so that writing a newer
+// snapshot can delete the values that only the older snapshot refers to.
+var (
+	knownSecretRefs   = make(map[string]secretRefsSnapshot)
+	knownSecretRefsMu sync.Mutex
+)
+
+// recordSecretRefs records the references in a snapshot that was read, unless
+// a newer snapshot of the same lineage is already known.
+func recordSecretRefs(lineage string, serial uint64, refs map[string]struct{}) {
+	replaceSecretRefs(lineage, serial, refs)
+}
+
+// replaceSecretRefs records the references in the given snapshot, and
+// returns the references in the previously known snapshot of the same lineage
+// that the given snapshot no longer refers to, if it's newer.
+//
+// Other snapshots with the same serial, such as the ones in a saved plan,
+// don't replace any references, and older snapshots, such as backups, are
+// ignored. States without a lineage are only written by tests and by
+// commands that don't persist them, so they're ignored too.
+func replaceSecretRefs(lineage string, serial uint64, refs map[string]struct{}) []string {
+	if lineage == "" {
+		return nil
+	}
+
+	knownSecretRefsMu.Lock()
+	defer knownSecretRefsMu.Unlock()
+
+	prev, known := knownSecretRefs[lineage]
+	switch {
+	case !known:
+		knownSecretRefs[lineage] = secretRefsSnapshot{serial: serial, refs: refs}
+		return nil
+	case serial < prev.serial:
+		return nil
+	case serial == prev.serial:
+		merged := make(map[string]struct{}, len(prev.refs)+len(refs))
+		for ref := range prev.refs {
+			merged[ref] = struct{}{}
+		}
+		for ref := range refs {
+			merged[ref] = struct{}{}
+		}
+		knownSecretRefs[lineage] = secretRefsSnapshot{serial: serial, refs: merged}
+		return nil
+	}
+
+	var unused []string
+	for ref := range prev.refs {
+		if _, ok := refs[ref]; !ok {
+			unused = append(unused, ref)
+		}
+	}
+	sort.Strings(unused)
+	knownSecretRefs[lineage] = secretRefsSnapshot{serial: serial, refs: refs}
+	return unused
+}
@@ -0,0 +1,188 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package statefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/secrets"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestWrite_secrets(t *testing.T) {
+	state := states.NewState()
+	state.RootModule().SetResourceInstanceCurrent(
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_thing",
+			Name: "foo",
+		}.Instance(addrs.NoKey),
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"foo","password":"hunter2","tags":{"a":"b"}}`),
+			AttrSensitivePaths: []cty.PathValueMarks{
+				{
+					Path:  cty.GetAttrPath("password"),
+					Marks: cty.NewValueMarks(marks.Sensitive, marks.Secret),
+				},
+				{
+					Path:  cty.GetAttrPath("tags"),
+					Marks: cty.NewValueMarks(marks.Sensitive),
+				},
+			},
+		},
+		addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		},
+		addrs.NoKey,
+	)
+	file := New(state, "lineage", 1)
+
+	t.Run("without a store", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := Write(file, &buf, encryption.StateEncryptionDisabled())
+		if err == nil {
+			t.Fatal("succeeded; want an error")
+		}
+		if got, want := err.Error(), "Failed to save secret values"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+
+	t.Run("with a store", func(t *testing.T) {
+		store := secrets.NewMemoryStore()
+		secrets.SetStore(store)
+		t.Cleanup(func() {
+			secrets.SetStore(nil)
+		})
+
+		var buf bytes.Buffer
+		if err := Write(file, &buf, encryption.StateEncryptionDisabled()); err != nil {
+			t.Fatal(err)
+		}
+		raw := buf.String()
+		if strings.Contains(raw, "hunter2") {
+			t.Fatalf("the state includes the secret value:\n%s", raw)
+		}
+		if !strings.Contains(raw, `"secret_attributes"`) {
+			t.Fatalf("the state doesn't record the secret attributes:\n%s", raw)
+		}
+		if got, want := store.Len(), 1; got != want {
+			t.Fatalf("wrong number of values in the store %d; want %d", got, want)
+		}
+
+		got, err := Read(&buf, encryption.StateEncryptionDisabled())
+		if err != nil {
+			t.Fatal(err)
+		}
+		obj := got.State.RootModule().Resources["test_thing.foo"].Instances[addrs.NoKey].Current
+
+		var gotAttrs, wantAttrs interface{}
+		if err := json.Unmarshal(obj.AttrsJSON, &gotAttrs); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(`{"id":"foo","password":"hunter2","tags":{"a":"b"}}`), &wantAttrs); err != nil {
+			t.Fatal(err)
+		}
+		if !jsonEqual(gotAttrs, wantAttrs) {
+			t.Fatalf("wrong attributes\ngot:  %s\nwant: %s", obj.AttrsJSON, `{"id":"foo","password":"hunter2","tags":{"a":"b"}}`)
+		}
+
+		var secret bool
+		for _, pvm := range obj.AttrSensitivePaths {
+			if _, ok := pvm.Marks[marks.Secret]; ok && pvm.Path.Equals(cty.GetAttrPath("password")) {
+				secret = true
+			}
+		}
+		if !secret {
+			t.Fatalf("the password attribute isn't marked as secret: %#v", obj.AttrSensitivePaths)
+		}
+	})
+}
+
+func TestWrite_secretsDeleted(t *testing.T) {
+	store := secrets.NewMemoryStore()
+	secrets.SetStore(store)
+	t.Cleanup(func() {
+		secrets.SetStore(nil)
+	})
+
+	state := states.NewState()
+	for _, name := range []string{"foo", "bar"} {
+		state.RootModule().SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_thing",
+				Name: name,
+			}.Instance(addrs.NoKey),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{"id":"` + name + `","password":"hunter2"}`),
+				AttrSensitivePaths: []cty.PathValueMarks{
+					{
+						Path:  cty.GetAttrPath("password"),
+						Marks: cty.NewValueMarks(marks.Sensitive, marks.Secret),
+					},
+				},
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(New(state, "secrets-deleted", 1), &buf, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := store.Len(), 2; got != want {
+		t.Fatalf("wrong number of values in the store %d; want %d", got, want)
+	}
+
+	state.RootModule().RemoveResource(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "bar",
+	})
+
+	// Another snapshot with the same serial, such as the one in a saved
+	// plan, doesn't delete anything.
+	buf.Reset()
+	if err := Write(New(state, "secrets-deleted", 1), &buf, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := store.Len(), 2; got != want {
+		t.Fatalf("wrong number of values in the store after writing the same serial %d; want %d", got, want)
+	}
+
+	buf.Reset()
+	if err := Write(New(state, "secrets-deleted", 2), &buf, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := store.Len(), 1; got != want {
+		t.Fatalf("wrong number of values in the store after the instance left the state %d; want %d", got, want)
+	}
+	if _, err := store.Get("memory:secrets-deleted/test_thing.foo.password"); err != nil {
+		t.Fatalf("the value of the remaining instance was deleted: %s", err)
+	}
+}
+
+func jsonEqual(a, b interface{}) bool {
+	aRaw, _ := json.Marshal(a)
+	bRaw, _ := json.Marshal(b)
+	return bytes.Equal(aRaw, bRaw)
+}
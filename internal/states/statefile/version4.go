@@ -9,7 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"sort"
+	"time"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/zclconf/go-cty/cty"
@@ -19,6 +21,7 @@ import (
 	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/secrets"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -60,6 +63,7 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 	}
 
 	state := states.NewState()
+	store := &refRecordingStore{Store: secrets.CurrentStore()}
 
 	for _, rsV4 := range sV4.Resources {
 		rAddr := addrs.Resource{
@@ -216,6 +220,32 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 				obj.AttrSensitivePaths = pvm
 			}
 
+			// Secret paths, whose values are saved in the secrets store
+			if isV4.AttributeSecretPaths != nil {
+				paths, pathsDiags := unmarshalPaths([]byte(isV4.AttributeSecretPaths))
+				diags = diags.Append(pathsDiags)
+				if pathsDiags.HasErrors() {
+					continue
+				}
+
+				attrsJSON, err := restoreSecretAttrs(obj.AttrsJSON, paths, store)
+				if err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Failed to retrieve secret values",
+						fmt.Sprintf("Instance %s has attributes marked as secret, which OpenTofu retrieves from the secrets store configured in the CLI configuration: %s.", instAddr.Absolute(moduleAddr), err),
+					))
+					continue
+				}
+				obj.AttrsJSON = attrsJSON
+				for _, path := range paths {
+					obj.AttrSensitivePaths = append(obj.AttrSensitivePaths, cty.PathValueMarks{
+						Path:  path,
+						Marks: cty.NewValueMarks(marks.Sensitive, marks.Secret),
+					})
+				}
+			}
+
 			{
 				// Status
 				raw := isV4.Status
@@ -274,6 +304,22 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 				}
 
 				ms.SetResourceInstanceDeposed(instAddr, dk, obj, instanceProvider, instanceProviderKey)
+				if isV4.DeposedAt != "" {
+					t, err := time.Parse(time.RFC3339, isV4.DeposedAt)
+					if err != nil {
+						diags = diags.Append(tfdiags.Sourceless(
+							tfdiags.Error,
+							"Invalid resource instance metadata in state",
+							fmt.Sprintf("Instance %s deposed object %q has an invalid deposed_at timestamp: %s.", instAddr.Absolute(moduleAddr), dk, err),
+						))
+						continue
+					}
+					is := ms.ResourceInstance(instAddr)
+					if is.DeposedAt == nil {
+						is.DeposedAt = make(map[states.DeposedKey]time.Time)
+					}
+					is.DeposedAt[dk] = t
+				}
 			default:
 				is := ms.ResourceInstance(instAddr)
 				if is.HasCurrent() {
@@ -286,6 +332,7 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 				}
 
 				ms.SetResourceInstanceCurrent(instAddr, obj, instanceProvider, instanceProviderKey)
+				ms.ResourceInstance(instAddr).Note = isV4.Note
 			}
 		}
 
@@ -353,11 +400,35 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 		diags = diags.Append(moreDiags)
 	}
 
+	if sV4.Workspace != nil {
+		var moreDiags tfdiags.Diagnostics
+		state.Workspace, moreDiags = decodeWorkspaceV4(sV4.Workspace)
+		diags = diags.Append(moreDiags)
+	}
+
+	if sV4.ModuleFingerprints != nil {
+		state.ModuleFingerprints = make(map[string]states.ModuleFingerprint, len(sV4.ModuleFingerprints))
+		for key, fV4 := range sV4.ModuleFingerprints {
+			state.ModuleFingerprints[key] = states.ModuleFingerprint{
+				Inputs:    fV4.Inputs,
+				Providers: fV4.Providers,
+			}
+		}
+	}
+
+	if !diags.HasErrors() {
+		recordSecretRefs(file.Lineage, file.Serial, store.refs)
+	}
+
 	file.State = state
 	return file, diags
 }
 
-func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdiags.Diagnostics {
+// writeStateV4 writes the given state, saving the values marked as secret in
+// the given secrets store. If the store is nil then the secret values are
+// written in cleartext, which is only acceptable when the result is never
+// persisted.
+func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption, store secrets.Store) tfdiags.Diagnostics {
 	// Here we'll convert back from the "File" representation to our
 	// stateV4 struct representation and write that.
 	//
@@ -371,6 +442,12 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 		panic("attempt to write nil state to file")
 	}
 
+	var recordingStore *refRecordingStore
+	if store != nil {
+		recordingStore = &refRecordingStore{Store: store}
+		store = recordingStore
+	}
+
 	var terraformVersion string
 	if file.TerraformVersion != nil {
 		terraformVersion = file.TerraformVersion.String()
@@ -461,6 +538,7 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 					rsV4.Instances, objDiags = appendInstanceObjectStateV4(
 						rs, is, key, is.Current, states.NotDeposed,
 						rsV4.Instances, hasProviderInstanceKeys,
+						store, file.Lineage,
 					)
 					diags = diags.Append(objDiags)
 				}
@@ -469,6 +547,7 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 					rsV4.Instances, objDiags = appendInstanceObjectStateV4(
 						rs, is, key, obj, dk,
 						rsV4.Instances, hasProviderInstanceKeys,
+						store, file.Lineage,
 					)
 					diags = diags.Append(objDiags)
 				}
@@ -477,6 +556,16 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 	}
 
 	sV4.CheckResults = encodeCheckResultsV4(file.State.CheckResults)
+	sV4.Workspace = encodeWorkspaceV4(file.State.Workspace)
+	if file.State.ModuleFingerprints != nil {
+		sV4.ModuleFingerprints = make(map[string]moduleFingerprintV4, len(file.State.ModuleFingerprints))
+		for key, f := range file.State.ModuleFingerprints {
+			sV4.ModuleFingerprints[key] = moduleFingerprintV4{
+				Inputs:    f.Inputs,
+				Providers: f.Providers,
+			}
+		}
+	}
 
 	sV4.normalize()
 
@@ -505,10 +594,21 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 		return diags
 	}
 
+	if recordingStore != nil && !diags.HasErrors() {
+		// The values that the previous snapshot of this state refers to but
+		// this one doesn't, such as those of the instances that left the
+		// state, are no longer needed.
+		for _, ref := range replaceSecretRefs(file.Lineage, file.Serial, recordingStore.refs) {
+			if err := recordingStore.Delete(ref); err != nil {
+				log.Printf("[WARN] Failed to delete a secret value that the state no longer refers to: %s", err)
+			}
+		}
+	}
+
 	return diags
 }
 
-func appendInstanceObjectStateV4(rs *states.Resource, is *states.ResourceInstance, key addrs.InstanceKey, obj *states.ResourceInstanceObjectSrc, deposed states.DeposedKey, isV4s []instanceObjectStateV4, hasProviderInstanceKeys bool) ([]instanceObjectStateV4, tfdiags.Diagnostics) {
+func appendInstanceObjectStateV4(rs *states.Resource, is *states.ResourceInstance, key addrs.InstanceKey, obj *states.ResourceInstanceObjectSrc, deposed states.DeposedKey, isV4s []instanceObjectStateV4, hasProviderInstanceKeys bool, store secrets.Store, lineage string) ([]instanceObjectStateV4, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
 	var status string
@@ -566,15 +666,56 @@ func appendInstanceObjectStateV4(rs *states.Resource, is *states.ResourceInstanc
 	attributeSensitivePaths, pathsDiags := marshalPaths(paths)
 	diags = diags.Append(pathsDiags)
 
+	// Values marked as secret are saved in the secrets store, and the state
+	// only records references to them.
+	attrsJSON := obj.AttrsJSON
+	var attributeSecretPaths json.RawMessage
+	if secretPaths := secretPaths(obj.AttrSensitivePaths); len(secretPaths) > 0 && attrsJSON != nil {
+		if store != nil {
+			keyPrefix := lineage + "/" + rs.Addr.Instance(key).String()
+			if deposed != states.NotDeposed {
+				keyPrefix += "/deposed/" + string(deposed)
+			}
+			var err error
+			attrsJSON, secretPaths, err = redactSecretAttrs(attrsJSON, secretPaths, store, keyPrefix)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to save secret values",
+					fmt.Sprintf("Instance %s has attributes marked as secret, which OpenTofu saves in the secrets store configured in the CLI configuration instead of the state: %s.", rs.Addr.Instance(key), err),
+				))
+			}
+		}
+		if len(secretPaths) > 0 {
+			raw, pathsDiags := marshalPaths(secretPaths)
+			diags = diags.Append(pathsDiags)
+			attributeSecretPaths = raw
+		}
+	}
+
+	var deposedAt string
+	if t, ok := is.DeposedAt[deposed]; ok && deposed != states.NotDeposed {
+		deposedAt = t.Format(time.RFC3339)
+	}
+
+	// The instance's note is saved with its current object.
+	var note string
+	if deposed == states.NotDeposed {
+		note = is.Note
+	}
+
 	return append(isV4s, instanceObjectStateV4{
 		IndexKey:                rawKey,
 		Deposed:                 string(deposed),
+		DeposedAt:               deposedAt,
+		Note:                    note,
 		Status:                  status,
 		ProviderConfig:          providerConfig,
 		SchemaVersion:           obj.SchemaVersion,
 		AttributesFlat:          obj.AttrsFlat,
-		AttributesRaw:           obj.AttrsJSON,
+		AttributesRaw:           attrsJSON,
 		AttributeSensitivePaths: attributeSensitivePaths,
+		AttributeSecretPaths:    attributeSecretPaths,
 		PrivateRaw:              privateRaw,
 		Dependencies:            deps,
 		CreateBeforeDestroy:     obj.CreateBeforeDestroy,
@@ -740,6 +881,51 @@ func encodeCheckableObjectKindV4(in addrs.CheckableKind) string {
 	}
 }
 
+func decodeWorkspaceV4(in *workspaceV4) (*states.WorkspaceMetadata, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	ret := &states.WorkspaceMetadata{
+		Description: in.Description,
+		Tags:        states.NormalizeTags(in.Tags),
+	}
+	decodeTime := func(name, raw string) time.Time {
+		if raw == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid workspace metadata in state",
+				fmt.Sprintf("The state file has an invalid %s timestamp %q: %s.", name, raw, err),
+			))
+		}
+		return t
+	}
+	ret.CreatedAt = decodeTime("created_at", in.CreatedAt)
+	ret.LastAppliedAt = decodeTime("last_applied_at", in.LastAppliedAt)
+
+	return ret, diags
+}
+
+func encodeWorkspaceV4(in *states.WorkspaceMetadata) *workspaceV4 {
+	if in == nil {
+		return nil
+	}
+	encodeTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return &workspaceV4{
+		Description:   in.Description,
+		Tags:          in.Tags,
+		CreatedAt:     encodeTime(in.CreatedAt),
+		LastAppliedAt: encodeTime(in.LastAppliedAt),
+	}
+}
+
 type stateV4 struct {
 	Version          stateVersionV4           `json:"version"`
 	TerraformVersion string                   `json:"terraform_version"`
@@ -748,6 +934,21 @@ type stateV4 struct {
 	RootOutputs      map[string]outputStateV4 `json:"outputs"`
 	Resources        []resourceStateV4        `json:"resources"`
 	CheckResults     []checkResultsV4         `json:"check_results"`
+	Workspace        *workspaceV4             `json:"workspace,omitempty"`
+
+	ModuleFingerprints map[string]moduleFingerprintV4 `json:"module_fingerprints,omitempty"`
+}
+
+type workspaceV4 struct {
+	Description   string   `json:"description,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	LastAppliedAt string   `json:"last_applied_at,omitempty"`
+}
+
+type moduleFingerprintV4 struct {
+	Inputs    string            `json:"inputs"`
+	Providers map[string]string `json:"providers"`
 }
 
 // normalize makes some in-place changes to normalize the way items are
@@ -782,12 +983,15 @@ type instanceObjectStateV4 struct {
 	IndexKey       interface{} `json:"index_key,omitempty"`
 	Status         string      `json:"status,omitempty"`
 	Deposed        string      `json:"deposed,omitempty"`
+	DeposedAt      string      `json:"deposed_at,omitempty"`
+	Note           string      `json:"note,omitempty"`
 	ProviderConfig string      `json:"provider,omitempty"`
 
 	SchemaVersion           uint64            `json:"schema_version"`
 	AttributesRaw           json.RawMessage   `json:"attributes,omitempty"`
 	AttributesFlat          map[string]string `json:"attributes_flat,omitempty"`
 	AttributeSensitivePaths json.RawMessage   `json:"sensitive_attributes,omitempty"`
+	AttributeSecretPaths    json.RawMessage   `json:"secret_attributes,omitempty"`
 
 	PrivateRaw []byte `json:"private,omitempty"`
 
@@ -9,16 +9,21 @@ import (
 	"io"
 
 	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/secrets"
 	tfversion "github.com/opentofu/opentofu/version"
 )
 
 // Write writes the given state to the given writer in the current state
 // serialization format.
+//
+// Values marked as secret are saved in the secrets store set with
+// secrets.SetStore, and the state only records references to them. It's an
+// error to write a state with secret values if no store is set.
 func Write(s *File, w io.Writer, enc encryption.StateEncryption) error {
 	// Always record the current tofu version in the state.
 	s.TerraformVersion = tfversion.SemVer
 
-	diags := writeStateV4(s, w, enc)
+	diags := writeStateV4(s, w, enc, secrets.CurrentStore())
 	return diags.Err()
 }
 
@@ -27,6 +32,6 @@ func Write(s *File, w io.Writer, enc encryption.StateEncryption) error {
 // intended for use in tests that need to override the current tofu
 // version.
 func WriteForTest(s *File, w io.Writer) error {
-	diags := writeStateV4(s, w, encryption.StateEncryptionDisabled())
+	diags := writeStateV4(s, w, encryption.StateEncryptionDisabled(), secrets.CurrentStore())
 	return diags.Err()
 }
@@ -0,0 +1,180 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package statemgr
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+// ConcurrentWriteMerger is an optional interface implemented by persistent
+// state managers that can merge the changes of concurrent writers when
+// persisting a snapshot, rather than overwriting them.
+type ConcurrentWriteMerger interface {
+	// EnableConcurrentWriteMerging makes subsequent calls to PersistState
+	// check whether the persisted snapshot was changed by someone else since
+	// it was last read, and if so merge both sets of changes with
+	// MergeConcurrentChanges.
+	//
+	// This is intended for situations where the state isn't locked for the
+	// duration of an operation, and should be called before any of the
+	// statemgr.Full interface methods have been called.
+	EnableConcurrentWriteMerging()
+}
+
+// MergeConflictError is returned by MergeConcurrentChanges when both writers
+// changed the same resources or root module output values in different ways.
+type MergeConflictError struct {
+	Resources []addrs.AbsResource
+	Outputs   []string
+}
+
+func (e *MergeConflictError) Error() string {
+	var items []string
+	for _, addr := range e.Resources {
+		items = append(items, addr.String())
+	}
+	for _, name := range e.Outputs {
+		items = append(items, "output."+name)
+	}
+	return fmt.Sprintf(
+		"the state was changed concurrently by another operation, and the following were changed by both operations:\n  %s",
+		strings.Join(items, "\n  "),
+	)
+}
+
+// MergeConcurrentChanges performs a three-way merge of two state snapshots
+// that were both derived from the same base snapshot, returning a state that
+// includes the changes of both.
+//
+// The merge is done separately for each resource, for each root module output
+// value and for the check results. Each of them is taken from whichever of
+// ours and theirs changed it relative to base, so that two writers changing
+// disjoint sets of resources can both succeed. If both changed the same item
+// in different ways the result is a *MergeConflictError describing all such
+// items, and no merged state.
+func MergeConcurrentChanges(base, ours, theirs *states.State) (*states.State, error) {
+	base, err := normalizeStateForMerge(base)
+	if err != nil {
+		return nil, err
+	}
+	ours, err = normalizeStateForMerge(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err = normalizeStateForMerge(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The result starts as "theirs", overridden by whatever we changed.
+	merged := theirs.DeepCopy()
+	conflicts := &MergeConflictError{}
+
+	seen := make(map[string]bool)
+	for _, s := range []*states.State{base, ours, theirs} {
+		for _, ms := range s.Modules {
+			for _, rs := range ms.Resources {
+				addr := rs.Addr
+				if seen[addr.String()] {
+					continue
+				}
+				seen[addr.String()] = true
+
+				b, o, t := base.Resource(addr), ours.Resource(addr), theirs.Resource(addr)
+				switch {
+				case reflect.DeepEqual(o, b), reflect.DeepEqual(o, t):
+					// Nothing to do: "theirs" is already in the result.
+				case reflect.DeepEqual(t, b):
+					mergeResource(merged, addr, o)
+				default:
+					conflicts.Resources = append(conflicts.Resources, addr)
+				}
+			}
+		}
+	}
+
+	bOutputs, oOutputs, tOutputs := base.RootModule().OutputValues, ours.RootModule().OutputValues, theirs.RootModule().OutputValues
+	names := make(map[string]bool)
+	for _, outputs := range []map[string]*states.OutputValue{bOutputs, oOutputs, tOutputs} {
+		for name := range outputs {
+			names[name] = true
+		}
+	}
+	for name := range names {
+		b, o, t := bOutputs[name], oOutputs[name], tOutputs[name]
+		switch {
+		case reflect.DeepEqual(o, b), reflect.DeepEqual(o, t):
+		case reflect.DeepEqual(t, b):
+			if o == nil {
+				merged.RootModule().RemoveOutputValue(name)
+			} else {
+				merged.RootModule().OutputValues[name] = o.DeepCopy()
+			}
+		default:
+			conflicts.Outputs = append(conflicts.Outputs, name)
+		}
+	}
+
+	if len(conflicts.Resources) > 0 || len(conflicts.Outputs) > 0 {
+		sort.Slice(conflicts.Resources, func(i, j int) bool {
+			return conflicts.Resources[i].Less(conflicts.Resources[j])
+		})
+		sort.Strings(conflicts.Outputs)
+		return nil, conflicts
+	}
+
+	// Check results are recalculated in full by each operation, so there's
+	// nothing to reconcile: we keep our own results if we produced any.
+	if !reflect.DeepEqual(ours.CheckResults, base.CheckResults) {
+		merged.CheckResults = ours.CheckResults.DeepCopy()
+	}
+
+	return merged, nil
+}
+
+// mergeResource replaces the resource with the given address in the given
+// state with the given object, or removes it if rs is nil.
+func mergeResource(s *states.State, addr addrs.AbsResource, rs *states.Resource) {
+	ms := s.EnsureModule(addr.Module)
+	if rs != nil {
+		ms.Resources[addr.Resource.String()] = rs.DeepCopy()
+		return
+	}
+	ms.RemoveResource(addr.Resource)
+	if !ms.Addr.IsRoot() && len(ms.Resources) == 0 && len(ms.OutputValues) == 0 {
+		s.RemoveModule(ms.Addr)
+	}
+}
+
+// normalizeStateForMerge round-trips the given state through the state file
+// format, so that objects that would be persisted identically also compare
+// as equal, regardless of how their JSON attributes happen to be formatted
+// in memory.
+func normalizeStateForMerge(s *states.State) (*states.State, error) {
+	if s == nil {
+		return states.NewState(), nil
+	}
+
+	var buf bytes.Buffer
+	enc := encryption.StateEncryptionDisabled()
+	if err := statefile.Write(statefile.New(s, "", 0), &buf, enc); err != nil {
+		return nil, fmt.Errorf("failed to prepare state for merging: %w", err)
+	}
+	f, err := statefile.Read(&buf, enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare state for merging: %w", err)
+	}
+	return f.State, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package statemgr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestMergeConcurrentChanges(t *testing.T) {
+	// buildState returns a state with a test_thing resource for each entry
+	// of ids, using the given JSON for its attributes, and a root module
+	// output value for each entry of outputs.
+	buildState := func(ids map[string]string, outputs map[string]string) *states.State {
+		return states.BuildState(func(s *states.SyncState) {
+			for name, attrs := range ids {
+				s.SetResourceInstanceCurrent(
+					addrs.Resource{
+						Mode: addrs.ManagedResourceMode,
+						Type: "test_thing",
+						Name: name,
+					}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+					&states.ResourceInstanceObjectSrc{
+						AttrsJSON: []byte(attrs),
+						Status:    states.ObjectReady,
+					},
+					addrs.AbsProviderConfig{
+						Provider: addrs.NewDefaultProvider("test"),
+						Module:   addrs.RootModule,
+					},
+					addrs.NoKey,
+				)
+			}
+			for name, v := range outputs {
+				s.SetOutputValue(
+					addrs.OutputValue{Name: name}.Absolute(addrs.RootModuleInstance),
+					cty.StringVal(v), false,
+				)
+			}
+		})
+	}
+	attrs := func(s *states.State) map[string]string {
+		ret := make(map[string]string)
+		for _, rs := range s.RootModule().Resources {
+			var v map[string]string
+			if err := json.Unmarshal(rs.Instances[addrs.NoKey].Current.AttrsJSON, &v); err != nil {
+				t.Fatal(err)
+			}
+			ret[rs.Addr.Resource.Name] = v["id"]
+		}
+		return ret
+	}
+	outputs := func(s *states.State) map[string]string {
+		ret := make(map[string]string)
+		for name, ov := range s.RootModule().OutputValues {
+			ret[name] = ov.Value.AsString()
+		}
+		return ret
+	}
+
+	base := buildState(
+		map[string]string{"a": `{"id":"a"}`, "b": `{"id":"b"}`, "c": `{"id":"c"}`},
+		map[string]string{"x": "x", "y": "y"},
+	)
+
+	tests := map[string]struct {
+		ours, theirs  *states.State
+		wantResources map[string]string
+		wantOutputs   map[string]string
+		wantConflicts []string
+	}{
+		"disjoint changes": {
+			ours: buildState(
+				map[string]string{"a": `{"id":"a-ours"}`, "b": `{"id":"b"}`},
+				map[string]string{"x": "x-ours", "y": "y"},
+			),
+			theirs: buildState(
+				map[string]string{"a": `{"id":"a"}`, "b": `{"id":"b-theirs"}`, "c": `{"id":"c"}`, "d": `{"id":"d-theirs"}`},
+				map[string]string{"x": "x"},
+			),
+			wantResources: map[string]string{"a": "a-ours", "b": "b-theirs", "d": "d-theirs"},
+			wantOutputs:   map[string]string{"x": "x-ours"},
+		},
+		"identical changes": {
+			ours: buildState(
+				map[string]string{"a": `{"id":"a-new"}`, "b": `{"id":"b"}`, "c": `{"id":"c"}`},
+				map[string]string{"x": "x", "y": "y"},
+			),
+			theirs: buildState(
+				map[string]string{"a": `{"id":"a-new"}`, "b": `{"id":"b"}`, "c": `{"id":"c"}`},
+				map[string]string{"x": "x", "y": "y"},
+			),
+			wantResources: map[string]string{"a": "a-new", "b": "b", "c": "c"},
+			wantOutputs:   map[string]string{"x": "x", "y": "y"},
+		},
+		"formatting differences are not changes": {
+			ours: buildState(
+				map[string]string{"a": "{\n  \"id\": \"a\"\n}", "b": `{"id":"b"}`, "c": `{"id":"c"}`},
+				map[string]string{"x": "x", "y": "y"},
+			),
+			theirs: buildState(
+				map[string]string{"a": `{"id":"a-theirs"}`, "b": `{"id":"b"}`, "c": `{"id":"c"}`},
+				map[string]string{"x": "x", "y": "y"},
+			),
+			wantResources: map[string]string{"a": "a-theirs", "b": "b", "c": "c"},
+			wantOutputs:   map[string]string{"x": "x", "y": "y"},
+		},
+		"overlapping changes": {
+			ours: buildState(
+				map[string]string{"a": `{"id":"a-ours"}`, "b": `{"id":"b"}`},
+				map[string]string{"x": "x-ours", "y": "y"},
+			),
+			theirs: buildState(
+				map[string]string{"a": `{"id":"a-theirs"}`, "b": `{"id":"b"}`, "c": `{"id":"c-theirs"}`},
+				map[string]string{"x": "x-theirs", "y": "y"},
+			),
+			wantConflicts: []string{"test_thing.a", "test_thing.c", "output.x"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := MergeConcurrentChanges(base, test.ours, test.theirs)
+			if test.wantConflicts != nil {
+				var conflicts *MergeConflictError
+				if !errors.As(err, &conflicts) {
+					t.Fatalf("wrong error: %v", err)
+				}
+				var gotConflicts []string
+				for _, addr := range conflicts.Resources {
+					gotConflicts = append(gotConflicts, addr.String())
+				}
+				for _, name := range conflicts.Outputs {
+					gotConflicts = append(gotConflicts, "output."+name)
+				}
+				if diff := cmp.Diff(test.wantConflicts, gotConflicts); diff != "" {
+					t.Errorf("wrong conflicts\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(test.wantResources, attrs(got)); diff != "" {
+				t.Errorf("wrong resources\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantOutputs, outputs(got)); diff != "" {
+				t.Errorf("wrong outputs\n%s", diff)
+			}
+		})
+	}
+}
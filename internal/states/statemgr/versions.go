@@ -0,0 +1,58 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package statemgr
+
+import (
+	"errors"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+// ErrStateVersionsNotSupported is returned by VersionReader.StateVersion when
+// the storage behind the state manager doesn't keep earlier snapshots.
+var ErrStateVersionsNotSupported = errors.New("the state storage doesn't keep earlier versions of the state")
+
+// ErrStateVersionNotFound is returned by VersionReader.StateVersion when
+// there's no snapshot matching the given selector.
+var ErrStateVersionNotFound = errors.New("no matching version of the state")
+
+// VersionReader is an optional interface implemented by persistent state
+// managers whose storage can keep earlier snapshots, such as an object
+// store with versioning enabled.
+type VersionReader interface {
+	// StateVersion returns the earlier snapshot selected by the given
+	// selector, without changing the snapshot returned by State.
+	//
+	// It returns ErrStateVersionsNotSupported if the storage doesn't keep
+	// earlier snapshots, and ErrStateVersionNotFound if none matches.
+	StateVersion(sel StateVersionSelector) (*statefile.File, error)
+}
+
+// StateVersionSelector selects one of the snapshots kept by the storage of a
+// VersionReader, either by the storage-specific ID of the version, or as
+// the latest one written at or before a time. Exactly one of ID and Time is
+// set.
+type StateVersionSelector struct {
+	ID   string
+	Time time.Time
+}
+
+// ParseStateVersionSelector interprets the given string as a timestamp in
+// RFC 3339 format if possible, and otherwise as a version ID.
+func ParseStateVersionSelector(s string) StateVersionSelector {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return StateVersionSelector{Time: t}
+	}
+	return StateVersionSelector{ID: s}
+}
+
+func (s StateVersionSelector) String() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Time.Format(time.RFC3339)
+}
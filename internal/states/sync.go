@@ -358,6 +358,18 @@ func (s *SyncState) DeposeResourceInstanceObjectForceKey(addr addrs.AbsResourceI
 	ms.deposeResourceInstanceObject(addr.Resource, forcedKey)
 }
 
+// SetResourceInstanceNote sets the note of the resource instance with the
+// given address, or removes it if the note is empty. If the instance isn't
+// tracked, this is a no-op.
+func (s *SyncState) SetResourceInstanceNote(addr addrs.AbsResourceInstance, note string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if is := s.state.ResourceInstance(addr); is != nil {
+		is.Note = note
+	}
+}
+
 // ForgetResourceInstanceAll removes the record of all objects associated with
 // the specified resource instance, if present. If not present, this is a no-op.
 func (s *SyncState) ForgetResourceInstanceAll(addr addrs.AbsResourceInstance) {
@@ -0,0 +1,53 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package states
+
+import (
+	"slices"
+	"time"
+)
+
+// WorkspaceMetadata describes the workspace that a state belongs to.
+//
+// Workspaces are otherwise just names, so the metadata is stored in the state
+// of the workspace itself, which means that it's available with any backend.
+// It's optional, and only present if it was set by "tofu workspace new" or
+// "tofu workspace update".
+type WorkspaceMetadata struct {
+	// Description is a human-readable description of the purpose of the
+	// workspace.
+	Description string
+
+	// Tags are arbitrary labels for tooling that manages many workspaces,
+	// sorted and without duplicates.
+	Tags []string
+
+	// CreatedAt is when the workspace was created, or the zero time if
+	// unknown, such as for the default workspace.
+	CreatedAt time.Time
+
+	// LastAppliedAt is when changes were last applied to the workspace, or
+	// the zero time if they never were since the metadata was created.
+	LastAppliedAt time.Time
+}
+
+// NormalizeTags sorts the given tags and removes any duplicates, returning
+// them in the form used for WorkspaceMetadata.Tags.
+func NormalizeTags(tags []string) []string {
+	ret := slices.Clone(tags)
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// DeepCopy returns a copy of the receiver that shares no memory with it.
+func (m *WorkspaceMetadata) DeepCopy() *WorkspaceMetadata {
+	if m == nil {
+		return nil
+	}
+	ret := *m
+	ret.Tags = slices.Clone(m.Tags)
+	return &ret
+}
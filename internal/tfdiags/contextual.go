@@ -361,6 +361,20 @@ func WholeContainingBody(severity Severity, summary, detail string) Diagnostic {
 	}
 }
 
+// RetryableWholeContainingBody is like WholeContainingBody, but the returned
+// diagnostic also reports that the failure it describes is transient, and so
+// retrying the same operation might succeed. See DiagnosticRetryable.
+func RetryableWholeContainingBody(severity Severity, summary, detail string) Diagnostic {
+	return &wholeBodyDiagnostic{
+		diagnosticBase: diagnosticBase{
+			severity: severity,
+			summary:  summary,
+			detail:   detail,
+			extra:    retryableExtra{},
+		},
+	}
+}
+
 type wholeBodyDiagnostic struct {
 	diagnosticBase
 	subject *SourceRange // populated only after ElaborateFromConfigBody
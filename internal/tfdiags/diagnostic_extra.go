@@ -201,3 +201,90 @@ func DoNotConsolidateDiagnostic(diag Diagnostic) bool {
 	}
 	return maybe.DoNotConsolidateDiagnostic()
 }
+
+// DiagnosticExtraRetryable is an interface implemented by values in the
+// Extra field of Diagnostic when the diagnostic describes a transient failure,
+// such as a plugin that stopped responding, after which retrying the same
+// operation might succeed.
+type DiagnosticExtraRetryable interface {
+	// DiagnosticRetryable returns true if the associated diagnostic
+	// describes a transient failure.
+	DiagnosticRetryable() bool
+}
+
+// DiagnosticRetryable returns true if the given diagnostic describes a
+// transient failure, after which retrying the same operation might succeed.
+func DiagnosticRetryable(diag Diagnostic) bool {
+	maybe := ExtraInfo[DiagnosticExtraRetryable](diag)
+	if maybe == nil {
+		return false
+	}
+	return maybe.DiagnosticRetryable()
+}
+
+type retryableExtra struct{}
+
+func (retryableExtra) DiagnosticRetryable() bool {
+	return true
+}
+
+// DiagnosticExtraRule is an interface implemented by values in the Extra
+// field of Diagnostic that identify the kind of problem that the diagnostic
+// reports, so that automation can recognize it without depending on the
+// wording of the diagnostic's summary and detail.
+type DiagnosticExtraRule interface {
+	// DiagnosticRuleID returns the ID of the rule of the associated
+	// diagnostic, which is one of the Rule constants in this package.
+	DiagnosticRuleID() string
+}
+
+// DiagnosticRuleID returns the ID of the rule of the given diagnostic, or an
+// empty string if it doesn't have one.
+func DiagnosticRuleID(diag Diagnostic) string {
+	maybe := ExtraInfo[DiagnosticExtraRule](diag)
+	if maybe == nil {
+		return ""
+	}
+	return maybe.DiagnosticRuleID()
+}
+
+// RuleExtra returns a value for the Extra field of a diagnostic that gives
+// the diagnostic the rule with the given ID, wrapping the given other value
+// for the Extra field, which can be nil.
+func RuleExtra(id string, wrapped interface{}) interface{} {
+	return &ruleExtra{
+		id:      id,
+		wrapped: wrapped,
+	}
+}
+
+// WithRule returns the given diagnostic with the rule with the given ID, for
+// diagnostics that aren't constructed with an Extra field.
+func WithRule(diag Diagnostic, id string) Diagnostic {
+	return Override(diag, diag.Severity(), func() DiagnosticExtraWrapper {
+		return &ruleExtra{id: id}
+	})
+}
+
+type ruleExtra struct {
+	id      string
+	wrapped interface{}
+}
+
+var (
+	_ DiagnosticExtraRule      = (*ruleExtra)(nil)
+	_ DiagnosticExtraUnwrapper = (*ruleExtra)(nil)
+	_ DiagnosticExtraWrapper   = (*ruleExtra)(nil)
+)
+
+func (e *ruleExtra) DiagnosticRuleID() string {
+	return e.id
+}
+
+func (e *ruleExtra) UnwrapDiagnosticExtra() interface{} {
+	return e.wrapped
+}
+
+func (e *ruleExtra) WrapDiagnosticExtra(inner interface{}) {
+	e.wrapped = inner
+}
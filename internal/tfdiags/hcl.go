@@ -120,6 +120,7 @@ func (diags Diagnostics) ToHCL() hcl.Diagnostics {
 			Summary:  desc.Summary,
 			Detail:   desc.Detail,
 			Severity: severity.ToHCL(),
+			Extra:    diag.ExtraInfo(),
 		}
 		if source.Subject != nil {
 			hclDiag.Subject = source.Subject.ToHCL().Ptr()
@@ -0,0 +1,37 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfdiags
+
+// The IDs of the rules that OpenTofu gives its own diagnostics with
+// RuleExtra or WithRule. The rule IDs are part of the machine-readable
+// validate output, so once added they must not change.
+const (
+	RuleInvalidModuleName        = "invalid-module-name"
+	RuleInvalidReference         = "invalid-reference"
+	RuleUndeclaredVariable       = "undeclared-variable"
+	RuleUndeclaredLocal          = "undeclared-local"
+	RuleUndeclaredModule         = "undeclared-module"
+	RuleUndeclaredOutput         = "undeclared-output"
+	RuleUndeclaredResource       = "undeclared-resource"
+	RuleUndefinedVariable        = "undefined-variable"
+	RuleInvalidProviderReference = "invalid-provider-reference"
+	RuleInvalidCount             = "invalid-count"
+	RuleInvalidVariableDefault   = "invalid-variable-default"
+	RuleInvalidForEach           = "invalid-for-each"
+	RuleInvalidVariableValue     = "invalid-variable-value"
+	RuleInvalidDataSource        = "invalid-data-source"
+	RuleInvalidResourceType      = "invalid-resource-type"
+	RuleDuplicateImport          = "duplicate-import"
+	RuleDuplicateLocal           = "duplicate-local"
+	RuleDuplicateModuleCall      = "duplicate-module-call"
+	RuleDuplicateOutput          = "duplicate-output"
+	RuleDuplicateProviderConfig  = "duplicate-provider-configuration"
+	RuleDuplicateResource        = "duplicate-resource"
+	RuleDuplicateVariable        = "duplicate-variable"
+	RuleMissingRequiredProvider  = "missing-required-provider"
+	RuleModuleNotInstalled       = "module-not-installed"
+	RuleUndeclaredVariableValue  = "undeclared-variable-value"
+)
@@ -16,3 +16,15 @@ func Sourceless(severity Severity, summary, detail string) Diagnostic {
 		detail:   detail,
 	}
 }
+
+// RetryableSourceless is like Sourceless, but the returned diagnostic also
+// reports that the failure it describes is transient, and so retrying the
+// same operation might succeed. See DiagnosticRetryable.
+func RetryableSourceless(severity Severity, summary, detail string) Diagnostic {
+	return diagnosticBase{
+		severity: severity,
+		summary:  summary,
+		detail:   detail,
+		extra:    retryableExtra{},
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// applySlowdownFactor is how many times slower than the fastest request to a
+// provider configuration a request must be before applyTuner treats it as a
+// sign that the provider's API is overloaded or throttling requests.
+const applySlowdownFactor = 3
+
+// ProviderParallelism describes the parallelism that was chosen for the apply
+// requests to a provider configuration when parallelism tuning is enabled.
+type ProviderParallelism struct {
+	Provider addrs.AbsProviderConfig
+
+	// Limit is the number of concurrent apply requests that the provider
+	// configuration was allowed at the end of the run, and MinLimit is the
+	// lowest number it was allowed at any point.
+	Limit    int
+	MinLimit int
+
+	// Requests is the number of apply requests made, and MeanLatency is the
+	// average time they took.
+	Requests    int
+	MeanLatency time.Duration
+}
+
+// applyTuner adjusts the number of concurrent apply requests allowed for
+// each provider configuration based on how long the provider takes to handle
+// them, so that a provider whose API slows down under load gets fewer
+// concurrent requests, leaving more of the overall parallelism for the
+// others.
+//
+// Each provider configuration starts out allowed the full parallelism. When a
+// request takes more than applySlowdownFactor times as long as the fastest
+// request seen so far, the limit is halved, and each time as many requests
+// as the current limit complete without a slowdown it's increased by one
+// again, up to the overall parallelism.
+type applyTuner struct {
+	// parallelSem is the semaphore that each graph node holds while it's
+	// executing, which is temporarily released while waiting for an apply
+	// slot so that nodes for other providers can run.
+	parallelSem Semaphore
+	maxLimit    int
+
+	mu        sync.Mutex
+	providers map[string]*applyTunerProvider
+}
+
+// applyTunerProvider is the state that applyTuner tracks for a single
+// provider configuration. All of its fields are protected by the applyTuner's
+// mutex.
+type applyTunerProvider struct {
+	addr addrs.AbsProviderConfig
+	cond *sync.Cond
+
+	limit    int
+	minLimit int
+	inUse    int
+
+	// healthy counts requests completed without a slowdown since the limit
+	// last changed, and lastDecrease is when the limit was last reduced, so
+	// that requests that started before then don't reduce it again.
+	healthy      int
+	lastDecrease time.Time
+
+	fastest  time.Duration
+	requests int
+	total    time.Duration
+}
+
+func newApplyTuner(parallelSem Semaphore, maxLimit int) *applyTuner {
+	return &applyTuner{
+		parallelSem: parallelSem,
+		maxLimit:    maxLimit,
+		providers:   make(map[string]*applyTunerProvider),
+	}
+}
+
+// Acquire blocks until the given provider configuration is allowed to handle
+// another apply request, and then returns a function that must be called
+// once the request is complete.
+//
+// The caller must be holding a slot from the parallelism semaphore, which is
+// released while waiting and then reacquired before Acquire returns.
+//
+// Acquire may be called on a nil *applyTuner, in which case apply requests
+// are limited only by the parallelism semaphore and the returned function
+// does nothing.
+func (t *applyTuner) Acquire(addr addrs.AbsProviderConfig) (release func()) {
+	if t == nil {
+		return func() {}
+	}
+
+	key := addr.String()
+	t.mu.Lock()
+	p, ok := t.providers[key]
+	if !ok {
+		p = &applyTunerProvider{
+			addr:     addr,
+			cond:     sync.NewCond(&t.mu),
+			limit:    t.maxLimit,
+			minLimit: t.maxLimit,
+		}
+		t.providers[key] = p
+	}
+	if p.inUse >= p.limit {
+		t.parallelSem.Release()
+		for p.inUse >= p.limit {
+			p.cond.Wait()
+		}
+		p.inUse++
+		t.mu.Unlock()
+		t.parallelSem.Acquire()
+	} else {
+		p.inUse++
+		t.mu.Unlock()
+	}
+
+	start := time.Now()
+	return func() {
+		t.done(p, start, time.Since(start))
+	}
+}
+
+// done records the completion of a request to the given provider that
+// started at the given time and took the given duration, and adjusts the
+// provider's limit accordingly.
+func (t *applyTuner) done(p *applyTunerProvider, start time.Time, took time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p.inUse--
+	p.requests++
+	p.total += took
+	if p.fastest == 0 || took < p.fastest {
+		p.fastest = took
+	}
+
+	switch {
+	case took > p.fastest*applySlowdownFactor:
+		if p.limit > 1 && start.After(p.lastDecrease) {
+			p.limit /= 2
+			p.lastDecrease = time.Now()
+			p.healthy = 0
+			if p.limit < p.minLimit {
+				p.minLimit = p.limit
+			}
+		}
+	case p.limit < t.maxLimit:
+		p.healthy++
+		if p.healthy >= p.limit {
+			p.limit++
+			p.healthy = 0
+		}
+	}
+
+	p.cond.Broadcast()
+}
+
+// Report returns the parallelism that was chosen for each provider
+// configuration that handled at least one apply request, sorted by provider
+// configuration address.
+func (t *applyTuner) Report() []ProviderParallelism {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ret := make([]ProviderParallelism, 0, len(t.providers))
+	for _, p := range t.providers {
+		if p.requests == 0 {
+			continue
+		}
+		ret = append(ret, ProviderParallelism{
+			Provider:    p.addr,
+			Limit:       p.limit,
+			MinLimit:    p.minLimit,
+			Requests:    p.requests,
+			MeanLatency: p.total / time.Duration(p.requests),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Provider.String() < ret[j].Provider.String()
+	})
+	return ret
+}
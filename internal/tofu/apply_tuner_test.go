@@ -0,0 +1,73 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestApplyTuner(t *testing.T) {
+	addr := addrs.RootModuleInstance.ProviderConfigDefault(addrs.NewDefaultProvider("test"))
+	sem := NewSemaphore(8)
+	tuner := newApplyTuner(sem, 8)
+
+	sem.Acquire()
+	defer sem.Release()
+	tuner.Acquire(addr)()
+	p := tuner.providers[addr.String()]
+
+	// Requests that are much slower than the fastest one halve the limit,
+	// but only once for requests that started before the last decrease.
+	start := time.Now()
+	p.fastest = time.Second
+	tuner.done(p, start, 5*time.Second)
+	if got, want := p.limit, 4; got != want {
+		t.Fatalf("wrong limit after slowdown %d; want %d", got, want)
+	}
+	tuner.done(p, start, 5*time.Second)
+	if got, want := p.limit, 4; got != want {
+		t.Fatalf("wrong limit after second slowdown of an earlier request %d; want %d", got, want)
+	}
+	tuner.done(p, time.Now(), 5*time.Second)
+	if got, want := p.limit, 2; got != want {
+		t.Fatalf("wrong limit after slowdown of a later request %d; want %d", got, want)
+	}
+
+	// Each time as many requests as the limit complete without a slowdown
+	// the limit increases by one, up to the overall parallelism.
+	for i := 0; i < 2; i++ {
+		tuner.done(p, time.Now(), time.Second)
+	}
+	if got, want := p.limit, 3; got != want {
+		t.Fatalf("wrong limit after healthy requests %d; want %d", got, want)
+	}
+	for i := 0; i < 100; i++ {
+		tuner.done(p, time.Now(), time.Second)
+	}
+	if got, want := p.limit, 8; got != want {
+		t.Fatalf("wrong limit after many healthy requests %d; want %d", got, want)
+	}
+
+	report := tuner.Report()
+	if len(report) != 1 {
+		t.Fatalf("wrong number of providers in report %d; want 1", len(report))
+	}
+	if got := report[0]; got.Limit != 8 || got.MinLimit != 2 || got.Requests != 106 {
+		t.Errorf("wrong report %#v", got)
+	}
+}
+
+func TestApplyTuner_nil(t *testing.T) {
+	var tuner *applyTuner
+	addr := addrs.RootModuleInstance.ProviderConfigDefault(addrs.NewDefaultProvider("test"))
+	tuner.Acquire(addr)()
+	if report := tuner.Report(); report != nil {
+		t.Errorf("unexpected report %#v", report)
+	}
+}
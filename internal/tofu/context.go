@@ -12,10 +12,13 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/states"
@@ -46,6 +49,23 @@ type ContextOpts struct {
 	Provisioners map[string]provisioners.Factory
 	Encryption   encryption.Encryption
 
+	// RefreshConcurrency, if greater than zero, limits the number of
+	// concurrent refresh requests separately for each provider
+	// configuration, instead of counting them against Parallelism.
+	RefreshConcurrency int
+
+	// TuneParallelism, if set, adjusts the number of concurrent apply
+	// requests allowed for each provider configuration during apply based
+	// on how long the provider takes to handle them, within the overall
+	// limit set by Parallelism.
+	TuneParallelism bool
+
+	// AllowedApplyActions, if not empty, makes Apply refuse to apply any
+	// plan that includes a resource instance change whose action isn't in
+	// the set. No-op and read changes are always allowed, and the two
+	// replace actions must be allowed individually.
+	AllowedApplyActions []plans.Action
+
 	UIInput UIInput
 }
 
@@ -68,6 +88,10 @@ type ContextMeta struct {
 	// for some exceptional cases where the original working directory is
 	// needed.
 	OriginalWorkingDir string
+
+	// RunID uniquely identifies the run of OpenTofu CLI that created the
+	// context, and is exposed to the configuration as tofu.run_id.
+	RunID string
 }
 
 // Context represents all the context that OpenTofu needs in order to
@@ -86,6 +110,9 @@ type Context struct {
 	uiInput UIInput
 
 	parallelSem         Semaphore
+	refreshLimiter      *refreshLimiter
+	applyTuner          *applyTuner
+	allowedActions      []plans.Action
 	l                   sync.Mutex // Lock acquired during any task
 	providerInputConfig map[string]map[string]cty.Value
 	runCond             *sync.Cond
@@ -109,12 +136,12 @@ func NewContext(opts *ContextOpts) (*Context, tfdiags.Diagnostics) {
 
 	log.Printf("[TRACE] tofu.NewContext: starting")
 
-	// Copy all the hooks and add our stop hook. We don't append directly
-	// to the Config so that we're not modifying that in-place.
+	// Copy all the hooks and add our stop and metrics hooks. We don't append
+	// directly to the Config so that we're not modifying that in-place.
 	sh := new(stopHook)
-	hooks := make([]Hook, len(opts.Hooks)+1)
+	hooks := make([]Hook, len(opts.Hooks), len(opts.Hooks)+2)
 	copy(hooks, opts.Hooks)
-	hooks[len(opts.Hooks)] = sh
+	hooks = append(hooks, sh, new(metricsHook))
 
 	// Determine parallelism, default to 10. We do this both to limit
 	// CPU pressure but also to have an extra guard against rate throttling
@@ -134,6 +161,25 @@ func NewContext(opts *ContextOpts) (*Context, tfdiags.Diagnostics) {
 		par = 10
 	}
 
+	if opts.RefreshConcurrency < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid refresh concurrency value",
+			fmt.Sprintf("The refresh concurrency must be a positive value. Not %d.", opts.RefreshConcurrency),
+		))
+		return nil, diags
+	}
+
+	parallelSem := NewSemaphore(par)
+	var refresh *refreshLimiter
+	if opts.RefreshConcurrency > 0 {
+		refresh = newRefreshLimiter(parallelSem, opts.RefreshConcurrency)
+	}
+	var tuner *applyTuner
+	if opts.TuneParallelism {
+		tuner = newApplyTuner(parallelSem, par)
+	}
+
 	plugins := newContextPlugins(opts.Providers, opts.Provisioners)
 
 	log.Printf("[TRACE] tofu.NewContext: complete")
@@ -145,7 +191,10 @@ func NewContext(opts *ContextOpts) (*Context, tfdiags.Diagnostics) {
 
 		plugins: plugins,
 
-		parallelSem:         NewSemaphore(par),
+		parallelSem:         parallelSem,
+		refreshLimiter:      refresh,
+		applyTuner:          tuner,
+		allowedActions:      opts.AllowedApplyActions,
 		providerInputConfig: make(map[string]map[string]cty.Value),
 		sh:                  sh,
 
@@ -168,6 +217,13 @@ func (c *Context) Schemas(config *configs.Config, state *states.State) (*Schemas
 	return ret, diags
 }
 
+// ApplyParallelism returns the parallelism that was chosen for the apply
+// requests to each provider configuration, if the context was created with
+// TuneParallelism set. Otherwise it returns nil.
+func (c *Context) ApplyParallelism() []ProviderParallelism {
+	return c.applyTuner.Report()
+}
+
 type ContextGraphOpts struct {
 	// If true, validates the graph structure (checks for cycles).
 	Validate bool
@@ -335,8 +391,9 @@ func (c *Context) watchStop(walker *ContextGraphWalker) (chan struct{}, <-chan s
 // support the given configuration, returning error diagnostics if not.
 //
 // Currently this function checks whether the current OpenTofu CLI version
-// matches the version requirements of all of the modules, and whether our
-// plugin library contains all of the plugin names/addresses needed.
+// matches the version requirements of all of the modules, whether our
+// plugin library contains all of the plugin names/addresses needed, and
+// whether those providers announce the capabilities that the modules require.
 //
 // This function does *not* check that external modules are installed (that's
 // the responsibility of the configuration loader) and doesn't check that the
@@ -366,25 +423,25 @@ func (c *Context) checkConfigDependencies(config *configs.Config) tfdiags.Diagno
 	for providerAddr := range providerReqs {
 		if !c.plugins.HasProvider(providerAddr) {
 			if !providerAddr.IsBuiltIn() {
-				diags = diags.Append(tfdiags.Sourceless(
+				diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
 					tfdiags.Error,
 					"Missing required provider",
 					fmt.Sprintf(
 						"This configuration requires provider %s, but that provider isn't available. You may be able to install it automatically by running:\n  tofu init",
 						providerAddr,
 					),
-				))
+				), tfdiags.RuleMissingRequiredProvider))
 			} else {
 				// Built-in providers can never be installed by "tofu init",
 				// so no point in confusing the user by suggesting that.
-				diags = diags.Append(tfdiags.Sourceless(
+				diags = diags.Append(tfdiags.WithRule(tfdiags.Sourceless(
 					tfdiags.Error,
 					"Missing required provider",
 					fmt.Sprintf(
 						"This configuration requires built-in provider %s, but that provider isn't available in this OpenTofu version.",
 						providerAddr,
 					),
-				))
+				), tfdiags.RuleMissingRequiredProvider))
 			}
 		}
 	}
@@ -440,5 +497,84 @@ func (c *Context) checkConfigDependencies(config *configs.Config) tfdiags.Diagno
 		}
 	})
 
+	if !diags.HasErrors() {
+		diags = diags.Append(c.checkProviderCapabilities(config))
+	}
+
+	return diags
+}
+
+// checkProviderCapabilities checks that the providers announce all of the
+// capabilities that the modules list in their required_providers blocks, so
+// that modules relying on newer provider behaviors fail early with older
+// provider versions, rather than behaving differently.
+//
+// Requiring a capability means that OpenTofu must start the provider to get
+// its schema, which it does for any operation that uses the provider anyway.
+func (c *Context) checkProviderCapabilities(config *configs.Config) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	config.DeepEach(func(modCfg *configs.Config) {
+		if modCfg == nil || modCfg.Module == nil || modCfg.Module.ProviderRequirements == nil {
+			return
+		}
+
+		names := make([]string, 0, len(modCfg.Module.ProviderRequirements.RequiredProviders))
+		for name := range modCfg.Module.ProviderRequirements.RequiredProviders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			req := modCfg.Module.ProviderRequirements.RequiredProviders[name]
+			if len(req.Capabilities) == 0 {
+				continue
+			}
+
+			var unknown []string
+			for _, capability := range req.Capabilities {
+				if !providers.IsServerCapabilityName(capability) {
+					unknown = append(unknown, capability)
+				}
+			}
+			if len(unknown) > 0 {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unknown provider capability",
+					Detail: fmt.Sprintf(
+						"The required capabilities of provider %q include %s, which OpenTofu doesn't know. The known capabilities are %s.",
+						name, quotedList(unknown), quotedList(providers.ServerCapabilityNames()),
+					),
+					Subject: req.CapabilitiesRange.Ptr(),
+				})
+				continue
+			}
+
+			schema, err := c.plugins.ProviderSchema(req.Type)
+			if err != nil {
+				// Failing to get the schema is reported by the operation
+				// itself, with more context than we have here.
+				continue
+			}
+			var missing []string
+			for _, capability := range req.Capabilities {
+				if !schema.ServerCapabilities.Has(capability) {
+					missing = append(missing, capability)
+				}
+			}
+			if len(missing) > 0 {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Provider lacks required capabilities",
+					Detail: fmt.Sprintf(
+						"This module requires provider %s to support %s, but the installed version of the provider doesn't announce these capabilities. Select a version of the provider that supports them, then run \"tofu init -upgrade\".",
+						req.Type.ForDisplay(), quotedList(missing),
+					),
+					Subject: req.CapabilitiesRange.Ptr(),
+				})
+			}
+		}
+	})
+
 	return diags
 }
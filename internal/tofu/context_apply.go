@@ -9,6 +9,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/zclconf/go-cty/cty"
 
@@ -41,6 +43,18 @@ func (c *Context) Apply(ctx context.Context, plan *plans.Plan, config *configs.C
 		))
 		return nil, diags
 	}
+	if plan.UIMode == plans.DriftOnlyMode {
+		var diags tfdiags.Diagnostics
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot apply drift-only plan",
+			`The given plan only reports changes made outside of OpenTofu, and so it cannot be applied.`,
+		))
+		return nil, diags
+	}
+	if diags := c.checkAllowedActions(plan); diags.HasErrors() {
+		return nil, diags
+	}
 
 	for _, rc := range plan.Changes.Resources {
 		// Import is a no-op change during an apply (all the real action happens during the plan) but we'd
@@ -55,6 +69,15 @@ func (c *Context) Apply(ctx context.Context, plan *plans.Plan, config *configs.C
 		}
 	}
 
+	if plan.CanSkipApply() {
+		// Walking the apply graph would only launch the providers to confirm
+		// that there's nothing to do, so we can just adopt the prior state.
+		log.Printf("[INFO] Plan has no changes or pending checks, so skipping the apply graph walk")
+		newState := plan.PriorState.DeepCopy()
+		newState.CheckResults = plan.Checks.DeepCopy()
+		return newState, nil
+	}
+
 	providerFunctionTracker := make(ProviderFunctionMapping)
 
 	graph, operation, diags := c.applyGraph(plan, config, providerFunctionTracker)
@@ -75,6 +98,7 @@ func (c *Context) Apply(ctx context.Context, plan *plans.Plan, config *configs.C
 
 		// We also want to propagate the timestamp from the plan file.
 		PlanTimeTimestamp:       plan.Timestamp,
+		PlanTimeRunID:           plan.RunID,
 		ProviderFunctionTracker: providerFunctionTracker,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
@@ -123,6 +147,69 @@ Note that the -target and -exclude options are not suitable for routine use, and
 	return newState, diags
 }
 
+// checkAllowedActions returns an error if the given plan includes any
+// resource instance changes that the context's AllowedApplyActions doesn't
+// allow. It's checked before the apply walk begins, so that no change is
+// applied at all if any of them isn't allowed.
+func (c *Context) checkAllowedActions(plan *plans.Plan) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(c.allowedActions) == 0 {
+		return diags
+	}
+
+	allowed := make(map[plans.Action]bool, len(c.allowedActions)+2)
+	allowed[plans.NoOp] = true
+	allowed[plans.Read] = true
+	for _, action := range c.allowedActions {
+		allowed[action] = true
+	}
+
+	var disallowed []string
+	for _, rc := range plan.Changes.Resources {
+		if allowed[rc.Action] {
+			continue
+		}
+		addr := rc.Addr.String()
+		if rc.DeposedKey != states.NotDeposed {
+			addr = fmt.Sprintf("%s (deposed object %s)", addr, rc.DeposedKey)
+		}
+		disallowed = append(disallowed, fmt.Sprintf("  - %s: %s", addr, actionDescription(rc.Action)))
+	}
+	if len(disallowed) == 0 {
+		return diags
+	}
+	sort.Strings(disallowed)
+
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Plan includes disallowed changes",
+		fmt.Sprintf(
+			"The plan includes changes of kinds that aren't allowed for this apply, so none of the changes were applied:\n\n%s",
+			strings.Join(disallowed, "\n"),
+		),
+	))
+	return diags
+}
+
+// actionDescription returns a short description of the given action for
+// use in error messages.
+func actionDescription(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "create"
+	case plans.Update:
+		return "update in-place"
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return "replace"
+	case plans.Delete:
+		return "destroy"
+	case plans.Forget:
+		return "forget"
+	default:
+		return action.String()
+	}
+}
+
 func (c *Context) applyGraph(plan *plans.Plan, config *configs.Config, providerFunctionTracker ProviderFunctionMapping) (*Graph, walkOperation, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
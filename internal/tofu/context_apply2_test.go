@@ -458,6 +458,73 @@ resource "test_resource" "b" {
 	}
 }
 
+func TestContext2Apply_secretInputVariable(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "password" {
+  type    = string
+  secret  = true
+  default = "hunter2"
+}
+
+resource "test_object" "a" {
+  test_string = var.password
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables)))
+	assertNoErrors(t, diags)
+
+	state, diags := ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	obj := state.ResourceInstance(mustResourceInstanceAddr("test_object.a")).Current
+	var secret bool
+	for _, pvm := range obj.AttrSensitivePaths {
+		if _, ok := pvm.Marks[marks.Secret]; ok && pvm.Path.Equals(cty.GetAttrPath("test_string")) {
+			secret = true
+		}
+	}
+	if !secret {
+		t.Fatalf("test_string isn't marked as secret in the state: %#v", obj.AttrSensitivePaths)
+	}
+}
+
+func TestContext2Plan_secretRootOutput(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "password" {
+  type    = string
+  secret  = true
+  default = "hunter2"
+}
+
+output "password" {
+  value     = var.password
+  sensitive = true
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables)))
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want an error")
+	}
+	if got, want := diags.Err().Error(), "Output refers to secret values"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestContext2Apply_sensitiveOutputPassthrough(t *testing.T) {
 	// Ensure we're not trying to double-mark values decoded from state
 	m := testModuleInline(t, map[string]string{
@@ -2172,6 +2239,63 @@ resource "test_resource" "b" {
 	assertNoErrors(t, diags)
 }
 
+func TestContext2Apply_runID(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_resource" "a" {
+  value = tofu.run_id
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Required: true,
+					},
+				},
+			},
+		},
+	})
+	var applied string
+	p.ApplyResourceChangeFn = func(request providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+		applied = request.PlannedState.GetAttr("value").AsString()
+		return providers.ApplyResourceChangeResponse{
+			NewState: request.PlannedState,
+		}
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Meta: &ContextMeta{RunID: "plan-run"},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+	if got, want := plan.RunID, "plan-run"; got != want {
+		t.Fatalf("wrong run ID in the plan %q; want %q", got, want)
+	}
+
+	// Applying a saved plan happens in a later run of OpenTofu, with a
+	// different run ID, but tofu.run_id must keep its value from the plan.
+	ctx = testContext2(t, &ContextOpts{
+		Meta: &ContextMeta{RunID: "apply-run"},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	_, diags = ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+	if got, want := applied, "plan-run"; got != want {
+		t.Fatalf("wrong applied value %q; want %q", got, want)
+	}
+}
+
 func TestContext2Apply_destroyUnusedModuleProvider(t *testing.T) {
 	// an unused provider within a module should not be called during destroy
 	unusedProvider := testProvider("unused")
@@ -2289,6 +2413,52 @@ import {
 	}
 }
 
+func TestContext2Apply_progressiveOutputs(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+}
+
+output "a" {
+  value = test_object.a.test_string
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	hook := new(MockHook)
+	ctx := testContext2(t, &ContextOpts{
+		Hooks: []Hook{hook},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	_, diags = ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	if !hook.PostApplyOutputCalled {
+		t.Fatal("PostApplyOutput hook not called")
+	}
+	wantAddr := addrs.OutputValue{Name: "a"}.Absolute(addrs.RootModuleInstance)
+	if !hook.PostApplyOutputAddr.Equal(wantAddr) {
+		t.Errorf("expected addr to be %s, but was %s", wantAddr, hook.PostApplyOutputAddr)
+	}
+	if got, want := hook.PostApplyOutputValue, cty.StringVal("foo"); !got.RawEquals(want) {
+		t.Errorf("wrong value %#v; want %#v", got, want)
+	}
+
+	// The output value must be in the state that the hooks received before
+	// the output value was published.
+	if ov := hook.PostStateUpdateState.OutputValue(wantAddr); ov == nil || !ov.Value.RawEquals(cty.StringVal("foo")) {
+		t.Errorf("output value not saved in the state before it was published: %#v", ov)
+	}
+}
+
 func TestContext2Apply_noExternalReferences(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
@@ -4966,3 +5136,381 @@ variable "res_data" {
 		t.Fatalf("Expected: %q, got %q", want, got)
 	}
 }
+
+func TestContext2Apply_noChangesSkipsWalk(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+
+  lifecycle {
+    postcondition {
+      condition     = self.test_string == "foo"
+      error_message = "Wrong value."
+    }
+  }
+}
+
+output "value" {
+  value = test_object.a.test_string
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.a"),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{"test_string":"foo"}`),
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			addrs.NoKey,
+		)
+		s.SetOutputValue(addrs.OutputValue{Name: "value"}.Absolute(addrs.RootModuleInstance), cty.StringVal("foo"), false)
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+	if !plan.CanSkipApply() {
+		t.Fatalf("plan should not need an apply walk")
+	}
+
+	// A new provider and context, so we can tell whether applying the plan
+	// launched the provider.
+	p = simpleMockProvider()
+	ctx = testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	newState, diags := ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	if p.GetProviderSchemaCalled || p.ConfigureProviderCalled {
+		t.Errorf("provider was launched for a plan without changes")
+	}
+	if !newState.ManagedResourcesEqual(plan.PriorState) {
+		t.Errorf("wrong resources in new state\n%s", newState)
+	}
+	if got := newState.RootModule().OutputValues["value"]; got == nil || got.Value != cty.StringVal("foo") {
+		t.Errorf("wrong output value: %#v", got)
+	}
+	if newState.CheckResults == nil || newState.CheckResults.GetObjectResult(mustResourceInstanceAddr("test_object.a")) == nil {
+		t.Errorf("missing check results in new state")
+	}
+}
+
+// ephemeralResourceTestProviders returns a "vault" provider whose ephemeral
+// resource "vault_token" returns a token, and a "test" provider that is
+// configured with a token.
+func ephemeralResourceTestProviders() (vault *MockProvider, test *MockProvider) {
+	vault = &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Provider: providers.Schema{Block: &configschema.Block{}},
+			EphemeralResources: map[string]providers.Schema{
+				"vault_token": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"name":  {Type: cty.String, Required: true},
+							"token": {Type: cty.String, Computed: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	vault.OpenEphemeralResourceFn = func(req providers.OpenEphemeralResourceRequest) (resp providers.OpenEphemeralResourceResponse) {
+		name := req.Config.GetAttr("name").AsString()
+		resp.Result = cty.ObjectVal(map[string]cty.Value{
+			"name":  cty.StringVal(name),
+			"token": cty.StringVal(name + "-secret"),
+		})
+		resp.Private = []byte(name)
+		return resp
+	}
+
+	test = &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Provider: providers.Schema{
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"token": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+			ResourceTypes: map[string]providers.Schema{
+				"test_object": {Block: simpleTestSchema()},
+			},
+		},
+	}
+	return vault, test
+}
+
+func TestContext2Apply_ephemeralResource(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+ephemeral "vault_token" "main" {
+  name = "ci"
+}
+
+provider "test" {
+  token = ephemeral.vault_token.main.token
+}
+
+resource "test_object" "a" {
+  test_string = "a"
+}
+`,
+	})
+
+	vault, test := ephemeralResourceTestProviders()
+	var mu sync.Mutex
+	opened, closed := 0, 0
+	openFn := vault.OpenEphemeralResourceFn
+	vault.OpenEphemeralResourceFn = func(req providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+		mu.Lock()
+		opened++
+		mu.Unlock()
+		return openFn(req)
+	}
+	vault.CloseEphemeralResourceFn = func(req providers.CloseEphemeralResourceRequest) (resp providers.CloseEphemeralResourceResponse) {
+		if got, want := string(req.Private), "ci"; got != want {
+			t.Errorf("wrong private data\ngot:  %s\nwant: %s", got, want)
+		}
+		// The token must stay valid until the provider that uses it is done.
+		test.Lock()
+		testClosed := test.CloseCalled
+		test.Unlock()
+		if !testClosed {
+			t.Errorf("token closed before the provider that uses it")
+		}
+		mu.Lock()
+		closed++
+		mu.Unlock()
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("vault"): testProviderFuncFixed(vault),
+			addrs.NewDefaultProvider("test"):  testProviderFuncFixed(test),
+		},
+	})
+
+	diags := ctx.Validate(context.Background(), m)
+	assertNoErrors(t, diags)
+	if !vault.ValidateEphemeralResourceConfigCalled {
+		t.Fatalf("ephemeral resource configuration not validated")
+	}
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	if got, want := test.ConfigureProviderRequest.Config.GetAttr("token"), cty.StringVal("ci-secret"); !got.RawEquals(want) {
+		t.Fatalf("wrong provider token during plan\ngot:  %#v\nwant: %#v", got, want)
+	}
+	addr := mustResourceInstanceAddr("ephemeral.vault_token.main")
+	if rs := plan.PriorState.ResourceInstance(addr); rs != nil {
+		t.Fatalf("ephemeral resource saved in the prior state")
+	}
+	if change := plan.Changes.ResourceInstance(addr); change != nil {
+		t.Fatalf("ephemeral resource saved in the plan")
+	}
+
+	test.ConfigureProviderRequest = providers.ConfigureProviderRequest{}
+	state, diags := ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	// The token is opened again during apply, since the plan doesn't save it.
+	if got, want := test.ConfigureProviderRequest.Config.GetAttr("token"), cty.StringVal("ci-secret"); !got.RawEquals(want) {
+		t.Fatalf("wrong provider token during apply\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if opened != 2 || closed != 2 {
+		t.Fatalf("token opened %d times and closed %d times, want 2 and 2", opened, closed)
+	}
+	if rs := state.ResourceInstance(addr); rs != nil {
+		t.Fatalf("ephemeral resource saved in the state")
+	}
+	if rs := state.ResourceInstance(mustResourceInstanceAddr("test_object.a")); rs == nil {
+		t.Fatalf("test_object.a not created")
+	}
+}
+
+func TestContext2Apply_resourceNote(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "noted" {
+  lifecycle {
+    note = "pending decommission JIRA-123"
+  }
+}
+
+resource "test_object" "annotated" {
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	// The instance without a note in its configuration keeps the note it
+	// already has in the state.
+	annotatedAddr := mustResourceInstanceAddr("test_object.annotated")
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			annotatedAddr,
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			addrs.NoKey,
+		)
+		s.SetResourceInstanceNote(annotatedAddr, "owned by the platform team")
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+	if got, want := plan.PriorState.ResourceInstance(annotatedAddr).Note, "owned by the platform team"; got != want {
+		t.Fatalf("wrong note in prior state\ngot:  %q\nwant: %q", got, want)
+	}
+
+	state, diags = ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	for addr, want := range map[string]string{
+		"test_object.noted":     "pending decommission JIRA-123",
+		"test_object.annotated": "owned by the platform team",
+	} {
+		if got := state.ResourceInstance(mustResourceInstanceAddr(addr)).Note; got != want {
+			t.Fatalf("wrong note for %s\ngot:  %q\nwant: %q", addr, got, want)
+		}
+	}
+
+	// Once the instance exists, its note is in the prior state of the next
+	// plan, which is what the plan renderer shows.
+	plan, diags = ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+	if got, want := plan.PriorState.ResourceInstance(mustResourceInstanceAddr("test_object.noted")).Note, "pending decommission JIRA-123"; got != want {
+		t.Fatalf("wrong note in prior state\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestContext2Apply_tuneParallelism(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			resource "test_object" "a" {
+				count = 4
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Parallelism:     2,
+		TuneParallelism: true,
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+	if report := ctx.ApplyParallelism(); len(report) != 0 {
+		t.Fatalf("unexpected parallelism report after plan: %#v", report)
+	}
+
+	_, diags = ctx.Apply(context.Background(), plan, m)
+	assertNoErrors(t, diags)
+
+	report := ctx.ApplyParallelism()
+	if len(report) != 1 {
+		t.Fatalf("wrong number of providers in parallelism report %d; want 1", len(report))
+	}
+	if got, want := report[0].Provider.String(), `provider["registry.opentofu.org/hashicorp/test"]`; got != want {
+		t.Errorf("wrong provider\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := report[0].Requests, 4; got != want {
+		t.Errorf("wrong number of requests %d; want %d", got, want)
+	}
+	if got := report[0].Limit; got < 1 || got > 2 {
+		t.Errorf("limit %d is outside of the overall parallelism", got)
+	}
+}
+
+func TestContext2Apply_allowedApplyActions(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			resource "test_object" "a" {
+			}
+		`,
+	})
+
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.old").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"test_string":"old"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		addrs.NoKey,
+	)
+
+	tests := map[string]struct {
+		allowed []plans.Action
+		wantErr string
+	}{
+		"unconstrained": {
+			allowed: nil,
+		},
+		"all allowed": {
+			allowed: []plans.Action{plans.Create, plans.Delete},
+		},
+		"delete not allowed": {
+			allowed: []plans.Action{plans.Create, plans.Update},
+			wantErr: "  - test_object.old: destroy",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := simpleMockProvider()
+			ctx := testContext2(t, &ContextOpts{
+				AllowedApplyActions: test.allowed,
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+				},
+			})
+
+			plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+			assertNoErrors(t, diags)
+
+			_, diags = ctx.Apply(context.Background(), plan, m)
+			if test.wantErr == "" {
+				assertNoErrors(t, diags)
+				return
+			}
+			if !diags.HasErrors() {
+				t.Fatal("apply succeeded; want error")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, test.wantErr) {
+				t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+			}
+			if p.ApplyResourceChangeCalled {
+				t.Fatal("provider was asked to apply changes")
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ package tofu
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/zclconf/go-cty/cty"
@@ -656,6 +657,157 @@ check "error" {
 				},
 			},
 		},
+		"error severity": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "failing" {
+  severity = "error"
+
+  data "checks_object" "positive" {}
+
+  assert {
+    condition     = data.checks_object.positive.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			planError: "Check block assertion failed: negative number",
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						State: cty.ObjectVal(map[string]cty.Value{
+							"number": cty.NumberIntVal(-1),
+						}),
+					}
+				},
+			},
+		},
+		"apply phase only": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "apply_only" {
+  phases = ["apply"]
+
+  data "checks_object" "positive" {}
+
+  assert {
+    condition     = data.checks_object.positive.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			plan: map[string]checksTestingStatus{
+				"apply_only": {
+					status: checks.StatusUnknown,
+				},
+			},
+			apply: map[string]checksTestingStatus{
+				"apply_only": {
+					status:   checks.StatusFail,
+					messages: []string{"negative number"},
+				},
+			},
+			applyWarning: "Check block assertion failed: negative number",
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						State: cty.ObjectVal(map[string]cty.Value{
+							"number": cty.NumberIntVal(-1),
+						}),
+					}
+				},
+			},
+		},
+		"plan phase only": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "plan_only" {
+  phases = ["plan"]
+
+  data "checks_object" "positive" {}
+
+  assert {
+    condition     = data.checks_object.positive.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			plan: map[string]checksTestingStatus{
+				"plan_only": {
+					status:   checks.StatusFail,
+					messages: []string{"negative number"},
+				},
+			},
+			planWarning: "Check block assertion failed: negative number",
+			apply: map[string]checksTestingStatus{
+				"plan_only": {
+					status: checks.StatusUnknown,
+				},
+			},
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						State: cty.ObjectVal(map[string]cty.Value{
+							"number": cty.NumberIntVal(-1),
+						}),
+					}
+				},
+			},
+		},
 		"invalid reference into check block": {
 			configs: map[string]string{
 				"main.tf": `
@@ -820,3 +972,114 @@ func validateCheckResults(t *testing.T, stage string, expected map[string]checks
 
 	}
 }
+
+func TestContextChecks_checksOnly(t *testing.T) {
+	configs := testModuleInline(t, map[string]string{
+		"main.tf": `
+provider "checks" {}
+
+data "checks_object" "outside" {}
+
+check "nested" {
+  data "checks_object" "nested" {}
+
+  assert {
+    condition     = data.checks_object.nested.number >= 0
+    error_message = "negative number"
+  }
+}
+
+check "outside" {
+  phases = ["check"]
+
+  assert {
+    condition     = data.checks_object.outside.number >= 0
+    error_message = "negative number"
+  }
+}
+
+check "plan_only" {
+  phases = ["plan"]
+
+  assert {
+    condition     = data.checks_object.outside.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+	})
+
+	var read []string
+	var readMu sync.Mutex
+	provider := &MockProvider{
+		Meta: "checks",
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			DataSources: map[string]providers.Schema{
+				"checks_object": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"number": {
+								Type:     cty.Number,
+								Computed: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+			readMu.Lock()
+			read = append(read, request.TypeName)
+			readMu.Unlock()
+			return providers.ReadDataSourceResponse{
+				State: cty.ObjectVal(map[string]cty.Value{
+					"number": cty.NumberIntVal(1),
+				}),
+			}
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("checks"): testProviderFuncFixed(provider),
+		},
+	})
+
+	// The data resource outside of the check blocks is in the state with a
+	// value that fails the "outside" check.
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("data.checks_object.outside"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"number":-1}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/checks"]`),
+			addrs.NoKey,
+		)
+	})
+
+	plan, diags := ctx.Plan(context.Background(), configs, state, &PlanOpts{
+		Mode:       plans.RefreshOnlyMode,
+		ChecksOnly: true,
+	})
+	if validateCheckDiagnostics(t, "checking", "Check block assertion failed: negative number", "", diags) {
+		return
+	}
+	validateCheckResults(t, "checking", map[string]checksTestingStatus{
+		"nested": {
+			status: checks.StatusPass,
+		},
+		"outside": {
+			status:   checks.StatusFail,
+			messages: []string{"negative number"},
+		},
+		"plan_only": {
+			status: checks.StatusUnknown,
+		},
+	}, plan.Checks)
+
+	// Only the data resource nested in the check block is read.
+	if len(read) != 1 {
+		t.Errorf("expected one data source read, got %d", len(read))
+	}
+}
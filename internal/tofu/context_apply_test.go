@@ -196,8 +196,12 @@ func TestContext2Apply_stop(t *testing.T) {
 	gotEvents := hook.Calls
 	wantEvents := []*testHookCall{
 		{"PreDiff", "indefinite.foo"},
+		{"PreProviderCall", "indefinite.foo"},
+		{"PostProviderCall", "indefinite.foo"},
 		{"PostDiff", "indefinite.foo"},
 		{"PreApply", "indefinite.foo"},
+		{"PreProviderCall", "indefinite.foo"},
+		{"PostProviderCall", "indefinite.foo"},
 		{"PostApply", "indefinite.foo"},
 		{"PostStateUpdate", ""}, // State gets updated one more time to include the apply result.
 	}
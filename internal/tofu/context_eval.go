@@ -7,6 +7,7 @@ package tofu
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -18,6 +19,12 @@ import (
 
 type EvalOpts struct {
 	SetVariables InputValues
+
+	// ProviderFunctions, if set, is used to call any provider functions in
+	// expressions evaluated in the returned scope. Otherwise, those
+	// functions are unavailable, because the providers that were started
+	// during the evaluation walk are closed before Eval returns.
+	ProviderFunctions *ProviderFunctions
 }
 
 // Eval produces a scope in which expressions can be evaluated for
@@ -34,10 +41,10 @@ type EvalOpts struct {
 // for type checking.
 //
 // The result is an evaluation scope that can be used to resolve references
-// against the root module. If the returned diagnostics contains errors then
-// the returned scope may be nil. If it is not nil then it may still be used
-// to attempt expression evaluation or other analysis, but some expressions
-// may not behave as expected.
+// against the given module instance, which must exist. If the returned
+// diagnostics contains errors then the returned scope may be nil. If it is
+// not nil then it may still be used to attempt expression evaluation or other
+// analysis, but some expressions may not behave as expected.
 func (c *Context) Eval(ctx context.Context, config *configs.Config, state *states.State, moduleAddr addrs.ModuleInstance, opts *EvalOpts) (*lang.Scope, tfdiags.Diagnostics) {
 	// This is intended for external callers such as the "tofu console"
 	// command. Internally, we create an evaluator in c.walk before walking
@@ -46,6 +53,15 @@ func (c *Context) Eval(ctx context.Context, config *configs.Config, state *state
 	var diags tfdiags.Diagnostics
 	defer c.acquireRun("eval")()
 
+	if config.DescendentForInstance(moduleAddr) == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Module not found",
+			fmt.Sprintf("The configuration has no module %s.", moduleAddr),
+		))
+		return nil, diags
+	}
+
 	// Start with a copy of state so that we don't affect the instance that
 	// the caller is holding.
 	state = state.DeepCopy()
@@ -83,6 +99,7 @@ func (c *Context) Eval(ctx context.Context, config *configs.Config, state *state
 		InputState:              state,
 		Config:                  config,
 		ProviderFunctionTracker: providerFunctionTracker,
+		ProviderFunctions:       opts.ProviderFunctions,
 	}
 
 	walker, moreDiags = c.walk(ctx, graph, walkEval, walkOpts)
@@ -101,6 +118,15 @@ func (c *Context) Eval(ctx context.Context, config *configs.Config, state *state
 	// just to get hold of an EvalContext for it. ContextGraphWalker
 	// caches its contexts, so we should get hold of the context that was
 	// previously used for evaluation here, unless we skipped walking.
+	if !moduleAddr.IsRoot() && !diags.HasErrors() && !walker.InstanceExpander.AllInstances().HasModuleInstance(moduleAddr) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Module instance not found",
+			fmt.Sprintf("The configuration declares %s, but it has no instance %s. Use the instance keys that its count or for_each argument produces.", moduleAddr.Module(), moduleAddr),
+		))
+		return nil, diags
+	}
+
 	evalCtx := walker.EnterPath(moduleAddr)
 	return evalCtx.EvaluationScope(nil, nil, EvalDataForNoInstanceKey), diags
 }
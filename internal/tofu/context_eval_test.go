@@ -7,6 +7,7 @@ package tofu
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -14,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -134,3 +136,118 @@ output "out" {
 	})
 	assertNoErrors(t, diags)
 }
+
+func TestContextEval_providerFunctions(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+terraform {
+  required_providers {
+    test = {
+      source = "hashicorp/test"
+    }
+  }
+}
+
+locals {
+  greeting = provider::test::echo("hello")
+}
+`,
+	})
+
+	p := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Functions: map[string]providers.FunctionSpec{
+				"echo": {
+					Parameters: []providers.FunctionParameterSpec{{
+						Name: "input",
+						Type: cty.String,
+					}},
+					Return: cty.String,
+				},
+				"upper": {
+					Parameters: []providers.FunctionParameterSpec{{
+						Name: "input",
+						Type: cty.String,
+					}},
+					Return: cty.String,
+				},
+			},
+		},
+	}
+	p.CallFunctionFn = func(req providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+		switch req.Name {
+		case "echo":
+			resp.Result = req.Arguments[0]
+		case "upper":
+			resp.Result = cty.StringVal(strings.ToUpper(req.Arguments[0].AsString()))
+		}
+		return resp
+	}
+	factories := map[addrs.Provider]providers.Factory{
+		addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: factories,
+	})
+
+	evaluate := func(t *testing.T, opts *EvalOpts, input string) (cty.Value, tfdiags.Diagnostics) {
+		t.Helper()
+		scope, diags := ctx.Eval(context.Background(), m, states.NewState(), addrs.RootModuleInstance, opts)
+		assertNoErrors(t, diags)
+
+		expr, hclDiags := hclsyntax.ParseExpression([]byte(input), "<test-input>", hcl.InitialPos)
+		if hclDiags.HasErrors() {
+			t.Fatal(hclDiags.Error())
+		}
+		return scope.EvalExpr(expr, cty.DynamicPseudoType)
+	}
+
+	funcs := NewProviderFunctions(factories)
+	defer funcs.Close()
+	opts := &EvalOpts{
+		SetVariables:      testInputValuesUnset(m.Module.Variables),
+		ProviderFunctions: funcs,
+	}
+
+	tests := map[string]cty.Value{
+		// Referenced by the configuration, so the provider was started and
+		// closed during the eval walk.
+		`provider::test::echo("hi")`: cty.StringVal("hi"),
+		// Not referenced by the configuration at all.
+		`provider::test::upper("hi")`: cty.StringVal("HI"),
+		`local.greeting`:              cty.StringVal("hello"),
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, diags := evaluate(t, opts, input)
+			assertNoErrors(t, diags)
+			if !got.RawEquals(want) {
+				t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+			}
+		})
+	}
+
+	t.Run("invalid argument", func(t *testing.T) {
+		_, diags := evaluate(t, opts, `provider::test::upper(["hi"])`)
+		if !strings.Contains(diags.Err().Error(), "Invalid function argument") {
+			t.Fatalf("wrong error: %s", diags.Err())
+		}
+		if p.CallFunctionRequest.Name == "upper" {
+			t.Fatal("provider was called with an invalid argument")
+		}
+	})
+
+	t.Run("unknown function", func(t *testing.T) {
+		_, diags := evaluate(t, opts, `provider::test::nope("hi")`)
+		if !strings.Contains(diags.Err().Error(), "Function not found in provider") {
+			t.Fatalf("wrong error: %s", diags.Err())
+		}
+	})
+
+	t.Run("no provider functions", func(t *testing.T) {
+		_, diags := evaluate(t, &EvalOpts{SetVariables: testInputValuesUnset(m.Module.Variables)}, `provider::test::upper("hi")`)
+		if !diags.HasErrors() {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -8,6 +8,7 @@ package tofu
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -69,16 +70,18 @@ func evalContextProviderFunction(provider providers.Interface, op walkOperation,
 		}
 	}
 
-	fn := providerFunction(pf.Function, spec, provider)
+	fn := providerFunction(pf.Function, spec, func() (providers.Interface, error) {
+		return provider, nil
+	})
 
 	return &fn, nil
 
 }
 
 // Turn a provider function spec into a cty callable function
-// This will use the instance factory to get a provider to support the
+// This will use the instance getter to get a provider to support the
 // function call.
-func providerFunction(name string, spec providers.FunctionSpec, provider providers.Interface) function.Function {
+func providerFunction(name string, spec providers.FunctionSpec, getProvider func() (providers.Interface, error)) function.Function {
 	params := make([]function.Parameter, len(spec.Parameters))
 	for i, param := range spec.Parameters {
 		params[i] = providerFunctionParameter(param)
@@ -91,6 +94,11 @@ func providerFunction(name string, spec providers.FunctionSpec, provider provide
 	}
 
 	impl := func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		provider, err := getProvider()
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+
 		resp := provider.CallFunction(providers.CallFunctionRequest{
 			Name:      name,
 			Arguments: args,
@@ -129,3 +137,97 @@ func providerFunctionParameter(spec providers.FunctionParameterSpec) function.Pa
 		AllowMarked: false,
 	}
 }
+
+// ProviderFunctions calls the functions of providers outside of a graph walk,
+// such as in "tofu console" or during static evaluation, where there's no
+// configured instance of the provider to call them on.
+//
+// The function signatures come from the cached provider schemas, so the
+// arguments of a call are validated before any provider is started. A
+// provider is started the first time one of its functions is called, and is
+// then reused for later calls until Close is called.
+type ProviderFunctions struct {
+	plugins *contextPlugins
+
+	mu        sync.Mutex
+	instances map[addrs.Provider]providers.Interface
+}
+
+// NewProviderFunctions creates a ProviderFunctions that starts providers
+// using the given factories.
+func NewProviderFunctions(factories map[addrs.Provider]providers.Factory) *ProviderFunctions {
+	return &ProviderFunctions{
+		plugins:   newContextPlugins(factories, nil),
+		instances: make(map[addrs.Provider]providers.Interface),
+	}
+}
+
+// Function returns the function with the given name from the given provider.
+// Only the functions declared in the provider schema are available, because
+// the provider isn't configured.
+func (p *ProviderFunctions) Function(provider addrs.Provider, name string, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if !p.plugins.HasProvider(provider) {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Provider not available",
+			Detail:   fmt.Sprintf("Function %q belongs to provider %s, which is not installed. Run \"tofu init\" to install it.", name, provider),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+
+	schema, err := p.plugins.ProviderSchema(provider)
+	if err != nil {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to load provider schema",
+			Detail:   fmt.Sprintf("Could not load the schema for provider %s: %s.", provider, err),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+	spec, ok := schema.Functions[name]
+	if !ok {
+		return nil, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Function not found in provider",
+			Detail:   fmt.Sprintf("Function %q was not registered by provider %s", name, provider),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+
+	fn := providerFunction(name, spec, func() (providers.Interface, error) {
+		return p.instance(provider)
+	})
+	return &fn, diags
+}
+
+func (p *ProviderFunctions) instance(provider addrs.Provider) (providers.Interface, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if inst, ok := p.instances[provider]; ok {
+		return inst, nil
+	}
+	inst, err := p.plugins.NewProviderInstance(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start provider %s: %w", provider, err)
+	}
+	p.instances[provider] = inst
+	return inst, nil
+}
+
+// Close stops all of the providers that were started to call functions.
+func (p *ProviderFunctions) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for addr, inst := range p.instances {
+		if err := inst.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close provider %s: %w", addr, err))
+		}
+		delete(p.instances, addr)
+	}
+	return errors.Join(errs...)
+}
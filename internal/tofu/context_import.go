@@ -187,6 +187,7 @@ func (ri *ImportResolver) resolveImport(importTarget *ImportTarget, ctx EvalCont
 			Summary:  fmt.Sprintf("Duplicate import configuration for %q", importAddress),
 			Detail:   fmt.Sprintf("An import block for the resource %q was already declared at %s. A resource can have only one import block.", importAddress, importTarget.Config.DeclRange),
 			Subject:  importTarget.Config.DeclRange.Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleDuplicateImport, nil),
 		})
 	}
 
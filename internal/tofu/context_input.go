@@ -104,6 +104,17 @@ func (c *Context) Input(ctx context.Context, config *configs.Config, mode InputM
 				log.Printf("[TRACE] Context.Input: Provider %s implied by data block at %s", pa, rc.DeclRange)
 			}
 		}
+		for _, rc := range config.Module.EphemeralResources {
+			pa := rc.ProviderConfigAddr()
+			if pa.Alias != "" {
+				continue // alias configurations cannot be implied
+			}
+			if _, exists := pcs[pa.String()]; !exists {
+				pcs[pa.String()] = nil
+				pas[pa.String()] = pa
+				log.Printf("[TRACE] Context.Input: Provider %s implied by ephemeral block at %s", pa, rc.DeclRange)
+			}
+		}
 
 		for pk, pa := range pas {
 			pc := pcs[pk] // will be nil if this is an implied config
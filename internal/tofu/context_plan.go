@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -78,6 +79,16 @@ type PlanOpts struct {
 	// warnings as part of the planning result.
 	Excludes []addrs.Targetable
 
+	// TargetGroups, if non-empty, also activates targeted planning mode,
+	// adding the managed resources whose lifecycle blocks assign them to any
+	// of these groups to Targets.
+	TargetGroups []string
+
+	// TargetStrict, if set, makes planning fail if the resources selected by
+	// Targets need changes to other managed resources, instead of planning
+	// those changes too.
+	TargetStrict bool
+
 	// ForceReplace is a set of resource instance addresses whose corresponding
 	// objects should be forced planned for replacement if the provider's
 	// plan would otherwise have been to either update the object in-place or
@@ -98,15 +109,36 @@ type PlanOpts struct {
 	// will be added to the plan graph.
 	ImportTargets []*ImportTarget
 
+	// ImportDiscoveries is a list of import blocks whose objects the provider
+	// lists during the plan, so that OpenTofu can generate an import block
+	// for each of them.
+	ImportDiscoveries []*ImportDiscovery
+
 	// EndpointsToRemove are the list of resources and modules to forget from
 	// the state.
 	EndpointsToRemove []addrs.ConfigRemovable
 
+	// EndpointsToDestroy are the list of resources and modules that removed
+	// blocks request to destroy.
+	EndpointsToDestroy []addrs.ConfigRemovable
+
 	// GenerateConfig tells OpenTofu where to write any generated configuration
 	// for any ImportTargets that do not have configuration already.
 	//
 	// If empty, then no config will be generated.
 	GenerateConfigPath string
+
+	// ModuleCache, if not nil, allows the plan to reuse the results of the
+	// previous plan for modules that haven't changed since. A successful
+	// plan records the new fingerprints in ModuleCache.Current.
+	ModuleCache *ModuleCache
+
+	// ChecksOnly, which requires the refresh-only mode, makes the plan only
+	// evaluate the check blocks that run in the "check" phase, against the
+	// current state. The plan then reads only the data resources nested in
+	// check blocks, and doesn't refresh managed resources. The results are
+	// in the Checks of the plan.
+	ChecksOnly bool
 }
 
 // Plan generates an execution plan by comparing the given configuration
@@ -166,6 +198,17 @@ func (c *Context) Plan(ctx context.Context, config *configs.Config, prevRunState
 			))
 			return nil, diags
 		}
+	case plans.DriftOnlyMode:
+		if opts.SkipRefresh {
+			// The CLI layer (and other similar callers) should prevent this
+			// combination of options.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"Cannot skip refreshing in drift-only mode. This is a bug in OpenTofu.",
+			))
+			return nil, diags
+		}
 	default:
 		// The CLI layer (and other similar callers) should not try to
 		// create a context for a mode that OpenTofu Core doesn't support.
@@ -176,6 +219,16 @@ func (c *Context) Plan(ctx context.Context, config *configs.Config, prevRunState
 		))
 		return nil, diags
 	}
+	if opts.ChecksOnly && opts.Mode != plans.RefreshOnlyMode {
+		// The CLI layer (and other similar callers) should prevent this
+		// combination of options.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan options",
+			fmt.Sprintf("Cannot evaluate only checks in %s. This is a bug in OpenTofu.", opts.Mode),
+		))
+		return nil, diags
+	}
 	if len(opts.ForceReplace) > 0 && opts.Mode != plans.NormalMode {
 		// The other modes don't generate no-op or update actions that we might
 		// upgrade to be "replace", so doesn't make sense to combine those.
@@ -197,6 +250,15 @@ func (c *Context) Plan(ctx context.Context, config *configs.Config, prevRunState
 	varDiags := checkInputVariables(config.Module.Variables, opts.SetVariables)
 	diags = diags.Append(varDiags)
 
+	if len(opts.TargetGroups) > 0 {
+		groupTargets, groupDiags := targetGroupTargets(config, opts.TargetGroups)
+		diags = diags.Append(groupDiags)
+		if groupDiags.HasErrors() {
+			return nil, diags
+		}
+		opts.Targets = append(slices.Clip(opts.Targets), groupTargets...)
+	}
+
 	if len(opts.Targets) > 0 || len(opts.Excludes) > 0 {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Warning,
@@ -214,7 +276,7 @@ The -target and -exclude options are not for routine use, and are provided only
 		plan, planDiags = c.plan(ctx, config, prevRunState, opts)
 	case plans.DestroyMode:
 		plan, planDiags = c.destroyPlan(ctx, config, prevRunState, opts)
-	case plans.RefreshOnlyMode:
+	case plans.RefreshOnlyMode, plans.DriftOnlyMode:
 		plan, planDiags = c.refreshOnlyPlan(ctx, config, prevRunState, opts)
 	default:
 		panic(fmt.Sprintf("unsupported plan mode %s", opts.Mode))
@@ -227,6 +289,7 @@ The -target and -exclude options are not for routine use, and are provided only
 
 	// convert the variables into the format expected for the plan
 	varVals := make(map[string]plans.DynamicValue, len(opts.SetVariables))
+	var secretVars map[string]bool
 	for k, iv := range opts.SetVariables {
 		if iv.Value == cty.NilVal {
 			continue // We only record values that the caller actually set
@@ -245,13 +308,20 @@ The -target and -exclude options are not for routine use, and are provided only
 			continue
 		}
 		varVals[k] = dv
+		if decl := config.Module.Variables[k]; decl != nil && decl.Secret {
+			if secretVars == nil {
+				secretVars = make(map[string]bool)
+			}
+			secretVars[k] = true
+		}
 	}
 
 	// insert the run-specific data from the context into the plan; variables,
 	// targets and provider SHAs.
 	if plan != nil {
 		plan.VariableValues = varVals
-		plan.TargetAddrs = opts.Targets
+		plan.SecretVariables = secretVars
+		plan.TargetAddrs = planTargetAddrs(opts.Targets, plan.Changes)
 		plan.ExcludeAddrs = opts.Excludes
 	} else if !diags.HasErrors() {
 		panic("nil plan but no errors")
@@ -261,6 +331,10 @@ The -target and -exclude options are not for routine use, and are provided only
 		relevantAttrs, rDiags := c.relevantResourceAttrsForPlan(config, plan)
 		diags = diags.Append(rDiags)
 		plan.RelevantAttributes = relevantAttrs
+
+		if opts.Mode == plans.NormalMode {
+			diags = diags.Append(c.checkRequiredTags(config, plan))
+		}
 	}
 
 	if diags.HasErrors() {
@@ -330,9 +404,14 @@ func (c *Context) plan(ctx context.Context, config *configs.Config, prevRunState
 		return nil, diags
 	}
 
+	var importDiscoveryDiags tfdiags.Diagnostics
+	opts.ImportDiscoveries, importDiscoveryDiags = c.findImportDiscoveries(config, opts.GenerateConfigPath)
+	diags = diags.Append(importDiscoveryDiags)
+
 	var endpointsToRemoveDiags tfdiags.Diagnostics
 	opts.EndpointsToRemove, endpointsToRemoveDiags = refactoring.GetEndpointsToRemove(config)
 	diags = diags.Append(endpointsToRemoveDiags)
+	opts.EndpointsToDestroy = refactoring.GetEndpointsToDestroy(config)
 
 	if diags.HasErrors() {
 		return nil, diags
@@ -347,7 +426,7 @@ func (c *Context) plan(ctx context.Context, config *configs.Config, prevRunState
 func (c *Context) refreshOnlyPlan(ctx context.Context, config *configs.Config, prevRunState *states.State, opts *PlanOpts) (*plans.Plan, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
-	if opts.Mode != plans.RefreshOnlyMode {
+	if !opts.Mode.RefreshesOnly() {
 		panic(fmt.Sprintf("called Context.refreshOnlyPlan with %s", opts.Mode))
 	}
 
@@ -645,6 +724,11 @@ func (c *Context) postPlanValidateImports(importResolver *ImportResolver, allIns
 func (c *Context) findImportTargets(config *configs.Config) []*ImportTarget {
 	var importTargets []*ImportTarget
 	for _, ic := range config.Module.Import {
+		if ic.Discover != nil {
+			// Import blocks with discover blocks don't import anything
+			// themselves, see findImportDiscoveries.
+			continue
+		}
 		importTargets = append(importTargets, &ImportTarget{
 			Config: ic,
 		})
@@ -756,8 +840,14 @@ func (c *Context) planWalk(ctx context.Context, config *configs.Config, prevRunS
 		return nil, diags
 	}
 	providerFunctionTracker := make(ProviderFunctionMapping)
+	moduleCache := newModuleCacheState(opts.ModuleCache, config, prevRunState, opts, moveResults)
 
-	graph, walkOp, moreDiags := c.planGraph(config, prevRunState, opts, providerFunctionTracker)
+	var targetDeps targetDependencies
+	if len(opts.Targets) > 0 {
+		targetDeps = make(targetDependencies)
+	}
+
+	graph, walkOp, moreDiags := c.planGraph(config, prevRunState, opts, providerFunctionTracker, moduleCache, targetDeps)
 	diags = diags.Append(moreDiags)
 	if diags.HasErrors() {
 		return nil, diags
@@ -775,9 +865,16 @@ func (c *Context) planWalk(ctx context.Context, config *configs.Config, prevRunS
 		MoveResults:             moveResults,
 		PlanTimeTimestamp:       timestamp,
 		ProviderFunctionTracker: providerFunctionTracker,
+		ModuleCache:             moduleCache,
+		ChecksOnly:              opts.ChecksOnly,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
 	diags = diags.Append(walkDiags)
+	diags = summarizeQuotaRisks(diags)
+	diags = diags.Append(targetDependencyDiags(opts.Targets, changes, targetDeps, opts.TargetStrict))
+	if opts.ModuleCache != nil && !diags.HasErrors() {
+		opts.ModuleCache.Current = moduleCache.results(changes)
+	}
 
 	allInsts := walker.InstanceExpander.AllInstances()
 
@@ -831,14 +928,16 @@ func (c *Context) planWalk(ctx context.Context, config *configs.Config, prevRunS
 		PlannedState:       walker.State.Close(),
 		ExternalReferences: opts.ExternalReferences,
 		Checks:             states.NewCheckResults(walker.Checks),
+		DiscoveredImports:  discoveredImports(opts.ImportDiscoveries),
 		Timestamp:          timestamp,
+		RunID:              walker.RunID,
 
 		// Other fields get populated by Context.Plan after we return
 	}
 	return plan, diags
 }
 
-func (c *Context) planGraph(config *configs.Config, prevRunState *states.State, opts *PlanOpts, providerFunctionTracker ProviderFunctionMapping) (*Graph, walkOperation, tfdiags.Diagnostics) {
+func (c *Context) planGraph(config *configs.Config, prevRunState *states.State, opts *PlanOpts, providerFunctionTracker ProviderFunctionMapping, moduleCache *moduleCacheState, targetDeps targetDependencies) (*Graph, walkOperation, tfdiags.Diagnostics) {
 	switch mode := opts.Mode; mode {
 	case plans.NormalMode:
 		graph, diags := (&PlanGraphBuilder{
@@ -854,12 +953,16 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			Operation:               walkPlan,
 			ExternalReferences:      opts.ExternalReferences,
 			ImportTargets:           opts.ImportTargets,
+			ImportDiscoveries:       opts.ImportDiscoveries,
 			GenerateConfigPath:      opts.GenerateConfigPath,
 			EndpointsToRemove:       opts.EndpointsToRemove,
+			EndpointsToDestroy:      opts.EndpointsToDestroy,
 			ProviderFunctionTracker: providerFunctionTracker,
+			TargetDependencies:      targetDeps,
+			ModuleCache:             moduleCache,
 		}).Build(addrs.RootModuleInstance)
 		return graph, walkPlan, diags
-	case plans.RefreshOnlyMode:
+	case plans.RefreshOnlyMode, plans.DriftOnlyMode:
 		graph, diags := (&PlanGraphBuilder{
 			Config:                  config,
 			State:                   prevRunState,
@@ -867,11 +970,12 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			Plugins:                 c.plugins,
 			Targets:                 opts.Targets,
 			Excludes:                opts.Excludes,
-			skipRefresh:             opts.SkipRefresh,
+			skipRefresh:             opts.SkipRefresh || opts.ChecksOnly,
 			skipPlanChanges:         true, // this activates "refresh only" mode.
 			Operation:               walkPlan,
 			ExternalReferences:      opts.ExternalReferences,
 			ProviderFunctionTracker: providerFunctionTracker,
+			TargetDependencies:      targetDeps,
 		}).Build(addrs.RootModuleInstance)
 		return graph, walkPlan, diags
 	case plans.DestroyMode:
@@ -885,6 +989,7 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			skipRefresh:             opts.SkipRefresh,
 			Operation:               walkPlanDestroy,
 			ProviderFunctionTracker: providerFunctionTracker,
+			TargetDependencies:      targetDeps,
 		}).Build(addrs.RootModuleInstance)
 		return graph, walkPlanDestroy, diags
 	default:
@@ -1052,7 +1157,7 @@ func (c *Context) PlanGraphForUI(config *configs.Config, prevRunState *states.St
 
 	opts := &PlanOpts{Mode: mode}
 
-	graph, _, moreDiags := c.planGraph(config, prevRunState, opts, make(ProviderFunctionMapping))
+	graph, _, moreDiags := c.planGraph(config, prevRunState, opts, make(ProviderFunctionMapping), nil, nil)
 	diags = diags.Append(moreDiags)
 	return graph, diags
 }
@@ -10,10 +10,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/google/go-cmp/cmp"
@@ -2285,6 +2288,157 @@ func TestContext2Plan_refreshOnlyMode(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_driftOnlyMode(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+
+	// As with refresh-only mode, the configuration change must not be
+	// considered, but the drift detected by refreshing must be reported and
+	// the resulting plan must not be applyable.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			resource "test_object" "a" {
+				arg = "after"
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"arg":"before"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		Provider: providers.Schema{Block: simpleTestSchema()},
+		ResourceTypes: map[string]providers.Schema{
+			"test_object": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"arg": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		return providers.ReadResourceResponse{
+			NewState: cty.ObjectVal(map[string]cty.Value{
+				"arg": cty.StringVal("current"),
+			}),
+		}
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode: plans.DriftOnlyMode,
+	})
+	assertNoErrors(t, diags)
+
+	if !p.ReadResourceCalled {
+		t.Errorf("Provider's ReadResource wasn't called; should've been")
+	}
+	if p.PlanResourceChangeCalled {
+		t.Errorf("Provider's PlanResourceChange was called; shouldn't have been")
+	}
+	if got, want := len(plan.Changes.Resources), 0; got != want {
+		t.Errorf("plan contains resource changes; want none\n%s", spew.Sdump(plan.Changes.Resources))
+	}
+	if got, want := len(plan.DriftedResources), 1; got != want {
+		t.Fatalf("wrong number of drifted resources %d; want %d\n%s", got, want, spew.Sdump(plan.DriftedResources))
+	}
+	if got, want := plan.DriftedResources[0].Action, plans.Update; got != want {
+		t.Errorf("wrong drift action %s; want %s", got, want)
+	}
+	if plan.CanApply() {
+		t.Errorf("drift-only plan is applyable; shouldn't be")
+	}
+
+	_, diags = ctx.Apply(context.Background(), plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("drift-only plan was applied without errors")
+	}
+	if got, want := diags.Err().Error(), "Cannot apply drift-only plan"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestContext2Plan_createBecauseDeleted(t *testing.T) {
+	addrA := mustResourceInstanceAddr("test_object.a")
+	addrB := mustResourceInstanceAddr("test_object.b")
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			resource "test_object" "a" {
+				arg = "a"
+			}
+
+			resource "test_object" "b" {
+				arg = "b"
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addrA, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"arg":"a"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		Provider: providers.Schema{Block: simpleTestSchema()},
+		ResourceTypes: map[string]providers.Schema{
+			"test_object": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"arg": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		// The remote object of test_object.a was deleted.
+		return providers.ReadResourceResponse{
+			NewState: cty.NullVal(req.PriorState.Type()),
+		}
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	for _, test := range []struct {
+		addr   addrs.AbsResourceInstance
+		reason plans.ResourceInstanceChangeActionReason
+	}{
+		{addrA, plans.ResourceInstanceCreateBecauseDeleted},
+		{addrB, plans.ResourceInstanceChangeNoReason},
+	} {
+		change := plan.Changes.ResourceInstance(test.addr)
+		if change == nil {
+			t.Fatalf("no change for %s", test.addr)
+		}
+		if got, want := change.Action, plans.Create; got != want {
+			t.Errorf("wrong action for %s %s; want %s", test.addr, got, want)
+		}
+		if got, want := change.ActionReason, test.reason; got != want {
+			t.Errorf("wrong action reason for %s %s; want %s", test.addr, got, want)
+		}
+	}
+}
+
 func TestContext2Plan_refreshOnlyMode_deposed(t *testing.T) {
 	addr := mustResourceInstanceAddr("test_object.a")
 	deposedKey := states.DeposedKey("byebye")
@@ -4017,6 +4171,85 @@ resource "test_object" "b" {
 	}
 }
 
+func TestContext2Plan_dataSourceCache(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+module "child" {
+  source = "./child"
+  count  = 3
+}
+
+data "test_data_source" "other" {
+  foo = "other"
+}
+
+data "test_data_source" "uncached" {
+  for_each = toset(["a", "b"])
+  foo      = "uncached"
+
+  lifecycle {
+    no_cache = true
+  }
+}
+`,
+		"child/main.tf": `
+data "test_data_source" "shared" {
+  foo = "shared"
+}
+
+output "id" {
+  value = data.test_data_source.shared.id
+}
+`,
+	})
+
+	p := testProvider("test")
+	var mu sync.Mutex
+	reads := make(map[string]int)
+	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		foo := req.Config.GetAttr("foo").AsString()
+		mu.Lock()
+		reads[foo]++
+		mu.Unlock()
+		resp.State = cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.StringVal(foo + "-id"),
+			"foo": req.Config.GetAttr("foo"),
+		})
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	// The three instances of the child module read the same data source with
+	// the same configuration, so the provider is only called once for them.
+	want := map[string]int{
+		"shared":   1,
+		"other":    1,
+		"uncached": 2,
+	}
+	if diff := cmp.Diff(want, reads); diff != "" {
+		t.Fatalf("wrong reads\n%s", diff)
+	}
+
+	for i := 0; i < 3; i++ {
+		addr := mustResourceInstanceAddr(fmt.Sprintf("module.child[%d].data.test_data_source.shared", i))
+		rs := plan.PriorState.ResourceInstance(addr)
+		if rs == nil || rs.Current == nil {
+			t.Fatalf("no state for %s", addr)
+		}
+		if got, want := string(rs.Current.AttrsJSON), `{"foo":"shared","id":"shared-id"}`; got != want {
+			t.Fatalf("wrong state for %s\ngot:  %s\nwant: %s", addr, got, want)
+		}
+	}
+}
+
 func TestContext2Plan_dataSchemaChange(t *testing.T) {
 	// We can't decode the prior state when a data source upgrades the schema
 	// in an incompatible way. Since prior state for data sources is purely
@@ -4139,6 +4372,40 @@ resource "test_object" "b" {
 	}
 }
 
+func TestContext2Plan_cycleDiagnostics(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  a = local.b
+  b = local.a
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{})
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.ErrWithWarnings())
+	}
+
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "Cycle: local.a (expand), local.b (expand)"; got != want {
+		t.Errorf("wrong summary\ngot:  %s\nwant: %s", got, want)
+	}
+	for _, want := range []string{
+		"main.tf:3,3-14) depends on local.b (expand)\n",
+		"main.tf:4,3-14) depends on local.a (expand)\n",
+		"digraph {\n  \"local.a (expand)\" -> \"local.b (expand)\"\n  \"local.b (expand)\" -> \"local.a (expand)\"\n}",
+	} {
+		if !strings.Contains(desc.Detail, want) {
+			t.Errorf("detail does not contain %q\n%s", want, desc.Detail)
+		}
+	}
+	if subject := diags[0].Source().Subject; subject == nil || filepath.Base(subject.Filename) != "main.tf" || subject.Start.Line != 3 {
+		t.Errorf("wrong subject %#v", subject)
+	}
+}
+
 // plan a destroy with no state where configuration could fail to evaluate
 // expansion indexes.
 func TestContext2Plan_emptyDestroy(t *testing.T) {
@@ -6691,6 +6958,100 @@ import {
 	})
 }
 
+func TestContext2Plan_importResourceConfigGenWithProviderInstance(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  region = "b"
+}
+
+provider "test" {
+  alias    = "multi"
+  for_each = toset(["a", "b"])
+
+  test_string = each.key
+}
+
+import {
+  provider = test.multi[local.region]
+  to       = test_object.a
+  id       = "123"
+}
+`,
+	})
+
+	// Each provider instance gets its own mock, so that we can tell which
+	// instance was used for the import.
+	var mu sync.Mutex
+	instances := map[string]*MockProvider{}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): func() (providers.Interface, error) {
+				p := simpleMockProvider()
+				p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+					mu.Lock()
+					defer mu.Unlock()
+					instances[req.Config.GetAttr("test_string").AsString()] = p
+					return providers.ConfigureProviderResponse{}
+				}
+				p.ReadResourceResponse = &providers.ReadResourceResponse{
+					NewState: cty.ObjectVal(map[string]cty.Value{
+						"test_string": cty.StringVal("foo"),
+					}),
+				}
+				p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+					ImportedResources: []providers.ImportedResource{
+						{
+							TypeName: "test_object",
+							State: cty.ObjectVal(map[string]cty.Value{
+								"test_string": cty.StringVal("foo"),
+							}),
+						},
+					},
+				}
+				return p, nil
+			},
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
+	})
+	assertNoErrors(t, diags)
+
+	if p := instances["a"]; p == nil || p.ImportResourceStateCalled {
+		t.Errorf("import used the provider instance for key \"a\"")
+	}
+	if p := instances["b"]; p == nil || !p.ImportResourceStateCalled {
+		t.Errorf("import did not use the provider instance for key \"b\"")
+	}
+
+	instPlan := plan.Changes.ResourceInstance(addr)
+	if instPlan == nil {
+		t.Fatalf("no plan for %s at all", addr)
+	}
+	if instPlan.Importing == nil || instPlan.Importing.ID != "123" {
+		t.Errorf("expected import change from \"123\", got non-import change")
+	}
+	if got, want := instPlan.ProviderAddr.String(), `provider["registry.opentofu.org/hashicorp/test"].multi`; got != want {
+		t.Errorf("wrong provider address\ngot:  %s\nwant: %s", got, want)
+	}
+
+	want := `resource "test_object" "a" {
+  provider    = test.multi["b"]
+  test_bool   = null
+  test_list   = null
+  test_map    = null
+  test_number = null
+  test_string = "foo"
+}`
+	if diff := cmp.Diff(want, instPlan.GeneratedConfig); len(diff) > 0 {
+		t.Errorf("wrong generated config\n%s", diff)
+	}
+}
+
 func TestContext2Plan_importResourceConfigGenValidation(t *testing.T) {
 	type TestConfiguration struct {
 		Description         string
@@ -7337,31 +7698,96 @@ func TestContext2Plan_removedModuleBasic(t *testing.T) {
 	}
 }
 
-func TestContext2Plan_removedModuleForgetsAllInstances(t *testing.T) {
-	addrFirst := mustResourceInstanceAddr("module.mod[0].test_object.a")
-	addrSecond := mustResourceInstanceAddr("module.mod[1].test_object.a")
-
+func TestContext2Plan_removedModuleDestroy(t *testing.T) {
+	addrA := mustResourceInstanceAddr("module.mod.test_object.a")
+	addrB := mustResourceInstanceAddr("module.other.test_object.b")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 			removed {
 				from = module.mod
+				lifecycle {
+					destroy = true
+				}
+			}
+
+			removed {
+				from = module.other
+				lifecycle {
+					destroy = false
+				}
 			}
 		`,
 	})
 
 	state := states.BuildState(func(s *states.SyncState) {
-		// The prior state tracks module.mod[0].test_object.a and
-		// module.mod[1].test_object.a, which we should be removed
-		// from the state by the "removed" block in the config.
-		s.SetResourceInstanceCurrent(addrFirst, &states.ResourceInstanceObjectSrc{
-			AttrsJSON: []byte(`{}`),
-			Status:    states.ObjectReady,
-		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
-		s.SetResourceInstanceCurrent(addrSecond, &states.ResourceInstanceObjectSrc{
-			AttrsJSON: []byte(`{}`),
-			Status:    states.ObjectReady,
-		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
-	})
+		for _, addr := range []addrs.AbsResourceInstance{addrA, addrB} {
+			s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		}
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	for _, test := range []struct {
+		addr       addrs.AbsResourceInstance
+		wantAction plans.Action
+		wantReason plans.ResourceInstanceChangeActionReason
+	}{
+		{addrA, plans.Delete, plans.ResourceInstanceDeleteBecauseRemovedBlock},
+		{addrB, plans.Forget, plans.ResourceInstanceDeleteBecauseNoResourceConfig},
+	} {
+		t.Run(test.addr.String(), func(t *testing.T) {
+			instPlan := plan.Changes.ResourceInstance(test.addr)
+			if instPlan == nil {
+				t.Fatalf("no plan for %s at all", test.addr)
+			}
+			if got, want := instPlan.Action, test.wantAction; got != want {
+				t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := instPlan.ActionReason, test.wantReason; got != want {
+				t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestContext2Plan_removedModuleForgetsAllInstances(t *testing.T) {
+	addrFirst := mustResourceInstanceAddr("module.mod[0].test_object.a")
+	addrSecond := mustResourceInstanceAddr("module.mod[1].test_object.a")
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			removed {
+				from = module.mod
+			}
+		`,
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		// The prior state tracks module.mod[0].test_object.a and
+		// module.mod[1].test_object.a, which we should be removed
+		// from the state by the "removed" block in the config.
+		s.SetResourceInstanceCurrent(addrFirst, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		s.SetResourceInstanceCurrent(addrSecond, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
 
 	p := simpleMockProvider()
 	ctx := testContext2(t, &ContextOpts{
@@ -8128,3 +8554,1036 @@ func featuresBlockTestSchema() *configschema.Block {
 		},
 	}
 }
+
+func TestContext2Plan_requiredTags(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+terraform {
+  required_tags {
+    resource_types = ["test_tagged*"]
+
+    tag "Owner" {}
+    tag "Environment" {
+      allowed_values = ["dev", "prod"]
+    }
+  }
+
+  required_tags {
+    resource_types = ["test_untagged"]
+    enforcement    = "warning"
+
+    tag "Owner" {}
+  }
+}
+
+resource "test_tagged" "ok" {
+  tags = {
+    Owner       = "platform"
+    Environment = "prod"
+  }
+}
+
+resource "test_tagged" "missing" {
+  tags = {
+    Environment = "dev"
+  }
+}
+
+resource "test_tagged" "invalid" {
+  tags = {
+    Owner       = "platform"
+    Environment = "staging"
+  }
+}
+
+resource "test_tagged" "unknown" {
+  tags = {
+    Owner       = test_tagged.ok.id
+    Environment = "dev"
+  }
+}
+
+resource "test_untagged" "a" {
+}
+`,
+	})
+
+	p := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			ResourceTypes: map[string]providers.Schema{
+				"test_tagged": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"id":   {Type: cty.String, Computed: true},
+							"tags": {Type: cty.Map(cty.String), Optional: true},
+						},
+					},
+				},
+				"test_untagged": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"id": {Type: cty.String, Computed: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	if !plan.Errored {
+		t.Errorf("plan should be marked as errored")
+	}
+
+	var got []string
+	for _, diag := range diags {
+		desc := diag.Description()
+		detail := strings.ReplaceAll(desc.Detail, m.Module.SourceDir+string(filepath.Separator), "")
+		got = append(got, fmt.Sprintf("%s: %s", desc.Summary, detail))
+	}
+	sort.Strings(got)
+	want := []string{
+		`Invalid required tag values: The planned value of test_tagged.invalid has tags in its "tags" attribute that aren't allowed by the required_tags block at main.tf:3,3-16: "Environment" must be one of "dev", "prod".`,
+		`Missing required tags: The planned value of test_tagged.missing is missing the following tags in its "tags" attribute, which are required by the required_tags block at main.tf:3,3-16: "Owner".`,
+		`Resource type does not support tags: The required_tags block at main.tf:12,3-16 applies to test_untagged.a, but the resource type test_untagged has no attribute named "tags". Set the "attribute" argument to the name of the attribute that holds its tags, or exclude the type from "resource_types".`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestContext2Plan_requiredTagsUnchanged(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+terraform {
+  required_tags {
+    resource_types = ["test_object"]
+    attribute      = "test_map"
+
+    tag "Owner" {}
+  }
+}
+
+resource "test_object" "a" {
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.a"),
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{}`),
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			addrs.NoKey,
+		)
+	})
+
+	// Existing objects that aren't changing aren't checked, while destroy
+	// plans only remove objects.
+	for _, mode := range []plans.Mode{plans.NormalMode, plans.DestroyMode} {
+		opts := SimplePlanOpts(mode, nil)
+		_, diags := ctx.Plan(context.Background(), m, state, opts)
+		assertNoErrors(t, diags)
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics in %s: %s", mode, diags.Err())
+		}
+	}
+}
+
+func TestContext2Plan_refreshConcurrencyPerProvider(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			provider "test" {
+			}
+
+			provider "test" {
+				alias = "b"
+			}
+
+			resource "test_object" "a" {
+			}
+
+			resource "test_object" "b" {
+				provider = test.b
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.a"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.b"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"].b`), addrs.NoKey)
+	})
+
+	// Each refresh waits until both of them are in progress, which can only
+	// happen if refreshing isn't limited by the overall parallelism of one.
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	bothArrived := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(bothArrived)
+	}()
+
+	ctx := testContext2(t, &ContextOpts{
+		Parallelism:        1,
+		RefreshConcurrency: 1,
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): func() (providers.Interface, error) {
+				p := simpleMockProvider()
+				p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+					arrived.Done()
+					select {
+					case <-bothArrived:
+					case <-time.After(10 * time.Second):
+						resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("the other refresh didn't start concurrently"))
+					}
+					resp.NewState = req.PriorState
+					return resp
+				}
+				return p, nil
+			},
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_refreshConcurrencyInvalid(t *testing.T) {
+	_, diags := NewContext(&ContextOpts{
+		RefreshConcurrency: -1,
+	})
+	if got, want := diags.Err().Error(), "The refresh concurrency must be a positive value. Not -1."; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestContext2Plan_providerRetry(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			terraform {
+				required_providers {
+					test = {
+						source = "hashicorp/test"
+						retry = {
+							max_attempts     = 3
+							initial_delay    = "1ms"
+							max_delay        = "1ms"
+							retryable_errors = ["(?i)rate exceeded"]
+						}
+					}
+				}
+			}
+
+			resource "test_object" "a" {
+				test_string = "foo"
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	reads := 0
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+		reads++
+		if reads == 1 {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(tfdiags.Error, "Rate exceeded", "Too many requests."))
+			return resp
+		}
+		resp.NewState = req.PriorState
+		return resp
+	}
+	hook := new(MockHook)
+	ctx := testContext2(t, &ContextOpts{
+		Hooks: []Hook{hook},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	if reads != 2 {
+		t.Errorf("provider got %d ReadResource calls; want 2", reads)
+	}
+	if !hook.ProviderRetryCalled {
+		t.Fatal("ProviderRetry hook was not called")
+	}
+	if got, want := hook.ProviderRetryAddr.String(), `provider["registry.opentofu.org/hashicorp/test"]`; got != want {
+		t.Errorf("wrong provider address %s; want %s", got, want)
+	}
+	if got, want := hook.ProviderRetryEvent.Method, "ReadResource"; got != want {
+		t.Errorf("wrong method %s; want %s", got, want)
+	}
+}
+
+func TestContext2Plan_resourceQuotas(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			provider "test" {
+				alias = "other"
+			}
+
+			resource "test_object" "a" {
+				count       = 3
+				test_string = "foo"
+			}
+
+			resource "test_object" "b" {
+				provider    = test.other
+				test_string = "foo"
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse.ServerCapabilities.CheckResourceQuotas = true
+	var mu sync.Mutex
+	var requested []int
+	p.CheckResourceQuotasFn = func(req providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+		mu.Lock()
+		defer mu.Unlock()
+		requested = append(requested, req.Creates["test_object"])
+		return providers.CheckResourceQuotasResponse{
+			Risks: []providers.QuotaRisk{
+				{
+					TypeName:  "test_object",
+					Requested: req.Creates["test_object"],
+					Available: 1,
+					Detail:    "objects per account",
+				},
+			},
+		}
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	sort.Ints(requested)
+	if diff := cmp.Diff([]int{1, 3}, requested); diff != "" {
+		t.Errorf("wrong quota requests\n%s", diff)
+	}
+
+	if len(diags) != 1 {
+		t.Fatalf("expected exactly one warning, got %d:\n%s", len(diags), diags.ErrWithWarnings())
+	}
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "Planned changes might exceed resource quotas"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	for _, want := range []string{
+		`  - test_object from provider["registry.opentofu.org/hashicorp/test"]: 3 to create, 1 available (objects per account)`,
+		`  - test_object from provider["registry.opentofu.org/hashicorp/test"].other: 1 to create, 1 available (objects per account)`,
+	} {
+		if !strings.Contains(desc.Detail, want) {
+			t.Errorf("detail is missing %q:\n%s", want, desc.Detail)
+		}
+	}
+}
+
+func TestContext2Plan_moduleCache(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			variable "child" {
+				type = string
+			}
+
+			resource "test_object" "root" {
+				test_string = "root"
+			}
+
+			module "child" {
+				source = "./child"
+				value  = var.child
+			}
+		`,
+		"child/main.tf": `
+			variable "value" {
+				type = string
+			}
+
+			resource "test_object" "a" {
+				test_string = var.value
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.root"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"root"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("module.child.test_object.a"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	var mu sync.Mutex
+	var planned []string
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+		mu.Lock()
+		planned = append(planned, req.Config.GetAttr("test_string").AsString())
+		mu.Unlock()
+		return providers.PlanResourceChangeResponse{PlannedState: req.ProposedNewState}
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan := func(value string, previous map[string]ModuleFingerprint) (*plans.Plan, map[string]ModuleFingerprint) {
+		t.Helper()
+		planned = nil
+		cache := &ModuleCache{
+			Previous: previous,
+			SourceHashes: map[string]string{
+				"":             "root",
+				"module.child": "child",
+			},
+			ProviderVersions: map[addrs.Provider]string{
+				addrs.NewDefaultProvider("test"): "1.0.0",
+			},
+		}
+		plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+			Mode:        plans.NormalMode,
+			SkipRefresh: true,
+			SetVariables: InputValues{
+				"child": &InputValue{
+					Value:      cty.StringVal(value),
+					SourceType: ValueFromCaller,
+				},
+			},
+			ModuleCache: cache,
+		})
+		assertNoErrors(t, diags)
+		sort.Strings(planned)
+		return plan, cache.Current
+	}
+
+	_, fingerprints := plan("foo", nil)
+	if diff := cmp.Diff([]string{"foo", "root"}, planned); diff != "" {
+		t.Errorf("wrong planned resources in the first plan\n%s", diff)
+	}
+	if _, ok := fingerprints["module.child"]; !ok {
+		t.Fatalf("no fingerprint recorded for module.child: %#v", fingerprints)
+	}
+
+	// Nothing changed, so the child module's resource isn't planned again.
+	reused, _ := plan("foo", fingerprints)
+	if diff := cmp.Diff([]string{"root"}, planned); diff != "" {
+		t.Errorf("wrong planned resources in the second plan\n%s", diff)
+	}
+	if got := reused.PlannedState.ResourceInstance(mustResourceInstanceAddr("module.child.test_object.a")); got == nil {
+		t.Error("module.child.test_object.a is missing from the planned state")
+	}
+
+	// A different input value invalidates the fingerprint.
+	changed, fingerprints := plan("bar", fingerprints)
+	if diff := cmp.Diff([]string{"bar", "root"}, planned); diff != "" {
+		t.Errorf("wrong planned resources in the third plan\n%s", diff)
+	}
+	if change := changed.Changes.ResourceInstance(mustResourceInstanceAddr("module.child.test_object.a")); change == nil || change.Action != plans.Update {
+		t.Errorf("expected an update for module.child.test_object.a, got %#v", change)
+	}
+	if _, ok := fingerprints["module.child"]; ok {
+		t.Error("fingerprint recorded for module.child even though it has changes")
+	}
+}
+
+func TestContext2Plan_moduleCacheCrossReferences(t *testing.T) {
+	// The inputs of each module depend on the resources of the other, so
+	// making the resources of both modules wait for their inputs would
+	// create a cycle.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			module "a" {
+				source = "./child"
+				value  = module.b.static
+			}
+
+			module "b" {
+				source = "./child"
+				value  = module.a.static
+			}
+		`,
+		"child/main.tf": `
+			variable "value" {
+				type = string
+			}
+
+			resource "test_object" "static" {
+				test_string = "static"
+			}
+
+			resource "test_object" "dynamic" {
+				test_string = var.value
+			}
+
+			output "static" {
+				value = test_object.static.test_string
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	cache := &ModuleCache{
+		SourceHashes: map[string]string{
+			"":         "root",
+			"module.a": "child",
+			"module.b": "child",
+		},
+	}
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:        plans.NormalMode,
+		SkipRefresh: true,
+		ModuleCache: cache,
+	})
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_moduleCacheOutsideFingerprint(t *testing.T) {
+	// The results of these expressions can change without changing the
+	// module's fingerprint, so the module is never reused.
+	tests := map[string]string{
+		"file":         `file("${path.module}/value.txt")`,
+		"templatefile": `templatefile("${path.module}/value.txt", {})`,
+		"core file":    `core::filebase64("${path.module}/value.txt")`,
+		"run_id":       `tofu.run_id`,
+		"path.cwd":     `path.cwd`,
+	}
+	for name, expr := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := testModuleInline(t, map[string]string{
+				"main.tf": `
+					module "child" {
+						source = "./child"
+					}
+				`,
+				"child/main.tf": `
+					resource "test_object" "a" {
+						test_string = "foo"
+					}
+
+					output "value" {
+						value = ` + expr + `
+					}
+				`,
+				"child/value.txt": "foo",
+			})
+			state := states.BuildState(func(s *states.SyncState) {
+				s.SetResourceInstanceCurrent(mustResourceInstanceAddr("module.child.test_object.a"), &states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"test_string":"foo"}`),
+					Status:    states.ObjectReady,
+				}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+			})
+
+			p := simpleMockProvider()
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+				},
+			})
+
+			cache := &ModuleCache{
+				SourceHashes: map[string]string{
+					"":             "root",
+					"module.child": "child",
+				},
+			}
+			_, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+				Mode:        plans.NormalMode,
+				SkipRefresh: true,
+				ModuleCache: cache,
+			})
+			assertNoErrors(t, diags)
+			if _, ok := cache.Current["module.child"]; ok {
+				t.Errorf("fingerprint recorded for module.child even though it uses %s", expr)
+			}
+		})
+	}
+}
+
+func TestContext2Plan_moduleCacheSkipRefresh(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			resource "test_object" "root" {
+				test_string = "root"
+			}
+
+			module "child" {
+				source = "./child"
+			}
+		`,
+		"child/main.tf": `
+			resource "test_object" "a" {
+				test_string = "foo"
+			}
+		`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.root"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"root"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("module.child.test_object.a"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	var mu sync.Mutex
+	var refreshed []string
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		mu.Lock()
+		refreshed = append(refreshed, req.PriorState.GetAttr("test_string").AsString())
+		mu.Unlock()
+		return providers.ReadResourceResponse{NewState: req.PriorState}
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan := func(previous map[string]ModuleFingerprint) map[string]ModuleFingerprint {
+		t.Helper()
+		refreshed = nil
+		cache := &ModuleCache{
+			Previous: previous,
+			SourceHashes: map[string]string{
+				"":             "root",
+				"module.child": "child",
+			},
+			SkipRefresh: true,
+		}
+		_, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+			Mode:        plans.NormalMode,
+			ModuleCache: cache,
+		})
+		assertNoErrors(t, diags)
+		sort.Strings(refreshed)
+		return cache.Current
+	}
+
+	// Refreshing is enabled, so both resources are refreshed the first time.
+	fingerprints := plan(nil)
+	if diff := cmp.Diff([]string{"foo", "root"}, refreshed); diff != "" {
+		t.Errorf("wrong refreshed resources in the first plan\n%s", diff)
+	}
+
+	// The child module is unchanged, so its resource isn't refreshed again.
+	plan(fingerprints)
+	if diff := cmp.Diff([]string{"root"}, refreshed); diff != "" {
+		t.Errorf("wrong refreshed resources in the second plan\n%s", diff)
+	}
+}
+
+func TestContext2Plan_targetGroups(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = test_object.dep.test_string
+
+  lifecycle {
+    groups = ["networking"]
+  }
+}
+
+resource "test_object" "dep" {
+  test_string = "dep"
+}
+
+resource "test_object" "b" {
+  lifecycle {
+    groups = ["compute"]
+  }
+}
+
+module "child" {
+  source = "./child"
+  count  = 2
+}
+`,
+		"child/main.tf": `
+resource "test_object" "x" {
+  lifecycle {
+    groups = ["networking"]
+  }
+}
+
+resource "test_object" "y" {
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:         plans.NormalMode,
+		TargetGroups: []string{"networking"},
+	})
+	assertNoErrors(t, diags)
+
+	var got []string
+	for _, change := range plan.Changes.Resources {
+		got = append(got, change.Addr.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		"module.child[0].test_object.x",
+		"module.child[1].test_object.x",
+		"test_object.a",
+		"test_object.dep",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong planned changes\n%s", diff)
+	}
+
+	got = nil
+	for _, target := range plan.TargetAddrs {
+		got = append(got, target.String())
+	}
+	want = []string{
+		"module.child[0].test_object.x",
+		"module.child[1].test_object.x",
+		"test_object.a",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong plan targets\n%s", diff)
+	}
+}
+
+func TestContext2Plan_targetGroupsUnknown(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  lifecycle {
+    groups = ["networking"]
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:         plans.NormalMode,
+		TargetGroups: []string{"networking", "compute"},
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), `The -target-group option selects the group "compute"`; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestContext2Plan_targetDependencies(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = test_object.dep.test_string
+}
+
+resource "test_object" "dep" {
+  test_string = test_object.unchanged.test_string
+}
+
+resource "test_object" "unchanged" {
+  test_string = "unchanged"
+}
+
+resource "test_object" "other" {
+}
+`,
+	})
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.unchanged"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"test_string":"unchanged"}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			addrs.NoKey,
+		)
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	targets := []addrs.Targetable{mustResourceInstanceAddr("test_object.a")}
+	_, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:    plans.NormalMode,
+		Targets: targets,
+	})
+	assertNoErrors(t, diags)
+	var found bool
+	for _, diag := range diags {
+		if diag.Description().Summary != "Targeting included more resources" {
+			continue
+		}
+		found = true
+		got := diag.Description().Detail
+		if want := "\n  - test_object.dep (needed by test_object.a)\n"; !strings.Contains(got, want) {
+			t.Errorf("wrong warning\ngot:  %s\nwant: %s", got, want)
+		}
+		if strings.Contains(got, "test_object.unchanged") {
+			t.Errorf("warning lists a resource without changes\n%s", got)
+		}
+	}
+	if !found {
+		t.Fatalf("missing warning about included resources\n%s", diags.ErrWithWarnings())
+	}
+
+	plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:         plans.NormalMode,
+		Targets:      targets,
+		TargetStrict: true,
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "Targeted plan includes unlisted resources"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if !plan.Errored {
+		t.Error("plan is not marked as errored")
+	}
+
+	// Targeting the dependency too satisfies the strict mode.
+	_, diags = ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:         plans.NormalMode,
+		Targets:      append(targets, mustResourceInstanceAddr("test_object.dep")),
+		TargetStrict: true,
+	})
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_ephemeralResourceNotAllowed(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+ephemeral "vault_token" "main" {
+  name = "ci"
+}
+
+locals {
+  token = ephemeral.vault_token.main.token
+}
+
+resource "test_object" "a" {
+  test_string = local.token
+}
+
+output "token" {
+  value     = local.token
+  sensitive = true
+}
+`,
+	})
+
+	vault, test := ephemeralResourceTestProviders()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("vault"): testProviderFuncFixed(vault),
+			addrs.NewDefaultProvider("test"):  testProviderFuncFixed(test),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success")
+	}
+	got := diags.Err().Error()
+	for _, want := range []string{
+		"Ephemeral value not allowed",
+		"Output refers to ephemeral values",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing error %q in:\n%s", want, got)
+		}
+	}
+	if !vault.CloseEphemeralResourceCalled {
+		t.Errorf("token not closed")
+	}
+}
+
+func TestContext2Plan_importDiscovery(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  env = "prod"
+}
+
+resource "test_object" "web_a" {
+  test_string = "existing"
+}
+
+import {
+  to = test_object.web
+  discover {
+    filter = { env = local.env }
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse.ServerCapabilities.ListResources = true
+	p.ListResourcesResponse = &providers.ListResourcesResponse{
+		Resources: []providers.ListedResource{
+			{ID: "b-1", Name: "b"},
+			{ID: "a-1", Name: "a"},
+			{ID: "a-2", Name: "a"},
+			{ID: "i-0123/x"},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf",
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	if !p.ListResourcesCalled {
+		t.Fatal("provider wasn't asked to list resources")
+	}
+	wantFilter := cty.ObjectVal(map[string]cty.Value{
+		"env": cty.StringVal("prod"),
+	})
+	if got := p.ListResourcesRequest.Filter; !got.RawEquals(wantFilter) {
+		t.Errorf("wrong filter\ngot:  %#v\nwant: %#v", got, wantFilter)
+	}
+
+	got := make(map[string]string)
+	for _, imp := range plan.DiscoveredImports {
+		got[imp.Addr.String()] = imp.ID
+	}
+	want := map[string]string{
+		"test_object.web_a_2":      "a-1",
+		"test_object.web_a_3":      "a-2",
+		"test_object.web_b":        "b-1",
+		"test_object.web_i-0123_x": "i-0123/x",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong discovered imports\n%s", diff)
+	}
+
+	wantConfig := `import {
+  to = test_object.web_b
+  id = "b-1"
+}`
+	for _, imp := range plan.DiscoveredImports {
+		if imp.ID == "b-1" && imp.GeneratedConfig != wantConfig {
+			t.Errorf("wrong generated config\ngot:\n%s\nwant:\n%s", imp.GeneratedConfig, wantConfig)
+		}
+	}
+
+	// Without generating configuration, the discover block is skipped.
+	p.ListResourcesCalled = false
+	plan, diags = ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	if p.ListResourcesCalled {
+		t.Error("provider was asked to list resources without generating configuration")
+	}
+	if len(plan.DiscoveredImports) != 0 {
+		t.Errorf("unexpected discovered imports %#v", plan.DiscoveredImports)
+	}
+}
+
+func TestContext2Plan_importDiscoveryNotSupported(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+import {
+  to = test_object.web
+  discover {}
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf",
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want an error")
+	}
+	if got, want := diags.Err().Error(), "Provider can't discover objects to import"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+	if p.ListResourcesCalled {
+		t.Error("provider was asked to list resources")
+	}
+}
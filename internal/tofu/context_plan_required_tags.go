@@ -0,0 +1,186 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// checkRequiredTags verifies the planned values of every managed resource
+// instance that is being created or updated against the required_tags blocks
+// declared in the root module.
+//
+// Objects that aren't changing are not checked, so introducing a new
+// requirement doesn't prevent unrelated changes from being applied until
+// every existing resource has been retagged.
+func (c *Context) checkRequiredTags(config *configs.Config, plan *plans.Plan) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	if config == nil || len(config.Module.RequiredTags) == 0 || plan.Changes == nil {
+		return diags
+	}
+
+	for _, change := range plan.Changes.Resources {
+		if change.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode || change.DeposedKey != states.NotDeposed {
+			continue
+		}
+		switch change.Action {
+		case plans.Create, plans.Update, plans.DeleteThenCreate, plans.CreateThenDelete:
+		default:
+			continue
+		}
+
+		resourceType := change.Addr.Resource.Resource.Type
+		var reqs []*configs.RequiredTags
+		for _, req := range config.Module.RequiredTags {
+			if req.AppliesTo(resourceType) {
+				reqs = append(reqs, req)
+			}
+		}
+		if len(reqs) == 0 {
+			continue
+		}
+
+		var subject *hcl.Range
+		if modCfg := config.DescendentForInstance(change.Addr.Module); modCfg != nil {
+			if rc := modCfg.Module.ResourceByAddr(change.Addr.Resource.Resource); rc != nil {
+				subject = rc.DeclRange.Ptr()
+			}
+		}
+
+		schema, _, err := c.plugins.ResourceTypeSchema(change.ProviderAddr.Provider, addrs.ManagedResourceMode, resourceType)
+		if err != nil || schema == nil {
+			// Missing schemas are already reported while planning.
+			continue
+		}
+		ric, err := change.Decode(schema.ImpliedType())
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to decode planned changes",
+				Detail:   fmt.Sprintf("OpenTofu could not decode the planned changes for %s to check its required tags: %s.", change.Addr, err),
+				Subject:  subject,
+			})
+			continue
+		}
+		after, _ := ric.After.UnmarkDeep()
+
+		for _, req := range reqs {
+			diags = diags.Append(checkRequiredTagsForInstance(change.Addr, after, req, subject))
+		}
+	}
+
+	return diags
+}
+
+func checkRequiredTagsForInstance(addr addrs.AbsResourceInstance, val cty.Value, req *configs.RequiredTags, subject *hcl.Range) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	severity := hcl.DiagError
+	if req.Enforcement == configs.RequiredTagsWarning {
+		severity = hcl.DiagWarning
+	}
+
+	if !val.IsKnown() || val.IsNull() {
+		return diags
+	}
+	if !val.Type().IsObjectType() || !val.Type().HasAttribute(req.Attribute) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  "Resource type does not support tags",
+			Detail:   fmt.Sprintf("The required_tags block at %s applies to %s, but the resource type %s has no attribute named %q. Set the \"attribute\" argument to the name of the attribute that holds its tags, or exclude the type from \"resource_types\".", req.DeclRange, addr, addr.Resource.Resource.Type, req.Attribute),
+			Subject:  subject,
+		})
+		return diags
+	}
+
+	tags := val.GetAttr(req.Attribute)
+	if !tags.IsWhollyKnown() {
+		// We'll only know the final tags during apply, so we can't check them
+		// yet.
+		return diags
+	}
+	if !tags.IsNull() && !tags.Type().IsMapType() && !tags.Type().IsObjectType() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  "Resource type does not support tags",
+			Detail:   fmt.Sprintf("The required_tags block at %s applies to %s, but the %q attribute of %s is not a map.", req.DeclRange, addr, req.Attribute, addr.Resource.Resource.Type),
+			Subject:  subject,
+		})
+		return diags
+	}
+
+	var missing, invalid []string
+	for _, tag := range req.Tags {
+		v := requiredTagValue(tags, tag.Key)
+		if v.IsNull() {
+			missing = append(missing, tag.Key)
+			continue
+		}
+		if len(tag.AllowedValues) == 0 {
+			continue
+		}
+		if v.Type() != cty.String || !slices.Contains(tag.AllowedValues, v.AsString()) {
+			invalid = append(invalid, fmt.Sprintf("%q must be one of %s", tag.Key, quotedList(tag.AllowedValues)))
+		}
+	}
+
+	if len(missing) > 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  "Missing required tags",
+			Detail:   fmt.Sprintf("The planned value of %s is missing the following tags in its %q attribute, which are required by the required_tags block at %s: %s.", addr, req.Attribute, req.DeclRange, quotedList(missing)),
+			Subject:  subject,
+		})
+	}
+	if len(invalid) > 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity,
+			Summary:  "Invalid required tag values",
+			Detail:   fmt.Sprintf("The planned value of %s has tags in its %q attribute that aren't allowed by the required_tags block at %s: %s.", addr, req.Attribute, req.DeclRange, strings.Join(invalid, "; ")),
+			Subject:  subject,
+		})
+	}
+
+	return diags
+}
+
+// requiredTagValue returns the value of the given tag, or a null value if the
+// tag isn't set.
+func requiredTagValue(tags cty.Value, key string) cty.Value {
+	switch {
+	case tags.IsNull():
+		return cty.NullVal(cty.String)
+	case tags.Type().IsMapType():
+		if !tags.HasIndex(cty.StringVal(key)).True() {
+			return cty.NullVal(cty.String)
+		}
+		return tags.Index(cty.StringVal(key))
+	case tags.Type().HasAttribute(key):
+		return tags.GetAttr(key)
+	default:
+		return cty.NullVal(cty.String)
+	}
+}
+
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for ix, v := range values {
+		quoted[ix] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
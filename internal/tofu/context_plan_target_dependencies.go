@@ -0,0 +1,117 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// targetDependencies records, for each resource that resource targeting
+// includes in a plan graph, the targeted resources that caused it to be
+// included. The keys are the string representations of the configuration
+// addresses of the included resources.
+//
+// A nil targetDependencies records nothing.
+type targetDependencies map[string][]addrs.ConfigResource
+
+// add records that the resource of the dep node is included because the
+// resource of the targeted node needs it. Nodes that don't belong to
+// resources are ignored.
+func (d targetDependencies) add(dep, targeted dag.Vertex) {
+	if d == nil {
+		return
+	}
+	depAddr, ok := targetableConfigResource(dep)
+	if !ok {
+		return
+	}
+	targetedAddr, ok := targetableConfigResource(targeted)
+	if !ok || depAddr.Equal(targetedAddr) {
+		return
+	}
+
+	key := depAddr.String()
+	if !slices.ContainsFunc(d[key], targetedAddr.Equal) {
+		d[key] = append(d[key], targetedAddr)
+	}
+}
+
+func targetableConfigResource(v dag.Vertex) (addrs.ConfigResource, bool) {
+	switch r := v.(type) {
+	case GraphNodeResourceInstance:
+		return r.ResourceInstanceAddr().ConfigResource(), true
+	case GraphNodeConfigResource:
+		return r.ResourceAddr(), true
+	default:
+		return addrs.ConfigResource{}, false
+	}
+}
+
+// targetDependencyDiags returns a diagnostic listing the managed resource
+// instances that have changes in the given plan even though none of the
+// given targets selects them, because targeted resources needed them.
+//
+// The diagnostic is a warning, unless strict is set, in which case it's an
+// error so that OpenTofu doesn't plan any changes that the user didn't list.
+func targetDependencyDiags(targets []addrs.Targetable, changes *plans.Changes, deps targetDependencies, strict bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(targets) == 0 || changes == nil {
+		return diags
+	}
+
+	seen := make(map[string]bool)
+	var lines []string
+	for _, change := range changes.Resources {
+		addr := change.Addr
+		if addr.Resource.Resource.Mode != addrs.ManagedResourceMode || change.Action == plans.NoOp || seen[addr.String()] {
+			continue
+		}
+		if slices.ContainsFunc(targets, func(target addrs.Targetable) bool {
+			return target.TargetContains(addr)
+		}) {
+			continue
+		}
+		seen[addr.String()] = true
+
+		line := fmt.Sprintf("\n  - %s", addr)
+		if needed := deps[addr.ConfigResource().String()]; len(needed) > 0 {
+			names := make([]string, len(needed))
+			for i, n := range needed {
+				names[i] = n.String()
+			}
+			sort.Strings(names)
+			line += fmt.Sprintf(" (needed by %s)", strings.Join(names, ", "))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return diags
+	}
+	sort.Strings(lines)
+
+	if strict {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Targeted plan includes unlisted resources",
+			fmt.Sprintf("The -target-strict option is set, but the targeted resources need changes to the following resource instances, which no -target option selects:%s\n\nTo plan these changes too, add them as targets.", strings.Join(lines, "")),
+		))
+		return diags
+	}
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Targeting included more resources",
+		fmt.Sprintf("No -target option selects the following resource instances, but OpenTofu also planned changes for them because the targeted resources need them:%s\n\nTo fail instead of planning changes for resources that aren't targeted, use the -target-strict option.", strings.Join(lines, "")),
+	))
+	return diags
+}
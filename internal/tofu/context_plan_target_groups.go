@@ -0,0 +1,98 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// targetGroupTargets returns a target address for each managed resource in
+// the configuration whose lifecycle block assigns it to at least one of the
+// given groups.
+//
+// The targets are configuration addresses, which select every instance of a
+// resource in every instance of its module.
+func targetGroupTargets(config *configs.Config, groups []string) ([]addrs.Targetable, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	found := make(map[string]bool, len(groups))
+	var targets []addrs.Targetable
+	config.DeepEach(func(c *configs.Config) {
+		for _, rc := range c.Module.ManagedResources {
+			for _, group := range groups {
+				if slices.Contains(rc.Managed.Groups, group) {
+					found[group] = true
+					targets = append(targets, rc.Addr().InModule(c.Path))
+					break
+				}
+			}
+		}
+	})
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].String() < targets[j].String()
+	})
+
+	for _, group := range groups {
+		if !found[group] {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"No resources in target group",
+				fmt.Sprintf("The -target-group option selects the group %q, but no resource in the configuration is in that group. Assign resources to groups using the \"groups\" argument in their lifecycle blocks.", group),
+			))
+		}
+	}
+	return targets, diags
+}
+
+// planTargetAddrs returns the targets to save in a plan that was created with
+// the given targets.
+//
+// A configuration address for a resource in a child module has the same
+// string representation as the address of the resource in the module's
+// unkeyed instance, so it wouldn't select the same resources after saving
+// and loading the plan. Such targets are replaced by the address of the
+// resource in each module instance that has changes for it.
+func planTargetAddrs(targets []addrs.Targetable, changes *plans.Changes) []addrs.Targetable {
+	var ret []addrs.Targetable
+	for _, target := range targets {
+		cr, ok := target.(addrs.ConfigResource)
+		if !ok || cr.Module.IsRoot() {
+			ret = append(ret, target)
+			continue
+		}
+
+		seen := make(map[string]bool)
+		var expanded []addrs.Targetable
+		if changes != nil {
+			for _, change := range changes.Resources {
+				addr := change.Addr.ContainingResource()
+				if !cr.TargetContains(addr) || seen[addr.String()] {
+					continue
+				}
+				seen[addr.String()] = true
+				expanded = append(expanded, addr)
+			}
+		}
+		if len(expanded) == 0 {
+			// Without any changes there is nothing for the targets to
+			// select, but we still keep the plan targeted.
+			ret = append(ret, target)
+			continue
+		}
+		sort.Slice(expanded, func(i, j int) bool {
+			return expanded[i].String() < expanded[j].String()
+		})
+		ret = append(ret, expanded...)
+	}
+	return ret
+}
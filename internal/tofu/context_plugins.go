@@ -121,6 +121,12 @@ func (cp *contextPlugins) ProviderSchema(addr addrs.Provider) (providers.Provide
 		}
 	}
 
+	for t, e := range resp.EphemeralResources {
+		if err := e.Block.InternalValidate(); err != nil {
+			return resp, fmt.Errorf("provider %s has invalid schema for ephemeral resource type %q, which is a bug in the provider: %w", addr, t, err)
+		}
+	}
+
 	return resp, nil
 }
 
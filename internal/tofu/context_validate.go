@@ -16,6 +16,18 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// ValidateOpts are the options for ValidateWithOpts.
+type ValidateOpts struct {
+	// SetVariables are values for root module input variables to validate
+	// the configuration with, instead of unknown values. Variables that
+	// aren't set here have their default values, or unknown values if they
+	// are required.
+	//
+	// With known variable values, providers can validate the parts of
+	// resource and provider configurations that depend on them.
+	SetVariables InputValues
+}
+
 // Validate performs semantic validation of a configuration, and returns
 // any warnings or errors.
 //
@@ -28,6 +40,13 @@ import (
 // all of the same checks as Validate, in addition to the other work it does
 // to consider the previous run state and the planning options.
 func (c *Context) Validate(ctx context.Context, config *configs.Config) tfdiags.Diagnostics {
+	return c.ValidateWithOpts(ctx, config, nil)
+}
+
+// ValidateWithOpts is like Validate, but if opts is not nil then it
+// validates the configuration with the root module input variable values
+// given in the options.
+func (c *Context) ValidateWithOpts(ctx context.Context, config *configs.Config, opts *ValidateOpts) tfdiags.Diagnostics {
 	defer c.acquireRun("validate")()
 
 	var diags tfdiags.Diagnostics
@@ -49,6 +68,21 @@ func (c *Context) Validate(ctx context.Context, config *configs.Config) tfdiags.
 	// to perform a type check without assuming any particular values.
 	varValues := make(InputValues)
 	for name, variable := range config.Module.Variables {
+		if opts != nil {
+			if given, ok := opts.SetVariables[name]; ok {
+				varValues[name] = given
+				continue
+			}
+			if variable.Default != cty.NilVal {
+				// A nil value selects the default value.
+				varValues[name] = &InputValue{
+					Value:      cty.NilVal,
+					SourceType: ValueFromConfig,
+				}
+				continue
+			}
+		}
+
 		ty := variable.Type
 		if ty == cty.NilType {
 			// Can't predict the type at all, so we'll just mark it as
@@ -79,6 +113,7 @@ func (c *Context) Validate(ctx context.Context, config *configs.Config) tfdiags.
 	walker, walkDiags := c.walk(ctx, graph, walkValidate, &graphWalkOpts{
 		Config:                  config,
 		ProviderFunctionTracker: providerFunctionTracker,
+		KnownRootVariables:      opts != nil,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
 	diags = diags.Append(walkDiags)
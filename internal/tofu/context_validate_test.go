@@ -2506,3 +2506,203 @@ func TestContext2Validate_rangeOverZeroPlanTimestamp(t *testing.T) {
 		t.Fatal(diags.ErrWithWarnings())
 	}
 }
+
+// Provider-defined validation of resource and data resource configurations
+// must run during validation without the provider being configured, so that
+// it works without credentials.
+func TestContext2Validate_providerResourceValidationUnconfigured(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "token" {
+  type = string
+}
+
+provider "test" {
+  test_string = var.token
+}
+
+resource "test_object" "a" {
+  test_string = "invalid-resource"
+}
+
+data "test_object" "b" {
+  test_string = "invalid-data"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	p.ValidateResourceConfigFn = func(req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+		if v := req.Config.GetAttr("test_string"); v.IsKnown() && v.AsString() == "invalid-resource" {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Invalid value",
+				`The value must be "a" or "b".`,
+				cty.GetAttrPath("test_string"),
+			))
+		}
+		return resp
+	}
+	p.ValidateDataResourceConfigFn = func(req providers.ValidateDataResourceConfigRequest) (resp providers.ValidateDataResourceConfigResponse) {
+		if v := req.Config.GetAttr("test_string"); v.IsKnown() && v.AsString() == "invalid-data" {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Invalid value",
+				`The value must be "c" or "d".`,
+				cty.GetAttrPath("test_string"),
+			))
+		}
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	diags := ctx.Validate(context.Background(), m)
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.ErrWithWarnings())
+	}
+	err := diags.Err().Error()
+	for _, want := range []string{`The value must be "a" or "b".`, `The value must be "c" or "d".`} {
+		if !strings.Contains(err, want) {
+			t.Errorf("missing diagnostic %q in:\n%s", want, err)
+		}
+	}
+	if p.ConfigureProviderCalled {
+		t.Fatal("provider was configured during validation")
+	}
+}
+
+func TestContext2Validate_knownRootVariables(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "given" {
+  type = string
+}
+
+variable "defaulted" {
+  type    = string
+  default = "invalid-default"
+}
+
+variable "required" {
+  type = string
+}
+
+resource "test_object" "given" {
+  test_string = var.given
+}
+
+resource "test_object" "defaulted" {
+  test_string = var.defaulted
+}
+
+resource "test_object" "required" {
+  test_string = var.required
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	p.ValidateResourceConfigFn = func(req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+		if v := req.Config.GetAttr("test_string"); v.IsKnown() && strings.HasPrefix(v.AsString(), "invalid") {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.AttributeValue(
+				tfdiags.Error,
+				"Invalid value",
+				fmt.Sprintf("The value %q is not valid.", v.AsString()),
+				cty.GetAttrPath("test_string"),
+			))
+		}
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	// Without options, all of the variables are unknown.
+	diags := ctx.Validate(context.Background(), m)
+	assertNoErrors(t, diags)
+
+	diags = ctx.ValidateWithOpts(context.Background(), m, &ValidateOpts{
+		SetVariables: InputValues{
+			"given": &InputValue{
+				Value:      cty.StringVal("invalid-given"),
+				SourceType: ValueFromCLIArg,
+			},
+		},
+	})
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.ErrWithWarnings())
+	}
+	err := diags.Err().Error()
+	for _, want := range []string{`The value "invalid-given" is not valid.`, `The value "invalid-default" is not valid.`} {
+		if !strings.Contains(err, want) {
+			t.Errorf("missing diagnostic %q in:\n%s", want, err)
+		}
+	}
+}
+
+func TestContext2Validate_providerCapabilities(t *testing.T) {
+	tests := map[string]struct {
+		capabilities string
+		wantErr      string
+	}{
+		"supported": {
+			capabilities: `["plan_destroy"]`,
+		},
+		"missing": {
+			capabilities: `["plan_destroy", "check_resource_quotas"]`,
+			wantErr:      `This module requires provider hashicorp/test to support "check_resource_quotas", but the installed version of the provider doesn't announce these capabilities.`,
+		},
+		"unknown": {
+			capabilities: `["time_travel"]`,
+			wantErr:      `The required capabilities of provider "test" include "time_travel", which OpenTofu doesn't know.`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := simpleMockProvider()
+			p.GetProviderSchemaResponse.ServerCapabilities.PlanDestroy = true
+			m := testModuleInline(t, map[string]string{
+				"main.tf": `
+terraform {
+  required_providers {
+    test = {
+      source       = "hashicorp/test"
+      capabilities = ` + test.capabilities + `
+    }
+  }
+}
+
+resource "test_object" "a" {
+}
+`,
+			})
+
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+				},
+			})
+
+			diags := ctx.Validate(context.Background(), m)
+			if test.wantErr == "" {
+				assertNoErrors(t, diags)
+				return
+			}
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want error")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, test.wantErr) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+			}
+		})
+	}
+}
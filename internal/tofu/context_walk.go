@@ -44,9 +44,32 @@ type graphWalkOpts struct {
 	// the apply phase.
 	PlanTimeTimestamp time.Time
 
+	// PlanTimeRunID should be read from the plan during the apply phase, so
+	// that tofu.run_id has the same value as during the plan. If it's empty,
+	// tofu.run_id is the run ID of the context.
+	PlanTimeRunID string
+
 	MoveResults refactoring.MoveResults
 
 	ProviderFunctionTracker ProviderFunctionMapping
+
+	// ProviderFunctions is used to call provider functions that aren't
+	// provided by a provider node in the graph, such as those in
+	// expressions evaluated after an eval walk.
+	ProviderFunctions *ProviderFunctions
+
+	// ModuleCache is populated during the plan phase if the plan might reuse
+	// the results of the previous plan for unchanged modules.
+	ModuleCache *moduleCacheState
+
+	// KnownRootVariables is set during the validate walk to evaluate root
+	// module input variables with the values given for them, instead of
+	// with unknown values.
+	KnownRootVariables bool
+
+	// ChecksOnly is set during the plan walk of "tofu check", which only
+	// evaluates check blocks.
+	ChecksOnly bool
 }
 
 func (c *Context) walk(ctx context.Context, graph *Graph, operation walkOperation, opts *graphWalkOpts) (*ContextGraphWalker, tfdiags.Diagnostics) {
@@ -60,6 +83,12 @@ func (c *Context) walk(ctx context.Context, graph *Graph, operation walkOperatio
 	// Walk the real graph, this will block until it completes
 	diags := graph.Walk(ctx, walker)
 
+	// Close any ephemeral resources whose providers weren't closed, such as
+	// when the walk was interrupted by an error.
+	if walker.ephemeralResources != nil {
+		diags = diags.Append(walker.ephemeralResources.CloseAll())
+	}
+
 	// Close the channel so the watcher stops, and wait for it to return.
 	close(watchStop)
 	<-watchWait
@@ -142,6 +171,21 @@ func (c *Context) graphWalker(operation walkOperation, opts *graphWalkOpts) *Con
 		}
 	}
 
+	var checkPhase configs.CheckPhase
+	switch {
+	case opts.ChecksOnly:
+		checkPhase = configs.CheckPhaseCheck
+	case operation == walkPlan || operation == walkImport:
+		checkPhase = configs.CheckPhasePlan
+	case operation == walkApply:
+		checkPhase = configs.CheckPhaseApply
+	}
+
+	runID := opts.PlanTimeRunID
+	if runID == "" && c.meta != nil {
+		runID = c.meta.RunID
+	}
+
 	return &ContextGraphWalker{
 		Context:                 c,
 		State:                   state,
@@ -152,11 +196,16 @@ func (c *Context) graphWalker(operation walkOperation, opts *graphWalkOpts) *Con
 		Checks:                  checkState,
 		InstanceExpander:        instances.NewExpander(),
 		MoveResults:             opts.MoveResults,
+		CheckPhase:              checkPhase,
 		ImportResolver:          NewImportResolver(),
 		Operation:               operation,
 		StopContext:             c.runContext,
 		PlanTimestamp:           opts.PlanTimeTimestamp,
+		RunID:                   runID,
 		Encryption:              c.encryption,
 		ProviderFunctionTracker: opts.ProviderFunctionTracker,
+		ProviderFunctions:       opts.ProviderFunctions,
+		ModuleCache:             opts.ModuleCache,
+		KnownRootVariables:      opts.KnownRootVariables,
 	}
 }
@@ -0,0 +1,101 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+// dataSourceCache deduplicates the reads of data sources within a single
+// graph walk, so that data resources that read the same data source with
+// the same configuration from the same provider configuration, such as one in
+// each instance of a module, only call the provider once.
+//
+// The zero value is not usable; use newDataSourceCache. A nil cache doesn't
+// cache anything.
+type dataSourceCache struct {
+	mu      sync.Mutex
+	entries map[string]*dataSourceCacheEntry
+}
+
+type dataSourceCacheEntry struct {
+	// done is closed once resp is set.
+	done chan struct{}
+	resp providers.ReadDataSourceResponse
+}
+
+func newDataSourceCache() *dataSourceCache {
+	return &dataSourceCache{
+		entries: make(map[string]*dataSourceCacheEntry),
+	}
+}
+
+// Read returns the response to the given request to the given provider
+// configuration, calling the given function to read the data source only if
+// no identical request was made earlier in the walk. The second result is
+// true if the response is the result of an earlier request.
+//
+// Concurrent identical requests wait for the first one to complete instead of
+// also calling the provider.
+func (c *dataSourceCache) Read(providerAddr string, req providers.ReadDataSourceRequest, read func() providers.ReadDataSourceResponse) (providers.ReadDataSourceResponse, bool) {
+	if c == nil {
+		return read(), false
+	}
+	key, ok := dataSourceCacheKey(providerAddr, req)
+	if !ok {
+		return read(), false
+	}
+
+	c.mu.Lock()
+	entry, exists := c.entries[key]
+	if !exists {
+		entry = &dataSourceCacheEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if exists {
+		<-entry.done
+		return entry.resp, true
+	}
+	entry.resp = read()
+	close(entry.done)
+	return entry.resp, false
+}
+
+// dataSourceCacheKey returns a key that identifies the result of the given
+// request to the given provider configuration, or false if the request can't
+// be cached.
+func dataSourceCacheKey(providerAddr string, req providers.ReadDataSourceRequest) (string, bool) {
+	h := sha256.New()
+	h.Write([]byte(providerAddr))
+	h.Write([]byte{0})
+	h.Write([]byte(req.TypeName))
+	for _, val := range []cty.Value{req.Config, req.ProviderMeta} {
+		h.Write([]byte{0})
+		if val == cty.NilVal {
+			continue
+		}
+		// Serializing with the dynamic type includes the type in the
+		// result, so values that only differ in their types have different
+		// keys.
+		src, err := ctyjson.Marshal(val, cty.DynamicPseudoType)
+		if err != nil {
+			// This includes values that aren't wholly known, whose
+			// results the provider can't decide yet.
+			return "", false
+		}
+		h.Write(src)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
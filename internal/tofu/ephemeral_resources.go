@@ -0,0 +1,212 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ephemeralResources tracks the results of the ephemeral resource instances
+// during a single graph walk, so that expressions can refer to them, and the
+// objects that the providers opened for them, so that the objects can be
+// renewed while they are in use and closed once they no longer are.
+//
+// The results are only ever kept in memory, because ephemeral resources must
+// never be saved in the state or in a plan.
+//
+// The zero value is not usable; use newEphemeralResources.
+type ephemeralResources struct {
+	mu sync.Mutex
+	// resources maps the string representation of each expanded ephemeral
+	// resource's address to its instances.
+	resources map[string]map[addrs.InstanceKey]*ephemeralResourceInstance
+}
+
+type ephemeralResourceInstance struct {
+	addr addrs.AbsResourceInstance
+
+	// value is the result of the instance, or cty.NilVal if it hasn't been
+	// decided yet.
+	value cty.Value
+
+	// The remaining fields are only set for instances whose provider opened
+	// an object, and provider is reset once the object is being closed.
+	providerAddr addrs.AbsProviderConfig
+	provider     providers.Interface
+	private      []byte
+	renewDiags   tfdiags.Diagnostics
+	stop         chan struct{}
+	renewDone    chan struct{}
+}
+
+func newEphemeralResources() *ephemeralResources {
+	return &ephemeralResources{
+		resources: make(map[string]map[addrs.InstanceKey]*ephemeralResourceInstance),
+	}
+}
+
+// Expand records the instances of the given ephemeral resource, whose results
+// are unknown until they are set.
+func (e *ephemeralResources) Expand(addr addrs.AbsResource, instAddrs []addrs.AbsResourceInstance) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	insts := make(map[addrs.InstanceKey]*ephemeralResourceInstance, len(instAddrs))
+	for _, instAddr := range instAddrs {
+		insts[instAddr.Resource.Key] = &ephemeralResourceInstance{addr: instAddr}
+	}
+	e.resources[addr.String()] = insts
+}
+
+// Set records the result of the given ephemeral resource instance, for
+// instances whose objects can't be opened yet.
+func (e *ephemeralResources) Set(addr addrs.AbsResourceInstance, val cty.Value) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if inst := e.instance(addr); inst != nil {
+		inst.value = val
+	}
+}
+
+// Open records the result of the given ephemeral resource instance, whose
+// object the given provider opened. If the provider asked for the object to
+// be renewed, it's renewed in the background until it's closed.
+func (e *ephemeralResources) Open(addr addrs.AbsResourceInstance, val cty.Value, providerAddr addrs.AbsProviderConfig, provider providers.Interface, resp providers.OpenEphemeralResourceResponse) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	inst := e.instance(addr)
+	if inst == nil {
+		// Should never happen, since instances are only opened after their
+		// resource is expanded, but we'll still close the object.
+		inst = &ephemeralResourceInstance{addr: addr}
+		res := e.resources[addr.ContainingResource().String()]
+		if res == nil {
+			res = make(map[addrs.InstanceKey]*ephemeralResourceInstance)
+			e.resources[addr.ContainingResource().String()] = res
+		}
+		res[addr.Resource.Key] = inst
+	}
+	inst.value = val
+	inst.providerAddr = providerAddr
+	inst.provider = provider
+	inst.private = resp.Private
+	inst.stop = make(chan struct{})
+	inst.renewDone = make(chan struct{})
+	go inst.renew(provider, resp.RenewAt)
+}
+
+func (e *ephemeralResources) instance(addr addrs.AbsResourceInstance) *ephemeralResourceInstance {
+	return e.resources[addr.ContainingResource().String()][addr.Resource.Key]
+}
+
+// Instances returns the results of the instances of the given ephemeral
+// resource, with cty.NilVal for those whose results aren't decided yet. The
+// second result is false if the resource wasn't expanded yet.
+func (e *ephemeralResources) Instances(addr addrs.AbsResource) (map[addrs.InstanceKey]cty.Value, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	insts, ok := e.resources[addr.String()]
+	if !ok {
+		return nil, false
+	}
+	ret := make(map[addrs.InstanceKey]cty.Value, len(insts))
+	for key, inst := range insts {
+		ret[key] = inst.value
+	}
+	return ret, true
+}
+
+// CloseProvider closes the objects that the given provider configuration
+// opened. It must be called before the provider itself is closed.
+func (e *ephemeralResources) CloseProvider(providerAddr addrs.AbsProviderConfig) tfdiags.Diagnostics {
+	return e.close(func(inst *ephemeralResourceInstance) bool {
+		return inst.providerAddr.String() == providerAddr.String()
+	})
+}
+
+// CloseAll closes all of the objects that are still open, such as those of
+// providers that weren't closed because the walk failed.
+func (e *ephemeralResources) CloseAll() tfdiags.Diagnostics {
+	return e.close(func(*ephemeralResourceInstance) bool {
+		return true
+	})
+}
+
+func (e *ephemeralResources) close(match func(*ephemeralResourceInstance) bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	e.mu.Lock()
+	closing := make(map[*ephemeralResourceInstance]providers.Interface)
+	for _, insts := range e.resources {
+		for _, inst := range insts {
+			if inst.provider != nil && match(inst) {
+				closing[inst] = inst.provider
+				inst.provider = nil
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	for inst, provider := range closing {
+		diags = diags.Append(inst.close(provider))
+	}
+	return diags
+}
+
+// renew renews the object at the given time, and then again at the times the
+// provider returns, until the object is closed.
+func (inst *ephemeralResourceInstance) renew(provider providers.Interface, renewAt time.Time) {
+	defer close(inst.renewDone)
+
+	for !renewAt.IsZero() {
+		timer := time.NewTimer(time.Until(renewAt))
+		select {
+		case <-inst.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		log.Printf("[TRACE] ephemeralResources: renewing %s", inst.addr)
+		resp := provider.RenewEphemeralResource(providers.RenewEphemeralResourceRequest{
+			TypeName: inst.addr.Resource.Resource.Type,
+			Private:  inst.private,
+		})
+		inst.renewDiags = inst.renewDiags.Append(resp.Diagnostics)
+		if resp.Diagnostics.HasErrors() {
+			return
+		}
+		inst.private = resp.Private
+		renewAt = resp.RenewAt
+	}
+}
+
+// close stops renewing the object and closes it using the provider that
+// opened it.
+func (inst *ephemeralResourceInstance) close(provider providers.Interface) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	close(inst.stop)
+	<-inst.renewDone
+	diags = diags.Append(inst.renewDiags)
+
+	log.Printf("[TRACE] ephemeralResources: closing %s", inst.addr)
+	resp := provider.CloseEphemeralResource(providers.CloseEphemeralResourceRequest{
+		TypeName: inst.addr.Resource.Resource.Type,
+		Private:  inst.private,
+	})
+	return diags.Append(resp.Diagnostics)
+}
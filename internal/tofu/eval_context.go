@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/instances"
@@ -77,6 +78,16 @@ type EvalContext interface {
 	// provider configuration does not match the Path() of the EvalContext.
 	ConfigureProvider(addrs.AbsProviderConfig, addrs.InstanceKey, cty.Value) tfdiags.Diagnostics
 
+	// AcquireRefreshSlot blocks until the given provider configuration is
+	// allowed to handle another refresh request, and returns a function that
+	// must be called once the request is complete.
+	AcquireRefreshSlot(addrs.AbsProviderConfig) (release func())
+
+	// AcquireApplySlot blocks until the given provider configuration is
+	// allowed to handle another apply request, and returns a function that
+	// must be called once the request is complete.
+	AcquireApplySlot(addrs.AbsProviderConfig) (release func())
+
 	// ProviderInput and SetProviderInput are used to configure providers
 	// from user input.
 	//
@@ -175,6 +186,24 @@ type EvalContext interface {
 	// declared in the configuration.
 	Checks() *checks.State
 
+	// CheckPhase returns the phase that decides which check blocks run during
+	// the current graph walk, or an empty string if they all run.
+	CheckPhase() configs.CheckPhase
+
+	// ModuleCache returns the object that decides whether the previous plan
+	// for a resource instance can be reused, or nil if the current operation
+	// doesn't reuse any previous results.
+	ModuleCache() *moduleCacheState
+
+	// DataSourceCache returns the object that deduplicates identical reads
+	// of data sources during the current graph walk, or nil if reads aren't
+	// deduplicated.
+	DataSourceCache() *dataSourceCache
+
+	// EphemeralResources returns the object that tracks the results and the
+	// open objects of the ephemeral resources during the current graph walk.
+	EphemeralResources() *ephemeralResources
+
 	// RefreshState returns a wrapper object that provides safe concurrent
 	// access to the state used to store the most recently refreshed resource
 	// values.
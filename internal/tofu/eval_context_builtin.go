@@ -17,6 +17,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/instances"
@@ -69,6 +70,8 @@ type BuiltinEvalContext struct {
 	ProviderLock        *sync.Mutex
 	ProviderCache       map[string]map[addrs.InstanceKey]providers.Interface
 	ProviderInputConfig map[string]map[string]cty.Value
+	RefreshLimiter      *refreshLimiter
+	ApplyTuner          *applyTuner
 
 	ProvisionerLock  *sync.Mutex
 	ProvisionerCache map[string]provisioners.Interface
@@ -81,8 +84,13 @@ type BuiltinEvalContext struct {
 	InstanceExpanderValue   *instances.Expander
 	MoveResultsValue        refactoring.MoveResults
 	ImportResolverValue     *ImportResolver
+	CheckPhaseValue         configs.CheckPhase
+	ModuleCacheValue        *moduleCacheState
+	DataSourceCacheValue    *dataSourceCache
+	EphemeralResourcesValue *ephemeralResources
 	Encryption              encryption.Encryption
 	ProviderFunctionTracker ProviderFunctionMapping
+	ProviderFunctions       *ProviderFunctions
 }
 
 // BuiltinEvalContext implements EvalContext
@@ -161,6 +169,15 @@ func (ctx *BuiltinEvalContext) InitProvider(addr addrs.AbsProviderConfig, provid
 			p = testP.
 				withMockResources(pc.MockResources).
 				withOverrideResources(pc.OverrideResources)
+		} else if policy := providerRetryPolicy(ctx.Evaluator.Config, addr); policy != nil {
+			factory := func() (providers.Interface, error) {
+				return ctx.Plugins.NewProviderInstance(addr.Provider)
+			}
+			p = providers.NewRetryingProvider(p, factory, *policy, func(event providers.RetryEvent) {
+				_ = ctx.Hook(func(h Hook) (HookAction, error) {
+					return h.ProviderRetry(addr, event)
+				})
+			})
 		}
 	}
 
@@ -170,6 +187,30 @@ func (ctx *BuiltinEvalContext) InitProvider(addr addrs.AbsProviderConfig, provid
 	return p, nil
 }
 
+// providerRetryPolicy returns the retry policy for the given provider
+// configuration, taken from the nearest module, starting at the module that
+// contains the configuration, whose provider requirements set one for the
+// same provider. It returns nil if there is no such policy.
+func providerRetryPolicy(config *configs.Config, addr addrs.AbsProviderConfig) *providers.RetryPolicy {
+	for c := config.Root.Descendent(addr.Module); c != nil; c = c.Parent {
+		if c.Module == nil || c.Module.ProviderRequirements == nil {
+			continue
+		}
+		for _, req := range c.Module.ProviderRequirements.RequiredProviders {
+			if req.Retry == nil || !req.Type.Equals(addr.Provider) {
+				continue
+			}
+			return &providers.RetryPolicy{
+				MaxAttempts:     req.Retry.MaxAttempts,
+				InitialDelay:    req.Retry.InitialDelay,
+				MaxDelay:        req.Retry.MaxDelay,
+				RetryableErrors: req.Retry.RetryableErrors,
+			}
+		}
+	}
+	return nil
+}
+
 func (ctx *BuiltinEvalContext) Provider(addr addrs.AbsProviderConfig, key addrs.InstanceKey) providers.Interface {
 	ctx.ProviderLock.Lock()
 	defer ctx.ProviderLock.Unlock()
@@ -230,9 +271,20 @@ func (ctx *BuiltinEvalContext) ConfigureProvider(addr addrs.AbsProviderConfig, p
 	}
 
 	resp := p.ConfigureProvider(req)
+	if !resp.Diagnostics.HasErrors() {
+		ctx.ModuleCacheValue.recordProviderConfig(addr, providerKey, cfg)
+	}
 	return resp.Diagnostics
 }
 
+func (ctx *BuiltinEvalContext) AcquireRefreshSlot(addr addrs.AbsProviderConfig) func() {
+	return ctx.RefreshLimiter.Acquire(addr)
+}
+
+func (ctx *BuiltinEvalContext) AcquireApplySlot(addr addrs.AbsProviderConfig) func() {
+	return ctx.ApplyTuner.Acquire(addr)
+}
+
 func (ctx *BuiltinEvalContext) ProviderInput(pc addrs.AbsProviderConfig) map[string]cty.Value {
 	ctx.ProviderLock.Lock()
 	defer ctx.ProviderLock.Unlock()
@@ -354,6 +406,7 @@ func (ctx *BuiltinEvalContext) EvaluateReplaceTriggeredBy(expr hcl.Expression, r
 			Summary:  `Reference to undeclared resource`,
 			Detail:   fmt.Sprintf(`A resource %s has not been declared in %s`, ref.Subject, moduleDisplayAddr(ctx.Path())),
 			Subject:  expr.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredResource, nil),
 		})
 		return nil, false, diags
 	}
@@ -452,11 +505,23 @@ func (ctx *BuiltinEvalContext) EvaluationScope(self addrs.Referenceable, source
 	mc := ctx.Evaluator.Config.DescendentForInstance(ctx.PathValue)
 
 	if mc == nil || mc.Module.ProviderRequirements == nil {
-		return ctx.Evaluator.Scope(data, self, source, nil)
+		return ctx.Evaluator.Scope(data, self, source, func(pf addrs.ProviderFunction, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+			return nil, tfdiags.Diagnostics{}.Append(unknownFunctionProviderDiag(pf, rng))
+		})
 	}
 
 	scope := ctx.Evaluator.Scope(data, self, source, func(pf addrs.ProviderFunction, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
 		providedBy, ok := ctx.ProviderFunctionTracker.Lookup(ctx.PathValue.Module(), pf)
+		if !ok && ctx.canCallUntrackedProviderFunctions() {
+			// Expressions evaluated after an eval walk, such as those
+			// entered in the console, can refer to functions that aren't
+			// referenced anywhere in the configuration.
+			pr, ok := mc.Module.ProviderRequirements.RequiredProviders[pf.ProviderName]
+			if !ok {
+				return nil, tfdiags.Diagnostics{}.Append(unknownFunctionProviderDiag(pf, rng))
+			}
+			return ctx.untrackedProviderFunction(pr.Type, pf, rng)
+		}
 		if !ok {
 			// This should not be possible if references are tracked correctly
 			return nil, tfdiags.Diagnostics{}.Append(&hcl.Diagnostic{
@@ -483,6 +548,11 @@ func (ctx *BuiltinEvalContext) EvaluationScope(self addrs.Referenceable, source
 
 		provider := ctx.Provider(providedBy.Provider, providerKey)
 
+		if provider == nil && ctx.canCallUntrackedProviderFunctions() {
+			// The providers are closed at the end of an eval walk, so we
+			// must start a new instance of the provider to call the function.
+			return ctx.untrackedProviderFunction(providedBy.Provider.Provider, pf, rng)
+		}
 		if provider == nil {
 			// This should not be possible if references are tracked correctly
 			return nil, tfdiags.Diagnostics{}.Append(&hcl.Diagnostic{
@@ -500,6 +570,35 @@ func (ctx *BuiltinEvalContext) EvaluationScope(self addrs.Referenceable, source
 	return scope
 }
 
+func unknownFunctionProviderDiag(pf addrs.ProviderFunction, rng tfdiags.SourceRange) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Unknown function provider",
+		Detail:   fmt.Sprintf("Provider %q does not exist within the required_providers of this module", pf.ProviderName),
+		Subject:  rng.ToHCL().Ptr(),
+	}
+}
+
+// canCallUntrackedProviderFunctions returns true if provider functions that
+// aren't provided by a provider node in the graph can still be called.
+func (ctx *BuiltinEvalContext) canCallUntrackedProviderFunctions() bool {
+	return ctx.ProviderFunctions != nil && ctx.Evaluator.Operation == walkEval
+}
+
+// untrackedProviderFunction returns a provider function that isn't provided by
+// a provider node in the graph, using an unconfigured instance of the provider.
+func (ctx *BuiltinEvalContext) untrackedProviderFunction(provider addrs.Provider, pf addrs.ProviderFunction, rng tfdiags.SourceRange) (*function.Function, tfdiags.Diagnostics) {
+	if !ctx.canCallUntrackedProviderFunctions() {
+		return nil, tfdiags.Diagnostics{}.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "BUG: Uninitialized function provider",
+			Detail:   fmt.Sprintf("Provider function %q has not been tracked properly", pf),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+	return ctx.ProviderFunctions.Function(provider, pf.Function, rng)
+}
+
 func (ctx *BuiltinEvalContext) Path() addrs.ModuleInstance {
 	if !ctx.pathSet {
 		panic("context path not set")
@@ -565,6 +664,22 @@ func (ctx *BuiltinEvalContext) Checks() *checks.State {
 	return ctx.ChecksValue
 }
 
+func (ctx *BuiltinEvalContext) CheckPhase() configs.CheckPhase {
+	return ctx.CheckPhaseValue
+}
+
+func (ctx *BuiltinEvalContext) ModuleCache() *moduleCacheState {
+	return ctx.ModuleCacheValue
+}
+
+func (ctx *BuiltinEvalContext) DataSourceCache() *dataSourceCache {
+	return ctx.DataSourceCacheValue
+}
+
+func (ctx *BuiltinEvalContext) EphemeralResources() *ephemeralResources {
+	return ctx.EphemeralResourcesValue
+}
+
 func (ctx *BuiltinEvalContext) RefreshState() *states.SyncState {
 	return ctx.RefreshStateValue
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hcldec"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/instances"
@@ -72,6 +73,12 @@ type MockEvalContext struct {
 	ConfigureProviderConfig cty.Value
 	ConfigureProviderDiags  tfdiags.Diagnostics
 
+	AcquireRefreshSlotCalled bool
+	AcquireRefreshSlotAddr   addrs.AbsProviderConfig
+
+	AcquireApplySlotCalled bool
+	AcquireApplySlotAddr   addrs.AbsProviderConfig
+
 	ProvisionerCalled      bool
 	ProvisionerName        string
 	ProvisionerProvisioner provisioners.Interface
@@ -143,6 +150,18 @@ type MockEvalContext struct {
 	ChecksCalled bool
 	ChecksState  *checks.State
 
+	CheckPhaseCalled bool
+	CheckPhaseValue  configs.CheckPhase
+
+	ModuleCacheCalled bool
+	ModuleCacheState  *moduleCacheState
+
+	DataSourceCacheCalled bool
+	DataSourceCacheState  *dataSourceCache
+
+	EphemeralResourcesCalled bool
+	EphemeralResourcesState  *ephemeralResources
+
 	RefreshStateCalled bool
 	RefreshStateState  *states.SyncState
 
@@ -218,6 +237,18 @@ func (c *MockEvalContext) ConfigureProvider(addr addrs.AbsProviderConfig, _ addr
 	return c.ConfigureProviderDiags
 }
 
+func (c *MockEvalContext) AcquireRefreshSlot(addr addrs.AbsProviderConfig) func() {
+	c.AcquireRefreshSlotCalled = true
+	c.AcquireRefreshSlotAddr = addr
+	return func() {}
+}
+
+func (c *MockEvalContext) AcquireApplySlot(addr addrs.AbsProviderConfig) func() {
+	c.AcquireApplySlotCalled = true
+	c.AcquireApplySlotAddr = addr
+	return func() {}
+}
+
 func (c *MockEvalContext) ProviderInput(addr addrs.AbsProviderConfig) map[string]cty.Value {
 	c.ProviderInputCalled = true
 	c.ProviderInputAddr = addr
@@ -388,6 +419,26 @@ func (c *MockEvalContext) Checks() *checks.State {
 	return c.ChecksState
 }
 
+func (c *MockEvalContext) CheckPhase() configs.CheckPhase {
+	c.CheckPhaseCalled = true
+	return c.CheckPhaseValue
+}
+
+func (c *MockEvalContext) ModuleCache() *moduleCacheState {
+	c.ModuleCacheCalled = true
+	return c.ModuleCacheState
+}
+
+func (c *MockEvalContext) DataSourceCache() *dataSourceCache {
+	c.DataSourceCacheCalled = true
+	return c.DataSourceCacheState
+}
+
+func (c *MockEvalContext) EphemeralResources() *ephemeralResources {
+	c.EphemeralResourcesCalled = true
+	return c.EphemeralResourcesState
+}
+
 func (c *MockEvalContext) RefreshState() *states.SyncState {
 	c.RefreshStateCalled = true
 	return c.RefreshStateState
@@ -143,6 +143,7 @@ func prepareFinalInputVariableValue(addr addrs.AbsInputVariableInstance, raw *In
 			Summary:  "Invalid value for input variable",
 			Detail:   detail,
 			Subject:  subject,
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidVariableValue, nil),
 		})
 		// We'll return a placeholder unknown value to avoid producing
 		// redundant downstream errors.
@@ -243,6 +244,9 @@ func evalVariableValidations(addr addrs.AbsInputVariableInstance, config *config
 		// value, so we need to apply this mark separately.
 		val = val.Mark(marks.Sensitive)
 	}
+	if config.Secret {
+		val = val.Mark(marks.Secret)
+	}
 	for ix, validation := range config.Validations {
 		condRefs, condDiags := lang.ReferencesInExpr(addrs.ParseRef, validation.Condition)
 		diags = diags.Append(condDiags)
@@ -26,6 +26,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/version"
 )
 
 // Evaluator provides the necessary contextual data for evaluating expressions
@@ -67,7 +68,20 @@ type Evaluator struct {
 	// ensures they can be safely accessed and modified concurrently.
 	Changes *plans.ChangesSync
 
+	// EphemeralResources tracks the results of the ephemeral resources,
+	// which are never saved in the state. It's nil if the operation doesn't
+	// open ephemeral resources.
+	EphemeralResources *ephemeralResources
+
 	PlanTimestamp time.Time
+
+	// RunID is the value of tofu.run_id, which is the run ID of the plan
+	// during both the plan and the apply phases.
+	RunID string
+
+	// KnownRootVariables is set during the validate walk to use the values
+	// of root module input variables, instead of unknown values.
+	KnownRootVariables bool
 }
 
 // Scope creates an evaluation scope for the given module path and optional
@@ -245,6 +259,7 @@ func (d *evaluationStateData) GetInputVariable(addr addrs.InputVariable, rng tfd
 			Summary:  `Reference to undeclared input variable`,
 			Detail:   fmt.Sprintf(`An input variable with the name %q has not been declared.%s`, addr.Name, suggestion),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredVariable, nil),
 		})
 		return cty.DynamicVal, diags
 	}
@@ -264,12 +279,19 @@ func (d *evaluationStateData) GetInputVariable(addr addrs.InputVariable, rng tfd
 	// that are disabled, etc. OpenTofu's static validation leans towards
 	// being liberal in what it accepts because the subsequent plan walk has
 	// more information available and so can be more conservative.
-	if d.Operation == walkValidate {
+	//
+	// The caller can opt in to validating with the known values of the root
+	// module input variables, which lets providers validate more.
+	if d.Operation == walkValidate && !(d.Evaluator.KnownRootVariables && d.ModulePath.IsRoot()) {
 		// Ensure variable sensitivity is captured in the validate walk
+		val := cty.UnknownVal(config.Type)
 		if config.Sensitive {
-			return cty.UnknownVal(config.Type).Mark(marks.Sensitive), diags
+			val = val.Mark(marks.Sensitive)
 		}
-		return cty.UnknownVal(config.Type), diags
+		if config.Secret {
+			val = val.Mark(marks.Secret)
+		}
+		return val, diags
 	}
 
 	moduleAddrStr := d.ModulePath.String()
@@ -307,6 +329,9 @@ func (d *evaluationStateData) GetInputVariable(addr addrs.InputVariable, rng tfd
 	if config.Sensitive {
 		val = val.Mark(marks.Sensitive)
 	}
+	if config.Secret {
+		val = val.Mark(marks.Secret)
+	}
 
 	return val, diags
 }
@@ -339,6 +364,7 @@ func (d *evaluationStateData) GetLocalValue(addr addrs.LocalValue, rng tfdiags.S
 			Summary:  `Reference to undeclared local value`,
 			Detail:   fmt.Sprintf(`A local value with the name %q has not been declared.%s`, addr.Name, suggestion),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredLocal, nil),
 		})
 		return cty.DynamicVal, diags
 	}
@@ -367,6 +393,7 @@ func (d *evaluationStateData) GetModule(addr addrs.ModuleCall, rng tfdiags.Sourc
 			Summary:  `Reference to undeclared module`,
 			Detail:   fmt.Sprintf(`The configuration contains no %s.`, moduleAddr),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredModule, nil),
 		})
 		return cty.DynamicVal, diags
 	}
@@ -661,6 +688,7 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 			Summary:  `Reference to undeclared resource`,
 			Detail:   fmt.Sprintf(`A resource %q %q has not been declared in %s`, addr.Type, addr.Name, moduleDisplayAddr(moduleAddr)),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredResource, nil),
 		})
 		return cty.DynamicVal, diags
 	}
@@ -683,6 +711,12 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 	}
 	ty := schema.ImpliedType()
 
+	// Ephemeral resources are never saved in the state, so their results
+	// are tracked separately.
+	if addr.Mode == addrs.EphemeralResourceMode {
+		return d.getEphemeralResource(addr, config, ty), diags
+	}
+
 	rs := d.Evaluator.State.Resource(addr.Absolute(d.ModulePath))
 
 	if rs == nil {
@@ -831,6 +865,15 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 		instances[key] = val
 	}
 
+	return resourceInstancesValue(config, instances, ty), diags
+}
+
+// resourceInstancesValue returns the value of a resource with the given
+// configuration whose instances have the given values, which is a tuple for
+// a resource with count, an object for a resource with for_each, and the value
+// of its only instance otherwise. Instances that are missing or have no value
+// are unknown values of the given type.
+func resourceInstancesValue(config *configs.Resource, instances map[addrs.InstanceKey]cty.Value, ty cty.Type) cty.Value {
 	// ret should be populated with a valid value in all cases below
 	var ret cty.Value
 
@@ -902,7 +945,31 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 		ret = val
 	}
 
-	return ret, diags
+	return ret
+}
+
+// getEphemeralResource returns the value of the given ephemeral resource,
+// whose results are all marked as ephemeral. The result is unknown if the
+// ephemeral resource wasn't expanded, such as during validation.
+func (d *evaluationStateData) getEphemeralResource(addr addrs.Resource, config *configs.Resource, ty cty.Type) cty.Value {
+	if d.Evaluator.EphemeralResources == nil {
+		return cty.DynamicVal.Mark(marks.Ephemeral)
+	}
+	instances, ok := d.Evaluator.EphemeralResources.Instances(addr.Absolute(d.ModulePath))
+	if !ok {
+		switch {
+		case config.Count != nil, config.ForEach != nil:
+			return cty.DynamicVal.Mark(marks.Ephemeral)
+		default:
+			return cty.UnknownVal(ty).Mark(marks.Ephemeral)
+		}
+	}
+	for key, val := range instances {
+		if val == cty.NilVal {
+			instances[key] = cty.UnknownVal(ty)
+		}
+	}
+	return resourceInstancesValue(config, instances, ty).Mark(marks.Ephemeral)
 }
 
 func (d *evaluationStateData) getResourceSchema(addr addrs.Resource, providerAddr addrs.Provider) *configschema.Block {
@@ -923,6 +990,18 @@ func (d *evaluationStateData) GetTerraformAttr(addr addrs.TerraformAttr, rng tfd
 		workspaceName := d.Evaluator.Meta.Env
 		return cty.StringVal(workspaceName), diags
 
+	case "version":
+		if diags := tofuOnlyAttrDiags(addr, rng); diags.HasErrors() {
+			return cty.DynamicVal, diags
+		}
+		return cty.StringVal(version.String()), diags
+
+	case "run_id":
+		if diags := tofuOnlyAttrDiags(addr, rng); diags.HasErrors() {
+			return cty.DynamicVal, diags
+		}
+		return cty.StringVal(d.Evaluator.RunID), diags
+
 	case "env":
 		// Prior to Terraform 0.12 there was an attribute "env", which was
 		// an alias name for "workspace". This was deprecated and is now
@@ -936,16 +1015,37 @@ func (d *evaluationStateData) GetTerraformAttr(addr addrs.TerraformAttr, rng tfd
 		return cty.DynamicVal, diags
 
 	default:
+		supported := fmt.Sprintf("The only supported attribute is %s.workspace, the name of the currently-selected workspace.", addr.Alias)
+		if addr.Alias == addrs.IdentTofu {
+			supported = "The supported attributes are tofu.workspace, the name of the currently-selected workspace, tofu.version, the version of OpenTofu, and tofu.run_id, a unique identifier of the current run."
+		}
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  fmt.Sprintf("Invalid %q attribute", addr.Alias),
-			Detail:   fmt.Sprintf(`The %q object does not have an attribute named %q. The only supported attribute is %s.workspace, the name of the currently-selected workspace.`, addr.Alias, addr.Name, addr.Alias),
+			Detail:   fmt.Sprintf(`The %q object does not have an attribute named %q. %s`, addr.Alias, addr.Name, supported),
 			Subject:  rng.ToHCL().Ptr(),
 		})
 		return cty.DynamicVal, diags
 	}
 }
 
+// tofuOnlyAttrDiags returns an error if the given attribute, which is only
+// available in the "tofu" object, is referenced through the "terraform"
+// object. These attributes are specific to OpenTofu, so we don't want them to
+// look like they'd work with other software that reads the configuration.
+func tofuOnlyAttrDiags(addr addrs.TerraformAttr, rng tfdiags.SourceRange) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if addr.Alias != addrs.IdentTofu {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Invalid %q attribute", addr.Alias),
+			Detail:   fmt.Sprintf(`The %s attribute is only available as tofu.%s.`, addr.String(), addr.Name),
+			Subject:  rng.ToHCL().Ptr(),
+		})
+	}
+	return diags
+}
+
 func (d *evaluationStateData) GetOutput(addr addrs.OutputValue, rng tfdiags.SourceRange) (cty.Value, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -974,6 +1074,7 @@ func (d *evaluationStateData) GetOutput(addr addrs.OutputValue, rng tfdiags.Sour
 			Summary:  `Reference to undeclared output value`,
 			Detail:   fmt.Sprintf(`An output value with the name %q has not been declared.%s`, addr.Name, suggestion),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredOutput, nil),
 		})
 		return cty.DynamicVal, diags
 	}
@@ -6,6 +6,7 @@
 package tofu
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/version"
 )
 
 func TestEvaluatorGetTerraformAttr(t *testing.T) {
@@ -27,6 +29,7 @@ func TestEvaluatorGetTerraformAttr(t *testing.T) {
 		Meta: &ContextMeta{
 			Env: "foo",
 		},
+		RunID: "run-123",
 	}
 	data := &evaluationStateData{
 		Evaluator: evaluator,
@@ -54,6 +57,38 @@ func TestEvaluatorGetTerraformAttr(t *testing.T) {
 			t.Errorf("wrong result %q; want %q", got, want)
 		}
 	})
+
+	t.Run("tofu.version", func(t *testing.T) {
+		want := cty.StringVal(version.String())
+		got, diags := scope.Data.GetTerraformAttr(addrs.NewTerraformAttr("tofu", "version"), tfdiags.SourceRange{})
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics %s", spew.Sdump(diags))
+		}
+		if !got.RawEquals(want) {
+			t.Errorf("wrong result %q; want %q", got, want)
+		}
+	})
+
+	t.Run("tofu.run_id", func(t *testing.T) {
+		want := cty.StringVal("run-123")
+		got, diags := scope.Data.GetTerraformAttr(addrs.NewTerraformAttr("tofu", "run_id"), tfdiags.SourceRange{})
+		if len(diags) != 0 {
+			t.Errorf("unexpected diagnostics %s", spew.Sdump(diags))
+		}
+		if !got.RawEquals(want) {
+			t.Errorf("wrong result %q; want %q", got, want)
+		}
+	})
+
+	t.Run("terraform.run_id", func(t *testing.T) {
+		_, diags := scope.Data.GetTerraformAttr(addrs.NewTerraformAttr("terraform", "run_id"), tfdiags.SourceRange{})
+		if !diags.HasErrors() {
+			t.Fatalf("unexpected success")
+		}
+		if got, want := diags.Err().Error(), "only available as tofu.run_id"; !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
 }
 
 func TestEvaluatorGetPathAttr(t *testing.T) {
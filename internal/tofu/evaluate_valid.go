@@ -201,6 +201,8 @@ func (d *evaluationStateData) staticValidateResourceReference(modCfg *configs.Co
 		modeAdjective = "managed"
 	case addrs.DataResourceMode:
 		modeAdjective = "data"
+	case addrs.EphemeralResourceMode:
+		modeAdjective = "ephemeral"
 	default:
 		// should never happen
 		modeAdjective = "<invalid-mode>"
@@ -224,6 +226,7 @@ func (d *evaluationStateData) staticValidateResourceReference(modCfg *configs.Co
 			Summary:  `Reference to undeclared resource`,
 			Detail:   fmt.Sprintf(`A %s resource %q %q has not been declared in %s.%s`, modeAdjective, addr.Type, addr.Name, moduleConfigDisplayAddr(modCfg.Path), suggestion),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredResource, nil),
 		})
 		return diags
 	}
@@ -260,6 +263,7 @@ func (d *evaluationStateData) staticValidateResourceReference(modCfg *configs.Co
 			Summary:  `Invalid resource type`,
 			Detail:   fmt.Sprintf(`A %s resource type %q is not supported by provider %q.`, modeAdjective, addr.Type, providerFqn.String()),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidResourceType, nil),
 		})
 		return diags
 	}
@@ -310,6 +314,7 @@ func (d *evaluationStateData) staticValidateModuleCallReference(modCfg *configs.
 			Summary:  `Reference to undeclared module`,
 			Detail:   fmt.Sprintf(`No module call named %q is declared in %s.%s`, addr.Name, moduleConfigDisplayAddr(modCfg.Path), suggestion),
 			Subject:  rng.ToHCL().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleUndeclaredModule, nil),
 		})
 		return diags
 	}
@@ -65,7 +65,13 @@ func (b *BasicGraphBuilder) Build(path addrs.ModuleInstance) (*Graph, tfdiags.Di
 
 	if err := g.Validate(); err != nil {
 		log.Printf("[ERROR] Graph validation failed. Graph:\n\n%s", g.String())
-		diags = diags.Append(err)
+		if cycleDiags := cycleDiagnostics(g); cycleDiags.HasErrors() {
+			// Cycles are usually caused by the configuration, so we
+			// describe them in terms of the objects it declares.
+			diags = diags.Append(cycleDiags)
+		} else {
+			diags = diags.Append(err)
+		}
 		return nil, diags
 	}
 
@@ -109,6 +109,17 @@ func (b *ApplyGraphBuilder) Steps() []GraphTransformer {
 			Config:   b.Config,
 		},
 
+		// Ephemeral resources are opened again during apply, since nothing
+		// saves them.
+		&ephemeralResourceTransformer{
+			Concrete: func(a *NodeAbstractResource) dag.Vertex {
+				return &nodeExpandEphemeralResource{
+					NodeAbstractResource: a,
+				}
+			},
+			Config: b.Config,
+		},
+
 		// Add dynamic values
 		&RootVariableTransformer{Config: b.Config, RawValues: b.RootVariableValues},
 		&ModuleVariableTransformer{Config: b.Config},
@@ -199,6 +210,7 @@ func (b *ApplyGraphBuilder) Steps() []GraphTransformer {
 
 		// Close opened plugin connections
 		&CloseProviderTransformer{},
+		&ephemeralResourceCloseTransformer{},
 
 		// close the root module
 		&CloseRootModuleTransformer{
@@ -70,6 +70,7 @@ type PlanGraphBuilder struct {
 
 	ConcreteProvider                ConcreteProviderNodeFunc
 	ConcreteResource                ConcreteResourceNodeFunc
+	ConcreteEphemeralResource       ConcreteResourceNodeFunc
 	ConcreteResourceInstance        ConcreteResourceInstanceNodeFunc
 	ConcreteResourceOrphan          ConcreteResourceInstanceNodeFunc
 	ConcreteResourceInstanceDeposed ConcreteResourceInstanceDeposedNodeFunc
@@ -86,10 +87,18 @@ type PlanGraphBuilder struct {
 	// ImportTargets are the list of resources to import.
 	ImportTargets []*ImportTarget
 
+	// ImportDiscoveries are the import blocks whose objects the provider
+	// lists, so that OpenTofu can generate an import block for each of them.
+	ImportDiscoveries []*ImportDiscovery
+
 	// EndpointsToRemove are the list of resources and modules to forget from
 	// the state.
 	EndpointsToRemove []addrs.ConfigRemovable
 
+	// EndpointsToDestroy are the list of resources and modules that removed
+	// blocks request to destroy.
+	EndpointsToDestroy []addrs.ConfigRemovable
+
 	// GenerateConfig tells OpenTofu where to write and generated config for
 	// any import targets that do not already have configuration.
 	//
@@ -97,6 +106,14 @@ type PlanGraphBuilder struct {
 	GenerateConfigPath string
 
 	ProviderFunctionTracker ProviderFunctionMapping
+
+	// TargetDependencies, if not nil, records the resources that resource
+	// targeting includes because targeted resources need them.
+	TargetDependencies targetDependencies
+
+	// ModuleCache, if not nil, decides which modules' previous results the
+	// plan might reuse.
+	ModuleCache *moduleCacheState
 }
 
 // See GraphBuilder
@@ -138,6 +155,13 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 			generateConfigPathForImportTargets: b.GenerateConfigPath,
 		},
 
+		// Ephemeral resources are added in every operation, since nothing
+		// saves them.
+		&ephemeralResourceTransformer{
+			Concrete: b.ConcreteEphemeralResource,
+			Config:   b.Config,
+		},
+
 		// Add dynamic values
 		&RootVariableTransformer{Config: b.Config, RawValues: b.RootVariableValues},
 		&ModuleVariableTransformer{Config: b.Config},
@@ -162,6 +186,13 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 			Operation: b.Operation,
 		},
 
+		// Add nodes that list the objects to import for import blocks with
+		// discover blocks.
+		&importDiscoveryTransformer{
+			Config:      b.Config,
+			Discoveries: b.ImportDiscoveries,
+		},
+
 		// Add orphan resources
 		&OrphanResourceInstanceTransformer{
 			Concrete: b.ConcreteResourceOrphan,
@@ -228,6 +259,10 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 		// configuration
 		&attachDataResourceDependsOnTransformer{},
 
+		// Make sure the module fingerprints can be computed before planning
+		// the resources in the modules, if we might reuse previous results.
+		&moduleCacheTransformer{State: b.ModuleCache},
+
 		// DestroyEdgeTransformer is only required during a plan so that the
 		// TargetingTransformer can determine which nodes to keep in the graph.
 		&DestroyEdgeTransformer{
@@ -239,7 +274,7 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 		},
 
 		// Target
-		&TargetingTransformer{Targets: b.Targets, Excludes: b.Excludes},
+		&TargetingTransformer{Targets: b.Targets, Excludes: b.Excludes, Dependencies: b.TargetDependencies},
 
 		// Detect when create_before_destroy must be forced on for a particular
 		// node due to dependency edges, to avoid graph cycles during apply.
@@ -247,6 +282,7 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 
 		// Close opened plugin connections
 		&CloseProviderTransformer{},
+		&ephemeralResourceCloseTransformer{},
 
 		// Close the root module
 		&CloseRootModuleTransformer{
@@ -278,12 +314,19 @@ func (b *PlanGraphBuilder) initPlan() {
 		}
 	}
 
+	b.ConcreteEphemeralResource = func(a *NodeAbstractResource) dag.Vertex {
+		return &nodeExpandEphemeralResource{
+			NodeAbstractResource: a,
+		}
+	}
+
 	b.ConcreteResourceOrphan = func(a *NodeAbstractResourceInstance) dag.Vertex {
 		return &NodePlannableResourceInstanceOrphan{
 			NodeAbstractResourceInstance: a,
 			skipRefresh:                  b.skipRefresh,
 			skipPlanChanges:              b.skipPlanChanges,
 			EndpointsToRemove:            b.EndpointsToRemove,
+			EndpointsToDestroy:           b.EndpointsToDestroy,
 		}
 	}
 
@@ -323,6 +366,7 @@ func (b *PlanGraphBuilder) initValidate() {
 			NodeAbstractResource: a,
 		}
 	}
+	b.ConcreteEphemeralResource = b.ConcreteResource
 
 	b.ConcreteModule = func(n *nodeExpandModule) dag.Vertex {
 		return &nodeValidateModule{
@@ -354,4 +398,10 @@ func (b *PlanGraphBuilder) initImport() {
 			skipRefresh: true,
 		}
 	}
+
+	b.ConcreteEphemeralResource = func(a *NodeAbstractResource) dag.Vertex {
+		return &nodeExpandEphemeralResource{
+			NodeAbstractResource: a,
+		}
+	}
 }
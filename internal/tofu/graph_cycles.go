@@ -0,0 +1,128 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// graphNodeDeclRange is implemented by graph nodes that represent an object
+// declared in the configuration, so that problems with the graph can be
+// reported against that declaration.
+type graphNodeDeclRange interface {
+	// declRange returns the range of the declaration, or nil if the node
+	// has no configuration.
+	declRange() *hcl.Range
+}
+
+// cycleDiagnostics returns an error diagnostic for each dependency cycle and
+// each self-reference in the given graph, which explains which objects are
+// involved and where they are declared.
+//
+// The summary of each diagnostic is the same as the corresponding error from
+// dag.AcyclicGraph.Validate.
+func cycleDiagnostics(g *Graph) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	for _, cycle := range g.Cycles() {
+		cycle = sortedVertices(cycle)
+		names := make([]string, len(cycle))
+		for i, v := range cycle {
+			names[i] = dag.VertexName(v)
+		}
+
+		var detail strings.Builder
+		detail.WriteString("Each of the following objects depends on another one of them, so OpenTofu can't find an order to evaluate them in:\n")
+		for _, v := range cycle {
+			var deps []string
+			for _, dep := range sortedVertices(dag.AsVertexList(g.DownEdges(v))) {
+				if containsVertex(cycle, dep) {
+					deps = append(deps, dag.VertexName(dep))
+				}
+			}
+			fmt.Fprintf(&detail, "  - %s%s depends on %s\n", dag.VertexName(v), cycleVertexDeclared(v), strings.Join(deps, ", "))
+		}
+		detail.WriteString("\nTo break the cycle, remove one of these references or replace it with a value that doesn't depend on the others. The cycle as a graph in the DOT language, which tools such as Graphviz can draw, is:\n\n")
+		detail.WriteString("digraph {\n")
+		for _, v := range cycle {
+			for _, dep := range sortedVertices(dag.AsVertexList(g.DownEdges(v))) {
+				if containsVertex(cycle, dep) {
+					fmt.Fprintf(&detail, "  %q -> %q\n", dag.VertexName(v), dag.VertexName(dep))
+				}
+			}
+		}
+		detail.WriteString("}")
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Cycle: %s", strings.Join(names, ", ")),
+			Detail:   detail.String(),
+			Subject:  cycleVertexRange(cycle),
+		})
+	}
+
+	for _, e := range g.Edges() {
+		if e.Source() != e.Target() {
+			continue
+		}
+		v := e.Source()
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Self reference: %s", dag.VertexName(v)),
+			Detail:   fmt.Sprintf("%s%s depends on itself, so OpenTofu can't evaluate it. Remove the reference to itself from its configuration.", dag.VertexName(v), cycleVertexDeclared(v)),
+			Subject:  cycleVertexRange([]dag.Vertex{v}),
+		})
+	}
+
+	return diags
+}
+
+// cycleVertexDeclared describes where the object that the given vertex
+// represents is declared, if it has a declaration.
+func cycleVertexDeclared(v dag.Vertex) string {
+	if rng := cycleVertexRange([]dag.Vertex{v}); rng != nil {
+		return fmt.Sprintf(" (declared at %s)", rng)
+	}
+	return ""
+}
+
+// cycleVertexRange returns the declaration range of the first of the given
+// vertices that has one, or nil if none do.
+func cycleVertexRange(vertices []dag.Vertex) *hcl.Range {
+	for _, v := range vertices {
+		if n, ok := v.(graphNodeDeclRange); ok {
+			if rng := n.declRange(); rng != nil {
+				return rng
+			}
+		}
+	}
+	return nil
+}
+
+func sortedVertices(vertices []dag.Vertex) []dag.Vertex {
+	ret := make([]dag.Vertex, len(vertices))
+	copy(ret, vertices)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return dag.VertexName(ret[i]) < dag.VertexName(ret[j])
+	})
+	return ret
+}
+
+func containsVertex(vertices []dag.Vertex, v dag.Vertex) bool {
+	for _, candidate := range vertices {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
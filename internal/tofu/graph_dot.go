@@ -12,3 +12,19 @@ import "github.com/opentofu/opentofu/internal/dag"
 func GraphDot(g *Graph, opts *dag.DotOpts) (string, error) {
 	return string(g.Dot(opts)), nil
 }
+
+// GraphMermaid returns a representation of the given OpenTofu graph as a
+// Mermaid flowchart, with the same nodes as GraphDot.
+func GraphMermaid(g *Graph, opts *dag.DotOpts) (string, error) {
+	return string(g.Mermaid(opts)), nil
+}
+
+// GraphJSON returns a representation of the given OpenTofu graph as a JSON
+// object listing its nodes and edges, with the same nodes as GraphDot.
+func GraphJSON(g *Graph, opts *dag.DotOpts) (string, error) {
+	src, err := g.JSON(opts)
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
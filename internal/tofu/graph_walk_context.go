@@ -7,6 +7,7 @@ package tofu
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -15,9 +16,11 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/dag"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/profiling"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/refactoring"
@@ -40,13 +43,18 @@ type ContextGraphWalker struct {
 	InstanceExpander        *instances.Expander     // Tracks our gradual expansion of module and resource instances
 	ImportResolver          *ImportResolver         // Tracks import targets as they are being resolved
 	MoveResults             refactoring.MoveResults // Read-only record of earlier processing of move statements
+	CheckPhase              configs.CheckPhase      // Decides which check blocks run
 	Operation               walkOperation
 	StopContext             context.Context
 	RootVariableValues      InputValues
 	Config                  *configs.Config
 	PlanTimestamp           time.Time
+	RunID                   string
 	Encryption              encryption.Encryption
 	ProviderFunctionTracker ProviderFunctionMapping
+	ProviderFunctions       *ProviderFunctions
+	ModuleCache             *moduleCacheState
+	KnownRootVariables      bool
 
 	// This is an output. Do not set this, nor read it while a graph walk
 	// is in progress.
@@ -64,6 +72,10 @@ type ContextGraphWalker struct {
 
 	provisionerLock  sync.Mutex
 	provisionerCache map[string]provisioners.Interface
+
+	dataSourceCache *dataSourceCache
+
+	ephemeralResources *ephemeralResources
 }
 
 func (w *ContextGraphWalker) EnterPath(path addrs.ModuleInstance) EvalContext {
@@ -97,6 +109,9 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		VariableValues:     w.variableValues,
 		VariableValuesLock: &w.variableValuesLock,
 		PlanTimestamp:      w.PlanTimestamp,
+		RunID:              w.RunID,
+		EphemeralResources: w.ephemeralResources,
+		KnownRootVariables: w.KnownRootVariables,
 	}
 
 	ctx := &BuiltinEvalContext{
@@ -107,9 +122,15 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		Plugins:                 w.Context.plugins,
 		MoveResultsValue:        w.MoveResults,
 		ImportResolverValue:     w.ImportResolver,
+		CheckPhaseValue:         w.CheckPhase,
+		ModuleCacheValue:        w.ModuleCache,
+		DataSourceCacheValue:    w.dataSourceCache,
+		EphemeralResourcesValue: w.ephemeralResources,
 		ProviderCache:           w.providerCache,
 		ProviderInputConfig:     w.Context.providerInputConfig,
 		ProviderLock:            &w.providerLock,
+		RefreshLimiter:          w.Context.refreshLimiter,
+		ApplyTuner:              w.Context.applyTuner,
 		ProvisionerCache:        w.provisionerCache,
 		ProvisionerLock:         &w.provisionerLock,
 		ChangesValue:            w.Changes,
@@ -122,6 +143,7 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		VariableValuesLock:      &w.variableValuesLock,
 		Encryption:              w.Encryption,
 		ProviderFunctionTracker: w.ProviderFunctionTracker,
+		ProviderFunctions:       w.ProviderFunctions,
 	}
 
 	return ctx
@@ -131,6 +153,8 @@ func (w *ContextGraphWalker) init() {
 	w.contexts = make(map[string]*BuiltinEvalContext)
 	w.providerCache = make(map[string]map[addrs.InstanceKey]providers.Interface)
 	w.provisionerCache = make(map[string]provisioners.Interface)
+	w.dataSourceCache = newDataSourceCache()
+	w.ephemeralResources = newEphemeralResources()
 	w.variableValues = make(map[string]map[string]cty.Value)
 
 	// Populate root module variable values. Other modules will be populated
@@ -142,9 +166,24 @@ func (w *ContextGraphWalker) init() {
 }
 
 func (w *ContextGraphWalker) Execute(ctx EvalContext, n GraphNodeExecutable) tfdiags.Diagnostics {
+	var queued time.Time
+	if profiling.RecordingGraphNodes() {
+		queued = time.Now()
+	}
+
 	// Acquire a lock on the semaphore
 	w.Context.parallelSem.Acquire()
 	defer w.Context.parallelSem.Release()
 
+	if !queued.IsZero() {
+		started := time.Now()
+		defer func() {
+			profiling.RecordGraphNode(dag.VertexName(n), fmt.Sprintf("%T", n), w.Operation.String(), queued, started, time.Now())
+		}()
+	}
+
+	graphWalkActiveNodes.Add(context.Background(), 1)
+	defer graphWalkActiveNodes.Add(context.Background(), -1)
+
 	return n.Execute(ctx, w.Operation)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // HookAction is an enum of actions that can be taken as a result of a hook
@@ -92,6 +93,27 @@ type Hook interface {
 	PreApplyImport(addr addrs.AbsResourceInstance, importing plans.ImportingSrc) (HookAction, error)
 	PostApplyImport(addr addrs.AbsResourceInstance, importing plans.ImportingSrc) (HookAction, error)
 
+	// ProviderRetry is called before OpenTofu retries a call to a provider
+	// that failed with a transient error, as allowed by the retry policy in
+	// the provider's requirements.
+	ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (HookAction, error)
+
+	// PreProviderCall and PostProviderCall are called before and after each
+	// call to a provider to plan, apply or read a resource instance. The
+	// method is the name of the provider method, such as
+	// "ApplyResourceChange", and PostProviderCall receives the diagnostics
+	// returned by the provider. These are only notifications, so they can't
+	// halt the operation.
+	PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (HookAction, error)
+	PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (HookAction, error)
+
+	// PostApplyOutput is called during an apply for each root module output
+	// value as soon as all of the objects it refers to have been applied and
+	// its new value has been saved in the state, so that the value can be
+	// published before the whole apply completes. It isn't called for output
+	// values whose new value is unknown or that are being destroyed.
+	PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (HookAction, error)
+
 	// Stopping is called if an external signal requests that OpenTofu
 	// gracefully abort an operation in progress.
 	//
@@ -188,6 +210,22 @@ func (h *NilHook) PostApplyImport(addr addrs.AbsResourceInstance, importing plan
 	return HookActionContinue, nil
 }
 
+func (*NilHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
 func (*NilHook) Stopping() {
 	// Does nothing at all by default
 }
@@ -0,0 +1,46 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"context"
+
+	"github.com/zclconf/go-cty/cty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// metricsHook is a private Hook implementation that OpenTofu uses to count
+// the resource instances it plans and applies changes for.
+type metricsHook struct {
+	NilHook
+}
+
+var _ Hook = (*metricsHook)(nil)
+
+func (h *metricsHook) PostDiff(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (HookAction, error) {
+	if action != plans.NoOp {
+		resourcesPlanned.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("action", action.String()),
+			attribute.String("resource_type", addr.Resource.Resource.Type),
+		))
+	}
+	return HookActionContinue, nil
+}
+
+func (h *metricsHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, err error) (HookAction, error) {
+	attrs := metric.WithAttributes(attribute.String("resource_type", addr.Resource.Resource.Type))
+	if err != nil {
+		resourcesErrored.Add(context.Background(), 1, attrs)
+	} else {
+		resourcesApplied.Add(context.Background(), 1, attrs)
+	}
+	return HookActionContinue, nil
+}
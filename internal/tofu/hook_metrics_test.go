@@ -0,0 +1,66 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestMetricsHook(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	addr := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_instance", "foo", addrs.NoKey)
+	h := new(metricsHook)
+	h.PostDiff(addr, states.CurrentGen, plans.Create, cty.NullVal(cty.DynamicPseudoType), cty.EmptyObjectVal)
+	h.PostDiff(addr, states.CurrentGen, plans.NoOp, cty.EmptyObjectVal, cty.EmptyObjectVal)
+	h.PostApply(addr, states.CurrentGen, cty.EmptyObjectVal, nil)
+	h.PostApply(addr, states.CurrentGen, cty.EmptyObjectVal, errors.New("failed"))
+	h.PostApply(addr, states.CurrentGen, cty.EmptyObjectVal, errors.New("failed again"))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if v, ok := dp.Attributes.Value(attribute.Key("resource_type")); !ok || v.AsString() != "test_instance" {
+					t.Errorf("%s has wrong attributes %#v", m.Name, dp.Attributes.ToSlice())
+				}
+				got[m.Name] += dp.Value
+			}
+		}
+	}
+
+	want := map[string]int64{
+		"opentofu.resources.planned": 1,
+		"opentofu.resources.applied": 1,
+		"opentofu.resources.errored": 2,
+	}
+	for name, count := range want {
+		if got[name] != count {
+			t.Errorf("wrong value for %s: got %d, want %d", name, got[name], count)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // MockHook is an implementation of Hook that can be used for tests.
@@ -133,6 +134,34 @@ type MockHook struct {
 	PostApplyImportReturn HookAction
 	PostApplyImportError  error
 
+	ProviderRetryCalled bool
+	ProviderRetryAddr   addrs.AbsProviderConfig
+	ProviderRetryEvent  providers.RetryEvent
+	ProviderRetryReturn HookAction
+	ProviderRetryError  error
+
+	PreProviderCallCalled   bool
+	PreProviderCallAddr     addrs.AbsResourceInstance
+	PreProviderCallProvider addrs.AbsProviderConfig
+	PreProviderCallMethod   string
+	PreProviderCallReturn   HookAction
+	PreProviderCallError    error
+
+	PostProviderCallCalled      bool
+	PostProviderCallAddr        addrs.AbsResourceInstance
+	PostProviderCallProvider    addrs.AbsProviderConfig
+	PostProviderCallMethod      string
+	PostProviderCallDiagnostics tfdiags.Diagnostics
+	PostProviderCallReturn      HookAction
+	PostProviderCallError       error
+
+	PostApplyOutputCalled    bool
+	PostApplyOutputAddr      addrs.AbsOutputValue
+	PostApplyOutputSensitive bool
+	PostApplyOutputValue     cty.Value
+	PostApplyOutputReturn    HookAction
+	PostApplyOutputError     error
+
 	StoppingCalled bool
 
 	PostStateUpdateCalled bool
@@ -327,6 +356,50 @@ func (h *MockHook) PostApplyImport(addr addrs.AbsResourceInstance, importing pla
 	return h.PostApplyImportReturn, h.PostApplyImportError
 }
 
+func (h *MockHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.ProviderRetryCalled = true
+	h.ProviderRetryAddr = addr
+	h.ProviderRetryEvent = event
+	return h.ProviderRetryReturn, h.ProviderRetryError
+}
+
+func (h *MockHook) PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PreProviderCallCalled = true
+	h.PreProviderCallAddr = addr
+	h.PreProviderCallProvider = provider
+	h.PreProviderCallMethod = method
+	return h.PreProviderCallReturn, h.PreProviderCallError
+}
+
+func (h *MockHook) PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PostProviderCallCalled = true
+	h.PostProviderCallAddr = addr
+	h.PostProviderCallProvider = provider
+	h.PostProviderCallMethod = method
+	h.PostProviderCallDiagnostics = diags
+	return h.PostProviderCallReturn, h.PostProviderCallError
+}
+
+func (h *MockHook) PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PostApplyOutputCalled = true
+	h.PostApplyOutputAddr = addr
+	h.PostApplyOutputSensitive = sensitive
+	h.PostApplyOutputValue = value
+	return h.PostApplyOutputReturn, h.PostApplyOutputError
+}
+
 func (h *MockHook) Stopping() {
 	h.Lock()
 	defer h.Unlock()
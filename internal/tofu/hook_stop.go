@@ -15,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // stopHook is a private Hook implementation that OpenTofu uses to
@@ -92,6 +93,22 @@ func (h *stopHook) PostApplyImport(addr addrs.AbsResourceInstance, importing pla
 	return h.hook()
 }
 
+func (h *stopHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (HookAction, error) {
+	return h.hook()
+}
+
 func (h *stopHook) Stopping() {}
 
 func (h *stopHook) PostStateUpdate(new *states.State) (HookAction, error) {
@@ -15,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 func TestNilHook_impl(t *testing.T) {
@@ -157,6 +158,34 @@ func (h *testHook) PostApplyImport(addr addrs.AbsResourceInstance, importing pla
 	return HookActionContinue, nil
 }
 
+func (h *testHook) ProviderRetry(addr addrs.AbsProviderConfig, event providers.RetryEvent) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"ProviderRetry", addr.String()})
+	return HookActionContinue, nil
+}
+
+func (h *testHook) PreProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"PreProviderCall", addr.String()})
+	return HookActionContinue, nil
+}
+
+func (h *testHook) PostProviderCall(addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, method string, diags tfdiags.Diagnostics) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"PostProviderCall", addr.String()})
+	return HookActionContinue, nil
+}
+
+func (h *testHook) PostApplyOutput(addr addrs.AbsOutputValue, sensitive bool, value cty.Value) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"PostApplyOutput", addr.String()})
+	return HookActionContinue, nil
+}
+
 func (h *testHook) Stopping() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
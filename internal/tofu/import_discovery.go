@@ -0,0 +1,306 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ImportDiscovery is an import block with a discover block, for which the
+// plan asks the provider to list the existing objects of the resource type.
+type ImportDiscovery struct {
+	// Config is the import block.
+	Config *configs.Import
+
+	// Results describes the objects that the provider listed, with an import
+	// block for each of them. The plan walk populates it.
+	Results []*plans.DiscoveredImport
+}
+
+// findImportDiscoveries returns the import blocks of the given configuration
+// that have discover blocks.
+//
+// Discovering objects only makes sense when OpenTofu writes the import blocks
+// for them into a file, so without a path to generate configuration into the
+// result is empty and has a warning instead.
+func (c *Context) findImportDiscoveries(config *configs.Config, generateConfigPath string) ([]*ImportDiscovery, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var discoveries []*ImportDiscovery
+	for _, ic := range config.Module.Import {
+		if ic.Discover == nil {
+			continue
+		}
+		if generateConfigPath == "" {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Import discovery skipped",
+				Detail:   "OpenTofu only lists the objects to import for an import block with a discover block when it generates configuration. Run \"tofu plan\" with the -generate-config-out option to write an import block for each object that the provider finds.",
+				Subject:  ic.Discover.DeclRange.Ptr(),
+			})
+			continue
+		}
+		discoveries = append(discoveries, &ImportDiscovery{Config: ic})
+	}
+	return discoveries, diags
+}
+
+// importDiscoveryTransformer adds a node for each import discovery, which
+// asks the provider to list the objects to import.
+type importDiscoveryTransformer struct {
+	Config      *configs.Config
+	Discoveries []*ImportDiscovery
+}
+
+func (t *importDiscoveryTransformer) Transform(g *Graph) error {
+	for _, discovery := range t.Discoveries {
+		g.Add(&nodeImportDiscovery{
+			Discovery: discovery,
+			Module:    t.Config.Module,
+		})
+	}
+	return nil
+}
+
+// nodeImportDiscovery lists the objects that an import block with a discover
+// block imports, and generates an import block for each of them.
+type nodeImportDiscovery struct {
+	Discovery *ImportDiscovery
+
+	// Module is the root module, whose resources the generated resource
+	// names mustn't conflict with.
+	Module *configs.Module
+
+	ResolvedProvider ResolvedProvider
+}
+
+var (
+	_ GraphNodeExecutable       = (*nodeImportDiscovery)(nil)
+	_ GraphNodeModuleInstance   = (*nodeImportDiscovery)(nil)
+	_ GraphNodeProviderConsumer = (*nodeImportDiscovery)(nil)
+	_ GraphNodeReferencer       = (*nodeImportDiscovery)(nil)
+)
+
+func (n *nodeImportDiscovery) Name() string {
+	return fmt.Sprintf("%s (import discovery)", n.Discovery.Config.StaticTo)
+}
+
+// GraphNodeModuleInstance
+func (n *nodeImportDiscovery) Path() addrs.ModuleInstance {
+	return addrs.RootModuleInstance
+}
+
+// GraphNodeModulePath
+func (n *nodeImportDiscovery) ModulePath() addrs.Module {
+	return addrs.RootModule
+}
+
+// GraphNodeProviderConsumer
+func (n *nodeImportDiscovery) ProvidedBy() RequestedProvider {
+	if n.ResolvedProvider.ProviderConfig.Provider.Type != "" {
+		return RequestedProvider{ProviderConfig: n.ResolvedProvider.ProviderConfig}
+	}
+	if ref := n.Discovery.Config.ProviderConfigRef; ref != nil {
+		return RequestedProvider{
+			ProviderConfig: addrs.LocalProviderConfig{
+				LocalName: ref.Name,
+				Alias:     ref.Alias,
+			},
+		}
+	}
+	return RequestedProvider{
+		ProviderConfig: addrs.LocalProviderConfig{
+			LocalName: n.Discovery.Config.StaticTo.Resource.ImpliedProvider(), // Unused, see ProviderTransformer
+		},
+	}
+}
+
+// GraphNodeProviderConsumer
+func (n *nodeImportDiscovery) Provider() addrs.Provider {
+	return n.Discovery.Config.Provider
+}
+
+// GraphNodeProviderConsumer
+func (n *nodeImportDiscovery) SetProvider(p ResolvedProvider) {
+	n.ResolvedProvider = p
+}
+
+// GraphNodeReferencer
+func (n *nodeImportDiscovery) References() []*addrs.Reference {
+	refs, _ := lang.ReferencesInExpr(addrs.ParseRef, n.Discovery.Config.Discover.Filter)
+	return refs
+}
+
+// GraphNodeExecutable
+func (n *nodeImportDiscovery) Execute(ctx EvalContext, op walkOperation) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	cfg := n.Discovery.Config
+	addr := cfg.ResolvedTo.Resource.Resource
+	log.Printf("[TRACE] nodeImportDiscovery: listing the objects to import into %s", addr)
+
+	provider, schema, err := getProvider(ctx, n.ResolvedProvider.ProviderConfig, addrs.NoKey)
+	if err != nil {
+		return diags.Append(err)
+	}
+	if !schema.ServerCapabilities.ListResources {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Provider can't discover objects to import",
+			Detail:   fmt.Sprintf("The provider %s doesn't support listing the existing objects of a resource type, so OpenTofu can't discover the objects to import into %s. Use import blocks with the IDs of the objects instead.", n.ResolvedProvider.ProviderConfig.Provider.ForDisplay(), addr),
+			Subject:  cfg.Discover.DeclRange.Ptr(),
+		})
+	}
+	if _, exists := schema.ResourceTypes[addr.Type]; !exists {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid resource type",
+			Detail:   fmt.Sprintf("The provider %s does not support resource type %q.", n.ResolvedProvider.ProviderConfig.Provider.ForDisplay(), addr.Type),
+			Subject:  cfg.To.Range().Ptr(),
+			Extra:    tfdiags.RuleExtra(tfdiags.RuleInvalidResourceType, nil),
+		})
+	}
+
+	filter := cty.NullVal(cty.DynamicPseudoType)
+	if cfg.Discover.Filter != nil {
+		var evalDiags tfdiags.Diagnostics
+		filter, evalDiags = ctx.EvaluateExpr(cfg.Discover.Filter, cty.DynamicPseudoType, nil)
+		diags = diags.Append(evalDiags)
+		if evalDiags.HasErrors() {
+			return diags
+		}
+		if !filter.IsWhollyKnown() {
+			return diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid discover filter",
+				Detail:   "The filter value depends on values that OpenTofu can't know until apply, so OpenTofu can't ask the provider which objects match it.",
+				Subject:  cfg.Discover.Filter.Range().Ptr(),
+			})
+		}
+		filter, _ = filter.UnmarkDeep()
+	}
+
+	resp := provider.ListResources(providers.ListResourcesRequest{
+		TypeName: addr.Type,
+		Filter:   filter,
+	})
+	diags = diags.Append(resp.Diagnostics)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	n.Discovery.Results = n.discoveredImports(addr, resp.Resources)
+	return diags
+}
+
+// discoveredImports returns an import block for each of the given objects
+// that the provider listed for a resource, naming the generated resources
+// after the resource in the import block and the names of the objects.
+func (n *nodeImportDiscovery) discoveredImports(prefix addrs.Resource, objs []providers.ListedResource) []*plans.DiscoveredImport {
+	used := make(map[string]bool)
+	for _, rc := range n.Module.ManagedResources {
+		used[rc.Addr().String()] = true
+	}
+
+	objs = append([]providers.ListedResource(nil), objs...)
+	sort.SliceStable(objs, func(i, j int) bool {
+		return objs[i].ID < objs[j].ID
+	})
+
+	ret := make([]*plans.DiscoveredImport, 0, len(objs))
+	for i, obj := range objs {
+		if obj.ID == "" {
+			continue
+		}
+
+		suffix := discoveredResourceNameSuffix(obj.Name)
+		if suffix == "" {
+			suffix = discoveredResourceNameSuffix(obj.ID)
+		}
+		if suffix == "" {
+			suffix = strconv.Itoa(i)
+		}
+		resource := prefix
+		resource.Name = prefix.Name + "_" + suffix
+		for k := 2; used[resource.String()]; k++ {
+			resource.Name = fmt.Sprintf("%s_%s_%d", prefix.Name, suffix, k)
+		}
+		used[resource.String()] = true
+
+		ret = append(ret, &plans.DiscoveredImport{
+			Addr:            resource.Absolute(addrs.RootModuleInstance).Instance(addrs.NoKey),
+			ID:              obj.ID,
+			GeneratedConfig: n.importBlockConfig(resource, obj.ID),
+		})
+	}
+	return ret
+}
+
+// importBlockConfig returns the HCL source of an import block that imports
+// the object with the given ID into the given resource.
+func (n *nodeImportDiscovery) importBlockConfig(resource addrs.Resource, id string) string {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body().AppendNewBlock("import", nil).Body()
+	body.SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resource.Type},
+		hcl.TraverseAttr{Name: resource.Name},
+	})
+	body.SetAttributeValue("id", cty.StringVal(id))
+	if ref := n.Discovery.Config.ProviderConfigRef; ref != nil {
+		traversal := hcl.Traversal{hcl.TraverseRoot{Name: ref.Name}}
+		if ref.Alias != "" {
+			traversal = append(traversal, hcl.TraverseAttr{Name: ref.Alias})
+		}
+		body.SetAttributeTraversal("provider", traversal)
+	}
+	return strings.TrimSpace(string(f.Bytes()))
+}
+
+// discoveredResourceNameSuffix returns the given name or ID of a listed
+// object with the characters that can't appear in resource names replaced,
+// or an empty string if nothing is left of it.
+func discoveredResourceNameSuffix(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			b.WriteRune(r)
+			underscore = false
+		case !underscore:
+			b.WriteRune('_')
+			underscore = true
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// discoveredImports returns the results of the given import discoveries,
+// sorted by the addresses of the generated resources.
+func discoveredImports(discoveries []*ImportDiscovery) []*plans.DiscoveredImport {
+	var ret []*plans.DiscoveredImport
+	for _, discovery := range discoveries {
+		ret = append(ret, discovery.Results...)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Addr.Less(ret[j].Addr)
+	})
+	return ret
+}
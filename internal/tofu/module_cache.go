@@ -0,0 +1,495 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/refactoring"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// ModuleCache allows a plan to reuse the results of the previous plan for
+// the resources in module subtrees that haven't changed since then.
+//
+// A module instance's subtree is unchanged if its configuration and the
+// configuration of all of its descendants, its input variable values, the
+// selected provider versions, the configuration of the providers its
+// resources use and the prior state of all of its resources are the same as
+// when the previous plan recorded its fingerprint, and that previous plan
+// proposed no changes for any of the resources in the subtree. OpenTofu then
+// doesn't ask the providers to plan those resources again, and instead
+// evaluates their values from the prior state.
+//
+// The fingerprint doesn't cover anything else that expressions can read, so
+// subtrees with modules that read files, call impure or provider functions,
+// or refer to tofu.run_id or path.cwd are never reused.
+//
+// Reusing results is only possible when refreshing is disabled, because
+// OpenTofu can't otherwise know whether the remote objects have changed,
+// unless SkipRefresh is set.
+type ModuleCache struct {
+	// Previous are the fingerprints recorded by the previous plan, by module
+	// instance address.
+	Previous map[string]ModuleFingerprint
+
+	// Current is populated by a successful plan with the fingerprints of all
+	// of the module instances whose subtrees had no changes, for use as
+	// Previous in the next plan.
+	Current map[string]ModuleFingerprint
+
+	// SourceHashes maps the address of each module in the configuration to
+	// a hash of the source files it was loaded from. The subtrees of modules
+	// that have no hash are never reused.
+	SourceHashes map[string]string
+
+	// ProviderVersions are the selected versions of all providers.
+	ProviderVersions map[addrs.Provider]string
+
+	// SkipRefresh allows reusing results even when refreshing is enabled,
+	// in which case the resources of unchanged subtrees aren't refreshed
+	// either. This is for incremental plans, which trust that the remote
+	// objects of unchanged subtrees haven't changed since the previous plan.
+	SkipRefresh bool
+}
+
+// ModuleFingerprint summarizes everything that affects the plan for the
+// resources in a module instance's subtree.
+type ModuleFingerprint = states.ModuleFingerprint
+
+// moduleCacheState tracks the use of a ModuleCache during a plan walk.
+type moduleCacheState struct {
+	cache  *ModuleCache
+	config *configs.Config
+	prior  *states.State
+
+	// eligible records the modules whose subtrees can be reused at all,
+	// by the string representation of their addresses.
+	eligible map[string]bool
+
+	mu              sync.Mutex
+	fingerprints    map[string]string
+	providerConfigs map[string]string
+	used            map[string]map[string]string
+}
+
+// newModuleCacheState returns the state for reusing results from the given
+// cache during a plan, or nil if the plan options rule out any reuse.
+func newModuleCacheState(cache *ModuleCache, config *configs.Config, prior *states.State, opts *PlanOpts, moveResults refactoring.MoveResults) *moduleCacheState {
+	if cache == nil {
+		return nil
+	}
+	switch {
+	case opts.Mode != plans.NormalMode:
+		log.Printf("[DEBUG] moduleCache: not reusing results in %s", opts.Mode)
+		return nil
+	case !opts.SkipRefresh && !cache.SkipRefresh:
+		log.Printf("[DEBUG] moduleCache: not reusing results because refreshing is enabled")
+		return nil
+	case len(opts.Targets) != 0 || len(opts.Excludes) != 0 || len(opts.ForceReplace) != 0:
+		log.Printf("[DEBUG] moduleCache: not reusing results for a targeted plan")
+		return nil
+	case len(opts.ImportTargets) != 0 || len(opts.EndpointsToRemove) != 0 || moveResults.Changes.Len() != 0:
+		log.Printf("[DEBUG] moduleCache: not reusing results because the plan imports, removes or moves objects")
+		return nil
+	}
+
+	s := &moduleCacheState{
+		cache:           cache,
+		config:          config,
+		prior:           prior,
+		eligible:        make(map[string]bool),
+		fingerprints:    make(map[string]string),
+		providerConfigs: make(map[string]string),
+		used:            make(map[string]map[string]string),
+	}
+	config.DeepEach(func(c *configs.Config) {
+		if c.Path.IsRoot() {
+			return
+		}
+		ok := true
+		c.DeepEach(func(c *configs.Config) {
+			if _, exists := cache.SourceHashes[c.Path.String()]; !exists {
+				ok = false
+			}
+			// Data sources are read again in every plan, and so the
+			// resources that depend on them might need a different plan.
+			if len(c.Module.DataResources) != 0 {
+				ok = false
+			}
+			// Ephemeral resources are opened again in every plan too.
+			if len(c.Module.EphemeralResources) != 0 {
+				ok = false
+			}
+			// Conditions are only checked when a resource is planned.
+			for _, r := range c.Module.ManagedResources {
+				if len(r.Preconditions) != 0 || len(r.Postconditions) != 0 {
+					ok = false
+				}
+			}
+			if moduleReadsOutsideFingerprint(c.Module) {
+				ok = false
+			}
+		})
+		s.eligible[c.Path.String()] = ok
+	})
+	return s
+}
+
+// Reuse returns true if the previous plan for the given resource instance is
+// still valid, in which case its prior state object is also its planned
+// state and there's no need to plan it again.
+//
+// The caller must already have configured the instance's provider.
+func (s *moduleCacheState) Reuse(ctx EvalContext, addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig, key addrs.InstanceKey, schema providers.ProviderSchema) bool {
+	if s == nil {
+		return false
+	}
+
+	providerAddr := provider.InstanceString(key)
+	s.mu.Lock()
+	providerConfig := s.providerConfigs[providerAddr]
+	s.mu.Unlock()
+
+	reuse := false
+	for i := 1; i <= len(addr.Module); i++ {
+		module := addr.Module[:i]
+		if !s.eligible[module.Module().String()] {
+			continue
+		}
+		fingerprint := s.fingerprint(ctx, module)
+		if fingerprint == "" {
+			continue
+		}
+
+		s.mu.Lock()
+		used := s.used[module.String()]
+		if used == nil {
+			used = make(map[string]string)
+			s.used[module.String()] = used
+		}
+		used[providerAddr] = providerConfig
+		s.mu.Unlock()
+
+		prev, ok := s.cache.Previous[module.String()]
+		if ok && prev.Inputs == fingerprint && providerConfig != "" && prev.Providers[providerAddr] == providerConfig {
+			reuse = true
+		}
+	}
+	if !reuse {
+		return false
+	}
+
+	// The previous plan might have upgraded the object to a newer schema
+	// without saving it, so it's only valid if the prior state already
+	// matches the current schema.
+	obj := s.prior.ResourceInstance(addr)
+	if obj == nil || obj.Current == nil {
+		return false
+	}
+	_, version := schema.SchemaForResourceAddr(addr.Resource.Resource)
+	return obj.Current.SchemaVersion == version
+}
+
+// recordProviderConfig records the configuration of a provider instance, for
+// comparison with the configuration used by the previous plan.
+func (s *moduleCacheState) recordProviderConfig(addr addrs.AbsProviderConfig, key addrs.InstanceKey, config cty.Value) {
+	if s == nil {
+		return
+	}
+
+	var hash string
+	config, _ = config.UnmarkDeep()
+	if config.IsWhollyKnown() {
+		if src, err := ctyjson.Marshal(config, config.Type()); err == nil {
+			sum := sha256.Sum256(src)
+			hash = hex.EncodeToString(sum[:])
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.providerConfigs[addr.InstanceString(key)] = hash
+}
+
+// fingerprint returns the fingerprint of the given module instance's
+// subtree, or an empty string if the subtree can't be reused. The caller
+// must make sure that all of the module's input variables have already been
+// evaluated.
+func (s *moduleCacheState) fingerprint(ctx EvalContext, module addrs.ModuleInstance) string {
+	s.mu.Lock()
+	fingerprint, ok := s.fingerprints[module.String()]
+	s.mu.Unlock()
+	if ok {
+		return fingerprint
+	}
+
+	fingerprint = s.computeFingerprint(ctx, module)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprints[module.String()] = fingerprint
+	return fingerprint
+}
+
+func (s *moduleCacheState) computeFingerprint(ctx EvalContext, module addrs.ModuleInstance) string {
+	config := s.config.DescendentForInstance(module)
+	if config == nil {
+		return ""
+	}
+
+	var lines []string
+	config.DeepEach(func(c *configs.Config) {
+		lines = append(lines, fmt.Sprintf("module %s %s", c.Path, s.cache.SourceHashes[c.Path.String()]))
+	})
+	for provider, version := range s.cache.ProviderVersions {
+		lines = append(lines, fmt.Sprintf("provider %s %s", provider, version))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		fmt.Fprintln(h, line)
+	}
+
+	names := make([]string, 0, len(config.Module.Variables))
+	for name := range config.Module.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		val := ctx.GetVariableValue(addrs.InputVariable{Name: name}.Absolute(module))
+		val, _ = val.UnmarkDeep()
+		if !val.IsWhollyKnown() {
+			return ""
+		}
+		src, err := ctyjson.Marshal(val, cty.DynamicPseudoType)
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(h, "variable %s %s\n", name, src)
+	}
+
+	if !writePriorStateFingerprint(h, s.prior, module) {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writePriorStateFingerprint writes a description of the objects in the prior
+// state of the given module instance's subtree, returning false if the
+// subtree has objects that always need a new plan.
+func writePriorStateFingerprint(w io.Writer, state *states.State, module addrs.ModuleInstance) bool {
+	var keys []string
+	for key, ms := range state.Modules {
+		if moduleInstanceWithin(ms.Addr, module) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ms := state.Modules[key]
+		var resources []string
+		for key := range ms.Resources {
+			resources = append(resources, key)
+		}
+		sort.Strings(resources)
+		for _, key := range resources {
+			rs := ms.Resources[key]
+			instances := make([]addrs.InstanceKey, 0, len(rs.Instances))
+			for key := range rs.Instances {
+				instances = append(instances, key)
+			}
+			sort.Slice(instances, func(i, j int) bool {
+				return addrs.InstanceKeyLess(instances[i], instances[j])
+			})
+			for _, key := range instances {
+				is := rs.Instances[key]
+				// Deposed objects are always planned for destruction.
+				if len(is.Deposed) != 0 {
+					return false
+				}
+				if is.Current == nil {
+					continue
+				}
+				fmt.Fprintf(w, "instance %s %s %d %s\n", rs.Addr.Instance(key), rs.ProviderConfig.InstanceString(is.ProviderKey), is.Current.SchemaVersion, is.Current.AttrsJSON)
+			}
+		}
+	}
+	return true
+}
+
+// results returns the fingerprints of the module instances whose subtrees
+// have no changes in the given plan.
+func (s *moduleCacheState) results(changes *plans.Changes) map[string]ModuleFingerprint {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := make(map[string]ModuleFingerprint)
+Modules:
+	for key, fingerprint := range s.fingerprints {
+		used := s.used[key]
+		if fingerprint == "" || len(used) == 0 {
+			continue
+		}
+		for _, config := range used {
+			if config == "" {
+				continue Modules
+			}
+		}
+		module, diags := addrs.ParseModuleInstanceStr(key)
+		if diags.HasErrors() {
+			continue
+		}
+		for _, change := range changes.Resources {
+			if change.Action != plans.NoOp && moduleInstanceWithin(change.Addr.Module, module) {
+				continue Modules
+			}
+		}
+		ret[key] = ModuleFingerprint{
+			Inputs:    fingerprint,
+			Providers: used,
+		}
+	}
+	return ret
+}
+
+// moduleInstanceWithin returns true if the given module instance is the same
+// as the given ancestor or one of its descendants.
+func moduleInstanceWithin(addr, ancestor addrs.ModuleInstance) bool {
+	if len(addr) < len(ancestor) {
+		return false
+	}
+	for i, step := range ancestor {
+		if addr[i] != step {
+			return false
+		}
+	}
+	return true
+}
+
+// moduleCacheUnsafeFunctions are the functions whose results can change
+// between plans without changing a module's fingerprint, because they read
+// files, depend on the environment or aren't pure.
+var moduleCacheUnsafeFunctions = map[string]bool{
+	"abspath":          true,
+	"bcrypt":           true,
+	"file":             true,
+	"filebase64":       true,
+	"filebase64sha256": true,
+	"filebase64sha512": true,
+	"fileexists":       true,
+	"filemd5":          true,
+	"fileset":          true,
+	"filesha1":         true,
+	"filesha256":       true,
+	"filesha512":       true,
+	"pathexpand":       true,
+	"templatefile":     true,
+	"timestamp":        true,
+	"uuid":             true,
+}
+
+// moduleReadsOutsideFingerprint returns true if the expressions of the given
+// module might evaluate differently in plans with the same fingerprint.
+//
+// Expressions that can't be analyzed, such as those written in JSON syntax
+// or merged from override files, are assumed to do so.
+func moduleReadsOutsideFingerprint(mod *configs.Module) bool {
+	var bodies []hcl.Body
+	var exprs []hcl.Expression
+	for _, r := range mod.ManagedResources {
+		bodies = append(bodies, r.Config)
+		exprs = append(exprs, r.Count, r.ForEach)
+	}
+	for _, mc := range mod.ModuleCalls {
+		bodies = append(bodies, mc.Config)
+		exprs = append(exprs, mc.Count, mc.ForEach)
+	}
+	for _, pc := range mod.ProviderConfigs {
+		bodies = append(bodies, pc.Config)
+		exprs = append(exprs, pc.ForEach)
+	}
+	for _, l := range mod.Locals {
+		exprs = append(exprs, l.Expr)
+	}
+	for _, o := range mod.Outputs {
+		exprs = append(exprs, o.Expr)
+	}
+
+	var nodes []hclsyntax.Node
+	for _, body := range bodies {
+		if body == nil {
+			continue
+		}
+		node, ok := body.(*hclsyntax.Body)
+		if !ok {
+			return true
+		}
+		nodes = append(nodes, node)
+	}
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		node, ok := expr.(hclsyntax.Expression)
+		if !ok {
+			return true
+		}
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range nodes {
+		found := false
+		hclsyntax.VisitAll(node, func(node hclsyntax.Node) hcl.Diagnostics {
+			switch node := node.(type) {
+			case *hclsyntax.FunctionCallExpr:
+				fn := addrs.ParseFunction(node.Name)
+				if fn.IsNamespace(addrs.FunctionNamespaceProvider) || moduleCacheUnsafeFunctions[strings.TrimPrefix(node.Name, lang.CoreNamespace)] {
+					found = true
+				}
+			case *hclsyntax.ScopeTraversalExpr:
+				ref, diags := addrs.ParseRef(node.Traversal)
+				if diags.HasErrors() {
+					return nil
+				}
+				switch subject := ref.Subject.(type) {
+				case addrs.TerraformAttr:
+					found = found || subject.Name == "run_id"
+				case addrs.PathAttr:
+					found = found || subject.Name == "cwd"
+				}
+			}
+			return nil
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
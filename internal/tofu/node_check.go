@@ -155,6 +155,13 @@ func (n *nodeCheckAssert) Execute(ctx EvalContext, _ walkOperation) tfdiags.Diag
 	// We only want to actually execute the checks during specific
 	// operations, such as plan and applies.
 	if n.executeChecks {
+		if phase := ctx.CheckPhase(); !n.config.RunsIn(phase) {
+			// The check block is restricted to other phases, so we leave
+			// its status unknown.
+			log.Printf("[TRACE] nodeCheckAssert: skipping %s, which doesn't run in the %s phase", n.addr, phase)
+			return nil
+		}
+
 		if status := ctx.Checks().ObjectCheckStatus(n.addr); status == checks.StatusFail || status == checks.StatusError {
 			// This check is already failing, so we won't try and evaluate it.
 			// This typically means there was an error in a data block within
@@ -168,7 +175,7 @@ func (n *nodeCheckAssert) Execute(ctx EvalContext, _ walkOperation) tfdiags.Diag
 			ctx,
 			n.addr,
 			EvalDataForNoInstanceKey,
-			tfdiags.Warning)
+			checkSeverity(n.config))
 
 	}
 
@@ -186,6 +193,15 @@ func (n *nodeCheckAssert) Name() string {
 	return n.addr.String() + " (assertions)"
 }
 
+// checkSeverity returns the severity of the diagnostics for the failures of
+// the given check block.
+func checkSeverity(check *configs.Check) tfdiags.Severity {
+	if check.Severity == configs.CheckSeverityError {
+		return tfdiags.Error
+	}
+	return tfdiags.Warning
+}
+
 var (
 	_ GraphNodeExecutable = (*nodeCheckStart)(nil)
 )
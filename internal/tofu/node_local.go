@@ -33,8 +33,16 @@ var (
 	_ GraphNodeDynamicExpandable = (*nodeExpandLocal)(nil)
 	_ graphNodeTemporaryValue    = (*nodeExpandLocal)(nil)
 	_ graphNodeExpandsInstances  = (*nodeExpandLocal)(nil)
+	_ graphNodeDeclRange         = (*nodeExpandLocal)(nil)
 )
 
+func (n *nodeExpandLocal) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *nodeExpandLocal) expandsInstances() {}
 
 // graphNodeTemporaryValue
@@ -99,8 +107,16 @@ var (
 	_ GraphNodeExecutable     = (*NodeLocal)(nil)
 	_ graphNodeTemporaryValue = (*NodeLocal)(nil)
 	_ dag.GraphNodeDotter     = (*NodeLocal)(nil)
+	_ graphNodeDeclRange      = (*NodeLocal)(nil)
 )
 
+func (n *NodeLocal) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 // graphNodeTemporaryValue
 func (n *NodeLocal) temporaryValue() bool {
 	return true
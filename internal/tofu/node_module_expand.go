@@ -8,6 +8,8 @@ package tofu
 import (
 	"log"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/dag"
@@ -31,8 +33,16 @@ var (
 	_ GraphNodeReferencer       = (*nodeExpandModule)(nil)
 	_ GraphNodeReferenceOutside = (*nodeExpandModule)(nil)
 	_ graphNodeExpandsInstances = (*nodeExpandModule)(nil)
+	_ graphNodeDeclRange        = (*nodeExpandModule)(nil)
 )
 
+func (n *nodeExpandModule) declRange() *hcl.Range {
+	if n.ModuleCall == nil {
+		return nil
+	}
+	return n.ModuleCall.DeclRange.Ptr()
+}
+
 func (n *nodeExpandModule) expandsInstances() {}
 
 func (n *nodeExpandModule) Name() string {
@@ -36,8 +36,16 @@ var (
 	_ GraphNodeReferencer        = (*nodeExpandModuleVariable)(nil)
 	_ graphNodeTemporaryValue    = (*nodeExpandModuleVariable)(nil)
 	_ graphNodeExpandsInstances  = (*nodeExpandModuleVariable)(nil)
+	_ graphNodeDeclRange         = (*nodeExpandModuleVariable)(nil)
 )
 
+func (n *nodeExpandModuleVariable) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *nodeExpandModuleVariable) expandsInstances() {}
 
 func (n *nodeExpandModuleVariable) temporaryValue() bool {
@@ -127,8 +135,16 @@ var (
 	_ GraphNodeExecutable     = (*nodeModuleVariable)(nil)
 	_ graphNodeTemporaryValue = (*nodeModuleVariable)(nil)
 	_ dag.GraphNodeDotter     = (*nodeModuleVariable)(nil)
+	_ graphNodeDeclRange      = (*nodeModuleVariable)(nil)
 )
 
+func (n *nodeModuleVariable) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *nodeModuleVariable) temporaryValue() bool {
 	return true
 }
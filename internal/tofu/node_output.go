@@ -47,8 +47,16 @@ var (
 	_ GraphNodeDynamicExpandable = (*nodeExpandOutput)(nil)
 	_ graphNodeTemporaryValue    = (*nodeExpandOutput)(nil)
 	_ graphNodeExpandsInstances  = (*nodeExpandOutput)(nil)
+	_ graphNodeDeclRange         = (*nodeExpandOutput)(nil)
 )
 
+func (n *nodeExpandOutput) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *nodeExpandOutput) expandsInstances() {}
 
 func (n *nodeExpandOutput) temporaryValue() bool {
@@ -217,8 +225,16 @@ var (
 	_ GraphNodeExecutable       = (*NodeApplyableOutput)(nil)
 	_ graphNodeTemporaryValue   = (*NodeApplyableOutput)(nil)
 	_ dag.GraphNodeDotter       = (*NodeApplyableOutput)(nil)
+	_ graphNodeDeclRange        = (*NodeApplyableOutput)(nil)
 )
 
+func (n *NodeApplyableOutput) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *NodeApplyableOutput) temporaryValue() bool {
 	// this must always be evaluated if it is a root module output
 	return !n.Addr.Module.IsRoot()
@@ -385,6 +401,27 @@ If you do intend to export this data, annotate the output value as sensitive by
 					Subject: n.Config.DeclRange.Ptr(),
 				})
 			}
+			// Root module outputs are saved in the state in cleartext, so
+			// unlike the outputs of child modules they can't return secret
+			// values.
+			if marks.Contains(val, marks.Secret) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Output refers to secret values",
+					Detail:   "OpenTofu saves the root module's output values in the state, so they can't refer to values derived from secret input variables, which must never be saved in cleartext.",
+					Subject:  n.Config.DeclRange.Ptr(),
+				})
+			}
+			// Root module outputs are saved in the state, so unlike the
+			// outputs of child modules they can't return ephemeral values.
+			if marks.Contains(val, marks.Ephemeral) {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Output refers to ephemeral values",
+					Detail:   "OpenTofu saves the root module's output values in the state, so they can't refer to ephemeral resources, whose results must never be saved.",
+					Subject:  n.Config.DeclRange.Ptr(),
+				})
+			}
 		}
 	}
 
@@ -424,6 +461,15 @@ If you do intend to export this data, annotate the output value as sensitive by
 		n.setValue(state, nil, val)
 	}
 
+	// During an apply we publish each root module output value as soon as
+	// it's known, so that it's available before the whole apply completes.
+	if op == walkApply && n.Addr.Module.IsRoot() && !n.DestroyApply && val != cty.NilVal && !val.IsNull() && val.IsWhollyKnown() {
+		diags = diags.Append(updateStateHook(ctx))
+		diags = diags.Append(ctx.Hook(func(h Hook) (HookAction, error) {
+			return h.PostApplyOutput(n.Addr, n.Config.Sensitive, val)
+		}))
+	}
+
 	return diags
 }
 
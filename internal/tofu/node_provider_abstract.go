@@ -6,6 +6,8 @@
 package tofu
 
 import (
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
@@ -37,8 +39,16 @@ var (
 	_ GraphNodeAttachProvider             = (*NodeAbstractProvider)(nil)
 	_ GraphNodeAttachProviderConfigSchema = (*NodeAbstractProvider)(nil)
 	_ dag.GraphNodeDotter                 = (*NodeAbstractProvider)(nil)
+	_ graphNodeDeclRange                  = (*NodeAbstractProvider)(nil)
 )
 
+func (n *NodeAbstractProvider) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *NodeAbstractProvider) Name() string {
 	return n.Addr.String()
 }
@@ -105,8 +105,16 @@ var (
 	_ GraphNodeTargetable                  = (*NodeAbstractResource)(nil)
 	_ graphNodeAttachDataResourceDependsOn = (*NodeAbstractResource)(nil)
 	_ dag.GraphNodeDotter                  = (*NodeAbstractResource)(nil)
+	_ graphNodeDeclRange                   = (*NodeAbstractResource)(nil)
 )
 
+func (n *NodeAbstractResource) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 // NewNodeAbstractResource creates an abstract resource graph node for
 // the given absolute resource address.
 func NewNodeAbstractResource(addr addrs.ConfigResource) *NodeAbstractResource {
@@ -274,6 +282,11 @@ func (n *NodeAbstractResource) RootReferences() []*addrs.Reference {
 
 		refs, _ = lang.ReferencesInExpr(addrs.ParseRef, importTarget.Config.ID)
 		root = append(root, refs...)
+
+		if ref := importTarget.Config.ProviderConfigRef; ref != nil && ref.KeyExpression != nil {
+			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, ref.KeyExpression)
+			root = append(root, refs...)
+		}
 	}
 
 	return root
@@ -351,13 +364,20 @@ func (n *NodeAbstractResource) ProvidedBy() RequestedProvider {
 		// of them should be. They should also all have the same provider, so it
 		// shouldn't matter which we check here, as they'll all give the same.
 		if n.importTargets[0].Config != nil && n.importTargets[0].Config.ProviderConfigRef != nil {
-			return RequestedProvider{
+			ref := n.importTargets[0].Config.ProviderConfigRef
+			result := RequestedProvider{
 				ProviderConfig: addrs.LocalProviderConfig{
-					LocalName: n.importTargets[0].Config.ProviderConfigRef.Name,
-					Alias:     n.importTargets[0].Config.ProviderConfigRef.Alias,
+					LocalName: ref.Name,
+					Alias:     ref.Alias,
 				},
-				// This is where we would specify a key expression if that was supported for import blocks
 			}
+			if ref.KeyExpression != nil {
+				// The key is evaluated for each resource instance, in the
+				// same way as for a provider argument in a resource block.
+				result.KeyResource = true
+				result.KeyExpression = ref.KeyExpression
+			}
+			return result
 		}
 	}
 
@@ -474,8 +494,16 @@ func (n *NodeAbstractResource) DotNode(name string, opts *dag.DotOpts) *dag.DotN
 // eval is the only change we get to set the resource "each mode" to list
 // in that case, allowing expression evaluation to see it as a zero-element list
 // rather than as not set at all.
+//
+// Ephemeral resources are never saved in the state, so for them only the
+// expansion is recorded.
 func (n *NodeAbstractResource) writeResourceState(ctx EvalContext, addr addrs.AbsResource) (diags tfdiags.Diagnostics) {
 	state := ctx.State()
+	setResourceProvider := func() {
+		if addr.Resource.Mode != addrs.EphemeralResourceMode {
+			state.SetResourceProvider(addr, n.ResolvedProvider.ProviderConfig)
+		}
+	}
 
 	// We'll record our expansion decision in the shared "expander" object
 	// so that later operations (i.e. DynamicExpand and expression evaluation)
@@ -491,7 +519,7 @@ func (n *NodeAbstractResource) writeResourceState(ctx EvalContext, addr addrs.Ab
 			return diags
 		}
 
-		state.SetResourceProvider(addr, n.ResolvedProvider.ProviderConfig)
+		setResourceProvider()
 		expander.SetResourceCount(addr.Module, n.Addr.Resource, count)
 
 	case n.Config != nil && n.Config.ForEach != nil:
@@ -503,11 +531,11 @@ func (n *NodeAbstractResource) writeResourceState(ctx EvalContext, addr addrs.Ab
 
 		// This method takes care of all of the business logic of updating this
 		// while ensuring that any existing instances are preserved, etc.
-		state.SetResourceProvider(addr, n.ResolvedProvider.ProviderConfig)
+		setResourceProvider()
 		expander.SetResourceForEach(addr.Module, n.Addr.Resource, forEach)
 
 	default:
-		state.SetResourceProvider(addr, n.ResolvedProvider.ProviderConfig)
+		setResourceProvider()
 		expander.SetResourceSingle(addr.Module, n.Addr.Resource)
 	}
 
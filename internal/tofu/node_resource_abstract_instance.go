@@ -19,6 +19,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/encryption"
 	"github.com/opentofu/opentofu/internal/instances"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/objchange"
 	"github.com/opentofu/opentofu/internal/providers"
@@ -138,7 +139,7 @@ func (n *NodeAbstractResourceInstance) resolveProvider(ctx EvalContext, hasExpan
 				}
 			}
 			if validExpansion {
-				n.ResolvedProviderKey, diags = resolveProviderResourceInstance(ctx, n.Config.ProviderConfigRef.KeyExpression, n.Addr)
+				n.ResolvedProviderKey, diags = resolveProviderResourceInstance(ctx, n.ResolvedProvider.KeyExpression, n.Addr)
 			} else {
 				useStateFallback = true
 			}
@@ -364,6 +365,21 @@ func (n *NodeAbstractResourceInstance) checkPreventDestroy(change *plans.Resourc
 	return nil
 }
 
+// notifyProviderCall calls the PreProviderCall hook for a call to the given
+// method of the instance's provider, and returns a function that calls the
+// PostProviderCall hook with the diagnostics from the call.
+func (n *NodeAbstractResourceInstance) notifyProviderCall(ctx EvalContext, method string) func(tfdiags.Diagnostics) {
+	provider := n.ResolvedProvider.ProviderConfig
+	_ = ctx.Hook(func(h Hook) (HookAction, error) {
+		return h.PreProviderCall(n.Addr, provider, method)
+	})
+	return func(diags tfdiags.Diagnostics) {
+		_ = ctx.Hook(func(h Hook) (HookAction, error) {
+			return h.PostProviderCall(n.Addr, provider, method, diags)
+		})
+	}
+}
+
 // preApplyHook calls the pre-Apply hook
 func (n *NodeAbstractResourceInstance) preApplyHook(ctx EvalContext, change *plans.ResourceInstanceChange) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
@@ -485,6 +501,12 @@ func (n *NodeAbstractResourceInstance) writeResourceInstanceStateImpl(ctx EvalCo
 	if deposedKey == states.NotDeposed {
 		write = func(src *states.ResourceInstanceObjectSrc) {
 			state.SetResourceInstanceCurrent(absAddr, src, n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey)
+			// A note in the configuration replaces any earlier note, but
+			// otherwise the instance keeps the note it already has, such as
+			// one set by "tofu state annotate".
+			if n.Config != nil && n.Config.Managed != nil && n.Config.Managed.Note != "" {
+				state.SetResourceInstanceNote(absAddr, n.Config.Managed.Note)
+			}
 		}
 	} else {
 		write = func(src *states.ResourceInstanceObjectSrc) {
@@ -767,7 +789,11 @@ func (n *NodeAbstractResourceInstance) refresh(ctx EvalContext, deposedKey state
 		ProviderMeta: metaConfigVal,
 	}
 
+	release := ctx.AcquireRefreshSlot(n.ResolvedProvider.ProviderConfig)
+	done := n.notifyProviderCall(ctx, "ReadResource")
 	resp := provider.ReadResource(providerReq)
+	done(resp.Diagnostics)
+	release()
 	if n.Config != nil {
 		resp.Diagnostics = resp.Diagnostics.InConfigBody(n.Config.Config, n.Addr.String())
 	}
@@ -921,6 +947,10 @@ func (n *NodeAbstractResourceInstance) plan(
 	if configDiags.HasErrors() {
 		return nil, nil, keyData, diags
 	}
+	diags = diags.Append(ephemeralValueDiags(origConfigVal, "a managed resource").InConfigBody(config.Config, n.Addr.String()))
+	if diags.HasErrors() {
+		return nil, nil, keyData, diags
+	}
 
 	metaConfigVal, metaDiags := n.providerMetas(ctx)
 	diags = diags.Append(metaDiags)
@@ -1000,6 +1030,7 @@ func (n *NodeAbstractResourceInstance) plan(
 		return nil, nil, keyData, diags
 	}
 
+	done := n.notifyProviderCall(ctx, "PlanResourceChange")
 	resp := provider.PlanResourceChange(providers.PlanResourceChangeRequest{
 		TypeName:         n.Addr.Resource.Resource.Type,
 		Config:           unmarkedConfigVal,
@@ -1008,6 +1039,7 @@ func (n *NodeAbstractResourceInstance) plan(
 		PriorPrivate:     priorPrivate,
 		ProviderMeta:     metaConfigVal,
 	})
+	done(resp.Diagnostics)
 
 	diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()))
 	if diags.HasErrors() {
@@ -1669,8 +1701,19 @@ func (n *NodeAbstractResourceInstance) readDataSource(ctx EvalContext, configVal
 	if tfp, ok := provider.(ProviderWithEncryption); ok {
 		// Special case for terraform_remote_state
 		resp = tfp.ReadDataSourceEncrypted(req, n.Addr, ctx.GetEncryption())
-	} else {
+	} else if _, ok := provider.(providerForTest); ok || config.NoCache {
+		// The results of test providers depend on the resource that they
+		// read for, so they can't be shared between resources.
 		resp = provider.ReadDataSource(req)
+	} else {
+		providerAddr := n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey)
+		var cached bool
+		resp, cached = ctx.DataSourceCache().Read(providerAddr, req, func() providers.ReadDataSourceResponse {
+			return provider.ReadDataSource(req)
+		})
+		if cached {
+			log.Printf("[TRACE] readDataSource: %s reuses the result of an identical read earlier in this operation", n.Addr)
+		}
 	}
 	diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()))
 	if diags.HasErrors() {
@@ -1816,9 +1859,27 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx EvalContext, checkRule
 	if configDiags.HasErrors() {
 		return nil, nil, keyData, diags
 	}
+	diags = diags.Append(ephemeralValueDiags(configVal, "a data resource").InConfigBody(config.Config, n.Addr.String()))
+	if diags.HasErrors() {
+		return nil, nil, keyData, diags
+	}
 
 	check, nested := n.nestedInCheckBlock()
-	if nested {
+
+	// If the check block doesn't run in this phase then we don't read its
+	// data source now, but we still plan to read it during the apply step if
+	// the check block runs then.
+	checkSkipped := nested && !check.RunsIn(ctx.CheckPhase())
+	if checkSkipped && (skipPlanChanges || !check.RunsIn(configs.CheckPhaseApply)) {
+		log.Printf("[TRACE] planDataSource: %s is nested in %s, which doesn't run in the %s phase", n.Addr, check.Addr().Absolute(n.Addr.Module), ctx.CheckPhase())
+		plannedNewState := &states.ResourceInstanceObject{
+			Value:  priorVal,
+			Status: states.ObjectReady,
+		}
+		return nil, plannedNewState, keyData, diags
+	}
+
+	if nested && !checkSkipped {
 		// Going forward from this point, the only reason we will fail is
 		// that the data source fails to load its data. Normally, this would
 		// cancel the entire plan and this error message would bubble its way
@@ -1846,10 +1907,11 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx EvalContext, checkRule
 	configKnown := configVal.IsWhollyKnown()
 	depsPending := n.dependenciesHavePendingChanges(ctx)
 	// If our configuration contains any unknown values, or we depend on any
-	// unknown values then we must defer the read to the apply phase by
+	// unknown values, or the check block containing the data resource only
+	// runs during apply, then we must defer the read to the apply phase by
 	// producing a "Read" change for this resource, and a placeholder value for
 	// it in the state.
-	if depsPending || !configKnown {
+	if depsPending || !configKnown || checkSkipped {
 		// We can't plan any changes if we're only refreshing, so the only
 		// value we can set here is whatever was in state previously.
 		if skipPlanChanges {
@@ -1872,6 +1934,9 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx EvalContext, checkRule
 			// specific.
 			log.Printf("[TRACE] planDataSource: %s configuration is fully known, at least one dependency has changes pending", n.Addr)
 			reason = plans.ResourceInstanceReadBecauseDependencyPending
+		case checkSkipped:
+			log.Printf("[TRACE] planDataSource: %s is nested in a check block that only runs during apply, so deferring to apply phase", n.Addr)
+			reason = plans.ResourceInstanceReadBecauseCheckNested
 		}
 
 		unmarkedConfigVal, configMarkPaths := configVal.UnmarkDeepWithPaths()
@@ -1946,16 +2011,21 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx EvalContext, checkRule
 
 		// Any warning or error diagnostics we'll wrap with some special checks
 		// diagnostics. This is so we can identify them later, and so they'll
-		// only report as warnings.
-		readDiags = tfdiags.OverrideAll(readDiags, tfdiags.Warning, func() tfdiags.DiagnosticExtraWrapper {
+		// only report as warnings, unless the check block asks for errors.
+		severity := tfdiags.Warning
+		if readDiags.HasErrors() {
+			severity = checkSeverity(check)
+		}
+		readDiags = tfdiags.OverrideAll(readDiags, severity, func() tfdiags.DiagnosticExtraWrapper {
 			return &addrs.CheckRuleDiagnosticExtra{
 				CheckRule: addrs.NewCheckRule(addr, addrs.CheckDataResource, 0),
 			}
 		})
 
-		if !skipPlanChanges {
+		if !skipPlanChanges && check.RunsIn(configs.CheckPhaseApply) {
 			// refreshOnly plans cannot produce planned changes, so we only do
-			// this if skipPlanChanges is false.
+			// this if skipPlanChanges is false. We also don't read the data
+			// source again if the check block doesn't run during apply.
 			plannedChange = &plans.ResourceInstanceChange{
 				Addr:         n.Addr,
 				PrevRunAddr:  n.prevRunAddr(ctx),
@@ -2110,10 +2180,10 @@ func (n *NodeAbstractResourceInstance) applyDataSource(ctx EvalContext, planned
 		addr := check.Addr().Absolute(n.Addr.Module)
 
 		// We're just going to jump in here and hide away any errors for nested
-		// data blocks.
+		// data blocks, unless the check block asks for errors.
 		if readDiags.HasErrors() {
 			ctx.Checks().ReportCheckFailure(addr, addrs.CheckDataResource, 0, readDiags.Err().Error())
-			diags = diags.Append(tfdiags.OverrideAll(readDiags, tfdiags.Warning, func() tfdiags.DiagnosticExtraWrapper {
+			diags = diags.Append(tfdiags.OverrideAll(readDiags, checkSeverity(check), func() tfdiags.DiagnosticExtraWrapper {
 				return &addrs.CheckRuleDiagnosticExtra{
 					CheckRule: addrs.NewCheckRule(addr, addrs.CheckDataResource, 0),
 				}
@@ -2452,6 +2522,10 @@ func (n *NodeAbstractResourceInstance) apply(
 		if configDiags.HasErrors() {
 			return nil, diags
 		}
+		diags = diags.Append(ephemeralValueDiags(configVal, "a managed resource").InConfigBody(applyConfig.Config, n.Addr.String()))
+		if diags.HasErrors() {
+			return nil, diags
+		}
 	}
 
 	if !configVal.IsWhollyKnown() {
@@ -2489,7 +2563,7 @@ func (n *NodeAbstractResourceInstance) apply(
 	// If our config, Before or After value contain any marked values,
 	// ensure those are stripped out before sending
 	// this to the provider
-	unmarkedConfigVal, _ := configVal.UnmarkDeep()
+	unmarkedConfigVal, configPaths := configVal.UnmarkDeepWithPaths()
 	unmarkedBefore, beforePaths := change.Before.UnmarkDeepWithPaths()
 	unmarkedAfter, afterPaths := change.After.UnmarkDeepWithPaths()
 
@@ -2512,6 +2586,8 @@ func (n *NodeAbstractResourceInstance) apply(
 		return newState, diags
 	}
 
+	release := ctx.AcquireApplySlot(n.ResolvedProvider.ProviderConfig)
+	done := n.notifyProviderCall(ctx, "ApplyResourceChange")
 	resp := provider.ApplyResourceChange(providers.ApplyResourceChangeRequest{
 		TypeName:       n.Addr.Resource.Resource.Type,
 		PriorState:     unmarkedBefore,
@@ -2520,6 +2596,8 @@ func (n *NodeAbstractResourceInstance) apply(
 		PlannedPrivate: change.Private,
 		ProviderMeta:   metaConfigVal,
 	})
+	done(resp.Diagnostics)
+	release()
 
 	applyDiags := resp.Diagnostics
 	if applyConfig != nil {
@@ -2539,6 +2617,13 @@ func (n *NodeAbstractResourceInstance) apply(
 		newVal = newVal.MarkWithPaths(afterPaths)
 	}
 
+	// A saved plan only records which values are sensitive, so we take the
+	// secret marks from the configuration instead, to make sure that the
+	// state never records the secret values in cleartext.
+	if secretPaths := secretValuePaths(configPaths); len(secretPaths) > 0 && newVal != cty.NilVal {
+		newVal = newVal.MarkWithPaths(secretPaths)
+	}
+
 	if newVal == cty.NilVal {
 		// Providers are supposed to return a partial new value even when errors
 		// occur, but sometimes they don't and so in that case we'll patch that up
@@ -2776,3 +2861,18 @@ func (n *NodeAbstractResourceInstance) getProvider(ctx EvalContext) (providers.I
 
 	return provider, schema, nil
 }
+
+// secretValuePaths returns the paths in the given marks that are marked as
+// secret, with only the secret and sensitive marks.
+func secretValuePaths(pvm []cty.PathValueMarks) []cty.PathValueMarks {
+	var ret []cty.PathValueMarks
+	for _, pm := range pvm {
+		if _, secret := pm.Marks[marks.Secret]; secret {
+			ret = append(ret, cty.PathValueMarks{
+				Path:  pm.Path,
+				Marks: cty.NewValueMarks(marks.Secret, marks.Sensitive),
+			})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,304 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// nodeExpandEphemeralResource represents an ephemeral resource in the
+// configuration, and expands to a node for each of its instances in each
+// instance of its module.
+//
+// Ephemeral resources are opened again in every operation, and their results
+// are never saved in the state or in a plan.
+type nodeExpandEphemeralResource struct {
+	*NodeAbstractResource
+}
+
+var (
+	_ GraphNodeDynamicExpandable    = (*nodeExpandEphemeralResource)(nil)
+	_ GraphNodeReferenceable        = (*nodeExpandEphemeralResource)(nil)
+	_ GraphNodeReferencer           = (*nodeExpandEphemeralResource)(nil)
+	_ GraphNodeConfigResource       = (*nodeExpandEphemeralResource)(nil)
+	_ GraphNodeAttachResourceConfig = (*nodeExpandEphemeralResource)(nil)
+	_ GraphNodeTargetable           = (*nodeExpandEphemeralResource)(nil)
+)
+
+func (n *nodeExpandEphemeralResource) Name() string {
+	return n.NodeAbstractResource.Name() + " (expand)"
+}
+
+func (n *nodeExpandEphemeralResource) References() []*addrs.Reference {
+	// See nodeExpandApplyableResource.References for why we filter out the
+	// self references.
+	return filterSelfRefs(n.Addr.Resource, n.NodeAbstractResource.References())
+}
+
+func (n *nodeExpandEphemeralResource) DynamicExpand(ctx EvalContext) (*Graph, error) {
+	var g Graph
+	var diags tfdiags.Diagnostics
+
+	expander := ctx.InstanceExpander()
+	for _, module := range expander.ExpandModule(n.Addr.Module) {
+		resAddr := n.Addr.Resource.Absolute(module)
+
+		// writeResourceState only registers the expansion of ephemeral
+		// resources, without saving anything in the state.
+		moreDiags := n.writeResourceState(ctx.WithPath(module), resAddr)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+
+		instanceAddrs := expander.ExpandResource(resAddr)
+		ctx.EphemeralResources().Expand(resAddr, instanceAddrs)
+		for _, addr := range instanceAddrs {
+			a := NewNodeAbstractResourceInstance(addr)
+			a.Config = n.Config
+			a.ResolvedProvider = n.ResolvedProvider
+			a.Schema = n.Schema
+			a.ProviderMetas = n.ProviderMetas
+			a.dependsOn = n.dependsOn
+			g.Add(&nodeEphemeralResourceInstance{
+				NodeAbstractResourceInstance: a,
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags.ErrWithWarnings()
+	}
+
+	addRootNodeToGraph(&g)
+
+	return &g, diags.ErrWithWarnings()
+}
+
+// nodeEphemeralResourceInstance opens a single instance of an ephemeral
+// resource, and records its result so that expressions can refer to it.
+type nodeEphemeralResourceInstance struct {
+	*NodeAbstractResourceInstance
+}
+
+var (
+	_ GraphNodeModuleInstance = (*nodeEphemeralResourceInstance)(nil)
+	_ GraphNodeExecutable     = (*nodeEphemeralResourceInstance)(nil)
+)
+
+func (n *nodeEphemeralResourceInstance) Execute(ctx EvalContext, op walkOperation) tfdiags.Diagnostics {
+	addr := n.ResourceInstanceAddr()
+
+	diags := n.resolveProvider(ctx, true, states.NotDeposed)
+	if diags.HasErrors() {
+		return diags
+	}
+	provider, providerSchema, err := n.getProvider(ctx)
+	diags = diags.Append(err)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	config := n.Config
+	schema, _ := providerSchema.SchemaForResourceAddr(addr.ContainingResource().Resource)
+	if schema == nil {
+		// Should be caught during validation, so we don't bother with a pretty error here
+		diags = diags.Append(fmt.Errorf("provider %q does not support ephemeral resource %q", n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey), addr.Resource.Resource.Type))
+		return diags
+	}
+
+	forEach, _ := evaluateForEachExpression(config.ForEach, ctx, n.Addr)
+	keyData := EvalDataForInstanceKey(addr.Resource.Key, forEach)
+
+	configVal, _, configDiags := ctx.EvaluateBlock(config.Config, schema, nil, keyData)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		return diags
+	}
+
+	// If the configuration isn't known yet, which can only happen during
+	// planning, then the result isn't known either.
+	if !configVal.IsWhollyKnown() {
+		log.Printf("[TRACE] nodeEphemeralResourceInstance: %s configuration is not known yet, so its result is unknown", addr)
+		ctx.EphemeralResources().Set(addr, cty.UnknownVal(schema.ImpliedType()).Mark(marks.Ephemeral))
+		return diags
+	}
+
+	// Unmark before sending to provider, will re-mark before recording
+	unmarkedConfigVal, pvm := configVal.UnmarkDeepWithPaths()
+
+	log.Printf("[TRACE] nodeEphemeralResourceInstance: opening %s", addr)
+	resp := provider.OpenEphemeralResource(providers.OpenEphemeralResourceRequest{
+		TypeName: addr.Resource.Resource.Type,
+		Config:   unmarkedConfigVal,
+	})
+	diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, addr.String()))
+	if diags.HasErrors() {
+		return diags
+	}
+	newVal := resp.Result
+	if newVal == cty.NilVal {
+		// This can happen with incompletely-configured mocks. We'll allow it
+		// and treat it as an alias for a properly-typed null value.
+		newVal = cty.NullVal(schema.ImpliedType())
+	}
+
+	for _, err := range newVal.Type().TestConformance(schema.ImpliedType()) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider produced invalid object",
+			fmt.Sprintf(
+				"Provider %q produced an invalid value for %s.\n\nThis is a bug in the provider, which should be reported in the provider's own issue tracker.",
+				n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey), tfdiags.FormatErrorPrefixed(err, addr.String()),
+			),
+		))
+	}
+	if newVal.IsNull() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider produced null object",
+			fmt.Sprintf(
+				"Provider %q produced a null value for %s.\n\nThis is a bug in the provider, which should be reported in the provider's own issue tracker.",
+				n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey), addr,
+			),
+		))
+	} else if !newVal.IsWhollyKnown() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider produced invalid object",
+			fmt.Sprintf(
+				"Provider %q produced a value for %s that is not wholly known.\n\nThis is a bug in the provider, which should be reported in the provider's own issue tracker.",
+				n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey), addr,
+			),
+		))
+	}
+
+	// The object is recorded even if the result is invalid, so that it's
+	// still closed.
+	newVal = newVal.MarkWithPaths(combinePathValueMarks(pvm, schema.ValueMarks(newVal, nil)))
+	ctx.EphemeralResources().Open(addr, newVal.Mark(marks.Ephemeral), n.ResolvedProvider.ProviderConfig, provider, resp)
+
+	return diags
+}
+
+// ephemeralResourceTransformer adds a node for each ephemeral resource in
+// the configuration, including those in child modules.
+//
+// Unlike the other resources, ephemeral resources are added in every
+// operation, including destroy, because their results are never saved.
+type ephemeralResourceTransformer struct {
+	Concrete ConcreteResourceNodeFunc
+	Config   *configs.Config
+}
+
+func (t *ephemeralResourceTransformer) Transform(g *Graph) error {
+	if t.Config == nil {
+		return nil
+	}
+	t.Config.DeepEach(func(c *configs.Config) {
+		// If the module is being overridden, we don't want to create
+		// anything from the underlying module.
+		if c.Module.IsOverridden {
+			return
+		}
+		for _, r := range c.Module.EphemeralResources {
+			abstract := &NodeAbstractResource{
+				Addr: r.Addr().InModule(c.Path),
+			}
+
+			var node dag.Vertex = abstract
+			if f := t.Concrete; f != nil {
+				node = f(abstract)
+			}
+			g.Add(node)
+		}
+	})
+	return nil
+}
+
+// ephemeralResourceCloseTransformer makes the node that closes each provider
+// depend on everything that refers to the ephemeral resources of that
+// provider, directly or indirectly, so that the objects that the provider
+// opened for them stay open until they're no longer needed.
+//
+// It must run after CloseProviderTransformer.
+type ephemeralResourceCloseTransformer struct{}
+
+func (t *ephemeralResourceCloseTransformer) Transform(g *Graph) error {
+	closers := make(map[string]GraphNodeCloseProvider)
+	for _, v := range g.Vertices() {
+		if closer, ok := v.(GraphNodeCloseProvider); ok {
+			closers[closer.CloseProviderAddr().String()] = closer
+		}
+	}
+
+	for _, v := range g.Vertices() {
+		ephemeral, ok := v.(*nodeExpandEphemeralResource)
+		if !ok {
+			continue
+		}
+		closer, ok := closers[ephemeral.ResolvedProvider.ProviderConfig.String()]
+		if !ok {
+			continue
+		}
+
+		// Connecting the closer to anything that already depends on it
+		// would create a cycle.
+		closerDependents, err := g.Descendents(closer)
+		if err != nil {
+			return err
+		}
+		dependents, err := g.Descendents(ephemeral)
+		if err != nil {
+			return err
+		}
+		for _, d := range dependents {
+			if d == closer || closerDependents.Include(d) {
+				continue
+			}
+			if _, ok := d.(graphNodeRoot); ok {
+				continue
+			}
+			log.Printf("[TRACE] ephemeralResourceCloseTransformer: %s must wait for %s", dag.VertexName(closer), dag.VertexName(d))
+			g.Connect(dag.BasicEdge(closer, d))
+		}
+	}
+	return nil
+}
+
+// ephemeralValueDiags returns an error for each value within the given value
+// that is derived from an ephemeral resource, since such values can't be
+// used in the given kind of object, which OpenTofu saves in the state or in
+// a plan.
+//
+// The diagnostics are attribute diagnostics, so the caller should elaborate
+// them using InConfigBody.
+func ephemeralValueDiags(val cty.Value, what string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	_, pvms := val.UnmarkDeepWithPaths()
+	for _, pvm := range pvms {
+		if _, ok := pvm.Marks[marks.Ephemeral]; !ok {
+			continue
+		}
+		diags = diags.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Ephemeral value not allowed",
+			fmt.Sprintf("This value is derived from an ephemeral resource, so it can't be used in the configuration of %s, which OpenTofu saves in the state and in plans. Ephemeral values can only be used in provider configurations, local values, and the configurations and outputs of other ephemeral resources and child modules.", what),
+			pvm.Path,
+		))
+	}
+	return diags
+}
@@ -108,6 +108,13 @@ func (n *NodePlannableResourceInstance) dataResourceExecute(ctx EvalContext) (di
 
 	var change *plans.ResourceInstanceChange
 
+	if _, nested := n.nestedInCheckBlock(); !nested && ctx.CheckPhase() == configs.CheckPhaseCheck {
+		// When only evaluating check blocks, we use the data from the
+		// current state for the data resources outside of them.
+		log.Printf("[TRACE] NodePlannableResourceInstance: not reading %s, which isn't nested in a check block", addr)
+		return diags
+	}
+
 	_, providerSchema, err := getProvider(ctx, n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey)
 	diags = diags.Append(err)
 	if diags.HasErrors() {
@@ -175,6 +182,14 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		return diags
 	}
 
+	// If nothing that affects this instance has changed since the previous
+	// plan, which proposed no change for it, then its prior state is also
+	// its planned state and there's no need to ask the provider again.
+	if ctx.ModuleCache().Reuse(ctx, addr, n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey, providerSchema) {
+		log.Printf("[TRACE] NodePlannableResourceInstance: reusing the previous plan for %s", addr)
+		return diags
+	}
+
 	if config != nil {
 		diags = diags.Append(validateSelfRef(addr.Resource, config.Config, providerSchema))
 		if diags.HasErrors() {
@@ -257,6 +272,7 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 
 	// Refresh, maybe
 	// The import process handles its own refresh
+	var deletedOutside bool
 	if !n.skipRefresh && !importing {
 		s, refreshDiags := n.refresh(ctx, states.NotDeposed, instanceRefreshState)
 		diags = diags.Append(refreshDiags)
@@ -264,6 +280,9 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 			return diags
 		}
 
+		// The provider returns a null object if the remote object no longer
+		// exists, which we'll report as the reason for creating it again.
+		deletedOutside = instanceRefreshState != nil && !instanceRefreshState.Value.IsNull() && (s == nil || s.Value.IsNull())
 		instanceRefreshState = s
 
 		if instanceRefreshState != nil {
@@ -338,6 +357,9 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		if len(n.replaceTriggeredBy) > 0 {
 			change.ActionReason = plans.ResourceInstanceReplaceByTriggers
 		}
+		if deletedOutside && change.Action == plans.Create {
+			change.ActionReason = plans.ResourceInstanceCreateBecauseDeleted
+		}
 
 		// FIXME: it is currently important that we write resource changes to
 		// the plan (n.writeChange) before we write the corresponding state
@@ -699,7 +721,7 @@ func (n *NodePlannableResourceInstance) generateHCLStringAttributes(addr addrs.A
 		Alias:     n.ResolvedProvider.ProviderConfig.Alias,
 	}
 
-	return genconfig.GenerateResourceContents(addr, filteredSchema, providerAddr, state.Value)
+	return genconfig.GenerateResourceContents(addr, filteredSchema, providerAddr, n.ResolvedProviderKey, state.Value)
 }
 
 // mergeDeps returns the union of 2 sets of dependencies
@@ -32,6 +32,12 @@ type NodePlannableResourceInstanceOrphan struct {
 	// it might contain addresses that have nothing to do with the resource
 	// that this node represents, which the node itself must therefore ignore.
 	EndpointsToRemove []addrs.ConfigRemovable
+
+	// EndpointsToDestroy are resource and module addresses that removed
+	// blocks request to destroy. We destroy orphaned objects anyway, so this
+	// only determines the reason we report for doing so. Like
+	// EndpointsToRemove, this set isn't pre-filtered.
+	EndpointsToDestroy []addrs.ConfigRemovable
 }
 
 var (
@@ -170,6 +176,13 @@ func (n *NodePlannableResourceInstanceOrphan) managedResourceExecute(ctx EvalCon
 	// planning to delete this object. (This is best-effort; we might
 	// sometimes not have a reason.)
 	change.ActionReason = n.deleteActionReason(ctx)
+	if !shouldForget {
+		for _, etd := range n.EndpointsToDestroy {
+			if etd.TargetContains(n.Addr) {
+				change.ActionReason = plans.ResourceInstanceDeleteBecauseRemovedBlock
+			}
+		}
+	}
 
 	diags = diags.Append(n.writeChange(ctx, change, ""))
 	if diags.HasErrors() {
@@ -8,6 +8,7 @@ package tofu
 import (
 	"log"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -33,8 +34,16 @@ var (
 	_ GraphNodeExecutable     = (*NodeRootVariable)(nil)
 	_ GraphNodeModuleInstance = (*NodeRootVariable)(nil)
 	_ GraphNodeReferenceable  = (*NodeRootVariable)(nil)
+	_ graphNodeDeclRange      = (*NodeRootVariable)(nil)
 )
 
+func (n *NodeRootVariable) declRange() *hcl.Range {
+	if n.Config == nil {
+		return nil
+	}
+	return n.Config.DeclRange.Ptr()
+}
+
 func (n *NodeRootVariable) Name() string {
 	return n.Addr.String()
 }
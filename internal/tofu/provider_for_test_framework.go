@@ -148,6 +148,10 @@ func (p providerForTest) ValidateDataResourceConfig(r providers.ValidateDataReso
 	return p.internal.ValidateDataResourceConfig(r)
 }
 
+func (p providerForTest) ValidateEphemeralResourceConfig(r providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse {
+	return p.internal.ValidateEphemeralResourceConfig(r)
+}
+
 func (p providerForTest) UpgradeResourceState(r providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
 	return p.internal.UpgradeResourceState(r)
 }
@@ -164,6 +168,43 @@ func (p providerForTest) CallFunction(r providers.CallFunctionRequest) providers
 	return p.internal.CallFunction(r)
 }
 
+// OpenEphemeralResource returns a mocked result, since the internal provider
+// isn't configured.
+func (p providerForTest) OpenEphemeralResource(r providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	resSchema, _ := p.schema.SchemaForResourceType(addrs.EphemeralResourceMode, r.TypeName)
+
+	var resp providers.OpenEphemeralResourceResponse
+
+	resp.Result, resp.Diagnostics = newMockValueComposer(r.TypeName).
+		ComposeBySchema(resSchema, r.Config, nil)
+
+	return resp
+}
+
+// RenewEphemeralResource is never called, since mocked ephemeral resources
+// don't need to be renewed.
+func (p providerForTest) RenewEphemeralResource(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	return providers.RenewEphemeralResourceResponse{}
+}
+
+// CloseEphemeralResource has nothing to close, since the mocked ephemeral
+// resources weren't opened by the internal provider.
+func (p providerForTest) CloseEphemeralResource(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	return providers.CloseEphemeralResourceResponse{}
+}
+
+// CheckResourceQuotas never reports any risks, since the objects of a mocked
+// provider don't count against any real quota.
+func (p providerForTest) CheckResourceQuotas(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse {
+	return providers.CheckResourceQuotasResponse{}
+}
+
+// ListResources never finds any objects, since a mocked provider has no
+// target platform to list them from.
+func (p providerForTest) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	return providers.ListResourcesResponse{}
+}
+
 func (p providerForTest) Close() error {
 	return p.internal.Close()
 }
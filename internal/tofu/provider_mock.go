@@ -96,6 +96,36 @@ type MockProvider struct {
 	CallFunctionRequest  providers.CallFunctionRequest
 	CallFunctionFn       func(providers.CallFunctionRequest) providers.CallFunctionResponse
 
+	ValidateEphemeralResourceConfigCalled   bool
+	ValidateEphemeralResourceConfigResponse *providers.ValidateEphemeralResourceConfigResponse
+	ValidateEphemeralResourceConfigRequest  providers.ValidateEphemeralResourceConfigRequest
+	ValidateEphemeralResourceConfigFn       func(providers.ValidateEphemeralResourceConfigRequest) providers.ValidateEphemeralResourceConfigResponse
+
+	OpenEphemeralResourceCalled   bool
+	OpenEphemeralResourceResponse *providers.OpenEphemeralResourceResponse
+	OpenEphemeralResourceRequest  providers.OpenEphemeralResourceRequest
+	OpenEphemeralResourceFn       func(providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse
+
+	RenewEphemeralResourceCalled   bool
+	RenewEphemeralResourceResponse *providers.RenewEphemeralResourceResponse
+	RenewEphemeralResourceRequest  providers.RenewEphemeralResourceRequest
+	RenewEphemeralResourceFn       func(providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse
+
+	CloseEphemeralResourceCalled   bool
+	CloseEphemeralResourceResponse *providers.CloseEphemeralResourceResponse
+	CloseEphemeralResourceRequest  providers.CloseEphemeralResourceRequest
+	CloseEphemeralResourceFn       func(providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse
+
+	CheckResourceQuotasCalled   bool
+	CheckResourceQuotasResponse *providers.CheckResourceQuotasResponse
+	CheckResourceQuotasRequest  providers.CheckResourceQuotasRequest
+	CheckResourceQuotasFn       func(providers.CheckResourceQuotasRequest) providers.CheckResourceQuotasResponse
+
+	ListResourcesCalled   bool
+	ListResourcesResponse *providers.ListResourcesResponse
+	ListResourcesRequest  providers.ListResourcesRequest
+	ListResourcesFn       func(providers.ListResourcesRequest) providers.ListResourcesResponse
+
 	CloseCalled bool
 	CloseError  error
 }
@@ -558,6 +588,130 @@ func (p *MockProvider) CallFunction(r providers.CallFunctionRequest) (resp provi
 	return resp
 }
 
+func (p *MockProvider) ValidateEphemeralResourceConfig(r providers.ValidateEphemeralResourceConfigRequest) (resp providers.ValidateEphemeralResourceConfigResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.ValidateEphemeralResourceConfigCalled = true
+	p.ValidateEphemeralResourceConfigRequest = r
+
+	// Marshall the value to replicate behavior by the GRPC protocol
+	ephemeralSchema, ok := p.getProviderSchema().EphemeralResources[r.TypeName]
+	if !ok {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("no schema found for %q", r.TypeName))
+		return resp
+	}
+	_, err := msgpack.Marshal(r.Config, ephemeralSchema.Block.ImpliedType())
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(err)
+		return resp
+	}
+
+	if p.ValidateEphemeralResourceConfigFn != nil {
+		return p.ValidateEphemeralResourceConfigFn(r)
+	}
+
+	if p.ValidateEphemeralResourceConfigResponse != nil {
+		return *p.ValidateEphemeralResourceConfigResponse
+	}
+
+	return resp
+}
+
+// OpenEphemeralResource returns the configuration as the result, unless the
+// mock has a response or function for it.
+func (p *MockProvider) OpenEphemeralResource(r providers.OpenEphemeralResourceRequest) (resp providers.OpenEphemeralResourceResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.ConfigureProviderCalled {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("Configure not called before OpenEphemeralResource %q", r.TypeName))
+		return resp
+	}
+
+	p.OpenEphemeralResourceCalled = true
+	p.OpenEphemeralResourceRequest = r
+
+	if p.OpenEphemeralResourceFn != nil {
+		return p.OpenEphemeralResourceFn(r)
+	}
+
+	if p.OpenEphemeralResourceResponse != nil {
+		return *p.OpenEphemeralResourceResponse
+	}
+
+	resp.Result = r.Config
+	return resp
+}
+
+func (p *MockProvider) RenewEphemeralResource(r providers.RenewEphemeralResourceRequest) (resp providers.RenewEphemeralResourceResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.RenewEphemeralResourceCalled = true
+	p.RenewEphemeralResourceRequest = r
+
+	if p.RenewEphemeralResourceFn != nil {
+		return p.RenewEphemeralResourceFn(r)
+	}
+
+	if p.RenewEphemeralResourceResponse != nil {
+		resp = *p.RenewEphemeralResourceResponse
+	}
+	return resp
+}
+
+func (p *MockProvider) CloseEphemeralResource(r providers.CloseEphemeralResourceRequest) (resp providers.CloseEphemeralResourceResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.CloseEphemeralResourceCalled = true
+	p.CloseEphemeralResourceRequest = r
+
+	if p.CloseEphemeralResourceFn != nil {
+		return p.CloseEphemeralResourceFn(r)
+	}
+
+	if p.CloseEphemeralResourceResponse != nil {
+		resp = *p.CloseEphemeralResourceResponse
+	}
+	return resp
+}
+
+func (p *MockProvider) CheckResourceQuotas(r providers.CheckResourceQuotasRequest) (resp providers.CheckResourceQuotasResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.CheckResourceQuotasCalled = true
+	p.CheckResourceQuotasRequest = r
+
+	if p.CheckResourceQuotasFn != nil {
+		return p.CheckResourceQuotasFn(r)
+	}
+
+	if p.CheckResourceQuotasResponse != nil {
+		resp = *p.CheckResourceQuotasResponse
+	}
+	return resp
+}
+
+func (p *MockProvider) ListResources(r providers.ListResourcesRequest) (resp providers.ListResourcesResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.ListResourcesCalled = true
+	p.ListResourcesRequest = r
+
+	if p.ListResourcesFn != nil {
+		return p.ListResourcesFn(r)
+	}
+
+	if p.ListResourcesResponse != nil {
+		resp = *p.ListResourcesResponse
+	}
+	return resp
+}
+
 func (p *MockProvider) Close() error {
 	p.Lock()
 	defer p.Unlock()
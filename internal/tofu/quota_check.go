@@ -0,0 +1,158 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// checkResourceQuotas asks each instance of the given provider configuration
+// whether the objects planned for creation so far would exceed any quotas,
+// if the provider supports that.
+//
+// The risks are returned as warnings that summarizeQuotaRisks later merges
+// into a single warning for the whole plan. A provider failing to check its
+// quotas is never an error, because the check is only advisory.
+func checkResourceQuotas(ctx EvalContext, addr addrs.AbsProviderConfig) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	schema, err := ctx.ProviderSchema(addr)
+	if err != nil || !schema.ServerCapabilities.CheckResourceQuotas {
+		return diags
+	}
+
+	creates := make(map[addrs.InstanceKey]map[string]int)
+	for _, change := range ctx.Changes().GetChangesForProvider(addr) {
+		// A create_before_destroy replacement also needs room for one more
+		// object while the old one still exists.
+		if change.Action != plans.Create && change.Action != plans.CreateThenDelete {
+			continue
+		}
+		if change.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode {
+			continue
+		}
+		key := addrs.NoKey
+		if is := ctx.State().ResourceInstance(change.Addr); is != nil {
+			key = is.ProviderKey
+		}
+		if creates[key] == nil {
+			creates[key] = make(map[string]int)
+		}
+		creates[key][change.Addr.Resource.Resource.Type]++
+	}
+
+	for key, counts := range creates {
+		provider := ctx.Provider(addr, key)
+		if provider == nil {
+			continue
+		}
+
+		log.Printf("[TRACE] checkResourceQuotas: checking quotas for %s", addr.InstanceString(key))
+		resp := provider.CheckResourceQuotas(providers.CheckResourceQuotasRequest{
+			Creates: counts,
+		})
+		if resp.Diagnostics.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to check resource quotas",
+				fmt.Sprintf("OpenTofu could not check whether the planned changes fit within the quotas of %s: %s.", addr.InstanceString(key), resp.Diagnostics.Err()),
+			))
+			continue
+		}
+		diags = diags.Append(resp.Diagnostics)
+		if len(resp.Risks) > 0 {
+			diags = diags.Append(&quotaRiskDiagnostic{
+				provider: addr.InstanceString(key),
+				risks:    resp.Risks,
+			})
+		}
+	}
+
+	return diags
+}
+
+// quotaRiskDiagnostic is a warning describing the quota risks reported by a
+// single provider instance.
+type quotaRiskDiagnostic struct {
+	provider string
+	risks    []providers.QuotaRisk
+}
+
+var _ tfdiags.Diagnostic = (*quotaRiskDiagnostic)(nil)
+
+func (d *quotaRiskDiagnostic) Severity() tfdiags.Severity {
+	return tfdiags.Warning
+}
+
+func (d *quotaRiskDiagnostic) Description() tfdiags.Description {
+	return quotaRisksDescription([]*quotaRiskDiagnostic{d})
+}
+
+func (d *quotaRiskDiagnostic) Source() tfdiags.Source {
+	return tfdiags.Source{}
+}
+
+func (d *quotaRiskDiagnostic) FromExpr() *tfdiags.FromExpr {
+	return nil
+}
+
+func (d *quotaRiskDiagnostic) ExtraInfo() interface{} {
+	return nil
+}
+
+// summarizeQuotaRisks replaces the quota risk warnings from all of the
+// providers in the given diagnostics with a single warning, so that the plan
+// reports all of its quota risks in one place.
+func summarizeQuotaRisks(diags tfdiags.Diagnostics) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	var risks []*quotaRiskDiagnostic
+	for _, diag := range diags {
+		if risk, ok := diag.(*quotaRiskDiagnostic); ok {
+			risks = append(risks, risk)
+			continue
+		}
+		ret = append(ret, diag)
+	}
+	if len(risks) == 0 {
+		return diags
+	}
+
+	desc := quotaRisksDescription(risks)
+	return ret.Append(tfdiags.Sourceless(tfdiags.Warning, desc.Summary, desc.Detail))
+}
+
+func quotaRisksDescription(diags []*quotaRiskDiagnostic) tfdiags.Description {
+	var lines []string
+	for _, diag := range diags {
+		for _, risk := range diag.risks {
+			line := fmt.Sprintf("  - %s from %s: %d to create", risk.TypeName, diag.provider, risk.Requested)
+			if risk.Available >= 0 {
+				line += fmt.Sprintf(", %d available", risk.Available)
+			}
+			if risk.Detail != "" {
+				line += fmt.Sprintf(" (%s)", risk.Detail)
+			}
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+
+	return tfdiags.Description{
+		Summary: "Planned changes might exceed resource quotas",
+		Detail: fmt.Sprintf(
+			"The providers reported that creating the following objects would probably exceed a quota or limit:\n\n%s\n\nApplying this plan is likely to fail part way through. Consider raising the quotas or creating fewer objects before you apply it.",
+			strings.Join(lines, "\n"),
+		),
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// refreshLimiter limits the number of concurrent refresh requests separately
+// for each provider configuration, so that a slow or heavily-used provider
+// doesn't hold up refreshing the objects belonging to other providers.
+//
+// Refresh requests made while holding a slot from a refreshLimiter don't also
+// count against the context's overall parallelism limit.
+type refreshLimiter struct {
+	// parallelSem is the semaphore that each graph node holds while it's
+	// executing, which is temporarily released while waiting for and
+	// holding a refresh slot.
+	parallelSem Semaphore
+	limit       int
+
+	mu   sync.Mutex
+	sems map[string]Semaphore
+}
+
+func newRefreshLimiter(parallelSem Semaphore, limit int) *refreshLimiter {
+	return &refreshLimiter{
+		parallelSem: parallelSem,
+		limit:       limit,
+		sems:        make(map[string]Semaphore),
+	}
+}
+
+// Acquire blocks until a refresh slot is available for the given provider
+// configuration, and then returns a function that must be called to release
+// it once the refresh request is complete.
+//
+// The caller must be holding a slot from the parallelism semaphore, which is
+// released while the refresh slot is held and then reacquired before the
+// returned function returns.
+//
+// Acquire may be called on a nil *refreshLimiter, in which case refresh
+// requests are limited only by the parallelism semaphore and the returned
+// function does nothing.
+func (l *refreshLimiter) Acquire(addr addrs.AbsProviderConfig) (release func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	key := addr.String()
+	l.mu.Lock()
+	sem, ok := l.sems[key]
+	if !ok {
+		sem = NewSemaphore(l.limit)
+		l.sems[key] = sem
+	}
+	l.mu.Unlock()
+
+	l.parallelSem.Release()
+	sem.Acquire()
+	return func() {
+		sem.Release()
+		l.parallelSem.Acquire()
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// These instruments report on the progress of plan and apply operations.
+// They discard all measurements unless OpenTofu was started with metrics
+// exporting enabled.
+var (
+	// resourcesPlanned counts the resource instances with a planned change,
+	// with the change action as an attribute.
+	resourcesPlanned metric.Int64Counter
+
+	// resourcesApplied and resourcesErrored count the resource instances
+	// whose changes were applied successfully and unsuccessfully.
+	resourcesApplied metric.Int64Counter
+	resourcesErrored metric.Int64Counter
+
+	// graphWalkActiveNodes tracks how many graph nodes are executing at the
+	// same time, which is limited by the -parallelism option.
+	graphWalkActiveNodes metric.Int64UpDownCounter
+)
+
+func init() {
+	meter := otel.Meter("github.com/opentofu/opentofu/internal/tofu")
+
+	var errs []error
+	var err error
+	resourcesPlanned, err = meter.Int64Counter(
+		"opentofu.resources.planned",
+		metric.WithDescription("Number of resource instances with a planned change."),
+	)
+	errs = append(errs, err)
+	resourcesApplied, err = meter.Int64Counter(
+		"opentofu.resources.applied",
+		metric.WithDescription("Number of resource instances whose changes were applied."),
+	)
+	errs = append(errs, err)
+	resourcesErrored, err = meter.Int64Counter(
+		"opentofu.resources.errored",
+		metric.WithDescription("Number of resource instances whose changes failed to apply."),
+	)
+	errs = append(errs, err)
+	graphWalkActiveNodes, err = meter.Int64UpDownCounter(
+		"opentofu.graph.walk.active_nodes",
+		metric.WithDescription("Number of graph nodes currently executing."),
+	)
+	errs = append(errs, err)
+
+	for _, err := range errs {
+		if err != nil {
+			// Can only fail if an instrument definition above is invalid.
+			panic(err)
+		}
+	}
+}
@@ -96,6 +96,7 @@ func (ctx *TestContext) evaluate(state *states.SyncState, changes *plans.Changes
 			}(),
 			VariableValuesLock: new(sync.Mutex),
 			PlanTimestamp:      ctx.Plan.Timestamp,
+			RunID:              ctx.Plan.RunID,
 		},
 		ModulePath:      nil, // nil for the root module
 		InstanceKeyData: EvalDataForNoInstanceKey,
@@ -0,0 +1,92 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+)
+
+var _ GraphTransformer = (*moduleCacheTransformer)(nil)
+
+// moduleCacheTransformer makes every managed resource depend on all of the
+// input variables of the modules that contain it, so that the fingerprints of
+// those modules can be computed before deciding whether to plan the resource.
+//
+// Modules whose input variables themselves depend on one of the resources in
+// the module's subtree can't be reused, because the extra edges would create
+// a cycle.
+type moduleCacheTransformer struct {
+	State *moduleCacheState
+}
+
+func (t *moduleCacheTransformer) Transform(g *Graph) error {
+	if t.State == nil {
+		return nil
+	}
+
+	variables := make(map[string][]dag.Vertex)
+	resources := make(map[string][]dag.Vertex)
+	for _, v := range g.Vertices() {
+		switch v := v.(type) {
+		case *nodeExpandModuleVariable:
+			key := v.Module.String()
+			variables[key] = append(variables[key], v)
+		case *nodeExpandPlannableResource:
+			addr := v.ResourceAddr()
+			if addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for i := 1; i <= len(addr.Module); i++ {
+				key := addr.Module[:i].String()
+				resources[key] = append(resources[key], v)
+			}
+		}
+	}
+
+	// We handle the modules from the root down, so that the edges for the
+	// outer modules are already in place when checking the inner ones.
+	var modules []string
+	for key, eligible := range t.State.eligible {
+		if eligible {
+			modules = append(modules, key)
+		}
+	}
+	sort.Strings(modules)
+
+Modules:
+	for _, key := range modules {
+		if len(resources[key]) == 0 {
+			t.State.eligible[key] = false
+			continue
+		}
+
+		for _, v := range variables[key] {
+			deps, err := g.Ancestors(v)
+			if err != nil {
+				return err
+			}
+			for _, r := range resources[key] {
+				if deps.Include(r) {
+					log.Printf("[TRACE] moduleCacheTransformer: inputs of %s depend on its own resources, so it can't be reused", key)
+					t.State.eligible[key] = false
+					continue Modules
+				}
+			}
+		}
+
+		for _, r := range resources[key] {
+			for _, v := range variables[key] {
+				g.Connect(dag.BasicEdge(r, v))
+			}
+		}
+	}
+
+	return nil
+}
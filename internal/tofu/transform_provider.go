@@ -594,6 +594,16 @@ func (n *graphNodeCloseProvider) ModulePath() addrs.Module {
 
 // GraphNodeExecutable impl.
 func (n *graphNodeCloseProvider) Execute(ctx EvalContext, op walkOperation) (diags tfdiags.Diagnostics) {
+	// All of the resources that use this provider have been planned by now,
+	// so this is the last chance to ask it about quotas before it's closed.
+	if op == walkPlan {
+		diags = diags.Append(checkResourceQuotas(ctx, n.Addr))
+	}
+	// The objects the provider opened for ephemeral resources must be closed
+	// while the provider is still running.
+	if ephemeral := ctx.EphemeralResources(); ephemeral != nil {
+		diags = diags.Append(ephemeral.CloseProvider(n.Addr))
+	}
 	return diags.Append(ctx.CloseProvider(n.Addr))
 }
 
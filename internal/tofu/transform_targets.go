@@ -33,6 +33,10 @@ type TargetingTransformer struct {
 	Targets []addrs.Targetable
 	// List of excluded resource names specified by the user
 	Excludes []addrs.Targetable
+
+	// Dependencies, if not nil, records the resources that are only
+	// included because targeted resources need them.
+	Dependencies targetDependencies
 }
 
 func (t *TargetingTransformer) Transform(g *Graph) error {
@@ -77,6 +81,7 @@ func (t *TargetingTransformer) selectTargetedNodes(g *Graph, addrs []addrs.Targe
 			deps, _ := g.Ancestors(v)
 			for _, d := range deps {
 				targetedNodes.Add(d)
+				t.Dependencies.add(d, v)
 			}
 		}
 	}
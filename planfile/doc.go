@@ -0,0 +1,23 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// The planfile package provides a read-only API for inspecting the saved plan
+// files created by "tofu plan -out=FILE", for use by programs such as policy
+// engines and cost estimators that would otherwise need to parse the output
+// of "tofu show -json".
+//
+// Unlike the rest of the OpenTofu codebase, which is internal and can change
+// in any release, the exported API of this package follows the compatibility
+// promises of semantic versioning: new fields and functions may be added in
+// minor releases, but existing ones will not be removed or change meaning
+// until a new major version. The types in this package are independent of
+// the internal representation of plan files, which remains an implementation
+// detail.
+//
+// A program using this package can read plan files created by any version of
+// OpenTofu that uses the same plan file format version as the version of this
+// package it was built with. Open returns an error for plan files in a
+// different format, and for encrypted plan files.
+package planfile
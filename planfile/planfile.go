@@ -0,0 +1,356 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/plans"
+	internal "github.com/opentofu/opentofu/internal/plans/planfile"
+)
+
+// Plan is the content of a saved plan file.
+type Plan struct {
+	// TofuVersion is the version of OpenTofu that created the plan.
+	TofuVersion string
+
+	// Mode is the planning mode the plan was created in.
+	Mode Mode
+
+	// Errored is true if planning stopped early because of an error, in
+	// which case the plan is incomplete and cannot be applied.
+	Errored bool
+
+	// ResourceChanges describes the planned change for each resource
+	// instance, including those with no planned action.
+	ResourceChanges []*ResourceChange
+
+	// ResourceDrift describes the changes that OpenTofu detected outside of
+	// OpenTofu while refreshing, since the last run.
+	ResourceDrift []*ResourceChange
+
+	// OutputChanges describes the planned change for each root module
+	// output value.
+	OutputChanges []*OutputChange
+}
+
+// Mode is the planning mode a plan was created in.
+type Mode string
+
+const (
+	NormalMode      Mode = "normal"
+	DestroyMode     Mode = "destroy"
+	RefreshOnlyMode Mode = "refresh-only"
+)
+
+// Action is the kind of change planned for an object.
+type Action string
+
+const (
+	ActionNoOp             Action = "no-op"
+	ActionCreate           Action = "create"
+	ActionRead             Action = "read"
+	ActionUpdate           Action = "update"
+	ActionDelete           Action = "delete"
+	ActionDeleteThenCreate Action = "delete-then-create"
+	ActionCreateThenDelete Action = "create-then-delete"
+	ActionForget           Action = "forget"
+)
+
+// IsReplace returns true for both of the actions that replace an object.
+func (a Action) IsReplace() bool {
+	return a == ActionDeleteThenCreate || a == ActionCreateThenDelete
+}
+
+// ActionReason gives additional detail about why a particular action was
+// planned for a resource instance. The values are the same as for the
+// "action_reason" property in the output of "tofu show -json".
+type ActionReason string
+
+const (
+	ReasonNone                          ActionReason = ""
+	ReasonReplaceBecauseCannotUpdate    ActionReason = "replace_because_cannot_update"
+	ReasonReplaceBecauseTainted         ActionReason = "replace_because_tainted"
+	ReasonReplaceByRequest              ActionReason = "replace_by_request"
+	ReasonReplaceByTriggers             ActionReason = "replace_by_triggers"
+	ReasonDeleteBecauseNoResourceConfig ActionReason = "delete_because_no_resource_config"
+	ReasonDeleteBecauseWrongRepetition  ActionReason = "delete_because_wrong_repetition"
+	ReasonDeleteBecauseCountIndex       ActionReason = "delete_because_count_index"
+	ReasonDeleteBecauseEachKey          ActionReason = "delete_because_each_key"
+	ReasonDeleteBecauseNoModule         ActionReason = "delete_because_no_module"
+	ReasonDeleteBecauseNoMoveTarget     ActionReason = "delete_because_no_move_target"
+	ReasonReadBecauseConfigUnknown      ActionReason = "read_because_config_unknown"
+	ReasonReadBecauseDependencyPending  ActionReason = "read_because_dependency_pending"
+	ReasonReadBecauseCheckNested        ActionReason = "read_because_check_nested"
+)
+
+// ResourceChange is the planned change for a single resource instance.
+type ResourceChange struct {
+	// Address is the absolute address of the resource instance, such as
+	// module.foo.aws_instance.bar["baz"].
+	Address string
+
+	// PreviousAddress is the address the resource instance had in the
+	// previous run, if it has moved. Otherwise it is the same as Address.
+	PreviousAddress string
+
+	// ModuleAddress is the address of the module instance containing the
+	// resource instance, or an empty string for the root module.
+	ModuleAddress string
+
+	// Mode is "managed" for managed resources and "data" for data resources.
+	Mode string
+
+	// Type and Name are the resource type and name from the configuration.
+	Type, Name string
+
+	// DeposedKey identifies a deposed object of the resource instance, or is
+	// empty if the change is for its current object.
+	DeposedKey string
+
+	// Provider is the source address of the provider, such as
+	// registry.opentofu.org/hashicorp/aws.
+	Provider string
+
+	// ProviderConfig is the absolute address of the provider configuration
+	// that will apply the change.
+	ProviderConfig string
+
+	// Action is the planned action, and ActionReason gives more detail
+	// about why it was planned.
+	Action       Action
+	ActionReason ActionReason
+
+	// ImportID is the import ID if the resource instance is being imported,
+	// or an empty string otherwise.
+	ImportID string
+
+	// GeneratedConfig is the configuration OpenTofu generated for an
+	// imported resource instance, if requested.
+	GeneratedConfig string
+
+	change plans.ChangeSrc
+}
+
+// Decode returns the values of the resource instance before and after the
+// change, conforming to the given type. Callers should use the type implied
+// by the schema of the resource type, as returned by ImpliedType.
+//
+// The before value is null when creating an object and the after value is
+// null when deleting it. The after value may contain unknown values for
+// attributes that won't be known until the change is applied.
+func (c *ResourceChange) Decode(ty cty.Type) (before, after cty.Value, err error) {
+	return decodeChange(&c.change, ty)
+}
+
+// SensitivePaths returns the paths of the values that are sensitive within
+// the before and after values returned by Decode. The decoded values
+// themselves are not marked.
+func (c *ResourceChange) SensitivePaths() (before, after []cty.Path) {
+	return sensitivePaths(&c.change)
+}
+
+// OutputChange is the planned change for a single root module output value.
+type OutputChange struct {
+	// Name is the name of the output value.
+	Name string
+
+	// Action is the planned action.
+	Action Action
+
+	// Sensitive is true if the output value is declared as sensitive.
+	Sensitive bool
+
+	change plans.ChangeSrc
+}
+
+// Decode returns the values of the output before and after the change.
+// Output values don't have a schema, so the values have whatever type
+// OpenTofu recorded for them.
+func (c *OutputChange) Decode() (before, after cty.Value, err error) {
+	return decodeChange(&c.change, cty.DynamicPseudoType)
+}
+
+// SensitivePaths returns the paths of the values that are sensitive within
+// the before and after values returned by Decode.
+func (c *OutputChange) SensitivePaths() (before, after []cty.Path) {
+	return sensitivePaths(&c.change)
+}
+
+// Open reads the saved plan file at the given path.
+func Open(filename string) (*Plan, error) {
+	r, err := internal.Open(filename, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		return nil, err
+	}
+	raw, createdBy, err := r.ReadPlanForInspection()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Plan{
+		TofuVersion: createdBy,
+		Errored:     raw.Errored,
+	}
+	switch raw.UIMode {
+	case plans.NormalMode:
+		ret.Mode = NormalMode
+	case plans.DestroyMode:
+		ret.Mode = DestroyMode
+	case plans.RefreshOnlyMode:
+		ret.Mode = RefreshOnlyMode
+	default:
+		return nil, fmt.Errorf("plan has unsupported mode %s", raw.UIMode)
+	}
+
+	if ret.ResourceChanges, err = resourceChanges(raw.Changes.Resources); err != nil {
+		return nil, err
+	}
+	if ret.ResourceDrift, err = resourceChanges(raw.DriftedResources); err != nil {
+		return nil, err
+	}
+	for _, oc := range raw.Changes.Outputs {
+		action, err := actionFromPlans(oc.Action)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", oc.Addr.OutputValue.Name, err)
+		}
+		ret.OutputChanges = append(ret.OutputChanges, &OutputChange{
+			Name:      oc.Addr.OutputValue.Name,
+			Action:    action,
+			Sensitive: oc.Sensitive,
+			change:    oc.ChangeSrc,
+		})
+	}
+
+	return ret, nil
+}
+
+func resourceChanges(srcs []*plans.ResourceInstanceChangeSrc) ([]*ResourceChange, error) {
+	ret := make([]*ResourceChange, 0, len(srcs))
+	for _, rc := range srcs {
+		addr := rc.Addr
+		c := &ResourceChange{
+			Address:         addr.String(),
+			PreviousAddress: rc.PrevRunAddr.String(),
+			ModuleAddress:   addr.Module.String(),
+			Type:            addr.Resource.Resource.Type,
+			Name:            addr.Resource.Resource.Name,
+			DeposedKey:      string(rc.DeposedKey),
+			Provider:        rc.ProviderAddr.Provider.String(),
+			ProviderConfig:  rc.ProviderAddr.String(),
+			GeneratedConfig: rc.GeneratedConfig,
+			change:          rc.ChangeSrc,
+		}
+		switch addr.Resource.Resource.Mode {
+		case addrs.ManagedResourceMode:
+			c.Mode = "managed"
+		case addrs.DataResourceMode:
+			c.Mode = "data"
+		default:
+			return nil, fmt.Errorf("resource %s has unsupported mode %s", c.Address, addr.Resource.Resource.Mode)
+		}
+		if rc.Importing != nil {
+			c.ImportID = rc.Importing.ID
+		}
+
+		var err error
+		if c.Action, err = actionFromPlans(rc.Action); err != nil {
+			return nil, fmt.Errorf("resource %s: %w", c.Address, err)
+		}
+		if c.ActionReason, err = actionReasonFromPlans(rc.ActionReason); err != nil {
+			return nil, fmt.Errorf("resource %s: %w", c.Address, err)
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+func actionFromPlans(action plans.Action) (Action, error) {
+	switch action {
+	case plans.NoOp:
+		return ActionNoOp, nil
+	case plans.Create:
+		return ActionCreate, nil
+	case plans.Read:
+		return ActionRead, nil
+	case plans.Update:
+		return ActionUpdate, nil
+	case plans.Delete:
+		return ActionDelete, nil
+	case plans.DeleteThenCreate:
+		return ActionDeleteThenCreate, nil
+	case plans.CreateThenDelete:
+		return ActionCreateThenDelete, nil
+	case plans.Forget:
+		return ActionForget, nil
+	default:
+		return "", fmt.Errorf("unsupported action %s", action)
+	}
+}
+
+func actionReasonFromPlans(reason plans.ResourceInstanceChangeActionReason) (ActionReason, error) {
+	switch reason {
+	case plans.ResourceInstanceChangeNoReason:
+		return ReasonNone, nil
+	case plans.ResourceInstanceReplaceBecauseCannotUpdate:
+		return ReasonReplaceBecauseCannotUpdate, nil
+	case plans.ResourceInstanceReplaceBecauseTainted:
+		return ReasonReplaceBecauseTainted, nil
+	case plans.ResourceInstanceReplaceByRequest:
+		return ReasonReplaceByRequest, nil
+	case plans.ResourceInstanceReplaceByTriggers:
+		return ReasonReplaceByTriggers, nil
+	case plans.ResourceInstanceDeleteBecauseNoResourceConfig:
+		return ReasonDeleteBecauseNoResourceConfig, nil
+	case plans.ResourceInstanceDeleteBecauseWrongRepetition:
+		return ReasonDeleteBecauseWrongRepetition, nil
+	case plans.ResourceInstanceDeleteBecauseCountIndex:
+		return ReasonDeleteBecauseCountIndex, nil
+	case plans.ResourceInstanceDeleteBecauseEachKey:
+		return ReasonDeleteBecauseEachKey, nil
+	case plans.ResourceInstanceDeleteBecauseNoModule:
+		return ReasonDeleteBecauseNoModule, nil
+	case plans.ResourceInstanceDeleteBecauseNoMoveTarget:
+		return ReasonDeleteBecauseNoMoveTarget, nil
+	case plans.ResourceInstanceReadBecauseConfigUnknown:
+		return ReasonReadBecauseConfigUnknown, nil
+	case plans.ResourceInstanceReadBecauseDependencyPending:
+		return ReasonReadBecauseDependencyPending, nil
+	case plans.ResourceInstanceReadBecauseCheckNested:
+		return ReasonReadBecauseCheckNested, nil
+	default:
+		return "", fmt.Errorf("unsupported action reason %s", reason)
+	}
+}
+
+func decodeChange(cs *plans.ChangeSrc, ty cty.Type) (before, after cty.Value, err error) {
+	before, after = cty.NullVal(ty), cty.NullVal(ty)
+	if len(cs.Before) > 0 {
+		if before, err = cs.Before.Decode(ty); err != nil {
+			return cty.NilVal, cty.NilVal, fmt.Errorf("error decoding 'before' value: %w", err)
+		}
+	}
+	if len(cs.After) > 0 {
+		if after, err = cs.After.Decode(ty); err != nil {
+			return cty.NilVal, cty.NilVal, fmt.Errorf("error decoding 'after' value: %w", err)
+		}
+	}
+	return before, after, nil
+}
+
+func sensitivePaths(cs *plans.ChangeSrc) (before, after []cty.Path) {
+	for _, pvm := range cs.BeforeValMarks {
+		before = append(before, pvm.Path)
+	}
+	for _, pvm := range cs.AfterValMarks {
+		after = append(after, pvm.Path)
+	}
+	return before, after
+}
@@ -0,0 +1,230 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/encryption"
+	"github.com/opentofu/opentofu/internal/plans"
+	internal "github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/version"
+)
+
+func TestOpen(t *testing.T) {
+	ty, err := ImpliedType([]byte(`{
+		"attributes": {
+			"id": {"type": "string", "computed": true},
+			"ami": {"type": "string", "optional": true},
+			"password": {"type": "string", "optional": true, "sensitive": true}
+		},
+		"block_types": {
+			"disk": {
+				"nesting_mode": "list",
+				"block": {
+					"attributes": {
+						"size": {"type": "number", "required": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTy := cty.Object(map[string]cty.Type{
+		"id":       cty.String,
+		"ami":      cty.String,
+		"password": cty.String,
+		"disk":     cty.List(cty.Object(map[string]cty.Type{"size": cty.Number})),
+	})
+	if !ty.Equals(wantTy) {
+		t.Fatalf("wrong implied type\ngot:  %#v\nwant: %#v", ty, wantTy)
+	}
+
+	after := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.UnknownVal(cty.String),
+		"ami":      cty.StringVal("ami-123"),
+		"password": cty.StringVal("hunter2"),
+		"disk": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(10)}),
+		}),
+	})
+	afterRaw, err := plans.NewDynamicValue(after, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforeRaw, err := plans.NewDynamicValue(cty.NullVal(ty), ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputRaw, err := plans.NewDynamicValue(cty.StringVal("ami-123"), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullOutputRaw, err := plans.NewDynamicValue(cty.NullVal(cty.DynamicPseudoType), cty.DynamicPseudoType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.StringKey("a")).Absolute(addrs.RootModuleInstance.Child("child", addrs.NoKey))
+	passwordPath := cty.GetAttrPath("password")
+
+	planFn := filepath.Join(t.TempDir(), "tfplan")
+	err = internal.Create(planFn, internal.CreateArgs{
+		ConfigSnapshot: &configload.Snapshot{
+			Modules: map[string]*configload.SnapshotModule{},
+		},
+		PreviousRunStateFile: &statefile.File{
+			TerraformVersion: version.SemVer,
+			State:            states.NewState(),
+		},
+		StateFile: &statefile.File{
+			TerraformVersion: version.SemVer,
+			State:            states.NewState(),
+		},
+		Plan: &plans.Plan{
+			UIMode: plans.NormalMode,
+			Changes: &plans.Changes{
+				Resources: []*plans.ResourceInstanceChangeSrc{
+					{
+						Addr:        addr,
+						PrevRunAddr: addr,
+						ProviderAddr: addrs.AbsProviderConfig{
+							Module:   addrs.RootModule,
+							Provider: addrs.NewDefaultProvider("test"),
+						},
+						ChangeSrc: plans.ChangeSrc{
+							Action: plans.Create,
+							Before: beforeRaw,
+							After:  afterRaw,
+							AfterValMarks: []cty.PathValueMarks{
+								{Path: passwordPath, Marks: cty.NewValueMarks("sensitive")},
+							},
+						},
+					},
+				},
+				Outputs: []*plans.OutputChangeSrc{
+					{
+						Addr: addrs.OutputValue{Name: "ami"}.Absolute(addrs.RootModuleInstance),
+						ChangeSrc: plans.ChangeSrc{
+							Action: plans.Create,
+							Before: nullOutputRaw,
+							After:  outputRaw,
+						},
+					},
+				},
+			},
+			VariableValues: map[string]plans.DynamicValue{},
+			Backend: plans.Backend{
+				Type:      "local",
+				Config:    plans.DynamicValue([]byte("config placeholder")),
+				Workspace: "default",
+			},
+			Checks: &states.CheckResults{},
+		},
+	}, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to create plan file: %s", err)
+	}
+
+	plan, err := Open(planFn)
+	if err != nil {
+		t.Fatalf("failed to open plan file: %s", err)
+	}
+	if got, want := plan.TofuVersion, version.String(); got != want {
+		t.Errorf("wrong version %q; want %q", got, want)
+	}
+	if got, want := plan.Mode, NormalMode; got != want {
+		t.Errorf("wrong mode %q; want %q", got, want)
+	}
+
+	if got, want := len(plan.ResourceChanges), 1; got != want {
+		t.Fatalf("wrong number of resource changes %d; want %d", got, want)
+	}
+	rc := plan.ResourceChanges[0]
+	if got, want := rc.Address, `module.child.test_instance.foo["a"]`; got != want {
+		t.Errorf("wrong address %q; want %q", got, want)
+	}
+	if got, want := rc.ModuleAddress, "module.child"; got != want {
+		t.Errorf("wrong module address %q; want %q", got, want)
+	}
+	if rc.Mode != "managed" || rc.Type != "test_instance" || rc.Name != "foo" {
+		t.Errorf("wrong resource %s %s.%s", rc.Mode, rc.Type, rc.Name)
+	}
+	if got, want := rc.Provider, "registry.opentofu.org/hashicorp/test"; got != want {
+		t.Errorf("wrong provider %q; want %q", got, want)
+	}
+	if got, want := rc.ProviderConfig, `provider["registry.opentofu.org/hashicorp/test"]`; got != want {
+		t.Errorf("wrong provider config %q; want %q", got, want)
+	}
+	if got, want := rc.Action, ActionCreate; got != want {
+		t.Errorf("wrong action %q; want %q", got, want)
+	}
+
+	gotBefore, gotAfter, err := rc.Decode(ty)
+	if err != nil {
+		t.Fatalf("failed to decode change: %s", err)
+	}
+	if !gotBefore.IsNull() {
+		t.Errorf("before value should be null, got %#v", gotBefore)
+	}
+	if !gotAfter.RawEquals(after) {
+		t.Errorf("wrong after value\ngot:  %#v\nwant: %#v", gotAfter, after)
+	}
+	if _, _, err := rc.Decode(cty.String); err == nil {
+		t.Errorf("decoding with the wrong type succeeded")
+	}
+	sensitiveBefore, sensitiveAfter := rc.SensitivePaths()
+	if len(sensitiveBefore) != 0 {
+		t.Errorf("unexpected sensitive paths before: %#v", sensitiveBefore)
+	}
+	if len(sensitiveAfter) != 1 || !sensitiveAfter[0].Equals(passwordPath) {
+		t.Errorf("wrong sensitive paths after: %#v", sensitiveAfter)
+	}
+
+	if got, want := len(plan.OutputChanges), 1; got != want {
+		t.Fatalf("wrong number of output changes %d; want %d", got, want)
+	}
+	oc := plan.OutputChanges[0]
+	if oc.Name != "ami" || oc.Action != ActionCreate {
+		t.Errorf("wrong output change %s %s", oc.Action, oc.Name)
+	}
+	_, gotOutput, err := oc.Decode()
+	if err != nil {
+		t.Fatalf("failed to decode output change: %s", err)
+	}
+	if want := cty.StringVal("ami-123"); !gotOutput.RawEquals(want) {
+		t.Errorf("wrong output value\ngot:  %#v\nwant: %#v", gotOutput, want)
+	}
+}
+
+func TestImpliedType_invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":         `{`,
+		"no type":          `{"attributes": {"foo": {"optional": true}}}`,
+		"bad type":         `{"attributes": {"foo": {"type": "strang"}}}`,
+		"bad nesting mode": `{"block_types": {"foo": {"nesting_mode": "nope", "block": {}}}}`,
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ImpliedType([]byte(src)); err == nil {
+				t.Fatal("unexpected success")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+// Copyright (c) 2023 HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+)
+
+// ImpliedType returns the type of the objects described by a resource type
+// schema, for use with ResourceChange.Decode.
+//
+// The given source must be a schema block as it appears in the "block"
+// property of a resource type or data source in the output of
+// "tofu providers schema -json".
+func ImpliedType(src []byte) (cty.Type, error) {
+	var raw jsonprovider.Block
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return cty.NilType, fmt.Errorf("invalid schema block: %w", err)
+	}
+	block, err := schemaBlock(&raw)
+	if err != nil {
+		return cty.NilType, err
+	}
+	return block.ImpliedType(), nil
+}
+
+func schemaBlock(raw *jsonprovider.Block) (*configschema.Block, error) {
+	ret := &configschema.Block{
+		Attributes: make(map[string]*configschema.Attribute, len(raw.Attributes)),
+		BlockTypes: make(map[string]*configschema.NestedBlock, len(raw.BlockTypes)),
+	}
+	for name, rawAttr := range raw.Attributes {
+		attr, err := schemaAttribute(rawAttr)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		ret.Attributes[name] = attr
+	}
+	for name, rawBlock := range raw.BlockTypes {
+		nesting, err := nestingMode(rawBlock.NestingMode)
+		if err != nil {
+			return nil, fmt.Errorf("block type %q: %w", name, err)
+		}
+		if rawBlock.Block == nil {
+			return nil, fmt.Errorf("block type %q has no schema", name)
+		}
+		block, err := schemaBlock(rawBlock.Block)
+		if err != nil {
+			return nil, fmt.Errorf("block type %q: %w", name, err)
+		}
+		ret.BlockTypes[name] = &configschema.NestedBlock{
+			Block:    *block,
+			Nesting:  nesting,
+			MinItems: int(rawBlock.MinItems),
+			MaxItems: int(rawBlock.MaxItems),
+		}
+	}
+	return ret, nil
+}
+
+func schemaAttribute(raw *jsonprovider.Attribute) (*configschema.Attribute, error) {
+	ret := &configschema.Attribute{
+		Required:  raw.Required,
+		Optional:  raw.Optional,
+		Computed:  raw.Computed,
+		Sensitive: raw.Sensitive,
+	}
+	switch {
+	case raw.AttributeNestedType != nil:
+		nesting, err := nestingMode(raw.AttributeNestedType.NestingMode)
+		if err != nil {
+			return nil, err
+		}
+		ret.NestedType = &configschema.Object{
+			Attributes: make(map[string]*configschema.Attribute, len(raw.AttributeNestedType.Attributes)),
+			Nesting:    nesting,
+		}
+		for name, rawAttr := range raw.AttributeNestedType.Attributes {
+			attr, err := schemaAttribute(rawAttr)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %q: %w", name, err)
+			}
+			ret.NestedType.Attributes[name] = attr
+		}
+	case len(raw.AttributeType) > 0:
+		ty, err := ctyjson.UnmarshalType(raw.AttributeType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type: %w", err)
+		}
+		ret.Type = ty
+	default:
+		return nil, fmt.Errorf("neither a type nor a nested type is specified")
+	}
+	return ret, nil
+}
+
+func nestingMode(raw string) (configschema.NestingMode, error) {
+	switch raw {
+	case "single":
+		return configschema.NestingSingle, nil
+	case "group":
+		return configschema.NestingGroup, nil
+	case "list":
+		return configschema.NestingList, nil
+	case "set":
+		return configschema.NestingSet, nil
+	case "map":
+		return configschema.NestingMap, nil
+	default:
+		return configschema.NestingMode(0), fmt.Errorf("unsupported nesting mode %q", raw)
+	}
+}
@@ -0,0 +1,7 @@
+resource "aws_s3_bucket" "test" {
+  bucket = "my-test-bucket"
+}
+
+output "bucket_arn" {
+  value = aws_s3_bucket.test.arn
+}
@@ -0,0 +1,20 @@
+// The mock resource declares its own schema, so OpenTofu
+// doesn't need the aws provider to run this test.
+mock_provider "aws" {
+  mock_resource "aws_s3_bucket" {
+    schema = {
+      bucket = string
+      arn    = string
+    }
+    defaults = {
+      arn = "arn:aws:s3:::my-test-bucket"
+    }
+  }
+}
+
+run "test" {
+  assert {
+    condition     = output.bucket_arn == "arn:aws:s3:::my-test-bucket"
+    error_message = "Incorrect bucket ARN: ${output.bucket_arn}"
+  }
+}